@@ -1,60 +1,11657 @@
-// Package api Code generated by swaggo/swag. DO NOT EDIT
+// Package api Code generated by cmd/apigen. DO NOT EDIT
 package api
 
 import "github.com/swaggo/swag"
 
 const docTemplate = `{
-    "schemes": {{ marshal .Schemes }},
     "swagger": "2.0",
     "info": {
-        "description": "{{escape .Description}}",
         "title": "{{.Title}}",
+        "description": "{{escape .Description}}",
         "contact": {},
         "version": "{{.Version}}"
     },
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/cost/global": {
+        "/admin/adjustments": {
             "get": {
-                "tags": ["Cost"],
-                "summary": "Global cost overview",
-                "description": "Returns L0 global cost. Response <10ms. L0 = sum of L1.",
-                "operationId": "getGlobalCost",
-                "responses": { "200": { "description": "OK" }, "500": { "description": "Internal Server Error" } }
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List adjustments of closed accounting periods",
+                "operationId": "listAdjustments",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "period",
+                        "in": "query",
+                        "description": "period label, e.g. FY2026 P09",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "namespace",
+                        "in": "query",
+                        "description": "namespace",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, max 500 (default all)",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "description": "next_cursor of the previous page (instead of offset)",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CostAdjustmentList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Record a manual adjustment of a closed accounting period",
+                "operationId": "createAdjustment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "scope, amount and reason",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateAdjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CostAdjustment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
-        "/cost/namespaces": {
+        "/admin/adjustments/{id}/approve": {
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Approve a manual adjustment of a closed accounting period",
+                "operationId": "approveAdjustment",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "adjustment id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CostAdjustment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chaos": {
+            "delete": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Stop the active resilience drill",
+                "operationId": "stopChaosDrill",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChaosResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "get": {
-                "tags": ["Cost"],
-                "summary": "List namespaces with cost summary",
-                "description": "Returns namespaces with cost summary (L1 aggregated)",
-                "operationId": "listNamespaces",
-                "responses": { "200": { "description": "OK" }, "500": { "description": "Internal Server Error" } }
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Configured chaos scenarios and the current or last resilience drill",
+                "operationId": "getChaos",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChaosResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Start a resilience drill",
+                "operationId": "startChaosDrill",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "scenario and duration",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StartChaosDrillRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChaosResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
-        "/cost/namespace/{namespace}": {
+        "/admin/dataset/export": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Export the cost dataset, optionally anonymized",
+                "operationId": "exportDataset",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339), default end_time - 7 days",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339), default the current hour",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "anonymize",
+                        "in": "query",
+                        "description": "replace namespace, workload, pod, node and cost center names with pseudonyms",
+                        "required": false,
+                        "type": "boolean"
+                    },
+                    {
+                        "name": "scale",
+                        "in": "query",
+                        "description": "cost multiplier, default 1",
+                        "required": false,
+                        "type": "number"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Dataset"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/import/allocations": {
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Import Kubecost / OpenCost allocation history",
+                "operationId": "importAllocations",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "source",
+                        "in": "query",
+                        "description": "source of the export, default kubecost",
+                        "required": false,
+                        "type": "string",
+                        "enum": [
+                            "kubecost",
+                            "opencost"
+                        ]
+                    },
+                    {
+                        "name": "format",
+                        "in": "query",
+                        "description": "json (allocation API response) or csv (allocation CSV export)",
+                        "required": false,
+                        "type": "string",
+                        "enum": [
+                            "json",
+                            "csv"
+                        ]
+                    },
+                    {
+                        "name": "dry_run",
+                        "in": "query",
+                        "description": "only report the rows the import would write",
+                        "required": false,
+                        "type": "boolean"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "allocation export",
+                        "required": true,
+                        "schema": {}
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CostImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/onboarding": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List onboarded namespaces",
+                "operationId": "listOnboardings",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, max 500 (default all)",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "description": "next_cursor of the previous page (instead of offset)",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NamespaceOnboardingList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Onboard a namespace",
+                "operationId": "onboardNamespace",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "namespace, owner team and settings",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OnboardNamespaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NamespaceOnboarding"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/onboarding/{namespace}": {
             "get": {
-                "tags": ["Cost"],
-                "summary": "Namespace cost details",
-                "description": "Returns cost breakdown for a namespace",
-                "operationId": "getNamespaceCost",
-                "parameters": [{ "name": "namespace", "in": "path", "required": true, "type": "string" }],
-                "responses": { "200": { "description": "OK" }, "404": { "description": "Not Found" }, "500": { "description": "Internal Server Error" } }
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get the onboarding of a namespace",
+                "operationId": "getOnboarding",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "path",
+                        "description": "namespace",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NamespaceOnboarding"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
-        "/cost/drilldown/{level}/{identifier}": {
+        "/admin/periods": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List closed accounting periods",
+                "operationId": "listAccountingPeriods",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, max 500 (default all)",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "description": "next_cursor of the previous page (instead of offset)",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountingPeriodList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/periods/close": {
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Close an accounting period",
+                "operationId": "closeAccountingPeriod",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "date",
+                        "in": "query",
+                        "description": "a day of the period (YYYY-MM-DD), default the last ended period",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "note",
+                        "required": false,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CloseAccountingPeriodRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountingPeriod"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/query-budgets": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Prometheus and database queries of every API consumer in the current query budget window",
+                "operationId": "listQueryBudgets",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QueryBudgetResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/regrade": {
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Regrade cost snapshots under new efficiency thresholds",
+                "operationId": "regradeSnapshots",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "window and thresholds",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegradeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegradeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/regrade/view": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "The view of snapshot grade counts currently served",
+                "operationId": "getGradeView",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GradeView"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Switch the view of snapshot grade counts",
+                "operationId": "setGradeView",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "view",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetGradeViewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GradeView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List service accounts with their API keys and today's usage",
+                "operationId": "listServiceAccounts",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ServiceAccountListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Create a service account",
+                "operationId": "createServiceAccount",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "service account",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateServiceAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ServiceAccount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts/{id}": {
+            "delete": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Delete a service account and its API keys",
+                "operationId": "deleteServiceAccount",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "service account id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get a service account with its API keys and today's usage",
+                "operationId": "getServiceAccount",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "service account id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ServiceAccount"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Update or disable a service account",
+                "operationId": "updateServiceAccount",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "service account id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "description and disabled flag",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateServiceAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ServiceAccount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts/{id}/keys": {
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Issue an API key for a service account",
+                "operationId": "createAPIKey",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "service account id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "scopes, daily quota and expiry",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts/{id}/keys/{key_id}": {
+            "delete": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke an API key",
+                "operationId": "revokeAPIKey",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "service account id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "key_id",
+                        "in": "path",
+                        "description": "api key id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.APIKey"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts/{id}/usage": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Daily request and quota rejection counts of a service account's API keys",
+                "operationId": "serviceAccountUsage",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "service account id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "days",
+                        "in": "query",
+                        "description": "days ending today, default 7, at most 90",
+                        "required": false,
+                        "type": "integer"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.APIKeyUsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/spool": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Spool stats and pending entries",
+                "operationId": "listSpool",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/spool/flush": {
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Replay all pending spool entries",
+                "operationId": "flushSpool",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/spool/{id}": {
+            "delete": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Discard a spool entry",
+                "operationId": "deleteSpoolEntry",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "entry id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "A spool entry with its payload",
+                "operationId": "getSpoolEntry",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "entry id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/state/export": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Export the application state bundle",
+                "operationId": "exportState",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StateBundle"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/state/import": {
+            "post": {
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Import an application state bundle",
+                "operationId": "importState",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "dry_run",
+                        "in": "query",
+                        "description": "only report the changes",
+                        "required": false,
+                        "type": "boolean"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "bundle",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StateBundle"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StateImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/routes": {
+            "get": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "List notification routing rules in matching order",
+                "operationId": "listNotificationRoutes",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationRouteListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Create a notification routing rule",
+                "operationId": "createNotificationRoute",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "label matchers, channels and priority",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationRouteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationRoute"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/routes/{id}": {
+            "delete": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Delete a notification routing rule",
+                "operationId": "deleteNotificationRoute",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "route id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "get": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Get a notification routing rule",
+                "operationId": "getNotificationRoute",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "route id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationRoute"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Replace a notification routing rule",
+                "operationId": "updateNotificationRoute",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "route id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "label matchers, channels and priority",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationRouteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationRoute"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/rules": {
+            "get": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "List alert rules with their state and the metrics conditions may use",
+                "operationId": "listAlertRules",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AlertRuleListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Create an alert rule",
+                "operationId": "createAlertRule",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "condition, window, interval, severity and routing",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AlertRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AlertRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/rules/{id}": {
+            "delete": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Delete an alert rule",
+                "operationId": "deleteAlertRule",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "alert rule id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "get": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Get an alert rule with its state",
+                "operationId": "getAlertRule",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "alert rule id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AlertRule"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Replace the definition of an alert rule",
+                "operationId": "updateAlertRule",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "alert rule id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "condition, window, interval, severity and routing",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AlertRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AlertRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/rules/{id}/evaluate": {
+            "post": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Evaluate an alert rule now",
+                "operationId": "evaluateAlertRule",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "alert rule id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AlertRuleEvaluation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/silences": {
+            "get": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "List notification silences",
+                "operationId": "listSilences",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "all",
+                        "in": "query",
+                        "description": "include expired silences",
+                        "required": false,
+                        "type": "boolean"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SilenceListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "Silence matching notifications for a time window",
+                "operationId": "createSilence",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "label matchers, time window and reason",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSilenceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Silence"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/silences/{id}": {
+            "delete": {
+                "tags": [
+                    "Alerts"
+                ],
+                "summary": "End a notification silence",
+                "operationId": "expireSilence",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "silence id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Silence"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/analysis/offhours": {
             "get": {
-                "tags": ["Cost"],
-                "summary": "Cost drilldown",
-                "description": "Drill down by level (L1-L4). Query dimension=compute|storage|network",
-                "operationId": "getDrilldownCost",
+                "tags": [
+                    "Analysis"
+                ],
+                "summary": "Off-hours (nights/weekends) idle cost and scaling candidates",
+                "operationId": "offHoursAnalysis",
+                "produces": [
+                    "application/json"
+                ],
                 "parameters": [
-                    { "name": "level", "in": "path", "required": true, "type": "string" },
-                    { "name": "identifier", "in": "path", "required": true, "type": "string" },
-                    { "name": "dimension", "in": "query", "required": false, "type": "string", "enum": ["compute", "storage", "network"] }
+                    {
+                        "name": "days",
+                        "in": "query",
+                        "description": "window length, default 7",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "namespace",
+                        "in": "query",
+                        "description": "namespace",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "workloads with the highest idle off-hours cost to keep",
+                        "required": false,
+                        "type": "integer"
+                    }
                 ],
-                "responses": { "200": { "description": "OK" }, "400": { "description": "Bad Request" }, "500": { "description": "Internal Server Error" } }
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OffHoursAnalysisResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/calculations/runs": {
+            "get": {
+                "tags": [
+                    "Calculation"
+                ],
+                "summary": "Cost calculation runs",
+                "operationId": "listCalculationRuns",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "status",
+                        "in": "query",
+                        "description": "run status",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "trigger",
+                        "in": "query",
+                        "description": "run trigger",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CalculationRunListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Calculation"
+                ],
+                "summary": "Run the cost pipeline for a window",
+                "operationId": "triggerCalculationRun",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "window",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TriggerCalculationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CalculationRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/calculations/runs/{id}": {
+            "get": {
+                "tags": [
+                    "Calculation"
+                ],
+                "summary": "A cost calculation run",
+                "operationId": "getCalculationRun",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "run id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CalculationRun"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/calculations/runs/{id}/retrigger": {
+            "post": {
+                "tags": [
+                    "Calculation"
+                ],
+                "summary": "Re-run a failed calculation run",
+                "operationId": "retriggerCalculationRun",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "run id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CalculationRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/capacity/projection": {
+            "get": {
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Capacity projection of a node pool",
+                "operationId": "capacityProjection",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "horizon_days",
+                        "in": "query",
+                        "description": "projection horizon, default 90",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "target_utilization",
+                        "in": "query",
+                        "description": "target utilization, default 0.85",
+                        "required": false,
+                        "type": "number"
+                    },
+                    {
+                        "name": "node_delta",
+                        "in": "query",
+                        "description": "nodes added (negative: removed) in the scenario",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "pool",
+                        "in": "query",
+                        "description": "node pool, default the largest",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CapacityProjectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/capacity/simulation": {
+            "get": {
+                "tags": [
+                    "Capacity"
+                ],
+                "summary": "Autoscaler simulation of a pod request change",
+                "operationId": "capacitySimulation",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request_change_percent",
+                        "in": "query",
+                        "description": "change of every pod's requests in %, e.g. -20",
+                        "required": false,
+                        "type": "number"
+                    },
+                    {
+                        "name": "target_utilization",
+                        "in": "query",
+                        "description": "target utilization, default 0.85",
+                        "required": false,
+                        "type": "number"
+                    },
+                    {
+                        "name": "pool",
+                        "in": "query",
+                        "description": "node pool, default all pools",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CapacitySimulationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/allocation/preview": {
+            "post": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Preview the per-namespace impact of candidate shared cost allocation rules",
+                "operationId": "previewAllocation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "candidate rules and period",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AllocationPreviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AllocationPreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/drilldown/{level}/{identifier}": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Cost drilldown by level",
+                "operationId": "drilldownCost",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "level",
+                        "in": "path",
+                        "description": "namespace/node/workload/pod or L1-L4",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "identifier",
+                        "in": "path",
+                        "description": "item id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "dimension",
+                        "in": "query",
+                        "description": "cost dimension",
+                        "required": false,
+                        "type": "string",
+                        "enum": [
+                            "compute",
+                            "storage",
+                            "network"
+                        ]
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/cost/efficiency/heatmap": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Efficiency heatmap of namespaces by day",
+                "operationId": "efficiencyHeatmap",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "days",
+                        "in": "query",
+                        "description": "window length ending today, default 30",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "bucket_days",
+                        "in": "query",
+                        "description": "days per bucket, default 1",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "namespaces with the highest billable cost to keep",
+                        "required": false,
+                        "type": "integer"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EfficiencyHeatmapResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/fiscal-report": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Cost of a fiscal period, quarter or year to date",
+                "operationId": "fiscalReport",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "unit",
+                        "in": "query",
+                        "description": "period, quarter or year; default period",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "date",
+                        "in": "query",
+                        "description": "a day of the span (YYYY-MM-DD), default today",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FiscalReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/global": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Global cost overview (L0 = sum of L1)",
+                "operationId": "globalCost",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GlobalCostResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/grades/history": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Workload efficiency grade transitions",
+                "operationId": "gradeHistory",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "query",
+                        "description": "namespace",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "workload",
+                        "in": "query",
+                        "description": "workload name",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "grade",
+                        "in": "query",
+                        "description": "grade transitioned to",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GradeHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/namespace/{namespace}": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Cost breakdown of a namespace",
+                "operationId": "namespaceCost",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "path",
+                        "description": "namespace",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "sort",
+                        "in": "query",
+                        "description": "workload sort field, default cost",
+                        "required": false,
+                        "type": "string",
+                        "enum": [
+                            "cost",
+                            "waste",
+                            "efficiency",
+                            "name"
+                        ]
+                    },
+                    {
+                        "name": "order",
+                        "in": "query",
+                        "description": "workload sort order, default desc",
+                        "required": false,
+                        "type": "string",
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ]
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "workload page size, default 50, max 500",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "workload page offset",
+                        "required": false,
+                        "type": "integer"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NamespaceCostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/namespaces": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Namespaces with cost summary",
+                "operationId": "listNamespaces",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "sort",
+                        "in": "query",
+                        "description": "sort field, default cost",
+                        "required": false,
+                        "type": "string",
+                        "enum": [
+                            "cost",
+                            "waste",
+                            "efficiency",
+                            "name"
+                        ]
+                    },
+                    {
+                        "name": "order",
+                        "in": "query",
+                        "description": "sort order, default desc",
+                        "required": false,
+                        "type": "string",
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ]
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, max 500 (default all)",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "description": "next_cursor of the previous page (instead of offset)",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NamespaceCostSummaryList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/series": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Cost time series at a resolution planned from the range",
+                "operationId": "costSeries",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "range start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "range end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "namespace",
+                        "in": "query",
+                        "description": "namespace, default all",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "resolution",
+                        "in": "query",
+                        "description": "granularity, default planned from the range (hourly up to 72h, daily up to 92 days, monthly beyond)",
+                        "required": false,
+                        "type": "string",
+                        "enum": [
+                            "hourly",
+                            "daily",
+                            "monthly"
+                        ]
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CostSeriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/targets": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "List namespace efficiency targets",
+                "operationId": "listEfficiencyTargets",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, max 500 (default all)",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "description": "next_cursor of the previous page (instead of offset)",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EfficiencyTargetListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/targets/{namespace}": {
+            "put": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Set the efficiency target of a namespace",
+                "operationId": "setEfficiencyTarget",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "path",
+                        "description": "namespace",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "target",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetEfficiencyTargetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EfficiencyTarget"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/targets/{namespace}/tracking": {
+            "get": {
+                "tags": [
+                    "Cost"
+                ],
+                "summary": "Track a namespace's efficiency against its target",
+                "operationId": "trackEfficiencyTarget",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "path",
+                        "description": "namespace",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EfficiencyTargetTrackingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dependencies": {
+            "get": {
+                "tags": [
+                    "Dependencies"
+                ],
+                "summary": "List service dependency edges",
+                "operationId": "listDependencies",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, max 500 (default all)",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "description": "next_cursor of the previous page (instead of offset)",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DependencyEdgeList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dependencies/edges": {
+            "post": {
+                "tags": [
+                    "Dependencies"
+                ],
+                "summary": "Ingest service dependency edges",
+                "operationId": "ingestDependencies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "source and edges",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.DependencyIngestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DependencyIngestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports": {
+            "get": {
+                "tags": [
+                    "Export"
+                ],
+                "summary": "List export jobs and the accepted formats",
+                "operationId": "listExportJobs",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportJobListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Export"
+                ],
+                "summary": "Create an async export job",
+                "operationId": "createExportJob",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "export job",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateExportJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}": {
+            "get": {
+                "tags": [
+                    "Export"
+                ],
+                "summary": "Get an export job",
+                "operationId": "getExportJob",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "export job ID",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}/download": {
+            "get": {
+                "tags": [
+                    "Export"
+                ],
+                "summary": "Download the file of an export job",
+                "operationId": "downloadExportJob",
+                "produces": [
+                    "text/csv,application/pdf"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "export job ID",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the export file (Content-Disposition names it)"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/grafana": {
+            "get": {
+                "tags": [
+                    "Grafana"
+                ],
+                "summary": "Grafana datasource test connection",
+                "operationId": "grafanaTestConnection",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/grafana/annotations": {
+            "post": {
+                "tags": [
+                    "Grafana"
+                ],
+                "summary": "Grafana annotations",
+                "operationId": "grafanaAnnotations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "annotation query",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GrafanaAnnotationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.GrafanaAnnotation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/grafana/query": {
+            "post": {
+                "tags": [
+                    "Grafana"
+                ],
+                "summary": "Grafana time series / table query",
+                "operationId": "grafanaQuery",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "query",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GrafanaQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {}
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/grafana/search": {
+            "post": {
+                "tags": [
+                    "Grafana"
+                ],
+                "summary": "Grafana metric search",
+                "operationId": "grafanaSearch",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "search",
+                        "required": false,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GrafanaSearchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lineage/{id}": {
+            "get": {
+                "tags": [
+                    "Lineage"
+                ],
+                "summary": "Data lineage of a cost query response",
+                "operationId": "getLineage",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "lineage id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QueryLineage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/analysis": {
+            "get": {
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Per-node costs and consolidation candidates",
+                "operationId": "nodeAnalysis",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "target_utilization",
+                        "in": "query",
+                        "description": "target utilization in (0, 1], default 0.85",
+                        "required": false,
+                        "type": "number"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NodeAnalysisResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/consolidation-plan": {
+            "get": {
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Reviewable node drain plan with savings and risk",
+                "operationId": "nodeConsolidationPlan",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "target_utilization",
+                        "in": "query",
+                        "description": "target utilization in (0, 1], default 0.85",
+                        "required": false,
+                        "type": "number"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NodeConsolidationPlan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/pools": {
+            "get": {
+                "tags": [
+                    "Node"
+                ],
+                "summary": "Capacity and workload cost per node pool",
+                "operationId": "nodePoolAnalysis",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339), default end_time - 24h",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339), default now",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NodePoolAnalysisResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/preferences": {
+            "get": {
+                "tags": [
+                    "Preferences"
+                ],
+                "summary": "Get the preferences of the calling user (defaults when none are saved)",
+                "operationId": "getPreferences",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserPreferences"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "tags": [
+                    "Preferences"
+                ],
+                "summary": "Update the preferences of the calling user",
+                "operationId": "updatePreferences",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "changed preferences",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/preferences/views": {
+            "post": {
+                "tags": [
+                    "Preferences"
+                ],
+                "summary": "Save a view (named page filters) of the calling user",
+                "operationId": "savePreferenceView",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "view",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SavedViewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SavedView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/preferences/views/{id}": {
+            "delete": {
+                "tags": [
+                    "Preferences"
+                ],
+                "summary": "Delete a saved view of the calling user",
+                "operationId": "deletePreferenceView",
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "view ID",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/changes": {
+            "get": {
+                "tags": [
+                    "Pricing"
+                ],
+                "summary": "List price changes",
+                "operationId": "listPriceChanges",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "status",
+                        "in": "query",
+                        "description": "draft, approved, effective or rejected",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceChangeListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Pricing"
+                ],
+                "summary": "Propose a unit price change",
+                "operationId": "proposePriceChange",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "proposed price version",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/changes/{id}": {
+            "get": {
+                "tags": [
+                    "Pricing"
+                ],
+                "summary": "Get a price change with its history",
+                "operationId": "getPriceChange",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "price change id",
+                        "required": true,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceChange"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/changes/{id}/approve": {
+            "post": {
+                "tags": [
+                    "Pricing"
+                ],
+                "summary": "Approve a price change and write it to the price history",
+                "operationId": "approvePriceChange",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "price change id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "review comment",
+                        "required": false,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReviewPriceChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceChangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/changes/{id}/reject": {
+            "post": {
+                "tags": [
+                    "Pricing"
+                ],
+                "summary": "Reject a price change",
+                "operationId": "rejectPriceChange",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "price change id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "review comment",
+                        "required": false,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReviewPriceChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/history": {
+            "get": {
+                "tags": [
+                    "Pricing"
+                ],
+                "summary": "Unit price history",
+                "operationId": "getPriceHistory",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceHistoryResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "tags": [
+                    "Pricing"
+                ],
+                "summary": "Add or correct a unit price version",
+                "operationId": "setPrice",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "price version",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceChangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/recalculate": {
+            "post": {
+                "tags": [
+                    "Pricing"
+                ],
+                "summary": "Re-price a window with the price history",
+                "operationId": "recalculatePrices",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "window",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TriggerCalculationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CalculationRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roi/baselines/{id}/compare": {
+            "get": {
+                "tags": [
+                    "ROI"
+                ],
+                "summary": "Compare a day against any stored ROI baseline",
+                "operationId": "compareROIBaseline",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "ROI baseline ID",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "date",
+                        "in": "query",
+                        "description": "compared day (YYYY-MM-DD), default yesterday",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ROIBaselineComparisonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roi/dashboard": {
+            "get": {
+                "tags": [
+                    "ROI"
+                ],
+                "summary": "ROI summary and trends",
+                "operationId": "roiDashboard",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/roi/optimizations": {
+            "get": {
+                "tags": [
+                    "ROI"
+                ],
+                "summary": "Optimization tracking records, e.g. of exported recommendations awaiting verification",
+                "operationId": "listOptimizationRecords",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "bundle_id",
+                        "in": "query",
+                        "description": "manifest bundle ID (X-Bundle-ID of the export)",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "status",
+                        "in": "query",
+                        "description": "pending or verified",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, max 500 (default all)",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "description": "next_cursor of the previous page (instead of offset)",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OptimizationRecordListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roi/recommendations/adoption": {
+            "get": {
+                "tags": [
+                    "ROI"
+                ],
+                "summary": "Adoption rate of issued recommendations per team and their unrealized savings",
+                "operationId": "recommendationAdoption",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "since",
+                        "in": "query",
+                        "description": "issued since (RFC3339), default 90 days ago",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecommendationAdoptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roi/recommendations/export": {
+            "post": {
+                "tags": [
+                    "ROI"
+                ],
+                "summary": "Export accepted recommendations as Kubernetes patch manifests",
+                "operationId": "exportRecommendations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/zip"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "recommendations to export",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportRecommendationsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the manifest bundle (Content-Disposition names it)"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slo/cost": {
+            "get": {
+                "tags": [
+                    "SLO"
+                ],
+                "summary": "Cost of SLO compliance per service",
+                "operationId": "sloCost",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339), default end_time - 7d",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339), default now",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SLOCostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slo/health": {
+            "get": {
+                "tags": [
+                    "SLO"
+                ],
+                "summary": "SLO health of services",
+                "operationId": "sloHealth",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, max 500 (default all)",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "description": "next_cursor of the previous page (instead of offset)",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SLOHealthList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slo/self": {
+            "get": {
+                "tags": [
+                    "SLO"
+                ],
+                "summary": "SLOs of Lighthouse's own API routes",
+                "operationId": "selfSLO",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SelfSLOResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshots": {
+            "get": {
+                "tags": [
+                    "Snapshot"
+                ],
+                "summary": "List cost snapshots",
+                "operationId": "listSnapshots",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "max snapshots",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "snapshots to skip",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "fields",
+                        "in": "query",
+                        "description": "full, summary (default) or counts",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "include",
+                        "in": "query",
+                        "description": "comma-separated aggregated_results, resource_results",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CostSnapshotListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshots/export": {
+            "get": {
+                "tags": [
+                    "Snapshot"
+                ],
+                "summary": "Export verified cost snapshots",
+                "operationId": "exportSnapshots",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SnapshotExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshots/grades": {
+            "get": {
+                "tags": [
+                    "Snapshot"
+                ],
+                "summary": "Grade counts of cost snapshots",
+                "operationId": "snapshotGrades",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "view",
+                        "in": "query",
+                        "description": "original / regraded, default the view currently served",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SnapshotGradesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshots/suspect": {
+            "get": {
+                "tags": [
+                    "Snapshot"
+                ],
+                "summary": "List suspect cost snapshots",
+                "operationId": "listSuspectSnapshots",
+                "produces": [
+                    "application/json"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuspectSnapshotListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshots/verify": {
+            "get": {
+                "tags": [
+                    "Snapshot"
+                ],
+                "summary": "Verify cost snapshots against the hash chain",
+                "operationId": "verifySnapshots",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "snapshot_id",
+                        "in": "query",
+                        "description": "only this snapshot",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SnapshotVerificationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshots/{id}": {
+            "get": {
+                "tags": [
+                    "Snapshot"
+                ],
+                "summary": "A cost snapshot with field selection",
+                "operationId": "getSnapshot",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "snapshot id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "fields",
+                        "in": "query",
+                        "description": "full (default), summary or counts",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "include",
+                        "in": "query",
+                        "description": "comma-separated aggregated_results, resource_results",
+                        "required": false,
+                        "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CostSnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/snapshots/{id}/confirm": {
+            "post": {
+                "tags": [
+                    "Snapshot"
+                ],
+                "summary": "Confirm a suspect cost snapshot",
+                "operationId": "confirmSnapshot",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "id",
+                        "in": "path",
+                        "description": "snapshot id",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "confirmation",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfirmSnapshotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuspectSnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/timeline": {
+            "get": {
+                "tags": [
+                    "Timeline"
+                ],
+                "summary": "Cluster events timeline",
+                "operationId": "timeline",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "query",
+                        "description": "namespace (default: whole cluster)",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "kinds",
+                        "in": "query",
+                        "description": "comma-separated k8s_event, config_change, calculation_run, price_change, slo_violation",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "latest events returned (default 1000)",
+                        "required": false,
+                        "type": "integer"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TimelineResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/timeline/config-changes": {
+            "post": {
+                "tags": [
+                    "Timeline"
+                ],
+                "summary": "Record a config change on the timeline",
+                "operationId": "recordConfigChange",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "changed object and values",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfigChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfigChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/timeline/slo-violations": {
+            "post": {
+                "tags": [
+                    "Timeline"
+                ],
+                "summary": "Record an SLO violation on the timeline",
+                "operationId": "recordSLOViolation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "request",
+                        "in": "body",
+                        "description": "violated service, type and values",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SLOViolationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SLOViolationRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workloads/catalog": {
+            "get": {
+                "tags": [
+                    "Workload"
+                ],
+                "summary": "Search the workload catalog",
+                "operationId": "searchCatalog",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "q",
+                        "in": "query",
+                        "description": "free text; every word must prefix-match the workload",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "namespace",
+                        "in": "query",
+                        "description": "namespace facet",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "team",
+                        "in": "query",
+                        "description": "owner team facet",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "grade",
+                        "in": "query",
+                        "description": "grade facet",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "type",
+                        "in": "query",
+                        "description": "workload type facet",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "slo_status",
+                        "in": "query",
+                        "description": "SLO status facet",
+                        "required": false,
+                        "type": "string"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "description": "page size, default 50, max 500",
+                        "required": false,
+                        "type": "integer"
+                    },
+                    {
+                        "name": "offset",
+                        "in": "query",
+                        "description": "page offset",
+                        "required": false,
+                        "type": "integer"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CatalogSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workloads/{namespace}/{name}/costs": {
+            "get": {
+                "tags": [
+                    "Workload"
+                ],
+                "summary": "Cost detail of a workload",
+                "operationId": "workloadCost",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "path",
+                        "description": "namespace",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "name",
+                        "in": "path",
+                        "description": "workload name",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WorkloadCostDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workloads/{namespace}/{name}/history": {
+            "get": {
+                "tags": [
+                    "Workload"
+                ],
+                "summary": "Daily cost, efficiency and grade series of a workload",
+                "operationId": "workloadHistory",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "path",
+                        "description": "namespace",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "name",
+                        "in": "path",
+                        "description": "workload name",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "days",
+                        "in": "query",
+                        "description": "days of history including today, default 90, at most 366",
+                        "required": false,
+                        "type": "integer"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WorkloadHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workloads/{namespace}/{name}/impact": {
+            "get": {
+                "tags": [
+                    "Workload"
+                ],
+                "summary": "Upstream services and spend depending on a workload",
+                "operationId": "workloadImpact",
+                "produces": [
+                    "application/json"
+                ],
+                "parameters": [
+                    {
+                        "name": "namespace",
+                        "in": "path",
+                        "description": "namespace",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "name",
+                        "in": "path",
+                        "description": "workload (service) name",
+                        "required": true,
+                        "type": "string"
+                    },
+                    {
+                        "name": "start_time",
+                        "in": "query",
+                        "description": "window start (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    },
+                    {
+                        "name": "end_time",
+                        "in": "query",
+                        "description": "window end (RFC3339)",
+                        "required": false,
+                        "type": "string",
+                        "format": "date-time"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DependencyImpact"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "costmodel.AggregationResult": {
+            "type": "object",
+            "description": "AggregationResult represents the result of aggregating costs at a specific level.",
+            "properties": {
+                "identifier": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "resource_count": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total_cost": {
+                    "$ref": "#/definitions/costmodel.CostResult"
+                }
+            }
+        },
+        "costmodel.CostPolicy": {
+            "type": "object",
+            "description": "CostPolicy is the declarative cost exception of a workload (usually parsed from annotations). The zero value is the default behavior.",
+            "properties": {
+                "cost_center": {
+                    "type": "string",
+                    "description": "CostCenter overrides the namespace for chargeback."
+                },
+                "exclude_from_waste": {
+                    "type": "boolean",
+                    "description": "ExcludeFromWaste treats the whole request as used (e.g. DR standby): waste is zero, the workload grades Healthy and no decommission/downsize is recommended."
+                },
+                "grade_thresholds": {
+                    "$ref": "#/definitions/costmodel.GradeThresholds"
+                }
+            }
+        },
+        "costmodel.CostResult": {
+            "type": "object",
+            "description": "CostResult represents the calculated cost results for a single resource.",
+            "properties": {
+                "cpu_billable_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "CPU costs"
+                },
+                "cpu_efficiency_score": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_usage_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_billable_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Memory costs"
+                },
+                "mem_efficiency_score": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_usage_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "overall_efficiency_score": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "overall_grade": {
+                    "type": "string",
+                    "description": "Efficiency grade"
+                },
+                "total_billable_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Total costs"
+                },
+                "total_usage_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total_waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "costmodel.GradeThresholds": {
+            "type": "object",
+            "description": "GradeThresholds are the efficiency score boundaries (percent) between grades: score < Zombie is Zombie, < OverProvisioned is OverProvisioned, > Risk is Risk, otherwise Healthy.",
+            "properties": {
+                "over_provisioned": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "risk": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "zombie": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "costmodel.OffHoursProfile": {
+            "type": "object",
+            "description": "OffHoursProfile splits a workload's hours into business hours and off-hours. Utilizations are mean dominant utilization in %; idle costs are billable cost of idle hours.",
+            "properties": {
+                "business_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "business_hours": {
+                    "type": "integer"
+                },
+                "business_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "idle_business_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "idle_business_hours": {
+                    "type": "integer"
+                },
+                "idle_off_hours": {
+                    "type": "integer"
+                },
+                "idle_off_hours_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "idle_off_hours_cost_share": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "idle off-hours cost / total cost, %"
+                },
+                "off_hours": {
+                    "type": "integer"
+                },
+                "off_hours_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "off_hours_cost_share": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "off-hours cost / total cost, %"
+                },
+                "off_hours_utilization": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "costmodel.Recommendation": {
+            "type": "object",
+            "description": "Recommendation is one optimization proposal. Resource is \"cpu\", \"memory\" or \"workload\"; EstimatedMonthlySavings is negative for upsizing (extra cost). Safety is set on reductions that went through CheckSafety.",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "current_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "recommended_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "resource": {
+                    "type": "string"
+                },
+                "safety": {
+                    "$ref": "#/definitions/costmodel.RecommendationSafety"
+                }
+            }
+        },
+        "costmodel.RecommendationSafety": {
+            "type": "object",
+            "description": "RecommendationSafety records the safety checks of a reduction: the outcome, why, and the signals it was decided on.",
+            "properties": {
+                "cpu_throttling_rate": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "error_budget_remaining": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "slo_status": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "costmodel.SLOCapacityLevel": {
+            "type": "object",
+            "description": "SLOCapacityLevel is the capacity serving usage at one availability level and its monthly cost.",
+            "properties": {
+                "availability": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "%; 50 is the typical demand (P50)"
+                },
+                "cpu": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "cores"
+                },
+                "marginal_monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "MarginalMonthlyCost is the cost over the previous level (the cost of this nine)."
+                },
+                "mem": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "bytes"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "costmodel.SLOCost": {
+            "type": "object",
+            "description": "SLOCost is what the availability target of a service costs.",
+            "properties": {
+                "availability_target": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "baseline": {
+                    "$ref": "#/definitions/costmodel.SLOCapacityLevel"
+                },
+                "excess_monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "ExcessMonthlyCost is requested capacity beyond what the target needs (not explained by the SLO)."
+                },
+                "levels": {
+                    "type": "array",
+                    "description": "Levels are the whole nines (90%, 99%, ...) up to one past the target.",
+                    "items": {
+                        "$ref": "#/definitions/costmodel.SLOCapacityLevel"
+                    }
+                },
+                "next_nine_monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "NextNineMonthlyCost is the extra capacity for one more nine; RelaxNineMonthlySavings what dropping one nine releases (down to typical demand)."
+                },
+                "nines": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "relax_nine_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "requested_monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "RequestedMonthlyCost prices the current requests."
+                },
+                "slo_headroom_monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "SLOHeadroomMonthlyCost is the capacity kept above typical demand for the target."
+                },
+                "target": {
+                    "$ref": "#/definitions/costmodel.SLOCapacityLevel"
+                }
+            }
+        },
+        "costmodel.SharedResource": {
+            "type": "object",
+            "description": "SharedResource is a cluster-shared resource whose cost is allocated to namespaces.",
+            "properties": {
+                "allocation_key": {
+                    "type": "string"
+                },
+                "billing": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "description": "e.g. \"load_balancer\", \"storage\"; informational"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "fixed billing"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespaces": {
+                    "type": "array",
+                    "description": "Namespaces restricts the allocation to these namespaces; empty means all namespaces.",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unit_price": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "metered billing, per unit"
+                }
+            }
+        },
+        "costmodel.TargetAttainment": {
+            "type": "object",
+            "description": "TargetAttainment summarizes daily efficiency against a target. A day meets the target when its efficiency is at or above it; a day without data breaks a streak.",
+            "properties": {
+                "attainment_rate": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "average_efficiency": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "current_streak": {
+                    "type": "integer",
+                    "description": "consecutive days meeting the target, ending on the last day"
+                },
+                "days": {
+                    "type": "integer",
+                    "description": "days with data"
+                },
+                "days_at_target": {
+                    "type": "integer",
+                    "description": "days meeting the target"
+                },
+                "longest_streak": {
+                    "type": "integer"
+                },
+                "target": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.APIKey": {
+            "type": "object",
+            "description": "APIKey is an API key of a service account; the secret is never returned after creation.",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "daily_quota": {
+                    "type": "integer",
+                    "description": "requests per UTC day, 0 = unlimited"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string",
+                    "description": "first characters of the secret, to recognize the key"
+                },
+                "requests_today": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.APIKeyUsageDay": {
+            "type": "object",
+            "description": "APIKeyUsageDay is the usage of one key on one UTC day.",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "key_name": {
+                    "type": "string"
+                },
+                "rejected": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "over the daily quota"
+                },
+                "requests": {
+                    "type": "integer",
+                    "format": "int64"
+                }
+            }
+        },
+        "dto.APIKeyUsageResponse": {
+            "type": "object",
+            "description": "APIKeyUsageResponse is the response of GET /api/v1/admin/service-accounts/:id/usage.",
+            "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "start_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total_rejected": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "total_requests": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "usage": {
+                    "type": "array",
+                    "description": "days without requests are omitted",
+                    "items": {
+                        "$ref": "#/definitions/dto.APIKeyUsageDay"
+                    }
+                }
+            }
+        },
+        "dto.AccountingPeriod": {
+            "type": "object",
+            "description": "AccountingPeriod is a closed fiscal period: its daily costs, allocations and the reports built from them no longer change; recalculations are recorded as adjustments instead.",
+            "properties": {
+                "adjustment_count": {
+                    "type": "integer",
+                    "description": "adjustments recorded since the close"
+                },
+                "closed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "closed_by": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "exclusive"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.AccountingPeriodList": {
+            "type": "object",
+            "description": "AccountingPeriodList is the response of GET /api/v1/admin/periods: the closed periods, latest first.",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AccountingPeriod"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page."
+                },
+                "total_estimate": {
+                    "type": "integer",
+                    "description": "TotalEstimate is the number of results before paging; exact for the current in-memory lists, an estimate once a source only counts approximately."
+                }
+            }
+        },
+        "dto.AlertClauseResult": {
+            "type": "object",
+            "description": "AlertClauseResult is the value of one clause of a condition.",
+            "properties": {
+                "clause": {
+                    "type": "string"
+                },
+                "has_data": {
+                    "type": "boolean",
+                    "description": "false: no data in the window, the clause does not hold"
+                },
+                "matched": {
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.AlertMetric": {
+            "type": "object",
+            "description": "AlertMetric describes a metric of the condition DSL and the labels it can be filtered by.",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AlertRule": {
+            "type": "object",
+            "description": "AlertRule is a user-defined alert: a condition over cost, efficiency, budget and SLO metrics, evaluated every interval over the trailing window and routed to notification channels.",
+            "properties": {
+                "alert_type": {
+                    "type": "string"
+                },
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "condition": {
+                    "type": "string",
+                    "description": "Condition in canonical form, e.g. ` + "`" + `cost{namespace=\"payments\"} > 500 and waste_ratio >= 30` + "`" + `."
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "firing_since": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string",
+                    "description": "Go duration, e.g. \"1h\""
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_evaluated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string",
+                    "description": "State of the latest evaluation: ok, firing or error; empty before the first one."
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window": {
+                    "type": "string",
+                    "description": "Go duration, e.g. \"24h\""
+                }
+            }
+        },
+        "dto.AlertRuleEvaluation": {
+            "type": "object",
+            "description": "AlertRuleEvaluation is the result of evaluating an alert rule.",
+            "properties": {
+                "clauses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AlertClauseResult"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "description": "the metrics could not be evaluated"
+                },
+                "evaluated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "firing": {
+                    "type": "boolean"
+                },
+                "notified": {
+                    "type": "boolean",
+                    "description": "an alert was sent (the rule started firing)"
+                },
+                "notify_error": {
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "string"
+                },
+                "silenced": {
+                    "type": "boolean",
+                    "description": "the alert matched an active silence and was not sent"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.AlertRuleListResponse": {
+            "type": "object",
+            "description": "AlertRuleListResponse is the response of GET /api/v1/alerts/rules.",
+            "properties": {
+                "metrics": {
+                    "type": "array",
+                    "description": "Metrics are the metrics conditions may use.",
+                    "items": {
+                        "$ref": "#/definitions/dto.AlertMetric"
+                    }
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AlertRule"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.AlertRuleRequest": {
+            "type": "object",
+            "description": "AlertRuleRequest is the body of POST /api/v1/alerts/rules and PUT /api/v1/alerts/rules/:id. Clauses ` + "`" + `metric{label=\"value\", ...} op threshold` + "`" + ` (op: > >= < <= == !=) are joined by and / or, and binding tighter than or.",
+            "properties": {
+                "alert_type": {
+                    "type": "string",
+                    "description": "routing type, default alert_rule"
+                },
+                "channels": {
+                    "type": "array",
+                    "description": "Channels restrict delivery to these channels (slack, dingtalk, wecom, pagerduty, opsgenie) of the alert type's route; empty sends to all of them.",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "description": "default: the service account of the caller's key"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "description": "default true"
+                },
+                "interval": {
+                    "type": "string",
+                    "description": "default \"1h\", at least \"1m\""
+                },
+                "name": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string",
+                    "description": "info / warning / critical, default warning"
+                },
+                "window": {
+                    "type": "string",
+                    "description": "default \"24h\""
+                }
+            },
+            "required": [
+                "condition",
+                "name"
+            ]
+        },
+        "dto.AllocationPreviewNamespace": {
+            "type": "object",
+            "description": "AllocationPreviewNamespace is the shared cost of a namespace under both rule sets.",
+            "properties": {
+                "billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "candidate_shared_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "current_shared_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "delta": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "delta_percent": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "relative to current; absent when current is zero"
+                },
+                "namespace": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AllocationPreviewRequest": {
+            "type": "object",
+            "description": "AllocationPreviewRequest is the body of POST /api/v1/cost/allocation/preview: a candidate set of shared cost rules replacing the current ones, applied to start_date..end_date (UTC days, inclusive; default the 30 days before today).",
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/costmodel.SharedResource"
+                    }
+                },
+                "start_date": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.AllocationPreviewResource": {
+            "type": "object",
+            "description": "AllocationPreviewResource is the allocated cost of one shared resource under both rule sets; a resource missing from one set has zero cost there.",
+            "properties": {
+                "candidate_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "current_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AllocationPreviewResponse": {
+            "type": "object",
+            "description": "AllocationPreviewResponse compares the shared cost allocated to each namespace by the current and the candidate rules. Namespaces are sorted by absolute delta, descending.",
+            "properties": {
+                "candidate_shared_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "current_shared_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "end_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AllocationPreviewNamespace"
+                    }
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AllocationPreviewResource"
+                    }
+                },
+                "start_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.BusinessHoursState": {
+            "type": "object",
+            "description": "BusinessHoursState describes the weekday hours [start_hour, end_hour) counted as business hours.",
+            "properties": {
+                "end_hour": {
+                    "type": "integer"
+                },
+                "start_hour": {
+                    "type": "integer"
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CalculationRun": {
+            "type": "object",
+            "description": "CalculationRun is one execution of the cost calculation pipeline.",
+            "properties": {
+                "dropped_rows": {
+                    "type": "integer"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed_scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "finished_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "retry_of": {
+                    "type": "string"
+                },
+                "rows_written": {
+                    "type": "integer"
+                },
+                "scopes": {
+                    "type": "array",
+                    "description": "Scopes 本次执行范围（namespace），为空表示全量；FailedScopes 失败且可定向重跑的范围。",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "status": {
+                    "type": "string",
+                    "description": "running / succeeded / partial / failed"
+                },
+                "trigger": {
+                    "type": "string",
+                    "description": "schedule / manual / retry"
+                },
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "zeroed_values": {
+                    "type": "integer",
+                    "description": "ZeroedValues / DroppedRows 计算中 NaN/Inf 被置 0 的值与被丢弃的指标行数（见 non_finite_policy）。"
+                }
+            }
+        },
+        "dto.CalculationRunListResponse": {
+            "type": "object",
+            "description": "CalculationRunListResponse is the response of GET /api/v1/calculations/runs.",
+            "properties": {
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CalculationRun"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CapacityPool": {
+            "type": "object",
+            "description": "CapacityPool is a group of nodes sharing the node pool label.",
+            "properties": {
+                "allocatable_cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "allocatable_mem": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CapacityProjectionPoint": {
+            "type": "object",
+            "description": "CapacityProjectionPoint is the projected cluster requests of one day (baseline capacity).",
+            "properties": {
+                "cpu_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "mem_request": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "mem_utilization": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.CapacityProjectionResponse": {
+            "type": "object",
+            "description": "CapacityProjectionResponse is the response of GET /api/v1/capacity/projection. Requests are projected per namespace from their daily trend; utilization is requested/allocatable in %.",
+            "properties": {
+                "baseline": {
+                    "$ref": "#/definitions/dto.CapacityScenario"
+                },
+                "generated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "history_days": {
+                    "type": "integer"
+                },
+                "horizon_days": {
+                    "type": "integer"
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NamespaceGrowth"
+                    }
+                },
+                "pools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CapacityPool"
+                    }
+                },
+                "scenario": {
+                    "$ref": "#/definitions/dto.CapacityScenario"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CapacityProjectionPoint"
+                    }
+                },
+                "target_utilization": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.CapacityScenario": {
+            "type": "object",
+            "description": "CapacityScenario is the cluster capacity, cost and exhaustion dates for a node count. Exhaustion dates are nil when requests stay below allocatable*target within the horizon.",
+            "properties": {
+                "allocatable_cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "allocatable_mem": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "cpu_exhaustion_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "cpu_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_exhaustion_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "mem_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "monthly_cost_delta": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "node_delta": {
+                    "type": "integer"
+                },
+                "pool": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CapacitySimulationPool": {
+            "type": "object",
+            "description": "CapacitySimulationPool is the autoscaler simulation of one node pool.",
+            "properties": {
+                "baseline_node_count": {
+                    "type": "integer"
+                },
+                "monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "node_cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "node_mem": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "node_monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "node_reduction": {
+                    "type": "integer"
+                },
+                "oversized_pods": {
+                    "type": "integer"
+                },
+                "pods": {
+                    "type": "integer"
+                },
+                "simulated_node_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CapacitySimulationResponse": {
+            "type": "object",
+            "description": "CapacitySimulationResponse is the response of GET /api/v1/capacity/simulation. Running and pending pods are bin-packed onto each pool's average node (allocatable*target per node) with current and changed requests; savings are the billable cost of the nodes removed. Pools without pods keep their nodes.",
+            "properties": {
+                "baseline_node_count": {
+                    "type": "integer",
+                    "description": "BaselineNodeCount 为按当前请求装箱所需节点数，与 NodeCount 的差异是已可整合的部分； NodeReduction = BaselineNodeCount - SimulatedNodeCount，只计入请求变化带来的节点减少"
+                },
+                "cpu_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "generated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "mem_request": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "node_reduction": {
+                    "type": "integer"
+                },
+                "pools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CapacitySimulationPool"
+                    }
+                },
+                "request_change_percent": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "request_cost_delta": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "RequestCostDelta 为请求变化按单价折算的月成本（负数为减少），仅供与节点节省对比"
+                },
+                "simulated_cpu_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "simulated_mem_request": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "simulated_monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "simulated_node_count": {
+                    "type": "integer"
+                },
+                "target_utilization": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.CatalogFacetCount": {
+            "type": "object",
+            "description": "CatalogFacetCount is the number of matching workloads with one facet value.",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CatalogSearchResponse": {
+            "type": "object",
+            "description": "CatalogSearchResponse is the response of GET /api/v1/workloads/catalog. Workloads are sorted by monthly cost, descending; Total counts all matches before paging and Facets are computed over them (facets: namespace, team, grade, type, slo_status).",
+            "properties": {
+                "facets": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/dto.CatalogFacetCount"
+                        }
+                    }
+                },
+                "refreshed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "workloads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CatalogWorkload"
+                    }
+                }
+            }
+        },
+        "dto.CatalogWorkload": {
+            "type": "object",
+            "description": "CatalogWorkload is the latest known state of one workload.",
+            "properties": {
+                "efficiency_score": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "last_recommendation": {
+                    "$ref": "#/definitions/costmodel.Recommendation"
+                },
+                "last_seen": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "billable cost of the observed hours extrapolated to a month"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "slo_status": {
+                    "type": "string",
+                    "description": "absent when the workload has no SLO"
+                },
+                "team": {
+                    "type": "string",
+                    "description": "owner team; absent when no team claims the namespace"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ChaosDrill": {
+            "type": "object",
+            "description": "ChaosDrill is a started drill and what it injected so far, by data source.",
+            "properties": {
+                "ends_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "injected": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/dto.ChaosInjectCount"
+                    }
+                },
+                "scenario": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "started_by": {
+                    "type": "string"
+                },
+                "stopped_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.ChaosFault": {
+            "type": "object",
+            "description": "ChaosFault is the failure injected into the calls of one data source, with the knobs of the mock configs.",
+            "properties": {
+                "error_rate": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "latency_jitter_ms": {
+                    "type": "integer"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "tail_latency_ms": {
+                    "type": "integer"
+                },
+                "tail_latency_rate": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.ChaosInjectCount": {
+            "type": "object",
+            "description": "ChaosInjectCount is the calls of a data source a drill delayed or failed, and the failures among them.",
+            "properties": {
+                "calls": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "errors": {
+                    "type": "integer",
+                    "format": "int64"
+                }
+            }
+        },
+        "dto.ChaosResponse": {
+            "type": "object",
+            "description": "ChaosResponse is the response of the /api/v1/admin/chaos endpoints: the configured scenarios and the current (or last) resilience drill.",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "drill": {
+                    "$ref": "#/definitions/dto.ChaosDrill"
+                },
+                "max_duration_seconds": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "scenarios": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChaosScenario"
+                    }
+                }
+            }
+        },
+        "dto.ChaosScenario": {
+            "type": "object",
+            "description": "ChaosScenario is a configured scenario: the fault injected into each data source (prometheus / kubernetes / database).",
+            "properties": {
+                "faults": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/dto.ChaosFault"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CloseAccountingPeriodRequest": {
+            "type": "object",
+            "description": "CloseAccountingPeriodRequest is the body of POST /api/v1/admin/periods/close.",
+            "properties": {
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CodeVersion": {
+            "type": "object",
+            "description": "CodeVersion is the build of the server that aggregated the figures.",
+            "properties": {
+                "build_time": {
+                    "type": "string"
+                },
+                "git_commit": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ConfigChange": {
+            "type": "object",
+            "description": "ConfigChange is a recorded config change.",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "change_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "new_value": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "old_value": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ConfigChangeRequest": {
+            "type": "object",
+            "description": "ConfigChangeRequest is the body of POST /api/v1/timeline/config-changes, sent by deploy pipelines once a change is applied.",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "change_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "description": "optional; re-sending an ID replaces the record"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "new_value": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "default now"
+                },
+                "old_value": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "change_type",
+                "kind",
+                "name"
+            ]
+        },
+        "dto.ConfirmSnapshotRequest": {
+            "type": "object",
+            "description": "ConfirmSnapshotRequest is the body of POST /api/v1/snapshots/{id}/confirm.",
+            "properties": {
+                "confirmed_by": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "confirmed_by"
+            ]
+        },
+        "dto.CostAdjustment": {
+            "type": "object",
+            "description": "CostAdjustment is a correction of a closed period: the difference between a recalculated value and the value stored when the period was closed, or a manual correction with its reason and approver. Pending manual corrections are listed but not included in reports.",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "approved_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "approver": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "record": {
+                    "type": "string",
+                    "description": "daily_namespace_cost / hourly_workload_stat"
+                },
+                "source": {
+                    "type": "string",
+                    "description": "recalculation / manual"
+                },
+                "status": {
+                    "type": "string",
+                    "description": "pending / approved"
+                },
+                "workload": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CostAdjustmentList": {
+            "type": "object",
+            "description": "CostAdjustmentList is the response of GET /api/v1/admin/adjustments.",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CostAdjustment"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page."
+                },
+                "total_estimate": {
+                    "type": "integer",
+                    "description": "TotalEstimate is the number of results before paging; exact for the current in-memory lists, an estimate once a source only counts approximately."
+                }
+            }
+        },
+        "dto.CostBreakdown": {
+            "type": "object",
+            "description": "CostBreakdown provides detailed cost breakdown.",
+            "properties": {
+                "billable": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "efficiency": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "memory": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "network": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "storage": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "usage": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "waste": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.CostImportResponse": {
+            "type": "object",
+            "description": "CostImportResponse is the response of POST /api/v1/admin/import/allocations: what an import of Kubecost / OpenCost allocation data wrote (or, with dry_run, would write).",
+            "properties": {
+                "allocations": {
+                    "type": "integer",
+                    "description": "Allocations parsed; Unallocated of them are idle / unallocated aggregates, which are skipped."
+                },
+                "daily_rows": {
+                    "type": "integer",
+                    "description": "Rows written; rows of earlier imports are replaced."
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "format": {
+                    "type": "string",
+                    "description": "json / csv"
+                },
+                "hourly_rows": {
+                    "type": "integer"
+                },
+                "imported_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "ImportedCost is the CPU and memory cost imported and SharedCost the shared cost allocated to the namespaces; OtherCost is the GPU, volume, network and load balancer cost of the export, which is outside the Lighthouse cost model and not imported."
+                },
+                "kept_rows": {
+                    "type": "integer",
+                    "description": "KeptRows are rows Lighthouse calculated itself, which the import does not overwrite."
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "other_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "shared_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "source": {
+                    "type": "string",
+                    "description": "kubecost / opencost, stored as the source of each row"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "unallocated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CostSeriesResponse": {
+            "type": "object",
+            "description": "CostSeriesResponse is the response of GET /api/v1/cost/series: billable (Cost), usage and waste cost over time, oldest first, at the resolution the query planner chose for the range (or the requested one), so clients know the granularity they received.",
+            "properties": {
+                "end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "namespace": {
+                    "type": "string",
+                    "description": "为空表示全部 namespace"
+                },
+                "planned": {
+                    "type": "boolean"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GranularCostDataPoint"
+                    }
+                },
+                "resolution": {
+                    "type": "string",
+                    "description": "Resolution 数据粒度（hourly / daily / monthly）；Planned 为 true 表示由查询计划按时间范围选择"
+                },
+                "source": {
+                    "type": "string",
+                    "description": "Source 读取的表；TimeZone 天/月边界所用的核算时区"
+                },
+                "start": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "step_seconds": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "名义步长，月按 30 天"
+                },
+                "time_zone": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CostSnapshot": {
+            "type": "object",
+            "description": "CostSnapshot is a cost snapshot read with field selection; only the groups listed in Fields were read, absent groups are omitted rather than zero.",
+            "properties": {
+                "aggregated_results": {
+                    "type": "object",
+                    "description": "AggregatedResults 按聚合层级（pod / workload / namespace / node / cluster / node_pool）",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/costmodel.AggregationResult"
+                        }
+                    }
+                },
+                "calculation_id": {
+                    "type": "string"
+                },
+                "counts": {
+                    "$ref": "#/definitions/dto.GradeCounts"
+                },
+                "fields": {
+                    "type": "array",
+                    "description": "Fields 本次读取的字段组：counts / summary / aggregated_results / resource_results",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "resource_results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/costmodel.CostResult"
+                    }
+                },
+                "summary": {
+                    "$ref": "#/definitions/dto.SnapshotSummary"
+                },
+                "time_range_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "time_range_start": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.CostSnapshotListResponse": {
+            "type": "object",
+            "description": "CostSnapshotListResponse is the response of GET /api/v1/snapshots.",
+            "properties": {
+                "snapshots": {
+                    "type": "array",
+                    "description": "newest first",
+                    "items": {
+                        "$ref": "#/definitions/dto.CostSnapshot"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreateAPIKeyRequest": {
+            "type": "object",
+            "description": "CreateAPIKeyRequest is the body of POST /api/v1/admin/service-accounts/:id/keys. Scopes are \"<group>:<read|write|*>\" where group is the first path segment after /api/v1 (cost, roi, admin, ...) or \"*\"; read covers GET requests, write the others.",
+            "properties": {
+                "daily_quota": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            },
+            "required": [
+                "scopes"
+            ]
+        },
+        "dto.CreateAdjustmentRequest": {
+            "type": "object",
+            "description": "CreateAdjustmentRequest is the body of POST /api/v1/admin/adjustments: a manual correction of the cost of a namespace (optionally one workload of it) on a day of a closed period. Amount is added to the cost of the day (negative: a credit) once someone other than the requester approves it (POST /api/v1/admin/adjustments/:id/approve).",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "date": {
+                    "type": "string",
+                    "description": "YYYY-MM-DD"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "workload": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "date",
+                "namespace",
+                "reason"
+            ]
+        },
+        "dto.CreateExportJobRequest": {
+            "type": "object",
+            "description": "CreateExportJobRequest is the body of POST /api/v1/exports. The window, anonymize and scale follow GET /api/v1/admin/dataset/export.",
+            "properties": {
+                "anonymize": {
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "default the current hour"
+                },
+                "format": {
+                    "type": "string",
+                    "description": "csv / pdf, or a format registered by the deployment (e.g. parquet)"
+                },
+                "kind": {
+                    "type": "string",
+                    "description": "namespace_costs / workload_stats"
+                },
+                "scale": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "default end_time - 7 days"
+                }
+            },
+            "required": [
+                "format",
+                "kind"
+            ]
+        },
+        "dto.CreateServiceAccountRequest": {
+            "type": "object",
+            "description": "CreateServiceAccountRequest is the body of POST /api/v1/admin/service-accounts.",
+            "properties": {
+                "created_by": {
+                    "type": "string",
+                    "description": "default: the service account of the caller's key"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "name"
+            ]
+        },
+        "dto.CreateSilenceRequest": {
+            "type": "object",
+            "description": "CreateSilenceRequest is the body of POST /api/v1/alerts/silences. The end is EndsAt or StartsAt plus Duration.",
+            "properties": {
+                "created_by": {
+                    "type": "string",
+                    "description": "default: the service account of the caller's key"
+                },
+                "duration": {
+                    "type": "string",
+                    "description": "Go duration, e.g. \"2h\""
+                },
+                "ends_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "matchers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "default now"
+                }
+            },
+            "required": [
+                "matchers",
+                "reason"
+            ]
+        },
+        "dto.CreatedAPIKey": {
+            "type": "object",
+            "description": "CreatedAPIKey is the response of key creation, the only time the secret is returned.",
+            "properties": {
+                "key": {
+                    "$ref": "#/definitions/dto.APIKey"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.Dataset": {
+            "type": "object",
+            "description": "Dataset is the stored cost data of a window, for vendors and demos. When Anonymized, namespace, workload, pod, node and cost center names are consistent pseudonyms (the same name has the same pseudonym everywhere in the dataset). Every cost is multiplied by Scale (1 unless requested); resource quantities, timestamps and the ratios between costs are kept, so the cost shapes are those of the source.",
+            "properties": {
+                "anonymized": {
+                    "type": "boolean"
+                },
+                "daily_namespace_costs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DatasetNamespaceDay"
+                    }
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "exported_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "hourly_workload_stats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DatasetWorkloadHour"
+                    }
+                },
+                "scale": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.DatasetNamespaceDay": {
+            "type": "object",
+            "description": "DatasetNamespaceDay is the daily cost of one namespace.",
+            "properties": {
+                "billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "efficiency_score": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "pod_count": {
+                    "type": "integer"
+                },
+                "shared_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "usage_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "workload_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.DatasetWorkloadHour": {
+            "type": "object",
+            "description": "DatasetWorkloadHour is the hourly stat of one workload (pod).",
+            "properties": {
+                "cost_center": {
+                    "type": "string"
+                },
+                "cpu_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_usage_p95": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_request": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "mem_usage_p95": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "pod_name": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total_usage_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total_waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "workload_name": {
+                    "type": "string"
+                },
+                "workload_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DependencyEdge": {
+            "type": "object",
+            "description": "DependencyEdge is an edge of the service dependency graph.",
+            "properties": {
+                "callee": {
+                    "type": "string"
+                },
+                "callee_namespace": {
+                    "type": "string"
+                },
+                "caller": {
+                    "type": "string"
+                },
+                "caller_namespace": {
+                    "type": "string"
+                },
+                "calls_per_minute": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "last_seen": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DependencyEdgeInput": {
+            "type": "object",
+            "description": "DependencyEdgeInput is one reported dependency: caller calls callee. Namespaces default to each other, so edges within a namespace need only one of them.",
+            "properties": {
+                "callee": {
+                    "type": "string"
+                },
+                "callee_namespace": {
+                    "type": "string"
+                },
+                "caller": {
+                    "type": "string"
+                },
+                "caller_namespace": {
+                    "type": "string"
+                },
+                "calls_per_minute": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.DependencyEdgeList": {
+            "type": "object",
+            "description": "DependencyEdgeList is the response of GET /api/v1/dependencies: the live edges of the graph.",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DependencyEdge"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page."
+                },
+                "total_estimate": {
+                    "type": "integer",
+                    "description": "TotalEstimate is the number of results before paging; exact for the current in-memory lists, an estimate once a source only counts approximately."
+                }
+            }
+        },
+        "dto.DependencyImpact": {
+            "type": "object",
+            "description": "DependencyImpact is the blast radius of a degraded workload: the services that call it directly or transitively, and what they cost. Monthly costs are extrapolated from the billable cost of the workloads named like the services over the window.",
+            "properties": {
+                "downstream": {
+                    "type": "array",
+                    "description": "Downstream lists the services the workload calls directly (\"namespace/service\").",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "unmatched": {
+                    "type": "array",
+                    "description": "Unmatched lists upstream services without workload stats in the window (namespace/service).",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "upstream": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UpstreamService"
+                    }
+                },
+                "upstream_monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "UpstreamMonthlyCost sums the monthly cost of Upstream: the spend depending on the workload."
+                }
+            }
+        },
+        "dto.DependencyIngestRequest": {
+            "type": "object",
+            "description": "DependencyIngestRequest is the body of POST /api/v1/dependencies/edges. The tracing pipeline reports the calls it observed (source tracing); a static dependency map is sent whole (source static) and replaces the previous one.",
+            "properties": {
+                "edges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DependencyEdgeInput"
+                    }
+                },
+                "observed_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "default now"
+                },
+                "source": {
+                    "type": "string",
+                    "description": "tracing / static"
+                }
+            },
+            "required": [
+                "source"
+            ]
+        },
+        "dto.DependencyIngestResponse": {
+            "type": "object",
+            "description": "DependencyIngestResponse is the result of an ingestion: the edges saved and the edges of the source removed (the previous static map, or tracing edges not seen within the TTL).",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DisplayUnits": {
+            "type": "object",
+            "description": "DisplayUnits are the units quantities are displayed in.",
+            "properties": {
+                "cpu": {
+                    "type": "string",
+                    "description": "cores / millicores"
+                },
+                "memory": {
+                    "type": "string",
+                    "description": "GiB / MiB / GB / MB"
+                }
+            }
+        },
+        "dto.DomainBreakdownItem": {
+            "type": "object",
+            "description": "DomainBreakdownItem represents a domain in the cost breakdown pie chart.",
+            "properties": {
+                "cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "efficiency": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "optimizable_space": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "terminated": {
+                    "type": "boolean",
+                    "description": "同 NamespaceCostSummary.Terminated"
+                }
+            }
+        },
+        "dto.EfficiencyHeatmapResponse": {
+            "type": "object",
+            "description": "EfficiencyHeatmapResponse is the response of GET /api/v1/cost/efficiency/heatmap. Rows are sorted by billable cost in the window, descending.",
+            "properties": {
+                "bucket_days": {
+                    "type": "integer"
+                },
+                "buckets": {
+                    "type": "array",
+                    "description": "start date of each bucket",
+                    "items": {
+                        "type": "string",
+                        "format": "date-time"
+                    }
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EfficiencyHeatmapRow"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.EfficiencyHeatmapRow": {
+            "type": "object",
+            "description": "EfficiencyHeatmapRow is the efficiency of one namespace in each bucket of the heatmap.",
+            "properties": {
+                "cells": {
+                    "type": "array",
+                    "description": "one per bucket; null: no data",
+                    "items": {
+                        "type": "number",
+                        "format": "double"
+                    }
+                },
+                "efficiency": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "over the whole window"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "efficiency target, when set"
+                }
+            }
+        },
+        "dto.EfficiencyTarget": {
+            "type": "object",
+            "description": "EfficiencyTarget is the efficiency target of a namespace.",
+            "properties": {
+                "namespace": {
+                    "type": "string"
+                },
+                "set_by": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.EfficiencyTargetDay": {
+            "type": "object",
+            "description": "EfficiencyTargetDay is the actual efficiency of one day against the target.",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "efficiency": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "met": {
+                    "type": "boolean"
+                },
+                "target": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.EfficiencyTargetListResponse": {
+            "type": "object",
+            "description": "EfficiencyTargetListResponse is the response of GET /api/v1/cost/targets.",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EfficiencyTarget"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page."
+                },
+                "total_estimate": {
+                    "type": "integer",
+                    "description": "TotalEstimate is the number of results before paging; exact for the current in-memory lists, an estimate once a source only counts approximately."
+                }
+            }
+        },
+        "dto.EfficiencyTargetTrackingResponse": {
+            "type": "object",
+            "description": "EfficiencyTargetTrackingResponse is the response of GET /api/v1/cost/targets/{namespace}/tracking.",
+            "properties": {
+                "attainment": {
+                    "$ref": "#/definitions/costmodel.TargetAttainment"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EfficiencyTargetDay"
+                    }
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.ErrorResponse": {
+            "type": "object",
+            "description": "ErrorResponse represents a standard error response.",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ExportJob": {
+            "type": "object",
+            "description": "ExportJob is an async export. The file of a succeeded job is downloadable from DownloadURL until ExpiresAt, after which the job is expired and the file deleted.",
+            "properties": {
+                "anonymized": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "integer",
+                    "description": "percent"
+                },
+                "rows_written": {
+                    "type": "integer"
+                },
+                "size_bytes": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "status": {
+                    "type": "string",
+                    "description": "queued / running / succeeded / failed / expired"
+                },
+                "total_rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ExportJobListResponse": {
+            "type": "object",
+            "description": "ExportJobListResponse is the response of GET /api/v1/exports.",
+            "properties": {
+                "formats": {
+                    "type": "array",
+                    "description": "formats accepted by POST /api/v1/exports",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ExportJob"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ExportRecommendationsRequest": {
+            "type": "object",
+            "description": "ExportRecommendationsRequest is the body of POST /api/v1/roi/recommendations/export: the issued recommendations to export as Kubernetes patch manifests.",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "description": "kustomize (default: overlay with kustomization.yaml) / kubectl (patch files and apply.sh)"
+                },
+                "recommendation_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "workload_kind": {
+                    "type": "string",
+                    "description": "kind of the patched workloads: Deployment (default) / StatefulSet"
+                }
+            },
+            "required": [
+                "recommendation_ids"
+            ]
+        },
+        "dto.ExportedSnapshot": {
+            "type": "object",
+            "description": "ExportedSnapshot is one snapshot of an export; Snapshot is the stored snapshot JSON whose content hash (postgres.SnapshotContentHash) must equal ContentHash.",
+            "properties": {
+                "content_hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "snapshot": {}
+            }
+        },
+        "dto.FavoriteWorkload": {
+            "type": "object",
+            "description": "FavoriteWorkload is a workload pinned by the user.",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "name",
+                "namespace"
+            ]
+        },
+        "dto.FiscalReportNamespace": {
+            "type": "object",
+            "description": "FiscalReportNamespace is the cost of one namespace in a fiscal report.",
+            "properties": {
+                "adjustments": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "previous_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "shared_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.FiscalReportResponse": {
+            "type": "object",
+            "description": "FiscalReportResponse is the response of GET /api/v1/cost/fiscal-report: the cost of a fiscal period, quarter or year to date (through AsOf) and of the whole previous one. Dates are day labels of the accounting time zone; EndDate is exclusive.",
+            "properties": {
+                "adjusted_total": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "adjustment_items": {
+                    "type": "array",
+                    "description": "by date",
+                    "items": {
+                        "$ref": "#/definitions/dto.CostAdjustment"
+                    }
+                },
+                "adjustments": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Adjustments sums the corrections of closed periods dated within the span to date; the costs above are the values stored at close. AdjustedTotal is BillableCost + SharedCost + Adjustments."
+                },
+                "as_of": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "days_elapsed": {
+                    "type": "integer"
+                },
+                "end_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "label": {
+                    "type": "string",
+                    "description": "e.g. FY2026 Q2"
+                },
+                "namespaces": {
+                    "type": "array",
+                    "description": "by billable cost, descending",
+                    "items": {
+                        "$ref": "#/definitions/dto.FiscalReportNamespace"
+                    }
+                },
+                "previous_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "previous_label": {
+                    "type": "string"
+                },
+                "projected": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Projected extrapolates the average daily billable cost to date to the whole span"
+                },
+                "shared_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "start_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "unit": {
+                    "type": "string",
+                    "description": "period / quarter / year"
+                },
+                "usage_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.GlobalCostResponse": {
+            "type": "object",
+            "description": "GlobalCostResponse represents the response for global cost overview.",
+            "properties": {
+                "domain_breakdown": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DomainBreakdownItem"
+                    }
+                },
+                "global_efficiency": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NamespaceCostSummary"
+                    }
+                },
+                "quarantined_rows": {
+                    "type": "integer",
+                    "description": "input rows left out by validation"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total_optimizable": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.GradeChangeEvent": {
+            "type": "object",
+            "description": "GradeChangeEvent is one efficiency grade transition of a workload. FromGrade is empty for the first grade assigned to the workload.",
+            "properties": {
+                "efficiency_score": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "from_grade": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "to_grade": {
+                    "type": "string"
+                },
+                "workload_name": {
+                    "type": "string"
+                },
+                "workload_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GradeCounts": {
+            "type": "object",
+            "description": "GradeCounts is the number of resources per efficiency grade.",
+            "properties": {
+                "healthy": {
+                    "type": "integer"
+                },
+                "over_provisioned": {
+                    "type": "integer"
+                },
+                "risk": {
+                    "type": "integer"
+                },
+                "zombie": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.GradeHistoryResponse": {
+            "type": "object",
+            "description": "GradeHistoryResponse is the response of GET /api/v1/cost/grades/history.",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GradeChangeEvent"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.GradeView": {
+            "type": "object",
+            "description": "GradeView is the view of snapshot grade counts served by GET /api/v1/snapshots/grades.",
+            "properties": {
+                "set_by": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "zero while the configured default applies"
+                },
+                "view": {
+                    "type": "string",
+                    "description": "original / regraded"
+                }
+            }
+        },
+        "dto.GrafanaAnnotation": {
+            "type": "object",
+            "description": "GrafanaAnnotation is one annotation event; Time is unix ms.",
+            "properties": {
+                "annotation": {
+                    "$ref": "#/definitions/dto.GrafanaAnnotationQuery"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "timeEnd": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GrafanaAnnotationQuery": {
+            "type": "object",
+            "description": "GrafanaAnnotationQuery is the annotation definition configured in Grafana.",
+            "properties": {
+                "datasource": {
+                    "type": "string"
+                },
+                "enable": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GrafanaAnnotationRequest": {
+            "type": "object",
+            "description": "GrafanaAnnotationRequest is the body of POST /annotations.",
+            "properties": {
+                "annotation": {
+                    "$ref": "#/definitions/dto.GrafanaAnnotationQuery"
+                },
+                "range": {
+                    "$ref": "#/definitions/dto.GrafanaRange"
+                }
+            }
+        },
+        "dto.GrafanaQueryRequest": {
+            "type": "object",
+            "description": "GrafanaQueryRequest is the body of POST /query.",
+            "properties": {
+                "intervalMs": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "maxDataPoints": {
+                    "type": "integer"
+                },
+                "range": {
+                    "$ref": "#/definitions/dto.GrafanaRange"
+                },
+                "targets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GrafanaQueryTarget"
+                    }
+                }
+            }
+        },
+        "dto.GrafanaQueryTarget": {
+            "type": "object",
+            "description": "GrafanaQueryTarget is one query of a panel.",
+            "properties": {
+                "refId": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "description": "\"timeserie\" (default) or \"table\""
+                }
+            }
+        },
+        "dto.GrafanaRange": {
+            "type": "object",
+            "description": "GrafanaRange is the dashboard time range sent by Grafana.",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "to": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.GrafanaSearchRequest": {
+            "type": "object",
+            "description": "GrafanaSearchRequest is the body of POST /search.",
+            "properties": {
+                "target": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GranularCostDataPoint": {
+            "type": "object",
+            "description": "GranularCostDataPoint represents a time-series data point for cost.",
+            "properties": {
+                "cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "usage": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "waste": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.LineageSnapshot": {
+            "type": "object",
+            "description": "LineageSnapshot is the cost snapshot a lineage record refers to.",
+            "properties": {
+                "calculation_id": {
+                    "type": "string"
+                },
+                "content_hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.NamespaceCostResponse": {
+            "type": "object",
+            "description": "NamespaceCostResponse represents the response for namespace cost details.",
+            "properties": {
+                "cost": {
+                    "$ref": "#/definitions/dto.CostBreakdown"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NodeCostSummary"
+                    }
+                },
+                "terminated": {
+                    "type": "boolean",
+                    "description": "已删除 namespace 的历史成本仍可查询，Terminated 标注其状态"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "workload_total": {
+                    "type": "integer",
+                    "description": "分页前的工作负载数"
+                },
+                "workloads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WorkloadCost"
+                    }
+                }
+            }
+        },
+        "dto.NamespaceCostSummary": {
+            "type": "object",
+            "description": "NamespaceCostSummary represents a summary of cost for a namespace.",
+            "properties": {
+                "cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "efficiency": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "usage / billable, %"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/dto.ResourceMetadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "pod_count": {
+                    "type": "integer"
+                },
+                "terminated": {
+                    "type": "boolean",
+                    "description": "Terminated 该 namespace 已从集群删除（namespace_lifecycle.deleted_at），成本仅来自历史数据"
+                },
+                "waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.NamespaceCostSummaryList": {
+            "type": "object",
+            "description": "NamespaceCostSummaryList is the response of GET /api/v1/cost/namespaces.",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NamespaceCostSummary"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page."
+                },
+                "total_estimate": {
+                    "type": "integer",
+                    "description": "TotalEstimate is the number of results before paging; exact for the current in-memory lists, an estimate once a source only counts approximately."
+                }
+            }
+        },
+        "dto.NamespaceGrowth": {
+            "type": "object",
+            "description": "NamespaceGrowth is the current requests of a namespace and their fitted daily growth.",
+            "properties": {
+                "cpu_growth_per_day": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_growth_per_day": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_request": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "namespace": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.NamespaceOnboarding": {
+            "type": "object",
+            "description": "NamespaceOnboarding is the onboarding of a namespace: the settings it was onboarded with and the steps of its latest onboarding.",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "efficiency_target": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "monthly_budget": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "onboarded_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "onboarded_by": {
+                    "type": "string"
+                },
+                "owners": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "report_recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "slo_templates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OnboardingStep"
+                    }
+                },
+                "team": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.NamespaceOnboardingList": {
+            "type": "object",
+            "description": "NamespaceOnboardingList is the response of GET /api/v1/admin/onboarding, sorted by namespace.",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NamespaceOnboarding"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page."
+                },
+                "total_estimate": {
+                    "type": "integer",
+                    "description": "TotalEstimate is the number of results before paging; exact for the current in-memory lists, an estimate once a source only counts approximately."
+                }
+            }
+        },
+        "dto.NodeAnalysisResponse": {
+            "type": "object",
+            "description": "NodeAnalysisResponse is the response of GET /api/v1/nodes/analysis. Costs are hourly; EstimatedMonthlySavings sums the consolidation candidates.",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NodeConsolidationCandidate"
+                    }
+                },
+                "cluster": {
+                    "$ref": "#/definitions/dto.NodeCostItem"
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NodeCostItem"
+                    }
+                },
+                "target_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "Timestamp 计算所用的小时（最新一小时的工作负载统计）；无统计数据时为零值"
+                }
+            }
+        },
+        "dto.NodeConsolidationBlock": {
+            "type": "object",
+            "description": "NodeConsolidationBlock is a consolidation candidate that cannot be drained now.",
+            "properties": {
+                "node": {
+                    "type": "string"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.NodeConsolidationCandidate": {
+            "type": "object",
+            "description": "NodeConsolidationCandidate is a node whose requests fit on the remaining nodes, in drain order.",
+            "properties": {
+                "bin_packing_efficiency": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "hourly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pod_count": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "requested_cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "requested_mem": {
+                    "type": "integer",
+                    "format": "int64"
+                }
+            }
+        },
+        "dto.NodeConsolidationPlan": {
+            "type": "object",
+            "description": "NodeConsolidationPlan is the response of GET /api/v1/nodes/consolidation-plan: the consolidation candidates as a reviewable drain order, each step with its savings, its risk and the commands to run. Nodes whose pods PodDisruptionBudgets would not let go are left out and listed in Blocked.",
+            "properties": {
+                "blocked": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NodeConsolidationBlock"
+                    }
+                },
+                "disruption_budgets_checked": {
+                    "type": "boolean",
+                    "description": "DisruptionBudgetsChecked is false when the cluster's PodDisruptionBudgets could not be consulted."
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NodeDrainStep"
+                    }
+                },
+                "target_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.NodeCostItem": {
+            "type": "object",
+            "description": "NodeCostItem is the allocatable vs requested vs used picture of a node (or the whole cluster). BinPackingEfficiency is the dominant request ratio max(cpu, memory) in %.",
+            "properties": {
+                "allocatable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "allocatable_cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "allocatable_mem": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "bin_packing_efficiency": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_request_ratio": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_request_ratio": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pod_count": {
+                    "type": "integer"
+                },
+                "requested_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "requested_cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "requested_mem": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "unallocated_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "used_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "used_cpu": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "sum of workload P95"
+                },
+                "used_mem": {
+                    "type": "integer",
+                    "format": "int64"
+                }
+            }
+        },
+        "dto.NodeCostSummary": {
+            "type": "object",
+            "description": "NodeCostSummary represents cost summary for a node.",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "pod_count": {
+                    "type": "integer"
+                },
+                "total_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "utilization_cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "utilization_mem": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.NodeDrainBudget": {
+            "type": "object",
+            "description": "NodeDrainBudget is a PodDisruptionBudget covering pods of a drained node.",
+            "properties": {
+                "disruptions_allowed": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "pods_on_node": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.NodeDrainStep": {
+            "type": "object",
+            "description": "NodeDrainStep is one node of the drain order.",
+            "properties": {
+                "commands": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cumulative_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "disruption_budgets": {
+                    "type": "array",
+                    "description": "DisruptionBudgets are the budgets covering pods of the node.",
+                    "items": {
+                        "$ref": "#/definitions/dto.NodeDrainBudget"
+                    }
+                },
+                "hourly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "node": {
+                    "type": "string"
+                },
+                "pod_count": {
+                    "type": "integer"
+                },
+                "remaining_cpu_request_ratio": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Request ratios (%) of the nodes kept after the step, carrying the requests of the drained nodes."
+                },
+                "remaining_mem_request_ratio": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "risk": {
+                    "type": "string",
+                    "description": "low / medium / high"
+                },
+                "risk_reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "step": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.NodePoolAnalysisResponse": {
+            "type": "object",
+            "description": "NodePoolAnalysisResponse is the response of GET /api/v1/nodes/pools: capacity and cost per node pool, the granularity of capacity decisions. Pools come from the current nodes; workload costs recorded without a pool are reported under \"unassigned\".",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "pools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NodePoolCostItem"
+                    }
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.NodePoolCostItem": {
+            "type": "object",
+            "description": "NodePoolCostItem is the capacity and the workload costs of one node pool. Allocatable figures are current; costs are summed over the window.",
+            "properties": {
+                "allocatable_cpu": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "allocatable_mem": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cost_percentage": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "efficiency_score": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "hourly_allocatable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "usage_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "workload_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.NotificationRoute": {
+            "type": "object",
+            "description": "NotificationRoute sends the alerts whose labels match all matchers to its channels only. Alerts are labelled with alert_type, severity and, where known, namespace, team and rule; a matcher value \"*\" only requires the label. The matching route of lowest priority wins; alerts naming their own channels (alert rules with channels) are not rerouted.",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "matchers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.NotificationRouteListResponse": {
+            "type": "object",
+            "description": "NotificationRouteListResponse is the response of GET /api/v1/alerts/routes, in matching order.",
+            "properties": {
+                "routes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NotificationRoute"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.NotificationRouteRequest": {
+            "type": "object",
+            "description": "NotificationRouteRequest is the body of POST /api/v1/alerts/routes and PUT /api/v1/alerts/routes/:id.",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_by": {
+                    "type": "string",
+                    "description": "default: the service account of the caller's key"
+                },
+                "matchers": {
+                    "type": "object",
+                    "description": "empty matches every alert",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                }
+            },
+            "required": [
+                "channels",
+                "name"
+            ]
+        },
+        "dto.OffHoursAnalysisResponse": {
+            "type": "object",
+            "description": "OffHoursAnalysisResponse is the response of GET /api/v1/analysis/offhours. Workloads are sorted by idle off-hours cost, descending.",
+            "properties": {
+                "business_hours": {
+                    "$ref": "#/definitions/dto.BusinessHoursState"
+                },
+                "idle_utilization": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "dominant usage/request ratio at or below which an hour is idle"
+                },
+                "summary": {
+                    "$ref": "#/definitions/dto.OffHoursSummary"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "workloads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OffHoursWorkload"
+                    }
+                }
+            }
+        },
+        "dto.OffHoursSummary": {
+            "type": "object",
+            "description": "OffHoursSummary sums the workloads of the analysis (before limit).",
+            "properties": {
+                "business_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "candidates": {
+                    "type": "integer"
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "idle_off_hours_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "idle_off_hours_cost_share": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "idle off-hours cost / total cost, %"
+                },
+                "off_hours_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.OffHoursWorkload": {
+            "type": "object",
+            "description": "OffHoursWorkload is the business hours vs off-hours profile of one workload.",
+            "properties": {
+                "namespace": {
+                    "type": "string"
+                },
+                "profile": {
+                    "$ref": "#/definitions/costmodel.OffHoursProfile"
+                },
+                "recommendation": {
+                    "$ref": "#/definitions/costmodel.Recommendation"
+                },
+                "workload_name": {
+                    "type": "string"
+                },
+                "workload_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OnboardNamespaceRequest": {
+            "type": "object",
+            "description": "OnboardNamespaceRequest is the body of POST /api/v1/admin/onboarding. Omitted settings take the defaults of business.onboarding; onboarding a namespace again replaces its settings.",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "description": "Channels restrict the budget and SLO alerts to these channels; empty sends to all of them.",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "efficiency_target": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "EfficiencyTarget is the efficiency score (0-100] tracked for the namespace."
+                },
+                "monthly_budget": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "MonthlyBudget is the budget of the namespace's rolling 30-day cost; 0 creates no budget alert."
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "owners": {
+                    "type": "array",
+                    "description": "e-mail addresses",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "report_recipients": {
+                    "type": "array",
+                    "description": "ReportRecipients subscribe to the weekly report of the namespace; omitted: the owners.",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "slo_templates": {
+                    "type": "array",
+                    "description": "SLOTemplates are the SLO alert templates to enable (availability, error_budget); omitted: the defaults, empty: none.",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "team": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "namespace",
+                "team"
+            ]
+        },
+        "dto.OnboardingStep": {
+            "type": "object",
+            "description": "OnboardingStep is the outcome of one onboarding step.",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "description": "e.g. the ID of the alert rule"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "step": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OptimizationRecordListResponse": {
+            "type": "object",
+            "description": "OptimizationRecordListResponse is the response of GET /api/v1/roi/optimizations.",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/roi.OptimizationTrackingRecord"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page."
+                },
+                "total_estimate": {
+                    "type": "integer",
+                    "description": "TotalEstimate is the number of results before paging; exact for the current in-memory lists, an estimate once a source only counts approximately."
+                }
+            }
+        },
+        "dto.PriceChange": {
+            "type": "object",
+            "description": "PriceChange is a proposed unit price version and its review. Status is draft, rejected, approved (written to the price history, effective later) or effective (approved and in effect).",
+            "properties": {
+                "cpu_price_per_core_hour": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "history": {
+                    "type": "array",
+                    "description": "按时间正序",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceChangeEvent"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "mem_price_per_gb_hour": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "price_version_id": {
+                    "type": "string"
+                },
+                "recalculate": {
+                    "type": "boolean"
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.PriceChangeEvent": {
+            "type": "object",
+            "description": "PriceChangeEvent is a step of a price change: proposed, approved or rejected.",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "comment": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PriceChangeListResponse": {
+            "type": "object",
+            "description": "PriceChangeListResponse is the response of GET /api/v1/pricing/changes.",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "description": "最新的在前",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceChange"
+                    }
+                }
+            }
+        },
+        "dto.PriceChangeResponse": {
+            "type": "object",
+            "description": "PriceChangeResponse is the response of PUT /api/v1/pricing/history.",
+            "properties": {
+                "affected_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "affected_start": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "AffectedStart..AffectedEnd 已计算的数据中使用该价格的窗口；未来生效的版本没有受影响窗口"
+                },
+                "change": {
+                    "$ref": "#/definitions/dto.PriceChange"
+                },
+                "recalculation": {
+                    "$ref": "#/definitions/dto.CalculationRun"
+                },
+                "version": {
+                    "$ref": "#/definitions/dto.PriceVersion"
+                }
+            }
+        },
+        "dto.PriceHistoryResponse": {
+            "type": "object",
+            "description": "PriceHistoryResponse is the response of GET /api/v1/pricing/history.",
+            "properties": {
+                "current": {
+                    "$ref": "#/definitions/dto.PriceVersion"
+                },
+                "versions": {
+                    "type": "array",
+                    "description": "按生效时间正序",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceVersion"
+                    }
+                }
+            }
+        },
+        "dto.PriceVersion": {
+            "type": "object",
+            "description": "PriceVersion is a CPU/memory unit price effective from EffectiveFrom until the next version.",
+            "properties": {
+                "change_id": {
+                    "type": "string"
+                },
+                "cpu_price_per_core_hour": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "mem_price_per_gb_hour": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer",
+                    "description": "Revision 该版本的更正次数（首次保存为 1）；ChangeID 批准该版本的单价变更申请"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.QueryBudgetConsumer": {
+            "type": "object",
+            "description": "QueryBudgetConsumer is the consumption of one API consumer (service account, or \"anonymous\"). Limits of 0 are unlimited.",
+            "properties": {
+                "consumer": {
+                    "type": "string"
+                },
+                "database_queries": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "database_query_limit": {
+                    "type": "integer"
+                },
+                "database_query_seconds": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "exceeded": {
+                    "type": "string",
+                    "description": "Exceeded is the data source whose quota is used up (\"prometheus\" / \"database\"); the consumer's requests are rejected until the window ends."
+                },
+                "prometheus_queries": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "prometheus_query_limit": {
+                    "type": "integer"
+                },
+                "prometheus_query_seconds": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.QueryBudgetResponse": {
+            "type": "object",
+            "description": "QueryBudgetResponse is the response of GET /api/v1/admin/query-budgets: the Prometheus and database queries of every API consumer in the current accounting window, by consumer.",
+            "properties": {
+                "consumers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.QueryBudgetConsumer"
+                    }
+                },
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.QueryLineage": {
+            "type": "object",
+            "description": "QueryLineage is the response of GET /api/v1/lineage/:id: the data and code that produced a cost query response, captured when it was served (its X-Lighthouse-Lineage-Id header).",
+            "properties": {
+                "calculation_run": {
+                    "$ref": "#/definitions/dto.CalculationRun"
+                },
+                "code": {
+                    "$ref": "#/definitions/dto.CodeVersion"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string",
+                    "description": "including the query string"
+                },
+                "price_version": {
+                    "$ref": "#/definitions/dto.PriceVersion"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "served_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "snapshot": {
+                    "$ref": "#/definitions/dto.LineageSnapshot"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ROIBaselineComparisonResponse": {
+            "type": "object",
+            "description": "ROIBaselineComparisonResponse is the response of GET /api/v1/roi/baselines/:id/compare: the metrics of Date compared against a stored baseline. Baseline fields not pinned in the baseline's metrics are derived from the data of its period (daily averages); PinnedMetrics lists the others.",
+            "properties": {
+                "baseline": {
+                    "$ref": "#/definitions/roi.BaselineSnapshot"
+                },
+                "baseline_id": {
+                    "type": "string"
+                },
+                "baseline_name": {
+                    "type": "string"
+                },
+                "baseline_period_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "baseline_period_start": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "baseline_type": {
+                    "type": "string"
+                },
+                "comparison": {
+                    "$ref": "#/definitions/roi.DailyComparison"
+                },
+                "current": {
+                    "$ref": "#/definitions/roi.BaselineSnapshot"
+                },
+                "pinned_metrics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.ROIBaselineState": {
+            "type": "object",
+            "description": "ROIBaselineState is an ROI baseline without its storage timestamps.",
+            "properties": {
+                "baseline_type": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metrics": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "double"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reference_data": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "time_period_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "time_period_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.RecommendationAdoption": {
+            "type": "object",
+            "description": "RecommendationAdoption summarizes the adoption of a set of issued recommendations. Savings only count recommendations that save money (decommission and downsize).",
+            "properties": {
+                "adoption_rate": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "applied / issued, %"
+                },
+                "applied": {
+                    "type": "integer"
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "issued": {
+                    "type": "integer"
+                },
+                "open": {
+                    "type": "integer"
+                },
+                "partial": {
+                    "type": "integer"
+                },
+                "realized_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "unrealized_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.RecommendationAdoptionResponse": {
+            "type": "object",
+            "description": "RecommendationAdoptionResponse is the response of GET /api/v1/roi/recommendations/adoption: how many of the recommendations issued since Since were applied, per team, and the savings they left unrealized.",
+            "properties": {
+                "since": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "teams": {
+                    "type": "array",
+                    "description": "by unrealized savings, descending",
+                    "items": {
+                        "$ref": "#/definitions/dto.RecommendationAdoption"
+                    }
+                },
+                "top_unrealized": {
+                    "type": "array",
+                    "description": "TopUnrealized are the recommendations with the highest unrealized savings (at most 10)",
+                    "items": {
+                        "$ref": "#/definitions/dto.UnrealizedRecommendation"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/dto.RecommendationAdoption"
+                }
+            }
+        },
+        "dto.RegradeRequest": {
+            "type": "object",
+            "description": "RegradeRequest is the body of POST /api/v1/admin/regrade. Snapshots are selected by timestamp; an empty window selects all of them.",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "description": "compute without saving"
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "regraded_by": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "thresholds": {
+                    "$ref": "#/definitions/costmodel.GradeThresholds"
+                }
+            }
+        },
+        "dto.RegradeResponse": {
+            "type": "object",
+            "description": "RegradeResponse is the response of POST /api/v1/admin/regrade. Snapshots without resource results cannot be regraded and are counted in Skipped.",
+            "properties": {
+                "changed": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "original": {
+                    "$ref": "#/definitions/dto.GradeCounts"
+                },
+                "regraded": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SnapshotRegradeResult"
+                    }
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "thresholds": {
+                    "$ref": "#/definitions/costmodel.GradeThresholds"
+                },
+                "total": {
+                    "$ref": "#/definitions/dto.GradeCounts"
+                }
+            }
+        },
+        "dto.ResourceMetadata": {
+            "type": "object",
+            "description": "ResourceMetadata is the K8s metadata of a namespace or workload joined onto cost results at query time; workloads inherit the fields they do not set from their namespace.",
+            "properties": {
+                "app": {
+                    "type": "string"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "tier": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReviewPriceChangeRequest": {
+            "type": "object",
+            "description": "ReviewPriceChangeRequest is the body of POST /api/v1/pricing/changes/:id/approve and /reject.",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SLOConfigState": {
+            "type": "object",
+            "description": "SLOConfigState holds the SLO thresholds of an environment.",
+            "properties": {
+                "availability_threshold": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "latency_p95_threshold_ms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SLOCostResponse": {
+            "type": "object",
+            "description": "SLOCostResponse is the response of GET /api/v1/slo/cost: what the availability target of each service costs in capacity, from its usage quantiles over the window. Monthly costs are at the configured unit prices.",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "services": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SLOServiceCost"
+                    }
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total_excess_monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total_slo_headroom_monthly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "TotalSLOHeadroomMonthlyCost sums the capacity kept above typical demand for the targets."
+                },
+                "unmatched": {
+                    "type": "array",
+                    "description": "Unmatched lists SLO services without workload stats in the window (namespace/service).",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.SLOHealth": {
+            "type": "object",
+            "description": "SLOHealth is the health of one service as the dashboard shows it.",
+            "properties": {
+                "errorRate": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "responseTime": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "serviceName": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "description": "healthy / warning / critical"
+                },
+                "uptime": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "%"
+                }
+            }
+        },
+        "dto.SLOHealthList": {
+            "type": "object",
+            "description": "SLOHealthList is the response of GET /api/v1/slo/health.",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SLOHealth"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page."
+                },
+                "total_estimate": {
+                    "type": "integer",
+                    "description": "TotalEstimate is the number of results before paging; exact for the current in-memory lists, an estimate once a source only counts approximately."
+                }
+            }
+        },
+        "dto.SLOServiceCost": {
+            "type": "object",
+            "description": "SLOServiceCost is the cost of the SLO of one service (the workload named like it).",
+            "properties": {
+                "availability": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Availability is the measured availability (%), next to the target in Cost."
+                },
+                "cost": {
+                    "$ref": "#/definitions/costmodel.SLOCost"
+                },
+                "latency_bound": {
+                    "type": "boolean"
+                },
+                "latency_p95_ms": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "latency_target_ms": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "LatencyTargetMs is the P95 latency target; LatencyBound is set when the measured P95 uses more than 80% of it, i.e. the headroom also keeps latency in target and relaxing nines risks it."
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "samples": {
+                    "type": "string",
+                    "description": "Samples is where the quantiles come from: \"sketch\" (merged usage sketches) or \"hourly_p95\"."
+                },
+                "service": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SLOViolationRecord": {
+            "type": "object",
+            "description": "SLOViolationRecord is a recorded SLO violation.",
+            "properties": {
+                "actual_value": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "impact": {
+                    "$ref": "#/definitions/dto.DependencyImpact"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "recovered_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "threshold_value": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "violated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "violation_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SLOViolationRequest": {
+            "type": "object",
+            "description": "SLOViolationRequest is the body of POST /api/v1/timeline/slo-violations. Report the start of a violation, then the same ID with recovered_at once it recovers.",
+            "properties": {
+                "actual_value": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "recovered_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string",
+                    "description": "warning / critical, default critical"
+                },
+                "threshold_value": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "violated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "default now"
+                },
+                "violation_type": {
+                    "type": "string",
+                    "description": "availability / latency / error_rate"
+                }
+            },
+            "required": [
+                "namespace",
+                "service",
+                "violation_type"
+            ]
+        },
+        "dto.SavedView": {
+            "type": "object",
+            "description": "SavedView is a named set of filters of a cockpit page.",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "filters": {
+                    "type": "object",
+                    "description": "Filters are the query parameters of the page, e.g. {\"namespace\": \"shop\", \"grade\": \"waste\"}.",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "string",
+                    "description": "Page is the cockpit route the view belongs to, e.g. \"/cost/namespaces\"."
+                }
+            }
+        },
+        "dto.SavedViewRequest": {
+            "type": "object",
+            "description": "SavedViewRequest is the body of POST /api/v1/preferences/views.",
+            "properties": {
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "name"
+            ]
+        },
+        "dto.SelfSLOResponse": {
+            "type": "object",
+            "description": "SelfSLOResponse is the response of GET /api/v1/slo/self: the availability and latency SLOs of Lighthouse's own API routes over the evaluation window, worst route first.",
+            "properties": {
+                "availability_target": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Default targets; routes may override them in server.self_slo.routes."
+                },
+                "latency_target_ms": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "P95"
+                },
+                "routes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SelfSLORoute"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "description": "worst route status: healthy / warning / critical"
+                },
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.SelfSLORoute": {
+            "type": "object",
+            "description": "SelfSLORoute is the SLO evaluation of one API route.",
+            "properties": {
+                "availability": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "%"
+                },
+                "availability_budget_remaining": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Remaining error budgets (%); ErrorBudgetRemaining is the lower of both."
+                },
+                "availability_target": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "error_budget_remaining": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "errors": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "5xx responses"
+                },
+                "latency_budget_remaining": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "latency_p50_ms": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Latency quantiles are estimated from the request latency histogram."
+                },
+                "latency_p95_ms": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "latency_p99_ms": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "latency_target_ms": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "requests": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "route": {
+                    "type": "string",
+                    "description": "registered path pattern, e.g. /api/v1/workloads/:id"
+                },
+                "slow_requests": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "slower than the latency target"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ServiceAccount": {
+            "type": "object",
+            "description": "ServiceAccount is a machine identity (CI job, other service) with its API keys.",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.APIKey"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.ServiceAccountListResponse": {
+            "type": "object",
+            "description": "ServiceAccountListResponse is the response of GET /api/v1/admin/service-accounts.",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ServiceAccount"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SetEfficiencyTargetRequest": {
+            "type": "object",
+            "description": "SetEfficiencyTargetRequest is the body of PUT /api/v1/cost/targets/{namespace}.",
+            "properties": {
+                "set_by": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "efficiency score 0-100"
+                }
+            }
+        },
+        "dto.SetGradeViewRequest": {
+            "type": "object",
+            "description": "SetGradeViewRequest is the body of PUT /api/v1/admin/regrade/view.",
+            "properties": {
+                "set_by": {
+                    "type": "string"
+                },
+                "view": {
+                    "type": "string",
+                    "description": "original / regraded"
+                }
+            },
+            "required": [
+                "view"
+            ]
+        },
+        "dto.SetPriceRequest": {
+            "type": "object",
+            "description": "SetPriceRequest is the body of PUT /api/v1/pricing/history. An existing EffectiveFrom corrects that version; Recalculate re-prices the affected window right away.",
+            "properties": {
+                "cpu_price_per_core_hour": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "mem_price_per_gb_hour": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "recalculate": {
+                    "type": "boolean"
+                }
+            },
+            "required": [
+                "cpu_price_per_core_hour",
+                "effective_from",
+                "mem_price_per_gb_hour"
+            ]
+        },
+        "dto.Silence": {
+            "type": "object",
+            "description": "Silence mutes the alerts whose labels match all matchers from StartsAt until EndsAt.",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "expired_by": {
+                    "type": "string",
+                    "description": "who ended it early"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "matchers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.SilenceListResponse": {
+            "type": "object",
+            "description": "SilenceListResponse is the response of GET /api/v1/alerts/silences.",
+            "properties": {
+                "silences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Silence"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SnapshotExportResponse": {
+            "type": "object",
+            "description": "SnapshotExportResponse is the response of GET /api/v1/snapshots/export. It is only produced when the exported snapshots verify; otherwise the endpoint returns 409 with the verification.",
+            "properties": {
+                "exported_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ExportedSnapshot"
+                    }
+                },
+                "verification": {
+                    "$ref": "#/definitions/dto.SnapshotVerificationResponse"
+                },
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.SnapshotGrades": {
+            "type": "object",
+            "description": "SnapshotGrades is the grade counts of a snapshot in a view. In the regraded view, snapshots not regraded yet keep their original counts (Regraded false).",
+            "properties": {
+                "counts": {
+                    "$ref": "#/definitions/dto.GradeCounts"
+                },
+                "regraded": {
+                    "type": "boolean"
+                },
+                "snapshot_id": {
+                    "type": "string"
+                },
+                "thresholds": {
+                    "$ref": "#/definitions/costmodel.GradeThresholds"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.SnapshotGradesResponse": {
+            "type": "object",
+            "description": "SnapshotGradesResponse is the response of GET /api/v1/snapshots/grades.",
+            "properties": {
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SnapshotGrades"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/dto.GradeCounts"
+                },
+                "view": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SnapshotIntegrityIssue": {
+            "type": "object",
+            "description": "SnapshotIntegrityIssue is one problem found while verifying snapshots against the hash chain.",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "problem": {
+                    "type": "string",
+                    "description": "chain_broken / content_mismatch / not_in_chain / missing"
+                },
+                "seq": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "snapshot_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SnapshotRegradeResult": {
+            "type": "object",
+            "description": "SnapshotRegradeResult compares the stored grade counts of a snapshot with its regraded counts.",
+            "properties": {
+                "changed": {
+                    "type": "boolean"
+                },
+                "original": {
+                    "$ref": "#/definitions/dto.GradeCounts"
+                },
+                "regraded": {
+                    "$ref": "#/definitions/dto.GradeCounts"
+                },
+                "resources": {
+                    "type": "integer"
+                },
+                "snapshot_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.SnapshotSummary": {
+            "type": "object",
+            "description": "SnapshotSummary is the headline part of a cost snapshot.",
+            "properties": {
+                "confirmed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "confirmed_by": {
+                    "type": "string"
+                },
+                "content_hash": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "overall_efficiency_score": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "requires_confirmation": {
+                    "type": "boolean"
+                },
+                "suspect": {
+                    "type": "boolean"
+                },
+                "total_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total_usage_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total_waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.SnapshotVerificationResponse": {
+            "type": "object",
+            "description": "SnapshotVerificationResponse is the response of GET /api/v1/snapshots/verify.",
+            "properties": {
+                "chain_length": {
+                    "type": "integer"
+                },
+                "head_hash": {
+                    "type": "string"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SnapshotIntegrityIssue"
+                    }
+                },
+                "snapshot_id": {
+                    "type": "string",
+                    "description": "为空表示校验全部快照"
+                },
+                "snapshots": {
+                    "type": "integer"
+                },
+                "verified": {
+                    "type": "boolean"
+                },
+                "verified_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.StartChaosDrillRequest": {
+            "type": "object",
+            "description": "StartChaosDrillRequest is the body of POST /api/v1/admin/chaos.",
+            "properties": {
+                "duration": {
+                    "type": "string",
+                    "description": "Go duration, e.g. \"15m\"; at most the configured max_duration"
+                },
+                "scenario": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "duration",
+                "scenario"
+            ]
+        },
+        "dto.StateBundle": {
+            "type": "object",
+            "description": "StateBundle is the application state of an environment, for cloning it into another one (e.g. staging -> prod). ROI baselines and price versions are stored data; SLO, teams and owners are configuration of the source environment.",
+            "properties": {
+                "environment": {
+                    "type": "string"
+                },
+                "exported_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "owners": {
+                    "type": "object",
+                    "description": "namespace -> 归属人，\"*\" 为兜底",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "price_versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceVersion"
+                    }
+                },
+                "roi_baselines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ROIBaselineState"
+                    }
+                },
+                "slo": {
+                    "$ref": "#/definitions/dto.SLOConfigState"
+                },
+                "teams": {
+                    "type": "object",
+                    "description": "团队名 -> namespace、接收人与月度预算",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/dto.TeamState"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.StateChange": {
+            "type": "object",
+            "description": "StateChange is one difference between a bundle and the target environment.",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "after": {},
+                "before": {},
+                "detail": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "section": {
+                    "type": "string",
+                    "description": "roi_baselines / price_versions / slo / teams / owners"
+                }
+            }
+        },
+        "dto.StateImportResponse": {
+            "type": "object",
+            "description": "StateImportResponse is the response of POST /api/v1/admin/state/import. Changes are applied unless DryRun; ConfigChanges are never applied and must be made in the target's configuration.",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StateChange"
+                    }
+                },
+                "config_changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StateChange"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "summary": {
+                    "type": "object",
+                    "description": "action -> count over stored sections",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "dto.SuspectSnapshot": {
+            "type": "object",
+            "description": "SuspectSnapshot is a snapshot flagged by the rate-of-change guardrail.",
+            "properties": {
+                "calculation_id": {
+                    "type": "string"
+                },
+                "confirmed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "confirmed_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "requires_confirmation": {
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.SuspectSnapshotListResponse": {
+            "type": "object",
+            "description": "SuspectSnapshotListResponse is the response of GET /api/v1/snapshots/suspect.",
+            "properties": {
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SuspectSnapshot"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.TeamState": {
+            "type": "object",
+            "description": "TeamState is a team of the weekly digest: its namespaces, recipients and monthly budget.",
+            "properties": {
+                "monthly_budget": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.TimelineEvent": {
+            "type": "object",
+            "description": "TimelineEvent is one entry of the cluster events timeline.",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "EndTime 持续性事件的结束时间（K8s 事件最后一次出现、计算执行完成、SLO 恢复）；进行中或瞬时事件为空"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string",
+                    "description": "Namespace 为空表示集群级事件（计算执行、单价变更、集群级配置变更）"
+                },
+                "object": {
+                    "type": "string",
+                    "description": "Object the event is about, \"Kind/name\", e.g. \"Deployment/checkout\" or \"Service/payment-api\"."
+                },
+                "ref": {
+                    "type": "string",
+                    "description": "Ref is the ID of the record in its source, e.g. the calculation run or price version ID."
+                },
+                "severity": {
+                    "type": "string",
+                    "description": "info / warning / critical"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TimelineResponse": {
+            "type": "object",
+            "description": "TimelineResponse is the response of GET /api/v1/timeline: the events of all sources in the window, oldest first.",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TimelineEvent"
+                    }
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "description": "Truncated is set when only the latest limit events are returned."
+                },
+                "unavailable": {
+                    "type": "array",
+                    "description": "Unavailable lists the kinds whose source failed; the timeline holds the other kinds.",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.TriggerCalculationRequest": {
+            "type": "object",
+            "description": "TriggerCalculationRequest is the body of POST /api/v1/calculations/runs (manual run).",
+            "properties": {
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            },
+            "required": [
+                "window_end",
+                "window_start"
+            ]
+        },
+        "dto.UnrealizedRecommendation": {
+            "type": "object",
+            "description": "UnrealizedRecommendation is an issued recommendation not (fully) applied yet.",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "current_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "issued_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "recommended_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "resource": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "unrealized_monthly_savings": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "workload": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UpdatePreferencesRequest": {
+            "type": "object",
+            "description": "UpdatePreferencesRequest is the body of PUT /api/v1/preferences. Omitted fields keep their value; saved views are managed with /api/v1/preferences/views.",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "default_namespaces": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "favorite_workloads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FavoriteWorkload"
+                    }
+                },
+                "units": {
+                    "$ref": "#/definitions/dto.DisplayUnits"
+                }
+            }
+        },
+        "dto.UpdateServiceAccountRequest": {
+            "type": "object",
+            "description": "UpdateServiceAccountRequest is the body of PUT /api/v1/admin/service-accounts/:id.",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.UpstreamService": {
+            "type": "object",
+            "description": "UpstreamService is a service depending on the degraded workload.",
+            "properties": {
+                "depth": {
+                    "type": "integer",
+                    "description": "Depth is the number of calls between the service and the workload; 1 calls it directly."
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "via": {
+                    "type": "string",
+                    "description": "Via is the service it calls on the shortest path to the workload (\"namespace/service\")."
+                }
+            }
+        },
+        "dto.UserPreferences": {
+            "type": "object",
+            "description": "UserPreferences are the cockpit settings of one user, returned by GET /api/v1/preferences.",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "description": "ISO 4217 code, e.g. \"CNY\""
+                },
+                "default_namespaces": {
+                    "type": "array",
+                    "description": "DefaultNamespaces preselects the namespace filter of the cockpit; empty means all.",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "favorite_workloads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FavoriteWorkload"
+                    }
+                },
+                "saved_views": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SavedView"
+                    }
+                },
+                "units": {
+                    "$ref": "#/definitions/dto.DisplayUnits"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "UpdatedAt is empty while the user has not saved any preference (defaults are returned)."
+                },
+                "user": {
+                    "type": "string",
+                    "description": "User is the identity the preferences are stored under (service account, or the user forwarded by the auth proxy)."
+                }
+            }
+        },
+        "dto.WorkloadCost": {
+            "type": "object",
+            "description": "WorkloadCost represents cost for a specific workload.",
+            "properties": {
+                "cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "billable cost of the window"
+                },
+                "efficiency": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "usage / billable, %"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/dto.ResourceMetadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "pod_count": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "description": "Deployment, StatefulSet, etc."
+                },
+                "waste_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.WorkloadCostDetailResponse": {
+            "type": "object",
+            "description": "WorkloadCostDetailResponse is the response of GET /api/v1/workloads/:namespace/:name/costs: everything the workload detail page needs in one payload.",
+            "properties": {
+                "cost": {
+                    "$ref": "#/definitions/dto.CostBreakdown"
+                },
+                "cost_policy": {
+                    "$ref": "#/definitions/costmodel.CostPolicy"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "grade_history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GradeChangeEvent"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "recommendations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/costmodel.Recommendation"
+                    }
+                },
+                "reliability_tax": {
+                    "$ref": "#/definitions/dto.WorkloadReliabilityTax"
+                },
+                "resources": {
+                    "$ref": "#/definitions/dto.WorkloadResourceUsage"
+                },
+                "series": {
+                    "type": "array",
+                    "description": "Series 按小时的 billable（Cost）/ usage / waste 成本",
+                    "items": {
+                        "$ref": "#/definitions/dto.GranularCostDataPoint"
+                    }
+                },
+                "suppressed_recommendations": {
+                    "type": "array",
+                    "description": "SuppressedRecommendations 因 CPU 限流或 SLO 风险被安全检查抑制的缩容建议（含 safety 说明）；未配置安全检查时为空",
+                    "items": {
+                        "$ref": "#/definitions/costmodel.Recommendation"
+                    }
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "window_end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "window_start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.WorkloadHistoryPoint": {
+            "type": "object",
+            "description": "WorkloadHistoryPoint is the cost of a workload on one day; Efficiency is usage/billable in % and Grade is graded from it with the workload's thresholds (empty without billable cost).",
+            "properties": {
+                "billable": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "efficiency": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "grade": {
+                    "type": "string"
+                },
+                "usage": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "waste": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.WorkloadHistoryResponse": {
+            "type": "object",
+            "description": "WorkloadHistoryResponse is the response of GET /api/v1/workloads/:namespace/:name/history: one point per UTC day with data, oldest first, read from the daily rollups (cost_daily_workload) and the hourly stats of the days not yet downsampled.",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "end": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WorkloadHistoryPoint"
+                    }
+                },
+                "start": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "dto.WorkloadReliabilityTax": {
+            "type": "object",
+            "description": "WorkloadReliabilityTax is the spend wasted on restarting pods in the window (see costmodel.ComputeReliabilityTax), next to the SLO of the service named like the workload.",
+            "properties": {
+                "error_budget_remaining": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "pod_hourly_cost": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "average billable cost of one pod-hour"
+                },
+                "restarts": {
+                    "type": "object",
+                    "description": "cause (eviction, oom_kill, crash_loop) -> restarts in the window",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "share": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "wasted / billable cost in %"
+                },
+                "slo_status": {
+                    "type": "string",
+                    "description": "SLOStatus and ErrorBudgetRemaining are absent when the workload has no SLO"
+                },
+                "wasted_cost": {
+                    "type": "number",
+                    "format": "double"
+                }
+            }
+        },
+        "dto.WorkloadResourceUsage": {
+            "type": "object",
+            "description": "WorkloadResourceUsage is the latest hour's requests vs P95 usage. Utilization is P95/request in %.",
+            "properties": {
+                "cpu_request": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_usage_p95": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "mem_request": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "mem_usage_p95": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "mem_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "roi.BaselineSnapshot": {
+            "type": "object",
+            "description": "BaselineSnapshot represents the Day 0 baseline snapshot for ROI tracking.",
+            "properties": {
+                "cpu_utilization": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Resource utilization metrics"
+                },
+                "mem_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "node_count": {
+                    "type": "integer",
+                    "description": "Node metrics"
+                },
+                "snapshot_id": {
+                    "type": "string",
+                    "description": "Snapshot metadata"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "Snapshot timestamp"
+                },
+                "total_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "total_waste_amount": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Cost metrics"
+                },
+                "zombie_asset_count": {
+                    "type": "integer",
+                    "description": "Zombie asset count"
+                }
+            }
+        },
+        "roi.DailyComparison": {
+            "type": "object",
+            "description": "DailyComparison represents the daily comparison against the baseline.",
+            "properties": {
+                "baseline_id": {
+                    "type": "string",
+                    "description": "Baseline reference"
+                },
+                "cost_savings_amount": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "cpu_utilization_improvement": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Comparison results"
+                },
+                "current_cpu_utilization": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Current metrics"
+                },
+                "current_mem_utilization": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "current_node_count": {
+                    "type": "integer"
+                },
+                "current_total_billable_cost": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "current_total_waste_amount": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "current_zombie_asset_count": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "Comparison metadata"
+                },
+                "mem_utilization_improvement": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "percentage points"
+                },
+                "node_reduction_count": {
+                    "type": "integer"
+                },
+                "resource_recovery_rate": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Efficiency gains"
+                },
+                "waste_reduction_amount": {
+                    "type": "number",
+                    "format": "double"
+                },
+                "zombie_cleanup_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "roi.OptimizationTrackingRecord": {
+            "type": "object",
+            "description": "OptimizationTrackingRecord tracks individual optimization actions.",
+            "properties": {
+                "after_state": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "before_state": {
+                    "type": "object",
+                    "description": "Before and after state",
+                    "additionalProperties": {}
+                },
+                "bundle_id": {
+                    "type": "string"
+                },
+                "business_impact": {
+                    "type": "string",
+                    "description": "\"positive\", \"neutral\", \"negative\""
+                },
+                "immediate_savings": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Savings achieved"
+                },
+                "implementation_date": {
+                    "type": "string",
+                    "format": "date-time",
+                    "description": "Implementation details"
+                },
+                "implementation_effort": {
+                    "type": "string",
+                    "description": "\"low\", \"medium\", \"high\""
+                },
+                "implemented_by": {
+                    "type": "string"
+                },
+                "optimization_type": {
+                    "type": "string",
+                    "description": "Optimization type"
+                },
+                "performance_impact": {
+                    "type": "string",
+                    "description": "\"improved\", \"neutral\", \"degraded\""
+                },
+                "projected_savings": {
+                    "type": "number",
+                    "format": "double",
+                    "description": "Projected annual savings"
+                },
+                "recommendation_id": {
+                    "type": "string",
+                    "description": "Origin: the issued recommendation and the manifest bundle it was exported in (empty when recorded by hand)"
+                },
+                "record_id": {
+                    "type": "string",
+                    "description": "Record identifier"
+                },
+                "resources_recovered": {
+                    "type": "object",
+                    "description": "Resources recovered (CPU, Memory, etc.)",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "double"
+                    }
+                },
+                "risk_level": {
+                    "type": "string",
+                    "description": "Impact assessment"
+                },
+                "target_resource_id": {
+                    "type": "string",
+                    "description": "Target resource"
+                },
+                "target_resource_type": {
+                    "type": "string",
+                    "description": "\"pod\", \"namespace\", \"node\", \"storage_class\""
+                },
+                "verification_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "verified": {
+                    "type": "boolean",
+                    "description": "Verification"
+                },
+                "verified_by": {
+                    "type": "string"
+                }
             }
         }
     }
@@ -62,12 +11659,12 @@ const docTemplate = `{
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
+	Version:          "1.0.0",
 	Host:             "",
-	BasePath:         "",
+	BasePath:         "/api/v1",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "Lighthouse API",
+	Description:      "Infrastructure Decision Cockpit: cost analysis, SLO health and ROI tracking.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,4 @@
+package api
+
+// swagger.json, swagger.yaml, docs.go and pkg/client are generated from the handler annotations.
+//go:generate go run ../cmd/apigen -root ..
@@ -0,0 +1,47 @@
+// Command apigen regenerates api/swagger.{json,yaml}, api/docs.go and pkg/client from the handler
+// annotations in internal/server/http.go. Run it from the repository root (go generate ./api); with
+// -check it only reports generated files that are out of date and exits 1.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/myxxhui/lighthouse-src/internal/apigen"
+)
+
+func main() {
+	root := flag.String("root", ".", "repository root")
+	check := flag.Bool("check", false, "report out-of-date files instead of writing them")
+	flag.Parse()
+
+	files, err := apigen.Render(apigen.LighthouseConfig(*root))
+	if err != nil {
+		log.Fatalf("apigen: %v", err)
+	}
+	stale, err := apigen.Stale(*root, files)
+	if err != nil {
+		log.Fatalf("apigen: %v", err)
+	}
+	if *check {
+		for _, path := range stale {
+			log.Printf("apigen: %s is out of date, run `go generate ./api`", path)
+		}
+		if len(stale) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	for _, path := range stale {
+		dst := filepath.Join(*root, path)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			log.Fatalf("apigen: %v", err)
+		}
+		if err := os.WriteFile(dst, files[path], 0o644); err != nil {
+			log.Fatalf("apigen: %v", err)
+		}
+		log.Printf("apigen: wrote %s", path)
+	}
+}
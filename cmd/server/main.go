@@ -1,32 +1,1064 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	_ "github.com/myxxhui/lighthouse-src/api" // 注册 Swagger docs 供 gin-swagger 使用
 	"github.com/myxxhui/lighthouse-src/internal/config"
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/internal/data/cloudbilling"
+	_ "github.com/myxxhui/lighthouse-src/internal/data/cloudbilling/aliyun"
+	"github.com/myxxhui/lighthouse-src/internal/data/demo"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/objectstore"
 	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+	"github.com/myxxhui/lighthouse-src/internal/data/querybudget"
+	"github.com/myxxhui/lighthouse-src/internal/data/retry"
+	"github.com/myxxhui/lighthouse-src/internal/data/storage"
+	"github.com/myxxhui/lighthouse-src/internal/eventbus"
+	"github.com/myxxhui/lighthouse-src/internal/exporter"
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
 	"github.com/myxxhui/lighthouse-src/internal/server"
+	"github.com/myxxhui/lighthouse-src/internal/server/apimetrics"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
 	"github.com/myxxhui/lighthouse-src/internal/server/service"
+	"github.com/myxxhui/lighthouse-src/internal/worker/etl"
+	"github.com/myxxhui/lighthouse-src/internal/worker/lock"
+	"github.com/myxxhui/lighthouse-src/internal/worker/spool"
+	"github.com/myxxhui/lighthouse-src/pkg/authz"
+	"github.com/myxxhui/lighthouse-src/pkg/client"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 )
 
+// Build information, set with -ldflags "-X main.Version=..." (see Makefile) and recorded in query lineage.
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildTime = ""
+)
+
+// @title       Lighthouse API
+// @version     1.0.0
+// @description Infrastructure Decision Cockpit: cost analysis, SLO health and ROI tracking.
+// @BasePath    /api/v1
 func main() {
 	// Lighthouse Server - Infrastructure Decision Cockpit (Phase3 Mock)
+	demoMode := flag.Bool("demo", false, "serve the scripted demo dataset (in-memory storage, demo K8s/Prometheus mocks)")
+	flag.Parse()
 	cfg, err := loadConfig()
 	if err != nil {
 		log.Printf("WARN: config load failed, using defaults: %v", err)
 		cfg = defaultConfig()
 	}
 
-	// Mock data layer (Phase3)
-	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
-	costSvc := service.NewCostService(mockRepo)
+	// 存储驱动（默认 mock，Phase3）
+	k8sMock, promMock := k8s.DefaultMockConfig(), prometheus.DefaultMockConfig()
+	if *demoMode {
+		// 演示模式：内存存储写入剧本数据，K8s / Prometheus Mock 与之保持一致
+		cfg.Storage.Driver = storage.DriverMemory
+	}
+	repo, err := storage.Open(context.Background(), newStorageConfig(cfg))
+	if err != nil {
+		// 不回退到 mock：只有显式配置 storage.driver: mock 时才使用演示数据
+		log.Fatalf("open storage driver %q: %v", cfg.Storage.Driver, err)
+	}
+	if *demoMode {
+		story := demo.NewStory(time.Now(), cfg.Business.CostCalculation.CPUPricePerCoreHour, cfg.Business.CostCalculation.MemPricePerGBHour)
+		if err := story.Seed(context.Background(), repo); err != nil {
+			log.Fatalf("seed demo dataset: %v", err)
+		}
+		k8sMock, promMock = story.K8sConfig(), story.PrometheusConfig()
+		log.Printf("Demo mode: %d days of scripted history seeded (incident at %s)", demo.Days, story.IncidentAt.Format(time.RFC3339))
+	}
+	rawRepo := repo
+	if cfg.Security.Encryption.EnableDataEncryption {
+		// metadata 与云账单汇总经 repo 透明加解密；其余可选存储（计算记录、单价历史等）不含敏感字段，透传
+		keys, err := storage.ParseKeyring(cfg.Security.Encryption.EncryptionKey)
+		if err != nil {
+			log.Fatalf("data encryption: %v", err)
+		}
+		encrypted := storage.NewEncryptedRepository(repo, keys)
+		report, err := encrypted.Migrate(context.Background())
+		if err != nil {
+			log.Printf("WARN: encrypt existing rows failed (plaintext rows stay readable, retried on next start): %v", err)
+		} else {
+			log.Printf("data encryption: key %s active, migrated %+v", keys.ActiveKeyID(), *report)
+		}
+		repo = encrypted
+	}
+	// 故障演练：在降级缓存、重试与熔断之内注入故障，演练时它们的行为与真实故障一致
+	var drills *chaos.Controller
+	if cfg.Chaos.Enabled {
+		drills = newChaosController(cfg.Chaos)
+		repo = storage.NewChaosRepository(repo, drills)
+	}
+	if cfg.Security.QueryBudgets.Enabled {
+		// 降级缓存返回的读不访问存储，不计入查询预算
+		repo = storage.NewBudgetRepository(repo)
+	}
+	// 可选存储（计算记录、单价历史、服务账号等）、计算写入与 spool 回放经 storeRepo：经过加密、故障演练
+	// 与查询预算，但不经过降级缓存
+	storeRepo := repo
+	var degradedRepo *storage.DegradedRepository
+	if cfg.Storage.DegradedMode {
+		degradedRepo = storage.NewDegradedRepository(repo, 0)
+		go degradedRepo.Run(context.Background(), cfg.Storage.HealthProbeInterval)
+		repo = degradedRepo
+	}
+	// 定时任务经同一个调度器执行：postgres 存储时以 advisory lock 选主，多副本中只有 leader 执行，
+	// 完成的时间槽记录在 metadata，接管的副本补跑未完成的槽
+	scheduler := newScheduler(rawRepo, repo)
+	costSvc := service.NewCostService(repo)
+	grafanaSvc := service.NewGrafanaService(repo, service.DefaultMockSLOStatus())
+	if lifecycles, ok := storeRepo.(service.NamespaceLifecycleReader); ok {
+		costSvc.SetNamespaceLifecycles(lifecycles)
+		grafanaSvc.SetNamespaceLifecycles(lifecycles)
+	}
 
 	srv := server.NewHTTPServer(cfg, costSvc)
+	if degradedRepo != nil {
+		srv.SetDegradedRepository(degradedRepo)
+	}
+	srv.SetGrafanaService(grafanaSvc)
+	metricsExporter := exporter.New(repo, service.DefaultMockSLOStatus(), exporter.Config{})
+	if cfg.Spool.Dir != "" {
+		if sp, err := spool.Open(cfg.Spool.Dir, cfg.Spool.MaxEntries); err != nil {
+			log.Printf("WARN: open spool %q failed, failed writes will not be spooled: %v", cfg.Spool.Dir, err)
+		} else {
+			srv.SetSpool(sp, spool.Handlers{etl.SpoolKindHourlyWorkloadStats: etl.ReplayHourlyWorkloadStats(storeRepo)})
+			metricsExporter.AddCollector(exporter.SpoolCollector(sp))
+		}
+	}
+	// 领域事件经 Kafka REST Proxy 发布；配置 spool.dir 时未确认事件落盘，重启后继续投递
+	var bus *eventbus.Bus
+	if cfg.EventBus.Enabled {
+		if bus, err = newEventBus(cfg); err != nil {
+			log.Fatalf("event_bus: %v", err)
+		}
+		go bus.Run(context.Background(), jobInterval(cfg.EventBus.FlushInterval, 5*time.Second), func(err error) {
+			log.Printf("WARN: event bus: %v (%d events pending)", err, bus.Pending())
+		})
+	}
+	var breakers externalBreakers
+	if cfg.CircuitBreaker.Enabled {
+		// Prometheus / K8s 客户端经 newPrometheusClient / newK8sClient 共享这些熔断器；ClickHouse 日志写入
+		// （etl.BreakerLogProcessor）不在 server 进程内运行，这里不为它登记熔断器
+		breakers = newBreakerSets(cfg.CircuitBreaker)
+		srv.SetBreakers(breakers.prometheus, breakers.kubernetes)
+		metricsExporter.AddCollector(exporter.BreakerCollector(breakers.prometheus, breakers.kubernetes))
+	}
+	metricsExporter.AddCollector(exporter.RequestCollector(srv.APIMetrics()))
+	// 合并的查询与请求共用处理时限，没有时限的调用方（如后台任务）同样受此上限约束
+	if d := cfg.Server.RequestDeadline(); d > 0 {
+		costSvc.Coalescer().SetTimeout(d)
+	}
+	metricsExporter.AddCollector(exporter.CoalesceCollector(costSvc.Coalescer()))
+	if drills != nil {
+		srv.SetChaos(drills)
+		metricsExporter.AddCollector(exporter.ChaosCollector(drills))
+	}
+	srv.SetMetricsHandler(metricsExporter.Handler())
+	if cfg.Server.SelfSLO.Enabled {
+		selfSLO := newSelfSLOService(cfg, srv.APIMetrics())
+		go selfSLO.Run(context.Background(), 0)
+		srv.SetSelfSLOService(selfSLO)
+	}
+	if cfg.Security.QueryBudgets.Enabled {
+		srv.SetQueryBudgets(newQueryBudgets(cfg.Security.QueryBudgets))
+	}
+	prices := cfg.Business.CostCalculation
+	// NaN/Inf 成本按同一策略处理：查询聚合、快照计算与重算
+	nonFinite, err := costmodel.ParseNonFinitePolicy(prices.NonFinitePolicy)
+	if err != nil {
+		log.Fatalf("business.cost_calculation.non_finite_policy: %v", err)
+	}
+	costSvc.SetNonFinitePolicy(nonFinite)
+	// 计算流水线与快照确认写入的快照经 snapshotRepo 发布 SnapshotCreated
+	snapshotRepo := newSnapshotRepository(storeRepo, bus)
+	guardrail := service.NewSnapshotGuardrail(snapshotRepo, cfg.Business.SnapshotGuardrail.MaxChangePercent, cfg.Business.SnapshotGuardrail.RequireConfirmation)
+	srv.SetSnapshotGuardrail(guardrail)
+	// 定时与重跑的计算在快照之后为窗口内的工作负载评级，等级变化写入 workload_grade_event
+	var grades *etl.GradeTracker
+	if gradeStore, ok := storeRepo.(etl.GradeEventStore); ok {
+		grades = &etl.GradeTracker{Store: gradeStore}
+		if bus != nil {
+			grades.Publisher = bus
+		}
+	}
+	if runStore, ok := storeRepo.(service.CalculationRunStore); ok {
+		pipeline := service.NewSnapshotPipeline(snapshotRepo, guardrail, nonFinite)
+		if grades != nil {
+			pipeline = service.WithGradeTracking(pipeline, storeRepo, grades)
+		}
+		runs := service.NewCalculationRunService(runStore, pipeline)
+		srv.SetCalculationRunService(runs)
+		scheduler.Jobs = append(scheduler.Jobs, calculationJob(runs, prices.CalculationInterval))
+		if priceStore, ok := storeRepo.(service.PriceHistoryStore); ok {
+			pricing := service.NewPricingService(priceStore, storeRepo, runStore, prices.CPUPricePerCoreHour, prices.MemPricePerGBHour)
+			changeStore, _ := storeRepo.(service.PriceChangeStore)
+			if changeStore == nil && cfg.Business.PriceApproval.Required {
+				log.Fatalf("business.price_approval.required is set but storage driver %q has no price changes; prices could not be changed", cfg.Storage.Driver)
+			}
+			pricing.SetApproval(changeStore, cfg.Business.PriceApproval.Required, cfg.Business.PriceApproval.AllowSelfApproval)
+			pricing.SetNonFinitePolicy(nonFinite)
+			srv.SetPricingService(pricing)
+		}
+	}
+	// 快照完整性校验读取底层存储，避免降级缓存中的旧值掩盖篡改
+	if chain, ok := storeRepo.(service.SnapshotHashChainStore); ok {
+		srv.SetSnapshotIntegrityService(service.NewSnapshotIntegrityService(storeRepo, chain))
+	}
+	priceStore, _ := storeRepo.(service.PriceHistoryStore)
+	if lineageStore, ok := storeRepo.(service.LineageStore); ok {
+		lineage := service.NewLineageService(lineageStore, storeRepo)
+		if runStore, ok := storeRepo.(service.CalculationRunStore); ok {
+			lineage.SetCalculationRuns(runStore)
+		}
+		if priceStore != nil {
+			lineage.SetPriceHistory(priceStore)
+		}
+		lineage.SetCodeVersion(postgres.CodeVersion{Version: Version, GitCommit: GitCommit, BuildTime: BuildTime})
+		srv.SetLineageService(lineage)
+	}
+	freshness := service.NewDataFreshnessService(storeRepo)
+	if runStore, ok := storeRepo.(service.CalculationRunStore); ok {
+		freshness.SetCalculationRuns(runStore)
+	}
+	srv.SetDataFreshnessService(freshness)
+	srv.SetStateService(service.NewStateService(repo, priceStore, newStateConfig(cfg)))
+	calendar := newAccountingCalendar(cfg.Business.AccountingTimeZone)
+	costSvc.SetCalendar(calendar)
+	datasetSvc := service.NewDatasetExportService(repo, []byte(cfg.Security.DatasetPseudonymKey))
+	datasetSvc.SetCalendar(calendar)
+	srv.SetDatasetExportService(datasetSvc)
+	costImport := service.NewCostImportService(repo)
+	costImport.SetCalendar(calendar)
+	srv.SetCostImportService(costImport)
+	exportJobs := service.NewExportJobService(datasetSvc, cfg.Export.Dir, cfg.Export.Retention)
+	go exportJobs.Run(context.Background(), cfg.Export.Workers, func(err error) { log.Printf("WARN: export jobs: %v", err) })
+	srv.SetExportJobService(exportJobs)
+	targetSvc := service.NewEfficiencyTargetService(repo)
+	targetSvc.SetCalendar(calendar)
+	srv.SetEfficiencyTargetService(targetSvc)
+	preferences := service.NewPreferenceService(repo)
+	preferences.SetDefaultCurrency(cfg.Notifier.Digest.Currency)
+	srv.SetPreferenceService(preferences)
+	fiscalSvc := service.NewFiscalReportService(repo, newFiscalCalendar(cfg.Business.Fiscal))
+	fiscalSvc.SetCalendar(calendar)
+	srv.SetFiscalReportService(fiscalSvc)
+	if periodStore, ok := storeRepo.(service.AccountingPeriodStore); ok {
+		periods := service.NewPeriodCloseService(periodStore, newFiscalCalendar(cfg.Business.Fiscal))
+		periods.SetCalendar(calendar)
+		srv.SetPeriodCloseService(periods)
+		fiscalSvc.SetAdjustments(periodStore)
+	}
+	roiComparison := service.NewROIComparisonService(repo)
+	roiComparison.SetCalendar(calendar)
+	srv.SetROIComparisonService(roiComparison)
+	workloadSvc := service.NewWorkloadService(repo)
+	catalog := service.NewCatalogService(repo, service.DefaultMockSLOStatus(), teamNamespaces(cfg))
+	if rollups, ok := storeRepo.(service.DailyWorkloadStatLister); ok {
+		workloadSvc.SetDailyRollups(rollups)
+	}
+	if gradeStore, ok := storeRepo.(service.GradeHistoryStore); ok {
+		srv.SetGradeHistoryService(service.NewGradeHistoryService(gradeStore))
+		workloadSvc.SetGradeHistory(gradeStore)
+		catalog.SetGradeHistory(gradeStore)
+	}
+	if regradeStore, ok := storeRepo.(service.SnapshotRegradeStore); ok {
+		regrade := service.NewRegradeService(repo, regradeStore, newGradeThresholds(cfg))
+		if cfg.Business.GradeView != "" {
+			if err := regrade.SetDefaultView(cfg.Business.GradeView); err != nil {
+				log.Printf("WARN: grade view: %v", err)
+			}
+		}
+		srv.SetRegradeService(regrade)
+	}
+	if recStore, ok := storeRepo.(service.RecommendationLister); ok {
+		srv.SetRecommendationAdoptionService(service.NewRecommendationAdoptionService(recStore))
+	}
+	if accountStore, ok := storeRepo.(service.ServiceAccountStore); ok {
+		accounts := service.NewServiceAccountService(accountStore)
+		accounts.SetBootstrapKey(cfg.Security.APIKeys.BootstrapKey)
+		srv.SetServiceAccountService(accounts)
+	} else if cfg.Security.APIKeys.Required {
+		log.Fatalf("security.api_keys.required is set but storage driver %q has no service accounts; every API request would be rejected", cfg.Storage.Driver)
+	}
+	authorizer, err := newAuthorizer(cfg.Security.Authz)
+	if err != nil {
+		log.Fatalf("authz: %v", err)
+	}
+	if authorizer != nil {
+		srv.SetAuthorizer(authorizer)
+	}
+	// 工作负载目录是每个副本各自的内存索引，用进程内锁使每个副本都刷新
+	catalogJobs := &etl.Scheduler{
+		Locker:   lock.NewMemoryBackend().Locker("server"),
+		LockName: "workload-catalog",
+		Jobs:     []etl.Job{{Name: "workload-catalog", Interval: 5 * time.Minute, Run: catalog.Refresh}},
+		OnError:  logJobError,
+	}
+	go startScheduler(catalogJobs)
+	srv.SetCatalogService(catalog)
+	// 节点清单与成本策略注解：kubernetes.client=cluster 时经 client-go 访问集群，否则（及演示模式）用 K8s mock，按 kubernetes.retry 重试
+	var k8sBase k8s.Client = k8s.NewMockClient(k8sMock)
+	k8sTarget := "mock"
+	if cfg.Kubernetes.Client == k8s.ClientCluster && !*demoMode {
+		if live, err := k8s.NewClusterClient(newClusterConfig(cfg.Kubernetes)); err != nil {
+			log.Printf("WARN: kubernetes cluster client failed, using mock: %v", err)
+		} else {
+			k8sBase, k8sTarget = live, clusterTarget(cfg.Kubernetes)
+		}
+	}
+	k8sClient := newK8sClient(k8sBase, k8sTarget, drills, newRetrier(cfg.Kubernetes.Retry), breakers.kubernetes)
+	workloadSvc.SetCostPolicies(&k8s.CostPolicyResolver{Client: k8sClient})
+	metadata := k8s.NewMetadataResolver(k8sClient)
+	if cfg.Kubernetes.MetadataCacheTTL > 0 {
+		metadata.SetCacheTTL(cfg.Kubernetes.MetadataCacheTTL)
+	}
+	costSvc.SetMetadata(metadata)
+	// namespace 生命周期（first_seen / last_seen / deleted_at）与工作负载目录同频同步，成本视图据此标注已终止的 namespace；
+	// 它写入存储，因此与其他写任务一样只在 leader 上执行
+	if lifecycles, ok := storeRepo.(etl.NamespaceLifecycleStore); ok {
+		namespaceSync := &etl.NamespaceSyncWorker{K8s: k8sClient, Store: lifecycles}
+		scheduler.Jobs = append(scheduler.Jobs, namespaceSync.Job(5*time.Minute))
+	}
+	if c := cfg.Kubernetes.CostAnnotations; c.Enabled {
+		scheduler.Jobs = append(scheduler.Jobs, costAnnotationJob(c, repo, storeRepo, k8sClient, newGradeThresholds(cfg)))
+	}
+	workloadSvc.SetReliabilitySources(k8sClient, service.DefaultMockSLOStatus())
+	// 限流指标暂用 Prometheus mock 客户端（Phase3）
+	safety := cfg.Business.RightsizingSafety
+	promClient := newPrometheusClient(prometheus.NewMockClient(promMock), "mock", drills, newRetrier(cfg.Prometheus.Retry), breakers.prometheus)
+	throttling := promClient
+	if cfg.Security.QueryBudgets.Enabled {
+		throttling = prometheus.NewBudgetClient(throttling)
+	}
+	rightsizing := service.NewRightsizingSafety(throttling, service.DefaultMockSLOStatus(), costmodel.SafetyLimits{
+		ThrottlingFlagRate:     safety.ThrottlingFlagRate,
+		ThrottlingSuppressRate: safety.ThrottlingSuppressRate,
+		MinErrorBudget:         safety.MinErrorBudget,
+	})
+	workloadSvc.SetRightsizingSafety(rightsizing)
+	srv.SetWorkloadService(workloadSvc)
+	var dependencies *service.DependencyService
+	if depStore, ok := storeRepo.(service.DependencyStore); ok {
+		dependencies = newDependencyService(cfg.Business.Dependencies, depStore, repo)
+		srv.SetDependencyService(dependencies)
+	}
+	if timelineStore, ok := storeRepo.(service.TimelineStore); ok {
+		timeline := service.NewTimelineService(timelineStore)
+		if dependencies != nil {
+			timeline.SetDependencies(dependencies)
+		}
+		timeline.SetEventSource(k8sClient)
+		if runStore, ok := storeRepo.(service.CalculationRunStore); ok {
+			timeline.SetCalculationRuns(runStore)
+		}
+		if priceStore != nil {
+			timeline.SetPriceHistory(priceStore)
+		}
+		if bus != nil {
+			timeline.SetPublisher(bus)
+		}
+		srv.SetTimelineService(timeline)
+	}
+	if c := cfg.Kubernetes.Admission; c.Enabled {
+		admission := service.NewAdmissionService(repo, prices.CPUPricePerCoreHour, prices.MemPricePerGBHour)
+		admission.SetBudgets(teamBudgets(cfg), c.NamespaceBudgets)
+		if err := admission.SetBudgetAction(c.BudgetAction); err != nil {
+			log.Printf("WARN: admission: %v", err)
+		}
+		srv.SetAdmissionService(admission)
+	}
+	nodeAnalysis := service.NewNodeAnalysisService(k8sClient, repo, prices.CPUPricePerCoreHour, prices.MemPricePerGBHour)
+	nodeAnalysis.SetNodePoolLabels(cfg.Business.NodePoolLabels)
+	nodeAnalysis.SetNonFinitePolicy(nonFinite)
+	nodeAnalysis.SetDisruptionBudgets(k8sClient, k8sClient)
+	srv.SetNodeAnalysisService(nodeAnalysis)
+	srv.SetSLOCostService(service.NewSLOCostService(repo, service.DefaultMockSLOStatus(), prices.CPUPricePerCoreHour, prices.MemPricePerGBHour,
+		cfg.Business.SLO.AvailabilityThreshold, float64(cfg.Business.SLO.LatencyP95Threshold)))
+	capacity := service.NewCapacityService(k8sClient, repo, prices.CPUPricePerCoreHour, prices.MemPricePerGBHour)
+	capacity.SetNodePoolLabels(cfg.Business.NodePoolLabels)
+	capacity.SetPodLister(k8sClient)
+	srv.SetCapacityService(capacity)
+	recStore, hasRecs := storeRepo.(service.RecommendationLister)
+	if records, ok := storeRepo.(service.OptimizationRecordStore); ok && hasRecs {
+		manifests := service.NewRecommendationExportService(recStore, records)
+		manifests.SetPodLister(k8sClient)
+		srv.SetRecommendationExportService(manifests)
+	}
+	srv.SetOffHoursService(service.NewOffHoursService(repo, newBusinessHours(cfg.Business.OffHours), cfg.Business.OffHours.IdleUtilization))
+	// 共享资源用量暂用 Prometheus mock 客户端（Phase3）
+	allocationPreview := service.NewAllocationPreviewService(repo, newSharedResources(cfg.Business.SharedCosts), prometheus.NewMockClient(promMock))
+	allocationPreview.SetCalendar(calendar)
+	srv.SetAllocationPreviewService(allocationPreview)
+	daily := &etl.DailyWorker{Repo: repo, Shared: newSharedResources(cfg.Business.SharedCosts), Usage: prometheus.NewMockClient(promMock), Calendar: calendar}
+	scheduler.Jobs = append(scheduler.Jobs, daily.Job(0))
+	if r := cfg.Retention.Postgres; r.CostHistory > 0 {
+		retention := &etl.RetentionWorker{Repo: repo, SnapshotRetention: r.CostHistory, HourlyStatRetention: r.HourlyStats, ArchiveRetention: cfg.Archive.Retention}
+		if downsampler, ok := storeRepo.(etl.HourlyStatDownsampler); ok {
+			retention.HourlyStats = downsampler
+		}
+		if cfg.Archive.Enabled {
+			archiver, err := newArchiver(cfg.Archive)
+			if err != nil {
+				// 不归档就删除过期快照会丢数据
+				log.Fatalf("archive: %v", err)
+			}
+			retention.Archiver = archiver
+		}
+		scheduler.Jobs = append(scheduler.Jobs, retention.Job(0))
+	}
+	dispatcher := newDispatcher(cfg.Notifier, cfg.I18n.Locale())
+	if policyStore, ok := storeRepo.(service.NotificationPolicyStore); ok {
+		policy := service.NewNotificationPolicyService(policyStore)
+		policy.SetTeams(teamNamespaces(cfg))
+		if dispatcher != nil {
+			dispatcher.SetFilter(policy)
+		}
+		srv.SetNotificationPolicyService(policy)
+	}
+	var alertRules *service.AlertRuleService
+	if ruleStore, ok := storeRepo.(service.AlertRuleStore); ok {
+		alertRules = service.NewAlertRuleService(ruleStore, repo)
+		alertRules.SetSLOStatus(service.DefaultMockSLOStatus())
+		alertRules.SetTeamBudgets(teamBudgets(cfg))
+		alertRules.SetCalendar(calendar)
+		if dispatcher != nil {
+			alertRules.SetNotifier(dispatcher)
+		}
+		// 每分钟检查一次，各规则按自己的 interval 评估
+		scheduler.Jobs = append(scheduler.Jobs, etl.Job{Name: "alert-rules", Interval: time.Minute, Run: func(ctx context.Context) error {
+			_, err := alertRules.EvaluateDue(ctx)
+			return err
+		}})
+		if bus != nil {
+			alertRules.SetPublisher(bus, cfg.Notifier.Digest.Currency)
+			scheduler.Jobs = append(scheduler.Jobs, etl.Job{Name: "budget-events", Interval: time.Hour, Run: func(ctx context.Context) error {
+				_, err := alertRules.CheckBudgets(ctx)
+				return err
+			}})
+		}
+		srv.SetAlertRuleService(alertRules)
+	}
+	// 云账单按账期拉取写入 cost_bill_account_summary；节点级单价覆盖尚无消费方，暂不计算
+	if cfg.CloudBilling.Enabled {
+		if job, err := newBillIngestJob(cfg, storeRepo); err != nil {
+			log.Printf("WARN: cloud bill ingestion disabled: %v", err)
+		} else {
+			scheduler.Jobs = append(scheduler.Jobs, job)
+		}
+	}
+	// 已下发建议的采纳情况每日对账；周度摘要按团队合并归属人、建议引擎与已启用的通知渠道投递
+	var recommendations etl.RecommendationStore
+	if recs, ok := storeRepo.(etl.RecommendationStore); ok {
+		recommendations = recs
+		reconciler := &etl.RecommendationReconciler{Repo: repo, Store: recs}
+		scheduler.Jobs = append(scheduler.Jobs, reconciler.Job(0))
+	}
+	if cfg.Notifier.Digest.Enabled {
+		if sender := newDigestSender(cfg.Notifier, dispatcher); sender != nil {
+			digest := &etl.DigestWorker{
+				Repo:            repo,
+				Teams:           digestTeams(cfg),
+				Owners:          notifier.StaticOwnership(cfg.Notifier.Email.Owners),
+				Sender:          sender,
+				Policies:        &k8s.CostPolicyResolver{Client: k8sClient},
+				Targets:         targetSvc,
+				Recommendations: recommendations,
+				Safety:          rightsizing,
+				Currency:        cfg.Notifier.Digest.Currency,
+				DashboardURL:    cfg.Notifier.Digest.DashboardURL,
+				Locale:          cfg.I18n.Locale(),
+				Calendar:        calendar,
+				Fiscal:          newFiscalCalendar(cfg.Business.Fiscal),
+			}
+			if bus != nil {
+				digest.Publisher = bus
+			}
+			scheduler.Jobs = append(scheduler.Jobs, digest.Job(cfg.Notifier.Digest.Interval))
+		} else {
+			log.Printf("WARN: notifier.digest is enabled but no email or chat channel is configured, digests are not sent")
+		}
+	}
+	onboarding := service.NewOnboardingService(repo, targetSvc)
+	if alertRules != nil {
+		onboarding.SetAlertRules(alertRules)
+	}
+	onboarding.SetAvailabilityThreshold(cfg.Business.SLO.AvailabilityThreshold)
+	onboarding.SetFallbackOwnership(notifier.StaticOwnership(cfg.Notifier.Email.Owners))
+	onboardingDefaults := cfg.Business.Onboarding
+	if err := onboarding.SetDefaults(service.OnboardingDefaults{
+		MonthlyBudget:    onboardingDefaults.DefaultMonthlyBudget,
+		EfficiencyTarget: onboardingDefaults.DefaultEfficiencyTarget,
+		SLOTemplates:     onboardingDefaults.SLOTemplates,
+	}); err != nil {
+		log.Printf("WARN: onboarding: %v", err)
+	}
+	srv.SetOnboardingService(onboarding)
+	// 看门狗读取底层存储，降级缓存中的旧数据不应掩盖管道停滞
+	watchdog := service.NewStaleDataWatchdog(storeRepo, cfg.Business.CostCalculation.CalculationInterval, cfg.Business.StaleData.Factor)
+	if dispatcher != nil {
+		watchdog.SetNotifier(dispatcher)
+	}
+	scheduler.Jobs = append(scheduler.Jobs, etl.Job{Name: "stale-data-watchdog", Interval: jobInterval(prices.CalculationInterval, time.Hour), Run: func(ctx context.Context) error {
+		_, err := watchdog.Check(ctx)
+		return err
+	}})
+	metricsExporter.AddCollector(exporter.FreshnessCollector(watchdog))
+	if c := cfg.Server.Canary; c.Enabled {
+		canary, err := newPipelineCanary(cfg, repo)
+		if err != nil {
+			log.Fatalf("canary: %v", err)
+		}
+		if dispatcher != nil {
+			canary.Notifier = dispatcher
+		}
+		scheduler.Jobs = append(scheduler.Jobs, canary.Job(c.Interval))
+		metricsExporter.AddCollector(exporter.CanaryCollector(canary))
+	}
+	go startScheduler(scheduler)
 	if err := srv.StartWithGracefulShutdown(); err != nil {
 		log.Fatal(err)
 	}
+	if closer, ok := rawRepo.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("WARN: close storage: %v", err)
+		}
+	}
+}
+
+// newClusterConfig 把 kubernetes 段转换为 client-go 客户端配置。
+func newClusterConfig(c config.KubernetesConfig) k8s.ClusterConfig {
+	return k8s.ClusterConfig{
+		InCluster:       c.InCluster,
+		Kubeconfig:      c.Kubeconfig,
+		Context:         c.Context,
+		APIServer:       c.APIServer,
+		BearerTokenFile: c.BearerTokenFile,
+		ReadOnly:        c.RBAC.ReadOnlyAccess,
+		Namespace:       c.Namespace,
+		NamespaceScoped: c.RBAC.NamespaceScoped,
+	}
+}
+
+// newStorageConfig 按 storage.driver 组装存储配置：file 使用 file_path 与合并落盘间隔（默认 1s），postgres 使用 postgres 段的连接串与连接池。
+func newStorageConfig(cfg *config.Config) storage.Config {
+	sc := storage.Config{Driver: cfg.Storage.Driver, DSN: cfg.Storage.FilePath, FlushInterval: cfg.Storage.FileFlushInterval}
+	if sc.FlushInterval == 0 {
+		sc.FlushInterval = time.Second
+	}
+	if sc.Driver == storage.DriverPostgres {
+		pg := cfg.Postgres
+		sc.DSN = pg.DSN()
+		sc.Postgres = postgres.SQLConfig{
+			MaxOpenConns:    pg.MaxOpenConns,
+			MaxIdleConns:    pg.MaxIdleConns,
+			ConnMaxLifetime: pg.ConnMaxLifetime,
+			Migrate:         pg.AutoMigrate,
+		}
+	}
+	return sc
+}
+
+func newBreakerSets(c config.CircuitBreakerConfig) externalBreakers {
+	bc := breaker.Config{
+		FailureRateThreshold: c.FailureRateThreshold,
+		MinRequests:          c.MinRequests,
+		Window:               c.Window,
+		OpenTimeout:          c.OpenTimeout,
+		HalfOpenProbes:       c.HalfOpenProbes,
+	}
+	return externalBreakers{prometheus: breaker.NewSet("prometheus", bc), kubernetes: breaker.NewSet("kubernetes", bc)}
+}
+
+// externalBreakers 外部数据源的熔断器，未启用熔断时为 nil。
+type externalBreakers struct {
+	prometheus *breaker.Set
+	kubernetes *breaker.Set
+}
+
+// k8sAPI server 使用的 K8s 客户端能力，k8s 的 Chaos / Retry / Breaker 包装均满足。
+type k8sAPI interface {
+	k8s.Client
+	k8s.WorkloadAnnotator
+	k8s.DisruptionBudgetLister
+}
+
+// newK8sClient 由内到外按故障注入 → 重试 → 熔断包装 base：重试耗尽的失败才计入熔断，演练的故障与真实故障一样经过两者。
+// breakers 为 nil 时不熔断。
+func newK8sClient(base k8s.Client, target string, drills *chaos.Controller, retrier *retry.Retrier, breakers *breaker.Set) k8sAPI {
+	if drills != nil {
+		base = k8s.NewChaosClient(base, drills)
+	}
+	var c k8sAPI = k8s.NewRetryClient(base, retrier)
+	if breakers != nil {
+		c = k8s.NewBreakerClient(c, target, breakers)
+	}
+	return c
+}
+
+// newPrometheusClient 与 newK8sClient 相同，包装 Prometheus 客户端。
+func newPrometheusClient(base prometheus.Client, target string, drills *chaos.Controller, retrier *retry.Retrier, breakers *breaker.Set) prometheus.Client {
+	if drills != nil {
+		base = prometheus.NewChaosClient(base, drills)
+	}
+	var c prometheus.Client = prometheus.NewRetryClient(base, retrier)
+	if breakers != nil {
+		c = prometheus.NewBreakerClient(c, target, breakers)
+	}
+	return c
+}
+
+// clusterTarget 集群客户端的熔断 target：in-cluster 或 kubeconfig 路径。
+func clusterTarget(c config.KubernetesConfig) string {
+	if c.InCluster {
+		return "in-cluster"
+	}
+	if c.Kubeconfig != "" {
+		return c.Kubeconfig
+	}
+	return "kubeconfig"
+}
+
+// newPipelineCanary 按 server.canary 创建管道合成监控：与小时级 ETL 相同的单价与计算器写入 repo，经本机（或 base_url）API 读回。
+func newPipelineCanary(cfg *config.Config, repo postgres.Repository) (*etl.PipelineCanary, error) {
+	c, prices := cfg.Server.Canary, cfg.Business.CostCalculation
+	calc, err := costmodel.NewCalculator(prices.Calculator, prices.CalculatorOptions)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://127.0.0.1:%d", cfg.Server.Port)
+	}
+	opts := []client.Option{client.WithHTTPClient(&http.Client{Timeout: 30 * time.Second})}
+	if c.APIKey != "" {
+		opts = append(opts, client.WithHeader("X-API-Key", c.APIKey))
+	}
+	return &etl.PipelineCanary{
+		Store:               repo,
+		API:                 client.New(baseURL, opts...),
+		CPUPricePerCoreHour: prices.CPUPricePerCoreHour,
+		MemPricePerGBHour:   prices.MemPricePerGBHour,
+		Calculator:          calc,
+		MaxLatency:          c.MaxLatency,
+	}, nil
+}
+
+// newRetrier 按客户端的 retry 配置创建重试器，0 值字段取 retry.DefaultPolicy。
+func newRetrier(c config.RetryConfig) *retry.Retrier {
+	return retry.New(retry.Policy{
+		MaxAttempts:    c.MaxAttempts,
+		InitialBackoff: c.InitialBackoff,
+		MaxBackoff:     c.MaxBackoff,
+		Multiplier:     c.Multiplier,
+		Jitter:         c.Jitter,
+	})
+}
+
+// newQueryBudgets 按 security.query_budgets 创建按调用方的查询预算账本。
+func newQueryBudgets(c config.QueryBudgetConfig) *querybudget.Ledger {
+	quotas := make(map[string]querybudget.Quota, len(c.Consumers))
+	for consumer, q := range c.Consumers {
+		quotas[consumer] = querybudget.Quota{PrometheusQueries: q.PrometheusQueries, DatabaseQueries: q.DatabaseQueries}
+	}
+	return querybudget.NewLedger(c.Window, querybudget.Quota{PrometheusQueries: c.PrometheusQueries, DatabaseQueries: c.DatabaseQueries}, quotas)
+}
+
+// newChaosController 按 chaos 配置创建故障演练控制器，场景按数据源转换为 chaos.Fault，未配置故障的数据源不受影响。
+func newChaosController(c config.ChaosConfig) *chaos.Controller {
+	scenarios := make(map[string]chaos.Scenario, len(c.Scenarios))
+	for name, sc := range c.Scenarios {
+		faults := chaos.Scenario{}
+		for source, f := range map[chaos.Source]config.ChaosFaultConfig{chaos.Prometheus: sc.Prometheus, chaos.Kubernetes: sc.Kubernetes, chaos.Database: sc.Database} {
+			if fault := chaos.Fault(f); !fault.IsZero() {
+				faults[source] = fault
+			}
+		}
+		scenarios[name] = faults
+	}
+	return chaos.NewController(scenarios, c.MaxDuration)
+}
+
+// newDependencyService 创建服务依赖图；配置了静态依赖时导入，替换此前的静态依赖（未配置时保留经 API 导入的）。
+func newDependencyService(c config.DependencyConfig, store service.DependencyStore, repo postgres.Repository) *service.DependencyService {
+	svc := service.NewDependencyService(store, repo, c.TracingTTL)
+	if len(c.Static) == 0 {
+		return svc
+	}
+	req := dto.DependencyIngestRequest{Source: postgres.DependencySourceStatic}
+	for _, e := range c.Static {
+		callerNS, caller, _ := strings.Cut(e.Caller, "/")
+		calleeNS, callee, _ := strings.Cut(e.Callee, "/")
+		req.Edges = append(req.Edges, dto.DependencyEdgeInput{CallerNamespace: callerNS, Caller: caller, CalleeNamespace: calleeNS, Callee: callee})
+	}
+	if _, err := svc.Ingest(context.Background(), req); err != nil {
+		log.Printf("WARN: static dependencies: %v", err)
+	}
+	return svc
+}
+
+// newSelfSLOService 按 server.self_slo 为自身 API 路由创建 SLO 评估，默认目标为 0 时取 business.slo 的阈值。
+func newSelfSLOService(cfg *config.Config, rec *apimetrics.Recorder) *service.SelfSLOService {
+	c := cfg.Server.SelfSLO
+	availability, latency := c.AvailabilityTarget, c.LatencyTargetMs
+	if availability == 0 {
+		availability = cfg.Business.SLO.AvailabilityThreshold
+	}
+	if latency == 0 {
+		latency = float64(cfg.Business.SLO.LatencyP95Threshold)
+	}
+	svc := service.NewSelfSLOService(rec, availability, latency)
+	svc.SetWindow(c.Window)
+	targets := make(map[string]service.SelfSLOTarget, len(c.Routes))
+	for route, t := range c.Routes {
+		targets[route] = service.SelfSLOTarget{AvailabilityTarget: t.AvailabilityTarget, LatencyTargetMs: t.LatencyTargetMs}
+	}
+	svc.SetRouteTargets(targets)
+	return svc
+}
+
+// newStateConfig 导出状态中由配置管理的部分（SLO、团队预算与归属）。
+func newStateConfig(cfg *config.Config) service.StateConfig {
+	teams := make(map[string]dto.TeamState, len(cfg.Notifier.Digest.Teams))
+	for name, t := range cfg.Notifier.Digest.Teams {
+		teams[name] = dto.TeamState{Namespaces: t.Namespaces, Recipients: t.Recipients, MonthlyBudget: t.MonthlyBudget}
+	}
+	return service.StateConfig{
+		Environment: string(cfg.Env),
+		SLO: &dto.SLOConfigState{
+			AvailabilityThreshold: cfg.Business.SLO.AvailabilityThreshold,
+			LatencyP95ThresholdMs: cfg.Business.SLO.LatencyP95Threshold,
+		},
+		Teams:  teams,
+		Owners: cfg.Notifier.Email.Owners,
+	}
+}
+
+// teamBudgets 取摘要配置中的团队归属与月度预算，供告警规则的 budget_used 指标使用。
+func teamBudgets(cfg *config.Config) map[string]service.TeamBudget {
+	budgets := make(map[string]service.TeamBudget, len(cfg.Notifier.Digest.Teams))
+	for name, t := range cfg.Notifier.Digest.Teams {
+		budgets[name] = service.TeamBudget{Namespaces: t.Namespaces, MonthlyBudget: t.MonthlyBudget}
+	}
+	return budgets
+}
+
+// newAuthorizer 按 security.authz 创建外部授权钩子；未启用时返回 nil。
+func newAuthorizer(c config.AuthzConfig) (authz.Authorizer, error) {
+	switch c.Mode {
+	case "webhook":
+		return authz.NewWebhook(authz.WebhookConfig{URL: c.WebhookURL, Token: c.WebhookToken, Timeout: c.Timeout, CacheTTL: c.CacheTTL}), nil
+	case "plugin":
+		return authz.OpenPlugin(c.PluginPath)
+	}
+	return nil, nil
+}
+
+// newScheduler 创建定时任务调度器。postgres 存储以 advisory lock 选主，多副本中只有持锁的副本执行任务；
+// 其他驱动的数据只在本进程内，使用进程内锁。完成的时间槽记录在 metadata，重启后不会重复执行同一时间槽。
+func newScheduler(rawRepo, repo postgres.Repository) *etl.Scheduler {
+	var locker lock.Locker = lock.NewMemoryBackend().Locker("server")
+	if sqlRepo, ok := rawRepo.(*postgres.SQLRepository); ok {
+		locker = lock.NewPGAdvisoryLocker(sqlRepo.DB(), "")
+	}
+	return &etl.Scheduler{
+		Locker:   locker,
+		LockName: "etl-scheduler",
+		Recorder: &etl.MetadataRunRecorder{Repo: repo},
+		OnError:  logJobError,
+	}
+}
+
+// startScheduler 运行调度器直到进程退出。
+func startScheduler(s *etl.Scheduler) {
+	if err := s.Start(context.Background()); err != nil {
+		log.Printf("WARN: scheduler %s: %v", s.LockName, err)
+	}
+}
+
+func logJobError(job string, err error) { log.Printf("WARN: %s: %v", job, err) }
+
+// jobInterval 返回 interval，未配置时取 fallback。
+func jobInterval(interval, fallback time.Duration) time.Duration {
+	if interval <= 0 {
+		return fallback
+	}
+	return interval
+}
+
+// calculationJob 每个 interval（默认 1 小时）对上一个完整窗口执行一次成本计算。流水线失败已记录在执行记录中，
+// 经 POST /api/v1/calculations/runs/:id/retrigger 重跑，不让调度器在同一时间槽内反复执行；只有记录失败时任务才失败。
+func calculationJob(runs *service.CalculationRunService, interval time.Duration) etl.Job {
+	interval = jobInterval(interval, time.Hour)
+	return etl.Job{
+		Name:     "hourly-calculation",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			end := time.Now().Truncate(interval)
+			run, err := runs.Execute(ctx, service.TriggerSchedule, end.Add(-interval), end)
+			if err != nil && run != nil {
+				log.Printf("WARN: hourly-calculation: %v", err)
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+// costAnnotationJob 按 interval（默认 1 小时）把成本与效率等级写回工作负载注解。
+func costAnnotationJob(c config.CostAnnotationConfig, repo, storeRepo postgres.Repository, annotator k8s.WorkloadAnnotator, thresholds costmodel.GradeThresholds) etl.Job {
+	worker := &etl.CostAnnotationWorker{Repo: repo, Annotator: annotator, Thresholds: thresholds, Namespaces: c.Namespaces}
+	if rollups, ok := storeRepo.(etl.DailyWorkloadStatLister); ok {
+		worker.Rollups = rollups
+	}
+	if grades, ok := storeRepo.(etl.GradeEventLister); ok {
+		worker.Grades = grades
+	}
+	return worker.Job(jobInterval(c.Interval, time.Hour))
+}
+
+// newArchiver 按 archive 段创建对象存储归档。
+func newArchiver(c config.ArchiveConfig) (*objectstore.Archiver, error) {
+	client, err := objectstore.NewS3Client(objectstore.Config{
+		Endpoint:        c.Endpoint,
+		Region:          c.Region,
+		Bucket:          c.Bucket,
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+		UsePathStyle:    c.UsePathStyle,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objectstore.NewArchiver(client, c.Prefix), nil
+}
+
+// newDispatcher 按 notifier 配置注册已启用的渠道作为兜底路由（Slack 的按告警类型频道覆盖保留），消息模板使用 locale；
+// 没有启用任何渠道时返回 nil。
+func newDispatcher(c config.NotifierConfig, locale i18n.Locale) *notifier.Dispatcher {
+	var drivers []notifier.Driver
+	if c.Slack.Enabled && c.Slack.WebhookURL != "" {
+		routes := make(map[notifier.AlertType]notifier.SlackRoute, len(c.Slack.Channels))
+		for alertType, channel := range c.Slack.Channels {
+			routes[notifier.AlertType(alertType)] = notifier.SlackRoute{Channel: channel}
+		}
+		drivers = append(drivers, notifier.NewSlackDriver(notifier.SlackConfig{
+			WebhookURL: c.Slack.WebhookURL, Channel: c.Slack.Channel, Username: c.Slack.Username, Routes: routes,
+		}))
+	}
+	if c.DingTalk.Enabled && c.DingTalk.WebhookURL != "" {
+		drivers = append(drivers, notifier.NewDingTalkDriver(notifier.DingTalkConfig{
+			WebhookURL: c.DingTalk.WebhookURL, Secret: c.DingTalk.Secret, AtMobiles: c.DingTalk.AtMobiles,
+		}))
+	}
+	if c.WeCom.Enabled && c.WeCom.WebhookURL != "" {
+		drivers = append(drivers, notifier.NewWeComDriver(notifier.WeComConfig{WebhookURL: c.WeCom.WebhookURL}))
+	}
+	if c.Paging.PagerDutyEnabled && c.Paging.PagerDutyRoutingKey != "" {
+		drivers = append(drivers, notifier.PagingDriver{Pager: notifier.NewPagerDutyPager(notifier.PagerDutyConfig{RoutingKey: c.Paging.PagerDutyRoutingKey})})
+	}
+	if c.Paging.OpsgenieEnabled && c.Paging.OpsgenieAPIKey != "" {
+		drivers = append(drivers, notifier.PagingDriver{Pager: notifier.NewOpsgeniePager(notifier.OpsgenieConfig{APIKey: c.Paging.OpsgenieAPIKey, APIURL: c.Paging.OpsgenieAPIURL})})
+	}
+	if len(drivers) == 0 {
+		return nil
+	}
+	dc := notifier.DefaultDispatcherConfig()
+	if c.RateLimitPerMinute > 0 {
+		dc.RateLimitPerMinute = c.RateLimitPerMinute
+	}
+	if c.MaxRetries > 0 {
+		dc.MaxRetries = c.MaxRetries
+	}
+	if c.RetryDelay > 0 {
+		dc.RetryDelay = c.RetryDelay
+	}
+	d := notifier.NewDispatcher(dc, notifier.LocalizedTemplates(locale))
+	d.RouteDefault(drivers...)
+	return d
+}
+
+// newEventBus 创建经 Kafka REST Proxy 投递的事件总线；配置 spool.dir 时 outbox 落盘到其 events 子目录
+// （spool 只读取目录下的文件，不会把事件当作写库失败的批次回放），否则保存在内存中。
+func newEventBus(cfg *config.Config) (*eventbus.Bus, error) {
+	c := cfg.EventBus
+	producer, err := eventbus.NewRESTProxyProducer(eventbus.RESTProxyConfig{URL: c.RESTProxyURL, Username: c.Username, Password: c.Password})
+	if err != nil {
+		return nil, err
+	}
+	busConfig := eventbus.Config{Topic: c.Topic, MaxRetries: c.MaxRetries, OutboxSize: c.OutboxSize, RetryDelay: eventbus.DefaultConfig().RetryDelay}
+	if cfg.Spool.Dir != "" {
+		if busConfig.Outbox, err = eventbus.NewSpoolOutbox(filepath.Join(cfg.Spool.Dir, "events")); err != nil {
+			return nil, err
+		}
+	}
+	return eventbus.New(producer, busConfig), nil
+}
+
+// newSnapshotRepository 在事件总线启用时为保存的快照发布 SnapshotCreated；存储的单价历史保留给
+// 快照计算标注单价版本。
+func newSnapshotRepository(repo postgres.Repository, bus *eventbus.Bus) postgres.Repository {
+	if bus == nil {
+		return repo
+	}
+	publishing := &eventbus.PublishingRepository{Repository: repo, Bus: bus}
+	if prices, ok := repo.(service.PriceHistoryStore); ok {
+		return struct {
+			*eventbus.PublishingRepository
+			service.PriceHistoryStore
+		}{publishing, prices}
+	}
+	return publishing
+}
+
+// newBillIngestJob 创建定时拉取云账单的任务；provider 未实现或存储不支持账单汇总时返回错误。
+func newBillIngestJob(cfg *config.Config, repo postgres.Repository) (etl.Job, error) {
+	b, prices := cfg.CloudBilling, cfg.Business.CostCalculation
+	fetcher := cloudbilling.NewFetcher(cloudbilling.CloudBillingConfig{
+		Provider:        b.Provider,
+		Endpoint:        b.Endpoint,
+		PeriodType:      b.PeriodType,
+		AccessKeyID:     b.AccessKeyID,
+		AccessKeySecret: b.AccessKeySecret,
+	})
+	if fetcher == nil {
+		return etl.Job{}, fmt.Errorf("cloud billing provider %q is not supported", b.Provider)
+	}
+	store, ok := repo.(etl.BillSummaryStore)
+	if !ok {
+		return etl.Job{}, fmt.Errorf("storage driver does not store bill summaries")
+	}
+	worker := &etl.BillIngestWorker{
+		Fetcher:             fetcher,
+		Store:               store,
+		AccountID:           b.AccountID,
+		PeriodType:          b.PeriodType,
+		CPUPricePerCoreHour: prices.CPUPricePerCoreHour,
+		MemPricePerGBHour:   prices.MemPricePerGBHour,
+	}
+	return worker.Job(b.Interval), nil
+}
+
+// newDigestSender 组合 SMTP（notifier.email 启用时）与聊天渠道 dispatcher 投递周度摘要；两者都没有时返回 nil。
+func newDigestSender(c config.NotifierConfig, dispatcher *notifier.Dispatcher) *notifier.DigestSender {
+	sender := &notifier.DigestSender{Dispatcher: dispatcher}
+	if e := c.Email; e.Enabled && e.Host != "" {
+		sender.Email = notifier.NewSMTPSender(notifier.SMTPConfig{
+			Host: e.Host, Port: e.Port, Username: e.Username, Password: e.Password, From: e.From,
+			TLSMode: e.TLSMode, InsecureSkipVerify: e.InsecureSkipVerify,
+		})
+	}
+	if sender.Email == nil && sender.Dispatcher == nil {
+		return nil
+	}
+	return sender
+}
+
+// digestTeams 按团队名排序转换摘要配置；团队语言无效时沿用 i18n.default_locale。
+func digestTeams(cfg *config.Config) []etl.DigestTeam {
+	names := make([]string, 0, len(cfg.Notifier.Digest.Teams))
+	for name := range cfg.Notifier.Digest.Teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	teams := make([]etl.DigestTeam, 0, len(names))
+	for _, name := range names {
+		t := cfg.Notifier.Digest.Teams[name]
+		locale, _ := i18n.Parse(t.Locale)
+		teams = append(teams, etl.DigestTeam{Name: name, Namespaces: t.Namespaces, Recipients: t.Recipients, MonthlyBudget: t.MonthlyBudget, Locale: locale})
+	}
+	return teams
+}
+
+// teamNamespaces 取摘要配置中的团队归属作为工作负载目录的 owner team。
+func teamNamespaces(cfg *config.Config) map[string][]string {
+	teams := make(map[string][]string, len(cfg.Notifier.Digest.Teams))
+	for name, t := range cfg.Notifier.Digest.Teams {
+		teams[name] = t.Namespaces
+	}
+	return teams
+}
+
+// newBusinessHours converts the validated off-hours config; zero hours keep the 09-18 default.
+func newBusinessHours(c config.OffHoursConfig) costmodel.BusinessHours {
+	hours := costmodel.DefaultBusinessHours()
+	if c.StartHour != 0 || c.EndHour != 0 {
+		hours.StartHour, hours.EndHour = c.StartHour, c.EndHour
+	}
+	if loc, err := time.LoadLocation(c.Timezone); err == nil {
+		hours.Location = loc
+	}
+	return hours
+}
+
+// newAccountingCalendar returns the calendar of the accounting time zone; an invalid zone falls back
+// to UTC with a warning.
+func newAccountingCalendar(zone string) costmodel.AccountingCalendar {
+	calendar, err := costmodel.NewAccountingCalendar(zone)
+	if err != nil {
+		log.Printf("WARN: %v, using UTC", err)
+	}
+	return calendar
+}
+
+// newFiscalCalendar converts the fiscal year config; an invalid one falls back to calendar months
+// with a warning.
+func newFiscalCalendar(c config.FiscalConfig) costmodel.FiscalCalendar {
+	fiscal, err := costmodel.NewFiscalCalendar(c.StartMonth, c.Pattern, c.WeekStart)
+	if err != nil {
+		log.Printf("WARN: %v, using calendar months", err)
+	}
+	return fiscal
+}
+
+// newGradeThresholds converts the efficiency thresholds (danger is the Risk boundary; healthy has no
+// boundary of its own); invalid thresholds fall back to the defaults with a warning.
+func newGradeThresholds(cfg *config.Config) costmodel.GradeThresholds {
+	c := cfg.Business.CostCalculation.EfficiencyThresholds
+	t := costmodel.GradeThresholds{Zombie: c.Zombie, OverProvisioned: c.OverProvisioned, Risk: c.Danger}
+	if err := t.Validate(); err != nil || t.Risk == 0 {
+		log.Printf("WARN: efficiency thresholds %+v: %v, using defaults", t, err)
+		return costmodel.DefaultGradeThresholds
+	}
+	return t
+}
+
+// newSharedResources converts the shared cost config; invalid resources are skipped with a warning.
+func newSharedResources(cs []config.SharedCostConfig) []costmodel.SharedResource {
+	out := make([]costmodel.SharedResource, 0, len(cs))
+	for _, c := range cs {
+		r := costmodel.SharedResource{
+			Name:          c.Name,
+			Kind:          c.Kind,
+			Billing:       costmodel.SharedBilling(c.Billing),
+			MonthlyCost:   c.MonthlyCost,
+			UnitPrice:     c.UnitPrice,
+			AllocationKey: costmodel.SharedAllocationKey(c.AllocationKey),
+			Namespaces:    c.Namespaces,
+		}
+		if err := r.Validate(); err != nil {
+			log.Printf("WARN: shared_costs: %v, skipped", err)
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
 }
 
 func loadConfig() (*config.Config, error) {
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/config"
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/internal/server/service"
+)
+
+func TestExternalClientsTripBreakers(t *testing.T) {
+	ctx := context.Background()
+	breakers := newBreakerSets(config.CircuitBreakerConfig{FailureRateThreshold: 0.5, MinRequests: 3, Window: time.Minute, OpenTimeout: time.Minute})
+	retrier := newRetrier(config.RetryConfig{MaxAttempts: 1})
+
+	// Prometheus 全部调用失败
+	promCfg := prometheus.DefaultMockConfig()
+	promCfg.ErrorRate, promCfg.LatencyMs = 1, 0
+	prom := newPrometheusClient(prometheus.NewMockClient(promCfg), "mock", nil, retrier, breakers.prometheus)
+	for i := 0; i < 3; i++ {
+		if _, err := prom.GetClusterMetrics(ctx, time.Now().Add(-time.Hour), time.Now()); err == nil {
+			t.Fatal("failing prometheus mock: want error")
+		}
+	}
+	if _, err := prom.GetClusterMetrics(ctx, time.Now().Add(-time.Hour), time.Now()); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("prometheus after failures: err = %v, want breaker.ErrOpen", err)
+	}
+
+	// K8s 故障经演练注入，同样计入熔断
+	drills := chaos.NewController(map[string]chaos.Scenario{"k8s-down": {chaos.Kubernetes: {ErrorRate: 1}}}, time.Hour)
+	if _, err := drills.Start("k8s-down", time.Minute, "test"); err != nil {
+		t.Fatal(err)
+	}
+	k8sCfg := k8s.DefaultMockConfig()
+	k8sCfg.LatencyMs = 0
+	kube := newK8sClient(k8s.NewMockClient(k8sCfg), "mock", drills, retrier, breakers.kubernetes)
+	for i := 0; i < 3; i++ {
+		if _, err := kube.GetNamespaces(ctx); err == nil {
+			t.Fatal("kubernetes drill: want error")
+		}
+	}
+	if _, err := kube.GetNamespaces(ctx); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("kubernetes after failures: err = %v, want breaker.ErrOpen", err)
+	}
+	for _, set := range []*breaker.Set{breakers.prometheus, breakers.kubernetes} {
+		if st := set.Statuses(); len(st) != 1 || st[0].State != breaker.StateOpen {
+			t.Errorf("%s breakers = %+v, want one open breaker", set.Source(), st)
+		}
+	}
+
+	// 未启用熔断时不包装
+	if _, ok := newK8sClient(k8s.NewMockClient(k8sCfg), "mock", nil, retrier, nil).(*k8s.BreakerClient); ok {
+		t.Error("nil breakers: want no breaker wrapper")
+	}
+}
+
+func TestDigestWiring(t *testing.T) {
+	var cfg config.Config
+	if newDigestSender(cfg.Notifier, nil) != nil {
+		t.Error("no channels: want nil digest sender")
+	}
+	cfg.Notifier.Email.Enabled, cfg.Notifier.Email.Host = true, "smtp.example.com"
+	sender := newDigestSender(cfg.Notifier, nil)
+	if sender == nil || sender.Email == nil || sender.Dispatcher != nil {
+		t.Fatalf("email only: sender = %+v, want SMTP without dispatcher", sender)
+	}
+	dispatcher := notifier.NewDispatcher(notifier.DefaultDispatcherConfig(), nil)
+	if s := newDigestSender(config.NotifierConfig{}, dispatcher); s == nil || s.Dispatcher != dispatcher || s.Email != nil {
+		t.Errorf("chat only: sender = %+v, want the dispatcher", s)
+	}
+
+	cfg.Notifier.Digest.Teams = map[string]config.DigestTeamConfig{
+		"search":   {Namespaces: []string{"search"}, Locale: "bogus"},
+		"payments": {Namespaces: []string{"payment"}, Recipients: []string{"pay@example.com"}, MonthlyBudget: 100, Locale: "zh-CN"},
+	}
+	teams := digestTeams(&cfg)
+	if len(teams) != 2 || teams[0].Name != "payments" || teams[1].Name != "search" {
+		t.Fatalf("teams = %+v, want payments then search", teams)
+	}
+	if teams[0].Locale != i18n.Chinese || teams[0].MonthlyBudget != 100 || teams[0].Recipients[0] != "pay@example.com" {
+		t.Errorf("payments = %+v", teams[0])
+	}
+	if teams[1].Locale != "" {
+		t.Errorf("invalid locale = %q, want empty (worker default)", teams[1].Locale)
+	}
+}
+
+func TestEventBusWiring(t *testing.T) {
+	ctx := context.Background()
+	var cfg config.Config
+	cfg.EventBus.RESTProxyURL = "http://kafka-rest:8082"
+	cfg.Spool.Dir = t.TempDir()
+	bus, err := newEventBus(&cfg)
+	if err != nil {
+		t.Fatalf("newEventBus: %v", err)
+	}
+
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	if newSnapshotRepository(repo, nil) != postgres.Repository(repo) {
+		t.Error("no bus: want the repository unchanged")
+	}
+	snapshots := newSnapshotRepository(repo, bus)
+	if _, ok := snapshots.(service.PriceHistoryStore); !ok {
+		t.Error("publishing repository hides the price history")
+	}
+	if err := snapshots.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "s1"}); err != nil {
+		t.Fatalf("SaveCostSnapshot: %v", err)
+	}
+
+	// 未投递的事件保存在 spool 目录，重启后的总线继续投递
+	restarted, err := newEventBus(&cfg)
+	if err != nil {
+		t.Fatalf("newEventBus after restart: %v", err)
+	}
+	if bus.Pending() != 1 || restarted.Pending() != 1 {
+		t.Errorf("pending = %d, after restart %d; want 1 spooled event", bus.Pending(), restarted.Pending())
+	}
+}
+
+func TestBillIngestWiring(t *testing.T) {
+	var cfg config.Config
+	cfg.CloudBilling = config.CloudBillingConfig{Enabled: true, Provider: "aliyun", AccountID: "acct-1", AccessKeyID: "ak", AccessKeySecret: "sk"}
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	job, err := newBillIngestJob(&cfg, repo)
+	if err != nil {
+		t.Fatalf("newBillIngestJob: %v", err)
+	}
+	if job.Name != "cloud-bill-ingest" || job.Interval != 24*time.Hour || job.Run == nil {
+		t.Errorf("job = %+v, want daily cloud-bill-ingest", job)
+	}
+	cfg.CloudBilling.Provider = "aws"
+	if _, err := newBillIngestJob(&cfg, repo); err == nil {
+		t.Error("unimplemented provider: want error")
+	}
+}
@@ -0,0 +1,296 @@
+package apigen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config lists the sources of the spec. Paths are relative to the working directory.
+type Config struct {
+	// GeneralInfo is the file with the @title / @version / @description / @BasePath annotations.
+	GeneralInfo string
+	// Handlers are the files with annotated handler functions.
+	Handlers []string
+	// Models maps package names used in annotations and fields to their directories.
+	Models map[string]string
+}
+
+// Generate builds the spec from the annotations in cfg.
+func Generate(cfg Config) (*Spec, error) {
+	spec := &Spec{Swagger: "2.0", Paths: make(map[string]PathItem)}
+	models, err := loadModels(cfg.Models)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	if cfg.GeneralInfo != "" {
+		f, err := parser.ParseFile(fset, cfg.GeneralInfo, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, cg := range f.Comments {
+			for _, a := range annotations(cg) {
+				switch strings.ToLower(a.name) {
+				case "title":
+					spec.Info.Title = a.value
+				case "version":
+					spec.Info.Version = a.value
+				case "description":
+					spec.Info.Description = joinLine(spec.Info.Description, a.value)
+				case "basepath":
+					spec.BasePath = a.value
+				case "host":
+					spec.Host = a.value
+				}
+			}
+		}
+	}
+	ids := make(map[string]string)
+	for _, path := range cfg.Handlers {
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			op, err := parseOperation(fn, models)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", fset.Position(fn.Pos()), fn.Name.Name, err)
+			}
+			if op == nil {
+				continue
+			}
+			if prev, dup := ids[op.OperationID]; dup {
+				return nil, fmt.Errorf("%s: duplicate operation id %s (also %s)", fset.Position(fn.Pos()), op.OperationID, prev)
+			}
+			ids[op.OperationID] = op.method + " " + op.path
+			if spec.Paths[op.path] == nil {
+				spec.Paths[op.path] = make(PathItem)
+			}
+			if spec.Paths[op.path][op.method] != nil {
+				return nil, fmt.Errorf("%s: duplicate route %s %s", fset.Position(fn.Pos()), op.method, op.path)
+			}
+			spec.Paths[op.path][op.method] = op
+		}
+	}
+	spec.Definitions = models.definitions
+	return spec, nil
+}
+
+type annotation struct {
+	name, value string
+}
+
+// annotations returns the "@name value" lines of a comment group.
+func annotations(cg *ast.CommentGroup) []annotation {
+	var out []annotation
+	for _, line := range strings.Split(cg.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		name, value, _ := strings.Cut(line[1:], " ")
+		out = append(out, annotation{name: name, value: strings.TrimSpace(value)})
+	}
+	return out
+}
+
+func joinLine(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n" + b
+}
+
+var (
+	routerPattern   = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+	paramPattern    = regexp.MustCompile(`^(\S+)\s+(path|query|body|header|formData)\s+(\S+)\s+(true|false)(?:\s+"([^"]*)")?(.*)$`)
+	responsePattern = regexp.MustCompile(`^(\d{3})(?:\s+\{(object|array)\}\s+(\S+))?(?:\s+"([^"]*)")?$`)
+	attrPattern     = regexp.MustCompile(`(\w+)\(([^)]*)\)`)
+)
+
+// parseOperation returns the operation of an annotated handler, or nil without @Router.
+func parseOperation(fn *ast.FuncDecl, models *models) (*Operation, error) {
+	op := &Operation{OperationID: fn.Name.Name, Responses: make(map[string]Response)}
+	for _, a := range annotations(fn.Doc) {
+		switch strings.ToLower(a.name) {
+		case "summary":
+			op.Summary = a.value
+		case "description":
+			op.Description = joinLine(op.Description, a.value)
+		case "tags":
+			for _, tag := range strings.Split(a.value, ",") {
+				op.Tags = append(op.Tags, strings.TrimSpace(tag))
+			}
+		case "id":
+			op.OperationID = a.value
+		case "accept":
+			op.Consumes = append(op.Consumes, mimeType(a.value))
+		case "produce":
+			op.Produces = append(op.Produces, mimeType(a.value))
+		case "param":
+			p, err := parseParam(a.value, models)
+			if err != nil {
+				return nil, err
+			}
+			op.Parameters = append(op.Parameters, p)
+		case "success", "failure":
+			code, resp, err := parseResponse(a.value, models)
+			if err != nil {
+				return nil, err
+			}
+			op.Responses[code] = resp
+		case "router":
+			m := routerPattern.FindStringSubmatch(a.value)
+			if m == nil {
+				return nil, fmt.Errorf("invalid @Router %q, want \"/path/{param} [method]\"", a.value)
+			}
+			op.path, op.method = m[1], strings.ToLower(m[2])
+		}
+	}
+	if op.path == "" {
+		return nil, nil
+	}
+	for _, name := range pathParams(op.path) {
+		found := false
+		for _, p := range op.Parameters {
+			found = found || (p.In == "path" && p.Name == name)
+		}
+		if !found {
+			return nil, fmt.Errorf("path parameter %s of %s has no @Param", name, op.path)
+		}
+	}
+	if len(op.Responses) == 0 {
+		return nil, fmt.Errorf("no @Success for %s", op.path)
+	}
+	return op, nil
+}
+
+// pathParams returns the {param} names of a route in order.
+func pathParams(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			names = append(names, seg[1:len(seg)-1])
+		}
+	}
+	return names
+}
+
+func mimeType(s string) string {
+	switch s {
+	case "json":
+		return "application/json"
+	case "plain":
+		return "text/plain"
+	}
+	return s
+}
+
+// parseParam parses `name in type required "description" Attr(...)`.
+func parseParam(value string, models *models) (Parameter, error) {
+	m := paramPattern.FindStringSubmatch(value)
+	if m == nil {
+		return Parameter{}, fmt.Errorf("invalid @Param %q, want `name in type required \"description\"`", value)
+	}
+	p := Parameter{Name: m[1], In: m[2], Required: m[4] == "true", Description: m[5]}
+	if p.In == "body" {
+		schema, err := typeSchema("object", m[3], models)
+		if err != nil {
+			return p, err
+		}
+		p.Schema = schema
+	} else {
+		s := basicSchema(m[3])
+		if s == nil {
+			s = &Schema{Type: m[3]}
+		}
+		if s.Type != "string" && s.Type != "integer" && s.Type != "number" && s.Type != "boolean" {
+			return p, fmt.Errorf("@Param %s: type %s is not a primitive", p.Name, m[3])
+		}
+		p.Type, p.Format = s.Type, s.Format
+	}
+	for _, attr := range attrPattern.FindAllStringSubmatch(m[6], -1) {
+		switch strings.ToLower(attr[1]) {
+		case "enums":
+			for _, v := range strings.Split(attr[2], ",") {
+				p.Enum = append(p.Enum, strings.TrimSpace(v))
+			}
+		case "format":
+			p.Format = attr[2]
+		default:
+			return p, fmt.Errorf("@Param %s: unsupported attribute %s", p.Name, attr[1])
+		}
+	}
+	return p, nil
+}
+
+// parseResponse parses `code {object|array} type "description"`; type and description are optional.
+func parseResponse(value string, models *models) (string, Response, error) {
+	m := responsePattern.FindStringSubmatch(value)
+	if m == nil {
+		return "", Response{}, fmt.Errorf("invalid response %q, want `code {object} type \"description\"`", value)
+	}
+	resp := Response{Description: m[4]}
+	if resp.Description == "" {
+		code, _ := strconv.Atoi(m[1])
+		resp.Description = http.StatusText(code)
+	}
+	if m[3] != "" {
+		schema, err := typeSchema(m[2], m[3], models)
+		if err != nil {
+			return "", resp, err
+		}
+		resp.Schema = schema
+	}
+	return m[1], resp, nil
+}
+
+// typeSchema returns the schema of an annotation type: a primitive, "object" (free-form) or pkg.Type.
+func typeSchema(kind, name string, models *models) (*Schema, error) {
+	var s *Schema
+	switch {
+	case name == "object":
+		s = &Schema{}
+	case basicSchema(name) != nil:
+		s = basicSchema(name)
+	default:
+		pkg, typeName, ok := strings.Cut(name, ".")
+		if !ok {
+			return nil, fmt.Errorf("type %s must be qualified with its package", name)
+		}
+		var err error
+		if s, err = models.namedSchema(pkg, typeName); err != nil {
+			return nil, err
+		}
+	}
+	if kind == "array" {
+		return &Schema{Type: "array", Items: s}, nil
+	}
+	return s, nil
+}
+
+// successResponse returns the lowest 2xx response of op.
+func (o *Operation) successResponse() (int, Response) {
+	codes := make([]int, 0, len(o.Responses))
+	for code := range o.Responses {
+		if c, err := strconv.Atoi(code); err == nil && c >= 200 && c < 300 {
+			codes = append(codes, c)
+		}
+	}
+	if len(codes) == 0 {
+		return 0, Response{}
+	}
+	sort.Ints(codes)
+	return codes[0], o.Responses[strconv.Itoa(codes[0])]
+}
@@ -0,0 +1,223 @@
+package apigen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testModels = `package dto
+
+import "time"
+
+// Grade is an efficiency grade.
+type Grade string
+
+type Page struct {
+	Total int ` + "`json:\"total\"`" + `
+}
+
+// Item is a listed item.
+type Item struct {
+	Page
+	ID      string            ` + "`json:\"id\" binding:\"required\"`" + `
+	Grade   Grade             ` + "`json:\"grade\"`" + `
+	At      *time.Time        ` + "`json:\"at,omitempty\"`" + ` // last change
+	Labels  map[string]string ` + "`json:\"labels\"`" + `
+	Parent  *Item             ` + "`json:\"parent,omitempty\"`" + `
+	Extra   interface{}       ` + "`json:\"extra\"`" + `
+	Ignored string            ` + "`json:\"-\"`" + `
+	hidden  string
+}
+`
+
+const testHandlers = `package server
+
+// getItem handles GET /items/:id
+// @Summary Get an item
+// @Tags    Item
+// @Param   id     path  string  true  "item id"
+// @Param   since  query string  false "changed since" Format(date-time)
+// @Param   limit  query integer false "page size"
+// @Success 200 {object} dto.Item
+// @Failure 404 {object} dto.Item "missing"
+// @Router  /items/{id} [get]
+func (s *HTTPServer) getItem(c *gin.Context) {}
+
+// putItems
+// @Param   request body dto.Item true "item"
+// @Success 204
+// @Router  /items [put]
+func (s *HTTPServer) putItems(c *gin.Context) {}
+
+// helper has no @Router and is not an operation.
+func helper() {}
+`
+
+func writeFixture(t *testing.T) Config {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "dto"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dto", "item.go"), []byte(testModels), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	main := "package main\n\n// @title Test API\n// @version 2.0\n// @BasePath /api/v2\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "http.go"), []byte(testHandlers), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return Config{
+		GeneralInfo: filepath.Join(dir, "main.go"),
+		Handlers:    []string{filepath.Join(dir, "http.go")},
+		Models:      map[string]string{"dto": filepath.Join(dir, "dto")},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	spec, err := Generate(writeFixture(t))
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if spec.Info.Title != "Test API" || spec.Info.Version != "2.0" || spec.BasePath != "/api/v2" {
+		t.Errorf("general info = %+v, basePath %q", spec.Info, spec.BasePath)
+	}
+	ops := spec.Operations()
+	if len(ops) != 2 || ops[0].OperationID != "getItem" || ops[1].OperationID != "putItems" {
+		t.Fatalf("operations = %+v, want getItem and putItems", ops)
+	}
+	get := spec.Paths["/items/{id}"]["get"]
+	if get == nil || len(get.Parameters) != 3 || get.Parameters[1].Format != "date-time" {
+		t.Fatalf("GET /items/{id} = %+v", get)
+	}
+	if ref := get.Responses["200"].Schema.Ref; ref != "#/definitions/dto.Item" {
+		t.Errorf("200 schema ref = %q", ref)
+	}
+	if d := get.Responses["404"].Description; d != "missing" {
+		t.Errorf("404 description = %q", d)
+	}
+	if d := spec.Paths["/items"]["put"].Responses["204"].Description; d != "No Content" {
+		t.Errorf("204 description = %q, want the status text", d)
+	}
+
+	item := spec.Definitions["dto.Item"]
+	if item == nil {
+		t.Fatal("dto.Item not defined")
+	}
+	for _, prop := range []string{"total", "id", "grade", "at", "labels", "parent", "extra"} {
+		if item.Properties[prop] == nil {
+			t.Errorf("dto.Item has no property %s", prop)
+		}
+	}
+	if len(item.Properties) != 7 {
+		t.Errorf("dto.Item properties = %d, want 7 (ignored and unexported fields skipped)", len(item.Properties))
+	}
+	if p := item.Properties["grade"]; p.Type != "string" || p.Ref != "" {
+		t.Errorf("named string type should inline as string: %+v", p)
+	}
+	if p := item.Properties["at"]; p.Format != "date-time" || p.Description != "last change" {
+		t.Errorf("at = %+v", p)
+	}
+	if p := item.Properties["parent"]; p.Ref != "#/definitions/dto.Item" {
+		t.Errorf("recursive field = %+v", p)
+	}
+	if len(item.Required) != 1 || item.Required[0] != "id" {
+		t.Errorf("required = %v, want [id] from binding:\"required\"", item.Required)
+	}
+
+	raw, err := spec.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	for name, handler := range map[string]string{
+		"missing path param": "// @Success 200\n// @Router /items/{id} [get]\nfunc a() {}\n",
+		"no success":         "// @Router /items [get]\nfunc a() {}\n",
+		"unknown model":      "// @Success 200 {object} dto.Missing\n// @Router /items [get]\nfunc a() {}\n",
+		"bad router":         "// @Success 200\n// @Router /items\nfunc a() {}\n",
+		"duplicate route":    "// @Success 200\n// @Router /items [get]\nfunc a() {}\n\n// @Success 200\n// @Router /items [get]\nfunc b() {}\n",
+	} {
+		cfg := writeFixture(t)
+		if err := os.WriteFile(cfg.Handlers[0], []byte("package server\n\n"+handler), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Generate(cfg); err == nil {
+			t.Errorf("%s: Generate should fail", name)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	spec, err := Generate(writeFixture(t))
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	src, err := spec.Client("client")
+	if err != nil {
+		t.Fatalf("Client: %v", err)
+	}
+	for _, want := range []string{
+		`const basePath = "/api/v2"`,
+		"func (c *Client) GetItem(ctx context.Context, id string, params GetItemParams) (*Item, error)",
+		`"/items/"+url.PathEscape(id)`,
+		"func (c *Client) PutItems(ctx context.Context, body Item) error",
+		"Since time.Time",
+		`q.Set("limit", strconv.Itoa(p.Limit))`,
+		"At *time.Time `json:\"at,omitempty\"`",
+		"Extra json.RawMessage `json:\"extra\"`",
+		"Parent *Item `json:\"parent,omitempty\"`",
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(string(src)), " "), strings.Join(strings.Fields(want), " ")) {
+			t.Errorf("generated client lacks %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestYAML(t *testing.T) {
+	spec, err := Generate(writeFixture(t))
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out, err := spec.YAML()
+	if err != nil {
+		t.Fatalf("YAML: %v", err)
+	}
+	for _, want := range []string{"basePath: /api/v2\n", "swagger: \"2.0\"\n", "  /items/{id}:\n", "$ref: \"#/definitions/dto.Item\"\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("YAML lacks %q:\n%s", want, out)
+		}
+	}
+	for in, want := range map[string]string{"plain text": "plain text", "/a/{id}": "/a/{id}", "true": `"true"`, "1.0": `"1.0"`, "a: b": `"a: b"`, "#/x": `"#/x"`, "": `""`} {
+		if got := yamlScalar(in); got != want {
+			t.Errorf("yamlScalar(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+// TestLighthouseSpecUpToDate fails when a handler annotation or DTO changed without regenerating
+// api/ and pkg/client (go generate ./api).
+func TestLighthouseSpecUpToDate(t *testing.T) {
+	root := filepath.Join("..", "..")
+	files, err := Render(LighthouseConfig(root))
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	stale, err := Stale(root, files)
+	if err != nil {
+		t.Fatalf("Stale: %v", err)
+	}
+	if len(stale) > 0 {
+		t.Errorf("out of date, run `go generate ./api`: %v", stale)
+	}
+}
@@ -0,0 +1,323 @@
+package apigen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Client returns the generated part of the Go client package pkg: one struct per definition and one
+// Client method per operation. The hand-written part of the package provides Client and Client.do.
+func (s *Spec) Client(pkg string) ([]byte, error) {
+	g := &clientGen{spec: s, names: make(map[string]string), imports: map[string]bool{"context": true}}
+	if err := g.nameModels(); err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "// basePath is the route prefix of all operations.\nconst basePath = %q\n", s.BasePath)
+	for _, op := range s.Operations() {
+		if err := g.operation(&body, op); err != nil {
+			return nil, fmt.Errorf("operation %s: %w", op.OperationID, err)
+		}
+	}
+	defNames := make([]string, 0, len(s.Definitions))
+	for name := range s.Definitions {
+		defNames = append(defNames, name)
+	}
+	sort.Strings(defNames)
+	for _, name := range defNames {
+		g.model(&body, name, s.Definitions[name])
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by cmd/apigen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg)
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format client: %w", err)
+	}
+	return src, nil
+}
+
+type clientGen struct {
+	spec    *Spec
+	names   map[string]string // definition -> Go type name
+	imports map[string]bool
+}
+
+// nameModels names each definition after its type, prefixed with the package when two packages
+// declare the same name.
+func (g *clientGen) nameModels() error {
+	byName := make(map[string][]string)
+	for def := range g.spec.Definitions {
+		_, name, _ := strings.Cut(def, ".")
+		byName[name] = append(byName[name], def)
+	}
+	used := make(map[string]string)
+	for name, defs := range byName {
+		for _, def := range defs {
+			goName := name
+			if len(defs) > 1 {
+				pkg, _, _ := strings.Cut(def, ".")
+				goName = exportedName(pkg) + name
+			}
+			if prev, dup := used[goName]; dup {
+				return fmt.Errorf("definitions %s and %s both map to %s", prev, def, goName)
+			}
+			used[goName] = def
+			g.names[def] = goName
+		}
+	}
+	return nil
+}
+
+// goType returns the Go type of s.
+func (g *clientGen) goType(s *Schema) string {
+	switch {
+	case s.Ref != "":
+		return g.names[s.RefName()]
+	case s.Type == "string" && s.Format == "date-time":
+		g.imports["time"] = true
+		return "time.Time"
+	case s.Type == "string" && s.Format == "byte":
+		return "[]byte"
+	case s.Type == "string":
+		return "string"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "integer" && s.Format == "int64":
+		return "int64"
+	case s.Type == "integer":
+		return "int"
+	case s.Type == "number" && s.Format == "float":
+		return "float32"
+	case s.Type == "number":
+		return "float64"
+	case s.Type == "array" && s.Items != nil:
+		return "[]" + g.goType(s.Items)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "map[string]" + g.goType(s.AdditionalProperties)
+	}
+	g.imports["encoding/json"] = true
+	return "json.RawMessage"
+}
+
+func (g *clientGen) model(b *bytes.Buffer, def string, s *Schema) {
+	name := g.names[def]
+	b.WriteString("\n")
+	if s.Description != "" {
+		writeComment(b, "", s.Description)
+	} else {
+		fmt.Fprintf(b, "// %s mirrors %s.\n", name, def)
+	}
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, prop := range s.order {
+		p := s.Properties[prop]
+		if p.Description != "" {
+			writeComment(b, "\t", p.Description)
+		}
+		typ := g.goType(p)
+		if p.pointer && !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "json.RawMessage" {
+			typ = "*" + typ
+		}
+		tag := prop
+		if p.omitEmpty {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "\t%s %s `json:%q`\n", p.goName, typ, tag)
+	}
+	b.WriteString("}\n")
+}
+
+func (g *clientGen) operation(b *bytes.Buffer, op *Operation) error {
+	method := exportedName(op.OperationID)
+	args := []string{"ctx context.Context"}
+	var query []Parameter
+	bodyArg := "nil"
+	for _, name := range pathParams(op.path) {
+		args = append(args, goIdent(name)+" string")
+	}
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "query":
+			query = append(query, p)
+		case "body":
+			args = append(args, "body "+g.goType(p.Schema))
+			bodyArg = "body"
+		case "path":
+		default:
+			return fmt.Errorf("%s parameters are not supported", p.In)
+		}
+	}
+	queryArg := "nil"
+	if len(query) > 0 {
+		paramsType := method + "Params"
+		g.queryParams(b, paramsType, op, query)
+		args = append(args, "params "+paramsType)
+		queryArg = "params.values()"
+	}
+
+	path := g.pathExpr(op.path)
+	_, success := op.successResponse()
+	b.WriteString("\n")
+	summary := op.Method() + " " + op.path
+	if op.Summary != "" {
+		summary += ": " + op.Summary
+	}
+	writeComment(b, "", method+" calls "+summary+".")
+	if success.Schema == nil {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) error {\n", method, strings.Join(args, ", "))
+		fmt.Fprintf(b, "\treturn c.do(ctx, %q, %s, %s, %s, nil)\n}\n", op.Method(), path, queryArg, bodyArg)
+		return nil
+	}
+	typ := g.goType(success.Schema)
+	if success.Schema.Ref != "" {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (*%s, error) {\n", method, strings.Join(args, ", "), typ)
+		fmt.Fprintf(b, "\tvar out %s\n", typ)
+		fmt.Fprintf(b, "\tif err := c.do(ctx, %q, %s, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", op.Method(), path, queryArg, bodyArg)
+		b.WriteString("\treturn &out, nil\n}\n")
+		return nil
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) (%s, error) {\n", method, strings.Join(args, ", "), typ)
+	fmt.Fprintf(b, "\tvar out %s\n", typ)
+	fmt.Fprintf(b, "\terr := c.do(ctx, %q, %s, %s, %s, &out)\n", op.Method(), path, queryArg, bodyArg)
+	b.WriteString("\treturn out, err\n}\n")
+	return nil
+}
+
+// queryParams writes the query parameter struct of an operation; zero fields are not sent.
+func (g *clientGen) queryParams(b *bytes.Buffer, name string, op *Operation, params []Parameter) {
+	g.imports["net/url"] = true
+	fmt.Fprintf(b, "\n// %s holds the query parameters of %s %s; zero values are not sent.\n", name, op.Method(), op.path)
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, p := range params {
+		if p.Description != "" {
+			writeComment(b, "\t", p.Description)
+		}
+		fmt.Fprintf(b, "\t%s %s\n", exportedName(p.Name), g.goType(&Schema{Type: p.Type, Format: p.Format}))
+	}
+	b.WriteString("}\n\n")
+	fmt.Fprintf(b, "func (p %s) values() url.Values {\n\tq := url.Values{}\n", name)
+	for _, p := range params {
+		field := "p." + exportedName(p.Name)
+		switch typ := g.goType(&Schema{Type: p.Type, Format: p.Format}); typ {
+		case "time.Time":
+			fmt.Fprintf(b, "\tif !%s.IsZero() {\n\t\tq.Set(%q, %s.Format(time.RFC3339))\n\t}\n", field, p.Name, field)
+		case "bool":
+			g.imports["strconv"] = true
+			fmt.Fprintf(b, "\tif %s {\n\t\tq.Set(%q, strconv.FormatBool(%s))\n\t}\n", field, p.Name, field)
+		case "int":
+			g.imports["strconv"] = true
+			fmt.Fprintf(b, "\tif %s != 0 {\n\t\tq.Set(%q, strconv.Itoa(%s))\n\t}\n", field, p.Name, field)
+		case "int64":
+			g.imports["strconv"] = true
+			fmt.Fprintf(b, "\tif %s != 0 {\n\t\tq.Set(%q, strconv.FormatInt(%s, 10))\n\t}\n", field, p.Name, field)
+		case "float64":
+			g.imports["strconv"] = true
+			fmt.Fprintf(b, "\tif %s != 0 {\n\t\tq.Set(%q, strconv.FormatFloat(%s, 'g', -1, 64))\n\t}\n", field, p.Name, field)
+		case "float32":
+			g.imports["strconv"] = true
+			fmt.Fprintf(b, "\tif %s != 0 {\n\t\tq.Set(%q, strconv.FormatFloat(float64(%s), 'g', -1, 32))\n\t}\n", field, p.Name, field)
+		default:
+			fmt.Fprintf(b, "\tif %s != \"\" {\n\t\tq.Set(%q, %s)\n\t}\n", field, p.Name, field)
+		}
+	}
+	b.WriteString("\treturn q\n}\n")
+}
+
+// pathExpr returns the Go expression of a route with escaped path parameters.
+func (g *clientGen) pathExpr(path string) string {
+	var parts []string
+	literal := ""
+	for _, seg := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		literal += "/"
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			g.imports["net/url"] = true
+			parts = append(parts, fmt.Sprintf("%q", literal), "url.PathEscape("+goIdent(seg[1:len(seg)-1])+")")
+			literal = ""
+			continue
+		}
+		literal += seg
+	}
+	if literal != "" {
+		parts = append(parts, fmt.Sprintf("%q", literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// writeComment writes text as // comments wrapped at about 100 columns.
+func writeComment(b *bytes.Buffer, indent, text string) {
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line != "" && len(indent)+3+len(line)+1+len(word) > 100 {
+				fmt.Fprintf(b, "%s// %s\n", indent, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		fmt.Fprintf(b, "%s// %s\n", indent, line)
+	}
+}
+
+// commonInitialisms are kept upper-case in Go names.
+var commonInitialisms = map[string]bool{
+	"API": true, "CPU": true, "DEK": true, "ID": true, "IP": true, "JSON": true, "LB": true,
+	"ROI": true, "SLO": true, "SQL": true, "TTL": true, "URL": true, "UTC": true,
+}
+
+// exportedName converts snake_case or lowerCamel to an exported Go name.
+func exportedName(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(word)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	return b.String()
+}
+
+// goIdent converts a parameter name to an unexported Go identifier.
+func goIdent(s string) string {
+	words := splitWords(s)
+	if len(words) == 0 {
+		return "_"
+	}
+	return strings.ToLower(words[0]) + exportedName(strings.Join(words[1:], "_"))
+}
+
+// splitWords splits snake_case and lowerCamel names into words.
+func splitWords(s string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' }) {
+		start := 0
+		r := []rune(part)
+		for i := 1; i < len(r); i++ {
+			if unicode.IsUpper(r[i]) && !unicode.IsUpper(r[i-1]) {
+				words = append(words, string(r[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(r[start:]))
+	}
+	return words
+}
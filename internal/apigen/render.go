@@ -0,0 +1,61 @@
+package apigen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LighthouseConfig returns the sources of the Lighthouse API spec relative to the repository root.
+func LighthouseConfig(root string) Config {
+	return Config{
+		GeneralInfo: filepath.Join(root, "cmd/server/main.go"),
+		Handlers:    []string{filepath.Join(root, "internal/server/http.go")},
+		Models: map[string]string{
+			"dto":       filepath.Join(root, "internal/server/dto"),
+			"costmodel": filepath.Join(root, "pkg/costmodel"),
+			"roi":       filepath.Join(root, "internal/biz/roi"),
+		},
+	}
+}
+
+// Render generates the spec of cfg and returns the generated files by path relative to the
+// repository root.
+func Render(cfg Config) (map[string][]byte, error) {
+	spec, err := Generate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	if files["api/swagger.json"], err = spec.JSON(); err != nil {
+		return nil, err
+	}
+	if files["api/swagger.yaml"], err = spec.YAML(); err != nil {
+		return nil, err
+	}
+	if files["api/docs.go"], err = spec.DocsGo("api"); err != nil {
+		return nil, err
+	}
+	if files["pkg/client/zz_generated.go"], err = spec.Client("client"); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Stale returns the generated files that differ from their copies under root, sorted.
+func Stale(root string, files map[string][]byte) ([]string, error) {
+	var stale []string
+	for path, want := range files {
+		got, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		if !bytes.Equal(got, want) {
+			stale = append(stale, path)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
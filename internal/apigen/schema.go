@@ -0,0 +1,238 @@
+package apigen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// models holds the type declarations of the model packages, keyed by package name.
+type models struct {
+	types       map[string]map[string]*ast.TypeSpec // package -> type name -> spec
+	imports     map[*ast.TypeSpec]map[string]string // import name -> package name, per declaring file
+	definitions map[string]*Schema
+}
+
+// loadModels parses the non-test Go files of dirs (package name -> directory).
+func loadModels(dirs map[string]string) (*models, error) {
+	m := &models{
+		types:       make(map[string]map[string]*ast.TypeSpec),
+		imports:     make(map[*ast.TypeSpec]map[string]string),
+		definitions: make(map[string]*Schema),
+	}
+	fset := token.NewFileSet()
+	for pkg, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		m.types[pkg] = make(map[string]*ast.TypeSpec)
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			imports := make(map[string]string)
+			for _, imp := range f.Imports {
+				p := strings.Trim(imp.Path.Value, `"`)
+				name := p[strings.LastIndex(p, "/")+1:]
+				if imp.Name != nil {
+					imports[imp.Name.Name] = name
+				} else {
+					imports[name] = name
+				}
+			}
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if ts.Doc == nil && len(gen.Specs) == 1 {
+						ts.Doc = gen.Doc
+					}
+					m.types[pkg][ts.Name.Name] = ts
+					m.imports[ts] = imports
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// namedSchema returns the schema of pkg.name: a $ref for structs (adding the definition) and the
+// inlined underlying schema otherwise.
+func (m *models) namedSchema(pkg, name string) (*Schema, error) {
+	switch pkg + "." + name {
+	case "time.Time":
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case "time.Duration":
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case "json.RawMessage":
+		return &Schema{}, nil
+	}
+	ts, ok := m.types[pkg][name]
+	if !ok {
+		return nil, fmt.Errorf("unknown model type %s.%s", pkg, name)
+	}
+	st, isStruct := ts.Type.(*ast.StructType)
+	if !isStruct {
+		return m.exprSchema(pkg, ts, ts.Type)
+	}
+	defName := pkg + "." + name
+	if _, done := m.definitions[defName]; !done {
+		def := &Schema{Type: "object", Properties: make(map[string]*Schema), Description: docText(ts.Doc)}
+		m.definitions[defName] = def // before the fields: recursive types
+		if err := m.addFields(def, pkg, ts, st); err != nil {
+			return nil, fmt.Errorf("%s: %w", defName, err)
+		}
+		sort.Strings(def.Required)
+	}
+	return &Schema{Ref: definitionsPrefix + defName}, nil
+}
+
+// addFields adds the JSON fields of st to def, inlining embedded structs.
+func (m *models) addFields(def *Schema, pkg string, ts *ast.TypeSpec, st *ast.StructType) error {
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		}
+		jsonName, opts, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" && opts == "" {
+			continue
+		}
+		if len(field.Names) == 0 {
+			if jsonName != "" {
+				return fmt.Errorf("named embedded field %s is not supported", jsonName)
+			}
+			ident, ok := field.Type.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("embedded field %T is not supported", field.Type)
+			}
+			embedded, ok := m.types[pkg][ident.Name].Type.(*ast.StructType)
+			if !ok {
+				return fmt.Errorf("embedded field %s is not a struct", ident.Name)
+			}
+			if err := m.addFields(def, pkg, m.types[pkg][ident.Name], embedded); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			prop, err := m.exprSchema(pkg, ts, field.Type)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", name.Name, err)
+			}
+			propName := jsonName
+			if propName == "" {
+				propName = name.Name
+			}
+			if prop.Ref == "" {
+				prop.Description = docText(field.Doc)
+				if prop.Description == "" {
+					prop.Description = docText(field.Comment)
+				}
+			}
+			_, prop.pointer = field.Type.(*ast.StarExpr)
+			prop.goName = name.Name
+			prop.omitEmpty = strings.Contains(","+opts+",", ",omitempty,")
+			def.Properties[propName] = prop
+			def.order = append(def.order, propName)
+			if strings.Contains(","+tag.Get("binding")+",", ",required,") {
+				def.Required = append(def.Required, propName)
+			}
+		}
+	}
+	return nil
+}
+
+// exprSchema returns the schema of a type expression declared in pkg (by ts).
+func (m *models) exprSchema(pkg string, ts *ast.TypeSpec, expr ast.Expr) (*Schema, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return m.exprSchema(pkg, ts, t.X)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := m.exprSchema(pkg, ts, t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		if key, ok := t.Key.(*ast.Ident); !ok || key.Name != "string" {
+			return nil, fmt.Errorf("map keys must be strings")
+		}
+		values, err := m.exprSchema(pkg, ts, t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case *ast.InterfaceType:
+		return &Schema{}, nil
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type %T", t.X)
+		}
+		imported := m.imports[ts][x.Name]
+		if imported == "" {
+			imported = x.Name
+		}
+		return m.namedSchema(imported, t.Sel.Name)
+	case *ast.Ident:
+		if s := basicSchema(t.Name); s != nil {
+			return s, nil
+		}
+		return m.namedSchema(pkg, t.Name)
+	}
+	return nil, fmt.Errorf("unsupported type %T", expr)
+}
+
+// basicSchema returns the schema of a predeclared type, or nil.
+func basicSchema(name string) *Schema {
+	switch name {
+	case "string":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32":
+		return &Schema{Type: "integer"}
+	case "int64", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	case "any":
+		return &Schema{}
+	}
+	return nil
+}
+
+// docText returns a comment as one line.
+func docText(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
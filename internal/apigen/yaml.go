@@ -0,0 +1,108 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// YAML returns the spec as YAML, as written to api/swagger.yaml. Keys are sorted like in the JSON form.
+func (s *Spec) YAML() ([]byte, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("encode spec: %w", err)
+	}
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("encode spec: %w", err)
+	}
+	var b bytes.Buffer
+	writeYAML(&b, doc, 0)
+	return b.Bytes(), nil
+}
+
+// writeYAML writes v in block style; maps and lists start on their own line.
+func writeYAML(b *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteString(pad + yamlScalar(k) + ":")
+			writeYAMLValue(b, v[k], indent)
+		}
+	case []interface{}:
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok && len(m) > 0 {
+				// "- " replaces the indentation of the first key
+				var nested bytes.Buffer
+				writeYAML(&nested, m, indent+1)
+				b.WriteString(pad + "- " + strings.TrimPrefix(nested.String(), pad+"  "))
+				continue
+			}
+			b.WriteString(pad + "-")
+			writeYAMLValue(b, item, indent)
+		}
+	}
+}
+
+func writeYAMLValue(b *bytes.Buffer, v interface{}, indent int) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		if len(c) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, c, indent+1)
+	case []interface{}:
+		if len(c) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, c, indent+1)
+	default:
+		b.WriteString(" " + yamlValue(c) + "\n")
+	}
+}
+
+func yamlValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return yamlScalar(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	}
+	return yamlScalar(fmt.Sprint(v))
+}
+
+// yamlScalar quotes s unless it is a plain scalar that reads back as the same string.
+func yamlScalar(s string) string {
+	if s == "" || strings.TrimSpace(s) != s || strings.ContainsAny(s, "\n\t\"") ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'%@`") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~", "y", "n":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -91,6 +91,55 @@ func (na *NodeAggregator) Aggregate(results []costmodel.DualCostResult) (*costmo
 	return nil, nil
 }
 
+// =============================================
+// L2: Node Pool Aggregator
+// =============================================
+
+// NodePoolAggregator implements Aggregator for node-pool-level aggregation (nodes sharing a pool
+// label, see costmodel.NodePoolOf).
+type NodePoolAggregator struct {
+	BaseAggregator
+	pool string
+}
+
+// NewNodePoolAggregator creates a new node pool aggregator.
+func NewNodePoolAggregator(pool string) *NodePoolAggregator {
+	return &NodePoolAggregator{
+		BaseAggregator: BaseAggregator{
+			level:      costmodel.LevelNodePool,
+			identifier: pool,
+			dimensions: []string{"cost", "efficiency", "waste", "resource_allocation", "pool_capacity"},
+		},
+		pool: pool,
+	}
+}
+
+// Aggregate sums the costs of the workloads running in the pool.
+func (npa *NodePoolAggregator) Aggregate(results []costmodel.DualCostResult) (*costmodel.AggregationResult, error) {
+	var total costmodel.CostResult
+	for _, r := range results {
+		total.CPUBillableCost += r.CPUBillableCost
+		total.CPUUsageCost += r.CPUUsageCost
+		total.CPUWasteCost += r.CPUWasteCost
+		total.MemBillableCost += r.MemBillableCost
+		total.MemUsageCost += r.MemUsageCost
+		total.MemWasteCost += r.MemWasteCost
+		total.TotalBillableCost += r.TotalBillableCost
+		total.TotalUsageCost += r.TotalUsageCost
+		total.TotalWasteCost += r.TotalWasteCost
+	}
+	if total.TotalBillableCost > 0 {
+		total.OverallEfficiencyScore = total.TotalUsageCost / total.TotalBillableCost * 100
+	}
+	return &costmodel.AggregationResult{
+		Level:         costmodel.LevelNodePool,
+		Identifier:    npa.pool,
+		TotalCost:     total,
+		ResourceCount: len(results),
+		Timestamp:     time.Now(),
+	}, nil
+}
+
 // =============================================
 // L3: Workload Aggregator
 // =============================================
@@ -201,6 +250,8 @@ func (af *AggregatorFactory) CreateAggregator(level costmodel.AggregationLevel,
 		return NewNamespaceAggregator(identifier)
 	case costmodel.LevelNode:
 		return NewNodeAggregator(identifier)
+	case costmodel.LevelNodePool:
+		return NewNodePoolAggregator(identifier)
 	case costmodel.LevelWorkload:
 		namespace := metadata["namespace"]
 		workloadType := metadata["workload_type"]
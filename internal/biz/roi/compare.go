@@ -0,0 +1,44 @@
+package roi
+
+import (
+	"math"
+	"time"
+)
+
+// Baseline metric keys: a stored baseline (postgres.ROIBaseline.Metrics) may pin any field of its
+// BaselineSnapshot under the field's JSON name instead of deriving it from its period's data.
+const (
+	MetricCPUUtilization    = "cpu_utilization"
+	MetricMemUtilization    = "mem_utilization"
+	MetricTotalWasteAmount  = "total_waste_amount"
+	MetricTotalBillableCost = "total_billable_cost"
+	MetricNodeCount         = "node_count"
+	MetricZombieAssetCount  = "zombie_asset_count"
+)
+
+// Compare returns the comparison of the metrics of date (current) against baseline. Savings and
+// reductions are positive when current is below the baseline; utilization improvements are in
+// percentage points and ResourceRecoveryRate is the waste reduction in percent of the baseline waste.
+func Compare(baseline, current BaselineSnapshot, date time.Time) DailyComparison {
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	c := DailyComparison{
+		Date:                      date,
+		BaselineID:                baseline.SnapshotID,
+		CurrentCPUUtilization:     current.CPUUtilization,
+		CurrentMemUtilization:     current.MemUtilization,
+		CurrentTotalWasteAmount:   current.TotalWasteAmount,
+		CurrentTotalBillableCost:  current.TotalBillableCost,
+		CurrentNodeCount:          current.NodeCount,
+		CurrentZombieAssetCount:   current.ZombieAssetCount,
+		CPUUtilizationImprovement: round(current.CPUUtilization - baseline.CPUUtilization),
+		MemUtilizationImprovement: round(current.MemUtilization - baseline.MemUtilization),
+		WasteReductionAmount:      round(baseline.TotalWasteAmount - current.TotalWasteAmount),
+		CostSavingsAmount:         round(baseline.TotalBillableCost - current.TotalBillableCost),
+		NodeReductionCount:        baseline.NodeCount - current.NodeCount,
+		ZombieCleanupCount:        baseline.ZombieAssetCount - current.ZombieAssetCount,
+	}
+	if baseline.TotalWasteAmount > 0 {
+		c.ResourceRecoveryRate = round(c.WasteReductionAmount / baseline.TotalWasteAmount * 100)
+	}
+	return c
+}
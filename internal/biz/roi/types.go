@@ -328,6 +328,10 @@ type OptimizationTrackingRecord struct {
 	TargetResourceID   string `json:"target_resource_id"`
 	TargetResourceType string `json:"target_resource_type"` // "pod", "namespace", "node", "storage_class"
 
+	// Origin: the issued recommendation and the manifest bundle it was exported in (empty when recorded by hand)
+	RecommendationID string `json:"recommendation_id,omitempty"`
+	BundleID         string `json:"bundle_id,omitempty"`
+
 	// Before and after state
 	BeforeState map[string]interface{} `json:"before_state"`
 	AfterState  map[string]interface{} `json:"after_state"`
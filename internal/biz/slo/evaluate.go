@@ -0,0 +1,159 @@
+// Package slo evaluate.go: SLO 评估——按可用性目标与延迟目标计算错误预算、燃烧率与红绿灯状态。
+// 可用性预算为目标允许失败的请求比例；延迟预算为 P95 目标允许超时的 5% 请求。两类预算剩余超过
+// WarningBudgetRemaining 为 healthy，预算未耗尽为 warning，耗尽为 critical。
+package slo
+
+import (
+	"math"
+	"time"
+)
+
+// WarningBudgetRemaining is the remaining error budget (%) below which an SLO turns warning.
+const WarningBudgetRemaining = 25.0
+
+// latencyBudget is the share (%) of requests a P95 latency target allows to be slower.
+const latencyBudget = 5.0
+
+// BudgetStatus is the status of an SLO with the given remaining error budget (%).
+func BudgetStatus(remaining float64) SLOStatus {
+	switch {
+	case remaining <= 0:
+		return SLOStatusCritical
+	case remaining < WarningBudgetRemaining:
+		return SLOStatusWarning
+	}
+	return SLOStatusHealthy
+}
+
+// WorstStatus returns the most severe of the statuses; healthy without any.
+func WorstStatus(statuses ...SLOStatus) SLOStatus {
+	worst := SLOStatusHealthy
+	for _, s := range statuses {
+		if StatusSeverity(s) > StatusSeverity(worst) {
+			worst = s
+		}
+	}
+	return worst
+}
+
+// StatusSeverity orders statuses: healthy 0, warning 1, critical 2.
+func StatusSeverity(s SLOStatus) int {
+	switch s {
+	case SLOStatusCritical:
+		return 2
+	case SLOStatusWarning:
+		return 1
+	}
+	return 0
+}
+
+// EvaluateAvailability scores the requests of [start, end] against an availability target (%, e.g.
+// 99.9). Without requests the SLO is met with its whole budget.
+func EvaluateAvailability(start, end time.Time, total, failed int64, target float64) AvailabilityScore {
+	score := AvailabilityScore{
+		StartTime:              start,
+		EndTime:                end,
+		TotalRequests:          total,
+		SuccessfulRequests:     total - failed,
+		FailedRequests:         failed,
+		AvailabilityPercentage: 100,
+		TargetSLO:              target,
+	}
+	if total > 0 {
+		score.AvailabilityPercentage = float64(total-failed) / float64(total) * 100
+	}
+	score.BurnRate, score.ErrorBudgetConsumed, score.ErrorBudgetRemaining = budget(100-score.AvailabilityPercentage, 100-target)
+	score.ComplianceStatus = BudgetStatus(score.ErrorBudgetRemaining)
+	return score
+}
+
+// LatencyHistogram is a cumulative latency histogram: Counts[i] requests took at most Bounds[i]
+// milliseconds; Count is the total including requests above the last bound and Sum their total
+// latency in milliseconds.
+type LatencyHistogram struct {
+	Bounds []float64
+	Counts []int64
+	Count  int64
+	Sum    float64
+}
+
+// Quantile estimates the q-quantile (0-1) in milliseconds by linear interpolation within the
+// bucket it falls in, like PromQL histogram_quantile. Quantiles above the last bound return the
+// last bound; 0 without samples.
+func (h LatencyHistogram) Quantile(q float64) float64 {
+	if h.Count == 0 || len(h.Bounds) == 0 {
+		return 0
+	}
+	rank := q * float64(h.Count)
+	lower, below := 0.0, int64(0)
+	for i, bound := range h.Bounds {
+		if float64(h.Counts[i]) >= rank {
+			inBucket := h.Counts[i] - below
+			if inBucket == 0 {
+				return bound
+			}
+			return lower + (bound-lower)*(rank-float64(below))/float64(inBucket)
+		}
+		lower, below = bound, h.Counts[i]
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
+
+// Above estimates the number of requests slower than ms, interpolating within its bucket.
+func (h LatencyHistogram) Above(ms float64) int64 {
+	lower, below := 0.0, int64(0)
+	for i, bound := range h.Bounds {
+		if ms <= bound {
+			within := float64(h.Counts[i]-below) * (ms - lower) / (bound - lower)
+			return h.Count - below - int64(math.Round(within))
+		}
+		lower, below = bound, h.Counts[i]
+	}
+	return h.Count - below
+}
+
+// EvaluateLatency scores the latency histogram of [start, end] against a P95 target in
+// milliseconds: the error budget is the 5% of requests the target allows to be slower.
+func EvaluateLatency(start, end time.Time, h LatencyHistogram, targetMs float64) LatencyP95 {
+	l := LatencyP95{
+		StartTime:     start,
+		EndTime:       end,
+		SampleCount:   h.Count,
+		P50:           h.Quantile(0.50),
+		P75:           h.Quantile(0.75),
+		P90:           h.Quantile(0.90),
+		P95:           h.Quantile(0.95),
+		P99:           h.Quantile(0.99),
+		P99_9:         h.Quantile(0.999),
+		TargetLatency: targetMs,
+	}
+	if h.Count > 0 {
+		l.Average = h.Sum / float64(h.Count)
+		l.ViolationCount = h.Above(targetMs)
+		l.ViolationPercentage = float64(l.ViolationCount) / float64(h.Count) * 100
+	}
+	_, _, remaining := budget(l.ViolationPercentage, latencyBudget)
+	l.ComplianceStatus = BudgetStatus(remaining)
+	return l
+}
+
+// LatencyBudgetRemaining is the remaining latency error budget (%) of an evaluated latency SLO.
+func LatencyBudgetRemaining(l LatencyP95) float64 {
+	_, _, remaining := budget(l.ViolationPercentage, latencyBudget)
+	return remaining
+}
+
+// budget returns the burn rate (bad share / allowed share) and the consumed and remaining error
+// budget (%) for a bad share and an allowed share in percent; an overspent budget has a negative
+// remainder. A zero allowance is consumed by the first bad request (burn rate undefined, 0).
+func budget(bad, allowed float64) (burnRate, consumed, remaining float64) {
+	switch {
+	case bad <= 0:
+		return 0, 0, 100
+	case allowed <= 0:
+		return 0, 100, 0
+	}
+	burnRate = bad / allowed
+	consumed = burnRate * 100
+	return burnRate, consumed, 100 - consumed
+}
@@ -0,0 +1,51 @@
+package slo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEvaluateAvailability(t *testing.T) {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	for name, tc := range map[string]struct {
+		total, failed int64
+		remaining     float64
+		status        SLOStatus
+	}{
+		"no requests": {0, 0, 100, SLOStatusHealthy},
+		"half budget": {10000, 5, 50, SLOStatusHealthy},
+		"warning":     {10000, 8, 20, SLOStatusWarning},
+		"exhausted":   {10000, 20, -100, SLOStatusCritical},
+	} {
+		s := EvaluateAvailability(start, end, tc.total, tc.failed, 99.9)
+		if math.Abs(s.ErrorBudgetRemaining-tc.remaining) > 1e-6 || s.ComplianceStatus != tc.status {
+			t.Errorf("%s: remaining %v status %s, want %v %s", name, s.ErrorBudgetRemaining, s.ComplianceStatus, tc.remaining, tc.status)
+		}
+	}
+}
+
+func TestEvaluateLatency(t *testing.T) {
+	// 100 requests: 90 within 100ms, 8 within 200ms, 2 within 500ms
+	h := LatencyHistogram{Bounds: []float64{100, 200, 500}, Counts: []int64{90, 98, 100}, Count: 100, Sum: 6000}
+	if p := h.Quantile(0.95); math.Abs(p-162.5) > 1e-6 {
+		t.Errorf("p95 = %v, want 162.5", p)
+	}
+	if n := h.Above(150); n != 6 {
+		t.Errorf("above 150ms = %d, want 6", n)
+	}
+	l := EvaluateLatency(time.Time{}, time.Time{}, h, 200)
+	if l.ViolationCount != 2 || l.Average != 60 || l.ComplianceStatus != SLOStatusHealthy {
+		t.Errorf("latency = %+v", l)
+	}
+	if r := LatencyBudgetRemaining(l); math.Abs(r-60) > 1e-6 {
+		t.Errorf("remaining = %v, want 60", r)
+	}
+	if l := EvaluateLatency(time.Time{}, time.Time{}, h, 100); l.ComplianceStatus != SLOStatusCritical {
+		t.Errorf("10%% slow against a 5%% budget should be critical, got %s", l.ComplianceStatus)
+	}
+	if WorstStatus(SLOStatusHealthy, SLOStatusCritical, SLOStatusWarning) != SLOStatusCritical || WorstStatus() != SLOStatusHealthy {
+		t.Error("WorstStatus should return the most severe status")
+	}
+}
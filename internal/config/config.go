@@ -1,6 +1,13 @@
 package config
 
-import "time"
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+)
 
 // Environment 应用环境类型
 type Environment string
@@ -19,6 +26,52 @@ type ServerConfig struct {
 	LogLevel     string        `mapstructure:"log_level" env:"LOG_LEVEL"`
 	MaxConn      int           `mapstructure:"max_conn" env:"SERVER_MAX_CONN"`
 	GracePeriod  time.Duration `mapstructure:"grace_period" env:"SERVER_GRACE_PERIOD"`
+	// SelfSLO：Lighthouse 自身 API 按路由的可用性 / P95 延迟 SLO（GET /api/v1/slo/self）
+	SelfSLO SelfSLOConfig `mapstructure:"self_slo"`
+	// Canary：管道合成监控，定期端到端检查 合成指标 → 计算 → 存储 → API 读回
+	Canary CanaryConfig `mapstructure:"canary"`
+}
+
+// CanaryConfig 管道合成监控：interval 为检查间隔（0 取 5m），端到端超过 max_latency（0 不限）或读回结果不符时告警
+// （pipeline_canary）；base_url 为读回所用的 API 地址，空则取本机 server.port
+type CanaryConfig struct {
+	Enabled    bool          `mapstructure:"enabled" env:"SERVER_CANARY_ENABLED"`
+	Interval   time.Duration `mapstructure:"interval" env:"SERVER_CANARY_INTERVAL"`
+	MaxLatency time.Duration `mapstructure:"max_latency" env:"SERVER_CANARY_MAX_LATENCY"`
+	BaseURL    string        `mapstructure:"base_url" env:"SERVER_CANARY_BASE_URL"`
+	APIKey     string        `mapstructure:"-" env:"SERVER_CANARY_API_KEY"` // 敏感字段，security.api_keys.required 时需要
+}
+
+// SelfSLOConfig 自身 API SLO：默认目标为 0 时取 business.slo 的可用性与延迟阈值，routes 按 "METHOD /path"
+// （注册的路由模式）覆盖单个路由的目标；window 为评估窗口，默认 24h
+type SelfSLOConfig struct {
+	Enabled            bool                     `mapstructure:"enabled" env:"SERVER_SELF_SLO_ENABLED"`
+	Window             time.Duration            `mapstructure:"window" env:"SERVER_SELF_SLO_WINDOW"`
+	AvailabilityTarget float64                  `mapstructure:"availability_target"` // %
+	LatencyTargetMs    float64                  `mapstructure:"latency_target_ms"`   // P95
+	Routes             map[string]SelfSLOTarget `mapstructure:"routes"`
+}
+
+// SelfSLOTarget 单个路由的 SLO 目标，0 取默认
+type SelfSLOTarget struct {
+	AvailabilityTarget float64 `mapstructure:"availability_target"`
+	LatencyTargetMs    float64 `mapstructure:"latency_target_ms"`
+}
+
+// RequestDeadline 单个请求的处理时限：WriteTimeout 预留 10% 用于写出超时响应，0 表示不设时限
+func (s ServerConfig) RequestDeadline() time.Duration {
+	return s.WriteTimeout - s.WriteTimeout/10
+}
+
+// 存储驱动配置：memory / mock / file / postgres，边缘集群可用 file 替代 PostgreSQL
+type StorageConfig struct {
+	Driver   string `mapstructure:"driver" env:"STORAGE_DRIVER"`
+	FilePath string `mapstructure:"file_path" env:"STORAGE_FILE_PATH"` // file 驱动数据文件
+	// file 驱动合并落盘间隔：间隔内的写入合并为一次整体重写，崩溃时最多丢失该间隔内的写入；0 取 1s，负数为每次写入同步落盘
+	FileFlushInterval time.Duration `mapstructure:"file_flush_interval" env:"STORAGE_FILE_FLUSH_INTERVAL"`
+	// 降级模式：存储不可达时只读返回最近缓存结果（响应头 X-Lighthouse-Stale），健康检查恢复后自动退出
+	DegradedMode        bool          `mapstructure:"degraded_mode" env:"STORAGE_DEGRADED_MODE"`
+	HealthProbeInterval time.Duration `mapstructure:"health_probe_interval" env:"STORAGE_HEALTH_PROBE_INTERVAL"`
 }
 
 // PostgreSQL控制平面配置 (Control Plane)
@@ -33,6 +86,25 @@ type PostgresConfig struct {
 	MaxIdleConns    int           `mapstructure:"max_idle_conns" env:"PG_MAX_IDLE_CONNS"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" env:"PG_CONN_MAX_LIFETIME"`
 	MigrationPath   string        `mapstructure:"migration_path" env:"PG_MIGRATION_PATH"`
+	AutoMigrate     bool          `mapstructure:"auto_migrate" env:"PG_AUTO_MIGRATE"` // 启动时应用内置 schema（需 DDL 权限）
+}
+
+// DSN postgres:// 连接串，用户名与密码做 URL 转义；SSLMode 为空时不设置（驱动默认 prefer）
+func (c PostgresConfig) DSN() string {
+	u := url.URL{Scheme: "postgres", Host: c.Host, Path: "/" + c.Database}
+	if c.Port > 0 {
+		u.Host = net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	}
+	switch {
+	case c.User != "" && c.Password != "":
+		u.User = url.UserPassword(c.User, c.Password)
+	case c.User != "":
+		u.User = url.User(c.User)
+	}
+	if c.SSLMode != "" {
+		u.RawQuery = url.Values{"sslmode": {c.SSLMode}}.Encode()
+	}
+	return u.String()
 }
 
 // ClickHouse证据平面配置 (Evidence Plane)
@@ -47,6 +119,8 @@ type ClickHouseConfig struct {
 	Compression  bool   `mapstructure:"compression" env:"CH_COMPRESSION"`
 	MaxOpenConns int    `mapstructure:"max_open_conns" env:"CH_MAX_OPEN_CONNS"`
 	MaxIdleConns int    `mapstructure:"max_idle_conns" env:"CH_MAX_IDLE_CONNS"`
+	// 写入日志批次的重试策略
+	Retry RetryConfig `mapstructure:"retry"`
 }
 
 // Prometheus信号平面配置 (Signal Plane)
@@ -58,20 +132,55 @@ type PrometheusConfig struct {
 	QueryConcurrency int           `mapstructure:"query_concurrency" env:"PROMETHEUS_QUERY_CONCURRENCY"`
 	BearerToken      string        `mapstructure:"-" env:"PROMETHEUS_BEARER_TOKEN"` // 敏感字段
 	SkipTLSVerify    bool          `mapstructure:"skip_tls_verify" env:"PROMETHEUS_SKIP_TLS_VERIFY"`
+
+	// 查询代价保护（prometheus.GuardedClient）：单个 selector 匹配的 series 超过 MaxSeries 直接拒绝；
+	// series × step 数超过 MaxSamples 时按时间切分为多段顺序查询。0 表示不限制
+	MaxSeries  int `mapstructure:"max_series" env:"PROMETHEUS_MAX_SERIES"`
+	MaxSamples int `mapstructure:"max_samples" env:"PROMETHEUS_MAX_SAMPLES"`
+	// 查询的重试策略（超时、5xx 等临时错误）
+	Retry RetryConfig `mapstructure:"retry"`
 }
 
 // Kubernetes配置
 type KubernetesConfig struct {
+	// 客户端：mock（默认，生成数据）/ cluster（经 client-go 访问集群，演示模式下仍用 mock）
+	Client          string `mapstructure:"client" env:"K8S_CLIENT"`
 	APIServer       string `mapstructure:"api_server" env:"K8S_API_SERVER"`
 	Namespace       string `mapstructure:"namespace" env:"K8S_NAMESPACE"`
 	ServiceAccount  string `mapstructure:"service_account" env:"K8S_SERVICE_ACCOUNT"`
 	BearerTokenFile string `mapstructure:"bearer_token_file" env:"K8S_BEARER_TOKEN_FILE"`
 	InCluster       bool   `mapstructure:"in_cluster" env:"K8S_IN_CLUSTER"`
-	RBAC            struct {
+	// in_cluster 为 false 时使用的 kubeconfig（空取 $KUBECONFIG 或 ~/.kube/config）及其 context（空取 current-context）
+	Kubeconfig string `mapstructure:"kubeconfig" env:"K8S_KUBECONFIG"`
+	Context    string `mapstructure:"context" env:"K8S_CONTEXT"`
+	RBAC       struct {
 		Enabled         bool `mapstructure:"enabled" env:"K8S_RBAC_ENABLED"`
 		ReadOnlyAccess  bool `mapstructure:"read_only_access" env:"K8S_READ_ONLY_ACCESS"`
 		NamespaceScoped bool `mapstructure:"namespace_scoped" env:"K8S_NAMESPACE_SCOPED"`
 	} `mapstructure:"rbac"`
+	// 成本注解控制器：定期把近 30 天成本与效率等级写回工作负载注解，需要 patch 工作负载的权限
+	CostAnnotations CostAnnotationConfig `mapstructure:"cost_annotations"`
+	// 准入阶段成本估算 webhook（/admission/validate、/admission/mutate）
+	Admission AdmissionWebhookConfig `mapstructure:"admission"`
+	// API Server 请求的重试策略
+	Retry RetryConfig `mapstructure:"retry"`
+	// 成本结果附带的 namespace / 工作负载元数据（app、owner、tier、environment 标签）的缓存时间，0 取 5m
+	MetadataCacheTTL time.Duration `mapstructure:"metadata_cache_ttl" env:"K8S_METADATA_CACHE_TTL"`
+}
+
+// AdmissionWebhookConfig 准入成本估算 webhook 配置。团队预算取 notifier.digest.teams 的 monthly_budget。
+type AdmissionWebhookConfig struct {
+	Enabled bool `mapstructure:"enabled" env:"K8S_ADMISSION_ENABLED"`
+	// 月度成本（近 30 天运行速率 + 新增估算）将超出 namespace 或团队预算时：none / warn（默认，放行并告警）/ reject
+	BudgetAction     string             `mapstructure:"budget_action" env:"K8S_ADMISSION_BUDGET_ACTION"`
+	NamespaceBudgets map[string]float64 `mapstructure:"namespace_budgets"` // namespace -> 月度预算
+}
+
+// CostAnnotationConfig 成本注解控制器配置（lighthouse.io/monthly-cost、lighthouse.io/grade）。
+type CostAnnotationConfig struct {
+	Enabled    bool          `mapstructure:"enabled" env:"K8S_COST_ANNOTATIONS_ENABLED"`
+	Interval   time.Duration `mapstructure:"interval" env:"K8S_COST_ANNOTATIONS_INTERVAL"` // 0 取 1h
+	Namespaces []string      `mapstructure:"namespaces"`                                   // 为空时写回所有 namespace
 }
 
 // Analysis Engine配置
@@ -82,6 +191,188 @@ type AnalysisEngineConfig struct {
 	MaxRetries    int           `mapstructure:"max_retries" env:"ANALYSIS_ENGINE_MAX_RETRIES"`
 	RetryDelay    time.Duration `mapstructure:"retry_delay" env:"ANALYSIS_ENGINE_RETRY_DELAY"`
 	EnableTracing bool          `mapstructure:"enable_tracing" env:"ANALYSIS_ENGINE_ENABLE_TRACING"`
+	// 熔断：连续失败 BreakerFailureThreshold 次后熔断 BreakerOpenTimeout，<=0 关闭熔断
+	BreakerFailureThreshold int           `mapstructure:"breaker_failure_threshold" env:"ANALYSIS_ENGINE_BREAKER_FAILURE_THRESHOLD"`
+	BreakerOpenTimeout      time.Duration `mapstructure:"breaker_open_timeout" env:"ANALYSIS_ENGINE_BREAKER_OPEN_TIMEOUT"`
+	// 重试策略；retry.max_attempts 未设置时沿用 max_retries + 1 次尝试、从 retry_delay 开始指数退避
+	Retry RetryConfig `mapstructure:"retry"`
+}
+
+// RetryConfig 外部客户端的重试策略（retry.Policy），按客户端分别配置；0 取默认：共 3 次尝试，
+// 从 200ms 开始按 multiplier（默认 2）指数退避、最长 5s，每次等待随机 ±jitter（默认 0.2）。
+// max_attempts 为 1 关闭重试。只重试临时错误（不可用、网络错误、5xx/429），不重试参数错误与取消
+type RetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+	Multiplier     float64       `mapstructure:"multiplier"`
+	Jitter         float64       `mapstructure:"jitter"` // 0-1
+}
+
+// 外部数据源熔断配置（server 内的 Prometheus / K8s 客户端，按 target 独立熔断）
+type CircuitBreakerConfig struct {
+	Enabled              bool          `mapstructure:"enabled" env:"CB_ENABLED"`
+	FailureRateThreshold float64       `mapstructure:"failure_rate_threshold" env:"CB_FAILURE_RATE_THRESHOLD"` // 0-1
+	MinRequests          int           `mapstructure:"min_requests" env:"CB_MIN_REQUESTS"`                     // 窗口内最少请求数才计算错误率
+	Window               time.Duration `mapstructure:"window" env:"CB_WINDOW"`
+	OpenTimeout          time.Duration `mapstructure:"open_timeout" env:"CB_OPEN_TIMEOUT"`
+	HalfOpenProbes       int           `mapstructure:"half_open_probes" env:"CB_HALF_OPEN_PROBES"`
+}
+
+// 故障演练配置：管理员经 /api/v1/admin/chaos 在限定时间窗口内启用某个场景，向 Prometheus / K8s / 数据库客户端
+// 注入与 Mock 相同的错误率与延迟，用于在 staging 演练降级行为；prod 环境不可启用
+type ChaosConfig struct {
+	Enabled     bool                           `mapstructure:"enabled" env:"CHAOS_ENABLED"`
+	MaxDuration time.Duration                  `mapstructure:"max_duration" env:"CHAOS_MAX_DURATION"` // 单次演练最长时间，0 取 1h
+	Scenarios   map[string]ChaosScenarioConfig `mapstructure:"scenarios"`
+}
+
+// ChaosScenarioConfig 单个演练场景按数据源的故障，未配置的数据源不受影响
+type ChaosScenarioConfig struct {
+	Prometheus ChaosFaultConfig `mapstructure:"prometheus"`
+	Kubernetes ChaosFaultConfig `mapstructure:"kubernetes"`
+	Database   ChaosFaultConfig `mapstructure:"database"`
+}
+
+// ChaosFaultConfig 注入单个数据源的故障，字段含义同 Mock 配置（见 latency.Profile）
+type ChaosFaultConfig struct {
+	ErrorRate       float64 `mapstructure:"error_rate"` // 0-1
+	LatencyMs       int     `mapstructure:"latency_ms"`
+	LatencyJitterMs int     `mapstructure:"latency_jitter_ms"`
+	TailLatencyRate float64 `mapstructure:"tail_latency_rate"` // 0-1
+	TailLatencyMs   int     `mapstructure:"tail_latency_ms"`
+}
+
+// 云账单配置（cloudbilling 工厂入参）：enabled 时服务端在 leader 上按 interval 拉取当前与上一账期的账单，
+// 写入 cost_bill_account_summary
+type CloudBillingConfig struct {
+	Enabled         bool          `mapstructure:"enabled" env:"CLOUD_BILL_ENABLED"`
+	Interval        time.Duration `mapstructure:"interval" env:"CLOUD_BILL_INTERVAL"` // 默认 24h
+	Provider        string        `mapstructure:"provider" env:"CLOUD_BILL_PROVIDER"` // aliyun（已实现）/aws/tencent，为空则不拉取
+	Endpoint        string        `mapstructure:"endpoint" env:"CLOUD_BILL_ENDPOINT"`
+	AccountID       string        `mapstructure:"account_id" env:"CLOUD_BILL_ACCOUNT_ID"`
+	PeriodType      string        `mapstructure:"period_type" env:"CLOUD_BILL_PERIOD_TYPE"` // day/month
+	AccessKeyID     string        `mapstructure:"-" env:"CLOUD_BILL_AK"`                    // 敏感字段
+	AccessKeySecret string        `mapstructure:"-" env:"CLOUD_BILL_SK"`                    // 敏感字段
+}
+
+// 告警通知配置
+type NotifierConfig struct {
+	RateLimitPerMinute int           `mapstructure:"rate_limit_per_minute" env:"NOTIFIER_RATE_LIMIT_PER_MINUTE"`
+	MaxRetries         int           `mapstructure:"max_retries" env:"NOTIFIER_MAX_RETRIES"`
+	RetryDelay         time.Duration `mapstructure:"retry_delay" env:"NOTIFIER_RETRY_DELAY"`
+
+	Slack struct {
+		Enabled    bool              `mapstructure:"enabled" env:"NOTIFIER_SLACK_ENABLED"`
+		WebhookURL string            `mapstructure:"-" env:"NOTIFIER_SLACK_WEBHOOK_URL"` // 敏感字段
+		Channel    string            `mapstructure:"channel" env:"NOTIFIER_SLACK_CHANNEL"`
+		Username   string            `mapstructure:"username" env:"NOTIFIER_SLACK_USERNAME"`
+		Channels   map[string]string `mapstructure:"channels"` // 告警类型 -> 频道覆盖
+	} `mapstructure:"slack"`
+
+	DingTalk struct {
+		Enabled    bool     `mapstructure:"enabled" env:"NOTIFIER_DINGTALK_ENABLED"`
+		WebhookURL string   `mapstructure:"-" env:"NOTIFIER_DINGTALK_WEBHOOK_URL"` // 敏感字段
+		Secret     string   `mapstructure:"-" env:"NOTIFIER_DINGTALK_SECRET"`      // 敏感字段，加签密钥
+		AtMobiles  []string `mapstructure:"at_mobiles" env:"NOTIFIER_DINGTALK_AT_MOBILES"`
+	} `mapstructure:"dingtalk"`
+
+	WeCom struct {
+		Enabled    bool   `mapstructure:"enabled" env:"NOTIFIER_WECOM_ENABLED"`
+		WebhookURL string `mapstructure:"-" env:"NOTIFIER_WECOM_WEBHOOK_URL"` // 敏感字段
+	} `mapstructure:"wecom"`
+
+	// Paging：仅 SLO critical 或极端燃烧率时触发
+	Paging struct {
+		ExtremeBurnRate     float64 `mapstructure:"extreme_burn_rate" env:"NOTIFIER_PAGING_EXTREME_BURN_RATE"`
+		PagerDutyEnabled    bool    `mapstructure:"pagerduty_enabled" env:"NOTIFIER_PAGERDUTY_ENABLED"`
+		PagerDutyRoutingKey string  `mapstructure:"-" env:"NOTIFIER_PAGERDUTY_ROUTING_KEY"` // 敏感字段
+		OpsgenieEnabled     bool    `mapstructure:"opsgenie_enabled" env:"NOTIFIER_OPSGENIE_ENABLED"`
+		OpsgenieAPIURL      string  `mapstructure:"opsgenie_api_url" env:"NOTIFIER_OPSGENIE_API_URL"`
+		OpsgenieAPIKey      string  `mapstructure:"-" env:"NOTIFIER_OPSGENIE_API_KEY"` // 敏感字段
+	} `mapstructure:"paging"`
+
+	// Email：定时报表 SMTP 投递
+	Email struct {
+		Enabled            bool                `mapstructure:"enabled" env:"NOTIFIER_EMAIL_ENABLED"`
+		Host               string              `mapstructure:"host" env:"SMTP_HOST"`
+		Port               int                 `mapstructure:"port" env:"SMTP_PORT"`
+		Username           string              `mapstructure:"username" env:"SMTP_USERNAME"`
+		Password           string              `mapstructure:"-" env:"SMTP_PASSWORD"` // 敏感字段
+		From               string              `mapstructure:"from" env:"SMTP_FROM"`
+		TLSMode            string              `mapstructure:"tls_mode" env:"SMTP_TLS_MODE"` // none/starttls/tls
+		InsecureSkipVerify bool                `mapstructure:"insecure_skip_verify" env:"SMTP_INSECURE_SKIP_VERIFY"`
+		Owners             map[string][]string `mapstructure:"owners"` // namespace -> 报表接收人，"*" 为兜底
+	} `mapstructure:"email"`
+
+	// Digest：按团队的周度优化建议摘要，经 email 与聊天渠道投递
+	Digest struct {
+		Enabled      bool                        `mapstructure:"enabled" env:"NOTIFIER_DIGEST_ENABLED"`
+		Interval     time.Duration               `mapstructure:"interval" env:"NOTIFIER_DIGEST_INTERVAL"` // 默认 168h
+		Currency     string                      `mapstructure:"currency" env:"NOTIFIER_DIGEST_CURRENCY"`
+		DashboardURL string                      `mapstructure:"dashboard_url" env:"NOTIFIER_DIGEST_DASHBOARD_URL"`
+		Teams        map[string]DigestTeamConfig `mapstructure:"teams"` // 团队名 -> namespace 与预算
+	} `mapstructure:"digest"`
+}
+
+// 团队摘要配置：Recipients 为空时使用 email.owners 中各 namespace 的归属人
+type DigestTeamConfig struct {
+	Namespaces    []string `mapstructure:"namespaces"`
+	Recipients    []string `mapstructure:"recipients"`
+	MonthlyBudget float64  `mapstructure:"monthly_budget"` // 0 表示不报告预算状态
+	Locale        string   `mapstructure:"locale"`         // 摘要语言（en / zh-CN），空取 i18n.default_locale
+}
+
+// 国际化配置：API 按 Accept-Language 协商语言，无匹配时与通知、报表一样使用 default_locale（en / zh-CN）
+type I18nConfig struct {
+	DefaultLocale string `mapstructure:"default_locale" env:"I18N_DEFAULT_LOCALE"`
+}
+
+// Locale 配置的默认语言，未配置时为 i18n.Default
+func (c I18nConfig) Locale() i18n.Locale {
+	if locale, ok := i18n.Parse(c.DefaultLocale); ok {
+		return locale
+	}
+	return i18n.Default
+}
+
+// 对象存储归档配置（S3 / OSS / MinIO），CostSnapshot 与 EvidenceChain 在创建时归档
+type ArchiveConfig struct {
+	Enabled         bool          `mapstructure:"enabled" env:"ARCHIVE_ENABLED"`
+	Endpoint        string        `mapstructure:"endpoint" env:"ARCHIVE_ENDPOINT"`
+	Region          string        `mapstructure:"region" env:"ARCHIVE_REGION"`
+	Bucket          string        `mapstructure:"bucket" env:"ARCHIVE_BUCKET"`
+	Prefix          string        `mapstructure:"prefix" env:"ARCHIVE_PREFIX"`
+	AccessKeyID     string        `mapstructure:"-" env:"ARCHIVE_ACCESS_KEY_ID"`               // 敏感字段
+	SecretAccessKey string        `mapstructure:"-" env:"ARCHIVE_SECRET_ACCESS_KEY"`           // 敏感字段
+	UsePathStyle    bool          `mapstructure:"use_path_style" env:"ARCHIVE_USE_PATH_STYLE"` // MinIO 需开启
+	Retention       time.Duration `mapstructure:"retention" env:"ARCHIVE_RETENTION"`           // 归档保留时间，0 为永久
+}
+
+// 事件总线配置：领域事件（快照生成、预算超支、SLO 违约、僵尸工作负载、建议下发、等级变化）经 Kafka REST Proxy
+// 写入 Kafka；配置 spool.dir 时未确认事件落盘到 <spool.dir>/events，重启后继续投递，否则只保存在内存中
+type EventBusConfig struct {
+	Enabled       bool          `mapstructure:"enabled" env:"EVENTBUS_ENABLED"`
+	RESTProxyURL  string        `mapstructure:"rest_proxy_url" env:"EVENTBUS_KAFKA_REST_PROXY_URL"`
+	Topic         string        `mapstructure:"topic" env:"EVENTBUS_KAFKA_TOPIC"`
+	Username      string        `mapstructure:"username" env:"EVENTBUS_KAFKA_USERNAME"`
+	Password      string        `mapstructure:"-" env:"EVENTBUS_KAFKA_PASSWORD"` // 敏感字段
+	FlushInterval time.Duration `mapstructure:"flush_interval" env:"EVENTBUS_FLUSH_INTERVAL"`
+	MaxRetries    int           `mapstructure:"max_retries" env:"EVENTBUS_MAX_RETRIES"`
+	OutboxSize    int           `mapstructure:"outbox_size" env:"EVENTBUS_OUTBOX_SIZE"`
+}
+
+// 失败写入 spool（dead-letter）配置：写库失败的批次落盘，下个周期回放
+type SpoolConfig struct {
+	Dir        string `mapstructure:"dir" env:"SPOOL_DIR"`                 // 为空则不启用
+	MaxEntries int    `mapstructure:"max_entries" env:"SPOOL_MAX_ENTRIES"` // 积压批次上限，0 为不限
+}
+
+// 异步导出任务配置：worker 生成的 CSV / PDF 文件写入 dir，成功后在 retention 内可下载，过期删除
+type ExportConfig struct {
+	Dir       string        `mapstructure:"dir" env:"EXPORT_DIR"`             // 为空则使用系统临时目录下的 lighthouse-exports
+	Retention time.Duration `mapstructure:"retention" env:"EXPORT_RETENTION"` // 0 取 24h
+	Workers   int           `mapstructure:"workers" env:"EXPORT_WORKERS"`     // 并发导出数，0 取 2
 }
 
 // 数据保留策略配置
@@ -91,6 +382,7 @@ type RetentionConfig struct {
 		Incidents      time.Duration `mapstructure:"incidents" env:"RETENTION_PG_INCIDENTS"`             // 故障快照元数据
 		DailySnapshots time.Duration `mapstructure:"daily_snapshots" env:"RETENTION_PG_DAILY_SNAPSHOTS"` // 日报
 		CostHistory    time.Duration `mapstructure:"cost_history" env:"RETENTION_PG_COST_HISTORY"`       // 成本历史
+		HourlyStats    time.Duration `mapstructure:"hourly_stats" env:"RETENTION_PG_HOURLY_STATS"`       // 小时级 pod 统计全分辨率保留，之后降采样为工作负载日汇总
 	} `mapstructure:"postgres"`
 
 	// ClickHouse证据平面保留策略
@@ -109,12 +401,16 @@ type BusinessConfig struct {
 		MemPricePerGBHour    float64       `mapstructure:"mem_price_per_gb_hour" env:"COST_MEM_PRICE"`
 		CalculationInterval  time.Duration `mapstructure:"calculation_interval" env:"COST_CALCULATION_INTERVAL"`
 		AggregationLevels    []string      `mapstructure:"aggregation_levels" env:"COST_AGGREGATION_LEVELS"`
+		NonFinitePolicy      string        `mapstructure:"non_finite_policy" env:"COST_NON_FINITE_POLICY"` // zero / drop / fail
 		EfficiencyThresholds struct {
 			Zombie          float64 `mapstructure:"zombie" env:"COST_EFFICIENCY_ZOMBIE_THRESHOLD"`
 			OverProvisioned float64 `mapstructure:"over_provisioned" env:"COST_EFFICIENCY_OVER_PROVISIONED_THRESHOLD"`
 			Healthy         float64 `mapstructure:"healthy" env:"COST_EFFICIENCY_HEALTHY_THRESHOLD"`
 			Danger          float64 `mapstructure:"danger" env:"COST_EFFICIENCY_DANGER_THRESHOLD"`
 		} `mapstructure:"efficiency_thresholds"`
+		// Calculator 成本计算器（costmodel.RegisterCalculator 注册的名称，空为 standard）及其参数
+		Calculator        string                 `mapstructure:"calculator" env:"COST_CALCULATOR"`
+		CalculatorOptions map[string]interface{} `mapstructure:"calculator_options"`
 	} `mapstructure:"cost_calculation"`
 
 	SLO struct {
@@ -134,6 +430,122 @@ type BusinessConfig struct {
 		TrackingFrequency time.Duration `mapstructure:"tracking_frequency" env:"ROI_TRACKING_FREQUENCY"`
 		Metrics           []string      `mapstructure:"metrics" env:"ROI_METRICS"`
 	} `mapstructure:"roi"`
+
+	// SharedCosts：集群共享资源（入口 LB、共享 NFS/Ceph），按日分摊到 namespace 的 SharedCost
+	SharedCosts []SharedCostConfig `mapstructure:"shared_costs"`
+
+	// SnapshotGuardrail：快照总成本相对上一快照的变化率检查
+	SnapshotGuardrail SnapshotGuardrailConfig `mapstructure:"snapshot_guardrail"`
+
+	// PriceApproval：单价变更审批（/api/v1/pricing/changes），审批人需 pricing:approve scope
+	PriceApproval PriceApprovalConfig `mapstructure:"price_approval"`
+
+	// StaleData：数据新鲜度看门狗，最新小时统计或成本快照超过 factor × calculation_interval 即告警
+	StaleData StaleDataConfig `mapstructure:"stale_data"`
+
+	// OffHours：非工作时间空闲成本分析（/api/v1/analysis/offhours）
+	OffHours OffHoursConfig `mapstructure:"off_hours"`
+
+	// AccountingTimeZone：财务关账时区（IANA 名称，如 Asia/Shanghai），日汇总、日期过滤与报表的“天/月”按该时区切分；空为 UTC
+	AccountingTimeZone string `mapstructure:"accounting_time_zone" env:"COST_ACCOUNTING_TIME_ZONE"`
+
+	// Fiscal：财年定义，预算摘要的“本期/本季度至今”与 /api/v1/cost/fiscal-report 按其切分；不配置为自然年月
+	Fiscal FiscalConfig `mapstructure:"fiscal"`
+
+	// GradeView：快照等级数量默认提供的视图，original（保存时的等级）或 regraded（按当前 efficiency_thresholds 重评级，
+	// 由 POST /api/v1/admin/regrade 生成）；空为 original，运行时可经 PUT /api/v1/admin/regrade/view 切换
+	GradeView string `mapstructure:"grade_view" env:"COST_GRADE_VIEW"`
+
+	// NodePoolLabels：标识节点所属节点池的节点标签，按顺序取第一个存在的；空为 ACK/GKE/EKS/AKS/Karpenter 节点池标签
+	// 与 node-type（见 costmodel.DefaultNodePoolLabels），均无时归入 default 池
+	NodePoolLabels []string `mapstructure:"node_pool_labels" env:"COST_NODE_POOL_LABELS"`
+
+	// RightsizingSafety：缩容/下线建议的安全检查（近期 CPU 限流率与 SLO 状态），风险建议被标记或抑制
+	RightsizingSafety RightsizingSafetyConfig `mapstructure:"rightsizing_safety"`
+
+	// Dependencies：服务依赖图（影响分析：哪些上游服务与多少支出依赖一个降级的工作负载）
+	Dependencies DependencyConfig `mapstructure:"dependencies"`
+
+	// Onboarding：namespace 自助接入（POST /api/v1/admin/onboarding）请求未指定时使用的默认值
+	Onboarding OnboardingConfig `mapstructure:"onboarding"`
+}
+
+// namespace 自助接入默认值：default_monthly_budget <= 0 时请求未指定预算即不创建预算告警；default_efficiency_target
+// 为 0 取 60；slo_templates 为默认启用的 SLO 告警模板（availability / error_budget），空为全部
+type OnboardingConfig struct {
+	DefaultMonthlyBudget    float64  `mapstructure:"default_monthly_budget" env:"ONBOARDING_DEFAULT_MONTHLY_BUDGET"`
+	DefaultEfficiencyTarget float64  `mapstructure:"default_efficiency_target" env:"ONBOARDING_DEFAULT_EFFICIENCY_TARGET"`
+	SLOTemplates            []string `mapstructure:"slo_templates" env:"ONBOARDING_SLO_TEMPLATES"`
+}
+
+// 服务依赖图：static 为静态依赖（启动时导入，替换上次导入的静态依赖），服务写作 namespace/service；
+// 链路追踪经 POST /api/v1/dependencies/edges 上报的边超过 tracing_ttl 未再出现即移出依赖图，0 取 24h
+type DependencyConfig struct {
+	TracingTTL time.Duration          `mapstructure:"tracing_ttl" env:"DEPENDENCY_TRACING_TTL"`
+	Static     []DependencyEdgeConfig `mapstructure:"static"`
+}
+
+// DependencyEdgeConfig 一条静态依赖：caller 调用 callee
+type DependencyEdgeConfig struct {
+	Caller string `mapstructure:"caller"`
+	Callee string `mapstructure:"callee"`
+}
+
+// 财年定义：start_month 为财年首月（1-12，0 取 1 月）；pattern 为 months（自然月）或 4-4-5 / 4-5-4 / 5-4-4（按周，
+// 财年从最接近 start_month 1 日的 week_start 开始，第 53 周并入最后一期）；week_start 为 sunday..saturday，空取 sunday
+type FiscalConfig struct {
+	StartMonth int    `mapstructure:"start_month" env:"COST_FISCAL_START_MONTH"`
+	Pattern    string `mapstructure:"pattern" env:"COST_FISCAL_PATTERN"`
+	WeekStart  string `mapstructure:"week_start" env:"COST_FISCAL_WEEK_START"`
+}
+
+// 工作时间定义：工作日 [start_hour, end_hour) 按 timezone 计，其余为夜间/周末；start_hour 与 end_hour 都为 0 时取 9-18。
+// idle_utilization 为一小时内 max(CPU, 内存) P95 使用量/请求量不超过该比例即视为空闲，0 取 0.05
+type OffHoursConfig struct {
+	Timezone        string  `mapstructure:"timezone" env:"OFFHOURS_TIMEZONE"`
+	StartHour       int     `mapstructure:"start_hour" env:"OFFHOURS_START_HOUR"`
+	EndHour         int     `mapstructure:"end_hour" env:"OFFHOURS_END_HOUR"`
+	IdleUtilization float64 `mapstructure:"idle_utilization" env:"OFFHOURS_IDLE_UTILIZATION"`
+}
+
+// 规格缩减安全检查：CPU 限流率（%，近 24 小时均值）达 throttling_flag_rate 时 CPU 缩容与下线建议标记复核，达
+// throttling_suppress_rate 时抑制；SLO 为 critical 时抑制所有缩减，为 warning 或剩余错误预算低于 min_error_budget（%）时标记。
+// 各项为 0 取默认 5 / 25 / 20
+type RightsizingSafetyConfig struct {
+	ThrottlingFlagRate     float64 `mapstructure:"throttling_flag_rate" env:"COST_SAFETY_THROTTLING_FLAG_RATE"`
+	ThrottlingSuppressRate float64 `mapstructure:"throttling_suppress_rate" env:"COST_SAFETY_THROTTLING_SUPPRESS_RATE"`
+	MinErrorBudget         float64 `mapstructure:"min_error_budget" env:"COST_SAFETY_MIN_ERROR_BUDGET"`
+}
+
+// 快照变化率护栏：max_change_percent <= 0 关闭检查；require_confirmation 时可疑快照需人工确认后才进入看板与事件流
+type SnapshotGuardrailConfig struct {
+	MaxChangePercent    float64 `mapstructure:"max_change_percent" env:"COST_SNAPSHOT_MAX_CHANGE_PERCENT"`
+	RequireConfirmation bool    `mapstructure:"require_confirmation" env:"COST_SNAPSHOT_REQUIRE_CONFIRMATION"`
+}
+
+// 单价变更审批：required 时 PUT /api/v1/pricing/history 被拒绝，单价变更须经 POST /api/v1/pricing/changes 提交、
+// 审批人批准后才写入单价历史，required 需同时开启 security.api_keys.required（审批人须持 API key）；
+// allow_self_approval 允许申请人批准自己的变更（默认不允许）
+type PriceApprovalConfig struct {
+	Required          bool `mapstructure:"required" env:"COST_PRICE_APPROVAL_REQUIRED"`
+	AllowSelfApproval bool `mapstructure:"allow_self_approval" env:"COST_PRICE_APPROVAL_ALLOW_SELF"`
+}
+
+// 数据新鲜度看门狗：factor 为计算间隔的倍数，0 取 3
+type StaleDataConfig struct {
+	Factor float64 `mapstructure:"factor" env:"COST_STALE_DATA_FACTOR"`
+}
+
+// 共享资源成本配置：billing 为 fixed（monthly_cost 按月内天数均摊）或 metered（计量单位 × unit_price）；
+// allocation_key 为 traffic / capacity / billable / even，namespaces 非空时只分摊到这些 namespace
+type SharedCostConfig struct {
+	Name          string   `mapstructure:"name"`
+	Kind          string   `mapstructure:"kind"` // load_balancer / storage
+	Billing       string   `mapstructure:"billing"`
+	MonthlyCost   float64  `mapstructure:"monthly_cost"`
+	UnitPrice     float64  `mapstructure:"unit_price"`
+	AllocationKey string   `mapstructure:"allocation_key"`
+	Namespaces    []string `mapstructure:"namespaces"`
 }
 
 // 安全配置
@@ -154,17 +566,73 @@ type SecurityConfig struct {
 		EnableDataEncryption bool   `mapstructure:"enable_data_encryption" env:"SECURITY_ENABLE_DATA_ENCRYPTION"`
 		EncryptionKey        string `mapstructure:"-" env:"SECURITY_ENCRYPTION_KEY"` // 敏感字段
 	} `mapstructure:"encryption"`
+
+	// 匿名化数据集导出的假名密钥：配置后同一名称在每次导出中得到相同假名；为空时每次导出随机
+	DatasetPseudonymKey string `mapstructure:"-" env:"SECURITY_DATASET_PSEUDONYM_KEY"` // 敏感字段
+
+	// 服务账号 API key：经 Authorization: Bearer 或 X-API-Key 传入；required 时 /api/v1 下的请求必须带有效 key。
+	// bootstrap_key 拥有全部权限且不限配额，用于创建最初的服务账号
+	APIKeys struct {
+		Required     bool   `mapstructure:"required" env:"SECURITY_API_KEYS_REQUIRED"`
+		BootstrapKey string `mapstructure:"-" env:"SECURITY_BOOTSTRAP_API_KEY"` // 敏感字段
+	} `mapstructure:"api_keys"`
+
+	// 外部授权钩子：在内置服务账号 scope 之外，每个 /api/v1 请求再询问企业 IAM（见 pkg/authz）
+	Authz AuthzConfig `mapstructure:"authz"`
+
+	// 查询预算：按 API 调用方统计 Prometheus 与数据库查询，超出软配额后拒绝其后续请求
+	QueryBudgets QueryBudgetConfig `mapstructure:"query_budgets"`
+}
+
+// QueryBudgetConfig 按调用方（服务账号名，未带 key 的请求共用 anonymous）统计每个窗口内的 Prometheus 与
+// 数据库查询。配额为软配额：用完后该调用方的后续 /api/v1 请求返回 429 直到窗口结束，已在执行的请求不中断；
+// /api/v1/admin 不受限。配额为 0 表示不限，consumers 按调用方覆盖默认配额
+type QueryBudgetConfig struct {
+	Enabled           bool                        `mapstructure:"enabled" env:"SECURITY_QUERY_BUDGETS_ENABLED"`
+	Window            time.Duration               `mapstructure:"window" env:"SECURITY_QUERY_BUDGETS_WINDOW"` // 默认 1h
+	PrometheusQueries int                         `mapstructure:"prometheus_queries" env:"SECURITY_QUERY_BUDGETS_PROMETHEUS_QUERIES"`
+	DatabaseQueries   int                         `mapstructure:"database_queries" env:"SECURITY_QUERY_BUDGETS_DATABASE_QUERIES"`
+	Consumers         map[string]QueryBudgetQuota `mapstructure:"consumers"`
+}
+
+// QueryBudgetQuota 单个调用方每个窗口的查询配额，0 表示不限
+type QueryBudgetQuota struct {
+	PrometheusQueries int `mapstructure:"prometheus_queries"`
+	DatabaseQueries   int `mapstructure:"database_queries"`
+}
+
+// AuthzConfig 外部授权钩子配置。Mode 为空时不启用。
+type AuthzConfig struct {
+	Mode         string        `mapstructure:"mode" env:"SECURITY_AUTHZ_MODE"` // webhook / plugin
+	WebhookURL   string        `mapstructure:"webhook_url" env:"SECURITY_AUTHZ_WEBHOOK_URL"`
+	WebhookToken string        `mapstructure:"-" env:"SECURITY_AUTHZ_WEBHOOK_TOKEN"` // 敏感字段
+	PluginPath   string        `mapstructure:"plugin_path" env:"SECURITY_AUTHZ_PLUGIN_PATH"`
+	Timeout      time.Duration `mapstructure:"timeout" env:"SECURITY_AUTHZ_TIMEOUT"`
+	// 相同请求（主体、资源、动作、命名空间、路径）的决策缓存时长；0 不缓存
+	CacheTTL time.Duration `mapstructure:"cache_ttl" env:"SECURITY_AUTHZ_CACHE_TTL"`
+	// 钩子出错或超时时放行请求；默认拒绝（503）
+	FailOpen bool `mapstructure:"fail_open" env:"SECURITY_AUTHZ_FAIL_OPEN"`
 }
 
 // Config 应用总配置
 type Config struct {
 	Env            Environment          `mapstructure:"env" env:"ENV"`
 	Server         ServerConfig         `mapstructure:"server"`
+	Storage        StorageConfig        `mapstructure:"storage"`
 	Postgres       PostgresConfig       `mapstructure:"postgres"`
 	ClickHouse     ClickHouseConfig     `mapstructure:"clickhouse"`
 	Prometheus     PrometheusConfig     `mapstructure:"prometheus"`
 	Kubernetes     KubernetesConfig     `mapstructure:"kubernetes"`
 	AnalysisEngine AnalysisEngineConfig `mapstructure:"analysis_engine"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	Chaos          ChaosConfig          `mapstructure:"chaos"`
+	CloudBilling   CloudBillingConfig   `mapstructure:"cloud_billing"`
+	Notifier       NotifierConfig       `mapstructure:"notifier"`
+	I18n           I18nConfig           `mapstructure:"i18n"`
+	Archive        ArchiveConfig        `mapstructure:"archive"`
+	EventBus       EventBusConfig       `mapstructure:"event_bus"`
+	Spool          SpoolConfig          `mapstructure:"spool"`
+	Export         ExportConfig         `mapstructure:"export"`
 	Retention      RetentionConfig      `mapstructure:"retention"`
 	Business       BusinessConfig       `mapstructure:"business"`
 	Security       SecurityConfig       `mapstructure:"security"`
@@ -81,10 +81,12 @@ func TestConfigStructure(t *testing.T) {
 				Incidents      time.Duration `mapstructure:"incidents" env:"RETENTION_PG_INCIDENTS"`
 				DailySnapshots time.Duration `mapstructure:"daily_snapshots" env:"RETENTION_PG_DAILY_SNAPSHOTS"`
 				CostHistory    time.Duration `mapstructure:"cost_history" env:"RETENTION_PG_COST_HISTORY"`
+				HourlyStats    time.Duration `mapstructure:"hourly_stats" env:"RETENTION_PG_HOURLY_STATS"`
 			}{
 				Incidents:      90 * 24 * time.Hour,
 				DailySnapshots: 180 * 24 * time.Hour,
 				CostHistory:    365 * 24 * time.Hour,
+				HourlyStats:    14 * 24 * time.Hour,
 			},
 			ClickHouse: struct {
 				ErrorLogs   time.Duration `mapstructure:"error_logs" env:"RETENTION_CH_ERROR_LOGS"`
@@ -104,12 +106,15 @@ func TestConfigStructure(t *testing.T) {
 				MemPricePerGBHour    float64       `mapstructure:"mem_price_per_gb_hour" env:"COST_MEM_PRICE"`
 				CalculationInterval  time.Duration `mapstructure:"calculation_interval" env:"COST_CALCULATION_INTERVAL"`
 				AggregationLevels    []string      `mapstructure:"aggregation_levels" env:"COST_AGGREGATION_LEVELS"`
+				NonFinitePolicy      string        `mapstructure:"non_finite_policy" env:"COST_NON_FINITE_POLICY"`
 				EfficiencyThresholds struct {
 					Zombie          float64 `mapstructure:"zombie" env:"COST_EFFICIENCY_ZOMBIE_THRESHOLD"`
 					OverProvisioned float64 `mapstructure:"over_provisioned" env:"COST_EFFICIENCY_OVER_PROVISIONED_THRESHOLD"`
 					Healthy         float64 `mapstructure:"healthy" env:"COST_EFFICIENCY_HEALTHY_THRESHOLD"`
 					Danger          float64 `mapstructure:"danger" env:"COST_EFFICIENCY_DANGER_THRESHOLD"`
 				} `mapstructure:"efficiency_thresholds"`
+				Calculator        string                 `mapstructure:"calculator" env:"COST_CALCULATOR"`
+				CalculatorOptions map[string]interface{} `mapstructure:"calculator_options"`
 			}{
 				CPUPricePerCoreHour: 0.025,
 				MemPricePerGBHour:   0.01,
@@ -236,12 +241,15 @@ func TestEnvironmentValidation(t *testing.T) {
 				MemPricePerGBHour    float64       `mapstructure:"mem_price_per_gb_hour" env:"COST_MEM_PRICE"`
 				CalculationInterval  time.Duration `mapstructure:"calculation_interval" env:"COST_CALCULATION_INTERVAL"`
 				AggregationLevels    []string      `mapstructure:"aggregation_levels" env:"COST_AGGREGATION_LEVELS"`
+				NonFinitePolicy      string        `mapstructure:"non_finite_policy" env:"COST_NON_FINITE_POLICY"`
 				EfficiencyThresholds struct {
 					Zombie          float64 `mapstructure:"zombie" env:"COST_EFFICIENCY_ZOMBIE_THRESHOLD"`
 					OverProvisioned float64 `mapstructure:"over_provisioned" env:"COST_EFFICIENCY_OVER_PROVISIONED_THRESHOLD"`
 					Healthy         float64 `mapstructure:"healthy" env:"COST_EFFICIENCY_HEALTHY_THRESHOLD"`
 					Danger          float64 `mapstructure:"danger" env:"COST_EFFICIENCY_DANGER_THRESHOLD"`
 				} `mapstructure:"efficiency_thresholds"`
+				Calculator        string                 `mapstructure:"calculator" env:"COST_CALCULATOR"`
+				CalculatorOptions map[string]interface{} `mapstructure:"calculator_options"`
 			}{
 				CPUPricePerCoreHour: 0.025,
 				MemPricePerGBHour:   0.01,
@@ -290,6 +298,30 @@ func TestEnvironmentValidation(t *testing.T) {
 	if err := validator.Validate(devCfg); err != nil {
 		t.Errorf("dev config validation failed: %v", err)
 	}
+	devCfg.Business.PriceApproval.Required = true
+	if err := validator.Validate(devCfg); err == nil {
+		t.Error("price approval without required api keys: want error")
+	}
+	devCfg.Security.APIKeys.Required = true
+	if err := validator.Validate(devCfg); err != nil {
+		t.Errorf("price approval with required api keys: %v", err)
+	}
+	devCfg.CloudBilling.Enabled, devCfg.CloudBilling.Provider, devCfg.CloudBilling.AccountID = true, "aliyun", "acct-1"
+	if err := validator.Validate(devCfg); err == nil {
+		t.Error("bill ingestion without access keys: want error")
+	}
+	devCfg.CloudBilling.AccessKeyID, devCfg.CloudBilling.AccessKeySecret = "ak", "sk"
+	if err := validator.Validate(devCfg); err != nil {
+		t.Errorf("bill ingestion with access keys: %v", err)
+	}
+	devCfg.EventBus.Enabled = true
+	if err := validator.Validate(devCfg); err == nil {
+		t.Error("event bus without rest proxy url: want error")
+	}
+	devCfg.EventBus.RESTProxyURL = "http://kafka-rest:8082"
+	if err := validator.Validate(devCfg); err != nil {
+		t.Errorf("event bus with rest proxy url: %v", err)
+	}
 
 	// 测试生产环境配置（应该失败，因为缺少安全配置）
 	prodCfg := &Config{
@@ -312,12 +344,15 @@ func TestEnvironmentValidation(t *testing.T) {
 				MemPricePerGBHour    float64       `mapstructure:"mem_price_per_gb_hour" env:"COST_MEM_PRICE"`
 				CalculationInterval  time.Duration `mapstructure:"calculation_interval" env:"COST_CALCULATION_INTERVAL"`
 				AggregationLevels    []string      `mapstructure:"aggregation_levels" env:"COST_AGGREGATION_LEVELS"`
+				NonFinitePolicy      string        `mapstructure:"non_finite_policy" env:"COST_NON_FINITE_POLICY"`
 				EfficiencyThresholds struct {
 					Zombie          float64 `mapstructure:"zombie" env:"COST_EFFICIENCY_ZOMBIE_THRESHOLD"`
 					OverProvisioned float64 `mapstructure:"over_provisioned" env:"COST_EFFICIENCY_OVER_PROVISIONED_THRESHOLD"`
 					Healthy         float64 `mapstructure:"healthy" env:"COST_EFFICIENCY_HEALTHY_THRESHOLD"`
 					Danger          float64 `mapstructure:"danger" env:"COST_EFFICIENCY_DANGER_THRESHOLD"`
 				} `mapstructure:"efficiency_thresholds"`
+				Calculator        string                 `mapstructure:"calculator" env:"COST_CALCULATOR"`
+				CalculatorOptions map[string]interface{} `mapstructure:"calculator_options"`
 			}{
 				CPUPricePerCoreHour: 0.025,
 				MemPricePerGBHour:   0.01,
@@ -516,3 +551,141 @@ func TestSensitiveFieldHandling(t *testing.T) {
 		t.Log("Encryption key field is empty as expected (should come from env)")
 	}
 }
+
+func TestPostgresDSN(t *testing.T) {
+	cases := []struct {
+		cfg  PostgresConfig
+		want string
+	}{
+		{PostgresConfig{Host: "db", Port: 5432, User: "lh", Password: "p@ss/word", Database: "control", SSLMode: "disable"},
+			"postgres://lh:p%40ss%2Fword@db:5432/control?sslmode=disable"},
+		{PostgresConfig{Host: "db", User: "lh", Database: "control"}, "postgres://lh@db/control"},
+		{PostgresConfig{Host: "::1", Port: 5433, Database: "control"}, "postgres://[::1]:5433/control"},
+	}
+	for _, c := range cases {
+		if got := c.cfg.DSN(); got != c.want {
+			t.Errorf("DSN() = %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestValidateOffHours(t *testing.T) {
+	valid := []OffHoursConfig{
+		{},
+		{Timezone: "Asia/Shanghai", StartHour: 9, EndHour: 18, IdleUtilization: 0.05},
+		{StartHour: 0, EndHour: 24},
+	}
+	for _, c := range valid {
+		if err := validateOffHours(c); err != nil {
+			t.Errorf("validateOffHours(%+v) = %v, want nil", c, err)
+		}
+	}
+	invalid := []OffHoursConfig{
+		{Timezone: "Mars/Olympus"},
+		{StartHour: 18, EndHour: 9},
+		{StartHour: 9, EndHour: 25},
+		{IdleUtilization: 1},
+	}
+	for _, c := range invalid {
+		if err := validateOffHours(c); err == nil {
+			t.Errorf("validateOffHours(%+v) = nil, want error", c)
+		}
+	}
+}
+
+func TestValidateFiscal(t *testing.T) {
+	for _, c := range []FiscalConfig{{}, {StartMonth: 4}, {StartMonth: 2, Pattern: "4-4-5", WeekStart: "Sunday"}} {
+		if err := validateFiscal(c); err != nil {
+			t.Errorf("validateFiscal(%+v) = %v, want nil", c, err)
+		}
+	}
+	for _, c := range []FiscalConfig{{StartMonth: 13}, {Pattern: "4-4-4"}, {Pattern: "4-4-5", WeekStart: "someday"}} {
+		if err := validateFiscal(c); err == nil {
+			t.Errorf("validateFiscal(%+v) = nil, want error", c)
+		}
+	}
+}
+
+func TestValidateRetry(t *testing.T) {
+	for _, c := range []RetryConfig{{}, {MaxAttempts: 1}, {MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 1.5, Jitter: 1}} {
+		if err := validateRetry("prometheus", c); err != nil {
+			t.Errorf("validateRetry(%+v) = %v, want nil", c, err)
+		}
+	}
+	for _, c := range []RetryConfig{{MaxAttempts: -1}, {InitialBackoff: time.Second, MaxBackoff: time.Millisecond}, {Multiplier: 0.5}, {Jitter: 1.5}} {
+		if err := validateRetry("prometheus", c); err == nil {
+			t.Errorf("validateRetry(%+v) = nil, want error", c)
+		}
+	}
+}
+
+func TestValidateKubernetesClient(t *testing.T) {
+	scoped := KubernetesConfig{Client: "cluster"}
+	scoped.RBAC.NamespaceScoped = true
+	if err := validateKubernetesClient(scoped); err == nil {
+		t.Error("namespace-scoped cluster client without namespace: want error")
+	}
+	scoped.Namespace = "lighthouse"
+	for _, k := range []KubernetesConfig{{}, {Client: "mock"}, {Client: "cluster", Kubeconfig: "/etc/kube/config"}, scoped} {
+		if err := validateKubernetesClient(k); err != nil {
+			t.Errorf("validateKubernetesClient(%+v) = %v, want nil", k, err)
+		}
+	}
+	if err := validateKubernetesClient(KubernetesConfig{Client: "live"}); err == nil {
+		t.Error("unknown client: want error")
+	}
+}
+
+func TestValidateQueryBudgets(t *testing.T) {
+	valid := QueryBudgetConfig{Enabled: true, Window: time.Hour, PrometheusQueries: 500, Consumers: map[string]QueryBudgetQuota{"batch": {DatabaseQueries: 100}}}
+	if err := validateQueryBudgets(valid); err != nil {
+		t.Errorf("validateQueryBudgets(%+v) = %v, want nil", valid, err)
+	}
+	for _, c := range []QueryBudgetConfig{
+		{Window: -time.Minute},
+		{DatabaseQueries: -1},
+		{Consumers: map[string]QueryBudgetQuota{"batch": {PrometheusQueries: -1}}},
+	} {
+		if err := validateQueryBudgets(c); err == nil {
+			t.Errorf("validateQueryBudgets(%+v) = nil, want error", c)
+		}
+	}
+}
+
+func TestValidateChaos(t *testing.T) {
+	valid := ChaosConfig{Enabled: true, MaxDuration: time.Hour, Scenarios: map[string]ChaosScenarioConfig{
+		"slow-database": {Database: ChaosFaultConfig{ErrorRate: 0.1, LatencyMs: 800, TailLatencyRate: 0.05, TailLatencyMs: 2000}},
+	}}
+	if err := validateChaos(EnvStaging, valid); err != nil {
+		t.Errorf("validateChaos(staging, %+v) = %v, want nil", valid, err)
+	}
+	if err := validateChaos(EnvProduction, valid); err == nil {
+		t.Error("validateChaos(prod, enabled) = nil, want error")
+	}
+	for _, c := range []ChaosConfig{
+		{MaxDuration: -time.Minute},
+		{Scenarios: map[string]ChaosScenarioConfig{"x": {Prometheus: ChaosFaultConfig{ErrorRate: 1.5}}}},
+		{Scenarios: map[string]ChaosScenarioConfig{"x": {Kubernetes: ChaosFaultConfig{LatencyMs: -1}}}},
+	} {
+		if err := validateChaos(EnvStaging, c); err == nil {
+			t.Errorf("validateChaos(%+v) = nil, want error", c)
+		}
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	valid := DependencyConfig{TracingTTL: time.Hour, Static: []DependencyEdgeConfig{{Caller: "shop/frontend", Callee: "shop/checkout"}}}
+	if err := validateDependencies(valid); err != nil {
+		t.Errorf("validateDependencies(%+v) = %v, want nil", valid, err)
+	}
+	for _, c := range []DependencyConfig{
+		{TracingTTL: -time.Minute},
+		{Static: []DependencyEdgeConfig{{Caller: "frontend", Callee: "shop/checkout"}}},
+		{Static: []DependencyEdgeConfig{{Caller: "shop/frontend", Callee: "shop/"}}},
+		{Static: []DependencyEdgeConfig{{Caller: "shop/checkout", Callee: "shop/checkout"}}},
+	} {
+		if err := validateDependencies(c); err == nil {
+			t.Errorf("validateDependencies(%+v) = nil, want error", c)
+		}
+	}
+}
@@ -7,12 +7,25 @@ func GetEnvMapping() map[string]string {
 		"ENV": "环境类型 (dev/staging/prod)",
 
 		// 服务器配置
-		"SERVER_PORT":          "服务端口",
-		"SERVER_READ_TIMEOUT":  "服务读取超时",
-		"SERVER_WRITE_TIMEOUT": "服务写入超时",
-		"LOG_LEVEL":            "日志级别",
-		"SERVER_MAX_CONN":      "最大连接数",
-		"SERVER_GRACE_PERIOD":  "优雅关闭等待时间",
+		"SERVER_PORT":               "服务端口",
+		"SERVER_READ_TIMEOUT":       "服务读取超时",
+		"SERVER_WRITE_TIMEOUT":      "服务写入超时",
+		"LOG_LEVEL":                 "日志级别",
+		"SERVER_MAX_CONN":           "最大连接数",
+		"SERVER_GRACE_PERIOD":       "优雅关闭等待时间",
+		"SERVER_SELF_SLO_ENABLED":   "启用 Lighthouse 自身 API 的按路由 SLO",
+		"SERVER_SELF_SLO_WINDOW":    "自身 API SLO 评估窗口",
+		"SERVER_CANARY_ENABLED":     "启用管道合成监控（端到端检查）",
+		"SERVER_CANARY_INTERVAL":    "管道合成监控检查间隔",
+		"SERVER_CANARY_MAX_LATENCY": "管道合成监控端到端延迟告警阈值",
+		"SERVER_CANARY_BASE_URL":    "管道合成监控读回所用的 API 地址",
+		"SERVER_CANARY_API_KEY":     "管道合成监控读回所用的 API Key (敏感信息)",
+
+		// 存储驱动配置
+		"STORAGE_DRIVER":                "存储驱动 (memory/mock/file)",
+		"STORAGE_FILE_PATH":             "file驱动数据文件路径",
+		"STORAGE_DEGRADED_MODE":         "存储不可达时只读返回缓存结果",
+		"STORAGE_HEALTH_PROBE_INTERVAL": "降级模式健康检查间隔",
 
 		// PostgreSQL控制平面配置
 		"PG_HOST":              "PostgreSQL主机地址",
@@ -25,6 +38,7 @@ func GetEnvMapping() map[string]string {
 		"PG_MAX_IDLE_CONNS":    "PostgreSQL最大空闲连接数",
 		"PG_CONN_MAX_LIFETIME": "PostgreSQL连接最大生命周期",
 		"PG_MIGRATION_PATH":    "PostgreSQL迁移文件路径",
+		"PG_AUTO_MIGRATE":      "PostgreSQL启动时自动应用内置schema",
 
 		// ClickHouse证据平面配置
 		"CH_HOST":           "ClickHouse主机地址",
@@ -46,29 +60,126 @@ func GetEnvMapping() map[string]string {
 		"PROMETHEUS_QUERY_CONCURRENCY": "Prometheus查询并发数",
 		"PROMETHEUS_BEARER_TOKEN":      "Prometheus Bearer Token (敏感信息)",
 		"PROMETHEUS_SKIP_TLS_VERIFY":   "Prometheus跳过TLS验证",
+		"PROMETHEUS_MAX_SERIES":        "Prometheus单个selector的series上限",
+		"PROMETHEUS_MAX_SAMPLES":       "Prometheus单次查询的样本数上限（超过则按时间切分）",
 
 		// Kubernetes配置
-		"K8S_API_SERVER":        "Kubernetes API服务器地址",
-		"K8S_NAMESPACE":         "Kubernetes命名空间",
-		"K8S_SERVICE_ACCOUNT":   "Kubernetes服务账户",
-		"K8S_BEARER_TOKEN_FILE": "Kubernetes Bearer Token文件路径",
-		"K8S_IN_CLUSTER":        "是否在集群内运行",
-		"K8S_RBAC_ENABLED":      "是否启用RBAC",
-		"K8S_READ_ONLY_ACCESS":  "是否只读访问",
-		"K8S_NAMESPACE_SCOPED":  "是否命名空间作用域",
+		"K8S_CLIENT":                    "Kubernetes 客户端 (mock/cluster)",
+		"K8S_API_SERVER":                "Kubernetes API服务器地址",
+		"K8S_NAMESPACE":                 "Kubernetes命名空间",
+		"K8S_SERVICE_ACCOUNT":           "Kubernetes服务账户",
+		"K8S_BEARER_TOKEN_FILE":         "Kubernetes Bearer Token文件路径",
+		"K8S_IN_CLUSTER":                "是否在集群内运行",
+		"K8S_KUBECONFIG":                "集群外运行时的 kubeconfig 路径",
+		"K8S_CONTEXT":                   "kubeconfig 中使用的 context",
+		"K8S_RBAC_ENABLED":              "是否启用RBAC",
+		"K8S_READ_ONLY_ACCESS":          "是否只读访问",
+		"K8S_NAMESPACE_SCOPED":          "是否命名空间作用域",
+		"K8S_COST_ANNOTATIONS_ENABLED":  "把成本与效率等级写回工作负载注解",
+		"K8S_COST_ANNOTATIONS_INTERVAL": "成本注解写回间隔",
+		"K8S_ADMISSION_ENABLED":         "启用准入成本估算 webhook",
+		"K8S_ADMISSION_BUDGET_ACTION":   "准入时超出预算的处理 (none/warn/reject)",
+		"K8S_METADATA_CACHE_TTL":        "成本结果附带的 K8s 元数据缓存时间",
 
 		// Analysis Engine配置
-		"ANALYSIS_ENGINE_ADDRESS":        "Analysis Engine地址",
-		"ANALYSIS_ENGINE_TIMEOUT":        "Analysis Engine超时",
-		"ANALYSIS_ENGINE_API_KEY":        "Analysis Engine API Key (敏感信息)",
-		"ANALYSIS_ENGINE_MAX_RETRIES":    "Analysis Engine最大重试次数",
-		"ANALYSIS_ENGINE_RETRY_DELAY":    "Analysis Engine重试延迟",
-		"ANALYSIS_ENGINE_ENABLE_TRACING": "Analysis Engine启用追踪",
+		"ANALYSIS_ENGINE_ADDRESS":                   "Analysis Engine地址",
+		"ANALYSIS_ENGINE_TIMEOUT":                   "Analysis Engine超时",
+		"ANALYSIS_ENGINE_API_KEY":                   "Analysis Engine API Key (敏感信息)",
+		"ANALYSIS_ENGINE_MAX_RETRIES":               "Analysis Engine最大重试次数",
+		"ANALYSIS_ENGINE_RETRY_DELAY":               "Analysis Engine重试延迟",
+		"ANALYSIS_ENGINE_ENABLE_TRACING":            "Analysis Engine启用追踪",
+		"ANALYSIS_ENGINE_BREAKER_FAILURE_THRESHOLD": "Analysis Engine熔断连续失败阈值",
+		"ANALYSIS_ENGINE_BREAKER_OPEN_TIMEOUT":      "Analysis Engine熔断持续时间",
+
+		// 外部数据源熔断配置
+		"CB_ENABLED":                "启用Prometheus/K8s/ClickHouse熔断",
+		"CB_FAILURE_RATE_THRESHOLD": "熔断错误率阈值 (0-1)",
+		"CB_MIN_REQUESTS":           "计算错误率的最少请求数",
+		"CB_WINDOW":                 "错误率统计窗口",
+		"CB_OPEN_TIMEOUT":           "熔断持续时间",
+		"CB_HALF_OPEN_PROBES":       "半开状态探测请求数",
+
+		// 故障演练配置
+		"CHAOS_ENABLED":      "启用故障演练（/api/v1/admin/chaos，prod 环境不可启用）",
+		"CHAOS_MAX_DURATION": "单次故障演练最长时间",
+
+		// 云账单配置
+		"CLOUD_BILL_ENABLED":     "启用云账单定时拉取",
+		"CLOUD_BILL_INTERVAL":    "云账单拉取间隔",
+		"CLOUD_BILL_PROVIDER":    "云账单Provider (aliyun/aws/tencent)",
+		"CLOUD_BILL_ENDPOINT":    "云账单API接入点",
+		"CLOUD_BILL_ACCOUNT_ID":  "云账号ID",
+		"CLOUD_BILL_PERIOD_TYPE": "云账单账期类型 (day/month)",
+		"CLOUD_BILL_AK":          "云账单AccessKey ID (敏感信息)",
+		"CLOUD_BILL_SK":          "云账单AccessKey Secret (敏感信息)",
+
+		// 告警通知配置
+		"NOTIFIER_RATE_LIMIT_PER_MINUTE":    "通知限流（每渠道每告警类型每分钟条数）",
+		"NOTIFIER_MAX_RETRIES":              "通知投递最大重试次数",
+		"NOTIFIER_RETRY_DELAY":              "通知投递重试间隔",
+		"NOTIFIER_SLACK_ENABLED":            "启用Slack通知",
+		"NOTIFIER_SLACK_WEBHOOK_URL":        "Slack Webhook地址 (敏感信息)",
+		"NOTIFIER_SLACK_CHANNEL":            "Slack默认频道",
+		"NOTIFIER_SLACK_USERNAME":           "Slack消息显示用户名",
+		"NOTIFIER_DINGTALK_ENABLED":         "启用钉钉机器人通知",
+		"NOTIFIER_DINGTALK_WEBHOOK_URL":     "钉钉机器人Webhook地址 (敏感信息)",
+		"NOTIFIER_DINGTALK_SECRET":          "钉钉机器人加签密钥 (敏感信息)",
+		"NOTIFIER_DINGTALK_AT_MOBILES":      "钉钉告警@手机号列表",
+		"NOTIFIER_WECOM_ENABLED":            "启用企业微信群机器人通知",
+		"NOTIFIER_WECOM_WEBHOOK_URL":        "企业微信群机器人Webhook地址 (敏感信息)",
+		"NOTIFIER_PAGING_EXTREME_BURN_RATE": "触发Paging的极端燃烧率阈值",
+		"NOTIFIER_PAGERDUTY_ENABLED":        "启用PagerDuty",
+		"NOTIFIER_PAGERDUTY_ROUTING_KEY":    "PagerDuty Events API Routing Key (敏感信息)",
+		"NOTIFIER_OPSGENIE_ENABLED":         "启用Opsgenie",
+		"NOTIFIER_OPSGENIE_API_URL":         "Opsgenie API地址",
+		"NOTIFIER_OPSGENIE_API_KEY":         "Opsgenie API Key (敏感信息)",
+		"NOTIFIER_EMAIL_ENABLED":            "启用报表邮件",
+		"SMTP_HOST":                         "SMTP服务器地址",
+		"SMTP_PORT":                         "SMTP端口",
+		"SMTP_USERNAME":                     "SMTP用户名",
+		"SMTP_PASSWORD":                     "SMTP密码 (敏感信息)",
+		"SMTP_FROM":                         "报表邮件发件人",
+		"SMTP_TLS_MODE":                     "SMTP TLS模式 (none/starttls/tls)",
+		"SMTP_INSECURE_SKIP_VERIFY":         "SMTP跳过证书校验",
+
+		// 国际化配置
+		"I18N_DEFAULT_LOCALE": "默认语言 (en/zh-CN)，API 无 Accept-Language 匹配时及通知、报表使用",
+
+		// 对象存储归档配置
+		"ARCHIVE_ENABLED":           "启用快照对象存储归档",
+		"ARCHIVE_ENDPOINT":          "对象存储Endpoint (S3/OSS/MinIO)",
+		"ARCHIVE_REGION":            "对象存储Region",
+		"ARCHIVE_BUCKET":            "归档Bucket",
+		"ARCHIVE_PREFIX":            "归档对象Key前缀",
+		"ARCHIVE_ACCESS_KEY_ID":     "对象存储AccessKey ID (敏感信息)",
+		"ARCHIVE_SECRET_ACCESS_KEY": "对象存储AccessKey Secret (敏感信息)",
+		"ARCHIVE_USE_PATH_STYLE":    "使用Path-Style寻址 (MinIO)",
+		"ARCHIVE_RETENTION":         "归档对象保留时间",
+
+		// 事件总线配置
+		"EVENTBUS_ENABLED":              "启用Kafka领域事件发布",
+		"EVENTBUS_KAFKA_REST_PROXY_URL": "Kafka REST Proxy地址",
+		"EVENTBUS_KAFKA_TOPIC":          "领域事件Topic",
+		"EVENTBUS_KAFKA_USERNAME":       "Kafka REST Proxy用户名",
+		"EVENTBUS_KAFKA_PASSWORD":       "Kafka REST Proxy密码 (敏感信息)",
+		"EVENTBUS_FLUSH_INTERVAL":       "事件投递间隔",
+		"EVENTBUS_MAX_RETRIES":          "事件投递最大重试次数",
+		"EVENTBUS_OUTBOX_SIZE":          "未确认事件缓冲上限",
+
+		// 失败写入 spool 配置
+		"SPOOL_DIR":         "失败写入批次落盘目录",
+		"SPOOL_MAX_ENTRIES": "spool 积压批次上限",
+
+		// 异步导出任务配置
+		"EXPORT_DIR":       "导出文件目录",
+		"EXPORT_RETENTION": "导出文件保留时间",
+		"EXPORT_WORKERS":   "并发导出任务数",
 
 		// 数据保留策略配置
 		"RETENTION_PG_INCIDENTS":       "PostgreSQL故障快照保留时间",
 		"RETENTION_PG_DAILY_SNAPSHOTS": "PostgreSQL日报保留时间",
 		"RETENTION_PG_COST_HISTORY":    "PostgreSQL成本历史保留时间",
+		"RETENTION_PG_HOURLY_STATS":    "PostgreSQL小时统计全分辨率保留时间，超期降采样为工作负载日汇总",
 		"RETENTION_CH_ERROR_LOGS":      "ClickHouse错误日志保留时间",
 		"RETENTION_CH_SAMPLED_LOGS":    "ClickHouse采样日志保留时间",
 		"RETENTION_CH_TRACE_DATA":      "ClickHouse Trace数据保留时间",
@@ -83,6 +194,22 @@ func GetEnvMapping() map[string]string {
 		"COST_EFFICIENCY_OVER_PROVISIONED_THRESHOLD": "过剩效率阈值",
 		"COST_EFFICIENCY_HEALTHY_THRESHOLD":          "健康效率阈值",
 		"COST_EFFICIENCY_DANGER_THRESHOLD":           "危险效率阈值",
+		"COST_ACCOUNTING_TIME_ZONE":                  "财务关账时区（日汇总与报表的天/月边界）",
+		"COST_PRICE_APPROVAL_REQUIRED":               "单价变更须经审批人批准后才生效（禁用直接修改单价历史）",
+		"COST_PRICE_APPROVAL_ALLOW_SELF":             "允许申请人批准自己的单价变更",
+		"COST_STALE_DATA_FACTOR":                     "最新数据超过计算间隔的该倍数即告警数据停滞（0 取 3）",
+		"COST_GRADE_VIEW":                            "快照等级数量默认视图（original / regraded）",
+		"COST_NODE_POOL_LABELS":                      "标识节点池的节点标签（逗号分隔，按顺序优先）",
+		"COST_SAFETY_THROTTLING_FLAG_RATE":           "CPU 限流率达到该值（%）时缩容建议标记复核",
+		"COST_SAFETY_THROTTLING_SUPPRESS_RATE":       "CPU 限流率达到该值（%）时抑制缩容建议",
+		"COST_SAFETY_MIN_ERROR_BUDGET":               "剩余错误预算低于该值（%）时缩容建议标记复核",
+		"DEPENDENCY_TRACING_TTL":                     "链路追踪上报的依赖边的过期时间",
+		"ONBOARDING_DEFAULT_MONTHLY_BUDGET":          "namespace 接入未指定时的默认月度预算（<= 0 不创建预算告警）",
+		"ONBOARDING_DEFAULT_EFFICIENCY_TARGET":       "namespace 接入未指定时的默认效率目标（0 取 60）",
+		"ONBOARDING_SLO_TEMPLATES":                   "namespace 接入默认启用的 SLO 告警模板（逗号分隔，空为全部）",
+		"COST_FISCAL_START_MONTH":                    "财年首月（1-12）",
+		"COST_FISCAL_PATTERN":                        "财务期间模式（months / 4-4-5 / 4-5-4 / 5-4-4）",
+		"COST_FISCAL_WEEK_START":                     "按周财历的每周起始日",
 
 		// 业务配置 - SLO
 		"SLO_AVAILABILITY_THRESHOLD":    "SLO可用性阈值",
@@ -107,5 +234,15 @@ func GetEnvMapping() map[string]string {
 		"SECURITY_DATABASE_QUERIES_PER_MINUTE":   "数据库每分钟查询限制",
 		"SECURITY_ENABLE_DATA_ENCRYPTION":        "启用数据加密",
 		"SECURITY_ENCRYPTION_KEY":                "加密密钥 (敏感信息)",
+		"SECURITY_DATASET_PSEUDONYM_KEY":         "匿名化数据集导出的假名密钥 (敏感信息)",
+		"SECURITY_API_KEYS_REQUIRED":             "要求 /api/v1 请求携带服务账号 API key",
+		"SECURITY_BOOTSTRAP_API_KEY":             "初始管理 API key (敏感信息)",
+		"SECURITY_AUTHZ_MODE":                    "外部授权钩子 (webhook/plugin，为空不启用)",
+		"SECURITY_AUTHZ_WEBHOOK_URL":             "外部授权 webhook 地址",
+		"SECURITY_AUTHZ_WEBHOOK_TOKEN":           "外部授权 webhook Bearer Token (敏感信息)",
+		"SECURITY_AUTHZ_PLUGIN_PATH":             "外部授权 Go 插件 (.so) 路径",
+		"SECURITY_AUTHZ_TIMEOUT":                 "外部授权钩子超时",
+		"SECURITY_AUTHZ_CACHE_TTL":               "外部授权决策缓存时长",
+		"SECURITY_AUTHZ_FAIL_OPEN":               "外部授权钩子出错时放行请求",
 	}
 }
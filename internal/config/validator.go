@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 )
 
 // Validator 配置验证接口
@@ -45,6 +49,53 @@ func (v *ConfigValidator) Validate(cfg *Config) error {
 		}
 	}
 
+	// 启用数据加密时必须配置密钥（格式见 storage.ParseKeyring）
+	if cfg.Security.Encryption.EnableDataEncryption && cfg.Security.Encryption.EncryptionKey == "" {
+		return fmt.Errorf("encryption key is required when data encryption is enabled")
+	}
+	if err := validateKubernetesClient(cfg.Kubernetes); err != nil {
+		return err
+	}
+	// 写回成本注解需要 patch 工作负载
+	if cfg.Kubernetes.CostAnnotations.Enabled && cfg.Kubernetes.RBAC.ReadOnlyAccess {
+		return fmt.Errorf("cost annotations need write access to workloads; disable kubernetes.rbac.read_only_access")
+	}
+	if cfg.Kubernetes.MetadataCacheTTL < 0 {
+		return fmt.Errorf("kubernetes metadata cache ttl must not be negative")
+	}
+	if cfg.Kubernetes.CostAnnotations.Interval < 0 {
+		return fmt.Errorf("cost annotation interval must not be negative")
+	}
+	switch cfg.Kubernetes.Admission.BudgetAction {
+	case "", "none", "warn", "reject":
+	default:
+		return fmt.Errorf("invalid admission budget action %q (want none, warn or reject)", cfg.Kubernetes.Admission.BudgetAction)
+	}
+	// 单价审批依赖审批人身份：不要求 API key 时匿名调用方可以提交并批准自己的变更
+	if cfg.Business.PriceApproval.Required && !cfg.Security.APIKeys.Required {
+		return fmt.Errorf("business.price_approval.required needs security.api_keys.required so that reviewers are authenticated")
+	}
+	// 初始管理 key 拥有全部权限，过短的值容易被猜中
+	if k := cfg.Security.APIKeys.BootstrapKey; k != "" && len(k) < 24 {
+		return fmt.Errorf("bootstrap api key must be at least 24 characters")
+	}
+	switch authz := cfg.Security.Authz; authz.Mode {
+	case "":
+	case "webhook":
+		if authz.WebhookURL == "" {
+			return fmt.Errorf("authz webhook url is required when authz mode is webhook")
+		}
+	case "plugin":
+		if authz.PluginPath == "" {
+			return fmt.Errorf("authz plugin path is required when authz mode is plugin")
+		}
+	default:
+		return fmt.Errorf("invalid authz mode %q (want webhook or plugin)", authz.Mode)
+	}
+	if cfg.Security.Authz.Timeout < 0 || cfg.Security.Authz.CacheTTL < 0 {
+		return fmt.Errorf("authz timeout and cache ttl must not be negative")
+	}
+
 	// PostgreSQL控制平面配置验证
 	if cfg.Postgres.Host == "" {
 		return fmt.Errorf("postgres host is required for control plane")
@@ -70,12 +121,50 @@ func (v *ConfigValidator) Validate(cfg *Config) error {
 		// Prometheus地址是可选的，但建议配置
 		fmt.Println("[WARNING] Prometheus address not configured, SLO monitoring will be limited")
 	}
+	if cfg.Prometheus.MaxSeries < 0 || cfg.Prometheus.MaxSamples < 0 {
+		return fmt.Errorf("prometheus max_series and max_samples must be non-negative (0 disables the limit)")
+	}
 
 	// Analysis Engine配置验证（可选）
 	if cfg.AnalysisEngine.Address != "" && !isValidURL(cfg.AnalysisEngine.Address) {
 		return fmt.Errorf("invalid Analysis Engine address format")
 	}
 
+	// 云账单拉取配置验证（可选）
+	if b := cfg.CloudBilling; b.Enabled {
+		if b.Provider == "" || b.AccountID == "" {
+			return fmt.Errorf("cloud_billing provider and account_id are required when bill ingestion is enabled")
+		}
+		if b.AccessKeyID == "" || b.AccessKeySecret == "" {
+			return fmt.Errorf("cloud_billing access keys (CLOUD_BILL_AK / CLOUD_BILL_SK) are required when bill ingestion is enabled")
+		}
+		if b.PeriodType != "" && b.PeriodType != "day" && b.PeriodType != "month" {
+			return fmt.Errorf("invalid cloud_billing period_type %q (want day or month)", b.PeriodType)
+		}
+	}
+
+	// 事件总线配置验证（可选）
+	if e := cfg.EventBus; e.Enabled {
+		if !isValidURL(e.RESTProxyURL) {
+			return fmt.Errorf("event_bus.rest_proxy_url must be a valid url when the event bus is enabled")
+		}
+		if e.FlushInterval < 0 || e.MaxRetries < 0 || e.OutboxSize < 0 {
+			return fmt.Errorf("event_bus flush_interval, max_retries and outbox_size must not be negative")
+		}
+	}
+
+	// 外部客户端重试策略验证
+	for name, r := range map[string]RetryConfig{
+		"clickhouse":      cfg.ClickHouse.Retry,
+		"prometheus":      cfg.Prometheus.Retry,
+		"kubernetes":      cfg.Kubernetes.Retry,
+		"analysis_engine": cfg.AnalysisEngine.Retry,
+	} {
+		if err := validateRetry(name, r); err != nil {
+			return err
+		}
+	}
+
 	// 业务配置验证
 	if cfg.Business.CostCalculation.CPUPricePerCoreHour <= 0 {
 		return fmt.Errorf("CPU price must be positive")
@@ -86,6 +175,31 @@ func (v *ConfigValidator) Validate(cfg *Config) error {
 	if cfg.Business.CostCalculation.CalculationInterval <= 0 {
 		return fmt.Errorf("cost calculation interval must be positive")
 	}
+	switch cfg.Business.CostCalculation.NonFinitePolicy {
+	case "", "zero", "drop", "fail":
+	default:
+		return fmt.Errorf("non-finite policy must be zero, drop or fail")
+	}
+	if _, err := costmodel.NewCalculator(cfg.Business.CostCalculation.Calculator, cfg.Business.CostCalculation.CalculatorOptions); err != nil {
+		return fmt.Errorf("invalid cost calculator: %w", err)
+	}
+	if cfg.Business.SnapshotGuardrail.MaxChangePercent < 0 {
+		return fmt.Errorf("snapshot guardrail max change percent cannot be negative")
+	}
+	if cfg.Business.StaleData.Factor < 0 {
+		return fmt.Errorf("stale data factor cannot be negative")
+	}
+	if err := validateOffHours(cfg.Business.OffHours); err != nil {
+		return err
+	}
+	if cfg.Business.AccountingTimeZone != "" {
+		if _, err := time.LoadLocation(cfg.Business.AccountingTimeZone); err != nil {
+			return fmt.Errorf("invalid accounting time zone %q: %w", cfg.Business.AccountingTimeZone, err)
+		}
+	}
+	if err := validateFiscal(cfg.Business.Fiscal); err != nil {
+		return err
+	}
 
 	// SLO配置验证
 	if cfg.Business.SLO.AvailabilityThreshold <= 0 || cfg.Business.SLO.AvailabilityThreshold > 100 {
@@ -94,6 +208,12 @@ func (v *ConfigValidator) Validate(cfg *Config) error {
 	if cfg.Business.SLO.LatencyP95Threshold <= 0 {
 		return fmt.Errorf("SLO latency threshold must be positive")
 	}
+	if err := validateSelfSLO(cfg.Server.SelfSLO); err != nil {
+		return err
+	}
+	if cfg.Server.Canary.Interval < 0 || cfg.Server.Canary.MaxLatency < 0 {
+		return fmt.Errorf("canary interval and max latency must not be negative")
+	}
 
 	// 效率阈值验证
 	thresholds := cfg.Business.CostCalculation.EfficiencyThresholds
@@ -109,6 +229,37 @@ func (v *ConfigValidator) Validate(cfg *Config) error {
 	if thresholds.Danger <= thresholds.Healthy || thresholds.Danger >= 100 {
 		return fmt.Errorf("danger efficiency threshold must be between healthy and 100")
 	}
+	if err := validateRightsizingSafety(cfg.Business.RightsizingSafety); err != nil {
+		return err
+	}
+	if err := validateDependencies(cfg.Business.Dependencies); err != nil {
+		return err
+	}
+	switch cfg.Business.GradeView {
+	case "", "original", "regraded":
+	default:
+		return fmt.Errorf("grade view must be original or regraded")
+	}
+
+	// 国际化配置验证
+	if err := validateLocale("i18n.default_locale", cfg.I18n.DefaultLocale); err != nil {
+		return err
+	}
+	for team, t := range cfg.Notifier.Digest.Teams {
+		if err := validateLocale("locale of digest team "+team, t.Locale); err != nil {
+			return err
+		}
+	}
+
+	// 导出任务配置验证
+	if cfg.Export.Retention < 0 || cfg.Export.Workers < 0 {
+		return fmt.Errorf("export retention and workers must be non-negative")
+	}
+
+	// 故障演练配置验证
+	if err := validateChaos(cfg.Env, cfg.Chaos); err != nil {
+		return err
+	}
 
 	// 安全配置验证
 	if cfg.Security.RateLimiting.PrometheusQueriesPerMinute <= 0 {
@@ -117,6 +268,9 @@ func (v *ConfigValidator) Validate(cfg *Config) error {
 	if cfg.Security.RateLimiting.K8SAPICallsPerMinute <= 0 {
 		return fmt.Errorf("K8S API call rate limit must be positive")
 	}
+	if err := validateQueryBudgets(cfg.Security.QueryBudgets); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -125,3 +279,170 @@ func isValidURL(url string) bool {
 	r, _ := regexp.Compile(`^(http|https)://[a-zA-Z0-9.-]+(:[0-9]+)?(/.*)?$`)
 	return r.MatchString(url)
 }
+
+// validateOffHours 校验工作时间定义：时区可加载、0 <= start_hour < end_hour <= 24（都为 0 表示默认）
+func validateFiscal(c FiscalConfig) error {
+	if c.StartMonth < 0 || c.StartMonth > 12 {
+		return fmt.Errorf("fiscal start_month must be 1-12")
+	}
+	switch c.Pattern {
+	case "", "months", "4-4-5", "4-5-4", "5-4-4":
+	default:
+		return fmt.Errorf("invalid fiscal pattern %q: must be months, 4-4-5, 4-5-4 or 5-4-4", c.Pattern)
+	}
+	if c.WeekStart != "" {
+		valid := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			valid = valid || strings.EqualFold(c.WeekStart, d.String())
+		}
+		if !valid {
+			return fmt.Errorf("invalid fiscal week_start %q: must be a day of the week", c.WeekStart)
+		}
+	}
+	return nil
+}
+
+// validateRightsizingSafety 校验规格缩减安全检查阈值：均在 [0, 100]，都配置时标记阈值不高于抑制阈值
+func validateRightsizingSafety(c RightsizingSafetyConfig) error {
+	for _, v := range []float64{c.ThrottlingFlagRate, c.ThrottlingSuppressRate, c.MinErrorBudget} {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("rightsizing safety thresholds must be percentages in [0, 100]")
+		}
+	}
+	if c.ThrottlingFlagRate > 0 && c.ThrottlingSuppressRate > 0 && c.ThrottlingFlagRate > c.ThrottlingSuppressRate {
+		return fmt.Errorf("rightsizing safety throttling_flag_rate cannot exceed throttling_suppress_rate")
+	}
+	return nil
+}
+
+// validateDependencies 校验服务依赖图：过期时间非负，静态依赖的两端为 namespace/service 且不相同
+func validateDependencies(c DependencyConfig) error {
+	if c.TracingTTL < 0 {
+		return fmt.Errorf("dependency tracing_ttl must be non-negative")
+	}
+	for i, e := range c.Static {
+		for _, svc := range []string{e.Caller, e.Callee} {
+			if ns, name, ok := strings.Cut(svc, "/"); !ok || ns == "" || name == "" || strings.Contains(name, "/") {
+				return fmt.Errorf("static dependency %d: %q is not namespace/service", i, svc)
+			}
+		}
+		if e.Caller == e.Callee {
+			return fmt.Errorf("static dependency %d: %s calls itself", i, e.Caller)
+		}
+	}
+	return nil
+}
+
+// validateSelfSLO 校验自身 API SLO：窗口非负，可用性目标在 [0, 100)，延迟目标非负，路由键为 "METHOD /path"
+func validateSelfSLO(c SelfSLOConfig) error {
+	if c.Window < 0 {
+		return fmt.Errorf("self SLO window must be non-negative")
+	}
+	targets := map[string]SelfSLOTarget{"": {AvailabilityTarget: c.AvailabilityTarget, LatencyTargetMs: c.LatencyTargetMs}}
+	for route, t := range c.Routes {
+		if method, path, ok := strings.Cut(route, " "); !ok || method == "" || !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("invalid self SLO route %q: must be \"METHOD /path\"", route)
+		}
+		targets[route] = t
+	}
+	for _, t := range targets {
+		if t.AvailabilityTarget < 0 || t.AvailabilityTarget >= 100 {
+			return fmt.Errorf("self SLO availability target must be in [0, 100)")
+		}
+		if t.LatencyTargetMs < 0 {
+			return fmt.Errorf("self SLO latency target must be non-negative")
+		}
+	}
+	return nil
+}
+
+// validateKubernetesClient 校验 K8s 客户端：client 为 mock / cluster，namespace_scoped 的集群客户端需指定 namespace
+func validateKubernetesClient(k KubernetesConfig) error {
+	switch k.Client {
+	case "", "mock", "cluster":
+	default:
+		return fmt.Errorf("invalid kubernetes client %q (mock/cluster)", k.Client)
+	}
+	if k.Client == "cluster" && k.RBAC.NamespaceScoped && k.Namespace == "" {
+		return fmt.Errorf("kubernetes namespace is required when rbac.namespace_scoped is set")
+	}
+	return nil
+}
+
+// validateRetry 校验重试策略：次数与退避时间非负，max_backoff 不小于 initial_backoff，multiplier 为 0 或 >= 1，jitter 在 [0, 1]
+func validateRetry(name string, c RetryConfig) error {
+	if c.MaxAttempts < 0 || c.InitialBackoff < 0 || c.MaxBackoff < 0 {
+		return fmt.Errorf("%s retry max_attempts and backoffs must be non-negative", name)
+	}
+	if c.MaxBackoff > 0 && c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("%s retry max_backoff cannot be less than initial_backoff", name)
+	}
+	if c.Multiplier != 0 && c.Multiplier < 1 {
+		return fmt.Errorf("%s retry multiplier must be at least 1", name)
+	}
+	if c.Jitter < 0 || c.Jitter > 1 {
+		return fmt.Errorf("%s retry jitter must be between 0 and 1", name)
+	}
+	return nil
+}
+
+// validateQueryBudgets 校验查询预算：窗口与各配额非负
+func validateQueryBudgets(c QueryBudgetConfig) error {
+	if c.Window < 0 {
+		return fmt.Errorf("query budget window must be non-negative")
+	}
+	quotas := map[string]QueryBudgetQuota{"default": {PrometheusQueries: c.PrometheusQueries, DatabaseQueries: c.DatabaseQueries}}
+	for consumer, q := range c.Consumers {
+		quotas[consumer] = q
+	}
+	for consumer, q := range quotas {
+		if q.PrometheusQueries < 0 || q.DatabaseQueries < 0 {
+			return fmt.Errorf("query budget quotas of %s must be non-negative", consumer)
+		}
+	}
+	return nil
+}
+
+// validateChaos 校验故障演练：prod 环境不可启用，最长时间非负，错误率与长尾比例在 [0, 1]，延迟非负
+func validateChaos(env Environment, c ChaosConfig) error {
+	if c.Enabled && env == EnvProduction {
+		return fmt.Errorf("chaos drills cannot be enabled in prod")
+	}
+	if c.MaxDuration < 0 {
+		return fmt.Errorf("chaos max_duration must be non-negative")
+	}
+	for name, s := range c.Scenarios {
+		for source, f := range map[string]ChaosFaultConfig{"prometheus": s.Prometheus, "kubernetes": s.Kubernetes, "database": s.Database} {
+			if f.ErrorRate < 0 || f.ErrorRate > 1 || f.TailLatencyRate < 0 || f.TailLatencyRate > 1 {
+				return fmt.Errorf("chaos scenario %s: %s error_rate and tail_latency_rate must be between 0 and 1", name, source)
+			}
+			if f.LatencyMs < 0 || f.LatencyJitterMs < 0 || f.TailLatencyMs < 0 {
+				return fmt.Errorf("chaos scenario %s: %s latencies must be non-negative", name, source)
+			}
+		}
+	}
+	return nil
+}
+
+// validateLocale 校验语言标签：空（默认语言）或 i18n 支持的语言
+func validateLocale(name, tag string) error {
+	if _, ok := i18n.Parse(tag); tag != "" && !ok {
+		return fmt.Errorf("invalid %s %q: supported locales are en and zh-CN", name, tag)
+	}
+	return nil
+}
+
+func validateOffHours(c OffHoursConfig) error {
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("invalid off-hours timezone %q: %w", c.Timezone, err)
+		}
+	}
+	if (c.StartHour != 0 || c.EndHour != 0) && (c.StartHour < 0 || c.EndHour > 24 || c.StartHour >= c.EndHour) {
+		return fmt.Errorf("off-hours business hours must satisfy 0 <= start_hour < end_hour <= 24")
+	}
+	if c.IdleUtilization < 0 || c.IdleUtilization >= 1 {
+		return fmt.Errorf("off-hours idle utilization must be in [0, 1)")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package analysisengine breaker.go: consecutive-failure circuit breaker.
+package analysisengine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// ErrCircuitOpen is returned without calling the engine while the circuit is open.
+var ErrCircuitOpen = dataerr.Unavailable("analysis engine circuit breaker is open")
+
+// BreakerState is the circuit breaker state.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// circuitBreaker opens after failureThreshold consecutive failures and, after openTimeout,
+// lets a single probe through (half-open). A successful probe closes the circuit.
+type circuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(failureThreshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+		state:            BreakerClosed,
+	}
+}
+
+// allow reports whether a call may proceed.
+func (b *circuitBreaker) allow() error {
+	if b.failureThreshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of an allowed call.
+func (b *circuitBreaker) record(success bool) {
+	if b.failureThreshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+func (b *circuitBreaker) currentState() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
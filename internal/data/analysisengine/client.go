@@ -0,0 +1,50 @@
+// Package analysisengine provides client implementations for the Analysis Engine,
+// which performs root-cause analysis on SLO evidence chains and anomaly detection on cost series.
+package analysisengine
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+)
+
+// Client defines the interface for Analysis Engine clients.
+type Client interface {
+	// AnalyzeRootCause runs root-cause analysis for an SLO violation using its evidence chain.
+	AnalyzeRootCause(ctx context.Context, req RootCauseRequest) (*slo.RootCauseAnalysis, error)
+
+	// DetectAnomalies detects anomalous points in a time series (e.g. daily namespace cost).
+	DetectAnomalies(ctx context.Context, req AnomalyRequest) ([]Anomaly, error)
+
+	// HealthCheck checks if the Analysis Engine is reachable and healthy.
+	HealthCheck(ctx context.Context) error
+}
+
+// RootCauseRequest is the input for root-cause analysis.
+type RootCauseRequest struct {
+	Violation     slo.SLOViolationEvent `json:"violation"`
+	EvidenceChain *slo.EvidenceChain    `json:"evidence_chain,omitempty"`
+}
+
+// SeriesPoint is a single time-series sample.
+type SeriesPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// AnomalyRequest is the input for anomaly detection.
+type AnomalyRequest struct {
+	SeriesName  string        `json:"series_name"` // e.g. "namespace_cost:app-prod"
+	Points      []SeriesPoint `json:"points"`
+	Sensitivity float64       `json:"sensitivity,omitempty"` // 0-1, engine default when zero
+}
+
+// Anomaly is a detected anomalous point.
+type Anomaly struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Value         float64   `json:"value"`
+	ExpectedValue float64   `json:"expected_value"`
+	Score         float64   `json:"score"`     // 0-1, higher is more anomalous
+	Direction     string    `json:"direction"` // "spike" or "drop"
+}
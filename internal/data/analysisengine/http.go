@@ -0,0 +1,268 @@
+// Package analysisengine http.go: HTTP/JSON client with retry, exponential backoff and circuit breaking.
+package analysisengine
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/retry"
+)
+
+// Config holds Analysis Engine client settings; the first six fields mirror config.AnalysisEngineConfig.
+type Config struct {
+	Address       string
+	APIKey        string `json:"-"`
+	Timeout       time.Duration
+	MaxRetries    int
+	RetryDelay    time.Duration
+	EnableTracing bool
+
+	// BreakerFailureThreshold opens the circuit after this many consecutive failed calls; <=0 disables.
+	BreakerFailureThreshold int
+	// BreakerOpenTimeout is how long the circuit stays open before a probe is allowed.
+	BreakerOpenTimeout time.Duration
+
+	// Retry overrides MaxRetries and RetryDelay when Retry.MaxAttempts is set; otherwise the client
+	// makes MaxRetries+1 attempts backing off exponentially from RetryDelay.
+	Retry retry.Policy
+}
+
+// APIError is a non-2xx response from the engine.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("analysis engine: HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap classifies the response status in the dataerr taxonomy (nil for other statuses).
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return dataerr.ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return dataerr.ErrConflict
+	case e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity:
+		return dataerr.ErrValidation
+	case e.Retryable():
+		return dataerr.ErrUnavailable
+	}
+	return nil
+}
+
+// Retryable reports whether the request may succeed on retry (5xx and 429).
+func (e *APIError) Retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+type requestIDKey struct{}
+
+// WithRequestID attaches a request ID that is forwarded as X-Request-Id when tracing is enabled.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// HTTPClient implements Client over the engine's REST API.
+type HTTPClient struct {
+	config  Config
+	client  *http.Client
+	breaker *circuitBreaker
+	retrier *retry.Retrier
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewHTTPClient creates an Analysis Engine client.
+func NewHTTPClient(config Config) (*HTTPClient, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("analysis engine address is required")
+	}
+	if !strings.Contains(config.Address, "://") {
+		config.Address = "http://" + config.Address
+	}
+	config.Address = strings.TrimRight(config.Address, "/")
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+	if config.BreakerOpenTimeout <= 0 {
+		config.BreakerOpenTimeout = 30 * time.Second
+	}
+	policy := config.Retry
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = config.MaxRetries + 1
+		if policy.InitialBackoff <= 0 {
+			policy.InitialBackoff = config.RetryDelay
+		}
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = retryable
+	}
+	c := &HTTPClient{
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		breaker: newCircuitBreaker(config.BreakerFailureThreshold, config.BreakerOpenTimeout),
+		sleep:   sleepContext,
+	}
+	c.retrier = retry.New(policy).WithSleep(func(ctx context.Context, d time.Duration) error { return c.sleep(ctx, d) })
+	return c, nil
+}
+
+// retryable retries every failure except caller errors (4xx other than 429) and cancellation.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// BreakerState returns the current circuit breaker state.
+func (c *HTTPClient) BreakerState() BreakerState {
+	return c.breaker.currentState()
+}
+
+// AnalyzeRootCause implements Client.
+func (c *HTTPClient) AnalyzeRootCause(ctx context.Context, req RootCauseRequest) (*slo.RootCauseAnalysis, error) {
+	var out slo.RootCauseAnalysis
+	if err := c.call(ctx, http.MethodPost, "/api/v1/analyze/root-cause", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DetectAnomalies implements Client.
+func (c *HTTPClient) DetectAnomalies(ctx context.Context, req AnomalyRequest) ([]Anomaly, error) {
+	var out struct {
+		Anomalies []Anomaly `json:"anomalies"`
+	}
+	if err := c.call(ctx, http.MethodPost, "/api/v1/analyze/anomalies", req, &out); err != nil {
+		return nil, err
+	}
+	return out.Anomalies, nil
+}
+
+// HealthCheck implements Client.
+func (c *HTTPClient) HealthCheck(ctx context.Context) error {
+	return c.call(ctx, http.MethodGet, "/healthz", nil, nil)
+}
+
+// call performs one logical request with retries (Config.Retry). The breaker sees one outcome per logical call;
+// 4xx responses (other than 429) are caller errors and neither retried nor counted as failures.
+func (c *HTTPClient) call(ctx context.Context, method, path string, in, out interface{}) error {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			c.breaker.record(true)
+			return fmt.Errorf("analysis engine: marshal request: %w", err)
+		}
+	}
+
+	err := c.retrier.Do(ctx, func() error { return c.do(ctx, method, path, body, out) })
+	var apiErr *APIError
+	c.breaker.record(err == nil || (errors.As(err, &apiErr) && !apiErr.Retryable()))
+	return err
+}
+
+func (c *HTTPClient) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.config.Address+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+	if c.config.EnableTracing {
+		setTraceHeaders(ctx, req)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("analysis engine %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("analysis engine %s %s: read response: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var e struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &e) == nil {
+			if e.Message != "" {
+				msg = e.Message
+			} else if e.Error != "" {
+				msg = e.Error
+			}
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: msg}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("analysis engine %s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+// setTraceHeaders forwards the request ID (from WithRequestID or the gin "requestId" key)
+// and starts a W3C trace context for the outbound call.
+func setTraceHeaders(ctx context.Context, req *http.Request) {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	if requestID == "" {
+		requestID, _ = ctx.Value("requestId").(string)
+	}
+	if requestID != "" {
+		req.Header.Set("X-Request-Id", requestID)
+	}
+	traceID := make([]byte, 16)
+	spanID := make([]byte, 8)
+	_, _ = rand.Read(traceID)
+	_, _ = rand.Read(spanID)
+	req.Header.Set("traceparent", "00-"+hex.EncodeToString(traceID)+"-"+hex.EncodeToString(spanID)+"-01")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+package analysisengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+func noSleep(ctx context.Context, d time.Duration) error { return nil }
+
+func newTestClient(t *testing.T, url string, cfg Config) *HTTPClient {
+	t.Helper()
+	cfg.Address = url
+	c, err := NewHTTPClient(cfg)
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+	c.sleep = noSleep
+	return c
+}
+
+func TestHTTPClient_AnalyzeRootCause(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if r.URL.Path != "/api/v1/analyze/root-cause" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("traceparent"), "00-") || r.Header.Get("X-Request-Id") != "req-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req RootCauseRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(slo.RootCauseAnalysis{RCAID: "rca-1", RootCauseDescription: req.Violation.ServiceName})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, Config{APIKey: "secret", MaxRetries: 2, EnableTracing: true})
+	ctx := WithRequestID(context.Background(), "req-1")
+	rca, err := c.AnalyzeRootCause(ctx, RootCauseRequest{Violation: slo.SLOViolationEvent{ServiceName: "api"}})
+	if err != nil {
+		t.Fatalf("AnalyzeRootCause: %v", err)
+	}
+	if rca.RCAID != "rca-1" || rca.RootCauseDescription != "api" || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("rca=%+v calls=%d", rca, calls)
+	}
+}
+
+func TestHTTPClient_NoRetryOnClientError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"series too short"}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, Config{MaxRetries: 3, BreakerFailureThreshold: 1})
+	_, err := c.DetectAnomalies(context.Background(), AnomalyRequest{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "series too short" {
+		t.Fatalf("err = %v", err)
+	}
+	if !errors.Is(err, dataerr.ErrValidation) {
+		t.Errorf("a 400 response should be classified as a validation error: %v", err)
+	}
+	if calls != 1 || c.BreakerState() != BreakerClosed {
+		t.Errorf("calls=%d breaker=%s", calls, c.BreakerState())
+	}
+}
+
+func TestHTTPClient_CircuitBreaker(t *testing.T) {
+	var calls, healthy int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL, Config{MaxRetries: 1, BreakerFailureThreshold: 2, BreakerOpenTimeout: time.Minute})
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := c.HealthCheck(ctx); err == nil {
+			t.Fatal("expected failure")
+		}
+	}
+	if c.BreakerState() != BreakerOpen || calls != 4 {
+		t.Fatalf("state=%s calls=%d", c.BreakerState(), calls)
+	}
+	if err := c.HealthCheck(ctx); !errors.Is(err, ErrCircuitOpen) || calls != 4 {
+		t.Fatalf("open circuit should short-circuit: err=%v calls=%d", err, calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	atomic.StoreInt32(&healthy, 1)
+	if err := c.HealthCheck(ctx); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if c.BreakerState() != BreakerClosed {
+		t.Errorf("state after successful probe = %s", c.BreakerState())
+	}
+}
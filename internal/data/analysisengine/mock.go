@@ -0,0 +1,192 @@
+// Package analysisengine provides mock implementations for testing.
+package analysisengine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/latency"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockid"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockrand"
+)
+
+// MockConfig defines configuration options for the mock Analysis Engine client.
+type MockConfig struct {
+	// RandomSeed for deterministic generation
+	RandomSeed int64 `json:"random_seed"`
+
+	// ErrorRate controls probability of returning errors (0.0 - 1.0)
+	ErrorRate float64 `json:"error_rate"`
+
+	// LatencyMs simulates network latency in milliseconds
+	LatencyMs int `json:"latency_ms"`
+
+	// LatencyJitterMs adds a uniform random 0..LatencyJitterMs to LatencyMs
+	LatencyJitterMs int `json:"latency_jitter_ms"`
+
+	// TailLatencyRate is the probability (0.0 - 1.0) of a long-tail call, which waits an extra
+	// Pareto-distributed TailLatencyMs..20×TailLatencyMs (see latency.Profile)
+	TailLatencyRate float64 `json:"tail_latency_rate"`
+	TailLatencyMs   int     `json:"tail_latency_ms"`
+
+	// AnomalyThreshold is the z-score above which a point is reported as anomalous
+	AnomalyThreshold float64 `json:"anomaly_threshold"`
+
+	// IDMode selects how IDs are generated (see mockid): "random" (default) or "deterministic"
+	// for seeded UUIDv5 IDs that are stable across runs
+	IDMode string `json:"id_mode,omitempty"`
+}
+
+// DefaultMockConfig returns a default configuration for the mock client.
+func DefaultMockConfig() MockConfig {
+	return MockConfig{
+		RandomSeed:       42,
+		ErrorRate:        0.0,
+		LatencyMs:        10,
+		AnomalyThreshold: 2.0,
+	}
+}
+
+// MockClient is a mock implementation of the Analysis Engine Client interface.
+// Root causes are derived from the evidence chain with simple rules; anomalies use a z-score.
+type MockClient struct {
+	config  MockConfig
+	rand    *rand.Rand
+	latency *latency.Simulator
+	ids     *mockid.Generator
+}
+
+// NewMockClient creates a new mock Analysis Engine client with the given configuration.
+func NewMockClient(config MockConfig) *MockClient {
+	if config.RandomSeed == 0 {
+		config.RandomSeed = time.Now().UnixNano()
+	}
+	if config.AnomalyThreshold <= 0 {
+		config.AnomalyThreshold = 2.0
+	}
+	r := mockrand.New(config.RandomSeed)
+	return &MockClient{
+		config:  config,
+		rand:    r,
+		latency: latency.New(latency.Millis(config.LatencyMs, config.LatencyJitterMs, config.TailLatencyRate, config.TailLatencyMs), config.RandomSeed),
+		ids:     mockid.New(config.IDMode, config.RandomSeed, r),
+	}
+}
+
+// AnalyzeRootCause returns a rule-based root cause: recent K8s/config changes point to configuration,
+// saturation-style violations (latency) to infrastructure, otherwise application.
+func (m *MockClient) AnalyzeRootCause(ctx context.Context, req RootCauseRequest) (*slo.RootCauseAnalysis, error) {
+	if err := m.simulateLatency(ctx); err != nil {
+		return nil, err
+	}
+
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock Analysis Engine error: root cause analysis failed")
+	}
+
+	rca := &slo.RootCauseAnalysis{
+		RCAID:                m.ids.Next("rca", req.Violation.Namespace, req.Violation.ServiceName, mockid.Time(req.Violation.ViolationTime)),
+		AnalyzedAt:           time.Now(),
+		RootCauseCategory:    "application",
+		RootCauseDescription: fmt.Sprintf("%s degradation in %s/%s", req.Violation.ViolationType, req.Violation.Namespace, req.Violation.ServiceName),
+		ConfidenceLevel:      0.5,
+	}
+
+	chain := req.EvidenceChain
+	switch {
+	case chain != nil && (len(chain.Change.K8sEvents) > 0 || len(chain.Change.ConfigChanges) > 0):
+		rca.RootCauseCategory = "configuration"
+		rca.ConfidenceLevel = 0.8
+		for _, ev := range chain.Change.K8sEvents {
+			rca.EvidenceReferences = append(rca.EvidenceReferences, slo.EvidenceReference{
+				EvidenceType:   "event",
+				ResourceID:     ev.Namespace + "/" + ev.Kind + "/" + ev.Name,
+				StartTime:      ev.Timestamp,
+				EndTime:        ev.Timestamp,
+				Location:       ev.Message,
+				RelevanceScore: 0.8,
+			})
+		}
+	case req.Violation.ViolationType == "latency":
+		rca.RootCauseCategory = "infrastructure"
+		rca.ConfidenceLevel = 0.6
+	}
+	return rca, nil
+}
+
+// DetectAnomalies flags points whose z-score exceeds AnomalyThreshold.
+func (m *MockClient) DetectAnomalies(ctx context.Context, req AnomalyRequest) ([]Anomaly, error) {
+	if err := m.simulateLatency(ctx); err != nil {
+		return nil, err
+	}
+
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock Analysis Engine error: anomaly detection failed")
+	}
+
+	if len(req.Points) < 2 {
+		return nil, nil
+	}
+	var sum, sumSq float64
+	for _, p := range req.Points {
+		sum += p.Value
+		sumSq += p.Value * p.Value
+	}
+	n := float64(len(req.Points))
+	mean := sum / n
+	std := math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+	if std == 0 {
+		return nil, nil
+	}
+
+	threshold := m.config.AnomalyThreshold
+	if req.Sensitivity > 0 && req.Sensitivity <= 1 {
+		threshold *= 1.5 - req.Sensitivity
+	}
+	var anomalies []Anomaly
+	for _, p := range req.Points {
+		z := (p.Value - mean) / std
+		if math.Abs(z) < threshold {
+			continue
+		}
+		direction := "spike"
+		if z < 0 {
+			direction = "drop"
+		}
+		anomalies = append(anomalies, Anomaly{
+			Timestamp:     p.Timestamp,
+			Value:         p.Value,
+			ExpectedValue: mean,
+			Score:         math.Min(math.Abs(z)/(2*threshold), 1),
+			Direction:     direction,
+		})
+	}
+	return anomalies, nil
+}
+
+// HealthCheck always returns nil (healthy) for mock client.
+func (m *MockClient) HealthCheck(ctx context.Context) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock Analysis Engine health check failed")
+	}
+	return nil
+}
+
+// Helper methods
+
+// simulateLatency waits out the configured latency, returning ctx.Err() early once ctx is done.
+func (m *MockClient) simulateLatency(ctx context.Context) error {
+	return m.latency.Wait(ctx)
+}
+
+func (m *MockClient) shouldReturnError() bool {
+	if m.config.ErrorRate <= 0.0 {
+		return false
+	}
+	return m.rand.Float64() < m.config.ErrorRate
+}
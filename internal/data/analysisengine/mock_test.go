@@ -0,0 +1,59 @@
+package analysisengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+)
+
+func TestMockClient_AnalyzeRootCause(t *testing.T) {
+	ctx := context.Background()
+	m := NewMockClient(MockConfig{RandomSeed: 1})
+
+	rca, err := m.AnalyzeRootCause(ctx, RootCauseRequest{
+		Violation: slo.SLOViolationEvent{Namespace: "prod", ServiceName: "api", ViolationType: "availability"},
+		EvidenceChain: &slo.EvidenceChain{Change: slo.EvidenceChange{
+			K8sEvents: []slo.K8sEvent{{Type: "ImageUpdate", Namespace: "prod", Name: "api", Kind: "Deployment"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeRootCause: %v", err)
+	}
+	if rca.RootCauseCategory != "configuration" || len(rca.EvidenceReferences) != 1 {
+		t.Errorf("unexpected RCA %+v", rca)
+	}
+
+	rca, _ = m.AnalyzeRootCause(ctx, RootCauseRequest{Violation: slo.SLOViolationEvent{ViolationType: "latency"}})
+	if rca.RootCauseCategory != "infrastructure" {
+		t.Errorf("latency without changes: category = %s", rca.RootCauseCategory)
+	}
+}
+
+func TestMockClient_DetectAnomalies(t *testing.T) {
+	m := NewMockClient(MockConfig{RandomSeed: 1})
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var points []SeriesPoint
+	for i := 0; i < 14; i++ {
+		v := 100.0
+		if i == 10 {
+			v = 400
+		}
+		points = append(points, SeriesPoint{Timestamp: base.AddDate(0, 0, i), Value: v})
+	}
+	anomalies, err := m.DetectAnomalies(context.Background(), AnomalyRequest{SeriesName: "cost", Points: points})
+	if err != nil {
+		t.Fatalf("DetectAnomalies: %v", err)
+	}
+	if len(anomalies) != 1 || anomalies[0].Direction != "spike" || !anomalies[0].Timestamp.Equal(points[10].Timestamp) {
+		t.Errorf("unexpected anomalies %+v", anomalies)
+	}
+}
+
+func TestMockClient_ErrorRate(t *testing.T) {
+	m := NewMockClient(MockConfig{RandomSeed: 1, ErrorRate: 1})
+	if err := m.HealthCheck(context.Background()); err == nil {
+		t.Error("expected error with ErrorRate=1")
+	}
+}
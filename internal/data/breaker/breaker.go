@@ -0,0 +1,263 @@
+// Package breaker provides error-rate circuit breakers for external data sources
+// (Prometheus, K8s API, ClickHouse). Breakers are grouped per source in a Set and keyed by
+// target (endpoint / cluster), so one flapping Prometheus does not trip the others.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// ErrOpen is returned without calling the data source while its circuit is open.
+var ErrOpen = dataerr.Unavailable("circuit breaker is open")
+
+// State is the circuit breaker state.
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open"
+)
+
+// Config controls when a breaker trips.
+type Config struct {
+	// FailureRateThreshold opens the circuit when failures/requests in the window reaches it (0-1).
+	FailureRateThreshold float64
+	// MinRequests is the minimum number of calls in the window before the rate is evaluated.
+	MinRequests int
+	// Window is the length of the counting window in the closed state.
+	Window time.Duration
+	// OpenTimeout is how long the circuit stays open before half-open probes are allowed.
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of probes that must succeed to close the circuit again.
+	HalfOpenProbes int
+	// IsFailure classifies call errors; default counts every error except context.Canceled.
+	IsFailure func(error) bool
+}
+
+// DefaultConfig returns the defaults used when a field is zero.
+func DefaultConfig() Config {
+	return Config{
+		FailureRateThreshold: 0.5,
+		MinRequests:          10,
+		Window:               time.Minute,
+		OpenTimeout:          30 * time.Second,
+		HalfOpenProbes:       1,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.FailureRateThreshold <= 0 || c.FailureRateThreshold > 1 {
+		c.FailureRateThreshold = d.FailureRateThreshold
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = d.MinRequests
+	}
+	if c.Window <= 0 {
+		c.Window = d.Window
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = d.OpenTimeout
+	}
+	if c.HalfOpenProbes <= 0 {
+		c.HalfOpenProbes = d.HalfOpenProbes
+	}
+	if c.IsFailure == nil {
+		c.IsFailure = func(err error) bool { return err != nil && !errors.Is(err, context.Canceled) }
+	}
+	return c
+}
+
+// Status is a point-in-time view of one breaker.
+type Status struct {
+	Source    string    `json:"source"`
+	Target    string    `json:"target"`
+	State     State     `json:"state"`
+	Requests  int       `json:"requests"` // calls in the current window
+	Failures  int       `json:"failures"` // failed calls in the current window
+	Rejected  uint64    `json:"rejected"` // calls short-circuited since start
+	OpenedAt  time.Time `json:"opened_at,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Breaker is the circuit breaker of one target.
+type Breaker struct {
+	config Config
+	now    func() time.Time
+
+	mu          sync.Mutex
+	state       State
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int // in-flight half-open probes
+	probeOK     int // successful half-open probes
+	rejected    uint64
+	lastError   string
+}
+
+// New creates a closed breaker.
+func New(config Config) *Breaker {
+	return newBreaker(config.withDefaults(), time.Now)
+}
+
+func newBreaker(config Config, now func() time.Time) *Breaker {
+	return &Breaker{config: config, now: now, state: StateClosed, windowStart: now()}
+}
+
+// Allow reports whether a call may proceed; every allowed call must be followed by Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.openedAt) < b.config.OpenTimeout {
+			b.rejected++
+			return ErrOpen
+		}
+		b.state = StateHalfOpen
+		b.probes, b.probeOK = 0, 0
+		fallthrough
+	case StateHalfOpen:
+		if b.probes+b.probeOK >= b.config.HalfOpenProbes {
+			b.rejected++
+			return ErrOpen
+		}
+		b.probes++
+	default:
+		if now.Sub(b.windowStart) >= b.config.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call.
+func (b *Breaker) Record(err error) {
+	failed := b.config.IsFailure(err)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if failed {
+		b.lastError = err.Error()
+	}
+	switch b.state {
+	case StateHalfOpen:
+		if b.probes > 0 {
+			b.probes--
+		}
+		if failed {
+			b.trip()
+			return
+		}
+		b.probeOK++
+		if b.probeOK >= b.config.HalfOpenProbes {
+			b.state = StateClosed
+			b.windowStart, b.requests, b.failures = b.now(), 0, 0
+		}
+	case StateClosed:
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.config.MinRequests && float64(b.failures)/float64(b.requests) >= b.config.FailureRateThreshold {
+			b.trip()
+		}
+	}
+}
+
+// trip opens the circuit. Caller holds b.mu.
+func (b *Breaker) trip() {
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.probes, b.probeOK = 0, 0
+}
+
+// State returns the current state; an open breaker whose timeout elapsed reports half-open.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+func (b *Breaker) status() Status {
+	state := b.State()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := Status{State: state, Requests: b.requests, Failures: b.failures, Rejected: b.rejected, LastError: b.lastError}
+	if state != StateClosed {
+		st.OpenedAt = b.openedAt
+	}
+	return st
+}
+
+// Set holds the per-target breakers of one data source.
+type Set struct {
+	source string
+	config Config
+	now    func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewSet creates an empty set for source (e.g. "prometheus").
+func NewSet(source string, config Config) *Set {
+	return &Set{source: source, config: config.withDefaults(), now: time.Now, breakers: make(map[string]*Breaker)}
+}
+
+// Source returns the data source name.
+func (s *Set) Source() string { return s.source }
+
+// Get returns the breaker of target, creating it on first use.
+func (s *Set) Get(target string) *Breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[target]
+	if !ok {
+		b = newBreaker(s.config, s.now)
+		s.breakers[target] = b
+	}
+	return b
+}
+
+// Do runs fn through the breaker of target. ErrOpen is wrapped with source and target.
+func (s *Set) Do(target string, fn func() error) error {
+	b := s.Get(target)
+	if err := b.Allow(); err != nil {
+		return fmt.Errorf("%s %s: %w", s.source, target, err)
+	}
+	err := fn()
+	b.Record(err)
+	return err
+}
+
+// Statuses returns the status of every target, sorted by target.
+func (s *Set) Statuses() []Status {
+	s.mu.Lock()
+	targets := make([]string, 0, len(s.breakers))
+	for t := range s.breakers {
+		targets = append(targets, t)
+	}
+	s.mu.Unlock()
+	sort.Strings(targets)
+	out := make([]Status, 0, len(targets))
+	for _, t := range targets {
+		st := s.Get(t).status()
+		st.Source, st.Target = s.source, t
+		out = append(out, st)
+	}
+	return out
+}
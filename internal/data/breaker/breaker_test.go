@@ -0,0 +1,117 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestSet(config Config) (*Set, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewSet("prometheus", config)
+	s.now = clock.now
+	return s, clock
+}
+
+var errBoom = errors.New("boom")
+
+func TestBreaker_TripsOnFailureRate(t *testing.T) {
+	s, clock := newTestSet(Config{FailureRateThreshold: 0.5, MinRequests: 4, OpenTimeout: 10 * time.Second, HalfOpenProbes: 1})
+	calls := 0
+	fail := func() error { calls++; return errBoom }
+	ok := func() error { calls++; return nil }
+
+	// 3 failures are below MinRequests; the 4th call evaluates 3/4 >= 0.5.
+	_ = s.Do("a", fail)
+	_ = s.Do("a", fail)
+	_ = s.Do("a", fail)
+	if st := s.Get("a").State(); st != StateClosed {
+		t.Fatalf("state after 3 calls = %s, want closed", st)
+	}
+	_ = s.Do("a", ok)
+	if st := s.Get("a").State(); st != StateOpen {
+		t.Fatalf("state after 4 calls = %s, want open", st)
+	}
+
+	// Open: calls are rejected without reaching the source; other targets are unaffected.
+	before := calls
+	if err := s.Do("a", ok); !errors.Is(err, ErrOpen) {
+		t.Errorf("Do while open: err = %v, want ErrOpen", err)
+	}
+	if calls != before {
+		t.Error("open breaker must not call the source")
+	}
+	if err := s.Do("b", ok); err != nil {
+		t.Errorf("target b: err = %v, want nil", err)
+	}
+
+	// Half-open: one probe; a failing probe re-opens.
+	clock.advance(10 * time.Second)
+	if st := s.Get("a").State(); st != StateHalfOpen {
+		t.Fatalf("state after timeout = %s, want half_open", st)
+	}
+	_ = s.Do("a", fail)
+	if st := s.Get("a").State(); st != StateOpen {
+		t.Fatalf("state after failed probe = %s, want open", st)
+	}
+
+	// A successful probe closes the circuit with a fresh window.
+	clock.advance(10 * time.Second)
+	if err := s.Do("a", ok); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	st := s.Statuses()
+	if len(st) != 2 || st[0].Target != "a" || st[0].State != StateClosed || st[0].Requests != 0 || st[0].Rejected != 1 {
+		t.Errorf("Statuses = %+v", st)
+	}
+}
+
+func TestBreaker_HalfOpenLimitsProbes(t *testing.T) {
+	s, clock := newTestSet(Config{MinRequests: 1, OpenTimeout: time.Second, HalfOpenProbes: 2})
+	b := s.Get("a")
+	_ = b.Allow()
+	b.Record(errBoom)
+	clock.advance(time.Second)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe 1: %v", err)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe 2: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("probe 3: err = %v, want ErrOpen", err)
+	}
+	b.Record(nil)
+	if b.State() != StateHalfOpen {
+		t.Errorf("after 1 of 2 probes: state = %s, want half_open", b.State())
+	}
+	b.Record(nil)
+	if b.State() != StateClosed {
+		t.Errorf("after 2 probes: state = %s, want closed", b.State())
+	}
+}
+
+func TestBreaker_WindowAndCancellation(t *testing.T) {
+	s, clock := newTestSet(Config{FailureRateThreshold: 0.5, MinRequests: 2, Window: time.Minute})
+	_ = s.Do("a", func() error { return context.Canceled })
+	_ = s.Do("a", func() error { return context.Canceled })
+	if st := s.Get("a").State(); st != StateClosed {
+		t.Errorf("caller cancellation tripped the breaker: %s", st)
+	}
+
+	_ = s.Do("a", func() error { return errBoom })
+	clock.advance(time.Minute)
+	// The window restarts, so only the two calls below are evaluated.
+	_ = s.Do("a", func() error { return nil })
+	_ = s.Do("a", func() error { return errBoom })
+	if st := s.Get("a").State(); st != StateOpen {
+		t.Errorf("1/2 failures in window: state = %s, want open", st)
+	}
+}
@@ -0,0 +1,216 @@
+// Package chaos injects failures and latency into the live data clients for resilience drills: an
+// admin starts a configured scenario for a bounded time window and the Prometheus, Kubernetes and
+// database wrappers fail or slow down their calls with the same knobs the mock clients use, so
+// degraded-mode behaviour can be rehearsed in staging. Outside a drill the wrappers are inert.
+package chaos
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/latency"
+)
+
+// DefaultMaxDuration bounds the window of a drill when no maximum is configured.
+const DefaultMaxDuration = time.Hour
+
+// Source is the data source a fault is injected into.
+type Source string
+
+const (
+	Prometheus Source = "prometheus"
+	Kubernetes Source = "kubernetes"
+	Database   Source = "database"
+)
+
+// Sources lists the data sources in display order.
+var Sources = []Source{Prometheus, Kubernetes, Database}
+
+// Fault is the failure injected into the calls of one data source, with the knobs of the mock
+// configs: every call waits LatencyMs plus jitter and long tail (see latency.Profile), then fails
+// with probability ErrorRate.
+type Fault struct {
+	ErrorRate       float64 `json:"error_rate"`
+	LatencyMs       int     `json:"latency_ms"`
+	LatencyJitterMs int     `json:"latency_jitter_ms"`
+	TailLatencyRate float64 `json:"tail_latency_rate"`
+	TailLatencyMs   int     `json:"tail_latency_ms"`
+}
+
+// IsZero reports whether f injects nothing.
+func (f Fault) IsZero() bool {
+	return f == Fault{}
+}
+
+// Scenario is the faults of a drill by data source; sources without a fault are left alone.
+type Scenario map[Source]Fault
+
+// Counts is what a drill injected into one data source.
+type Counts struct {
+	Calls  int64 `json:"calls"`
+	Errors int64 `json:"errors"`
+}
+
+// Drill is a started drill. It is active from StartedAt until EndsAt unless stopped earlier.
+type Drill struct {
+	Scenario  string
+	Faults    Scenario
+	StartedBy string
+	StartedAt time.Time
+	EndsAt    time.Time
+	StoppedAt time.Time // zero unless stopped before EndsAt
+	Injected  map[Source]Counts
+}
+
+// ActiveAt reports whether the drill injects faults at t.
+func (d Drill) ActiveAt(t time.Time) bool {
+	return d.StoppedAt.IsZero() && !t.Before(d.StartedAt) && t.Before(d.EndsAt)
+}
+
+// Controller holds the configured scenarios and the current drill. It is safe for concurrent use.
+type Controller struct {
+	scenarios   map[string]Scenario
+	maxDuration time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	seed     int64
+	drill    *Drill
+	rand     *rand.Rand
+	latency  map[Source]*latency.Simulator
+	injected map[Source]*Counts
+}
+
+// NewController creates a controller for the named scenarios; a drill lasts at most maxDuration
+// (<= 0: DefaultMaxDuration).
+func NewController(scenarios map[string]Scenario, maxDuration time.Duration) *Controller {
+	if maxDuration <= 0 {
+		maxDuration = DefaultMaxDuration
+	}
+	return &Controller{scenarios: scenarios, maxDuration: maxDuration, now: time.Now, seed: time.Now().UnixNano()}
+}
+
+// MaxDuration returns the longest window a drill may be started for.
+func (c *Controller) MaxDuration() time.Duration {
+	return c.maxDuration
+}
+
+// Scenarios returns the configured scenarios by name.
+func (c *Controller) Scenarios() map[string]Scenario {
+	return c.scenarios
+}
+
+// ScenarioNames returns the names of the configured scenarios, sorted.
+func (c *Controller) ScenarioNames() []string {
+	names := make([]string, 0, len(c.scenarios))
+	for name := range c.scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start starts scenario for duration on behalf of actor. Only one drill runs at a time: starting
+// while another is active is a conflict, so a drill has to be stopped before switching scenarios.
+func (c *Controller) Start(scenario string, duration time.Duration, actor string) (Drill, error) {
+	faults, ok := c.scenarios[scenario]
+	if !ok {
+		return Drill{}, dataerr.NotFound("chaos scenario %q not configured", scenario)
+	}
+	if duration <= 0 || duration > c.maxDuration {
+		return Drill{}, dataerr.Validation("chaos drill duration must be positive and at most %s", c.maxDuration)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.drill != nil && c.drill.ActiveAt(now) {
+		return Drill{}, dataerr.Conflict("chaos scenario %q is active until %s", c.drill.Scenario, c.drill.EndsAt.Format(time.RFC3339))
+	}
+	c.seed++
+	c.drill = &Drill{Scenario: scenario, Faults: faults, StartedBy: actor, StartedAt: now, EndsAt: now.Add(duration)}
+	c.rand = rand.New(rand.NewSource(c.seed))
+	c.latency = make(map[Source]*latency.Simulator, len(faults))
+	c.injected = make(map[Source]*Counts, len(faults))
+	for source, f := range faults {
+		c.latency[source] = latency.New(latency.Millis(f.LatencyMs, f.LatencyJitterMs, f.TailLatencyRate, f.TailLatencyMs), c.seed)
+		c.injected[source] = &Counts{}
+	}
+	return c.snapshot(), nil
+}
+
+// Stop ends the active drill early and returns it; false when no drill is active.
+func (c *Controller) Stop() (Drill, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.drill == nil || !c.drill.ActiveAt(now) {
+		return Drill{}, false
+	}
+	c.drill.StoppedAt = now
+	return c.snapshot(), true
+}
+
+// Last returns the current or last drill; false when none was started.
+func (c *Controller) Last() (Drill, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.drill == nil {
+		return Drill{}, false
+	}
+	return c.snapshot(), true
+}
+
+// Active reports whether a drill injects faults now.
+func (c *Controller) Active() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.drill != nil && c.drill.ActiveAt(c.now())
+}
+
+// snapshot copies the drill and its counters; c.mu must be held.
+func (c *Controller) snapshot() Drill {
+	d := *c.drill
+	d.Injected = make(map[Source]Counts, len(c.injected))
+	for source, n := range c.injected {
+		d.Injected[source] = *n
+	}
+	return d
+}
+
+// Inject applies the fault of source in the active drill to a call: it waits the sampled latency
+// (returning ctx.Err() if ctx is done first), then fails the call with probability ErrorRate with
+// an unavailable error, as an outage of the data source would. Outside a drill it returns nil at once.
+func (c *Controller) Inject(ctx context.Context, source Source) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	if c.drill == nil || !c.drill.ActiveAt(c.now()) {
+		c.mu.Unlock()
+		return nil
+	}
+	fault, ok := c.drill.Faults[source]
+	if !ok || fault.IsZero() {
+		c.mu.Unlock()
+		return nil
+	}
+	scenario, sim, n := c.drill.Scenario, c.latency[source], c.injected[source]
+	fail := fault.ErrorRate > 0 && c.rand.Float64() < fault.ErrorRate
+	n.Calls++
+	if fail {
+		n.Errors++
+	}
+	c.mu.Unlock()
+
+	if err := sim.Wait(ctx); err != nil {
+		return err
+	}
+	if fail {
+		return dataerr.Unavailable("chaos drill %q: injected %s failure", scenario, source)
+	}
+	return nil
+}
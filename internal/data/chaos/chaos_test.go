@@ -0,0 +1,96 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+func TestControllerDrillWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := NewController(map[string]Scenario{
+		"prometheus-outage": {Prometheus: {ErrorRate: 1}},
+	}, 30*time.Minute)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := c.Inject(ctx, Prometheus); err != nil {
+		t.Fatalf("Inject outside a drill = %v, want nil", err)
+	}
+	if _, err := c.Start("missing", time.Minute, "ops"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("Start(missing) = %v, want not found", err)
+	}
+	if _, err := c.Start("prometheus-outage", time.Hour, "ops"); !errors.Is(err, dataerr.ErrValidation) {
+		t.Errorf("Start over max duration = %v, want validation error", err)
+	}
+
+	drill, err := c.Start("prometheus-outage", 10*time.Minute, "ops")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if !drill.EndsAt.Equal(now.Add(10*time.Minute)) || drill.StartedBy != "ops" {
+		t.Errorf("drill = %+v", drill)
+	}
+	if _, err := c.Start("prometheus-outage", time.Minute, "ops"); !errors.Is(err, dataerr.ErrConflict) {
+		t.Errorf("Start during a drill = %v, want conflict", err)
+	}
+	if err := c.Inject(ctx, Prometheus); !errors.Is(err, dataerr.ErrUnavailable) {
+		t.Errorf("Inject(prometheus) = %v, want unavailable", err)
+	}
+	if err := c.Inject(ctx, Database); err != nil {
+		t.Errorf("Inject(database) = %v, want nil: the scenario has no database fault", err)
+	}
+	if last, _ := c.Last(); last.Injected[Prometheus] != (Counts{Calls: 1, Errors: 1}) {
+		t.Errorf("injected = %+v", last.Injected)
+	}
+
+	// 窗口结束后自动失效，无需 Stop
+	now = now.Add(10 * time.Minute)
+	if c.Active() {
+		t.Error("drill still active after its window")
+	}
+	if err := c.Inject(ctx, Prometheus); err != nil {
+		t.Errorf("Inject after the window = %v, want nil", err)
+	}
+	if _, ok := c.Stop(); ok {
+		t.Error("Stop after the window: want no active drill")
+	}
+
+	if _, err := c.Start("prometheus-outage", time.Minute, "ops"); err != nil {
+		t.Fatalf("Start after the window: %v", err)
+	}
+	stopped, ok := c.Stop()
+	if !ok || !stopped.StoppedAt.Equal(now) {
+		t.Errorf("Stop = %+v, %v", stopped, ok)
+	}
+	if err := c.Inject(ctx, Prometheus); err != nil {
+		t.Errorf("Inject after Stop = %v, want nil", err)
+	}
+}
+
+func TestControllerInjectLatency(t *testing.T) {
+	c := NewController(map[string]Scenario{"slow-database": {Database: {LatencyMs: 50}}}, 0)
+	if c.MaxDuration() != DefaultMaxDuration {
+		t.Errorf("MaxDuration = %s, want %s", c.MaxDuration(), DefaultMaxDuration)
+	}
+	if _, err := c.Start("slow-database", time.Minute, ""); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	start := time.Now()
+	if err := c.Inject(context.Background(), Database); err != nil {
+		t.Fatalf("Inject: %v", err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("Inject waited %s, want at least 50ms", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Inject(ctx, Database); !errors.Is(err, context.Canceled) {
+		t.Errorf("Inject with a cancelled context = %v, want context.Canceled", err)
+	}
+}
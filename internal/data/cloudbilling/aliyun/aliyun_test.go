@@ -0,0 +1,155 @@
+package aliyun
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/cloudbilling"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+)
+
+func newTestServer(t *testing.T, handler func(q url.Values) interface{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("Signature") == "" || q.Get("AccessKeyId") != "ak" {
+			t.Errorf("request not signed: %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(handler(q))
+	}))
+}
+
+func bssPage(total int, items []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"Code":    "Success",
+		"Success": true,
+		"Data": map[string]interface{}{
+			"TotalCount": total,
+			"Items":      map[string]interface{}{"Item": items},
+		},
+	}
+}
+
+func TestFetcherRegistered(t *testing.T) {
+	f := cloudbilling.NewFetcher(cloudbilling.CloudBillingConfig{Provider: "aliyun"})
+	if f == nil {
+		t.Fatal("expected aliyun provider to be registered")
+	}
+	if _, ok := f.(cloudbilling.InstanceBillFetcher); !ok {
+		t.Error("aliyun fetcher should implement InstanceBillFetcher")
+	}
+}
+
+func TestFetchAccountSummary(t *testing.T) {
+	srv := newTestServer(t, func(q url.Values) interface{} {
+		if q.Get("Action") != "QueryAccountBill" || q.Get("BillingCycle") != "2025-01" {
+			t.Errorf("unexpected query: %v", q)
+		}
+		return bssPage(3, []map[string]interface{}{
+			{"ProductCode": "ecs", "PretaxAmount": 600.0, "Currency": "CNY"},
+			{"ProductCode": "oss", "PretaxAmount": 100.0, "Currency": "CNY"},
+			{"ProductCode": "slb", "PretaxAmount": 50.0, "Currency": "CNY"},
+		})
+	})
+	defer srv.Close()
+
+	f := NewFetcher(cloudbilling.CloudBillingConfig{Endpoint: srv.URL, AccessKeyID: "ak", AccessKeySecret: "sk"})
+	resp, err := f.FetchAccountSummary(context.Background(), cloudbilling.FetchAccountSummaryRequest{BillingCycle: "2025-01"})
+	if err != nil {
+		t.Fatalf("FetchAccountSummary failed: %v", err)
+	}
+	if resp.TotalAmount != 750 {
+		t.Errorf("TotalAmount want 750, got %v", resp.TotalAmount)
+	}
+	if resp.ByCategory["compute"] != 600 || resp.ByCategory["storage"] != 100 || resp.ByCategory["network"] != 50 {
+		t.Errorf("unexpected ByCategory: %v", resp.ByCategory)
+	}
+
+	summary, err := cloudbilling.ToBillAccountSummary("1234", resp)
+	if err != nil {
+		t.Fatalf("ToBillAccountSummary failed: %v", err)
+	}
+	if summary.PeriodType != "month" || summary.PeriodEnd.Day() != 31 {
+		t.Errorf("unexpected period: %s %v", summary.PeriodType, summary.PeriodEnd)
+	}
+}
+
+func TestFetchInstanceBillsPaginates(t *testing.T) {
+	srv := newTestServer(t, func(q url.Values) interface{} {
+		page, _ := strconv.Atoi(q.Get("PageNum"))
+		return bssPage(2, []map[string]interface{}{
+			{"InstanceID": "i-" + strconv.Itoa(page), "ProductCode": "ecs", "PretaxAmount": 744.0, "Currency": "CNY"},
+		})
+	})
+	defer srv.Close()
+
+	f := NewFetcher(cloudbilling.CloudBillingConfig{Endpoint: srv.URL, AccessKeyID: "ak", AccessKeySecret: "sk"})
+	bills, err := f.FetchInstanceBills(context.Background(), cloudbilling.FetchInstanceBillsRequest{BillingCycle: "2025-01"})
+	if err != nil {
+		t.Fatalf("FetchInstanceBills failed: %v", err)
+	}
+	if len(bills) != 2 {
+		t.Fatalf("expected 2 bills across pages, got %d", len(bills))
+	}
+}
+
+func TestFetcherReportsAPIError(t *testing.T) {
+	srv := newTestServer(t, func(q url.Values) interface{} {
+		return map[string]interface{}{"Code": "InvalidAccessKeyId.NotFound", "Message": "bad key", "Success": false}
+	})
+	defer srv.Close()
+
+	f := NewFetcher(cloudbilling.CloudBillingConfig{Endpoint: srv.URL, AccessKeyID: "ak", AccessKeySecret: "sk"})
+	if _, err := f.FetchInstanceBills(context.Background(), cloudbilling.FetchInstanceBillsRequest{BillingCycle: "2025-01"}); err == nil {
+		t.Fatal("expected error for failed BSS response")
+	}
+}
+
+func TestBuildNodePricingOverrides(t *testing.T) {
+	bills := []cloudbilling.InstanceBill{
+		{InstanceID: "i-bp1", BillingCycle: "2025-01", Amount: 700, Currency: "CNY", Category: "compute"},
+		{InstanceID: "i-bp1", BillingCycle: "2025-01", Amount: 44, Currency: "CNY", Category: "compute"},
+		{InstanceID: "i-orphan", BillingCycle: "2025-01", Amount: 100, Category: "compute"},
+	}
+	nodes := []k8s.Node{
+		{Name: "cn-hangzhou.10.0.0.1", ProviderID: "cn-hangzhou.i-bp1", Capacity: map[string]string{"cpu": "8", "memory": "32Gi"}},
+		{Name: "cn-hangzhou.10.0.0.2", ProviderID: "cn-hangzhou.i-bp2", Capacity: map[string]string{"cpu": "8", "memory": "32Gi"}},
+	}
+
+	overrides, err := BuildNodePricingOverrides(bills, nodes, 0.025, 0.01)
+	if err != nil {
+		t.Fatalf("BuildNodePricingOverrides failed: %v", err)
+	}
+	if len(overrides) != 1 {
+		t.Fatalf("expected 1 override, got %d", len(overrides))
+	}
+	o := overrides[0]
+	// 744 CNY over 31 days = 1 CNY/hour
+	if math.Abs(o.HourlyCost-1.0) > 1e-9 {
+		t.Errorf("HourlyCost want 1.0, got %v", o.HourlyCost)
+	}
+	modeled := 8*o.CPUPricePerCoreHour + 32*o.MemPricePerGBHour
+	if math.Abs(modeled-o.HourlyCost) > 1e-9 {
+		t.Errorf("override prices should reproduce hourly cost, got %v", modeled)
+	}
+}
+
+func TestInstanceIDFromProviderID(t *testing.T) {
+	cases := map[string]string{
+		"cn-hangzhou.i-bp1abc":           "i-bp1abc",
+		"alicloud://cn-beijing.i-2ze9xx": "i-2ze9xx",
+		"aws:///us-east-1a/i-0abc":       "",
+		"":                               "",
+	}
+	for in, want := range cases {
+		if got := InstanceIDFromProviderID(in); got != want {
+			t.Errorf("InstanceIDFromProviderID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package aliyun 实现阿里云 BSS OpenAPI（费用中心，版本 2017-12-14）的账单拉取。
+// 通过 cloudbilling.RegisterProvider 注册为 "aliyun"；AKSK 仅来自环境变量/Secret。
+package aliyun
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/cloudbilling"
+)
+
+const (
+	// DefaultEndpoint BSS OpenAPI 默认接入点（中国站）。
+	DefaultEndpoint = "business.aliyuncs.com"
+
+	apiVersion = "2017-12-14"
+	// pageSize QueryInstanceBill 单页上限为 300。
+	pageSize = 300
+)
+
+func init() {
+	cloudbilling.RegisterProvider("aliyun", func(cfg cloudbilling.CloudBillingConfig) cloudbilling.CloudBillingFetcher {
+		return NewFetcher(cfg)
+	})
+}
+
+// Fetcher 阿里云 BSS 账单拉取实现，同时实现 CloudBillingFetcher 与 InstanceBillFetcher。
+type Fetcher struct {
+	cfg        cloudbilling.CloudBillingConfig
+	baseURL    string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewFetcher 创建阿里云账单拉取器。Endpoint 为空时使用 DefaultEndpoint，未带 scheme 时默认 https。
+func NewFetcher(cfg cloudbilling.CloudBillingConfig) *Fetcher {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "https://" + endpoint
+	}
+	return &Fetcher{
+		cfg:        cfg,
+		baseURL:    strings.TrimRight(endpoint, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// billItem QueryAccountBill / QueryInstanceBill 返回的单条明细（仅取用到的字段）。
+type billItem struct {
+	InstanceID   string  `json:"InstanceID"`
+	ProductCode  string  `json:"ProductCode"`
+	Region       string  `json:"Region"`
+	BillingDate  string  `json:"BillingDate"`
+	PretaxAmount float64 `json:"PretaxAmount"`
+	Currency     string  `json:"Currency"`
+}
+
+// bssResponse BSS OpenAPI 通用响应包。
+type bssResponse struct {
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	RequestID string `json:"RequestId"`
+	Success   bool   `json:"Success"`
+	Data      struct {
+		BillingCycle string `json:"BillingCycle"`
+		AccountID    string `json:"AccountID"`
+		TotalCount   int    `json:"TotalCount"`
+		PageNum      int    `json:"PageNum"`
+		PageSize     int    `json:"PageSize"`
+		Items        struct {
+			Item []billItem `json:"Item"`
+		} `json:"Items"`
+	} `json:"Data"`
+}
+
+// FetchAccountSummary 调用 QueryAccountBill（按产品汇总）并按 compute/storage/network/other 归类。
+// 日账期（"2025-01-01"）使用 Granularity=DAILY + BillingDate。
+func (f *Fetcher) FetchAccountSummary(ctx context.Context, req cloudbilling.FetchAccountSummaryRequest) (*cloudbilling.FetchAccountSummaryResponse, error) {
+	params, err := cycleParams(req.BillingCycle)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("IsGroupByProduct", "true")
+
+	items, err := f.queryAll(ctx, "QueryAccountBill", params)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(req.CategoryFilter))
+	for _, c := range req.CategoryFilter {
+		allowed[c] = true
+	}
+
+	resp := &cloudbilling.FetchAccountSummaryResponse{
+		BillingCycle: req.BillingCycle,
+		Currency:     "CNY",
+		ByCategory:   make(map[string]float64),
+	}
+	for _, it := range items {
+		category := CategoryForProduct(it.ProductCode)
+		if len(allowed) > 0 && !allowed[category] {
+			continue
+		}
+		if it.Currency != "" {
+			resp.Currency = it.Currency
+		}
+		resp.TotalAmount += it.PretaxAmount
+		resp.ByCategory[category] += it.PretaxAmount
+		resp.Items = append(resp.Items, cloudbilling.BillItem{
+			ProductCode: it.ProductCode,
+			Amount:      it.PretaxAmount,
+			Category:    category,
+		})
+	}
+	return resp, nil
+}
+
+// FetchInstanceBills 调用 QueryInstanceBill 分页拉取实例级账单。
+func (f *Fetcher) FetchInstanceBills(ctx context.Context, req cloudbilling.FetchInstanceBillsRequest) ([]cloudbilling.InstanceBill, error) {
+	params, err := cycleParams(req.BillingCycle)
+	if err != nil {
+		return nil, err
+	}
+	if req.ProductCode != "" {
+		params.Set("ProductCode", req.ProductCode)
+	}
+
+	items, err := f.queryAll(ctx, "QueryInstanceBill", params)
+	if err != nil {
+		return nil, err
+	}
+
+	bills := make([]cloudbilling.InstanceBill, 0, len(items))
+	for _, it := range items {
+		if it.InstanceID == "" {
+			continue
+		}
+		bills = append(bills, cloudbilling.InstanceBill{
+			InstanceID:   it.InstanceID,
+			ProductCode:  it.ProductCode,
+			Region:       it.Region,
+			BillingCycle: req.BillingCycle,
+			Amount:       it.PretaxAmount,
+			Currency:     it.Currency,
+			Category:     CategoryForProduct(it.ProductCode),
+		})
+	}
+	return bills, nil
+}
+
+// cycleParams 将 Lighthouse 账期转换为 BSS 的 BillingCycle(/BillingDate) 参数。
+func cycleParams(cycle string) (url.Values, error) {
+	_, _, periodType, err := cloudbilling.ParseBillingCycle(cycle)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	if periodType == "day" {
+		params.Set("BillingCycle", cycle[:7])
+		params.Set("BillingDate", cycle)
+		params.Set("Granularity", "DAILY")
+	} else {
+		params.Set("BillingCycle", cycle)
+	}
+	return params, nil
+}
+
+// queryAll 按 PageNum 翻页直到取完 TotalCount 条记录。
+func (f *Fetcher) queryAll(ctx context.Context, action string, params url.Values) ([]billItem, error) {
+	var all []billItem
+	for page := 1; ; page++ {
+		p := url.Values{}
+		for k, v := range params {
+			p[k] = v
+		}
+		p.Set("PageNum", strconv.Itoa(page))
+		p.Set("PageSize", strconv.Itoa(pageSize))
+
+		resp, err := f.call(ctx, action, p)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Data.Items.Item...)
+		if len(resp.Data.Items.Item) == 0 || len(all) >= resp.Data.TotalCount {
+			return all, nil
+		}
+	}
+}
+
+// call 发送签名后的 RPC 请求并解析响应。
+func (f *Fetcher) call(ctx context.Context, action string, params url.Values) (*bssResponse, error) {
+	if f.cfg.AccessKeyID == "" || f.cfg.AccessKeySecret == "" {
+		return nil, fmt.Errorf("aliyun bss: access key not configured (CLOUD_BILL_AK/CLOUD_BILL_SK)")
+	}
+
+	params.Set("Action", action)
+	params.Set("Format", "JSON")
+	params.Set("Version", apiVersion)
+	params.Set("AccessKeyId", f.cfg.AccessKeyID)
+	params.Set("SignatureMethod", "HMAC-SHA1")
+	params.Set("SignatureVersion", "1.0")
+	params.Set("SignatureNonce", newNonce())
+	params.Set("Timestamp", f.now().UTC().Format("2006-01-02T15:04:05Z"))
+	params.Set("Signature", sign(http.MethodGet, params, f.cfg.AccessKeySecret))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, f.baseURL+"/?"+canonicalQuery(params), nil)
+	if err != nil {
+		return nil, fmt.Errorf("aliyun bss %s: build request: %w", action, err)
+	}
+	httpResp, err := f.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("aliyun bss %s: %w", action, err)
+	}
+	defer httpResp.Body.Close()
+
+	var out bssResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("aliyun bss %s: decode response (status %d): %w", action, httpResp.StatusCode, err)
+	}
+	if httpResp.StatusCode != http.StatusOK || !out.Success {
+		return nil, fmt.Errorf("aliyun bss %s failed: code=%s message=%s request_id=%s", action, out.Code, out.Message, out.RequestID)
+	}
+	return &out, nil
+}
+
+// percentEncode 按阿里云 RPC 签名规范编码（RFC3986，空格为 %20，保留 ~）。
+func percentEncode(s string) string {
+	e := url.QueryEscape(s)
+	e = strings.ReplaceAll(e, "+", "%20")
+	e = strings.ReplaceAll(e, "*", "%2A")
+	e = strings.ReplaceAll(e, "%7E", "~")
+	return e
+}
+
+// canonicalQuery 按参数名排序并编码，用于签名与请求 URL。
+func canonicalQuery(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, percentEncode(k)+"="+percentEncode(params.Get(k)))
+	}
+	return strings.Join(parts, "&")
+}
+
+// sign 计算 RPC 签名（SignatureVersion 1.0，HMAC-SHA1）；Signature 参数本身不参与签名。
+func sign(method string, params url.Values, secret string) string {
+	p := url.Values{}
+	for k, v := range params {
+		if k != "Signature" {
+			p[k] = v
+		}
+	}
+	stringToSign := method + "&" + percentEncode("/") + "&" + percentEncode(canonicalQuery(p))
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	return hex.EncodeToString(b)
+}
+
+// CategoryForProduct 将 BSS ProductCode 归类为 compute/storage/network/other。
+func CategoryForProduct(productCode string) string {
+	switch strings.ToLower(productCode) {
+	case "ecs", "eci", "ack", "cs", "ecsgpu":
+		return "compute"
+	case "yundisk", "disk", "ebs", "nas", "oss", "cpfs":
+		return "storage"
+	case "slb", "alb", "nlb", "eip", "cbwp", "nat", "vpc", "cdn":
+		return "network"
+	default:
+		return "other"
+	}
+}
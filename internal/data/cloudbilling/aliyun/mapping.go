@@ -0,0 +1,100 @@
+// Package aliyun mapping.go: ECS 实例账单 → K8s 节点单价覆盖。
+package aliyun
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/cloudbilling"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+)
+
+// InstanceIDFromProviderID 从 ACK 节点的 spec.providerID 中解析 ECS 实例 ID。
+// 支持 "cn-hangzhou.i-bp1xxx" 与 "alicloud://cn-hangzhou.i-bp1xxx" 两种格式。
+func InstanceIDFromProviderID(providerID string) string {
+	id := providerID
+	if i := strings.Index(id, "://"); i >= 0 {
+		id = id[i+3:]
+	}
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		id = id[i+1:]
+	}
+	if !strings.HasPrefix(id, "i-") {
+		return ""
+	}
+	return id
+}
+
+// BuildNodePricingOverrides 按实例 ID 将 ECS 账单映射到节点，并折算出节点级单价。
+// 同一实例的多条账单金额累加；无 providerID 时退化为节点名与实例 ID 直接匹配。
+// CPU/内存单价按全局单价比例缩放，使得节点 capacity 的建模小时成本等于账单小时成本。
+func BuildNodePricingOverrides(bills []cloudbilling.InstanceBill, nodes []k8s.Node, cpuPricePerCoreHour, memPricePerGBHour float64) ([]cloudbilling.NodePricingOverride, error) {
+	if cpuPricePerCoreHour <= 0 || memPricePerGBHour <= 0 {
+		return nil, fmt.Errorf("global CPU and memory prices must be positive")
+	}
+
+	type instanceTotal struct {
+		amount   float64
+		currency string
+		cycle    string
+	}
+	totals := make(map[string]*instanceTotal)
+	for _, b := range bills {
+		if b.Category != "compute" {
+			continue
+		}
+		t, ok := totals[b.InstanceID]
+		if !ok {
+			t = &instanceTotal{currency: b.Currency, cycle: b.BillingCycle}
+			totals[b.InstanceID] = t
+		}
+		t.amount += b.Amount
+	}
+
+	var overrides []cloudbilling.NodePricingOverride
+	for _, node := range nodes {
+		instanceID := InstanceIDFromProviderID(node.ProviderID)
+		if instanceID == "" {
+			instanceID = node.Name
+		}
+		t, ok := totals[instanceID]
+		if !ok {
+			continue
+		}
+
+		hours, err := cloudbilling.BillingCycleHours(t.cycle)
+		if err != nil {
+			return nil, err
+		}
+		cores, err := k8s.ParseCPUCores(node.Capacity["cpu"])
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", node.Name, err)
+		}
+		memGiB, err := k8s.ParseMemoryGiB(node.Capacity["memory"])
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", node.Name, err)
+		}
+		modeled := cores*cpuPricePerCoreHour + memGiB*memPricePerGBHour
+		if modeled <= 0 {
+			continue
+		}
+
+		hourly := t.amount / hours
+		factor := hourly / modeled
+		overrides = append(overrides, cloudbilling.NodePricingOverride{
+			NodeName:            node.Name,
+			InstanceID:          instanceID,
+			BillingCycle:        t.cycle,
+			HourlyCost:          hourly,
+			CPUPricePerCoreHour: cpuPricePerCoreHour * factor,
+			MemPricePerGBHour:   memPricePerGBHour * factor,
+			Currency:            t.currency,
+		})
+	}
+
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].NodeName < overrides[j].NodeName
+	})
+	return overrides, nil
+}
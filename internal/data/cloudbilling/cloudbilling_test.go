@@ -15,9 +15,9 @@ func TestCloudBillingFetcherInterface(t *testing.T) {
 	}
 	cfg.Provider = "aliyun"
 	f = NewFetcher(cfg)
-	// Phase3 占位返回 nil
+	// 未 import aliyun 子包时 Provider 未注册，返回 nil
 	if f != nil {
-		t.Fatal("Phase3 placeholder: expected nil for aliyun")
+		t.Fatal("expected nil for unregistered provider aliyun")
 	}
 }
 
@@ -2,22 +2,43 @@
 // AKSK 仅从环境变量（如 CLOUD_BILL_AK、CLOUD_BILL_SK）或 Secret 注入，不在配置明文。
 package cloudbilling
 
+import "sync"
+
 // CloudBillingConfig 云账单配置（占位）。Provider 决定工厂返回的实现。
 // AccessKeyID / AccessKeySecret 仅由环境变量或 Secret 填充，不落配置文件。
 type CloudBillingConfig struct {
-	Provider   string `json:"provider"`   // "aliyun" | "aws" | "tencent" | ""
-	Endpoint   string `json:"endpoint"`   // 可选
+	Provider   string `json:"provider"` // "aliyun" | "aws" | "tencent" | ""
+	Endpoint   string `json:"endpoint"` // 可选
 	PeriodType string `json:"period_type"`
+
+	AccessKeyID     string `json:"-"` // CLOUD_BILL_AK
+	AccessKeySecret string `json:"-"` // CLOUD_BILL_SK
 }
 
-// NewFetcher 根据配置返回 CloudBillingFetcher 实现。Phase3 占位：无 Provider 或未实现时返回 nil。
-// 调用方需判断 nil，Phase4 接入 aliyun/ 等实现。
+// FetcherConstructor 由各 Provider 子包注册的构造函数。
+type FetcherConstructor func(cfg CloudBillingConfig) CloudBillingFetcher
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]FetcherConstructor)
+)
+
+// RegisterProvider 注册 Provider 实现，由子包（如 cloudbilling/aliyun）在 init 中调用。
+// 子包依赖本包的接口与类型，因此工厂不能直接 import 子包，改由注册表解耦。
+func RegisterProvider(name string, ctor FetcherConstructor) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = ctor
+}
+
+// NewFetcher 根据配置返回 CloudBillingFetcher 实现。无 Provider 或 Provider 未注册时返回 nil。
+// 调用方需判断 nil；使用 aliyun 时需 import _ ".../cloudbilling/aliyun" 完成注册。
 func NewFetcher(cfg CloudBillingConfig) CloudBillingFetcher {
-	switch cfg.Provider {
-	case "aliyun", "aws", "tencent":
-		// Phase4: return aliyun.NewFetcher(cfg) 等
-		return nil
-	default:
+	providersMu.RLock()
+	ctor, ok := providers[cfg.Provider]
+	providersMu.RUnlock()
+	if !ok {
 		return nil
 	}
+	return ctor(cfg)
 }
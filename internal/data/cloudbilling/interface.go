@@ -37,3 +37,40 @@ type BillItem struct {
 type CloudBillingFetcher interface {
 	FetchAccountSummary(ctx context.Context, req FetchAccountSummaryRequest) (*FetchAccountSummaryResponse, error)
 }
+
+// FetchInstanceBillsRequest 拉取实例级账单的请求。
+// ProductCode: 可选，如 "ecs"；为空时返回全部产品的实例账单
+type FetchInstanceBillsRequest struct {
+	BillingCycle string `json:"billing_cycle"`
+	ProductCode  string `json:"product_code,omitempty"`
+}
+
+// InstanceBill 实例级账单（如单台 ECS 在账期内的应付金额）。
+type InstanceBill struct {
+	InstanceID   string  `json:"instance_id"`
+	ProductCode  string  `json:"product_code"`
+	Region       string  `json:"region"`
+	BillingCycle string  `json:"billing_cycle"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+	Category     string  `json:"category"` // compute/storage/network/other
+}
+
+// InstanceBillFetcher 实例级账单拉取接口。支持实例账单的 Provider（如 aliyun）额外实现此接口，
+// 调用方通过类型断言判断：if f, ok := fetcher.(InstanceBillFetcher); ok { ... }
+type InstanceBillFetcher interface {
+	FetchInstanceBills(ctx context.Context, req FetchInstanceBillsRequest) ([]InstanceBill, error)
+}
+
+// NodePricingOverride 由实例账单反推的节点单价，覆盖 Business.CostCalculation 中的全局单价。
+// HourlyCost 为账期内实例金额折算到每小时；CPU/内存单价按全局单价比例拆分，保证
+// cores*CPUPricePerCoreHour + memGiB*MemPricePerGBHour == HourlyCost。
+type NodePricingOverride struct {
+	NodeName            string  `json:"node_name"`
+	InstanceID          string  `json:"instance_id"`
+	BillingCycle        string  `json:"billing_cycle"`
+	HourlyCost          float64 `json:"hourly_cost"`
+	CPUPricePerCoreHour float64 `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64 `json:"mem_price_per_gb_hour"`
+	Currency            string  `json:"currency"`
+}
@@ -0,0 +1,55 @@
+// Package cloudbilling 账单汇总转换：FetchAccountSummaryResponse → postgres.BillAccountSummary。
+package cloudbilling
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// ParseBillingCycle 解析账期，返回 [start, end) 区间与 period_type。
+// 支持 "2025-01"（月账期）与 "2025-01-01"（日账期）。
+func ParseBillingCycle(cycle string) (start, end time.Time, periodType string, err error) {
+	if t, e := time.Parse("2006-01-02", cycle); e == nil {
+		return t, t.AddDate(0, 0, 1), "day", nil
+	}
+	if t, e := time.Parse("2006-01", cycle); e == nil {
+		return t, t.AddDate(0, 1, 0), "month", nil
+	}
+	return time.Time{}, time.Time{}, "", fmt.Errorf("invalid billing cycle: %s", cycle)
+}
+
+// BillingCycleHours 返回账期包含的小时数，用于将账期金额折算为小时成本。
+func BillingCycleHours(cycle string) (float64, error) {
+	start, end, _, err := ParseBillingCycle(cycle)
+	if err != nil {
+		return 0, err
+	}
+	return end.Sub(start).Hours(), nil
+}
+
+// ToBillAccountSummary 将云厂商账单汇总转换为 cost_bill_account_summary 行。
+// PeriodEnd 为账期最后一天（与表中 DATE 语义一致）。
+func ToBillAccountSummary(accountID string, resp *FetchAccountSummaryResponse) (postgres.BillAccountSummary, error) {
+	if resp == nil {
+		return postgres.BillAccountSummary{}, fmt.Errorf("nil account summary response")
+	}
+	start, end, periodType, err := ParseBillingCycle(resp.BillingCycle)
+	if err != nil {
+		return postgres.BillAccountSummary{}, err
+	}
+	byCategory := make(map[string]float64, len(resp.ByCategory))
+	for k, v := range resp.ByCategory {
+		byCategory[k] = v
+	}
+	return postgres.BillAccountSummary{
+		AccountID:   accountID,
+		PeriodType:  periodType,
+		PeriodStart: start,
+		PeriodEnd:   end.AddDate(0, 0, -1),
+		TotalAmount: resp.TotalAmount,
+		Currency:    resp.Currency,
+		ByCategory:  byCategory,
+	}, nil
+}
@@ -0,0 +1,86 @@
+// Package dataerr is the error taxonomy shared by repositories, data clients and services.
+// Errors are classified by wrapping one of four sentinels, so callers use errors.Is whatever
+// backend produced them, and the HTTP server maps them to status codes in one place (HTTPStatus).
+package dataerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound: the requested entity does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict: the request conflicts with the current state (e.g. failed integrity check).
+	ErrConflict = errors.New("conflict")
+	// ErrUnavailable: a transient backend failure; retrying later may succeed.
+	ErrUnavailable = errors.New("unavailable")
+	// ErrValidation: the input is invalid; retrying the same request will fail again.
+	ErrValidation = errors.New("validation failed")
+)
+
+// Error is a leaf error of one kind. Error() is the message alone, so adopting the taxonomy does
+// not change existing error texts; errors.Is(err, kind) matches through Unwrap.
+type Error struct {
+	kind error
+	msg  string
+}
+
+func (e *Error) Error() string { return e.msg }
+
+// Unwrap returns the kind sentinel.
+func (e *Error) Unwrap() error { return e.kind }
+
+func newError(kind error, format string, args ...interface{}) error {
+	return &Error{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
+
+// NotFound returns an ErrNotFound error with the formatted message.
+func NotFound(format string, args ...interface{}) error {
+	return newError(ErrNotFound, format, args...)
+}
+
+// Conflict returns an ErrConflict error with the formatted message.
+func Conflict(format string, args ...interface{}) error {
+	return newError(ErrConflict, format, args...)
+}
+
+// Unavailable returns an ErrUnavailable error with the formatted message.
+func Unavailable(format string, args ...interface{}) error {
+	return newError(ErrUnavailable, format, args...)
+}
+
+// Validation returns an ErrValidation error with the formatted message.
+func Validation(format string, args ...interface{}) error {
+	return newError(ErrValidation, format, args...)
+}
+
+// Kind returns the sentinel err is classified as, or nil for unclassified errors.
+func Kind(err error) error {
+	for _, kind := range []error{ErrNotFound, ErrConflict, ErrValidation, ErrUnavailable} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// HTTPStatus maps err to the response status code and error code of the API.
+// Unclassified errors are 500 with no code.
+func HTTPStatus(err error) (status int, code string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, "NOT_FOUND"
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest, "VALIDATION_FAILED"
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict, "CONFLICT"
+	case errors.Is(err, ErrUnavailable):
+		return http.StatusServiceUnavailable, "UNAVAILABLE"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "TIMEOUT"
+	}
+	return http.StatusInternalServerError, ""
+}
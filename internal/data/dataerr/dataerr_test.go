@@ -0,0 +1,46 @@
+package dataerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestKinds(t *testing.T) {
+	err := NotFound("cost snapshot not found: %s", "s-1")
+	if err.Error() != "cost snapshot not found: s-1" {
+		t.Errorf("message = %q, want the formatted message alone", err)
+	}
+	wrapped := fmt.Errorf("verify: %w", err)
+	if !errors.Is(wrapped, ErrNotFound) || errors.Is(wrapped, ErrUnavailable) {
+		t.Errorf("wrapped NotFound should match ErrNotFound only")
+	}
+	if Kind(wrapped) != ErrNotFound || Kind(errors.New("plain")) != nil {
+		t.Errorf("Kind = %v / %v", Kind(wrapped), Kind(errors.New("plain")))
+	}
+	sentinel := Unavailable("circuit breaker is open")
+	if !errors.Is(fmt.Errorf("query: %w", sentinel), sentinel) {
+		t.Error("package sentinels built with the constructors must match by identity")
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{NotFound("x"), http.StatusNotFound, "NOT_FOUND"},
+		{fmt.Errorf("set price: %w", Validation("bad")), http.StatusBadRequest, "VALIDATION_FAILED"},
+		{Conflict("x"), http.StatusConflict, "CONFLICT"},
+		{Unavailable("x"), http.StatusServiceUnavailable, "UNAVAILABLE"},
+		{fmt.Errorf("list: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "TIMEOUT"},
+		{errors.New("boom"), http.StatusInternalServerError, ""},
+	} {
+		if status, code := HTTPStatus(tc.err); status != tc.status || code != tc.code {
+			t.Errorf("HTTPStatus(%v) = %d %q, want %d %q", tc.err, status, code, tc.status, tc.code)
+		}
+	}
+}
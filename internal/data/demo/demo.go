@@ -0,0 +1,484 @@
+// Package demo builds the scripted dataset of demo mode (lighthouse-server --demo): six weeks of
+// history across the repository and the Prometheus / K8s mocks that tell one coherent story:
+//
+//   - ml-serving launches four weeks ago and triples in size;
+//   - legacy-batch runs idle (zombie) workloads until it is cleaned up two weeks ago;
+//   - app-prod/order-service is right-sized ten days ago, which shows up as ROI savings;
+//   - app-prod/payment-service has an SLO incident three days ago, minutes after an image update,
+//     so the timeline holds its evidence chain.
+//
+// The dataset is derived from the workloads below without randomness, so every view (costs, grades,
+// ROI, timeline, SLO) agrees with the others.
+package demo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Days is the length of the history.
+const Days = 42
+
+// HourlyRetention is how long the history is kept at hourly resolution; older hours are rolled up
+// into daily workload stats when the repository supports it.
+const HourlyRetention = 7 * 24 * time.Hour
+
+// Namespaces of the story.
+const (
+	NamespaceDefault     = "default"
+	NamespaceMonitoring  = "monitoring"
+	NamespaceAppProd     = "app-prod"
+	NamespaceMLServing   = "ml-serving"
+	NamespaceLegacyBatch = "legacy-batch"
+)
+
+const (
+	gib    = 1 << 30
+	author = "lighthouse-demo"
+)
+
+// Story anchors the narrative at Now; all times are UTC.
+type Story struct {
+	Now         time.Time // last full hour of the history
+	Start       time.Time // first hour of the history, Days before Now
+	LaunchAt    time.Time // ml-serving is created
+	CleanupAt   time.Time // legacy-batch is deleted
+	RightsizeAt time.Time // order-service requests are cut
+	IncidentAt  time.Time // payment-service starts violating its SLO
+	RecoveredAt time.Time // payment-service recovers
+
+	CPUPricePerCoreHour float64
+	MemPricePerGBHour   float64
+}
+
+// NewStory anchors the story at now with the given unit prices; unset prices default to those of
+// config.example.yaml (0.025 per core hour, 0.01 per GB hour).
+func NewStory(now time.Time, cpuPricePerCoreHour, memPricePerGBHour float64) Story {
+	if cpuPricePerCoreHour <= 0 {
+		cpuPricePerCoreHour = 0.025
+	}
+	if memPricePerGBHour <= 0 {
+		memPricePerGBHour = 0.01
+	}
+	now = now.UTC().Truncate(time.Hour)
+	today := now.Truncate(24 * time.Hour)
+	incident := today.AddDate(0, 0, -3).Add(14 * time.Hour)
+	return Story{
+		Now:                 now,
+		Start:               now.AddDate(0, 0, -Days),
+		LaunchAt:            today.AddDate(0, 0, -28).Add(9 * time.Hour),
+		CleanupAt:           today.AddDate(0, 0, -14).Add(10 * time.Hour),
+		RightsizeAt:         today.AddDate(0, 0, -10).Add(11 * time.Hour),
+		IncidentAt:          incident,
+		RecoveredAt:         incident.Add(45 * time.Minute),
+		CPUPricePerCoreHour: cpuPricePerCoreHour,
+		MemPricePerGBHour:   memPricePerGBHour,
+	}
+}
+
+// workload is one workload of the story: requests and P95 usage in cores and GiB.
+type workload struct {
+	namespace, name, kind string
+	cpu, cpuUsed          float64
+	mem, memUsed          float64
+}
+
+var workloads = []workload{
+	{NamespaceDefault, "api-gateway", "Deployment", 2, 1.1, 4, 2.6},
+	{NamespaceMonitoring, "prometheus", "StatefulSet", 2, 1.2, 16, 11},
+	{NamespaceMonitoring, "grafana", "Deployment", 0.5, 0.2, 1, 0.5},
+	{NamespaceAppProd, "order-service", "Deployment", 6, 1.2, 12, 4},
+	{NamespaceAppProd, "payment-service", "Deployment", 2, 1.3, 4, 2.8},
+	{NamespaceAppProd, "checkout-web", "Deployment", 1.5, 0.9, 3, 1.8},
+	{NamespaceMLServing, "recommender", "Deployment", 2, 1.4, 8, 5.5},
+	{NamespaceLegacyBatch, "report-generator", "Deployment", 4, 0.02, 8, 0.3},
+	{NamespaceLegacyBatch, "nightly-etl", "Deployment", 3, 0.03, 6, 0.2},
+}
+
+// active reports whether the workload runs at t.
+func (s Story) active(w workload, t time.Time) bool {
+	switch w.namespace {
+	case NamespaceMLServing:
+		return !t.Before(s.LaunchAt)
+	case NamespaceLegacyBatch:
+		return t.Before(s.CleanupAt)
+	}
+	return true
+}
+
+// usage returns the requests and P95 usage (cores, GiB) of w in the hour starting at t.
+func (s Story) usage(w workload, t time.Time) (cpu, cpuUsed, mem, memUsed float64) {
+	// daily traffic pattern peaking in the afternoon
+	load := 1 + 0.25*math.Sin(2*math.Pi*float64(t.Hour()-8)/24)
+	cpu, cpuUsed, mem, memUsed = w.cpu, w.cpuUsed*load, w.mem, w.memUsed
+	switch {
+	case w.namespace == NamespaceMLServing:
+		// three times the launch size by now
+		f := 1 + 2*t.Sub(s.LaunchAt).Hours()/s.Now.Sub(s.LaunchAt).Hours()
+		cpu, cpuUsed, mem, memUsed = cpu*f, cpuUsed*f, mem*f, memUsed*f
+	case w.name == "order-service" && !t.Before(s.RightsizeAt):
+		cpu, mem = w.cpuUsed*1.6, w.memUsed*1.3
+	}
+	return cpu, cpuUsed, mem, memUsed
+}
+
+// grade returns the efficiency grade of w at t as told by the story.
+func (s Story) grade(w workload, t time.Time) costmodel.EfficiencyGrade {
+	switch {
+	case w.namespace == NamespaceLegacyBatch:
+		return costmodel.GradeZombie
+	case w.name == "order-service" && t.Before(s.RightsizeAt):
+		return costmodel.GradeOverProvisioned
+	}
+	return costmodel.GradeHealthy
+}
+
+// stat returns the hourly stat of w for the hour starting at t.
+func (s Story) stat(w workload, t time.Time, node int) postgres.HourlyWorkloadStat {
+	cpu, cpuUsed, mem, memUsed := s.usage(w, t)
+	cpuBillable, cpuUsage := cpu*s.CPUPricePerCoreHour, cpuUsed*s.CPUPricePerCoreHour
+	memBillable, memUsage := mem*s.MemPricePerGBHour, memUsed*s.MemPricePerGBHour
+	return postgres.HourlyWorkloadStat{
+		Namespace:         w.namespace,
+		WorkloadName:      w.name,
+		WorkloadType:      w.kind,
+		NodeName:          fmt.Sprintf("node-%d", 1+node%4),
+		PodName:           w.name + "-0",
+		Timestamp:         t,
+		CPURequest:        cpu,
+		CPUUsageP95:       cpuUsed,
+		MemRequest:        int64(mem * gib),
+		MemUsageP95:       int64(memUsed * gib),
+		CPUBillableCost:   cpuBillable,
+		CPUUsageCost:      cpuUsage,
+		CPUWasteCost:      cpuBillable - cpuUsage,
+		MemBillableCost:   memBillable,
+		MemUsageCost:      memUsage,
+		MemWasteCost:      int64(math.Round(memBillable - memUsage)),
+		TotalBillableCost: cpuBillable + memBillable,
+		TotalUsageCost:    cpuUsage + memUsage,
+		TotalWasteCost:    cpuBillable + memBillable - cpuUsage - memUsage,
+	}
+}
+
+// Optional stores the story writes to when the repository provides them; *postgres.MockRepository
+// provides all of them.
+type (
+	hourlyDownsampler interface {
+		DownsampleHourlyWorkloadStats(ctx context.Context, before time.Time) (postgres.DownsampleResult, error)
+	}
+	lifecycleStore interface {
+		SaveNamespaceLifecycle(ctx context.Context, lifecycle postgres.NamespaceLifecycle) error
+	}
+	gradeEventStore interface {
+		SaveGradeChangeEvent(ctx context.Context, event postgres.GradeChangeEvent) error
+	}
+	recommendationStore interface {
+		SaveIssuedRecommendation(ctx context.Context, rec postgres.IssuedRecommendation) error
+	}
+	timelineStore interface {
+		SaveConfigChange(ctx context.Context, change postgres.ConfigChange) error
+		SaveSLOViolation(ctx context.Context, violation postgres.SLOViolation) error
+	}
+)
+
+// Seed writes the story into repo: hourly workload stats (rolled up into daily stats after
+// HourlyRetention), daily namespace costs, one cost snapshot per day and the ROI baseline, plus the
+// namespace lifecycles, grade changes, issued recommendations, config changes and SLO violations
+// when repo stores them. repo should be empty.
+func (s Story) Seed(ctx context.Context, repo postgres.Repository) error {
+	type dayKey struct {
+		namespace string
+		date      time.Time
+	}
+	days := make(map[dayKey]*postgres.DailyNamespaceCost)
+	// the baseline period is rolled up by the time anyone compares, so its utilization is pinned
+	baselineEnd := s.LaunchAt.Truncate(24 * time.Hour)
+	var cpuReq, cpuUse, memReq, memUse float64
+	for t := s.Start; t.Before(s.Now); t = t.Add(time.Hour) {
+		for i, w := range workloads {
+			if !s.active(w, t) {
+				continue
+			}
+			stat := s.stat(w, t, i)
+			if t.Before(baselineEnd) {
+				cpuReq, cpuUse = cpuReq+stat.CPURequest, cpuUse+stat.CPUUsageP95
+				memReq, memUse = memReq+float64(stat.MemRequest), memUse+float64(stat.MemUsageP95)
+			}
+			if err := repo.SaveHourlyWorkloadStat(ctx, stat); err != nil {
+				return fmt.Errorf("save hourly workload stat: %w", err)
+			}
+			key := dayKey{w.namespace, t.Truncate(24 * time.Hour)}
+			day, ok := days[key]
+			if !ok {
+				day = &postgres.DailyNamespaceCost{Namespace: w.namespace, Date: key.date, NodeCount: 4, CreatedAt: key.date}
+				days[key] = day
+			}
+			day.BillableCost += stat.TotalBillableCost
+			day.UsageCost += stat.TotalUsageCost
+			day.WasteCost += stat.TotalWasteCost
+			if t.Hour() == 0 || t.Equal(s.Start) || (t.Equal(s.LaunchAt) && w.namespace == NamespaceMLServing) {
+				day.WorkloadCount++
+				day.PodCount += 2
+			}
+		}
+	}
+	for _, day := range days {
+		if day.BillableCost > 0 {
+			day.EfficiencyScore = day.UsageCost / day.BillableCost
+		}
+		if err := repo.SaveDailyNamespaceCost(ctx, *day); err != nil {
+			return fmt.Errorf("save daily namespace cost: %w", err)
+		}
+	}
+	if d, ok := repo.(hourlyDownsampler); ok {
+		if _, err := d.DownsampleHourlyWorkloadStats(ctx, s.Now.Add(-HourlyRetention)); err != nil {
+			return fmt.Errorf("roll up hourly workload stats: %w", err)
+		}
+	}
+	if err := s.seedSnapshots(ctx, repo); err != nil {
+		return err
+	}
+	if err := repo.SaveROIBaseline(ctx, postgres.ROIBaseline{
+		ID:              "demo-before-cleanup",
+		Name:            "Before cleanup",
+		Description:     "The two weeks before ml-serving launched, with legacy-batch still running and order-service not yet right-sized",
+		BaselineType:    "historical",
+		TimePeriodStart: s.Start.Truncate(24 * time.Hour),
+		TimePeriodEnd:   baselineEnd,
+		Metrics: map[string]float64{
+			roi.MetricCPUUtilization:   math.Round(cpuUse/cpuReq*10000) / 100,
+			roi.MetricMemUtilization:   math.Round(memUse/memReq*10000) / 100,
+			roi.MetricNodeCount:        4,
+			roi.MetricZombieAssetCount: 2,
+		},
+		ReferenceData: map[string]interface{}{"source": author},
+		CreatedBy:     author,
+		CreatedAt:     s.LaunchAt,
+		UpdatedAt:     s.LaunchAt,
+	}); err != nil {
+		return fmt.Errorf("save ROI baseline: %w", err)
+	}
+	return s.seedEvents(ctx, repo)
+}
+
+// seedSnapshots writes one cost snapshot per day with the day's totals and grade counts.
+func (s Story) seedSnapshots(ctx context.Context, repo postgres.Repository) error {
+	for day := s.Start.Truncate(24 * time.Hour).Add(24 * time.Hour); !day.After(s.Now); day = day.Add(24 * time.Hour) {
+		snapshot := postgres.CostSnapshot{
+			ID:                fmt.Sprintf("demo-%s", day.Format("2006-01-02")),
+			CalculationID:     fmt.Sprintf("demo-calc-%s", day.Format("2006-01-02")),
+			Timestamp:         day,
+			TimeRangeStart:    day.Add(-24 * time.Hour),
+			TimeRangeEnd:      day,
+			AggregatedResults: map[costmodel.AggregationLevel][]costmodel.AggregationResult{},
+			Metadata:          map[string]interface{}{"generated_by": author},
+			CreatedAt:         day,
+		}
+		for i, w := range workloads {
+			t := day.Add(-time.Hour)
+			if !s.active(w, t) {
+				continue
+			}
+			for h := day.Add(-24 * time.Hour); h.Before(day); h = h.Add(time.Hour) {
+				if !s.active(w, h) {
+					continue
+				}
+				stat := s.stat(w, h, i)
+				snapshot.TotalBillableCost += stat.TotalBillableCost
+				snapshot.TotalUsageCost += stat.TotalUsageCost
+				snapshot.TotalWasteCost += stat.TotalWasteCost
+			}
+			switch s.grade(w, t) {
+			case costmodel.GradeZombie:
+				snapshot.ZombieCount++
+			case costmodel.GradeOverProvisioned:
+				snapshot.OverProvisionedCount++
+			default:
+				snapshot.HealthyCount++
+			}
+		}
+		if snapshot.TotalBillableCost > 0 {
+			snapshot.OverallEfficiencyScore = snapshot.TotalUsageCost / snapshot.TotalBillableCost
+		}
+		if err := repo.SaveCostSnapshot(ctx, snapshot); err != nil {
+			return fmt.Errorf("save cost snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// seedEvents writes the lifecycle, grade, recommendation and timeline records of the story.
+func (s Story) seedEvents(ctx context.Context, repo postgres.Repository) error {
+	if store, ok := repo.(lifecycleStore); ok {
+		for _, ns := range []string{NamespaceDefault, NamespaceMonitoring, NamespaceAppProd, NamespaceMLServing, NamespaceLegacyBatch} {
+			lifecycle := postgres.NamespaceLifecycle{Namespace: ns, FirstSeen: s.Start, LastSeen: s.Now}
+			switch ns {
+			case NamespaceMLServing:
+				lifecycle.FirstSeen = s.LaunchAt
+			case NamespaceLegacyBatch:
+				deleted := s.CleanupAt
+				lifecycle.LastSeen, lifecycle.DeletedAt = s.CleanupAt, &deleted
+			}
+			if err := store.SaveNamespaceLifecycle(ctx, lifecycle); err != nil {
+				return fmt.Errorf("save namespace lifecycle: %w", err)
+			}
+		}
+	}
+	if store, ok := repo.(gradeEventStore); ok {
+		for i, w := range workloads {
+			first := s.Start
+			if w.namespace == NamespaceMLServing {
+				first = s.LaunchAt
+			}
+			events := []postgres.GradeChangeEvent{{ToGrade: string(s.grade(w, first)), OccurredAt: first}}
+			if w.name == "order-service" {
+				events = append(events, postgres.GradeChangeEvent{FromGrade: string(costmodel.GradeOverProvisioned), ToGrade: string(costmodel.GradeHealthy), OccurredAt: s.RightsizeAt.Add(time.Hour)})
+			}
+			for j, e := range events {
+				e.ID = fmt.Sprintf("demo-grade-%d-%d", i, j)
+				e.Namespace, e.WorkloadName, e.WorkloadType = w.namespace, w.name, w.kind
+				if st := s.stat(w, e.OccurredAt, i); st.TotalBillableCost > 0 {
+					e.EfficiencyScore = st.TotalUsageCost / st.TotalBillableCost
+				}
+				if err := store.SaveGradeChangeEvent(ctx, e); err != nil {
+					return fmt.Errorf("save grade change event: %w", err)
+				}
+			}
+		}
+	}
+	if store, ok := repo.(recommendationStore); ok {
+		for _, rec := range s.recommendations() {
+			if err := store.SaveIssuedRecommendation(ctx, rec); err != nil {
+				return fmt.Errorf("save issued recommendation: %w", err)
+			}
+		}
+	}
+	if store, ok := repo.(timelineStore); ok {
+		for _, change := range s.configChanges() {
+			if err := store.SaveConfigChange(ctx, change); err != nil {
+				return fmt.Errorf("save config change: %w", err)
+			}
+		}
+		for _, violation := range s.sloViolations() {
+			if err := store.SaveSLOViolation(ctx, violation); err != nil {
+				return fmt.Errorf("save SLO violation: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// monthlyCost returns the monthly (30 day) billable cost of cores and GiB.
+func (s Story) monthlyCost(cores, gibs float64) float64 {
+	return (cores*s.CPUPricePerCoreHour + gibs*s.MemPricePerGBHour) * 24 * 30
+}
+
+// recommendations returns the recommendations issued along the story: the legacy-batch
+// decommissions and the order-service downsize were applied, the recommender one is still open.
+func (s Story) recommendations() []postgres.IssuedRecommendation {
+	var recs []postgres.IssuedRecommendation
+	for _, w := range workloads {
+		if w.namespace != NamespaceLegacyBatch {
+			continue
+		}
+		savings := s.monthlyCost(w.cpu, w.mem)
+		recs = append(recs, postgres.IssuedRecommendation{
+			ID: "demo-rec-" + w.name, Team: "data", Namespace: w.namespace, WorkloadName: w.name,
+			Action: "decommission", Resource: "workload", BaselineRequest: 1, RecommendedRequest: 0,
+			EstimatedMonthlySavings: savings, IssuedAt: s.Start.AddDate(0, 0, 7),
+			Status: string(costmodel.AdoptionApplied), AdoptedFraction: 1, RealizedMonthlySavings: savings, CheckedAt: s.CleanupAt.Add(24 * time.Hour),
+		})
+	}
+	order := workloads[3]
+	target := order.cpuUsed * 1.6
+	orderSavings := s.monthlyCost(order.cpu-target, 0)
+	recs = append(recs, postgres.IssuedRecommendation{
+		ID: "demo-rec-order-service-cpu", Team: "commerce", Namespace: order.namespace, WorkloadName: order.name,
+		Action: "downsize", Resource: "cpu", BaselineRequest: order.cpu, RecommendedRequest: target,
+		EstimatedMonthlySavings: orderSavings, IssuedAt: s.RightsizeAt.AddDate(0, 0, -5),
+		Status: string(costmodel.AdoptionApplied), CurrentRequest: target, AdoptedFraction: 1, RealizedMonthlySavings: orderSavings, CheckedAt: s.RightsizeAt.Add(24 * time.Hour),
+	})
+	rec := workloads[6]
+	cpu, cpuUsed, _, _ := s.usage(rec, s.Now.Add(-time.Hour))
+	recs = append(recs, postgres.IssuedRecommendation{
+		ID: "demo-rec-recommender-cpu", Team: "ml", Namespace: rec.namespace, WorkloadName: rec.name,
+		Action: "downsize", Resource: "cpu", BaselineRequest: cpu, RecommendedRequest: cpuUsed * 1.2,
+		EstimatedMonthlySavings: s.monthlyCost(cpu-cpuUsed*1.2, 0), IssuedAt: s.Now.AddDate(0, 0, -2),
+		Status: string(costmodel.AdoptionOpen),
+	})
+	return recs
+}
+
+// configChanges returns the changes reported by the deploy pipeline; the payment-service image
+// update 10 minutes before the incident is its likely cause.
+func (s Story) configChanges() []postgres.ConfigChange {
+	changes := []postgres.ConfigChange{
+		{ID: "demo-change-ml-serving", Namespace: NamespaceMLServing, Kind: "HelmRelease", Name: "recommender", ChangeType: "helm_upgrade",
+			NewValue: "recommender-1.0.0", OccurredAt: s.LaunchAt.Add(-10 * time.Minute)},
+		{ID: "demo-change-legacy-batch", Namespace: NamespaceLegacyBatch, Kind: "Namespace", Name: NamespaceLegacyBatch, ChangeType: "namespace_delete",
+			OldValue: "2 deployments", OccurredAt: s.CleanupAt.Add(-5 * time.Minute)},
+		{ID: "demo-change-order-service", Namespace: NamespaceAppProd, Kind: "Deployment", Name: "order-service", ChangeType: "resources_update",
+			OldValue: "cpu: 6, memory: 12Gi", NewValue: fmt.Sprintf("cpu: %.2f, memory: %.1fGi", workloads[3].cpuUsed*1.6, workloads[3].memUsed*1.3), OccurredAt: s.RightsizeAt.Add(-5 * time.Minute)},
+		{ID: "demo-change-payment-service", Namespace: NamespaceAppProd, Kind: "Deployment", Name: "payment-service", ChangeType: "image_update",
+			OldValue: "payment-service:2.3.1", NewValue: "payment-service:2.4.0", OccurredAt: s.IncidentAt.Add(-10 * time.Minute)},
+		{ID: "demo-change-payment-service-rollback", Namespace: NamespaceAppProd, Kind: "Deployment", Name: "payment-service", ChangeType: "image_update",
+			OldValue: "payment-service:2.4.0", NewValue: "payment-service:2.3.1", OccurredAt: s.RecoveredAt.Add(-5 * time.Minute)},
+	}
+	for i := range changes {
+		changes[i].Source, changes[i].Author, changes[i].CreatedAt = "argocd", author, changes[i].OccurredAt
+	}
+	return changes
+}
+
+// sloViolations returns the availability and latency violations of the payment-service incident.
+func (s Story) sloViolations() []postgres.SLOViolation {
+	recovered := s.RecoveredAt
+	violations := []postgres.SLOViolation{
+		{ID: "demo-slo-payment-availability", ViolationType: "availability", ActualValue: 96.5, ThresholdValue: 99.9, Severity: "critical"},
+		{ID: "demo-slo-payment-latency", ViolationType: "latency", ActualValue: 720, ThresholdValue: 300, Severity: "warning"},
+	}
+	for i := range violations {
+		v := &violations[i]
+		v.Namespace, v.Service = NamespaceAppProd, "payment-service"
+		v.ViolatedAt, v.RecoveredAt = s.IncidentAt.Add(5*time.Minute), &recovered
+		v.CreatedAt, v.UpdatedAt = v.ViolatedAt, recovered
+	}
+	return violations
+}
+
+// Namespaces returns the namespaces that exist at Now (legacy-batch is gone).
+func (s Story) Namespaces() []string {
+	return []string{NamespaceDefault, NamespaceMonitoring, NamespaceAppProd, NamespaceMLServing}
+}
+
+// PrometheusConfig returns the Prometheus mock configuration of the story: its namespaces and the
+// request series of the SLO services, with the payment-service incident as a violation window.
+func (s Story) PrometheusConfig() prometheus.MockConfig {
+	c := prometheus.DefaultMockConfig()
+	c.Namespaces = s.Namespaces()
+	c.SLOServices = prometheus.DefaultMockSLOServices()
+	for i := range c.SLOServices {
+		if c.SLOServices[i].Service == "payment-service" {
+			c.SLOServices[i].Violations = []prometheus.MockSLOViolation{{
+				Start: s.IncidentAt, Duration: s.RecoveredAt.Sub(s.IncidentAt), Availability: 96.5, LatencyFactor: 4,
+			}}
+		}
+	}
+	return c
+}
+
+// K8sConfig returns the K8s mock configuration of the story: the namespaces that exist at Now.
+func (s Story) K8sConfig() k8s.MockConfig {
+	c := k8s.DefaultMockConfig()
+	c.Namespaces = s.Namespaces()
+	return c
+}
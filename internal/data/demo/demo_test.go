@@ -0,0 +1,105 @@
+package demo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+)
+
+func TestStorySeed(t *testing.T) {
+	ctx := context.Background()
+	s := NewStory(time.Date(2020, 3, 1, 10, 30, 0, 0, time.UTC), 0.05, 0.01)
+	cfg := postgres.DefaultMockConfig()
+	cfg.Scenario, cfg.LatencyMs, cfg.ErrorRate = "empty", 0, 0
+	repo := postgres.NewMockRepository(cfg)
+	if err := s.Seed(ctx, repo); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+
+	daily := func(ns string) []postgres.DailyNamespaceCost {
+		costs, err := repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{Namespace: ns})
+		if err != nil {
+			t.Fatalf("ListDailyNamespaceCosts(%s): %v", ns, err)
+		}
+		return costs
+	}
+	for _, c := range daily(NamespaceLegacyBatch) {
+		if c.Date.After(s.CleanupAt) {
+			t.Errorf("legacy-batch has costs on %v, after the cleanup", c.Date)
+		}
+	}
+	// first and last full days of ml-serving
+	firstDay, lastDay := s.LaunchAt.Truncate(24*time.Hour).AddDate(0, 0, 1), s.Now.Truncate(24*time.Hour).AddDate(0, 0, -1)
+	var first, last float64
+	for _, c := range daily(NamespaceMLServing) {
+		if c.Date.Before(s.LaunchAt.Truncate(24 * time.Hour)) {
+			t.Errorf("ml-serving has costs on %v, before the launch", c.Date)
+		}
+		switch {
+		case c.Date.Equal(firstDay):
+			first = c.BillableCost
+		case c.Date.Equal(lastDay):
+			last = c.BillableCost
+		}
+	}
+	if first == 0 || last < 2*first {
+		t.Errorf("ml-serving billable cost %.2f on %v, %.2f on %v; want growth", first, firstDay, last, lastDay)
+	}
+
+	waste := func(at time.Time) float64 {
+		stat, err := repo.GetHourlyWorkloadStat(ctx, NamespaceAppProd, "order-service", at)
+		if err != nil {
+			t.Fatalf("GetHourlyWorkloadStat(%v): %v", at, err)
+		}
+		return stat.CPUWasteCost
+	}
+	// same hour of day before and after rightsizing; the hours before are rolled up by now
+	before, after := s.stat(workloads[3], s.RightsizeAt.Add(-time.Hour), 3), waste(s.Now.Add(-24*time.Hour))
+	if after >= before.CPUWasteCost/2 {
+		t.Errorf("order-service CPU waste %.3f after rightsizing, %.3f before", after, before.CPUWasteCost)
+	}
+
+	changes, err := repo.ListConfigChanges(ctx, postgres.ConfigChangeFilter{Namespace: NamespaceAppProd, StartTime: s.IncidentAt.Add(-time.Hour), EndTime: s.IncidentAt})
+	if err != nil || len(changes) != 1 || changes[0].Name != "payment-service" {
+		t.Errorf("changes before the incident = %+v, %v; want the payment-service image update", changes, err)
+	}
+	violations, err := repo.ListSLOViolations(ctx, postgres.SLOViolationFilter{Service: "payment-service"})
+	if err != nil || len(violations) != 2 || violations[0].RecoveredAt == nil {
+		t.Errorf("payment-service violations = %+v, %v", violations, err)
+	}
+	baselines, err := repo.ListROIBaselines(ctx, postgres.ROIBaselineFilter{})
+	if err != nil || len(baselines) != 1 {
+		t.Errorf("ROI baselines = %+v, %v", baselines, err)
+	}
+}
+
+func TestStoryPrometheusConfig(t *testing.T) {
+	s := NewStory(time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC), 0.05, 0.01)
+	c := s.PrometheusConfig()
+	c.LatencyMs = 0
+	client := prometheus.NewMockClient(c)
+	rate := func(start, end time.Time) float64 {
+		metrics, err := client.GetRequestMetrics(context.Background(), NamespaceAppProd, "payment-service", start, end)
+		if err != nil || len(metrics) == 0 {
+			t.Fatalf("GetRequestMetrics: %d points, %v", len(metrics), err)
+		}
+		var requests, errors int64
+		for _, m := range metrics {
+			requests, errors = requests+m.Requests, errors+m.Errors
+		}
+		return float64(errors) / float64(requests)
+	}
+	during := rate(s.IncidentAt, s.RecoveredAt.Add(-5*time.Minute))
+	dayBefore := rate(s.IncidentAt.Add(-24*time.Hour), s.RecoveredAt.Add(-24*time.Hour-5*time.Minute))
+	if during < 0.02 || dayBefore > 0.01 {
+		t.Errorf("payment-service error rate %.4f during the incident, %.4f the day before", during, dayBefore)
+	}
+	for _, ns := range c.Namespaces {
+		if ns == NamespaceLegacyBatch {
+			t.Error("legacy-batch still in the Prometheus namespaces")
+		}
+	}
+}
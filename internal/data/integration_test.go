@@ -3,6 +3,7 @@ package data
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -659,3 +660,42 @@ func TestMockConfiguration(t *testing.T) {
 		})
 	}
 }
+
+// TestMocksConcurrentUse shares each mock between goroutines the way the server does. The mocks'
+// random sources are used on every call (error injection, generated metrics), so under
+// go test -race this fails if a source is not safe for concurrent use.
+func TestMocksConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	promConfig := prometheus.DefaultMockConfig()
+	promConfig.ErrorRate = 0.1
+	promConfig.LatencyMs = 0
+	k8sConfig := k8s.DefaultMockConfig()
+	k8sConfig.ErrorRate = 0.1
+	k8sConfig.LatencyMs = 0
+	postgresConfig := postgres.DefaultMockConfig()
+	postgresConfig.ErrorRate = 0.1
+	postgresConfig.LatencyMs = 0
+
+	promClient := prometheus.NewMockClient(promConfig)
+	k8sClient := k8s.NewMockClient(k8sConfig)
+	postgresRepo := postgres.NewMockRepository(postgresConfig)
+
+	end := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-time.Hour)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				_, _ = promClient.GetResourceMetrics(ctx, "default", "api", "", start, end)
+				_, _, _ = promClient.GetSharedResourceUsage(ctx, "lb", "traffic", start, end)
+				_, _ = k8sClient.GetDeployments(ctx, "default")
+				_, _ = k8sClient.GetEvents(ctx, "default", "", "")
+				_, _ = postgresRepo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{Limit: 5})
+				_ = postgresRepo.HealthCheck(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,130 @@
+// Package k8s provides client implementations for interacting with Kubernetes API.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Cost policy annotations, set on a namespace (default of its workloads) or a workload.
+const (
+	// AnnotationExcludeFromWaste ("true"/"false") excludes idle requests from waste, e.g. DR standby.
+	AnnotationExcludeFromWaste = "lighthouse.io/exclude-from-waste"
+	// AnnotationCostCenter is the chargeback cost center.
+	AnnotationCostCenter = "lighthouse.io/cost-center"
+	// AnnotationGradeThresholdOverride overrides grade boundaries in percent, e.g.
+	// "zombie=5,over_provisioned=20,risk=95"; omitted keys keep the defaults.
+	AnnotationGradeThresholdOverride = "lighthouse.io/grade-threshold-override"
+)
+
+// Cost context annotations, written back onto workloads by the cost annotation controller
+// (etl.CostAnnotationWorker) so kubectl users and other controllers see cost in-cluster.
+const (
+	// AnnotationMonthlyCost is the billable cost of the last 30 days, e.g. "1234.56".
+	AnnotationMonthlyCost = "lighthouse.io/monthly-cost"
+	// AnnotationGrade is the efficiency grade, e.g. "Healthy" or "OverProvisioned".
+	AnnotationGrade = "lighthouse.io/grade"
+	// AnnotationCostUpdatedAt is when the cost annotations were computed (RFC3339).
+	AnnotationCostUpdatedAt = "lighthouse.io/cost-updated-at"
+	// AnnotationEstimatedMonthlyCost is the monthly cost of the requests estimated at admission by
+	// the cost estimation webhook.
+	AnnotationEstimatedMonthlyCost = "lighthouse.io/estimated-monthly-cost"
+)
+
+// ParseCostPolicy reads the cost policy annotations. Invalid values are skipped and reported in the
+// returned error, so one bad annotation never disables the valid ones.
+func ParseCostPolicy(annotations map[string]string) (costmodel.CostPolicy, error) {
+	var policy costmodel.CostPolicy
+	var errs []error
+	if v, ok := annotations[AnnotationExcludeFromWaste]; ok {
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", AnnotationExcludeFromWaste, err))
+		}
+		policy.ExcludeFromWaste = b
+	}
+	policy.CostCenter = strings.TrimSpace(annotations[AnnotationCostCenter])
+	if v, ok := annotations[AnnotationGradeThresholdOverride]; ok {
+		t, err := parseGradeThresholds(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", AnnotationGradeThresholdOverride, err))
+		} else {
+			policy.GradeThresholds = &t
+		}
+	}
+	return policy, errors.Join(errs...)
+}
+
+func parseGradeThresholds(v string) (costmodel.GradeThresholds, error) {
+	t := costmodel.DefaultGradeThresholds
+	for _, part := range strings.Split(v, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return t, fmt.Errorf("expected key=value, got %q", part)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return t, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		switch strings.TrimSpace(key) {
+		case "zombie":
+			t.Zombie = f
+		case "over_provisioned":
+			t.OverProvisioned = f
+		case "risk":
+			t.Risk = f
+		default:
+			return t, fmt.Errorf("unknown threshold %q", key)
+		}
+	}
+	return t, t.Validate()
+}
+
+// CostPolicyResolver resolves workload cost policies from namespace and deployment annotations;
+// workload annotations override the namespace ones field by field.
+type CostPolicyResolver struct {
+	Client Client
+}
+
+// NamespacePolicies returns the namespace policy and the effective policy of each deployment in it.
+// Invalid annotations are ignored (see ParseCostPolicy).
+func (r *CostPolicyResolver) NamespacePolicies(ctx context.Context, namespace string) (costmodel.CostPolicy, map[string]costmodel.CostPolicy, error) {
+	namespaces, err := r.Client.GetNamespaces(ctx)
+	if err != nil {
+		return costmodel.CostPolicy{}, nil, err
+	}
+	var nsPolicy costmodel.CostPolicy
+	for _, ns := range namespaces {
+		if ns.Name == namespace {
+			nsPolicy, _ = ParseCostPolicy(ns.Annotations)
+			break
+		}
+	}
+	deployments, err := r.Client.GetDeployments(ctx, namespace)
+	if err != nil {
+		return nsPolicy, nil, err
+	}
+	policies := make(map[string]costmodel.CostPolicy, len(deployments))
+	for _, d := range deployments {
+		p, _ := ParseCostPolicy(d.Annotations)
+		policies[d.Name] = nsPolicy.Merge(p)
+	}
+	return nsPolicy, policies, nil
+}
+
+// PolicyFor returns the effective policy of one workload; unknown workloads get the namespace policy.
+func (r *CostPolicyResolver) PolicyFor(ctx context.Context, namespace, workload string) (costmodel.CostPolicy, error) {
+	nsPolicy, policies, err := r.NamespacePolicies(ctx, namespace)
+	if err != nil {
+		return costmodel.CostPolicy{}, err
+	}
+	if p, ok := policies[workload]; ok {
+		return p, nil
+	}
+	return nsPolicy, nil
+}
@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+func TestParseCostPolicy(t *testing.T) {
+	policy, err := ParseCostPolicy(map[string]string{
+		AnnotationExcludeFromWaste:       "true",
+		AnnotationCostCenter:             " cc-1024 ",
+		AnnotationGradeThresholdOverride: "zombie=5, risk=95",
+	})
+	if err != nil {
+		t.Fatalf("ParseCostPolicy: %v", err)
+	}
+	want := costmodel.GradeThresholds{Zombie: 5, OverProvisioned: 40, Risk: 95}
+	if !policy.ExcludeFromWaste || policy.CostCenter != "cc-1024" || policy.GradeThresholds == nil || *policy.GradeThresholds != want {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+
+	// 非法值被跳过，其余注解仍然生效
+	policy, err = ParseCostPolicy(map[string]string{
+		AnnotationCostCenter:             "cc-1",
+		AnnotationGradeThresholdOverride: "zombie=50,over_provisioned=20",
+	})
+	if err == nil || policy.CostCenter != "cc-1" || policy.GradeThresholds != nil {
+		t.Errorf("invalid thresholds: got %+v, %v", policy, err)
+	}
+	if _, err := ParseCostPolicy(map[string]string{AnnotationGradeThresholdOverride: "idle=3"}); err == nil {
+		t.Error("expected unknown threshold key to be rejected")
+	}
+}
+
+func TestCostPolicyResolver(t *testing.T) {
+	cfg := DefaultMockConfig()
+	cfg.LatencyMs = 0
+	cfg.Namespaces = []string{"dr"}
+	cfg.Annotations = map[string]map[string]string{
+		"dr":              {AnnotationExcludeFromWaste: "true", AnnotationCostCenter: "cc-dr"},
+		"dr-deployment-1": {AnnotationCostCenter: "cc-db"},
+	}
+	r := &CostPolicyResolver{Client: NewMockClient(cfg)}
+	ctx := context.Background()
+
+	p, err := r.PolicyFor(ctx, "dr", "dr-deployment-1")
+	if err != nil {
+		t.Fatalf("PolicyFor: %v", err)
+	}
+	if !p.ExcludeFromWaste || p.CostCenter != "cc-db" {
+		t.Errorf("workload policy should inherit exclusion and override cost center, got %+v", p)
+	}
+	if p, _ = r.PolicyFor(ctx, "dr", "unknown"); p.CostCenter != "cc-dr" {
+		t.Errorf("unknown workloads get the namespace policy, got %+v", p)
+	}
+}
@@ -0,0 +1,113 @@
+// Package k8s breaker.go: circuit-breaker wrapper so an unresponsive API server fails fast
+// instead of piling up requests.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+)
+
+// BreakerClient wraps a Client with the breaker of target in set. HealthCheck bypasses the
+// breaker so readiness probes still reach the API server while the circuit is open.
+type BreakerClient struct {
+	client   Client
+	target   string
+	breakers *breaker.Set
+}
+
+// NewBreakerClient wraps client; target identifies the cluster (e.g. API server address).
+func NewBreakerClient(client Client, target string, breakers *breaker.Set) *BreakerClient {
+	return &BreakerClient{client: client, target: target, breakers: breakers}
+}
+
+// GetNamespaces implements Client.
+func (c *BreakerClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
+	var out []Namespace
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetNamespaces(ctx)
+		return err
+	})
+	return out, err
+}
+
+// GetDeployments implements Client.
+func (c *BreakerClient) GetDeployments(ctx context.Context, namespace string) ([]Deployment, error) {
+	var out []Deployment
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetDeployments(ctx, namespace)
+		return err
+	})
+	return out, err
+}
+
+// GetPods implements Client.
+func (c *BreakerClient) GetPods(ctx context.Context, namespace, deployment string) ([]Pod, error) {
+	var out []Pod
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetPods(ctx, namespace, deployment)
+		return err
+	})
+	return out, err
+}
+
+// GetNodes implements Client.
+func (c *BreakerClient) GetNodes(ctx context.Context) ([]Node, error) {
+	var out []Node
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetNodes(ctx)
+		return err
+	})
+	return out, err
+}
+
+// GetEvents implements Client.
+func (c *BreakerClient) GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]Event, error) {
+	var out []Event
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetEvents(ctx, namespace, resourceType, resourceName)
+		return err
+	})
+	return out, err
+}
+
+// GetResourceQuotas implements Client.
+func (c *BreakerClient) GetResourceQuotas(ctx context.Context, namespace string) ([]ResourceQuota, error) {
+	var out []ResourceQuota
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetResourceQuotas(ctx, namespace)
+		return err
+	})
+	return out, err
+}
+
+// AnnotateWorkload implements WorkloadAnnotator when the wrapped client does.
+func (c *BreakerClient) AnnotateWorkload(ctx context.Context, namespace, kind, name string, annotations map[string]string) error {
+	annotator, ok := c.client.(WorkloadAnnotator)
+	if !ok {
+		return fmt.Errorf("k8s client %T cannot annotate workloads", c.client)
+	}
+	return c.breakers.Do(c.target, func() error {
+		return annotator.AnnotateWorkload(ctx, namespace, kind, name, annotations)
+	})
+}
+
+// GetPodDisruptionBudgets implements DisruptionBudgetLister when the wrapped client does.
+func (c *BreakerClient) GetPodDisruptionBudgets(ctx context.Context, namespace string) ([]PodDisruptionBudget, error) {
+	lister, ok := c.client.(DisruptionBudgetLister)
+	if !ok {
+		return nil, fmt.Errorf("k8s client %T cannot list pod disruption budgets", c.client)
+	}
+	var out []PodDisruptionBudget
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = lister.GetPodDisruptionBudgets(ctx, namespace)
+		return err
+	})
+	return out, err
+}
+
+// HealthCheck implements Client without going through the breaker.
+func (c *BreakerClient) HealthCheck(ctx context.Context) error {
+	return c.client.HealthCheck(ctx)
+}
@@ -0,0 +1,103 @@
+// Package k8s chaos.go: resilience drill wrapper that injects the failures and latency of the active
+// chaos scenario into API server calls.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+)
+
+// ChaosClient wraps a Client with the kubernetes fault of the active drill of controller. It wraps
+// the base client inside the retry and breaker wrappers, so a drill exercises them like an outage.
+// HealthCheck is injected too, so readiness reflects the drill.
+type ChaosClient struct {
+	client     Client
+	controller *chaos.Controller
+}
+
+// NewChaosClient wraps client.
+func NewChaosClient(client Client, controller *chaos.Controller) *ChaosClient {
+	return &ChaosClient{client: client, controller: controller}
+}
+
+// GetNamespaces implements Client.
+func (c *ChaosClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return nil, err
+	}
+	return c.client.GetNamespaces(ctx)
+}
+
+// GetDeployments implements Client.
+func (c *ChaosClient) GetDeployments(ctx context.Context, namespace string) ([]Deployment, error) {
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return nil, err
+	}
+	return c.client.GetDeployments(ctx, namespace)
+}
+
+// GetPods implements Client.
+func (c *ChaosClient) GetPods(ctx context.Context, namespace, deployment string) ([]Pod, error) {
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return nil, err
+	}
+	return c.client.GetPods(ctx, namespace, deployment)
+}
+
+// GetNodes implements Client.
+func (c *ChaosClient) GetNodes(ctx context.Context) ([]Node, error) {
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return nil, err
+	}
+	return c.client.GetNodes(ctx)
+}
+
+// GetEvents implements Client.
+func (c *ChaosClient) GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]Event, error) {
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return nil, err
+	}
+	return c.client.GetEvents(ctx, namespace, resourceType, resourceName)
+}
+
+// GetResourceQuotas implements Client.
+func (c *ChaosClient) GetResourceQuotas(ctx context.Context, namespace string) ([]ResourceQuota, error) {
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return nil, err
+	}
+	return c.client.GetResourceQuotas(ctx, namespace)
+}
+
+// AnnotateWorkload implements WorkloadAnnotator when the wrapped client does.
+func (c *ChaosClient) AnnotateWorkload(ctx context.Context, namespace, kind, name string, annotations map[string]string) error {
+	annotator, ok := c.client.(WorkloadAnnotator)
+	if !ok {
+		return fmt.Errorf("k8s client %T cannot annotate workloads", c.client)
+	}
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return err
+	}
+	return annotator.AnnotateWorkload(ctx, namespace, kind, name, annotations)
+}
+
+// GetPodDisruptionBudgets implements DisruptionBudgetLister when the wrapped client does.
+func (c *ChaosClient) GetPodDisruptionBudgets(ctx context.Context, namespace string) ([]PodDisruptionBudget, error) {
+	lister, ok := c.client.(DisruptionBudgetLister)
+	if !ok {
+		return nil, fmt.Errorf("k8s client %T cannot list pod disruption budgets", c.client)
+	}
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return nil, err
+	}
+	return lister.GetPodDisruptionBudgets(ctx, namespace)
+}
+
+// HealthCheck implements Client.
+func (c *ChaosClient) HealthCheck(ctx context.Context) error {
+	if err := c.controller.Inject(ctx, chaos.Kubernetes); err != nil {
+		return err
+	}
+	return c.client.HealthCheck(ctx)
+}
@@ -30,6 +30,21 @@ type Client interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// WorkloadAnnotator patches annotations onto workloads. It is only used by the cost annotation
+// controller, which needs write access to the cluster. *MockClient and *ClusterClient satisfy this interface.
+type WorkloadAnnotator interface {
+	// AnnotateWorkload merges annotations into the workload kind/name (e.g. "Deployment"); an
+	// empty value removes the annotation. A missing workload returns a dataerr.NotFound error.
+	AnnotateWorkload(ctx context.Context, namespace, kind, name string, annotations map[string]string) error
+}
+
+// DisruptionBudgetLister lists PodDisruptionBudgets. It is used by the node consolidation planner
+// to check that draining a node can evict its pods. *MockClient and *ClusterClient satisfy this interface.
+type DisruptionBudgetLister interface {
+	// GetPodDisruptionBudgets retrieves the PodDisruptionBudgets of a namespace ("" for all).
+	GetPodDisruptionBudgets(ctx context.Context, namespace string) ([]PodDisruptionBudget, error)
+}
+
 // Namespace represents a Kubernetes namespace.
 type Namespace struct {
 	Name              string            `json:"name"`
@@ -81,6 +96,7 @@ type ContainerResources struct {
 // Node represents a Kubernetes node.
 type Node struct {
 	Name              string            `json:"name"`
+	ProviderID        string            `json:"provider_id"` // e.g., "cn-hangzhou.i-bp1..." on Alibaba Cloud ACK
 	CreationTimestamp time.Time         `json:"creation_timestamp"`
 	Labels            map[string]string `json:"labels"`
 	Annotations       map[string]string `json:"annotations"`
@@ -137,3 +153,32 @@ type ResourceQuota struct {
 	Scopes            []string          `json:"scopes"`
 	ScopeSelector     map[string]string `json:"scope_selector"`
 }
+
+// PodDisruptionBudget represents a Kubernetes PodDisruptionBudget with its status.
+type PodDisruptionBudget struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Selector  map[string]string `json:"selector"` // matchLabels of the pods it covers
+	// MinAvailable / MaxUnavailable as in the spec: a count ("2") or a percentage ("50%"); one is set.
+	MinAvailable   string `json:"min_available,omitempty"`
+	MaxUnavailable string `json:"max_unavailable,omitempty"`
+	// Status: the healthy pods, those the budget requires, and the evictions allowed right now.
+	CurrentHealthy     int32 `json:"current_healthy"`
+	DesiredHealthy     int32 `json:"desired_healthy"`
+	ExpectedPods       int32 `json:"expected_pods"`
+	DisruptionsAllowed int32 `json:"disruptions_allowed"`
+}
+
+// Covers reports whether the budget selects a pod with the given labels. An empty selector
+// selects no pods, as for policy/v1 budgets.
+func (b PodDisruptionBudget) Covers(namespace string, labels map[string]string) bool {
+	if namespace != b.Namespace || len(b.Selector) == 0 {
+		return false
+	}
+	for k, v := range b.Selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
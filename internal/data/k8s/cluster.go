@@ -0,0 +1,536 @@
+// Package k8s cluster.go: client-go backed Client for a live cluster, in-cluster or via kubeconfig.
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// Client implementations selectable by kubernetes.client.
+const (
+	ClientMock    = "mock"    // generated data (default)
+	ClientCluster = "cluster" // live cluster through client-go
+)
+
+// ErrReadOnly is returned for writes when the client is configured read-only.
+var ErrReadOnly = errors.New("k8s: client is read-only (kubernetes.rbac.read_only_access)")
+
+// ClusterConfig holds live cluster client settings; it mirrors config.KubernetesConfig.
+type ClusterConfig struct {
+	// InCluster uses the pod's service account; otherwise Kubeconfig (default: $KUBECONFIG, then
+	// ~/.kube/config) and Context select the cluster and credentials.
+	InCluster  bool
+	Kubeconfig string
+	Context    string
+	// APIServer overrides the server address of either mode.
+	APIServer string
+	// BearerTokenFile overrides the credentials with a token read (and re-read on rotation) from file.
+	BearerTokenFile string
+	// Timeout bounds each request; 0 leaves it to the request context.
+	Timeout time.Duration
+
+	// ReadOnly rejects every mutating request before it leaves the process.
+	ReadOnly bool
+	// Namespace with NamespaceScoped restricts namespace listing and cluster-wide queries ("" namespace)
+	// to this namespace, for service accounts bound by a Role rather than a ClusterRole.
+	Namespace       string
+	NamespaceScoped bool
+}
+
+// RESTConfig resolves the client-go configuration of the selected mode.
+func (c ClusterConfig) RESTConfig() (*rest.Config, error) {
+	var rc *rest.Config
+	if c.InCluster {
+		var err error
+		if rc, err = rest.InClusterConfig(); err != nil {
+			return nil, fmt.Errorf("k8s: in-cluster config: %w", err)
+		}
+		if c.APIServer != "" {
+			rc.Host = c.APIServer
+		}
+	} else {
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = c.Kubeconfig
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: c.Context}
+		overrides.ClusterInfo.Server = c.APIServer
+		var err error
+		if rc, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig(); err != nil {
+			return nil, fmt.Errorf("k8s: kubeconfig: %w", err)
+		}
+	}
+	if c.BearerTokenFile != "" {
+		rc.BearerToken, rc.BearerTokenFile = "", c.BearerTokenFile
+	}
+	if c.Timeout > 0 {
+		rc.Timeout = c.Timeout
+	}
+	if c.ReadOnly {
+		rc.Wrap(func(rt http.RoundTripper) http.RoundTripper { return readOnlyTransport{rt} })
+	}
+	rc.UserAgent = "lighthouse"
+	return rc, nil
+}
+
+// readOnlyTransport refuses requests that could change cluster state.
+type readOnlyTransport struct{ next http.RoundTripper }
+
+func (t readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrReadOnly, req.Method, req.URL.Path)
+}
+
+// APIError is a failed API server request.
+type APIError struct {
+	Op  string
+	Err error
+}
+
+func (e *APIError) Error() string { return "k8s: " + e.Op + ": " + e.Err.Error() }
+
+// Unwrap returns the underlying error and, when it has one, its dataerr kind: NotFound, Conflict,
+// Validation for rejected requests and Unavailable for timeouts, throttling and server errors.
+// Authorization failures have no kind and are not retried.
+func (e *APIError) Unwrap() []error {
+	switch {
+	case apierrors.IsNotFound(e.Err):
+		return []error{dataerr.ErrNotFound, e.Err}
+	case apierrors.IsConflict(e.Err) || apierrors.IsAlreadyExists(e.Err):
+		return []error{dataerr.ErrConflict, e.Err}
+	case apierrors.IsInvalid(e.Err) || apierrors.IsBadRequest(e.Err):
+		return []error{dataerr.ErrValidation, e.Err}
+	case apierrors.IsTimeout(e.Err) || apierrors.IsServerTimeout(e.Err) || apierrors.IsTooManyRequests(e.Err) ||
+		apierrors.IsServiceUnavailable(e.Err) || apierrors.IsInternalError(e.Err) || apierrors.IsUnexpectedServerError(e.Err):
+		return []error{dataerr.ErrUnavailable, e.Err}
+	}
+	return []error{e.Err}
+}
+
+// ClusterClient implements Client, WorkloadAnnotator and DisruptionBudgetLister over client-go.
+type ClusterClient struct {
+	config    ClusterConfig
+	clientset kubernetes.Interface
+}
+
+// NewClusterClient connects to the cluster selected by config. No request is made; use HealthCheck
+// to verify connectivity.
+func NewClusterClient(config ClusterConfig) (*ClusterClient, error) {
+	rc, err := config.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(rc)
+	if err != nil {
+		return nil, fmt.Errorf("k8s: clientset: %w", err)
+	}
+	return NewClusterClientForClientset(clientset, config), nil
+}
+
+// NewClusterClientForClientset wraps an existing clientset, e.g. a fake one in tests. The connection
+// settings of config are ignored; ReadOnly and the namespace scope still apply.
+func NewClusterClientForClientset(clientset kubernetes.Interface, config ClusterConfig) *ClusterClient {
+	return &ClusterClient{config: config, clientset: clientset}
+}
+
+// scope maps the "all namespaces" query to the configured namespace when namespace-scoped.
+func (c *ClusterClient) scope(namespace string) string {
+	if namespace == "" && c.config.NamespaceScoped {
+		return c.config.Namespace
+	}
+	return namespace
+}
+
+// GetNamespaces lists the namespaces; namespace-scoped, only the configured one.
+func (c *ClusterClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
+	if c.config.NamespaceScoped {
+		ns, err := c.clientset.CoreV1().Namespaces().Get(ctx, c.config.Namespace, metav1.GetOptions{})
+		if apierrors.IsForbidden(err) {
+			// A Role cannot grant reading the namespace object itself
+			return []Namespace{{Name: c.config.Namespace, Status: string(corev1.NamespaceActive)}}, nil
+		}
+		if err != nil {
+			return nil, &APIError{Op: "get namespace " + c.config.Namespace, Err: err}
+		}
+		return []Namespace{toNamespace(ns)}, nil
+	}
+	list, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, &APIError{Op: "list namespaces", Err: err}
+	}
+	namespaces := make([]Namespace, 0, len(list.Items))
+	for i := range list.Items {
+		namespaces = append(namespaces, toNamespace(&list.Items[i]))
+	}
+	return namespaces, nil
+}
+
+// GetDeployments lists the deployments of a namespace.
+func (c *ClusterClient) GetDeployments(ctx context.Context, namespace string) ([]Deployment, error) {
+	namespace = c.scope(namespace)
+	list, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, &APIError{Op: "list deployments in " + namespace, Err: err}
+	}
+	deployments := make([]Deployment, 0, len(list.Items))
+	for i := range list.Items {
+		deployments = append(deployments, toDeployment(&list.Items[i]))
+	}
+	return deployments, nil
+}
+
+// GetPods lists the pods of a namespace, or of one deployment when deployment is set. A pod belongs
+// to the deployment that owns its ReplicaSet.
+func (c *ClusterClient) GetPods(ctx context.Context, namespace, deployment string) ([]Pod, error) {
+	namespace = c.scope(namespace)
+	list, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, &APIError{Op: "list pods in " + namespace, Err: err}
+	}
+	pods := make([]Pod, 0, len(list.Items))
+	for i := range list.Items {
+		pod := toPod(&list.Items[i])
+		if deployment != "" && pod.Deployment != deployment {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// GetNodes lists the cluster nodes.
+func (c *ClusterClient) GetNodes(ctx context.Context) ([]Node, error) {
+	list, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, &APIError{Op: "list nodes", Err: err}
+	}
+	nodes := make([]Node, 0, len(list.Items))
+	for i := range list.Items {
+		nodes = append(nodes, toNode(&list.Items[i]))
+	}
+	return nodes, nil
+}
+
+// GetEvents lists the events of a namespace, narrowed to the involved object's kind and name when set.
+func (c *ClusterClient) GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]Event, error) {
+	namespace = c.scope(namespace)
+	selector := fields.Set{}
+	if resourceType != "" {
+		selector["involvedObject.kind"] = resourceType
+	}
+	if resourceName != "" {
+		selector["involvedObject.name"] = resourceName
+	}
+	list, err := c.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.AsSelector().String()})
+	if err != nil {
+		return nil, &APIError{Op: "list events in " + namespace, Err: err}
+	}
+	events := make([]Event, 0, len(list.Items))
+	for i := range list.Items {
+		events = append(events, toEvent(&list.Items[i]))
+	}
+	return events, nil
+}
+
+// GetResourceQuotas lists the resource quotas of a namespace.
+func (c *ClusterClient) GetResourceQuotas(ctx context.Context, namespace string) ([]ResourceQuota, error) {
+	namespace = c.scope(namespace)
+	list, err := c.clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, &APIError{Op: "list resource quotas in " + namespace, Err: err}
+	}
+	quotas := make([]ResourceQuota, 0, len(list.Items))
+	for i := range list.Items {
+		quotas = append(quotas, toResourceQuota(&list.Items[i]))
+	}
+	return quotas, nil
+}
+
+// GetPodDisruptionBudgets lists the PodDisruptionBudgets of a namespace ("" for all).
+func (c *ClusterClient) GetPodDisruptionBudgets(ctx context.Context, namespace string) ([]PodDisruptionBudget, error) {
+	namespace = c.scope(namespace)
+	list, err := c.clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, &APIError{Op: "list pod disruption budgets", Err: err}
+	}
+	budgets := make([]PodDisruptionBudget, 0, len(list.Items))
+	for _, pdb := range list.Items {
+		b := PodDisruptionBudget{
+			Name:               pdb.Name,
+			Namespace:          pdb.Namespace,
+			CurrentHealthy:     pdb.Status.CurrentHealthy,
+			DesiredHealthy:     pdb.Status.DesiredHealthy,
+			ExpectedPods:       pdb.Status.ExpectedPods,
+			DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+		}
+		if pdb.Spec.Selector != nil {
+			b.Selector = pdb.Spec.Selector.MatchLabels
+		}
+		if pdb.Spec.MinAvailable != nil {
+			b.MinAvailable = pdb.Spec.MinAvailable.String()
+		}
+		if pdb.Spec.MaxUnavailable != nil {
+			b.MaxUnavailable = pdb.Spec.MaxUnavailable.String()
+		}
+		budgets = append(budgets, b)
+	}
+	return budgets, nil
+}
+
+// HealthCheck queries the API server version endpoint.
+func (c *ClusterClient) HealthCheck(ctx context.Context) error {
+	rc := c.clientset.Discovery().RESTClient()
+	if rc == nil {
+		// Fake clientsets have no REST client
+		_, err := c.clientset.Discovery().ServerVersion()
+		return wrapHealth(err)
+	}
+	return wrapHealth(rc.Get().AbsPath("/version").Do(ctx).Error())
+}
+
+func wrapHealth(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return dataerr.Unavailable("k8s health check failed: %v", err)
+}
+
+// AnnotateWorkload merge-patches annotations onto a Deployment, StatefulSet, DaemonSet, ReplicaSet,
+// Job or CronJob; an empty value removes the annotation. It returns ErrReadOnly when read-only.
+func (c *ClusterClient) AnnotateWorkload(ctx context.Context, namespace, kind, name string, annotations map[string]string) error {
+	if c.config.ReadOnly {
+		return fmt.Errorf("%w: annotate %s %s/%s", ErrReadOnly, kind, namespace, name)
+	}
+	values := make(map[string]*string, len(annotations))
+	for k, v := range annotations {
+		if v == "" {
+			values[k] = nil // null deletes the key in a merge patch
+			continue
+		}
+		v := v
+		values[k] = &v
+	}
+	patch, err := json.Marshal(map[string]any{"metadata": map[string]any{"annotations": values}})
+	if err != nil {
+		return err
+	}
+	opts := metav1.PatchOptions{FieldManager: "lighthouse"}
+	switch kind {
+	case "Deployment":
+		_, err = c.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	case "StatefulSet":
+		_, err = c.clientset.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	case "DaemonSet":
+		_, err = c.clientset.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	case "ReplicaSet":
+		_, err = c.clientset.AppsV1().ReplicaSets(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	case "Job":
+		_, err = c.clientset.BatchV1().Jobs(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	case "CronJob":
+		_, err = c.clientset.BatchV1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, patch, opts)
+	default:
+		return dataerr.Validation("cannot annotate workload kind %q", kind)
+	}
+	if err != nil {
+		return &APIError{Op: fmt.Sprintf("annotate %s %s/%s", kind, namespace, name), Err: err}
+	}
+	return nil
+}
+
+func toNamespace(ns *corev1.Namespace) Namespace {
+	return Namespace{
+		Name:              ns.Name,
+		CreationTimestamp: ns.CreationTimestamp.Time,
+		Labels:            ns.Labels,
+		Annotations:       ns.Annotations,
+		Status:            string(ns.Status.Phase),
+	}
+}
+
+func toDeployment(d *appsv1.Deployment) Deployment {
+	replicas := int32(1) // the API server default
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return Deployment{
+		Name:              d.Name,
+		Namespace:         d.Namespace,
+		Replicas:          replicas,
+		AvailableReplicas: d.Status.AvailableReplicas,
+		Labels:            d.Labels,
+		Annotations:       d.Annotations,
+		CreationTimestamp: d.CreationTimestamp.Time,
+		StrategyType:      string(d.Spec.Strategy.Type),
+	}
+}
+
+func toPod(p *corev1.Pod) Pod {
+	ready := make(map[string]bool, len(p.Status.ContainerStatuses))
+	for _, s := range p.Status.ContainerStatuses {
+		ready[s.Name] = s.Ready
+	}
+	containers := make([]Container, 0, len(p.Spec.Containers))
+	for _, c := range p.Spec.Containers {
+		containers = append(containers, Container{
+			Name:  c.Name,
+			Image: c.Image,
+			Resources: ContainerResources{
+				Requests: quantities(c.Resources.Requests),
+				Limits:   quantities(c.Resources.Limits),
+			},
+			Ready: ready[c.Name],
+		})
+	}
+	return Pod{
+		Name:              p.Name,
+		Namespace:         p.Namespace,
+		Deployment:        podDeployment(p),
+		NodeName:          p.Spec.NodeName,
+		Phase:             string(p.Status.Phase),
+		CreationTimestamp: p.CreationTimestamp.Time,
+		Labels:            p.Labels,
+		Annotations:       p.Annotations,
+		Containers:        containers,
+	}
+}
+
+// podDeployment derives the owning deployment from the pod's ReplicaSet, named
+// <deployment>-<pod-template-hash>; "" for pods not managed by a deployment.
+func podDeployment(p *corev1.Pod) string {
+	owner := metav1.GetControllerOf(p)
+	if owner == nil || owner.Kind != "ReplicaSet" {
+		return ""
+	}
+	hash := p.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+	if hash == "" || !strings.HasSuffix(owner.Name, "-"+hash) {
+		return ""
+	}
+	return strings.TrimSuffix(owner.Name, "-"+hash)
+}
+
+func toNode(n *corev1.Node) Node {
+	conditions := make([]NodeCondition, 0, len(n.Status.Conditions))
+	for _, c := range n.Status.Conditions {
+		conditions = append(conditions, NodeCondition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message})
+	}
+	addresses := make([]NodeAddress, 0, len(n.Status.Addresses))
+	for _, a := range n.Status.Addresses {
+		addresses = append(addresses, NodeAddress{Type: string(a.Type), Address: a.Address})
+	}
+	return Node{
+		Name:              n.Name,
+		ProviderID:        n.Spec.ProviderID,
+		CreationTimestamp: n.CreationTimestamp.Time,
+		Labels:            n.Labels,
+		Annotations:       n.Annotations,
+		Conditions:        conditions,
+		Capacity:          quantities(n.Status.Capacity),
+		Allocatable:       quantities(n.Status.Allocatable),
+		Addresses:         addresses,
+	}
+}
+
+func toEvent(e *corev1.Event) Event {
+	first, last := e.FirstTimestamp.Time, e.LastTimestamp.Time
+	if first.IsZero() {
+		// events.k8s.io/v1 writers set only eventTime
+		first = e.EventTime.Time
+	}
+	if last.IsZero() {
+		last = first
+	}
+	if e.Series != nil && e.Series.LastObservedTime.After(last) {
+		last = e.Series.LastObservedTime.Time
+	}
+	count := e.Count
+	if count == 0 {
+		count = 1
+		if e.Series != nil {
+			count = e.Series.Count
+		}
+	}
+	component, host := e.Source.Component, e.Source.Host
+	if component == "" {
+		component, host = e.ReportingController, e.ReportingInstance
+	}
+	return Event{
+		Name:            e.Name,
+		Namespace:       e.Namespace,
+		Type:            e.Type,
+		Reason:          e.Reason,
+		Message:         e.Message,
+		SourceComponent: component,
+		SourceHost:      host,
+		Count:           count,
+		FirstTimestamp:  first,
+		LastTimestamp:   last,
+		InvolvedObject: ObjectReference{
+			Kind:      e.InvolvedObject.Kind,
+			Namespace: e.InvolvedObject.Namespace,
+			Name:      e.InvolvedObject.Name,
+			UID:       string(e.InvolvedObject.UID),
+		},
+	}
+}
+
+func toResourceQuota(q *corev1.ResourceQuota) ResourceQuota {
+	hard := q.Status.Hard
+	if len(hard) == 0 {
+		// Not yet reconciled by the quota controller
+		hard = q.Spec.Hard
+	}
+	scopes := make([]string, 0, len(q.Spec.Scopes))
+	for _, s := range q.Spec.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	selector := map[string]string{}
+	if q.Spec.ScopeSelector != nil {
+		for _, r := range q.Spec.ScopeSelector.MatchExpressions {
+			// e.g. PriorityClass: "In(high,critical)", Terminating: "Exists"
+			value := string(r.Operator)
+			if len(r.Values) > 0 {
+				value += "(" + strings.Join(r.Values, ",") + ")"
+			}
+			selector[string(r.ScopeName)] = value
+		}
+	}
+	return ResourceQuota{
+		Name:              q.Name,
+		Namespace:         q.Namespace,
+		CreationTimestamp: q.CreationTimestamp.Time,
+		Hard:              quantities(hard),
+		Used:              quantities(q.Status.Used),
+		Scopes:            scopes,
+		ScopeSelector:     selector,
+	}
+}
+
+// quantities renders a resource list in canonical quantity notation ("500m", "2Gi").
+func quantities(list corev1.ResourceList) map[string]string {
+	out := make(map[string]string, len(list))
+	for name, q := range list {
+		out[string(name)] = q.String()
+	}
+	return out
+}
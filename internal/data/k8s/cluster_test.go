@@ -0,0 +1,350 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/retry"
+)
+
+func newFakeCluster(config ClusterConfig) (*ClusterClient, *fake.Clientset) {
+	replicas := int32(3)
+	controller := true
+	pod := func(name, owner, hash string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": "api"}},
+			Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{
+				Name:  "api",
+				Image: "api:1.2",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{Name: "api", Ready: true}}},
+		}
+		if owner != "" {
+			p.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = hash
+			p.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: owner, Controller: &controller}}
+		}
+		return p
+	}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"team": "payments"}}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ops"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Strategy: appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType}},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 2},
+		},
+		pod("api-7d9f8b6c5-x2k4p", "api-7d9f8b6c5", "7d9f8b6c5"),
+		pod("api-v2-5c4b3a2-q9w8e", "api-v2-5c4b3a2", "5c4b3a2"),
+		pod("debug", "", ""),
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       corev1.NodeSpec{ProviderID: "cn-hangzhou.i-bp1"},
+			Status: corev1.NodeStatus{
+				Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("32Gi")},
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("7500m")},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				Addresses:   []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}},
+			},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "api.1", Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Namespace: "shop", Name: "api"},
+			Type:           corev1.EventTypeNormal, Reason: "ScalingReplicaSet", ReportingController: "deployment-controller",
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "shop"},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard:          corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")},
+				ScopeSelector: &corev1.ScopeSelector{MatchExpressions: []corev1.ScopedResourceSelectorRequirement{{ScopeName: corev1.ResourceQuotaScopePriorityClass, Operator: corev1.ScopeSelectorOpIn, Values: []string{"high"}}}},
+			},
+			Status: corev1.ResourceQuotaStatus{Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2500m")}},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"}, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+			Status:     policyv1.PodDisruptionBudgetStatus{CurrentHealthy: 3, DesiredHealthy: 2, ExpectedPods: 3, DisruptionsAllowed: 1},
+		},
+	)
+	return NewClusterClientForClientset(clientset, config), clientset
+}
+
+func TestClusterClient_Reads(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeCluster(ClusterConfig{})
+
+	namespaces, err := client.GetNamespaces(ctx)
+	if err != nil || len(namespaces) != 2 {
+		t.Fatalf("GetNamespaces = %v, %v; want 2 namespaces", namespaces, err)
+	}
+
+	deployments, err := client.GetDeployments(ctx, "shop")
+	if err != nil || len(deployments) != 1 {
+		t.Fatalf("GetDeployments = %v, %v", deployments, err)
+	}
+	if d := deployments[0]; d.Replicas != 3 || d.AvailableReplicas != 2 || d.StrategyType != "RollingUpdate" {
+		t.Errorf("deployment = %+v", d)
+	}
+
+	pods, err := client.GetPods(ctx, "shop", "")
+	if err != nil || len(pods) != 3 {
+		t.Fatalf("GetPods(all) = %d pods, %v; want 3", len(pods), err)
+	}
+	pods, err = client.GetPods(ctx, "shop", "api")
+	if err != nil || len(pods) != 1 {
+		t.Fatalf("GetPods(api) = %d pods, %v; want only the pod of its ReplicaSet", len(pods), err)
+	}
+	p := pods[0]
+	if p.Deployment != "api" || p.NodeName != "node-1" || p.Phase != "Running" {
+		t.Errorf("pod = %+v", p)
+	}
+	c := p.Containers[0]
+	if !c.Ready || c.Resources.Requests["cpu"] != "500m" || c.Resources.Requests["memory"] != "512Mi" || c.Resources.Limits["cpu"] != "1" {
+		t.Errorf("container = %+v", c)
+	}
+	if _, err := ParseCPUCores(c.Resources.Requests["cpu"]); err != nil {
+		t.Errorf("requests must parse with ParseCPUCores: %v", err)
+	}
+
+	nodes, err := client.GetNodes(ctx)
+	if err != nil || len(nodes) != 1 {
+		t.Fatalf("GetNodes = %v, %v", nodes, err)
+	}
+	if n := nodes[0]; n.ProviderID != "cn-hangzhou.i-bp1" || n.Capacity["memory"] != "32Gi" || n.Allocatable["cpu"] != "7500m" ||
+		n.Conditions[0].Type != "Ready" || n.Addresses[0].Address != "10.0.0.1" {
+		t.Errorf("node = %+v", n)
+	}
+
+	quotas, err := client.GetResourceQuotas(ctx, "shop")
+	if err != nil || len(quotas) != 1 {
+		t.Fatalf("GetResourceQuotas = %v, %v", quotas, err)
+	}
+	if q := quotas[0]; q.Hard["cpu"] != "10" || q.Used["cpu"] != "2500m" || q.ScopeSelector["PriorityClass"] != "In(high)" {
+		t.Errorf("quota = %+v", q)
+	}
+
+	budgets, err := client.GetPodDisruptionBudgets(ctx, "")
+	if err != nil || len(budgets) != 1 {
+		t.Fatalf("GetPodDisruptionBudgets = %v, %v", budgets, err)
+	}
+	if b := budgets[0]; b.MinAvailable != "50%" || b.DisruptionsAllowed != 1 || !b.Covers("shop", map[string]string{"app": "api"}) {
+		t.Errorf("budget = %+v", b)
+	}
+}
+
+func TestClusterClient_GetEvents(t *testing.T) {
+	client, clientset := newFakeCluster(ClusterConfig{})
+	events, err := client.GetEvents(context.Background(), "shop", "Deployment", "api")
+	if err != nil || len(events) != 1 {
+		t.Fatalf("GetEvents = %v, %v", events, err)
+	}
+	e := events[0]
+	if e.Reason != "ScalingReplicaSet" || e.SourceComponent != "deployment-controller" || e.Count != 1 || e.InvolvedObject.Name != "api" {
+		t.Errorf("event = %+v", e)
+	}
+	// The involved object narrows the query by field selector
+	var selector string
+	for _, action := range clientset.Actions() {
+		if list, ok := action.(k8stesting.ListAction); ok && action.GetResource().Resource == "events" {
+			selector = list.GetListRestrictions().Fields.String()
+		}
+	}
+	if selector != "involvedObject.kind=Deployment,involvedObject.name=api" {
+		t.Errorf("field selector = %q", selector)
+	}
+}
+
+func TestClusterClient_NamespaceScoped(t *testing.T) {
+	ctx := context.Background()
+	client, clientset := newFakeCluster(ClusterConfig{Namespace: "shop", NamespaceScoped: true})
+
+	namespaces, err := client.GetNamespaces(ctx)
+	if err != nil || len(namespaces) != 1 || namespaces[0].Labels["team"] != "payments" {
+		t.Fatalf("GetNamespaces = %v, %v; want only shop", namespaces, err)
+	}
+	if _, err := client.GetPodDisruptionBudgets(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	last := clientset.Actions()[len(clientset.Actions())-1]
+	if last.GetNamespace() != "shop" {
+		t.Errorf("all-namespace query went to %q, want the configured namespace", last.GetNamespace())
+	}
+
+	// A Role cannot read the namespace object: the configured namespace is still listed
+	clientset.PrependReactor("get", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "shop", errors.New("rbac"))
+	})
+	namespaces, err = client.GetNamespaces(ctx)
+	if err != nil || len(namespaces) != 1 || namespaces[0].Name != "shop" {
+		t.Errorf("GetNamespaces (forbidden) = %v, %v", namespaces, err)
+	}
+}
+
+func TestClusterClient_AnnotateWorkload(t *testing.T) {
+	ctx := context.Background()
+	client, clientset := newFakeCluster(ClusterConfig{})
+
+	if err := client.AnnotateWorkload(ctx, "shop", "Deployment", "api", map[string]string{AnnotationMonthlyCost: "12.50", AnnotationGrade: "B"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AnnotateWorkload(ctx, "shop", "Deployment", "api", map[string]string{AnnotationGrade: ""}); err != nil {
+		t.Fatal(err)
+	}
+	d, err := clientset.AppsV1().Deployments("shop").Get(ctx, "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Annotations[AnnotationMonthlyCost] != "12.50" {
+		t.Errorf("annotations = %v", d.Annotations)
+	}
+	if _, ok := d.Annotations[AnnotationGrade]; ok {
+		t.Errorf("empty value should remove the annotation: %v", d.Annotations)
+	}
+
+	err = client.AnnotateWorkload(ctx, "shop", "Deployment", "missing", map[string]string{AnnotationGrade: "A"})
+	if !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("missing workload: err = %v, want NotFound", err)
+	}
+	if err := client.AnnotateWorkload(ctx, "shop", "Pod", "api", nil); !errors.Is(err, dataerr.ErrValidation) {
+		t.Errorf("unsupported kind: err = %v, want Validation", err)
+	}
+
+	readOnly, clientset := newFakeCluster(ClusterConfig{ReadOnly: true})
+	err = readOnly.AnnotateWorkload(ctx, "shop", "Deployment", "api", map[string]string{AnnotationGrade: "A"})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("read-only: err = %v, want ErrReadOnly", err)
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Error("read-only client must not send the patch")
+		}
+	}
+}
+
+func TestAPIError_Classification(t *testing.T) {
+	gr := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		err       error
+		kind      error
+		retryable bool
+	}{
+		{apierrors.NewNotFound(gr, "x"), dataerr.ErrNotFound, false},
+		{apierrors.NewConflict(gr, "x", errors.New("stale")), dataerr.ErrConflict, false},
+		{apierrors.NewBadRequest("bad"), dataerr.ErrValidation, false},
+		{apierrors.NewTooManyRequests("slow down", 1), dataerr.ErrUnavailable, true},
+		{apierrors.NewServiceUnavailable("down"), dataerr.ErrUnavailable, true},
+		{apierrors.NewForbidden(gr, "x", errors.New("rbac")), nil, false},
+	}
+	for _, tt := range tests {
+		err := &APIError{Op: "list pods", Err: tt.err}
+		if got := dataerr.Kind(err); got != tt.kind {
+			t.Errorf("Kind(%v) = %v, want %v", tt.err, got, tt.kind)
+		}
+		if got := retry.IsRetryable(err); got != tt.retryable {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.retryable)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%v: the API server error must stay reachable through Unwrap", tt.err)
+		}
+	}
+}
+
+func TestClusterConfig_RESTConfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster: {server: "https://prod.example.com:6443"}
+- name: staging
+  cluster: {server: "https://staging.example.com:6443"}
+users:
+- name: admin
+  user: {token: "secret"}
+contexts:
+- name: prod
+  context: {cluster: prod, user: admin}
+- name: staging
+  context: {cluster: staging, user: admin}
+current-context: prod
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := ClusterConfig{Kubeconfig: kubeconfig}.RESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Host != "https://prod.example.com:6443" || rc.BearerToken != "secret" {
+		t.Errorf("current context: host %q token %q", rc.Host, rc.BearerToken)
+	}
+
+	rc, err = ClusterConfig{Kubeconfig: kubeconfig, Context: "staging", BearerTokenFile: "/var/run/token"}.RESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Host != "https://staging.example.com:6443" || rc.BearerToken != "" || rc.BearerTokenFile != "/var/run/token" {
+		t.Errorf("context override: host %q token %q file %q", rc.Host, rc.BearerToken, rc.BearerTokenFile)
+	}
+
+	rc, err = ClusterConfig{Kubeconfig: kubeconfig, APIServer: "https://lb.example.com"}.RESTConfig()
+	if err != nil || rc.Host != "https://lb.example.com" {
+		t.Errorf("api server override: %v, %v", rc, err)
+	}
+
+	if _, err := (ClusterConfig{Kubeconfig: filepath.Join(t.TempDir(), "missing")}).RESTConfig(); err == nil {
+		t.Error("expected an error for a missing kubeconfig")
+	}
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	if _, err := (ClusterConfig{InCluster: true}).RESTConfig(); err == nil {
+		t.Error("expected an error outside a cluster")
+	}
+}
+
+type recordingTransport struct{ calls int }
+
+func (t *recordingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	t.calls++
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func TestReadOnlyTransport(t *testing.T) {
+	next := &recordingTransport{}
+	rt := readOnlyTransport{next}
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		req, _ := http.NewRequest(method, "https://k8s/api/v1/pods", nil)
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Errorf("%s: %v", method, err)
+		}
+	}
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		req, _ := http.NewRequest(method, "https://k8s/apis/apps/v1/namespaces/shop/deployments/api", nil)
+		if _, err := rt.RoundTrip(req); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: err = %v, want ErrReadOnly", method, err)
+		}
+	}
+	if next.calls != 2 {
+		t.Errorf("forwarded %d requests, want 2", next.calls)
+	}
+}
@@ -0,0 +1,154 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WorkloadMetadata is the descriptive metadata of a namespace or workload joined onto cost results,
+// read from well-known labels (and annotations as a fallback).
+type WorkloadMetadata struct {
+	App         string `json:"app,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Tier        string `json:"tier,omitempty"`
+	Environment string `json:"environment,omitempty"`
+}
+
+// Metadata keys in order of precedence; labels are checked before annotations.
+var (
+	appKeys         = []string{"app.kubernetes.io/name", "app"}
+	ownerKeys       = []string{"lighthouse.io/owner", "owner", "team"}
+	tierKeys        = []string{"tier", "app.kubernetes.io/component"}
+	environmentKeys = []string{"environment", "env"}
+)
+
+// ParseWorkloadMetadata reads the metadata of an object from its labels and annotations.
+func ParseWorkloadMetadata(labels, annotations map[string]string) WorkloadMetadata {
+	get := func(keys []string) string {
+		for _, m := range []map[string]string{labels, annotations} {
+			for _, k := range keys {
+				if v := m[k]; v != "" {
+					return v
+				}
+			}
+		}
+		return ""
+	}
+	return WorkloadMetadata{
+		App:         get(appKeys),
+		Owner:       get(ownerKeys),
+		Tier:        get(tierKeys),
+		Environment: get(environmentKeys),
+	}
+}
+
+// Merge returns m with the empty fields taken from defaults (e.g. the namespace metadata).
+func (m WorkloadMetadata) Merge(defaults WorkloadMetadata) WorkloadMetadata {
+	if m.App == "" {
+		m.App = defaults.App
+	}
+	if m.Owner == "" {
+		m.Owner = defaults.Owner
+	}
+	if m.Tier == "" {
+		m.Tier = defaults.Tier
+	}
+	if m.Environment == "" {
+		m.Environment = defaults.Environment
+	}
+	return m
+}
+
+// IsZero reports whether no metadata was found.
+func (m WorkloadMetadata) IsZero() bool {
+	return m == WorkloadMetadata{}
+}
+
+// DefaultMetadataCacheTTL is how long namespace and deployment metadata is reused before K8s is read again.
+const DefaultMetadataCacheTTL = 5 * time.Minute
+
+// MetadataResolver reads namespace and workload metadata from K8s at query time and caches it, so
+// cost queries are enriched without a K8s round trip per request. Errors are not cached. Safe for
+// concurrent use.
+type MetadataResolver struct {
+	client Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu         sync.Mutex
+	namespaces *metadataEntry
+	workloads  map[string]*metadataEntry // by namespace
+}
+
+type metadataEntry struct {
+	at       time.Time
+	metadata map[string]WorkloadMetadata
+}
+
+// NewMetadataResolver creates a MetadataResolver with DefaultMetadataCacheTTL.
+func NewMetadataResolver(client Client) *MetadataResolver {
+	return &MetadataResolver{client: client, ttl: DefaultMetadataCacheTTL, now: time.Now, workloads: make(map[string]*metadataEntry)}
+}
+
+// SetCacheTTL sets how long metadata is reused; <= 0 reads K8s on every call.
+func (r *MetadataResolver) SetCacheTTL(ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ttl = ttl
+}
+
+// NamespaceMetadata returns the metadata of every namespace by name. The returned map is shared and
+// must not be modified.
+func (r *MetadataResolver) NamespaceMetadata(ctx context.Context) (map[string]WorkloadMetadata, error) {
+	if m, ok := r.cached(func() *metadataEntry { return r.namespaces }); ok {
+		return m, nil
+	}
+	namespaces, err := r.client.GetNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]WorkloadMetadata, len(namespaces))
+	for _, ns := range namespaces {
+		out[ns.Name] = ParseWorkloadMetadata(ns.Labels, ns.Annotations)
+	}
+	r.mu.Lock()
+	r.namespaces = &metadataEntry{at: r.now(), metadata: out}
+	r.mu.Unlock()
+	return out, nil
+}
+
+// WorkloadMetadata returns the metadata of every deployment in namespace by name; fields a deployment
+// does not set are inherited from the namespace. The returned map is shared and must not be modified.
+func (r *MetadataResolver) WorkloadMetadata(ctx context.Context, namespace string) (map[string]WorkloadMetadata, error) {
+	if m, ok := r.cached(func() *metadataEntry { return r.workloads[namespace] }); ok {
+		return m, nil
+	}
+	namespaces, err := r.NamespaceMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := r.client.GetDeployments(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]WorkloadMetadata, len(deployments))
+	for _, d := range deployments {
+		out[d.Name] = ParseWorkloadMetadata(d.Labels, d.Annotations).Merge(namespaces[namespace])
+	}
+	r.mu.Lock()
+	r.workloads[namespace] = &metadataEntry{at: r.now(), metadata: out}
+	r.mu.Unlock()
+	return out, nil
+}
+
+// cached returns the metadata of the entry returned by get (called with r.mu held) if it is fresh.
+func (r *MetadataResolver) cached(get func() *metadataEntry) (map[string]WorkloadMetadata, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := get()
+	if e == nil || r.ttl <= 0 || r.now().Sub(e.at) >= r.ttl {
+		return nil, false
+	}
+	return e.metadata, true
+}
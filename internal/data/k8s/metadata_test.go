@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// countingClient counts the list calls reaching the wrapped client.
+type countingClient struct {
+	Client
+	namespaces, deployments int
+}
+
+func (c *countingClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
+	c.namespaces++
+	return c.Client.GetNamespaces(ctx)
+}
+
+func (c *countingClient) GetDeployments(ctx context.Context, namespace string) ([]Deployment, error) {
+	c.deployments++
+	return c.Client.GetDeployments(ctx, namespace)
+}
+
+func TestParseWorkloadMetadata(t *testing.T) {
+	got := ParseWorkloadMetadata(
+		map[string]string{"app": "checkout", "app.kubernetes.io/name": "checkout-api", "env": "prod"},
+		map[string]string{"lighthouse.io/owner": "team-pay", "tier": "backend"},
+	)
+	want := WorkloadMetadata{App: "checkout-api", Owner: "team-pay", Tier: "backend", Environment: "prod"}
+	if got != want {
+		t.Errorf("ParseWorkloadMetadata = %+v, want %+v", got, want)
+	}
+	if merged := (WorkloadMetadata{Tier: "frontend"}).Merge(want); merged.Tier != "frontend" || merged.Owner != "team-pay" {
+		t.Errorf("Merge = %+v", merged)
+	}
+}
+
+func TestMetadataResolver(t *testing.T) {
+	cfg := DefaultMockConfig()
+	cfg.LatencyMs = 0
+	cfg.Namespaces = []string{"payments"}
+	cfg.Annotations = map[string]map[string]string{
+		"payments":              {"lighthouse.io/owner": "team-pay"},
+		"payments-deployment-1": {"tier": "backend", "lighthouse.io/owner": "team-core"},
+	}
+	client := &countingClient{Client: NewMockClient(cfg)}
+	r := NewMetadataResolver(client)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	workloads, err := r.WorkloadMetadata(ctx, "payments")
+	if err != nil {
+		t.Fatalf("WorkloadMetadata: %v", err)
+	}
+	if m := workloads["payments-deployment-1"]; m.Owner != "team-core" || m.Tier != "backend" || m.App != "payments-deployment-1" || m.Environment == "" {
+		t.Errorf("deployment-1 metadata = %+v", m)
+	}
+	// 工作负载未设置的字段继承 namespace
+	if m := workloads["payments-deployment-2"]; m.Owner != "team-pay" {
+		t.Errorf("deployment-2 owner = %q, want team-pay from the namespace", m.Owner)
+	}
+
+	if _, err := r.NamespaceMetadata(ctx); err != nil {
+		t.Fatalf("NamespaceMetadata: %v", err)
+	}
+	if _, err := r.WorkloadMetadata(ctx, "payments"); err != nil {
+		t.Fatalf("WorkloadMetadata: %v", err)
+	}
+	if client.namespaces != 1 || client.deployments != 1 {
+		t.Errorf("cached reads hit K8s: %d namespace, %d deployment lists", client.namespaces, client.deployments)
+	}
+
+	now = now.Add(DefaultMetadataCacheTTL)
+	if _, err := r.WorkloadMetadata(ctx, "payments"); err != nil {
+		t.Fatalf("WorkloadMetadata: %v", err)
+	}
+	if client.namespaces != 2 || client.deployments != 2 {
+		t.Errorf("expired cache not refreshed: %d namespace, %d deployment lists", client.namespaces, client.deployments)
+	}
+}
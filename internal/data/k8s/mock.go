@@ -6,7 +6,13 @@ import (
 	"fmt"
 	"math/rand"
 	"strconv"
+	"sync"
 	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/latency"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockid"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockrand"
 )
 
 // MockConfig defines configuration options for the mock K8s client.
@@ -40,6 +46,26 @@ type MockConfig struct {
 
 	// LatencyMs simulates network latency in milliseconds
 	LatencyMs int `json:"latency_ms"`
+
+	// LatencyJitterMs adds a uniform random 0..LatencyJitterMs to LatencyMs
+	LatencyJitterMs int `json:"latency_jitter_ms"`
+
+	// TailLatencyRate is the probability (0.0 - 1.0) of a long-tail call, which waits an extra
+	// Pareto-distributed TailLatencyMs..20×TailLatencyMs (see latency.Profile)
+	TailLatencyRate float64 `json:"tail_latency_rate"`
+	TailLatencyMs   int     `json:"tail_latency_ms"`
+
+	// Annotations adds annotations by resource name (namespace or deployment name),
+	// e.g. cost policy annotations for demos and tests
+	Annotations map[string]map[string]string `json:"annotations,omitempty"`
+
+	// DisruptionBudgets are the PodDisruptionBudgets returned by GetPodDisruptionBudgets, for
+	// demos and tests of the node consolidation planner
+	DisruptionBudgets []PodDisruptionBudget `json:"disruption_budgets,omitempty"`
+
+	// IDMode selects how object UIDs are generated (see mockid): "random" (default) or "deterministic"
+	// for seeded UUIDv5 IDs that are stable across runs
+	IDMode string `json:"id_mode,omitempty"`
 }
 
 // DefaultMockConfig returns a default configuration for mock data generation.
@@ -60,8 +86,14 @@ func DefaultMockConfig() MockConfig {
 
 // MockClient is a mock implementation of the K8s Client interface.
 type MockClient struct {
-	config MockConfig
-	rand   *rand.Rand
+	config  MockConfig
+	rand    *rand.Rand
+	latency *latency.Simulator
+	ids     *mockid.Generator
+
+	// mu guards written, the annotations set with AnnotateWorkload by workload name
+	mu      sync.RWMutex
+	written map[string]map[string]string
 }
 
 // NewMockClient creates a new mock K8s client with the given configuration.
@@ -69,20 +101,24 @@ func NewMockClient(config MockConfig) *MockClient {
 	if config.RandomSeed == 0 {
 		config.RandomSeed = time.Now().UnixNano()
 	}
+	r := mockrand.New(config.RandomSeed)
 	return &MockClient{
-		config: config,
-		rand:   rand.New(rand.NewSource(config.RandomSeed)),
+		config:  config,
+		rand:    r,
+		latency: latency.New(latency.Millis(config.LatencyMs, config.LatencyJitterMs, config.TailLatencyRate, config.TailLatencyMs), config.RandomSeed),
+		ids:     mockid.New(config.IDMode, config.RandomSeed, r),
+		written: make(map[string]map[string]string),
 	}
 }
 
 // GetNamespaces retrieves mock namespaces.
 func (m *MockClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock K8s error: cannot get namespaces")
+		return nil, dataerr.Unavailable("mock K8s error: cannot get namespaces")
 	}
 
 	if m.config.Scenario == "empty" {
@@ -106,12 +142,12 @@ func (m *MockClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
 
 // GetDeployments retrieves mock deployments for a namespace.
 func (m *MockClient) GetDeployments(ctx context.Context, namespace string) ([]Deployment, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock K8s error: cannot get deployments for namespace %s", namespace)
+		return nil, dataerr.Unavailable("mock K8s error: cannot get deployments for namespace %s", namespace)
 	}
 
 	if m.config.Scenario == "empty" {
@@ -157,12 +193,12 @@ func (m *MockClient) GetDeployments(ctx context.Context, namespace string) ([]De
 
 // GetPods retrieves mock pods for a namespace or deployment.
 func (m *MockClient) GetPods(ctx context.Context, namespace, deployment string) ([]Pod, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock K8s error: cannot get pods for namespace %s", namespace)
+		return nil, dataerr.Unavailable("mock K8s error: cannot get pods for namespace %s", namespace)
 	}
 
 	if m.config.Scenario == "empty" {
@@ -209,12 +245,12 @@ func (m *MockClient) GetPods(ctx context.Context, namespace, deployment string)
 
 // GetNodes retrieves mock cluster nodes.
 func (m *MockClient) GetNodes(ctx context.Context) ([]Node, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock K8s error: cannot get nodes")
+		return nil, dataerr.Unavailable("mock K8s error: cannot get nodes")
 	}
 
 	if m.config.Scenario == "empty" {
@@ -241,12 +277,12 @@ func (m *MockClient) GetNodes(ctx context.Context) ([]Node, error) {
 
 // GetEvents retrieves mock events for a namespace or resource.
 func (m *MockClient) GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]Event, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock K8s error: cannot get events")
+		return nil, dataerr.Unavailable("mock K8s error: cannot get events")
 	}
 
 	if m.config.Scenario == "empty" {
@@ -263,13 +299,15 @@ func (m *MockClient) GetEvents(ctx context.Context, namespace, resourceType, res
 
 		if m.config.Scenario == "chaos" && m.rand.Float64() > 0.6 {
 			eventType = "Warning"
-			reasons := []string{"FailedScheduling", "FailedMount", "FailedPull", "CrashLoopBackOff"}
+			reasons := []string{"FailedScheduling", "FailedMount", "FailedPull", "CrashLoopBackOff", "Evicted", "OOMKilling"}
 			reason = reasons[m.rand.Intn(len(reasons))]
 			messages := []string{
 				"0/4 nodes are available: 4 node(s) had taint {node.kubernetes.io/not-ready: }",
 				"MountVolume.SetUp failed for volume",
 				"Failed to pull image",
 				"Back-off restarting failed container",
+				"The node was low on resource: memory",
+				"Memory cgroup out of memory: Killed process",
 			}
 			message = messages[m.rand.Intn(len(messages))]
 		}
@@ -289,7 +327,7 @@ func (m *MockClient) GetEvents(ctx context.Context, namespace, resourceType, res
 				Kind:      resourceType,
 				Namespace: namespace,
 				Name:      resourceName,
-				UID:       fmt.Sprintf("uid-%s-%d", resourceName, i+1),
+				UID:       m.objectUID(resourceType, namespace, resourceName, i+1),
 			},
 		}
 		events = append(events, event)
@@ -300,12 +338,12 @@ func (m *MockClient) GetEvents(ctx context.Context, namespace, resourceType, res
 
 // GetResourceQuotas retrieves mock resource quotas for a namespace.
 func (m *MockClient) GetResourceQuotas(ctx context.Context, namespace string) ([]ResourceQuota, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock K8s error: cannot get resource quotas")
+		return nil, dataerr.Unavailable("mock K8s error: cannot get resource quotas")
 	}
 
 	if m.config.Scenario == "empty" {
@@ -347,23 +385,67 @@ func (m *MockClient) GetResourceQuotas(ctx context.Context, namespace string) ([
 	return quotas, nil
 }
 
+// GetPodDisruptionBudgets returns the configured budgets of the namespace ("" for all).
+func (m *MockClient) GetPodDisruptionBudgets(ctx context.Context, namespace string) ([]PodDisruptionBudget, error) {
+	if err := m.simulateLatency(ctx); err != nil {
+		return nil, err
+	}
+
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock K8s error: cannot get pod disruption budgets")
+	}
+
+	budgets := []PodDisruptionBudget{}
+	for _, b := range m.config.DisruptionBudgets {
+		if namespace == "" || b.Namespace == namespace {
+			budgets = append(budgets, b)
+		}
+	}
+	return budgets, nil
+}
+
 // HealthCheck always returns nil (healthy) for mock client.
 func (m *MockClient) HealthCheck(ctx context.Context) error {
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock K8s health check failed")
+		return dataerr.Unavailable("mock K8s health check failed")
 	}
 	return nil
 }
 
 // Helper methods
 
-func (m *MockClient) simulateLatency() error {
-	if m.config.LatencyMs > 0 {
-		time.Sleep(time.Duration(m.config.LatencyMs) * time.Millisecond)
+// AnnotateWorkload 记录写回的注解，之后按名称生成的 deployment / pod 会带上这些注解（namespace 与 kind 不参与匹配）。
+func (m *MockClient) AnnotateWorkload(ctx context.Context, namespace, kind, name string, annotations map[string]string) error {
+	if err := m.simulateLatency(ctx); err != nil {
+		return err
+	}
+
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock K8s error: cannot annotate %s %s/%s", kind, namespace, name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.written[name]
+	if !ok {
+		current = make(map[string]string, len(annotations))
+		m.written[name] = current
+	}
+	for k, v := range annotations {
+		if v == "" {
+			delete(current, k)
+			continue
+		}
+		current[k] = v
 	}
 	return nil
 }
 
+// simulateLatency waits out the configured latency, returning ctx.Err() early once ctx is done.
+func (m *MockClient) simulateLatency(ctx context.Context) error {
+	return m.latency.Wait(ctx)
+}
+
 func (m *MockClient) shouldReturnError() bool {
 	if m.config.ErrorRate <= 0.0 {
 		return false
@@ -432,6 +514,14 @@ func (m *MockClient) generateAnnotations(resourceType, name string) map[string]s
 	if resourceType == "deployment" || resourceType == "pod" {
 		annotations["description"] = fmt.Sprintf("Mock %s for testing", resourceType)
 	}
+	for k, v := range m.config.Annotations[name] {
+		annotations[k] = v
+	}
+	m.mu.RLock()
+	for k, v := range m.written[name] {
+		annotations[k] = v
+	}
+	m.mu.RUnlock()
 
 	return annotations
 }
@@ -555,3 +645,12 @@ func (m *MockClient) parseResource(resourceStr string) int {
 func (m *MockClient) randomChoice(choices []string) string {
 	return choices[m.rand.Intn(len(choices))]
 }
+
+// objectUID returns the UID of a mock object: "uid-<name>-<index>" by default, or a UUIDv5 of its
+// kind, namespace and name in deterministic ID mode.
+func (m *MockClient) objectUID(kind, namespace, name string, index int) string {
+	if m.ids.Deterministic() {
+		return m.ids.Name("uid", kind, namespace, name)
+	}
+	return fmt.Sprintf("uid-%s-%d", name, index)
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -283,6 +284,39 @@ func TestMockClient_GetResourceQuotas(t *testing.T) {
 	}
 }
 
+func TestMockClient_GetPodDisruptionBudgets(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	config.DisruptionBudgets = []PodDisruptionBudget{
+		{Name: "api", Namespace: "app-prod", Selector: map[string]string{"app": "api"}, MinAvailable: "1", DisruptionsAllowed: 1},
+		{Name: "db", Namespace: "app-staging", Selector: map[string]string{"app": "db"}, MaxUnavailable: "0"},
+	}
+	client := NewMockClient(config)
+
+	budgets, err := client.GetPodDisruptionBudgets(ctx, "app-prod")
+	if err != nil {
+		t.Fatalf("GetPodDisruptionBudgets failed: %v", err)
+	}
+	if len(budgets) != 1 || budgets[0].Name != "api" {
+		t.Fatalf("Expected the app-prod budget, got %+v", budgets)
+	}
+	if all, _ := client.GetPodDisruptionBudgets(ctx, ""); len(all) != 2 {
+		t.Errorf("Expected 2 budgets in all namespaces, got %d", len(all))
+	}
+
+	b := budgets[0]
+	if !b.Covers("app-prod", map[string]string{"app": "api", "version": "v2"}) {
+		t.Error("Expected the budget to cover a pod with its labels")
+	}
+	if b.Covers("app-staging", map[string]string{"app": "api"}) || b.Covers("app-prod", map[string]string{"app": "web"}) {
+		t.Error("Expected the budget not to cover pods of other namespaces or labels")
+	}
+	if (PodDisruptionBudget{Namespace: "app-prod"}).Covers("app-prod", map[string]string{"app": "api"}) {
+		t.Error("Expected an empty selector to cover no pods")
+	}
+}
+
 func TestMockClient_HealthCheck(t *testing.T) {
 	ctx := context.Background()
 	client := NewMockClient(DefaultMockConfig())
@@ -477,3 +511,33 @@ func TestMockClient_WithLatency(t *testing.T) {
 		t.Errorf("Expected at least 20ms latency, got %v", elapsed)
 	}
 }
+
+func TestMockClient_TailLatency(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	plain := NewMockClient(config)
+	config.TailLatencyRate = 1
+	config.TailLatencyMs = 1000
+	slow := NewMockClient(config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := slow.GetNamespaces(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded on a long-tail call, got %v", err)
+	}
+
+	// 延迟采样不消耗数据生成的随机源，开启延迟参数不改变生成的数据
+	want, err := plain.GetDeployments(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("GetDeployments failed: %v", err)
+	}
+	config.TailLatencyRate = 0
+	config.LatencyJitterMs = 2
+	got, err := NewMockClient(config).GetDeployments(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("GetDeployments failed: %v", err)
+	}
+	if len(got) != len(want) || got[0].Name != want[0].Name || got[0].Replicas != want[0].Replicas {
+		t.Errorf("latency knobs changed generated data: %+v vs %+v", got, want)
+	}
+}
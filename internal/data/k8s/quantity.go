@@ -0,0 +1,92 @@
+// Package k8s provides client implementations for interacting with Kubernetes API.
+package k8s
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// binarySuffixes maps Kubernetes binary quantity suffixes to their byte multipliers.
+var binarySuffixes = map[string]float64{
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+}
+
+// decimalSuffixes maps Kubernetes decimal quantity suffixes to their multipliers.
+var decimalSuffixes = map[string]float64{
+	"m": 1e-3,
+	"k": 1e3,
+	"M": 1e6,
+	"G": 1e9,
+	"T": 1e12,
+	"P": 1e15,
+}
+
+// ParseQuantity parses a Kubernetes resource quantity string (e.g. "500m", "8", "32Gi")
+// into its base unit value (cores for CPU, bytes for memory).
+func ParseQuantity(quantity string) (float64, error) {
+	q := strings.TrimSpace(quantity)
+	if q == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+
+	if len(q) > 2 {
+		if mult, ok := binarySuffixes[q[len(q)-2:]]; ok {
+			v, err := strconv.ParseFloat(q[:len(q)-2], 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid quantity %q: %w", quantity, err)
+			}
+			return v * mult, nil
+		}
+	}
+	if mult, ok := decimalSuffixes[q[len(q)-1:]]; ok {
+		v, err := strconv.ParseFloat(q[:len(q)-1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid quantity %q: %w", quantity, err)
+		}
+		return v * mult, nil
+	}
+
+	v, err := strconv.ParseFloat(q, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q: %w", quantity, err)
+	}
+	return v, nil
+}
+
+// ParseCPUCores parses a CPU quantity (e.g. "7500m", "8") into cores.
+func ParseCPUCores(quantity string) (float64, error) {
+	return ParseQuantity(quantity)
+}
+
+// ParseMemoryGiB parses a memory quantity (e.g. "32Gi", "33554432Ki") into GiB.
+func ParseMemoryGiB(quantity string) (float64, error) {
+	bytes, err := ParseQuantity(quantity)
+	if err != nil {
+		return 0, err
+	}
+	return bytes / (1 << 30), nil
+}
+
+// FormatCPU formats cores as a CPU quantity, rounded up to the millicore ("250m", whole cores as "2").
+func FormatCPU(cores float64) string {
+	milli := int64(math.Ceil(cores*1000 - 1e-6))
+	if milli%1000 == 0 {
+		return strconv.FormatInt(milli/1000, 10)
+	}
+	return strconv.FormatInt(milli, 10) + "m"
+}
+
+// FormatMemory formats bytes as a memory quantity, rounded up to the MiB ("384Mi", whole GiB as "2Gi").
+func FormatMemory(bytes float64) string {
+	mib := int64(math.Ceil(bytes/(1<<20) - 1e-6))
+	if mib%1024 == 0 && mib > 0 {
+		return strconv.FormatInt(mib/1024, 10) + "Gi"
+	}
+	return strconv.FormatInt(mib, 10) + "Mi"
+}
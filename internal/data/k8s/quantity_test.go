@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected float64
+		wantErr  bool
+	}{
+		{"plain cores", "8", 8, false},
+		{"fractional cores", "7.5", 7.5, false},
+		{"millicores", "500m", 0.5, false},
+		{"gibibytes", "32Gi", 32 * (1 << 30), false},
+		{"mebibytes", "512Mi", 512 * (1 << 20), false},
+		{"kibibytes", "1024Ki", 1024 * (1 << 10), false},
+		{"decimal gigabytes", "1G", 1e9, false},
+		{"empty", "", 0, true},
+		{"garbage", "abcGi", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuantity(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuantity(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("ParseQuantity(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseMemoryGiB(t *testing.T) {
+	got, err := ParseMemoryGiB("33554432Ki")
+	if err != nil {
+		t.Fatalf("ParseMemoryGiB failed: %v", err)
+	}
+	if got != 32 {
+		t.Errorf("Expected 32 GiB, got %v", got)
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	for cores, want := range map[float64]string{0.25: "250m", 2: "2", 0.1234: "124m", 0: "0"} {
+		if got := FormatCPU(cores); got != want {
+			t.Errorf("FormatCPU(%v) = %q, want %q", cores, got, want)
+		}
+	}
+	for bytes, want := range map[float64]string{384 << 20: "384Mi", 2 << 30: "2Gi", 1e8: "96Mi", 0: "0Mi"} {
+		if got := FormatMemory(bytes); got != want {
+			t.Errorf("FormatMemory(%v) = %q, want %q", bytes, got, want)
+		}
+	}
+	if v, _ := ParseQuantity(FormatCPU(1.5)); v != 1.5 {
+		t.Errorf("FormatCPU does not round-trip: %v", v)
+	}
+}
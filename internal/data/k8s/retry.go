@@ -0,0 +1,113 @@
+// Package k8s retry.go: retry wrapper so transient API server failures (timeouts, 5xx, throttling)
+// are retried with backoff instead of failing the request.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/retry"
+)
+
+// RetryClient wraps a Client with a retrier. HealthCheck is not retried so readiness probes
+// report the current state. Wrap it inside BreakerClient, not around it.
+type RetryClient struct {
+	client  Client
+	retrier *retry.Retrier
+}
+
+// NewRetryClient wraps client with retrier.
+func NewRetryClient(client Client, retrier *retry.Retrier) *RetryClient {
+	return &RetryClient{client: client, retrier: retrier}
+}
+
+// GetNamespaces implements Client.
+func (c *RetryClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
+	var out []Namespace
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetNamespaces(ctx)
+		return err
+	})
+	return out, err
+}
+
+// GetDeployments implements Client.
+func (c *RetryClient) GetDeployments(ctx context.Context, namespace string) ([]Deployment, error) {
+	var out []Deployment
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetDeployments(ctx, namespace)
+		return err
+	})
+	return out, err
+}
+
+// GetPods implements Client.
+func (c *RetryClient) GetPods(ctx context.Context, namespace, deployment string) ([]Pod, error) {
+	var out []Pod
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetPods(ctx, namespace, deployment)
+		return err
+	})
+	return out, err
+}
+
+// GetNodes implements Client.
+func (c *RetryClient) GetNodes(ctx context.Context) ([]Node, error) {
+	var out []Node
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetNodes(ctx)
+		return err
+	})
+	return out, err
+}
+
+// GetEvents implements Client.
+func (c *RetryClient) GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]Event, error) {
+	var out []Event
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetEvents(ctx, namespace, resourceType, resourceName)
+		return err
+	})
+	return out, err
+}
+
+// GetResourceQuotas implements Client.
+func (c *RetryClient) GetResourceQuotas(ctx context.Context, namespace string) ([]ResourceQuota, error) {
+	var out []ResourceQuota
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetResourceQuotas(ctx, namespace)
+		return err
+	})
+	return out, err
+}
+
+// AnnotateWorkload implements WorkloadAnnotator when the wrapped client does. Annotation patches
+// are merges, so retrying one is safe.
+func (c *RetryClient) AnnotateWorkload(ctx context.Context, namespace, kind, name string, annotations map[string]string) error {
+	annotator, ok := c.client.(WorkloadAnnotator)
+	if !ok {
+		return fmt.Errorf("k8s client %T cannot annotate workloads", c.client)
+	}
+	return c.retrier.Do(ctx, func() error {
+		return annotator.AnnotateWorkload(ctx, namespace, kind, name, annotations)
+	})
+}
+
+// GetPodDisruptionBudgets implements DisruptionBudgetLister when the wrapped client does.
+func (c *RetryClient) GetPodDisruptionBudgets(ctx context.Context, namespace string) ([]PodDisruptionBudget, error) {
+	lister, ok := c.client.(DisruptionBudgetLister)
+	if !ok {
+		return nil, fmt.Errorf("k8s client %T cannot list pod disruption budgets", c.client)
+	}
+	var out []PodDisruptionBudget
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = lister.GetPodDisruptionBudgets(ctx, namespace)
+		return err
+	})
+	return out, err
+}
+
+// HealthCheck implements Client without retrying.
+func (c *RetryClient) HealthCheck(ctx context.Context) error {
+	return c.client.HealthCheck(ctx)
+}
@@ -0,0 +1,295 @@
+// Package kubecost reads cost allocation exports of Kubecost and OpenCost: allocation API responses
+// (GET /model/allocation, JSON) and allocation CSV exports. Both tools share the allocation model,
+// so one parser serves teams migrating from either.
+package kubecost
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// ErrInvalidExport is returned for an export that cannot be parsed.
+var ErrInvalidExport = dataerr.Validation("invalid allocation export")
+
+// Allocation is the cost of one aggregate (namespace, controller, pod, ...) over one window.
+type Allocation struct {
+	Name           string
+	Namespace      string
+	Controller     string // empty unless aggregated by controller or finer
+	ControllerKind string // as exported, e.g. "deployment"
+	Pod            string
+	Node           string
+	Start          time.Time
+	End            time.Time
+
+	CPUCoreRequestAverage float64
+	CPUCoreUsageAverage   float64
+	CPUCost               float64
+	RAMByteRequestAverage float64
+	RAMByteUsageAverage   float64
+	RAMCost               float64
+	GPUCost               float64
+	PVCost                float64
+	NetworkCost           float64
+	LoadBalancerCost      float64
+	SharedCost            float64
+	TotalCost             float64
+}
+
+// Unallocated reports whether a is one of the synthetic aggregates (__idle__, __unallocated__,
+// __unmounted__) that belong to no namespace.
+func (a Allocation) Unallocated() bool {
+	return strings.HasPrefix(a.Name, "__") || strings.HasPrefix(a.Namespace, "__")
+}
+
+// OtherCost is the cost outside CPU and memory: GPU, volumes, network and load balancers.
+func (a Allocation) OtherCost() float64 {
+	return a.GPUCost + a.PVCost + a.NetworkCost + a.LoadBalancerCost
+}
+
+// apiAllocation is an allocation of the allocation API.
+type apiAllocation struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Namespace      string `json:"namespace"`
+		Controller     string `json:"controller"`
+		ControllerKind string `json:"controllerKind"`
+		Pod            string `json:"pod"`
+		Node           string `json:"node"`
+	} `json:"properties"`
+	Window struct {
+		Start *time.Time `json:"start"`
+		End   *time.Time `json:"end"`
+	} `json:"window"`
+	Start                 *time.Time `json:"start"`
+	End                   *time.Time `json:"end"`
+	CPUCoreRequestAverage float64    `json:"cpuCoreRequestAverage"`
+	CPUCoreUsageAverage   float64    `json:"cpuCoreUsageAverage"`
+	CPUCost               float64    `json:"cpuCost"`
+	RAMByteRequestAverage float64    `json:"ramByteRequestAverage"`
+	RAMByteUsageAverage   float64    `json:"ramByteUsageAverage"`
+	RAMCost               float64    `json:"ramCost"`
+	GPUCost               float64    `json:"gpuCost"`
+	PVCost                float64    `json:"pvCost"`
+	NetworkCost           float64    `json:"networkCost"`
+	LoadBalancerCost      float64    `json:"loadBalancerCost"`
+	SharedCost            float64    `json:"sharedCost"`
+	TotalCost             float64    `json:"totalCost"`
+}
+
+// ParseAllocationJSON parses an allocation API response: {"code": 200, "data": [set, ...]} where
+// each set maps aggregate names to allocations (one set per step), or the bare list of sets.
+func ParseAllocationJSON(r io.Reader) ([]Allocation, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read allocation export: %w", err)
+	}
+	var sets []map[string]*apiAllocation
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(raw, &sets)
+	} else {
+		var resp struct {
+			Code    int                         `json:"code"`
+			Message string                      `json:"message"`
+			Data    []map[string]*apiAllocation `json:"data"`
+		}
+		err = json.Unmarshal(raw, &resp)
+		if err == nil && resp.Code != 0 && resp.Code != 200 {
+			return nil, fmt.Errorf("%w: response code %d: %s", ErrInvalidExport, resp.Code, resp.Message)
+		}
+		sets = resp.Data
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+
+	var out []Allocation
+	for i, set := range sets {
+		names := make([]string, 0, len(set))
+		for name := range set {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			src := set[name]
+			if src == nil {
+				continue
+			}
+			a := Allocation{
+				Name:                  src.Name,
+				Namespace:             src.Properties.Namespace,
+				Controller:            src.Properties.Controller,
+				ControllerKind:        src.Properties.ControllerKind,
+				Pod:                   src.Properties.Pod,
+				Node:                  src.Properties.Node,
+				CPUCoreRequestAverage: src.CPUCoreRequestAverage,
+				CPUCoreUsageAverage:   src.CPUCoreUsageAverage,
+				CPUCost:               src.CPUCost,
+				RAMByteRequestAverage: src.RAMByteRequestAverage,
+				RAMByteUsageAverage:   src.RAMByteUsageAverage,
+				RAMCost:               src.RAMCost,
+				GPUCost:               src.GPUCost,
+				PVCost:                src.PVCost,
+				NetworkCost:           src.NetworkCost,
+				LoadBalancerCost:      src.LoadBalancerCost,
+				SharedCost:            src.SharedCost,
+				TotalCost:             src.TotalCost,
+			}
+			if a.Name == "" {
+				a.Name = name
+			}
+			switch {
+			case src.Start != nil && src.End != nil:
+				a.Start, a.End = *src.Start, *src.End
+			case src.Window.Start != nil && src.Window.End != nil:
+				a.Start, a.End = *src.Window.Start, *src.Window.End
+			}
+			if err := a.normalize(); err != nil {
+				return nil, fmt.Errorf("%w: set %d, %s: %v", ErrInvalidExport, i, name, err)
+			}
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// csvColumns maps normalized CSV headers (lower case, letters and digits only) to the fields they fill.
+var csvColumns = map[string]func(a *Allocation, v string) error{
+	"name":                  func(a *Allocation, v string) error { a.Name = v; return nil },
+	"namespace":             func(a *Allocation, v string) error { a.Namespace = v; return nil },
+	"controller":            func(a *Allocation, v string) error { a.Controller = v; return nil },
+	"controllerkind":        func(a *Allocation, v string) error { a.ControllerKind = v; return nil },
+	"pod":                   func(a *Allocation, v string) error { a.Pod = v; return nil },
+	"node":                  func(a *Allocation, v string) error { a.Node = v; return nil },
+	"windowstart":           func(a *Allocation, v string) (err error) { a.Start, err = parseTime(v); return },
+	"start":                 func(a *Allocation, v string) (err error) { a.Start, err = parseTime(v); return },
+	"windowend":             func(a *Allocation, v string) (err error) { a.End, err = parseTime(v); return },
+	"end":                   func(a *Allocation, v string) (err error) { a.End, err = parseTime(v); return },
+	"cpucorerequestaverage": floatColumn(func(a *Allocation) *float64 { return &a.CPUCoreRequestAverage }),
+	"cpucoreusageaverage":   floatColumn(func(a *Allocation) *float64 { return &a.CPUCoreUsageAverage }),
+	"cpucost":               floatColumn(func(a *Allocation) *float64 { return &a.CPUCost }),
+	"rambyterequestaverage": floatColumn(func(a *Allocation) *float64 { return &a.RAMByteRequestAverage }),
+	"rambyteusageaverage":   floatColumn(func(a *Allocation) *float64 { return &a.RAMByteUsageAverage }),
+	"ramcost":               floatColumn(func(a *Allocation) *float64 { return &a.RAMCost }),
+	"gpucost":               floatColumn(func(a *Allocation) *float64 { return &a.GPUCost }),
+	"pvcost":                floatColumn(func(a *Allocation) *float64 { return &a.PVCost }),
+	"networkcost":           floatColumn(func(a *Allocation) *float64 { return &a.NetworkCost }),
+	"loadbalancercost":      floatColumn(func(a *Allocation) *float64 { return &a.LoadBalancerCost }),
+	"sharedcost":            floatColumn(func(a *Allocation) *float64 { return &a.SharedCost }),
+	"totalcost":             floatColumn(func(a *Allocation) *float64 { return &a.TotalCost }),
+}
+
+func floatColumn(field func(a *Allocation) *float64) func(a *Allocation, v string) error {
+	return func(a *Allocation, v string) error {
+		if v == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*field(a) = f
+		return nil
+	}
+}
+
+// ParseAllocationCSV parses an allocation CSV export. Columns are matched by header ignoring case,
+// spaces and punctuation ("Window Start", "windowStart" and "window_start" are the same column);
+// unknown columns are ignored. The window columns and a namespace or name column are required.
+func ParseAllocationCSV(r io.Reader) ([]Allocation, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: empty CSV", ErrInvalidExport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+	columns := make([]func(a *Allocation, v string) error, len(header))
+	seen := make(map[string]bool)
+	for i, h := range header {
+		key := normalizeHeader(h)
+		columns[i] = csvColumns[key]
+		seen[key] = true
+	}
+	if !(seen["windowstart"] || seen["start"]) || !(seen["windowend"] || seen["end"]) {
+		return nil, fmt.Errorf("%w: CSV needs window start and end columns", ErrInvalidExport)
+	}
+	if !seen["namespace"] && !seen["name"] {
+		return nil, fmt.Errorf("%w: CSV needs a namespace or name column", ErrInvalidExport)
+	}
+
+	var out []Allocation
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+		}
+		var a Allocation
+		for i, v := range record {
+			if i >= len(columns) || columns[i] == nil {
+				continue
+			}
+			if err := columns[i](&a, strings.TrimSpace(v)); err != nil {
+				return nil, fmt.Errorf("%w: line %d, column %q: %v", ErrInvalidExport, line, header[i], err)
+			}
+		}
+		if err := a.normalize(); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidExport, line, err)
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// normalize validates the window and fills in the namespace of namespace-aggregated exports, whose
+// only name is the namespace.
+func (a *Allocation) normalize() error {
+	if a.Start.IsZero() || a.End.IsZero() {
+		return fmt.Errorf("no window")
+	}
+	if !a.End.After(a.Start) {
+		return fmt.Errorf("window end %s is not after start %s", a.End.Format(time.RFC3339), a.Start.Format(time.RFC3339))
+	}
+	a.Start, a.End = a.Start.UTC(), a.End.UTC()
+	if a.Namespace == "" {
+		a.Namespace = a.Name
+	}
+	if a.Namespace == "" {
+		return fmt.Errorf("no namespace")
+	}
+	return nil
+}
+
+func normalizeHeader(h string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(h) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func parseTime(v string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", v)
+}
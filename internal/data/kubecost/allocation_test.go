@@ -0,0 +1,91 @@
+package kubecost
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAllocationJSON(t *testing.T) {
+	body := `{"code": 200, "data": [{
+		"shop/deployment:api": {
+			"name": "shop/deployment:api",
+			"properties": {"namespace": "shop", "controller": "api", "controllerKind": "deployment", "node": "n1"},
+			"window": {"start": "2020-05-01T00:00:00Z", "end": "2020-05-02T00:00:00Z"},
+			"start": "2020-05-01T00:00:00Z", "end": "2020-05-02T00:00:00Z",
+			"cpuCoreRequestAverage": 2, "cpuCoreUsageAverage": 0.5, "cpuCost": 4.8,
+			"ramByteRequestAverage": 4294967296, "ramByteUsageAverage": 2147483648, "ramCost": 1.2,
+			"pvCost": 0.3, "sharedCost": 0.5, "totalCost": 6.3
+		},
+		"__idle__": {"name": "__idle__", "window": {"start": "2020-05-01T00:00:00Z", "end": "2020-05-02T00:00:00Z"}, "totalCost": 9}
+	}]}`
+	allocs, err := ParseAllocationJSON(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("ParseAllocationJSON: %v", err)
+	}
+	if len(allocs) != 2 {
+		t.Fatalf("got %d allocations, want 2", len(allocs))
+	}
+	idle, api := allocs[0], allocs[1]
+	if !idle.Unallocated() || api.Unallocated() {
+		t.Errorf("unallocated = %v / %v", idle.Unallocated(), api.Unallocated())
+	}
+	if api.Namespace != "shop" || api.Controller != "api" || api.ControllerKind != "deployment" || api.CPUCost != 4.8 || api.RAMByteRequestAverage != 4294967296 {
+		t.Errorf("api = %+v", api)
+	}
+	if !api.Start.Equal(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)) || api.End.Sub(api.Start) != 24*time.Hour {
+		t.Errorf("window = %v - %v", api.Start, api.End)
+	}
+	if api.OtherCost() != 0.3 {
+		t.Errorf("other cost = %v", api.OtherCost())
+	}
+
+	// Bare list of sets; namespace aggregation names the namespace only
+	allocs, err = ParseAllocationJSON(strings.NewReader(`[{"pay": {"window": {"start": "2020-05-01T00:00:00Z", "end": "2020-05-01T01:00:00Z"}, "cpuCost": 1}}]`))
+	if err != nil || len(allocs) != 1 || allocs[0].Namespace != "pay" {
+		t.Errorf("bare list = %+v, %v", allocs, err)
+	}
+
+	for name, body := range map[string]string{
+		"error code": `{"code": 500, "message": "boom"}`,
+		"no window":  `{"code": 200, "data": [{"shop": {"cpuCost": 1}}]}`,
+		"reversed":   `[{"shop": {"start": "2020-05-02T00:00:00Z", "end": "2020-05-01T00:00:00Z"}}]`,
+		"not json":   `shop,1`,
+	} {
+		if _, err := ParseAllocationJSON(strings.NewReader(body)); !errors.Is(err, ErrInvalidExport) {
+			t.Errorf("%s: err = %v, want ErrInvalidExport", name, err)
+		}
+	}
+}
+
+func TestParseAllocationCSV(t *testing.T) {
+	body := "Namespace,Controller,Controller Kind,Window Start,Window End,CPU Core Request Average,cpu_cost,RAM Cost,GPU Cost,Total Cost,Extra\n" +
+		"shop,api,deployment,2020-05-01T00:00:00Z,2020-05-02T00:00:00Z,2,4.8,1.2,,6,x\n" +
+		"pay,,,2020-05-01,2020-05-02,,1,0.5,2,3.5,\n"
+	allocs, err := ParseAllocationCSV(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("ParseAllocationCSV: %v", err)
+	}
+	if len(allocs) != 2 {
+		t.Fatalf("got %d allocations, want 2", len(allocs))
+	}
+	if a := allocs[0]; a.Namespace != "shop" || a.Controller != "api" || a.ControllerKind != "deployment" || a.CPUCoreRequestAverage != 2 || a.CPUCost != 4.8 || a.TotalCost != 6 {
+		t.Errorf("shop = %+v", a)
+	}
+	if a := allocs[1]; a.Namespace != "pay" || a.GPUCost != 2 || a.End.Sub(a.Start) != 24*time.Hour {
+		t.Errorf("pay = %+v", a)
+	}
+
+	for name, body := range map[string]string{
+		"empty":        "",
+		"no window":    "namespace,cpu cost\nshop,1\n",
+		"no namespace": "window start,window end,cpu cost\n2020-05-01,2020-05-02,1\n",
+		"bad number":   "namespace,window start,window end,cpu cost\nshop,2020-05-01,2020-05-02,lots\n",
+		"bad time":     "namespace,window start,window end\nshop,yesterday,2020-05-02\n",
+	} {
+		if _, err := ParseAllocationCSV(strings.NewReader(body)); !errors.Is(err, ErrInvalidExport) {
+			t.Errorf("%s: err = %v, want ErrInvalidExport", name, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package latency simulates data source latency for the mock clients: a fixed base, uniform jitter
+// and an occasional Pareto-distributed long tail, so that timeouts, cancellation and p99 behaviour
+// can be exercised without a real backend.
+package latency
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// tailAlpha is the Pareto shape of the long tail; below 2 the variance is infinite, which is
+	// what makes a few calls dominate p99.
+	tailAlpha = 1.5
+	// maxTailFactor caps a long-tail delay at maxTailFactor × Profile.Tail.
+	maxTailFactor = 20
+)
+
+// Profile describes the latency distribution of a call.
+type Profile struct {
+	// Base is waited on every call.
+	Base time.Duration
+	// Jitter adds a uniform random delay in [0, Jitter).
+	Jitter time.Duration
+	// TailRate is the probability (0-1) that a call is a long-tail call.
+	TailRate float64
+	// Tail is the minimum extra delay of a long-tail call. The extra delay is Pareto-distributed
+	// (alpha 1.5) and capped at 20 × Tail.
+	Tail time.Duration
+}
+
+// Millis builds a Profile from the millisecond knobs of the mock configs.
+func Millis(baseMs, jitterMs int, tailRate float64, tailMs int) Profile {
+	return Profile{
+		Base:     time.Duration(baseMs) * time.Millisecond,
+		Jitter:   time.Duration(jitterMs) * time.Millisecond,
+		TailRate: tailRate,
+		Tail:     time.Duration(tailMs) * time.Millisecond,
+	}
+}
+
+// Simulator samples and waits out latencies of a Profile. It is safe for concurrent use.
+type Simulator struct {
+	profile Profile
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New returns a Simulator for p. The seed makes the sampled latencies reproducible; the random
+// source is separate from the mock's data generation so enabling latency knobs does not change data.
+func New(p Profile, seed int64) *Simulator {
+	return &Simulator{profile: p, rand: rand.New(rand.NewSource(seed))}
+}
+
+// Sample returns the latency of the next call.
+func (s *Simulator) Sample() time.Duration {
+	d := s.profile.Base
+	if s.profile.Jitter <= 0 && (s.profile.TailRate <= 0 || s.profile.Tail <= 0) {
+		return d
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.profile.Jitter > 0 {
+		d += time.Duration(s.rand.Int63n(int64(s.profile.Jitter)))
+	}
+	if s.profile.Tail > 0 && s.rand.Float64() < s.profile.TailRate {
+		// 1-U 落在 (0, 1]，避免除零
+		factor := math.Min(math.Pow(1-s.rand.Float64(), -1/tailAlpha), maxTailFactor)
+		d += time.Duration(float64(s.profile.Tail) * factor)
+	}
+	return d
+}
+
+// Wait sleeps for a sampled latency and returns ctx.Err() as soon as ctx is done.
+// An already cancelled ctx fails even when the latency is zero.
+func (s *Simulator) Wait(ctx context.Context) error {
+	d := s.Sample()
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,73 @@
+package latency
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSample(t *testing.T) {
+	if d := New(Millis(10, 0, 0, 0), 1).Sample(); d != 10*time.Millisecond {
+		t.Errorf("fixed latency = %v, want 10ms", d)
+	}
+
+	jitter := New(Millis(10, 5, 0, 0), 1)
+	for i := 0; i < 100; i++ {
+		if d := jitter.Sample(); d < 10*time.Millisecond || d >= 15*time.Millisecond {
+			t.Fatalf("jittered latency %v outside [10ms, 15ms)", d)
+		}
+	}
+
+	tail := New(Millis(10, 0, 1, 100), 1)
+	for i := 0; i < 100; i++ {
+		if d := tail.Sample(); d < 110*time.Millisecond || d > 2010*time.Millisecond {
+			t.Fatalf("tail latency %v outside [110ms, 2010ms]", d)
+		}
+	}
+
+	a, b := New(Millis(1, 50, 0.5, 100), 7), New(Millis(1, 50, 0.5, 100), 7)
+	for i := 0; i < 20; i++ {
+		if da, db := a.Sample(), b.Sample(); da != db {
+			t.Fatalf("same seed sampled %v and %v", da, db)
+		}
+	}
+}
+
+func TestSample_LongTail(t *testing.T) {
+	s := New(Millis(10, 0, 0.05, 200), 42)
+	samples := make([]time.Duration, 2000)
+	for i := range samples {
+		samples[i] = s.Sample()
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	p50, p99 := samples[len(samples)/2], samples[len(samples)*99/100]
+	if p50 != 10*time.Millisecond {
+		t.Errorf("p50 = %v, want the base latency", p50)
+	}
+	if p99 < 210*time.Millisecond {
+		t.Errorf("p99 = %v, want a long-tail call (>= 210ms) with a 5%% tail rate", p99)
+	}
+}
+
+func TestWait(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := New(Millis(1000, 0, 0, 0), 1).Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Wait kept sleeping after the deadline: %v", elapsed)
+	}
+
+	if err := New(Millis(1, 0, 0, 0), 1).Wait(context.Background()); err != nil {
+		t.Errorf("Wait = %v", err)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := New(Profile{}, 1).Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("zero latency with a cancelled ctx = %v, want Canceled", err)
+	}
+}
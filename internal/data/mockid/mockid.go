@@ -0,0 +1,97 @@
+// Package mockid generates record IDs for the mock clients. The random mode keeps the historic
+// "<kind>-<n>" IDs; the deterministic mode derives a UUIDv5 from the seed, the record kind and the
+// record's identifying fields (namespace, workload, timestamp, ...) so that datasets exported by two
+// runs with the same seed can be diffed without ID noise.
+package mockid
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ID modes selectable in the mock configs.
+const (
+	// ModeRandom generates "<kind>-<index>" IDs for generated records and "<kind>-<rand.Int63>"
+	// IDs for saved records without one. It is the default.
+	ModeRandom = "random"
+	// ModeDeterministic generates seeded UUIDv5 IDs for every record.
+	ModeDeterministic = "deterministic"
+)
+
+// Generator hands out record IDs. It is safe for concurrent use; the random source passed to New
+// must be too when the mock uses it elsewhere (see mockrand).
+type Generator struct {
+	deterministic bool
+	space         uuid.UUID
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	seen map[string]int
+}
+
+// New returns a Generator for mode ("" is ModeRandom). r is the mock's own random source, used by
+// the random mode so that its IDs stay what they were before modes existed; the deterministic mode
+// derives its UUID namespace from seed instead.
+func New(mode string, seed int64, r *rand.Rand) *Generator {
+	return &Generator{
+		deterministic: mode == ModeDeterministic,
+		space:         uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("lighthouse-mock/%d", seed))),
+		rand:          r,
+		seen:          make(map[string]int),
+	}
+}
+
+// Valid reports whether mode is a known ID mode.
+func Valid(mode string) bool {
+	return mode == "" || mode == ModeRandom || mode == ModeDeterministic
+}
+
+// Deterministic reports whether the Generator produces seeded UUIDs.
+func (g *Generator) Deterministic() bool { return g.deterministic }
+
+// Indexed returns the ID of the index-th generated record of kind.
+func (g *Generator) Indexed(kind string, index int) string {
+	if !g.deterministic {
+		return fmt.Sprintf("%s-%d", kind, index)
+	}
+	return g.uuid(kind, "index", fmt.Sprint(index))
+}
+
+// Next returns the ID of a record of kind identified by parts, for records saved without an ID.
+// In deterministic mode the same seed, kind and parts give the same ID across runs; repeating them
+// within a run yields the next ID of a per-name sequence, so IDs stay unique.
+func (g *Generator) Next(kind string, parts ...string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.deterministic {
+		return fmt.Sprintf("%s-%d", kind, g.rand.Int63())
+	}
+	name := strings.Join(append([]string{kind}, parts...), "/")
+	n := g.seen[name]
+	g.seen[name] = n + 1
+	return g.uuid(kind, append(parts, fmt.Sprint(n))...)
+}
+
+// Name returns the UUIDv5 of a record of kind whose parts are already unique, such as the
+// namespace, kind and name of a Kubernetes object. It does not depend on the mode.
+func (g *Generator) Name(kind string, parts ...string) string {
+	return g.uuid(kind, parts...)
+}
+
+func (g *Generator) uuid(kind string, parts ...string) string {
+	name := strings.Join(append([]string{kind}, parts...), "/")
+	return uuid.NewSHA1(g.space, []byte(name)).String()
+}
+
+// Time formats t as an ID part; the zero time formats as "".
+func Time(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
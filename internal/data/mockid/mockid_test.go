@@ -0,0 +1,55 @@
+package mockid
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGenerator_Random(t *testing.T) {
+	g := New("", 1, rand.New(rand.NewSource(1)))
+	if id := g.Indexed("snapshot", 3); id != "snapshot-3" {
+		t.Errorf("Indexed = %q, want snapshot-3", id)
+	}
+	want := rand.New(rand.NewSource(1)).Int63()
+	if id := g.Next("run", "manual"); id != fmt.Sprintf("run-%d", want) {
+		t.Errorf("Next = %q, want run-%d", id, want)
+	}
+}
+
+func TestGenerator_Deterministic(t *testing.T) {
+	at := Time(time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC))
+	a := New(ModeDeterministic, 42, rand.New(rand.NewSource(1)))
+	b := New(ModeDeterministic, 42, rand.New(rand.NewSource(2)))
+
+	for _, id := range []string{a.Indexed("snapshot", 0), a.Next("grade", "web", "api", at)} {
+		if u, err := uuid.Parse(id); err != nil || u.Version() != 5 {
+			t.Errorf("%q is not a UUIDv5", id)
+		}
+	}
+	if a.Indexed("roi", 1) != b.Indexed("roi", 1) {
+		t.Error("same seed should give the same indexed ID")
+	}
+	b.Next("grade", "web", "api", at)
+	if x, y := a.Next("grade", "web", "api", at), b.Next("grade", "web", "api", at); x != y {
+		t.Errorf("same seed and sequence gave %s and %s", x, y)
+	}
+
+	first := New(ModeDeterministic, 42, nil).Next("run", "manual", at)
+	c := New(ModeDeterministic, 42, nil)
+	if c.Next("run", "manual", at) != first || c.Next("run", "manual", at) == first {
+		t.Error("a repeated name should get the next ID of its sequence")
+	}
+	if New(ModeDeterministic, 43, nil).Next("run", "manual", at) == first {
+		t.Error("a different seed should give a different ID")
+	}
+	if a.Name("uid", "Pod", "web", "api-0") != b.Name("uid", "Pod", "web", "api-0") {
+		t.Error("Name should not depend on the sequence")
+	}
+	if Valid("uuid") || !Valid("") || !Valid(ModeDeterministic) {
+		t.Error("Valid misclassified a mode")
+	}
+}
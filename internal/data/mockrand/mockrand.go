@@ -0,0 +1,41 @@
+// Package mockrand provides the seeded random sources of the mock clients. A mock is shared by every
+// goroutine of the server (and by parallel tests), so its *rand.Rand must be safe for concurrent use;
+// a plain rand.New(rand.NewSource(seed)) is not.
+package mockrand
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// New returns a *rand.Rand seeded with seed that is safe for concurrent use. Used from a single
+// goroutine it yields the same sequence as rand.New(rand.NewSource(seed)), so seeded mock datasets
+// do not change. Read is the only method that is not safe for concurrent use.
+func New(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+// lockedSource serializes access to a rand.Source64. *rand.Rand keeps no state of its own apart
+// from Read's buffer, so locking the source makes all other methods safe.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
@@ -0,0 +1,41 @@
+package mockrand
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestNew_SameSequenceAsUnlockedSource(t *testing.T) {
+	want := rand.New(rand.NewSource(42))
+	got := New(42)
+	for i := 0; i < 100; i++ {
+		if w, g := want.Int63(), got.Int63(); w != g {
+			t.Fatalf("Int63 #%d = %d, want %d", i, g, w)
+		}
+		if w, g := want.Float64(), got.Float64(); w != g {
+			t.Fatalf("Float64 #%d = %v, want %v", i, g, w)
+		}
+		if w, g := want.Intn(1000), got.Intn(1000); w != g {
+			t.Fatalf("Intn #%d = %d, want %d", i, g, w)
+		}
+	}
+}
+
+// TestNew_ConcurrentUse is meaningful under go test -race.
+func TestNew_ConcurrentUse(t *testing.T) {
+	r := New(1)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				_ = r.Float64()
+				_ = r.Intn(10)
+				_ = r.Uint64()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,143 @@
+// Package objectstore archive.go: CostSnapshot / EvidenceChain 归档到对象存储，以及从归档恢复。
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// Archive object kinds (key sub-prefixes).
+const (
+	KindCostSnapshot  = "cost_snapshots"
+	KindEvidenceChain = "evidence_chains"
+)
+
+// Archiver writes domain objects as JSON under <prefix>/<kind>/<id>.json.
+type Archiver struct {
+	client Client
+	prefix string
+}
+
+// NewArchiver creates an Archiver; prefix may be empty.
+func NewArchiver(client Client, prefix string) *Archiver {
+	return &Archiver{client: client, prefix: strings.Trim(prefix, "/")}
+}
+
+// Key returns the object key for an archived object.
+func (a *Archiver) Key(kind, id string) string {
+	return path.Join(a.prefix, kind, id+".json")
+}
+
+// ArchiveSnapshot archives a cost snapshot.
+func (a *Archiver) ArchiveSnapshot(ctx context.Context, snapshot postgres.CostSnapshot) error {
+	if snapshot.ID == "" {
+		return fmt.Errorf("archive cost snapshot: empty id")
+	}
+	return a.put(ctx, KindCostSnapshot, snapshot.ID, snapshot)
+}
+
+// RestoreSnapshot reads an archived cost snapshot.
+func (a *Archiver) RestoreSnapshot(ctx context.Context, id string) (*postgres.CostSnapshot, error) {
+	var snapshot postgres.CostSnapshot
+	if err := a.get(ctx, KindCostSnapshot, id, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// RestoreSnapshotInto reads an archived snapshot and saves it back into the repository.
+func (a *Archiver) RestoreSnapshotInto(ctx context.Context, repo postgres.Repository, id string) (*postgres.CostSnapshot, error) {
+	snapshot, err := a.RestoreSnapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.SaveCostSnapshot(ctx, *snapshot); err != nil {
+		return nil, fmt.Errorf("restore cost snapshot %s: %w", id, err)
+	}
+	return snapshot, nil
+}
+
+// ArchiveEvidenceChain archives an SLO evidence chain keyed by its snapshot ID.
+func (a *Archiver) ArchiveEvidenceChain(ctx context.Context, chain slo.EvidenceChain) error {
+	if chain.SnapshotID == "" {
+		return fmt.Errorf("archive evidence chain: empty snapshot id")
+	}
+	return a.put(ctx, KindEvidenceChain, chain.SnapshotID, chain)
+}
+
+// RestoreEvidenceChain reads an archived evidence chain.
+func (a *Archiver) RestoreEvidenceChain(ctx context.Context, snapshotID string) (*slo.EvidenceChain, error) {
+	var chain slo.EvidenceChain
+	if err := a.get(ctx, KindEvidenceChain, snapshotID, &chain); err != nil {
+		return nil, err
+	}
+	return &chain, nil
+}
+
+// PurgeExpired deletes archived objects of kind last modified before cutoff.
+// Returns the number of deleted objects.
+func (a *Archiver) PurgeExpired(ctx context.Context, kind string, cutoff time.Time) (int, error) {
+	objects, err := a.client.List(ctx, path.Join(a.prefix, kind)+"/")
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, obj := range objects {
+		if !obj.LastModified.Before(cutoff) {
+			continue
+		}
+		if err := a.client.Delete(ctx, obj.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (a *Archiver) put(ctx context.Context, kind, id string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("archive %s %s: %w", kind, id, err)
+	}
+	if err := a.client.Put(ctx, a.Key(kind, id), data, "application/json"); err != nil {
+		return fmt.Errorf("archive %s %s: %w", kind, id, err)
+	}
+	return nil
+}
+
+func (a *Archiver) get(ctx context.Context, kind, id string, v interface{}) error {
+	data, err := a.client.Get(ctx, a.Key(kind, id))
+	if err != nil {
+		return fmt.Errorf("restore %s %s: %w", kind, id, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("restore %s %s: %w", kind, id, err)
+	}
+	return nil
+}
+
+// ArchivingRepository wraps a Repository and archives every cost snapshot on creation.
+// The snapshot is saved first; an archive failure is returned as an error but the saved row is kept.
+type ArchivingRepository struct {
+	postgres.Repository
+	Archiver *Archiver
+}
+
+// SaveCostSnapshot saves the snapshot and then archives it. Snapshots without an ID get one
+// here so the stored row and the archive key match.
+func (r *ArchivingRepository) SaveCostSnapshot(ctx context.Context, snapshot postgres.CostSnapshot) error {
+	if snapshot.ID == "" {
+		snapshot.ID = fmt.Sprintf("snapshot-%d", time.Now().UnixNano())
+	}
+	if err := r.Repository.SaveCostSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	return r.Archiver.ArchiveSnapshot(ctx, snapshot)
+}
@@ -0,0 +1,49 @@
+// Package objectstore provides S3-compatible object storage clients (AWS S3, Aliyun OSS, MinIO)
+// used for archiving snapshots and evidence chains.
+package objectstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// ErrNotFound is returned by Get when the object does not exist.
+var ErrNotFound = dataerr.NotFound("object not found")
+
+// Client defines the interface for object storage operations.
+type Client interface {
+	// Put uploads an object, overwriting any existing object with the same key.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+
+	// Get downloads an object. Returns ErrNotFound if the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes an object. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// List returns all objects whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Config holds S3-compatible connection settings.
+// AccessKeyID / SecretAccessKey are injected from environment variables only.
+type Config struct {
+	Endpoint        string `json:"endpoint"` // e.g. https://s3.amazonaws.com, https://oss-cn-hangzhou.aliyuncs.com, http://minio:9000
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyID     string `json:"-"`
+	SecretAccessKey string `json:"-"`
+	// UsePathStyle addresses objects as <endpoint>/<bucket>/<key> (required by MinIO);
+	// otherwise virtual-hosted style <bucket>.<endpoint>/<key> is used (AWS/OSS default).
+	UsePathStyle bool          `json:"use_path_style"`
+	Timeout      time.Duration `json:"timeout"`
+}
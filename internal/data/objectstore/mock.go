@@ -0,0 +1,76 @@
+// Package objectstore provides mock implementations for testing.
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockClient is an in-memory Client.
+type MockClient struct {
+	mu      sync.RWMutex
+	objects map[string]mockObject
+	now     func() time.Time
+}
+
+type mockObject struct {
+	data         []byte
+	lastModified time.Time
+}
+
+// NewMockClient creates an empty in-memory object store.
+func NewMockClient() *MockClient {
+	return &MockClient{objects: make(map[string]mockObject), now: time.Now}
+}
+
+// Put implements Client.
+func (m *MockClient) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = mockObject{data: append([]byte(nil), data...), lastModified: m.now()}
+	return nil
+}
+
+// Get implements Client.
+func (m *MockClient) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return append([]byte(nil), obj.data...), nil
+}
+
+// Delete implements Client.
+func (m *MockClient) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+// List implements Client. Results are sorted by key like S3.
+func (m *MockClient) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []ObjectInfo
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			out = append(out, ObjectInfo{Key: key, Size: int64(len(obj.data)), LastModified: obj.lastModified})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+// SetClock overrides the timestamp used for LastModified (testing retention).
+func (m *MockClient) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
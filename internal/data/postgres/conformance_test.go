@@ -0,0 +1,27 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/storage/storagetest"
+)
+
+// TestMockConformance runs the shared Repository conformance suite against the seeded mock,
+// so its filter, sort and upsert semantics stay in line with the other backends.
+func TestMockConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) postgres.Repository {
+		config := postgres.DefaultMockConfig()
+		config.LatencyMs = 0
+		return postgres.NewMockRepository(config)
+	})
+}
+
+// TestMockStoresConformance runs the optional store suite against the mock.
+func TestMockStoresConformance(t *testing.T) {
+	storagetest.RunStores(t, func(t *testing.T) postgres.Repository {
+		config := postgres.DefaultMockConfig()
+		config.LatencyMs = 0
+		return postgres.NewMockRepository(config)
+	})
+}
@@ -0,0 +1,4 @@
+// Package postgres driver_pgx.go: 链接 pgx 的 database/sql 驱动（名称 "pgx"），即 DefaultSQLDriver。
+package postgres
+
+import _ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
@@ -5,10 +5,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/latency"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockid"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockrand"
 	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 )
 
@@ -38,8 +45,20 @@ type MockConfig struct {
 	// LatencyMs simulates database latency in milliseconds
 	LatencyMs int `json:"latency_ms"`
 
+	// LatencyJitterMs adds a uniform random 0..LatencyJitterMs to LatencyMs
+	LatencyJitterMs int `json:"latency_jitter_ms"`
+
+	// TailLatencyRate is the probability (0.0 - 1.0) of a long-tail call, which waits an extra
+	// Pareto-distributed TailLatencyMs..20×TailLatencyMs (see latency.Profile)
+	TailLatencyRate float64 `json:"tail_latency_rate"`
+	TailLatencyMs   int     `json:"tail_latency_ms"`
+
 	// EnableTransactions simulates transaction support
 	EnableTransactions bool `json:"enable_transactions"`
+
+	// IDMode selects how record IDs are generated (see mockid): "random" (default) or
+	// "deterministic" for seeded UUIDv5 IDs that are stable across runs
+	IDMode string `json:"id_mode,omitempty"`
 }
 
 // DefaultMockConfig returns a default configuration for mock data generation.
@@ -67,15 +86,44 @@ func DefaultMockConfig() MockConfig {
 type MockRepository struct {
 	config              MockConfig
 	rand                *rand.Rand
+	latency             *latency.Simulator
+	ids                 *mockid.Generator
 	costSnapshots       map[string]CostSnapshot
 	roiBaselines        map[string]ROIBaseline
 	dailyNamespaceCosts map[string]DailyNamespaceCost // key: namespace-date
 	hourlyWorkloadStats map[string]HourlyWorkloadStat // key: namespace-workload-timestamp
+	dailyIndex          *timeIndex                    // dailyNamespaceCosts by namespace / day
+	hourlyIndex         *timeIndex                    // hourlyWorkloadStats by namespace / hour
 	metadata            map[string]Metadata
 	// Phase3 必做：总账单、存储/网络表 Mock 占位（schema 见 schema.sql）
 	billAccountSummaries map[string]BillAccountSummary // key: account_id-period_type-period_start
 	dailyStorageCosts     map[string]DailyStorageCost   // key: day-namespace-pvc_name
 	dailyNetworkCosts     map[string]DailyNetworkCost   // key: day-namespace-resource_id
+	calculationRuns       map[string]CalculationRun     // key: id
+	namespaceLifecycles   map[string]NamespaceLifecycle // key: namespace
+	gradeChangeEvents     []GradeChangeEvent            // append-only
+	priceVersions         map[int64]PriceVersion        // key: effective_from unix seconds
+	priceChanges          map[string]PriceChange        // key: id
+	snapshotHashChain     []SnapshotHashRecord          // append-only, ordered by seq
+
+	recommendations map[string]IssuedRecommendation // key: namespace/workload/resource
+	serviceAccounts map[string]ServiceAccount       // key: id
+	apiKeys         map[string]APIKey               // key: id
+	apiKeyUsage     map[string]APIKeyUsage          // key: key_id/date
+	regrades        map[string]SnapshotRegrade      // key: snapshot_id
+	alertRules      map[string]AlertRule            // key: id
+	notifyRoutes    map[string]NotificationRoute    // key: id
+	silences        map[string]NotificationSilence  // key: id
+	lineages        map[string]QueryLineage         // key: id
+	dailyWorkloads  map[string]DailyWorkloadStat    // key: namespace-workload-date
+	configChanges   map[string]ConfigChange         // key: id
+	sloViolations   map[string]SLOViolation         // key: id
+	dependencyEdges map[string]DependencyEdge       // key: caller/callee/source
+
+	accountingPeriods map[string]AccountingPeriod // key: start_date
+	adjustments       []Adjustment                // append-only
+
+	optimizations map[string]roi.OptimizationTrackingRecord // key: record_id
 }
 
 // MockTransaction is a mock implementation of the Transaction interface.
@@ -118,17 +166,41 @@ func NewMockRepository(config MockConfig) *MockRepository {
 	// Apply DataSize to InitialDataCount when using default counts (so tests get expected ranges)
 	applyDataSizeToInitialCount(&config)
 
+	r := mockrand.New(config.RandomSeed)
 	repo := &MockRepository{
 		config:                config,
-		rand:                  rand.New(rand.NewSource(config.RandomSeed)),
+		rand:                  r,
+		ids:                   mockid.New(config.IDMode, config.RandomSeed, r),
+		latency:               latency.New(latency.Millis(config.LatencyMs, config.LatencyJitterMs, config.TailLatencyRate, config.TailLatencyMs), config.RandomSeed),
 		costSnapshots:         make(map[string]CostSnapshot),
 		roiBaselines:          make(map[string]ROIBaseline),
 		dailyNamespaceCosts:   make(map[string]DailyNamespaceCost),
 		hourlyWorkloadStats:   make(map[string]HourlyWorkloadStat),
+		dailyIndex:            newTimeIndex(24 * time.Hour),
+		hourlyIndex:           newTimeIndex(time.Hour),
 		metadata:              make(map[string]Metadata),
 		billAccountSummaries: make(map[string]BillAccountSummary),
 		dailyStorageCosts:    make(map[string]DailyStorageCost),
 		dailyNetworkCosts:    make(map[string]DailyNetworkCost),
+		calculationRuns:      make(map[string]CalculationRun),
+		namespaceLifecycles:  make(map[string]NamespaceLifecycle),
+		priceVersions:        make(map[int64]PriceVersion),
+		priceChanges:         make(map[string]PriceChange),
+		recommendations:      make(map[string]IssuedRecommendation),
+		optimizations:        make(map[string]roi.OptimizationTrackingRecord),
+		serviceAccounts:      make(map[string]ServiceAccount),
+		apiKeys:              make(map[string]APIKey),
+		apiKeyUsage:          make(map[string]APIKeyUsage),
+		regrades:             make(map[string]SnapshotRegrade),
+		alertRules:           make(map[string]AlertRule),
+		notifyRoutes:         make(map[string]NotificationRoute),
+		silences:             make(map[string]NotificationSilence),
+		lineages:             make(map[string]QueryLineage),
+		dailyWorkloads:       make(map[string]DailyWorkloadStat),
+		configChanges:        make(map[string]ConfigChange),
+		sloViolations:        make(map[string]SLOViolation),
+		dependencyEdges:      make(map[string]DependencyEdge),
+		accountingPeriods:    make(map[string]AccountingPeriod),
 	}
 
 	// Pre-populate with initial data
@@ -139,21 +211,24 @@ func NewMockRepository(config MockConfig) *MockRepository {
 
 // SaveCostSnapshot saves a mock cost snapshot.
 func (m *MockRepository) SaveCostSnapshot(ctx context.Context, snapshot CostSnapshot) error {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return err
 	}
 
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot save cost snapshot")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save cost snapshot")
 	}
 
 	if snapshot.ID == "" {
-		snapshot.ID = fmt.Sprintf("snapshot-%d", m.rand.Int63())
+		snapshot.ID = m.ids.Next("snapshot", snapshot.CalculationID, mockid.Time(snapshot.Timestamp))
 	}
 	if snapshot.CreatedAt.IsZero() {
 		snapshot.CreatedAt = time.Now()
 	}
 	snapshot.UpdatedAt = time.Now()
+	if err := m.sealSnapshot(&snapshot); err != nil {
+		return err
+	}
 
 	m.costSnapshots[snapshot.ID] = snapshot
 	return nil
@@ -161,17 +236,17 @@ func (m *MockRepository) SaveCostSnapshot(ctx context.Context, snapshot CostSnap
 
 // GetCostSnapshot retrieves a mock cost snapshot.
 func (m *MockRepository) GetCostSnapshot(ctx context.Context, id string) (*CostSnapshot, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot get cost snapshot")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get cost snapshot")
 	}
 
 	snapshot, exists := m.costSnapshots[id]
 	if !exists {
-		return nil, fmt.Errorf("cost snapshot not found: %s", id)
+		return nil, dataerr.NotFound("cost snapshot not found: %s", id)
 	}
 
 	return &snapshot, nil
@@ -179,12 +254,12 @@ func (m *MockRepository) GetCostSnapshot(ctx context.Context, id string) (*CostS
 
 // ListCostSnapshots lists mock cost snapshots with filtering.
 func (m *MockRepository) ListCostSnapshots(ctx context.Context, filter CostSnapshotFilter) ([]CostSnapshot, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot list cost snapshots")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list cost snapshots")
 	}
 
 	var snapshots []CostSnapshot
@@ -205,6 +280,12 @@ func (m *MockRepository) ListCostSnapshots(ctx context.Context, filter CostSnaps
 		if filter.MaxTotalCost > 0 && snapshot.TotalBillableCost > filter.MaxTotalCost {
 			continue
 		}
+		if filter.ExcludeUnconfirmed && snapshot.AwaitingConfirmation() {
+			continue
+		}
+		if filter.ID != "" && snapshot.ID != filter.ID {
+			continue
+		}
 
 		snapshots = append(snapshots, snapshot)
 	}
@@ -227,39 +308,78 @@ func (m *MockRepository) ListCostSnapshots(ctx context.Context, filter CostSnaps
 		return []CostSnapshot{}, nil
 	}
 
-	return snapshots[start:end], nil
+	page := snapshots[start:end]
+	for i := range page {
+		page[i] = filter.Fields.Project(page[i])
+	}
+	return page, nil
 }
 
 // DeleteCostSnapshot deletes a mock cost snapshot.
 func (m *MockRepository) DeleteCostSnapshot(ctx context.Context, id string) error {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return err
 	}
 
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot delete cost snapshot")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot delete cost snapshot")
 	}
 
 	if _, exists := m.costSnapshots[id]; !exists {
-		return fmt.Errorf("cost snapshot not found: %s", id)
+		return dataerr.NotFound("cost snapshot not found: %s", id)
 	}
 
 	delete(m.costSnapshots, id)
+	delete(m.regrades, id)
+	m.appendSnapshotHash(id, "")
+	return nil
+}
+
+// ListSnapshotHashChain returns the snapshot hash chain ordered by seq.
+func (m *MockRepository) ListSnapshotHashChain(ctx context.Context) ([]SnapshotHashRecord, error) {
+	if err := m.simulateLatency(ctx); err != nil {
+		return nil, err
+	}
+
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list snapshot hash chain")
+	}
+
+	return append([]SnapshotHashRecord(nil), m.snapshotHashChain...), nil
+}
+
+// sealSnapshot sets the content hash of snapshot and appends it to the hash chain.
+func (m *MockRepository) sealSnapshot(snapshot *CostSnapshot) error {
+	hash, err := SnapshotContentHash(*snapshot)
+	if err != nil {
+		return err
+	}
+	snapshot.ContentHash = hash
+	m.appendSnapshotHash(snapshot.ID, hash)
 	return nil
 }
 
+// appendSnapshotHash appends a chain record; an empty contentHash records a deletion.
+func (m *MockRepository) appendSnapshotHash(id, contentHash string) {
+	var prev *SnapshotHashRecord
+	if n := len(m.snapshotHashChain); n > 0 {
+		prev = &m.snapshotHashChain[n-1]
+	}
+	m.snapshotHashChain = append(m.snapshotHashChain, NextSnapshotHashRecord(prev, id, contentHash, time.Now()))
+}
+
 // SaveROIBaseline saves a mock ROI baseline.
 func (m *MockRepository) SaveROIBaseline(ctx context.Context, baseline ROIBaseline) error {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return err
 	}
 
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot save ROI baseline")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save ROI baseline")
 	}
 
 	if baseline.ID == "" {
-		baseline.ID = fmt.Sprintf("roi-%d", m.rand.Int63())
+		baseline.ID = m.ids.Next("roi", baseline.Name, baseline.BaselineType, mockid.Time(baseline.TimePeriodStart))
 	}
 	if baseline.CreatedAt.IsZero() {
 		baseline.CreatedAt = time.Now()
@@ -272,17 +392,17 @@ func (m *MockRepository) SaveROIBaseline(ctx context.Context, baseline ROIBaseli
 
 // GetROIBaseline retrieves a mock ROI baseline.
 func (m *MockRepository) GetROIBaseline(ctx context.Context, id string) (*ROIBaseline, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot get ROI baseline")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get ROI baseline")
 	}
 
 	baseline, exists := m.roiBaselines[id]
 	if !exists {
-		return nil, fmt.Errorf("ROI baseline not found: %s", id)
+		return nil, dataerr.NotFound("ROI baseline not found: %s", id)
 	}
 
 	return &baseline, nil
@@ -290,12 +410,12 @@ func (m *MockRepository) GetROIBaseline(ctx context.Context, id string) (*ROIBas
 
 // ListROIBaselines lists mock ROI baselines with filtering.
 func (m *MockRepository) ListROIBaselines(ctx context.Context, filter ROIBaselineFilter) ([]ROIBaseline, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot list ROI baselines")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list ROI baselines")
 	}
 
 	var baselines []ROIBaseline
@@ -340,16 +460,16 @@ func (m *MockRepository) ListROIBaselines(ctx context.Context, filter ROIBaselin
 
 // DeleteROIBaseline deletes a mock ROI baseline.
 func (m *MockRepository) DeleteROIBaseline(ctx context.Context, id string) error {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return err
 	}
 
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot delete ROI baseline")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot delete ROI baseline")
 	}
 
 	if _, exists := m.roiBaselines[id]; !exists {
-		return fmt.Errorf("ROI baseline not found: %s", id)
+		return dataerr.NotFound("ROI baseline not found: %s", id)
 	}
 
 	delete(m.roiBaselines, id)
@@ -358,37 +478,47 @@ func (m *MockRepository) DeleteROIBaseline(ctx context.Context, id string) error
 
 // SaveDailyNamespaceCost saves a mock daily namespace cost.
 func (m *MockRepository) SaveDailyNamespaceCost(ctx context.Context, cost DailyNamespaceCost) error {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return err
 	}
 
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot save daily namespace cost")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save daily namespace cost")
 	}
 
 	key := fmt.Sprintf("%s-%s", cost.Namespace, cost.Date.Format("2006-01-02"))
+	if p := m.closedPeriodOn(cost.Date); p != nil {
+		var old float64
+		if prev, ok := m.dailyNamespaceCosts[key]; ok {
+			old = prev.BillableCost + prev.SharedCost
+		}
+		return m.adjustClosedPeriod(ctx, p, Adjustment{
+			Record: AdjustmentRecordDailyNamespaceCost, Date: cost.Date, Namespace: cost.Namespace,
+			Amount: cost.BillableCost + cost.SharedCost - old,
+		})
+	}
 	if cost.CreatedAt.IsZero() {
 		cost.CreatedAt = time.Now()
 	}
 
-	m.dailyNamespaceCosts[key] = cost
+	m.putDailyNamespaceCost(key, cost)
 	return nil
 }
 
 // GetDailyNamespaceCost retrieves a mock daily namespace cost.
 func (m *MockRepository) GetDailyNamespaceCost(ctx context.Context, namespace string, date time.Time) (*DailyNamespaceCost, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot get daily namespace cost")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get daily namespace cost")
 	}
 
 	key := fmt.Sprintf("%s-%s", namespace, date.Format("2006-01-02"))
 	cost, exists := m.dailyNamespaceCosts[key]
 	if !exists {
-		return nil, fmt.Errorf("daily namespace cost not found for %s on %s", namespace, date.Format("2006-01-02"))
+		return nil, dataerr.NotFound("daily namespace cost not found for %s on %s", namespace, date.Format("2006-01-02"))
 	}
 
 	return &cost, nil
@@ -396,39 +526,45 @@ func (m *MockRepository) GetDailyNamespaceCost(ctx context.Context, namespace st
 
 // ListDailyNamespaceCosts lists mock daily namespace costs with filtering.
 func (m *MockRepository) ListDailyNamespaceCosts(ctx context.Context, filter DailyNamespaceCostFilter) ([]DailyNamespaceCost, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot list daily namespace costs")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list daily namespace costs")
 	}
 
+	// Walk the index newest bucket first; a bounded page stops once it has enough sorted rows
 	var costs []DailyNamespaceCost
-	for _, cost := range m.dailyNamespaceCosts {
-		// Apply filters
-		if filter.Namespace != "" && cost.Namespace != filter.Namespace {
-			continue
-		}
-		if !filter.StartDate.IsZero() && cost.Date.Before(filter.StartDate) {
-			continue
-		}
-		if !filter.EndDate.IsZero() && cost.Date.After(filter.EndDate) {
-			continue
-		}
-		if filter.MinEfficiency > 0 && cost.EfficiencyScore < filter.MinEfficiency {
-			continue
-		}
-		if filter.MaxEfficiency > 0 && cost.EfficiencyScore > filter.MaxEfficiency {
-			continue
+	need := pageLimit(filter.Limit, filter.Offset)
+	m.dailyIndex.scan(filter.Namespace, filter.StartDate, filter.EndDate, func(keys map[string]struct{}) bool {
+		var bucket []DailyNamespaceCost
+		for key := range keys {
+			cost := m.dailyNamespaceCosts[key]
+			// Apply filters
+			if filter.Namespace != "" && cost.Namespace != filter.Namespace {
+				continue
+			}
+			if !filter.StartDate.IsZero() && cost.Date.Before(filter.StartDate) {
+				continue
+			}
+			if !filter.EndDate.IsZero() && cost.Date.After(filter.EndDate) {
+				continue
+			}
+			if filter.MinEfficiency > 0 && cost.EfficiencyScore < filter.MinEfficiency {
+				continue
+			}
+			if filter.MaxEfficiency > 0 && cost.EfficiencyScore > filter.MaxEfficiency {
+				continue
+			}
+			bucket = append(bucket, cost)
 		}
-
-		costs = append(costs, cost)
-	}
-
-	// Sort by date descending
-	sort.Slice(costs, func(i, j int) bool {
-		return costs[i].Date.After(costs[j].Date)
+		// Sort by date descending
+		sort.Slice(bucket, func(i, j int) bool {
+			return bucket[i].Date.After(bucket[j].Date)
+		})
+		costs = append(costs, bucket...)
+		return need < 0 || len(costs) < need
 	})
 
 	// Apply limit and offset
@@ -449,48 +585,54 @@ func (m *MockRepository) ListDailyNamespaceCosts(ctx context.Context, filter Dai
 
 // AggregateDailyNamespaceCosts aggregates mock daily namespace costs.
 func (m *MockRepository) AggregateDailyNamespaceCosts(ctx context.Context, startDate, endDate time.Time) ([]DailyNamespaceCost, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot aggregate daily namespace costs")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot aggregate daily namespace costs")
 	}
 
 	// Simple aggregation by namespace
 	aggregated := make(map[string]*DailyNamespaceCost)
-	for _, cost := range m.dailyNamespaceCosts {
-		if !startDate.IsZero() && cost.Date.Before(startDate) {
-			continue
-		}
-		if !endDate.IsZero() && cost.Date.After(endDate) {
-			continue
-		}
+	m.dailyIndex.scan("", startDate, endDate, func(keys map[string]struct{}) bool {
+		for rowKey := range keys {
+			cost := m.dailyNamespaceCosts[rowKey]
+			if !startDate.IsZero() && cost.Date.Before(startDate) {
+				continue
+			}
+			if !endDate.IsZero() && cost.Date.After(endDate) {
+				continue
+			}
 
-		if agg, exists := aggregated[cost.Namespace]; exists {
-			agg.BillableCost += cost.BillableCost
-			agg.UsageCost += cost.UsageCost
-			agg.WasteCost += cost.WasteCost
-			agg.PodCount += cost.PodCount
-			agg.NodeCount += cost.NodeCount
-			agg.WorkloadCount += cost.WorkloadCount
-			// Recalculate average efficiency
-			agg.EfficiencyScore = (agg.EfficiencyScore + cost.EfficiencyScore) / 2
-		} else {
-			aggregated[cost.Namespace] = &DailyNamespaceCost{
-				Namespace:       cost.Namespace,
-				Date:            cost.Date,
-				BillableCost:    cost.BillableCost,
-				UsageCost:       cost.UsageCost,
-				WasteCost:       cost.WasteCost,
-				PodCount:        cost.PodCount,
-				NodeCount:       cost.NodeCount,
-				WorkloadCount:   cost.WorkloadCount,
-				EfficiencyScore: cost.EfficiencyScore,
-				CreatedAt:       cost.CreatedAt,
+			if agg, exists := aggregated[cost.Namespace]; exists {
+				agg.BillableCost += cost.BillableCost
+				agg.UsageCost += cost.UsageCost
+				agg.WasteCost += cost.WasteCost
+				agg.SharedCost += cost.SharedCost
+				agg.PodCount += cost.PodCount
+				agg.NodeCount += cost.NodeCount
+				agg.WorkloadCount += cost.WorkloadCount
+				// Recalculate average efficiency
+				agg.EfficiencyScore = (agg.EfficiencyScore + cost.EfficiencyScore) / 2
+			} else {
+				aggregated[cost.Namespace] = &DailyNamespaceCost{
+					Namespace:       cost.Namespace,
+					Date:            cost.Date,
+					BillableCost:    cost.BillableCost,
+					UsageCost:       cost.UsageCost,
+					WasteCost:       cost.WasteCost,
+					SharedCost:      cost.SharedCost,
+					PodCount:        cost.PodCount,
+					NodeCount:       cost.NodeCount,
+					WorkloadCount:   cost.WorkloadCount,
+					EfficiencyScore: cost.EfficiencyScore,
+					CreatedAt:       cost.CreatedAt,
+				}
 			}
 		}
-	}
+		return true
+	})
 
 	var result []DailyNamespaceCost
 	for _, cost := range aggregated {
@@ -507,33 +649,43 @@ func (m *MockRepository) AggregateDailyNamespaceCosts(ctx context.Context, start
 
 // SaveHourlyWorkloadStat saves a mock hourly workload stat.
 func (m *MockRepository) SaveHourlyWorkloadStat(ctx context.Context, stat HourlyWorkloadStat) error {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return err
 	}
 
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot save hourly workload stat")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save hourly workload stat")
 	}
 
 	key := fmt.Sprintf("%s-%s-%s", stat.Namespace, stat.WorkloadName, stat.Timestamp.Format("2006-01-02-15"))
-	m.hourlyWorkloadStats[key] = stat
+	if p := m.closedPeriodAt(stat.Timestamp); p != nil {
+		var old float64
+		if prev, ok := m.hourlyWorkloadStats[key]; ok {
+			old = prev.TotalBillableCost
+		}
+		return m.adjustClosedPeriod(ctx, p, Adjustment{
+			Record: AdjustmentRecordHourlyWorkloadStat, Date: stat.Timestamp.UTC().Truncate(time.Hour),
+			Namespace: stat.Namespace, Workload: stat.WorkloadName, Amount: stat.TotalBillableCost - old,
+		})
+	}
+	m.putHourlyWorkloadStat(key, stat)
 	return nil
 }
 
 // GetHourlyWorkloadStat retrieves a mock hourly workload stat.
 func (m *MockRepository) GetHourlyWorkloadStat(ctx context.Context, namespace, workloadName string, timestamp time.Time) (*HourlyWorkloadStat, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot get hourly workload stat")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get hourly workload stat")
 	}
 
 	key := fmt.Sprintf("%s-%s-%s", namespace, workloadName, timestamp.Format("2006-01-02-15"))
 	stat, exists := m.hourlyWorkloadStats[key]
 	if !exists {
-		return nil, fmt.Errorf("hourly workload stat not found for %s/%s at %s", namespace, workloadName, timestamp.Format("2006-01-02 15:04"))
+		return nil, dataerr.NotFound("hourly workload stat not found for %s/%s at %s", namespace, workloadName, timestamp.Format("2006-01-02 15:04"))
 	}
 
 	return &stat, nil
@@ -541,39 +693,48 @@ func (m *MockRepository) GetHourlyWorkloadStat(ctx context.Context, namespace, w
 
 // ListHourlyWorkloadStats lists mock hourly workload stats with filtering.
 func (m *MockRepository) ListHourlyWorkloadStats(ctx context.Context, filter HourlyWorkloadStatFilter) ([]HourlyWorkloadStat, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot list hourly workload stats")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list hourly workload stats")
 	}
 
+	// Walk the index newest bucket first; a bounded page stops once it has enough sorted rows
 	var stats []HourlyWorkloadStat
-	for _, stat := range m.hourlyWorkloadStats {
-		// Apply filters
-		if filter.Namespace != "" && stat.Namespace != filter.Namespace {
-			continue
-		}
-		if filter.WorkloadName != "" && stat.WorkloadName != filter.WorkloadName {
-			continue
-		}
-		if filter.NodeName != "" && stat.NodeName != filter.NodeName {
-			continue
-		}
-		if !filter.StartTime.IsZero() && stat.Timestamp.Before(filter.StartTime) {
-			continue
-		}
-		if !filter.EndTime.IsZero() && stat.Timestamp.After(filter.EndTime) {
-			continue
+	need := pageLimit(filter.Limit, filter.Offset)
+	m.hourlyIndex.scan(filter.Namespace, filter.StartTime, filter.EndTime, func(keys map[string]struct{}) bool {
+		var bucket []HourlyWorkloadStat
+		for key := range keys {
+			stat := m.hourlyWorkloadStats[key]
+			// Apply filters
+			if filter.Namespace != "" && stat.Namespace != filter.Namespace {
+				continue
+			}
+			if filter.WorkloadName != "" && stat.WorkloadName != filter.WorkloadName {
+				continue
+			}
+			if filter.NodeName != "" && stat.NodeName != filter.NodeName {
+				continue
+			}
+			if filter.NodePool != "" && stat.NodePool != filter.NodePool {
+				continue
+			}
+			if !filter.StartTime.IsZero() && stat.Timestamp.Before(filter.StartTime) {
+				continue
+			}
+			if !filter.EndTime.IsZero() && stat.Timestamp.After(filter.EndTime) {
+				continue
+			}
+			bucket = append(bucket, stat)
 		}
-
-		stats = append(stats, stat)
-	}
-
-	// Sort by timestamp descending
-	sort.Slice(stats, func(i, j int) bool {
-		return stats[i].Timestamp.After(stats[j].Timestamp)
+		// Sort by timestamp descending
+		sort.Slice(bucket, func(i, j int) bool {
+			return bucket[i].Timestamp.After(bucket[j].Timestamp)
+		})
+		stats = append(stats, bucket...)
+		return need < 0 || len(stats) < need
 	})
 
 	// Apply limit and offset
@@ -594,66 +755,84 @@ func (m *MockRepository) ListHourlyWorkloadStats(ctx context.Context, filter Hou
 
 // AggregateHourlyWorkloadStats aggregates mock hourly workload stats.
 func (m *MockRepository) AggregateHourlyWorkloadStats(ctx context.Context, startTime, endTime time.Time) ([]HourlyWorkloadStat, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot aggregate hourly workload stats")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot aggregate hourly workload stats")
 	}
 
 	// Simple aggregation by workload
 	aggregated := make(map[string]*HourlyWorkloadStat)
-	for _, stat := range m.hourlyWorkloadStats {
-		if !startTime.IsZero() && stat.Timestamp.Before(startTime) {
-			continue
-		}
-		if !endTime.IsZero() && stat.Timestamp.After(endTime) {
-			continue
-		}
+	cpuSketches := make(map[string][]*costmodel.UsageSketch)
+	memSketches := make(map[string][]*costmodel.UsageSketch)
+	m.hourlyIndex.scan("", startTime, endTime, func(keys map[string]struct{}) bool {
+		for rowKey := range keys {
+			stat := m.hourlyWorkloadStats[rowKey]
+			if !startTime.IsZero() && stat.Timestamp.Before(startTime) {
+				continue
+			}
+			if !endTime.IsZero() && stat.Timestamp.After(endTime) {
+				continue
+			}
 
-		key := fmt.Sprintf("%s-%s", stat.Namespace, stat.WorkloadName)
-		if agg, exists := aggregated[key]; exists {
-			agg.CPURequest += stat.CPURequest
-			agg.CPUUsageP95 += stat.CPUUsageP95
-			agg.MemRequest += stat.MemRequest
-			agg.MemUsageP95 += stat.MemUsageP95
-			agg.CPUBillableCost += stat.CPUBillableCost
-			agg.CPUUsageCost += stat.CPUUsageCost
-			agg.CPUWasteCost += stat.CPUWasteCost
-			agg.MemBillableCost += stat.MemBillableCost
-			agg.MemUsageCost += stat.MemUsageCost
-			agg.MemWasteCost += stat.MemWasteCost
-			agg.TotalBillableCost += stat.TotalBillableCost
-			agg.TotalUsageCost += stat.TotalUsageCost
-			agg.TotalWasteCost += stat.TotalWasteCost
-		} else {
-			aggregated[key] = &HourlyWorkloadStat{
-				Namespace:         stat.Namespace,
-				WorkloadName:      stat.WorkloadName,
-				WorkloadType:      stat.WorkloadType,
-				NodeName:          stat.NodeName,
-				PodName:           stat.PodName,
-				Timestamp:         stat.Timestamp,
-				CPURequest:        stat.CPURequest,
-				CPUUsageP95:       stat.CPUUsageP95,
-				MemRequest:        stat.MemRequest,
-				MemUsageP95:       stat.MemUsageP95,
-				CPUBillableCost:   stat.CPUBillableCost,
-				CPUUsageCost:      stat.CPUUsageCost,
-				CPUWasteCost:      stat.CPUWasteCost,
-				MemBillableCost:   stat.MemBillableCost,
-				MemUsageCost:      stat.MemUsageCost,
-				MemWasteCost:      stat.MemWasteCost,
-				TotalBillableCost: stat.TotalBillableCost,
-				TotalUsageCost:    stat.TotalUsageCost,
-				TotalWasteCost:    stat.TotalWasteCost,
+			key := fmt.Sprintf("%s-%s", stat.Namespace, stat.WorkloadName)
+			cpuSketches[key] = append(cpuSketches[key], stat.CPUUsageSketch)
+			memSketches[key] = append(memSketches[key], stat.MemUsageSketch)
+			if agg, exists := aggregated[key]; exists {
+				agg.CPURequest += stat.CPURequest
+				agg.CPUUsageP95 += stat.CPUUsageP95
+				agg.MemRequest += stat.MemRequest
+				agg.MemUsageP95 += stat.MemUsageP95
+				agg.CPUBillableCost += stat.CPUBillableCost
+				agg.CPUUsageCost += stat.CPUUsageCost
+				agg.CPUWasteCost += stat.CPUWasteCost
+				agg.MemBillableCost += stat.MemBillableCost
+				agg.MemUsageCost += stat.MemUsageCost
+				agg.MemWasteCost += stat.MemWasteCost
+				agg.TotalBillableCost += stat.TotalBillableCost
+				agg.TotalUsageCost += stat.TotalUsageCost
+				agg.TotalWasteCost += stat.TotalWasteCost
+			} else {
+				aggregated[key] = &HourlyWorkloadStat{
+					Namespace:         stat.Namespace,
+					WorkloadName:      stat.WorkloadName,
+					WorkloadType:      stat.WorkloadType,
+					NodeName:          stat.NodeName,
+					NodePool:          stat.NodePool,
+					PodName:           stat.PodName,
+					Timestamp:         stat.Timestamp,
+					CPURequest:        stat.CPURequest,
+					CPUUsageP95:       stat.CPUUsageP95,
+					MemRequest:        stat.MemRequest,
+					MemUsageP95:       stat.MemUsageP95,
+					CPUBillableCost:   stat.CPUBillableCost,
+					CPUUsageCost:      stat.CPUUsageCost,
+					CPUWasteCost:      stat.CPUWasteCost,
+					MemBillableCost:   stat.MemBillableCost,
+					MemUsageCost:      stat.MemUsageCost,
+					MemWasteCost:      stat.MemWasteCost,
+					TotalBillableCost: stat.TotalBillableCost,
+					TotalUsageCost:    stat.TotalUsageCost,
+					TotalWasteCost:    stat.TotalWasteCost,
+				}
 			}
 		}
-	}
+		return true
+	})
 
 	var result []HourlyWorkloadStat
-	for _, stat := range aggregated {
+	for key, stat := range aggregated {
+		// 各小时均带 sketch 时，窗口 P95 取合并后的分位数（替代逐小时 P95 的累加）
+		if merged, ok := costmodel.MergeSketches(cpuSketches[key]); ok {
+			stat.CPUUsageP95 = merged.Quantile(0.95)
+			stat.CPUUsageSketch = merged
+		}
+		if merged, ok := costmodel.MergeSketches(memSketches[key]); ok {
+			stat.MemUsageP95 = int64(math.Round(merged.Quantile(0.95)))
+			stat.MemUsageSketch = merged
+		}
 		result = append(result, *stat)
 	}
 
@@ -670,12 +849,12 @@ func (m *MockRepository) AggregateHourlyWorkloadStats(ctx context.Context, start
 
 // SaveMetadata saves mock metadata.
 func (m *MockRepository) SaveMetadata(ctx context.Context, metadata Metadata) error {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return err
 	}
 
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot save metadata")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save metadata")
 	}
 
 	if metadata.CreatedAt.IsZero() {
@@ -689,17 +868,17 @@ func (m *MockRepository) SaveMetadata(ctx context.Context, metadata Metadata) er
 
 // GetMetadata retrieves mock metadata.
 func (m *MockRepository) GetMetadata(ctx context.Context, key string) (*Metadata, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot get metadata")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get metadata")
 	}
 
 	metadata, exists := m.metadata[key]
 	if !exists {
-		return nil, fmt.Errorf("metadata not found: %s", key)
+		return nil, dataerr.NotFound("metadata not found: %s", key)
 	}
 
 	return &metadata, nil
@@ -707,18 +886,18 @@ func (m *MockRepository) GetMetadata(ctx context.Context, key string) (*Metadata
 
 // ListMetadata lists mock metadata with filtering.
 func (m *MockRepository) ListMetadata(ctx context.Context, filter MetadataFilter) ([]Metadata, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot list metadata")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list metadata")
 	}
 
 	var result []Metadata
 	for key, metadata := range m.metadata {
 		// Apply filters
-		if filter.KeyPrefix != "" && len(key) >= len(filter.KeyPrefix) && key[:len(filter.KeyPrefix)] != filter.KeyPrefix {
+		if filter.KeyPrefix != "" && !strings.HasPrefix(key, filter.KeyPrefix) {
 			continue
 		}
 		if filter.CreatedBy != "" && metadata.CreatedBy != filter.CreatedBy {
@@ -751,16 +930,16 @@ func (m *MockRepository) ListMetadata(ctx context.Context, filter MetadataFilter
 
 // DeleteMetadata deletes mock metadata.
 func (m *MockRepository) DeleteMetadata(ctx context.Context, key string) error {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return err
 	}
 
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot delete metadata")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot delete metadata")
 	}
 
 	if _, exists := m.metadata[key]; !exists {
-		return fmt.Errorf("metadata not found: %s", key)
+		return dataerr.NotFound("metadata not found: %s", key)
 	}
 
 	delete(m.metadata, key)
@@ -776,7 +955,7 @@ func billAccountSummaryKey(accountID, periodType string, periodStart time.Time)
 // SaveBillAccountSummary 保存总账单汇总（Mock 占位）。
 func (m *MockRepository) SaveBillAccountSummary(ctx context.Context, s BillAccountSummary) error {
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot save bill account summary")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save bill account summary")
 	}
 	if s.CreatedAt.IsZero() {
 		s.CreatedAt = time.Now()
@@ -789,12 +968,12 @@ func (m *MockRepository) SaveBillAccountSummary(ctx context.Context, s BillAccou
 // GetBillAccountSummary 按账户+账期查询总账单（Mock 占位）。
 func (m *MockRepository) GetBillAccountSummary(ctx context.Context, accountID, periodType string, periodStart time.Time) (*BillAccountSummary, error) {
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot get bill account summary")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get bill account summary")
 	}
 	key := billAccountSummaryKey(accountID, periodType, periodStart)
 	s, ok := m.billAccountSummaries[key]
 	if !ok {
-		return nil, fmt.Errorf("bill account summary not found: %s", key)
+		return nil, dataerr.NotFound("bill account summary not found: %s", key)
 	}
 	return &s, nil
 }
@@ -802,7 +981,7 @@ func (m *MockRepository) GetBillAccountSummary(ctx context.Context, accountID, p
 // ListBillAccountSummaries 列出总账单（Mock 占位）。
 func (m *MockRepository) ListBillAccountSummaries(ctx context.Context, accountID string) ([]BillAccountSummary, error) {
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot list bill account summaries")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list bill account summaries")
 	}
 	var out []BillAccountSummary
 	for _, s := range m.billAccountSummaries {
@@ -815,6 +994,67 @@ func (m *MockRepository) ListBillAccountSummaries(ctx context.Context, accountID
 	return out, nil
 }
 
+// SaveCalculationRun 保存（新增或更新）一次计算执行记录。
+func (m *MockRepository) SaveCalculationRun(ctx context.Context, run CalculationRun) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save calculation run")
+	}
+	if run.ID == "" {
+		run.ID = m.ids.Next("run", run.Trigger, mockid.Time(run.WindowStart), mockid.Time(run.WindowEnd))
+	}
+	m.calculationRuns[run.ID] = run
+	return nil
+}
+
+// GetCalculationRun 按 ID 查询计算执行记录。
+func (m *MockRepository) GetCalculationRun(ctx context.Context, id string) (*CalculationRun, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get calculation run")
+	}
+	run, ok := m.calculationRuns[id]
+	if !ok {
+		return nil, dataerr.NotFound("calculation run not found: %s", id)
+	}
+	return &run, nil
+}
+
+// ListCalculationRuns 列出计算执行记录，按开始时间倒序。
+func (m *MockRepository) ListCalculationRuns(ctx context.Context, filter CalculationRunFilter) ([]CalculationRun, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list calculation runs")
+	}
+	var runs []CalculationRun
+	for _, run := range m.calculationRuns {
+		if filter.Status != "" && run.Status != filter.Status {
+			continue
+		}
+		if filter.Trigger != "" && run.Trigger != filter.Trigger {
+			continue
+		}
+		if !filter.StartTime.IsZero() && run.WindowStart.Before(filter.StartTime) {
+			continue
+		}
+		if !filter.EndTime.IsZero() && run.WindowEnd.After(filter.EndTime) {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+
+	start := filter.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > len(runs) {
+		start = len(runs)
+	}
+	end := len(runs)
+	if filter.Limit > 0 && start+filter.Limit < end {
+		end = start + filter.Limit
+	}
+	return runs[start:end], nil
+}
+
 func dailyStorageCostKey(day time.Time, namespace, pvcName string) string {
 	return fmt.Sprintf("%s-%s-%s", day.Format("2006-01-02"), namespace, pvcName)
 }
@@ -822,7 +1062,7 @@ func dailyStorageCostKey(day time.Time, namespace, pvcName string) string {
 // SaveDailyStorageCost 保存存储维度日成本（Mock 占位）。
 func (m *MockRepository) SaveDailyStorageCost(ctx context.Context, c DailyStorageCost) error {
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot save daily storage cost")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save daily storage cost")
 	}
 	if c.CreatedAt.IsZero() {
 		c.CreatedAt = time.Now()
@@ -835,12 +1075,12 @@ func (m *MockRepository) SaveDailyStorageCost(ctx context.Context, c DailyStorag
 // GetDailyStorageCost 查询存储维度日成本（Mock 占位）。
 func (m *MockRepository) GetDailyStorageCost(ctx context.Context, day time.Time, namespace, pvcName string) (*DailyStorageCost, error) {
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot get daily storage cost")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get daily storage cost")
 	}
 	key := dailyStorageCostKey(day, namespace, pvcName)
 	c, ok := m.dailyStorageCosts[key]
 	if !ok {
-		return nil, fmt.Errorf("daily storage cost not found: %s", key)
+		return nil, dataerr.NotFound("daily storage cost not found: %s", key)
 	}
 	return &c, nil
 }
@@ -852,7 +1092,7 @@ func dailyNetworkCostKey(day time.Time, namespace, resourceID string) string {
 // SaveDailyNetworkCost 保存网络维度日成本（Mock 占位）。
 func (m *MockRepository) SaveDailyNetworkCost(ctx context.Context, c DailyNetworkCost) error {
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL error: cannot save daily network cost")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save daily network cost")
 	}
 	if c.CreatedAt.IsZero() {
 		c.CreatedAt = time.Now()
@@ -865,89 +1105,1093 @@ func (m *MockRepository) SaveDailyNetworkCost(ctx context.Context, c DailyNetwor
 // GetDailyNetworkCost 查询网络维度日成本（Mock 占位）。
 func (m *MockRepository) GetDailyNetworkCost(ctx context.Context, day time.Time, namespace, resourceID string) (*DailyNetworkCost, error) {
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot get daily network cost")
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get daily network cost")
 	}
 	key := dailyNetworkCostKey(day, namespace, resourceID)
 	c, ok := m.dailyNetworkCosts[key]
 	if !ok {
-		return nil, fmt.Errorf("daily network cost not found: %s", key)
+		return nil, dataerr.NotFound("daily network cost not found: %s", key)
 	}
 	return &c, nil
 }
 
-// HealthCheck always returns nil (healthy) for mock repository.
-func (m *MockRepository) HealthCheck(ctx context.Context) error {
+// SaveNamespaceLifecycle 保存（新增或更新）namespace 生命周期记录。
+func (m *MockRepository) SaveNamespaceLifecycle(ctx context.Context, lifecycle NamespaceLifecycle) error {
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock PostgreSQL health check failed")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save namespace lifecycle")
+	}
+	if lifecycle.Namespace == "" {
+		return dataerr.Validation("namespace is required")
 	}
+	m.namespaceLifecycles[lifecycle.Namespace] = lifecycle
 	return nil
 }
 
-// BeginTx starts a mock transaction.
-func (m *MockRepository) BeginTx(ctx context.Context) (Transaction, error) {
-	if err := m.simulateLatency(); err != nil {
-		return nil, err
+// ListNamespaceLifecycles 列出全部 namespace 生命周期记录，按 namespace 排序。
+func (m *MockRepository) ListNamespaceLifecycles(ctx context.Context) ([]NamespaceLifecycle, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list namespace lifecycles")
 	}
+	out := make([]NamespaceLifecycle, 0, len(m.namespaceLifecycles))
+	for _, l := range m.namespaceLifecycles {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out, nil
+}
 
+// SavePriceVersion 保存单价版本；EffectiveFrom 相同的已有版本被更正（保留 ID 与 CreatedAt，Revision 加一）。
+func (m *MockRepository) SavePriceVersion(ctx context.Context, version PriceVersion) error {
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock PostgreSQL error: cannot begin transaction")
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save price version")
 	}
-
-	if !m.config.EnableTransactions {
-		return nil, errors.New("transactions not enabled in mock configuration")
+	if err := version.UnitPrice().Validate(); err != nil {
+		return err
 	}
-
-	// Create copies of current data for transaction isolation
-	txSnapshots := make(map[string]CostSnapshot)
-	for k, v := range m.costSnapshots {
-		txSnapshots[k] = v
+	key := version.EffectiveFrom.Unix()
+	now := time.Now().UTC()
+	version.Revision = 1
+	if prev, ok := m.priceVersions[key]; ok {
+		version.ID, version.CreatedAt = prev.ID, prev.CreatedAt
+		version.Revision = prev.Revision + 1
 	}
-
-	txBaselines := make(map[string]ROIBaseline)
-	for k, v := range m.roiBaselines {
-		txBaselines[k] = v
+	if version.ID == "" {
+		version.ID = fmt.Sprintf("price-%d", key)
 	}
-
-	txDailyCosts := make(map[string]DailyNamespaceCost)
-	for k, v := range m.dailyNamespaceCosts {
-		txDailyCosts[k] = v
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = now
 	}
+	version.UpdatedAt = now
+	m.priceVersions[key] = version
+	return nil
+}
 
-	txWorkloads := make(map[string]HourlyWorkloadStat)
-	for k, v := range m.hourlyWorkloadStats {
-		txWorkloads[k] = v
+// ListPriceVersions 列出全部单价版本，按生效时间正序。
+func (m *MockRepository) ListPriceVersions(ctx context.Context) ([]PriceVersion, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list price versions")
 	}
-
-	txMetadata := make(map[string]Metadata)
-	for k, v := range m.metadata {
-		txMetadata[k] = v
+	out := make([]PriceVersion, 0, len(m.priceVersions))
+	for _, v := range m.priceVersions {
+		out = append(out, v)
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].EffectiveFrom.Before(out[j].EffectiveFrom) })
+	return out, nil
+}
 
-	tx := &MockTransaction{
-		repo:       m,
-		snapshots:  txSnapshots,
-		baselines:  txBaselines,
-		dailyCosts: txDailyCosts,
-		workloads:  txWorkloads,
-		metadata:   txMetadata,
-		committed:  false,
+// SavePriceChange 保存单价变更申请并返回保存后的记录；ID 为空时生成，已有申请保留 CreatedAt。
+func (m *MockRepository) SavePriceChange(ctx context.Context, change PriceChange) (PriceChange, error) {
+	if m.shouldReturnError() {
+		return PriceChange{}, dataerr.Unavailable("mock PostgreSQL error: cannot save price change")
 	}
-
-	return tx, nil
+	if change.ID == "" {
+		change.ID = m.ids.Next("pricechange", mockid.Time(change.EffectiveFrom), mockid.Time(change.CreatedAt))
+	}
+	if old, ok := m.priceChanges[change.ID]; ok {
+		change.CreatedAt = old.CreatedAt
+	}
+	now := time.Now().UTC()
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = now
+	}
+	change.UpdatedAt = now
+	change.History = append([]PriceChangeEvent(nil), change.History...)
+	m.priceChanges[change.ID] = change
+	return change, nil
 }
 
-// Commit commits the mock transaction.
-func (tx *MockTransaction) Commit() error {
-	if tx.committed {
-		return errors.New("transaction already committed")
+// GetPriceChange 按 ID 获取单价变更申请。
+func (m *MockRepository) GetPriceChange(ctx context.Context, id string) (*PriceChange, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get price change")
 	}
-
-	// Apply transaction changes to repository
+	change, ok := m.priceChanges[id]
+	if !ok {
+		return nil, dataerr.NotFound("price change not found: %s", id)
+	}
+	change.History = append([]PriceChangeEvent(nil), change.History...)
+	return &change, nil
+}
+
+// ListPriceChanges 列出单价变更申请，按创建时间倒序。
+func (m *MockRepository) ListPriceChanges(ctx context.Context) ([]PriceChange, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list price changes")
+	}
+	out := make([]PriceChange, 0, len(m.priceChanges))
+	for _, c := range m.priceChanges {
+		c.History = append([]PriceChangeEvent(nil), c.History...)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+// SaveGradeChangeEvent 追加一条工作负载等级变更事件。
+func (m *MockRepository) SaveGradeChangeEvent(ctx context.Context, event GradeChangeEvent) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save grade change event")
+	}
+	if event.ID == "" {
+		event.ID = m.ids.Next("grade", event.Namespace, event.WorkloadName, mockid.Time(event.OccurredAt))
+	}
+	m.gradeChangeEvents = append(m.gradeChangeEvents, event)
+	return nil
+}
+
+// ListGradeChangeEvents 列出等级变更事件，按发生时间正序。
+func (m *MockRepository) ListGradeChangeEvents(ctx context.Context, filter GradeChangeEventFilter) ([]GradeChangeEvent, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list grade change events")
+	}
+	var events []GradeChangeEvent
+	for _, e := range m.gradeChangeEvents {
+		if filter.Namespace != "" && e.Namespace != filter.Namespace {
+			continue
+		}
+		if filter.WorkloadName != "" && e.WorkloadName != filter.WorkloadName {
+			continue
+		}
+		if filter.ToGrade != "" && e.ToGrade != filter.ToGrade {
+			continue
+		}
+		if !filter.StartTime.IsZero() && e.OccurredAt.Before(filter.StartTime) {
+			continue
+		}
+		if !filter.EndTime.IsZero() && e.OccurredAt.After(filter.EndTime) {
+			continue
+		}
+		events = append(events, e)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].OccurredAt.Before(events[j].OccurredAt) })
+
+	start := filter.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > len(events) {
+		start = len(events)
+	}
+	end := len(events)
+	if filter.Limit > 0 && start+filter.Limit < end {
+		end = start + filter.Limit
+	}
+	return events[start:end], nil
+}
+
+// SaveIssuedRecommendation 按 namespace/工作负载/资源 写入一条已下发建议，已存在时覆盖（保留原 ID）。
+func (m *MockRepository) SaveIssuedRecommendation(ctx context.Context, rec IssuedRecommendation) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save issued recommendation")
+	}
+	key := rec.Namespace + "/" + rec.WorkloadName + "/" + rec.Resource
+	if prev, ok := m.recommendations[key]; ok {
+		rec.ID = prev.ID
+	}
+	if rec.ID == "" {
+		rec.ID = m.ids.Next("rec", rec.Namespace, rec.WorkloadName, rec.Resource)
+	}
+	m.recommendations[key] = rec
+	return nil
+}
+
+// ListIssuedRecommendations 列出已下发建议，按下发时间正序。
+func (m *MockRepository) ListIssuedRecommendations(ctx context.Context, filter IssuedRecommendationFilter) ([]IssuedRecommendation, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list issued recommendations")
+	}
+	out := []IssuedRecommendation{}
+	for _, r := range m.recommendations {
+		if filter.Team != "" && r.Team != filter.Team {
+			continue
+		}
+		if filter.Namespace != "" && r.Namespace != filter.Namespace {
+			continue
+		}
+		if filter.WorkloadName != "" && r.WorkloadName != filter.WorkloadName {
+			continue
+		}
+		if filter.Status != "" && r.Status != filter.Status {
+			continue
+		}
+		if !filter.IssuedAfter.IsZero() && r.IssuedAt.Before(filter.IssuedAfter) {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].IssuedAt.Equal(out[j].IssuedAt) {
+			return out[i].IssuedAt.Before(out[j].IssuedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// SaveOptimizationRecord 保存优化跟踪记录并返回保存后的记录；RecordID 为空时生成。
+func (m *MockRepository) SaveOptimizationRecord(ctx context.Context, rec roi.OptimizationTrackingRecord) (roi.OptimizationTrackingRecord, error) {
+	if m.shouldReturnError() {
+		return roi.OptimizationTrackingRecord{}, dataerr.Unavailable("mock PostgreSQL error: cannot save optimization record")
+	}
+	if rec.RecordID == "" {
+		rec.RecordID = m.ids.Next("opt", rec.BundleID, rec.RecommendationID, rec.TargetResourceID)
+	}
+	m.optimizations[rec.RecordID] = rec
+	return rec, nil
+}
+
+// ListOptimizationRecords 按条件列出优化跟踪记录，按实施时间正序。
+func (m *MockRepository) ListOptimizationRecords(ctx context.Context, filter OptimizationRecordFilter) ([]roi.OptimizationTrackingRecord, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list optimization records")
+	}
+	out := []roi.OptimizationTrackingRecord{}
+	for _, r := range m.optimizations {
+		if filter.BundleID != "" && r.BundleID != filter.BundleID {
+			continue
+		}
+		if filter.RecommendationID != "" && r.RecommendationID != filter.RecommendationID {
+			continue
+		}
+		if filter.Unverified && r.Verified {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ImplementationDate.Equal(out[j].ImplementationDate) {
+			return out[i].ImplementationDate.Before(out[j].ImplementationDate)
+		}
+		return out[i].RecordID < out[j].RecordID
+	})
+	return out, nil
+}
+
+// SaveServiceAccount 写入（或按 ID 覆盖）一个服务账号，ID 为空时生成。
+func (m *MockRepository) SaveServiceAccount(ctx context.Context, account ServiceAccount) (ServiceAccount, error) {
+	if m.shouldReturnError() {
+		return ServiceAccount{}, dataerr.Unavailable("mock PostgreSQL error: cannot save service account")
+	}
+	if account.ID == "" {
+		account.ID = m.ids.Next("sa", account.Name)
+	}
+	m.serviceAccounts[account.ID] = account
+	return account, nil
+}
+
+// GetServiceAccount 按 ID 获取服务账号。
+func (m *MockRepository) GetServiceAccount(ctx context.Context, id string) (*ServiceAccount, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get service account")
+	}
+	account, ok := m.serviceAccounts[id]
+	if !ok {
+		return nil, dataerr.NotFound("service account not found: %s", id)
+	}
+	return &account, nil
+}
+
+// ListServiceAccounts 列出服务账号，按名称排序。
+func (m *MockRepository) ListServiceAccounts(ctx context.Context) ([]ServiceAccount, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list service accounts")
+	}
+	out := make([]ServiceAccount, 0, len(m.serviceAccounts))
+	for _, a := range m.serviceAccounts {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// DeleteServiceAccount 删除服务账号及其 API key（用量记录保留）。
+func (m *MockRepository) DeleteServiceAccount(ctx context.Context, id string) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot delete service account")
+	}
+	if _, ok := m.serviceAccounts[id]; !ok {
+		return dataerr.NotFound("service account not found: %s", id)
+	}
+	delete(m.serviceAccounts, id)
+	for keyID, k := range m.apiKeys {
+		if k.AccountID == id {
+			delete(m.apiKeys, keyID)
+		}
+	}
+	return nil
+}
+
+// SaveAlertRule 写入（或按 ID 覆盖）一条告警规则，ID 为空时生成。
+func (m *MockRepository) SaveAlertRule(ctx context.Context, rule AlertRule) (AlertRule, error) {
+	if m.shouldReturnError() {
+		return AlertRule{}, dataerr.Unavailable("mock PostgreSQL error: cannot save alert rule")
+	}
+	if rule.ID == "" {
+		rule.ID = m.ids.Next("rule", rule.Name)
+	}
+	rule.Channels = append([]string(nil), rule.Channels...)
+	m.alertRules[rule.ID] = rule
+	return rule, nil
+}
+
+// GetAlertRule 按 ID 获取告警规则。
+func (m *MockRepository) GetAlertRule(ctx context.Context, id string) (*AlertRule, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get alert rule")
+	}
+	rule, ok := m.alertRules[id]
+	if !ok {
+		return nil, dataerr.NotFound("alert rule not found: %s", id)
+	}
+	return &rule, nil
+}
+
+// ListAlertRules 列出告警规则，按名称排序。
+func (m *MockRepository) ListAlertRules(ctx context.Context) ([]AlertRule, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list alert rules")
+	}
+	out := make([]AlertRule, 0, len(m.alertRules))
+	for _, r := range m.alertRules {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// DeleteAlertRule 删除告警规则。
+func (m *MockRepository) DeleteAlertRule(ctx context.Context, id string) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot delete alert rule")
+	}
+	if _, ok := m.alertRules[id]; !ok {
+		return dataerr.NotFound("alert rule not found: %s", id)
+	}
+	delete(m.alertRules, id)
+	return nil
+}
+
+// SaveNotificationRoute 写入（或按 ID 覆盖）一条通知路由规则，ID 为空时生成。
+func (m *MockRepository) SaveNotificationRoute(ctx context.Context, route NotificationRoute) (NotificationRoute, error) {
+	if m.shouldReturnError() {
+		return NotificationRoute{}, dataerr.Unavailable("mock PostgreSQL error: cannot save notification route")
+	}
+	if route.ID == "" {
+		route.ID = m.ids.Next("route", route.Name)
+	}
+	route.Matchers = copyStringMap(route.Matchers)
+	route.Channels = append([]string(nil), route.Channels...)
+	m.notifyRoutes[route.ID] = route
+	return route, nil
+}
+
+// GetNotificationRoute 按 ID 获取通知路由规则。
+func (m *MockRepository) GetNotificationRoute(ctx context.Context, id string) (*NotificationRoute, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get notification route")
+	}
+	route, ok := m.notifyRoutes[id]
+	if !ok {
+		return nil, dataerr.NotFound("notification route not found: %s", id)
+	}
+	return &route, nil
+}
+
+// ListNotificationRoutes 列出通知路由规则，按 Priority、名称排序。
+func (m *MockRepository) ListNotificationRoutes(ctx context.Context) ([]NotificationRoute, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list notification routes")
+	}
+	out := make([]NotificationRoute, 0, len(m.notifyRoutes))
+	for _, r := range m.notifyRoutes {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority < out[j].Priority
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// DeleteNotificationRoute 删除通知路由规则。
+func (m *MockRepository) DeleteNotificationRoute(ctx context.Context, id string) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot delete notification route")
+	}
+	if _, ok := m.notifyRoutes[id]; !ok {
+		return dataerr.NotFound("notification route not found: %s", id)
+	}
+	delete(m.notifyRoutes, id)
+	return nil
+}
+
+// SaveNotificationSilence 写入（或按 ID 覆盖）一条静默，ID 为空时生成。
+func (m *MockRepository) SaveNotificationSilence(ctx context.Context, silence NotificationSilence) (NotificationSilence, error) {
+	if m.shouldReturnError() {
+		return NotificationSilence{}, dataerr.Unavailable("mock PostgreSQL error: cannot save notification silence")
+	}
+	if silence.ID == "" {
+		silence.ID = m.ids.Next("silence", silence.Reason)
+	}
+	silence.Matchers = copyStringMap(silence.Matchers)
+	m.silences[silence.ID] = silence
+	return silence, nil
+}
+
+// GetNotificationSilence 按 ID 获取静默。
+func (m *MockRepository) GetNotificationSilence(ctx context.Context, id string) (*NotificationSilence, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get notification silence")
+	}
+	silence, ok := m.silences[id]
+	if !ok {
+		return nil, dataerr.NotFound("notification silence not found: %s", id)
+	}
+	return &silence, nil
+}
+
+// ListNotificationSilences 列出在 activeAt 时刻生效的静默（activeAt 为零时列出全部），按结束时间排序。
+func (m *MockRepository) ListNotificationSilences(ctx context.Context, activeAt time.Time) ([]NotificationSilence, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list notification silences")
+	}
+	out := make([]NotificationSilence, 0, len(m.silences))
+	for _, s := range m.silences {
+		if activeAt.IsZero() || (!activeAt.Before(s.StartsAt) && activeAt.Before(s.EndsAt)) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].EndsAt.Equal(out[j].EndsAt) {
+			return out[i].EndsAt.Before(out[j].EndsAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// SaveQueryLineage 写入一条成本查询血缘记录。
+func (m *MockRepository) SaveQueryLineage(ctx context.Context, lineage QueryLineage) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save query lineage")
+	}
+	if lineage.ID == "" {
+		return dataerr.Validation("query lineage id is required")
+	}
+	m.lineages[lineage.ID] = lineage
+	return nil
+}
+
+// GetQueryLineage 按 ID 获取成本查询血缘记录。
+func (m *MockRepository) GetQueryLineage(ctx context.Context, id string) (*QueryLineage, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get query lineage")
+	}
+	lineage, ok := m.lineages[id]
+	if !ok {
+		return nil, dataerr.NotFound("query lineage not found: %s", id)
+	}
+	return &lineage, nil
+}
+
+// SaveConfigChange 写入一条配置变更记录；ID 为空时生成，已存在时覆盖（保留 CreatedAt）。
+func (m *MockRepository) SaveConfigChange(ctx context.Context, change ConfigChange) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save config change")
+	}
+	if change.ID == "" {
+		change.ID = m.ids.Next("change", change.Namespace, change.Kind, change.Name, mockid.Time(change.OccurredAt))
+	}
+	if old, ok := m.configChanges[change.ID]; ok {
+		change.CreatedAt = old.CreatedAt
+	}
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+	m.configChanges[change.ID] = change
+	return nil
+}
+
+// ListConfigChanges 按条件列出配置变更，按发生时间正序；namespace 过滤同时包含集群级变更。
+func (m *MockRepository) ListConfigChanges(ctx context.Context, filter ConfigChangeFilter) ([]ConfigChange, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list config changes")
+	}
+	changes := []ConfigChange{}
+	for _, c := range m.configChanges {
+		if filter.Namespace != "" && c.Namespace != "" && c.Namespace != filter.Namespace {
+			continue
+		}
+		if !filter.StartTime.IsZero() && c.OccurredAt.Before(filter.StartTime) {
+			continue
+		}
+		if !filter.EndTime.IsZero() && c.OccurredAt.After(filter.EndTime) {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].OccurredAt.Equal(changes[j].OccurredAt) {
+			return changes[i].OccurredAt.Before(changes[j].OccurredAt)
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes, nil
+}
+
+// SaveSLOViolation 写入一条 SLO 违约记录；ID 为空时生成，已存在时更新（保留 CreatedAt），用于上报恢复时间。
+func (m *MockRepository) SaveSLOViolation(ctx context.Context, violation SLOViolation) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save SLO violation")
+	}
+	if violation.ID == "" {
+		violation.ID = m.ids.Next("slo-violation", violation.Namespace, violation.Service, violation.ViolationType, mockid.Time(violation.ViolatedAt))
+	}
+	now := time.Now()
+	if old, ok := m.sloViolations[violation.ID]; ok {
+		violation.CreatedAt = old.CreatedAt
+	}
+	if violation.CreatedAt.IsZero() {
+		violation.CreatedAt = now
+	}
+	violation.UpdatedAt = now
+	m.sloViolations[violation.ID] = violation
+	return nil
+}
+
+// ListSLOViolations 列出窗口内任意时刻处于违约状态的记录，按违约开始时间正序。
+func (m *MockRepository) ListSLOViolations(ctx context.Context, filter SLOViolationFilter) ([]SLOViolation, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list SLO violations")
+	}
+	violations := []SLOViolation{}
+	for _, v := range m.sloViolations {
+		if filter.Namespace != "" && v.Namespace != filter.Namespace {
+			continue
+		}
+		if filter.Service != "" && v.Service != filter.Service {
+			continue
+		}
+		if !filter.EndTime.IsZero() && v.ViolatedAt.After(filter.EndTime) {
+			continue
+		}
+		if !filter.StartTime.IsZero() && v.RecoveredAt != nil && v.RecoveredAt.Before(filter.StartTime) {
+			continue
+		}
+		violations = append(violations, v)
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		if !violations[i].ViolatedAt.Equal(violations[j].ViolatedAt) {
+			return violations[i].ViolatedAt.Before(violations[j].ViolatedAt)
+		}
+		return violations[i].ID < violations[j].ID
+	})
+	return violations, nil
+}
+
+// SaveDependencyEdges 写入依赖边，同一 caller、callee 与来源的边已存在时更新（保留 CreatedAt）。
+func (m *MockRepository) SaveDependencyEdges(ctx context.Context, edges []DependencyEdge) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save dependency edges")
+	}
+	now := time.Now()
+	for _, e := range edges {
+		key := e.CallerNamespace + "/" + e.Caller + "/" + e.CalleeNamespace + "/" + e.Callee + "/" + e.Source
+		if old, ok := m.dependencyEdges[key]; ok {
+			e.CreatedAt = old.CreatedAt
+		}
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = now
+		}
+		m.dependencyEdges[key] = e
+	}
+	return nil
+}
+
+// ListDependencyEdges 按条件列出依赖边，按 caller、callee、来源排序。
+func (m *MockRepository) ListDependencyEdges(ctx context.Context, filter DependencyEdgeFilter) ([]DependencyEdge, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list dependency edges")
+	}
+	keys := make([]string, 0, len(m.dependencyEdges))
+	for key, e := range m.dependencyEdges {
+		if dependencyEdgeMatches(e, filter) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	edges := make([]DependencyEdge, 0, len(keys))
+	for _, key := range keys {
+		edges = append(edges, m.dependencyEdges[key])
+	}
+	return edges, nil
+}
+
+// DeleteDependencyEdges 删除符合条件的依赖边，返回删除的条数。
+func (m *MockRepository) DeleteDependencyEdges(ctx context.Context, filter DependencyEdgeFilter) (int, error) {
+	if m.shouldReturnError() {
+		return 0, dataerr.Unavailable("mock PostgreSQL error: cannot delete dependency edges")
+	}
+	deleted := 0
+	for key, e := range m.dependencyEdges {
+		if dependencyEdgeMatches(e, filter) {
+			delete(m.dependencyEdges, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func dependencyEdgeMatches(e DependencyEdge, filter DependencyEdgeFilter) bool {
+	if filter.Source != "" && e.Source != filter.Source {
+		return false
+	}
+	return filter.SeenBefore.IsZero() || e.LastSeen.Before(filter.SeenBefore)
+}
+
+// CloseAccountingPeriod 关账：写入已关账期间，之后期间内的日汇总与小时统计不可再写。与已关账期间重叠时返回冲突。
+func (m *MockRepository) CloseAccountingPeriod(ctx context.Context, period AccountingPeriod) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot close accounting period")
+	}
+	for _, p := range m.accountingPeriods {
+		if period.StartDate.Before(p.EndDate) && p.StartDate.Before(period.EndDate) {
+			return periodClosedError(&p)
+		}
+	}
+	if period.ClosedAt.IsZero() {
+		period.ClosedAt = time.Now()
+	}
+	m.accountingPeriods[period.StartDate.Format("2006-01-02")] = period
+	return nil
+}
+
+// ListAccountingPeriods 列出已关账期间，按开始日期正序。
+func (m *MockRepository) ListAccountingPeriods(ctx context.Context) ([]AccountingPeriod, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list accounting periods")
+	}
+	periods := make([]AccountingPeriod, 0, len(m.accountingPeriods))
+	for _, p := range m.accountingPeriods {
+		periods = append(periods, p)
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].StartDate.Before(periods[j].StartDate) })
+	return periods, nil
+}
+
+// SaveAdjustment 追加一条手工更正，Date 须落在已关账期间内（Period 取该期间）；返回带 ID 的更正。
+func (m *MockRepository) SaveAdjustment(ctx context.Context, adj Adjustment) (Adjustment, error) {
+	if m.shouldReturnError() {
+		return Adjustment{}, dataerr.Unavailable("mock PostgreSQL error: cannot save adjustment")
+	}
+	p := m.closedPeriodOn(adj.Date)
+	if p == nil {
+		return Adjustment{}, dataerr.Validation("no closed accounting period on %s", adj.Date.Format("2006-01-02"))
+	}
+	adj.Period = p.Label
+	m.appendAdjustment(&adj)
+	return adj, nil
+}
+
+// ListAdjustments 按条件列出更正记录，按写入顺序。
+func (m *MockRepository) ListAdjustments(ctx context.Context, filter AdjustmentFilter) ([]Adjustment, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list adjustments")
+	}
+	adjustments := []Adjustment{}
+	for _, a := range m.adjustments {
+		if (filter.Period != "" && a.Period != filter.Period) || (filter.Namespace != "" && a.Namespace != filter.Namespace) ||
+			(filter.Record != "" && a.Record != filter.Record) {
+			continue
+		}
+		if (!filter.StartDate.IsZero() && a.Date.Before(filter.StartDate)) || (!filter.EndDate.IsZero() && a.Date.After(filter.EndDate)) {
+			continue
+		}
+		adjustments = append(adjustments, a)
+	}
+	return adjustments, nil
+}
+
+// closedPeriodOn 返回包含日标签 date 的已关账期间，未关账时返回 nil。
+func (m *MockRepository) closedPeriodOn(date time.Time) *AccountingPeriod {
+	for _, p := range m.accountingPeriods {
+		if p.Contains(date) {
+			return &p
+		}
+	}
+	return nil
+}
+
+// closedPeriodAt 返回包含时刻 t 的已关账期间，未关账时返回 nil。
+func (m *MockRepository) closedPeriodAt(t time.Time) *AccountingPeriod {
+	for _, p := range m.accountingPeriods {
+		if p.ContainsTime(t) {
+			return &p
+		}
+	}
+	return nil
+}
+
+// adjustClosedPeriod 处理对已关账期间的写入：非重算写入返回 ErrPeriodClosed；重算不改动原记录，差额非零时记为一条更正。
+func (m *MockRepository) adjustClosedPeriod(ctx context.Context, p *AccountingPeriod, adj Adjustment) error {
+	reason, ok := RecalculationReason(ctx)
+	if !ok {
+		return periodClosedError(p)
+	}
+	if math.Abs(adj.Amount) < 1e-9 {
+		return nil
+	}
+	adj.Period, adj.Source, adj.Reason, adj.Status = p.Label, AdjustmentSourceRecalculation, reason, AdjustmentApproved
+	m.appendAdjustment(&adj)
+	return nil
+}
+
+// GetAdjustment 按 ID 返回更正。
+func (m *MockRepository) GetAdjustment(ctx context.Context, id string) (*Adjustment, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get adjustment")
+	}
+	for _, a := range m.adjustments {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, dataerr.NotFound("adjustment not found: %s", id)
+}
+
+// ApproveAdjustment 由 approver 批准待审批的更正；已批准的更正返回 Conflict。
+func (m *MockRepository) ApproveAdjustment(ctx context.Context, id, approver string) (Adjustment, error) {
+	if m.shouldReturnError() {
+		return Adjustment{}, dataerr.Unavailable("mock PostgreSQL error: cannot approve adjustment")
+	}
+	for i, a := range m.adjustments {
+		if a.ID != id {
+			continue
+		}
+		if !a.Pending() {
+			return Adjustment{}, dataerr.Conflict("adjustment %s is already approved", id)
+		}
+		now := time.Now().UTC()
+		a.Status, a.Approver, a.ApprovedAt = AdjustmentApproved, approver, &now
+		m.adjustments[i] = a
+		return a, nil
+	}
+	return Adjustment{}, dataerr.NotFound("adjustment not found: %s", id)
+}
+
+// appendAdjustment 为更正分配 ID 与写入时间并追加。
+func (m *MockRepository) appendAdjustment(adj *Adjustment) {
+	adj.CreatedAt = time.Now()
+	adj.ID = m.ids.Next("adjustment", adj.Record, adj.Namespace, adj.Workload, mockid.Time(adj.Date))
+	m.adjustments = append(m.adjustments, *adj)
+}
+
+// DownsampleHourlyWorkloadStats 将 before 之前的小时统计按工作负载与 UTC 日期汇总进日表（与已有日汇总合并）并删除这些小时行，
+// 写日表与删除在同一事务中完成。
+func (m *MockRepository) DownsampleHourlyWorkloadStats(ctx context.Context, before time.Time) (DownsampleResult, error) {
+	if m.shouldReturnError() {
+		return DownsampleResult{}, dataerr.Unavailable("mock PostgreSQL error: cannot downsample hourly workload stats")
+	}
+	rollups := make(map[string]DailyWorkloadStat)
+	var keys []string
+	m.hourlyIndex.scan("", time.Time{}, before, func(bucket map[string]struct{}) bool {
+		for key := range bucket {
+			if m.hourlyWorkloadStats[key].Timestamp.Before(before) {
+				keys = append(keys, key)
+			}
+		}
+		return true
+	})
+	// 按时间顺序合并，结果与 map 遍历顺序无关
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := m.hourlyWorkloadStats[keys[i]], m.hourlyWorkloadStats[keys[j]]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		hour := DailyWorkloadStatOf(m.hourlyWorkloadStats[key])
+		dayKey := fmt.Sprintf("%s-%s-%s", hour.Namespace, hour.WorkloadName, hour.Date.Format("2006-01-02"))
+		day, ok := rollups[dayKey]
+		if !ok {
+			rollups[dayKey] = hour
+			continue
+		}
+		day.Merge(hour)
+		rollups[dayKey] = day
+	}
+	for dayKey, day := range rollups {
+		if existing, ok := m.dailyWorkloads[dayKey]; ok {
+			existing.Merge(day)
+			day = existing
+		}
+		m.dailyWorkloads[dayKey] = day
+	}
+	for _, key := range keys {
+		m.deleteHourlyWorkloadStat(key)
+	}
+	return DownsampleResult{Rollups: len(rollups), Deleted: len(keys)}, nil
+}
+
+// ListDailyWorkloadStats 按条件列出工作负载日汇总，日期倒序。
+func (m *MockRepository) ListDailyWorkloadStats(ctx context.Context, filter DailyWorkloadStatFilter) ([]DailyWorkloadStat, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list daily workload stats")
+	}
+	out := []DailyWorkloadStat{}
+	for _, day := range m.dailyWorkloads {
+		if filter.Namespace != "" && day.Namespace != filter.Namespace {
+			continue
+		}
+		if filter.WorkloadName != "" && day.WorkloadName != filter.WorkloadName {
+			continue
+		}
+		if !filter.StartTime.IsZero() && day.Date.Before(filter.StartTime) {
+			continue
+		}
+		if !filter.EndTime.IsZero() && day.Date.After(filter.EndTime) {
+			continue
+		}
+		out = append(out, day)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Date.Equal(out[j].Date) {
+			return out[i].Date.After(out[j].Date)
+		}
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].WorkloadName < out[j].WorkloadName
+	})
+	return out, nil
+}
+
+func copyStringMap(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+// SaveAPIKey 写入（或按 ID 覆盖）一个 API key，ID 为空时生成。
+func (m *MockRepository) SaveAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	if m.shouldReturnError() {
+		return APIKey{}, dataerr.Unavailable("mock PostgreSQL error: cannot save api key")
+	}
+	if key.ID == "" {
+		key.ID = m.ids.Next("key", key.AccountID, key.Prefix)
+	}
+	key.Scopes = append([]string(nil), key.Scopes...)
+	m.apiKeys[key.ID] = key
+	return key, nil
+}
+
+// GetAPIKeyByHash 按密钥哈希查找 API key（含已吊销与已过期的）。
+func (m *MockRepository) GetAPIKeyByHash(ctx context.Context, secretHash string) (*APIKey, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get api key")
+	}
+	for _, k := range m.apiKeys {
+		if k.SecretHash == secretHash {
+			return &k, nil
+		}
+	}
+	return nil, dataerr.NotFound("api key not found")
+}
+
+// ListAPIKeys 列出服务账号的 API key（accountID 为空时列出全部），按创建时间正序。
+func (m *MockRepository) ListAPIKeys(ctx context.Context, accountID string) ([]APIKey, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list api keys")
+	}
+	out := []APIKey{}
+	for _, k := range m.apiKeys {
+		if accountID == "" || k.AccountID == accountID {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// IncrementAPIKeyUsage 原子地为 key 记一次当日请求：quota > 0 且当日已放行 quota 次时记为拒绝（Rejected），
+// 否则计入 Requests。返回更新后的用量与是否放行。
+func (m *MockRepository) IncrementAPIKeyUsage(ctx context.Context, keyID string, date time.Time, quota int) (APIKeyUsage, bool, error) {
+	if m.shouldReturnError() {
+		return APIKeyUsage{}, false, dataerr.Unavailable("mock PostgreSQL error: cannot record api key usage")
+	}
+	date = date.UTC().Truncate(24 * time.Hour)
+	key := keyID + "/" + date.Format("2006-01-02")
+	u, ok := m.apiKeyUsage[key]
+	if !ok {
+		u = APIKeyUsage{KeyID: keyID, Date: date}
+	}
+	allowed := quota <= 0 || u.Requests < int64(quota)
+	if allowed {
+		u.Requests++
+	} else {
+		u.Rejected++
+	}
+	m.apiKeyUsage[key] = u
+	return u, allowed, nil
+}
+
+// ListAPIKeyUsage 列出 key 在 [startDate, endDate] 的每日用量，按 key、日期排序。
+func (m *MockRepository) ListAPIKeyUsage(ctx context.Context, keyIDs []string, startDate, endDate time.Time) ([]APIKeyUsage, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot list api key usage")
+	}
+	wanted := make(map[string]bool, len(keyIDs))
+	for _, id := range keyIDs {
+		wanted[id] = true
+	}
+	out := []APIKeyUsage{}
+	for _, u := range m.apiKeyUsage {
+		if !wanted[u.KeyID] || u.Date.Before(startDate) || u.Date.After(endDate) {
+			continue
+		}
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].KeyID != out[j].KeyID {
+			return out[i].KeyID < out[j].KeyID
+		}
+		return out[i].Date.Before(out[j].Date)
+	})
+	return out, nil
+}
+
+// SaveSnapshotRegrade 写入（或覆盖）一个快照的重评级视图。
+func (m *MockRepository) SaveSnapshotRegrade(ctx context.Context, regrade SnapshotRegrade) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL error: cannot save snapshot regrade")
+	}
+	if _, ok := m.costSnapshots[regrade.SnapshotID]; !ok {
+		return dataerr.NotFound("cost snapshot not found: %s", regrade.SnapshotID)
+	}
+	m.regrades[regrade.SnapshotID] = regrade
+	return nil
+}
+
+// GetSnapshotRegrade 获取快照的重评级视图。
+func (m *MockRepository) GetSnapshotRegrade(ctx context.Context, snapshotID string) (*SnapshotRegrade, error) {
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot get snapshot regrade")
+	}
+	regrade, ok := m.regrades[snapshotID]
+	if !ok {
+		return nil, dataerr.NotFound("snapshot regrade not found: %s", snapshotID)
+	}
+	return &regrade, nil
+}
+
+// HealthCheck always returns nil (healthy) for mock repository.
+func (m *MockRepository) HealthCheck(ctx context.Context) error {
+	if m.shouldReturnError() {
+		return dataerr.Unavailable("mock PostgreSQL health check failed")
+	}
+	return nil
+}
+
+// BeginTx starts a mock transaction.
+func (m *MockRepository) BeginTx(ctx context.Context) (Transaction, error) {
+	if err := m.simulateLatency(ctx); err != nil {
+		return nil, err
+	}
+
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock PostgreSQL error: cannot begin transaction")
+	}
+
+	if !m.config.EnableTransactions {
+		return nil, errors.New("transactions not enabled in mock configuration")
+	}
+
+	// Create copies of current data for transaction isolation
+	txSnapshots := make(map[string]CostSnapshot)
+	for k, v := range m.costSnapshots {
+		txSnapshots[k] = v
+	}
+
+	txBaselines := make(map[string]ROIBaseline)
+	for k, v := range m.roiBaselines {
+		txBaselines[k] = v
+	}
+
+	txDailyCosts := make(map[string]DailyNamespaceCost)
+	for k, v := range m.dailyNamespaceCosts {
+		txDailyCosts[k] = v
+	}
+
+	txWorkloads := make(map[string]HourlyWorkloadStat)
+	for k, v := range m.hourlyWorkloadStats {
+		txWorkloads[k] = v
+	}
+
+	txMetadata := make(map[string]Metadata)
+	for k, v := range m.metadata {
+		txMetadata[k] = v
+	}
+
+	tx := &MockTransaction{
+		repo:       m,
+		snapshots:  txSnapshots,
+		baselines:  txBaselines,
+		dailyCosts: txDailyCosts,
+		workloads:  txWorkloads,
+		metadata:   txMetadata,
+		committed:  false,
+	}
+
+	return tx, nil
+}
+
+// Commit commits the mock transaction.
+func (tx *MockTransaction) Commit() error {
+	if tx.committed {
+		return dataerr.Conflict("transaction already committed")
+	}
+
+	// Seal snapshot changes in the hash chain (in id order) before applying them
+	ids := make([]string, 0, len(tx.snapshots)+len(tx.repo.costSnapshots))
+	for id := range tx.snapshots {
+		ids = append(ids, id)
+	}
+	for id := range tx.repo.costSnapshots {
+		if _, kept := tx.snapshots[id]; !kept {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		snapshot, kept := tx.snapshots[id]
+		if old, existed := tx.repo.costSnapshots[id]; existed && kept && old.ContentHash == snapshot.ContentHash {
+			continue
+		}
+		tx.repo.appendSnapshotHash(id, snapshot.ContentHash) // "" for a snapshot deleted in the transaction
+	}
+
+	// Apply transaction changes to repository
 	tx.repo.costSnapshots = tx.snapshots
 	tx.repo.roiBaselines = tx.baselines
 	tx.repo.dailyNamespaceCosts = tx.dailyCosts
 	tx.repo.hourlyWorkloadStats = tx.workloads
 	tx.repo.metadata = tx.metadata
+	tx.repo.reindex()
 
 	tx.committed = true
 	return nil
@@ -956,7 +2200,7 @@ func (tx *MockTransaction) Commit() error {
 // Rollback rolls back the mock transaction.
 func (tx *MockTransaction) Rollback() error {
 	if tx.committed {
-		return errors.New("transaction already committed")
+		return dataerr.Conflict("transaction already committed")
 	}
 	// Nothing to do, transaction changes are discarded
 	return nil
@@ -975,12 +2219,17 @@ type transactionRepository struct {
 
 func (tr *transactionRepository) SaveCostSnapshot(ctx context.Context, snapshot CostSnapshot) error {
 	if snapshot.ID == "" {
-		snapshot.ID = fmt.Sprintf("tx-snapshot-%d", tr.tx.repo.rand.Int63())
+		snapshot.ID = tr.tx.repo.ids.Next("tx-snapshot", snapshot.CalculationID, mockid.Time(snapshot.Timestamp))
 	}
 	if snapshot.CreatedAt.IsZero() {
 		snapshot.CreatedAt = time.Now()
 	}
 	snapshot.UpdatedAt = time.Now()
+	hash, err := SnapshotContentHash(snapshot)
+	if err != nil {
+		return err
+	}
+	snapshot.ContentHash = hash // appended to the hash chain on commit
 
 	tr.tx.snapshots[snapshot.ID] = snapshot
 	return nil
@@ -989,7 +2238,7 @@ func (tr *transactionRepository) SaveCostSnapshot(ctx context.Context, snapshot
 func (tr *transactionRepository) GetCostSnapshot(ctx context.Context, id string) (*CostSnapshot, error) {
 	snapshot, exists := tr.tx.snapshots[id]
 	if !exists {
-		return nil, fmt.Errorf("cost snapshot not found: %s", id)
+		return nil, dataerr.NotFound("cost snapshot not found: %s", id)
 	}
 	return &snapshot, nil
 }
@@ -1012,6 +2261,12 @@ func (tr *transactionRepository) ListCostSnapshots(ctx context.Context, filter C
 		if filter.MaxTotalCost > 0 && snapshot.TotalBillableCost > filter.MaxTotalCost {
 			continue
 		}
+		if filter.ExcludeUnconfirmed && snapshot.AwaitingConfirmation() {
+			continue
+		}
+		if filter.ID != "" && snapshot.ID != filter.ID {
+			continue
+		}
 		snapshots = append(snapshots, snapshot)
 	}
 	sort.Slice(snapshots, func(i, j int) bool {
@@ -1028,12 +2283,16 @@ func (tr *transactionRepository) ListCostSnapshots(ctx context.Context, filter C
 	if start >= end {
 		return []CostSnapshot{}, nil
 	}
-	return snapshots[start:end], nil
+	page := snapshots[start:end]
+	for i := range page {
+		page[i] = filter.Fields.Project(page[i])
+	}
+	return page, nil
 }
 
 func (tr *transactionRepository) DeleteCostSnapshot(ctx context.Context, id string) error {
 	if _, exists := tr.tx.snapshots[id]; !exists {
-		return fmt.Errorf("cost snapshot not found: %s", id)
+		return dataerr.NotFound("cost snapshot not found: %s", id)
 	}
 	delete(tr.tx.snapshots, id)
 	return nil
@@ -1041,7 +2300,7 @@ func (tr *transactionRepository) DeleteCostSnapshot(ctx context.Context, id stri
 
 func (tr *transactionRepository) SaveROIBaseline(ctx context.Context, baseline ROIBaseline) error {
 	if baseline.ID == "" {
-		baseline.ID = fmt.Sprintf("tx-roi-%d", tr.tx.repo.rand.Int63())
+		baseline.ID = tr.tx.repo.ids.Next("tx-roi", baseline.Name, baseline.BaselineType, mockid.Time(baseline.TimePeriodStart))
 	}
 	if baseline.CreatedAt.IsZero() {
 		baseline.CreatedAt = time.Now()
@@ -1054,7 +2313,7 @@ func (tr *transactionRepository) SaveROIBaseline(ctx context.Context, baseline R
 func (tr *transactionRepository) GetROIBaseline(ctx context.Context, id string) (*ROIBaseline, error) {
 	baseline, exists := tr.tx.baselines[id]
 	if !exists {
-		return nil, fmt.Errorf("ROI baseline not found: %s", id)
+		return nil, dataerr.NotFound("ROI baseline not found: %s", id)
 	}
 	return &baseline, nil
 }
@@ -1095,13 +2354,16 @@ func (tr *transactionRepository) ListROIBaselines(ctx context.Context, filter RO
 
 func (tr *transactionRepository) DeleteROIBaseline(ctx context.Context, id string) error {
 	if _, exists := tr.tx.baselines[id]; !exists {
-		return fmt.Errorf("ROI baseline not found: %s", id)
+		return dataerr.NotFound("ROI baseline not found: %s", id)
 	}
 	delete(tr.tx.baselines, id)
 	return nil
 }
 
 func (tr *transactionRepository) SaveDailyNamespaceCost(ctx context.Context, cost DailyNamespaceCost) error {
+	if p := tr.tx.repo.closedPeriodOn(cost.Date); p != nil {
+		return periodClosedError(p)
+	}
 	key := fmt.Sprintf("%s-%s", cost.Namespace, cost.Date.Format("2006-01-02"))
 	if cost.CreatedAt.IsZero() {
 		cost.CreatedAt = time.Now()
@@ -1114,7 +2376,7 @@ func (tr *transactionRepository) GetDailyNamespaceCost(ctx context.Context, name
 	key := fmt.Sprintf("%s-%s", namespace, date.Format("2006-01-02"))
 	cost, exists := tr.tx.dailyCosts[key]
 	if !exists {
-		return nil, fmt.Errorf("daily namespace cost not found for %s on %s", namespace, date.Format("2006-01-02"))
+		return nil, dataerr.NotFound("daily namespace cost not found for %s on %s", namespace, date.Format("2006-01-02"))
 	}
 	return &cost, nil
 }
@@ -1199,6 +2461,9 @@ func (tr *transactionRepository) AggregateDailyNamespaceCosts(ctx context.Contex
 }
 
 func (tr *transactionRepository) SaveHourlyWorkloadStat(ctx context.Context, stat HourlyWorkloadStat) error {
+	if p := tr.tx.repo.closedPeriodAt(stat.Timestamp); p != nil {
+		return periodClosedError(p)
+	}
 	key := fmt.Sprintf("%s-%s-%s", stat.Namespace, stat.WorkloadName, stat.Timestamp.Format("2006-01-02-15"))
 	tr.tx.workloads[key] = stat
 	return nil
@@ -1208,7 +2473,7 @@ func (tr *transactionRepository) GetHourlyWorkloadStat(ctx context.Context, name
 	key := fmt.Sprintf("%s-%s-%s", namespace, workloadName, timestamp.Format("2006-01-02-15"))
 	stat, exists := tr.tx.workloads[key]
 	if !exists {
-		return nil, fmt.Errorf("hourly workload stat not found for %s/%s at %s", namespace, workloadName, timestamp.Format("2006-01-02 15:04"))
+		return nil, dataerr.NotFound("hourly workload stat not found for %s/%s at %s", namespace, workloadName, timestamp.Format("2006-01-02 15:04"))
 	}
 	return &stat, nil
 }
@@ -1225,6 +2490,9 @@ func (tr *transactionRepository) ListHourlyWorkloadStats(ctx context.Context, fi
 		if filter.NodeName != "" && stat.NodeName != filter.NodeName {
 			continue
 		}
+		if filter.NodePool != "" && stat.NodePool != filter.NodePool {
+			continue
+		}
 		if !filter.StartTime.IsZero() && stat.Timestamp.Before(filter.StartTime) {
 			continue
 		}
@@ -1280,6 +2548,7 @@ func (tr *transactionRepository) AggregateHourlyWorkloadStats(ctx context.Contex
 				WorkloadName:      stat.WorkloadName,
 				WorkloadType:      stat.WorkloadType,
 				NodeName:          stat.NodeName,
+				NodePool:          stat.NodePool,
 				PodName:           stat.PodName,
 				Timestamp:         stat.Timestamp,
 				CPURequest:        stat.CPURequest,
@@ -1323,7 +2592,7 @@ func (tr *transactionRepository) SaveMetadata(ctx context.Context, metadata Meta
 func (tr *transactionRepository) GetMetadata(ctx context.Context, key string) (*Metadata, error) {
 	metadata, exists := tr.tx.metadata[key]
 	if !exists {
-		return nil, fmt.Errorf("metadata not found: %s", key)
+		return nil, dataerr.NotFound("metadata not found: %s", key)
 	}
 	return &metadata, nil
 }
@@ -1331,7 +2600,7 @@ func (tr *transactionRepository) GetMetadata(ctx context.Context, key string) (*
 func (tr *transactionRepository) ListMetadata(ctx context.Context, filter MetadataFilter) ([]Metadata, error) {
 	var result []Metadata
 	for key, metadata := range tr.tx.metadata {
-		if filter.KeyPrefix != "" && len(key) >= len(filter.KeyPrefix) && key[:len(filter.KeyPrefix)] != filter.KeyPrefix {
+		if filter.KeyPrefix != "" && !strings.HasPrefix(key, filter.KeyPrefix) {
 			continue
 		}
 		if filter.CreatedBy != "" && metadata.CreatedBy != filter.CreatedBy {
@@ -1358,7 +2627,7 @@ func (tr *transactionRepository) ListMetadata(ctx context.Context, filter Metada
 
 func (tr *transactionRepository) DeleteMetadata(ctx context.Context, key string) error {
 	if _, exists := tr.tx.metadata[key]; !exists {
-		return fmt.Errorf("metadata not found: %s", key)
+		return dataerr.NotFound("metadata not found: %s", key)
 	}
 	delete(tr.tx.metadata, key)
 	return nil
@@ -1374,11 +2643,9 @@ func (tr *transactionRepository) BeginTx(ctx context.Context) (Transaction, erro
 
 // Helper methods for MockRepository
 
-func (m *MockRepository) simulateLatency() error {
-	if m.config.LatencyMs > 0 {
-		time.Sleep(time.Duration(m.config.LatencyMs) * time.Millisecond)
-	}
-	return nil
+// simulateLatency waits out the configured latency, returning ctx.Err() early once ctx is done.
+func (m *MockRepository) simulateLatency(ctx context.Context) error {
+	return m.latency.Wait(ctx)
 }
 
 func (m *MockRepository) shouldReturnError() bool {
@@ -1396,6 +2663,7 @@ func (m *MockRepository) initializeData() {
 	// Initialize cost snapshots
 	for i := 0; i < m.config.InitialDataCount["cost_snapshots"]; i++ {
 		snapshot := m.generateCostSnapshot(i)
+		_ = m.sealSnapshot(&snapshot) // generated snapshots always marshal
 		m.costSnapshots[snapshot.ID] = snapshot
 	}
 
@@ -1409,14 +2677,14 @@ func (m *MockRepository) initializeData() {
 	for i := 0; i < m.config.InitialDataCount["daily_namespace_costs"]; i++ {
 		cost := m.generateDailyNamespaceCost(i)
 		key := fmt.Sprintf("%s-%s", cost.Namespace, cost.Date.Format("2006-01-02"))
-		m.dailyNamespaceCosts[key] = cost
+		m.putDailyNamespaceCost(key, cost)
 	}
 
 	// Initialize hourly workload stats
 	for i := 0; i < m.config.InitialDataCount["hourly_workload_stats"]; i++ {
 		stat := m.generateHourlyWorkloadStat(i)
 		key := fmt.Sprintf("%s-%s-%s", stat.Namespace, stat.WorkloadName, stat.Timestamp.Format("2006-01-02-15"))
-		m.hourlyWorkloadStats[key] = stat
+		m.putHourlyWorkloadStat(key, stat)
 	}
 
 	// Initialize metadata
@@ -1453,8 +2721,8 @@ func (m *MockRepository) generateCostSnapshot(index int) CostSnapshot {
 	}
 
 	return CostSnapshot{
-		ID:                     fmt.Sprintf("snapshot-%d", index),
-		CalculationID:          fmt.Sprintf("calc-%d", index),
+		ID:                     m.ids.Indexed("snapshot", index),
+		CalculationID:          m.ids.Indexed("calc", index),
 		Timestamp:              timestamp,
 		TimeRangeStart:         timestamp.Add(-24 * time.Hour),
 		TimeRangeEnd:           timestamp,
@@ -1490,7 +2758,7 @@ func (m *MockRepository) generateROIBaseline(index int) ROIBaseline {
 	}
 
 	return ROIBaseline{
-		ID:              fmt.Sprintf("roi-%d", index),
+		ID:              m.ids.Indexed("roi", index),
 		Name:            fmt.Sprintf("%s-baseline-%d", baselineType, index),
 		Description:     fmt.Sprintf("Mock %s baseline for testing", baselineType),
 		BaselineType:    baselineType,
@@ -0,0 +1,162 @@
+// Package postgres provides repository implementations for PostgreSQL storage.
+// mock_index.go: Mock 表的二级索引（按 namespace、按时间桶），与 schema.sql 中的 (namespace, time) / (time) 索引对应，
+// 让 List* / Aggregate* 只访问过滤条件可能命中的行，而不是扫描全表。
+package postgres
+
+import (
+	"sort"
+	"time"
+)
+
+// timeIndex indexes the row keys of a mock table by time bucket, once over all rows and once per
+// namespace, so a scan visits only the buckets of the requested window, newest first.
+type timeIndex struct {
+	width       time.Duration
+	all         *bucketIndex
+	byNamespace map[string]*bucketIndex
+}
+
+// bucketIndex maps bucket starts (unix seconds) to the keys of the rows in the bucket.
+type bucketIndex struct {
+	rows    map[int64]map[string]struct{}
+	buckets []int64 // ascending
+}
+
+func newTimeIndex(width time.Duration) *timeIndex {
+	return &timeIndex{width: width, all: newBucketIndex(), byNamespace: make(map[string]*bucketIndex)}
+}
+
+func newBucketIndex() *bucketIndex {
+	return &bucketIndex{rows: make(map[int64]map[string]struct{})}
+}
+
+func (x *timeIndex) bucketOf(t time.Time) int64 {
+	return t.UTC().Truncate(x.width).Unix()
+}
+
+func (x *timeIndex) add(key, namespace string, t time.Time) {
+	b := x.bucketOf(t)
+	x.all.add(key, b)
+	ns, ok := x.byNamespace[namespace]
+	if !ok {
+		ns = newBucketIndex()
+		x.byNamespace[namespace] = ns
+	}
+	ns.add(key, b)
+}
+
+func (x *timeIndex) remove(key, namespace string, t time.Time) {
+	b := x.bucketOf(t)
+	x.all.remove(key, b)
+	if ns, ok := x.byNamespace[namespace]; ok {
+		ns.remove(key, b)
+		if len(ns.buckets) == 0 {
+			delete(x.byNamespace, namespace)
+		}
+	}
+}
+
+// scan calls fn with the keys of each bucket that may hold rows of namespace ("" for all) within
+// [start, end] (zero: unbounded), newest bucket first, until fn returns false. Buckets at the edges
+// of the window can hold rows outside it, so callers still apply their filters.
+func (x *timeIndex) scan(namespace string, start, end time.Time, fn func(keys map[string]struct{}) bool) {
+	idx := x.all
+	if namespace != "" {
+		var ok bool
+		if idx, ok = x.byNamespace[namespace]; !ok {
+			return
+		}
+	}
+	lo, hi := 0, len(idx.buckets)
+	if !start.IsZero() {
+		from := x.bucketOf(start)
+		lo = sort.Search(len(idx.buckets), func(i int) bool { return idx.buckets[i] >= from })
+	}
+	if !end.IsZero() {
+		to := x.bucketOf(end)
+		hi = sort.Search(len(idx.buckets), func(i int) bool { return idx.buckets[i] > to })
+	}
+	for i := hi - 1; i >= lo; i-- {
+		if !fn(idx.rows[idx.buckets[i]]) {
+			return
+		}
+	}
+}
+
+func (b *bucketIndex) add(key string, bucket int64) {
+	keys, ok := b.rows[bucket]
+	if !ok {
+		keys = make(map[string]struct{})
+		b.rows[bucket] = keys
+		i := sort.Search(len(b.buckets), func(i int) bool { return b.buckets[i] >= bucket })
+		b.buckets = append(b.buckets, 0)
+		copy(b.buckets[i+1:], b.buckets[i:])
+		b.buckets[i] = bucket
+	}
+	keys[key] = struct{}{}
+}
+
+func (b *bucketIndex) remove(key string, bucket int64) {
+	keys, ok := b.rows[bucket]
+	if !ok {
+		return
+	}
+	delete(keys, key)
+	if len(keys) > 0 {
+		return
+	}
+	delete(b.rows, bucket)
+	i := sort.Search(len(b.buckets), func(i int) bool { return b.buckets[i] >= bucket })
+	if i < len(b.buckets) && b.buckets[i] == bucket {
+		b.buckets = append(b.buckets[:i], b.buckets[i+1:]...)
+	}
+}
+
+// pageLimit is the number of sorted rows a list needs before it can stop scanning older buckets;
+// -1 when the list is unbounded.
+func pageLimit(limit, offset int) int {
+	if limit <= 0 {
+		return -1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset + limit
+}
+
+// putHourlyWorkloadStat stores stat under key and keeps hourlyIndex in sync.
+func (m *MockRepository) putHourlyWorkloadStat(key string, stat HourlyWorkloadStat) {
+	if old, ok := m.hourlyWorkloadStats[key]; ok {
+		m.hourlyIndex.remove(key, old.Namespace, old.Timestamp)
+	}
+	m.hourlyWorkloadStats[key] = stat
+	m.hourlyIndex.add(key, stat.Namespace, stat.Timestamp)
+}
+
+func (m *MockRepository) deleteHourlyWorkloadStat(key string) {
+	if old, ok := m.hourlyWorkloadStats[key]; ok {
+		m.hourlyIndex.remove(key, old.Namespace, old.Timestamp)
+		delete(m.hourlyWorkloadStats, key)
+	}
+}
+
+// putDailyNamespaceCost stores cost under key and keeps dailyIndex in sync.
+func (m *MockRepository) putDailyNamespaceCost(key string, cost DailyNamespaceCost) {
+	if old, ok := m.dailyNamespaceCosts[key]; ok {
+		m.dailyIndex.remove(key, old.Namespace, old.Date)
+	}
+	m.dailyNamespaceCosts[key] = cost
+	m.dailyIndex.add(key, cost.Namespace, cost.Date)
+}
+
+// reindex rebuilds the secondary indexes, e.g. after a transaction replaced the tables.
+func (m *MockRepository) reindex() {
+	m.hourlyIndex = newTimeIndex(time.Hour)
+	for key, stat := range m.hourlyWorkloadStats {
+		m.hourlyIndex.add(key, stat.Namespace, stat.Timestamp)
+	}
+	m.dailyIndex = newTimeIndex(24 * time.Hour)
+	for key, cost := range m.dailyNamespaceCosts {
+		m.dailyIndex.add(key, cost.Namespace, cost.Date)
+	}
+}
@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newEmptyMockRepository() *MockRepository {
+	config := DefaultMockConfig()
+	config.Scenario = "empty"
+	config.LatencyMs = 0
+	return NewMockRepository(config)
+}
+
+// scanHourlyWorkloadStats is the full-table scan the index replaced: the reference result for the
+// index tests and the baseline for the benchmarks.
+func scanHourlyWorkloadStats(m *MockRepository, filter HourlyWorkloadStatFilter) []HourlyWorkloadStat {
+	var stats []HourlyWorkloadStat
+	for _, stat := range m.hourlyWorkloadStats {
+		if filter.Namespace != "" && stat.Namespace != filter.Namespace {
+			continue
+		}
+		if filter.WorkloadName != "" && stat.WorkloadName != filter.WorkloadName {
+			continue
+		}
+		if !filter.StartTime.IsZero() && stat.Timestamp.Before(filter.StartTime) {
+			continue
+		}
+		if !filter.EndTime.IsZero() && stat.Timestamp.After(filter.EndTime) {
+			continue
+		}
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Timestamp.After(stats[j].Timestamp)
+	})
+	return stats
+}
+
+// workloadStatKeys identifies rows for comparison. Rows with equal timestamps have no defined
+// order, so the keys are sorted within each timestamp.
+func workloadStatKeys(stats []HourlyWorkloadStat) []string {
+	keys := make([]string, len(stats))
+	for i, stat := range stats {
+		keys[i] = fmt.Sprintf("%s/%s/%s", stat.Timestamp.Format(time.RFC3339), stat.Namespace, stat.WorkloadName)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	return keys
+}
+
+func workloadStatTimes(stats []HourlyWorkloadStat) []string {
+	times := make([]string, len(stats))
+	for i, stat := range stats {
+		times[i] = stat.Timestamp.Format(time.RFC3339)
+	}
+	return times
+}
+
+func TestMockRepository_SecondaryIndexes(t *testing.T) {
+	ctx := context.Background()
+	repo := newEmptyMockRepository()
+	base := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for h := 0; h < 72; h++ {
+		for i, ns := range []string{"shop", "pay", "ops"} {
+			if (h+i)%4 == 0 {
+				continue
+			}
+			stat := HourlyWorkloadStat{Namespace: ns, WorkloadName: fmt.Sprintf("api-%d", h%3), Timestamp: base.Add(time.Duration(h)*time.Hour + 30*time.Minute)}
+			if err := repo.SaveHourlyWorkloadStat(ctx, stat); err != nil {
+				t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+			}
+		}
+		if h%24 == 0 {
+			cost := DailyNamespaceCost{Namespace: "shop", Date: base.Add(time.Duration(h) * time.Hour), BillableCost: float64(h)}
+			if err := repo.SaveDailyNamespaceCost(ctx, cost); err != nil {
+				t.Fatalf("SaveDailyNamespaceCost: %v", err)
+			}
+		}
+	}
+
+	filters := []HourlyWorkloadStatFilter{
+		{},
+		{Namespace: "shop"},
+		{Namespace: "shop", WorkloadName: "api-1"},
+		{Namespace: "missing"},
+		{StartTime: base.Add(10*time.Hour + 45*time.Minute), EndTime: base.Add(20 * time.Hour)},
+		{Namespace: "pay", StartTime: base.Add(24 * time.Hour)},
+		{Namespace: "ops", EndTime: base.Add(5*time.Hour + 30*time.Minute)},
+	}
+	check := func(stage string) {
+		t.Helper()
+		for _, filter := range filters {
+			all := scanHourlyWorkloadStats(repo, filter)
+			want := workloadStatTimes(all)
+			for _, page := range [][2]int{{0, 0}, {5, 0}, {5, 3}, {1, 0}} {
+				f := filter
+				f.Limit, f.Offset = page[0], page[1]
+				got, err := repo.ListHourlyWorkloadStats(ctx, f)
+				if err != nil {
+					t.Fatalf("%s: ListHourlyWorkloadStats(%+v): %v", stage, f, err)
+				}
+				exp := want
+				if f.Limit > 0 {
+					if f.Offset >= len(exp) {
+						exp = nil
+					} else {
+						exp = exp[f.Offset:]
+					}
+					if len(exp) > f.Limit {
+						exp = exp[:f.Limit]
+					}
+				}
+				if g := workloadStatTimes(got); fmt.Sprint(g) != fmt.Sprint(exp) {
+					t.Errorf("%s: ListHourlyWorkloadStats(%+v) timestamps = %v, want %v", stage, f, g, exp)
+				}
+				if f.Limit == 0 && fmt.Sprint(workloadStatKeys(got)) != fmt.Sprint(workloadStatKeys(all)) {
+					t.Errorf("%s: ListHourlyWorkloadStats(%+v) rows differ from a full scan", stage, f)
+				}
+			}
+		}
+	}
+	check("initial")
+
+	// Overwriting a row keeps a single index entry for its key
+	moved := HourlyWorkloadStat{Namespace: "shop", WorkloadName: "api-1", Timestamp: base.Add(time.Hour + 40*time.Minute)}
+	if err := repo.SaveHourlyWorkloadStat(ctx, moved); err != nil {
+		t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+	}
+	check("overwrite")
+
+	// Downsampling removes rows from the index
+	if _, err := repo.DownsampleHourlyWorkloadStats(ctx, base.Add(24*time.Hour)); err != nil {
+		t.Fatalf("DownsampleHourlyWorkloadStats: %v", err)
+	}
+	check("downsample")
+	if got := repo.hourlyIndex.all.buckets; len(got) != 48 || got[0] != base.Add(24*time.Hour).Unix() {
+		t.Errorf("hourly buckets after downsample: %d starting at %v, want 48 starting at day 2", len(got), time.Unix(got[0], 0).UTC())
+	}
+
+	// A committed transaction replaces the tables; the indexes follow
+	tx, err := repo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if err := tx.Repository().SaveHourlyWorkloadStat(ctx, HourlyWorkloadStat{Namespace: "tx", WorkloadName: "job", Timestamp: base.Add(70 * time.Hour)}); err != nil {
+		t.Fatalf("tx SaveHourlyWorkloadStat: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	filters = append(filters, HourlyWorkloadStatFilter{Namespace: "tx"})
+	check("transaction")
+
+	costs, err := repo.ListDailyNamespaceCosts(ctx, DailyNamespaceCostFilter{Namespace: "shop", StartDate: base.Add(24 * time.Hour), Limit: 1})
+	if err != nil {
+		t.Fatalf("ListDailyNamespaceCosts: %v", err)
+	}
+	if len(costs) != 1 || !costs[0].Date.Equal(base.Add(48*time.Hour)) {
+		t.Errorf("latest shop cost = %+v, want day 3", costs)
+	}
+}
+
+const benchmarkRows = 1_000_000
+
+var (
+	benchmarkRepoOnce sync.Once
+	benchmarkRepo     *MockRepository
+	benchmarkBase     = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// benchmarkRepository holds 1M hourly workload stats: 200 namespaces x 5 workloads x 1000 hours.
+func benchmarkRepository(b *testing.B) *MockRepository {
+	b.Helper()
+	benchmarkRepoOnce.Do(func() {
+		repo := newEmptyMockRepository()
+		for h := 0; h < benchmarkRows/1000; h++ {
+			ts := benchmarkBase.Add(time.Duration(h) * time.Hour)
+			for ns := 0; ns < 200; ns++ {
+				namespace := fmt.Sprintf("ns-%03d", ns)
+				for w := 0; w < 5; w++ {
+					stat := HourlyWorkloadStat{Namespace: namespace, WorkloadName: fmt.Sprintf("app-%d", w), Timestamp: ts, TotalBillableCost: 1}
+					repo.putHourlyWorkloadStat(fmt.Sprintf("%s-%s-%s", stat.Namespace, stat.WorkloadName, ts.Format("2006-01-02-15")), stat)
+				}
+			}
+		}
+		benchmarkRepo = repo
+	})
+	return benchmarkRepo
+}
+
+func BenchmarkListHourlyWorkloadStats(b *testing.B) {
+	repo := benchmarkRepository(b)
+	ctx := context.Background()
+	cases := []struct {
+		name   string
+		filter HourlyWorkloadStatFilter
+	}{
+		{"namespace", HourlyWorkloadStatFilter{Namespace: "ns-042"}},
+		{"namespace_window", HourlyWorkloadStatFilter{Namespace: "ns-042", StartTime: benchmarkBase.Add(500 * time.Hour), EndTime: benchmarkBase.Add(523 * time.Hour)}},
+		{"window", HourlyWorkloadStatFilter{StartTime: benchmarkBase.Add(500 * time.Hour), EndTime: benchmarkBase.Add(501 * time.Hour)}},
+		{"latest", HourlyWorkloadStatFilter{Limit: 1}},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.ListHourlyWorkloadStats(ctx, tc.filter); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(tc.name+"/full_scan", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				scanHourlyWorkloadStats(repo, tc.filter)
+			}
+		})
+	}
+}
+
+func BenchmarkAggregateHourlyWorkloadStats(b *testing.B) {
+	repo := benchmarkRepository(b)
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.AggregateHourlyWorkloadStats(ctx, benchmarkBase.Add(900*time.Hour), benchmarkBase.Add(924*time.Hour)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
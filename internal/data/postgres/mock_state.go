@@ -0,0 +1,154 @@
+// Package postgres mock_state.go: MockRepository 全部表的可序列化快照，storage 的 file 驱动据此整体落盘与恢复。
+package postgres
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
+)
+
+// MockState MockRepository 各表的内容（map 表按主键排序）。前五项的 JSON 名沿用 file 驱动最初的落盘格式。
+type MockState struct {
+	CostSnapshots       []CostSnapshot       `json:"cost_snapshots"`
+	ROIBaselines        []ROIBaseline        `json:"roi_baselines"`
+	DailyNamespaceCosts []DailyNamespaceCost `json:"daily_namespace_costs"`
+	HourlyWorkloadStats []HourlyWorkloadStat `json:"hourly_workload_stats"`
+	Metadata            []Metadata           `json:"metadata"`
+
+	BillAccountSummaries []BillAccountSummary             `json:"bill_account_summaries,omitempty"`
+	DailyStorageCosts    []DailyStorageCost               `json:"daily_storage_costs,omitempty"`
+	DailyNetworkCosts    []DailyNetworkCost               `json:"daily_network_costs,omitempty"`
+	CalculationRuns      []CalculationRun                 `json:"calculation_runs,omitempty"`
+	NamespaceLifecycles  []NamespaceLifecycle             `json:"namespace_lifecycles,omitempty"`
+	GradeChangeEvents    []GradeChangeEvent               `json:"grade_change_events,omitempty"`
+	PriceVersions        []PriceVersion                   `json:"price_versions,omitempty"`
+	PriceChanges         []PriceChange                    `json:"price_changes,omitempty"`
+	SnapshotHashChain    []SnapshotHashRecord             `json:"snapshot_hash_chain,omitempty"`
+	Recommendations      []IssuedRecommendation           `json:"recommendations,omitempty"`
+	ServiceAccounts      []ServiceAccount                 `json:"service_accounts,omitempty"`
+	APIKeys              []APIKey                         `json:"api_keys,omitempty"`
+	APIKeyUsage          []APIKeyUsage                    `json:"api_key_usage,omitempty"`
+	Regrades             []SnapshotRegrade                `json:"regrades,omitempty"`
+	AlertRules           []AlertRule                      `json:"alert_rules,omitempty"`
+	NotificationRoutes   []NotificationRoute              `json:"notification_routes,omitempty"`
+	NotificationSilences []NotificationSilence            `json:"notification_silences,omitempty"`
+	QueryLineages        []QueryLineage                   `json:"query_lineages,omitempty"`
+	DailyWorkloadStats   []DailyWorkloadStat              `json:"daily_workload_stats,omitempty"`
+	ConfigChanges        []ConfigChange                   `json:"config_changes,omitempty"`
+	SLOViolations        []SLOViolation                   `json:"slo_violations,omitempty"`
+	DependencyEdges      []DependencyEdge                 `json:"dependency_edges,omitempty"`
+	AccountingPeriods    []AccountingPeriod               `json:"accounting_periods,omitempty"`
+	Adjustments          []Adjustment                     `json:"adjustments,omitempty"`
+	Optimizations        []roi.OptimizationTrackingRecord `json:"optimizations,omitempty"`
+}
+
+// State 返回全部表的内容。
+func (m *MockRepository) State() MockState {
+	return MockState{
+		CostSnapshots:        sortedValues(m.costSnapshots),
+		ROIBaselines:         sortedValues(m.roiBaselines),
+		DailyNamespaceCosts:  sortedValues(m.dailyNamespaceCosts),
+		HourlyWorkloadStats:  sortedValues(m.hourlyWorkloadStats),
+		Metadata:             sortedValues(m.metadata),
+		BillAccountSummaries: sortedValues(m.billAccountSummaries),
+		DailyStorageCosts:    sortedValues(m.dailyStorageCosts),
+		DailyNetworkCosts:    sortedValues(m.dailyNetworkCosts),
+		CalculationRuns:      sortedValues(m.calculationRuns),
+		NamespaceLifecycles:  sortedValues(m.namespaceLifecycles),
+		GradeChangeEvents:    slices.Clone(m.gradeChangeEvents),
+		PriceVersions:        sortedValues(m.priceVersions),
+		PriceChanges:         sortedValues(m.priceChanges),
+		SnapshotHashChain:    slices.Clone(m.snapshotHashChain),
+		Recommendations:      sortedValues(m.recommendations),
+		ServiceAccounts:      sortedValues(m.serviceAccounts),
+		APIKeys:              sortedValues(m.apiKeys),
+		APIKeyUsage:          sortedValues(m.apiKeyUsage),
+		Regrades:             sortedValues(m.regrades),
+		AlertRules:           sortedValues(m.alertRules),
+		NotificationRoutes:   sortedValues(m.notifyRoutes),
+		NotificationSilences: sortedValues(m.silences),
+		QueryLineages:        sortedValues(m.lineages),
+		DailyWorkloadStats:   sortedValues(m.dailyWorkloads),
+		ConfigChanges:        sortedValues(m.configChanges),
+		SLOViolations:        sortedValues(m.sloViolations),
+		DependencyEdges:      sortedValues(m.dependencyEdges),
+		AccountingPeriods:    sortedValues(m.accountingPeriods),
+		Adjustments:          slices.Clone(m.adjustments),
+		Optimizations:        sortedValues(m.optimizations),
+	}
+}
+
+// Restore 用 s 替换全部表的内容（含二级索引），不经过 Save* 的校验与关账检查，也不追加哈希链。
+func (m *MockRepository) Restore(s MockState) {
+	m.costSnapshots = keyed(s.CostSnapshots, func(v CostSnapshot) string { return v.ID })
+	m.roiBaselines = keyed(s.ROIBaselines, func(v ROIBaseline) string { return v.ID })
+	m.dailyNamespaceCosts = make(map[string]DailyNamespaceCost, len(s.DailyNamespaceCosts))
+	m.dailyIndex = newTimeIndex(m.dailyIndex.width)
+	for _, v := range s.DailyNamespaceCosts {
+		m.putDailyNamespaceCost(fmt.Sprintf("%s-%s", v.Namespace, v.Date.Format("2006-01-02")), v)
+	}
+	m.hourlyWorkloadStats = make(map[string]HourlyWorkloadStat, len(s.HourlyWorkloadStats))
+	m.hourlyIndex = newTimeIndex(m.hourlyIndex.width)
+	for _, v := range s.HourlyWorkloadStats {
+		m.putHourlyWorkloadStat(fmt.Sprintf("%s-%s-%s", v.Namespace, v.WorkloadName, v.Timestamp.Format("2006-01-02-15")), v)
+	}
+	m.metadata = keyed(s.Metadata, func(v Metadata) string { return v.Key })
+	m.billAccountSummaries = keyed(s.BillAccountSummaries, func(v BillAccountSummary) string {
+		return billAccountSummaryKey(v.AccountID, v.PeriodType, v.PeriodStart)
+	})
+	m.dailyStorageCosts = keyed(s.DailyStorageCosts, func(v DailyStorageCost) string {
+		return dailyStorageCostKey(v.Day, v.Namespace, v.PVCName)
+	})
+	m.dailyNetworkCosts = keyed(s.DailyNetworkCosts, func(v DailyNetworkCost) string {
+		return dailyNetworkCostKey(v.Day, v.Namespace, v.ResourceID)
+	})
+	m.calculationRuns = keyed(s.CalculationRuns, func(v CalculationRun) string { return v.ID })
+	m.namespaceLifecycles = keyed(s.NamespaceLifecycles, func(v NamespaceLifecycle) string { return v.Namespace })
+	m.gradeChangeEvents = slices.Clone(s.GradeChangeEvents)
+	m.priceVersions = keyed(s.PriceVersions, func(v PriceVersion) int64 { return v.EffectiveFrom.Unix() })
+	m.priceChanges = keyed(s.PriceChanges, func(v PriceChange) string { return v.ID })
+	m.snapshotHashChain = slices.Clone(s.SnapshotHashChain)
+	m.recommendations = keyed(s.Recommendations, func(v IssuedRecommendation) string {
+		return v.Namespace + "/" + v.WorkloadName + "/" + v.Resource
+	})
+	m.serviceAccounts = keyed(s.ServiceAccounts, func(v ServiceAccount) string { return v.ID })
+	m.apiKeys = keyed(s.APIKeys, func(v APIKey) string { return v.ID })
+	m.apiKeyUsage = keyed(s.APIKeyUsage, func(v APIKeyUsage) string { return v.KeyID + "/" + v.Date.Format("2006-01-02") })
+	m.regrades = keyed(s.Regrades, func(v SnapshotRegrade) string { return v.SnapshotID })
+	m.alertRules = keyed(s.AlertRules, func(v AlertRule) string { return v.ID })
+	m.notifyRoutes = keyed(s.NotificationRoutes, func(v NotificationRoute) string { return v.ID })
+	m.silences = keyed(s.NotificationSilences, func(v NotificationSilence) string { return v.ID })
+	m.lineages = keyed(s.QueryLineages, func(v QueryLineage) string { return v.ID })
+	m.dailyWorkloads = keyed(s.DailyWorkloadStats, func(v DailyWorkloadStat) string {
+		return fmt.Sprintf("%s-%s-%s", v.Namespace, v.WorkloadName, v.Date.Format("2006-01-02"))
+	})
+	m.configChanges = keyed(s.ConfigChanges, func(v ConfigChange) string { return v.ID })
+	m.sloViolations = keyed(s.SLOViolations, func(v SLOViolation) string { return v.ID })
+	m.dependencyEdges = keyed(s.DependencyEdges, func(v DependencyEdge) string {
+		return v.CallerNamespace + "/" + v.Caller + "/" + v.CalleeNamespace + "/" + v.Callee + "/" + v.Source
+	})
+	m.accountingPeriods = keyed(s.AccountingPeriods, func(v AccountingPeriod) string { return v.StartDate.Format("2006-01-02") })
+	m.adjustments = slices.Clone(s.Adjustments)
+	m.optimizations = keyed(s.Optimizations, func(v roi.OptimizationTrackingRecord) string { return v.RecordID })
+}
+
+// sortedValues 按主键顺序返回 m 的值，nil map 返回空切片。
+func sortedValues[K cmp.Ordered, V any](m map[K]V) []V {
+	out := make([]V, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		out = append(out, m[k])
+	}
+	return out
+}
+
+// keyed 以 key(v) 为主键建表，后出现的值覆盖先出现的。
+func keyed[K comparable, V any](values []V, key func(V) K) map[K]V {
+	out := make(map[K]V, len(values))
+	for _, v := range values {
+		out[key(v)] = v
+	}
+	return out
+}
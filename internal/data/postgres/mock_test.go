@@ -2,9 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockid"
 	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 )
 
@@ -17,6 +21,47 @@ func TestNewMockRepository(t *testing.T) {
 	}
 }
 
+func TestMockRepository_LatencyObservesContext(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 1000
+	repo := NewMockRepository(config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := repo.ListCostSnapshots(ctx, CostSnapshotFilter{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("mock kept sleeping after the deadline: %v", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	config.LatencyMs = 0
+	if err := NewMockRepository(config).SaveCostSnapshot(canceled, CostSnapshot{ID: "s"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Canceled without latency, got %v", err)
+	}
+}
+
+func TestMockRepository_ErrorKinds(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	repo := NewMockRepository(config)
+	if _, err := repo.GetCostSnapshot(ctx, "missing"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("missing snapshot: expected ErrNotFound, got %v", err)
+	}
+	if _, err := repo.GetCalculationRun(ctx, "missing"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("missing run: expected ErrNotFound, got %v", err)
+	}
+
+	config.ErrorRate = 1
+	if _, err := NewMockRepository(config).ListCostSnapshots(ctx, CostSnapshotFilter{}); !errors.Is(err, dataerr.ErrUnavailable) {
+		t.Errorf("injected failure: expected ErrUnavailable, got %v", err)
+	}
+}
+
 func TestMockRepository_SaveAndGetCostSnapshot(t *testing.T) {
 	ctx := context.Background()
 	repo := NewMockRepository(DefaultMockConfig())
@@ -736,3 +781,248 @@ func TestMockRepository_DeterministicGeneration(t *testing.T) {
 		}
 	}
 }
+
+func TestMockRepository_SnapshotHashChain(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultMockConfig()
+	config.Scenario = "empty"
+	repo := NewMockRepository(config)
+
+	verify := func() SnapshotVerification {
+		t.Helper()
+		snapshots, err := repo.ListCostSnapshots(ctx, CostSnapshotFilter{})
+		if err != nil {
+			t.Fatalf("ListCostSnapshots failed: %v", err)
+		}
+		chain, err := repo.ListSnapshotHashChain(ctx)
+		if err != nil {
+			t.Fatalf("ListSnapshotHashChain failed: %v", err)
+		}
+		return VerifySnapshotChain(snapshots, chain)
+	}
+	problems := func(v SnapshotVerification) map[string]string {
+		out := make(map[string]string)
+		for _, issue := range v.Issues {
+			out[issue.SnapshotID] = issue.Problem
+		}
+		return out
+	}
+
+	now := time.Now()
+	for _, id := range []string{"snap-a", "snap-b", "snap-c"} {
+		s := CostSnapshot{ID: id, Timestamp: now, TotalBillableCost: 100, Metadata: map[string]interface{}{"source": "test"}}
+		if err := repo.SaveCostSnapshot(ctx, s); err != nil {
+			t.Fatalf("SaveCostSnapshot failed: %v", err)
+		}
+	}
+	// A legitimate update and delete are sealed in the chain as well
+	updated, _ := repo.GetCostSnapshot(ctx, "snap-a")
+	updated.TotalBillableCost = 120
+	if err := repo.SaveCostSnapshot(ctx, *updated); err != nil {
+		t.Fatalf("SaveCostSnapshot failed: %v", err)
+	}
+	if err := repo.DeleteCostSnapshot(ctx, "snap-c"); err != nil {
+		t.Fatalf("DeleteCostSnapshot failed: %v", err)
+	}
+	if v := verify(); !v.OK() || v.ChainLength != 5 || v.HeadHash == "" {
+		t.Fatalf("untampered repository: chain %d, head %q, issues %+v", v.ChainLength, v.HeadHash, v.Issues)
+	}
+
+	// Out-of-band edit of a saved snapshot
+	tampered := repo.costSnapshots["snap-b"]
+	tampered.TotalBillableCost = 1
+	repo.costSnapshots["snap-b"] = tampered
+	if got := problems(verify())["snap-b"]; got != IntegrityContentMismatch {
+		t.Errorf("edited snapshot: problem %q, want %q", got, IntegrityContentMismatch)
+	}
+	// Re-hashing the edit is not enough: the chain still records the original hash
+	tampered.ContentHash, _ = SnapshotContentHash(tampered)
+	repo.costSnapshots["snap-b"] = tampered
+	if got := problems(verify())["snap-b"]; got != IntegrityNotInChain {
+		t.Errorf("re-hashed snapshot: problem %q, want %q", got, IntegrityNotInChain)
+	}
+
+	// Out-of-band delete and rewriting a chain record
+	delete(repo.costSnapshots, "snap-a")
+	repo.snapshotHashChain[1].ContentHash = repo.snapshotHashChain[3].ContentHash
+	v := verify()
+	if got := problems(v)["snap-a"]; got != IntegrityMissing {
+		t.Errorf("removed snapshot: problem %q, want %q", got, IntegrityMissing)
+	}
+	broken := false
+	for _, issue := range v.Issues {
+		broken = broken || (issue.Problem == IntegrityChainBroken && issue.Seq == 2)
+	}
+	if !broken {
+		t.Errorf("rewritten chain record not reported: %+v", v.Issues)
+	}
+}
+
+func TestMockRepository_TransactionSealsSnapshots(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository(DefaultMockConfig())
+	before, _ := repo.ListSnapshotHashChain(ctx)
+
+	tx, _ := repo.BeginTx(ctx)
+	if err := tx.Repository().SaveCostSnapshot(ctx, CostSnapshot{ID: "tx-sealed", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("SaveCostSnapshot in transaction failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	chain, _ := repo.ListSnapshotHashChain(ctx)
+	if len(chain) != len(before)+1 || chain[len(chain)-1].SnapshotID != "tx-sealed" {
+		t.Fatalf("expected one chain record for the committed snapshot, chain grew %d -> %d", len(before), len(chain))
+	}
+	snapshots, _ := repo.ListCostSnapshots(ctx, CostSnapshotFilter{})
+	if v := VerifySnapshotChain(snapshots, chain); !v.OK() {
+		t.Errorf("issues after commit: %+v", v.Issues)
+	}
+}
+
+func TestMockRepository_DeterministicIDs(t *testing.T) {
+	newRepo := func() *MockRepository {
+		config := DefaultMockConfig()
+		config.LatencyMs = 0
+		config.IDMode = mockid.ModeDeterministic
+		return NewMockRepository(config)
+	}
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	ids := func(repo *MockRepository) []string {
+		if err := repo.SaveCostSnapshot(ctx, CostSnapshot{CalculationID: "calc-a", Timestamp: at}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.SaveGradeChangeEvent(ctx, GradeChangeEvent{Namespace: "web", WorkloadName: "api", OccurredAt: at}); err != nil {
+			t.Fatal(err)
+		}
+		list, err := repo.ListCostSnapshots(ctx, CostSnapshotFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		events, _ := repo.ListGradeChangeEvents(ctx, GradeChangeEventFilter{})
+		var out []string
+		for _, s := range list {
+			out = append(out, s.ID, s.CalculationID)
+		}
+		for _, e := range events {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	a, b := ids(newRepo()), ids(newRepo())
+	if len(a) == 0 || len(a) != len(b) {
+		t.Fatalf("got %d and %d IDs", len(a), len(b))
+	}
+	seen := make(map[string]bool)
+	for _, id := range a {
+		seen[id] = true
+	}
+	for _, id := range b {
+		if !seen[id] {
+			t.Errorf("ID %s of the second run is not in the first", id)
+		}
+		if strings.HasPrefix(id, "snapshot-") || strings.HasPrefix(id, "grade-") {
+			t.Errorf("ID %s uses the random scheme", id)
+		}
+	}
+}
+
+func TestMockRepository_IssuedRecommendations(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	repo := NewMockRepository(config)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	rec := IssuedRecommendation{Team: "pay", Namespace: "payments", WorkloadName: "ledger", Action: "downsize", Resource: "cpu", BaselineRequest: 4, RecommendedRequest: 1.2, IssuedAt: at, Status: "open"}
+	if err := repo.SaveIssuedRecommendation(ctx, rec); err != nil {
+		t.Fatalf("SaveIssuedRecommendation: %v", err)
+	}
+	rec.Status = "applied"
+	_ = repo.SaveIssuedRecommendation(ctx, rec)
+	_ = repo.SaveIssuedRecommendation(ctx, IssuedRecommendation{Team: "search", Namespace: "search", WorkloadName: "index", Resource: "workload", IssuedAt: at.Add(time.Hour), Status: "open"})
+
+	all, _ := repo.ListIssuedRecommendations(ctx, IssuedRecommendationFilter{})
+	if len(all) != 2 || all[0].WorkloadName != "ledger" || all[0].Status != "applied" || all[0].ID == "" {
+		t.Fatalf("recommendations = %+v, want one row per workload resource", all)
+	}
+	if got, _ := repo.ListIssuedRecommendations(ctx, IssuedRecommendationFilter{Team: "search", Status: "open"}); len(got) != 1 {
+		t.Errorf("filtered = %+v", got)
+	}
+}
+
+func TestMockRepository_AccountingPeriods(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	repo := NewMockRepository(config)
+	ctx := context.Background()
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	hour := day.Add(9 * time.Hour)
+	_ = repo.SaveDailyNamespaceCost(ctx, DailyNamespaceCost{Namespace: "web", Date: day, BillableCost: 10, SharedCost: 2})
+	_ = repo.SaveHourlyWorkloadStat(ctx, HourlyWorkloadStat{Namespace: "web", WorkloadName: "api", Timestamp: hour, TotalBillableCost: 1})
+
+	march := AccountingPeriod{Label: "FY2026 P03", StartDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)}
+	march.StartTime, march.EndTime = march.StartDate, march.EndDate
+	if err := repo.CloseAccountingPeriod(ctx, march); err != nil {
+		t.Fatalf("CloseAccountingPeriod: %v", err)
+	}
+	if err := repo.CloseAccountingPeriod(ctx, march); !errors.Is(err, ErrPeriodClosed) {
+		t.Errorf("closing twice: want ErrPeriodClosed, got %v", err)
+	}
+
+	// 普通写入被拒绝，原记录不变
+	if err := repo.SaveDailyNamespaceCost(ctx, DailyNamespaceCost{Namespace: "web", Date: day, BillableCost: 99}); !errors.Is(err, ErrPeriodClosed) || !errors.Is(err, dataerr.ErrConflict) {
+		t.Errorf("write to closed day: want ErrPeriodClosed, got %v", err)
+	}
+	if err := repo.SaveHourlyWorkloadStat(ctx, HourlyWorkloadStat{Namespace: "web", WorkloadName: "api", Timestamp: hour, TotalBillableCost: 5}); !errors.Is(err, ErrPeriodClosed) {
+		t.Errorf("write to closed hour: want ErrPeriodClosed, got %v", err)
+	}
+	tx, _ := repo.BeginTx(ctx)
+	if err := tx.Repository().SaveDailyNamespaceCost(ctx, DailyNamespaceCost{Namespace: "web", Date: day}); !errors.Is(err, ErrPeriodClosed) {
+		t.Errorf("write in transaction: want ErrPeriodClosed, got %v", err)
+	}
+	_ = tx.Rollback()
+	if err := repo.SaveDailyNamespaceCost(ctx, DailyNamespaceCost{Namespace: "web", Date: march.EndDate, BillableCost: 3}); err != nil {
+		t.Errorf("write after the period: %v", err)
+	}
+
+	// 重算记录差额而不改动原记录；差额为零时不记录
+	recalc := WithRecalculation(ctx, "recalculation run-1")
+	if err := repo.SaveDailyNamespaceCost(recalc, DailyNamespaceCost{Namespace: "web", Date: day, BillableCost: 11.5, SharedCost: 2}); err != nil {
+		t.Fatalf("recalculated day: %v", err)
+	}
+	if err := repo.SaveHourlyWorkloadStat(recalc, HourlyWorkloadStat{Namespace: "web", WorkloadName: "api", Timestamp: hour, TotalBillableCost: 1}); err != nil {
+		t.Fatalf("recalculated hour: %v", err)
+	}
+	if got, _ := repo.GetDailyNamespaceCost(ctx, "web", day); got.BillableCost != 10 {
+		t.Errorf("closed day changed to %v", got.BillableCost)
+	}
+	adjustments, _ := repo.ListAdjustments(ctx, AdjustmentFilter{Period: "FY2026 P03"})
+	if len(adjustments) != 1 {
+		t.Fatalf("adjustments = %+v, want one", adjustments)
+	}
+	if a := adjustments[0]; a.Record != AdjustmentRecordDailyNamespaceCost || a.Amount != 1.5 || a.Source != AdjustmentSourceRecalculation || a.Reason != "recalculation run-1" || a.ID == "" {
+		t.Errorf("unexpected adjustment: %+v", a)
+	}
+
+	// 手工更正只能落在已关账期间
+	manual := Adjustment{Record: AdjustmentRecordDailyNamespaceCost, Date: day.AddDate(0, 0, 5), Namespace: "web", Amount: -4, Source: AdjustmentSourceManual, Reason: "credit", CreatedBy: "alice", Approver: "bob"}
+	saved, err := repo.SaveAdjustment(ctx, manual)
+	if err != nil {
+		t.Fatalf("SaveAdjustment: %v", err)
+	}
+	if saved.Period != "FY2026 P03" || saved.ID == "" || saved.ID == adjustments[0].ID {
+		t.Errorf("saved adjustment = %+v", saved)
+	}
+	manual.Date = march.EndDate
+	if _, err := repo.SaveAdjustment(ctx, manual); !errors.Is(err, dataerr.ErrValidation) {
+		t.Errorf("adjustment of an open period: want validation error, got %v", err)
+	}
+	adjustments, _ = repo.ListAdjustments(ctx, AdjustmentFilter{StartDate: day.AddDate(0, 0, 1), EndDate: day.AddDate(0, 0, 5)})
+	if len(adjustments) != 1 || adjustments[0].Approver != "bob" {
+		t.Errorf("adjustments by date = %+v, want the manual one", adjustments)
+	}
+}
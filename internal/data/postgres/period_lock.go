@@ -0,0 +1,45 @@
+// Package postgres period_lock.go: 会计期间关账。已关账期间内的日汇总与小时统计不可再写；标记为重算的写入
+// 不改动原记录，而是把差额记为 Adjustment（见 WithRecalculation）。
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// ErrPeriodClosed is returned for a write to a closed accounting period that is not a recalculation.
+var ErrPeriodClosed = dataerr.Conflict("accounting period closed")
+
+type recalculationKey struct{}
+
+// WithRecalculation marks the writes made with ctx as a recalculation for reason (e.g. "recalculation
+// <run id>"): in a closed period they leave the stored rows unchanged and record the difference as an
+// Adjustment instead of failing with ErrPeriodClosed.
+func WithRecalculation(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, recalculationKey{}, reason)
+}
+
+// RecalculationReason returns the reason ctx was marked with by WithRecalculation.
+func RecalculationReason(ctx context.Context) (string, bool) {
+	reason, ok := ctx.Value(recalculationKey{}).(string)
+	return reason, ok
+}
+
+// Contains reports whether the day labelled date belongs to the period.
+func (p AccountingPeriod) Contains(date time.Time) bool {
+	y, m, d := date.UTC().Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return !day.Before(p.StartDate) && day.Before(p.EndDate)
+}
+
+// ContainsTime reports whether the instant t falls within the period.
+func (p AccountingPeriod) ContainsTime(t time.Time) bool {
+	return !t.Before(p.StartTime) && t.Before(p.EndTime)
+}
+
+func periodClosedError(p *AccountingPeriod) error {
+	return fmt.Errorf("%w: %s was closed at %s", ErrPeriodClosed, p.Label, p.ClosedAt.UTC().Format(time.RFC3339))
+}
@@ -3,6 +3,8 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
@@ -74,6 +76,20 @@ type CostSnapshot struct {
 	Metadata               map[string]interface{}                                       `json:"metadata"`
 	CreatedAt              time.Time                                                    `json:"created_at"`
 	UpdatedAt              time.Time                                                    `json:"updated_at"`
+	ContentHash            string                                                       `json:"content_hash,omitempty"` // SnapshotContentHash, set by the repository on save
+	// Rate-of-change guardrail: a snapshot whose total billable cost deviates too much from the
+	// previous one is marked Suspect; with RequiresConfirmation it stays out of dashboards and
+	// events until someone confirms it.
+	Suspect              bool       `json:"suspect,omitempty"`
+	SuspectReason        string     `json:"suspect_reason,omitempty"`
+	RequiresConfirmation bool       `json:"requires_confirmation,omitempty"`
+	ConfirmedBy          string     `json:"confirmed_by,omitempty"`
+	ConfirmedAt          *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// AwaitingConfirmation reports whether the snapshot is held back until it is confirmed.
+func (s CostSnapshot) AwaitingConfirmation() bool {
+	return s.RequiresConfirmation && s.ConfirmedAt == nil
 }
 
 // CostSnapshotFilter defines filtering options for cost snapshots.
@@ -85,6 +101,74 @@ type CostSnapshotFilter struct {
 	MaxTotalCost  float64   `json:"max_total_cost"`
 	Limit         int       `json:"limit"`
 	Offset        int       `json:"offset"`
+	// ExcludeUnconfirmed leaves out snapshots awaiting confirmation (see CostSnapshot.AwaitingConfirmation).
+	ExcludeUnconfirmed bool `json:"exclude_unconfirmed"`
+	// ID restricts the list to one snapshot, so a single snapshot can be read with Fields.
+	ID string `json:"id"`
+	// Fields selects the parts of each snapshot that are read; zero reads whole snapshots.
+	Fields SnapshotFields `json:"fields"`
+}
+
+// SnapshotFields is a set of CostSnapshot field groups. ResourceResults and AggregatedResults make
+// up almost all of a snapshot, so reads that only need headline numbers leave them out and SQL
+// drivers select (and decode) only the columns of the requested groups. Identity fields (ID,
+// CalculationID, Timestamp and the time range) are always read.
+type SnapshotFields uint8
+
+// Snapshot field groups.
+const (
+	// SnapshotFieldCounts reads ZombieCount, OverProvisionedCount, HealthyCount and RiskCount.
+	SnapshotFieldCounts SnapshotFields = 1 << iota
+	// SnapshotFieldSummary reads the totals, efficiency score, metadata, content hash, guardrail flags and timestamps.
+	SnapshotFieldSummary
+	// SnapshotFieldAggregated reads AggregatedResults.
+	SnapshotFieldAggregated
+	// SnapshotFieldResources reads ResourceResults.
+	SnapshotFieldResources
+
+	SnapshotFieldsAll = SnapshotFieldCounts | SnapshotFieldSummary | SnapshotFieldAggregated | SnapshotFieldResources
+)
+
+// Project returns snapshot with only the field groups in f set; zero f keeps everything.
+func (f SnapshotFields) Project(snapshot CostSnapshot) CostSnapshot {
+	if f == 0 || f == SnapshotFieldsAll {
+		return snapshot
+	}
+	out := CostSnapshot{
+		ID:             snapshot.ID,
+		CalculationID:  snapshot.CalculationID,
+		Timestamp:      snapshot.Timestamp,
+		TimeRangeStart: snapshot.TimeRangeStart,
+		TimeRangeEnd:   snapshot.TimeRangeEnd,
+	}
+	if f&SnapshotFieldCounts != 0 {
+		out.ZombieCount = snapshot.ZombieCount
+		out.OverProvisionedCount = snapshot.OverProvisionedCount
+		out.HealthyCount = snapshot.HealthyCount
+		out.RiskCount = snapshot.RiskCount
+	}
+	if f&SnapshotFieldSummary != 0 {
+		out.TotalBillableCost = snapshot.TotalBillableCost
+		out.TotalUsageCost = snapshot.TotalUsageCost
+		out.TotalWasteCost = snapshot.TotalWasteCost
+		out.OverallEfficiencyScore = snapshot.OverallEfficiencyScore
+		out.Metadata = snapshot.Metadata
+		out.CreatedAt = snapshot.CreatedAt
+		out.UpdatedAt = snapshot.UpdatedAt
+		out.ContentHash = snapshot.ContentHash
+		out.Suspect = snapshot.Suspect
+		out.SuspectReason = snapshot.SuspectReason
+		out.RequiresConfirmation = snapshot.RequiresConfirmation
+		out.ConfirmedBy = snapshot.ConfirmedBy
+		out.ConfirmedAt = snapshot.ConfirmedAt
+	}
+	if f&SnapshotFieldAggregated != 0 {
+		out.AggregatedResults = snapshot.AggregatedResults
+	}
+	if f&SnapshotFieldResources != 0 {
+		out.ResourceResults = snapshot.ResourceResults
+	}
+	return out
 }
 
 // ROIBaseline represents a Return on Investment baseline for comparison.
@@ -119,10 +203,12 @@ type DailyNamespaceCost struct {
 	BillableCost    float64   `json:"billable_cost"`
 	UsageCost       float64   `json:"usage_cost"`
 	WasteCost       float64   `json:"waste_cost"`
+	SharedCost      float64   `json:"shared_cost"` // 分摊的集群共享资源成本（入口 LB、共享存储等），不计入 BillableCost 与效率
 	PodCount        int       `json:"pod_count"`
 	NodeCount       int       `json:"node_count"`
 	WorkloadCount   int       `json:"workload_count"`
 	EfficiencyScore float64   `json:"efficiency_score"`
+	Source          string    `json:"source,omitempty"` // 数据来源：空为 Lighthouse 自身计算，迁移导入的历史为导入来源（如 "kubecost"）
 	CreatedAt       time.Time `json:"created_at"`
 }
 
@@ -158,6 +244,12 @@ type HourlyWorkloadStat struct {
 	TotalBillableCost float64   `json:"total_billable_cost"`
 	TotalUsageCost    float64   `json:"total_usage_cost"`
 	TotalWasteCost    float64   `json:"total_waste_cost"`
+	CostCenter        string    `json:"cost_center,omitempty"`
+	NodePool          string    `json:"node_pool,omitempty"` // 运行该工作负载的节点池（costmodel.NodePoolOf），未知时为空
+	Source            string    `json:"source,omitempty"`    // 数据来源，同 DailyNamespaceCost.Source
+	// 小时内使用量样本的 sketch（可选），多小时 P95 由合并后的 sketch 计算而非对小时 P95 取平均
+	CPUUsageSketch *costmodel.UsageSketch `json:"cpu_usage_sketch,omitempty"`
+	MemUsageSketch *costmodel.UsageSketch `json:"mem_usage_sketch,omitempty"`
 }
 
 // HourlyWorkloadStatFilter defines filtering options for hourly workload stats.
@@ -165,12 +257,145 @@ type HourlyWorkloadStatFilter struct {
 	Namespace    string    `json:"namespace"`
 	WorkloadName string    `json:"workload_name"`
 	NodeName     string    `json:"node_name"`
+	NodePool     string    `json:"node_pool"`
 	StartTime    time.Time `json:"start_time"`
 	EndTime      time.Time `json:"end_time"`
 	Limit        int       `json:"limit"`
 	Offset       int       `json:"offset"`
 }
 
+// DailyWorkloadStat 工作负载日汇总（表 cost_daily_workload）：小时统计超过全分辨率保留期后降采样到此表，
+// 不保留 pod / node 等高基数列。请求量为小时均值，成本为求和；各小时均带 sketch 时 P95 取合并 sketch 的分位数，
+// 否则取小时 P95 的最大值（保守上界）。
+type DailyWorkloadStat struct {
+	Namespace         string                 `json:"namespace"`
+	WorkloadName      string                 `json:"workload_name"`
+	WorkloadType      string                 `json:"workload_type"`
+	NodePool          string                 `json:"node_pool,omitempty"`
+	CostCenter        string                 `json:"cost_center,omitempty"`
+	Date              time.Time              `json:"date"`  // UTC 日期 00:00
+	Hours             int                    `json:"hours"` // 汇总的小时行数
+	CPURequest        float64                `json:"cpu_request"`
+	CPUUsageP95       float64                `json:"cpu_usage_p95"`
+	MemRequest        int64                  `json:"mem_request"`
+	MemUsageP95       int64                  `json:"mem_usage_p95"`
+	CPUBillableCost   float64                `json:"cpu_billable_cost"`
+	CPUUsageCost      float64                `json:"cpu_usage_cost"`
+	CPUWasteCost      float64                `json:"cpu_waste_cost"`
+	MemBillableCost   float64                `json:"mem_billable_cost"`
+	MemUsageCost      float64                `json:"mem_usage_cost"`
+	MemWasteCost      int64                  `json:"mem_waste_cost"`
+	TotalBillableCost float64                `json:"total_billable_cost"`
+	TotalUsageCost    float64                `json:"total_usage_cost"`
+	TotalWasteCost    float64                `json:"total_waste_cost"`
+	CPUUsageSketch    *costmodel.UsageSketch `json:"cpu_usage_sketch,omitempty"`
+	MemUsageSketch    *costmodel.UsageSketch `json:"mem_usage_sketch,omitempty"`
+}
+
+// DailyWorkloadStatOf returns the one-hour rollup of stat; its sketches are copies.
+func DailyWorkloadStatOf(stat HourlyWorkloadStat) DailyWorkloadStat {
+	y, m, d := stat.Timestamp.UTC().Date()
+	return DailyWorkloadStat{
+		Namespace:         stat.Namespace,
+		WorkloadName:      stat.WorkloadName,
+		WorkloadType:      stat.WorkloadType,
+		NodePool:          stat.NodePool,
+		CostCenter:        stat.CostCenter,
+		Date:              time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+		Hours:             1,
+		CPURequest:        stat.CPURequest,
+		CPUUsageP95:       stat.CPUUsageP95,
+		MemRequest:        stat.MemRequest,
+		MemUsageP95:       stat.MemUsageP95,
+		CPUBillableCost:   stat.CPUBillableCost,
+		CPUUsageCost:      stat.CPUUsageCost,
+		CPUWasteCost:      stat.CPUWasteCost,
+		MemBillableCost:   stat.MemBillableCost,
+		MemUsageCost:      stat.MemUsageCost,
+		MemWasteCost:      stat.MemWasteCost,
+		TotalBillableCost: stat.TotalBillableCost,
+		TotalUsageCost:    stat.TotalUsageCost,
+		TotalWasteCost:    stat.TotalWasteCost,
+		CPUUsageSketch:    copySketch(stat.CPUUsageSketch),
+		MemUsageSketch:    copySketch(stat.MemUsageSketch),
+	}
+}
+
+// Merge folds other (the same workload and day) into d, e.g. hours that arrived after the day was
+// downsampled.
+func (d *DailyWorkloadStat) Merge(other DailyWorkloadStat) {
+	if other.Hours <= 0 {
+		return
+	}
+	n, o := float64(d.Hours), float64(other.Hours)
+	d.CPURequest = (d.CPURequest*n + other.CPURequest*o) / (n + o)
+	d.MemRequest = int64(math.Round((float64(d.MemRequest)*n + float64(other.MemRequest)*o) / (n + o)))
+	d.Hours += other.Hours
+	d.CPUBillableCost += other.CPUBillableCost
+	d.CPUUsageCost += other.CPUUsageCost
+	d.CPUWasteCost += other.CPUWasteCost
+	d.MemBillableCost += other.MemBillableCost
+	d.MemUsageCost += other.MemUsageCost
+	d.MemWasteCost += other.MemWasteCost
+	d.TotalBillableCost += other.TotalBillableCost
+	d.TotalUsageCost += other.TotalUsageCost
+	d.TotalWasteCost += other.TotalWasteCost
+	if d.WorkloadType == "" {
+		d.WorkloadType = other.WorkloadType
+	}
+	if d.NodePool == "" {
+		d.NodePool = other.NodePool
+	}
+	if d.CostCenter == "" {
+		d.CostCenter = other.CostCenter
+	}
+	d.CPUUsageSketch = mergeSketch(d.CPUUsageSketch, other.CPUUsageSketch)
+	if d.CPUUsageSketch != nil {
+		d.CPUUsageP95 = d.CPUUsageSketch.Quantile(0.95)
+	} else {
+		d.CPUUsageP95 = math.Max(d.CPUUsageP95, other.CPUUsageP95)
+	}
+	d.MemUsageSketch = mergeSketch(d.MemUsageSketch, other.MemUsageSketch)
+	if d.MemUsageSketch != nil {
+		d.MemUsageP95 = int64(math.Round(d.MemUsageSketch.Quantile(0.95)))
+	} else if other.MemUsageP95 > d.MemUsageP95 {
+		d.MemUsageP95 = other.MemUsageP95
+	}
+}
+
+// mergeSketch merges b into a (owned by the caller); nil when either side has no sketch.
+func mergeSketch(a, b *costmodel.UsageSketch) *costmodel.UsageSketch {
+	if a == nil || b == nil || a.Merge(b) != nil {
+		return nil
+	}
+	return a
+}
+
+func copySketch(s *costmodel.UsageSketch) *costmodel.UsageSketch {
+	if s == nil {
+		return nil
+	}
+	out := costmodel.NewUsageSketch(s.Accuracy)
+	if out.Merge(s) != nil {
+		return nil
+	}
+	return out
+}
+
+// DailyWorkloadStatFilter defines filtering options for daily workload rollups.
+type DailyWorkloadStatFilter struct {
+	Namespace    string    `json:"namespace"`
+	WorkloadName string    `json:"workload_name"`
+	StartTime    time.Time `json:"start_time"` // Date >= StartTime
+	EndTime      time.Time `json:"end_time"`   // Date <= EndTime
+}
+
+// DownsampleResult is the outcome of downsampling hourly workload stats.
+type DownsampleResult struct {
+	Rollups int `json:"rollups"` // daily rows written
+	Deleted int `json:"deleted"` // hourly rows removed
+}
+
 // Metadata represents generic key-value metadata storage.
 type Metadata struct {
 	Key         string                 `json:"key"`
@@ -189,6 +414,46 @@ type MetadataFilter struct {
 	Offset    int    `json:"offset"`
 }
 
+// CalculationRun 成本计算流水线的一次执行记录（表 cost_calculation_run）。
+type CalculationRun struct {
+	ID          string    `json:"id"`
+	Trigger     string    `json:"trigger"` // "schedule", "manual", "retry"
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	Status      string    `json:"status"` // "running", "succeeded", "partial", "failed"
+	RowsWritten int       `json:"rows_written"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	DurationMs  int64     `json:"duration_ms"`
+	Error       string    `json:"error,omitempty"`
+	RetryOf     string    `json:"retry_of,omitempty"` // 重跑时指向失败的原执行
+	// Scopes 限定本次执行的范围（namespace）；为空表示全量。定向重跑时为原执行的 FailedScopes。
+	Scopes []string `json:"scopes,omitempty"`
+	// FailedScopes 执行失败的范围；其余范围的结果已落库。
+	FailedScopes []string `json:"failed_scopes,omitempty"`
+	// ZeroedValues / DroppedRows 计算中遇到 NaN/Inf 时被置 0 的值与被丢弃的指标行数。
+	ZeroedValues int `json:"zeroed_values,omitempty"`
+	DroppedRows  int `json:"dropped_rows,omitempty"`
+}
+
+// CalculationRun statuses.
+const (
+	CalculationRunRunning   = "running"
+	CalculationRunSucceeded = "succeeded"
+	CalculationRunPartial   = "partial" // 部分范围失败，成功范围已落库
+	CalculationRunFailed    = "failed"
+)
+
+// CalculationRunFilter defines filtering options for calculation runs.
+type CalculationRunFilter struct {
+	Status    string    `json:"status"`
+	Trigger   string    `json:"trigger"`
+	StartTime time.Time `json:"start_time"` // WindowStart >= StartTime
+	EndTime   time.Time `json:"end_time"`   // WindowEnd <= EndTime
+	Limit     int       `json:"limit"`
+	Offset    int       `json:"offset"`
+}
+
 // BillAccountSummary 云账户总账单汇总（表 cost_bill_account_summary）。Phase3 Mock 占位。
 type BillAccountSummary struct {
 	AccountID   string             `json:"account_id"`
@@ -199,6 +464,7 @@ type BillAccountSummary struct {
 	Currency    string            `json:"currency"`
 	ByCategory  map[string]float64 `json:"by_category"`
 	CreatedAt   time.Time         `json:"created_at"`
+	Sealed      string            `json:"sealed,omitempty"` // 启用加密时 TotalAmount/ByCategory 的密文（storage.EncryptedRepository），明文列置零
 }
 
 // DailyStorageCost 存储维度日成本（表 cost_daily_storage）。Phase3 Mock 占位。
@@ -220,3 +486,419 @@ type DailyNetworkCost struct {
 	Cost         float64   `json:"cost"`
 	CreatedAt    time.Time `json:"created_at"`
 }
+
+// NamespaceLifecycle namespace 生命周期记录（表 namespace_lifecycle），由 K8s 同步任务维护。
+// 已删除 namespace 的成本仍保留在历史数据中，趋势/分解视图据此标注已终止的 namespace。
+type NamespaceLifecycle struct {
+	Namespace string     `json:"namespace"`
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // 同步时发现已不存在的时间；重新创建后清空
+}
+
+// Terminated reports whether the namespace no longer exists in the cluster.
+func (l NamespaceLifecycle) Terminated() bool { return l.DeletedAt != nil }
+
+// GradeChangeEvent 工作负载效率等级变更事件（表 workload_grade_event）。
+// 首次评级记录 FromGrade 为空；之后只有等级（经迟滞判断后）发生变化时才记录。
+type GradeChangeEvent struct {
+	ID              string    `json:"id"`
+	Namespace       string    `json:"namespace"`
+	WorkloadName    string    `json:"workload_name"`
+	WorkloadType    string    `json:"workload_type"`
+	FromGrade       string    `json:"from_grade,omitempty"`
+	ToGrade         string    `json:"to_grade"`
+	EfficiencyScore float64   `json:"efficiency_score"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// SnapshotRegrade 成本快照在新效率阈值下的重评级视图（表 cost_snapshot_regrade）。原快照保持不变（内容哈希链不受影响），
+// 每个快照只保留最近一次重评级；Grades 与快照的 ResourceResults 按下标一一对应。
+type SnapshotRegrade struct {
+	SnapshotID           string                      `json:"snapshot_id"`
+	JobID                string                      `json:"job_id"`
+	Thresholds           costmodel.GradeThresholds   `json:"thresholds"`
+	Grades               []costmodel.EfficiencyGrade `json:"grades"`
+	ZombieCount          int                         `json:"zombie_count"`
+	OverProvisionedCount int                         `json:"over_provisioned_count"`
+	HealthyCount         int                         `json:"healthy_count"`
+	RiskCount            int                         `json:"risk_count"`
+	RegradedBy           string                      `json:"regraded_by,omitempty"`
+	RegradedAt           time.Time                   `json:"regraded_at"`
+}
+
+// IssuedRecommendation 已下发的规格调整/下线建议（表 workload_recommendation）：周报下发时记录，每日对账任务按工作负载
+// 当前请求量更新采纳进度。同一工作负载的同一资源只保留一条，未采纳前再次下发只更新目标与预计节省。
+type IssuedRecommendation struct {
+	ID                      string    `json:"id"`
+	Team                    string    `json:"team"`
+	Namespace               string    `json:"namespace"`
+	WorkloadName            string    `json:"workload_name"`
+	Action                  string    `json:"action"`   // decommission / downsize / upsize
+	Resource                string    `json:"resource"` // cpu / memory / workload
+	BaselineRequest         float64   `json:"baseline_request"`
+	RecommendedRequest      float64   `json:"recommended_request"`
+	EstimatedMonthlySavings float64   `json:"estimated_monthly_savings"`
+	IssuedAt                time.Time `json:"issued_at"`
+	// 对账结果：Status 为 open / partial / applied（costmodel.AdoptionStatus），CheckedAt 为零表示尚未对账
+	Status                 string    `json:"status"`
+	CurrentRequest         float64   `json:"current_request"`
+	AdoptedFraction        float64   `json:"adopted_fraction"`
+	RealizedMonthlySavings float64   `json:"realized_monthly_savings"`
+	CheckedAt              time.Time `json:"checked_at"`
+}
+
+// IssuedRecommendationFilter defines filtering options for issued recommendations.
+type IssuedRecommendationFilter struct {
+	Team         string    `json:"team"`
+	Namespace    string    `json:"namespace"`
+	WorkloadName string    `json:"workload_name"`
+	Status       string    `json:"status"`
+	IssuedAfter  time.Time `json:"issued_after"`
+}
+
+// OptimizationRecordFilter defines filtering options for optimization tracking records
+// (roi.OptimizationTrackingRecord, table optimization_tracking).
+type OptimizationRecordFilter struct {
+	BundleID         string `json:"bundle_id"`
+	RecommendationID string `json:"recommendation_id"`
+	Unverified       bool   `json:"unverified"` // only records awaiting verification
+}
+
+// ServiceAccount 机器服务账号（表 service_account）：CI 任务与其他服务以其 API key 调用 API，停用后所有 key 失效。
+type ServiceAccount struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Disabled    bool      `json:"disabled"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// APIKey 服务账号的 API key（表 service_account_key）。只保存密钥的 SHA-256（SecretHash），Prefix 为明文前缀，
+// 用于在列表与日志中识别 key。Scopes 形如 cost:read、pricing:approve、admin:*、*；DailyQuota 为每日（UTC）请求上限，0 不限。
+type APIKey struct {
+	ID         string     `json:"id"`
+	AccountID  string     `json:"account_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	SecretHash string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	DailyQuota int        `json:"daily_quota"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyUsage API key 的每日用量（表 api_key_usage）：Requests 为放行的请求数，Rejected 为超出配额被拒绝的请求数。
+type APIKeyUsage struct {
+	KeyID    string    `json:"key_id"`
+	Date     time.Time `json:"date"` // UTC 日期
+	Requests int64     `json:"requests"`
+	Rejected int64     `json:"rejected"`
+}
+
+// AlertRule 用户自定义告警规则（表 alert_rule）：Condition 为条件 DSL（notifier.ParseCondition），
+// 每 Interval 对截至当时的 Window 求值一次；状态由 ok 变为 firing 时按 AlertType 路由（Channels 非空时只发往其中渠道）。
+// Last* 与 FiringSince 记录最近一次求值结果。
+type AlertRule struct {
+	ID              string        `json:"id"`
+	Name            string        `json:"name"`
+	Description     string        `json:"description,omitempty"`
+	Condition       string        `json:"condition"`
+	Window          time.Duration `json:"window"`
+	Interval        time.Duration `json:"interval"`
+	Severity        string        `json:"severity"`
+	AlertType       string        `json:"alert_type"`
+	Channels        []string      `json:"channels,omitempty"`
+	Enabled         bool          `json:"enabled"`
+	CreatedBy       string        `json:"created_by,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	LastEvaluatedAt *time.Time    `json:"last_evaluated_at,omitempty"`
+	LastState       string        `json:"last_state,omitempty"` // ok / firing / error
+	LastError       string        `json:"last_error,omitempty"`
+	FiringSince     *time.Time    `json:"firing_since,omitempty"`
+}
+
+// NotificationRoute 通知路由规则（表 notification_route）：告警标签（notifier.AlertLabels）满足 Matchers 时
+// 只投递到 Channels；多条规则匹配时取 Priority 最小的一条（相同时按名称）。
+type NotificationRoute struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Matchers  map[string]string `json:"matchers"`
+	Channels  []string          `json:"channels"`
+	Priority  int               `json:"priority"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// NotificationSilence 通知静默（表 notification_silence）：[StartsAt, EndsAt) 内标签满足 Matchers 的告警不投递。
+// 提前结束时 ExpiredBy 记录操作人、EndsAt 改为结束时刻，记录保留用于审计。
+type NotificationSilence struct {
+	ID        string            `json:"id"`
+	Matchers  map[string]string `json:"matchers"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    time.Time         `json:"ends_at"`
+	Reason    string            `json:"reason"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiredBy string            `json:"expired_by,omitempty"`
+}
+
+// QueryLineage 成本查询响应的数据血缘（表 cost_query_lineage）：响应时看板所用的最新快照、最近完成的计算执行、
+// 该执行窗口适用的单价版本以及服务的构建版本，按值记录，之后的重算或价格更正不会改写已记录的血缘。
+type QueryLineage struct {
+	ID             string           `json:"id"`
+	RequestID      string           `json:"request_id,omitempty"`
+	Method         string           `json:"method"`
+	Path           string           `json:"path"` // 含查询参数
+	Status         int              `json:"status"`
+	ServedAt       time.Time        `json:"served_at"`
+	Snapshot       *LineageSnapshot `json:"snapshot,omitempty"`
+	CalculationRun *CalculationRun  `json:"calculation_run,omitempty"`
+	PriceVersion   *PriceVersion    `json:"price_version,omitempty"` // nil: 使用配置中的全局单价
+	Code           CodeVersion      `json:"code"`
+}
+
+// LineageSnapshot 血缘中引用的成本快照。
+type LineageSnapshot struct {
+	ID            string    `json:"id"`
+	CalculationID string    `json:"calculation_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	ContentHash   string    `json:"content_hash,omitempty"`
+}
+
+// CodeVersion 产出数据的服务构建版本（构建时由 -ldflags 注入）。
+type CodeVersion struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time,omitempty"`
+}
+
+// PriceVersion 单价历史版本（表 cost_price_history），自 EffectiveFrom 起生效直到下一版本。
+// 同一 EffectiveFrom 再次保存视为更正该版本，Revision 随之递增；ChangeID 为批准该版本的单价变更申请。
+type PriceVersion struct {
+	ID                  string    `json:"id"`
+	EffectiveFrom       time.Time `json:"effective_from"`
+	CPUPricePerCoreHour float64   `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64   `json:"mem_price_per_gb_hour"`
+	Note                string    `json:"note,omitempty"`
+	Revision            int       `json:"revision"`
+	ChangeID            string    `json:"change_id,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// Label identifies the revision of the version, e.g. "price-1704067200@2"; snapshots record the
+// labels of the versions that priced them.
+func (v PriceVersion) Label() string {
+	return fmt.Sprintf("%s@%d", v.ID, v.Revision)
+}
+
+// Price change statuses. A draft becomes approved (written to the price history) or rejected.
+const (
+	PriceChangeDraft    = "draft"
+	PriceChangeApproved = "approved"
+	PriceChangeRejected = "rejected"
+)
+
+// PriceChange 单价变更申请（表 cost_price_change）：草稿经审批人批准后才写入单价历史（PriceVersionID），
+// 驳回的申请保留记录。History 为按时间正序的提交与审批记录。
+type PriceChange struct {
+	ID                  string             `json:"id"`
+	Status              string             `json:"status"`
+	EffectiveFrom       time.Time          `json:"effective_from"`
+	CPUPricePerCoreHour float64            `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64            `json:"mem_price_per_gb_hour"`
+	Note                string             `json:"note,omitempty"`
+	Recalculate         bool               `json:"recalculate"`
+	RequestedBy         string             `json:"requested_by,omitempty"`
+	PriceVersionID      string             `json:"price_version_id,omitempty"`
+	History             []PriceChangeEvent `json:"history"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+}
+
+// PriceChangeEvent 单价变更申请的一条提交或审批记录；Action 为 proposed / approved / rejected。
+type PriceChangeEvent struct {
+	Action  string    `json:"action"`
+	Actor   string    `json:"actor,omitempty"`
+	Comment string    `json:"comment,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// UnitPrice returns the costmodel view of the version.
+func (v PriceVersion) UnitPrice() costmodel.UnitPrice {
+	return costmodel.UnitPrice{
+		EffectiveFrom:       v.EffectiveFrom,
+		CPUPricePerCoreHour: v.CPUPricePerCoreHour,
+		MemPricePerGBHour:   v.MemPricePerGBHour,
+	}
+}
+
+// PriceHistoryOf converts price versions to a costmodel.PriceHistory.
+func PriceHistoryOf(versions []PriceVersion) costmodel.PriceHistory {
+	h := make(costmodel.PriceHistory, 0, len(versions))
+	for _, v := range versions {
+		h = append(h, v.UnitPrice())
+	}
+	return h
+}
+
+// GradeChangeEventFilter defines filtering options for grade change events.
+type GradeChangeEventFilter struct {
+	Namespace    string    `json:"namespace"`
+	WorkloadName string    `json:"workload_name"`
+	ToGrade      string    `json:"to_grade"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Limit        int       `json:"limit"`
+	Offset       int       `json:"offset"`
+}
+
+// ConfigChange 集群配置变更记录（表 config_change）：由部署流水线（Helm、Argo CD 等）在变更生效后上报，
+// 与 K8s 事件、计算执行、单价变更、SLO 违约一起组成集群事件时间线。
+type ConfigChange struct {
+	ID         string    `json:"id"`
+	Namespace  string    `json:"namespace"` // 为空表示集群级变更
+	Kind       string    `json:"kind"`      // 如 Deployment / ConfigMap / HelmRelease
+	Name       string    `json:"name"`
+	ChangeType string    `json:"change_type"` // 如 helm_upgrade / image_update / configmap_update / crd_update
+	OldValue   string    `json:"old_value,omitempty"`
+	NewValue   string    `json:"new_value,omitempty"`
+	Source     string    `json:"source,omitempty"` // 上报系统，如 argocd
+	Author     string    `json:"author,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ConfigChangeFilter defines filtering options for config changes. A namespace filter also
+// matches cluster-level changes (empty namespace).
+type ConfigChangeFilter struct {
+	Namespace string    `json:"namespace"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// SLOViolation SLO 违约记录（表 slo_violation）：违约开始时上报，恢复时以同一 ID 再次上报并带 RecoveredAt。
+type SLOViolation struct {
+	ID             string     `json:"id"`
+	Namespace      string     `json:"namespace"`
+	Service        string     `json:"service"`
+	ViolationType  string     `json:"violation_type"` // availability / latency / error_rate
+	ActualValue    float64    `json:"actual_value"`
+	ThresholdValue float64    `json:"threshold_value"`
+	Severity       string     `json:"severity,omitempty"`
+	ViolatedAt     time.Time  `json:"violated_at"`
+	RecoveredAt    *time.Time `json:"recovered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// SLOViolationFilter defines filtering options for SLO violations. The window matches violations
+// that were ongoing at any time within it.
+type SLOViolationFilter struct {
+	Namespace string    `json:"namespace"`
+	Service   string    `json:"service"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// Sources of dependency edges.
+const (
+	DependencySourceTracing = "tracing" // observed calls, reported by the tracing pipeline
+	DependencySourceStatic  = "static"  // declared in the static dependency map
+)
+
+// DependencyEdge 服务依赖边（表 service_dependency）：caller 调用 callee。链路追踪上报的边按最近一次观测刷新
+// LastSeen，静态依赖每次导入整体替换；同一对服务可同时有两种来源的边。
+type DependencyEdge struct {
+	CallerNamespace string    `json:"caller_namespace"`
+	Caller          string    `json:"caller"`
+	CalleeNamespace string    `json:"callee_namespace"`
+	Callee          string    `json:"callee"`
+	Source          string    `json:"source"`           // tracing / static
+	CallsPerMinute  float64   `json:"calls_per_minute"` // 观测到的调用速率，静态依赖为 0
+	LastSeen        time.Time `json:"last_seen"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// DependencyEdgeFilter defines filtering options for dependency edges. SeenBefore matches edges
+// last seen before it (the stale edges of a source).
+type DependencyEdgeFilter struct {
+	Source     string    `json:"source"`
+	SeenBefore time.Time `json:"seen_before"`
+}
+
+// AccountingPeriod 已关账的会计期间（表 accounting_period）：关账后期间内的日汇总与小时统计不可再写，
+// 重算产生的差异记为 Adjustment。StartDate / EndDate 为日标签（EndDate 不含），StartTime / EndTime 为
+// 按会计时区换算的起止时刻，小时统计按时刻判断所属期间。
+type AccountingPeriod struct {
+	Label     string    `json:"label"` // 财务期间标签，如 FY2026 P09
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	ClosedBy  string    `json:"closed_by,omitempty"`
+	ClosedAt  time.Time `json:"closed_at"`
+	Note      string    `json:"note,omitempty"`
+}
+
+// Records an adjustment corrects. Reports built from the daily namespace costs include the
+// daily_namespace_cost adjustments.
+const (
+	AdjustmentRecordDailyNamespaceCost = "daily_namespace_cost" // BillableCost + SharedCost of a namespace day
+	AdjustmentRecordHourlyWorkloadStat = "hourly_workload_stat" // TotalBillableCost of a workload hour
+)
+
+// Sources of adjustments.
+const (
+	AdjustmentSourceRecalculation = "recalculation" // a recalculation wrote to a closed period
+	AdjustmentSourceManual        = "manual"        // entered by finance, with a reason and an approver
+)
+
+// Statuses of adjustments. A manual adjustment is pending until a second person approves it and only
+// pending ones are left out of reports; recalculations, and adjustments recorded without a status, are approved.
+const (
+	AdjustmentPending  = "pending"
+	AdjustmentApproved = "approved"
+)
+
+// Adjustment 已关账期间的成本更正（表 cost_adjustment，只追加）：重算写入已关账期间时，原记录保持不变，
+// 新旧值之差记为一条更正；财务也可手工录入更正（需原因，经另一人批准后生效）。Amount 为差额（新值 - 原值），
+// 作用于 Namespace（可细化到 Workload）在 Date 当天的成本；更正不可修改或删除，错误的更正以反向更正冲销。
+type Adjustment struct {
+	ID        string    `json:"id"`
+	Period    string    `json:"period"` // AccountingPeriod.Label
+	Record    string    `json:"record"` // daily_namespace_cost / hourly_workload_stat
+	Date      time.Time `json:"date"`   // 日标签；小时统计为其所在小时
+	Namespace string    `json:"namespace"`
+	Workload  string    `json:"workload,omitempty"`
+	Amount    float64   `json:"amount"`
+	Source    string    `json:"source"` // recalculation / manual
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Approver  string    `json:"approver,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Status     string     `json:"status,omitempty"` // pending / approved，空为 approved
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+}
+
+// Pending reports whether the adjustment still awaits approval.
+func (a Adjustment) Pending() bool {
+	return a.Status == AdjustmentPending
+}
+
+// AdjustmentFilter defines filtering options for adjustments. StartDate and EndDate (inclusive)
+// bound Date.
+type AdjustmentFilter struct {
+	Period    string    `json:"period"`
+	Namespace string    `json:"namespace"`
+	Record    string    `json:"record"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
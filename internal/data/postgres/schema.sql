@@ -27,6 +27,28 @@ CREATE TABLE IF NOT EXISTS cost_hourly_workload (
     PRIMARY KEY (time_bucket, namespace, workload_name)
 );
 
+-- cost_daily_workload: 工作负载日汇总。cost_hourly_workload 超过 retention.postgres.hourly_stats 的行由保留任务
+-- 降采样到此表后删除（同一事务），不保留 pod / node 等高基数列
+CREATE TABLE IF NOT EXISTS cost_daily_workload (
+    day                 DATE NOT NULL,
+    namespace           VARCHAR(64) NOT NULL,
+    workload_name       VARCHAR(128) NOT NULL,
+    workload_kind       VARCHAR(32),
+    node_pool           VARCHAR(64),
+    cost_center         VARCHAR(64),
+    hours               INT NOT NULL,
+    request_cores       DECIMAL(10, 4),
+    p95_cpu_usage       DECIMAL(10, 4),
+    request_mem_bytes   BIGINT,
+    p95_mem_usage_bytes BIGINT,
+    billable_cost       DECIMAL(12, 4),
+    usage_cost          DECIMAL(12, 4),
+    waste_cost          DECIMAL(12, 4),
+    cpu_usage_sketch    JSONB,
+    mem_usage_sketch    JSONB,
+    PRIMARY KEY (day, namespace, workload_name)
+);
+
 -- cost_roi_events: 优化动作流水
 CREATE TABLE IF NOT EXISTS cost_roi_events (
     id              SERIAL PRIMARY KEY,
@@ -73,3 +95,444 @@ CREATE TABLE IF NOT EXISTS cost_daily_network (
     created_at      TIMESTAMP DEFAULT NOW(),
     PRIMARY KEY (day, namespace, resource_id)
 );
+
+-- cost_calculation_run: 成本计算流水线执行记录，失败窗口可查询与重跑
+CREATE TABLE IF NOT EXISTS cost_calculation_run (
+    id              VARCHAR(64) PRIMARY KEY,
+    trigger         VARCHAR(16) NOT NULL,
+    window_start    TIMESTAMP NOT NULL,
+    window_end      TIMESTAMP NOT NULL,
+    status          VARCHAR(16) NOT NULL,
+    rows_written    INTEGER DEFAULT 0,
+    started_at      TIMESTAMP NOT NULL,
+    finished_at     TIMESTAMP,
+    duration_ms     BIGINT,
+    error           TEXT,
+    retry_of        VARCHAR(64),
+    scopes          JSONB,
+    failed_scopes   JSONB,
+    zeroed_values   INTEGER DEFAULT 0,
+    dropped_rows    INTEGER DEFAULT 0
+);
+
+-- namespace_lifecycle: namespace 首次/最近出现与删除时间，用于标注已终止 namespace 的历史成本
+CREATE TABLE IF NOT EXISTS namespace_lifecycle (
+    namespace       VARCHAR(64) PRIMARY KEY,
+    first_seen      TIMESTAMP NOT NULL,
+    last_seen       TIMESTAMP NOT NULL,
+    deleted_at      TIMESTAMP
+);
+
+-- workload_grade_event: 工作负载效率等级变更历史（含首次评级）
+CREATE TABLE IF NOT EXISTS workload_grade_event (
+    id                  VARCHAR(64) PRIMARY KEY,
+    namespace           VARCHAR(64) NOT NULL,
+    workload_name       VARCHAR(128) NOT NULL,
+    workload_type       VARCHAR(32),
+    from_grade          VARCHAR(32),
+    to_grade            VARCHAR(32) NOT NULL,
+    efficiency_score    DECIMAL(5, 2),
+    occurred_at         TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_workload_grade_event_workload ON workload_grade_event (namespace, workload_name, occurred_at);
+
+-- workload_recommendation: 已下发的规格调整/下线建议及每日对账得到的采纳进度（同一工作负载同一资源一条）
+CREATE TABLE IF NOT EXISTS workload_recommendation (
+    id                          VARCHAR(64) PRIMARY KEY,
+    team                        VARCHAR(64),
+    namespace                   VARCHAR(64) NOT NULL,
+    workload_name               VARCHAR(128) NOT NULL,
+    action                      VARCHAR(32) NOT NULL,
+    resource                    VARCHAR(32) NOT NULL,
+    baseline_request            DECIMAL(20, 6),
+    recommended_request         DECIMAL(20, 6),
+    estimated_monthly_savings   DECIMAL(15, 6),
+    issued_at                   TIMESTAMP NOT NULL,
+    status                      VARCHAR(16) NOT NULL DEFAULT 'open',
+    current_request             DECIMAL(20, 6),
+    adopted_fraction            DECIMAL(5, 4),
+    realized_monthly_savings    DECIMAL(15, 6),
+    checked_at                  TIMESTAMP,
+    UNIQUE (namespace, workload_name, resource)
+);
+CREATE INDEX IF NOT EXISTS idx_workload_recommendation_team ON workload_recommendation (team, issued_at);
+
+-- cost_hourly_workload.cost_center: lighthouse.io/cost-center 注解（工作负载优先于 namespace），用于分摊
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS cost_center VARCHAR(64);
+
+-- cost_price_history: CPU/内存单价历史，自 effective_from 起生效直到下一版本；更正历史单价后需重算受影响窗口
+CREATE TABLE IF NOT EXISTS cost_price_history (
+    id                      VARCHAR(64) PRIMARY KEY,
+    effective_from          TIMESTAMP NOT NULL UNIQUE,
+    cpu_price_per_core_hour DECIMAL(12, 6) NOT NULL,
+    mem_price_per_gb_hour   DECIMAL(12, 6) NOT NULL,
+    note                    TEXT,
+    created_at              TIMESTAMP NOT NULL,
+    updated_at              TIMESTAMP NOT NULL
+);
+
+-- cost_daily_namespace.shared_cost: 按分摊键（流量/容量/计算成本/均分）分摊的集群共享资源成本
+ALTER TABLE cost_daily_namespace ADD COLUMN IF NOT EXISTS shared_cost DECIMAL(15, 6) DEFAULT 0;
+
+-- cost_hourly_workload 使用量 sketch（costmodel.UsageSketch JSON），跨小时的 P95 由合并后的 sketch 计算
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS cpu_usage_sketch JSONB;
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS mem_usage_sketch JSONB;
+
+-- cost_hourly_workload 节点池：运行该工作负载的多数 Pod 所在节点的池（节点池标签，见 costmodel.NodePoolOf），未知时为空
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS node_pool VARCHAR(128);
+CREATE INDEX IF NOT EXISTS idx_cost_hourly_workload_pool ON cost_hourly_workload (node_pool, time_bucket);
+
+-- 成本快照完整性：保存时写入 content_hash；cost_snapshot_hash_chain 为追加式哈希链（保存/删除各追加一条），
+-- 应用账号只授予 INSERT/SELECT，任何事后修改都会导致 GET /api/v1/snapshots/verify 校验失败。
+-- 快照表 cost_snapshot 见文末（含 content_hash 与变化率护栏的 suspect / requires_confirmation / confirmed_* 列）
+CREATE TABLE IF NOT EXISTS cost_snapshot_hash_chain (
+    seq             BIGINT PRIMARY KEY,
+    snapshot_id     VARCHAR(64) NOT NULL,
+    content_hash    CHAR(64),
+    prev_hash       CHAR(64),
+    hash            CHAR(64) NOT NULL,
+    recorded_at     TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cost_snapshot_hash_chain_snapshot ON cost_snapshot_hash_chain (snapshot_id, seq);
+
+-- 字段级加密（security.encryption）：cost_bill_account_summary.sealed 保存金额与分类明细的密文，
+-- 启用后 total_amount / by_category 写入零值；metadata.value 以 {"__sealed": "lhenc:v1:..."} 保存
+ALTER TABLE cost_bill_account_summary ADD COLUMN IF NOT EXISTS sealed TEXT;
+
+-- service_account / service_account_key / api_key_usage: 机器服务账号与 API key；key 只保存 SHA-256，
+-- 用量按 key 与 UTC 日期累计（放行 requests 与超配额 rejected），配额判断与计数在同一条 UPDATE 中完成
+CREATE TABLE IF NOT EXISTS service_account (
+    id              VARCHAR(64) PRIMARY KEY,
+    name            VARCHAR(128) NOT NULL UNIQUE,
+    description     TEXT,
+    disabled        BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by      VARCHAR(128),
+    created_at      TIMESTAMP NOT NULL,
+    updated_at      TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS service_account_key (
+    id              VARCHAR(64) PRIMARY KEY,
+    account_id      VARCHAR(64) NOT NULL REFERENCES service_account (id) ON DELETE CASCADE,
+    name            VARCHAR(128),
+    prefix          VARCHAR(16) NOT NULL,
+    secret_hash     CHAR(64) NOT NULL UNIQUE,
+    scopes          TEXT[] NOT NULL,
+    daily_quota     INTEGER NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP NOT NULL,
+    expires_at      TIMESTAMP,
+    revoked_at      TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id          VARCHAR(64) NOT NULL,
+    day             DATE NOT NULL,
+    requests        BIGINT NOT NULL DEFAULT 0,
+    rejected        BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
+
+-- cost_snapshot_regrade: 效率阈值调整后按新阈值重评级的快照视图，原快照（及其 content_hash）保持不变；
+-- 每个快照一行，再次重评级时覆盖。grades 与快照 resource_results 按下标对应
+CREATE TABLE IF NOT EXISTS cost_snapshot_regrade (
+    snapshot_id             VARCHAR(64) PRIMARY KEY,
+    job_id                  VARCHAR(64) NOT NULL,
+    thresholds              JSONB NOT NULL,
+    grades                  JSONB NOT NULL,
+    zombie_count            INTEGER NOT NULL DEFAULT 0,
+    over_provisioned_count  INTEGER NOT NULL DEFAULT 0,
+    healthy_count           INTEGER NOT NULL DEFAULT 0,
+    risk_count              INTEGER NOT NULL DEFAULT 0,
+    regraded_by             VARCHAR(128),
+    regraded_at             TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cost_snapshot_regrade_job ON cost_snapshot_regrade (job_id);
+
+-- alert_rule: 用户自定义告警规则；condition 为条件 DSL（指标{标签="值"} 运算符 阈值，and / or 连接），
+-- 每 interval_seconds 对截至当时的 window_seconds 求值一次，last_* 与 firing_since 为最近一次求值的状态
+CREATE TABLE IF NOT EXISTS alert_rule (
+    id                  VARCHAR(64) PRIMARY KEY,
+    name                VARCHAR(128) NOT NULL UNIQUE,
+    description         TEXT,
+    condition           TEXT NOT NULL,
+    window_seconds      INTEGER NOT NULL,
+    interval_seconds    INTEGER NOT NULL,
+    severity            VARCHAR(16) NOT NULL,
+    alert_type          VARCHAR(32) NOT NULL,
+    channels            TEXT[],
+    enabled             BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by          VARCHAR(128),
+    created_at          TIMESTAMP NOT NULL,
+    updated_at          TIMESTAMP NOT NULL,
+    last_evaluated_at   TIMESTAMP,
+    last_state          VARCHAR(16),
+    last_error          TEXT,
+    firing_since        TIMESTAMP
+);
+
+-- notification_route / notification_silence: 通知投递前的路由与静默。matchers 为告警标签（alert_type、severity、
+-- namespace、team、rule 等）的等值匹配，值为 "*" 只要求标签存在；路由取 priority 最小的匹配规则。
+-- 静默提前结束时改写 ends_at 并记录 expired_by，记录不删除
+CREATE TABLE IF NOT EXISTS notification_route (
+    id              VARCHAR(64) PRIMARY KEY,
+    name            VARCHAR(128) NOT NULL UNIQUE,
+    matchers        JSONB NOT NULL,
+    channels        TEXT[] NOT NULL,
+    priority        INTEGER NOT NULL DEFAULT 0,
+    created_by      VARCHAR(128),
+    created_at      TIMESTAMP NOT NULL,
+    updated_at      TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS notification_silence (
+    id              VARCHAR(64) PRIMARY KEY,
+    matchers        JSONB NOT NULL,
+    starts_at       TIMESTAMP NOT NULL,
+    ends_at         TIMESTAMP NOT NULL,
+    reason          TEXT NOT NULL,
+    created_by      VARCHAR(128),
+    created_at      TIMESTAMP NOT NULL,
+    expired_by      VARCHAR(128)
+);
+CREATE INDEX IF NOT EXISTS idx_notification_silence_ends_at ON notification_silence (ends_at);
+
+-- cost_query_lineage: 成本查询响应的数据血缘（响应头 X-Lighthouse-Lineage-Id），记录响应时所用的快照、计算执行、
+-- 单价版本与构建版本，按值保存以便财务对数字存疑时回溯
+CREATE TABLE IF NOT EXISTS cost_query_lineage (
+    id              VARCHAR(64) PRIMARY KEY,
+    request_id      VARCHAR(64),
+    method          VARCHAR(8) NOT NULL,
+    path            TEXT NOT NULL,
+    status          INTEGER NOT NULL,
+    served_at       TIMESTAMP NOT NULL,
+    snapshot        JSONB,
+    calculation_run JSONB,
+    price_version   JSONB,
+    code            JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cost_query_lineage_served_at ON cost_query_lineage (served_at);
+
+-- List* 过滤索引：主键以时间开头，覆盖按时间窗口的查询；按 namespace 过滤（可叠加时间窗口）走下列索引。
+-- Mock 中对应的二级索引见 mock_index.go
+CREATE INDEX IF NOT EXISTS idx_cost_daily_namespace_namespace ON cost_daily_namespace (namespace, day);
+CREATE INDEX IF NOT EXISTS idx_cost_hourly_workload_namespace ON cost_hourly_workload (namespace, time_bucket);
+CREATE INDEX IF NOT EXISTS idx_cost_daily_workload_namespace ON cost_daily_workload (namespace, day);
+
+-- config_change: 部署流水线上报的集群配置变更（POST /api/v1/timeline/config-changes），进入集群事件时间线
+CREATE TABLE IF NOT EXISTS config_change (
+    id              VARCHAR(64) PRIMARY KEY,
+    namespace       VARCHAR(64) NOT NULL DEFAULT '',
+    kind            VARCHAR(64) NOT NULL,
+    name            VARCHAR(253) NOT NULL,
+    change_type     VARCHAR(64) NOT NULL,
+    old_value       TEXT,
+    new_value       TEXT,
+    source          VARCHAR(64),
+    author          VARCHAR(128),
+    occurred_at     TIMESTAMP NOT NULL,
+    created_at      TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_config_change_namespace ON config_change (namespace, occurred_at);
+
+-- slo_violation: SLO 违约记录（POST /api/v1/timeline/slo-violations），恢复时以同一 id 更新 recovered_at
+CREATE TABLE IF NOT EXISTS slo_violation (
+    id              VARCHAR(64) PRIMARY KEY,
+    namespace       VARCHAR(64) NOT NULL,
+    service         VARCHAR(128) NOT NULL,
+    violation_type  VARCHAR(32) NOT NULL,
+    actual_value    DOUBLE PRECISION,
+    threshold_value DOUBLE PRECISION,
+    severity        VARCHAR(16),
+    violated_at     TIMESTAMP NOT NULL,
+    recovered_at    TIMESTAMP,
+    created_at      TIMESTAMP NOT NULL,
+    updated_at      TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_slo_violation_namespace ON slo_violation (namespace, violated_at);
+
+-- 迁移导入的历史（POST /api/v1/admin/import/allocations，Kubecost / OpenCost 分配数据）：source 记录导入来源，
+-- 空为 Lighthouse 自身计算的行；导入不覆盖 source 为空的行
+ALTER TABLE cost_daily_namespace ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT '';
+
+-- cost_price_history.revision: 同一版本每次更正加一，快照 metadata.price_versions 记录为 <id>@<revision>；
+-- change_id 为批准该版本的单价变更申请
+ALTER TABLE cost_price_history ADD COLUMN IF NOT EXISTS revision INT NOT NULL DEFAULT 1;
+ALTER TABLE cost_price_history ADD COLUMN IF NOT EXISTS change_id VARCHAR(64);
+
+-- cost_price_change: 单价变更申请（POST /api/v1/pricing/changes），草稿经审批人批准后写入 cost_price_history；
+-- history 为提交与审批记录（JSON 数组）
+CREATE TABLE IF NOT EXISTS cost_price_change (
+    id                      VARCHAR(64) PRIMARY KEY,
+    status                  VARCHAR(16) NOT NULL,
+    effective_from          TIMESTAMP NOT NULL,
+    cpu_price_per_core_hour DECIMAL(12, 6) NOT NULL,
+    mem_price_per_gb_hour   DECIMAL(12, 6) NOT NULL,
+    note                    TEXT,
+    recalculate             BOOLEAN NOT NULL DEFAULT FALSE,
+    requested_by            VARCHAR(128),
+    price_version_id        VARCHAR(64),
+    history                 JSONB NOT NULL DEFAULT '[]',
+    created_at              TIMESTAMP NOT NULL,
+    updated_at              TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cost_price_change_status ON cost_price_change (status, created_at);
+
+-- optimization_tracking: 优化措施跟踪（roi.OptimizationTrackingRecord）。POST /api/v1/roi/recommendations/export
+-- 为每条导出的建议写入一条待验证记录（verified = false），bundle_id 为导出的清单包；before/after_state 为 JSONB
+CREATE TABLE IF NOT EXISTS optimization_tracking (
+    record_id               VARCHAR(64) PRIMARY KEY,
+    optimization_type       VARCHAR(32) NOT NULL,
+    target_resource_id      VARCHAR(255) NOT NULL,
+    target_resource_type    VARCHAR(32) NOT NULL,
+    recommendation_id       VARCHAR(64),
+    bundle_id               VARCHAR(64),
+    before_state            JSONB NOT NULL DEFAULT '{}',
+    after_state             JSONB NOT NULL DEFAULT '{}',
+    immediate_savings       DECIMAL(15, 6),
+    projected_savings       DECIMAL(15, 6),
+    resources_recovered     JSONB NOT NULL DEFAULT '{}',
+    implementation_date     TIMESTAMP NOT NULL,
+    implemented_by          VARCHAR(128),
+    verified                BOOLEAN NOT NULL DEFAULT FALSE,
+    verification_date       TIMESTAMP,
+    verified_by             VARCHAR(128),
+    risk_level              VARCHAR(16)
+);
+CREATE INDEX IF NOT EXISTS idx_optimization_tracking_bundle ON optimization_tracking (bundle_id);
+CREATE INDEX IF NOT EXISTS idx_optimization_tracking_recommendation ON optimization_tracking (recommendation_id, verified);
+
+-- service_dependency: 服务依赖边（POST /api/v1/dependencies/edges，链路追踪上报或静态依赖导入），caller 调用 callee；
+-- 影响分析沿反向边找出依赖某个服务的上游服务。tracing 边按 last_seen 过期，static 边每次导入整体替换
+CREATE TABLE IF NOT EXISTS service_dependency (
+    caller_namespace VARCHAR(64) NOT NULL,
+    caller           VARCHAR(128) NOT NULL,
+    callee_namespace VARCHAR(64) NOT NULL,
+    callee           VARCHAR(128) NOT NULL,
+    source           VARCHAR(16) NOT NULL,
+    calls_per_minute DOUBLE PRECISION NOT NULL DEFAULT 0,
+    last_seen        TIMESTAMP NOT NULL,
+    created_at       TIMESTAMP NOT NULL,
+    PRIMARY KEY (caller_namespace, caller, callee_namespace, callee, source)
+);
+CREATE INDEX IF NOT EXISTS idx_service_dependency_callee ON service_dependency (callee_namespace, callee);
+
+-- accounting_period: 已关账的会计期间（POST /api/v1/admin/periods/close），关账后期间内的 cost_daily_namespace 与
+-- cost_hourly_workload 不可再写；start/end_date 为日标签（end_date 不含），start/end_time 为按会计时区换算的时刻
+CREATE TABLE IF NOT EXISTS accounting_period (
+    label      VARCHAR(32) NOT NULL,
+    start_date DATE NOT NULL PRIMARY KEY,
+    end_date   DATE NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time   TIMESTAMP NOT NULL,
+    closed_by  VARCHAR(128),
+    closed_at  TIMESTAMP NOT NULL,
+    note       TEXT
+);
+
+-- cost_adjustment: 已关账期间的成本更正，只追加。重算写入已关账期间时原记录不变，差额（新值 - 原值）记为一条更正；
+-- 手工更正（POST /api/v1/admin/adjustments）记录录入人，经批准后记录审批人。报表按 date 叠加 daily_namespace_cost 更正
+CREATE TABLE IF NOT EXISTS cost_adjustment (
+    id         VARCHAR(64) PRIMARY KEY,
+    period     VARCHAR(32) NOT NULL,
+    record     VARCHAR(32) NOT NULL,
+    date       TIMESTAMP NOT NULL,
+    namespace  VARCHAR(64) NOT NULL,
+    workload   VARCHAR(128),
+    amount     DECIMAL(15, 6) NOT NULL,
+    source     VARCHAR(16) NOT NULL,
+    reason     TEXT,
+    created_by VARCHAR(128),
+    approver   VARCHAR(128),
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cost_adjustment_period ON cost_adjustment (period, namespace);
+CREATE INDEX IF NOT EXISTS idx_cost_adjustment_date ON cost_adjustment (date, record);
+
+-- Repository 的 SQL 实现（sql_repository.go）：核心表补齐 Go 结构体的全部字段。金额列沿用 DECIMAL；
+-- 快照的金额与效率为 DOUBLE PRECISION，读回值与保存值逐位相同，content_hash 才能在读回后复算一致
+ALTER TABLE cost_daily_namespace ADD COLUMN IF NOT EXISTS node_count INT;
+ALTER TABLE cost_daily_namespace ADD COLUMN IF NOT EXISTS workload_count INT;
+ALTER TABLE cost_daily_namespace ADD COLUMN IF NOT EXISTS created_at TIMESTAMP;
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS node_name VARCHAR(253);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS pod_name VARCHAR(253);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS request_mem_bytes BIGINT;
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS p95_mem_usage_bytes BIGINT;
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS cpu_billable_cost DECIMAL(15, 6);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS cpu_usage_cost DECIMAL(15, 6);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS cpu_waste_cost DECIMAL(15, 6);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS mem_billable_cost DECIMAL(15, 6);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS mem_usage_cost DECIMAL(15, 6);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS mem_waste_cost BIGINT;
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS billable_cost DECIMAL(15, 6);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS usage_cost DECIMAL(15, 6);
+ALTER TABLE cost_hourly_workload ADD COLUMN IF NOT EXISTS waste_cost DECIMAL(15, 6);
+
+-- cost_snapshot: 成本计算结果快照；明细与聚合结果为 JSONB，列表只读取请求的字段组（postgres.SnapshotFields）
+CREATE TABLE IF NOT EXISTS cost_snapshot (
+    id                       VARCHAR(64) PRIMARY KEY,
+    calculation_id           VARCHAR(64) NOT NULL DEFAULT '',
+    snapshot_time            TIMESTAMP NOT NULL,
+    time_range_start         TIMESTAMP NOT NULL,
+    time_range_end           TIMESTAMP NOT NULL,
+    total_billable_cost      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total_usage_cost         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total_waste_cost         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    overall_efficiency_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    zombie_count             INT NOT NULL DEFAULT 0,
+    over_provisioned_count   INT NOT NULL DEFAULT 0,
+    healthy_count            INT NOT NULL DEFAULT 0,
+    risk_count               INT NOT NULL DEFAULT 0,
+    metadata                 JSONB,
+    resource_results         JSONB,
+    aggregated_results       JSONB,
+    content_hash             CHAR(64) NOT NULL,
+    suspect                  BOOLEAN NOT NULL DEFAULT FALSE,
+    suspect_reason           TEXT NOT NULL DEFAULT '',
+    requires_confirmation    BOOLEAN NOT NULL DEFAULT FALSE,
+    confirmed_by             VARCHAR(128) NOT NULL DEFAULT '',
+    confirmed_at             TIMESTAMP,
+    created_at               TIMESTAMP NOT NULL,
+    updated_at               TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cost_snapshot_timestamp ON cost_snapshot (snapshot_time);
+CREATE INDEX IF NOT EXISTS idx_cost_snapshot_calculation ON cost_snapshot (calculation_id, snapshot_time);
+
+-- roi_baseline: ROI 对比基线
+CREATE TABLE IF NOT EXISTS roi_baseline (
+    id                VARCHAR(64) PRIMARY KEY,
+    name              VARCHAR(128) NOT NULL,
+    description       TEXT NOT NULL DEFAULT '',
+    baseline_type     VARCHAR(32) NOT NULL,
+    time_period_start TIMESTAMP NOT NULL,
+    time_period_end   TIMESTAMP NOT NULL,
+    metrics           JSONB,
+    reference_data    JSONB,
+    created_by        VARCHAR(128) NOT NULL DEFAULT '',
+    created_at        TIMESTAMP NOT NULL,
+    updated_at        TIMESTAMP NOT NULL
+);
+
+-- metadata: 键值配置与运行状态（偏好、水位线等），按 key 前缀列出
+CREATE TABLE IF NOT EXISTS metadata (
+    key         VARCHAR(256) PRIMARY KEY,
+    value       JSONB,
+    description TEXT NOT NULL DEFAULT '',
+    created_by  VARCHAR(128) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL,
+    updated_at  TIMESTAMP NOT NULL
+);
+
+-- SQL 实现的可选存储（sql_stores.go）：补齐日汇总的 CPU/内存成本拆分、优化跟踪的实施与影响说明；
+-- api_key_usage.last_allowed 为最近一次计数是否放行，由同一条 upsert 返回
+ALTER TABLE cost_daily_workload ADD COLUMN IF NOT EXISTS cpu_billable_cost DECIMAL(12, 4);
+ALTER TABLE cost_daily_workload ADD COLUMN IF NOT EXISTS cpu_usage_cost DECIMAL(12, 4);
+ALTER TABLE cost_daily_workload ADD COLUMN IF NOT EXISTS cpu_waste_cost DECIMAL(12, 4);
+ALTER TABLE cost_daily_workload ADD COLUMN IF NOT EXISTS mem_billable_cost DECIMAL(12, 4);
+ALTER TABLE cost_daily_workload ADD COLUMN IF NOT EXISTS mem_usage_cost DECIMAL(12, 4);
+ALTER TABLE cost_daily_workload ADD COLUMN IF NOT EXISTS mem_waste_cost DECIMAL(12, 4);
+ALTER TABLE optimization_tracking ADD COLUMN IF NOT EXISTS implementation_effort VARCHAR(32);
+ALTER TABLE optimization_tracking ADD COLUMN IF NOT EXISTS business_impact TEXT;
+ALTER TABLE optimization_tracking ADD COLUMN IF NOT EXISTS performance_impact TEXT;
+ALTER TABLE api_key_usage ADD COLUMN IF NOT EXISTS last_allowed BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- 手工更正须经另一人批准（POST /api/v1/admin/adjustments/:id/approve）后才计入报表；已有的行与重算更正为 approved
+ALTER TABLE cost_adjustment ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'approved';
+ALTER TABLE cost_adjustment ADD COLUMN IF NOT EXISTS approved_at TIMESTAMP;
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// pgError is a driver error carrying a SQLSTATE, like pgconn.PgError and pq.Error.
+type pgError string
+
+func (e pgError) Error() string    { return "pg error " + string(e) }
+func (e pgError) SQLState() string { return string(e) }
+
+func TestSQLErrorClassification(t *testing.T) {
+	cases := []struct {
+		err  error
+		want error // nil: unclassified
+	}{
+		{sql.ErrNoRows, dataerr.ErrNotFound},
+		{sql.ErrTxDone, dataerr.ErrConflict},
+		{pgError("23505"), dataerr.ErrConflict},
+		{fmt.Errorf("exec: %w", pgError("40001")), dataerr.ErrUnavailable},
+		{pgError("40P01"), dataerr.ErrUnavailable},
+		{pgError("08006"), dataerr.ErrUnavailable},
+		{pgError("53300"), dataerr.ErrUnavailable},
+		{pgError("57P01"), dataerr.ErrUnavailable},
+		{pgError("22P02"), dataerr.ErrValidation},
+		{pgError("23502"), dataerr.ErrValidation},
+		{errors.New("dial tcp: connection refused"), dataerr.ErrUnavailable},
+		{pgError("42601"), nil},
+		{context.Canceled, nil},
+	}
+	kinds := []error{dataerr.ErrNotFound, dataerr.ErrConflict, dataerr.ErrUnavailable, dataerr.ErrValidation}
+	for _, c := range cases {
+		got := sqlError("op", c.err)
+		for _, kind := range kinds {
+			if is := errors.Is(got, kind); is != (kind == c.want) {
+				t.Errorf("sqlError(%v) = %v, errors.Is(%v) = %v", c.err, got, kind, is)
+			}
+		}
+	}
+	if !errors.Is(sqlError("op", context.Canceled), context.Canceled) {
+		t.Error("context errors must stay matchable")
+	}
+	if sqlError("op", nil) != nil {
+		t.Error("sqlError(nil) != nil")
+	}
+}
+
+func TestUpsertSQL(t *testing.T) {
+	got := upsertSQL("cost_daily_namespace", []string{"day", "namespace", "billable_cost"}, 2)
+	want := "INSERT INTO cost_daily_namespace (day, namespace, billable_cost) VALUES ($1, $2, $3) " +
+		"ON CONFLICT (day, namespace) DO UPDATE SET billable_cost = EXCLUDED.billable_cost"
+	if got != want {
+		t.Errorf("upsertSQL = %q, want %q", got, want)
+	}
+}
+
+func TestSQLFilter(t *testing.T) {
+	var f sqlFilter
+	if f.where() != "" || f.page(0, 0) != "" {
+		t.Fatal("empty filter must add no clauses")
+	}
+	f.add("namespace = ?", "ns")
+	f.add("day >= ?", "2020-01-01")
+	if got := f.where() + f.page(10, 20); got != " WHERE namespace = $1 AND day >= $2 LIMIT $3 OFFSET $4" {
+		t.Errorf("clauses = %q", got)
+	}
+	if len(f.args) != 4 || f.args[2] != 10 || f.args[3] != 20 {
+		t.Errorf("args = %v", f.args)
+	}
+}
+
+func TestLikePrefix(t *testing.T) {
+	for prefix, want := range map[string]string{"": "%", "team/": "team/%", `50%_a\b`: `50\%\_a\\b%`} {
+		if got := likePrefix(prefix); got != want {
+			t.Errorf("likePrefix(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
@@ -0,0 +1,1480 @@
+// Package postgres sql_stores.go: SQLRepository 的可选存储（服务层与 ETL 按接口断言使用）：计算执行、单价历史与变更申请、
+// 等级事件、建议与优化跟踪、服务账号与 API key、告警规则、通知路由与静默、数据血缘、事件时间线、服务依赖、
+// 会计期间与更正、工作负载日汇总、快照重评级及账单/存储/网络明细。语义与 MockRepository 一致；这些表写入频率低，
+// 直接执行参数化 SQL，不预编译。
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// upsertKeepingSQL is upsertSQL leaving the columns keep of an existing row unchanged (e.g. created_at).
+func upsertKeepingSQL(table string, columns []string, keys int, keep ...string) string {
+	kept := make(map[string]bool, len(keep))
+	for _, c := range keep {
+		kept[c] = true
+	}
+	update := make([]string, 0, len(columns))
+	for i, c := range columns {
+		if i < keys || !kept[c] {
+			update = append(update, c)
+		}
+	}
+	q := upsertSQL(table, columns, keys)
+	sets := make([]string, 0, len(update)-keys)
+	for _, c := range update[keys:] {
+		sets = append(sets, c+" = EXCLUDED."+c)
+	}
+	return q[:strings.Index(q, " DO UPDATE SET ")] + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// exec runs a write of op outside the prepared statements.
+func (r *SQLRepository) exec(ctx context.Context, op, query string, args ...interface{}) error {
+	_, err := r.conn().ExecContext(ctx, query, args...)
+	return sqlError(op, err)
+}
+
+// queryOne reads the single row of query; no row is dataerr.ErrNotFound with notFound as message.
+func queryOne[T any](ctx context.Context, r *SQLRepository, op, notFound, query string, args []interface{}, scan func(rowScanner) (*T, error)) (*T, error) {
+	v, err := scan(r.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dataerr.NotFound("%s", notFound)
+	}
+	if err != nil {
+		return nil, sqlError(op, err)
+	}
+	return v, nil
+}
+
+// nullTime returns the parameter of an optional TIMESTAMP: NULL for nil or the zero time.
+func nullTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// timeOf returns a nullable TIMESTAMP as an optional time in UTC.
+func timeOf(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time.UTC()
+	return &v
+}
+
+// textArray is the select expression of a TEXT[] column, read as JSON (NULL stays NULL).
+func textArray(column string) string {
+	return "to_json(" + column + ")"
+}
+
+// textArrayArg returns the TEXT[] parameter of values; nil is an empty array.
+func textArrayArg(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// --- BillAccountSummary / DailyStorageCost / DailyNetworkCost ---
+
+const billSummarySelect = "account_id, period_type, period_start, period_end, COALESCE(total_amount, 0), COALESCE(currency, ''), " +
+	"by_category, created_at, COALESCE(sealed, '')"
+
+var billSummaryColumns = []string{"account_id", "period_type", "period_start", "period_end", "total_amount", "currency",
+	"by_category", "created_at", "sealed"}
+
+// SaveBillAccountSummary upserts the bill of an account period.
+func (r *SQLRepository) SaveBillAccountSummary(ctx context.Context, s BillAccountSummary) error {
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+	byCategory, err := jsonArg(s.ByCategory)
+	if err != nil {
+		return fmt.Errorf("encode bill account summary %s: %w", s.AccountID, err)
+	}
+	return r.exec(ctx, "save bill account summary", upsertSQL("cost_bill_account_summary", billSummaryColumns, 3),
+		s.AccountID, s.PeriodType, dayArg(s.PeriodStart), dayArg(s.PeriodEnd), s.TotalAmount, s.Currency, byCategory,
+		s.CreatedAt.UTC(), nullString(s.Sealed))
+}
+
+// GetBillAccountSummary reads the bill of an account period.
+func (r *SQLRepository) GetBillAccountSummary(ctx context.Context, accountID, periodType string, periodStart time.Time) (*BillAccountSummary, error) {
+	return queryOne(ctx, r, "get bill account summary",
+		fmt.Sprintf("bill account summary not found: %s-%s-%s", accountID, periodType, dayArg(periodStart)),
+		"SELECT "+billSummarySelect+" FROM cost_bill_account_summary WHERE account_id = $1 AND period_type = $2 AND period_start = $3",
+		[]interface{}{accountID, periodType, dayArg(periodStart)}, scanBillSummary)
+}
+
+// ListBillAccountSummaries lists the bills of an account (all accounts when empty) by period descending.
+func (r *SQLRepository) ListBillAccountSummaries(ctx context.Context, accountID string) ([]BillAccountSummary, error) {
+	var f sqlFilter
+	if accountID != "" {
+		f.add("account_id = ?", accountID)
+	}
+	return queryAll(ctx, r, "list bill account summaries",
+		"SELECT "+billSummarySelect+" FROM cost_bill_account_summary"+f.where()+" ORDER BY period_start DESC, account_id, period_type",
+		f.args, scanBillSummary)
+}
+
+func scanBillSummary(row rowScanner) (*BillAccountSummary, error) {
+	var s BillAccountSummary
+	var byCategory []byte
+	var createdAt sql.NullTime
+	if err := row.Scan(&s.AccountID, &s.PeriodType, &s.PeriodStart, &s.PeriodEnd, &s.TotalAmount, &s.Currency,
+		&byCategory, &createdAt, &s.Sealed); err != nil {
+		return nil, err
+	}
+	utc(&s.PeriodStart, &s.PeriodEnd)
+	s.CreatedAt = createdAt.Time.UTC()
+	if err := scanJSON(byCategory, &s.ByCategory); err != nil {
+		return nil, fmt.Errorf("decode bill account summary %s: %w", s.AccountID, err)
+	}
+	return &s, nil
+}
+
+// SaveDailyStorageCost upserts the cost of a PVC day.
+func (r *SQLRepository) SaveDailyStorageCost(ctx context.Context, c DailyStorageCost) error {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	return r.exec(ctx, "save daily storage cost",
+		upsertSQL("cost_daily_storage", []string{"day", "namespace", "pvc_name", "storage_class", "cost", "created_at"}, 3),
+		dayArg(c.Day), c.Namespace, c.PVCName, c.StorageClass, c.Cost, c.CreatedAt.UTC())
+}
+
+// GetDailyStorageCost reads the cost of a PVC day.
+func (r *SQLRepository) GetDailyStorageCost(ctx context.Context, day time.Time, namespace, pvcName string) (*DailyStorageCost, error) {
+	return queryOne(ctx, r, "get daily storage cost",
+		fmt.Sprintf("daily storage cost not found: %s-%s-%s", dayArg(day), namespace, pvcName),
+		"SELECT day, namespace, COALESCE(storage_class, ''), pvc_name, COALESCE(cost, 0), created_at FROM cost_daily_storage "+
+			"WHERE day = $1 AND namespace = $2 AND pvc_name = $3",
+		[]interface{}{dayArg(day), namespace, pvcName}, func(row rowScanner) (*DailyStorageCost, error) {
+			var c DailyStorageCost
+			var createdAt sql.NullTime
+			if err := row.Scan(&c.Day, &c.Namespace, &c.StorageClass, &c.PVCName, &c.Cost, &createdAt); err != nil {
+				return nil, err
+			}
+			utc(&c.Day)
+			c.CreatedAt = createdAt.Time.UTC()
+			return &c, nil
+		})
+}
+
+// SaveDailyNetworkCost upserts the cost of a network resource day.
+func (r *SQLRepository) SaveDailyNetworkCost(ctx context.Context, c DailyNetworkCost) error {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	return r.exec(ctx, "save daily network cost",
+		upsertSQL("cost_daily_network", []string{"day", "namespace", "resource_id", "resource_type", "cost", "created_at"}, 3),
+		dayArg(c.Day), c.Namespace, c.ResourceID, c.ResourceType, c.Cost, c.CreatedAt.UTC())
+}
+
+// GetDailyNetworkCost reads the cost of a network resource day.
+func (r *SQLRepository) GetDailyNetworkCost(ctx context.Context, day time.Time, namespace, resourceID string) (*DailyNetworkCost, error) {
+	return queryOne(ctx, r, "get daily network cost",
+		fmt.Sprintf("daily network cost not found: %s-%s-%s", dayArg(day), namespace, resourceID),
+		"SELECT day, namespace, COALESCE(resource_type, ''), resource_id, COALESCE(cost, 0), created_at FROM cost_daily_network "+
+			"WHERE day = $1 AND namespace = $2 AND resource_id = $3",
+		[]interface{}{dayArg(day), namespace, resourceID}, func(row rowScanner) (*DailyNetworkCost, error) {
+			var c DailyNetworkCost
+			var createdAt sql.NullTime
+			if err := row.Scan(&c.Day, &c.Namespace, &c.ResourceType, &c.ResourceID, &c.Cost, &createdAt); err != nil {
+				return nil, err
+			}
+			utc(&c.Day)
+			c.CreatedAt = createdAt.Time.UTC()
+			return &c, nil
+		})
+}
+
+// --- CalculationRun / NamespaceLifecycle ---
+
+var calculationRunColumns = []string{"id", "trigger", "window_start", "window_end", "status", "rows_written", "started_at",
+	"finished_at", "duration_ms", "error", "retry_of", "scopes", "failed_scopes", "zeroed_values", "dropped_rows"}
+
+const calculationRunSelect = "id, trigger, window_start, window_end, status, COALESCE(rows_written, 0), started_at, finished_at, " +
+	"COALESCE(duration_ms, 0), COALESCE(error, ''), COALESCE(retry_of, ''), scopes, failed_scopes, COALESCE(zeroed_values, 0), " +
+	"COALESCE(dropped_rows, 0)"
+
+// SaveCalculationRun upserts a calculation run.
+func (r *SQLRepository) SaveCalculationRun(ctx context.Context, run CalculationRun) error {
+	if run.ID == "" {
+		run.ID = "run-" + uuid.New().String()
+	}
+	scopes, err := jsonArg(run.Scopes)
+	if err != nil {
+		return fmt.Errorf("encode calculation run %s: %w", run.ID, err)
+	}
+	failed, err := jsonArg(run.FailedScopes)
+	if err != nil {
+		return fmt.Errorf("encode calculation run %s: %w", run.ID, err)
+	}
+	return r.exec(ctx, "save calculation run", upsertSQL("cost_calculation_run", calculationRunColumns, 1),
+		run.ID, run.Trigger, run.WindowStart.UTC(), run.WindowEnd.UTC(), run.Status, run.RowsWritten, run.StartedAt.UTC(),
+		nullTime(&run.FinishedAt), run.DurationMs, nullString(run.Error), nullString(run.RetryOf), scopes, failed,
+		run.ZeroedValues, run.DroppedRows)
+}
+
+// GetCalculationRun reads a calculation run.
+func (r *SQLRepository) GetCalculationRun(ctx context.Context, id string) (*CalculationRun, error) {
+	return queryOne(ctx, r, "get calculation run", "calculation run not found: "+id,
+		"SELECT "+calculationRunSelect+" FROM cost_calculation_run WHERE id = $1", []interface{}{id}, scanCalculationRun)
+}
+
+// ListCalculationRuns lists calculation runs by start time descending.
+func (r *SQLRepository) ListCalculationRuns(ctx context.Context, filter CalculationRunFilter) ([]CalculationRun, error) {
+	var f sqlFilter
+	if filter.Status != "" {
+		f.add("status = ?", filter.Status)
+	}
+	if filter.Trigger != "" {
+		f.add("trigger = ?", filter.Trigger)
+	}
+	if !filter.StartTime.IsZero() {
+		f.add("window_start >= ?", filter.StartTime.UTC())
+	}
+	if !filter.EndTime.IsZero() {
+		f.add("window_end <= ?", filter.EndTime.UTC())
+	}
+	query := "SELECT " + calculationRunSelect + " FROM cost_calculation_run" + f.where() + " ORDER BY started_at DESC, id"
+	query += f.page(filter.Limit, filter.Offset)
+	return queryAll(ctx, r, "list calculation runs", query, f.args, scanCalculationRun)
+}
+
+func scanCalculationRun(row rowScanner) (*CalculationRun, error) {
+	var run CalculationRun
+	var finishedAt sql.NullTime
+	var scopes, failed []byte
+	if err := row.Scan(&run.ID, &run.Trigger, &run.WindowStart, &run.WindowEnd, &run.Status, &run.RowsWritten, &run.StartedAt,
+		&finishedAt, &run.DurationMs, &run.Error, &run.RetryOf, &scopes, &failed, &run.ZeroedValues, &run.DroppedRows); err != nil {
+		return nil, err
+	}
+	utc(&run.WindowStart, &run.WindowEnd, &run.StartedAt)
+	if finishedAt.Valid {
+		run.FinishedAt = finishedAt.Time.UTC()
+	}
+	if err := scanJSON(scopes, &run.Scopes); err != nil {
+		return nil, fmt.Errorf("decode calculation run %s: %w", run.ID, err)
+	}
+	if err := scanJSON(failed, &run.FailedScopes); err != nil {
+		return nil, fmt.Errorf("decode calculation run %s: %w", run.ID, err)
+	}
+	return &run, nil
+}
+
+// SaveNamespaceLifecycle upserts the lifecycle of a namespace.
+func (r *SQLRepository) SaveNamespaceLifecycle(ctx context.Context, lifecycle NamespaceLifecycle) error {
+	if lifecycle.Namespace == "" {
+		return dataerr.Validation("namespace is required")
+	}
+	return r.exec(ctx, "save namespace lifecycle",
+		upsertSQL("namespace_lifecycle", []string{"namespace", "first_seen", "last_seen", "deleted_at"}, 1),
+		lifecycle.Namespace, lifecycle.FirstSeen.UTC(), lifecycle.LastSeen.UTC(), nullTime(lifecycle.DeletedAt))
+}
+
+// ListNamespaceLifecycles lists the namespace lifecycles by namespace.
+func (r *SQLRepository) ListNamespaceLifecycles(ctx context.Context) ([]NamespaceLifecycle, error) {
+	return queryAll(ctx, r, "list namespace lifecycles",
+		"SELECT namespace, first_seen, last_seen, deleted_at FROM namespace_lifecycle ORDER BY namespace", nil,
+		func(row rowScanner) (*NamespaceLifecycle, error) {
+			var l NamespaceLifecycle
+			var deletedAt sql.NullTime
+			if err := row.Scan(&l.Namespace, &l.FirstSeen, &l.LastSeen, &deletedAt); err != nil {
+				return nil, err
+			}
+			utc(&l.FirstSeen, &l.LastSeen)
+			l.DeletedAt = timeOf(deletedAt)
+			return &l, nil
+		})
+}
+
+// --- PriceVersion / PriceChange ---
+
+// SavePriceVersion saves a price version; an existing version with the same EffectiveFrom is
+// corrected (its ID and CreatedAt are kept, its Revision is incremented).
+func (r *SQLRepository) SavePriceVersion(ctx context.Context, version PriceVersion) error {
+	if err := version.UnitPrice().Validate(); err != nil {
+		return err
+	}
+	if version.ID == "" {
+		version.ID = fmt.Sprintf("price-%d", version.EffectiveFrom.Unix())
+	}
+	now := time.Now().UTC()
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = now
+	}
+	return r.exec(ctx, "save price version",
+		"INSERT INTO cost_price_history (id, effective_from, cpu_price_per_core_hour, mem_price_per_gb_hour, note, revision, change_id, "+
+			"created_at, updated_at) VALUES ($1, $2, $3, $4, $5, 1, $6, $7, $8) ON CONFLICT (effective_from) DO UPDATE SET "+
+			"cpu_price_per_core_hour = EXCLUDED.cpu_price_per_core_hour, mem_price_per_gb_hour = EXCLUDED.mem_price_per_gb_hour, "+
+			"note = EXCLUDED.note, change_id = EXCLUDED.change_id, revision = cost_price_history.revision + 1, updated_at = EXCLUDED.updated_at",
+		version.ID, version.EffectiveFrom.UTC(), version.CPUPricePerCoreHour, version.MemPricePerGBHour, nullString(version.Note),
+		nullString(version.ChangeID), version.CreatedAt.UTC(), now)
+}
+
+// ListPriceVersions lists the price versions by effective time.
+func (r *SQLRepository) ListPriceVersions(ctx context.Context) ([]PriceVersion, error) {
+	return queryAll(ctx, r, "list price versions",
+		"SELECT id, effective_from, cpu_price_per_core_hour, mem_price_per_gb_hour, COALESCE(note, ''), revision, "+
+			"COALESCE(change_id, ''), created_at, updated_at FROM cost_price_history ORDER BY effective_from", nil,
+		func(row rowScanner) (*PriceVersion, error) {
+			var v PriceVersion
+			if err := row.Scan(&v.ID, &v.EffectiveFrom, &v.CPUPricePerCoreHour, &v.MemPricePerGBHour, &v.Note, &v.Revision,
+				&v.ChangeID, &v.CreatedAt, &v.UpdatedAt); err != nil {
+				return nil, err
+			}
+			utc(&v.EffectiveFrom, &v.CreatedAt, &v.UpdatedAt)
+			return &v, nil
+		})
+}
+
+var priceChangeColumns = []string{"id", "status", "effective_from", "cpu_price_per_core_hour", "mem_price_per_gb_hour", "note",
+	"recalculate", "requested_by", "price_version_id", "history", "created_at", "updated_at"}
+
+const priceChangeSelect = "id, status, effective_from, cpu_price_per_core_hour, mem_price_per_gb_hour, COALESCE(note, ''), " +
+	"recalculate, COALESCE(requested_by, ''), COALESCE(price_version_id, ''), history, created_at, updated_at"
+
+// SavePriceChange upserts a price change request and returns it as saved; an existing request
+// keeps its CreatedAt.
+func (r *SQLRepository) SavePriceChange(ctx context.Context, change PriceChange) (PriceChange, error) {
+	if change.ID == "" {
+		change.ID = "pricechange-" + uuid.New().String()
+	}
+	now := time.Now().UTC()
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = now
+	}
+	change.UpdatedAt = now
+	history := change.History
+	if history == nil {
+		history = []PriceChangeEvent{}
+	}
+	historyArg, err := jsonArg(history)
+	if err != nil {
+		return PriceChange{}, fmt.Errorf("encode price change %s: %w", change.ID, err)
+	}
+	query := upsertKeepingSQL("cost_price_change", priceChangeColumns, 1, "created_at") + " RETURNING created_at"
+	if err := r.conn().QueryRowContext(ctx, query,
+		change.ID, change.Status, change.EffectiveFrom.UTC(), change.CPUPricePerCoreHour, change.MemPricePerGBHour,
+		nullString(change.Note), change.Recalculate, nullString(change.RequestedBy), nullString(change.PriceVersionID),
+		historyArg, change.CreatedAt.UTC(), change.UpdatedAt,
+	).Scan(&change.CreatedAt); err != nil {
+		return PriceChange{}, sqlError("save price change", err)
+	}
+	utc(&change.CreatedAt)
+	change.History = append([]PriceChangeEvent(nil), change.History...)
+	return change, nil
+}
+
+// GetPriceChange reads a price change request.
+func (r *SQLRepository) GetPriceChange(ctx context.Context, id string) (*PriceChange, error) {
+	return queryOne(ctx, r, "get price change", "price change not found: "+id,
+		"SELECT "+priceChangeSelect+" FROM cost_price_change WHERE id = $1", []interface{}{id}, scanPriceChange)
+}
+
+// ListPriceChanges lists the price change requests by creation time descending.
+func (r *SQLRepository) ListPriceChanges(ctx context.Context) ([]PriceChange, error) {
+	return queryAll(ctx, r, "list price changes",
+		"SELECT "+priceChangeSelect+" FROM cost_price_change ORDER BY created_at DESC, id DESC", nil, scanPriceChange)
+}
+
+func scanPriceChange(row rowScanner) (*PriceChange, error) {
+	var c PriceChange
+	var history []byte
+	if err := row.Scan(&c.ID, &c.Status, &c.EffectiveFrom, &c.CPUPricePerCoreHour, &c.MemPricePerGBHour, &c.Note,
+		&c.Recalculate, &c.RequestedBy, &c.PriceVersionID, &history, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	utc(&c.EffectiveFrom, &c.CreatedAt, &c.UpdatedAt)
+	if err := scanJSON(history, &c.History); err != nil {
+		return nil, fmt.Errorf("decode price change %s: %w", c.ID, err)
+	}
+	for i := range c.History {
+		utc(&c.History[i].At)
+	}
+	return &c, nil
+}
+
+// --- GradeChangeEvent / SnapshotRegrade ---
+
+// SaveGradeChangeEvent appends a grade change event.
+func (r *SQLRepository) SaveGradeChangeEvent(ctx context.Context, event GradeChangeEvent) error {
+	if event.ID == "" {
+		event.ID = "grade-" + uuid.New().String()
+	}
+	return r.exec(ctx, "save grade change event",
+		"INSERT INTO workload_grade_event (id, namespace, workload_name, workload_type, from_grade, to_grade, efficiency_score, occurred_at) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		event.ID, event.Namespace, event.WorkloadName, nullString(event.WorkloadType), nullString(event.FromGrade), event.ToGrade,
+		event.EfficiencyScore, event.OccurredAt.UTC())
+}
+
+// ListGradeChangeEvents lists grade change events by occurrence.
+func (r *SQLRepository) ListGradeChangeEvents(ctx context.Context, filter GradeChangeEventFilter) ([]GradeChangeEvent, error) {
+	var f sqlFilter
+	for _, eq := range []struct{ column, value string }{
+		{"namespace", filter.Namespace}, {"workload_name", filter.WorkloadName}, {"to_grade", filter.ToGrade},
+	} {
+		if eq.value != "" {
+			f.add(eq.column+" = ?", eq.value)
+		}
+	}
+	if !filter.StartTime.IsZero() {
+		f.add("occurred_at >= ?", filter.StartTime.UTC())
+	}
+	if !filter.EndTime.IsZero() {
+		f.add("occurred_at <= ?", filter.EndTime.UTC())
+	}
+	query := "SELECT id, namespace, workload_name, COALESCE(workload_type, ''), COALESCE(from_grade, ''), to_grade, " +
+		"COALESCE(efficiency_score, 0), occurred_at FROM workload_grade_event" + f.where() + " ORDER BY occurred_at, id"
+	query += f.page(filter.Limit, filter.Offset)
+	return queryAll(ctx, r, "list grade change events", query, f.args, func(row rowScanner) (*GradeChangeEvent, error) {
+		var e GradeChangeEvent
+		if err := row.Scan(&e.ID, &e.Namespace, &e.WorkloadName, &e.WorkloadType, &e.FromGrade, &e.ToGrade,
+			&e.EfficiencyScore, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		utc(&e.OccurredAt)
+		return &e, nil
+	})
+}
+
+var regradeColumns = []string{"snapshot_id", "job_id", "thresholds", "grades", "zombie_count", "over_provisioned_count",
+	"healthy_count", "risk_count", "regraded_by", "regraded_at"}
+
+// SaveSnapshotRegrade upserts the regrade of a snapshot, which must exist.
+func (r *SQLRepository) SaveSnapshotRegrade(ctx context.Context, regrade SnapshotRegrade) error {
+	thresholds, err := jsonArg(regrade.Thresholds)
+	if err != nil {
+		return fmt.Errorf("encode snapshot regrade %s: %w", regrade.SnapshotID, err)
+	}
+	grades, err := jsonArg(regrade.Grades)
+	if err != nil {
+		return fmt.Errorf("encode snapshot regrade %s: %w", regrade.SnapshotID, err)
+	}
+	return r.inTx(ctx, "save snapshot regrade", func(tx *SQLRepository) error {
+		var exists bool
+		if err := tx.conn().QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM cost_snapshot WHERE id = $1 FOR SHARE)",
+			regrade.SnapshotID).Scan(&exists); err != nil {
+			return sqlError("save snapshot regrade", err)
+		}
+		if !exists {
+			return dataerr.NotFound("cost snapshot not found: %s", regrade.SnapshotID)
+		}
+		return tx.exec(ctx, "save snapshot regrade", upsertSQL("cost_snapshot_regrade", regradeColumns, 1),
+			regrade.SnapshotID, regrade.JobID, thresholds, grades, regrade.ZombieCount, regrade.OverProvisionedCount,
+			regrade.HealthyCount, regrade.RiskCount, nullString(regrade.RegradedBy), regrade.RegradedAt.UTC())
+	})
+}
+
+// GetSnapshotRegrade reads the regrade of a snapshot.
+func (r *SQLRepository) GetSnapshotRegrade(ctx context.Context, snapshotID string) (*SnapshotRegrade, error) {
+	return queryOne(ctx, r, "get snapshot regrade", "snapshot regrade not found: "+snapshotID,
+		"SELECT snapshot_id, job_id, thresholds, grades, zombie_count, over_provisioned_count, healthy_count, risk_count, "+
+			"COALESCE(regraded_by, ''), regraded_at FROM cost_snapshot_regrade WHERE snapshot_id = $1",
+		[]interface{}{snapshotID}, func(row rowScanner) (*SnapshotRegrade, error) {
+			var g SnapshotRegrade
+			var thresholds, grades []byte
+			if err := row.Scan(&g.SnapshotID, &g.JobID, &thresholds, &grades, &g.ZombieCount, &g.OverProvisionedCount,
+				&g.HealthyCount, &g.RiskCount, &g.RegradedBy, &g.RegradedAt); err != nil {
+				return nil, err
+			}
+			utc(&g.RegradedAt)
+			if err := scanJSON(thresholds, &g.Thresholds); err != nil {
+				return nil, fmt.Errorf("decode snapshot regrade %s: %w", g.SnapshotID, err)
+			}
+			if err := scanJSON(grades, &g.Grades); err != nil {
+				return nil, fmt.Errorf("decode snapshot regrade %s: %w", g.SnapshotID, err)
+			}
+			return &g, nil
+		})
+}
+
+// --- IssuedRecommendation / OptimizationTrackingRecord ---
+
+var recommendationColumns = []string{"namespace", "workload_name", "resource", "id", "team", "action", "baseline_request",
+	"recommended_request", "estimated_monthly_savings", "issued_at", "status", "current_request", "adopted_fraction",
+	"realized_monthly_savings", "checked_at"}
+
+// SaveIssuedRecommendation upserts the recommendation of a workload resource; an existing one keeps its ID.
+func (r *SQLRepository) SaveIssuedRecommendation(ctx context.Context, rec IssuedRecommendation) error {
+	if rec.ID == "" {
+		rec.ID = "rec-" + uuid.New().String()
+	}
+	if rec.Status == "" {
+		rec.Status = "open"
+	}
+	return r.exec(ctx, "save issued recommendation", upsertKeepingSQL("workload_recommendation", recommendationColumns, 3, "id"),
+		rec.Namespace, rec.WorkloadName, rec.Resource, rec.ID, nullString(rec.Team), rec.Action, rec.BaselineRequest,
+		rec.RecommendedRequest, rec.EstimatedMonthlySavings, rec.IssuedAt.UTC(), rec.Status, rec.CurrentRequest,
+		rec.AdoptedFraction, rec.RealizedMonthlySavings, nullTime(&rec.CheckedAt))
+}
+
+// ListIssuedRecommendations lists issued recommendations by issue time.
+func (r *SQLRepository) ListIssuedRecommendations(ctx context.Context, filter IssuedRecommendationFilter) ([]IssuedRecommendation, error) {
+	var f sqlFilter
+	for _, eq := range []struct{ column, value string }{
+		{"team", filter.Team}, {"namespace", filter.Namespace}, {"workload_name", filter.WorkloadName}, {"status", filter.Status},
+	} {
+		if eq.value != "" {
+			f.add(eq.column+" = ?", eq.value)
+		}
+	}
+	if !filter.IssuedAfter.IsZero() {
+		f.add("issued_at >= ?", filter.IssuedAfter.UTC())
+	}
+	query := "SELECT id, COALESCE(team, ''), namespace, workload_name, action, resource, COALESCE(baseline_request, 0), " +
+		"COALESCE(recommended_request, 0), COALESCE(estimated_monthly_savings, 0), issued_at, status, COALESCE(current_request, 0), " +
+		"COALESCE(adopted_fraction, 0), COALESCE(realized_monthly_savings, 0), checked_at FROM workload_recommendation" +
+		f.where() + " ORDER BY issued_at, id"
+	return queryAll(ctx, r, "list issued recommendations", query, f.args, func(row rowScanner) (*IssuedRecommendation, error) {
+		var rec IssuedRecommendation
+		var checkedAt sql.NullTime
+		if err := row.Scan(&rec.ID, &rec.Team, &rec.Namespace, &rec.WorkloadName, &rec.Action, &rec.Resource, &rec.BaselineRequest,
+			&rec.RecommendedRequest, &rec.EstimatedMonthlySavings, &rec.IssuedAt, &rec.Status, &rec.CurrentRequest,
+			&rec.AdoptedFraction, &rec.RealizedMonthlySavings, &checkedAt); err != nil {
+			return nil, err
+		}
+		utc(&rec.IssuedAt)
+		if checkedAt.Valid {
+			rec.CheckedAt = checkedAt.Time.UTC()
+		}
+		return &rec, nil
+	})
+}
+
+var optimizationColumns = []string{"record_id", "optimization_type", "target_resource_id", "target_resource_type",
+	"recommendation_id", "bundle_id", "before_state", "after_state", "immediate_savings", "projected_savings",
+	"resources_recovered", "implementation_date", "implemented_by", "implementation_effort", "verified", "verification_date",
+	"verified_by", "risk_level", "business_impact", "performance_impact"}
+
+// SaveOptimizationRecord upserts an optimization tracking record and returns it as saved.
+func (r *SQLRepository) SaveOptimizationRecord(ctx context.Context, rec roi.OptimizationTrackingRecord) (roi.OptimizationTrackingRecord, error) {
+	if rec.RecordID == "" {
+		rec.RecordID = "opt-" + uuid.New().String()
+	}
+	recovered := rec.ResourcesRecovered
+	if recovered == nil {
+		recovered = map[string]float64{}
+	}
+	states := make([]string, 3)
+	for i, v := range []interface{}{nonNilMap(rec.BeforeState), nonNilMap(rec.AfterState), recovered} {
+		s, err := jsonArg(v)
+		if err != nil {
+			return roi.OptimizationTrackingRecord{}, fmt.Errorf("encode optimization record %s: %w", rec.RecordID, err)
+		}
+		states[i] = s
+	}
+	err := r.exec(ctx, "save optimization record", upsertSQL("optimization_tracking", optimizationColumns, 1),
+		rec.RecordID, rec.OptimizationType, rec.TargetResourceID, rec.TargetResourceType, nullString(rec.RecommendationID),
+		nullString(rec.BundleID), states[0], states[1], rec.ImmediateSavings, rec.ProjectedSavings, states[2],
+		rec.ImplementationDate.UTC(), nullString(rec.ImplementedBy), nullString(rec.ImplementationEffort), rec.Verified,
+		nullTime(&rec.VerificationDate), nullString(rec.VerifiedBy), nullString(rec.RiskLevel), nullString(rec.BusinessImpact),
+		nullString(rec.PerformanceImpact))
+	if err != nil {
+		return roi.OptimizationTrackingRecord{}, err
+	}
+	return rec, nil
+}
+
+// ListOptimizationRecords lists optimization tracking records by implementation date.
+func (r *SQLRepository) ListOptimizationRecords(ctx context.Context, filter OptimizationRecordFilter) ([]roi.OptimizationTrackingRecord, error) {
+	var f sqlFilter
+	if filter.BundleID != "" {
+		f.add("bundle_id = ?", filter.BundleID)
+	}
+	if filter.RecommendationID != "" {
+		f.add("recommendation_id = ?", filter.RecommendationID)
+	}
+	if filter.Unverified {
+		f.conds = append(f.conds, "NOT verified")
+	}
+	query := "SELECT record_id, optimization_type, target_resource_id, target_resource_type, COALESCE(recommendation_id, ''), " +
+		"COALESCE(bundle_id, ''), before_state, after_state, COALESCE(immediate_savings, 0), COALESCE(projected_savings, 0), " +
+		"resources_recovered, implementation_date, COALESCE(implemented_by, ''), COALESCE(implementation_effort, ''), verified, " +
+		"verification_date, COALESCE(verified_by, ''), COALESCE(risk_level, ''), COALESCE(business_impact, ''), " +
+		"COALESCE(performance_impact, '') FROM optimization_tracking" + f.where() + " ORDER BY implementation_date, record_id"
+	return queryAll(ctx, r, "list optimization records", query, f.args, func(row rowScanner) (*roi.OptimizationTrackingRecord, error) {
+		var rec roi.OptimizationTrackingRecord
+		var before, after, recovered []byte
+		var verifiedAt sql.NullTime
+		if err := row.Scan(&rec.RecordID, &rec.OptimizationType, &rec.TargetResourceID, &rec.TargetResourceType,
+			&rec.RecommendationID, &rec.BundleID, &before, &after, &rec.ImmediateSavings, &rec.ProjectedSavings, &recovered,
+			&rec.ImplementationDate, &rec.ImplementedBy, &rec.ImplementationEffort, &rec.Verified, &verifiedAt, &rec.VerifiedBy,
+			&rec.RiskLevel, &rec.BusinessImpact, &rec.PerformanceImpact); err != nil {
+			return nil, err
+		}
+		utc(&rec.ImplementationDate)
+		if verifiedAt.Valid {
+			rec.VerificationDate = verifiedAt.Time.UTC()
+		}
+		for _, s := range []struct {
+			b []byte
+			v interface{}
+		}{{before, &rec.BeforeState}, {after, &rec.AfterState}, {recovered, &rec.ResourcesRecovered}} {
+			if err := scanJSON(s.b, s.v); err != nil {
+				return nil, fmt.Errorf("decode optimization record %s: %w", rec.RecordID, err)
+			}
+		}
+		return &rec, nil
+	})
+}
+
+// nonNilMap returns m, or an empty map for nil (the state columns are NOT NULL).
+func nonNilMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+// --- ServiceAccount / APIKey / APIKeyUsage ---
+
+// SaveServiceAccount upserts a service account and returns it as saved.
+func (r *SQLRepository) SaveServiceAccount(ctx context.Context, account ServiceAccount) (ServiceAccount, error) {
+	if account.ID == "" {
+		account.ID = "sa-" + uuid.New().String()
+	}
+	now := time.Now().UTC()
+	if account.CreatedAt.IsZero() {
+		account.CreatedAt = now
+	}
+	if account.UpdatedAt.IsZero() {
+		account.UpdatedAt = now
+	}
+	err := r.exec(ctx, "save service account",
+		upsertSQL("service_account", []string{"id", "name", "description", "disabled", "created_by", "created_at", "updated_at"}, 1),
+		account.ID, account.Name, nullString(account.Description), account.Disabled, nullString(account.CreatedBy),
+		account.CreatedAt.UTC(), account.UpdatedAt.UTC())
+	if err != nil {
+		return ServiceAccount{}, err
+	}
+	return account, nil
+}
+
+const serviceAccountSelect = "SELECT id, name, COALESCE(description, ''), disabled, COALESCE(created_by, ''), created_at, updated_at FROM service_account"
+
+// GetServiceAccount reads a service account.
+func (r *SQLRepository) GetServiceAccount(ctx context.Context, id string) (*ServiceAccount, error) {
+	return queryOne(ctx, r, "get service account", "service account not found: "+id,
+		serviceAccountSelect+" WHERE id = $1", []interface{}{id}, scanServiceAccount)
+}
+
+// ListServiceAccounts lists the service accounts by name.
+func (r *SQLRepository) ListServiceAccounts(ctx context.Context) ([]ServiceAccount, error) {
+	return queryAll(ctx, r, "list service accounts", serviceAccountSelect+" ORDER BY name", nil, scanServiceAccount)
+}
+
+// DeleteServiceAccount deletes a service account and its API keys; their usage is kept.
+func (r *SQLRepository) DeleteServiceAccount(ctx context.Context, id string) error {
+	res, err := r.conn().ExecContext(ctx, "DELETE FROM service_account WHERE id = $1", id)
+	if err := deleted(res, err, "delete service account"); errors.Is(err, dataerr.ErrNotFound) {
+		return dataerr.NotFound("service account not found: %s", id)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func scanServiceAccount(row rowScanner) (*ServiceAccount, error) {
+	var a ServiceAccount
+	if err := row.Scan(&a.ID, &a.Name, &a.Description, &a.Disabled, &a.CreatedBy, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		return nil, err
+	}
+	utc(&a.CreatedAt, &a.UpdatedAt)
+	return &a, nil
+}
+
+// SaveAPIKey upserts an API key and returns it as saved.
+func (r *SQLRepository) SaveAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	if key.ID == "" {
+		key.ID = "key-" + uuid.New().String()
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now().UTC()
+	}
+	err := r.exec(ctx, "save api key",
+		upsertSQL("service_account_key", []string{"id", "account_id", "name", "prefix", "secret_hash", "scopes", "daily_quota",
+			"created_at", "expires_at", "revoked_at"}, 1),
+		key.ID, key.AccountID, nullString(key.Name), key.Prefix, key.SecretHash, textArrayArg(key.Scopes), key.DailyQuota,
+		key.CreatedAt.UTC(), nullTime(key.ExpiresAt), nullTime(key.RevokedAt))
+	if err != nil {
+		return APIKey{}, err
+	}
+	key.Scopes = append([]string(nil), key.Scopes...)
+	return key, nil
+}
+
+var apiKeySelect = "SELECT id, account_id, COALESCE(name, ''), prefix, secret_hash, " + textArray("scopes") +
+	", daily_quota, created_at, expires_at, revoked_at FROM service_account_key"
+
+// GetAPIKeyByHash finds an API key, revoked and expired ones included, by the hash of its secret.
+func (r *SQLRepository) GetAPIKeyByHash(ctx context.Context, secretHash string) (*APIKey, error) {
+	return queryOne(ctx, r, "get api key", "api key not found", apiKeySelect+" WHERE secret_hash = $1",
+		[]interface{}{secretHash}, scanAPIKey)
+}
+
+// ListAPIKeys lists the API keys of an account (all accounts when empty) by creation time.
+func (r *SQLRepository) ListAPIKeys(ctx context.Context, accountID string) ([]APIKey, error) {
+	var f sqlFilter
+	if accountID != "" {
+		f.add("account_id = ?", accountID)
+	}
+	return queryAll(ctx, r, "list api keys", apiKeySelect+f.where()+" ORDER BY created_at, id", f.args, scanAPIKey)
+}
+
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	var k APIKey
+	var scopes []byte
+	var expiresAt, revokedAt sql.NullTime
+	if err := row.Scan(&k.ID, &k.AccountID, &k.Name, &k.Prefix, &k.SecretHash, &scopes, &k.DailyQuota, &k.CreatedAt,
+		&expiresAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	utc(&k.CreatedAt)
+	k.ExpiresAt, k.RevokedAt = timeOf(expiresAt), timeOf(revokedAt)
+	if err := scanJSON(scopes, &k.Scopes); err != nil {
+		return nil, fmt.Errorf("decode api key %s: %w", k.ID, err)
+	}
+	return &k, nil
+}
+
+// IncrementAPIKeyUsage counts a request of the key on the UTC day of date in one statement: with
+// quota > 0 and quota requests already allowed that day it is rejected, otherwise allowed. It
+// returns the updated usage and whether the request is allowed.
+func (r *SQLRepository) IncrementAPIKeyUsage(ctx context.Context, keyID string, date time.Time, quota int) (APIKeyUsage, bool, error) {
+	u := APIKeyUsage{KeyID: keyID, Date: date.UTC().Truncate(24 * time.Hour)}
+	// the SET expressions see the row before the update, so last_allowed is the decision of this request
+	var allowed bool
+	err := r.conn().QueryRowContext(ctx,
+		"INSERT INTO api_key_usage AS u (key_id, day, requests, rejected, last_allowed) VALUES ($1, $2, 1, 0, TRUE) "+
+			"ON CONFLICT (key_id, day) DO UPDATE SET "+
+			"requests = u.requests + CASE WHEN $3 <= 0 OR u.requests < $3 THEN 1 ELSE 0 END, "+
+			"rejected = u.rejected + CASE WHEN $3 <= 0 OR u.requests < $3 THEN 0 ELSE 1 END, "+
+			"last_allowed = ($3 <= 0 OR u.requests < $3) "+
+			"RETURNING requests, rejected, last_allowed",
+		keyID, dayArg(u.Date), quota,
+	).Scan(&u.Requests, &u.Rejected, &allowed)
+	if err != nil {
+		return APIKeyUsage{}, false, sqlError("record api key usage", err)
+	}
+	return u, allowed, nil
+}
+
+// ListAPIKeyUsage lists the daily usage of the keys within [startDate, endDate] by key and day.
+func (r *SQLRepository) ListAPIKeyUsage(ctx context.Context, keyIDs []string, startDate, endDate time.Time) ([]APIKeyUsage, error) {
+	if len(keyIDs) == 0 {
+		return []APIKeyUsage{}, nil
+	}
+	return queryAll(ctx, r, "list api key usage",
+		"SELECT key_id, day, requests, rejected FROM api_key_usage WHERE key_id = ANY($1) AND day >= $2 AND day <= $3 ORDER BY key_id, day",
+		[]interface{}{keyIDs, dayArg(startDate), dayArg(endDate)}, func(row rowScanner) (*APIKeyUsage, error) {
+			var u APIKeyUsage
+			if err := row.Scan(&u.KeyID, &u.Date, &u.Requests, &u.Rejected); err != nil {
+				return nil, err
+			}
+			utc(&u.Date)
+			return &u, nil
+		})
+}
+
+// --- AlertRule / NotificationRoute / NotificationSilence ---
+
+var alertRuleColumns = []string{"id", "name", "description", "condition", "window_seconds", "interval_seconds", "severity",
+	"alert_type", "channels", "enabled", "created_by", "created_at", "updated_at", "last_evaluated_at", "last_state", "last_error",
+	"firing_since"}
+
+var alertRuleSelect = "SELECT id, name, COALESCE(description, ''), condition, window_seconds, interval_seconds, severity, alert_type, " +
+	textArray("channels") + ", enabled, COALESCE(created_by, ''), created_at, updated_at, last_evaluated_at, COALESCE(last_state, ''), " +
+	"COALESCE(last_error, ''), firing_since FROM alert_rule"
+
+// SaveAlertRule upserts an alert rule and returns it as saved.
+func (r *SQLRepository) SaveAlertRule(ctx context.Context, rule AlertRule) (AlertRule, error) {
+	if rule.ID == "" {
+		rule.ID = "rule-" + uuid.New().String()
+	}
+	now := time.Now().UTC()
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = now
+	}
+	if rule.UpdatedAt.IsZero() {
+		rule.UpdatedAt = now
+	}
+	err := r.exec(ctx, "save alert rule", upsertSQL("alert_rule", alertRuleColumns, 1),
+		rule.ID, rule.Name, nullString(rule.Description), rule.Condition, int(rule.Window/time.Second), int(rule.Interval/time.Second),
+		rule.Severity, rule.AlertType, textArrayArg(rule.Channels), rule.Enabled, nullString(rule.CreatedBy), rule.CreatedAt.UTC(),
+		rule.UpdatedAt.UTC(), nullTime(rule.LastEvaluatedAt), nullString(rule.LastState), nullString(rule.LastError),
+		nullTime(rule.FiringSince))
+	if err != nil {
+		return AlertRule{}, err
+	}
+	rule.Channels = append([]string(nil), rule.Channels...)
+	return rule, nil
+}
+
+// GetAlertRule reads an alert rule.
+func (r *SQLRepository) GetAlertRule(ctx context.Context, id string) (*AlertRule, error) {
+	return queryOne(ctx, r, "get alert rule", "alert rule not found: "+id, alertRuleSelect+" WHERE id = $1",
+		[]interface{}{id}, scanAlertRule)
+}
+
+// ListAlertRules lists the alert rules by name.
+func (r *SQLRepository) ListAlertRules(ctx context.Context) ([]AlertRule, error) {
+	return queryAll(ctx, r, "list alert rules", alertRuleSelect+" ORDER BY name", nil, scanAlertRule)
+}
+
+// DeleteAlertRule deletes an alert rule.
+func (r *SQLRepository) DeleteAlertRule(ctx context.Context, id string) error {
+	res, err := r.conn().ExecContext(ctx, "DELETE FROM alert_rule WHERE id = $1", id)
+	if err := deleted(res, err, "delete alert rule"); errors.Is(err, dataerr.ErrNotFound) {
+		return dataerr.NotFound("alert rule not found: %s", id)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func scanAlertRule(row rowScanner) (*AlertRule, error) {
+	var rule AlertRule
+	var window, interval int64
+	var channels []byte
+	var evaluatedAt, firingSince sql.NullTime
+	if err := row.Scan(&rule.ID, &rule.Name, &rule.Description, &rule.Condition, &window, &interval, &rule.Severity,
+		&rule.AlertType, &channels, &rule.Enabled, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt, &evaluatedAt,
+		&rule.LastState, &rule.LastError, &firingSince); err != nil {
+		return nil, err
+	}
+	rule.Window, rule.Interval = time.Duration(window)*time.Second, time.Duration(interval)*time.Second
+	utc(&rule.CreatedAt, &rule.UpdatedAt)
+	rule.LastEvaluatedAt, rule.FiringSince = timeOf(evaluatedAt), timeOf(firingSince)
+	if err := scanJSON(channels, &rule.Channels); err != nil {
+		return nil, fmt.Errorf("decode alert rule %s: %w", rule.ID, err)
+	}
+	return &rule, nil
+}
+
+var notificationRouteSelect = "SELECT id, name, matchers, " + textArray("channels") +
+	", priority, COALESCE(created_by, ''), created_at, updated_at FROM notification_route"
+
+// SaveNotificationRoute upserts a notification route and returns it as saved.
+func (r *SQLRepository) SaveNotificationRoute(ctx context.Context, route NotificationRoute) (NotificationRoute, error) {
+	if route.ID == "" {
+		route.ID = "route-" + uuid.New().String()
+	}
+	now := time.Now().UTC()
+	if route.CreatedAt.IsZero() {
+		route.CreatedAt = now
+	}
+	if route.UpdatedAt.IsZero() {
+		route.UpdatedAt = now
+	}
+	route.Matchers = copyStringMap(route.Matchers)
+	matchers, err := jsonArg(route.Matchers)
+	if err != nil {
+		return NotificationRoute{}, fmt.Errorf("encode notification route %s: %w", route.ID, err)
+	}
+	err = r.exec(ctx, "save notification route",
+		upsertSQL("notification_route", []string{"id", "name", "matchers", "channels", "priority", "created_by", "created_at", "updated_at"}, 1),
+		route.ID, route.Name, matchers, textArrayArg(route.Channels), route.Priority, nullString(route.CreatedBy),
+		route.CreatedAt.UTC(), route.UpdatedAt.UTC())
+	if err != nil {
+		return NotificationRoute{}, err
+	}
+	route.Channels = append([]string(nil), route.Channels...)
+	return route, nil
+}
+
+// GetNotificationRoute reads a notification route.
+func (r *SQLRepository) GetNotificationRoute(ctx context.Context, id string) (*NotificationRoute, error) {
+	return queryOne(ctx, r, "get notification route", "notification route not found: "+id,
+		notificationRouteSelect+" WHERE id = $1", []interface{}{id}, scanNotificationRoute)
+}
+
+// ListNotificationRoutes lists the notification routes by priority and name.
+func (r *SQLRepository) ListNotificationRoutes(ctx context.Context) ([]NotificationRoute, error) {
+	return queryAll(ctx, r, "list notification routes", notificationRouteSelect+" ORDER BY priority, name", nil, scanNotificationRoute)
+}
+
+// DeleteNotificationRoute deletes a notification route.
+func (r *SQLRepository) DeleteNotificationRoute(ctx context.Context, id string) error {
+	res, err := r.conn().ExecContext(ctx, "DELETE FROM notification_route WHERE id = $1", id)
+	if err := deleted(res, err, "delete notification route"); errors.Is(err, dataerr.ErrNotFound) {
+		return dataerr.NotFound("notification route not found: %s", id)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func scanNotificationRoute(row rowScanner) (*NotificationRoute, error) {
+	var route NotificationRoute
+	var matchers, channels []byte
+	if err := row.Scan(&route.ID, &route.Name, &matchers, &channels, &route.Priority, &route.CreatedBy, &route.CreatedAt,
+		&route.UpdatedAt); err != nil {
+		return nil, err
+	}
+	utc(&route.CreatedAt, &route.UpdatedAt)
+	if err := scanJSON(matchers, &route.Matchers); err != nil {
+		return nil, fmt.Errorf("decode notification route %s: %w", route.ID, err)
+	}
+	if err := scanJSON(channels, &route.Channels); err != nil {
+		return nil, fmt.Errorf("decode notification route %s: %w", route.ID, err)
+	}
+	return &route, nil
+}
+
+const notificationSilenceSelect = "SELECT id, matchers, starts_at, ends_at, reason, COALESCE(created_by, ''), created_at, " +
+	"COALESCE(expired_by, '') FROM notification_silence"
+
+// SaveNotificationSilence upserts a notification silence and returns it as saved.
+func (r *SQLRepository) SaveNotificationSilence(ctx context.Context, silence NotificationSilence) (NotificationSilence, error) {
+	if silence.ID == "" {
+		silence.ID = "silence-" + uuid.New().String()
+	}
+	if silence.CreatedAt.IsZero() {
+		silence.CreatedAt = time.Now().UTC()
+	}
+	silence.Matchers = copyStringMap(silence.Matchers)
+	matchers, err := jsonArg(silence.Matchers)
+	if err != nil {
+		return NotificationSilence{}, fmt.Errorf("encode notification silence %s: %w", silence.ID, err)
+	}
+	err = r.exec(ctx, "save notification silence",
+		upsertSQL("notification_silence", []string{"id", "matchers", "starts_at", "ends_at", "reason", "created_by", "created_at", "expired_by"}, 1),
+		silence.ID, matchers, silence.StartsAt.UTC(), silence.EndsAt.UTC(), silence.Reason, nullString(silence.CreatedBy),
+		silence.CreatedAt.UTC(), nullString(silence.ExpiredBy))
+	if err != nil {
+		return NotificationSilence{}, err
+	}
+	return silence, nil
+}
+
+// GetNotificationSilence reads a notification silence.
+func (r *SQLRepository) GetNotificationSilence(ctx context.Context, id string) (*NotificationSilence, error) {
+	return queryOne(ctx, r, "get notification silence", "notification silence not found: "+id,
+		notificationSilenceSelect+" WHERE id = $1", []interface{}{id}, scanNotificationSilence)
+}
+
+// ListNotificationSilences lists the silences active at activeAt (all when zero) by end time.
+func (r *SQLRepository) ListNotificationSilences(ctx context.Context, activeAt time.Time) ([]NotificationSilence, error) {
+	var f sqlFilter
+	if !activeAt.IsZero() {
+		f.add("starts_at <= ?", activeAt.UTC())
+		f.add("ends_at > ?", activeAt.UTC())
+	}
+	return queryAll(ctx, r, "list notification silences", notificationSilenceSelect+f.where()+" ORDER BY ends_at, id", f.args,
+		scanNotificationSilence)
+}
+
+func scanNotificationSilence(row rowScanner) (*NotificationSilence, error) {
+	var s NotificationSilence
+	var matchers []byte
+	if err := row.Scan(&s.ID, &matchers, &s.StartsAt, &s.EndsAt, &s.Reason, &s.CreatedBy, &s.CreatedAt, &s.ExpiredBy); err != nil {
+		return nil, err
+	}
+	utc(&s.StartsAt, &s.EndsAt, &s.CreatedAt)
+	if err := scanJSON(matchers, &s.Matchers); err != nil {
+		return nil, fmt.Errorf("decode notification silence %s: %w", s.ID, err)
+	}
+	return &s, nil
+}
+
+// --- QueryLineage ---
+
+// SaveQueryLineage upserts the lineage of a cost query response.
+func (r *SQLRepository) SaveQueryLineage(ctx context.Context, lineage QueryLineage) error {
+	if lineage.ID == "" {
+		return dataerr.Validation("query lineage id is required")
+	}
+	args := []interface{}{lineage.ID, nullString(lineage.RequestID), lineage.Method, lineage.Path, lineage.Status, lineage.ServedAt.UTC()}
+	for _, v := range []interface{}{lineage.Snapshot, lineage.CalculationRun, lineage.PriceVersion, lineage.Code} {
+		// nil pointers are NULL
+		var arg interface{}
+		if b, err := jsonArg(v); err != nil {
+			return fmt.Errorf("encode query lineage %s: %w", lineage.ID, err)
+		} else if b != "null" {
+			arg = b
+		}
+		args = append(args, arg)
+	}
+	return r.exec(ctx, "save query lineage", upsertSQL("cost_query_lineage", []string{"id", "request_id", "method", "path", "status",
+		"served_at", "snapshot", "calculation_run", "price_version", "code"}, 1), args...)
+}
+
+// GetQueryLineage reads the lineage of a cost query response.
+func (r *SQLRepository) GetQueryLineage(ctx context.Context, id string) (*QueryLineage, error) {
+	return queryOne(ctx, r, "get query lineage", "query lineage not found: "+id,
+		"SELECT id, COALESCE(request_id, ''), method, path, status, served_at, snapshot, calculation_run, price_version, code "+
+			"FROM cost_query_lineage WHERE id = $1", []interface{}{id}, func(row rowScanner) (*QueryLineage, error) {
+			var l QueryLineage
+			var snapshot, run, price, code []byte
+			if err := row.Scan(&l.ID, &l.RequestID, &l.Method, &l.Path, &l.Status, &l.ServedAt, &snapshot, &run, &price, &code); err != nil {
+				return nil, err
+			}
+			utc(&l.ServedAt)
+			for _, s := range []struct {
+				b []byte
+				v interface{}
+			}{{snapshot, &l.Snapshot}, {run, &l.CalculationRun}, {price, &l.PriceVersion}, {code, &l.Code}} {
+				if err := scanJSON(s.b, s.v); err != nil {
+					return nil, fmt.Errorf("decode query lineage %s: %w", l.ID, err)
+				}
+			}
+			return &l, nil
+		})
+}
+
+// --- ConfigChange / SLOViolation / DependencyEdge ---
+
+// SaveConfigChange upserts a config change; an existing change keeps its CreatedAt.
+func (r *SQLRepository) SaveConfigChange(ctx context.Context, change ConfigChange) error {
+	if change.ID == "" {
+		change.ID = "change-" + uuid.New().String()
+	}
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+	return r.exec(ctx, "save config change",
+		upsertKeepingSQL("config_change", []string{"id", "namespace", "kind", "name", "change_type", "old_value", "new_value", "source",
+			"author", "occurred_at", "created_at"}, 1, "created_at"),
+		change.ID, change.Namespace, change.Kind, change.Name, change.ChangeType, nullString(change.OldValue), nullString(change.NewValue),
+		nullString(change.Source), nullString(change.Author), change.OccurredAt.UTC(), change.CreatedAt.UTC())
+}
+
+// ListConfigChanges lists config changes by occurrence; a namespace filter also matches
+// cluster-level changes.
+func (r *SQLRepository) ListConfigChanges(ctx context.Context, filter ConfigChangeFilter) ([]ConfigChange, error) {
+	var f sqlFilter
+	if filter.Namespace != "" {
+		f.add("(namespace = ? OR namespace = '')", filter.Namespace)
+	}
+	if !filter.StartTime.IsZero() {
+		f.add("occurred_at >= ?", filter.StartTime.UTC())
+	}
+	if !filter.EndTime.IsZero() {
+		f.add("occurred_at <= ?", filter.EndTime.UTC())
+	}
+	return queryAll(ctx, r, "list config changes",
+		"SELECT id, namespace, kind, name, change_type, COALESCE(old_value, ''), COALESCE(new_value, ''), COALESCE(source, ''), "+
+			"COALESCE(author, ''), occurred_at, created_at FROM config_change"+f.where()+" ORDER BY occurred_at, id", f.args,
+		func(row rowScanner) (*ConfigChange, error) {
+			var c ConfigChange
+			if err := row.Scan(&c.ID, &c.Namespace, &c.Kind, &c.Name, &c.ChangeType, &c.OldValue, &c.NewValue, &c.Source,
+				&c.Author, &c.OccurredAt, &c.CreatedAt); err != nil {
+				return nil, err
+			}
+			utc(&c.OccurredAt, &c.CreatedAt)
+			return &c, nil
+		})
+}
+
+// SaveSLOViolation upserts an SLO violation (e.g. its recovery); an existing violation keeps its CreatedAt.
+func (r *SQLRepository) SaveSLOViolation(ctx context.Context, violation SLOViolation) error {
+	if violation.ID == "" {
+		violation.ID = "slo-violation-" + uuid.New().String()
+	}
+	now := time.Now()
+	if violation.CreatedAt.IsZero() {
+		violation.CreatedAt = now
+	}
+	violation.UpdatedAt = now
+	return r.exec(ctx, "save SLO violation",
+		upsertKeepingSQL("slo_violation", []string{"id", "namespace", "service", "violation_type", "actual_value", "threshold_value",
+			"severity", "violated_at", "recovered_at", "created_at", "updated_at"}, 1, "created_at"),
+		violation.ID, violation.Namespace, violation.Service, violation.ViolationType, violation.ActualValue, violation.ThresholdValue,
+		nullString(violation.Severity), violation.ViolatedAt.UTC(), nullTime(violation.RecoveredAt), violation.CreatedAt.UTC(),
+		violation.UpdatedAt.UTC())
+}
+
+// ListSLOViolations lists the violations ongoing at any time within the window by start time.
+func (r *SQLRepository) ListSLOViolations(ctx context.Context, filter SLOViolationFilter) ([]SLOViolation, error) {
+	var f sqlFilter
+	if filter.Namespace != "" {
+		f.add("namespace = ?", filter.Namespace)
+	}
+	if filter.Service != "" {
+		f.add("service = ?", filter.Service)
+	}
+	if !filter.EndTime.IsZero() {
+		f.add("violated_at <= ?", filter.EndTime.UTC())
+	}
+	if !filter.StartTime.IsZero() {
+		f.add("(recovered_at IS NULL OR recovered_at >= ?)", filter.StartTime.UTC())
+	}
+	return queryAll(ctx, r, "list SLO violations",
+		"SELECT id, namespace, service, violation_type, COALESCE(actual_value, 0), COALESCE(threshold_value, 0), COALESCE(severity, ''), "+
+			"violated_at, recovered_at, created_at, updated_at FROM slo_violation"+f.where()+" ORDER BY violated_at, id", f.args,
+		func(row rowScanner) (*SLOViolation, error) {
+			var v SLOViolation
+			var recoveredAt sql.NullTime
+			if err := row.Scan(&v.ID, &v.Namespace, &v.Service, &v.ViolationType, &v.ActualValue, &v.ThresholdValue, &v.Severity,
+				&v.ViolatedAt, &recoveredAt, &v.CreatedAt, &v.UpdatedAt); err != nil {
+				return nil, err
+			}
+			utc(&v.ViolatedAt, &v.CreatedAt, &v.UpdatedAt)
+			v.RecoveredAt = timeOf(recoveredAt)
+			return &v, nil
+		})
+}
+
+var dependencyColumns = []string{"caller_namespace", "caller", "callee_namespace", "callee", "source", "calls_per_minute",
+	"last_seen", "created_at"}
+
+// SaveDependencyEdges upserts the edges in one transaction; an existing edge keeps its CreatedAt.
+func (r *SQLRepository) SaveDependencyEdges(ctx context.Context, edges []DependencyEdge) error {
+	query := upsertKeepingSQL("service_dependency", dependencyColumns, 5, "created_at")
+	now := time.Now().UTC()
+	return r.inTx(ctx, "save dependency edges", func(tx *SQLRepository) error {
+		for _, e := range edges {
+			if e.CreatedAt.IsZero() {
+				e.CreatedAt = now
+			}
+			if err := tx.exec(ctx, "save dependency edges", query, e.CallerNamespace, e.Caller, e.CalleeNamespace, e.Callee, e.Source,
+				e.CallsPerMinute, e.LastSeen.UTC(), e.CreatedAt.UTC()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListDependencyEdges lists the matching edges by caller, callee and source.
+func (r *SQLRepository) ListDependencyEdges(ctx context.Context, filter DependencyEdgeFilter) ([]DependencyEdge, error) {
+	f := dependencyFilter(filter)
+	return queryAll(ctx, r, "list dependency edges",
+		"SELECT "+strings.Join(dependencyColumns, ", ")+" FROM service_dependency"+f.where()+
+			" ORDER BY caller_namespace, caller, callee_namespace, callee, source", f.args,
+		func(row rowScanner) (*DependencyEdge, error) {
+			var e DependencyEdge
+			if err := row.Scan(&e.CallerNamespace, &e.Caller, &e.CalleeNamespace, &e.Callee, &e.Source, &e.CallsPerMinute,
+				&e.LastSeen, &e.CreatedAt); err != nil {
+				return nil, err
+			}
+			utc(&e.LastSeen, &e.CreatedAt)
+			return &e, nil
+		})
+}
+
+// DeleteDependencyEdges deletes the matching edges and returns how many were deleted.
+func (r *SQLRepository) DeleteDependencyEdges(ctx context.Context, filter DependencyEdgeFilter) (int, error) {
+	f := dependencyFilter(filter)
+	res, err := r.conn().ExecContext(ctx, "DELETE FROM service_dependency"+f.where(), f.args...)
+	if err != nil {
+		return 0, sqlError("delete dependency edges", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, sqlError("delete dependency edges", err)
+	}
+	return int(n), nil
+}
+
+func dependencyFilter(filter DependencyEdgeFilter) sqlFilter {
+	var f sqlFilter
+	if filter.Source != "" {
+		f.add("source = ?", filter.Source)
+	}
+	if !filter.SeenBefore.IsZero() {
+		f.add("last_seen < ?", filter.SeenBefore.UTC())
+	}
+	return f
+}
+
+// --- AccountingPeriod / Adjustment ---
+
+const accountingPeriodSelect = "SELECT label, start_date, end_date, start_time, end_time, COALESCE(closed_by, ''), closed_at, " +
+	"COALESCE(note, '') FROM accounting_period"
+
+// CloseAccountingPeriod records a closed period; the daily namespace costs and hourly workload
+// stats within it can no longer be written. A period overlapping a closed one conflicts.
+func (r *SQLRepository) CloseAccountingPeriod(ctx context.Context, period AccountingPeriod) error {
+	if period.ClosedAt.IsZero() {
+		period.ClosedAt = time.Now()
+	}
+	return r.inTx(ctx, "close accounting period", func(tx *SQLRepository) error {
+		// serializes closes, so two overlapping periods cannot both pass the check
+		if err := tx.exec(ctx, "close accounting period", "LOCK TABLE accounting_period IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+		overlapping, err := queryOne(ctx, tx, "close accounting period", "", accountingPeriodSelect+
+			" WHERE start_date < $1 AND $2 < end_date LIMIT 1", []interface{}{dayArg(period.EndDate), dayArg(period.StartDate)},
+			scanAccountingPeriod)
+		switch {
+		case err == nil:
+			return periodClosedError(overlapping)
+		case !errors.Is(err, dataerr.ErrNotFound):
+			return err
+		}
+		return tx.exec(ctx, "close accounting period",
+			"INSERT INTO accounting_period (label, start_date, end_date, start_time, end_time, closed_by, closed_at, note) "+
+				"VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+			period.Label, dayArg(period.StartDate), dayArg(period.EndDate), period.StartTime.UTC(), period.EndTime.UTC(),
+			nullString(period.ClosedBy), period.ClosedAt.UTC(), nullString(period.Note))
+	})
+}
+
+// ListAccountingPeriods lists the closed periods by start date.
+func (r *SQLRepository) ListAccountingPeriods(ctx context.Context) ([]AccountingPeriod, error) {
+	return queryAll(ctx, r, "list accounting periods", accountingPeriodSelect+" ORDER BY start_date", nil, scanAccountingPeriod)
+}
+
+func scanAccountingPeriod(row rowScanner) (*AccountingPeriod, error) {
+	var p AccountingPeriod
+	if err := row.Scan(&p.Label, &p.StartDate, &p.EndDate, &p.StartTime, &p.EndTime, &p.ClosedBy, &p.ClosedAt, &p.Note); err != nil {
+		return nil, err
+	}
+	utc(&p.StartDate, &p.EndDate, &p.StartTime, &p.EndTime, &p.ClosedAt)
+	return &p, nil
+}
+
+// closedPeriodOn returns the closed period containing the day labelled date, nil when it is open.
+func (r *SQLRepository) closedPeriodOn(ctx context.Context, date time.Time) (*AccountingPeriod, error) {
+	return r.closedPeriod(ctx, "start_date <= $1 AND $1 < end_date", dayArg(date))
+}
+
+// closedPeriodAt returns the closed period containing the instant t, nil when it is open.
+func (r *SQLRepository) closedPeriodAt(ctx context.Context, t time.Time) (*AccountingPeriod, error) {
+	return r.closedPeriod(ctx, "start_time <= $1 AND $1 < end_time", t.UTC())
+}
+
+func (r *SQLRepository) closedPeriod(ctx context.Context, cond string, arg interface{}) (*AccountingPeriod, error) {
+	p, err := queryOne(ctx, r, "read accounting periods", "", accountingPeriodSelect+" WHERE "+cond+" LIMIT 1",
+		[]interface{}{arg}, scanAccountingPeriod)
+	if errors.Is(err, dataerr.ErrNotFound) {
+		return nil, nil
+	}
+	return p, err
+}
+
+// adjustClosedPeriod handles a write to the closed period p: ErrPeriodClosed unless it is a
+// recalculation, whose non-zero difference is recorded as an adjustment instead.
+func (r *SQLRepository) adjustClosedPeriod(ctx context.Context, p *AccountingPeriod, adj Adjustment) error {
+	reason, ok := RecalculationReason(ctx)
+	if !ok {
+		return periodClosedError(p)
+	}
+	if math.Abs(adj.Amount) < 1e-9 {
+		return nil
+	}
+	adj.Period, adj.Source, adj.Reason, adj.Status = p.Label, AdjustmentSourceRecalculation, reason, AdjustmentApproved
+	_, err := r.insertAdjustment(ctx, adj)
+	return err
+}
+
+// SaveAdjustment appends a manual adjustment; Date must fall within a closed period, whose label
+// becomes its Period. It returns the adjustment with its ID.
+func (r *SQLRepository) SaveAdjustment(ctx context.Context, adj Adjustment) (Adjustment, error) {
+	p, err := r.closedPeriodOn(ctx, adj.Date)
+	if err != nil {
+		return Adjustment{}, err
+	}
+	if p == nil {
+		return Adjustment{}, dataerr.Validation("no closed accounting period on %s", dayArg(adj.Date))
+	}
+	adj.Period = p.Label
+	return r.insertAdjustment(ctx, adj)
+}
+
+func (r *SQLRepository) insertAdjustment(ctx context.Context, adj Adjustment) (Adjustment, error) {
+	adj.ID = "adjustment-" + uuid.New().String()
+	adj.CreatedAt = time.Now().UTC()
+	if adj.Status == "" {
+		adj.Status = AdjustmentApproved
+	}
+	err := r.exec(ctx, "save adjustment",
+		"INSERT INTO cost_adjustment (id, period, record, date, namespace, workload, amount, source, reason, created_by, approver, created_at, "+
+			"status, approved_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)",
+		adj.ID, adj.Period, adj.Record, adj.Date.UTC(), adj.Namespace, nullString(adj.Workload), adj.Amount, adj.Source,
+		nullString(adj.Reason), nullString(adj.CreatedBy), nullString(adj.Approver), adj.CreatedAt, adj.Status, nullTime(adj.ApprovedAt))
+	if err != nil {
+		return Adjustment{}, err
+	}
+	return adj, nil
+}
+
+const adjustmentSelect = "SELECT id, period, record, date, namespace, COALESCE(workload, ''), amount, source, COALESCE(reason, ''), " +
+	"COALESCE(created_by, ''), COALESCE(approver, ''), created_at, status, approved_at FROM cost_adjustment"
+
+func scanAdjustment(row rowScanner) (*Adjustment, error) {
+	var a Adjustment
+	var approvedAt sql.NullTime
+	if err := row.Scan(&a.ID, &a.Period, &a.Record, &a.Date, &a.Namespace, &a.Workload, &a.Amount, &a.Source, &a.Reason,
+		&a.CreatedBy, &a.Approver, &a.CreatedAt, &a.Status, &approvedAt); err != nil {
+		return nil, err
+	}
+	utc(&a.Date, &a.CreatedAt)
+	a.ApprovedAt = timeOf(approvedAt)
+	return &a, nil
+}
+
+// GetAdjustment returns an adjustment by ID.
+func (r *SQLRepository) GetAdjustment(ctx context.Context, id string) (*Adjustment, error) {
+	return queryOne(ctx, r, "get adjustment", "adjustment not found: "+id, adjustmentSelect+" WHERE id = $1", []interface{}{id}, scanAdjustment)
+}
+
+// ApproveAdjustment approves a pending adjustment by approver; an approved one is dataerr.ErrConflict.
+func (r *SQLRepository) ApproveAdjustment(ctx context.Context, id, approver string) (Adjustment, error) {
+	var out *Adjustment
+	err := r.inTx(ctx, "approve adjustment", func(tx *SQLRepository) error {
+		a, err := queryOne(ctx, tx, "approve adjustment", "adjustment not found: "+id,
+			adjustmentSelect+" WHERE id = $1 FOR UPDATE", []interface{}{id}, scanAdjustment)
+		if err != nil {
+			return err
+		}
+		if !a.Pending() {
+			return dataerr.Conflict("adjustment %s is already approved", id)
+		}
+		now := time.Now().UTC()
+		a.Status, a.Approver, a.ApprovedAt = AdjustmentApproved, approver, &now
+		out = a
+		return tx.exec(ctx, "approve adjustment", "UPDATE cost_adjustment SET status = $2, approver = $3, approved_at = $4 WHERE id = $1",
+			id, a.Status, a.Approver, now)
+	})
+	if err != nil {
+		return Adjustment{}, err
+	}
+	return *out, nil
+}
+
+// ListAdjustments lists the matching adjustments in the order they were written.
+func (r *SQLRepository) ListAdjustments(ctx context.Context, filter AdjustmentFilter) ([]Adjustment, error) {
+	var f sqlFilter
+	for _, eq := range []struct{ column, value string }{
+		{"period", filter.Period}, {"namespace", filter.Namespace}, {"record", filter.Record},
+	} {
+		if eq.value != "" {
+			f.add(eq.column+" = ?", eq.value)
+		}
+	}
+	if !filter.StartDate.IsZero() {
+		f.add("date >= ?", filter.StartDate.UTC())
+	}
+	if !filter.EndDate.IsZero() {
+		f.add("date <= ?", filter.EndDate.UTC())
+	}
+	return queryAll(ctx, r, "list adjustments", adjustmentSelect+f.where()+" ORDER BY created_at, id", f.args, scanAdjustment)
+}
+
+// --- DailyWorkloadStat ---
+
+var dailyWorkloadColumns = []string{"day", "namespace", "workload_name", "workload_kind", "node_pool", "cost_center", "hours",
+	"request_cores", "p95_cpu_usage", "request_mem_bytes", "p95_mem_usage_bytes", "cpu_billable_cost", "cpu_usage_cost",
+	"cpu_waste_cost", "mem_billable_cost", "mem_usage_cost", "mem_waste_cost", "billable_cost", "usage_cost", "waste_cost",
+	"cpu_usage_sketch", "mem_usage_sketch"}
+
+const dailyWorkloadSelect = "SELECT day, namespace, workload_name, COALESCE(workload_kind, ''), COALESCE(node_pool, ''), " +
+	"COALESCE(cost_center, ''), hours, COALESCE(request_cores, 0), COALESCE(p95_cpu_usage, 0), COALESCE(request_mem_bytes, 0), " +
+	"COALESCE(p95_mem_usage_bytes, 0), COALESCE(cpu_billable_cost, 0), COALESCE(cpu_usage_cost, 0), COALESCE(cpu_waste_cost, 0), " +
+	"COALESCE(mem_billable_cost, 0), COALESCE(mem_usage_cost, 0), COALESCE(mem_waste_cost, 0), COALESCE(billable_cost, 0), " +
+	"COALESCE(usage_cost, 0), COALESCE(waste_cost, 0), cpu_usage_sketch, mem_usage_sketch FROM cost_daily_workload"
+
+// DownsampleHourlyWorkloadStats folds the hourly stats before before into daily rollups per
+// workload and UTC day (merged with existing rollups) and deletes those hours, in one transaction.
+func (r *SQLRepository) DownsampleHourlyWorkloadStats(ctx context.Context, before time.Time) (DownsampleResult, error) {
+	var result DownsampleResult
+	err := r.inTx(ctx, "downsample hourly workload stats", func(tx *SQLRepository) error {
+		hours, err := queryAll(ctx, tx, "downsample hourly workload stats",
+			"SELECT "+hourlySelect+" FROM cost_hourly_workload WHERE time_bucket < $1 ORDER BY time_bucket, namespace, workload_name FOR UPDATE",
+			[]interface{}{before.UTC()}, scanHourly)
+		if err != nil {
+			return err
+		}
+		// 按时间顺序合并，与 MockRepository 的结果一致
+		rollups := make(map[string]*DailyWorkloadStat)
+		var order []string
+		for _, h := range hours {
+			hour := DailyWorkloadStatOf(h)
+			key := hour.Namespace + "/" + hour.WorkloadName + "/" + dayArg(hour.Date)
+			if day, ok := rollups[key]; ok {
+				day.Merge(hour)
+				continue
+			}
+			rollups[key] = &hour
+			order = append(order, key)
+		}
+		upsert := upsertSQL("cost_daily_workload", dailyWorkloadColumns, 3)
+		for _, key := range order {
+			day := *rollups[key]
+			existing, err := queryOne(ctx, tx, "downsample hourly workload stats", "",
+				dailyWorkloadSelect+" WHERE day = $1 AND namespace = $2 AND workload_name = $3 FOR UPDATE",
+				[]interface{}{dayArg(day.Date), day.Namespace, day.WorkloadName}, scanDailyWorkload)
+			switch {
+			case err == nil:
+				existing.Merge(day)
+				day = *existing
+			case !errors.Is(err, dataerr.ErrNotFound):
+				return err
+			}
+			cpuSketch, memSketch, err := sketchArgs(day.CPUUsageSketch, day.MemUsageSketch)
+			if err != nil {
+				return fmt.Errorf("encode daily workload stat %s: %w", key, err)
+			}
+			if err := tx.exec(ctx, "downsample hourly workload stats", upsert,
+				dayArg(day.Date), day.Namespace, day.WorkloadName, day.WorkloadType, day.NodePool, day.CostCenter, day.Hours,
+				day.CPURequest, day.CPUUsageP95, day.MemRequest, day.MemUsageP95, day.CPUBillableCost, day.CPUUsageCost,
+				day.CPUWasteCost, day.MemBillableCost, day.MemUsageCost, day.MemWasteCost, day.TotalBillableCost,
+				day.TotalUsageCost, day.TotalWasteCost, cpuSketch, memSketch); err != nil {
+				return err
+			}
+		}
+		res, err := tx.conn().ExecContext(ctx, "DELETE FROM cost_hourly_workload WHERE time_bucket < $1", before.UTC())
+		if err != nil {
+			return sqlError("downsample hourly workload stats", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return sqlError("downsample hourly workload stats", err)
+		}
+		result = DownsampleResult{Rollups: len(order), Deleted: int(n)}
+		return nil
+	})
+	if err != nil {
+		return DownsampleResult{}, err
+	}
+	return result, nil
+}
+
+// ListDailyWorkloadStats lists daily workload rollups by day descending.
+func (r *SQLRepository) ListDailyWorkloadStats(ctx context.Context, filter DailyWorkloadStatFilter) ([]DailyWorkloadStat, error) {
+	f := dailyFilter(filter.StartTime, filter.EndTime)
+	if filter.Namespace != "" {
+		f.add("namespace = ?", filter.Namespace)
+	}
+	if filter.WorkloadName != "" {
+		f.add("workload_name = ?", filter.WorkloadName)
+	}
+	return queryAll(ctx, r, "list daily workload stats",
+		dailyWorkloadSelect+f.where()+" ORDER BY day DESC, namespace, workload_name", f.args, scanDailyWorkload)
+}
+
+func scanDailyWorkload(row rowScanner) (*DailyWorkloadStat, error) {
+	var d DailyWorkloadStat
+	var cpuSketch, memSketch []byte
+	if err := row.Scan(&d.Date, &d.Namespace, &d.WorkloadName, &d.WorkloadType, &d.NodePool, &d.CostCenter, &d.Hours,
+		&d.CPURequest, &d.CPUUsageP95, &d.MemRequest, &d.MemUsageP95, &d.CPUBillableCost, &d.CPUUsageCost, &d.CPUWasteCost,
+		&d.MemBillableCost, &d.MemUsageCost, &d.MemWasteCost, &d.TotalBillableCost, &d.TotalUsageCost, &d.TotalWasteCost,
+		&cpuSketch, &memSketch); err != nil {
+		return nil, err
+	}
+	utc(&d.Date)
+	if err := scanJSON(cpuSketch, &d.CPUUsageSketch); err != nil {
+		return nil, fmt.Errorf("decode cpu usage sketch of %s/%s: %w", d.Namespace, d.WorkloadName, err)
+	}
+	if err := scanJSON(memSketch, &d.MemUsageSketch); err != nil {
+		return nil, fmt.Errorf("decode memory usage sketch of %s/%s: %w", d.Namespace, d.WorkloadName, err)
+	}
+	return &d, nil
+}
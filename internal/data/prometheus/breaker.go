@@ -0,0 +1,79 @@
+// Package prometheus breaker.go: circuit-breaker wrapper so a flapping Prometheus fails fast
+// instead of piling up requests.
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// BreakerClient wraps a Client with the breaker of target in set. HealthCheck bypasses the
+// breaker so readiness probes still reach Prometheus while the circuit is open.
+type BreakerClient struct {
+	client   Client
+	target   string
+	breakers *breaker.Set
+}
+
+// NewBreakerClient wraps client; target identifies the Prometheus instance (e.g. its address).
+func NewBreakerClient(client Client, target string, breakers *breaker.Set) *BreakerClient {
+	return &BreakerClient{client: client, target: target, breakers: breakers}
+}
+
+// GetResourceMetrics implements Client.
+func (c *BreakerClient) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	var out []costmodel.ResourceMetric
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetResourceMetrics(ctx, namespace, workload, pod, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// GetNodeMetrics implements Client.
+func (c *BreakerClient) GetNodeMetrics(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	var out []costmodel.ResourceMetric
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetNodeMetrics(ctx, nodeName, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// GetClusterMetrics implements Client.
+func (c *BreakerClient) GetClusterMetrics(ctx context.Context, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	var out []costmodel.ResourceMetric
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetClusterMetrics(ctx, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// GetThrottlingMetrics implements Client.
+func (c *BreakerClient) GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]ThrottlingMetric, error) {
+	var out []ThrottlingMetric
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetThrottlingMetrics(ctx, namespace, pod, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// GetSaturationMetrics implements Client.
+func (c *BreakerClient) GetSaturationMetrics(ctx context.Context, resourceType string, startTime, endTime time.Time) ([]SaturationMetric, error) {
+	var out []SaturationMetric
+	err := c.breakers.Do(c.target, func() (err error) {
+		out, err = c.client.GetSaturationMetrics(ctx, resourceType, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// HealthCheck implements Client without going through the breaker.
+func (c *BreakerClient) HealthCheck(ctx context.Context) error {
+	return c.client.HealthCheck(ctx)
+}
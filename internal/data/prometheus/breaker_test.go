@@ -0,0 +1,32 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+)
+
+func TestBreakerClient_ShortCircuits(t *testing.T) {
+	config := DefaultMockConfig()
+	config.ErrorRate = 1
+	config.LatencyMs = 0
+	set := breaker.NewSet("prometheus", breaker.Config{MinRequests: 2, OpenTimeout: time.Hour})
+	c := NewBreakerClient(NewMockClient(config), "prom-a", set)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetClusterMetrics(ctx, now.Add(-time.Hour), now); err == nil || errors.Is(err, breaker.ErrOpen) {
+			t.Fatalf("call %d: err = %v, want source error", i, err)
+		}
+	}
+	if _, err := c.GetResourceMetrics(ctx, "default", "", "", now.Add(-time.Hour), now); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("after failures: err = %v, want ErrOpen", err)
+	}
+	if st := set.Statuses(); len(st) != 1 || st[0].Target != "prom-a" || st[0].State != breaker.StateOpen {
+		t.Errorf("Statuses = %+v", st)
+	}
+}
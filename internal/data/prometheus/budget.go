@@ -0,0 +1,57 @@
+// Package prometheus budget.go: accounts every query to the API consumer of the request context, so
+// per-consumer query budgets (see querybudget) can be enforced.
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/querybudget"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// BudgetClient wraps a Client and reports every query to querybudget.Track. HealthCheck is not
+// accounted. Wrap it around the retry and breaker wrappers so a logical query counts once.
+type BudgetClient struct {
+	client Client
+}
+
+// NewBudgetClient wraps client.
+func NewBudgetClient(client Client) *BudgetClient {
+	return &BudgetClient{client: client}
+}
+
+// GetResourceMetrics implements Client.
+func (c *BudgetClient) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	defer querybudget.Track(ctx, querybudget.Prometheus, time.Now())
+	return c.client.GetResourceMetrics(ctx, namespace, workload, pod, startTime, endTime)
+}
+
+// GetNodeMetrics implements Client.
+func (c *BudgetClient) GetNodeMetrics(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	defer querybudget.Track(ctx, querybudget.Prometheus, time.Now())
+	return c.client.GetNodeMetrics(ctx, nodeName, startTime, endTime)
+}
+
+// GetClusterMetrics implements Client.
+func (c *BudgetClient) GetClusterMetrics(ctx context.Context, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	defer querybudget.Track(ctx, querybudget.Prometheus, time.Now())
+	return c.client.GetClusterMetrics(ctx, startTime, endTime)
+}
+
+// GetThrottlingMetrics implements Client.
+func (c *BudgetClient) GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]ThrottlingMetric, error) {
+	defer querybudget.Track(ctx, querybudget.Prometheus, time.Now())
+	return c.client.GetThrottlingMetrics(ctx, namespace, pod, startTime, endTime)
+}
+
+// GetSaturationMetrics implements Client.
+func (c *BudgetClient) GetSaturationMetrics(ctx context.Context, resourceType string, startTime, endTime time.Time) ([]SaturationMetric, error) {
+	defer querybudget.Track(ctx, querybudget.Prometheus, time.Now())
+	return c.client.GetSaturationMetrics(ctx, resourceType, startTime, endTime)
+}
+
+// HealthCheck implements Client without accounting.
+func (c *BudgetClient) HealthCheck(ctx context.Context) error {
+	return c.client.HealthCheck(ctx)
+}
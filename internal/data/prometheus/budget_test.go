@@ -0,0 +1,40 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/querybudget"
+)
+
+func TestBudgetClient(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	client := NewBudgetClient(NewMockClient(config))
+	ledger := querybudget.NewLedger(time.Hour, querybudget.Quota{PrometheusQueries: 2}, nil)
+	end := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx := querybudget.WithConsumer(context.Background(), ledger, "team-a")
+	if _, err := client.GetClusterMetrics(ctx, end.Add(-time.Hour), end); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetThrottlingMetrics(ctx, "default", "", end.Add(-time.Hour), end); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.HealthCheck(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 后台任务没有调用方，不计入
+	if _, err := client.GetClusterMetrics(context.Background(), end.Add(-time.Hour), end); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, usage := ledger.Usage()
+	if len(usage) != 1 || usage[0].PrometheusQueries != 2 || usage[0].DatabaseQueries != 0 {
+		t.Fatalf("usage = %+v, want 2 prometheus queries of team-a", usage)
+	}
+	if k, _ := ledger.Check("team-a"); k != querybudget.Prometheus {
+		t.Errorf("Check = %q, want prometheus", k)
+	}
+}
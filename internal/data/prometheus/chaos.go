@@ -0,0 +1,72 @@
+// Package prometheus chaos.go: resilience drill wrapper that injects the failures and latency of the
+// active chaos scenario into Prometheus queries.
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// ChaosClient wraps a Client with the prometheus fault of the active drill of controller. It wraps
+// the base client inside the retry and breaker wrappers, so a drill exercises them like an outage.
+// HealthCheck is injected too, so readiness reflects the drill.
+type ChaosClient struct {
+	client     Client
+	controller *chaos.Controller
+}
+
+// NewChaosClient wraps client.
+func NewChaosClient(client Client, controller *chaos.Controller) *ChaosClient {
+	return &ChaosClient{client: client, controller: controller}
+}
+
+// GetResourceMetrics implements Client.
+func (c *ChaosClient) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	if err := c.controller.Inject(ctx, chaos.Prometheus); err != nil {
+		return nil, err
+	}
+	return c.client.GetResourceMetrics(ctx, namespace, workload, pod, startTime, endTime)
+}
+
+// GetNodeMetrics implements Client.
+func (c *ChaosClient) GetNodeMetrics(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	if err := c.controller.Inject(ctx, chaos.Prometheus); err != nil {
+		return nil, err
+	}
+	return c.client.GetNodeMetrics(ctx, nodeName, startTime, endTime)
+}
+
+// GetClusterMetrics implements Client.
+func (c *ChaosClient) GetClusterMetrics(ctx context.Context, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	if err := c.controller.Inject(ctx, chaos.Prometheus); err != nil {
+		return nil, err
+	}
+	return c.client.GetClusterMetrics(ctx, startTime, endTime)
+}
+
+// GetThrottlingMetrics implements Client.
+func (c *ChaosClient) GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]ThrottlingMetric, error) {
+	if err := c.controller.Inject(ctx, chaos.Prometheus); err != nil {
+		return nil, err
+	}
+	return c.client.GetThrottlingMetrics(ctx, namespace, pod, startTime, endTime)
+}
+
+// GetSaturationMetrics implements Client.
+func (c *ChaosClient) GetSaturationMetrics(ctx context.Context, resourceType string, startTime, endTime time.Time) ([]SaturationMetric, error) {
+	if err := c.controller.Inject(ctx, chaos.Prometheus); err != nil {
+		return nil, err
+	}
+	return c.client.GetSaturationMetrics(ctx, resourceType, startTime, endTime)
+}
+
+// HealthCheck implements Client.
+func (c *ChaosClient) HealthCheck(ctx context.Context) error {
+	if err := c.controller.Inject(ctx, chaos.Prometheus); err != nil {
+		return err
+	}
+	return c.client.HealthCheck(ctx)
+}
@@ -29,6 +29,45 @@ type Client interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// SharedUsageClient queries the usage of cluster-shared resources (ingress LBs, shared storage).
+// It is separate from Client because only the daily ETL needs it; MockClient implements both.
+type SharedUsageClient interface {
+	// GetSharedResourceUsage returns the metered units of a shared resource in the time range and the
+	// per-namespace measure of key: "traffic" is bytes served, "capacity" is bytes provisioned.
+	GetSharedResourceUsage(ctx context.Context, resource, key string, startTime, endTime time.Time) (units float64, byNamespace map[string]float64, err error)
+}
+
+// CardinalityClient counts the series a selector matches, cheaply (series API), before an
+// expensive range query runs. It is separate from Client because only GuardedClient needs it;
+// MockClient implements both.
+type CardinalityClient interface {
+	// CountSeries returns the number of series matching the label matchers in the time range; empty
+	// matcher values match everything. Keys are namespace, workload, pod, node and resource.
+	CountSeries(ctx context.Context, matchers map[string]string, startTime, endTime time.Time) (int, error)
+}
+
+// RequestClient queries the request rate, error and latency series SLOs are evaluated from. It is
+// separate from Client because only SLO evaluation needs it; MockClient implements both.
+type RequestClient interface {
+	// GetRequestMetrics returns the request series of the services in the time range, one point per
+	// step and service; empty namespace or service match every one.
+	GetRequestMetrics(ctx context.Context, namespace, service string, startTime, endTime time.Time) ([]RequestMetric, error)
+}
+
+// RequestMetric is the requests a service served in the step ending at Timestamp.
+type RequestMetric struct {
+	Namespace string    `json:"namespace"`
+	Service   string    `json:"service"`
+	Requests  int64     `json:"requests"`
+	Errors    int64     `json:"errors"` // 5xx responses
+	Timestamp time.Time `json:"timestamp"`
+	// Latency histogram: LatencyCounts[i] requests took at most LatencyBoundsMs[i] milliseconds
+	// (cumulative); LatencySumMs is the total latency of all requests.
+	LatencyBoundsMs []float64 `json:"latency_bounds_ms"`
+	LatencyCounts   []int64   `json:"latency_counts"`
+	LatencySumMs    float64   `json:"latency_sum_ms"`
+}
+
 // ThrottlingMetric represents CPU throttling metrics.
 type ThrottlingMetric struct {
 	Namespace       string    `json:"namespace"`
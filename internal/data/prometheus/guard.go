@@ -0,0 +1,157 @@
+// Package prometheus guard.go: cardinality / query cost guard so one oversized selector fails fast
+// with an actionable error instead of timing out the whole calculation run.
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// ErrQueryTooExpensive is returned when a query is predicted to exceed the configured limits.
+var ErrQueryTooExpensive = dataerr.Validation("prometheus query too expensive")
+
+// GuardConfig limits the cost of a single query. Zero limits disable the check.
+type GuardConfig struct {
+	// MaxSeries is the number of series one selector may match; larger queries are refused.
+	MaxSeries int
+	// MaxSamples is series × steps of one query; larger queries are split into consecutive time
+	// ranges, and refused when even one step is too large.
+	MaxSamples int
+	// Step is the query resolution used to estimate samples (PrometheusConfig.StepInterval).
+	Step time.Duration
+}
+
+// GuardedClient wraps a Client and checks the series cardinality of every range query with
+// counter before running it. HealthCheck is not guarded.
+type GuardedClient struct {
+	client  Client
+	counter CardinalityClient
+	config  GuardConfig
+}
+
+// NewGuardedClient wraps client; counter is usually the same Prometheus (MockClient implements both).
+func NewGuardedClient(client Client, counter CardinalityClient, config GuardConfig) *GuardedClient {
+	return &GuardedClient{client: client, counter: counter, config: config}
+}
+
+// GetResourceMetrics implements Client.
+func (c *GuardedClient) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	matchers := map[string]string{"namespace": namespace, "workload": workload, "pod": pod}
+	return guarded(ctx, c, matchers, startTime, endTime, func(start, end time.Time) ([]costmodel.ResourceMetric, error) {
+		return c.client.GetResourceMetrics(ctx, namespace, workload, pod, start, end)
+	})
+}
+
+// GetNodeMetrics implements Client.
+func (c *GuardedClient) GetNodeMetrics(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	return guarded(ctx, c, map[string]string{"node": nodeName}, startTime, endTime, func(start, end time.Time) ([]costmodel.ResourceMetric, error) {
+		return c.client.GetNodeMetrics(ctx, nodeName, start, end)
+	})
+}
+
+// GetClusterMetrics implements Client.
+func (c *GuardedClient) GetClusterMetrics(ctx context.Context, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	return guarded(ctx, c, nil, startTime, endTime, func(start, end time.Time) ([]costmodel.ResourceMetric, error) {
+		return c.client.GetClusterMetrics(ctx, start, end)
+	})
+}
+
+// GetThrottlingMetrics implements Client.
+func (c *GuardedClient) GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]ThrottlingMetric, error) {
+	matchers := map[string]string{"namespace": namespace, "pod": pod}
+	return guarded(ctx, c, matchers, startTime, endTime, func(start, end time.Time) ([]ThrottlingMetric, error) {
+		return c.client.GetThrottlingMetrics(ctx, namespace, pod, start, end)
+	})
+}
+
+// GetSaturationMetrics implements Client.
+func (c *GuardedClient) GetSaturationMetrics(ctx context.Context, resourceType string, startTime, endTime time.Time) ([]SaturationMetric, error) {
+	return guarded(ctx, c, map[string]string{"resource": resourceType}, startTime, endTime, func(start, end time.Time) ([]SaturationMetric, error) {
+		return c.client.GetSaturationMetrics(ctx, resourceType, start, end)
+	})
+}
+
+// HealthCheck implements Client without a cardinality check.
+func (c *GuardedClient) HealthCheck(ctx context.Context) error {
+	return c.client.HealthCheck(ctx)
+}
+
+// Plan returns the time ranges a query over startTime..endTime matching matchers is run as, or
+// ErrQueryTooExpensive when it must be refused.
+func (c *GuardedClient) Plan(ctx context.Context, matchers map[string]string, startTime, endTime time.Time) ([][2]time.Time, error) {
+	whole := [][2]time.Time{{startTime, endTime}}
+	if c.config.MaxSeries <= 0 && c.config.MaxSamples <= 0 {
+		return whole, nil
+	}
+	series, err := c.counter.CountSeries(ctx, matchers, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("count series of %s: %w", selector(matchers), err)
+	}
+	if c.config.MaxSeries > 0 && series > c.config.MaxSeries {
+		return nil, fmt.Errorf("%w: %s matches %d series (limit %d); narrow it by namespace, workload or pod, or raise prometheus.max_series",
+			ErrQueryTooExpensive, selector(matchers), series, c.config.MaxSeries)
+	}
+	if c.config.MaxSamples <= 0 || c.config.Step <= 0 || !endTime.After(startTime) {
+		return whole, nil
+	}
+	steps := int64((endTime.Sub(startTime) + c.config.Step - 1) / c.config.Step)
+	if int64(series)*steps <= int64(c.config.MaxSamples) {
+		return whole, nil
+	}
+	stepsPerChunk := int64(c.config.MaxSamples / series)
+	if stepsPerChunk < 1 {
+		return nil, fmt.Errorf("%w: %s matches %d series, more than prometheus.max_samples (%d) at one step of %s; narrow it by namespace, workload or pod, or raise prometheus.max_samples",
+			ErrQueryTooExpensive, selector(matchers), series, c.config.MaxSamples, c.config.Step)
+	}
+	chunk := time.Duration(stepsPerChunk) * c.config.Step
+	var ranges [][2]time.Time
+	for start := startTime; start.Before(endTime); start = start.Add(chunk) {
+		end := start.Add(chunk)
+		if end.After(endTime) {
+			end = endTime
+		}
+		ranges = append(ranges, [2]time.Time{start, end})
+	}
+	return ranges, nil
+}
+
+// guarded runs query once per range of the plan, sequentially, and concatenates the results.
+func guarded[T any](ctx context.Context, c *GuardedClient, matchers map[string]string, startTime, endTime time.Time, query func(start, end time.Time) ([]T, error)) ([]T, error) {
+	ranges, err := c.Plan(ctx, matchers, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranges) == 1 {
+		return query(ranges[0][0], ranges[0][1])
+	}
+	var out []T
+	for _, r := range ranges {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		part, err := query(r[0], r[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s %s..%s: %w", selector(matchers), r[0].Format(time.RFC3339), r[1].Format(time.RFC3339), err)
+		}
+		out = append(out, part...)
+	}
+	return out, nil
+}
+
+// selector renders the non-empty matchers PromQL-style, e.g. {namespace="a",pod="b"}.
+func selector(matchers map[string]string) string {
+	parts := make([]string, 0, len(matchers))
+	for k, v := range matchers {
+		if v != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", k, v))
+		}
+	}
+	sort.Strings(parts)
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,79 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+func TestGuardedClient(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	mock := NewMockClient(config) // 5 namespaces × 3 workloads × 2 pods × 4 series = 120
+	ctx := context.Background()
+	end := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-24 * time.Hour)
+
+	t.Run("refuses selectors over max series", func(t *testing.T) {
+		c := NewGuardedClient(mock, mock, GuardConfig{MaxSeries: 50})
+		_, err := c.GetClusterMetrics(ctx, start, end)
+		if !errors.Is(err, ErrQueryTooExpensive) || !errors.Is(err, dataerr.ErrValidation) {
+			t.Fatalf("err = %v, want ErrQueryTooExpensive", err)
+		}
+		if !strings.Contains(err.Error(), "120 series (limit 50)") || !strings.Contains(err.Error(), "prometheus.max_series") {
+			t.Errorf("error is not actionable: %v", err)
+		}
+		// 按 namespace 缩小后 24 series，不再拒绝
+		if _, err := c.GetResourceMetrics(ctx, "default", "", "", start, end); err != nil {
+			t.Errorf("narrowed selector: %v", err)
+		}
+	})
+
+	t.Run("splits queries over max samples", func(t *testing.T) {
+		c := NewGuardedClient(mock, mock, GuardConfig{MaxSamples: 24 * 30, Step: time.Hour})
+		ranges, err := c.Plan(ctx, map[string]string{"namespace": "default"}, start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 24 series × 24 steps = 576 <= 720: one query
+		if len(ranges) != 1 {
+			t.Errorf("namespace plan = %v, want one range", ranges)
+		}
+		ranges, err = c.Plan(ctx, nil, start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 120 series: 6 steps per query
+		if len(ranges) != 4 || !ranges[0][0].Equal(start) || !ranges[3][1].Equal(end) || ranges[0][1].Sub(ranges[0][0]) != 6*time.Hour {
+			t.Errorf("cluster plan = %v, want 4 ranges of 6h", ranges)
+		}
+		metrics, err := c.GetResourceMetrics(ctx, "", "", "", start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 4 * mock.getMetricCount(); len(metrics) != want {
+			t.Errorf("len(metrics) = %d, want %d (results of all ranges)", len(metrics), want)
+		}
+	})
+
+	t.Run("refuses when one step is too large", func(t *testing.T) {
+		c := NewGuardedClient(mock, mock, GuardConfig{MaxSamples: 100, Step: time.Hour})
+		if _, err := c.GetThrottlingMetrics(ctx, "", "", start, end); !errors.Is(err, ErrQueryTooExpensive) || !strings.Contains(err.Error(), "prometheus.max_samples") {
+			t.Errorf("err = %v, want ErrQueryTooExpensive for max_samples", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		failing := DefaultMockConfig()
+		failing.ErrorRate = 1
+		failing.LatencyMs = 0
+		c := NewGuardedClient(mock, NewMockClient(failing), GuardConfig{})
+		if _, err := c.GetClusterMetrics(ctx, start, end); err != nil {
+			t.Errorf("no limits should not count series: %v", err)
+		}
+	})
+}
@@ -7,6 +7,9 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/latency"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockrand"
 	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 )
 
@@ -30,6 +33,10 @@ type MockConfig struct {
 	// Nodes to simulate
 	Nodes []string `json:"nodes"`
 
+	// SeriesPerPod is the number of series every pod exposes, for CountSeries (0 = 4: CPU/memory
+	// request/usage)
+	SeriesPerPod int `json:"series_per_pod"`
+
 	// RandomSeed for deterministic generation
 	RandomSeed int64 `json:"random_seed"`
 
@@ -38,6 +45,21 @@ type MockConfig struct {
 
 	// LatencyMs simulates network latency in milliseconds
 	LatencyMs int `json:"latency_ms"`
+
+	// LatencyJitterMs adds a uniform random 0..LatencyJitterMs to LatencyMs
+	LatencyJitterMs int `json:"latency_jitter_ms"`
+
+	// TailLatencyRate is the probability (0.0 - 1.0) of a long-tail call, which waits an extra
+	// Pareto-distributed TailLatencyMs..20×TailLatencyMs (see latency.Profile)
+	TailLatencyRate float64 `json:"tail_latency_rate"`
+	TailLatencyMs   int     `json:"tail_latency_ms"`
+
+	// SLOServices are the services GetRequestMetrics generates request series for, with their
+	// availability, latency distribution and violation windows (nil: DefaultMockSLOServices)
+	SLOServices []MockSLOService `json:"slo_services"`
+
+	// SLOStep is the resolution of the request series (0 = 5m)
+	SLOStep time.Duration `json:"slo_step"`
 }
 
 // DefaultMockConfig returns a default configuration for mock data generation.
@@ -57,8 +79,9 @@ func DefaultMockConfig() MockConfig {
 
 // MockClient is a mock implementation of the Prometheus Client interface.
 type MockClient struct {
-	config MockConfig
-	rand   *rand.Rand
+	config  MockConfig
+	rand    *rand.Rand
+	latency *latency.Simulator
 }
 
 // NewMockClient creates a new mock Prometheus client with the given configuration.
@@ -67,19 +90,20 @@ func NewMockClient(config MockConfig) *MockClient {
 		config.RandomSeed = time.Now().UnixNano()
 	}
 	return &MockClient{
-		config: config,
-		rand:   rand.New(rand.NewSource(config.RandomSeed)),
+		config:  config,
+		rand:    mockrand.New(config.RandomSeed),
+		latency: latency.New(latency.Millis(config.LatencyMs, config.LatencyJitterMs, config.TailLatencyRate, config.TailLatencyMs), config.RandomSeed),
 	}
 }
 
 // GetResourceMetrics retrieves mock resource metrics for the given parameters.
 func (m *MockClient) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock Prometheus error: simulated failure")
+		return nil, dataerr.Unavailable("mock Prometheus error: simulated failure")
 	}
 
 	// Generate metrics based on configuration
@@ -96,12 +120,12 @@ func (m *MockClient) GetResourceMetrics(ctx context.Context, namespace, workload
 
 // GetNodeMetrics retrieves mock node-level metrics.
 func (m *MockClient) GetNodeMetrics(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock Prometheus error: node metrics unavailable")
+		return nil, dataerr.Unavailable("mock Prometheus error: node metrics unavailable")
 	}
 
 	var metrics []costmodel.ResourceMetric
@@ -123,12 +147,12 @@ func (m *MockClient) GetNodeMetrics(ctx context.Context, nodeName string, startT
 
 // GetClusterMetrics retrieves mock cluster-wide metrics.
 func (m *MockClient) GetClusterMetrics(ctx context.Context, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock Prometheus error: cluster metrics unavailable")
+		return nil, dataerr.Unavailable("mock Prometheus error: cluster metrics unavailable")
 	}
 
 	// Cluster metrics are aggregated, return a smaller set
@@ -151,12 +175,12 @@ func (m *MockClient) GetClusterMetrics(ctx context.Context, startTime, endTime t
 
 // GetThrottlingMetrics retrieves mock CPU throttling metrics.
 func (m *MockClient) GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]ThrottlingMetric, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock Prometheus error: throttling metrics unavailable")
+		return nil, dataerr.Unavailable("mock Prometheus error: throttling metrics unavailable")
 	}
 
 	var metrics []ThrottlingMetric
@@ -181,12 +205,12 @@ func (m *MockClient) GetThrottlingMetrics(ctx context.Context, namespace, pod st
 
 // GetSaturationMetrics retrieves mock resource saturation metrics.
 func (m *MockClient) GetSaturationMetrics(ctx context.Context, resourceType string, startTime, endTime time.Time) ([]SaturationMetric, error) {
-	if err := m.simulateLatency(); err != nil {
+	if err := m.simulateLatency(ctx); err != nil {
 		return nil, err
 	}
 
 	if m.shouldReturnError() {
-		return nil, fmt.Errorf("mock Prometheus error: saturation metrics unavailable")
+		return nil, dataerr.Unavailable("mock Prometheus error: saturation metrics unavailable")
 	}
 
 	var metrics []SaturationMetric
@@ -206,21 +230,78 @@ func (m *MockClient) GetSaturationMetrics(ctx context.Context, resourceType stri
 	return metrics, nil
 }
 
+// GetSharedResourceUsage returns mock shared resource usage: units scale with the range length and
+// every configured namespace gets a random measure ("empty" scenario returns nothing).
+func (m *MockClient) GetSharedResourceUsage(ctx context.Context, resource, key string, startTime, endTime time.Time) (float64, map[string]float64, error) {
+	if err := m.simulateLatency(ctx); err != nil {
+		return 0, nil, err
+	}
+	if m.shouldReturnError() {
+		return 0, nil, dataerr.Unavailable("mock Prometheus error: cannot query shared resource usage")
+	}
+	byNamespace := make(map[string]float64)
+	if m.config.Scenario == "empty" {
+		return 0, byNamespace, nil
+	}
+	hours := endTime.Sub(startTime).Hours()
+	units := hours * (1 + m.rand.Float64()*9) // 1-10 units per hour
+	for _, ns := range m.config.Namespaces {
+		switch key {
+		case "traffic":
+			byNamespace[ns] = hours * m.rand.Float64() * 1e9
+		case "capacity":
+			byNamespace[ns] = float64(1+m.rand.Intn(100)) * (1 << 30)
+		}
+	}
+	return units, byNamespace, nil
+}
+
+// CountSeries returns the number of series the configured namespaces, workloads and pods expose,
+// narrowed by every non-empty matcher ("empty" scenario returns 0).
+func (m *MockClient) CountSeries(ctx context.Context, matchers map[string]string, startTime, endTime time.Time) (int, error) {
+	if err := m.simulateLatency(ctx); err != nil {
+		return 0, err
+	}
+	if m.shouldReturnError() {
+		return 0, dataerr.Unavailable("mock Prometheus error: cannot count series")
+	}
+	if m.config.Scenario == "empty" {
+		return 0, nil
+	}
+	perPod := m.config.SeriesPerPod
+	if perPod <= 0 {
+		perPod = 4
+	}
+	namespaces, workloads, pods := len(m.config.Namespaces), m.config.WorkloadsPerNamespace, m.config.PodsPerWorkload
+	if matchers["namespace"] != "" {
+		namespaces = 1
+	}
+	if matchers["workload"] != "" {
+		workloads = 1
+	}
+	if matchers["pod"] != "" {
+		workloads, pods = 1, 1
+	}
+	series := namespaces * workloads * pods * perPod
+	if matchers["node"] != "" && len(m.config.Nodes) > 0 {
+		series /= len(m.config.Nodes)
+	}
+	return max(series, 1), nil
+}
+
 // HealthCheck always returns nil (healthy) for mock client.
 func (m *MockClient) HealthCheck(ctx context.Context) error {
 	if m.shouldReturnError() {
-		return fmt.Errorf("mock Prometheus health check failed")
+		return dataerr.Unavailable("mock Prometheus health check failed")
 	}
 	return nil
 }
 
 // Helper methods
 
-func (m *MockClient) simulateLatency() error {
-	if m.config.LatencyMs > 0 {
-		time.Sleep(time.Duration(m.config.LatencyMs) * time.Millisecond)
-	}
-	return nil
+// simulateLatency waits out the configured latency, returning ctx.Err() early once ctx is done.
+func (m *MockClient) simulateLatency(ctx context.Context) error {
+	return m.latency.Wait(ctx)
 }
 
 func (m *MockClient) shouldReturnError() bool {
@@ -251,12 +332,28 @@ func (m *MockClient) generateResourceMetric(namespace, workload, pod string, sta
 	memUsage := m.generateMemoryUsageForRequest(memRequest)
 
 	return costmodel.ResourceMetric{
-		CPURequest:  cpuRequest,
-		CPUUsageP95: cpuUsage,
-		MemRequest:  memRequest,
-		MemUsageP95: memUsage,
-		Timestamp:   m.generateTimestamp(startTime, endTime, index, m.getMetricCount()),
+		CPURequest:     cpuRequest,
+		CPUUsageP95:    cpuUsage,
+		MemRequest:     memRequest,
+		MemUsageP95:    memUsage,
+		CPUUsageSketch: mockUsageSketch(cpuUsage),
+		MemUsageSketch: mockUsageSketch(float64(memUsage)),
+		Timestamp:      m.generateTimestamp(startTime, endTime, index, m.getMetricCount()),
+	}
+}
+
+// mockUsageSketch returns a deterministic one-minute-resolution sketch of an hour whose P95 is p95:
+// 57 samples ramping from 0.4×p95 up to p95 and 3 short bursts above it. It draws no random numbers,
+// so seeded scenarios keep producing the same metrics.
+func mockUsageSketch(p95 float64) *costmodel.UsageSketch {
+	sketch := costmodel.NewUsageSketch(0)
+	for i := 0; i < 57; i++ {
+		sketch.Add(p95 * (0.4 + 0.6*float64(i)/56))
+	}
+	for _, burst := range []float64{1.02, 1.05, 1.1} {
+		sketch.Add(p95 * burst)
 	}
+	return sketch
 }
 
 func (m *MockClient) generateCPURequest(resourceType string) float64 {
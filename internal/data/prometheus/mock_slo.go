@@ -0,0 +1,157 @@
+// Package prometheus mock_slo.go: request rate, error and latency series of the mock client, so SLO
+// evaluation can be exercised with configurable availability and latency per service and windows in
+// which a service violates its SLO.
+package prometheus
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+const defaultMockSLOStep = 5 * time.Minute
+
+// mockLatencyBoundsMs are the latency histogram bounds of the mock request series.
+var mockLatencyBoundsMs = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// MockSLOService configures the request series of one service. Latency is log-normal, fitted to
+// LatencyMedianMs and LatencyP95Ms.
+type MockSLOService struct {
+	Namespace         string  `json:"namespace"`
+	Service           string  `json:"service"`
+	RequestsPerMinute float64 `json:"requests_per_minute"` // ±10% per step; 0 = 100
+	Availability      float64 `json:"availability"`        // % successful requests; 0 = 99.9
+	LatencyMedianMs   float64 `json:"latency_median_ms"`   // 0 = 50
+	LatencyP95Ms      float64 `json:"latency_p95_ms"`      // 0 (or below the median) = 3× median
+
+	Violations []MockSLOViolation `json:"violations"`
+}
+
+// MockSLOViolation is a window in which a service serves worse than usual: from Start for Duration,
+// repeated every Every (0: once).
+type MockSLOViolation struct {
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Every    time.Duration `json:"every"`
+	// Availability is the % of successful requests in the window; 0 keeps the service's.
+	Availability float64 `json:"availability"`
+	// LatencyFactor multiplies the latency of the requests in the window; 0 keeps it.
+	LatencyFactor float64 `json:"latency_factor"`
+}
+
+// active reports whether t is inside the window.
+func (v MockSLOViolation) active(t time.Time) bool {
+	if t.Before(v.Start) {
+		return false
+	}
+	offset := t.Sub(v.Start)
+	if v.Every > 0 {
+		offset %= v.Every
+	}
+	return offset < v.Duration
+}
+
+// DefaultMockSLOServices returns the services of the default SLO scenario: api-gateway and
+// order-service meet their SLOs, payment-service has a 30 minute incident every day at 14:00 UTC
+// (95% availability, 3× latency).
+func DefaultMockSLOServices() []MockSLOService {
+	return []MockSLOService{
+		{Namespace: "default", Service: "api-gateway", RequestsPerMinute: 600, Availability: 99.97, LatencyMedianMs: 40, LatencyP95Ms: 110},
+		{Namespace: "app-prod", Service: "order-service", RequestsPerMinute: 300, Availability: 99.95, LatencyMedianMs: 30, LatencyP95Ms: 80},
+		{Namespace: "app-prod", Service: "payment-service", RequestsPerMinute: 120, Availability: 99.9, LatencyMedianMs: 70, LatencyP95Ms: 180,
+			Violations: []MockSLOViolation{{
+				Start:         time.Date(2020, 1, 1, 14, 0, 0, 0, time.UTC),
+				Duration:      30 * time.Minute,
+				Every:         24 * time.Hour,
+				Availability:  95,
+				LatencyFactor: 3,
+			}}},
+	}
+}
+
+// GetRequestMetrics returns mock request series of the configured SLO services, one point per
+// SLOStep aligned to the step ("empty" scenario returns nothing).
+func (m *MockClient) GetRequestMetrics(ctx context.Context, namespace, service string, startTime, endTime time.Time) ([]RequestMetric, error) {
+	if err := m.simulateLatency(ctx); err != nil {
+		return nil, err
+	}
+	if m.shouldReturnError() {
+		return nil, dataerr.Unavailable("mock Prometheus error: request metrics unavailable")
+	}
+	if m.config.Scenario == "empty" {
+		return nil, nil
+	}
+	services := m.config.SLOServices
+	if services == nil {
+		services = DefaultMockSLOServices()
+	}
+	step := m.config.SLOStep
+	if step <= 0 {
+		step = defaultMockSLOStep
+	}
+
+	var metrics []RequestMetric
+	for _, svc := range services {
+		if (namespace != "" && svc.Namespace != namespace) || (service != "" && svc.Service != service) {
+			continue
+		}
+		for t := startTime.Truncate(step).Add(step); !t.After(endTime); t = t.Add(step) {
+			metrics = append(metrics, m.generateRequestMetric(svc, t, step))
+		}
+	}
+	return metrics, nil
+}
+
+// generateRequestMetric returns the requests svc served in the step ending at t.
+func (m *MockClient) generateRequestMetric(svc MockSLOService, t time.Time, step time.Duration) RequestMetric {
+	rpm, availability, median, p95 := svc.RequestsPerMinute, svc.Availability, svc.LatencyMedianMs, svc.LatencyP95Ms
+	if rpm <= 0 {
+		rpm = 100
+	}
+	if availability <= 0 {
+		availability = 99.9
+	}
+	if median <= 0 {
+		median = 50
+	}
+	if p95 <= median {
+		p95 = 3 * median
+	}
+	factor := 1.0
+	for _, v := range svc.Violations {
+		if !v.active(t) {
+			continue
+		}
+		if v.Availability > 0 {
+			availability = v.Availability
+		}
+		if v.LatencyFactor > 0 {
+			factor = v.LatencyFactor
+		}
+	}
+
+	requests := int64(math.Round(rpm * step.Minutes() * (0.9 + 0.2*m.rand.Float64())))
+	// stochastic rounding keeps the expected error count at the configured availability
+	errors := min(int64(float64(requests)*(100-availability)/100+m.rand.Float64()), requests)
+
+	// log-normal: median e^mu, P95 e^(mu + 1.645 sigma)
+	mu := math.Log(median * factor)
+	sigma := math.Log(p95/median) / 1.6449
+	counts := make([]int64, len(mockLatencyBoundsMs))
+	for i, bound := range mockLatencyBoundsMs {
+		cdf := 0.5 * math.Erfc(-(math.Log(bound)-mu)/(sigma*math.Sqrt2))
+		counts[i] = int64(math.Round(float64(requests) * cdf))
+	}
+	return RequestMetric{
+		Namespace:       svc.Namespace,
+		Service:         svc.Service,
+		Requests:        requests,
+		Errors:          errors,
+		Timestamp:       t,
+		LatencyBoundsMs: mockLatencyBoundsMs,
+		LatencyCounts:   counts,
+		LatencySumMs:    float64(requests) * math.Exp(mu+sigma*sigma/2),
+	}
+}
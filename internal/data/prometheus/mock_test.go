@@ -300,3 +300,88 @@ func TestMockClient_WithLatency(t *testing.T) {
 		t.Errorf("Expected at least 10ms latency, got %v", elapsed)
 	}
 }
+
+func TestMockClient_GetRequestMetrics(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	config.SLOStep = time.Minute
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	config.SLOServices = []MockSLOService{
+		{Namespace: "app-prod", Service: "checkout", RequestsPerMinute: 1000, Availability: 99.9, LatencyMedianMs: 40, LatencyP95Ms: 100,
+			Violations: []MockSLOViolation{{Start: start.Add(2 * time.Hour), Duration: time.Hour, Availability: 90, LatencyFactor: 5}}},
+		{Namespace: "default", Service: "api-gateway"},
+	}
+	client := NewMockClient(config)
+	ctx := context.Background()
+
+	metrics, err := client.GetRequestMetrics(ctx, "app-prod", "", start, start.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("GetRequestMetrics failed: %v", err)
+	}
+	if len(metrics) != 240 {
+		t.Fatalf("Expected 240 one-minute points of checkout, got %d", len(metrics))
+	}
+	type totals struct {
+		requests, errors, slow int64
+	}
+	var normal, violation totals
+	for _, p := range metrics {
+		if p.Service != "checkout" || p.Requests < 900 || p.Requests > 1100 {
+			t.Fatalf("Unexpected point %+v", p)
+		}
+		w := &normal
+		if !p.Timestamp.Before(start.Add(2*time.Hour)) && p.Timestamp.Before(start.Add(3*time.Hour)) {
+			w = &violation
+		}
+		w.requests += p.Requests
+		w.errors += p.Errors
+		w.slow += p.Requests - p.LatencyCounts[4] // > 100ms
+	}
+	if a := 100 - float64(normal.errors)/float64(normal.requests)*100; a < 99.85 || a > 99.95 {
+		t.Errorf("Expected availability ~99.9%% outside the violation window, got %.3f", a)
+	}
+	if a := 100 - float64(violation.errors)/float64(violation.requests)*100; a < 89.5 || a > 90.5 {
+		t.Errorf("Expected availability ~90%% in the violation window, got %.3f", a)
+	}
+	if share := float64(normal.slow) / float64(normal.requests); share < 0.04 || share > 0.06 {
+		t.Errorf("Expected ~5%% of requests above the 100ms P95, got %.3f", share)
+	}
+	if share := float64(violation.slow) / float64(violation.requests); share < 0.5 {
+		t.Errorf("Expected most requests above 100ms with 5x latency, got %.3f", share)
+	}
+
+	all, err := client.GetRequestMetrics(ctx, "", "", start, start.Add(time.Hour))
+	if err != nil || len(all) != 120 {
+		t.Errorf("Expected 60 points per service, got %d (%v)", len(all), err)
+	}
+
+	config.Scenario = "empty"
+	if metrics, _ := NewMockClient(config).GetRequestMetrics(ctx, "", "", start, start.Add(time.Hour)); len(metrics) != 0 {
+		t.Errorf("Expected no request metrics in the empty scenario, got %d", len(metrics))
+	}
+}
+
+func TestDefaultMockSLOServices(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	client := NewMockClient(config)
+	day := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	metrics, err := client.GetRequestMetrics(context.Background(), "app-prod", "payment-service", day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("GetRequestMetrics failed: %v", err)
+	}
+	var inWindow, outside [2]int64 // errors, requests
+	for _, p := range metrics {
+		w := &outside
+		if p.Timestamp.Hour() == 14 && p.Timestamp.Minute() < 30 {
+			w = &inWindow
+		}
+		w[0] += p.Errors
+		w[1] += p.Requests
+	}
+	windowRate, outsideRate := float64(inWindow[0])/float64(inWindow[1]), float64(outside[0])/float64(outside[1])
+	if windowRate < 10*outsideRate {
+		t.Errorf("Expected the daily 14:00 incident to burn the error budget, got error rate %.4f in window vs %.4f outside", windowRate, outsideRate)
+	}
+}
@@ -0,0 +1,78 @@
+// Package prometheus retry.go: retry wrapper so transient Prometheus failures (timeouts, 5xx) are
+// retried with backoff instead of failing the query.
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/retry"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// RetryClient wraps a Client with a retrier. HealthCheck is not retried so readiness probes
+// report the current state. Wrap it inside BreakerClient, not around it.
+type RetryClient struct {
+	client  Client
+	retrier *retry.Retrier
+}
+
+// NewRetryClient wraps client with retrier.
+func NewRetryClient(client Client, retrier *retry.Retrier) *RetryClient {
+	return &RetryClient{client: client, retrier: retrier}
+}
+
+// GetResourceMetrics implements Client.
+func (c *RetryClient) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	var out []costmodel.ResourceMetric
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetResourceMetrics(ctx, namespace, workload, pod, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// GetNodeMetrics implements Client.
+func (c *RetryClient) GetNodeMetrics(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	var out []costmodel.ResourceMetric
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetNodeMetrics(ctx, nodeName, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// GetClusterMetrics implements Client.
+func (c *RetryClient) GetClusterMetrics(ctx context.Context, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	var out []costmodel.ResourceMetric
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetClusterMetrics(ctx, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// GetThrottlingMetrics implements Client.
+func (c *RetryClient) GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]ThrottlingMetric, error) {
+	var out []ThrottlingMetric
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetThrottlingMetrics(ctx, namespace, pod, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// GetSaturationMetrics implements Client.
+func (c *RetryClient) GetSaturationMetrics(ctx context.Context, resourceType string, startTime, endTime time.Time) ([]SaturationMetric, error) {
+	var out []SaturationMetric
+	err := c.retrier.Do(ctx, func() (err error) {
+		out, err = c.client.GetSaturationMetrics(ctx, resourceType, startTime, endTime)
+		return err
+	})
+	return out, err
+}
+
+// HealthCheck implements Client without retrying.
+func (c *RetryClient) HealthCheck(ctx context.Context) error {
+	return c.client.HealthCheck(ctx)
+}
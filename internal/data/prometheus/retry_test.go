@@ -0,0 +1,42 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/retry"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// flakyClient fails the first failures cluster queries.
+type flakyClient struct {
+	*MockClient
+	failures, calls int
+}
+
+func (c *flakyClient) GetClusterMetrics(ctx context.Context, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, dataerr.Unavailable("prometheus: 503")
+	}
+	return c.MockClient.GetClusterMetrics(ctx, startTime, endTime)
+}
+
+func TestRetryClient(t *testing.T) {
+	config := DefaultMockConfig()
+	config.LatencyMs = 0
+	flaky := &flakyClient{MockClient: NewMockClient(config), failures: 2}
+	noSleep := func(ctx context.Context, d time.Duration) error { return nil }
+	c := NewRetryClient(flaky, retry.New(retry.Policy{MaxAttempts: 3}).WithSleep(noSleep))
+	now := time.Now()
+
+	if _, err := c.GetClusterMetrics(context.Background(), now.Add(-time.Hour), now); err != nil || flaky.calls != 3 {
+		t.Fatalf("GetClusterMetrics = %v after %d calls, want success on the 3rd", err, flaky.calls)
+	}
+	flaky.calls, flaky.failures = 0, 5
+	if _, err := c.GetClusterMetrics(context.Background(), now.Add(-time.Hour), now); err == nil || flaky.calls != 3 {
+		t.Errorf("GetClusterMetrics = %v after %d calls, want failure after 3", err, flaky.calls)
+	}
+}
@@ -0,0 +1,81 @@
+// Package pseudonym replaces cluster object names with consistent pseudonyms for datasets that
+// leave the company (vendor support, public demos).
+//
+// A pseudonym is a keyed hash (HMAC-SHA256) of the name, so the same name always maps to the same
+// pseudonym under one key and the original cannot be recovered or brute-forced from a list of
+// likely names without the key.
+package pseudonym
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Kinds of names; the kind is the prefix of the pseudonym (e.g. "ns-1a2b3c4d5e6f").
+const (
+	KindNamespace  = "ns"
+	KindWorkload   = "wl"
+	KindNode       = "node"
+	KindCostCenter = "cc"
+)
+
+// hexLen is the number of hex digits of a pseudonym (48 bits).
+const hexLen = 12
+
+// Pseudonymizer maps names to pseudonyms. It is safe for concurrent use.
+type Pseudonymizer struct {
+	key []byte
+}
+
+// New returns a Pseudonymizer for key. An empty key uses a random one, so pseudonyms are only
+// consistent within the lifetime of the Pseudonymizer.
+func New(key []byte) (*Pseudonymizer, error) {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("pseudonym: generate key: %w", err)
+		}
+	}
+	return &Pseudonymizer{key: key}, nil
+}
+
+// Name returns the pseudonym of name of the given kind; "" stays "".
+func (p *Pseudonymizer) Name(kind, name string) string {
+	if name == "" {
+		return ""
+	}
+	return kind + "-" + p.hash(kind, name)
+}
+
+// Pod returns the pseudonym of a pod. It is the pseudonym of its workload plus a suffix, so pods of
+// one workload still group together like their generated names do.
+func (p *Pseudonymizer) Pod(namespace, workload, pod string) string {
+	if pod == "" {
+		return ""
+	}
+	suffix := p.hash("pod", namespace+"/"+pod)[:5]
+	if workload == "" {
+		return "pod-" + suffix
+	}
+	return p.Name(KindWorkload, namespace+"/"+workload) + "-" + suffix
+}
+
+// Workload returns the pseudonym of a workload. Workloads are scoped by namespace: the same
+// workload name in two namespaces gets two pseudonyms.
+func (p *Pseudonymizer) Workload(namespace, workload string) string {
+	if workload == "" {
+		return ""
+	}
+	return p.Name(KindWorkload, namespace+"/"+workload)
+}
+
+func (p *Pseudonymizer) hash(kind, name string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil))[:hexLen]
+}
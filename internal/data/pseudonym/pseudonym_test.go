@@ -0,0 +1,41 @@
+package pseudonym
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPseudonymizer(t *testing.T) {
+	a, _ := New([]byte("k1"))
+	b, _ := New([]byte("k1"))
+	other, _ := New([]byte("k2"))
+
+	ns := a.Name(KindNamespace, "payments")
+	if !strings.HasPrefix(ns, "ns-") || len(ns) != len("ns-")+hexLen || strings.Contains(ns, "payments") {
+		t.Errorf("Name = %q", ns)
+	}
+	if b.Name(KindNamespace, "payments") != ns {
+		t.Error("same key should give the same pseudonym")
+	}
+	if other.Name(KindNamespace, "payments") == ns {
+		t.Error("another key should give another pseudonym")
+	}
+	if a.Name(KindNode, "payments") == a.Name(KindCostCenter, "payments") {
+		t.Error("kinds should not share pseudonyms")
+	}
+	if a.Workload("a", "api") == a.Workload("b", "api") {
+		t.Error("workloads should be scoped by namespace")
+	}
+	if p := a.Pod("a", "api", "api-7d9f-x1"); !strings.HasPrefix(p, a.Workload("a", "api")+"-") || p == a.Pod("a", "api", "api-7d9f-x2") {
+		t.Errorf("Pod = %q, want workload pseudonym prefix and distinct pods", p)
+	}
+	if a.Name(KindNamespace, "") != "" || a.Pod("a", "api", "") != "" {
+		t.Error("empty names should stay empty")
+	}
+
+	r1, _ := New(nil)
+	r2, _ := New(nil)
+	if r1.Name(KindNamespace, "payments") == r2.Name(KindNamespace, "payments") {
+		t.Error("random keys should differ")
+	}
+}
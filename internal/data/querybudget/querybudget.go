@@ -0,0 +1,171 @@
+// Package querybudget accounts the Prometheus and database queries made on behalf of each API
+// consumer (tenant / team) and checks them against per-window soft quotas, so one consumer's heavy
+// dashboards cannot starve everyone else. The HTTP layer binds the consumer to the request context
+// with WithConsumer; the data source wrappers report every query with Track.
+package querybudget
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the accounting window used when none is configured.
+const DefaultWindow = time.Hour
+
+// Kind is the data source a query was sent to.
+type Kind string
+
+const (
+	Prometheus Kind = "prometheus"
+	Database   Kind = "database"
+)
+
+// Quota is the number of queries a consumer may make per window; 0 is unlimited.
+type Quota struct {
+	PrometheusQueries int
+	DatabaseQueries   int
+}
+
+func (q Quota) limit(k Kind) int {
+	if k == Prometheus {
+		return q.PrometheusQueries
+	}
+	return q.DatabaseQueries
+}
+
+// Usage is the consumption of one consumer in the current window.
+type Usage struct {
+	Consumer          string
+	Quota             Quota
+	PrometheusQueries int64
+	PrometheusTime    time.Duration
+	DatabaseQueries   int64
+	DatabaseTime      time.Duration
+}
+
+// Exceeded returns the first data source whose quota the usage has reached, or "".
+func (u Usage) Exceeded() Kind {
+	for _, k := range []Kind{Prometheus, Database} {
+		if n := u.Quota.limit(k); n > 0 && u.queries(k) >= int64(n) {
+			return k
+		}
+	}
+	return ""
+}
+
+func (u Usage) queries(k Kind) int64 {
+	if k == Prometheus {
+		return u.PrometheusQueries
+	}
+	return u.DatabaseQueries
+}
+
+// Ledger keeps the usage of every consumer over fixed windows (aligned to the window length); a new
+// window starts every consumer from zero. It is safe for concurrent use.
+type Ledger struct {
+	window   time.Duration
+	defaults Quota
+	quotas   map[string]Quota
+	now      func() time.Time
+
+	mu    sync.Mutex
+	start time.Time
+	usage map[string]*Usage
+}
+
+// NewLedger creates a ledger with window (<= 0: DefaultWindow), the default quota of every consumer
+// and per-consumer overrides.
+func NewLedger(window time.Duration, defaults Quota, quotas map[string]Quota) *Ledger {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Ledger{window: window, defaults: defaults, quotas: quotas, now: time.Now, usage: make(map[string]*Usage)}
+}
+
+// Quota returns the quota of consumer.
+func (l *Ledger) Quota(consumer string) Quota {
+	if q, ok := l.quotas[consumer]; ok {
+		return q
+	}
+	return l.defaults
+}
+
+// Record adds one query of kind that took d to the usage of consumer.
+func (l *Ledger) Record(consumer string, k Kind, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll()
+	u, ok := l.usage[consumer]
+	if !ok {
+		u = &Usage{Consumer: consumer, Quota: l.Quota(consumer)}
+		l.usage[consumer] = u
+	}
+	switch k {
+	case Prometheus:
+		u.PrometheusQueries++
+		u.PrometheusTime += d
+	case Database:
+		u.DatabaseQueries++
+		u.DatabaseTime += d
+	}
+}
+
+// Check returns the data source whose quota consumer has used up in the current window ("" when
+// within quota) and when the window ends.
+func (l *Ledger) Check(consumer string) (Kind, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll()
+	end := l.start.Add(l.window)
+	if u, ok := l.usage[consumer]; ok {
+		return u.Exceeded(), end
+	}
+	return "", end
+}
+
+// Usage returns the bounds of the current window and the usage of every consumer in it, by consumer.
+func (l *Ledger) Usage() (start, end time.Time, usage []Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll()
+	usage = make([]Usage, 0, len(l.usage))
+	for _, u := range l.usage {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Consumer < usage[j].Consumer })
+	return l.start, l.start.Add(l.window), usage
+}
+
+// roll starts a new window when the current one is over. l.mu must be held.
+func (l *Ledger) roll() {
+	start := l.now().UTC().Truncate(l.window)
+	if start.Equal(l.start) {
+		return
+	}
+	l.start = start
+	l.usage = make(map[string]*Usage)
+}
+
+type meter struct {
+	ledger   *Ledger
+	consumer string
+}
+
+type meterKey struct{}
+
+// WithConsumer returns a context whose queries are accounted to consumer in l.
+func WithConsumer(ctx context.Context, l *Ledger, consumer string) context.Context {
+	return context.WithValue(ctx, meterKey{}, meter{ledger: l, consumer: consumer})
+}
+
+// Track records a query of kind started at start for the consumer of ctx; it does nothing for
+// contexts without a consumer (background jobs). Use it as defer Track(ctx, kind, time.Now()).
+func Track(ctx context.Context, k Kind, start time.Time) {
+	m, ok := ctx.Value(meterKey{}).(meter)
+	if !ok {
+		return
+	}
+	m.ledger.Record(m.consumer, k, time.Since(start))
+}
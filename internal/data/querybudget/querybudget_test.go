@@ -0,0 +1,52 @@
+package querybudget
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLedger(t *testing.T) {
+	now := time.Date(2020, 3, 1, 10, 15, 0, 0, time.UTC)
+	l := NewLedger(time.Hour, Quota{PrometheusQueries: 2}, map[string]Quota{"batch": {DatabaseQueries: 1}})
+	l.now = func() time.Time { return now }
+
+	ctx := WithConsumer(context.Background(), l, "team-a")
+	Track(ctx, Prometheus, now)
+	Track(context.Background(), Prometheus, now) // 无调用方，不计入
+	if k, end := l.Check("team-a"); k != "" || !end.Equal(time.Date(2020, 3, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Check = %q, %v; want within quota until 11:00", k, end)
+	}
+	Track(ctx, Prometheus, now)
+	if k, _ := l.Check("team-a"); k != Prometheus {
+		t.Errorf("Check after 2 queries = %q, want prometheus", k)
+	}
+
+	// 覆盖配额：不限 Prometheus，数据库 1 次
+	l.Record("batch", Prometheus, time.Second)
+	l.Record("batch", Prometheus, time.Second)
+	if k, _ := l.Check("batch"); k != "" {
+		t.Errorf("batch within quota: got %q", k)
+	}
+	l.Record("batch", Database, 2*time.Second)
+	if k, _ := l.Check("batch"); k != Database {
+		t.Errorf("batch database: got %q, want database", k)
+	}
+
+	start, _, usage := l.Usage()
+	if !start.Equal(time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC)) || len(usage) != 2 || usage[0].Consumer != "batch" {
+		t.Fatalf("Usage = %v %+v", start, usage)
+	}
+	if u := usage[0]; u.PrometheusQueries != 2 || u.DatabaseQueries != 1 || u.DatabaseTime != 2*time.Second || u.Quota.DatabaseQueries != 1 {
+		t.Errorf("batch usage = %+v", u)
+	}
+
+	// 新窗口重新计数
+	now = now.Add(time.Hour)
+	if k, _ := l.Check("team-a"); k != "" {
+		t.Errorf("next window: got %q, want within quota", k)
+	}
+	if _, _, usage := l.Usage(); len(usage) != 0 {
+		t.Errorf("next window usage = %+v, want empty", usage)
+	}
+}
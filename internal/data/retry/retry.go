@@ -0,0 +1,165 @@
+// Package retry provides the retry policy shared by the external data source clients (Prometheus,
+// K8s API, ClickHouse, Analysis Engine): bounded attempts, exponential backoff with jitter and a
+// classification of the errors worth retrying. Wrap a client with its retrier inside its circuit
+// breaker, so the breaker sees one outcome per logical call.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// Policy controls how a failed call is retried.
+type Policy struct {
+	// MaxAttempts is the number of calls including the first; 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait before a retry.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after every retry (>= 1).
+	Multiplier float64
+	// Jitter randomizes every backoff within ±Jitter of its value (0-1), spreading the retries of
+	// concurrent callers.
+	Jitter float64
+	// Retryable classifies call errors; default IsRetryable.
+	Retryable func(error) bool
+}
+
+// DefaultPolicy returns the defaults used when a field is zero.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// WithDefaults returns p with every zero (or out-of-range) field set to its default.
+func (p Policy) WithDefaults() Policy {
+	d := DefaultPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = d.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = d.MaxBackoff
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = d.Multiplier
+	}
+	if p.Jitter <= 0 || p.Jitter > 1 {
+		p.Jitter = d.Jitter
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	return p
+}
+
+// Backoff returns the wait before retry n (1 for the first retry) without jitter.
+func (p Policy) Backoff(n int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(n-1))
+	if d > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(d)
+}
+
+// IsRetryable is the default classification: transient failures (dataerr.ErrUnavailable, network
+// errors) are retried; errors with a Retryable() bool method decide for themselves; cancellation,
+// deadlines and caller errors are not.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	if errors.Is(err, dataerr.ErrUnavailable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Retrier runs calls under a Policy. It is safe for concurrent use.
+type Retrier struct {
+	policy Policy
+	sleep  func(ctx context.Context, d time.Duration) error
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New creates a retrier for p (zero fields take their defaults).
+func New(p Policy) *Retrier {
+	return &Retrier{
+		policy: p.WithDefaults(),
+		sleep:  sleepContext,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// WithSleep replaces how the retrier waits between attempts (e.g. a no-op in tests) and returns r.
+func (r *Retrier) WithSleep(sleep func(ctx context.Context, d time.Duration) error) *Retrier {
+	r.sleep = sleep
+	return r
+}
+
+// Policy returns the effective policy.
+func (r *Retrier) Policy() Policy {
+	return r.policy
+}
+
+// Do calls fn until it succeeds, returns an error the policy does not retry, or MaxAttempts calls
+// were made; it returns the last error of fn. Waiting stops early once ctx is done.
+func (r *Retrier) Do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= r.policy.MaxAttempts || !r.policy.Retryable(err) || ctx.Err() != nil {
+			return err
+		}
+		if r.sleep(ctx, r.backoff(attempt)) != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns Backoff(n) with jitter applied.
+func (r *Retrier) backoff(n int) time.Duration {
+	d := r.policy.Backoff(n)
+	r.mu.Lock()
+	f := 1 + r.policy.Jitter*(2*r.rand.Float64()-1)
+	r.mu.Unlock()
+	return time.Duration(float64(d) * f)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// recordSleeps makes r record its waits instead of sleeping.
+func recordSleeps(r *Retrier) *[]time.Duration {
+	var waits []time.Duration
+	r.WithSleep(func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	})
+	return &waits
+}
+
+func TestRetrierDo(t *testing.T) {
+	ctx := context.Background()
+	r := New(Policy{MaxAttempts: 4, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 250 * time.Millisecond, Multiplier: 2, Jitter: 0.1})
+	waits := recordSleeps(r)
+
+	calls := 0
+	err := r.Do(ctx, func() error {
+		calls++
+		if calls < 3 {
+			return dataerr.Unavailable("flaky")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("Do = %v after %d calls, want success after 3", err, calls)
+	}
+	// 100ms、200ms，各 ±10%
+	for i, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond} {
+		if d := (*waits)[i]; d < want*9/10 || d > want*11/10 {
+			t.Errorf("wait %d = %v, want %v ±10%%", i, d, want)
+		}
+	}
+
+	calls = 0
+	err = r.Do(ctx, func() error { calls++; return dataerr.Unavailable("down") })
+	if !errors.Is(err, dataerr.ErrUnavailable) || calls != 4 {
+		t.Errorf("Do = %v after %d calls, want the last error after 4", err, calls)
+	}
+	if p := r.Policy(); p.Backoff(3) != 250*time.Millisecond {
+		t.Errorf("Backoff(3) = %v, want capped at 250ms", p.Backoff(3))
+	}
+
+	calls = 0
+	err = r.Do(ctx, func() error { calls++; return dataerr.Validation("bad query") })
+	if calls != 1 || !errors.Is(err, dataerr.ErrValidation) {
+		t.Errorf("validation errors must not be retried: %v after %d calls", err, calls)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	_ = r.Do(cancelled, func() error { calls++; return dataerr.Unavailable("down") })
+	if calls != 1 {
+		t.Errorf("a done context must stop retries, got %d calls", calls)
+	}
+}
+
+type statusError struct{ retryable bool }
+
+func (e statusError) Error() string   { return fmt.Sprintf("status (retryable=%v)", e.retryable) }
+func (e statusError) Retryable() bool { return e.retryable }
+
+func TestIsRetryable(t *testing.T) {
+	cases := map[error]bool{
+		nil:                            false,
+		dataerr.Unavailable("timeout"): true,
+		dataerr.NotFound("missing"):    false,
+		context.Canceled:               false,
+		fmt.Errorf("query: %w", context.DeadlineExceeded):               false,
+		&net.OpError{Op: "dial", Err: errors.New("connection refused")}: true,
+		fmt.Errorf("wrapped: %w", statusError{retryable: true}):         true,
+		statusError{retryable: false}:                                   false,
+	}
+	for err, want := range cases {
+		if got := IsRetryable(err); got != want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
+
+func TestPolicyWithDefaults(t *testing.T) {
+	p := Policy{MaxAttempts: 1, InitialBackoff: time.Second, MaxBackoff: time.Millisecond}.WithDefaults()
+	if p.MaxAttempts != 1 || p.MaxBackoff != time.Second || p.Multiplier != 2 || p.Jitter != 0.2 || p.Retryable == nil {
+		t.Errorf("unexpected policy %+v", p)
+	}
+	calls := 0
+	_ = New(Policy{MaxAttempts: 1}).Do(context.Background(), func() error { calls++; return dataerr.Unavailable("down") })
+	if calls != 1 {
+		t.Errorf("MaxAttempts 1 must disable retries, got %d calls", calls)
+	}
+}
@@ -0,0 +1,147 @@
+// Package storage budget.go: 将每次 Repository 查询计入请求 context 中的 API 调用方（见 querybudget），
+// 用于按调用方执行查询预算。
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/querybudget"
+)
+
+// BudgetRepository 包装 Repository：每次读写（含可选存储，见 stores）计为调用方的一次数据库查询；
+// HealthCheck 与 BeginTx 不计入。包在 DegradedRepository 之内，降级时由缓存返回的读不计入。
+type BudgetRepository struct {
+	postgres.Repository
+	stores
+}
+
+// NewBudgetRepository 包装 repo。
+func NewBudgetRepository(repo postgres.Repository) *BudgetRepository {
+	track := func(ctx context.Context) (func(), error) {
+		start := time.Now()
+		return func() { querybudget.Track(ctx, querybudget.Database, start) }, nil
+	}
+	return &BudgetRepository{Repository: repo, stores: stores{repo: repo, hook: track}}
+}
+
+// SaveCostSnapshot implements postgres.Repository.
+func (r *BudgetRepository) SaveCostSnapshot(ctx context.Context, snapshot postgres.CostSnapshot) error {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.SaveCostSnapshot(ctx, snapshot)
+}
+
+// GetCostSnapshot implements postgres.Repository.
+func (r *BudgetRepository) GetCostSnapshot(ctx context.Context, id string) (*postgres.CostSnapshot, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.GetCostSnapshot(ctx, id)
+}
+
+// ListCostSnapshots implements postgres.Repository.
+func (r *BudgetRepository) ListCostSnapshots(ctx context.Context, filter postgres.CostSnapshotFilter) ([]postgres.CostSnapshot, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.ListCostSnapshots(ctx, filter)
+}
+
+// DeleteCostSnapshot implements postgres.Repository.
+func (r *BudgetRepository) DeleteCostSnapshot(ctx context.Context, id string) error {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.DeleteCostSnapshot(ctx, id)
+}
+
+// SaveROIBaseline implements postgres.Repository.
+func (r *BudgetRepository) SaveROIBaseline(ctx context.Context, baseline postgres.ROIBaseline) error {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.SaveROIBaseline(ctx, baseline)
+}
+
+// GetROIBaseline implements postgres.Repository.
+func (r *BudgetRepository) GetROIBaseline(ctx context.Context, id string) (*postgres.ROIBaseline, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.GetROIBaseline(ctx, id)
+}
+
+// ListROIBaselines implements postgres.Repository.
+func (r *BudgetRepository) ListROIBaselines(ctx context.Context, filter postgres.ROIBaselineFilter) ([]postgres.ROIBaseline, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.ListROIBaselines(ctx, filter)
+}
+
+// DeleteROIBaseline implements postgres.Repository.
+func (r *BudgetRepository) DeleteROIBaseline(ctx context.Context, id string) error {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.DeleteROIBaseline(ctx, id)
+}
+
+// SaveDailyNamespaceCost implements postgres.Repository.
+func (r *BudgetRepository) SaveDailyNamespaceCost(ctx context.Context, cost postgres.DailyNamespaceCost) error {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.SaveDailyNamespaceCost(ctx, cost)
+}
+
+// GetDailyNamespaceCost implements postgres.Repository.
+func (r *BudgetRepository) GetDailyNamespaceCost(ctx context.Context, namespace string, date time.Time) (*postgres.DailyNamespaceCost, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.GetDailyNamespaceCost(ctx, namespace, date)
+}
+
+// ListDailyNamespaceCosts implements postgres.Repository.
+func (r *BudgetRepository) ListDailyNamespaceCosts(ctx context.Context, filter postgres.DailyNamespaceCostFilter) ([]postgres.DailyNamespaceCost, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.ListDailyNamespaceCosts(ctx, filter)
+}
+
+// AggregateDailyNamespaceCosts implements postgres.Repository.
+func (r *BudgetRepository) AggregateDailyNamespaceCosts(ctx context.Context, startDate, endDate time.Time) ([]postgres.DailyNamespaceCost, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.AggregateDailyNamespaceCosts(ctx, startDate, endDate)
+}
+
+// SaveHourlyWorkloadStat implements postgres.Repository.
+func (r *BudgetRepository) SaveHourlyWorkloadStat(ctx context.Context, stat postgres.HourlyWorkloadStat) error {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.SaveHourlyWorkloadStat(ctx, stat)
+}
+
+// GetHourlyWorkloadStat implements postgres.Repository.
+func (r *BudgetRepository) GetHourlyWorkloadStat(ctx context.Context, namespace, workloadName string, timestamp time.Time) (*postgres.HourlyWorkloadStat, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.GetHourlyWorkloadStat(ctx, namespace, workloadName, timestamp)
+}
+
+// ListHourlyWorkloadStats implements postgres.Repository.
+func (r *BudgetRepository) ListHourlyWorkloadStats(ctx context.Context, filter postgres.HourlyWorkloadStatFilter) ([]postgres.HourlyWorkloadStat, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.ListHourlyWorkloadStats(ctx, filter)
+}
+
+// AggregateHourlyWorkloadStats implements postgres.Repository.
+func (r *BudgetRepository) AggregateHourlyWorkloadStats(ctx context.Context, startTime, endTime time.Time) ([]postgres.HourlyWorkloadStat, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.AggregateHourlyWorkloadStats(ctx, startTime, endTime)
+}
+
+// SaveMetadata implements postgres.Repository.
+func (r *BudgetRepository) SaveMetadata(ctx context.Context, metadata postgres.Metadata) error {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.SaveMetadata(ctx, metadata)
+}
+
+// GetMetadata implements postgres.Repository.
+func (r *BudgetRepository) GetMetadata(ctx context.Context, key string) (*postgres.Metadata, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.GetMetadata(ctx, key)
+}
+
+// ListMetadata implements postgres.Repository.
+func (r *BudgetRepository) ListMetadata(ctx context.Context, filter postgres.MetadataFilter) ([]postgres.Metadata, error) {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.ListMetadata(ctx, filter)
+}
+
+// DeleteMetadata implements postgres.Repository.
+func (r *BudgetRepository) DeleteMetadata(ctx context.Context, key string) error {
+	defer querybudget.Track(ctx, querybudget.Database, time.Now())
+	return r.Repository.DeleteMetadata(ctx, key)
+}
@@ -0,0 +1,202 @@
+// Package storage chaos.go: 故障演练时向 Repository 的每次调用注入当前演练场景的数据库故障与延迟（见 chaos）。
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// ChaosRepository 包装 Repository：演练期间每次调用（含 HealthCheck、BeginTx 与可选存储，见 stores）先注入
+// database 故障。包在 DegradedRepository 之内，演练可触发降级模式。
+type ChaosRepository struct {
+	postgres.Repository
+	stores
+	controller *chaos.Controller
+}
+
+// NewChaosRepository 包装 repo。
+func NewChaosRepository(repo postgres.Repository, controller *chaos.Controller) *ChaosRepository {
+	inject := func(ctx context.Context) (func(), error) {
+		return func() {}, controller.Inject(ctx, chaos.Database)
+	}
+	return &ChaosRepository{Repository: repo, stores: stores{repo: repo, hook: inject}, controller: controller}
+}
+
+// SaveCostSnapshot implements postgres.Repository.
+func (r *ChaosRepository) SaveCostSnapshot(ctx context.Context, snapshot postgres.CostSnapshot) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.SaveCostSnapshot(ctx, snapshot)
+}
+
+// GetCostSnapshot implements postgres.Repository.
+func (r *ChaosRepository) GetCostSnapshot(ctx context.Context, id string) (*postgres.CostSnapshot, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetCostSnapshot(ctx, id)
+}
+
+// ListCostSnapshots implements postgres.Repository.
+func (r *ChaosRepository) ListCostSnapshots(ctx context.Context, filter postgres.CostSnapshotFilter) ([]postgres.CostSnapshot, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.ListCostSnapshots(ctx, filter)
+}
+
+// DeleteCostSnapshot implements postgres.Repository.
+func (r *ChaosRepository) DeleteCostSnapshot(ctx context.Context, id string) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.DeleteCostSnapshot(ctx, id)
+}
+
+// SaveROIBaseline implements postgres.Repository.
+func (r *ChaosRepository) SaveROIBaseline(ctx context.Context, baseline postgres.ROIBaseline) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.SaveROIBaseline(ctx, baseline)
+}
+
+// GetROIBaseline implements postgres.Repository.
+func (r *ChaosRepository) GetROIBaseline(ctx context.Context, id string) (*postgres.ROIBaseline, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetROIBaseline(ctx, id)
+}
+
+// ListROIBaselines implements postgres.Repository.
+func (r *ChaosRepository) ListROIBaselines(ctx context.Context, filter postgres.ROIBaselineFilter) ([]postgres.ROIBaseline, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.ListROIBaselines(ctx, filter)
+}
+
+// DeleteROIBaseline implements postgres.Repository.
+func (r *ChaosRepository) DeleteROIBaseline(ctx context.Context, id string) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.DeleteROIBaseline(ctx, id)
+}
+
+// SaveDailyNamespaceCost implements postgres.Repository.
+func (r *ChaosRepository) SaveDailyNamespaceCost(ctx context.Context, cost postgres.DailyNamespaceCost) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.SaveDailyNamespaceCost(ctx, cost)
+}
+
+// GetDailyNamespaceCost implements postgres.Repository.
+func (r *ChaosRepository) GetDailyNamespaceCost(ctx context.Context, namespace string, date time.Time) (*postgres.DailyNamespaceCost, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetDailyNamespaceCost(ctx, namespace, date)
+}
+
+// ListDailyNamespaceCosts implements postgres.Repository.
+func (r *ChaosRepository) ListDailyNamespaceCosts(ctx context.Context, filter postgres.DailyNamespaceCostFilter) ([]postgres.DailyNamespaceCost, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.ListDailyNamespaceCosts(ctx, filter)
+}
+
+// AggregateDailyNamespaceCosts implements postgres.Repository.
+func (r *ChaosRepository) AggregateDailyNamespaceCosts(ctx context.Context, startDate, endDate time.Time) ([]postgres.DailyNamespaceCost, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.AggregateDailyNamespaceCosts(ctx, startDate, endDate)
+}
+
+// SaveHourlyWorkloadStat implements postgres.Repository.
+func (r *ChaosRepository) SaveHourlyWorkloadStat(ctx context.Context, stat postgres.HourlyWorkloadStat) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.SaveHourlyWorkloadStat(ctx, stat)
+}
+
+// GetHourlyWorkloadStat implements postgres.Repository.
+func (r *ChaosRepository) GetHourlyWorkloadStat(ctx context.Context, namespace, workloadName string, timestamp time.Time) (*postgres.HourlyWorkloadStat, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetHourlyWorkloadStat(ctx, namespace, workloadName, timestamp)
+}
+
+// ListHourlyWorkloadStats implements postgres.Repository.
+func (r *ChaosRepository) ListHourlyWorkloadStats(ctx context.Context, filter postgres.HourlyWorkloadStatFilter) ([]postgres.HourlyWorkloadStat, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.ListHourlyWorkloadStats(ctx, filter)
+}
+
+// AggregateHourlyWorkloadStats implements postgres.Repository.
+func (r *ChaosRepository) AggregateHourlyWorkloadStats(ctx context.Context, startTime, endTime time.Time) ([]postgres.HourlyWorkloadStat, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.AggregateHourlyWorkloadStats(ctx, startTime, endTime)
+}
+
+// SaveMetadata implements postgres.Repository.
+func (r *ChaosRepository) SaveMetadata(ctx context.Context, metadata postgres.Metadata) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.SaveMetadata(ctx, metadata)
+}
+
+// GetMetadata implements postgres.Repository.
+func (r *ChaosRepository) GetMetadata(ctx context.Context, key string) (*postgres.Metadata, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetMetadata(ctx, key)
+}
+
+// ListMetadata implements postgres.Repository.
+func (r *ChaosRepository) ListMetadata(ctx context.Context, filter postgres.MetadataFilter) ([]postgres.Metadata, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.ListMetadata(ctx, filter)
+}
+
+// DeleteMetadata implements postgres.Repository.
+func (r *ChaosRepository) DeleteMetadata(ctx context.Context, key string) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.DeleteMetadata(ctx, key)
+}
+
+// HealthCheck implements postgres.Repository.
+func (r *ChaosRepository) HealthCheck(ctx context.Context) error {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return err
+	}
+	return r.Repository.HealthCheck(ctx)
+}
+
+// BeginTx implements postgres.Repository.
+func (r *ChaosRepository) BeginTx(ctx context.Context) (postgres.Transaction, error) {
+	if err := r.controller.Inject(ctx, chaos.Database); err != nil {
+		return nil, err
+	}
+	return r.Repository.BeginTx(ctx)
+}
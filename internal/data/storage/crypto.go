@@ -0,0 +1,184 @@
+// Package storage crypto.go: 字段级加密（AES-256-GCM，信封加密）。每个值使用随机数据密钥加密，
+// 数据密钥再由主密钥包装；轮换主密钥只需重新包装数据密钥，无需重新加密数据。
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// envelopePrefix 标识加密值：lhenc:v1:<主密钥ID>:<包装后的数据密钥>:<密文>（均为 base64）。
+const envelopePrefix = "lhenc:v1:"
+
+// defaultKeyID 未写密钥 ID 时使用的 ID。
+const defaultKeyID = "default"
+
+var (
+	// ErrUnknownKey 加密值的主密钥不在密钥环中（轮换时移除了仍在使用的旧密钥）。
+	ErrUnknownKey = errors.New("encryption key not in keyring")
+	// ErrNotSealed 值不是加密信封。
+	ErrNotSealed = errors.New("value is not encrypted")
+)
+
+// Keyring 主密钥环：第一个密钥用于加密，其余密钥仅用于解密与重新包装轮换前的数据。
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// ParseKeyring 解析 security.encryption 的密钥配置 "id:key[,id:key...]"，第一个为当前密钥。
+// key 为 base64 编码的 32 字节密钥，否则视为口令并取其 SHA-256；单个不带 ID 的密钥使用 ID "default"。
+func ParseKeyring(spec string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, material := defaultKeyID, entry
+		if i := strings.Index(entry, ":"); i > 0 {
+			id, material = entry[:i], entry[i+1:]
+		}
+		if material == "" {
+			return nil, fmt.Errorf("encryption key %q is empty", id)
+		}
+		if _, dup := k.keys[id]; dup {
+			return nil, fmt.Errorf("duplicate encryption key id %q", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(material)
+		if err != nil || len(key) != 32 {
+			sum := sha256.Sum256([]byte(material))
+			key = sum[:]
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+		if k.active == "" {
+			k.active = id
+		}
+	}
+	if k.active == "" {
+		return nil, errors.New("no encryption key configured")
+	}
+	return k, nil
+}
+
+// ActiveKeyID 当前用于加密的主密钥 ID。
+func (k *Keyring) ActiveKeyID() string { return k.active }
+
+// IsSealed 判断 s 是否为加密信封。
+func IsSealed(s string) bool { return strings.HasPrefix(s, envelopePrefix) }
+
+// Seal 以随机数据密钥加密 plaintext 并用当前主密钥包装数据密钥。aad 绑定值所属的行与字段，
+// 防止把一行的密文复制到另一行。
+func (k *Keyring) Seal(plaintext, aad []byte) (string, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("generate data key: %w", err)
+	}
+	data, err := newGCM(dek)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := gcmSeal(k.keys[k.active], dek, []byte(k.active))
+	if err != nil {
+		return "", err
+	}
+	payload, err := gcmSeal(data, plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + k.active + ":" + base64.StdEncoding.EncodeToString(wrapped) + ":" + base64.StdEncoding.EncodeToString(payload), nil
+}
+
+// Open 解密 Seal 生成的信封。
+func (k *Keyring) Open(envelope string, aad []byte) ([]byte, error) {
+	id, dek, payload, err := k.unwrap(envelope)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcmOpen(data, payload, aad)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt value (key %s): %w", id, err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap 用当前主密钥重新包装信封中的数据密钥，密文不变；已使用当前密钥时返回 false。
+func (k *Keyring) Rewrap(envelope string) (string, bool, error) {
+	id, dek, payload, err := k.unwrap(envelope)
+	if err != nil {
+		return "", false, err
+	}
+	if id == k.active {
+		return envelope, false, nil
+	}
+	wrapped, err := gcmSeal(k.keys[k.active], dek, []byte(k.active))
+	if err != nil {
+		return "", false, err
+	}
+	return envelopePrefix + k.active + ":" + base64.StdEncoding.EncodeToString(wrapped) + ":" + base64.StdEncoding.EncodeToString(payload), true, nil
+}
+
+// unwrap 解析信封并解开数据密钥。
+func (k *Keyring) unwrap(envelope string) (id string, dek, payload []byte, err error) {
+	if !IsSealed(envelope) {
+		return "", nil, nil, ErrNotSealed
+	}
+	parts := strings.Split(strings.TrimPrefix(envelope, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+	id = parts[0]
+	kek, ok := k.keys[id]
+	if !ok {
+		return id, nil, nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return id, nil, nil, fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	if payload, err = base64.StdEncoding.DecodeString(parts[2]); err != nil {
+		return id, nil, nil, fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	if dek, err = gcmOpen(kek, wrapped, []byte(id)); err != nil {
+		return id, nil, nil, fmt.Errorf("unwrap data key (key %s): %w", id, err)
+	}
+	return id, dek, payload, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// gcmSeal 返回 nonce || 密文。
+func gcmSeal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func gcmOpen(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], aad)
+}
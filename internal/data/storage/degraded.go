@@ -0,0 +1,369 @@
+// Package storage degraded.go: 降级模式。Repository 不可达时以只读方式返回最近一次缓存的聚合结果，
+// 并通过 context 中的 Staleness 标记响应为陈旧数据；健康检查恢复后自动退出降级。
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// ErrDegraded 降级期间写操作或无缓存的读操作返回此错误。
+var ErrDegraded = dataerr.Unavailable("repository unavailable: serving read-only cached data")
+
+// Staleness 记录一次请求中是否使用了缓存数据及其时间；由 WithStaleness 放入 context。
+type Staleness struct {
+	mu   sync.Mutex
+	asOf time.Time
+}
+
+type stalenessKey struct{}
+
+// WithStaleness 返回携带 Staleness 的 context，供 HTTP 层在响应中标记陈旧数据。
+func WithStaleness(ctx context.Context) (context.Context, *Staleness) {
+	s := &Staleness{}
+	return context.WithValue(ctx, stalenessKey{}, s), s
+}
+
+// Stale 本次请求是否返回了缓存数据。
+func (s *Staleness) Stale() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.asOf.IsZero()
+}
+
+// AsOf 返回所用缓存数据中最旧的时间。
+func (s *Staleness) AsOf() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.asOf
+}
+
+func markStale(ctx context.Context, at time.Time) {
+	s, ok := ctx.Value(stalenessKey{}).(*Staleness)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.asOf.IsZero() || at.Before(s.asOf) {
+		s.asOf = at
+	}
+}
+
+// DegradedRepository 包装 Repository：正常时透传并缓存读结果；读写失败且健康检查不通过时进入降级，
+// 降级期间不再访问底层存储（避免请求堆积），读返回缓存，写返回 ErrDegraded。
+// 聚合查询的时间窗口通常以 now 结尾，精确命中失败时返回同一方法最近一次的结果。
+type DegradedRepository struct {
+	postgres.Repository
+
+	maxEntries int
+	now        func() time.Time
+
+	degraded atomic.Bool
+	mu       sync.Mutex
+	since    time.Time // 进入降级的时间
+	cache    map[string]cacheEntry
+	latest   map[string]string // method -> 最近一次写入缓存的 key
+}
+
+type cacheEntry struct {
+	value interface{}
+	at    time.Time
+}
+
+// DegradedStatus 降级状态，供 /readyz 展示。
+type DegradedStatus struct {
+	Degraded      bool      `json:"degraded"`
+	Since         time.Time `json:"since,omitempty"`
+	CachedEntries int       `json:"cached_entries"`
+}
+
+// NewDegradedRepository 包装 repo；maxEntries<=0 时默认缓存 256 条结果。
+func NewDegradedRepository(repo postgres.Repository, maxEntries int) *DegradedRepository {
+	if maxEntries <= 0 {
+		maxEntries = 256
+	}
+	return &DegradedRepository{
+		Repository: repo,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		cache:      make(map[string]cacheEntry),
+		latest:     make(map[string]string),
+	}
+}
+
+// Degraded 是否处于降级模式。
+func (r *DegradedRepository) Degraded() bool { return r.degraded.Load() }
+
+// Status 返回降级状态。
+func (r *DegradedRepository) Status() DegradedStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := DegradedStatus{Degraded: r.degraded.Load(), CachedEntries: len(r.cache)}
+	if st.Degraded {
+		st.Since = r.since
+	}
+	return st
+}
+
+// HealthCheck 检查底层存储并据此进入或退出降级模式。
+func (r *DegradedRepository) HealthCheck(ctx context.Context) error {
+	err := r.Repository.HealthCheck(ctx)
+	r.setDegraded(err != nil)
+	return err
+}
+
+// Run 每 interval 执行一次健康检查，直到 ctx 结束；降级后据此自动恢复。
+func (r *DegradedRepository) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = r.HealthCheck(ctx)
+		}
+	}
+}
+
+func (r *DegradedRepository) setDegraded(v bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v && !r.degraded.Load() {
+		r.since = r.now()
+	}
+	r.degraded.Store(v)
+}
+
+// checkFailure 在调用失败后确认存储是否不可达；not found、校验失败等已分类的业务错误不触发检查。
+func (r *DegradedRepository) checkFailure(ctx context.Context, err error) {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	if kind := dataerr.Kind(err); kind != nil && kind != dataerr.ErrUnavailable {
+		return
+	}
+	_ = r.HealthCheck(ctx)
+}
+
+func (r *DegradedRepository) store(method, key string, v interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cache[key]; !ok && len(r.cache) >= r.maxEntries {
+		r.evictOldest()
+	}
+	r.cache[key] = cacheEntry{value: v, at: r.now()}
+	r.latest[method] = key
+}
+
+// evictOldest 删除最旧的缓存条目，但保留各方法的最近结果。调用方持有 r.mu。
+func (r *DegradedRepository) evictOldest() {
+	keep := make(map[string]bool, len(r.latest))
+	for _, k := range r.latest {
+		keep[k] = true
+	}
+	oldest := ""
+	for k, e := range r.cache {
+		if keep[k] {
+			continue
+		}
+		if oldest == "" || e.at.Before(r.cache[oldest].at) {
+			oldest = k
+		}
+	}
+	if oldest != "" {
+		delete(r.cache, oldest)
+	}
+}
+
+func (r *DegradedRepository) lookup(method, key string, latest bool) (cacheEntry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.cache[key]; ok {
+		return e, true
+	}
+	if latest {
+		if e, ok := r.cache[r.latest[method]]; ok {
+			return e, true
+		}
+	}
+	return cacheEntry{}, false
+}
+
+// cachedRead 执行读操作：正常时透传并缓存；降级或失败后存储不可达时返回缓存并标记陈旧。
+// latest 为 true 时精确 key 未命中可返回该方法最近一次的结果（用于以 now 结尾的聚合窗口）。
+func cachedRead[T any](ctx context.Context, r *DegradedRepository, method string, latest bool, args []interface{}, fn func() (T, error)) (T, error) {
+	key := fmt.Sprintf("%s%v", method, args)
+	if !r.Degraded() {
+		v, err := fn()
+		if err == nil {
+			r.store(method, key, v)
+			return v, nil
+		}
+		r.checkFailure(ctx, err)
+		if !r.Degraded() {
+			return v, err
+		}
+	}
+	if e, ok := r.lookup(method, key, latest); ok {
+		markStale(ctx, e.at)
+		return e.value.(T), nil
+	}
+	var zero T
+	return zero, fmt.Errorf("%s: %w", method, ErrDegraded)
+}
+
+// write 执行写操作：降级期间直接拒绝。
+func (r *DegradedRepository) write(ctx context.Context, method string, fn func() error) error {
+	if r.Degraded() {
+		return fmt.Errorf("%s: %w", method, ErrDegraded)
+	}
+	err := fn()
+	r.checkFailure(ctx, err)
+	return err
+}
+
+// SaveCostSnapshot implements postgres.Repository.
+func (r *DegradedRepository) SaveCostSnapshot(ctx context.Context, snapshot postgres.CostSnapshot) error {
+	return r.write(ctx, "SaveCostSnapshot", func() error { return r.Repository.SaveCostSnapshot(ctx, snapshot) })
+}
+
+// GetCostSnapshot implements postgres.Repository.
+func (r *DegradedRepository) GetCostSnapshot(ctx context.Context, id string) (*postgres.CostSnapshot, error) {
+	return cachedRead(ctx, r, "GetCostSnapshot", false, []interface{}{id}, func() (*postgres.CostSnapshot, error) {
+		return r.Repository.GetCostSnapshot(ctx, id)
+	})
+}
+
+// ListCostSnapshots implements postgres.Repository.
+func (r *DegradedRepository) ListCostSnapshots(ctx context.Context, filter postgres.CostSnapshotFilter) ([]postgres.CostSnapshot, error) {
+	return cachedRead(ctx, r, "ListCostSnapshots", false, []interface{}{filter}, func() ([]postgres.CostSnapshot, error) {
+		return r.Repository.ListCostSnapshots(ctx, filter)
+	})
+}
+
+// DeleteCostSnapshot implements postgres.Repository.
+func (r *DegradedRepository) DeleteCostSnapshot(ctx context.Context, id string) error {
+	return r.write(ctx, "DeleteCostSnapshot", func() error { return r.Repository.DeleteCostSnapshot(ctx, id) })
+}
+
+// SaveROIBaseline implements postgres.Repository.
+func (r *DegradedRepository) SaveROIBaseline(ctx context.Context, baseline postgres.ROIBaseline) error {
+	return r.write(ctx, "SaveROIBaseline", func() error { return r.Repository.SaveROIBaseline(ctx, baseline) })
+}
+
+// GetROIBaseline implements postgres.Repository.
+func (r *DegradedRepository) GetROIBaseline(ctx context.Context, id string) (*postgres.ROIBaseline, error) {
+	return cachedRead(ctx, r, "GetROIBaseline", false, []interface{}{id}, func() (*postgres.ROIBaseline, error) {
+		return r.Repository.GetROIBaseline(ctx, id)
+	})
+}
+
+// ListROIBaselines implements postgres.Repository.
+func (r *DegradedRepository) ListROIBaselines(ctx context.Context, filter postgres.ROIBaselineFilter) ([]postgres.ROIBaseline, error) {
+	return cachedRead(ctx, r, "ListROIBaselines", false, []interface{}{filter}, func() ([]postgres.ROIBaseline, error) {
+		return r.Repository.ListROIBaselines(ctx, filter)
+	})
+}
+
+// DeleteROIBaseline implements postgres.Repository.
+func (r *DegradedRepository) DeleteROIBaseline(ctx context.Context, id string) error {
+	return r.write(ctx, "DeleteROIBaseline", func() error { return r.Repository.DeleteROIBaseline(ctx, id) })
+}
+
+// SaveDailyNamespaceCost implements postgres.Repository.
+func (r *DegradedRepository) SaveDailyNamespaceCost(ctx context.Context, cost postgres.DailyNamespaceCost) error {
+	return r.write(ctx, "SaveDailyNamespaceCost", func() error { return r.Repository.SaveDailyNamespaceCost(ctx, cost) })
+}
+
+// GetDailyNamespaceCost implements postgres.Repository.
+func (r *DegradedRepository) GetDailyNamespaceCost(ctx context.Context, namespace string, date time.Time) (*postgres.DailyNamespaceCost, error) {
+	return cachedRead(ctx, r, "GetDailyNamespaceCost", false, []interface{}{namespace, date.Unix()}, func() (*postgres.DailyNamespaceCost, error) {
+		return r.Repository.GetDailyNamespaceCost(ctx, namespace, date)
+	})
+}
+
+// ListDailyNamespaceCosts implements postgres.Repository.
+func (r *DegradedRepository) ListDailyNamespaceCosts(ctx context.Context, filter postgres.DailyNamespaceCostFilter) ([]postgres.DailyNamespaceCost, error) {
+	return cachedRead(ctx, r, "ListDailyNamespaceCosts", false, []interface{}{filter}, func() ([]postgres.DailyNamespaceCost, error) {
+		return r.Repository.ListDailyNamespaceCosts(ctx, filter)
+	})
+}
+
+// AggregateDailyNamespaceCosts implements postgres.Repository.
+func (r *DegradedRepository) AggregateDailyNamespaceCosts(ctx context.Context, startDate, endDate time.Time) ([]postgres.DailyNamespaceCost, error) {
+	return cachedRead(ctx, r, "AggregateDailyNamespaceCosts", true, []interface{}{startDate.Unix(), endDate.Unix()}, func() ([]postgres.DailyNamespaceCost, error) {
+		return r.Repository.AggregateDailyNamespaceCosts(ctx, startDate, endDate)
+	})
+}
+
+// SaveHourlyWorkloadStat implements postgres.Repository.
+func (r *DegradedRepository) SaveHourlyWorkloadStat(ctx context.Context, stat postgres.HourlyWorkloadStat) error {
+	return r.write(ctx, "SaveHourlyWorkloadStat", func() error { return r.Repository.SaveHourlyWorkloadStat(ctx, stat) })
+}
+
+// GetHourlyWorkloadStat implements postgres.Repository.
+func (r *DegradedRepository) GetHourlyWorkloadStat(ctx context.Context, namespace, workloadName string, timestamp time.Time) (*postgres.HourlyWorkloadStat, error) {
+	return cachedRead(ctx, r, "GetHourlyWorkloadStat", false, []interface{}{namespace, workloadName, timestamp.Unix()}, func() (*postgres.HourlyWorkloadStat, error) {
+		return r.Repository.GetHourlyWorkloadStat(ctx, namespace, workloadName, timestamp)
+	})
+}
+
+// ListHourlyWorkloadStats implements postgres.Repository.
+func (r *DegradedRepository) ListHourlyWorkloadStats(ctx context.Context, filter postgres.HourlyWorkloadStatFilter) ([]postgres.HourlyWorkloadStat, error) {
+	return cachedRead(ctx, r, "ListHourlyWorkloadStats", false, []interface{}{filter}, func() ([]postgres.HourlyWorkloadStat, error) {
+		return r.Repository.ListHourlyWorkloadStats(ctx, filter)
+	})
+}
+
+// AggregateHourlyWorkloadStats implements postgres.Repository.
+func (r *DegradedRepository) AggregateHourlyWorkloadStats(ctx context.Context, startTime, endTime time.Time) ([]postgres.HourlyWorkloadStat, error) {
+	return cachedRead(ctx, r, "AggregateHourlyWorkloadStats", true, []interface{}{startTime.Unix(), endTime.Unix()}, func() ([]postgres.HourlyWorkloadStat, error) {
+		return r.Repository.AggregateHourlyWorkloadStats(ctx, startTime, endTime)
+	})
+}
+
+// SaveMetadata implements postgres.Repository.
+func (r *DegradedRepository) SaveMetadata(ctx context.Context, metadata postgres.Metadata) error {
+	return r.write(ctx, "SaveMetadata", func() error { return r.Repository.SaveMetadata(ctx, metadata) })
+}
+
+// GetMetadata implements postgres.Repository.
+func (r *DegradedRepository) GetMetadata(ctx context.Context, key string) (*postgres.Metadata, error) {
+	return cachedRead(ctx, r, "GetMetadata", false, []interface{}{key}, func() (*postgres.Metadata, error) {
+		return r.Repository.GetMetadata(ctx, key)
+	})
+}
+
+// ListMetadata implements postgres.Repository.
+func (r *DegradedRepository) ListMetadata(ctx context.Context, filter postgres.MetadataFilter) ([]postgres.Metadata, error) {
+	return cachedRead(ctx, r, "ListMetadata", false, []interface{}{filter}, func() ([]postgres.Metadata, error) {
+		return r.Repository.ListMetadata(ctx, filter)
+	})
+}
+
+// DeleteMetadata implements postgres.Repository.
+func (r *DegradedRepository) DeleteMetadata(ctx context.Context, key string) error {
+	return r.write(ctx, "DeleteMetadata", func() error { return r.Repository.DeleteMetadata(ctx, key) })
+}
+
+// BeginTx implements postgres.Repository; transactions are rejected while degraded.
+func (r *DegradedRepository) BeginTx(ctx context.Context) (postgres.Transaction, error) {
+	if r.Degraded() {
+		return nil, fmt.Errorf("BeginTx: %w", ErrDegraded)
+	}
+	tx, err := r.Repository.BeginTx(ctx)
+	r.checkFailure(ctx, err)
+	return tx, err
+}
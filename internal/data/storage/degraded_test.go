@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+func newMock(errorRate float64) *postgres.MockRepository {
+	cfg := postgres.DefaultMockConfig()
+	cfg.LatencyMs = 0
+	cfg.ErrorRate = errorRate
+	return postgres.NewMockRepository(cfg)
+}
+
+func TestDegradedRepositoryServesCacheWhenDown(t *testing.T) {
+	ctx := context.Background()
+	healthy := newMock(0)
+	repo := NewDegradedRepository(healthy, 0)
+
+	end := time.Now()
+	want, err := repo.AggregateHourlyWorkloadStats(ctx, end.Add(-24*time.Hour), end)
+	if err != nil {
+		t.Fatalf("AggregateHourlyWorkloadStats: %v", err)
+	}
+	if st := repo.Status(); st.Degraded || st.CachedEntries != 1 {
+		t.Fatalf("unexpected status after healthy read: %+v", st)
+	}
+
+	// 存储不可达：首次失败触发健康检查进入降级，聚合窗口不同也返回最近一次结果。
+	repo.Repository = newMock(1)
+	sctx, staleness := WithStaleness(ctx)
+	later := end.Add(time.Minute)
+	got, err := repo.AggregateHourlyWorkloadStats(sctx, later.Add(-24*time.Hour), later)
+	if err != nil {
+		t.Fatalf("expected cached result while degraded, got %v", err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("cached result has %d rows, want %d", len(got), len(want))
+	}
+	if !repo.Degraded() {
+		t.Fatal("expected degraded mode")
+	}
+	if !staleness.Stale() || staleness.AsOf().IsZero() {
+		t.Error("expected response to be marked stale")
+	}
+
+	if _, err := repo.GetCostSnapshot(ctx, "missing"); !errors.Is(err, ErrDegraded) {
+		t.Errorf("uncached read: expected ErrDegraded, got %v", err)
+	}
+	if err := repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "ns"}); !errors.Is(err, ErrDegraded) {
+		t.Errorf("write: expected ErrDegraded, got %v", err)
+	}
+	if _, err := repo.BeginTx(ctx); !errors.Is(err, ErrDegraded) {
+		t.Errorf("BeginTx: expected ErrDegraded, got %v", err)
+	}
+
+	// 恢复后健康检查退出降级，读取不再标记陈旧。
+	repo.Repository = healthy
+	if err := repo.HealthCheck(ctx); err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+	if repo.Degraded() {
+		t.Fatal("expected recovery after successful health check")
+	}
+	fresh, staleness := WithStaleness(ctx)
+	if _, err := repo.AggregateHourlyWorkloadStats(fresh, end.Add(-24*time.Hour), end); err != nil {
+		t.Fatalf("read after recovery: %v", err)
+	}
+	if staleness.Stale() {
+		t.Error("fresh read should not be marked stale")
+	}
+}
+
+func TestDegradedRepositoryPassesBusinessErrors(t *testing.T) {
+	ctx := context.Background()
+	repo := NewDegradedRepository(newMock(0), 0)
+	if _, err := repo.GetCostSnapshot(ctx, "missing"); !errors.Is(err, dataerr.ErrNotFound) || errors.Is(err, ErrDegraded) {
+		t.Errorf("expected not-found error from the underlying repository, got %v", err)
+	}
+	if !errors.Is(ErrDegraded, dataerr.ErrUnavailable) {
+		t.Error("ErrDegraded should be classified as unavailable")
+	}
+	if repo.Degraded() {
+		t.Error("a not-found error must not enter degraded mode")
+	}
+}
+
+func TestDegradedRepositoryEvictsOldest(t *testing.T) {
+	ctx := context.Background()
+	repo := NewDegradedRepository(newMock(0), 2)
+	for _, key := range []string{"a", "b", "c"} {
+		_ = repo.SaveMetadata(ctx, postgres.Metadata{Key: key, Value: map[string]interface{}{"v": key}})
+		if _, err := repo.GetMetadata(ctx, key); err != nil {
+			t.Fatalf("GetMetadata(%s): %v", key, err)
+		}
+	}
+	if n := repo.Status().CachedEntries; n != 2 {
+		t.Errorf("expected cache bounded to 2 entries, got %d", n)
+	}
+}
+
+func TestChaosRepositoryDrivesDegradedMode(t *testing.T) {
+	ctx := context.Background()
+	drills := chaos.NewController(map[string]chaos.Scenario{"database-outage": {chaos.Database: {ErrorRate: 1}}}, time.Hour)
+	repo := NewDegradedRepository(NewChaosRepository(newMock(0), drills), 0)
+
+	end := time.Now()
+	if _, err := repo.AggregateHourlyWorkloadStats(ctx, end.Add(-24*time.Hour), end); err != nil {
+		t.Fatalf("AggregateHourlyWorkloadStats outside a drill: %v", err)
+	}
+
+	if _, err := drills.Start("database-outage", time.Minute, "ops"); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	sctx, staleness := WithStaleness(ctx)
+	if _, err := repo.AggregateHourlyWorkloadStats(sctx, end.Add(-24*time.Hour), end); err != nil {
+		t.Fatalf("expected cached result during the drill, got %v", err)
+	}
+	if !repo.Degraded() || !staleness.Stale() {
+		t.Fatal("expected the drill to put the repository in degraded mode")
+	}
+
+	drills.Stop()
+	if err := repo.HealthCheck(ctx); err != nil || repo.Degraded() {
+		t.Errorf("after Stop: HealthCheck = %v, degraded = %v", err, repo.Degraded())
+	}
+}
@@ -0,0 +1,300 @@
+// Package storage encrypted.go: 在 Repository 层透明地加密敏感字段（metadata 值、云账单汇总金额），
+// 读取时解密；未加密的历史行照常返回，Migrate 将其加密并把旧主密钥的数据重新包装到当前密钥。
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// sealedValueKey 加密后 metadata.value 中唯一的键，值为加密信封。
+const sealedValueKey = "__sealed"
+
+// ErrBillStoreUnsupported 底层 Repository 不支持云账单汇总。
+var ErrBillStoreUnsupported = errors.New("repository does not store bill account summaries")
+
+// BillSummaryStore 云账单汇总存储；*postgres.MockRepository 满足此接口。
+type BillSummaryStore interface {
+	SaveBillAccountSummary(ctx context.Context, s postgres.BillAccountSummary) error
+	GetBillAccountSummary(ctx context.Context, accountID, periodType string, periodStart time.Time) (*postgres.BillAccountSummary, error)
+	ListBillAccountSummaries(ctx context.Context, accountID string) ([]postgres.BillAccountSummary, error)
+}
+
+// EncryptedRepository 包装 Repository：SaveMetadata 加密整个 Value，云账单汇总的 TotalAmount/ByCategory
+// 加密到 Sealed 并清零明文列；其余方法（含可选存储，见 stores）透传。密文绑定所属行（AAD），复制到其他行无法解密。
+type EncryptedRepository struct {
+	postgres.Repository
+	stores
+	keys *Keyring
+}
+
+// NewEncryptedRepository 创建 EncryptedRepository。
+func NewEncryptedRepository(repo postgres.Repository, keys *Keyring) *EncryptedRepository {
+	return &EncryptedRepository{Repository: repo, stores: stores{repo: repo}, keys: keys}
+}
+
+// SaveMetadata implements postgres.Repository.
+func (r *EncryptedRepository) SaveMetadata(ctx context.Context, m postgres.Metadata) error {
+	sealed, err := r.sealMetadata(m)
+	if err != nil {
+		return err
+	}
+	return r.Repository.SaveMetadata(ctx, sealed)
+}
+
+// GetMetadata implements postgres.Repository.
+func (r *EncryptedRepository) GetMetadata(ctx context.Context, key string) (*postgres.Metadata, error) {
+	m, err := r.Repository.GetMetadata(ctx, key)
+	if err != nil || m == nil {
+		return m, err
+	}
+	opened, err := r.openMetadata(*m)
+	if err != nil {
+		return nil, err
+	}
+	return &opened, nil
+}
+
+// ListMetadata implements postgres.Repository.
+func (r *EncryptedRepository) ListMetadata(ctx context.Context, filter postgres.MetadataFilter) ([]postgres.Metadata, error) {
+	list, err := r.Repository.ListMetadata(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i], err = r.openMetadata(list[i]); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// BeginTx implements postgres.Repository; the transaction's repository encrypts as well.
+func (r *EncryptedRepository) BeginTx(ctx context.Context) (postgres.Transaction, error) {
+	tx, err := r.Repository.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return encryptedTx{Transaction: tx, keys: r.keys}, nil
+}
+
+type encryptedTx struct {
+	postgres.Transaction
+	keys *Keyring
+}
+
+func (tx encryptedTx) Repository() postgres.Repository {
+	return NewEncryptedRepository(tx.Transaction.Repository(), tx.keys)
+}
+
+// SaveBillAccountSummary 加密金额后写入。
+func (r *EncryptedRepository) SaveBillAccountSummary(ctx context.Context, s postgres.BillAccountSummary) error {
+	bills, err := r.bills()
+	if err != nil {
+		return err
+	}
+	sealed, err := r.sealBill(s)
+	if err != nil {
+		return err
+	}
+	return bills.SaveBillAccountSummary(ctx, sealed)
+}
+
+// GetBillAccountSummary 读取并解密。
+func (r *EncryptedRepository) GetBillAccountSummary(ctx context.Context, accountID, periodType string, periodStart time.Time) (*postgres.BillAccountSummary, error) {
+	bills, err := r.bills()
+	if err != nil {
+		return nil, err
+	}
+	s, err := bills.GetBillAccountSummary(ctx, accountID, periodType, periodStart)
+	if err != nil || s == nil {
+		return s, err
+	}
+	opened, err := r.openBill(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &opened, nil
+}
+
+// ListBillAccountSummaries 读取并解密。
+func (r *EncryptedRepository) ListBillAccountSummaries(ctx context.Context, accountID string) ([]postgres.BillAccountSummary, error) {
+	bills, err := r.bills()
+	if err != nil {
+		return nil, err
+	}
+	list, err := bills.ListBillAccountSummaries(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i], err = r.openBill(list[i]); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// MigrationReport 一次 Migrate 的结果。
+type MigrationReport struct {
+	MetadataSealed    int `json:"metadata_sealed"`    // 明文行被加密
+	MetadataRewrapped int `json:"metadata_rewrapped"` // 旧主密钥的行重新包装到当前密钥
+	BillsSealed       int `json:"bills_sealed"`
+	BillsRewrapped    int `json:"bills_rewrapped"`
+}
+
+// Migrate 加密所有明文的 metadata 与云账单汇总，并把仍由旧主密钥包装的行重新包装到当前密钥。
+// 可重复执行；完成后即可从密钥环中移除旧密钥。底层不支持云账单汇总时只处理 metadata。
+func (r *EncryptedRepository) Migrate(ctx context.Context) (*MigrationReport, error) {
+	report := &MigrationReport{}
+	list, err := r.Repository.ListMetadata(ctx, postgres.MetadataFilter{})
+	if err != nil {
+		return report, fmt.Errorf("list metadata: %w", err)
+	}
+	for _, m := range list {
+		envelope, sealed := sealedMetadataValue(m)
+		if !sealed {
+			if err := r.SaveMetadata(ctx, m); err != nil {
+				return report, fmt.Errorf("encrypt metadata %s: %w", m.Key, err)
+			}
+			report.MetadataSealed++
+			continue
+		}
+		rewrapped, changed, err := r.keys.Rewrap(envelope)
+		if err != nil {
+			return report, fmt.Errorf("rewrap metadata %s: %w", m.Key, err)
+		}
+		if changed {
+			m.Value = map[string]interface{}{sealedValueKey: rewrapped}
+			if err := r.Repository.SaveMetadata(ctx, m); err != nil {
+				return report, fmt.Errorf("rewrap metadata %s: %w", m.Key, err)
+			}
+			report.MetadataRewrapped++
+		}
+	}
+
+	bills, err := r.bills()
+	if err != nil {
+		return report, nil
+	}
+	summaries, err := bills.ListBillAccountSummaries(ctx, "")
+	if err != nil {
+		return report, fmt.Errorf("list bill account summaries: %w", err)
+	}
+	for _, s := range summaries {
+		if s.Sealed == "" {
+			if err := r.SaveBillAccountSummary(ctx, s); err != nil {
+				return report, fmt.Errorf("encrypt bill account summary %s: %w", s.AccountID, err)
+			}
+			report.BillsSealed++
+			continue
+		}
+		rewrapped, changed, err := r.keys.Rewrap(s.Sealed)
+		if err != nil {
+			return report, fmt.Errorf("rewrap bill account summary %s: %w", s.AccountID, err)
+		}
+		if changed {
+			s.Sealed = rewrapped
+			if err := bills.SaveBillAccountSummary(ctx, s); err != nil {
+				return report, fmt.Errorf("rewrap bill account summary %s: %w", s.AccountID, err)
+			}
+			report.BillsRewrapped++
+		}
+	}
+	return report, nil
+}
+
+func (r *EncryptedRepository) bills() (BillSummaryStore, error) {
+	bills, ok := r.Repository.(BillSummaryStore)
+	if !ok {
+		return nil, ErrBillStoreUnsupported
+	}
+	return bills, nil
+}
+
+func metadataAAD(key string) []byte { return []byte("metadata:" + key) }
+
+func billAAD(s postgres.BillAccountSummary) []byte {
+	return []byte(fmt.Sprintf("bill:%s|%s|%d", s.AccountID, s.PeriodType, s.PeriodStart.Unix()))
+}
+
+// sealedMetadataValue 返回已加密 Value 的信封。
+func sealedMetadataValue(m postgres.Metadata) (string, bool) {
+	if len(m.Value) != 1 {
+		return "", false
+	}
+	envelope, ok := m.Value[sealedValueKey].(string)
+	return envelope, ok && IsSealed(envelope)
+}
+
+func (r *EncryptedRepository) sealMetadata(m postgres.Metadata) (postgres.Metadata, error) {
+	if _, sealed := sealedMetadataValue(m); sealed {
+		return m, nil
+	}
+	plaintext, err := json.Marshal(m.Value)
+	if err != nil {
+		return m, fmt.Errorf("encrypt metadata %s: %w", m.Key, err)
+	}
+	envelope, err := r.keys.Seal(plaintext, metadataAAD(m.Key))
+	if err != nil {
+		return m, fmt.Errorf("encrypt metadata %s: %w", m.Key, err)
+	}
+	m.Value = map[string]interface{}{sealedValueKey: envelope}
+	return m, nil
+}
+
+func (r *EncryptedRepository) openMetadata(m postgres.Metadata) (postgres.Metadata, error) {
+	envelope, sealed := sealedMetadataValue(m)
+	if !sealed {
+		return m, nil // 迁移前的明文行
+	}
+	plaintext, err := r.keys.Open(envelope, metadataAAD(m.Key))
+	if err != nil {
+		return m, fmt.Errorf("decrypt metadata %s: %w", m.Key, err)
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(plaintext, &value); err != nil {
+		return m, fmt.Errorf("decrypt metadata %s: %w", m.Key, err)
+	}
+	m.Value = value
+	return m, nil
+}
+
+// billSecret 云账单汇总中加密的字段。
+type billSecret struct {
+	TotalAmount float64            `json:"total_amount"`
+	ByCategory  map[string]float64 `json:"by_category"`
+}
+
+func (r *EncryptedRepository) sealBill(s postgres.BillAccountSummary) (postgres.BillAccountSummary, error) {
+	plaintext, err := json.Marshal(billSecret{TotalAmount: s.TotalAmount, ByCategory: s.ByCategory})
+	if err != nil {
+		return s, fmt.Errorf("encrypt bill account summary %s: %w", s.AccountID, err)
+	}
+	if s.Sealed, err = r.keys.Seal(plaintext, billAAD(s)); err != nil {
+		return s, fmt.Errorf("encrypt bill account summary %s: %w", s.AccountID, err)
+	}
+	s.TotalAmount, s.ByCategory = 0, nil
+	return s, nil
+}
+
+func (r *EncryptedRepository) openBill(s postgres.BillAccountSummary) (postgres.BillAccountSummary, error) {
+	if s.Sealed == "" {
+		return s, nil // 迁移前的明文行
+	}
+	plaintext, err := r.keys.Open(s.Sealed, billAAD(s))
+	if err != nil {
+		return s, fmt.Errorf("decrypt bill account summary %s: %w", s.AccountID, err)
+	}
+	var secret billSecret
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return s, fmt.Errorf("decrypt bill account summary %s: %w", s.AccountID, err)
+	}
+	s.TotalAmount, s.ByCategory, s.Sealed = secret.TotalAmount, secret.ByCategory, ""
+	return s, nil
+}
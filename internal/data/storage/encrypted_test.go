@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+func TestKeyringSealOpenRewrap(t *testing.T) {
+	old, err := ParseKeyring("k1:old-passphrase")
+	if err != nil {
+		t.Fatalf("ParseKeyring: %v", err)
+	}
+	envelope, err := old.Seal([]byte("secret"), []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(envelope) || strings.Contains(envelope, "secret") {
+		t.Fatalf("unexpected envelope %q", envelope)
+	}
+	if _, err := old.Open(envelope, []byte("row-2")); err == nil {
+		t.Error("a ciphertext moved to another row must not decrypt")
+	}
+
+	// 轮换：新密钥在前，旧密钥保留用于解密与重新包装
+	rotated, err := ParseKeyring("k2:AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=,k1:old-passphrase")
+	if err != nil {
+		t.Fatalf("ParseKeyring: %v", err)
+	}
+	if got, err := rotated.Open(envelope, []byte("row-1")); err != nil || string(got) != "secret" {
+		t.Fatalf("Open with rotated keyring = %q, %v", got, err)
+	}
+	rewrapped, changed, err := rotated.Rewrap(envelope)
+	if err != nil || !changed || !strings.HasPrefix(rewrapped, envelopePrefix+"k2:") {
+		t.Fatalf("Rewrap = %q, %v, %v", rewrapped, changed, err)
+	}
+	if _, changed, _ := rotated.Rewrap(rewrapped); changed {
+		t.Error("an envelope of the active key should not be rewrapped again")
+	}
+	newOnly, _ := ParseKeyring("k2:AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	if got, err := newOnly.Open(rewrapped, []byte("row-1")); err != nil || string(got) != "secret" {
+		t.Errorf("Open after removing the old key = %q, %v", got, err)
+	}
+	if _, err := newOnly.Open(envelope, []byte("row-1")); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Open of a not rewrapped value error = %v, want ErrUnknownKey", err)
+	}
+	if _, err := ParseKeyring(" , "); err == nil {
+		t.Error("an empty keyring should be rejected")
+	}
+}
+
+func TestEncryptedRepositoryMigratesAndRotates(t *testing.T) {
+	ctx := context.Background()
+	cfg := postgres.DefaultMockConfig()
+	cfg.LatencyMs = 0
+	cfg.Scenario = "empty"
+	base := postgres.NewMockRepository(cfg)
+	period := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// 启用加密前写入的明文行
+	if err := base.SaveMetadata(ctx, postgres.Metadata{Key: "cloud/credentials", Value: map[string]interface{}{"token": "t0p"}}); err != nil {
+		t.Fatalf("SaveMetadata: %v", err)
+	}
+	if err := base.SaveBillAccountSummary(ctx, postgres.BillAccountSummary{AccountID: "acct", PeriodType: "month", PeriodStart: period,
+		TotalAmount: 1234.5, ByCategory: map[string]float64{"compute": 1000}}); err != nil {
+		t.Fatalf("SaveBillAccountSummary: %v", err)
+	}
+
+	k1, _ := ParseKeyring("k1:first")
+	repo := NewEncryptedRepository(base, k1)
+	if m, err := repo.GetMetadata(ctx, "cloud/credentials"); err != nil || m.Value["token"] != "t0p" {
+		t.Fatalf("plaintext rows must stay readable before migration: %+v, %v", m, err)
+	}
+	report, err := repo.Migrate(ctx)
+	if err != nil || report.MetadataSealed != 1 || report.BillsSealed != 1 {
+		t.Fatalf("Migrate = %+v, %v", report, err)
+	}
+
+	stored, _ := base.GetMetadata(ctx, "cloud/credentials")
+	if _, ok := stored.Value["token"]; ok {
+		t.Errorf("metadata stored in plaintext after migration: %v", stored.Value)
+	}
+	storedBill, _ := base.GetBillAccountSummary(ctx, "acct", "month", period)
+	if storedBill.TotalAmount != 0 || storedBill.ByCategory != nil || !IsSealed(storedBill.Sealed) {
+		t.Errorf("bill summary stored in plaintext after migration: %+v", storedBill)
+	}
+	bills, err := repo.ListBillAccountSummaries(ctx, "acct")
+	if err != nil || len(bills) != 1 || bills[0].TotalAmount != 1234.5 || bills[0].ByCategory["compute"] != 1000 || bills[0].Sealed != "" {
+		t.Fatalf("ListBillAccountSummaries = %+v, %v", bills, err)
+	}
+
+	// 事务中的写入同样加密
+	tx, _ := repo.BeginTx(ctx)
+	if err := tx.Repository().SaveMetadata(ctx, postgres.Metadata{Key: "tx/secret", Value: map[string]interface{}{"v": "x"}}); err != nil {
+		t.Fatalf("SaveMetadata in transaction: %v", err)
+	}
+	_ = tx.Commit()
+	if stored, _ := base.GetMetadata(ctx, "tx/secret"); stored.Value["v"] != nil {
+		t.Errorf("transaction wrote plaintext metadata: %v", stored.Value)
+	}
+
+	// 轮换到 k2 后重新包装，之后仅用 k2 即可读取
+	k2, _ := ParseKeyring("k2:second,k1:first")
+	report, err = NewEncryptedRepository(base, k2).Migrate(ctx)
+	if err != nil || report.MetadataRewrapped != 2 || report.BillsRewrapped != 1 || report.MetadataSealed != 0 {
+		t.Fatalf("Migrate after rotation = %+v, %v", report, err)
+	}
+	k2Only, _ := ParseKeyring("k2:second")
+	list, err := NewEncryptedRepository(base, k2Only).ListMetadata(ctx, postgres.MetadataFilter{KeyPrefix: "cloud/"})
+	if err != nil || len(list) != 1 || list[0].Value["token"] != "t0p" {
+		t.Errorf("ListMetadata with the new key only = %+v, %v", list, err)
+	}
+}
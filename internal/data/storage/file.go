@@ -0,0 +1,208 @@
+// Package storage file.go: 嵌入式单文件驱动。数据常驻内存，写入后原子地整体落盘为 JSON，
+// 适合数据量小、无外部数据库的边缘集群。设置落盘间隔后，间隔内的写入合并为一次落盘。
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// FileRepository 持久化到单个 JSON 文件的 Repository。读操作直接走内存；
+// 写操作（含事务提交与各可选存储的写入）成功后整体重写文件，内容为 postgres.MockState。
+// 每次重写的代价与数据总量成正比，逐条写入大量记录时应设置落盘间隔（SetFlushInterval）。
+type FileRepository struct {
+	*postgres.MockRepository
+	path string
+	mu   sync.Mutex
+
+	interval time.Duration // <=0 每次写入同步落盘
+	dirty    bool          // 内存中有尚未落盘的写入
+	timer    *time.Timer   // 已安排的合并落盘
+	flushErr error         // 最近一次后台落盘的错误
+}
+
+// OpenFile 打开（或创建）path 处的数据文件；默认每次写入同步落盘。
+func OpenFile(ctx context.Context, path string) (*FileRepository, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file storage: path (dsn) is required")
+	}
+	r := &FileRepository{MockRepository: newMemoryRepository(), path: path}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file storage: read %s: %w", path, err)
+	}
+	var state postgres.MockState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, fmt.Errorf("file storage: decode %s: %w", path, err)
+	}
+	if len(state.SnapshotHashChain) == 0 && len(state.CostSnapshots) > 0 {
+		// 早期的数据文件没有哈希链：重放快照的保存以重建哈希链
+		snapshots := state.CostSnapshots
+		state.CostSnapshots = nil
+		r.MockRepository.Restore(state)
+		for _, v := range snapshots {
+			if err := r.MockRepository.SaveCostSnapshot(ctx, v); err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
+	}
+	r.MockRepository.Restore(state)
+	return r, nil
+}
+
+// SetFlushInterval 合并落盘：写入只更新内存，首个未落盘的写入之后 interval 内的写入一起重写一次文件，
+// 逐条写入 N 条记录的代价从 N 次整体重写降为每个间隔一次。进程崩溃时最多丢失最近 interval 内的写入；
+// Close 与 Sync 会立即落盘。后台落盘失败时，下一次写入同步重试并返回错误。interval <= 0 恢复同步落盘。
+func (r *FileRepository) SetFlushInterval(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interval = interval
+}
+
+// Sync 立即落盘尚未写入文件的修改。
+func (r *FileRepository) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	if !r.dirty {
+		return nil
+	}
+	return r.flushDirty()
+}
+
+// Close 落盘尚未写入文件的修改。
+func (r *FileRepository) Close() error {
+	return r.Sync()
+}
+
+// SaveCostSnapshot implements Repository.
+func (r *FileRepository) SaveCostSnapshot(ctx context.Context, snapshot postgres.CostSnapshot) error {
+	return r.write(func() error { return r.MockRepository.SaveCostSnapshot(ctx, snapshot) })
+}
+
+// DeleteCostSnapshot implements Repository.
+func (r *FileRepository) DeleteCostSnapshot(ctx context.Context, id string) error {
+	return r.write(func() error { return r.MockRepository.DeleteCostSnapshot(ctx, id) })
+}
+
+// SaveROIBaseline implements Repository.
+func (r *FileRepository) SaveROIBaseline(ctx context.Context, baseline postgres.ROIBaseline) error {
+	return r.write(func() error { return r.MockRepository.SaveROIBaseline(ctx, baseline) })
+}
+
+// DeleteROIBaseline implements Repository.
+func (r *FileRepository) DeleteROIBaseline(ctx context.Context, id string) error {
+	return r.write(func() error { return r.MockRepository.DeleteROIBaseline(ctx, id) })
+}
+
+// SaveDailyNamespaceCost implements Repository.
+func (r *FileRepository) SaveDailyNamespaceCost(ctx context.Context, cost postgres.DailyNamespaceCost) error {
+	return r.write(func() error { return r.MockRepository.SaveDailyNamespaceCost(ctx, cost) })
+}
+
+// SaveHourlyWorkloadStat implements Repository.
+func (r *FileRepository) SaveHourlyWorkloadStat(ctx context.Context, stat postgres.HourlyWorkloadStat) error {
+	return r.write(func() error { return r.MockRepository.SaveHourlyWorkloadStat(ctx, stat) })
+}
+
+// SaveMetadata implements Repository.
+func (r *FileRepository) SaveMetadata(ctx context.Context, metadata postgres.Metadata) error {
+	return r.write(func() error { return r.MockRepository.SaveMetadata(ctx, metadata) })
+}
+
+// DeleteMetadata implements Repository.
+func (r *FileRepository) DeleteMetadata(ctx context.Context, key string) error {
+	return r.write(func() error { return r.MockRepository.DeleteMetadata(ctx, key) })
+}
+
+// BeginTx implements Repository; the file is rewritten when the transaction commits.
+func (r *FileRepository) BeginTx(ctx context.Context) (postgres.Transaction, error) {
+	tx, err := r.MockRepository.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fileTransaction{Transaction: tx, repo: r}, nil
+}
+
+type fileTransaction struct {
+	postgres.Transaction
+	repo *FileRepository
+}
+
+func (tx *fileTransaction) Commit() error {
+	return tx.repo.write(tx.Transaction.Commit)
+}
+
+// write applies fn and persists the full data set, immediately or with the next batched flush.
+func (r *FileRepository) write(fn func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := fn(); err != nil {
+		return err
+	}
+	r.dirty = true
+	if r.interval <= 0 || r.flushErr != nil {
+		return r.flushDirty()
+	}
+	if r.timer == nil {
+		r.timer = time.AfterFunc(r.interval, r.flushBatch)
+	}
+	return nil
+}
+
+// flushBatch is the scheduled flush of the writes batched since the first unflushed one.
+func (r *FileRepository) flushBatch() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timer = nil
+	if r.dirty {
+		_ = r.flushDirty() // 错误记录在 flushErr，由下一次写入或 Sync 返回
+	}
+}
+
+// flushDirty persists the data set and records the outcome. Caller holds r.mu.
+func (r *FileRepository) flushDirty() error {
+	r.flushErr = r.flush()
+	if r.flushErr == nil {
+		r.dirty = false
+	}
+	return r.flushErr
+}
+
+func (r *FileRepository) flush() error {
+	raw, err := json.Marshal(r.MockRepository.State())
+	if err != nil {
+		return fmt.Errorf("file storage: encode: %w", err)
+	}
+
+	// 先写临时文件再 rename，避免进程中途退出留下半截文件。
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("file storage: %w", err)
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", r.path, time.Now().UnixNano())
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("file storage: write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("file storage: rename %s: %w", tmp, err)
+	}
+	return nil
+}
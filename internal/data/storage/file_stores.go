@@ -0,0 +1,179 @@
+// Package storage file_stores.go: FileRepository 的可选存储写入。读取沿用嵌入的 MockRepository，
+// 每次写入成功后与核心表一样整体落盘，重启后不丢失。
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// writeValue is write for a store method returning a value.
+func writeValue[T any](r *FileRepository, fn func() (T, error)) (T, error) {
+	var v T
+	err := r.write(func() error {
+		var err error
+		v, err = fn()
+		return err
+	})
+	return v, err
+}
+
+// SaveBillAccountSummary persists the bill of an account period.
+func (r *FileRepository) SaveBillAccountSummary(ctx context.Context, s postgres.BillAccountSummary) error {
+	return r.write(func() error { return r.MockRepository.SaveBillAccountSummary(ctx, s) })
+}
+
+// SaveCalculationRun persists a calculation run.
+func (r *FileRepository) SaveCalculationRun(ctx context.Context, run postgres.CalculationRun) error {
+	return r.write(func() error { return r.MockRepository.SaveCalculationRun(ctx, run) })
+}
+
+// SaveDailyStorageCost persists the cost of a PVC day.
+func (r *FileRepository) SaveDailyStorageCost(ctx context.Context, c postgres.DailyStorageCost) error {
+	return r.write(func() error { return r.MockRepository.SaveDailyStorageCost(ctx, c) })
+}
+
+// SaveDailyNetworkCost persists the cost of a network resource day.
+func (r *FileRepository) SaveDailyNetworkCost(ctx context.Context, c postgres.DailyNetworkCost) error {
+	return r.write(func() error { return r.MockRepository.SaveDailyNetworkCost(ctx, c) })
+}
+
+// SaveNamespaceLifecycle persists the lifecycle of a namespace.
+func (r *FileRepository) SaveNamespaceLifecycle(ctx context.Context, lifecycle postgres.NamespaceLifecycle) error {
+	return r.write(func() error { return r.MockRepository.SaveNamespaceLifecycle(ctx, lifecycle) })
+}
+
+// SavePriceVersion persists a price version.
+func (r *FileRepository) SavePriceVersion(ctx context.Context, version postgres.PriceVersion) error {
+	return r.write(func() error { return r.MockRepository.SavePriceVersion(ctx, version) })
+}
+
+// SavePriceChange persists a price change request.
+func (r *FileRepository) SavePriceChange(ctx context.Context, change postgres.PriceChange) (postgres.PriceChange, error) {
+	return writeValue(r, func() (postgres.PriceChange, error) { return r.MockRepository.SavePriceChange(ctx, change) })
+}
+
+// SaveGradeChangeEvent persists a grade change event.
+func (r *FileRepository) SaveGradeChangeEvent(ctx context.Context, event postgres.GradeChangeEvent) error {
+	return r.write(func() error { return r.MockRepository.SaveGradeChangeEvent(ctx, event) })
+}
+
+// SaveIssuedRecommendation persists an issued recommendation.
+func (r *FileRepository) SaveIssuedRecommendation(ctx context.Context, rec postgres.IssuedRecommendation) error {
+	return r.write(func() error { return r.MockRepository.SaveIssuedRecommendation(ctx, rec) })
+}
+
+// SaveOptimizationRecord persists an optimization tracking record.
+func (r *FileRepository) SaveOptimizationRecord(ctx context.Context, rec roi.OptimizationTrackingRecord) (roi.OptimizationTrackingRecord, error) {
+	return writeValue(r, func() (roi.OptimizationTrackingRecord, error) {
+		return r.MockRepository.SaveOptimizationRecord(ctx, rec)
+	})
+}
+
+// SaveServiceAccount persists a service account.
+func (r *FileRepository) SaveServiceAccount(ctx context.Context, account postgres.ServiceAccount) (postgres.ServiceAccount, error) {
+	return writeValue(r, func() (postgres.ServiceAccount, error) { return r.MockRepository.SaveServiceAccount(ctx, account) })
+}
+
+// DeleteServiceAccount deletes a service account and its API keys.
+func (r *FileRepository) DeleteServiceAccount(ctx context.Context, id string) error {
+	return r.write(func() error { return r.MockRepository.DeleteServiceAccount(ctx, id) })
+}
+
+// SaveAPIKey persists an API key.
+func (r *FileRepository) SaveAPIKey(ctx context.Context, key postgres.APIKey) (postgres.APIKey, error) {
+	return writeValue(r, func() (postgres.APIKey, error) { return r.MockRepository.SaveAPIKey(ctx, key) })
+}
+
+// IncrementAPIKeyUsage counts a request of the key and persists the usage, so quotas survive a restart.
+func (r *FileRepository) IncrementAPIKeyUsage(ctx context.Context, keyID string, date time.Time, quota int) (postgres.APIKeyUsage, bool, error) {
+	var allowed bool
+	usage, err := writeValue(r, func() (postgres.APIKeyUsage, error) {
+		u, ok, err := r.MockRepository.IncrementAPIKeyUsage(ctx, keyID, date, quota)
+		allowed = ok
+		return u, err
+	})
+	return usage, allowed, err
+}
+
+// SaveSnapshotRegrade persists the regrade of a snapshot.
+func (r *FileRepository) SaveSnapshotRegrade(ctx context.Context, regrade postgres.SnapshotRegrade) error {
+	return r.write(func() error { return r.MockRepository.SaveSnapshotRegrade(ctx, regrade) })
+}
+
+// SaveAlertRule persists an alert rule.
+func (r *FileRepository) SaveAlertRule(ctx context.Context, rule postgres.AlertRule) (postgres.AlertRule, error) {
+	return writeValue(r, func() (postgres.AlertRule, error) { return r.MockRepository.SaveAlertRule(ctx, rule) })
+}
+
+// DeleteAlertRule deletes an alert rule.
+func (r *FileRepository) DeleteAlertRule(ctx context.Context, id string) error {
+	return r.write(func() error { return r.MockRepository.DeleteAlertRule(ctx, id) })
+}
+
+// SaveNotificationRoute persists a notification route.
+func (r *FileRepository) SaveNotificationRoute(ctx context.Context, route postgres.NotificationRoute) (postgres.NotificationRoute, error) {
+	return writeValue(r, func() (postgres.NotificationRoute, error) { return r.MockRepository.SaveNotificationRoute(ctx, route) })
+}
+
+// DeleteNotificationRoute deletes a notification route.
+func (r *FileRepository) DeleteNotificationRoute(ctx context.Context, id string) error {
+	return r.write(func() error { return r.MockRepository.DeleteNotificationRoute(ctx, id) })
+}
+
+// SaveNotificationSilence persists a notification silence.
+func (r *FileRepository) SaveNotificationSilence(ctx context.Context, silence postgres.NotificationSilence) (postgres.NotificationSilence, error) {
+	return writeValue(r, func() (postgres.NotificationSilence, error) {
+		return r.MockRepository.SaveNotificationSilence(ctx, silence)
+	})
+}
+
+// SaveQueryLineage persists the lineage of a cost query response.
+func (r *FileRepository) SaveQueryLineage(ctx context.Context, lineage postgres.QueryLineage) error {
+	return r.write(func() error { return r.MockRepository.SaveQueryLineage(ctx, lineage) })
+}
+
+// SaveConfigChange persists a config change.
+func (r *FileRepository) SaveConfigChange(ctx context.Context, change postgres.ConfigChange) error {
+	return r.write(func() error { return r.MockRepository.SaveConfigChange(ctx, change) })
+}
+
+// SaveSLOViolation persists an SLO violation.
+func (r *FileRepository) SaveSLOViolation(ctx context.Context, violation postgres.SLOViolation) error {
+	return r.write(func() error { return r.MockRepository.SaveSLOViolation(ctx, violation) })
+}
+
+// SaveDependencyEdges persists dependency edges.
+func (r *FileRepository) SaveDependencyEdges(ctx context.Context, edges []postgres.DependencyEdge) error {
+	return r.write(func() error { return r.MockRepository.SaveDependencyEdges(ctx, edges) })
+}
+
+// DeleteDependencyEdges deletes the matching dependency edges.
+func (r *FileRepository) DeleteDependencyEdges(ctx context.Context, filter postgres.DependencyEdgeFilter) (int, error) {
+	return writeValue(r, func() (int, error) { return r.MockRepository.DeleteDependencyEdges(ctx, filter) })
+}
+
+// CloseAccountingPeriod persists a closed accounting period.
+func (r *FileRepository) CloseAccountingPeriod(ctx context.Context, period postgres.AccountingPeriod) error {
+	return r.write(func() error { return r.MockRepository.CloseAccountingPeriod(ctx, period) })
+}
+
+// SaveAdjustment persists a manual adjustment.
+func (r *FileRepository) SaveAdjustment(ctx context.Context, adj postgres.Adjustment) (postgres.Adjustment, error) {
+	return writeValue(r, func() (postgres.Adjustment, error) { return r.MockRepository.SaveAdjustment(ctx, adj) })
+}
+
+// ApproveAdjustment persists the approval of a pending adjustment.
+func (r *FileRepository) ApproveAdjustment(ctx context.Context, id, approver string) (postgres.Adjustment, error) {
+	return writeValue(r, func() (postgres.Adjustment, error) { return r.MockRepository.ApproveAdjustment(ctx, id, approver) })
+}
+
+// DownsampleHourlyWorkloadStats folds old hourly stats into daily rollups and persists both.
+func (r *FileRepository) DownsampleHourlyWorkloadStats(ctx context.Context, before time.Time) (postgres.DownsampleResult, error) {
+	return writeValue(r, func() (postgres.DownsampleResult, error) {
+		return r.MockRepository.DownsampleHourlyWorkloadStats(ctx, before)
+	})
+}
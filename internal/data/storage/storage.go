@@ -0,0 +1,118 @@
+// Package storage 存储驱动注册表：按配置选择 Repository 实现（postgres、嵌入式文件、内存/mock），
+// 使边缘集群等场景无需部署 PostgreSQL。所有驱动须通过 storagetest 一致性测试，保证语义一致。
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// ErrUnknownDriver 驱动未注册。
+var ErrUnknownDriver = errors.New("unknown storage driver")
+
+// Config 存储配置。
+type Config struct {
+	// Driver 驱动名：memory / mock / file，以及由其他包注册的驱动（如 postgres）。
+	Driver string `json:"driver"`
+	// DSN 驱动相关的连接串；file 驱动为数据文件路径，postgres 驱动为连接 URL。
+	DSN string `json:"-"`
+	// Postgres postgres 驱动的连接池与迁移选项；其 DSN 为空时使用上面的 DSN。
+	Postgres postgres.SQLConfig `json:"-"`
+	// FlushInterval file 驱动的合并落盘间隔（见 FileRepository.SetFlushInterval）；<=0 每次写入同步落盘。
+	FlushInterval time.Duration `json:"flush_interval"`
+}
+
+// Opener 打开一个 Repository。
+type Opener func(ctx context.Context, cfg Config) (postgres.Repository, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Opener)
+)
+
+// Register 注册驱动；重复注册同名驱动会 panic（与 database/sql 一致，暴露初始化错误）。
+func Register(name string, opener Opener) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if opener == nil {
+		panic("storage: Register opener is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("storage: Register called twice for driver " + name)
+	}
+	drivers[name] = opener
+}
+
+// Drivers 返回已注册驱动名（排序）。
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open 按 cfg.Driver 打开 Repository；Driver 为空时使用 mock。
+func Open(ctx context.Context, cfg Config) (postgres.Repository, error) {
+	if cfg.Driver == "" {
+		cfg.Driver = DriverMock
+	}
+	driversMu.RLock()
+	opener, ok := drivers[cfg.Driver]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (registered: %v)", ErrUnknownDriver, cfg.Driver, Drivers())
+	}
+	return opener(ctx, cfg)
+}
+
+// Built-in driver names.
+const (
+	DriverMemory   = "memory"   // 空的内存存储，进程退出即丢失
+	DriverMock     = "mock"     // 带演示数据的 Mock 存储（Phase3 默认）
+	DriverFile     = "file"     // 嵌入式单文件存储，适合边缘集群
+	DriverPostgres = "postgres" // PostgreSQL（database/sql + pgx 驱动）
+)
+
+func init() {
+	Register(DriverMemory, func(ctx context.Context, cfg Config) (postgres.Repository, error) {
+		return newMemoryRepository(), nil
+	})
+	Register(DriverMock, func(ctx context.Context, cfg Config) (postgres.Repository, error) {
+		return postgres.NewMockRepository(postgres.DefaultMockConfig()), nil
+	})
+	Register(DriverFile, func(ctx context.Context, cfg Config) (postgres.Repository, error) {
+		repo, err := OpenFile(ctx, cfg.DSN)
+		if err != nil {
+			return nil, err
+		}
+		repo.SetFlushInterval(cfg.FlushInterval)
+		return repo, nil
+	})
+	Register(DriverPostgres, func(ctx context.Context, cfg Config) (postgres.Repository, error) {
+		sqlCfg := cfg.Postgres
+		if sqlCfg.DSN == "" {
+			sqlCfg.DSN = cfg.DSN
+		}
+		return postgres.OpenSQLRepository(ctx, sqlCfg)
+	})
+}
+
+// newMemoryRepository 返回无预置数据、无模拟延迟与错误的内存 Repository。
+func newMemoryRepository() *postgres.MockRepository {
+	cfg := postgres.DefaultMockConfig()
+	cfg.Scenario = "empty"
+	cfg.LatencyMs = 0
+	cfg.ErrorRate = 0
+	cfg.EnableTransactions = true
+	return postgres.NewMockRepository(cfg)
+}
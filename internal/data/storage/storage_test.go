@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/querybudget"
+	"github.com/myxxhui/lighthouse-src/internal/data/storage/storagetest"
+)
+
+// TestConformance runs the conformance suite against every registered driver.
+func TestConformance(t *testing.T) {
+	for _, driver := range Drivers() {
+		driver := driver
+		t.Run(driver, func(t *testing.T) {
+			if driver == DriverPostgres {
+				t.Skip("needs a PostgreSQL server; covered by the integration test in internal/data/postgres")
+			}
+			open := func(t *testing.T) postgres.Repository {
+				repo, err := Open(context.Background(), Config{Driver: driver, DSN: filepath.Join(t.TempDir(), "lighthouse.json")})
+				if err != nil {
+					t.Fatalf("Open(%s): %v", driver, err)
+				}
+				return repo
+			}
+			storagetest.Run(t, open)
+			storagetest.RunStores(t, open)
+		})
+	}
+}
+
+// TestWrapperConformance checks that the repository wrappers keep the semantics of the
+// repository they wrap.
+func TestWrapperConformance(t *testing.T) {
+	t.Run("encrypted", func(t *testing.T) {
+		storagetest.Run(t, func(t *testing.T) postgres.Repository {
+			keys, err := ParseKeyring("k1:conformance")
+			if err != nil {
+				t.Fatalf("ParseKeyring: %v", err)
+			}
+			return NewEncryptedRepository(newMemoryRepository(), keys)
+		})
+	})
+	t.Run("degraded", func(t *testing.T) {
+		storagetest.Run(t, func(t *testing.T) postgres.Repository {
+			return NewDegradedRepository(newMemoryRepository(), 0)
+		})
+	})
+	// 可选存储经 stores 透传
+	t.Run("encrypted stores", func(t *testing.T) {
+		storagetest.RunStores(t, func(t *testing.T) postgres.Repository {
+			keys, err := ParseKeyring("k1:conformance")
+			if err != nil {
+				t.Fatalf("ParseKeyring: %v", err)
+			}
+			return NewEncryptedRepository(newMemoryRepository(), keys)
+		})
+	})
+	t.Run("chaos", func(t *testing.T) {
+		open := func(t *testing.T) postgres.Repository {
+			return NewChaosRepository(newMemoryRepository(), chaos.NewController(nil, 0))
+		}
+		storagetest.Run(t, open)
+		storagetest.RunStores(t, open)
+	})
+	t.Run("budget", func(t *testing.T) {
+		open := func(t *testing.T) postgres.Repository {
+			return NewBudgetRepository(newMemoryRepository())
+		}
+		storagetest.Run(t, open)
+		storagetest.RunStores(t, open)
+	})
+}
+
+// TestBudgetRepositoryStores checks that calls to the optional stores count against the budget of
+// the consumer like the core methods.
+func TestBudgetRepositoryStores(t *testing.T) {
+	ledger := querybudget.NewLedger(time.Hour, querybudget.Quota{}, nil)
+	ctx := querybudget.WithConsumer(context.Background(), ledger, "ci")
+	repo := NewBudgetRepository(newMemoryRepository())
+	if _, err := repo.ListCalculationRuns(ctx, postgres.CalculationRunFilter{}); err != nil {
+		t.Fatalf("ListCalculationRuns: %v", err)
+	}
+	if _, err := repo.SaveServiceAccount(ctx, postgres.ServiceAccount{Name: "ci"}); err != nil {
+		t.Fatalf("SaveServiceAccount: %v", err)
+	}
+	if _, err := repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{}); err != nil {
+		t.Fatalf("ListCostSnapshots: %v", err)
+	}
+	_, _, usage := ledger.Usage()
+	if len(usage) != 1 || usage[0].DatabaseQueries != 3 {
+		t.Errorf("usage = %+v, want 3 database queries of ci", usage)
+	}
+}
+
+// TestChaosRepositoryStores checks that drills also fail the optional stores.
+func TestChaosRepositoryStores(t *testing.T) {
+	ctx := context.Background()
+	drills := chaos.NewController(map[string]chaos.Scenario{"database-outage": {chaos.Database: {ErrorRate: 1}}}, time.Hour)
+	repo := NewChaosRepository(newMemoryRepository(), drills)
+	run := postgres.CalculationRun{ID: "run-1", Status: postgres.CalculationRunSucceeded}
+	if err := repo.SaveCalculationRun(ctx, run); err != nil {
+		t.Fatalf("SaveCalculationRun outside a drill: %v", err)
+	}
+	if _, err := drills.Start("database-outage", time.Minute, "test"); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := repo.GetCalculationRun(ctx, run.ID); err == nil {
+		t.Error("GetCalculationRun during a database outage drill succeeded")
+	}
+	drills.Stop()
+	if _, err := repo.GetCalculationRun(ctx, run.ID); err != nil {
+		t.Errorf("GetCalculationRun after the drill: %v", err)
+	}
+}
+
+func TestOpenUnknownDriver(t *testing.T) {
+	if _, err := Open(context.Background(), Config{Driver: "nope"}); !errors.Is(err, ErrUnknownDriver) {
+		t.Errorf("expected ErrUnknownDriver, got %v", err)
+	}
+}
+
+func TestFileRepositoryPersists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "lighthouse.json")
+	repo, err := OpenFile(ctx, path)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "s1", TotalBillableCost: 7})
+	_ = repo.SaveMetadata(ctx, postgres.Metadata{Key: "k", Value: map[string]interface{}{"a": "b"}})
+	tx, _ := repo.BeginTx(ctx)
+	_ = tx.Repository().SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "s2"})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	reopened, err := OpenFile(ctx, path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if s, err := reopened.GetCostSnapshot(ctx, "s1"); err != nil || s.TotalBillableCost != 7 {
+		t.Errorf("snapshot not persisted: %+v, %v", s, err)
+	}
+	if _, err := reopened.GetCostSnapshot(ctx, "s2"); err != nil {
+		t.Errorf("committed snapshot not persisted: %v", err)
+	}
+	if m, err := reopened.GetMetadata(ctx, "k"); err != nil || m.Value["a"] != "b" {
+		t.Errorf("metadata not persisted: %+v, %v", m, err)
+	}
+}
+
+func TestFileRepositoryBatchesWrites(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "lighthouse.json")
+	repo, err := OpenFile(ctx, path)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	repo.SetFlushInterval(time.Hour)
+	for i := 0; i < 100; i++ {
+		if err := repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "prod", WorkloadName: "api", Timestamp: time.Unix(int64(i)*3600, 0)}); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	// 间隔内的写入只在内存中，文件尚未重写
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("file written before the flush interval: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	reopened, err := OpenFile(ctx, path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if stats, _ := reopened.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "prod"}); len(stats) != 100 {
+		t.Errorf("persisted %d stats, want 100", len(stats))
+	}
+
+	// 定时落盘
+	reopened.SetFlushInterval(10 * time.Millisecond)
+	_ = reopened.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "s1"})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if again, err := OpenFile(ctx, path); err == nil {
+			if _, err := again.GetCostSnapshot(ctx, "s1"); err == nil {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("batched write not flushed after the interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestFileRepositoryPersistsStores checks that the optional stores survive a reopen along with
+// the hash chain and the closed periods.
+func TestFileRepositoryPersistsStores(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "lighthouse.json")
+	repo, err := OpenFile(ctx, path)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	account, err := repo.SaveServiceAccount(ctx, postgres.ServiceAccount{Name: "ci"})
+	if err != nil {
+		t.Fatalf("SaveServiceAccount: %v", err)
+	}
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	if _, _, err := repo.IncrementAPIKeyUsage(ctx, "key-1", day, 0); err != nil {
+		t.Fatalf("IncrementAPIKeyUsage: %v", err)
+	}
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "s1", Timestamp: day})
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "prod", Date: day, BillableCost: 5})
+	if err := repo.CloseAccountingPeriod(ctx, postgres.AccountingPeriod{Label: "2024-05", StartDate: day, EndDate: day.AddDate(0, 1, 0),
+		StartTime: day, EndTime: day.AddDate(0, 1, 0)}); err != nil {
+		t.Fatalf("CloseAccountingPeriod: %v", err)
+	}
+
+	reopened, err := OpenFile(ctx, path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got, err := reopened.GetServiceAccount(ctx, account.ID); err != nil || got.Name != "ci" {
+		t.Errorf("service account not persisted: %+v, %v", got, err)
+	}
+	if usage, _ := reopened.ListAPIKeyUsage(ctx, []string{"key-1"}, day, day); len(usage) != 1 || usage[0].Requests != 1 {
+		t.Errorf("api key usage not persisted: %+v", usage)
+	}
+	if chain, _ := reopened.ListSnapshotHashChain(ctx); len(chain) != 1 {
+		t.Errorf("hash chain = %+v, want the one record of s1", chain)
+	}
+	if got, err := reopened.GetDailyNamespaceCost(ctx, "prod", day); err != nil || got.BillableCost != 5 {
+		t.Errorf("closed period row not restored: %+v, %v", got, err)
+	}
+	if err := reopened.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "prod", Date: day}); !errors.Is(err, postgres.ErrPeriodClosed) {
+		t.Errorf("write to the restored closed period: %v, want ErrPeriodClosed", err)
+	}
+}
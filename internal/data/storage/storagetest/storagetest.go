@@ -0,0 +1,427 @@
+// Package storagetest 存储驱动一致性测试：每个 Repository 实现（mock、文件、包装器及未来的 SQL 驱动）
+// 都必须在自己的测试中调用 Run，保证与 Repository 约定的语义一致，避免实现之间悄悄分叉。
+// 用例只读写自己创建的数据（2020 年时间戳、conformance- 前缀），允许驱动预置演示数据。
+//
+// 约定的语义：
+//   - 时间/数值范围过滤两端都包含；零值表示不过滤。
+//   - 排序：快照、每日成本、每小时统计按时间倒序，ROI 基线按 CreatedAt 倒序，元数据按 key 升序。
+//   - Limit 为 0 表示不限；Offset 超出结果时返回空列表而非错误。
+//   - Save 是 upsert：相同主键覆盖，不新增行。
+//   - 事务内的写入在事务内立即可见，提交前对外不可见，回滚后丢弃。
+package storagetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Factory 为每个子测试返回一个新的 Repository。
+type Factory func(t *testing.T) postgres.Repository
+
+// Run 执行全部一致性用例。
+func Run(t *testing.T, open Factory) {
+	t.Run("CostSnapshot", func(t *testing.T) { testCostSnapshot(t, open(t)) })
+	t.Run("ROIBaseline", func(t *testing.T) { testROIBaseline(t, open(t)) })
+	t.Run("DailyNamespaceCost", func(t *testing.T) { testDailyNamespaceCost(t, open(t)) })
+	t.Run("HourlyWorkloadStat", func(t *testing.T) { testHourlyWorkloadStat(t, open(t)) })
+	t.Run("Metadata", func(t *testing.T) { testMetadata(t, open(t)) })
+	t.Run("Transaction", func(t *testing.T) { testTransaction(t, open(t)) })
+	t.Run("HealthCheck", func(t *testing.T) {
+		if err := open(t).HealthCheck(context.Background()); err != nil {
+			t.Errorf("HealthCheck: %v", err)
+		}
+	})
+}
+
+var base = time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func testCostSnapshot(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	s := postgres.CostSnapshot{ID: "conformance-snap-1", CalculationID: "conformance-calc", Timestamp: base, TotalBillableCost: 100}
+	if err := repo.SaveCostSnapshot(ctx, s); err != nil {
+		t.Fatalf("SaveCostSnapshot: %v", err)
+	}
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "conformance-snap-2", CalculationID: "conformance-calc", Timestamp: base.Add(time.Hour), TotalBillableCost: 50})
+
+	got, err := repo.GetCostSnapshot(ctx, s.ID)
+	if err != nil || got.TotalBillableCost != 100 || !got.Timestamp.Equal(base) {
+		t.Fatalf("GetCostSnapshot = %+v, %v", got, err)
+	}
+
+	s.TotalBillableCost = 120
+	if err := repo.SaveCostSnapshot(ctx, s); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	list, err := repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{CalculationID: "conformance-calc"})
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListCostSnapshots = %d, %v", len(list), err)
+	}
+	if list[0].ID != "conformance-snap-2" || list[1].TotalBillableCost != 120 {
+		t.Errorf("list not sorted by timestamp desc or overwrite lost: %+v", list)
+	}
+	list, _ = repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{CalculationID: "conformance-calc", Limit: 1, Offset: 1})
+	if len(list) != 1 || list[0].ID != "conformance-snap-1" {
+		t.Errorf("limit/offset: %+v", list)
+	}
+	list, err = repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{CalculationID: "conformance-calc", Offset: 5})
+	if err != nil || len(list) != 0 {
+		t.Errorf("offset past the end = %+v, %v, want empty", list, err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter postgres.CostSnapshotFilter
+		want   []string
+	}{
+		{"start inclusive", postgres.CostSnapshotFilter{StartTime: base.Add(time.Hour)}, []string{"conformance-snap-2"}},
+		{"end inclusive", postgres.CostSnapshotFilter{EndTime: base}, []string{"conformance-snap-1"}},
+		{"min cost", postgres.CostSnapshotFilter{MinTotalCost: 100}, []string{"conformance-snap-1"}},
+		{"max cost", postgres.CostSnapshotFilter{MaxTotalCost: 60}, []string{"conformance-snap-2"}},
+		{"no match", postgres.CostSnapshotFilter{CalculationID: "conformance-none"}, nil},
+	} {
+		// 演示数据不属于 conformance-calc，按 CalculationID 收窄后再比较
+		if tc.filter.CalculationID == "" {
+			tc.filter.CalculationID = "conformance-calc"
+		}
+		list, err := repo.ListCostSnapshots(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: ListCostSnapshots: %v", tc.name, err)
+		}
+		if got := snapshotIDs(list); !equalStrings(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// 字段选择：只读取请求的字段组，标识字段总会返回
+	full := postgres.CostSnapshot{
+		ID: "conformance-snap-3", CalculationID: "conformance-fields", Timestamp: base, TotalBillableCost: 80, ZombieCount: 2,
+		ResourceResults:   []costmodel.CostResult{{TotalBillableCost: 80}},
+		AggregatedResults: map[costmodel.AggregationLevel][]costmodel.AggregationResult{costmodel.LevelNamespace: {{Identifier: "a"}}},
+	}
+	_ = repo.SaveCostSnapshot(ctx, full)
+	list, err = repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{ID: full.ID, Fields: postgres.SnapshotFieldCounts})
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListCostSnapshots by id = %d, %v", len(list), err)
+	}
+	if got := list[0]; got.CalculationID != "conformance-fields" || got.ZombieCount != 2 || got.TotalBillableCost != 0 || got.ResourceResults != nil || got.AggregatedResults != nil {
+		t.Errorf("counts-only snapshot = %+v", got)
+	}
+	list, _ = repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{ID: full.ID, Fields: postgres.SnapshotFieldSummary | postgres.SnapshotFieldAggregated})
+	if got := list[0]; got.TotalBillableCost != 80 || got.ZombieCount != 0 || len(got.AggregatedResults) != 1 || got.ResourceResults != nil {
+		t.Errorf("summary+aggregated snapshot = %+v", got)
+	}
+	if list, _ = repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{ID: full.ID}); len(list) != 1 || len(list[0].ResourceResults) != 1 {
+		t.Errorf("zero Fields should read the whole snapshot: %+v", list)
+	}
+
+	if err := repo.DeleteCostSnapshot(ctx, s.ID); err != nil {
+		t.Fatalf("DeleteCostSnapshot: %v", err)
+	}
+	if _, err := repo.GetCostSnapshot(ctx, s.ID); err == nil {
+		t.Error("Get after delete should fail")
+	}
+	if err := repo.DeleteCostSnapshot(ctx, s.ID); err == nil {
+		t.Error("deleting a missing snapshot should fail")
+	}
+}
+
+func testROIBaseline(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	b := postgres.ROIBaseline{
+		ID: "conformance-baseline", Name: "conformance", BaselineType: "target",
+		TimePeriodStart: base, TimePeriodEnd: base.AddDate(0, 1, 0),
+		Metrics:   map[string]float64{"efficiency_score": 0.8},
+		CreatedAt: base,
+	}
+	if err := repo.SaveROIBaseline(ctx, b); err != nil {
+		t.Fatalf("SaveROIBaseline: %v", err)
+	}
+	later := postgres.ROIBaseline{
+		ID: "conformance-baseline-2", Name: "conformance", BaselineType: "historical",
+		TimePeriodStart: base.AddDate(0, 1, 0), TimePeriodEnd: base.AddDate(0, 2, 0),
+		Metrics:   map[string]float64{"efficiency_score": 0.6},
+		CreatedAt: base.Add(time.Hour),
+	}
+	if err := repo.SaveROIBaseline(ctx, later); err != nil {
+		t.Fatalf("SaveROIBaseline: %v", err)
+	}
+	got, err := repo.GetROIBaseline(ctx, b.ID)
+	if err != nil || got.Metrics["efficiency_score"] != 0.8 {
+		t.Fatalf("GetROIBaseline = %+v, %v", got, err)
+	}
+
+	b.Metrics = map[string]float64{"efficiency_score": 0.9}
+	if err := repo.SaveROIBaseline(ctx, b); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	list, err := repo.ListROIBaselines(ctx, postgres.ROIBaselineFilter{Name: "conformance"})
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListROIBaselines = %d, %v", len(list), err)
+	}
+	if list[0].ID != later.ID || list[1].Metrics["efficiency_score"] != 0.9 {
+		t.Errorf("list not sorted by created_at desc or overwrite lost: %+v", list)
+	}
+	for _, tc := range []struct {
+		name   string
+		filter postgres.ROIBaselineFilter
+		want   string
+	}{
+		{"type", postgres.ROIBaselineFilter{Name: "conformance", BaselineType: "target"}, b.ID},
+		{"period start inclusive", postgres.ROIBaselineFilter{Name: "conformance", StartDate: base.AddDate(0, 1, 0)}, later.ID},
+		{"period end inclusive", postgres.ROIBaselineFilter{Name: "conformance", EndDate: base.AddDate(0, 1, 0)}, b.ID},
+		{"limit/offset", postgres.ROIBaselineFilter{Name: "conformance", Limit: 1, Offset: 1}, b.ID},
+	} {
+		list, err := repo.ListROIBaselines(ctx, tc.filter)
+		if err != nil || len(list) != 1 || list[0].ID != tc.want {
+			t.Errorf("%s: ListROIBaselines = %+v, %v, want only %s", tc.name, list, err, tc.want)
+		}
+	}
+	if err := repo.DeleteROIBaseline(ctx, b.ID); err != nil {
+		t.Fatalf("DeleteROIBaseline: %v", err)
+	}
+	if _, err := repo.GetROIBaseline(ctx, b.ID); err == nil {
+		t.Error("Get after delete should fail")
+	}
+}
+
+func testDailyNamespaceCost(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	ns := "conformance-ns"
+	for i, cost := range []float64{10, 20, 30} {
+		c := postgres.DailyNamespaceCost{Namespace: ns, Date: base.AddDate(0, 0, i), BillableCost: cost, UsageCost: cost / 2, EfficiencyScore: float64(i+1) / 10}
+		if err := repo.SaveDailyNamespaceCost(ctx, c); err != nil {
+			t.Fatalf("SaveDailyNamespaceCost: %v", err)
+		}
+	}
+	// Same namespace and day overwrites.
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: ns, Date: base.AddDate(0, 0, 2), BillableCost: 40, UsageCost: 20, EfficiencyScore: 0.3})
+
+	got, err := repo.GetDailyNamespaceCost(ctx, ns, base.AddDate(0, 0, 1))
+	if err != nil || got.BillableCost != 20 {
+		t.Fatalf("GetDailyNamespaceCost = %+v, %v", got, err)
+	}
+	if _, err := repo.GetDailyNamespaceCost(ctx, ns, base.AddDate(0, 0, 9)); err == nil {
+		t.Error("missing day should fail")
+	}
+
+	list, err := repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{Namespace: ns})
+	if err != nil || len(list) != 3 || list[0].BillableCost != 40 {
+		t.Fatalf("ListDailyNamespaceCosts = %+v, %v", list, err)
+	}
+	for i := 1; i < len(list); i++ {
+		if !list[i-1].Date.After(list[i].Date) {
+			t.Errorf("list not sorted by date desc: %v before %v", list[i-1].Date, list[i].Date)
+		}
+	}
+	for _, tc := range []struct {
+		name   string
+		filter postgres.DailyNamespaceCostFilter
+		want   []float64 // billable costs, in order
+	}{
+		{"date range inclusive", postgres.DailyNamespaceCostFilter{StartDate: base.AddDate(0, 0, 1), EndDate: base.AddDate(0, 0, 1)}, []float64{20}},
+		{"min efficiency", postgres.DailyNamespaceCostFilter{MinEfficiency: 0.2}, []float64{40, 20}},
+		{"max efficiency", postgres.DailyNamespaceCostFilter{MaxEfficiency: 0.2}, []float64{20, 10}},
+		{"limit/offset", postgres.DailyNamespaceCostFilter{Limit: 2, Offset: 1}, []float64{20, 10}},
+	} {
+		tc.filter.Namespace = ns
+		list, err := repo.ListDailyNamespaceCosts(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: ListDailyNamespaceCosts: %v", tc.name, err)
+		}
+		got := make([]float64, 0, len(list))
+		for _, c := range list {
+			got = append(got, c.BillableCost)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	agg, err := repo.AggregateDailyNamespaceCosts(ctx, base, base.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("AggregateDailyNamespaceCosts: %v", err)
+	}
+	var found bool
+	for _, a := range agg {
+		if a.Namespace == ns {
+			found = true
+			if a.BillableCost != 30 || a.UsageCost != 15 {
+				t.Errorf("aggregate = %+v, want billable 30 usage 15", a)
+			}
+		}
+	}
+	if !found {
+		t.Error("aggregate missing conformance namespace")
+	}
+}
+
+func testHourlyWorkloadStat(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	st := postgres.HourlyWorkloadStat{Namespace: "conformance-ns", WorkloadName: "api", WorkloadType: "Deployment", NodeName: "node-a", Timestamp: base, TotalBillableCost: 3}
+	if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+		t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+	}
+	got, err := repo.GetHourlyWorkloadStat(ctx, st.Namespace, st.WorkloadName, st.Timestamp)
+	if err != nil || got.TotalBillableCost != 3 {
+		t.Fatalf("GetHourlyWorkloadStat = %+v, %v", got, err)
+	}
+	list, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: st.Namespace})
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListHourlyWorkloadStats = %d, %v", len(list), err)
+	}
+
+	// (namespace, workload, hour) is the key: saving the same hour again overwrites.
+	st.TotalBillableCost = 4
+	if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	for i, w := range []struct {
+		workload, node string
+	}{{"api", "node-a"}, {"api", "node-b"}, {"worker", "node-b"}} {
+		s := postgres.HourlyWorkloadStat{Namespace: st.Namespace, WorkloadName: w.workload, WorkloadType: "Deployment", NodeName: w.node, Timestamp: base.Add(time.Duration(i+1) * time.Hour), TotalBillableCost: float64(10 + i)}
+		if err := repo.SaveHourlyWorkloadStat(ctx, s); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	list, err = repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: st.Namespace})
+	if err != nil || len(list) != 4 {
+		t.Fatalf("ListHourlyWorkloadStats = %+v, %v, want 4 rows", list, err)
+	}
+	if last := list[len(list)-1]; !last.Timestamp.Equal(base) || last.TotalBillableCost != 4 {
+		t.Errorf("list not sorted by timestamp desc or overwrite lost: %+v", list)
+	}
+	for _, tc := range []struct {
+		name   string
+		filter postgres.HourlyWorkloadStatFilter
+		want   []float64 // total billable costs, in order
+	}{
+		{"workload", postgres.HourlyWorkloadStatFilter{WorkloadName: "worker"}, []float64{12}},
+		{"node", postgres.HourlyWorkloadStatFilter{NodeName: "node-b"}, []float64{12, 11}},
+		{"time range inclusive", postgres.HourlyWorkloadStatFilter{StartTime: base.Add(time.Hour), EndTime: base.Add(2 * time.Hour)}, []float64{11, 10}},
+		{"limit/offset", postgres.HourlyWorkloadStatFilter{Limit: 2, Offset: 1}, []float64{11, 10}},
+		{"offset past the end", postgres.HourlyWorkloadStatFilter{Offset: 10}, []float64{}},
+	} {
+		tc.filter.Namespace = st.Namespace
+		list, err := repo.ListHourlyWorkloadStats(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: ListHourlyWorkloadStats: %v", tc.name, err)
+		}
+		got := make([]float64, 0, len(list))
+		for _, s := range list {
+			got = append(got, s.TotalBillableCost)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func testMetadata(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	for _, key := range []string{"conformance/b", "conformance/a", "conformance", "conf"} {
+		if err := repo.SaveMetadata(ctx, postgres.Metadata{Key: key, Value: map[string]interface{}{"v": key}, CreatedBy: "conformance-" + key[len(key)-1:]}); err != nil {
+			t.Fatalf("SaveMetadata: %v", err)
+		}
+	}
+	got, err := repo.GetMetadata(ctx, "conformance/a")
+	if err != nil || got.Value["v"] != "conformance/a" {
+		t.Fatalf("GetMetadata = %+v, %v", got, err)
+	}
+	// Keys shorter than the prefix ("conformance", "conf") do not match it.
+	list, err := repo.ListMetadata(ctx, postgres.MetadataFilter{KeyPrefix: "conformance/"})
+	if err != nil || len(list) != 2 || list[0].Key != "conformance/a" {
+		t.Fatalf("ListMetadata = %+v, %v", list, err)
+	}
+	list, err = repo.ListMetadata(ctx, postgres.MetadataFilter{KeyPrefix: "conformance/", Limit: 1, Offset: 1})
+	if err != nil || len(list) != 1 || list[0].Key != "conformance/b" {
+		t.Errorf("limit/offset: %+v, %v", list, err)
+	}
+	list, err = repo.ListMetadata(ctx, postgres.MetadataFilter{CreatedBy: "conformance-a"})
+	if err != nil || len(list) != 1 || list[0].Key != "conformance/a" {
+		t.Errorf("created_by filter: %+v, %v", list, err)
+	}
+
+	if err := repo.SaveMetadata(ctx, postgres.Metadata{Key: "conformance/a", Value: map[string]interface{}{"v": "updated"}}); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	got, err = repo.GetMetadata(ctx, "conformance/a")
+	if err != nil || got.Value["v"] != "updated" {
+		t.Errorf("overwrite lost: %+v, %v", got, err)
+	}
+	if list, _ := repo.ListMetadata(ctx, postgres.MetadataFilter{KeyPrefix: "conformance/"}); len(list) != 2 {
+		t.Errorf("overwrite added a row: %+v", list)
+	}
+	if err := repo.DeleteMetadata(ctx, "conformance/a"); err != nil {
+		t.Fatalf("DeleteMetadata: %v", err)
+	}
+	if _, err := repo.GetMetadata(ctx, "conformance/a"); err == nil {
+		t.Error("Get after delete should fail")
+	}
+}
+
+func testTransaction(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	tx, err := repo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if err := tx.Repository().SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "conformance-tx-commit", CalculationID: "conformance-tx", Timestamp: base}); err != nil {
+		t.Fatalf("tx save: %v", err)
+	}
+	if _, err := tx.Repository().GetCostSnapshot(ctx, "conformance-tx-commit"); err != nil {
+		t.Errorf("write not visible inside its own transaction: %v", err)
+	}
+	if list, err := tx.Repository().ListCostSnapshots(ctx, postgres.CostSnapshotFilter{CalculationID: "conformance-tx"}); err != nil || len(list) != 1 {
+		t.Errorf("transaction list = %+v, %v, want its own write", list, err)
+	}
+	if _, err := repo.GetCostSnapshot(ctx, "conformance-tx-commit"); err == nil {
+		t.Error("uncommitted write must not be visible outside the transaction")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if _, err := repo.GetCostSnapshot(ctx, "conformance-tx-commit"); err != nil {
+		t.Errorf("committed write not visible: %v", err)
+	}
+
+	tx, err = repo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	_ = tx.Repository().SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "conformance-tx-rollback", Timestamp: base})
+	_ = tx.Repository().SaveMetadata(ctx, postgres.Metadata{Key: "conformance-tx/rollback", Value: map[string]interface{}{"v": 1}})
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if _, err := repo.GetCostSnapshot(ctx, "conformance-tx-rollback"); err == nil {
+		t.Error("rolled back write must not be visible")
+	}
+	if _, err := repo.GetMetadata(ctx, "conformance-tx/rollback"); err == nil {
+		t.Error("rolled back metadata must not be visible")
+	}
+}
+
+func snapshotIDs(list []postgres.CostSnapshot) []string {
+	ids := make([]string, 0, len(list))
+	for _, s := range list {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
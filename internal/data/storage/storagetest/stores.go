@@ -0,0 +1,316 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// RunStores 执行可选存储（服务层按接口断言使用的服务账号、会计期间、降采样等）的一致性用例；
+// 声称提供这些存储的 Repository 实现都必须通过，未实现某个存储时对应子测试失败。
+func RunStores(t *testing.T, open Factory) {
+	t.Run("ServiceAccount", func(t *testing.T) { testServiceAccount(t, open(t)) })
+	t.Run("APIKeyUsage", func(t *testing.T) { testAPIKeyUsage(t, open(t)) })
+	t.Run("PriceVersion", func(t *testing.T) { testPriceVersion(t, open(t)) })
+	t.Run("NotificationSilence", func(t *testing.T) { testNotificationSilence(t, open(t)) })
+	t.Run("DependencyEdge", func(t *testing.T) { testDependencyEdge(t, open(t)) })
+	t.Run("AccountingPeriod", func(t *testing.T) { testAccountingPeriod(t, open(t)) })
+	t.Run("Downsample", func(t *testing.T) { testDownsample(t, open(t)) })
+}
+
+// store 断言 repo 提供存储 T。
+func store[T any](t *testing.T, repo postgres.Repository) T {
+	t.Helper()
+	s, ok := repo.(T)
+	if !ok {
+		var zero *T
+		t.Fatalf("%T does not implement %T", repo, zero)
+	}
+	return s
+}
+
+func testServiceAccount(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	s := store[interface {
+		SaveServiceAccount(ctx context.Context, account postgres.ServiceAccount) (postgres.ServiceAccount, error)
+		GetServiceAccount(ctx context.Context, id string) (*postgres.ServiceAccount, error)
+		DeleteServiceAccount(ctx context.Context, id string) error
+		SaveAPIKey(ctx context.Context, key postgres.APIKey) (postgres.APIKey, error)
+		GetAPIKeyByHash(ctx context.Context, secretHash string) (*postgres.APIKey, error)
+	}](t, repo)
+
+	account, err := s.SaveServiceAccount(ctx, postgres.ServiceAccount{ID: "conformance-sa", Name: "conformance-sa"})
+	if err != nil {
+		t.Fatalf("SaveServiceAccount: %v", err)
+	}
+	hash := strings.Repeat("c", 64)
+	key, err := s.SaveAPIKey(ctx, postgres.APIKey{ID: "conformance-key", AccountID: account.ID, Prefix: "lh_conf",
+		SecretHash: hash, Scopes: []string{"read", "write"}, DailyQuota: 2})
+	if err != nil {
+		t.Fatalf("SaveAPIKey: %v", err)
+	}
+	got, err := s.GetAPIKeyByHash(ctx, hash)
+	if err != nil || got.ID != key.ID || !equalStrings(got.Scopes, []string{"read", "write"}) || got.DailyQuota != 2 {
+		t.Fatalf("GetAPIKeyByHash = %+v, %v", got, err)
+	}
+
+	if err := s.DeleteServiceAccount(ctx, account.ID); err != nil {
+		t.Fatalf("DeleteServiceAccount: %v", err)
+	}
+	if _, err := s.GetServiceAccount(ctx, account.ID); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("GetServiceAccount after delete: %v, want not found", err)
+	}
+	if _, err := s.GetAPIKeyByHash(ctx, hash); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("key of a deleted account: %v, want not found", err)
+	}
+	if err := s.DeleteServiceAccount(ctx, account.ID); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("delete twice: %v, want not found", err)
+	}
+}
+
+func testAPIKeyUsage(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	s := store[interface {
+		IncrementAPIKeyUsage(ctx context.Context, keyID string, date time.Time, quota int) (postgres.APIKeyUsage, bool, error)
+		ListAPIKeyUsage(ctx context.Context, keyIDs []string, startDate, endDate time.Time) ([]postgres.APIKeyUsage, error)
+	}](t, repo)
+
+	// 配额 2：前两次放行，之后拒绝；次日重新计数
+	for i, want := range []bool{true, true, false} {
+		u, allowed, err := s.IncrementAPIKeyUsage(ctx, "conformance-usage", base.Add(time.Hour), 2)
+		if err != nil || allowed != want {
+			t.Fatalf("request %d: allowed = %v, %v; want %v", i, allowed, err, want)
+		}
+		if i == 2 && (u.Requests != 2 || u.Rejected != 1) {
+			t.Errorf("usage = %+v, want 2 requests and 1 rejected", u)
+		}
+	}
+	if _, allowed, err := s.IncrementAPIKeyUsage(ctx, "conformance-usage", base.AddDate(0, 0, 1), 2); err != nil || !allowed {
+		t.Errorf("next day: allowed = %v, %v", allowed, err)
+	}
+	usage, err := s.ListAPIKeyUsage(ctx, []string{"conformance-usage"}, base, base.AddDate(0, 0, 1))
+	if err != nil || len(usage) != 2 || !usage[0].Date.Equal(base) || usage[1].Requests != 1 {
+		t.Errorf("ListAPIKeyUsage = %+v, %v", usage, err)
+	}
+}
+
+func testPriceVersion(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	s := store[interface {
+		SavePriceVersion(ctx context.Context, version postgres.PriceVersion) error
+		ListPriceVersions(ctx context.Context) ([]postgres.PriceVersion, error)
+	}](t, repo)
+
+	v := postgres.PriceVersion{EffectiveFrom: base, CPUPricePerCoreHour: 0.1, MemPricePerGBHour: 0.01}
+	if err := s.SavePriceVersion(ctx, v); err != nil {
+		t.Fatalf("SavePriceVersion: %v", err)
+	}
+	v.CPUPricePerCoreHour = 0.2
+	if err := s.SavePriceVersion(ctx, v); err != nil {
+		t.Fatalf("SavePriceVersion (correction): %v", err)
+	}
+	versions, err := s.ListPriceVersions(ctx)
+	if err != nil {
+		t.Fatalf("ListPriceVersions: %v", err)
+	}
+	var found []postgres.PriceVersion
+	for _, got := range versions {
+		if got.EffectiveFrom.Equal(base) {
+			found = append(found, got)
+		}
+	}
+	if len(found) != 1 || found[0].CPUPricePerCoreHour != 0.2 || found[0].Revision != 2 {
+		t.Errorf("corrected version = %+v, want one version at revision 2", found)
+	}
+	if err := s.SavePriceVersion(ctx, postgres.PriceVersion{EffectiveFrom: base.Add(time.Hour), CPUPricePerCoreHour: -1}); err == nil {
+		t.Error("negative price accepted")
+	}
+}
+
+func testNotificationSilence(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	s := store[interface {
+		SaveNotificationSilence(ctx context.Context, silence postgres.NotificationSilence) (postgres.NotificationSilence, error)
+		ListNotificationSilences(ctx context.Context, activeAt time.Time) ([]postgres.NotificationSilence, error)
+	}](t, repo)
+
+	for _, silence := range []postgres.NotificationSilence{
+		{ID: "conformance-silence-1", Matchers: map[string]string{"team": "a"}, StartsAt: base, EndsAt: base.Add(2 * time.Hour), Reason: "drill"},
+		{ID: "conformance-silence-2", Matchers: map[string]string{"team": "b"}, StartsAt: base.Add(time.Hour), EndsAt: base.Add(3 * time.Hour), Reason: "drill"},
+	} {
+		if _, err := s.SaveNotificationSilence(ctx, silence); err != nil {
+			t.Fatalf("SaveNotificationSilence: %v", err)
+		}
+	}
+	// 生效区间为 [StartsAt, EndsAt)
+	for _, tc := range []struct {
+		at   time.Time
+		want []string
+	}{
+		{base, []string{"conformance-silence-1"}},
+		{base.Add(time.Hour), []string{"conformance-silence-1", "conformance-silence-2"}},
+		{base.Add(2 * time.Hour), []string{"conformance-silence-2"}},
+		{base.Add(3 * time.Hour), nil},
+	} {
+		silences, err := s.ListNotificationSilences(ctx, tc.at)
+		if err != nil {
+			t.Fatalf("ListNotificationSilences: %v", err)
+		}
+		var ids []string
+		for _, silence := range silences {
+			if strings.HasPrefix(silence.ID, "conformance-") {
+				ids = append(ids, silence.ID)
+			}
+		}
+		if !equalStrings(ids, tc.want) {
+			t.Errorf("active at %s = %v, want %v", tc.at.Format(time.Kitchen), ids, tc.want)
+		}
+	}
+}
+
+func testDependencyEdge(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	s := store[interface {
+		SaveDependencyEdges(ctx context.Context, edges []postgres.DependencyEdge) error
+		ListDependencyEdges(ctx context.Context, filter postgres.DependencyEdgeFilter) ([]postgres.DependencyEdge, error)
+		DeleteDependencyEdges(ctx context.Context, filter postgres.DependencyEdgeFilter) (int, error)
+	}](t, repo)
+
+	edge := postgres.DependencyEdge{CallerNamespace: "conformance-ns", Caller: "web", CalleeNamespace: "conformance-ns",
+		Callee: "db", Source: "conformance", LastSeen: base}
+	stale := edge
+	stale.Callee, stale.LastSeen = "cache", base.Add(-time.Hour)
+	if err := s.SaveDependencyEdges(ctx, []postgres.DependencyEdge{edge, stale}); err != nil {
+		t.Fatalf("SaveDependencyEdges: %v", err)
+	}
+	edge.CallsPerMinute = 5
+	if err := s.SaveDependencyEdges(ctx, []postgres.DependencyEdge{edge}); err != nil {
+		t.Fatalf("SaveDependencyEdges (update): %v", err)
+	}
+	edges, err := s.ListDependencyEdges(ctx, postgres.DependencyEdgeFilter{Source: "conformance"})
+	if err != nil || len(edges) != 2 {
+		t.Fatalf("ListDependencyEdges = %+v, %v", edges, err)
+	}
+	n, err := s.DeleteDependencyEdges(ctx, postgres.DependencyEdgeFilter{Source: "conformance", SeenBefore: base})
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteDependencyEdges = %d, %v; want the stale edge", n, err)
+	}
+	edges, _ = s.ListDependencyEdges(ctx, postgres.DependencyEdgeFilter{Source: "conformance"})
+	if len(edges) != 1 || edges[0].Callee != "db" || edges[0].CallsPerMinute != 5 {
+		t.Errorf("remaining edges = %+v", edges)
+	}
+	if _, err := s.DeleteDependencyEdges(ctx, postgres.DependencyEdgeFilter{Source: "conformance"}); err != nil {
+		t.Errorf("DeleteDependencyEdges: %v", err)
+	}
+}
+
+func testAccountingPeriod(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	s := store[interface {
+		CloseAccountingPeriod(ctx context.Context, period postgres.AccountingPeriod) error
+		SaveAdjustment(ctx context.Context, adj postgres.Adjustment) (postgres.Adjustment, error)
+		ListAdjustments(ctx context.Context, filter postgres.AdjustmentFilter) ([]postgres.Adjustment, error)
+		GetAdjustment(ctx context.Context, id string) (*postgres.Adjustment, error)
+		ApproveAdjustment(ctx context.Context, id, approver string) (postgres.Adjustment, error)
+	}](t, repo)
+
+	const ns = "conformance-period"
+	day := base.AddDate(0, 1, 0) // 2020-04-01，与其他用例的数据错开
+	if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: ns, Date: day, BillableCost: 10}); err != nil {
+		t.Fatalf("SaveDailyNamespaceCost: %v", err)
+	}
+	period := postgres.AccountingPeriod{Label: "conformance-2020-04", StartDate: day, EndDate: day.AddDate(0, 1, 0),
+		StartTime: day, EndTime: day.AddDate(0, 1, 0)}
+	if err := s.CloseAccountingPeriod(ctx, period); err != nil {
+		t.Fatalf("CloseAccountingPeriod: %v", err)
+	}
+	overlapping := period
+	overlapping.StartDate, overlapping.Label = day.AddDate(0, 0, 10), "conformance-overlap"
+	if err := s.CloseAccountingPeriod(ctx, overlapping); !errors.Is(err, postgres.ErrPeriodClosed) {
+		t.Errorf("overlapping close: %v, want ErrPeriodClosed", err)
+	}
+
+	// 关账后普通写入被拒绝；重算写入保留原记录，差额记为更正
+	if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: ns, Date: day, BillableCost: 12}); !errors.Is(err, postgres.ErrPeriodClosed) {
+		t.Errorf("write to a closed period: %v, want ErrPeriodClosed", err)
+	}
+	recalc := postgres.WithRecalculation(ctx, "conformance")
+	if err := repo.SaveDailyNamespaceCost(recalc, postgres.DailyNamespaceCost{Namespace: ns, Date: day, BillableCost: 12}); err != nil {
+		t.Fatalf("recalculation: %v", err)
+	}
+	if got, err := repo.GetDailyNamespaceCost(ctx, ns, day); err != nil || got.BillableCost != 10 {
+		t.Errorf("closed record = %+v, %v; want it unchanged", got, err)
+	}
+	if _, err := s.SaveAdjustment(ctx, postgres.Adjustment{Record: postgres.AdjustmentRecordDailyNamespaceCost,
+		Date: day.AddDate(0, 2, 0), Namespace: ns, Amount: 1, Source: postgres.AdjustmentSourceManual}); !errors.Is(err, dataerr.ErrValidation) {
+		t.Errorf("adjustment outside a closed period: %v, want validation error", err)
+	}
+	manual, err := s.SaveAdjustment(ctx, postgres.Adjustment{Record: postgres.AdjustmentRecordDailyNamespaceCost,
+		Date: day, Namespace: ns, Amount: -0.5, Source: postgres.AdjustmentSourceManual, Reason: "credit", CreatedBy: "ops",
+		Status: postgres.AdjustmentPending})
+	if err != nil {
+		t.Fatalf("SaveAdjustment: %v", err)
+	}
+	if got, err := s.GetAdjustment(ctx, manual.ID); err != nil || !got.Pending() || got.CreatedBy != "ops" {
+		t.Errorf("GetAdjustment = %+v, %v; want the pending adjustment", got, err)
+	}
+	if approved, err := s.ApproveAdjustment(ctx, manual.ID, "finance"); err != nil || approved.Pending() || approved.Approver != "finance" || approved.ApprovedAt == nil {
+		t.Errorf("ApproveAdjustment = %+v, %v", approved, err)
+	}
+	if _, err := s.ApproveAdjustment(ctx, manual.ID, "finance"); !errors.Is(err, dataerr.ErrConflict) {
+		t.Errorf("approving twice: %v, want conflict", err)
+	}
+	if _, err := s.ApproveAdjustment(ctx, "adjustment-missing", "finance"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("approving a missing adjustment: %v, want not found", err)
+	}
+	adjustments, err := s.ListAdjustments(ctx, postgres.AdjustmentFilter{Namespace: ns})
+	if err != nil || len(adjustments) != 2 {
+		t.Fatalf("ListAdjustments = %+v, %v", adjustments, err)
+	}
+	if a := adjustments[0]; a.Source != postgres.AdjustmentSourceRecalculation || a.Amount != 2 || a.Period != period.Label || a.Reason != "conformance" {
+		t.Errorf("recalculation adjustment = %+v", a)
+	}
+	if a := adjustments[1]; a.Source != postgres.AdjustmentSourceManual || a.Amount != -0.5 || a.Approver != "finance" || a.Pending() {
+		t.Errorf("manual adjustment = %+v", a)
+	}
+}
+
+func testDownsample(t *testing.T, repo postgres.Repository) {
+	ctx := context.Background()
+	s := store[interface {
+		DownsampleHourlyWorkloadStats(ctx context.Context, before time.Time) (postgres.DownsampleResult, error)
+		ListDailyWorkloadStats(ctx context.Context, filter postgres.DailyWorkloadStatFilter) ([]postgres.DailyWorkloadStat, error)
+	}](t, repo)
+
+	// 早于其他用例数据的一天，降采样只会处理这些小时
+	day := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	const ns = "conformance-downsample"
+	for h := 0; h < 3; h++ {
+		if err := repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: ns, WorkloadName: "api",
+			Timestamp: day.Add(time.Duration(h) * time.Hour), CPURequest: 1, TotalBillableCost: 1}); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	if _, err := s.DownsampleHourlyWorkloadStats(ctx, day.Add(2*time.Hour)); err != nil {
+		t.Fatalf("DownsampleHourlyWorkloadStats: %v", err)
+	}
+	if _, err := s.DownsampleHourlyWorkloadStats(ctx, day.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("DownsampleHourlyWorkloadStats (rest of the day): %v", err)
+	}
+	days, err := s.ListDailyWorkloadStats(ctx, postgres.DailyWorkloadStatFilter{Namespace: ns})
+	if err != nil || len(days) != 1 {
+		t.Fatalf("ListDailyWorkloadStats = %+v, %v", days, err)
+	}
+	if d := days[0]; !d.Date.Equal(day) || d.Hours != 3 || d.TotalBillableCost != 3 {
+		t.Errorf("rollup = %+v, want the three hours merged", d)
+	}
+	hours, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: ns})
+	if err != nil || len(hours) != 0 {
+		t.Errorf("hours left after downsampling = %d, %v", len(hours), err)
+	}
+}
@@ -0,0 +1,1049 @@
+// Package storage stores.go: the wrappers (chaos, query budgets, encryption) forward the optional stores
+// of the wrapped repository (calculation runs, price history, service accounts, alert rules, ...), which
+// postgres.Repository does not declare, so services built on a wrapper keep them and drills and budgets
+// cover them like the core methods.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// ErrStoreUnsupported 底层 Repository 没有被调用的可选存储。
+var ErrStoreUnsupported = errors.New("repository does not support this store")
+
+// storeHook runs before each forwarded call; done runs after it, and an error fails the call without
+// reaching the wrapped repository.
+type storeHook func(ctx context.Context) (done func(), err error)
+
+// stores forwards the optional store methods to repo through hook (nil: calls pass straight through).
+// Every storage driver implements all of them (see storagetest); a method repo lacks fails with
+// ErrStoreUnsupported.
+type stores struct {
+	repo postgres.Repository
+	hook storeHook
+}
+
+func (s stores) enter(ctx context.Context) (func(), error) {
+	if s.hook == nil {
+		return func() {}, nil
+	}
+	return s.hook(ctx)
+}
+
+func unsupported(method string) error {
+	return fmt.Errorf("%w: %s", ErrStoreUnsupported, method)
+}
+
+// SaveBillAccountSummary forwards to the wrapped repository.
+func (s stores) SaveBillAccountSummary(ctx context.Context, summary postgres.BillAccountSummary) error {
+	r, ok := s.repo.(interface {
+		SaveBillAccountSummary(context.Context, postgres.BillAccountSummary) error
+	})
+	if !ok {
+		return unsupported("SaveBillAccountSummary")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveBillAccountSummary(ctx, summary)
+}
+
+// GetBillAccountSummary forwards to the wrapped repository.
+func (s stores) GetBillAccountSummary(ctx context.Context, accountID, periodType string, periodStart time.Time) (*postgres.BillAccountSummary, error) {
+	r, ok := s.repo.(interface {
+		GetBillAccountSummary(context.Context, string, string, time.Time) (*postgres.BillAccountSummary, error)
+	})
+	if !ok {
+		return nil, unsupported("GetBillAccountSummary")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetBillAccountSummary(ctx, accountID, periodType, periodStart)
+}
+
+// ListBillAccountSummaries forwards to the wrapped repository.
+func (s stores) ListBillAccountSummaries(ctx context.Context, accountID string) ([]postgres.BillAccountSummary, error) {
+	r, ok := s.repo.(interface {
+		ListBillAccountSummaries(context.Context, string) ([]postgres.BillAccountSummary, error)
+	})
+	if !ok {
+		return nil, unsupported("ListBillAccountSummaries")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListBillAccountSummaries(ctx, accountID)
+}
+
+// SaveDailyStorageCost forwards to the wrapped repository.
+func (s stores) SaveDailyStorageCost(ctx context.Context, c postgres.DailyStorageCost) error {
+	r, ok := s.repo.(interface {
+		SaveDailyStorageCost(context.Context, postgres.DailyStorageCost) error
+	})
+	if !ok {
+		return unsupported("SaveDailyStorageCost")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveDailyStorageCost(ctx, c)
+}
+
+// GetDailyStorageCost forwards to the wrapped repository.
+func (s stores) GetDailyStorageCost(ctx context.Context, day time.Time, namespace, pvcName string) (*postgres.DailyStorageCost, error) {
+	r, ok := s.repo.(interface {
+		GetDailyStorageCost(context.Context, time.Time, string, string) (*postgres.DailyStorageCost, error)
+	})
+	if !ok {
+		return nil, unsupported("GetDailyStorageCost")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetDailyStorageCost(ctx, day, namespace, pvcName)
+}
+
+// SaveDailyNetworkCost forwards to the wrapped repository.
+func (s stores) SaveDailyNetworkCost(ctx context.Context, c postgres.DailyNetworkCost) error {
+	r, ok := s.repo.(interface {
+		SaveDailyNetworkCost(context.Context, postgres.DailyNetworkCost) error
+	})
+	if !ok {
+		return unsupported("SaveDailyNetworkCost")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveDailyNetworkCost(ctx, c)
+}
+
+// GetDailyNetworkCost forwards to the wrapped repository.
+func (s stores) GetDailyNetworkCost(ctx context.Context, day time.Time, namespace, resourceID string) (*postgres.DailyNetworkCost, error) {
+	r, ok := s.repo.(interface {
+		GetDailyNetworkCost(context.Context, time.Time, string, string) (*postgres.DailyNetworkCost, error)
+	})
+	if !ok {
+		return nil, unsupported("GetDailyNetworkCost")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetDailyNetworkCost(ctx, day, namespace, resourceID)
+}
+
+// SaveCalculationRun forwards to the wrapped repository.
+func (s stores) SaveCalculationRun(ctx context.Context, run postgres.CalculationRun) error {
+	r, ok := s.repo.(interface {
+		SaveCalculationRun(context.Context, postgres.CalculationRun) error
+	})
+	if !ok {
+		return unsupported("SaveCalculationRun")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveCalculationRun(ctx, run)
+}
+
+// GetCalculationRun forwards to the wrapped repository.
+func (s stores) GetCalculationRun(ctx context.Context, id string) (*postgres.CalculationRun, error) {
+	r, ok := s.repo.(interface {
+		GetCalculationRun(context.Context, string) (*postgres.CalculationRun, error)
+	})
+	if !ok {
+		return nil, unsupported("GetCalculationRun")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetCalculationRun(ctx, id)
+}
+
+// ListCalculationRuns forwards to the wrapped repository.
+func (s stores) ListCalculationRuns(ctx context.Context, filter postgres.CalculationRunFilter) ([]postgres.CalculationRun, error) {
+	r, ok := s.repo.(interface {
+		ListCalculationRuns(context.Context, postgres.CalculationRunFilter) ([]postgres.CalculationRun, error)
+	})
+	if !ok {
+		return nil, unsupported("ListCalculationRuns")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListCalculationRuns(ctx, filter)
+}
+
+// SaveNamespaceLifecycle forwards to the wrapped repository.
+func (s stores) SaveNamespaceLifecycle(ctx context.Context, lifecycle postgres.NamespaceLifecycle) error {
+	r, ok := s.repo.(interface {
+		SaveNamespaceLifecycle(context.Context, postgres.NamespaceLifecycle) error
+	})
+	if !ok {
+		return unsupported("SaveNamespaceLifecycle")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveNamespaceLifecycle(ctx, lifecycle)
+}
+
+// ListNamespaceLifecycles forwards to the wrapped repository.
+func (s stores) ListNamespaceLifecycles(ctx context.Context) ([]postgres.NamespaceLifecycle, error) {
+	r, ok := s.repo.(interface {
+		ListNamespaceLifecycles(context.Context) ([]postgres.NamespaceLifecycle, error)
+	})
+	if !ok {
+		return nil, unsupported("ListNamespaceLifecycles")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListNamespaceLifecycles(ctx)
+}
+
+// SavePriceVersion forwards to the wrapped repository.
+func (s stores) SavePriceVersion(ctx context.Context, version postgres.PriceVersion) error {
+	r, ok := s.repo.(interface {
+		SavePriceVersion(context.Context, postgres.PriceVersion) error
+	})
+	if !ok {
+		return unsupported("SavePriceVersion")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SavePriceVersion(ctx, version)
+}
+
+// ListPriceVersions forwards to the wrapped repository.
+func (s stores) ListPriceVersions(ctx context.Context) ([]postgres.PriceVersion, error) {
+	r, ok := s.repo.(interface {
+		ListPriceVersions(context.Context) ([]postgres.PriceVersion, error)
+	})
+	if !ok {
+		return nil, unsupported("ListPriceVersions")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListPriceVersions(ctx)
+}
+
+// SavePriceChange forwards to the wrapped repository.
+func (s stores) SavePriceChange(ctx context.Context, change postgres.PriceChange) (postgres.PriceChange, error) {
+	r, ok := s.repo.(interface {
+		SavePriceChange(context.Context, postgres.PriceChange) (postgres.PriceChange, error)
+	})
+	if !ok {
+		return postgres.PriceChange{}, unsupported("SavePriceChange")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.PriceChange{}, err
+	}
+	defer done()
+	return r.SavePriceChange(ctx, change)
+}
+
+// GetPriceChange forwards to the wrapped repository.
+func (s stores) GetPriceChange(ctx context.Context, id string) (*postgres.PriceChange, error) {
+	r, ok := s.repo.(interface {
+		GetPriceChange(context.Context, string) (*postgres.PriceChange, error)
+	})
+	if !ok {
+		return nil, unsupported("GetPriceChange")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetPriceChange(ctx, id)
+}
+
+// ListPriceChanges forwards to the wrapped repository.
+func (s stores) ListPriceChanges(ctx context.Context) ([]postgres.PriceChange, error) {
+	r, ok := s.repo.(interface {
+		ListPriceChanges(context.Context) ([]postgres.PriceChange, error)
+	})
+	if !ok {
+		return nil, unsupported("ListPriceChanges")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListPriceChanges(ctx)
+}
+
+// SaveGradeChangeEvent forwards to the wrapped repository.
+func (s stores) SaveGradeChangeEvent(ctx context.Context, event postgres.GradeChangeEvent) error {
+	r, ok := s.repo.(interface {
+		SaveGradeChangeEvent(context.Context, postgres.GradeChangeEvent) error
+	})
+	if !ok {
+		return unsupported("SaveGradeChangeEvent")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveGradeChangeEvent(ctx, event)
+}
+
+// ListGradeChangeEvents forwards to the wrapped repository.
+func (s stores) ListGradeChangeEvents(ctx context.Context, filter postgres.GradeChangeEventFilter) ([]postgres.GradeChangeEvent, error) {
+	r, ok := s.repo.(interface {
+		ListGradeChangeEvents(context.Context, postgres.GradeChangeEventFilter) ([]postgres.GradeChangeEvent, error)
+	})
+	if !ok {
+		return nil, unsupported("ListGradeChangeEvents")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListGradeChangeEvents(ctx, filter)
+}
+
+// SaveSnapshotRegrade forwards to the wrapped repository.
+func (s stores) SaveSnapshotRegrade(ctx context.Context, regrade postgres.SnapshotRegrade) error {
+	r, ok := s.repo.(interface {
+		SaveSnapshotRegrade(context.Context, postgres.SnapshotRegrade) error
+	})
+	if !ok {
+		return unsupported("SaveSnapshotRegrade")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveSnapshotRegrade(ctx, regrade)
+}
+
+// GetSnapshotRegrade forwards to the wrapped repository.
+func (s stores) GetSnapshotRegrade(ctx context.Context, snapshotID string) (*postgres.SnapshotRegrade, error) {
+	r, ok := s.repo.(interface {
+		GetSnapshotRegrade(context.Context, string) (*postgres.SnapshotRegrade, error)
+	})
+	if !ok {
+		return nil, unsupported("GetSnapshotRegrade")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetSnapshotRegrade(ctx, snapshotID)
+}
+
+// SaveIssuedRecommendation forwards to the wrapped repository.
+func (s stores) SaveIssuedRecommendation(ctx context.Context, rec postgres.IssuedRecommendation) error {
+	r, ok := s.repo.(interface {
+		SaveIssuedRecommendation(context.Context, postgres.IssuedRecommendation) error
+	})
+	if !ok {
+		return unsupported("SaveIssuedRecommendation")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveIssuedRecommendation(ctx, rec)
+}
+
+// ListIssuedRecommendations forwards to the wrapped repository.
+func (s stores) ListIssuedRecommendations(ctx context.Context, filter postgres.IssuedRecommendationFilter) ([]postgres.IssuedRecommendation, error) {
+	r, ok := s.repo.(interface {
+		ListIssuedRecommendations(context.Context, postgres.IssuedRecommendationFilter) ([]postgres.IssuedRecommendation, error)
+	})
+	if !ok {
+		return nil, unsupported("ListIssuedRecommendations")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListIssuedRecommendations(ctx, filter)
+}
+
+// SaveOptimizationRecord forwards to the wrapped repository.
+func (s stores) SaveOptimizationRecord(ctx context.Context, rec roi.OptimizationTrackingRecord) (roi.OptimizationTrackingRecord, error) {
+	r, ok := s.repo.(interface {
+		SaveOptimizationRecord(context.Context, roi.OptimizationTrackingRecord) (roi.OptimizationTrackingRecord, error)
+	})
+	if !ok {
+		return roi.OptimizationTrackingRecord{}, unsupported("SaveOptimizationRecord")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return roi.OptimizationTrackingRecord{}, err
+	}
+	defer done()
+	return r.SaveOptimizationRecord(ctx, rec)
+}
+
+// ListOptimizationRecords forwards to the wrapped repository.
+func (s stores) ListOptimizationRecords(ctx context.Context, filter postgres.OptimizationRecordFilter) ([]roi.OptimizationTrackingRecord, error) {
+	r, ok := s.repo.(interface {
+		ListOptimizationRecords(context.Context, postgres.OptimizationRecordFilter) ([]roi.OptimizationTrackingRecord, error)
+	})
+	if !ok {
+		return nil, unsupported("ListOptimizationRecords")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListOptimizationRecords(ctx, filter)
+}
+
+// SaveServiceAccount forwards to the wrapped repository.
+func (s stores) SaveServiceAccount(ctx context.Context, account postgres.ServiceAccount) (postgres.ServiceAccount, error) {
+	r, ok := s.repo.(interface {
+		SaveServiceAccount(context.Context, postgres.ServiceAccount) (postgres.ServiceAccount, error)
+	})
+	if !ok {
+		return postgres.ServiceAccount{}, unsupported("SaveServiceAccount")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.ServiceAccount{}, err
+	}
+	defer done()
+	return r.SaveServiceAccount(ctx, account)
+}
+
+// GetServiceAccount forwards to the wrapped repository.
+func (s stores) GetServiceAccount(ctx context.Context, id string) (*postgres.ServiceAccount, error) {
+	r, ok := s.repo.(interface {
+		GetServiceAccount(context.Context, string) (*postgres.ServiceAccount, error)
+	})
+	if !ok {
+		return nil, unsupported("GetServiceAccount")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetServiceAccount(ctx, id)
+}
+
+// ListServiceAccounts forwards to the wrapped repository.
+func (s stores) ListServiceAccounts(ctx context.Context) ([]postgres.ServiceAccount, error) {
+	r, ok := s.repo.(interface {
+		ListServiceAccounts(context.Context) ([]postgres.ServiceAccount, error)
+	})
+	if !ok {
+		return nil, unsupported("ListServiceAccounts")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListServiceAccounts(ctx)
+}
+
+// DeleteServiceAccount forwards to the wrapped repository.
+func (s stores) DeleteServiceAccount(ctx context.Context, id string) error {
+	r, ok := s.repo.(interface {
+		DeleteServiceAccount(context.Context, string) error
+	})
+	if !ok {
+		return unsupported("DeleteServiceAccount")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.DeleteServiceAccount(ctx, id)
+}
+
+// SaveAPIKey forwards to the wrapped repository.
+func (s stores) SaveAPIKey(ctx context.Context, key postgres.APIKey) (postgres.APIKey, error) {
+	r, ok := s.repo.(interface {
+		SaveAPIKey(context.Context, postgres.APIKey) (postgres.APIKey, error)
+	})
+	if !ok {
+		return postgres.APIKey{}, unsupported("SaveAPIKey")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.APIKey{}, err
+	}
+	defer done()
+	return r.SaveAPIKey(ctx, key)
+}
+
+// GetAPIKeyByHash forwards to the wrapped repository.
+func (s stores) GetAPIKeyByHash(ctx context.Context, secretHash string) (*postgres.APIKey, error) {
+	r, ok := s.repo.(interface {
+		GetAPIKeyByHash(context.Context, string) (*postgres.APIKey, error)
+	})
+	if !ok {
+		return nil, unsupported("GetAPIKeyByHash")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetAPIKeyByHash(ctx, secretHash)
+}
+
+// ListAPIKeys forwards to the wrapped repository.
+func (s stores) ListAPIKeys(ctx context.Context, accountID string) ([]postgres.APIKey, error) {
+	r, ok := s.repo.(interface {
+		ListAPIKeys(context.Context, string) ([]postgres.APIKey, error)
+	})
+	if !ok {
+		return nil, unsupported("ListAPIKeys")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListAPIKeys(ctx, accountID)
+}
+
+// IncrementAPIKeyUsage forwards to the wrapped repository.
+func (s stores) IncrementAPIKeyUsage(ctx context.Context, keyID string, date time.Time, quota int) (postgres.APIKeyUsage, bool, error) {
+	r, ok := s.repo.(interface {
+		IncrementAPIKeyUsage(context.Context, string, time.Time, int) (postgres.APIKeyUsage, bool, error)
+	})
+	if !ok {
+		return postgres.APIKeyUsage{}, false, unsupported("IncrementAPIKeyUsage")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.APIKeyUsage{}, false, err
+	}
+	defer done()
+	return r.IncrementAPIKeyUsage(ctx, keyID, date, quota)
+}
+
+// ListAPIKeyUsage forwards to the wrapped repository.
+func (s stores) ListAPIKeyUsage(ctx context.Context, keyIDs []string, startDate, endDate time.Time) ([]postgres.APIKeyUsage, error) {
+	r, ok := s.repo.(interface {
+		ListAPIKeyUsage(context.Context, []string, time.Time, time.Time) ([]postgres.APIKeyUsage, error)
+	})
+	if !ok {
+		return nil, unsupported("ListAPIKeyUsage")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListAPIKeyUsage(ctx, keyIDs, startDate, endDate)
+}
+
+// SaveAlertRule forwards to the wrapped repository.
+func (s stores) SaveAlertRule(ctx context.Context, rule postgres.AlertRule) (postgres.AlertRule, error) {
+	r, ok := s.repo.(interface {
+		SaveAlertRule(context.Context, postgres.AlertRule) (postgres.AlertRule, error)
+	})
+	if !ok {
+		return postgres.AlertRule{}, unsupported("SaveAlertRule")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.AlertRule{}, err
+	}
+	defer done()
+	return r.SaveAlertRule(ctx, rule)
+}
+
+// GetAlertRule forwards to the wrapped repository.
+func (s stores) GetAlertRule(ctx context.Context, id string) (*postgres.AlertRule, error) {
+	r, ok := s.repo.(interface {
+		GetAlertRule(context.Context, string) (*postgres.AlertRule, error)
+	})
+	if !ok {
+		return nil, unsupported("GetAlertRule")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetAlertRule(ctx, id)
+}
+
+// ListAlertRules forwards to the wrapped repository.
+func (s stores) ListAlertRules(ctx context.Context) ([]postgres.AlertRule, error) {
+	r, ok := s.repo.(interface {
+		ListAlertRules(context.Context) ([]postgres.AlertRule, error)
+	})
+	if !ok {
+		return nil, unsupported("ListAlertRules")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListAlertRules(ctx)
+}
+
+// DeleteAlertRule forwards to the wrapped repository.
+func (s stores) DeleteAlertRule(ctx context.Context, id string) error {
+	r, ok := s.repo.(interface {
+		DeleteAlertRule(context.Context, string) error
+	})
+	if !ok {
+		return unsupported("DeleteAlertRule")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.DeleteAlertRule(ctx, id)
+}
+
+// SaveNotificationRoute forwards to the wrapped repository.
+func (s stores) SaveNotificationRoute(ctx context.Context, route postgres.NotificationRoute) (postgres.NotificationRoute, error) {
+	r, ok := s.repo.(interface {
+		SaveNotificationRoute(context.Context, postgres.NotificationRoute) (postgres.NotificationRoute, error)
+	})
+	if !ok {
+		return postgres.NotificationRoute{}, unsupported("SaveNotificationRoute")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.NotificationRoute{}, err
+	}
+	defer done()
+	return r.SaveNotificationRoute(ctx, route)
+}
+
+// GetNotificationRoute forwards to the wrapped repository.
+func (s stores) GetNotificationRoute(ctx context.Context, id string) (*postgres.NotificationRoute, error) {
+	r, ok := s.repo.(interface {
+		GetNotificationRoute(context.Context, string) (*postgres.NotificationRoute, error)
+	})
+	if !ok {
+		return nil, unsupported("GetNotificationRoute")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetNotificationRoute(ctx, id)
+}
+
+// ListNotificationRoutes forwards to the wrapped repository.
+func (s stores) ListNotificationRoutes(ctx context.Context) ([]postgres.NotificationRoute, error) {
+	r, ok := s.repo.(interface {
+		ListNotificationRoutes(context.Context) ([]postgres.NotificationRoute, error)
+	})
+	if !ok {
+		return nil, unsupported("ListNotificationRoutes")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListNotificationRoutes(ctx)
+}
+
+// DeleteNotificationRoute forwards to the wrapped repository.
+func (s stores) DeleteNotificationRoute(ctx context.Context, id string) error {
+	r, ok := s.repo.(interface {
+		DeleteNotificationRoute(context.Context, string) error
+	})
+	if !ok {
+		return unsupported("DeleteNotificationRoute")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.DeleteNotificationRoute(ctx, id)
+}
+
+// SaveNotificationSilence forwards to the wrapped repository.
+func (s stores) SaveNotificationSilence(ctx context.Context, silence postgres.NotificationSilence) (postgres.NotificationSilence, error) {
+	r, ok := s.repo.(interface {
+		SaveNotificationSilence(context.Context, postgres.NotificationSilence) (postgres.NotificationSilence, error)
+	})
+	if !ok {
+		return postgres.NotificationSilence{}, unsupported("SaveNotificationSilence")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.NotificationSilence{}, err
+	}
+	defer done()
+	return r.SaveNotificationSilence(ctx, silence)
+}
+
+// GetNotificationSilence forwards to the wrapped repository.
+func (s stores) GetNotificationSilence(ctx context.Context, id string) (*postgres.NotificationSilence, error) {
+	r, ok := s.repo.(interface {
+		GetNotificationSilence(context.Context, string) (*postgres.NotificationSilence, error)
+	})
+	if !ok {
+		return nil, unsupported("GetNotificationSilence")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetNotificationSilence(ctx, id)
+}
+
+// ListNotificationSilences forwards to the wrapped repository.
+func (s stores) ListNotificationSilences(ctx context.Context, activeAt time.Time) ([]postgres.NotificationSilence, error) {
+	r, ok := s.repo.(interface {
+		ListNotificationSilences(context.Context, time.Time) ([]postgres.NotificationSilence, error)
+	})
+	if !ok {
+		return nil, unsupported("ListNotificationSilences")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListNotificationSilences(ctx, activeAt)
+}
+
+// SaveQueryLineage forwards to the wrapped repository.
+func (s stores) SaveQueryLineage(ctx context.Context, lineage postgres.QueryLineage) error {
+	r, ok := s.repo.(interface {
+		SaveQueryLineage(context.Context, postgres.QueryLineage) error
+	})
+	if !ok {
+		return unsupported("SaveQueryLineage")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveQueryLineage(ctx, lineage)
+}
+
+// GetQueryLineage forwards to the wrapped repository.
+func (s stores) GetQueryLineage(ctx context.Context, id string) (*postgres.QueryLineage, error) {
+	r, ok := s.repo.(interface {
+		GetQueryLineage(context.Context, string) (*postgres.QueryLineage, error)
+	})
+	if !ok {
+		return nil, unsupported("GetQueryLineage")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetQueryLineage(ctx, id)
+}
+
+// SaveConfigChange forwards to the wrapped repository.
+func (s stores) SaveConfigChange(ctx context.Context, change postgres.ConfigChange) error {
+	r, ok := s.repo.(interface {
+		SaveConfigChange(context.Context, postgres.ConfigChange) error
+	})
+	if !ok {
+		return unsupported("SaveConfigChange")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveConfigChange(ctx, change)
+}
+
+// ListConfigChanges forwards to the wrapped repository.
+func (s stores) ListConfigChanges(ctx context.Context, filter postgres.ConfigChangeFilter) ([]postgres.ConfigChange, error) {
+	r, ok := s.repo.(interface {
+		ListConfigChanges(context.Context, postgres.ConfigChangeFilter) ([]postgres.ConfigChange, error)
+	})
+	if !ok {
+		return nil, unsupported("ListConfigChanges")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListConfigChanges(ctx, filter)
+}
+
+// SaveSLOViolation forwards to the wrapped repository.
+func (s stores) SaveSLOViolation(ctx context.Context, violation postgres.SLOViolation) error {
+	r, ok := s.repo.(interface {
+		SaveSLOViolation(context.Context, postgres.SLOViolation) error
+	})
+	if !ok {
+		return unsupported("SaveSLOViolation")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveSLOViolation(ctx, violation)
+}
+
+// ListSLOViolations forwards to the wrapped repository.
+func (s stores) ListSLOViolations(ctx context.Context, filter postgres.SLOViolationFilter) ([]postgres.SLOViolation, error) {
+	r, ok := s.repo.(interface {
+		ListSLOViolations(context.Context, postgres.SLOViolationFilter) ([]postgres.SLOViolation, error)
+	})
+	if !ok {
+		return nil, unsupported("ListSLOViolations")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListSLOViolations(ctx, filter)
+}
+
+// SaveDependencyEdges forwards to the wrapped repository.
+func (s stores) SaveDependencyEdges(ctx context.Context, edges []postgres.DependencyEdge) error {
+	r, ok := s.repo.(interface {
+		SaveDependencyEdges(context.Context, []postgres.DependencyEdge) error
+	})
+	if !ok {
+		return unsupported("SaveDependencyEdges")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.SaveDependencyEdges(ctx, edges)
+}
+
+// ListDependencyEdges forwards to the wrapped repository.
+func (s stores) ListDependencyEdges(ctx context.Context, filter postgres.DependencyEdgeFilter) ([]postgres.DependencyEdge, error) {
+	r, ok := s.repo.(interface {
+		ListDependencyEdges(context.Context, postgres.DependencyEdgeFilter) ([]postgres.DependencyEdge, error)
+	})
+	if !ok {
+		return nil, unsupported("ListDependencyEdges")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListDependencyEdges(ctx, filter)
+}
+
+// DeleteDependencyEdges forwards to the wrapped repository.
+func (s stores) DeleteDependencyEdges(ctx context.Context, filter postgres.DependencyEdgeFilter) (int, error) {
+	r, ok := s.repo.(interface {
+		DeleteDependencyEdges(context.Context, postgres.DependencyEdgeFilter) (int, error)
+	})
+	if !ok {
+		return 0, unsupported("DeleteDependencyEdges")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+	return r.DeleteDependencyEdges(ctx, filter)
+}
+
+// CloseAccountingPeriod forwards to the wrapped repository.
+func (s stores) CloseAccountingPeriod(ctx context.Context, period postgres.AccountingPeriod) error {
+	r, ok := s.repo.(interface {
+		CloseAccountingPeriod(context.Context, postgres.AccountingPeriod) error
+	})
+	if !ok {
+		return unsupported("CloseAccountingPeriod")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return r.CloseAccountingPeriod(ctx, period)
+}
+
+// ListAccountingPeriods forwards to the wrapped repository.
+func (s stores) ListAccountingPeriods(ctx context.Context) ([]postgres.AccountingPeriod, error) {
+	r, ok := s.repo.(interface {
+		ListAccountingPeriods(context.Context) ([]postgres.AccountingPeriod, error)
+	})
+	if !ok {
+		return nil, unsupported("ListAccountingPeriods")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListAccountingPeriods(ctx)
+}
+
+// SaveAdjustment forwards to the wrapped repository.
+func (s stores) SaveAdjustment(ctx context.Context, adj postgres.Adjustment) (postgres.Adjustment, error) {
+	r, ok := s.repo.(interface {
+		SaveAdjustment(context.Context, postgres.Adjustment) (postgres.Adjustment, error)
+	})
+	if !ok {
+		return postgres.Adjustment{}, unsupported("SaveAdjustment")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.Adjustment{}, err
+	}
+	defer done()
+	return r.SaveAdjustment(ctx, adj)
+}
+
+// GetAdjustment forwards to the wrapped repository.
+func (s stores) GetAdjustment(ctx context.Context, id string) (*postgres.Adjustment, error) {
+	r, ok := s.repo.(interface {
+		GetAdjustment(context.Context, string) (*postgres.Adjustment, error)
+	})
+	if !ok {
+		return nil, unsupported("GetAdjustment")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.GetAdjustment(ctx, id)
+}
+
+// ApproveAdjustment forwards to the wrapped repository.
+func (s stores) ApproveAdjustment(ctx context.Context, id, approver string) (postgres.Adjustment, error) {
+	r, ok := s.repo.(interface {
+		ApproveAdjustment(context.Context, string, string) (postgres.Adjustment, error)
+	})
+	if !ok {
+		return postgres.Adjustment{}, unsupported("ApproveAdjustment")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.Adjustment{}, err
+	}
+	defer done()
+	return r.ApproveAdjustment(ctx, id, approver)
+}
+
+// ListAdjustments forwards to the wrapped repository.
+func (s stores) ListAdjustments(ctx context.Context, filter postgres.AdjustmentFilter) ([]postgres.Adjustment, error) {
+	r, ok := s.repo.(interface {
+		ListAdjustments(context.Context, postgres.AdjustmentFilter) ([]postgres.Adjustment, error)
+	})
+	if !ok {
+		return nil, unsupported("ListAdjustments")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListAdjustments(ctx, filter)
+}
+
+// DownsampleHourlyWorkloadStats forwards to the wrapped repository.
+func (s stores) DownsampleHourlyWorkloadStats(ctx context.Context, before time.Time) (postgres.DownsampleResult, error) {
+	r, ok := s.repo.(interface {
+		DownsampleHourlyWorkloadStats(context.Context, time.Time) (postgres.DownsampleResult, error)
+	})
+	if !ok {
+		return postgres.DownsampleResult{}, unsupported("DownsampleHourlyWorkloadStats")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return postgres.DownsampleResult{}, err
+	}
+	defer done()
+	return r.DownsampleHourlyWorkloadStats(ctx, before)
+}
+
+// ListDailyWorkloadStats forwards to the wrapped repository.
+func (s stores) ListDailyWorkloadStats(ctx context.Context, filter postgres.DailyWorkloadStatFilter) ([]postgres.DailyWorkloadStat, error) {
+	r, ok := s.repo.(interface {
+		ListDailyWorkloadStats(context.Context, postgres.DailyWorkloadStatFilter) ([]postgres.DailyWorkloadStat, error)
+	})
+	if !ok {
+		return nil, unsupported("ListDailyWorkloadStats")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListDailyWorkloadStats(ctx, filter)
+}
+
+// ListSnapshotHashChain forwards to the wrapped repository.
+func (s stores) ListSnapshotHashChain(ctx context.Context) ([]postgres.SnapshotHashRecord, error) {
+	r, ok := s.repo.(interface {
+		ListSnapshotHashChain(context.Context) ([]postgres.SnapshotHashRecord, error)
+	})
+	if !ok {
+		return nil, unsupported("ListSnapshotHashChain")
+	}
+	done, err := s.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return r.ListSnapshotHashChain(ctx)
+}
@@ -0,0 +1,220 @@
+// Package eventbus bus.go: 发布器，负责批量投递、重试与未确认事件的保留（at-least-once）。
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOutboxFull Outbox 已满，新事件被拒绝（避免下游长时间不可用时内存无限增长）。
+var ErrOutboxFull = errors.New("event outbox is full")
+
+// Record 一条待写入 Kafka 的消息。
+type Record struct {
+	Key   string
+	Value []byte
+}
+
+// Producer 将一批消息写入 topic；仅当整批都被 broker 确认后返回 nil。
+type Producer interface {
+	Produce(ctx context.Context, topic string, records []Record) error
+}
+
+// Config 发布器配置。
+type Config struct {
+	Topic  string
+	Source string // Envelope.Source，默认 "lighthouse"
+	// MaxRetries 单次 Flush 内失败后最多重试次数；仍失败的事件留在 outbox 等待下次 Flush。
+	MaxRetries int
+	RetryDelay time.Duration
+	// OutboxSize outbox 最大事件数；<=0 使用默认 10000。
+	OutboxSize int
+	// BatchSize 每次 Produce 的最大消息数；<=0 使用默认 100。
+	BatchSize int
+	// Outbox 未确认事件的存储；nil 使用内存 outbox（重启丢失），需要持久化时用 SpoolOutbox。
+	Outbox Outbox
+}
+
+// DefaultConfig 返回默认配置。
+func DefaultConfig() Config {
+	return Config{
+		Topic:      "lighthouse.events",
+		Source:     "lighthouse",
+		MaxRetries: 3,
+		RetryDelay: 500 * time.Millisecond,
+		OutboxSize: 10000,
+		BatchSize:  100,
+	}
+}
+
+// Bus 事件发布器。Publish 先写入 outbox，Flush 按序投递；只有被 Producer 确认的事件才从 outbox 移除，
+// 因此投递失败或重试成功都可能产生重复，消费者需按 Envelope.ID 幂等处理。
+type Bus struct {
+	producer Producer
+	config   Config
+
+	mu     sync.Mutex
+	outbox Outbox
+
+	flushMu sync.Mutex
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// New 创建 Bus。
+func New(producer Producer, config Config) *Bus {
+	def := DefaultConfig()
+	if config.Topic == "" {
+		config.Topic = def.Topic
+	}
+	if config.Source == "" {
+		config.Source = def.Source
+	}
+	if config.OutboxSize <= 0 {
+		config.OutboxSize = def.OutboxSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = def.BatchSize
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	outbox := config.Outbox
+	if outbox == nil {
+		outbox = &memoryOutbox{}
+	}
+	return &Bus{producer: producer, config: config, outbox: outbox, now: time.Now, sleep: sleepContext}
+}
+
+// Publish 封装并入队事件，不立即投递。
+func (b *Bus) Publish(payloads ...Payload) error {
+	envelopes := make([]Envelope, 0, len(payloads))
+	for _, p := range payloads {
+		env, err := NewEnvelope(b.config.Source, p, b.now())
+		if err != nil {
+			return err
+		}
+		envelopes = append(envelopes, env)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.outbox.Len()+len(envelopes) > b.config.OutboxSize {
+		return ErrOutboxFull
+	}
+	return b.outbox.Append(envelopes)
+}
+
+// PublishAndFlush 入队并立即投递。
+func (b *Bus) PublishAndFlush(ctx context.Context, payloads ...Payload) error {
+	if err := b.Publish(payloads...); err != nil {
+		return err
+	}
+	return b.Flush(ctx)
+}
+
+// Pending 返回 outbox 中未确认事件数。
+func (b *Bus) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.outbox.Len()
+}
+
+// Flush 按批次投递 outbox 中的事件。某批重试后仍失败时停止并返回错误，该批及之后的事件保留。
+func (b *Bus) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	for {
+		b.mu.Lock()
+		batch, err := b.outbox.Peek(b.config.BatchSize)
+		b.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		records, err := toRecords(batch)
+		if err != nil {
+			return err
+		}
+		if err := b.produceWithRetry(ctx, records); err != nil {
+			return err
+		}
+
+		b.mu.Lock()
+		err = b.outbox.Remove(len(batch))
+		b.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Run 每隔 interval 执行一次 Flush，直到 ctx 取消；退出前尽力再 Flush 一次。
+func (b *Bus) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := b.Flush(flushCtx); err != nil && onError != nil {
+				onError(err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := b.Flush(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (b *Bus) produceWithRetry(ctx context.Context, records []Record) error {
+	var lastErr error
+	delay := b.config.RetryDelay
+	for attempt := 0; attempt <= b.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := b.sleep(ctx, delay); err != nil {
+				return err
+			}
+			delay *= 2
+		}
+		if lastErr = b.producer.Produce(ctx, b.config.Topic, records); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("produce to %s after %d attempts: %w", b.config.Topic, b.config.MaxRetries+1, lastErr)
+}
+
+func toRecords(envelopes []Envelope) ([]Record, error) {
+	records := make([]Record, 0, len(envelopes))
+	for _, env := range envelopes {
+		value, err := marshalEnvelope(env)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, Record{Key: env.Key, Value: value})
+	}
+	return records, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
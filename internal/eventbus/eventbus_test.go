@@ -0,0 +1,194 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+func noSleep(ctx context.Context, d time.Duration) error { return nil }
+
+func TestBusPublishAndFlush(t *testing.T) {
+	ctx := context.Background()
+	producer := NewMockProducer()
+	bus := New(producer, Config{Topic: "events", BatchSize: 2})
+
+	err := bus.PublishAndFlush(ctx,
+		BudgetExceeded{Scope: "payments", Period: "2020-01", BudgetAmount: 100, ActualAmount: 120},
+		SLOViolatedFrom(slo.SLOViolationEvent{Namespace: "prod", ServiceName: "api", ViolationType: "latency"}),
+		ZombieDetected{Namespace: "dev", WorkloadName: "old-job", WorkloadType: "Deployment"},
+	)
+	if err != nil {
+		t.Fatalf("PublishAndFlush: %v", err)
+	}
+	envs := producer.Envelopes("events")
+	if len(envs) != 3 || bus.Pending() != 0 {
+		t.Fatalf("got %d envelopes, %d pending", len(envs), bus.Pending())
+	}
+	if envs[1].Type != EventSLOViolated || envs[1].Key != "prod/api" || envs[1].SchemaVersion != SchemaVersion {
+		t.Errorf("unexpected envelope %+v", envs[1])
+	}
+	var payload SLOViolated
+	if err := json.Unmarshal(envs[1].Payload, &payload); err != nil || payload.ViolationType != "latency" {
+		t.Errorf("payload = %+v, %v", payload, err)
+	}
+}
+
+func TestBusKeepsUnacknowledgedEvents(t *testing.T) {
+	ctx := context.Background()
+	producer := NewMockProducer()
+	bus := New(producer, Config{Topic: "events", MaxRetries: 1})
+	bus.sleep = noSleep
+
+	producer.FailNext(2, errors.New("broker unavailable"))
+	if err := bus.PublishAndFlush(ctx, RecommendationIssued{RecommendationID: "r1", Namespace: "prod"}); err == nil {
+		t.Fatal("expected flush error")
+	}
+	if bus.Pending() != 1 {
+		t.Fatalf("pending = %d, want 1", bus.Pending())
+	}
+	if err := bus.Flush(ctx); err != nil {
+		t.Fatalf("second Flush: %v", err)
+	}
+	if bus.Pending() != 0 || len(producer.Envelopes("events")) != 1 {
+		t.Errorf("event not delivered after recovery")
+	}
+}
+
+func TestBusOutboxFull(t *testing.T) {
+	bus := New(NewMockProducer(), Config{OutboxSize: 1})
+	_ = bus.Publish(BudgetExceeded{Scope: "a"})
+	if err := bus.Publish(BudgetExceeded{Scope: "b"}); !errors.Is(err, ErrOutboxFull) {
+		t.Errorf("expected ErrOutboxFull, got %v", err)
+	}
+}
+
+func TestSpoolOutboxSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	outbox, err := NewSpoolOutbox(dir)
+	if err != nil {
+		t.Fatalf("NewSpoolOutbox: %v", err)
+	}
+	producer := NewMockProducer()
+	bus := New(producer, Config{Topic: "events", BatchSize: 2, Outbox: outbox})
+	bus.sleep = noSleep
+	producer.FailNext(1, errors.New("broker unavailable"))
+	if err := bus.PublishAndFlush(ctx, ZombieDetected{Namespace: "dev", WorkloadName: "a"}, ZombieDetected{Namespace: "dev", WorkloadName: "b"},
+		ZombieDetected{Namespace: "dev", WorkloadName: "c"}); err == nil {
+		t.Fatal("expected flush error")
+	}
+
+	// 重启：新进程打开同一目录，未确认事件按原顺序投递
+	reopened, err := NewSpoolOutbox(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	restarted := New(producer, Config{Topic: "events", BatchSize: 2, Outbox: reopened})
+	if restarted.Pending() != 3 {
+		t.Fatalf("pending after restart = %d, want 3", restarted.Pending())
+	}
+	if err := restarted.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	envs := producer.Envelopes("events")
+	if len(envs) != 3 || restarted.Pending() != 0 {
+		t.Fatalf("got %d envelopes, %d pending", len(envs), restarted.Pending())
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		if want := "dev//" + name; envs[i].Key != want {
+			t.Errorf("envelope %d key = %q, want %q", i, envs[i].Key, want)
+		}
+	}
+}
+
+func TestPublishingRepository(t *testing.T) {
+	ctx := context.Background()
+	producer := NewMockProducer()
+	bus := New(producer, DefaultConfig())
+	repo := &PublishingRepository{Repository: postgres.NewMockRepository(postgres.DefaultMockConfig()), Bus: bus}
+
+	if err := repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{TotalBillableCost: 10}); err != nil {
+		t.Fatalf("SaveCostSnapshot: %v", err)
+	}
+	if err := bus.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	envs := producer.Envelopes(DefaultConfig().Topic)
+	if len(envs) != 1 || envs[0].Type != EventSnapshotCreated || envs[0].Key == "" {
+		t.Fatalf("unexpected envelopes %+v", envs)
+	}
+	if _, err := repo.GetCostSnapshot(ctx, envs[0].Key); err != nil {
+		t.Errorf("event key should reference stored snapshot: %v", err)
+	}
+}
+
+func TestPublishingRepositoryHoldsUnconfirmedSnapshots(t *testing.T) {
+	ctx := context.Background()
+	producer := NewMockProducer()
+	bus := New(producer, DefaultConfig())
+	repo := &PublishingRepository{Repository: postgres.NewMockRepository(postgres.DefaultMockConfig()), Bus: bus}
+
+	held := postgres.CostSnapshot{ID: "held", TotalBillableCost: 1e6, Suspect: true, RequiresConfirmation: true}
+	if err := repo.SaveCostSnapshot(ctx, held); err != nil {
+		t.Fatalf("SaveCostSnapshot: %v", err)
+	}
+	_ = bus.Flush(ctx)
+	if envs := producer.Envelopes(DefaultConfig().Topic); len(envs) != 0 {
+		t.Fatalf("snapshot awaiting confirmation was published: %+v", envs)
+	}
+
+	now := time.Now()
+	held.ConfirmedBy, held.ConfirmedAt = "finops", &now
+	if err := repo.SaveCostSnapshot(ctx, held); err != nil {
+		t.Fatalf("SaveCostSnapshot: %v", err)
+	}
+	_ = bus.Flush(ctx)
+	if envs := producer.Envelopes(DefaultConfig().Topic); len(envs) != 1 || envs[0].Key != "held" {
+		t.Errorf("confirmed snapshot not published once: %+v", envs)
+	}
+}
+
+func TestRESTProxyProducer(t *testing.T) {
+	var gotPath, gotType string
+	var gotValue []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotType = r.URL.Path, r.Header.Get("Content-Type")
+		var body struct {
+			Records []restProxyRecord `json:"records"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Records) > 0 {
+			gotValue, _ = base64.StdEncoding.DecodeString(body.Records[0].Value)
+		}
+		if strings.Contains(string(gotValue), "reject") {
+			_, _ = w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"Kafka error"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":42}]}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewRESTProxyProducer(RESTProxyConfig{URL: srv.URL + "/"})
+	if err != nil {
+		t.Fatalf("NewRESTProxyProducer: %v", err)
+	}
+	if err := p.Produce(context.Background(), "lighthouse.events", []Record{{Key: "k", Value: []byte(`{"a":1}`)}}); err != nil {
+		t.Fatalf("Produce: %v", err)
+	}
+	if gotPath != "/topics/lighthouse.events" || gotType != restProxyContentType || string(gotValue) != `{"a":1}` {
+		t.Errorf("path=%s type=%s value=%s", gotPath, gotType, gotValue)
+	}
+	if err := p.Produce(context.Background(), "t", []Record{{Value: []byte("reject")}}); err == nil {
+		t.Error("expected error for rejected record")
+	}
+}
@@ -0,0 +1,201 @@
+// Package eventbus 将成本与 SLO 领域事件发布到 Kafka，供下游数据平台消费，无需轮询 API。
+// 事件统一封装为带 schema 版本的 Envelope，投递语义为 at-least-once（消费者按 Envelope.ID 去重）。
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// EventType 领域事件类型。
+type EventType string
+
+const (
+	EventSnapshotCreated      EventType = "lighthouse.snapshot.created"
+	EventBudgetExceeded       EventType = "lighthouse.budget.exceeded"
+	EventSLOViolated          EventType = "lighthouse.slo.violated"
+	EventZombieDetected       EventType = "lighthouse.workload.zombie_detected"
+	EventRecommendationIssued EventType = "lighthouse.recommendation.issued"
+	EventGradeChanged         EventType = "lighthouse.workload.grade_changed"
+)
+
+// SchemaVersion 当前 payload schema 版本；字段只增不删，破坏性变更时递增。
+const SchemaVersion = 1
+
+// Envelope 事件信封，Kafka 消息 value 即其 JSON。
+type Envelope struct {
+	ID            string          `json:"id"`
+	Type          EventType       `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	Source        string          `json:"source"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Key           string          `json:"key"` // Kafka 分区 key，同一实体的事件保持有序
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Payload 事件 payload，由具体事件结构实现。
+type Payload interface {
+	EventType() EventType
+	// PartitionKey 同一实体（namespace/服务/工作负载）返回相同 key。
+	PartitionKey() string
+}
+
+// SnapshotCreated 成本快照已生成。
+type SnapshotCreated struct {
+	SnapshotID             string    `json:"snapshot_id"`
+	CalculationID          string    `json:"calculation_id"`
+	TimeRangeStart         time.Time `json:"time_range_start"`
+	TimeRangeEnd           time.Time `json:"time_range_end"`
+	TotalBillableCost      float64   `json:"total_billable_cost"`
+	TotalUsageCost         float64   `json:"total_usage_cost"`
+	TotalWasteCost         float64   `json:"total_waste_cost"`
+	OverallEfficiencyScore float64   `json:"overall_efficiency_score"`
+	ZombieCount            int       `json:"zombie_count"`
+	OverProvisionedCount   int       `json:"over_provisioned_count"`
+}
+
+func (SnapshotCreated) EventType() EventType   { return EventSnapshotCreated }
+func (e SnapshotCreated) PartitionKey() string { return e.SnapshotID }
+
+// BudgetExceeded 预算超支。
+type BudgetExceeded struct {
+	Scope        string  `json:"scope"` // namespace 或 "cluster"
+	Period       string  `json:"period"`
+	BudgetAmount float64 `json:"budget_amount"`
+	ActualAmount float64 `json:"actual_amount"`
+	Currency     string  `json:"currency,omitempty"`
+}
+
+func (BudgetExceeded) EventType() EventType   { return EventBudgetExceeded }
+func (e BudgetExceeded) PartitionKey() string { return e.Scope }
+
+// SLOViolated SLO 违约。
+type SLOViolated struct {
+	Namespace      string        `json:"namespace"`
+	Service        string        `json:"service"`
+	ViolationType  string        `json:"violation_type"` // availability / latency / error_rate
+	ActualValue    float64       `json:"actual_value"`
+	ThresholdValue float64       `json:"threshold_value"`
+	Duration       time.Duration `json:"duration"`
+	ViolationTime  time.Time     `json:"violation_time"`
+}
+
+func (SLOViolated) EventType() EventType   { return EventSLOViolated }
+func (e SLOViolated) PartitionKey() string { return e.Namespace + "/" + e.Service }
+
+// ZombieDetected 识别到僵尸工作负载。
+type ZombieDetected struct {
+	Namespace       string  `json:"namespace"`
+	WorkloadName    string  `json:"workload_name"`
+	WorkloadType    string  `json:"workload_type"`
+	WasteCost       float64 `json:"waste_cost"`
+	EfficiencyScore float64 `json:"efficiency_score"`
+}
+
+func (ZombieDetected) EventType() EventType { return EventZombieDetected }
+func (e ZombieDetected) PartitionKey() string {
+	return e.Namespace + "/" + e.WorkloadType + "/" + e.WorkloadName
+}
+
+// RecommendationIssued 发布了一条优化建议。
+type RecommendationIssued struct {
+	RecommendationID string  `json:"recommendation_id"`
+	Namespace        string  `json:"namespace"`
+	WorkloadName     string  `json:"workload_name,omitempty"`
+	Action           string  `json:"action"` // 如 downsize / delete / rightsize
+	Description      string  `json:"description"`
+	EstimatedSavings float64 `json:"estimated_savings"`
+}
+
+func (RecommendationIssued) EventType() EventType { return EventRecommendationIssued }
+func (e RecommendationIssued) PartitionKey() string {
+	return e.Namespace + "/" + e.WorkloadName
+}
+
+// GradeChanged 工作负载效率等级发生变化（已做迟滞处理，不含首次评级）。
+type GradeChanged struct {
+	Namespace       string    `json:"namespace"`
+	WorkloadName    string    `json:"workload_name"`
+	WorkloadType    string    `json:"workload_type"`
+	FromGrade       string    `json:"from_grade"`
+	ToGrade         string    `json:"to_grade"`
+	EfficiencyScore float64   `json:"efficiency_score"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+func (GradeChanged) EventType() EventType { return EventGradeChanged }
+func (e GradeChanged) PartitionKey() string {
+	return e.Namespace + "/" + e.WorkloadType + "/" + e.WorkloadName
+}
+
+// NewEnvelope 将 payload 封装为信封。
+func NewEnvelope(source string, p Payload, occurredAt time.Time) (Envelope, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("marshal %s payload: %w", p.EventType(), err)
+	}
+	return Envelope{
+		ID:            uuid.New().String(),
+		Type:          p.EventType(),
+		SchemaVersion: SchemaVersion,
+		Source:        source,
+		OccurredAt:    occurredAt.UTC(),
+		Key:           p.PartitionKey(),
+		Payload:       data,
+	}, nil
+}
+
+// SnapshotCreatedFrom 由成本快照构造事件。
+func SnapshotCreatedFrom(s postgres.CostSnapshot) SnapshotCreated {
+	return SnapshotCreated{
+		SnapshotID:             s.ID,
+		CalculationID:          s.CalculationID,
+		TimeRangeStart:         s.TimeRangeStart,
+		TimeRangeEnd:           s.TimeRangeEnd,
+		TotalBillableCost:      s.TotalBillableCost,
+		TotalUsageCost:         s.TotalUsageCost,
+		TotalWasteCost:         s.TotalWasteCost,
+		OverallEfficiencyScore: s.OverallEfficiencyScore,
+		ZombieCount:            s.ZombieCount,
+		OverProvisionedCount:   s.OverProvisionedCount,
+	}
+}
+
+// SLOViolatedFrom 由 SLO 违约事件构造事件。
+func SLOViolatedFrom(v slo.SLOViolationEvent) SLOViolated {
+	return SLOViolated{
+		Namespace:      v.Namespace,
+		Service:        v.ServiceName,
+		ViolationType:  v.ViolationType,
+		ActualValue:    v.ActualValue,
+		ThresholdValue: v.ThresholdValue,
+		Duration:       v.Duration,
+		ViolationTime:  v.ViolationTime,
+	}
+}
+
+// GradeChangedFrom 由等级变更记录构造事件。
+func GradeChangedFrom(e postgres.GradeChangeEvent) GradeChanged {
+	return GradeChanged{
+		Namespace:       e.Namespace,
+		WorkloadName:    e.WorkloadName,
+		WorkloadType:    e.WorkloadType,
+		FromGrade:       e.FromGrade,
+		ToGrade:         e.ToGrade,
+		EfficiencyScore: e.EfficiencyScore,
+		ChangedAt:       e.OccurredAt,
+	}
+}
+
+func marshalEnvelope(env Envelope) ([]byte, error) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s envelope: %w", env.Type, err)
+	}
+	return data, nil
+}
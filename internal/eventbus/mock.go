@@ -0,0 +1,54 @@
+// Package eventbus provides mock implementations for testing.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// MockProducer 内存 Producer，记录全部已确认消息；FailNext 可模拟 broker 故障。
+type MockProducer struct {
+	mu       sync.Mutex
+	messages map[string][]Record
+	failNext int
+	err      error
+}
+
+// NewMockProducer 创建 MockProducer。
+func NewMockProducer() *MockProducer {
+	return &MockProducer{messages: make(map[string][]Record)}
+}
+
+// FailNext 让接下来 n 次 Produce 返回 err。
+func (m *MockProducer) FailNext(n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failNext, m.err = n, err
+}
+
+// Produce implements Producer.
+func (m *MockProducer) Produce(ctx context.Context, topic string, records []Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failNext > 0 {
+		m.failNext--
+		return m.err
+	}
+	m.messages[topic] = append(m.messages[topic], records...)
+	return nil
+}
+
+// Envelopes 返回 topic 中已确认的事件。
+func (m *MockProducer) Envelopes(topic string) []Envelope {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Envelope, 0, len(m.messages[topic]))
+	for _, r := range m.messages[topic] {
+		var env Envelope
+		if err := json.Unmarshal(r.Value, &env); err == nil {
+			out = append(out, env)
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+// Package eventbus outbox.go: 未确认事件的存储。默认保存在内存，进程重启即丢失；SpoolOutbox 逐条落盘，
+// 重启后继续投递此前未被 broker 确认的事件。
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/myxxhui/lighthouse-src/internal/worker/spool"
+)
+
+// Outbox 按入队顺序保存未确认事件。Bus 串行调用 Peek 与 Remove，期间只会有新事件追加到末尾。
+type Outbox interface {
+	// Append 追加事件。
+	Append(envelopes []Envelope) error
+	// Peek 返回最早的至多 n 个事件，不移除。
+	Peek(n int) ([]Envelope, error)
+	// Remove 移除最早的 n 个事件（已被 Producer 确认）。
+	Remove(n int) error
+	// Len 返回未确认事件数。
+	Len() int
+}
+
+// memoryOutbox 内存 outbox。
+type memoryOutbox struct {
+	envelopes []Envelope
+}
+
+func (o *memoryOutbox) Append(envelopes []Envelope) error {
+	o.envelopes = append(o.envelopes, envelopes...)
+	return nil
+}
+
+func (o *memoryOutbox) Peek(n int) ([]Envelope, error) {
+	if n > len(o.envelopes) {
+		n = len(o.envelopes)
+	}
+	return append([]Envelope(nil), o.envelopes[:n]...), nil
+}
+
+func (o *memoryOutbox) Remove(n int) error {
+	if n > len(o.envelopes) {
+		n = len(o.envelopes)
+	}
+	o.envelopes = o.envelopes[n:]
+	return nil
+}
+
+func (o *memoryOutbox) Len() int { return len(o.envelopes) }
+
+// spoolKind 事件在 spool 中的条目类型。
+const spoolKind = "event"
+
+// SpoolOutbox 把每个事件作为一条 spool 条目持久化到本地目录。目录应专用于事件，不与写库失败的
+// dead-letter 条目混用（否则 Replay 会跳过这些条目，Peek 也会读到非事件条目）。
+type SpoolOutbox struct {
+	spool *spool.Spool
+
+	mu     sync.Mutex
+	peeked []string // 最近一次 Peek 返回的条目 ID，Remove 据此删除
+}
+
+// NewSpoolOutbox 打开（必要时创建）dir 作为事件 outbox；目录中已有的事件会在下次 Flush 时投递。
+func NewSpoolOutbox(dir string) (*SpoolOutbox, error) {
+	sp, err := spool.Open(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("event outbox: %w", err)
+	}
+	return &SpoolOutbox{spool: sp}, nil
+}
+
+// Append implements Outbox.
+func (o *SpoolOutbox) Append(envelopes []Envelope) error {
+	for _, env := range envelopes {
+		if _, err := o.spool.Enqueue(spoolKind, env); err != nil {
+			return fmt.Errorf("event outbox: %w", err)
+		}
+	}
+	return nil
+}
+
+// Peek implements Outbox.
+func (o *SpoolOutbox) Peek(n int) ([]Envelope, error) {
+	entries, err := o.spool.Oldest(n)
+	if err != nil {
+		return nil, fmt.Errorf("event outbox: %w", err)
+	}
+	envelopes := make([]Envelope, 0, len(entries))
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		var env Envelope
+		if err := json.Unmarshal(e.Payload, &env); err != nil {
+			return nil, fmt.Errorf("event outbox: decode %s: %w", e.ID, err)
+		}
+		envelopes = append(envelopes, env)
+		ids = append(ids, e.ID)
+	}
+	o.mu.Lock()
+	o.peeked = ids
+	o.mu.Unlock()
+	return envelopes, nil
+}
+
+// Remove implements Outbox.
+func (o *SpoolOutbox) Remove(n int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if n > len(o.peeked) {
+		return fmt.Errorf("event outbox: remove %d events, only %d peeked", n, len(o.peeked))
+	}
+	for _, id := range o.peeked[:n] {
+		if err := o.spool.Delete(id); err != nil {
+			return fmt.Errorf("event outbox: %w", err)
+		}
+	}
+	o.peeked = o.peeked[n:]
+	return nil
+}
+
+// Len implements Outbox.
+func (o *SpoolOutbox) Len() int {
+	return o.spool.Stats().Pending
+}
@@ -0,0 +1,33 @@
+// Package eventbus repository.go: 在快照写入成功后发布 SnapshotCreated。
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// PublishingRepository wraps a Repository and publishes SnapshotCreated for every saved snapshot.
+// Events are only queued here; the Bus flush loop delivers them.
+type PublishingRepository struct {
+	postgres.Repository
+	Bus *Bus
+}
+
+// SaveCostSnapshot saves the snapshot and queues a SnapshotCreated event. Snapshots without an ID
+// get one here so the event references the stored row. Snapshots awaiting confirmation are
+// published when they are saved again after confirmation.
+func (r *PublishingRepository) SaveCostSnapshot(ctx context.Context, snapshot postgres.CostSnapshot) error {
+	if snapshot.ID == "" {
+		snapshot.ID = fmt.Sprintf("snapshot-%d", time.Now().UnixNano())
+	}
+	if err := r.Repository.SaveCostSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	if snapshot.AwaitingConfirmation() {
+		return nil
+	}
+	return r.Bus.Publish(SnapshotCreatedFrom(snapshot))
+}
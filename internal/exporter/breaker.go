@@ -0,0 +1,37 @@
+// Package exporter breaker.go: 导出外部数据源熔断器状态。
+package exporter
+
+import (
+	"context"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+)
+
+// Circuit breaker metric names.
+const (
+	MetricBreakerState    = "lighthouse_circuit_breaker_state"
+	MetricBreakerRejected = "lighthouse_circuit_breaker_rejected_total"
+)
+
+// breakerStateValue 0=closed 1=half_open 2=open，便于直接按阈值告警。
+var breakerStateValue = map[breaker.State]float64{
+	breaker.StateClosed:   0,
+	breaker.StateHalfOpen: 1,
+	breaker.StateOpen:     2,
+}
+
+// BreakerCollector 返回熔断器指标的 Collector（每个 source/target 一个样本）。
+func BreakerCollector(sets ...*breaker.Set) Collector {
+	return func(ctx context.Context) []Family {
+		state := Family{Name: MetricBreakerState, Help: "Circuit breaker state per data source target (0=closed, 1=half_open, 2=open).", Type: "gauge"}
+		rejected := Family{Name: MetricBreakerRejected, Help: "Calls short-circuited by the circuit breaker since start.", Type: "counter"}
+		for _, set := range sets {
+			for _, st := range set.Statuses() {
+				labels := map[string]string{"source": st.Source, "target": st.Target}
+				state.Samples = append(state.Samples, Sample{Labels: labels, Value: breakerStateValue[st.State]})
+				rejected.Samples = append(rejected.Samples, Sample{Labels: labels, Value: float64(st.Rejected)})
+			}
+		}
+		return []Family{state, rejected}
+	}
+}
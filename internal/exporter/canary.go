@@ -0,0 +1,56 @@
+// Package exporter canary.go: 管道合成监控（etl.PipelineCanary）的端到端延迟、正确性与告警计数。
+package exporter
+
+import (
+	"context"
+
+	"github.com/myxxhui/lighthouse-src/internal/worker/etl"
+)
+
+// Pipeline canary metric names.
+const (
+	MetricCanarySuccess      = "lighthouse_canary_success"
+	MetricCanaryLatency      = "lighthouse_canary_latency_seconds"
+	MetricCanaryStageLatency = "lighthouse_canary_stage_latency_seconds"
+	MetricCanaryLastSuccess  = "lighthouse_canary_last_success_timestamp_seconds"
+	MetricCanaryRuns         = "lighthouse_canary_runs_total"
+	MetricCanaryFailures     = "lighthouse_canary_failures_total"
+	MetricCanaryAlerts       = "lighthouse_canary_alerts_total"
+)
+
+// CanaryCollector 返回管道合成监控指标的 Collector。成功与延迟取最近一次检查（首次检查前不导出），
+// 阶段延迟只包含已完成的阶段。
+func CanaryCollector(c *etl.PipelineCanary) Collector {
+	return func(ctx context.Context) []Family {
+		stats := c.Stats()
+		success := Family{Name: MetricCanarySuccess, Help: "Whether the last pipeline canary check passed within the latency limit (0/1).", Type: "gauge"}
+		latency := Family{Name: MetricCanaryLatency, Help: "End-to-end latency of the last pipeline canary check.", Type: "gauge"}
+		stages := Family{Name: MetricCanaryStageLatency, Help: "Latency per stage of the last pipeline canary check.", Type: "gauge"}
+		lastSuccess := Family{Name: MetricCanaryLastSuccess, Help: "Unix time of the last passed pipeline canary check.", Type: "gauge"}
+		if r := stats.Last; r != nil {
+			v := 0.0
+			if r.OK() {
+				v = 1
+			}
+			success.Samples = append(success.Samples, Sample{Value: v})
+			latency.Samples = append(latency.Samples, Sample{Value: r.Latency.Seconds()})
+			for _, stage := range []string{etl.CanaryStageCalculate, etl.CanaryStageReadBack} {
+				if d, ok := r.Stages[stage]; ok {
+					stages.Samples = append(stages.Samples, Sample{Labels: map[string]string{"stage": stage}, Value: d.Seconds()})
+				}
+			}
+		}
+		if !stats.LastSuccess.IsZero() {
+			lastSuccess.Samples = append(lastSuccess.Samples, Sample{Value: float64(stats.LastSuccess.Unix())})
+		}
+		return []Family{
+			success,
+			latency,
+			stages,
+			lastSuccess,
+			{Name: MetricCanaryRuns, Help: "Pipeline canary checks since start.", Type: "counter", Samples: []Sample{{Value: float64(stats.Runs)}}},
+			{Name: MetricCanaryFailures, Help: "Failed pipeline canary checks since start.", Type: "counter", Samples: []Sample{{Value: float64(stats.Failures)}}},
+			{Name: MetricCanaryAlerts, Help: "Pipeline canary alerts sent since start.", Type: "counter", Samples: []Sample{{Value: float64(stats.Alerts)}}},
+		}
+	}
+}
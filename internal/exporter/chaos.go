@@ -0,0 +1,44 @@
+// Package exporter chaos.go: 导出故障演练状态——演练期间的告警与看板异常可据此与真实故障区分。
+package exporter
+
+import (
+	"context"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+)
+
+// Chaos drill metric names.
+const (
+	MetricChaosDrillActive    = "lighthouse_chaos_drill_active"
+	MetricChaosInjectedCalls  = "lighthouse_chaos_injected_calls_total"
+	MetricChaosInjectedErrors = "lighthouse_chaos_injected_errors_total"
+)
+
+// ChaosCollector 返回故障演练指标的 Collector：演练是否进行中，以及当前（或最近一次）演练按数据源注入的调用与错误数。
+func ChaosCollector(c *chaos.Controller) Collector {
+	return func(ctx context.Context) []Family {
+		active := Family{Name: MetricChaosDrillActive, Help: "1 while a chaos drill injects faults into the data clients.", Type: "gauge"}
+		calls := Family{Name: MetricChaosInjectedCalls, Help: "Data source calls delayed or failed by the current or last chaos drill.", Type: "counter"}
+		failures := Family{Name: MetricChaosInjectedErrors, Help: "Data source calls failed by the current or last chaos drill.", Type: "counter"}
+		drill, ok := c.Last()
+		if !ok {
+			active.Samples = append(active.Samples, Sample{Value: 0})
+			return []Family{active}
+		}
+		v := 0.0
+		if c.Active() {
+			v = 1
+		}
+		active.Samples = append(active.Samples, Sample{Labels: map[string]string{"scenario": drill.Scenario}, Value: v})
+		for _, source := range chaos.Sources {
+			n, ok := drill.Injected[source]
+			if !ok {
+				continue
+			}
+			labels := map[string]string{"scenario": drill.Scenario, "source": string(source)}
+			calls.Samples = append(calls.Samples, Sample{Labels: labels, Value: float64(n.Calls)})
+			failures.Samples = append(failures.Samples, Sample{Labels: labels, Value: float64(n.Errors)})
+		}
+		return []Family{active, calls, failures}
+	}
+}
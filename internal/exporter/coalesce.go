@@ -0,0 +1,35 @@
+// Package exporter coalesce.go: 导出成本查询的请求合并计数——调用数与实际计算次数之差即被合并的请求。
+package exporter
+
+import (
+	"context"
+	"sort"
+
+	"github.com/myxxhui/lighthouse-src/internal/server/service"
+)
+
+// Query coalescing metric names.
+const (
+	MetricQueryCalls      = "lighthouse_query_calls_total"
+	MetricQueryExecutions = "lighthouse_query_executions_total"
+)
+
+// CoalesceCollector 返回请求合并指标的 Collector（每个查询一组样本）。
+func CoalesceCollector(c *service.QueryCoalescer) Collector {
+	return func(ctx context.Context) []Family {
+		calls := Family{Name: MetricQueryCalls, Help: "Calls per coalesced service query since start.", Type: "counter"}
+		executions := Family{Name: MetricQueryExecutions, Help: "Computations per coalesced service query since start; the other calls shared an identical query in flight.", Type: "counter"}
+		stats := c.Stats()
+		queries := make([]string, 0, len(stats))
+		for q := range stats {
+			queries = append(queries, q)
+		}
+		sort.Strings(queries)
+		for _, q := range queries {
+			labels := map[string]string{"query": q}
+			calls.Samples = append(calls.Samples, Sample{Labels: labels, Value: float64(stats[q].Calls)})
+			executions.Samples = append(executions.Samples, Sample{Labels: labels, Value: float64(stats[q].Executions)})
+		}
+		return []Family{calls, executions}
+	}
+}
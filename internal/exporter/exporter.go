@@ -0,0 +1,202 @@
+// Package exporter 以 Prometheus 文本格式导出 Lighthouse 计算结果（成本、浪费、效率、错误预算），
+// 供现有告警体系直接基于成本与预算告警。不依赖 client_golang，按需在抓取时计算。
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/service"
+)
+
+// Metric names.
+const (
+	MetricNamespaceBillableCost   = "lighthouse_namespace_billable_cost"
+	MetricWorkloadWasteCost       = "lighthouse_workload_waste_cost"
+	MetricEfficiencyScore         = "lighthouse_efficiency_score"
+	MetricSLOErrorBudgetRemaining = "lighthouse_slo_error_budget_remaining"
+	metricScrapeErrors            = "lighthouse_exporter_scrape_errors"
+)
+
+// Sample 一个带标签的样本。
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+	Suffix string // 追加在指标名后，如 histogram 的 _bucket / _sum / _count
+}
+
+// Family 一个指标族。
+type Family struct {
+	Name    string
+	Help    string
+	Type    string // gauge / counter / histogram
+	Samples []Sample
+}
+
+// Config 导出配置。
+type Config struct {
+	// Window 成本统计窗口（向前回溯），默认 24h。
+	Window time.Duration
+}
+
+// Collector 额外指标来源（如 spool），抓取时调用。
+type Collector func(ctx context.Context) []Family
+
+// Exporter 抓取时从 Repository / SLO Provider 计算指标。
+type Exporter struct {
+	repo       postgres.Repository
+	slo        service.SLOStatusProvider
+	config     Config
+	now        func() time.Time
+	collectors []Collector
+}
+
+// New 创建 Exporter；sloProvider 可为 nil（不导出错误预算）。
+func New(repo postgres.Repository, sloProvider service.SLOStatusProvider, config Config) *Exporter {
+	if config.Window <= 0 {
+		config.Window = 24 * time.Hour
+	}
+	return &Exporter{repo: repo, slo: sloProvider, config: config, now: time.Now}
+}
+
+// AddCollector 注册额外指标来源，其指标族追加在内置指标之后。
+func (e *Exporter) AddCollector(c Collector) {
+	e.collectors = append(e.collectors, c)
+}
+
+// Collect 计算全部指标族。某个数据源失败时跳过对应指标族并计入 scrape_errors，其余照常导出。
+func (e *Exporter) Collect(ctx context.Context) []Family {
+	end := e.now()
+	start := end.Add(-e.config.Window)
+	var scrapeErrors float64
+
+	billable := Family{Name: MetricNamespaceBillableCost, Help: "Billable cost per namespace over the exporter window.", Type: "gauge"}
+	efficiency := Family{Name: MetricEfficiencyScore, Help: "Usage/billable efficiency score (0-100) per namespace over the exporter window.", Type: "gauge"}
+	if costs, err := e.repo.AggregateDailyNamespaceCosts(ctx, start, end); err == nil {
+		for _, c := range costs {
+			labels := map[string]string{"namespace": c.Namespace}
+			billable.Samples = append(billable.Samples, Sample{Labels: labels, Value: c.BillableCost})
+			score := 0.0
+			if c.BillableCost > 0 {
+				score = c.UsageCost / c.BillableCost * 100
+			}
+			efficiency.Samples = append(efficiency.Samples, Sample{Labels: labels, Value: score})
+		}
+	} else {
+		scrapeErrors++
+	}
+
+	waste := Family{Name: MetricWorkloadWasteCost, Help: "Waste cost per workload over the exporter window.", Type: "gauge"}
+	if stats, err := e.repo.AggregateHourlyWorkloadStats(ctx, start, end); err == nil {
+		for _, st := range stats {
+			waste.Samples = append(waste.Samples, Sample{
+				Labels: map[string]string{"namespace": st.Namespace, "workload": st.WorkloadName, "workload_type": st.WorkloadType},
+				Value:  st.TotalWasteCost,
+			})
+		}
+	} else {
+		scrapeErrors++
+	}
+
+	families := []Family{billable, waste, efficiency}
+
+	if e.slo != nil {
+		budget := Family{Name: MetricSLOErrorBudgetRemaining, Help: "Remaining SLO error budget percentage per service.", Type: "gauge"}
+		if statuses, err := e.slo.ListSLOStatus(ctx); err == nil {
+			for _, st := range statuses {
+				budget.Samples = append(budget.Samples, Sample{
+					Labels: map[string]string{"namespace": st.Namespace, "service": st.Service},
+					Value:  st.ErrorBudgetRemaining,
+				})
+			}
+		} else {
+			scrapeErrors++
+		}
+		families = append(families, budget)
+	}
+
+	for _, c := range e.collectors {
+		families = append(families, c(ctx)...)
+	}
+
+	families = append(families, Family{
+		Name:    metricScrapeErrors,
+		Help:    "Number of data sources that failed during the last scrape.",
+		Type:    "gauge",
+		Samples: []Sample{{Value: scrapeErrors}},
+	})
+	return families
+}
+
+// WriteText 以 Prometheus text exposition format 0.0.4 输出。样本按标签排序，保证输出稳定。
+func WriteText(w io.Writer, families []Family) error {
+	for _, f := range families {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.Name, escapeHelp(f.Help), f.Name, f.Type); err != nil {
+			return err
+		}
+		samples := append([]Sample(nil), f.Samples...)
+		sort.Slice(samples, func(i, j int) bool {
+			if samples[i].Suffix != samples[j].Suffix {
+				return samples[i].Suffix < samples[j].Suffix
+			}
+			return formatLabels(samples[i].Labels) < formatLabels(samples[j].Labels)
+		})
+		for _, s := range samples {
+			if _, err := fmt.Fprintf(w, "%s%s%s %s\n", f.Name, s.Suffix, formatLabels(s.Labels), formatValue(s.Value)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Handler 返回 /metrics 的 http.Handler。
+func (e *Exporter) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = WriteText(w, e.Collect(r.Context()))
+	})
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+`="`+escapeLabelValue(labels[k])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabelValue(s string) string { return labelValueEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
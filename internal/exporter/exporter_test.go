@@ -0,0 +1,251 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/apimetrics"
+	"github.com/myxxhui/lighthouse-src/internal/server/service"
+	"github.com/myxxhui/lighthouse-src/internal/worker/etl"
+	"github.com/myxxhui/lighthouse-src/internal/worker/spool"
+)
+
+func TestExporterHandler(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "app-prod", Date: now.Add(-time.Hour), BillableCost: 200, UsageCost: 150}); err != nil {
+		t.Fatalf("SaveDailyNamespaceCost: %v", err)
+	}
+	if err := repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "app-prod", WorkloadName: "api", WorkloadType: "Deployment", Timestamp: now.Add(-2 * time.Hour), TotalWasteCost: 12.5}); err != nil {
+		t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+	}
+
+	e := New(repo, service.DefaultMockSLOStatus(), Config{})
+	e.now = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	e.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE lighthouse_namespace_billable_cost gauge",
+		`lighthouse_namespace_billable_cost{namespace="app-prod"} 200`,
+		`lighthouse_efficiency_score{namespace="app-prod"} 75`,
+		`lighthouse_workload_waste_cost{namespace="app-prod",workload="api",workload_type="Deployment"} 12.5`,
+		`lighthouse_slo_error_budget_remaining{namespace="app-prod",service="payment-service"} 12`,
+		"lighthouse_exporter_scrape_errors 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q\n%s", want, body)
+		}
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestWriteTextEscapesLabels(t *testing.T) {
+	var b strings.Builder
+	err := WriteText(&b, []Family{{Name: "m", Help: "h", Type: "gauge", Samples: []Sample{{Labels: map[string]string{"l": "a\"b\\c\nd"}, Value: 1}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `m{l="a\"b\\c\nd"} 1`) {
+		t.Errorf("unexpected escaping: %s", b.String())
+	}
+}
+
+func TestSpoolCollector(t *testing.T) {
+	sp, err := spool.Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("spool.Open: %v", err)
+	}
+	if _, err := sp.Enqueue("hourly_workload_stats", []int{1}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	e := New(postgres.NewMockRepository(postgres.DefaultMockConfig()), nil, Config{})
+	e.AddCollector(SpoolCollector(sp))
+
+	var b strings.Builder
+	if err := WriteText(&b, e.Collect(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{
+		"lighthouse_spool_pending_batches 1",
+		"# TYPE lighthouse_spool_enqueued_total counter",
+		"lighthouse_spool_enqueued_total 1",
+		"lighthouse_spool_dropped_total 0",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics output missing %q\n%s", want, b.String())
+		}
+	}
+}
+
+func TestBreakerCollector(t *testing.T) {
+	set := breaker.NewSet("prometheus", breaker.Config{MinRequests: 1, OpenTimeout: time.Hour})
+	_ = set.Do("prom-a", func() error { return errors.New("down") })
+	_ = set.Do("prom-a", func() error { return nil })
+	_ = set.Do("prom-b", func() error { return nil })
+
+	var b strings.Builder
+	if err := WriteText(&b, BreakerCollector(set)(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{
+		`lighthouse_circuit_breaker_state{source="prometheus",target="prom-a"} 2`,
+		`lighthouse_circuit_breaker_state{source="prometheus",target="prom-b"} 0`,
+		`lighthouse_circuit_breaker_rejected_total{source="prometheus",target="prom-a"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics output missing %q\n%s", want, b.String())
+		}
+	}
+}
+
+func TestFreshnessCollector(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	repo := postgres.NewMockRepository(mockCfg)
+	_ = repo.SaveHourlyWorkloadStat(context.Background(), postgres.HourlyWorkloadStat{Namespace: "shop", WorkloadName: "api", Timestamp: time.Now().Add(-5 * time.Hour)})
+	w := service.NewStaleDataWatchdog(repo, time.Hour, 3)
+
+	var b strings.Builder
+	if err := WriteText(&b, FreshnessCollector(w)(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{
+		`lighthouse_data_age_seconds{source="hourly_workload_stats"} 18000`, // 5h, plus the test runtime
+		`lighthouse_data_stale{source="hourly_workload_stats"} 1`,
+		"lighthouse_data_stale_threshold_seconds 10800",
+		"lighthouse_data_stale_alerts_total 0",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics output missing %q\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), `source="cost_snapshots"`) {
+		t.Errorf("source without data should not be exported\n%s", b.String())
+	}
+}
+
+func TestCanaryCollector(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	// 未配置 API 客户端：计算阶段通过，读回阶段失败
+	canary := &etl.PipelineCanary{Store: postgres.NewMockRepository(mockCfg), CPUPricePerCoreHour: 0.05, MemPricePerGBHour: 0.01}
+
+	var b strings.Builder
+	if err := WriteText(&b, CanaryCollector(canary)(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	if strings.Contains(b.String(), "\n"+MetricCanarySuccess+" ") || !strings.Contains(b.String(), "lighthouse_canary_runs_total 0") {
+		t.Errorf("before the first check only counters should be exported\n%s", b.String())
+	}
+
+	if _, err := canary.Check(context.Background()); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	b.Reset()
+	if err := WriteText(&b, CanaryCollector(canary)(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{
+		"lighthouse_canary_success 0",
+		`lighthouse_canary_stage_latency_seconds{stage="calculate"}`,
+		"lighthouse_canary_runs_total 1",
+		"lighthouse_canary_failures_total 1",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics output missing %q\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), `stage="read_back"`) || strings.Contains(b.String(), "\n"+MetricCanaryLastSuccess+" ") {
+		t.Errorf("failed stage and missing success should not be exported\n%s", b.String())
+	}
+}
+
+func TestRequestCollector(t *testing.T) {
+	rec := apimetrics.NewRecorder()
+	rec.ObserveRequest("GET", "/api/v1/workloads/:id", 200, 20*time.Millisecond)
+	rec.ObserveRequest("GET", "/api/v1/workloads/:id", 503, 3*time.Second)
+
+	var b strings.Builder
+	if err := WriteText(&b, RequestCollector(rec)(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{
+		`lighthouse_http_requests_total{method="GET",route="/api/v1/workloads/:id"} 2`,
+		`lighthouse_http_request_errors_total{method="GET",route="/api/v1/workloads/:id"} 1`,
+		"# TYPE lighthouse_http_request_duration_seconds histogram",
+		`lighthouse_http_request_duration_seconds_bucket{le="0.025",method="GET",route="/api/v1/workloads/:id"} 1`,
+		`lighthouse_http_request_duration_seconds_bucket{le="5",method="GET",route="/api/v1/workloads/:id"} 2`,
+		`lighthouse_http_request_duration_seconds_bucket{le="+Inf",method="GET",route="/api/v1/workloads/:id"} 2`,
+		`lighthouse_http_request_duration_seconds_sum{method="GET",route="/api/v1/workloads/:id"} 3.02`,
+		`lighthouse_http_request_duration_seconds_count{method="GET",route="/api/v1/workloads/:id"} 2`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics output missing %q\n%s", want, b.String())
+		}
+	}
+}
+
+func TestCoalesceCollector(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.LatencyMs = 0
+	costs := service.NewCostService(postgres.NewMockRepository(mockCfg))
+	if _, err := costs.GetGlobalCost(context.Background()); err != nil {
+		t.Fatalf("GetGlobalCost: %v", err)
+	}
+
+	var b strings.Builder
+	if err := WriteText(&b, CoalesceCollector(costs.Coalescer())(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{
+		`lighthouse_query_calls_total{query="global_cost"} 1`,
+		`lighthouse_query_executions_total{query="global_cost"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics output missing %q\n%s", want, b.String())
+		}
+	}
+}
+
+func TestChaosCollector(t *testing.T) {
+	drills := chaos.NewController(map[string]chaos.Scenario{"prometheus-outage": {chaos.Prometheus: {ErrorRate: 1}}}, time.Hour)
+	var b strings.Builder
+	if err := WriteText(&b, ChaosCollector(drills)(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	if !strings.Contains(b.String(), "lighthouse_chaos_drill_active 0") {
+		t.Errorf("metrics output missing the inactive drill gauge\n%s", b.String())
+	}
+
+	if _, err := drills.Start("prometheus-outage", time.Minute, "ops"); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	_ = drills.Inject(context.Background(), chaos.Prometheus)
+	b.Reset()
+	if err := WriteText(&b, ChaosCollector(drills)(context.Background())); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{
+		`lighthouse_chaos_drill_active{scenario="prometheus-outage"} 1`,
+		`lighthouse_chaos_injected_calls_total{scenario="prometheus-outage",source="prometheus"} 1`,
+		`lighthouse_chaos_injected_errors_total{scenario="prometheus-outage",source="prometheus"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics output missing %q\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Package exporter freshness.go: 数据新鲜度 SLI（最新小时统计 / 成本快照的数据年龄）与停滞告警计数。
+package exporter
+
+import (
+	"context"
+
+	"github.com/myxxhui/lighthouse-src/internal/server/service"
+)
+
+// Data freshness metric names.
+const (
+	MetricDataAge            = "lighthouse_data_age_seconds"
+	MetricDataStale          = "lighthouse_data_stale"
+	MetricDataStaleThreshold = "lighthouse_data_stale_threshold_seconds"
+	MetricDataStaleAlerts    = "lighthouse_data_stale_alerts_total"
+)
+
+// FreshnessCollector 返回数据新鲜度指标的 Collector（每个数据源一个样本；尚无数据的源不导出年龄）。
+// 读取失败时只导出阈值与告警计数。
+func FreshnessCollector(w *service.StaleDataWatchdog) Collector {
+	return func(ctx context.Context) []Family {
+		age := Family{Name: MetricDataAge, Help: "Age of the newest row per data source.", Type: "gauge"}
+		stale := Family{Name: MetricDataStale, Help: "Whether the newest row per data source is older than the stale threshold (0/1).", Type: "gauge"}
+		if sources, err := w.Measure(ctx); err == nil {
+			for _, f := range sources {
+				if f.NewestAt == nil {
+					continue
+				}
+				labels := map[string]string{"source": f.Source}
+				age.Samples = append(age.Samples, Sample{Labels: labels, Value: f.Age.Seconds()})
+				v := 0.0
+				if f.Stale {
+					v = 1
+				}
+				stale.Samples = append(stale.Samples, Sample{Labels: labels, Value: v})
+			}
+		}
+		return []Family{
+			age,
+			stale,
+			{Name: MetricDataStaleThreshold, Help: "Data age after which a source is stale (factor x calculation interval).", Type: "gauge", Samples: []Sample{{Value: w.Threshold().Seconds()}}},
+			{Name: MetricDataStaleAlerts, Help: "Stale-data alerts sent since start.", Type: "counter", Samples: []Sample{{Value: float64(w.Alerts())}}},
+		}
+	}
+}
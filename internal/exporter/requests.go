@@ -0,0 +1,50 @@
+// Package exporter requests.go: 导出 Lighthouse 自身 API 的按路由请求数、5xx 数与延迟直方图，自身 SLO 基于同一计数评估。
+package exporter
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/myxxhui/lighthouse-src/internal/server/apimetrics"
+)
+
+// API request metric names.
+const (
+	MetricHTTPRequests       = "lighthouse_http_requests_total"
+	MetricHTTPRequestErrors  = "lighthouse_http_request_errors_total"
+	MetricHTTPRequestLatency = "lighthouse_http_request_duration_seconds"
+)
+
+// RequestCollector 返回 API 请求指标的 Collector（每个 method/route 一组样本）。
+func RequestCollector(rec *apimetrics.Recorder) Collector {
+	return func(ctx context.Context) []Family {
+		requests := Family{Name: MetricHTTPRequests, Help: "Requests served per route since start.", Type: "counter"}
+		errors := Family{Name: MetricHTTPRequestErrors, Help: "Requests answered with a 5xx status per route since start.", Type: "counter"}
+		latency := Family{Name: MetricHTTPRequestLatency, Help: "Request latency per route.", Type: "histogram"}
+		snapshot := rec.Snapshot()
+		for _, route := range apimetrics.SortedRoutes(snapshot) {
+			c := snapshot[route]
+			labels := map[string]string{"method": route.Method, "route": route.Path}
+			requests.Samples = append(requests.Samples, Sample{Labels: labels, Value: float64(c.Requests)})
+			errors.Samples = append(errors.Samples, Sample{Labels: labels, Value: float64(c.Errors)})
+			for i, bound := range apimetrics.LatencyBuckets {
+				latency.Samples = append(latency.Samples, Sample{Labels: withLabel(labels, "le", strconv.FormatFloat(bound, 'g', -1, 64)), Value: float64(c.Buckets[i]), Suffix: "_bucket"})
+			}
+			latency.Samples = append(latency.Samples,
+				Sample{Labels: withLabel(labels, "le", "+Inf"), Value: float64(c.Requests), Suffix: "_bucket"},
+				Sample{Labels: labels, Value: c.Sum, Suffix: "_sum"},
+				Sample{Labels: labels, Value: float64(c.Requests), Suffix: "_count"},
+			)
+		}
+		return []Family{requests, errors, latency}
+	}
+}
+
+func withLabel(labels map[string]string, key, value string) map[string]string {
+	out := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
@@ -0,0 +1,34 @@
+// Package exporter spool.go: 导出失败写入 spool（dead-letter）的积压与回放指标。
+package exporter
+
+import (
+	"context"
+
+	"github.com/myxxhui/lighthouse-src/internal/worker/spool"
+)
+
+// Spool metric names.
+const (
+	MetricSpoolPending        = "lighthouse_spool_pending_batches"
+	MetricSpoolEnqueued       = "lighthouse_spool_enqueued_total"
+	MetricSpoolReplayed       = "lighthouse_spool_replayed_total"
+	MetricSpoolReplayFailures = "lighthouse_spool_replay_failures_total"
+	MetricSpoolDropped        = "lighthouse_spool_dropped_total"
+)
+
+// SpoolCollector 返回 spool 指标的 Collector，用于 Exporter.AddCollector。
+func SpoolCollector(s *spool.Spool) Collector {
+	return func(ctx context.Context) []Family {
+		st := s.Stats()
+		single := func(name, help, typ string, v float64) Family {
+			return Family{Name: name, Help: help, Type: typ, Samples: []Sample{{Value: v}}}
+		}
+		return []Family{
+			single(MetricSpoolPending, "Failed write batches waiting in the spool for replay.", "gauge", float64(st.Pending)),
+			single(MetricSpoolEnqueued, "Failed write batches spooled since start.", "counter", float64(st.Enqueued)),
+			single(MetricSpoolReplayed, "Spooled batches replayed successfully since start.", "counter", float64(st.Replayed)),
+			single(MetricSpoolReplayFailures, "Failed replay attempts of spooled batches since start.", "counter", float64(st.ReplayFailures)),
+			single(MetricSpoolDropped, "Failed write batches dropped because the spool was full.", "counter", float64(st.Dropped)),
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package i18n
+
+// english is the English message catalog, the fallback of every other catalog.
+var english = map[string]string{
+	// API errors, by error code
+	"error.NOT_FOUND":              "The requested resource was not found.",
+	"error.NOT_CONFIGURED":         "This feature is not enabled on this Lighthouse instance.",
+	"error.VALIDATION_FAILED":      "The request is invalid; check its parameters.",
+	"error.CONFLICT":               "The request conflicts with the current state.",
+	"error.UNAVAILABLE":            "A data source is temporarily unavailable; retry later.",
+	"error.TIMEOUT":                "The request took too long and was aborted.",
+	"error.INTERNAL_ERROR":         "An unexpected error occurred",
+	"error.UNAUTHENTICATED":        "Authentication is required.",
+	"error.FORBIDDEN":              "You are not allowed to perform this request.",
+	"error.QUOTA_EXCEEDED":         "The request quota of this API key is exhausted.",
+	"error.GONE":                   "The requested resource has expired.",
+	"error.INTEGRITY_CHECK_FAILED": "The stored data failed its integrity check.",
+	"error.PAYLOAD_TOO_LARGE":      "The request body is too large.",
+
+	// Notifications
+	"notify.budget_breached": "Budget breached",
+	"notify.slo_violated":    "SLO violated",
+	"notify.stale_data":      "Stale data",
+	"notify.pipeline_canary": "Pipeline canary failed",
+	"notify.details":         "Details",
+	"notify.evidence":        "Evidence",
+	"notify.report":          "Report",
+
+	// Reports
+	"report.open":                 "Open in Lighthouse",
+	"report.none":                 "None.",
+	"report.weekly.subject":       "[Lighthouse] Weekly cost report: %s (%s)",
+	"report.weekly.heading":       "Lighthouse weekly report: %s",
+	"report.weekly.total_cost":    "Total cost",
+	"report.weekly.vs_previous":   "%s vs previous week",
+	"report.weekly.optimizable":   "Optimizable",
+	"report.weekly.efficiency":    "Efficiency",
+	"report.weekly.target":        "Efficiency target",
+	"report.weekly.target_status": "met %s of days, %d-day streak",
+	"report.weekly.chart":         "daily cost",
+
+	"digest.subject":           "[Lighthouse] Weekly recommendations: %s (%s)",
+	"digest.heading":           "Lighthouse weekly recommendations: %s",
+	"digest.title":             "Weekly recommendations: %s",
+	"digest.summary":           "%d zombie workloads, %d right-sizing suggestions, estimated savings %.2f %s/month",
+	"digest.savings":           "estimated savings",
+	"digest.per_month":         "month",
+	"digest.budget":            "Budget",
+	"digest.budget_value":      "%s: %.2f / %.2f (projected %.2f, quarter to date %.2f)",
+	"digest.month_to_date":     "month to date",
+	"digest.projected":         "projected",
+	"digest.quarter_to_date":   "quarter to date",
+	"digest.targets":           "Efficiency targets",
+	"digest.target":            "Target %s",
+	"digest.target_value":      "efficiency %.1f%% vs target %.1f%%, met %.0f%% of days, streak %d",
+	"digest.more":              "More",
+	"digest.more_value":        "%d more suggestions",
+	"digest.item":              "%s %s, saves %.2f/month",
+	"digest.zombies":           "Zombie workloads",
+	"digest.rightsizing":       "Right-sizing",
+	"digest.namespace":         "Namespace",
+	"digest.workload":          "Workload",
+	"digest.efficiency":        "Efficiency",
+	"digest.target_column":     "Target",
+	"digest.days_met":          "Days met",
+	"digest.streak":            "Streak",
+	"digest.savings_per_month": "Savings/month",
+	"digest.reason":            "Reason",
+	"digest.change":            "Change",
+
+	"digest.action.decommission": "decommission",
+	"digest.action.downsize":     "downsize",
+	"digest.action.upsize":       "upsize",
+
+	"budget.status.ok":       "ok",
+	"budget.status.at_risk":  "at_risk",
+	"budget.status.exceeded": "exceeded",
+}
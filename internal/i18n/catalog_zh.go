@@ -0,0 +1,78 @@
+package i18n
+
+// chinese is the Simplified Chinese message catalog.
+var chinese = map[string]string{
+	// API 错误，按错误码
+	"error.NOT_FOUND":              "请求的资源不存在。",
+	"error.NOT_CONFIGURED":         "该功能未在此 Lighthouse 实例上启用。",
+	"error.VALIDATION_FAILED":      "请求无效，请检查参数。",
+	"error.CONFLICT":               "请求与当前状态冲突。",
+	"error.UNAVAILABLE":            "数据源暂时不可用，请稍后重试。",
+	"error.TIMEOUT":                "请求处理超时，已中止。",
+	"error.INTERNAL_ERROR":         "发生了意外错误",
+	"error.UNAUTHENTICATED":        "需要身份认证。",
+	"error.FORBIDDEN":              "无权执行该请求。",
+	"error.QUOTA_EXCEEDED":         "该 API Key 的请求配额已用尽。",
+	"error.GONE":                   "请求的资源已过期。",
+	"error.INTEGRITY_CHECK_FAILED": "存储的数据未通过完整性校验。",
+	"error.PAYLOAD_TOO_LARGE":      "请求体过大。",
+
+	// 通知
+	"notify.budget_breached": "预算超支",
+	"notify.slo_violated":    "SLO 违约",
+	"notify.stale_data":      "数据过期",
+	"notify.pipeline_canary": "管道合成监控失败",
+	"notify.details":         "详情",
+	"notify.evidence":        "证据",
+	"notify.report":          "报表",
+
+	// 报表
+	"report.open":                 "在 Lighthouse 中查看",
+	"report.none":                 "无。",
+	"report.weekly.subject":       "[Lighthouse] 成本周报：%s（%s）",
+	"report.weekly.heading":       "Lighthouse 周报：%s",
+	"report.weekly.total_cost":    "总成本",
+	"report.weekly.vs_previous":   "较上周 %s",
+	"report.weekly.optimizable":   "可优化",
+	"report.weekly.efficiency":    "效率",
+	"report.weekly.target":        "效率目标",
+	"report.weekly.target_status": "达标天数 %s，连续达标 %d 天",
+	"report.weekly.chart":         "每日成本",
+
+	"digest.subject":           "[Lighthouse] 每周优化建议：%s（%s）",
+	"digest.heading":           "Lighthouse 每周优化建议：%s",
+	"digest.title":             "每周优化建议：%s",
+	"digest.summary":           "%d 个僵尸工作负载，%d 条规格调整建议，预计每月节省 %.2f %s",
+	"digest.savings":           "预计节省",
+	"digest.per_month":         "月",
+	"digest.budget":            "预算",
+	"digest.budget_value":      "%s：%.2f / %.2f（预计 %.2f，本季度至今 %.2f）",
+	"digest.month_to_date":     "本月至今",
+	"digest.projected":         "预计",
+	"digest.quarter_to_date":   "本季度至今",
+	"digest.targets":           "效率目标",
+	"digest.target":            "目标 %s",
+	"digest.target_value":      "效率 %.1f%%，目标 %.1f%%，达标天数 %.0f%%，连续达标 %d 天",
+	"digest.more":              "更多",
+	"digest.more_value":        "另有 %d 条建议",
+	"digest.item":              "%s %s，每月节省 %.2f",
+	"digest.zombies":           "僵尸工作负载",
+	"digest.rightsizing":       "规格调整",
+	"digest.namespace":         "Namespace",
+	"digest.workload":          "工作负载",
+	"digest.efficiency":        "效率",
+	"digest.target_column":     "目标",
+	"digest.days_met":          "达标天数",
+	"digest.streak":            "连续达标",
+	"digest.savings_per_month": "每月节省",
+	"digest.reason":            "原因",
+	"digest.change":            "调整",
+
+	"digest.action.decommission": "下线",
+	"digest.action.downsize":     "缩容",
+	"digest.action.upsize":       "扩容",
+
+	"budget.status.ok":       "正常",
+	"budget.status.at_risk":  "有超支风险",
+	"budget.status.exceeded": "已超支",
+}
@@ -0,0 +1,116 @@
+// Package i18n i18n.go: 语言协商与消息目录。API 按 Accept-Language 选择语言（请求上下文携带），通知与报表按
+// 配置的默认语言（或团队语言）渲染；目录中缺失的消息回退到英文，再回退到 key 本身。
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a supported language tag.
+type Locale string
+
+// Supported locales.
+const (
+	English Locale = "en"
+	Chinese Locale = "zh-CN"
+)
+
+// Default is the locale used when none is configured or negotiated.
+const Default = English
+
+// Supported returns the supported locales.
+func Supported() []Locale {
+	return []Locale{English, Chinese}
+}
+
+// Parse maps a language tag (e.g. "zh", "zh-Hans-CN", "en_US") to a supported locale by its
+// primary language. ok is false for empty or unsupported tags.
+func Parse(tag string) (Locale, bool) {
+	primary, _, _ := strings.Cut(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	switch strings.ToLower(primary) {
+	case "en":
+		return English, true
+	case "zh":
+		return Chinese, true
+	}
+	return "", false
+}
+
+// Negotiate picks the locale of an Accept-Language header: the supported language with the highest
+// quality, in header order on ties; fallback without a match ("*" included).
+func Negotiate(acceptLanguage string, fallback Locale) Locale {
+	type candidate struct {
+		locale Locale
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		locale, ok := Parse(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{locale, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale
+}
+
+// T returns the message key of locale, formatted with args when given. Messages missing in the
+// catalog of locale fall back to English, then to the key.
+func T(locale Locale, key string, args ...interface{}) string {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		if msg, ok = catalogs[English][key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Translator returns T bound to locale, e.g. as the "t" function of report templates.
+func Translator(locale Locale) func(key string, args ...interface{}) string {
+	return func(key string, args ...interface{}) string {
+		return T(locale, key, args...)
+	}
+}
+
+type contextKey struct{}
+
+// WithLocale returns ctx carrying locale.
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale carried by ctx, Default without one.
+func FromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(contextKey{}).(Locale); ok {
+		return locale
+	}
+	return Default
+}
+
+var catalogs = map[Locale]map[string]string{
+	English: english,
+	Chinese: chinese,
+}
@@ -0,0 +1,48 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	for header, want := range map[string]Locale{
+		"":                        English,
+		"*":                       English,
+		"zh-CN,zh;q=0.9,en;q=0.8": Chinese,
+		"en-US,zh;q=0.5":          English,
+		"fr-FR, zh-Hans;q=0.7":    Chinese,
+		"en;q=0.3, zh_TW;q=0.6":   Chinese,
+		"zh;q=0, de":              English,
+		"zh;q=abc":                English,
+	} {
+		if got := Negotiate(header, English); got != want {
+			t.Errorf("Negotiate(%q) = %s, want %s", header, got, want)
+		}
+	}
+	if got := Negotiate("fr", Chinese); got != Chinese {
+		t.Errorf("unsupported languages should fall back, got %s", got)
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(Chinese, "digest.title", "payments"); got != "每周优化建议：payments" {
+		t.Errorf("zh-CN = %q", got)
+	}
+	if got := T(Locale("de"), "digest.title", "payments"); got != "Weekly recommendations: payments" {
+		t.Errorf("unknown locale should fall back to English, got %q", got)
+	}
+	if got := T(Chinese, "no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key = %q, want the key", got)
+	}
+	for key := range english {
+		if _, ok := chinese[key]; !ok {
+			t.Errorf("zh-CN catalog misses %q", key)
+		}
+	}
+
+	ctx := WithLocale(context.Background(), Chinese)
+	if FromContext(ctx) != Chinese || FromContext(context.Background()) != Default {
+		t.Error("FromContext should return the carried locale, Default without one")
+	}
+}
@@ -0,0 +1,208 @@
+// Package notifier digest.go: 团队周度优化建议摘要（僵尸工作负载、规格调整建议、预算状态），经邮件与聊天渠道投递。
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+)
+
+// 预算状态。
+const (
+	BudgetStatusOK       = "ok"
+	BudgetStatusAtRisk   = "at_risk"  // 按当前速度月底将超支
+	BudgetStatusExceeded = "exceeded" // 本月已超支
+)
+
+// digestAlertMaxItems 聊天消息中最多列出的建议条数，完整列表见邮件。
+const digestAlertMaxItems = 5
+
+// DigestItem 一条优化建议。Action 为 decommission / downsize / upsize。
+type DigestItem struct {
+	Namespace          string
+	Workload           string
+	Action             string
+	Resource           string
+	CurrentRequest     float64
+	RecommendedRequest float64
+	MonthlySavings     float64 // 负数表示扩容增加的成本
+	Reason             string
+}
+
+// BudgetStatus 团队月度预算执行情况；“月”为财务期间（见 costmodel.FiscalCalendar），Period 为其名称，如 FY2026 P03。
+type BudgetStatus struct {
+	Period        string
+	MonthlyBudget float64
+	MonthToDate   float64
+	QuarterToDate float64 // 本财务季度至今
+	Projected     float64 // 按本期日均推算的期末成本
+	Status        string
+}
+
+// TargetStatus namespace 效率目标的达成情况。
+type TargetStatus struct {
+	Namespace         string
+	Target            float64 // 0-100
+	AverageEfficiency float64
+	AttainmentRate    float64 // 达标天数占比（%）
+	CurrentStreak     int     // 截至最近一天的连续达标天数
+}
+
+// TeamDigest 单个团队的周度摘要。
+type TeamDigest struct {
+	Team          string
+	Recipients    []string
+	Namespaces    []string
+	PeriodStart   time.Time
+	PeriodEnd     time.Time
+	Currency      string
+	Zombies       []DigestItem
+	Rightsizing   []DigestItem
+	Budget        *BudgetStatus  // 未配置预算时为 nil
+	Targets       []TargetStatus // 设置了效率目标的 namespace
+	DashboardLink string
+	Locale        i18n.Locale // 邮件与聊天消息的语言，空为默认语言
+}
+
+// EstimatedMonthlySavings 所有建议的月度节省合计。
+func (d TeamDigest) EstimatedMonthlySavings() float64 {
+	var total float64
+	for _, it := range d.Zombies {
+		total += it.MonthlySavings
+	}
+	for _, it := range d.Rightsizing {
+		total += it.MonthlySavings
+	}
+	return total
+}
+
+// Alert 将摘要转换为聊天渠道告警；预算超支时级别为 warning。
+func (d TeamDigest) Alert() Alert {
+	t := i18n.Translator(d.Locale)
+	alert := Alert{
+		Type:      AlertTypeRecommendationDigest,
+		Severity:  SeverityInfo,
+		Title:     t("digest.title", d.Team),
+		Summary:   t("digest.summary", len(d.Zombies), len(d.Rightsizing), d.EstimatedMonthlySavings(), d.Currency),
+		Link:      d.DashboardLink,
+		Timestamp: d.PeriodEnd,
+	}
+	if b := d.Budget; b != nil {
+		if b.Status == BudgetStatusExceeded {
+			alert.Severity = SeverityWarning
+		}
+		alert.Fields = append(alert.Fields, Field{
+			Name:  t("digest.budget"),
+			Value: t("digest.budget_value", t("budget.status."+b.Status), b.MonthToDate, b.MonthlyBudget, b.Projected, b.QuarterToDate),
+		})
+	}
+	for _, target := range d.Targets {
+		alert.Fields = append(alert.Fields, Field{
+			Name:  t("digest.target", target.Namespace),
+			Value: t("digest.target_value", target.AverageEfficiency, target.Target, target.AttainmentRate, target.CurrentStreak),
+		})
+	}
+	items := append(append([]DigestItem(nil), d.Zombies...), d.Rightsizing...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].MonthlySavings > items[j].MonthlySavings })
+	for i, it := range items {
+		if i == digestAlertMaxItems {
+			alert.Fields = append(alert.Fields, Field{Name: t("digest.more"), Value: t("digest.more_value", len(items)-i)})
+			break
+		}
+		alert.Fields = append(alert.Fields, Field{
+			Name:  fmt.Sprintf("%s/%s", it.Namespace, it.Workload),
+			Value: t("digest.item", t("digest.action."+it.Action), it.Resource, it.MonthlySavings),
+		})
+	}
+	return alert
+}
+
+var digestTemplates = localizedHTML("recommendation_digest", template.FuncMap{
+	"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"num":   func(v float64) string { return fmt.Sprintf("%.3g", v) },
+	"pct":   func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"date":  func(t time.Time) string { return t.Format("2006-01-02") },
+}, `<!DOCTYPE html>
+<html><body style="font-family:Arial,sans-serif;color:#222">
+<h2>{{t "digest.heading" .Team}}</h2>
+<p>{{date .PeriodStart}} ~ {{date .PeriodEnd}} &middot; {{t "digest.savings"}} <b>{{money .EstimatedMonthlySavings}} {{.Currency}}/{{t "digest.per_month"}}</b></p>
+{{with .Budget}}<p>{{t "digest.budget"}}: <b>{{t (print "budget.status." .Status)}}</b> &middot; {{with .Period}}{{.}} {{end}}{{t "digest.month_to_date"}} {{money .MonthToDate}} / {{money .MonthlyBudget}} ({{t "digest.projected"}} {{money .Projected}}) &middot; {{t "digest.quarter_to_date"}} {{money .QuarterToDate}}</p>{{end}}
+{{if .Targets}}<h3>{{t "digest.targets"}}</h3>
+<table cellpadding="6" style="border-collapse:collapse">
+<tr><th align="left">{{t "digest.namespace"}}</th><th align="right">{{t "digest.efficiency"}}</th><th align="right">{{t "digest.target_column"}}</th><th align="right">{{t "digest.days_met"}}</th><th align="right">{{t "digest.streak"}}</th></tr>
+{{range .Targets}}<tr><td>{{.Namespace}}</td><td align="right">{{pct .AverageEfficiency}}</td><td align="right">{{pct .Target}}</td><td align="right">{{pct .AttainmentRate}}</td><td align="right">{{.CurrentStreak}}</td></tr>
+{{end}}</table>{{end}}
+<h3>{{t "digest.zombies"}} ({{len .Zombies}})</h3>
+{{if .Zombies}}<table cellpadding="6" style="border-collapse:collapse">
+<tr><th align="left">{{t "digest.workload"}}</th><th align="right">{{t "digest.savings_per_month"}}</th><th align="left">{{t "digest.reason"}}</th></tr>
+{{range .Zombies}}<tr><td>{{.Namespace}}/{{.Workload}}</td><td align="right">{{money .MonthlySavings}}</td><td>{{.Reason}}</td></tr>
+{{end}}</table>{{else}}<p>{{t "report.none"}}</p>{{end}}
+<h3>{{t "digest.rightsizing"}} ({{len .Rightsizing}})</h3>
+{{if .Rightsizing}}<table cellpadding="6" style="border-collapse:collapse">
+<tr><th align="left">{{t "digest.workload"}}</th><th align="left">{{t "digest.change"}}</th><th align="right">{{t "digest.savings_per_month"}}</th></tr>
+{{range .Rightsizing}}<tr><td>{{.Namespace}}/{{.Workload}}</td><td>{{t (print "digest.action." .Action)}} {{.Resource}} {{num .CurrentRequest}} &rarr; {{num .RecommendedRequest}}</td><td align="right">{{money .MonthlySavings}}</td></tr>
+{{end}}</table>{{else}}<p>{{t "report.none"}}</p>{{end}}
+{{if .DashboardLink}}<p><a href="{{.DashboardLink}}">{{t "report.open"}}</a></p>{{end}}
+</body></html>`)
+
+// RenderDigestEmail 渲染摘要邮件，收件人为 d.Recipients，语言为 d.Locale。
+func RenderDigestEmail(d TeamDigest) (EmailMessage, error) {
+	var body bytes.Buffer
+	if err := localeTemplate(digestTemplates, d.Locale).Execute(&body, d); err != nil {
+		return EmailMessage{}, fmt.Errorf("render digest for %s: %w", d.Team, err)
+	}
+	return EmailMessage{
+		To:       d.Recipients,
+		Subject:  i18n.T(d.Locale, "digest.subject", d.Team, d.PeriodStart.Format("2006-01-02")),
+		HTMLBody: body.String(),
+	}, nil
+}
+
+// DigestSender 投递团队摘要：Email 发给团队收件人，Dispatcher 按 AlertTypeRecommendationDigest 路由到聊天渠道。
+// 两者均可为 nil。
+type DigestSender struct {
+	Email      EmailSender
+	Dispatcher *Dispatcher
+}
+
+// SendDigests 逐个团队投递，单个团队或渠道失败不影响其余投递。
+// 返回至少在一个渠道投递成功的团队数与第一个错误。
+func (s *DigestSender) SendDigests(ctx context.Context, digests []TeamDigest) (int, error) {
+	var sent int
+	var firstErr error
+	record := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, d := range digests {
+		delivered := false
+		if s.Email != nil && len(d.Recipients) > 0 {
+			msg, err := RenderDigestEmail(d)
+			if err == nil {
+				err = s.Email.Send(ctx, msg)
+			}
+			if err != nil {
+				record(fmt.Errorf("send digest email for %s: %w", d.Team, err))
+			} else {
+				delivered = true
+			}
+		}
+		if s.Dispatcher != nil {
+			if err := s.Dispatcher.Notify(ctx, d.Alert()); err != nil {
+				record(fmt.Errorf("notify digest for %s: %w", d.Team, err))
+			} else {
+				delivered = true
+			}
+		}
+		if delivered {
+			sent++
+		}
+	}
+	return sent, firstErr
+}
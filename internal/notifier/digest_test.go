@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+)
+
+func TestDigestSender(t *testing.T) {
+	sender := &recordingSender{}
+	drv := &recordingDriver{name: "slack"}
+	d := newTestDispatcher(DispatcherConfig{})
+	d.Route(AlertTypeRecommendationDigest, drv)
+
+	digest := TeamDigest{
+		Team:        "payments",
+		Recipients:  []string{"payments@example.com"},
+		Currency:    "CNY",
+		Zombies:     []DigestItem{{Namespace: "pay", Workload: "idle", Action: "decommission", Resource: "workload", MonthlySavings: 730}},
+		Rightsizing: []DigestItem{{Namespace: "pay", Workload: "fat", Action: "downsize", Resource: "cpu", CurrentRequest: 4, RecommendedRequest: 1.2, MonthlySavings: 511}},
+		Budget:      &BudgetStatus{MonthlyBudget: 1000, MonthToDate: 1200, Projected: 2400, Status: BudgetStatusExceeded},
+		Targets:     []TargetStatus{{Namespace: "pay", Target: 60, AverageEfficiency: 52.9, AttainmentRate: 42.9, CurrentStreak: 3}},
+	}
+	sent, err := (&DigestSender{Email: sender, Dispatcher: d}).SendDigests(context.Background(), []TeamDigest{digest})
+	if err != nil || sent != 1 {
+		t.Fatalf("SendDigests = %d, %v", sent, err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].To[0] != "payments@example.com" || !strings.Contains(sender.sent[0].HTMLBody, "pay/idle") ||
+		!strings.Contains(sender.sent[0].HTMLBody, "<td>pay</td><td align=\"right\">52.9%</td><td align=\"right\">60.0%</td>") {
+		t.Errorf("unexpected digest email: %+v", sender.sent)
+	}
+	if len(drv.sent) != 1 {
+		t.Fatalf("expected one chat message, got %d", len(drv.sent))
+	}
+	msg := drv.sent[0]
+	if msg.Alert.Severity != SeverityWarning || !strings.Contains(msg.Text, "estimated savings 1241.00 CNY/month") ||
+		!strings.Contains(msg.Text, "efficiency 52.9% vs target 60.0%, met 43% of days, streak 3") {
+		t.Errorf("unexpected digest message: %+v", msg)
+	}
+}
+
+func TestDigestLocale(t *testing.T) {
+	digest := TeamDigest{
+		Team:     "payments",
+		Currency: "CNY",
+		Zombies:  []DigestItem{{Namespace: "pay", Workload: "idle", Action: "decommission", Resource: "workload", MonthlySavings: 730}},
+		Budget:   &BudgetStatus{MonthlyBudget: 1000, MonthToDate: 1200, Projected: 2400, Status: BudgetStatusExceeded},
+		Locale:   i18n.Chinese,
+	}
+	msg, err := RenderDigestEmail(digest)
+	if err != nil {
+		t.Fatalf("RenderDigestEmail: %v", err)
+	}
+	for _, want := range []string{"每周优化建议：payments", "已超支", "僵尸工作负载 (1)", "<p>无。</p>"} {
+		if !strings.Contains(msg.Subject+msg.HTMLBody, want) {
+			t.Errorf("digest email missing %q\n%s", want, msg.HTMLBody)
+		}
+	}
+
+	d := newTestDispatcher(DispatcherConfig{})
+	d.templates = LocalizedTemplates(i18n.Chinese)
+	drv := &recordingDriver{name: "slack"}
+	d.Route(AlertTypeRecommendationDigest, drv)
+	if err := d.Notify(context.Background(), digest.Alert()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(drv.sent) != 1 || !strings.Contains(drv.sent[0].Text, "预计每月节省 730.00 CNY") || !strings.Contains(drv.sent[0].Text, "- pay/idle: 下线 workload，每月节省 730.00") {
+		t.Errorf("unexpected digest message: %+v", drv.sent)
+	}
+}
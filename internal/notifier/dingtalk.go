@@ -0,0 +1,120 @@
+// Package notifier dingtalk.go: 钉钉自定义机器人驱动（支持加签）。
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DingTalkConfig 钉钉机器人配置。WebhookURL 与 Secret 属敏感信息，仅从环境变量注入。
+type DingTalkConfig struct {
+	WebhookURL string // https://oapi.dingtalk.com/robot/send?access_token=...
+	Secret     string // 安全设置“加签”的密钥（SEC 开头），为空则不签名
+	AtMobiles  []string
+	AtAll      bool
+	Timeout    time.Duration
+}
+
+// DingTalkDriver 通过机器人 webhook 发送 markdown 消息。
+type DingTalkDriver struct {
+	config DingTalkConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewDingTalkDriver 创建钉钉驱动。
+func NewDingTalkDriver(config DingTalkConfig) *DingTalkDriver {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &DingTalkDriver{config: config, client: &http.Client{Timeout: config.Timeout}, now: time.Now}
+}
+
+// Name implements Driver.
+func (d *DingTalkDriver) Name() string { return "dingtalk" }
+
+type dingTalkPayload struct {
+	MsgType  string `json:"msgtype"`
+	Markdown struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	} `json:"markdown"`
+	At struct {
+		AtMobiles []string `json:"atMobiles,omitempty"`
+		IsAtAll   bool     `json:"isAtAll"`
+	} `json:"at"`
+}
+
+// robotResponse 钉钉与企业微信机器人共用的响应格式。
+type robotResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// Send implements Driver.
+func (d *DingTalkDriver) Send(ctx context.Context, msg Message) error {
+	if d.config.WebhookURL == "" {
+		return fmt.Errorf("dingtalk webhook not configured")
+	}
+	endpoint, err := d.signedURL()
+	if err != nil {
+		return err
+	}
+
+	var payload dingTalkPayload
+	payload.MsgType = "markdown"
+	payload.Markdown.Title = msg.Title
+	payload.Markdown.Text = markdownBody(msg)
+	payload.At.AtMobiles = d.config.AtMobiles
+	payload.At.IsAtAll = d.config.AtAll
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var resp robotResponse
+	if err := postJSON(ctx, d.client, endpoint, body, &resp); err != nil {
+		return err
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("dingtalk error %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+// signedURL 按钉钉加签规则追加 timestamp 与 sign：
+// sign = urlencode(base64(HmacSHA256(secret, timestamp + "\n" + secret)))。
+func (d *DingTalkDriver) signedURL() (string, error) {
+	if d.config.Secret == "" {
+		return d.config.WebhookURL, nil
+	}
+	u, err := url.Parse(d.config.WebhookURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid dingtalk webhook: %w", err)
+	}
+	ts := strconv.FormatInt(d.now().UnixMilli(), 10)
+	q := u.Query()
+	q.Set("timestamp", ts)
+	q.Set("sign", dingTalkSign(ts, d.config.Secret))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func dingTalkSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// markdownBody 钉钉/企业微信 markdown 正文：标题作为三级标题，正文沿用共享模板渲染结果。
+func markdownBody(msg Message) string {
+	return "### " + msg.Title + "\n\n" + msg.Text
+}
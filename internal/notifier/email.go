@@ -0,0 +1,221 @@
+// Package notifier email.go: SMTP 邮件发送（HTML 正文 + 内联图片），供定时报表投递使用。
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP TLS 模式。
+const (
+	SMTPTLSNone     = "none"     // 明文（仅限内网中继）
+	SMTPTLSStartTLS = "starttls" // 587 端口常用
+	SMTPTLSImplicit = "tls"      // 465 端口 SMTPS
+)
+
+// SMTPConfig SMTP 配置。Password 属敏感信息，仅从环境变量注入。
+type SMTPConfig struct {
+	Host               string
+	Port               int
+	Username           string
+	Password           string
+	From               string
+	TLSMode            string // none/starttls/tls，默认 starttls
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+}
+
+// InlineImage 以 cid: 引用的内联图片。
+type InlineImage struct {
+	ContentID   string // HTML 中以 <img src="cid:ContentID"> 引用
+	ContentType string // image/png
+	Data        []byte
+}
+
+// EmailMessage 一封 HTML 邮件。
+type EmailMessage struct {
+	To       []string
+	Subject  string
+	HTMLBody string
+	Inline   []InlineImage
+}
+
+// SMTPSender 通过 SMTP 发送邮件。
+type SMTPSender struct {
+	config SMTPConfig
+	// send 实际投递函数，测试中替换。
+	send func(ctx context.Context, from string, to []string, data []byte) error
+}
+
+// NewSMTPSender 创建 SMTPSender。
+func NewSMTPSender(config SMTPConfig) *SMTPSender {
+	if config.TLSMode == "" {
+		config.TLSMode = SMTPTLSStartTLS
+	}
+	if config.Port == 0 {
+		switch config.TLSMode {
+		case SMTPTLSImplicit:
+			config.Port = 465
+		case SMTPTLSNone:
+			config.Port = 25
+		default:
+			config.Port = 587
+		}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	s := &SMTPSender{config: config}
+	s.send = s.deliver
+	return s
+}
+
+// Send 构造 MIME 并投递。
+func (s *SMTPSender) Send(ctx context.Context, msg EmailMessage) error {
+	if s.config.Host == "" || s.config.From == "" {
+		return fmt.Errorf("smtp host and from address are required")
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("email has no recipients")
+	}
+	data, err := buildMIME(s.config.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, s.config.From, msg.To, data)
+}
+
+// deliver 连接 SMTP 服务器并发送，按 TLSMode 建立 TLS。
+func (s *SMTPSender) deliver(ctx context.Context, from string, to []string, data []byte) error {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	tlsConfig := &tls.Config{ServerName: s.config.Host, InsecureSkipVerify: s.config.InsecureSkipVerify}
+
+	dialer := &net.Dialer{Timeout: s.config.Timeout}
+	var conn net.Conn
+	var err error
+	if s.config.TLSMode == SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp dial %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(s.config.Timeout))
+	}
+
+	c, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer c.Close()
+
+	if s.config.TLSMode == SMTPTLSStartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if s.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp write body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp end body: %w", err)
+	}
+	return c.Quit()
+}
+
+// buildMIME 构造 multipart/related 邮件：HTML 正文在前，内联图片随后。
+func buildMIME(from string, msg EmailMessage, now time.Time) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	writeHeader := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	writeHeader("From", from)
+	writeHeader("To", strings.Join(msg.To, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", now.Format(time.RFC1123Z))
+	writeHeader("MIME-Version", "1.0")
+	if len(msg.Inline) == 0 {
+		writeHeader("Content-Type", `text/html; charset="utf-8"`)
+		writeHeader("Content-Transfer-Encoding", "base64")
+		b.WriteString("\r\n")
+		writeBase64(&b, []byte(msg.HTMLBody))
+		return b.Bytes(), nil
+	}
+
+	writeHeader("Content-Type", fmt.Sprintf(`multipart/related; boundary="%s"`, boundary))
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	writeHeader("Content-Type", `text/html; charset="utf-8"`)
+	writeHeader("Content-Transfer-Encoding", "base64")
+	b.WriteString("\r\n")
+	writeBase64(&b, []byte(msg.HTMLBody))
+
+	for _, img := range msg.Inline {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		writeHeader("Content-Type", img.ContentType)
+		writeHeader("Content-Transfer-Encoding", "base64")
+		writeHeader("Content-ID", "<"+img.ContentID+">")
+		writeHeader("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, img.ContentID))
+		b.WriteString("\r\n")
+		writeBase64(&b, img.Data)
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes(), nil
+}
+
+// writeBase64 按 RFC 2045 每行 76 字符写入 base64。
+func writeBase64(b *bytes.Buffer, data []byte) {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		b.WriteString(enc[:76])
+		b.WriteString("\r\n")
+		enc = enc[76:]
+	}
+	b.WriteString(enc)
+	b.WriteString("\r\n")
+}
+
+func randomBoundary() (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	return "lighthouse-" + hex.EncodeToString(buf[:]), nil
+}
@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSMTPSenderBuildsMultipartWithInlineChart(t *testing.T) {
+	var gotFrom string
+	var gotTo []string
+	var gotData []byte
+	s := NewSMTPSender(SMTPConfig{Host: "smtp.example.com", From: "lighthouse@example.com"})
+	s.send = func(ctx context.Context, from string, to []string, data []byte) error {
+		gotFrom, gotTo, gotData = from, to, data
+		return nil
+	}
+
+	msg, err := RenderWeeklyReport(WeeklyReport{
+		Namespace:    "app-prod",
+		PeriodStart:  time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:    time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+		Currency:     "CNY",
+		TotalCost:    1100,
+		PreviousCost: 1000,
+		Efficiency:   62.5,
+		DailyCosts:   []float64{150, 160, 155, 170, 165, 150, 150},
+		Target:       &TargetStatus{Namespace: "app-prod", Target: 60, AttainmentRate: 71.4, CurrentStreak: 3},
+	})
+	if err != nil {
+		t.Fatalf("RenderWeeklyReport failed: %v", err)
+	}
+	if !strings.Contains(msg.HTMLBody, "(10.0% vs previous week)") || !strings.Contains(msg.HTMLBody, "cid:"+weeklyReportChartCID) ||
+		!strings.Contains(msg.HTMLBody, "60.0% &middot; met 71.4% of days, 3-day streak") {
+		t.Errorf("unexpected body: %s", msg.HTMLBody)
+	}
+	if len(msg.Inline) != 1 || !bytes.HasPrefix(msg.Inline[0].Data, []byte("\x89PNG")) {
+		t.Fatalf("expected inline PNG chart")
+	}
+
+	msg.To = []string{"team@example.com"}
+	if err := s.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if gotFrom != "lighthouse@example.com" || len(gotTo) != 1 {
+		t.Errorf("unexpected envelope: %s %v", gotFrom, gotTo)
+	}
+	data := string(gotData)
+	for _, want := range []string{"multipart/related", "Content-ID: <" + weeklyReportChartCID + ">", "To: team@example.com"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("MIME missing %q", want)
+		}
+	}
+}
+
+func TestSMTPSenderRequiresRecipients(t *testing.T) {
+	s := NewSMTPSender(SMTPConfig{Host: "smtp.example.com", From: "lighthouse@example.com"})
+	if err := s.Send(context.Background(), EmailMessage{Subject: "x"}); err == nil {
+		t.Fatal("expected error without recipients")
+	}
+	if s.config.Port != 587 {
+		t.Errorf("default starttls port want 587, got %d", s.config.Port)
+	}
+}
+
+type recordingSender struct{ sent []EmailMessage }
+
+func (r *recordingSender) Send(ctx context.Context, msg EmailMessage) error {
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+func TestReportMailerResolvesOwners(t *testing.T) {
+	sender := &recordingSender{}
+	m := &ReportMailer{
+		Sender: sender,
+		Owners: StaticOwnership{"app-prod": {"prod-owner@example.com"}, "*": {"finops@example.com"}},
+	}
+	sent, err := m.SendWeeklyReports(context.Background(), []WeeklyReport{
+		{Namespace: "monitoring"},
+		{Namespace: "app-prod"},
+	})
+	if err != nil || sent != 2 {
+		t.Fatalf("SendWeeklyReports = %d, %v", sent, err)
+	}
+	if sender.sent[0].To[0] != "prod-owner@example.com" || sender.sent[1].To[0] != "finops@example.com" {
+		t.Errorf("unexpected recipients: %v / %v", sender.sent[0].To, sender.sent[1].To)
+	}
+}
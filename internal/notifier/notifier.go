@@ -0,0 +1,252 @@
+// Package notifier 告警通知子系统：统一的告警模型、模板渲染、限流与重试，具体渠道由 Driver 实现。
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AlertType 告警类型，用于选择模板与渠道路由。
+type AlertType string
+
+const (
+	AlertTypeBudgetBreach AlertType = "budget_breach" // 预算超支
+	AlertTypeSLOViolation AlertType = "slo_violation" // SLO 违约
+	AlertTypeAnomaly      AlertType = "anomaly"       // 成本/资源异常
+	AlertTypeWeeklyReport AlertType = "weekly_report" // 周报链接
+
+	AlertTypeRecommendationDigest AlertType = "recommendation_digest" // 团队周度优化建议摘要
+	AlertTypeRule                 AlertType = "alert_rule"            // 用户自定义告警规则触发
+	AlertTypeStaleData            AlertType = "stale_data"            // 数据管道停滞，最新数据超过新鲜度阈值
+	AlertTypePipelineCanary       AlertType = "pipeline_canary"       // 管道合成监控失败：端到端读回错误或过慢
+)
+
+// Severity 告警级别。
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Field 告警附加字段，按顺序渲染。
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Alert 渠道无关的告警内容。
+type Alert struct {
+	Type      AlertType `json:"type"`
+	Severity  Severity  `json:"severity"`
+	Title     string    `json:"title"`
+	Summary   string    `json:"summary"`
+	Fields    []Field   `json:"fields,omitempty"`
+	Link      string    `json:"link,omitempty"`
+	DedupKey  string    `json:"dedup_key,omitempty"` // 相同 DedupKey 视为同一事件（用于 paging 类渠道）
+	Timestamp time.Time `json:"timestamp"`
+	// Channels 非空时只投递到该告警类型路由中名称（Driver.Name）在列表内的渠道
+	Channels []string `json:"channels,omitempty"`
+	// Labels 用于路由规则与静默匹配的标签（namespace、team、rule 等）
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Message 模板渲染后的消息，由 Driver 转换为渠道格式。
+type Message struct {
+	Alert Alert
+	Title string
+	Text  string
+}
+
+// Driver 通知渠道实现（Slack、钉钉、企业微信等）。
+type Driver interface {
+	// Name 渠道名，用于限流 key 与错误信息。
+	Name() string
+	// Send 投递一条消息；返回 error 时 Dispatcher 按重试策略重投。
+	Send(ctx context.Context, msg Message) error
+}
+
+// DispatcherConfig 限流与重试配置。
+type DispatcherConfig struct {
+	// RateLimitPerMinute 每个 (渠道, 告警类型) 每分钟最多投递条数；<=0 不限流。
+	RateLimitPerMinute int
+	// MaxRetries 失败后最多重试次数。
+	MaxRetries int
+	// RetryDelay 首次重试间隔，之后指数退避。
+	RetryDelay time.Duration
+}
+
+// DefaultDispatcherConfig 返回默认配置。
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		RateLimitPerMinute: 10,
+		MaxRetries:         3,
+		RetryDelay:         time.Second,
+	}
+}
+
+// ErrRateLimited 投递被限流丢弃。
+var ErrRateLimited = errors.New("notification rate limited")
+
+// ErrSilenced 告警命中静默，未投递。
+var ErrSilenced = errors.New("notification silenced")
+
+// Filter 在渲染与投递前处理告警：按路由规则选择渠道（设置 Alert.Channels），或返回 ErrSilenced 丢弃告警。
+type Filter interface {
+	Filter(ctx context.Context, alert Alert) (Alert, error)
+}
+
+// Dispatcher 按告警类型路由到 Driver，负责模板渲染、限流与重试。
+type Dispatcher struct {
+	config    DispatcherConfig
+	templates *Templates
+	routes    map[AlertType][]Driver
+	fallback  []Driver
+	filter    Filter
+
+	mu    sync.Mutex
+	sent  map[string][]time.Time // key: driver/alert_type
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewDispatcher 创建 Dispatcher；templates 为 nil 时使用默认模板。
+func NewDispatcher(config DispatcherConfig, templates *Templates) *Dispatcher {
+	if templates == nil {
+		templates = DefaultTemplates()
+	}
+	return &Dispatcher{
+		config:    config,
+		templates: templates,
+		routes:    make(map[AlertType][]Driver),
+		sent:      make(map[string][]time.Time),
+		now:       time.Now,
+		sleep:     sleepContext,
+	}
+}
+
+// Route 为告警类型注册渠道；未注册的类型发往 RouteDefault 注册的渠道。
+func (d *Dispatcher) Route(alertType AlertType, drivers ...Driver) {
+	d.routes[alertType] = append(d.routes[alertType], drivers...)
+}
+
+// RouteDefault 注册兜底渠道。
+func (d *Dispatcher) RouteDefault(drivers ...Driver) {
+	d.fallback = append(d.fallback, drivers...)
+}
+
+// SetFilter 设置投递前的路由与静默处理。
+func (d *Dispatcher) SetFilter(f Filter) {
+	d.filter = f
+}
+
+// Notify 经 Filter 处理后渲染告警并投递到所有路由渠道（Alert.Channels 非空时仅其中的渠道）。
+// 单个渠道失败不影响其他渠道，返回第一个错误；被静默时返回 ErrSilenced。
+func (d *Dispatcher) Notify(ctx context.Context, alert Alert) error {
+	if alert.Timestamp.IsZero() {
+		alert.Timestamp = d.now()
+	}
+	if d.filter != nil {
+		var err error
+		if alert, err = d.filter.Filter(ctx, alert); err != nil {
+			return err
+		}
+	}
+	msg, err := d.templates.Render(alert)
+	if err != nil {
+		return err
+	}
+
+	drivers := d.routes[alert.Type]
+	if len(drivers) == 0 {
+		drivers = d.fallback
+	}
+	if len(alert.Channels) > 0 {
+		drivers = selectDrivers(drivers, alert.Channels)
+	}
+	if len(drivers) == 0 {
+		return fmt.Errorf("no notifier route for alert type %s", alert.Type)
+	}
+
+	var firstErr error
+	for _, drv := range drivers {
+		if err := d.deliver(ctx, drv, msg); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", drv.Name(), err)
+		}
+	}
+	return firstErr
+}
+
+// selectDrivers 返回名称在 names 中的渠道。
+func selectDrivers(drivers []Driver, names []string) []Driver {
+	var out []Driver
+	for _, drv := range drivers {
+		for _, name := range names {
+			if drv.Name() == name {
+				out = append(out, drv)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, drv Driver, msg Message) error {
+	if !d.allow(drv.Name() + "/" + string(msg.Alert.Type)) {
+		return ErrRateLimited
+	}
+
+	delay := d.config.RetryDelay
+	var err error
+	for attempt := 0; attempt <= d.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if serr := d.sleep(ctx, delay); serr != nil {
+				return serr
+			}
+			delay *= 2
+		}
+		if err = drv.Send(ctx, msg); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("delivery failed after %d attempts: %w", d.config.MaxRetries+1, err)
+}
+
+// allow 滑动窗口限流（1 分钟）。
+func (d *Dispatcher) allow(key string) bool {
+	if d.config.RateLimitPerMinute <= 0 {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	cutoff := now.Add(-time.Minute)
+	recent := d.sent[key][:0]
+	for _, t := range d.sent[key] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= d.config.RateLimitPerMinute {
+		d.sent[key] = recent
+		return false
+	}
+	d.sent[key] = append(recent, now)
+	return true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
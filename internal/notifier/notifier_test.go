@@ -0,0 +1,259 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingDriver struct {
+	name     string
+	failures int
+	sent     []Message
+}
+
+func (r *recordingDriver) Name() string { return r.name }
+
+func (r *recordingDriver) Send(ctx context.Context, msg Message) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("temporary failure")
+	}
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+func newTestDispatcher(config DispatcherConfig) *Dispatcher {
+	d := NewDispatcher(config, nil)
+	d.sleep = func(ctx context.Context, _ time.Duration) error { return nil }
+	return d
+}
+
+func TestTemplatesRender(t *testing.T) {
+	msg, err := DefaultTemplates().Render(Alert{
+		Type:     AlertTypeBudgetBreach,
+		Severity: SeverityCritical,
+		Title:    "app-prod over budget",
+		Summary:  "spent 1200 of 1000",
+		Fields:   []Field{{Name: "namespace", Value: "app-prod"}},
+		Link:     "https://lighthouse/cost/app-prod",
+	})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if msg.Title != "[critical] app-prod over budget" {
+		t.Errorf("unexpected title %q", msg.Title)
+	}
+	for _, want := range []string{"Budget breached: spent 1200 of 1000", "- namespace: app-prod", "Details: https://lighthouse/cost/app-prod"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("text %q missing %q", msg.Text, want)
+		}
+	}
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	d := newTestDispatcher(DispatcherConfig{MaxRetries: 2})
+	drv := &recordingDriver{name: "test", failures: 2}
+	d.Route(AlertTypeAnomaly, drv)
+
+	if err := d.Notify(context.Background(), Alert{Type: AlertTypeAnomaly, Title: "spike"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(drv.sent) != 1 {
+		t.Fatalf("expected delivery after retries, got %d", len(drv.sent))
+	}
+
+	drv.failures = 3
+	if err := d.Notify(context.Background(), Alert{Type: AlertTypeAnomaly, Title: "spike"}); err == nil {
+		t.Fatal("expected error when retries are exhausted")
+	}
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	d := newTestDispatcher(DispatcherConfig{RateLimitPerMinute: 2})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	drv := &recordingDriver{name: "test"}
+	d.RouteDefault(drv)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := d.Notify(ctx, Alert{Type: AlertTypeSLOViolation, Title: "burn"}); err != nil {
+			t.Fatalf("Notify %d failed: %v", i, err)
+		}
+	}
+	if err := d.Notify(ctx, Alert{Type: AlertTypeSLOViolation, Title: "burn"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	// other alert types have their own budget
+	if err := d.Notify(ctx, Alert{Type: AlertTypeAnomaly, Title: "spike"}); err != nil {
+		t.Fatalf("Notify for other type failed: %v", err)
+	}
+
+	now = now.Add(61 * time.Second)
+	if err := d.Notify(ctx, Alert{Type: AlertTypeSLOViolation, Title: "burn"}); err != nil {
+		t.Fatalf("Notify after window failed: %v", err)
+	}
+}
+
+func TestDispatcherNoRoute(t *testing.T) {
+	d := newTestDispatcher(DefaultDispatcherConfig())
+	if err := d.Notify(context.Background(), Alert{Type: AlertTypeWeeklyReport}); err == nil {
+		t.Fatal("expected error without routes")
+	}
+}
+
+func TestDispatcherAlertChannels(t *testing.T) {
+	d := newTestDispatcher(DefaultDispatcherConfig())
+	slack, wecom := &recordingDriver{name: "slack"}, &recordingDriver{name: "wecom"}
+	d.RouteDefault(slack, wecom)
+	ctx := context.Background()
+
+	if err := d.Notify(ctx, Alert{Type: AlertTypeRule, Title: "rule", Channels: []string{"wecom"}}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(slack.sent) != 0 || len(wecom.sent) != 1 {
+		t.Errorf("sent slack=%d wecom=%d, want 0 and 1", len(slack.sent), len(wecom.sent))
+	}
+	if err := d.Notify(ctx, Alert{Type: AlertTypeRule, Channels: []string{"email"}}); err == nil {
+		t.Error("expected error when no routed channel is selected")
+	}
+}
+
+func TestSlackDriverRoutesByAlertType(t *testing.T) {
+	var got slackPayload
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	drv := NewSlackDriver(SlackConfig{
+		WebhookURL: srv.URL + "/default",
+		Channel:    "#finops",
+		Routes: map[AlertType]SlackRoute{
+			AlertTypeSLOViolation: {WebhookURL: srv.URL + "/sre", Channel: "#sre-oncall"},
+		},
+	})
+	d := newTestDispatcher(DefaultDispatcherConfig())
+	d.RouteDefault(drv)
+
+	if err := d.Notify(context.Background(), Alert{Type: AlertTypeSLOViolation, Severity: SeverityCritical, Title: "checkout availability"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if path != "/sre" || got.Channel != "#sre-oncall" {
+		t.Errorf("expected SLO route, got path=%s channel=%s", path, got.Channel)
+	}
+	if len(got.Attachments) != 1 || got.Attachments[0].Color != "danger" {
+		t.Errorf("unexpected attachments: %+v", got.Attachments)
+	}
+
+	if err := d.Notify(context.Background(), Alert{Type: AlertTypeWeeklyReport, Title: "weekly", Link: "https://x"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if path != "/default" || got.Channel != "#finops" {
+		t.Errorf("expected default route, got path=%s channel=%s", path, got.Channel)
+	}
+}
+
+func TestSlackDriverHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	drv := NewSlackDriver(SlackConfig{WebhookURL: srv.URL})
+	if err := drv.Send(context.Background(), Message{Alert: Alert{Type: AlertTypeAnomaly}}); err == nil {
+		t.Fatal("expected error for non-2xx response")
+	}
+}
+
+func TestDingTalkDriverSignsRequest(t *testing.T) {
+	var got dingTalkPayload
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	drv := NewDingTalkDriver(DingTalkConfig{WebhookURL: srv.URL + "/robot/send?access_token=abc", Secret: "SECxyz"})
+	drv.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	d := newTestDispatcher(DefaultDispatcherConfig())
+	d.RouteDefault(drv)
+	if err := d.Notify(context.Background(), Alert{Type: AlertTypeBudgetBreach, Severity: SeverityWarning, Title: "over budget", Summary: "app-prod"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if query.Get("access_token") != "abc" || query.Get("timestamp") != "1700000000000" {
+		t.Errorf("unexpected query: %v", query)
+	}
+	if query.Get("sign") != dingTalkSign("1700000000000", "SECxyz") {
+		t.Errorf("unexpected sign %q", query.Get("sign"))
+	}
+	if got.MsgType != "markdown" || !strings.Contains(got.Markdown.Text, "Budget breached: app-prod") {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+}
+
+func TestWeComDriverReportsErrCode(t *testing.T) {
+	var got weComPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+	}))
+	defer srv.Close()
+
+	drv := NewWeComDriver(WeComConfig{WebhookURL: srv.URL + "/cgi-bin/webhook/send?key=k"})
+	msg, _ := DefaultTemplates().Render(Alert{Type: AlertTypeAnomaly, Title: "spike", Summary: "cpu cost +80%"})
+	if err := drv.Send(context.Background(), msg); err == nil || !strings.Contains(err.Error(), "93000") {
+		t.Fatalf("expected errcode error, got %v", err)
+	}
+	if got.Markdown.Content != "### [info] spike\n\n"+msg.Text {
+		t.Errorf("unexpected content %q", got.Markdown.Content)
+	}
+}
+
+type silenceCritical struct{}
+
+func (silenceCritical) Filter(ctx context.Context, alert Alert) (Alert, error) {
+	labels := AlertLabels(alert)
+	if MatchLabels(map[string]string{LabelSeverity: "critical", LabelNamespace: "*"}, labels) {
+		return alert, ErrSilenced
+	}
+	if MatchLabels(map[string]string{LabelTeam: "payments"}, labels) {
+		alert.Channels = []string{"wecom"}
+	}
+	return alert, nil
+}
+
+func TestDispatcherFilter(t *testing.T) {
+	d := newTestDispatcher(DefaultDispatcherConfig())
+	slack, wecom := &recordingDriver{name: "slack"}, &recordingDriver{name: "wecom"}
+	d.RouteDefault(slack, wecom)
+	d.SetFilter(silenceCritical{})
+	ctx := context.Background()
+
+	err := d.Notify(ctx, Alert{Type: AlertTypeRule, Severity: SeverityCritical, Labels: map[string]string{LabelNamespace: "shop"}})
+	if !errors.Is(err, ErrSilenced) {
+		t.Errorf("critical alert with namespace: err = %v, want silenced", err)
+	}
+	if err := d.Notify(ctx, Alert{Type: AlertTypeRule, Severity: SeverityCritical}); err != nil {
+		t.Fatalf("critical alert without namespace: %v", err)
+	}
+	if err := d.Notify(ctx, Alert{Type: AlertTypeRule, Labels: map[string]string{LabelTeam: "payments"}}); err != nil {
+		t.Fatalf("payments alert: %v", err)
+	}
+	if len(slack.sent) != 1 || len(wecom.sent) != 2 {
+		t.Errorf("sent slack=%d wecom=%d, want 1 and 2", len(slack.sent), len(wecom.sent))
+	}
+}
@@ -0,0 +1,311 @@
+// Package notifier paging.go: 值班 paging 集成（PagerDuty / Opsgenie），按 dedup key 打开、确认、恢复事件。
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// IncidentAction 事件动作。
+type IncidentAction string
+
+const (
+	IncidentTrigger     IncidentAction = "trigger"
+	IncidentAcknowledge IncidentAction = "acknowledge"
+	IncidentResolve     IncidentAction = "resolve"
+)
+
+// Incident 渠道无关的事件内容。DedupKey 决定 trigger/acknowledge/resolve 作用于同一事件。
+type Incident struct {
+	DedupKey  string                 `json:"dedup_key"`
+	Summary   string                 `json:"summary"`
+	Source    string                 `json:"source"`
+	Severity  Severity               `json:"severity"`
+	Component string                 `json:"component,omitempty"`
+	Group     string                 `json:"group,omitempty"`
+	Class     string                 `json:"class,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// Pager 事件管理平台。
+type Pager interface {
+	Name() string
+	Page(ctx context.Context, action IncidentAction, incident Incident) error
+}
+
+// PagingDriver 将 critical 告警转为 trigger 事件，使 Pager 可以挂在 Dispatcher 路由上。
+// 非 critical 告警直接忽略，避免低级别告警叫醒值班。
+type PagingDriver struct {
+	Pager Pager
+}
+
+// Name implements Driver.
+func (p PagingDriver) Name() string { return p.Pager.Name() }
+
+// Send implements Driver.
+func (p PagingDriver) Send(ctx context.Context, msg Message) error {
+	if msg.Alert.Severity != SeverityCritical {
+		return nil
+	}
+	dedupKey := msg.Alert.DedupKey
+	if dedupKey == "" {
+		dedupKey = "lighthouse/" + string(msg.Alert.Type) + "/" + msg.Alert.Title
+	}
+	details := make(map[string]interface{}, len(msg.Alert.Fields)+1)
+	for _, f := range msg.Alert.Fields {
+		details[f.Name] = f.Value
+	}
+	if msg.Alert.Link != "" {
+		details["link"] = msg.Alert.Link
+	}
+	return p.Pager.Page(ctx, IncidentTrigger, Incident{
+		DedupKey:  dedupKey,
+		Summary:   msg.Title,
+		Source:    "lighthouse",
+		Severity:  msg.Alert.Severity,
+		Class:     string(msg.Alert.Type),
+		Details:   details,
+		Timestamp: msg.Alert.Timestamp,
+	})
+}
+
+// =============================================
+// PagerDuty Events API v2
+// =============================================
+
+// DefaultPagerDutyEventsURL PagerDuty Events API v2 地址。
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyConfig PagerDuty 配置。RoutingKey 属敏感信息，仅从环境变量注入。
+type PagerDutyConfig struct {
+	RoutingKey string
+	EventsURL  string
+	Timeout    time.Duration
+}
+
+// PagerDutyPager 通过 Events API v2 管理事件。
+type PagerDutyPager struct {
+	config PagerDutyConfig
+	client *http.Client
+}
+
+// NewPagerDutyPager 创建 PagerDuty Pager。
+func NewPagerDutyPager(config PagerDutyConfig) *PagerDutyPager {
+	if config.EventsURL == "" {
+		config.EventsURL = DefaultPagerDutyEventsURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &PagerDutyPager{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// Name implements Pager.
+func (p *PagerDutyPager) Name() string { return "pagerduty" }
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Group         string                 `json:"group,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// Page implements Pager. payload 仅在 trigger 时发送。
+func (p *PagerDutyPager) Page(ctx context.Context, action IncidentAction, incident Incident) error {
+	if p.config.RoutingKey == "" {
+		return fmt.Errorf("pagerduty routing key not configured")
+	}
+	if incident.DedupKey == "" {
+		return fmt.Errorf("pagerduty: dedup key is required")
+	}
+	event := pagerDutyEvent{
+		RoutingKey:  p.config.RoutingKey,
+		EventAction: string(action),
+		DedupKey:    incident.DedupKey,
+	}
+	if action == IncidentTrigger {
+		payload := &pagerDutyPayload{
+			Summary:       incident.Summary,
+			Source:        incident.Source,
+			Severity:      pagerDutySeverity(incident.Severity),
+			Component:     incident.Component,
+			Group:         incident.Group,
+			Class:         incident.Class,
+			CustomDetails: incident.Details,
+		}
+		if !incident.Timestamp.IsZero() {
+			payload.Timestamp = incident.Timestamp.UTC().Format(time.RFC3339)
+		}
+		event.Payload = payload
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, p.client, p.config.EventsURL, body, nil)
+}
+
+// pagerDutySeverity PagerDuty 仅接受 critical/error/warning/info。
+func pagerDutySeverity(sev Severity) string {
+	switch sev {
+	case SeverityCritical:
+		return "critical"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// =============================================
+// Opsgenie Alert API v2
+// =============================================
+
+// DefaultOpsgenieAPIURL Opsgenie API 地址（EU 区域为 https://api.eu.opsgenie.com）。
+const DefaultOpsgenieAPIURL = "https://api.opsgenie.com"
+
+// OpsgenieConfig Opsgenie 配置。APIKey 属敏感信息，仅从环境变量注入。
+type OpsgenieConfig struct {
+	APIKey  string
+	APIURL  string
+	Timeout time.Duration
+}
+
+// OpsgeniePager 通过 Alert API 管理事件，dedup key 映射为 alias。
+type OpsgeniePager struct {
+	config OpsgenieConfig
+	client *http.Client
+}
+
+// NewOpsgeniePager 创建 Opsgenie Pager。
+func NewOpsgeniePager(config OpsgenieConfig) *OpsgeniePager {
+	if config.APIURL == "" {
+		config.APIURL = DefaultOpsgenieAPIURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &OpsgeniePager{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// Name implements Pager.
+func (o *OpsgeniePager) Name() string { return "opsgenie" }
+
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source,omitempty"`
+	Entity      string            `json:"entity,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+type opsgenieAction struct {
+	Source string `json:"source,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// Page implements Pager.
+func (o *OpsgeniePager) Page(ctx context.Context, action IncidentAction, incident Incident) error {
+	if o.config.APIKey == "" {
+		return fmt.Errorf("opsgenie api key not configured")
+	}
+	if incident.DedupKey == "" {
+		return fmt.Errorf("opsgenie: dedup key is required")
+	}
+
+	base := strings.TrimRight(o.config.APIURL, "/") + "/v2/alerts"
+	var endpoint string
+	var payload interface{}
+	switch action {
+	case IncidentTrigger:
+		endpoint = base
+		details := make(map[string]string, len(incident.Details))
+		for k, v := range incident.Details {
+			details[k] = fmt.Sprint(v)
+		}
+		var tags []string
+		if incident.Class != "" {
+			tags = append(tags, incident.Class)
+		}
+		payload = opsgenieAlert{
+			Message:     truncate(incident.Summary, 130), // Opsgenie message 上限 130 字符
+			Alias:       incident.DedupKey,
+			Description: incident.Summary,
+			Priority:    opsgeniePriority(incident.Severity),
+			Source:      incident.Source,
+			Entity:      incident.Component,
+			Tags:        tags,
+			Details:     details,
+		}
+	case IncidentAcknowledge:
+		endpoint = base + "/" + url.PathEscape(incident.DedupKey) + "/acknowledge?identifierType=alias"
+		payload = opsgenieAction{Source: incident.Source}
+	case IncidentResolve:
+		endpoint = base + "/" + url.PathEscape(incident.DedupKey) + "/close?identifierType=alias"
+		payload = opsgenieAction{Source: incident.Source}
+	default:
+		return fmt.Errorf("opsgenie: unsupported action %s", action)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.config.APIKey)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("opsgenie returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func opsgeniePriority(sev Severity) string {
+	switch sev {
+	case SeverityCritical:
+		return "P1"
+	case SeverityWarning:
+		return "P3"
+	default:
+		return "P5"
+	}
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,147 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+)
+
+type recordingPager struct {
+	actions []IncidentAction
+	keys    []string
+}
+
+func (r *recordingPager) Name() string { return "recording" }
+
+func (r *recordingPager) Page(ctx context.Context, action IncidentAction, incident Incident) error {
+	r.actions = append(r.actions, action)
+	r.keys = append(r.keys, incident.DedupKey)
+	return nil
+}
+
+func testViolation() slo.SLOViolationEvent {
+	return slo.SLOViolationEvent{
+		EventID:        "ev-1",
+		ViolationTime:  time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		ViolationType:  "availability",
+		ActualValue:    98.5,
+		ThresholdValue: 99.9,
+		ServiceName:    "checkout",
+		Namespace:      "app-prod",
+	}
+}
+
+func TestSLOIncidentManagerLifecycle(t *testing.T) {
+	pager := &recordingPager{}
+	m := NewSLOIncidentManager(SLOPagingConfig{}, pager)
+	ctx := context.Background()
+	ev := testViolation()
+
+	// warning with low burn rate: no page
+	action, err := m.HandleViolation(ctx, ev, slo.SLOStatusWarning, &slo.SLOBurnRate{CurrentBurnRate: 2})
+	if err != nil || action != "" {
+		t.Fatalf("expected no action for warning, got %q %v", action, err)
+	}
+	// extreme burn rate pages even before critical
+	action, _ = m.HandleViolation(ctx, ev, slo.SLOStatusWarning, &slo.SLOBurnRate{CurrentBurnRate: 20})
+	if action != IncidentTrigger {
+		t.Fatalf("expected trigger for extreme burn rate, got %q", action)
+	}
+	// still critical: deduplicated
+	action, _ = m.HandleViolation(ctx, ev, slo.SLOStatusCritical, nil)
+	if action != "" {
+		t.Fatalf("expected dedup of open incident, got %q", action)
+	}
+	if m.OpenIncidents() != 1 {
+		t.Fatalf("expected 1 open incident, got %d", m.OpenIncidents())
+	}
+
+	recovered := ev.ViolationTime.Add(time.Hour)
+	ev.RecoveryTime = &recovered
+	action, _ = m.HandleViolation(ctx, ev, slo.SLOStatusHealthy, nil)
+	if action != IncidentResolve {
+		t.Fatalf("expected resolve on recovery, got %q", action)
+	}
+	if len(pager.actions) != 2 || pager.keys[0] != pager.keys[1] || pager.keys[0] != "lighthouse/slo/app-prod/checkout/availability" {
+		t.Errorf("unexpected pager calls: %v %v", pager.actions, pager.keys)
+	}
+}
+
+func TestPagerDutyPagerPayload(t *testing.T) {
+	var events []pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev pagerDutyEvent
+		_ = json.NewDecoder(r.Body).Decode(&ev)
+		events = append(events, ev)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := NewPagerDutyPager(PagerDutyConfig{RoutingKey: "rk", EventsURL: srv.URL})
+	m := NewSLOIncidentManager(SLOPagingConfig{}, p)
+	ctx := context.Background()
+	if _, err := m.HandleViolation(ctx, testViolation(), slo.SLOStatusCritical, nil); err != nil {
+		t.Fatalf("trigger failed: %v", err)
+	}
+	if err := m.Acknowledge(ctx, SLODedupKey(testViolation())); err != nil {
+		t.Fatalf("acknowledge failed: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	trigger := events[0]
+	if trigger.EventAction != "trigger" || trigger.RoutingKey != "rk" || trigger.Payload == nil {
+		t.Fatalf("unexpected trigger event: %+v", trigger)
+	}
+	if trigger.Payload.Severity != "critical" || trigger.Payload.Group != "app-prod" || trigger.Payload.CustomDetails["event_id"] != "ev-1" {
+		t.Errorf("unexpected payload: %+v", trigger.Payload)
+	}
+	if events[1].EventAction != "acknowledge" || events[1].Payload != nil || events[1].DedupKey != trigger.DedupKey {
+		t.Errorf("unexpected acknowledge event: %+v", events[1])
+	}
+}
+
+func TestOpsgeniePagerUsesAlias(t *testing.T) {
+	var paths []string
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := NewOpsgeniePager(OpsgenieConfig{APIKey: "key", APIURL: srv.URL})
+	ctx := context.Background()
+	inc := IncidentFromViolation(testViolation(), nil)
+	for _, action := range []IncidentAction{IncidentTrigger, IncidentResolve} {
+		if err := p.Page(ctx, action, inc); err != nil {
+			t.Fatalf("%s failed: %v", action, err)
+		}
+	}
+	if auth != "GenieKey key" {
+		t.Errorf("unexpected auth header %q", auth)
+	}
+	if paths[0] != "/v2/alerts" || paths[1] != "/v2/alerts/lighthouse%2Fslo%2Fapp-prod%2Fcheckout%2Favailability/close?identifierType=alias" {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+}
+
+func TestPagingDriverOnlyPagesCritical(t *testing.T) {
+	pager := &recordingPager{}
+	d := newTestDispatcher(DefaultDispatcherConfig())
+	d.Route(AlertTypeSLOViolation, PagingDriver{Pager: pager})
+	ctx := context.Background()
+
+	_ = d.Notify(ctx, Alert{Type: AlertTypeSLOViolation, Severity: SeverityWarning, Title: "degraded"})
+	_ = d.Notify(ctx, Alert{Type: AlertTypeSLOViolation, Severity: SeverityCritical, Title: "down", DedupKey: "k1"})
+	if len(pager.actions) != 1 || pager.keys[0] != "k1" {
+		t.Errorf("expected only critical alert paged, got %v %v", pager.actions, pager.keys)
+	}
+}
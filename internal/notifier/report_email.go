@@ -0,0 +1,206 @@
+// Package notifier report_email.go: 周报邮件（成本/ROI 摘要 + 内联趋势图），按 namespace 归属人投递。
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+)
+
+// OwnershipResolver 解析 namespace 的报表接收人。
+type OwnershipResolver interface {
+	OwnersFor(ctx context.Context, namespace string) ([]string, error)
+}
+
+// StaticOwnership 基于配置的 namespace → 邮箱映射；"*" 为兜底接收人。
+type StaticOwnership map[string][]string
+
+// OwnersFor implements OwnershipResolver.
+func (s StaticOwnership) OwnersFor(ctx context.Context, namespace string) ([]string, error) {
+	if owners := s[namespace]; len(owners) > 0 {
+		return owners, nil
+	}
+	return s["*"], nil
+}
+
+// WeeklyReport 单个 namespace 的周报数据。
+type WeeklyReport struct {
+	Namespace       string
+	PeriodStart     time.Time
+	PeriodEnd       time.Time
+	Currency        string
+	TotalCost       float64
+	PreviousCost    float64
+	OptimizableCost float64
+	Efficiency      float64       // 0-100
+	ROI             float64       // 百分比；无 ROI 基线时为 0
+	DailyCosts      []float64     // 按天排列，用于趋势图
+	Target          *TargetStatus // 未设置效率目标时为 nil
+	DashboardLink   string
+	Locale          i18n.Locale // 邮件语言，空为默认语言
+}
+
+// CostChange 环比变化百分比。
+func (r WeeklyReport) CostChange() float64 {
+	if r.PreviousCost <= 0 {
+		return 0
+	}
+	return (r.TotalCost - r.PreviousCost) / r.PreviousCost * 100
+}
+
+const weeklyReportChartCID = "daily-cost-chart"
+
+var weeklyReportTemplates = localizedHTML("weekly_report", template.FuncMap{
+	"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"pct":   func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"date":  func(t time.Time) string { return t.Format("2006-01-02") },
+}, `<!DOCTYPE html>
+<html><body style="font-family:Arial,sans-serif;color:#222">
+<h2>{{t "report.weekly.heading" .Namespace}}</h2>
+<p>{{date .PeriodStart}} ~ {{date .PeriodEnd}}</p>
+<table cellpadding="6" style="border-collapse:collapse">
+<tr><td>{{t "report.weekly.total_cost"}}</td><td><b>{{money .TotalCost}} {{.Currency}}</b> ({{t "report.weekly.vs_previous" (pct .CostChange)}})</td></tr>
+<tr><td>{{t "report.weekly.optimizable"}}</td><td>{{money .OptimizableCost}} {{.Currency}}</td></tr>
+<tr><td>{{t "report.weekly.efficiency"}}</td><td>{{pct .Efficiency}}</td></tr>
+{{if .ROI}}<tr><td>ROI</td><td>{{pct .ROI}}</td></tr>{{end}}
+{{with .Target}}<tr><td>{{t "report.weekly.target"}}</td><td>{{pct .Target}} &middot; {{t "report.weekly.target_status" (pct .AttainmentRate) .CurrentStreak}}</td></tr>{{end}}
+</table>
+{{if .DailyCosts}}<p><img src="cid:`+weeklyReportChartCID+`" alt="{{t "report.weekly.chart"}}"></p>{{end}}
+{{if .DashboardLink}}<p><a href="{{.DashboardLink}}">{{t "report.open"}}</a></p>{{end}}
+</body></html>`)
+
+// localizedHTML 按每种支持的语言解析一份 HTML 模板，t 绑定该语言的消息目录（见 i18n.T）。
+func localizedHTML(name string, funcs template.FuncMap, text string) map[i18n.Locale]*template.Template {
+	templates := make(map[i18n.Locale]*template.Template)
+	for _, locale := range i18n.Supported() {
+		localeFuncs := template.FuncMap{"t": i18n.Translator(locale)}
+		for k, f := range funcs {
+			localeFuncs[k] = f
+		}
+		templates[locale] = template.Must(template.New(name).Funcs(localeFuncs).Parse(text))
+	}
+	return templates
+}
+
+// localeTemplate 返回 locale 的模板，未知或空语言取默认语言。
+func localeTemplate(templates map[i18n.Locale]*template.Template, locale i18n.Locale) *template.Template {
+	if tpl, ok := templates[locale]; ok {
+		return tpl
+	}
+	return templates[i18n.Default]
+}
+
+// RenderWeeklyReport 渲染周报邮件（不含收件人），语言为 r.Locale。
+func RenderWeeklyReport(r WeeklyReport) (EmailMessage, error) {
+	var body bytes.Buffer
+	if err := localeTemplate(weeklyReportTemplates, r.Locale).Execute(&body, r); err != nil {
+		return EmailMessage{}, fmt.Errorf("render weekly report for %s: %w", r.Namespace, err)
+	}
+	msg := EmailMessage{
+		Subject:  i18n.T(r.Locale, "report.weekly.subject", r.Namespace, r.PeriodStart.Format("2006-01-02")),
+		HTMLBody: body.String(),
+	}
+	if len(r.DailyCosts) > 0 {
+		chart, err := renderBarChart(r.DailyCosts, 420, 160)
+		if err != nil {
+			return EmailMessage{}, err
+		}
+		msg.Inline = []InlineImage{{ContentID: weeklyReportChartCID, ContentType: "image/png", Data: chart}}
+	}
+	return msg, nil
+}
+
+// renderBarChart 生成简单柱状图 PNG（邮件客户端普遍不执行 JS/SVG，内联 PNG 兼容性最好）。
+func renderBarChart(values []float64, width, height int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bg := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	bar := color.RGBA{R: 0x2f, G: 0x6f, B: 0xeb, A: 0xff}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, bg)
+		}
+	}
+
+	var maxV float64
+	for _, v := range values {
+		if v > maxV {
+			maxV = v
+		}
+	}
+	if maxV > 0 {
+		slot := width / len(values)
+		gap := slot / 5
+		for i, v := range values {
+			h := int(v / maxV * float64(height-4))
+			x0 := i*slot + gap
+			for x := x0; x < x0+slot-2*gap && x < width; x++ {
+				for y := height - h; y < height; y++ {
+					img.Set(x, y, bar)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EmailSender 邮件投递接口，*SMTPSender 实现该接口。
+type EmailSender interface {
+	Send(ctx context.Context, msg EmailMessage) error
+}
+
+// ReportMailer 报表调度器调用的周报投递入口。
+type ReportMailer struct {
+	Sender EmailSender
+	Owners OwnershipResolver
+}
+
+// SendWeeklyReports 逐个 namespace 渲染并发送给归属人。无归属人的 namespace 跳过。
+// 单个 namespace 失败不影响其余发送，返回已发送数量与第一个错误。
+func (m *ReportMailer) SendWeeklyReports(ctx context.Context, reports []WeeklyReport) (int, error) {
+	sorted := append([]WeeklyReport(nil), reports...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Namespace < sorted[j].Namespace })
+
+	var sent int
+	var firstErr error
+	for _, r := range sorted {
+		owners, err := m.Owners.OwnersFor(ctx, r.Namespace)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("resolve owners for %s: %w", r.Namespace, err)
+			}
+			continue
+		}
+		if len(owners) == 0 {
+			continue
+		}
+		msg, err := RenderWeeklyReport(r)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		msg.To = owners
+		if err := m.Sender.Send(ctx, msg); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("send weekly report for %s: %w", r.Namespace, err)
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, firstErr
+}
@@ -0,0 +1,38 @@
+// Package notifier routing.go: 路由规则与静默的标签匹配。告警的匹配标签为 Alert.Labels 加上
+// alert_type 与 severity；匹配器中每个标签都须相等（值 "*" 只要求标签存在），空匹配器匹配所有告警。
+package notifier
+
+// Label names every alert can be matched on.
+const (
+	LabelAlertType = "alert_type"
+	LabelSeverity  = "severity"
+	LabelNamespace = "namespace"
+	LabelTeam      = "team"
+	LabelRule      = "rule"
+)
+
+// AlertLabels 返回告警的匹配标签。
+func AlertLabels(alert Alert) map[string]string {
+	labels := make(map[string]string, len(alert.Labels)+2)
+	for k, v := range alert.Labels {
+		labels[k] = v
+	}
+	labels[LabelAlertType] = string(alert.Type)
+	severity := alert.Severity
+	if severity == "" {
+		severity = SeverityInfo
+	}
+	labels[LabelSeverity] = string(severity)
+	return labels
+}
+
+// MatchLabels 判断 labels 是否满足全部 matchers。
+func MatchLabels(matchers, labels map[string]string) bool {
+	for k, want := range matchers {
+		got, ok := labels[k]
+		if !ok || (want != "*" && got != want) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,267 @@
+// Package notifier rule.go: 告警规则的条件 DSL。条件由比较子句组成，子句为
+// `指标{标签="值", ...} 运算符 阈值`（标签过滤可省略），以 and / or 连接，and 优先于 or，例如
+// `cost{namespace="payments"} > 500 and waste_ratio{namespace="payments"} >= 30 or zombie_count > 3`。
+// 指标名与标签的含义由求值方（MetricResolver）决定。
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Comparison operators of a condition clause.
+var comparisonOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// Comparison 一个条件子句：指标（按标签过滤）与阈值的比较。
+type Comparison struct {
+	Metric    string            `json:"metric"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Op        string            `json:"op"`
+	Threshold float64           `json:"threshold"`
+}
+
+// String 返回子句的规范写法（标签按名称排序）。
+func (c Comparison) String() string {
+	var b strings.Builder
+	b.WriteString(c.Metric)
+	if len(c.Labels) > 0 {
+		names := make([]string, 0, len(c.Labels))
+		for name := range c.Labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteByte('{')
+		for i, name := range names {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(name + "=" + strconv.Quote(c.Labels[name]))
+		}
+		b.WriteByte('}')
+	}
+	b.WriteString(" " + c.Op + " " + strconv.FormatFloat(c.Threshold, 'f', -1, 64))
+	return b.String()
+}
+
+// Holds 判断 value 是否满足子句。
+func (c Comparison) Holds(value float64) bool {
+	switch c.Op {
+	case ">":
+		return value > c.Threshold
+	case ">=":
+		return value >= c.Threshold
+	case "<":
+		return value < c.Threshold
+	case "<=":
+		return value <= c.Threshold
+	case "==":
+		return value == c.Threshold
+	case "!=":
+		return value != c.Threshold
+	}
+	return false
+}
+
+// Condition 解析后的条件：Any 中任一组（and 连接的子句）全部满足即成立。
+type Condition struct {
+	Any [][]Comparison
+}
+
+// Comparisons 按出现顺序返回所有子句。
+func (c *Condition) Comparisons() []Comparison {
+	var out []Comparison
+	for _, all := range c.Any {
+		out = append(out, all...)
+	}
+	return out
+}
+
+// String 返回条件的规范写法。
+func (c *Condition) String() string {
+	groups := make([]string, 0, len(c.Any))
+	for _, all := range c.Any {
+		clauses := make([]string, 0, len(all))
+		for _, cmp := range all {
+			clauses = append(clauses, cmp.String())
+		}
+		groups = append(groups, strings.Join(clauses, " and "))
+	}
+	return strings.Join(groups, " or ")
+}
+
+// MetricResolver 返回子句所指指标的当前值；ok 为 false 表示没有数据，此时子句不成立。
+type MetricResolver func(metric string, labels map[string]string) (value float64, ok bool, err error)
+
+// ComparisonResult 一个子句的求值结果。
+type ComparisonResult struct {
+	Comparison
+	Value   float64 `json:"value"`
+	HasData bool    `json:"has_data"`
+	Matched bool    `json:"matched"`
+}
+
+// Evaluate 对所有子句求值（不短路，便于展示每个子句的取值）并返回条件是否成立。
+func (c *Condition) Evaluate(resolve MetricResolver) (bool, []ComparisonResult, error) {
+	var results []ComparisonResult
+	holds := false
+	for _, all := range c.Any {
+		groupHolds := true
+		for _, cmp := range all {
+			value, ok, err := resolve(cmp.Metric, cmp.Labels)
+			if err != nil {
+				return false, nil, fmt.Errorf("evaluate %s: %w", cmp.Metric, err)
+			}
+			r := ComparisonResult{Comparison: cmp, Value: value, HasData: ok, Matched: ok && cmp.Holds(value)}
+			groupHolds = groupHolds && r.Matched
+			results = append(results, r)
+		}
+		holds = holds || groupHolds
+	}
+	return holds, results, nil
+}
+
+// ParseCondition 解析条件 DSL。
+func ParseCondition(s string) (*Condition, error) {
+	p := &conditionParser{src: s}
+	cond := &Condition{}
+	all := []Comparison{}
+	for {
+		cmp, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, cmp)
+		p.skipSpace()
+		if p.eof() {
+			break
+		}
+		switch word := strings.ToLower(p.ident()); word {
+		case "and":
+		case "or":
+			cond.Any = append(cond.Any, all)
+			all = []Comparison{}
+		default:
+			return nil, p.errorf("expected and / or")
+		}
+	}
+	cond.Any = append(cond.Any, all)
+	return cond, nil
+}
+
+type conditionParser struct {
+	src string
+	pos int
+}
+
+func (p *conditionParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *conditionParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("condition: "+format+" at offset %d", append(args, p.pos)...)
+}
+
+func (p *conditionParser) skipSpace() {
+	for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// ident reads [A-Za-z_][A-Za-z0-9_]*; empty when none.
+func (p *conditionParser) ident() string {
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() {
+		ch := p.src[p.pos]
+		if ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || p.pos > start && ch >= '0' && ch <= '9' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *conditionParser) consume(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) comparison() (Comparison, error) {
+	cmp := Comparison{Metric: p.ident()}
+	if cmp.Metric == "" {
+		return cmp, p.errorf("expected metric name")
+	}
+	if p.consume("{") {
+		cmp.Labels = make(map[string]string)
+		for !p.consume("}") {
+			if len(cmp.Labels) > 0 && !p.consume(",") {
+				return cmp, p.errorf("expected , or }")
+			}
+			name := p.ident()
+			if name == "" {
+				return cmp, p.errorf("expected label name")
+			}
+			if !p.consume("=") {
+				return cmp, p.errorf("expected = after label %s", name)
+			}
+			value, err := p.quoted()
+			if err != nil {
+				return cmp, err
+			}
+			if _, dup := cmp.Labels[name]; dup {
+				return cmp, p.errorf("duplicate label %s", name)
+			}
+			cmp.Labels[name] = value
+		}
+	}
+	for _, op := range comparisonOps {
+		if p.consume(op) {
+			cmp.Op = op
+			break
+		}
+	}
+	if cmp.Op == "" {
+		return cmp, p.errorf("expected comparison operator (>, >=, <, <=, ==, !=)")
+	}
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() && strings.ContainsRune("0123456789.-+eE", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	threshold, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return cmp, p.errorf("expected numeric threshold")
+	}
+	cmp.Threshold = threshold
+	return cmp, nil
+}
+
+// quoted reads a double-quoted Go string literal.
+func (p *conditionParser) quoted() (string, error) {
+	p.skipSpace()
+	if p.eof() || p.src[p.pos] != '"' {
+		return "", p.errorf("expected quoted label value")
+	}
+	end := p.pos + 1
+	for end < len(p.src) && p.src[end] != '"' {
+		if p.src[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= len(p.src) {
+		return "", p.errorf("unterminated label value")
+	}
+	value, err := strconv.Unquote(p.src[p.pos : end+1])
+	if err != nil {
+		return "", p.errorf("invalid label value")
+	}
+	p.pos = end + 1
+	return value, nil
+}
@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCondition(t *testing.T) {
+	cond, err := ParseCondition(`cost{namespace="payments", workload="api"} > 500 AND waste_ratio>=30 or zombie_count != 0`)
+	if err != nil {
+		t.Fatalf("ParseCondition failed: %v", err)
+	}
+	if len(cond.Any) != 2 || len(cond.Any[0]) != 2 || len(cond.Any[1]) != 1 {
+		t.Fatalf("groups = %+v, want [[cost waste_ratio] [zombie_count]]", cond.Any)
+	}
+	first := cond.Any[0][0]
+	if first.Metric != "cost" || first.Labels["namespace"] != "payments" || first.Labels["workload"] != "api" || first.Op != ">" || first.Threshold != 500 {
+		t.Errorf("first clause = %+v", first)
+	}
+	want := `cost{namespace="payments", workload="api"} > 500 and waste_ratio >= 30 or zombie_count != 0`
+	if got := cond.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+
+	for _, bad := range []string{"", "cost", "cost > ", "cost >> 5", `cost{namespace=payments} > 1`, `cost{ns="a"} > 1 xor waste > 2`, `cost{ns="a",ns="b"} > 1`, "cost > 1 and"} {
+		if _, err := ParseCondition(bad); err == nil {
+			t.Errorf("ParseCondition(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	cond, err := ParseCondition(`cost{namespace="a"} > 100 and waste_ratio < 50 or budget_used >= 90`)
+	if err != nil {
+		t.Fatalf("ParseCondition failed: %v", err)
+	}
+	values := map[string]float64{"cost": 120, "waste_ratio": 60}
+	resolve := func(metric string, labels map[string]string) (float64, bool, error) {
+		v, ok := values[metric]
+		return v, ok, nil
+	}
+	holds, results, err := cond.Evaluate(resolve)
+	if err != nil || holds {
+		t.Fatalf("Evaluate = %v, %v; want false", holds, err)
+	}
+	if len(results) != 3 || !results[0].Matched || results[1].Matched || results[2].HasData {
+		t.Errorf("results = %+v", results)
+	}
+
+	values["budget_used"] = 95 // 无数据的子句不成立，有数据后 or 分支成立
+	if holds, _, _ = cond.Evaluate(resolve); !holds {
+		t.Error("want condition to hold once budget_used >= 90")
+	}
+
+	failing := func(string, map[string]string) (float64, bool, error) { return 0, false, errors.New("boom") }
+	if _, _, err := cond.Evaluate(failing); err == nil {
+		t.Error("expected resolver error")
+	}
+}
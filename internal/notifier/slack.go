@@ -0,0 +1,129 @@
+// Package notifier slack.go: Slack Incoming Webhook 驱动。
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SlackRoute 某告警类型使用的 webhook 与频道；字段为空时回退到 SlackConfig 默认值。
+type SlackRoute struct {
+	WebhookURL string
+	Channel    string
+}
+
+// SlackConfig Slack 驱动配置。WebhookURL 属敏感信息，仅从环境变量注入。
+type SlackConfig struct {
+	WebhookURL string
+	Channel    string
+	Username   string
+	Routes     map[AlertType]SlackRoute
+	Timeout    time.Duration
+}
+
+// SlackDriver 通过 Incoming Webhook 投递消息。
+type SlackDriver struct {
+	config SlackConfig
+	client *http.Client
+}
+
+// NewSlackDriver 创建 Slack 驱动。
+func NewSlackDriver(config SlackConfig) *SlackDriver {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &SlackDriver{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// Name implements Driver.
+func (s *SlackDriver) Name() string { return "slack" }
+
+type slackPayload struct {
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color string `json:"color,omitempty"`
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text"`
+	Ts    int64  `json:"ts,omitempty"`
+}
+
+// Send implements Driver.
+func (s *SlackDriver) Send(ctx context.Context, msg Message) error {
+	route := s.config.Routes[msg.Alert.Type]
+	webhook := route.WebhookURL
+	if webhook == "" {
+		webhook = s.config.WebhookURL
+	}
+	if webhook == "" {
+		return fmt.Errorf("slack webhook not configured for alert type %s", msg.Alert.Type)
+	}
+	channel := route.Channel
+	if channel == "" {
+		channel = s.config.Channel
+	}
+
+	body, err := json.Marshal(slackPayload{
+		Channel:  channel,
+		Username: s.config.Username,
+		Text:     msg.Title,
+		Attachments: []slackAttachment{{
+			Color: slackColor(msg.Alert.Severity),
+			Text:  msg.Text,
+			Ts:    msg.Alert.Timestamp.Unix(),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.client, webhook, body, nil)
+}
+
+func slackColor(sev Severity) string {
+	switch sev {
+	case SeverityCritical:
+		return "danger"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "good"
+	}
+}
+
+// postJSON POST JSON 并校验 2xx；out 非 nil 时解析响应体。
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, string(data))
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("decode webhook response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Package notifier slo_paging.go: SLO 违约 → paging 事件映射与生命周期管理。
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+)
+
+// DefaultExtremeBurnRate 1h 窗口 14.4 倍燃烧率即 2% 月度错误预算/小时（Google SRE 多窗口告警推荐值）。
+const DefaultExtremeBurnRate = 14.4
+
+// SLODedupKey 同一服务、同一违约类型共享一个事件。
+func SLODedupKey(ev slo.SLOViolationEvent) string {
+	return fmt.Sprintf("lighthouse/slo/%s/%s/%s", ev.Namespace, ev.ServiceName, ev.ViolationType)
+}
+
+// IncidentFromViolation 将 SLOViolationEvent 映射为事件。
+func IncidentFromViolation(ev slo.SLOViolationEvent, burn *slo.SLOBurnRate) Incident {
+	details := map[string]interface{}{
+		"event_id":        ev.EventID,
+		"violation_type":  ev.ViolationType,
+		"actual_value":    ev.ActualValue,
+		"threshold_value": ev.ThresholdValue,
+		"deviation":       ev.Deviation,
+		"duration":        ev.Duration.String(),
+	}
+	if ev.Endpoint != "" {
+		details["endpoint"] = ev.Endpoint
+	}
+	if ev.UserImpact != "" {
+		details["user_impact"] = ev.UserImpact
+	}
+	if ev.BusinessImpact != "" {
+		details["business_impact"] = ev.BusinessImpact
+	}
+	if ev.FinancialImpact != "" {
+		details["financial_impact"] = ev.FinancialImpact
+	}
+	if burn != nil {
+		details["burn_rate"] = burn.CurrentBurnRate
+		details["burn_rate_window"] = burn.WindowSize.String()
+		details["time_to_exhaustion"] = burn.TimeToExhaustion.String()
+	}
+
+	return Incident{
+		DedupKey: SLODedupKey(ev),
+		Summary: fmt.Sprintf("SLO %s violated for %s/%s: %.4g (threshold %.4g)",
+			ev.ViolationType, ev.Namespace, ev.ServiceName, ev.ActualValue, ev.ThresholdValue),
+		Source:    "lighthouse",
+		Severity:  SeverityCritical,
+		Component: ev.ServiceName,
+		Group:     ev.Namespace,
+		Class:     "slo_" + ev.ViolationType,
+		Details:   details,
+		Timestamp: ev.ViolationTime,
+	}
+}
+
+// SLOPagingConfig 何时叫醒值班。
+type SLOPagingConfig struct {
+	// ExtremeBurnRate 燃烧率达到该值即 page，即使 SLO 状态尚未 critical；<=0 使用默认值。
+	ExtremeBurnRate float64
+}
+
+// SLOIncidentManager 驱动 SLO 事件生命周期：critical 或极端燃烧率时 trigger，恢复时 resolve。
+// 记录已打开的 dedup key，避免同一违约重复 trigger。
+type SLOIncidentManager struct {
+	pagers []Pager
+	config SLOPagingConfig
+
+	mu   sync.Mutex
+	open map[string]time.Time // dedup key -> 打开时间
+}
+
+// NewSLOIncidentManager 创建事件管理器。
+func NewSLOIncidentManager(config SLOPagingConfig, pagers ...Pager) *SLOIncidentManager {
+	if config.ExtremeBurnRate <= 0 {
+		config.ExtremeBurnRate = DefaultExtremeBurnRate
+	}
+	return &SLOIncidentManager{pagers: pagers, config: config, open: make(map[string]time.Time)}
+}
+
+// ShouldPage critical 状态或燃烧率达到极端阈值时需要 page。
+func (m *SLOIncidentManager) ShouldPage(status slo.SLOStatus, burn *slo.SLOBurnRate) bool {
+	if status == slo.SLOStatusCritical {
+		return true
+	}
+	return burn != nil && burn.CurrentBurnRate >= m.config.ExtremeBurnRate
+}
+
+// HandleViolation 处理一次 SLO 评估结果，返回执行的动作（无动作时为空）。
+// RecoveryTime 非空表示已恢复，对已打开的事件执行 resolve。
+func (m *SLOIncidentManager) HandleViolation(ctx context.Context, ev slo.SLOViolationEvent, status slo.SLOStatus, burn *slo.SLOBurnRate) (IncidentAction, error) {
+	key := SLODedupKey(ev)
+
+	m.mu.Lock()
+	_, isOpen := m.open[key]
+	m.mu.Unlock()
+
+	switch {
+	case ev.RecoveryTime != nil || status == slo.SLOStatusHealthy:
+		if !isOpen {
+			return "", nil
+		}
+		if err := m.page(ctx, IncidentResolve, Incident{DedupKey: key, Source: "lighthouse"}); err != nil {
+			return "", err
+		}
+		m.mu.Lock()
+		delete(m.open, key)
+		m.mu.Unlock()
+		return IncidentResolve, nil
+
+	case m.ShouldPage(status, burn):
+		if isOpen {
+			return "", nil
+		}
+		if err := m.page(ctx, IncidentTrigger, IncidentFromViolation(ev, burn)); err != nil {
+			return "", err
+		}
+		m.mu.Lock()
+		m.open[key] = time.Now()
+		m.mu.Unlock()
+		return IncidentTrigger, nil
+	}
+	return "", nil
+}
+
+// Acknowledge 确认事件（例如值班已在 Lighthouse 中查看证据链）。
+func (m *SLOIncidentManager) Acknowledge(ctx context.Context, dedupKey string) error {
+	return m.page(ctx, IncidentAcknowledge, Incident{DedupKey: dedupKey, Source: "lighthouse"})
+}
+
+// OpenIncidents 返回当前打开的 dedup key 数量。
+func (m *SLOIncidentManager) OpenIncidents() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.open)
+}
+
+// page 发往所有 Pager；返回第一个错误，其余 Pager 仍会执行。
+func (m *SLOIncidentManager) page(ctx context.Context, action IncidentAction, incident Incident) error {
+	if len(m.pagers) == 0 {
+		return fmt.Errorf("no pager configured")
+	}
+	var firstErr error
+	for _, p := range m.pagers {
+		if err := p.Page(ctx, action, incident); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s %s: %w", p.Name(), action, err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,121 @@
+// Package notifier template.go: 告警模板，所有渠道共享同一份渲染结果，保证内容一致。
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+)
+
+// defaultTitleTemplate / defaultTextTemplate 未单独配置模板的告警类型使用。
+const (
+	defaultTitleTemplate = `[{{.Severity}}] {{.Title}}`
+	defaultTextTemplate  = `{{.Summary}}
+{{range .Fields}}
+- {{.Name}}: {{.Value}}{{end}}
+{{if .Link}}
+{{.Link}}{{end}}`
+)
+
+// 各告警类型默认正文模板；标签经 t 取自 i18n 消息目录。
+var defaultTextTemplates = map[AlertType]string{
+	AlertTypeBudgetBreach: `{{t "notify.budget_breached"}}: {{.Summary}}
+{{range .Fields}}
+- {{.Name}}: {{.Value}}{{end}}
+{{if .Link}}
+{{t "notify.details"}}: {{.Link}}{{end}}`,
+	AlertTypeSLOViolation: `{{t "notify.slo_violated"}}: {{.Summary}}
+{{range .Fields}}
+- {{.Name}}: {{.Value}}{{end}}
+{{if .Link}}
+{{t "notify.evidence"}}: {{.Link}}{{end}}`,
+	AlertTypeWeeklyReport: `{{.Summary}}
+{{if .Link}}
+{{t "notify.report"}}: {{.Link}}{{end}}`,
+	AlertTypeStaleData: `{{t "notify.stale_data"}}: {{.Summary}}
+{{range .Fields}}
+- {{.Name}}: {{.Value}}{{end}}`,
+	AlertTypePipelineCanary: `{{t "notify.pipeline_canary"}}: {{.Summary}}
+{{range .Fields}}
+- {{.Name}}: {{.Value}}{{end}}`,
+	AlertTypeRecommendationDigest: `{{.Summary}}
+{{range .Fields}}
+- {{.Name}}: {{.Value}}{{end}}
+{{if .Link}}
+{{t "notify.details"}}: {{.Link}}{{end}}`,
+}
+
+// Templates 按告警类型维护标题与正文模板。
+type Templates struct {
+	title map[AlertType]*template.Template
+	text  map[AlertType]*template.Template
+	dflt  [2]*template.Template
+	funcs template.FuncMap
+}
+
+// DefaultTemplates 返回默认语言（英文）的内置模板。
+func DefaultTemplates() *Templates {
+	return LocalizedTemplates(i18n.Default)
+}
+
+// LocalizedTemplates 返回指定语言的内置模板；模板（含 Set 覆盖的模板）可用 {{t "key"}} 引用消息目录。
+func LocalizedTemplates(locale i18n.Locale) *Templates {
+	t := &Templates{
+		title: make(map[AlertType]*template.Template),
+		text:  make(map[AlertType]*template.Template),
+		funcs: template.FuncMap{"t": i18n.Translator(locale)},
+	}
+	t.dflt = [2]*template.Template{
+		template.Must(template.New("title").Funcs(t.funcs).Parse(defaultTitleTemplate)),
+		template.Must(template.New("text").Funcs(t.funcs).Parse(defaultTextTemplate)),
+	}
+	for alertType, text := range defaultTextTemplates {
+		t.text[alertType] = template.Must(template.New(string(alertType)).Funcs(t.funcs).Parse(text))
+	}
+	return t
+}
+
+// Set 覆盖某告警类型的模板；空字符串表示保留原模板。
+func (t *Templates) Set(alertType AlertType, title, text string) error {
+	if title != "" {
+		tpl, err := template.New(string(alertType) + "-title").Funcs(t.funcs).Parse(title)
+		if err != nil {
+			return fmt.Errorf("parse title template for %s: %w", alertType, err)
+		}
+		t.title[alertType] = tpl
+	}
+	if text != "" {
+		tpl, err := template.New(string(alertType)).Funcs(t.funcs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("parse text template for %s: %w", alertType, err)
+		}
+		t.text[alertType] = tpl
+	}
+	return nil
+}
+
+// Render 渲染告警为 Message；未设置级别时按 info 处理。
+func (t *Templates) Render(alert Alert) (Message, error) {
+	if alert.Severity == "" {
+		alert.Severity = SeverityInfo
+	}
+	titleTpl, ok := t.title[alert.Type]
+	if !ok {
+		titleTpl = t.dflt[0]
+	}
+	textTpl, ok := t.text[alert.Type]
+	if !ok {
+		textTpl = t.dflt[1]
+	}
+
+	var title, text bytes.Buffer
+	if err := titleTpl.Execute(&title, alert); err != nil {
+		return Message{}, fmt.Errorf("render title for %s: %w", alert.Type, err)
+	}
+	if err := textTpl.Execute(&text, alert); err != nil {
+		return Message{}, fmt.Errorf("render text for %s: %w", alert.Type, err)
+	}
+	return Message{Alert: alert, Title: title.String(), Text: text.String()}, nil
+}
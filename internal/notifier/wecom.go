@@ -0,0 +1,64 @@
+// Package notifier wecom.go: 企业微信群机器人驱动。
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WeComConfig 企业微信群机器人配置。WebhookURL 含 key，属敏感信息，仅从环境变量注入。
+type WeComConfig struct {
+	WebhookURL string // https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=...
+	Timeout    time.Duration
+}
+
+// WeComDriver 通过群机器人 webhook 发送 markdown 消息。
+type WeComDriver struct {
+	config WeComConfig
+	client *http.Client
+}
+
+// NewWeComDriver 创建企业微信驱动。
+func NewWeComDriver(config WeComConfig) *WeComDriver {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &WeComDriver{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// Name implements Driver.
+func (w *WeComDriver) Name() string { return "wecom" }
+
+type weComPayload struct {
+	MsgType  string `json:"msgtype"`
+	Markdown struct {
+		Content string `json:"content"`
+	} `json:"markdown"`
+}
+
+// Send implements Driver.
+func (w *WeComDriver) Send(ctx context.Context, msg Message) error {
+	if w.config.WebhookURL == "" {
+		return fmt.Errorf("wecom webhook not configured")
+	}
+
+	var payload weComPayload
+	payload.MsgType = "markdown"
+	payload.Markdown.Content = markdownBody(msg)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var resp robotResponse
+	if err := postJSON(ctx, w.client, w.config.WebhookURL, body, &resp); err != nil {
+		return err
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("wecom error %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
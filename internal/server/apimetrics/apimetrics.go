@@ -0,0 +1,105 @@
+// Package apimetrics counts the requests of Lighthouse's own API per route: requests, server
+// errors and a latency histogram, exported in /metrics and evaluated as self-SLOs.
+package apimetrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds (seconds) of the request latency histogram.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Route identifies a route: the method and the registered path pattern (e.g. "/api/v1/workloads/:id").
+type Route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// String returns "METHOD path", the form used in configuration.
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Counts are the cumulative counters of a route.
+type Counts struct {
+	Requests uint64
+	Errors   uint64   // 5xx responses
+	Buckets  []uint64 // cumulative: requests that took at most LatencyBuckets[i]
+	Sum      float64  // total latency, seconds
+}
+
+// Sub returns the increase of c since prev.
+func (c Counts) Sub(prev Counts) Counts {
+	d := Counts{Requests: c.Requests - prev.Requests, Errors: c.Errors - prev.Errors, Sum: c.Sum - prev.Sum, Buckets: make([]uint64, len(c.Buckets))}
+	for i := range c.Buckets {
+		d.Buckets[i] = c.Buckets[i]
+		if i < len(prev.Buckets) {
+			d.Buckets[i] -= prev.Buckets[i]
+		}
+	}
+	return d
+}
+
+// Recorder records requests per route. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	routes map[Route]*Counts
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{routes: make(map[Route]*Counts)}
+}
+
+// ObserveRequest records a request of the route path (the registered pattern, not the URL).
+func (r *Recorder) ObserveRequest(method, path string, status int, latency time.Duration) {
+	seconds := latency.Seconds()
+	key := Route{Method: method, Path: path}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.routes[key]
+	if !ok {
+		c = &Counts{Buckets: make([]uint64, len(LatencyBuckets))}
+		r.routes[key] = c
+	}
+	c.Requests++
+	if status >= 500 {
+		c.Errors++
+	}
+	c.Sum += seconds
+	for i, bound := range LatencyBuckets {
+		if seconds <= bound {
+			c.Buckets[i]++
+		}
+	}
+}
+
+// Snapshot returns a copy of the counters of every route that has served a request.
+func (r *Recorder) Snapshot() map[Route]Counts {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[Route]Counts, len(r.routes))
+	for key, c := range r.routes {
+		cp := *c
+		cp.Buckets = append([]uint64(nil), c.Buckets...)
+		out[key] = cp
+	}
+	return out
+}
+
+// SortedRoutes returns the routes of a snapshot ordered by path, then method.
+func SortedRoutes(snapshot map[Route]Counts) []Route {
+	routes := make([]Route, 0, len(snapshot))
+	for r := range snapshot {
+		routes = append(routes, r)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
@@ -0,0 +1,56 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"encoding/json"
+)
+
+// =============================================
+// Kubernetes admission webhook DTOs
+// =============================================
+
+// The subset of admission.k8s.io/v1 AdmissionReview the cost estimation webhook reads and writes.
+
+// AdmissionReview is the body of an admission webhook call and of its answer.
+type AdmissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *AdmissionRequest  `json:"request,omitempty"`
+	Response   *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest describes the object being admitted.
+type AdmissionRequest struct {
+	UID       string                `json:"uid"`
+	Kind      AdmissionGroupVersion `json:"kind"`
+	Namespace string                `json:"namespace,omitempty"`
+	Name      string                `json:"name,omitempty"`
+	Operation string                `json:"operation"` // CREATE / UPDATE / DELETE / CONNECT
+	Object    json.RawMessage       `json:"object,omitempty"`
+	OldObject json.RawMessage       `json:"oldObject,omitempty"`
+	DryRun    *bool                 `json:"dryRun,omitempty"`
+}
+
+// AdmissionGroupVersion is the group/version/kind of the admitted object.
+type AdmissionGroupVersion struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// AdmissionResponse is the decision of the webhook.
+type AdmissionResponse struct {
+	UID     string           `json:"uid"`
+	Allowed bool             `json:"allowed"`
+	Result  *AdmissionStatus `json:"status,omitempty"`
+	// Patch is a JSON patch (RFC 6902), base64-encoded by encoding/json; mutating webhooks only.
+	Patch     []byte   `json:"patch,omitempty"`
+	PatchType *string  `json:"patchType,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// AdmissionStatus explains a rejection.
+type AdmissionStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
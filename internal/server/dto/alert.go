@@ -0,0 +1,153 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Alert rule DTOs
+// =============================================
+
+// AlertRule is a user-defined alert: a condition over cost, efficiency, budget and SLO metrics,
+// evaluated every interval over the trailing window and routed to notification channels.
+type AlertRule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Condition in canonical form, e.g. `cost{namespace="payments"} > 500 and waste_ratio >= 30`.
+	Condition string    `json:"condition"`
+	Window    string    `json:"window"`   // Go duration, e.g. "24h"
+	Interval  string    `json:"interval"` // Go duration, e.g. "1h"
+	Severity  string    `json:"severity"`
+	AlertType string    `json:"alert_type"`
+	Channels  []string  `json:"channels"`
+	Enabled   bool      `json:"enabled"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// State of the latest evaluation: ok, firing or error; empty before the first one.
+	State           string     `json:"state,omitempty"`
+	LastEvaluatedAt *time.Time `json:"last_evaluated_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	FiringSince     *time.Time `json:"firing_since,omitempty"`
+}
+
+// AlertRuleListResponse is the response of GET /api/v1/alerts/rules.
+type AlertRuleListResponse struct {
+	Rules []AlertRule `json:"rules"`
+	Total int         `json:"total"`
+	// Metrics are the metrics conditions may use.
+	Metrics []AlertMetric `json:"metrics"`
+}
+
+// AlertMetric describes a metric of the condition DSL and the labels it can be filtered by.
+type AlertMetric struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+}
+
+// AlertRuleRequest is the body of POST /api/v1/alerts/rules and PUT /api/v1/alerts/rules/:id.
+// Clauses `metric{label="value", ...} op threshold` (op: > >= < <= == !=) are joined by and / or,
+// and binding tighter than or.
+type AlertRuleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Condition   string `json:"condition" binding:"required"`
+	Window      string `json:"window"`     // default "24h"
+	Interval    string `json:"interval"`   // default "1h", at least "1m"
+	Severity    string `json:"severity"`   // info / warning / critical, default warning
+	AlertType   string `json:"alert_type"` // routing type, default alert_rule
+	// Channels restrict delivery to these channels (slack, dingtalk, wecom, pagerduty, opsgenie) of
+	// the alert type's route; empty sends to all of them.
+	Channels  []string `json:"channels"`
+	Enabled   *bool    `json:"enabled"`    // default true
+	CreatedBy string   `json:"created_by"` // default: the service account of the caller's key
+}
+
+// AlertRuleEvaluation is the result of evaluating an alert rule.
+type AlertRuleEvaluation struct {
+	RuleID      string              `json:"rule_id"`
+	EvaluatedAt time.Time           `json:"evaluated_at"`
+	WindowStart time.Time           `json:"window_start"`
+	Firing      bool                `json:"firing"`
+	State       string              `json:"state"`
+	Error       string              `json:"error,omitempty"` // the metrics could not be evaluated
+	Notified    bool                `json:"notified"`        // an alert was sent (the rule started firing)
+	Silenced    bool                `json:"silenced"`        // the alert matched an active silence and was not sent
+	NotifyError string              `json:"notify_error,omitempty"`
+	Clauses     []AlertClauseResult `json:"clauses"`
+}
+
+// AlertClauseResult is the value of one clause of a condition.
+type AlertClauseResult struct {
+	Clause  string  `json:"clause"`
+	Value   float64 `json:"value"`
+	HasData bool    `json:"has_data"` // false: no data in the window, the clause does not hold
+	Matched bool    `json:"matched"`
+}
+
+// =============================================
+// Notification routing and silence DTOs
+// =============================================
+
+// NotificationRoute sends the alerts whose labels match all matchers to its channels only. Alerts are
+// labelled with alert_type, severity and, where known, namespace, team and rule; a matcher value "*"
+// only requires the label. The matching route of lowest priority wins; alerts naming their own
+// channels (alert rules with channels) are not rerouted.
+type NotificationRoute struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Matchers  map[string]string `json:"matchers"`
+	Channels  []string          `json:"channels"`
+	Priority  int               `json:"priority"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// NotificationRouteListResponse is the response of GET /api/v1/alerts/routes, in matching order.
+type NotificationRouteListResponse struct {
+	Routes []NotificationRoute `json:"routes"`
+	Total  int                 `json:"total"`
+}
+
+// NotificationRouteRequest is the body of POST /api/v1/alerts/routes and PUT /api/v1/alerts/routes/:id.
+type NotificationRouteRequest struct {
+	Name      string            `json:"name" binding:"required"`
+	Matchers  map[string]string `json:"matchers"` // empty matches every alert
+	Channels  []string          `json:"channels" binding:"required"`
+	Priority  int               `json:"priority"`
+	CreatedBy string            `json:"created_by"` // default: the service account of the caller's key
+}
+
+// Silence mutes the alerts whose labels match all matchers from StartsAt until EndsAt.
+type Silence struct {
+	ID        string            `json:"id"`
+	Matchers  map[string]string `json:"matchers"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    time.Time         `json:"ends_at"`
+	Reason    string            `json:"reason"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiredBy string            `json:"expired_by,omitempty"` // who ended it early
+	Active    bool              `json:"active"`
+}
+
+// SilenceListResponse is the response of GET /api/v1/alerts/silences.
+type SilenceListResponse struct {
+	Silences []Silence `json:"silences"`
+	Total    int       `json:"total"`
+}
+
+// CreateSilenceRequest is the body of POST /api/v1/alerts/silences. The end is EndsAt or StartsAt
+// plus Duration.
+type CreateSilenceRequest struct {
+	Matchers  map[string]string `json:"matchers" binding:"required"`
+	StartsAt  *time.Time        `json:"starts_at"` // default now
+	EndsAt    *time.Time        `json:"ends_at"`
+	Duration  string            `json:"duration"` // Go duration, e.g. "2h"
+	Reason    string            `json:"reason" binding:"required"`
+	CreatedBy string            `json:"created_by"` // default: the service account of the caller's key
+}
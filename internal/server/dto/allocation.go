@@ -0,0 +1,52 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// =============================================
+// Shared cost allocation preview DTOs
+// =============================================
+
+// AllocationPreviewRequest is the body of POST /api/v1/cost/allocation/preview: a candidate set of
+// shared cost rules replacing the current ones, applied to start_date..end_date (UTC days,
+// inclusive; default the 30 days before today).
+type AllocationPreviewRequest struct {
+	Rules     []costmodel.SharedResource `json:"rules"`
+	StartDate time.Time                  `json:"start_date,omitempty"`
+	EndDate   time.Time                  `json:"end_date,omitempty"`
+}
+
+// AllocationPreviewResponse compares the shared cost allocated to each namespace by the current
+// and the candidate rules. Namespaces are sorted by absolute delta, descending.
+type AllocationPreviewResponse struct {
+	StartDate           time.Time                    `json:"start_date"`
+	EndDate             time.Time                    `json:"end_date"`
+	Days                int                          `json:"days"`
+	CurrentSharedCost   float64                      `json:"current_shared_cost"`
+	CandidateSharedCost float64                      `json:"candidate_shared_cost"`
+	Resources           []AllocationPreviewResource  `json:"resources"`
+	Namespaces          []AllocationPreviewNamespace `json:"namespaces"`
+	Total               int                          `json:"total"`
+}
+
+// AllocationPreviewResource is the allocated cost of one shared resource under both rule sets;
+// a resource missing from one set has zero cost there.
+type AllocationPreviewResource struct {
+	Name          string  `json:"name"`
+	CurrentCost   float64 `json:"current_cost"`
+	CandidateCost float64 `json:"candidate_cost"`
+}
+
+// AllocationPreviewNamespace is the shared cost of a namespace under both rule sets.
+type AllocationPreviewNamespace struct {
+	Namespace           string   `json:"namespace"`
+	BillableCost        float64  `json:"billable_cost"`
+	CurrentSharedCost   float64  `json:"current_shared_cost"`
+	CandidateSharedCost float64  `json:"candidate_shared_cost"`
+	Delta               float64  `json:"delta"`
+	DeltaPercent        *float64 `json:"delta_percent,omitempty"` // relative to current; absent when current is zero
+}
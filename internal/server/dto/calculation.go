@@ -0,0 +1,43 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Calculation run tracking DTOs
+// =============================================
+
+// CalculationRun is one execution of the cost calculation pipeline.
+type CalculationRun struct {
+	ID          string    `json:"id"`
+	Trigger     string    `json:"trigger"` // schedule / manual / retry
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	Status      string    `json:"status"` // running / succeeded / partial / failed
+	RowsWritten int       `json:"rows_written"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	Error       string    `json:"error,omitempty"`
+	RetryOf     string    `json:"retry_of,omitempty"`
+	// Scopes 本次执行范围（namespace），为空表示全量；FailedScopes 失败且可定向重跑的范围。
+	Scopes       []string `json:"scopes,omitempty"`
+	FailedScopes []string `json:"failed_scopes,omitempty"`
+	// ZeroedValues / DroppedRows 计算中 NaN/Inf 被置 0 的值与被丢弃的指标行数（见 non_finite_policy）。
+	ZeroedValues int `json:"zeroed_values,omitempty"`
+	DroppedRows  int `json:"dropped_rows,omitempty"`
+}
+
+// CalculationRunListResponse is the response of GET /api/v1/calculations/runs.
+type CalculationRunListResponse struct {
+	Runs  []CalculationRun `json:"runs"`
+	Total int              `json:"total"`
+}
+
+// TriggerCalculationRequest is the body of POST /api/v1/calculations/runs (manual run).
+type TriggerCalculationRequest struct {
+	WindowStart time.Time `json:"window_start" binding:"required"`
+	WindowEnd   time.Time `json:"window_end" binding:"required"`
+}
@@ -0,0 +1,107 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import "time"
+
+// =============================================
+// Capacity Planning DTOs
+// =============================================
+
+// CapacityProjectionResponse is the response of GET /api/v1/capacity/projection.
+// Requests are projected per namespace from their daily trend; utilization is requested/allocatable in %.
+type CapacityProjectionResponse struct {
+	GeneratedAt       time.Time         `json:"generated_at"`
+	HistoryDays       int               `json:"history_days"`
+	HorizonDays       int               `json:"horizon_days"`
+	TargetUtilization float64           `json:"target_utilization"`
+	Pools             []CapacityPool    `json:"pools"`
+	Namespaces        []NamespaceGrowth `json:"namespaces"`
+	Baseline          CapacityScenario  `json:"baseline"`
+	// Scenario 仅在 node_delta 非 0 时返回：在 Pool 中增加（或减少）NodeDelta 个平均规格节点
+	Scenario *CapacityScenario         `json:"scenario,omitempty"`
+	Series   []CapacityProjectionPoint `json:"series"`
+}
+
+// CapacityPool is a group of nodes sharing the node pool label.
+type CapacityPool struct {
+	Name           string  `json:"name"`
+	NodeCount      int     `json:"node_count"`
+	AllocatableCPU float64 `json:"allocatable_cpu"`
+	AllocatableMem int64   `json:"allocatable_mem"`
+	MonthlyCost    float64 `json:"monthly_cost"`
+}
+
+// NamespaceGrowth is the current requests of a namespace and their fitted daily growth.
+type NamespaceGrowth struct {
+	Namespace       string  `json:"namespace"`
+	CPURequest      float64 `json:"cpu_request"`
+	MemRequest      int64   `json:"mem_request"`
+	CPUGrowthPerDay float64 `json:"cpu_growth_per_day"`
+	MemGrowthPerDay float64 `json:"mem_growth_per_day"`
+}
+
+// CapacityScenario is the cluster capacity, cost and exhaustion dates for a node count.
+// Exhaustion dates are nil when requests stay below allocatable*target within the horizon.
+type CapacityScenario struct {
+	Pool              string     `json:"pool,omitempty"`
+	NodeDelta         int        `json:"node_delta"`
+	NodeCount         int        `json:"node_count"`
+	AllocatableCPU    float64    `json:"allocatable_cpu"`
+	AllocatableMem    int64      `json:"allocatable_mem"`
+	MonthlyCost       float64    `json:"monthly_cost"`
+	MonthlyCostDelta  float64    `json:"monthly_cost_delta"`
+	CPUUtilization    float64    `json:"cpu_utilization"`
+	MemUtilization    float64    `json:"mem_utilization"`
+	CPUExhaustionDate *time.Time `json:"cpu_exhaustion_date"`
+	MemExhaustionDate *time.Time `json:"mem_exhaustion_date"`
+}
+
+// CapacityProjectionPoint is the projected cluster requests of one day (baseline capacity).
+type CapacityProjectionPoint struct {
+	Date           time.Time `json:"date"`
+	CPURequest     float64   `json:"cpu_request"`
+	MemRequest     int64     `json:"mem_request"`
+	CPUUtilization float64   `json:"cpu_utilization"`
+	MemUtilization float64   `json:"mem_utilization"`
+}
+
+// CapacitySimulationResponse is the response of GET /api/v1/capacity/simulation.
+// Running and pending pods are bin-packed onto each pool's average node (allocatable*target per
+// node) with current and changed requests; savings are the billable cost of the nodes removed.
+// Pools without pods keep their nodes.
+type CapacitySimulationResponse struct {
+	GeneratedAt          time.Time `json:"generated_at"`
+	RequestChangePercent float64   `json:"request_change_percent"`
+	TargetUtilization    float64   `json:"target_utilization"`
+	NodeCount            int       `json:"node_count"`
+	// BaselineNodeCount 为按当前请求装箱所需节点数，与 NodeCount 的差异是已可整合的部分；
+	// NodeReduction = BaselineNodeCount - SimulatedNodeCount，只计入请求变化带来的节点减少
+	BaselineNodeCount   int     `json:"baseline_node_count"`
+	SimulatedNodeCount  int     `json:"simulated_node_count"`
+	NodeReduction       int     `json:"node_reduction"`
+	CPURequest          float64 `json:"cpu_request"`
+	SimulatedCPURequest float64 `json:"simulated_cpu_request"`
+	MemRequest          int64   `json:"mem_request"`
+	SimulatedMemRequest int64   `json:"simulated_mem_request"`
+	// RequestCostDelta 为请求变化按单价折算的月成本（负数为减少），仅供与节点节省对比
+	RequestCostDelta     float64                  `json:"request_cost_delta"`
+	MonthlyCost          float64                  `json:"monthly_cost"`
+	SimulatedMonthlyCost float64                  `json:"simulated_monthly_cost"`
+	MonthlySavings       float64                  `json:"monthly_savings"`
+	Pools                []CapacitySimulationPool `json:"pools"`
+}
+
+// CapacitySimulationPool is the autoscaler simulation of one node pool.
+type CapacitySimulationPool struct {
+	Name               string  `json:"name"`
+	Pods               int     `json:"pods"`
+	OversizedPods      int     `json:"oversized_pods"`
+	NodeCPU            float64 `json:"node_cpu"`
+	NodeMem            int64   `json:"node_mem"`
+	NodeMonthlyCost    float64 `json:"node_monthly_cost"`
+	NodeCount          int     `json:"node_count"`
+	BaselineNodeCount  int     `json:"baseline_node_count"`
+	SimulatedNodeCount int     `json:"simulated_node_count"`
+	NodeReduction      int     `json:"node_reduction"`
+	MonthlySavings     float64 `json:"monthly_savings"`
+}
@@ -0,0 +1,43 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// =============================================
+// Workload catalog DTOs
+// =============================================
+
+// CatalogWorkload is the latest known state of one workload.
+type CatalogWorkload struct {
+	Namespace       string  `json:"namespace"`
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	Team            string  `json:"team,omitempty"` // owner team; absent when no team claims the namespace
+	Grade           string  `json:"grade"`
+	EfficiencyScore float64 `json:"efficiency_score"`
+	MonthlyCost     float64 `json:"monthly_cost"` // billable cost of the observed hours extrapolated to a month
+	// LastRecommendation is the recommendation with the largest estimated saving, if any
+	LastRecommendation *costmodel.Recommendation `json:"last_recommendation,omitempty"`
+	SLOStatus          string                    `json:"slo_status,omitempty"` // absent when the workload has no SLO
+	LastSeen           time.Time                 `json:"last_seen"`
+}
+
+// CatalogFacetCount is the number of matching workloads with one facet value.
+type CatalogFacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// CatalogSearchResponse is the response of GET /api/v1/workloads/catalog. Workloads are sorted by
+// monthly cost, descending; Total counts all matches before paging and Facets are computed over
+// them (facets: namespace, team, grade, type, slo_status).
+type CatalogSearchResponse struct {
+	Workloads   []CatalogWorkload              `json:"workloads"`
+	Facets      map[string][]CatalogFacetCount `json:"facets"`
+	Total       int                            `json:"total"`
+	RefreshedAt time.Time                      `json:"refreshed_at"`
+}
@@ -0,0 +1,52 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import "time"
+
+// ChaosResponse is the response of the /api/v1/admin/chaos endpoints: the configured scenarios and
+// the current (or last) resilience drill.
+type ChaosResponse struct {
+	Active             bool            `json:"active"`
+	MaxDurationSeconds int64           `json:"max_duration_seconds"`
+	Scenarios          []ChaosScenario `json:"scenarios"`
+	Drill              *ChaosDrill     `json:"drill,omitempty"`
+}
+
+// ChaosScenario is a configured scenario: the fault injected into each data source
+// (prometheus / kubernetes / database).
+type ChaosScenario struct {
+	Name   string                `json:"name"`
+	Faults map[string]ChaosFault `json:"faults"`
+}
+
+// ChaosFault is the failure injected into the calls of one data source, with the knobs of the mock
+// configs.
+type ChaosFault struct {
+	ErrorRate       float64 `json:"error_rate"`
+	LatencyMs       int     `json:"latency_ms"`
+	LatencyJitterMs int     `json:"latency_jitter_ms"`
+	TailLatencyRate float64 `json:"tail_latency_rate"`
+	TailLatencyMs   int     `json:"tail_latency_ms"`
+}
+
+// ChaosDrill is a started drill and what it injected so far, by data source.
+type ChaosDrill struct {
+	Scenario  string                      `json:"scenario"`
+	StartedBy string                      `json:"started_by,omitempty"`
+	StartedAt time.Time                   `json:"started_at"`
+	EndsAt    time.Time                   `json:"ends_at"`
+	StoppedAt *time.Time                  `json:"stopped_at,omitempty"`
+	Injected  map[string]ChaosInjectCount `json:"injected"`
+}
+
+// ChaosInjectCount is the calls of a data source a drill delayed or failed, and the failures among them.
+type ChaosInjectCount struct {
+	Calls  int64 `json:"calls"`
+	Errors int64 `json:"errors"`
+}
+
+// StartChaosDrillRequest is the body of POST /api/v1/admin/chaos.
+type StartChaosDrillRequest struct {
+	Scenario string `json:"scenario" binding:"required"`
+	Duration string `json:"duration" binding:"required"` // Go duration, e.g. "15m"; at most the configured max_duration
+}
@@ -17,6 +17,9 @@ type DomainBreakdownItem struct {
 	Cost            float64 `json:"cost"`
 	OptimizableSpace float64 `json:"optimizable_space"`
 	Efficiency      float64 `json:"efficiency"`
+	// 同 NamespaceCostSummary.Terminated
+	Terminated bool       `json:"terminated,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
 
 // GlobalCostResponse represents the response for global cost overview.
@@ -26,16 +29,33 @@ type GlobalCostResponse struct {
 	GlobalEfficiency float64                 `json:"global_efficiency"`
 	DomainBreakdown  []DomainBreakdownItem   `json:"domain_breakdown"`
 	Namespaces       []NamespaceCostSummary  `json:"namespaces"`
+	QuarantinedRows  int                     `json:"quarantined_rows,omitempty"` // input rows left out by validation
 	Timestamp        time.Time               `json:"timestamp"`
 }
 
 // NamespaceCostSummary represents a summary of cost for a namespace.
 type NamespaceCostSummary struct {
-	Name      string  `json:"name"`
-	Cost      float64 `json:"cost"`
-	Grade     string  `json:"grade"`
-	PodCount  int     `json:"pod_count"`
-	NodeCount int     `json:"node_count"`
+	Name       string  `json:"name"`
+	Cost       float64 `json:"cost"`
+	WasteCost  float64 `json:"waste_cost"`
+	Efficiency float64 `json:"efficiency"` // usage / billable, %
+	Grade      string  `json:"grade"`
+	PodCount   int     `json:"pod_count"`
+	NodeCount  int     `json:"node_count"`
+	// Terminated 该 namespace 已从集群删除（namespace_lifecycle.deleted_at），成本仅来自历史数据
+	Terminated bool       `json:"terminated,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	// Metadata 来自 K8s 标签/注解（app、owner、tier、environment），未接入 K8s 时省略
+	Metadata *ResourceMetadata `json:"metadata,omitempty"`
+}
+
+// ResourceMetadata is the K8s metadata of a namespace or workload joined onto cost results at query
+// time; workloads inherit the fields they do not set from their namespace.
+type ResourceMetadata struct {
+	App         string `json:"app,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Tier        string `json:"tier,omitempty"`
+	Environment string `json:"environment,omitempty"`
 }
 
 // =============================================
@@ -51,11 +71,15 @@ type NamespaceCostRequest struct {
 
 // NamespaceCostResponse represents the response for namespace cost details.
 type NamespaceCostResponse struct {
-	Namespace string            `json:"namespace"`
-	Cost      CostBreakdown     `json:"cost"`
-	Workloads []WorkloadCost    `json:"workloads"`
-	Nodes     []NodeCostSummary `json:"nodes"`
-	Timestamp time.Time         `json:"timestamp"`
+	Namespace     string            `json:"namespace"`
+	Cost          CostBreakdown     `json:"cost"`
+	Workloads     []WorkloadCost    `json:"workloads"`
+	WorkloadTotal int               `json:"workload_total"` // 分页前的工作负载数
+	Nodes         []NodeCostSummary `json:"nodes"`
+	Timestamp     time.Time         `json:"timestamp"`
+	// 已删除 namespace 的历史成本仍可查询，Terminated 标注其状态
+	Terminated bool       `json:"terminated,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
 
 // CostBreakdown provides detailed cost breakdown.
@@ -73,12 +97,16 @@ type CostBreakdown struct {
 
 // WorkloadCost represents cost for a specific workload.
 type WorkloadCost struct {
-	Name      string  `json:"name"`
-	Type      string  `json:"type"` // Deployment, StatefulSet, etc.
-	Cost      float64 `json:"cost"`
-	PodCount  int     `json:"pod_count"`
-	Grade     string  `json:"grade"`
-	Namespace string  `json:"namespace"`
+	Name       string  `json:"name"`
+	Type       string  `json:"type"` // Deployment, StatefulSet, etc.
+	Cost       float64 `json:"cost"` // billable cost of the window
+	WasteCost  float64 `json:"waste_cost"`
+	Efficiency float64 `json:"efficiency"` // usage / billable, %
+	PodCount   int     `json:"pod_count"`
+	Grade      string  `json:"grade"`
+	Namespace  string  `json:"namespace"`
+	// Metadata 同 NamespaceCostSummary.Metadata，未设置的字段继承 namespace
+	Metadata *ResourceMetadata `json:"metadata,omitempty"`
 }
 
 // NodeCostSummary represents cost summary for a node.
@@ -131,6 +159,23 @@ type GranularCostDataPoint struct {
 	Waste     float64   `json:"waste"`
 }
 
+// CostSeriesResponse is the response of GET /api/v1/cost/series: billable (Cost), usage and waste
+// cost over time, oldest first, at the resolution the query planner chose for the range (or the
+// requested one), so clients know the granularity they received.
+type CostSeriesResponse struct {
+	Namespace string    `json:"namespace,omitempty"` // 为空表示全部 namespace
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	// Resolution 数据粒度（hourly / daily / monthly）；Planned 为 true 表示由查询计划按时间范围选择
+	Resolution  string `json:"resolution"`
+	Planned     bool   `json:"planned"`
+	StepSeconds int64  `json:"step_seconds"` // 名义步长，月按 30 天
+	// Source 读取的表；TimeZone 天/月边界所用的核算时区
+	Source   string                  `json:"source"`
+	TimeZone string                  `json:"time_zone"`
+	Points   []GranularCostDataPoint `json:"points"`
+}
+
 // =============================================
 // Error Response DTO
 // =============================================
@@ -0,0 +1,29 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import "time"
+
+// CostImportResponse is the response of POST /api/v1/admin/import/allocations: what an import of
+// Kubecost / OpenCost allocation data wrote (or, with dry_run, would write).
+type CostImportResponse struct {
+	Source    string    `json:"source"` // kubecost / opencost, stored as the source of each row
+	Format    string    `json:"format"` // json / csv
+	DryRun    bool      `json:"dry_run"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// Allocations parsed; Unallocated of them are idle / unallocated aggregates, which are skipped.
+	Allocations int      `json:"allocations"`
+	Unallocated int      `json:"unallocated"`
+	Namespaces  []string `json:"namespaces"`
+	// Rows written; rows of earlier imports are replaced.
+	DailyRows  int `json:"daily_rows"`
+	HourlyRows int `json:"hourly_rows"`
+	// KeptRows are rows Lighthouse calculated itself, which the import does not overwrite.
+	KeptRows int `json:"kept_rows"`
+	// ImportedCost is the CPU and memory cost imported and SharedCost the shared cost allocated to
+	// the namespaces; OtherCost is the GPU, volume, network and load balancer cost of the export,
+	// which is outside the Lighthouse cost model and not imported.
+	ImportedCost float64 `json:"imported_cost"`
+	SharedCost   float64 `json:"shared_cost"`
+	OtherCost    float64 `json:"other_cost"`
+}
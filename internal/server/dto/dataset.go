@@ -0,0 +1,63 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Cost dataset export DTOs
+// =============================================
+
+// DatasetVersion is the dataset format written by GET /api/v1/admin/dataset/export.
+const DatasetVersion = 1
+
+// Dataset is the stored cost data of a window, for vendors and demos. When Anonymized, namespace,
+// workload, pod, node and cost center names are consistent pseudonyms (the same name has the same
+// pseudonym everywhere in the dataset). Every cost is multiplied by Scale (1 unless requested);
+// resource quantities, timestamps and the ratios between costs are kept, so the cost shapes are
+// those of the source.
+type Dataset struct {
+	Version             int                   `json:"version"`
+	ExportedAt          time.Time             `json:"exported_at"`
+	StartTime           time.Time             `json:"start_time"`
+	EndTime             time.Time             `json:"end_time"`
+	Anonymized          bool                  `json:"anonymized"`
+	Scale               float64               `json:"scale"`
+	DailyNamespaceCosts []DatasetNamespaceDay `json:"daily_namespace_costs"`
+	HourlyWorkloadStats []DatasetWorkloadHour `json:"hourly_workload_stats"`
+}
+
+// DatasetNamespaceDay is the daily cost of one namespace.
+type DatasetNamespaceDay struct {
+	Namespace       string    `json:"namespace"`
+	Date            time.Time `json:"date"`
+	BillableCost    float64   `json:"billable_cost"`
+	UsageCost       float64   `json:"usage_cost"`
+	WasteCost       float64   `json:"waste_cost"`
+	SharedCost      float64   `json:"shared_cost"`
+	PodCount        int       `json:"pod_count"`
+	NodeCount       int       `json:"node_count"`
+	WorkloadCount   int       `json:"workload_count"`
+	EfficiencyScore float64   `json:"efficiency_score"`
+}
+
+// DatasetWorkloadHour is the hourly stat of one workload (pod).
+type DatasetWorkloadHour struct {
+	Namespace         string    `json:"namespace"`
+	WorkloadName      string    `json:"workload_name"`
+	WorkloadType      string    `json:"workload_type"`
+	NodeName          string    `json:"node_name,omitempty"`
+	PodName           string    `json:"pod_name,omitempty"`
+	CostCenter        string    `json:"cost_center,omitempty"`
+	Timestamp         time.Time `json:"timestamp"`
+	CPURequest        float64   `json:"cpu_request"`
+	CPUUsageP95       float64   `json:"cpu_usage_p95"`
+	MemRequest        int64     `json:"mem_request"`
+	MemUsageP95       int64     `json:"mem_usage_p95"`
+	CPUBillableCost   float64   `json:"cpu_billable_cost"`
+	MemBillableCost   float64   `json:"mem_billable_cost"`
+	TotalBillableCost float64   `json:"total_billable_cost"`
+	TotalUsageCost    float64   `json:"total_usage_cost"`
+	TotalWasteCost    float64   `json:"total_waste_cost"`
+}
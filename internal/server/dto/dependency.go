@@ -0,0 +1,77 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import "time"
+
+// DependencyIngestRequest is the body of POST /api/v1/dependencies/edges. The tracing pipeline
+// reports the calls it observed (source tracing); a static dependency map is sent whole (source
+// static) and replaces the previous one.
+type DependencyIngestRequest struct {
+	Source     string                `json:"source" binding:"required"` // tracing / static
+	ObservedAt time.Time             `json:"observed_at"`               // default now
+	Edges      []DependencyEdgeInput `json:"edges"`
+}
+
+// DependencyEdgeInput is one reported dependency: caller calls callee. Namespaces default to each
+// other, so edges within a namespace need only one of them.
+type DependencyEdgeInput struct {
+	CallerNamespace string  `json:"caller_namespace"`
+	Caller          string  `json:"caller"`
+	CalleeNamespace string  `json:"callee_namespace"`
+	Callee          string  `json:"callee"`
+	CallsPerMinute  float64 `json:"calls_per_minute"`
+}
+
+// DependencyIngestResponse is the result of an ingestion: the edges saved and the edges of the
+// source removed (the previous static map, or tracing edges not seen within the TTL).
+type DependencyIngestResponse struct {
+	Source   string `json:"source"`
+	Accepted int    `json:"accepted"`
+	Removed  int    `json:"removed"`
+}
+
+// DependencyEdge is an edge of the service dependency graph.
+type DependencyEdge struct {
+	CallerNamespace string    `json:"caller_namespace"`
+	Caller          string    `json:"caller"`
+	CalleeNamespace string    `json:"callee_namespace"`
+	Callee          string    `json:"callee"`
+	Source          string    `json:"source"`
+	CallsPerMinute  float64   `json:"calls_per_minute"`
+	LastSeen        time.Time `json:"last_seen"`
+}
+
+// DependencyEdgeList is the response of GET /api/v1/dependencies: the live edges of the graph.
+type DependencyEdgeList struct {
+	Items []DependencyEdge `json:"items"`
+	ListPage
+}
+
+// DependencyImpact is the blast radius of a degraded workload: the services that call it directly
+// or transitively, and what they cost. Monthly costs are extrapolated from the billable cost of the
+// workloads named like the services over the window.
+type DependencyImpact struct {
+	Namespace   string    `json:"namespace"`
+	Service     string    `json:"service"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	MonthlyCost float64   `json:"monthly_cost"`
+	// UpstreamMonthlyCost sums the monthly cost of Upstream: the spend depending on the workload.
+	UpstreamMonthlyCost float64           `json:"upstream_monthly_cost"`
+	Upstream            []UpstreamService `json:"upstream"`
+	// Downstream lists the services the workload calls directly ("namespace/service").
+	Downstream []string `json:"downstream"`
+	// Unmatched lists upstream services without workload stats in the window (namespace/service).
+	Unmatched []string `json:"unmatched"`
+}
+
+// UpstreamService is a service depending on the degraded workload.
+type UpstreamService struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	// Depth is the number of calls between the service and the workload; 1 calls it directly.
+	Depth int `json:"depth"`
+	// Via is the service it calls on the shortest path to the workload ("namespace/service").
+	Via         string  `json:"via"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
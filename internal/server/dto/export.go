@@ -0,0 +1,52 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Async export job DTOs
+// =============================================
+
+// CreateExportJobRequest is the body of POST /api/v1/exports. The window, anonymize and scale
+// follow GET /api/v1/admin/dataset/export.
+type CreateExportJobRequest struct {
+	Kind      string    `json:"kind" binding:"required"`   // namespace_costs / workload_stats
+	Format    string    `json:"format" binding:"required"` // csv / pdf, or a format registered by the deployment (e.g. parquet)
+	StartTime time.Time `json:"start_time,omitempty"`      // default end_time - 7 days
+	EndTime   time.Time `json:"end_time,omitempty"`        // default the current hour
+	Anonymize bool      `json:"anonymize,omitempty"`
+	Scale     float64   `json:"scale,omitempty"`
+}
+
+// ExportJob is an async export. The file of a succeeded job is downloadable from DownloadURL until
+// ExpiresAt, after which the job is expired and the file deleted.
+type ExportJob struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Format      string     `json:"format"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Anonymized  bool       `json:"anonymized"`
+	Status      string     `json:"status"`   // queued / running / succeeded / failed / expired
+	Progress    int        `json:"progress"` // percent
+	TotalRows   int        `json:"total_rows"`
+	RowsWritten int        `json:"rows_written"`
+	FileName    string     `json:"file_name,omitempty"`
+	SizeBytes   int64      `json:"size_bytes,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// ExportJobListResponse is the response of GET /api/v1/exports.
+type ExportJobListResponse struct {
+	Jobs    []ExportJob `json:"jobs"`
+	Total   int         `json:"total"`
+	Formats []string    `json:"formats"` // formats accepted by POST /api/v1/exports
+}
@@ -0,0 +1,50 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Fiscal period report DTOs
+// =============================================
+
+// FiscalReportResponse is the response of GET /api/v1/cost/fiscal-report: the cost of a fiscal
+// period, quarter or year to date (through AsOf) and of the whole previous one. Dates are day labels
+// of the accounting time zone; EndDate is exclusive.
+type FiscalReportResponse struct {
+	Unit        string    `json:"unit"`  // period / quarter / year
+	Label       string    `json:"label"` // e.g. FY2026 Q2
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	AsOf        time.Time `json:"as_of"`
+	Days        int       `json:"days"`
+	DaysElapsed int       `json:"days_elapsed"`
+
+	BillableCost float64 `json:"billable_cost"`
+	UsageCost    float64 `json:"usage_cost"`
+	WasteCost    float64 `json:"waste_cost"`
+	SharedCost   float64 `json:"shared_cost"`
+	// Projected extrapolates the average daily billable cost to date to the whole span
+	Projected float64 `json:"projected"`
+
+	PreviousLabel        string  `json:"previous_label"`
+	PreviousBillableCost float64 `json:"previous_billable_cost"`
+
+	// Adjustments sums the corrections of closed periods dated within the span to date; the costs
+	// above are the values stored at close. AdjustedTotal is BillableCost + SharedCost + Adjustments.
+	Adjustments     float64          `json:"adjustments"`
+	AdjustedTotal   float64          `json:"adjusted_total"`
+	AdjustmentItems []CostAdjustment `json:"adjustment_items"` // by date
+
+	Namespaces []FiscalReportNamespace `json:"namespaces"` // by billable cost, descending
+}
+
+// FiscalReportNamespace is the cost of one namespace in a fiscal report.
+type FiscalReportNamespace struct {
+	Namespace            string  `json:"namespace"`
+	BillableCost         float64 `json:"billable_cost"`
+	SharedCost           float64 `json:"shared_cost"`
+	Adjustments          float64 `json:"adjustments"`
+	PreviousBillableCost float64 `json:"previous_billable_cost"`
+}
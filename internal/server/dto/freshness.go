@@ -0,0 +1,28 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Data freshness DTOs
+// =============================================
+
+// Data freshness sources.
+const (
+	FreshnessSourceCalculationRun = "calculation_run"
+	FreshnessSourceCostSnapshot   = "cost_snapshot"
+)
+
+// DataFreshness is the "data" section of GET /readyz: whether the repository has had a successful
+// data sync yet and how recent the served data is.
+type DataFreshness struct {
+	// Synced 至少有一次成功（或部分成功）的计算执行，或已有确认的成本快照（如 mock 场景预置数据）
+	Synced bool `json:"synced"`
+	// AsOf 最新数据的截止时间：计算执行的窗口结束或快照时间，取较晚者；未同步时为空
+	AsOf             *time.Time `json:"as_of,omitempty"`
+	Source           string     `json:"source,omitempty"` // calculation_run / cost_snapshot
+	CalculationRunID string     `json:"calculation_run_id,omitempty"`
+	CheckedAt        time.Time  `json:"checked_at"`
+}
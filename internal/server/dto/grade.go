@@ -0,0 +1,108 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// =============================================
+// Efficiency grade history DTOs
+// =============================================
+
+// GradeChangeEvent is one efficiency grade transition of a workload. FromGrade is empty for the
+// first grade assigned to the workload.
+type GradeChangeEvent struct {
+	Namespace       string    `json:"namespace"`
+	WorkloadName    string    `json:"workload_name"`
+	WorkloadType    string    `json:"workload_type"`
+	FromGrade       string    `json:"from_grade,omitempty"`
+	ToGrade         string    `json:"to_grade"`
+	EfficiencyScore float64   `json:"efficiency_score"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// GradeHistoryResponse is the response of GET /api/v1/cost/grades/history.
+type GradeHistoryResponse struct {
+	Events []GradeChangeEvent `json:"events"`
+	Total  int                `json:"total"`
+}
+
+// =============================================
+// Snapshot regrade DTOs
+// =============================================
+
+// GradeCounts is the number of resources per efficiency grade.
+type GradeCounts struct {
+	Zombie          int `json:"zombie"`
+	OverProvisioned int `json:"over_provisioned"`
+	Healthy         int `json:"healthy"`
+	Risk            int `json:"risk"`
+}
+
+// RegradeRequest is the body of POST /api/v1/admin/regrade. Snapshots are selected by timestamp;
+// an empty window selects all of them.
+type RegradeRequest struct {
+	StartTime  time.Time                  `json:"start_time,omitempty"`
+	EndTime    time.Time                  `json:"end_time,omitempty"`
+	Thresholds *costmodel.GradeThresholds `json:"thresholds,omitempty"` // default the configured efficiency thresholds
+	DryRun     bool                       `json:"dry_run,omitempty"`    // compute without saving
+	RegradedBy string                     `json:"regraded_by,omitempty"`
+}
+
+// SnapshotRegradeResult compares the stored grade counts of a snapshot with its regraded counts.
+type SnapshotRegradeResult struct {
+	SnapshotID string      `json:"snapshot_id"`
+	Timestamp  time.Time   `json:"timestamp"`
+	Resources  int         `json:"resources"`
+	Original   GradeCounts `json:"original"`
+	Regraded   GradeCounts `json:"regraded"`
+	Changed    bool        `json:"changed"`
+}
+
+// RegradeResponse is the response of POST /api/v1/admin/regrade. Snapshots without resource
+// results cannot be regraded and are counted in Skipped.
+type RegradeResponse struct {
+	JobID      string                    `json:"job_id"`
+	DryRun     bool                      `json:"dry_run"`
+	Thresholds costmodel.GradeThresholds `json:"thresholds"`
+	Regraded   int                       `json:"regraded"`
+	Changed    int                       `json:"changed"`
+	Skipped    int                       `json:"skipped"`
+	Original   GradeCounts               `json:"original"`
+	Total      GradeCounts               `json:"total"` // regraded counts of all regraded snapshots
+	Snapshots  []SnapshotRegradeResult   `json:"snapshots"`
+	StartedAt  time.Time                 `json:"started_at"`
+	FinishedAt time.Time                 `json:"finished_at"`
+}
+
+// GradeView is the view of snapshot grade counts served by GET /api/v1/snapshots/grades.
+type GradeView struct {
+	View      string    `json:"view"` // original / regraded
+	SetBy     string    `json:"set_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"` // zero while the configured default applies
+}
+
+// SetGradeViewRequest is the body of PUT /api/v1/admin/regrade/view.
+type SetGradeViewRequest struct {
+	View  string `json:"view" binding:"required"` // original / regraded
+	SetBy string `json:"set_by,omitempty"`
+}
+
+// SnapshotGrades is the grade counts of a snapshot in a view. In the regraded view, snapshots not
+// regraded yet keep their original counts (Regraded false).
+type SnapshotGrades struct {
+	SnapshotID string                     `json:"snapshot_id"`
+	Timestamp  time.Time                  `json:"timestamp"`
+	Regraded   bool                       `json:"regraded"`
+	Thresholds *costmodel.GradeThresholds `json:"thresholds,omitempty"` // thresholds of the regrade
+	Counts     GradeCounts                `json:"counts"`
+}
+
+// SnapshotGradesResponse is the response of GET /api/v1/snapshots/grades.
+type SnapshotGradesResponse struct {
+	View      string           `json:"view"`
+	Snapshots []SnapshotGrades `json:"snapshots"`
+	Total     GradeCounts      `json:"total"`
+}
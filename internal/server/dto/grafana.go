@@ -0,0 +1,80 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Grafana JSON datasource DTOs
+// (simple-JSON / Infinity compatible: /search, /query, /annotations)
+// =============================================
+
+// GrafanaRange is the dashboard time range sent by Grafana.
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// GrafanaSearchRequest is the body of POST /search.
+type GrafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+// GrafanaQueryTarget is one query of a panel.
+type GrafanaQueryTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+	Type   string `json:"type"` // "timeserie" (default) or "table"
+}
+
+// GrafanaQueryRequest is the body of POST /query.
+type GrafanaQueryRequest struct {
+	Range         GrafanaRange         `json:"range"`
+	IntervalMs    int64                `json:"intervalMs"`
+	MaxDataPoints int                  `json:"maxDataPoints"`
+	Targets       []GrafanaQueryTarget `json:"targets"`
+}
+
+// GrafanaTimeSeries is a time series response; each datapoint is [value, unix_ms].
+type GrafanaTimeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// GrafanaTableColumn describes a table column.
+type GrafanaTableColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"` // "string", "number", "time"
+}
+
+// GrafanaTable is a table response.
+type GrafanaTable struct {
+	Type    string               `json:"type"` // always "table"
+	Columns []GrafanaTableColumn `json:"columns"`
+	Rows    [][]interface{}      `json:"rows"`
+}
+
+// GrafanaAnnotationQuery is the annotation definition configured in Grafana.
+type GrafanaAnnotationQuery struct {
+	Name       string `json:"name"`
+	Datasource string `json:"datasource"`
+	Enable     bool   `json:"enable"`
+	Query      string `json:"query"`
+}
+
+// GrafanaAnnotationRequest is the body of POST /annotations.
+type GrafanaAnnotationRequest struct {
+	Range      GrafanaRange           `json:"range"`
+	Annotation GrafanaAnnotationQuery `json:"annotation"`
+}
+
+// GrafanaAnnotation is one annotation event; Time is unix ms.
+type GrafanaAnnotation struct {
+	Annotation GrafanaAnnotationQuery `json:"annotation"`
+	Time       int64                  `json:"time"`
+	TimeEnd    int64                  `json:"timeEnd,omitempty"`
+	Title      string                 `json:"title"`
+	Text       string                 `json:"text"`
+	Tags       []string               `json:"tags"`
+}
@@ -0,0 +1,75 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// =============================================
+// Snapshot integrity DTOs
+// =============================================
+
+// SnapshotIntegrityIssue is one problem found while verifying snapshots against the hash chain.
+type SnapshotIntegrityIssue struct {
+	SnapshotID string `json:"snapshot_id,omitempty"`
+	Seq        int64  `json:"seq,omitempty"`
+	Problem    string `json:"problem"` // chain_broken / content_mismatch / not_in_chain / missing
+	Detail     string `json:"detail,omitempty"`
+}
+
+// SnapshotVerificationResponse is the response of GET /api/v1/snapshots/verify.
+type SnapshotVerificationResponse struct {
+	SnapshotID  string                   `json:"snapshot_id,omitempty"` // 为空表示校验全部快照
+	Verified    bool                     `json:"verified"`
+	Snapshots   int                      `json:"snapshots"`
+	ChainLength int                      `json:"chain_length"`
+	HeadHash    string                   `json:"head_hash"`
+	Issues      []SnapshotIntegrityIssue `json:"issues"`
+	VerifiedAt  time.Time                `json:"verified_at"`
+}
+
+// ExportedSnapshot is one snapshot of an export; Snapshot is the stored snapshot JSON whose
+// content hash (postgres.SnapshotContentHash) must equal ContentHash.
+type ExportedSnapshot struct {
+	ID          string          `json:"id"`
+	ContentHash string          `json:"content_hash"`
+	Snapshot    json.RawMessage `json:"snapshot"`
+}
+
+// SnapshotExportResponse is the response of GET /api/v1/snapshots/export. It is only produced
+// when the exported snapshots verify; otherwise the endpoint returns 409 with the verification.
+type SnapshotExportResponse struct {
+	WindowStart  time.Time                    `json:"window_start"`
+	WindowEnd    time.Time                    `json:"window_end"`
+	Snapshots    []ExportedSnapshot           `json:"snapshots"`
+	Verification SnapshotVerificationResponse `json:"verification"`
+	ExportedAt   time.Time                    `json:"exported_at"`
+}
+
+// =============================================
+// Snapshot guardrail DTOs
+// =============================================
+
+// SuspectSnapshot is a snapshot flagged by the rate-of-change guardrail.
+type SuspectSnapshot struct {
+	ID                   string     `json:"id"`
+	CalculationID        string     `json:"calculation_id,omitempty"`
+	Timestamp            time.Time  `json:"timestamp"`
+	TotalBillableCost    float64    `json:"total_billable_cost"`
+	Reason               string     `json:"reason"`
+	RequiresConfirmation bool       `json:"requires_confirmation"`
+	ConfirmedBy          string     `json:"confirmed_by,omitempty"`
+	ConfirmedAt          *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// SuspectSnapshotListResponse is the response of GET /api/v1/snapshots/suspect.
+type SuspectSnapshotListResponse struct {
+	Snapshots []SuspectSnapshot `json:"snapshots"`
+	Total     int               `json:"total"`
+}
+
+// ConfirmSnapshotRequest is the body of POST /api/v1/snapshots/{id}/confirm.
+type ConfirmSnapshotRequest struct {
+	ConfirmedBy string `json:"confirmed_by" binding:"required"`
+}
@@ -0,0 +1,43 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Cost query lineage DTOs
+// =============================================
+
+// QueryLineage is the response of GET /api/v1/lineage/:id: the data and code that produced a cost
+// query response, captured when it was served (its X-Lighthouse-Lineage-Id header).
+type QueryLineage struct {
+	ID        string    `json:"id"`
+	RequestID string    `json:"request_id,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"` // including the query string
+	Status    int       `json:"status"`
+	ServedAt  time.Time `json:"served_at"`
+	// Snapshot 响应时看板所用的最新（已确认）成本快照
+	Snapshot *LineageSnapshot `json:"snapshot,omitempty"`
+	// CalculationRun 响应时最近完成的计算执行
+	CalculationRun *CalculationRun `json:"calculation_run,omitempty"`
+	// PriceVersion 该执行窗口适用的单价版本；为空表示使用配置中的全局单价
+	PriceVersion *PriceVersion `json:"price_version,omitempty"`
+	Code         CodeVersion   `json:"code"`
+}
+
+// LineageSnapshot is the cost snapshot a lineage record refers to.
+type LineageSnapshot struct {
+	ID            string    `json:"id"`
+	CalculationID string    `json:"calculation_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	ContentHash   string    `json:"content_hash,omitempty"`
+}
+
+// CodeVersion is the build of the server that aggregated the figures.
+type CodeVersion struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time,omitempty"`
+}
@@ -0,0 +1,33 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+// ListPage is embedded in the list responses next to their items: where the next page starts and
+// how many results there are across all pages.
+type ListPage struct {
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists,
+	// an estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// NamespaceCostSummaryList is the response of GET /api/v1/cost/namespaces.
+type NamespaceCostSummaryList struct {
+	Items []NamespaceCostSummary `json:"items"`
+	ListPage
+}
+
+// SLOHealthList is the response of GET /api/v1/slo/health.
+type SLOHealthList struct {
+	Items []SLOHealth `json:"items"`
+	ListPage
+}
+
+// SLOHealth is the health of one service as the dashboard shows it.
+type SLOHealth struct {
+	ServiceName  string  `json:"serviceName"`
+	Status       string  `json:"status"` // healthy / warning / critical
+	Uptime       float64 `json:"uptime"` // %
+	ResponseTime float64 `json:"responseTime"`
+	ErrorRate    float64 `json:"errorRate"`
+}
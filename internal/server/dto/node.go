@@ -0,0 +1,124 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import "time"
+
+// =============================================
+// Node (L2) Analysis DTOs
+// =============================================
+
+// NodeAnalysisResponse is the response of GET /api/v1/nodes/analysis.
+// Costs are hourly; EstimatedMonthlySavings sums the consolidation candidates.
+type NodeAnalysisResponse struct {
+	// Timestamp 计算所用的小时（最新一小时的工作负载统计）；无统计数据时为零值
+	Timestamp               time.Time                    `json:"timestamp"`
+	TargetUtilization       float64                      `json:"target_utilization"`
+	Cluster                 NodeCostItem                 `json:"cluster"`
+	Nodes                   []NodeCostItem               `json:"nodes"`
+	Candidates              []NodeConsolidationCandidate `json:"candidates"`
+	EstimatedMonthlySavings float64                      `json:"estimated_monthly_savings"`
+}
+
+// NodeCostItem is the allocatable vs requested vs used picture of a node (or the whole cluster).
+// BinPackingEfficiency is the dominant request ratio max(cpu, memory) in %.
+type NodeCostItem struct {
+	Name                 string  `json:"name"`
+	PodCount             int     `json:"pod_count"`
+	AllocatableCPU       float64 `json:"allocatable_cpu"`
+	AllocatableMem       int64   `json:"allocatable_mem"`
+	RequestedCPU         float64 `json:"requested_cpu"`
+	RequestedMem         int64   `json:"requested_mem"`
+	UsedCPU              float64 `json:"used_cpu"` // sum of workload P95
+	UsedMem              int64   `json:"used_mem"`
+	AllocatableCost      float64 `json:"allocatable_cost"`
+	RequestedCost        float64 `json:"requested_cost"`
+	UsedCost             float64 `json:"used_cost"`
+	UnallocatedCost      float64 `json:"unallocated_cost"`
+	CPURequestRatio      float64 `json:"cpu_request_ratio"`
+	MemRequestRatio      float64 `json:"mem_request_ratio"`
+	BinPackingEfficiency float64 `json:"bin_packing_efficiency"`
+}
+
+// NodeConsolidationCandidate is a node whose requests fit on the remaining nodes, in drain order.
+type NodeConsolidationCandidate struct {
+	Rank                    int     `json:"rank"`
+	Name                    string  `json:"name"`
+	PodCount                int     `json:"pod_count"`
+	RequestedCPU            float64 `json:"requested_cpu"`
+	RequestedMem            int64   `json:"requested_mem"`
+	BinPackingEfficiency    float64 `json:"bin_packing_efficiency"`
+	HourlyCost              float64 `json:"hourly_cost"`
+	EstimatedMonthlySavings float64 `json:"estimated_monthly_savings"`
+}
+
+// NodeConsolidationPlan is the response of GET /api/v1/nodes/consolidation-plan: the consolidation
+// candidates as a reviewable drain order, each step with its savings, its risk and the commands to
+// run. Nodes whose pods PodDisruptionBudgets would not let go are left out and listed in Blocked.
+type NodeConsolidationPlan struct {
+	Timestamp         time.Time `json:"timestamp"`
+	TargetUtilization float64   `json:"target_utilization"`
+	// DisruptionBudgetsChecked is false when the cluster's PodDisruptionBudgets could not be consulted.
+	DisruptionBudgetsChecked bool                     `json:"disruption_budgets_checked"`
+	Steps                    []NodeDrainStep          `json:"steps"`
+	Blocked                  []NodeConsolidationBlock `json:"blocked"`
+	EstimatedMonthlySavings  float64                  `json:"estimated_monthly_savings"`
+}
+
+// NodeDrainStep is one node of the drain order.
+type NodeDrainStep struct {
+	Step                     int     `json:"step"`
+	Node                     string  `json:"node"`
+	PodCount                 int     `json:"pod_count"`
+	HourlyCost               float64 `json:"hourly_cost"`
+	MonthlySavings           float64 `json:"monthly_savings"`
+	CumulativeMonthlySavings float64 `json:"cumulative_monthly_savings"`
+	// Request ratios (%) of the nodes kept after the step, carrying the requests of the drained nodes.
+	RemainingCPURequestRatio float64  `json:"remaining_cpu_request_ratio"`
+	RemainingMemRequestRatio float64  `json:"remaining_mem_request_ratio"`
+	Risk                     string   `json:"risk"` // low / medium / high
+	RiskReasons              []string `json:"risk_reasons"`
+	// DisruptionBudgets are the budgets covering pods of the node.
+	DisruptionBudgets []NodeDrainBudget `json:"disruption_budgets"`
+	Commands          []string          `json:"commands"`
+}
+
+// NodeDrainBudget is a PodDisruptionBudget covering pods of a drained node.
+type NodeDrainBudget struct {
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	PodsOnNode         int    `json:"pods_on_node"`
+	DisruptionsAllowed int32  `json:"disruptions_allowed"`
+}
+
+// NodeConsolidationBlock is a consolidation candidate that cannot be drained now.
+type NodeConsolidationBlock struct {
+	Node    string   `json:"node"`
+	Reasons []string `json:"reasons"`
+}
+
+// NodePoolAnalysisResponse is the response of GET /api/v1/nodes/pools: capacity and cost per node
+// pool, the granularity of capacity decisions. Pools come from the current nodes; workload costs
+// recorded without a pool are reported under "unassigned".
+type NodePoolAnalysisResponse struct {
+	StartTime         time.Time          `json:"start_time"`
+	EndTime           time.Time          `json:"end_time"`
+	TotalBillableCost float64            `json:"total_billable_cost"`
+	Pools             []NodePoolCostItem `json:"pools"`
+}
+
+// NodePoolCostItem is the capacity and the workload costs of one node pool. Allocatable figures are
+// current; costs are summed over the window.
+type NodePoolCostItem struct {
+	Name                  string   `json:"name"`
+	NodeCount             int      `json:"node_count"`
+	Nodes                 []string `json:"nodes"`
+	AllocatableCPU        float64  `json:"allocatable_cpu"`
+	AllocatableMem        int64    `json:"allocatable_mem"`
+	HourlyAllocatableCost float64  `json:"hourly_allocatable_cost"`
+	WorkloadCount         int      `json:"workload_count"`
+	BillableCost          float64  `json:"billable_cost"`
+	UsageCost             float64  `json:"usage_cost"`
+	WasteCost             float64  `json:"waste_cost"`
+	EfficiencyScore       float64  `json:"efficiency_score"`
+	CostPercentage        float64  `json:"cost_percentage"`
+}
@@ -0,0 +1,50 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// =============================================
+// Off-hours waste analysis DTOs
+// =============================================
+
+// OffHoursAnalysisResponse is the response of GET /api/v1/analysis/offhours. Workloads are sorted
+// by idle off-hours cost, descending.
+type OffHoursAnalysisResponse struct {
+	WindowStart     time.Time          `json:"window_start"`
+	WindowEnd       time.Time          `json:"window_end"`
+	BusinessHours   BusinessHoursState `json:"business_hours"`
+	IdleUtilization float64            `json:"idle_utilization"` // dominant usage/request ratio at or below which an hour is idle
+	Summary         OffHoursSummary    `json:"summary"`
+	Workloads       []OffHoursWorkload `json:"workloads"`
+	Total           int                `json:"total"`
+}
+
+// BusinessHoursState describes the weekday hours [start_hour, end_hour) counted as business hours.
+type BusinessHoursState struct {
+	Timezone  string `json:"timezone"`
+	StartHour int    `json:"start_hour"`
+	EndHour   int    `json:"end_hour"`
+}
+
+// OffHoursSummary sums the workloads of the analysis (before limit).
+type OffHoursSummary struct {
+	BusinessCost            float64 `json:"business_cost"`
+	OffHoursCost            float64 `json:"off_hours_cost"`
+	IdleOffHoursCost        float64 `json:"idle_off_hours_cost"`
+	IdleOffHoursCostShare   float64 `json:"idle_off_hours_cost_share"` // idle off-hours cost / total cost, %
+	Candidates              int     `json:"candidates"`
+	EstimatedMonthlySavings float64 `json:"estimated_monthly_savings"`
+}
+
+// OffHoursWorkload is the business hours vs off-hours profile of one workload.
+type OffHoursWorkload struct {
+	Namespace      string                    `json:"namespace"`
+	WorkloadName   string                    `json:"workload_name"`
+	WorkloadType   string                    `json:"workload_type,omitempty"`
+	Profile        costmodel.OffHoursProfile `json:"profile"`
+	Recommendation *costmodel.Recommendation `json:"recommendation,omitempty"`
+}
@@ -0,0 +1,77 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Namespace onboarding DTOs
+// =============================================
+
+// Onboarding steps, in the order they are applied.
+const (
+	OnboardingStepOwnership = "ownership"           // namespace -> owner team and owners
+	OnboardingStepBudget    = "budget"              // monthly budget alert rule
+	OnboardingStepSLO       = "slo"                 // alert rules of the SLO templates
+	OnboardingStepReports   = "report_subscription" // weekly report recipients
+	OnboardingStepTracking  = "tracking"            // efficiency target tracking
+)
+
+// Onboarding step statuses.
+const (
+	OnboardingStatusCreated = "created"
+	OnboardingStatusUpdated = "updated"
+	OnboardingStatusRemoved = "removed" // no longer requested, e.g. a budget of 0 on re-onboarding
+	OnboardingStatusSkipped = "skipped" // nothing to set up, or the feature is not configured
+)
+
+// OnboardNamespaceRequest is the body of POST /api/v1/admin/onboarding. Omitted settings take the
+// defaults of business.onboarding; onboarding a namespace again replaces its settings.
+type OnboardNamespaceRequest struct {
+	Namespace string   `json:"namespace" binding:"required"`
+	Team      string   `json:"team" binding:"required"`
+	Owners    []string `json:"owners"` // e-mail addresses
+	// MonthlyBudget is the budget of the namespace's rolling 30-day cost; 0 creates no budget alert.
+	MonthlyBudget *float64 `json:"monthly_budget"`
+	// SLOTemplates are the SLO alert templates to enable (availability, error_budget); omitted: the
+	// defaults, empty: none.
+	SLOTemplates []string `json:"slo_templates"`
+	// ReportRecipients subscribe to the weekly report of the namespace; omitted: the owners.
+	ReportRecipients []string `json:"report_recipients"`
+	// EfficiencyTarget is the efficiency score (0-100] tracked for the namespace.
+	EfficiencyTarget *float64 `json:"efficiency_target"`
+	// Channels restrict the budget and SLO alerts to these channels; empty sends to all of them.
+	Channels []string `json:"channels"`
+}
+
+// NamespaceOnboarding is the onboarding of a namespace: the settings it was onboarded with and the
+// steps of its latest onboarding.
+type NamespaceOnboarding struct {
+	Namespace        string           `json:"namespace"`
+	Team             string           `json:"team"`
+	Owners           []string         `json:"owners"`
+	MonthlyBudget    float64          `json:"monthly_budget"`
+	SLOTemplates     []string         `json:"slo_templates"`
+	ReportRecipients []string         `json:"report_recipients"`
+	EfficiencyTarget float64          `json:"efficiency_target"`
+	Channels         []string         `json:"channels,omitempty"`
+	OnboardedBy      string           `json:"onboarded_by,omitempty"`
+	OnboardedAt      time.Time        `json:"onboarded_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Steps            []OnboardingStep `json:"steps"`
+}
+
+// OnboardingStep is the outcome of one onboarding step.
+type OnboardingStep struct {
+	Step       string `json:"step"`
+	Status     string `json:"status"`
+	ResourceID string `json:"resource_id,omitempty"` // e.g. the ID of the alert rule
+	Detail     string `json:"detail,omitempty"`
+}
+
+// NamespaceOnboardingList is the response of GET /api/v1/admin/onboarding, sorted by namespace.
+type NamespaceOnboardingList struct {
+	Items []NamespaceOnboarding `json:"items"`
+	ListPage
+}
@@ -0,0 +1,65 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import "time"
+
+// CloseAccountingPeriodRequest is the body of POST /api/v1/admin/periods/close.
+type CloseAccountingPeriodRequest struct {
+	Note string `json:"note"`
+}
+
+// AccountingPeriod is a closed fiscal period: its daily costs, allocations and the reports built
+// from them no longer change; recalculations are recorded as adjustments instead.
+type AccountingPeriod struct {
+	Label           string    `json:"label"`
+	StartDate       time.Time `json:"start_date"`
+	EndDate         time.Time `json:"end_date"` // exclusive
+	ClosedBy        string    `json:"closed_by,omitempty"`
+	ClosedAt        time.Time `json:"closed_at"`
+	Note            string    `json:"note,omitempty"`
+	AdjustmentCount int       `json:"adjustment_count"` // adjustments recorded since the close
+}
+
+// AccountingPeriodList is the response of GET /api/v1/admin/periods: the closed periods, latest first.
+type AccountingPeriodList struct {
+	Items []AccountingPeriod `json:"items"`
+	ListPage
+}
+
+// CreateAdjustmentRequest is the body of POST /api/v1/admin/adjustments: a manual correction of the
+// cost of a namespace (optionally one workload of it) on a day of a closed period. Amount is added
+// to the cost of the day (negative: a credit) once someone other than the requester approves it
+// (POST /api/v1/admin/adjustments/:id/approve).
+type CreateAdjustmentRequest struct {
+	Date      string  `json:"date" binding:"required"` // YYYY-MM-DD
+	Namespace string  `json:"namespace" binding:"required"`
+	Workload  string  `json:"workload"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason" binding:"required"`
+}
+
+// CostAdjustment is a correction of a closed period: the difference between a recalculated value and
+// the value stored when the period was closed, or a manual correction with its reason and approver.
+// Pending manual corrections are listed but not included in reports.
+type CostAdjustment struct {
+	ID         string     `json:"id"`
+	Period     string     `json:"period"`
+	Record     string     `json:"record"` // daily_namespace_cost / hourly_workload_stat
+	Date       time.Time  `json:"date"`
+	Namespace  string     `json:"namespace"`
+	Workload   string     `json:"workload,omitempty"`
+	Amount     float64    `json:"amount"`
+	Source     string     `json:"source"` // recalculation / manual
+	Reason     string     `json:"reason,omitempty"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	Approver   string     `json:"approver,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Status     string     `json:"status"` // pending / approved
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+}
+
+// CostAdjustmentList is the response of GET /api/v1/admin/adjustments.
+type CostAdjustmentList struct {
+	Items []CostAdjustment `json:"items"`
+	ListPage
+}
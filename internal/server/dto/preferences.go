@@ -0,0 +1,64 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// User preferences DTOs
+// =============================================
+
+// UserPreferences are the cockpit settings of one user, returned by GET /api/v1/preferences.
+type UserPreferences struct {
+	// User is the identity the preferences are stored under (service account, or the user
+	// forwarded by the auth proxy).
+	User string `json:"user"`
+	// DefaultNamespaces preselects the namespace filter of the cockpit; empty means all.
+	DefaultNamespaces []string           `json:"default_namespaces"`
+	Currency          string             `json:"currency"` // ISO 4217 code, e.g. "CNY"
+	Units             DisplayUnits       `json:"units"`
+	FavoriteWorkloads []FavoriteWorkload `json:"favorite_workloads"`
+	SavedViews        []SavedView        `json:"saved_views"`
+	// UpdatedAt is empty while the user has not saved any preference (defaults are returned).
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// DisplayUnits are the units quantities are displayed in.
+type DisplayUnits struct {
+	CPU    string `json:"cpu"`    // cores / millicores
+	Memory string `json:"memory"` // GiB / MiB / GB / MB
+}
+
+// FavoriteWorkload is a workload pinned by the user.
+type FavoriteWorkload struct {
+	Namespace string `json:"namespace" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+}
+
+// SavedView is a named set of filters of a cockpit page.
+type SavedView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Page is the cockpit route the view belongs to, e.g. "/cost/namespaces".
+	Page string `json:"page,omitempty"`
+	// Filters are the query parameters of the page, e.g. {"namespace": "shop", "grade": "waste"}.
+	Filters   map[string]string `json:"filters"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// UpdatePreferencesRequest is the body of PUT /api/v1/preferences. Omitted fields keep their
+// value; saved views are managed with /api/v1/preferences/views.
+type UpdatePreferencesRequest struct {
+	DefaultNamespaces *[]string           `json:"default_namespaces"`
+	Currency          *string             `json:"currency"`
+	Units             *DisplayUnits       `json:"units"`
+	FavoriteWorkloads *[]FavoriteWorkload `json:"favorite_workloads"`
+}
+
+// SavedViewRequest is the body of POST /api/v1/preferences/views.
+type SavedViewRequest struct {
+	Name    string            `json:"name" binding:"required"`
+	Page    string            `json:"page"`
+	Filters map[string]string `json:"filters"`
+}
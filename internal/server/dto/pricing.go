@@ -0,0 +1,87 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Price history DTOs
+// =============================================
+
+// PriceVersion is a CPU/memory unit price effective from EffectiveFrom until the next version.
+type PriceVersion struct {
+	ID                  string    `json:"id"`
+	EffectiveFrom       time.Time `json:"effective_from"`
+	CPUPricePerCoreHour float64   `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64   `json:"mem_price_per_gb_hour"`
+	Note                string    `json:"note,omitempty"`
+	// Revision 该版本的更正次数（首次保存为 1）；ChangeID 批准该版本的单价变更申请
+	Revision  int       `json:"revision"`
+	ChangeID  string    `json:"change_id,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PriceHistoryResponse is the response of GET /api/v1/pricing/history.
+type PriceHistoryResponse struct {
+	Versions []PriceVersion `json:"versions"` // 按生效时间正序
+	// Current 当前生效的版本；尚无版本时为 nil（使用配置中的全局单价）
+	Current *PriceVersion `json:"current,omitempty"`
+}
+
+// SetPriceRequest is the body of PUT /api/v1/pricing/history. An existing EffectiveFrom corrects
+// that version; Recalculate re-prices the affected window right away.
+type SetPriceRequest struct {
+	EffectiveFrom       time.Time `json:"effective_from" binding:"required"`
+	CPUPricePerCoreHour float64   `json:"cpu_price_per_core_hour" binding:"required"`
+	MemPricePerGBHour   float64   `json:"mem_price_per_gb_hour" binding:"required"`
+	Note                string    `json:"note"`
+	Recalculate         bool      `json:"recalculate"`
+}
+
+// PriceChangeResponse is the response of PUT /api/v1/pricing/history.
+type PriceChangeResponse struct {
+	Version PriceVersion `json:"version"`
+	// AffectedStart..AffectedEnd 已计算的数据中使用该价格的窗口；未来生效的版本没有受影响窗口
+	AffectedStart *time.Time `json:"affected_start,omitempty"`
+	AffectedEnd   *time.Time `json:"affected_end,omitempty"`
+	// Recalculation 请求重算时的执行记录
+	Recalculation *CalculationRun `json:"recalculation,omitempty"`
+	// Change 经审批生效时对应的单价变更申请
+	Change *PriceChange `json:"change,omitempty"`
+}
+
+// PriceChange is a proposed unit price version and its review. Status is draft, rejected, approved
+// (written to the price history, effective later) or effective (approved and in effect).
+type PriceChange struct {
+	ID                  string             `json:"id"`
+	Status              string             `json:"status"`
+	EffectiveFrom       time.Time          `json:"effective_from"`
+	CPUPricePerCoreHour float64            `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64            `json:"mem_price_per_gb_hour"`
+	Note                string             `json:"note,omitempty"`
+	Recalculate         bool               `json:"recalculate"`
+	RequestedBy         string             `json:"requested_by,omitempty"`
+	PriceVersionID      string             `json:"price_version_id,omitempty"`
+	History             []PriceChangeEvent `json:"history"` // 按时间正序
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+}
+
+// PriceChangeEvent is a step of a price change: proposed, approved or rejected.
+type PriceChangeEvent struct {
+	Action  string    `json:"action"`
+	Actor   string    `json:"actor,omitempty"`
+	Comment string    `json:"comment,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// PriceChangeListResponse is the response of GET /api/v1/pricing/changes.
+type PriceChangeListResponse struct {
+	Changes []PriceChange `json:"changes"` // 最新的在前
+}
+
+// ReviewPriceChangeRequest is the body of POST /api/v1/pricing/changes/:id/approve and /reject.
+type ReviewPriceChangeRequest struct {
+	Comment string `json:"comment"`
+}
@@ -0,0 +1,30 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import "time"
+
+// QueryBudgetResponse is the response of GET /api/v1/admin/query-budgets: the Prometheus and
+// database queries of every API consumer in the current accounting window, by consumer.
+type QueryBudgetResponse struct {
+	WindowStart time.Time             `json:"window_start"`
+	WindowEnd   time.Time             `json:"window_end"`
+	Consumers   []QueryBudgetConsumer `json:"consumers"`
+}
+
+// QueryBudgetConsumer is the consumption of one API consumer (service account, or "anonymous").
+// Limits of 0 are unlimited.
+type QueryBudgetConsumer struct {
+	Consumer string `json:"consumer"`
+
+	PrometheusQueries      int64   `json:"prometheus_queries"`
+	PrometheusQueryLimit   int     `json:"prometheus_query_limit"`
+	PrometheusQuerySeconds float64 `json:"prometheus_query_seconds"`
+
+	DatabaseQueries      int64   `json:"database_queries"`
+	DatabaseQueryLimit   int     `json:"database_query_limit"`
+	DatabaseQuerySeconds float64 `json:"database_query_seconds"`
+
+	// Exceeded is the data source whose quota is used up ("prometheus" / "database"); the consumer's
+	// requests are rejected until the window ends.
+	Exceeded string `json:"exceeded,omitempty"`
+}
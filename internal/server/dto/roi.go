@@ -3,6 +3,8 @@ package dto
 
 import (
 	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
 )
 
 // =============================================
@@ -161,3 +163,79 @@ type ROIDelta struct {
 	PercentageChange float64 `json:"percentage_change"`
 	Interpretation   string  `json:"interpretation"`
 }
+
+// =============================================
+// Recommendation Adoption DTOs
+// =============================================
+
+// RecommendationAdoptionResponse is the response of GET /api/v1/roi/recommendations/adoption: how
+// many of the recommendations issued since Since were applied, per team, and the savings they
+// left unrealized.
+type RecommendationAdoptionResponse struct {
+	Since time.Time                `json:"since"`
+	Total RecommendationAdoption   `json:"total"`
+	Teams []RecommendationAdoption `json:"teams"` // by unrealized savings, descending
+	// TopUnrealized are the recommendations with the highest unrealized savings (at most 10)
+	TopUnrealized []UnrealizedRecommendation `json:"top_unrealized"`
+}
+
+// RecommendationAdoption summarizes the adoption of a set of issued recommendations. Savings only
+// count recommendations that save money (decommission and downsize).
+type RecommendationAdoption struct {
+	Team                     string  `json:"team,omitempty"`
+	Issued                   int     `json:"issued"`
+	Applied                  int     `json:"applied"`
+	Partial                  int     `json:"partial"`
+	Open                     int     `json:"open"`
+	AdoptionRate             float64 `json:"adoption_rate"` // applied / issued, %
+	EstimatedMonthlySavings  float64 `json:"estimated_monthly_savings"`
+	RealizedMonthlySavings   float64 `json:"realized_monthly_savings"`
+	UnrealizedMonthlySavings float64 `json:"unrealized_monthly_savings"`
+}
+
+// UnrealizedRecommendation is an issued recommendation not (fully) applied yet.
+type UnrealizedRecommendation struct {
+	Team                     string    `json:"team"`
+	Namespace                string    `json:"namespace"`
+	Workload                 string    `json:"workload"`
+	Action                   string    `json:"action"`
+	Resource                 string    `json:"resource"`
+	RecommendedRequest       float64   `json:"recommended_request"`
+	CurrentRequest           float64   `json:"current_request"`
+	Status                   string    `json:"status"`
+	IssuedAt                 time.Time `json:"issued_at"`
+	UnrealizedMonthlySavings float64   `json:"unrealized_monthly_savings"`
+}
+
+// ExportRecommendationsRequest is the body of POST /api/v1/roi/recommendations/export: the issued
+// recommendations to export as Kubernetes patch manifests.
+type ExportRecommendationsRequest struct {
+	RecommendationIDs []string `json:"recommendation_ids" binding:"required,min=1"`
+	Format            string   `json:"format,omitempty"`        // kustomize (default: overlay with kustomization.yaml) / kubectl (patch files and apply.sh)
+	WorkloadKind      string   `json:"workload_kind,omitempty"` // kind of the patched workloads: Deployment (default) / StatefulSet
+}
+
+// OptimizationRecordListResponse is the response of GET /api/v1/roi/optimizations.
+type OptimizationRecordListResponse struct {
+	Items []roi.OptimizationTrackingRecord `json:"items"`
+	ListPage
+}
+
+// =============================================
+// ROI Baseline Comparison DTOs
+// =============================================
+
+// ROIBaselineComparisonResponse is the response of GET /api/v1/roi/baselines/:id/compare: the
+// metrics of Date compared against a stored baseline. Baseline fields not pinned in the baseline's
+// metrics are derived from the data of its period (daily averages); PinnedMetrics lists the others.
+type ROIBaselineComparisonResponse struct {
+	BaselineID          string               `json:"baseline_id"`
+	BaselineName        string               `json:"baseline_name"`
+	BaselineType        string               `json:"baseline_type"`
+	BaselinePeriodStart time.Time            `json:"baseline_period_start"`
+	BaselinePeriodEnd   time.Time            `json:"baseline_period_end"`
+	PinnedMetrics       []string             `json:"pinned_metrics"`
+	Baseline            roi.BaselineSnapshot `json:"baseline"`
+	Current             roi.BaselineSnapshot `json:"current"`
+	Comparison          roi.DailyComparison  `json:"comparison"`
+}
@@ -0,0 +1,40 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import "time"
+
+// SelfSLOResponse is the response of GET /api/v1/slo/self: the availability and latency SLOs of
+// Lighthouse's own API routes over the evaluation window, worst route first.
+type SelfSLOResponse struct {
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	// Default targets; routes may override them in server.self_slo.routes.
+	AvailabilityTarget float64        `json:"availability_target"` // %
+	LatencyTargetMs    float64        `json:"latency_target_ms"`   // P95
+	Status             string         `json:"status"`              // worst route status: healthy / warning / critical
+	Routes             []SelfSLORoute `json:"routes"`
+}
+
+// SelfSLORoute is the SLO evaluation of one API route.
+type SelfSLORoute struct {
+	Method string `json:"method"`
+	Route  string `json:"route"` // registered path pattern, e.g. /api/v1/workloads/:id
+	Status string `json:"status"`
+
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"` // 5xx responses
+
+	Availability       float64 `json:"availability"` // %
+	AvailabilityTarget float64 `json:"availability_target"`
+	// Latency quantiles are estimated from the request latency histogram.
+	LatencyP50Ms    float64 `json:"latency_p50_ms"`
+	LatencyP95Ms    float64 `json:"latency_p95_ms"`
+	LatencyP99Ms    float64 `json:"latency_p99_ms"`
+	LatencyTargetMs float64 `json:"latency_target_ms"`
+	SlowRequests    int64   `json:"slow_requests"` // slower than the latency target
+
+	// Remaining error budgets (%); ErrorBudgetRemaining is the lower of both.
+	AvailabilityBudgetRemaining float64 `json:"availability_budget_remaining"`
+	LatencyBudgetRemaining      float64 `json:"latency_budget_remaining"`
+	ErrorBudgetRemaining        float64 `json:"error_budget_remaining"`
+}
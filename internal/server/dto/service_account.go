@@ -0,0 +1,89 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Service account and API key DTOs
+// =============================================
+
+// ServiceAccount is a machine identity (CI job, other service) with its API keys.
+type ServiceAccount struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Disabled    bool      `json:"disabled"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Keys        []APIKey  `json:"keys"`
+}
+
+// APIKey is an API key of a service account; the secret is never returned after creation.
+type APIKey struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name,omitempty"`
+	Prefix        string     `json:"prefix"` // first characters of the secret, to recognize the key
+	Scopes        []string   `json:"scopes"`
+	DailyQuota    int        `json:"daily_quota"` // requests per UTC day, 0 = unlimited
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RequestsToday int64      `json:"requests_today"`
+}
+
+// ServiceAccountListResponse is the response of GET /api/v1/admin/service-accounts.
+type ServiceAccountListResponse struct {
+	Accounts []ServiceAccount `json:"accounts"`
+	Total    int              `json:"total"`
+}
+
+// CreateServiceAccountRequest is the body of POST /api/v1/admin/service-accounts.
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	CreatedBy   string `json:"created_by"` // default: the service account of the caller's key
+}
+
+// UpdateServiceAccountRequest is the body of PUT /api/v1/admin/service-accounts/:id.
+type UpdateServiceAccountRequest struct {
+	Description string `json:"description"`
+	Disabled    bool   `json:"disabled"`
+}
+
+// CreateAPIKeyRequest is the body of POST /api/v1/admin/service-accounts/:id/keys. Scopes are
+// "<group>:<read|write|*>" where group is the first path segment after /api/v1 (cost, roi, admin, ...)
+// or "*"; read covers GET requests, write the others.
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes" binding:"required"`
+	DailyQuota int        `json:"daily_quota"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey is the response of key creation, the only time the secret is returned.
+type CreatedAPIKey struct {
+	Key    APIKey `json:"key"`
+	Secret string `json:"secret"`
+}
+
+// APIKeyUsageResponse is the response of GET /api/v1/admin/service-accounts/:id/usage.
+type APIKeyUsageResponse struct {
+	AccountID     string           `json:"account_id"`
+	StartDate     time.Time        `json:"start_date"`
+	EndDate       time.Time        `json:"end_date"`
+	TotalRequests int64            `json:"total_requests"`
+	TotalRejected int64            `json:"total_rejected"`
+	Usage         []APIKeyUsageDay `json:"usage"` // days without requests are omitted
+}
+
+// APIKeyUsageDay is the usage of one key on one UTC day.
+type APIKeyUsageDay struct {
+	KeyID    string    `json:"key_id"`
+	KeyName  string    `json:"key_name,omitempty"`
+	Date     time.Time `json:"date"`
+	Requests int64     `json:"requests"`
+	Rejected int64     `json:"rejected"` // over the daily quota
+}
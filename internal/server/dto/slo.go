@@ -3,6 +3,8 @@ package dto
 
 import (
 	"time"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 )
 
 // =============================================
@@ -113,3 +115,38 @@ type SLOBurnRateResponse struct {
 	ProjectedExhaustion time.Time `json:"projected_exhaustion"`
 	Timestamp           time.Time `json:"timestamp"`
 }
+
+// =============================================
+// SLO Cost DTOs
+// =============================================
+
+// SLOCostResponse is the response of GET /api/v1/slo/cost: what the availability target of each
+// service costs in capacity, from its usage quantiles over the window. Monthly costs are at the
+// configured unit prices.
+type SLOCostResponse struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// TotalSLOHeadroomMonthlyCost sums the capacity kept above typical demand for the targets.
+	TotalSLOHeadroomMonthlyCost float64          `json:"total_slo_headroom_monthly_cost"`
+	TotalExcessMonthlyCost      float64          `json:"total_excess_monthly_cost"`
+	Services                    []SLOServiceCost `json:"services"`
+	// Unmatched lists SLO services without workload stats in the window (namespace/service).
+	Unmatched []string `json:"unmatched"`
+}
+
+// SLOServiceCost is the cost of the SLO of one service (the workload named like it).
+type SLOServiceCost struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	Status    string `json:"status"`
+	// Availability is the measured availability (%), next to the target in Cost.
+	Availability float64 `json:"availability"`
+	LatencyP95Ms float64 `json:"latency_p95_ms"`
+	// LatencyTargetMs is the P95 latency target; LatencyBound is set when the measured P95 uses
+	// more than 80% of it, i.e. the headroom also keeps latency in target and relaxing nines risks it.
+	LatencyTargetMs float64 `json:"latency_target_ms"`
+	LatencyBound    bool    `json:"latency_bound"`
+	// Samples is where the quantiles come from: "sketch" (merged usage sketches) or "hourly_p95".
+	Samples string            `json:"samples"`
+	Cost    costmodel.SLOCost `json:"cost"`
+}
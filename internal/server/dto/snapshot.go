@@ -0,0 +1,61 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// =============================================
+// Cost snapshot query DTOs
+// =============================================
+
+// Snapshot field groups of the fields / include query parameters of the snapshot endpoints.
+const (
+	SnapshotFieldsFull    = "full"    // every group
+	SnapshotFieldsSummary = "summary" // counts + summary
+	SnapshotFieldsCounts  = "counts"  // counts only
+
+	SnapshotIncludeAggregated = "aggregated_results"
+	SnapshotIncludeResources  = "resource_results"
+)
+
+// CostSnapshot is a cost snapshot read with field selection; only the groups listed in Fields
+// were read, absent groups are omitted rather than zero.
+type CostSnapshot struct {
+	ID             string    `json:"id"`
+	CalculationID  string    `json:"calculation_id"`
+	Timestamp      time.Time `json:"timestamp"`
+	TimeRangeStart time.Time `json:"time_range_start"`
+	TimeRangeEnd   time.Time `json:"time_range_end"`
+	// Fields 本次读取的字段组：counts / summary / aggregated_results / resource_results
+	Fields  []string         `json:"fields"`
+	Counts  *GradeCounts     `json:"counts,omitempty"`
+	Summary *SnapshotSummary `json:"summary,omitempty"`
+	// AggregatedResults 按聚合层级（pod / workload / namespace / node / cluster / node_pool）
+	AggregatedResults map[string][]costmodel.AggregationResult `json:"aggregated_results,omitempty"`
+	ResourceResults   []costmodel.CostResult                   `json:"resource_results,omitempty"`
+}
+
+// SnapshotSummary is the headline part of a cost snapshot.
+type SnapshotSummary struct {
+	TotalBillableCost      float64                `json:"total_billable_cost"`
+	TotalUsageCost         float64                `json:"total_usage_cost"`
+	TotalWasteCost         float64                `json:"total_waste_cost"`
+	OverallEfficiencyScore float64                `json:"overall_efficiency_score"`
+	ContentHash            string                 `json:"content_hash,omitempty"`
+	Suspect                bool                   `json:"suspect,omitempty"`
+	RequiresConfirmation   bool                   `json:"requires_confirmation,omitempty"`
+	ConfirmedBy            string                 `json:"confirmed_by,omitempty"`
+	ConfirmedAt            *time.Time             `json:"confirmed_at,omitempty"`
+	Metadata               map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt              time.Time              `json:"created_at"`
+	UpdatedAt              time.Time              `json:"updated_at"`
+}
+
+// CostSnapshotListResponse is the response of GET /api/v1/snapshots.
+type CostSnapshotListResponse struct {
+	Snapshots []CostSnapshot `json:"snapshots"` // newest first
+	Total     int            `json:"total"`
+}
@@ -0,0 +1,80 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Application state bundle DTOs
+// =============================================
+
+// StateBundleVersion is the bundle format written by GET /api/v1/admin/state/export.
+const StateBundleVersion = 1
+
+// Import change actions.
+const (
+	StateActionCreate    = "create"
+	StateActionUpdate    = "update"
+	StateActionUnchanged = "unchanged"
+	StateActionSkipped   = "skipped" // the target environment has no store for the section
+)
+
+// StateBundle is the application state of an environment, for cloning it into another one
+// (e.g. staging -> prod). ROI baselines and price versions are stored data; SLO, teams and owners
+// are configuration of the source environment.
+type StateBundle struct {
+	Version       int                  `json:"version"`
+	Environment   string               `json:"environment,omitempty"`
+	ExportedAt    time.Time            `json:"exported_at"`
+	ROIBaselines  []ROIBaselineState   `json:"roi_baselines"`
+	PriceVersions []PriceVersion       `json:"price_versions"`
+	SLO           *SLOConfigState      `json:"slo,omitempty"`
+	Teams         map[string]TeamState `json:"teams,omitempty"`  // 团队名 -> namespace、接收人与月度预算
+	Owners        map[string][]string  `json:"owners,omitempty"` // namespace -> 归属人，"*" 为兜底
+}
+
+// ROIBaselineState is an ROI baseline without its storage timestamps.
+type ROIBaselineState struct {
+	ID              string                 `json:"id"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description,omitempty"`
+	BaselineType    string                 `json:"baseline_type"`
+	TimePeriodStart time.Time              `json:"time_period_start"`
+	TimePeriodEnd   time.Time              `json:"time_period_end"`
+	Metrics         map[string]float64     `json:"metrics,omitempty"`
+	ReferenceData   map[string]interface{} `json:"reference_data,omitempty"`
+	CreatedBy       string                 `json:"created_by,omitempty"`
+}
+
+// SLOConfigState holds the SLO thresholds of an environment.
+type SLOConfigState struct {
+	AvailabilityThreshold float64 `json:"availability_threshold"`
+	LatencyP95ThresholdMs int     `json:"latency_p95_threshold_ms"`
+}
+
+// TeamState is a team of the weekly digest: its namespaces, recipients and monthly budget.
+type TeamState struct {
+	Namespaces    []string `json:"namespaces"`
+	Recipients    []string `json:"recipients,omitempty"`
+	MonthlyBudget float64  `json:"monthly_budget,omitempty"`
+}
+
+// StateChange is one difference between a bundle and the target environment.
+type StateChange struct {
+	Section string      `json:"section"` // roi_baselines / price_versions / slo / teams / owners
+	Key     string      `json:"key"`
+	Action  string      `json:"action"`
+	Before  interface{} `json:"before,omitempty"`
+	After   interface{} `json:"after,omitempty"`
+	Detail  string      `json:"detail,omitempty"`
+}
+
+// StateImportResponse is the response of POST /api/v1/admin/state/import. Changes are applied
+// unless DryRun; ConfigChanges are never applied and must be made in the target's configuration.
+type StateImportResponse struct {
+	DryRun        bool           `json:"dry_run"`
+	Changes       []StateChange  `json:"changes"`
+	ConfigChanges []StateChange  `json:"config_changes"`
+	Summary       map[string]int `json:"summary"` // action -> count over stored sections
+}
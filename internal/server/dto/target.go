@@ -0,0 +1,68 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// =============================================
+// Namespace efficiency target DTOs
+// =============================================
+
+// SetEfficiencyTargetRequest is the body of PUT /api/v1/cost/targets/{namespace}.
+type SetEfficiencyTargetRequest struct {
+	Target float64 `json:"target"` // efficiency score 0-100
+	SetBy  string  `json:"set_by,omitempty"`
+}
+
+// EfficiencyTarget is the efficiency target of a namespace.
+type EfficiencyTarget struct {
+	Namespace string    `json:"namespace"`
+	Target    float64   `json:"target"`
+	SetBy     string    `json:"set_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EfficiencyTargetListResponse is the response of GET /api/v1/cost/targets.
+type EfficiencyTargetListResponse struct {
+	Items []EfficiencyTarget `json:"items"`
+	ListPage
+}
+
+// EfficiencyTargetDay is the actual efficiency of one day against the target.
+type EfficiencyTargetDay struct {
+	Date       time.Time `json:"date"`
+	Efficiency float64   `json:"efficiency"`
+	Target     float64   `json:"target"`
+	Met        bool      `json:"met"`
+}
+
+// EfficiencyTargetTrackingResponse is the response of GET /api/v1/cost/targets/{namespace}/tracking.
+type EfficiencyTargetTrackingResponse struct {
+	Namespace   string                     `json:"namespace"`
+	WindowStart time.Time                  `json:"window_start"`
+	WindowEnd   time.Time                  `json:"window_end"`
+	Days        []EfficiencyTargetDay      `json:"days"`
+	Attainment  costmodel.TargetAttainment `json:"attainment"`
+}
+
+// EfficiencyHeatmapRow is the efficiency of one namespace in each bucket of the heatmap.
+type EfficiencyHeatmapRow struct {
+	Namespace  string     `json:"namespace"`
+	Efficiency float64    `json:"efficiency"`       // over the whole window
+	Target     *float64   `json:"target,omitempty"` // efficiency target, when set
+	Cells      []*float64 `json:"cells"`            // one per bucket; null: no data
+}
+
+// EfficiencyHeatmapResponse is the response of GET /api/v1/cost/efficiency/heatmap. Rows are
+// sorted by billable cost in the window, descending.
+type EfficiencyHeatmapResponse struct {
+	WindowStart time.Time              `json:"window_start"`
+	WindowEnd   time.Time              `json:"window_end"`
+	BucketDays  int                    `json:"bucket_days"`
+	Buckets     []time.Time            `json:"buckets"` // start date of each bucket
+	Rows        []EfficiencyHeatmapRow `json:"rows"`
+	Total       int                    `json:"total"`
+}
@@ -0,0 +1,108 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+)
+
+// =============================================
+// Cluster events timeline DTOs
+// =============================================
+
+// Timeline event kinds.
+const (
+	TimelineKindK8sEvent       = "k8s_event"
+	TimelineKindConfigChange   = "config_change"
+	TimelineKindCalculationRun = "calculation_run"
+	TimelineKindPriceChange    = "price_change"
+	TimelineKindSLOViolation   = "slo_violation"
+)
+
+// TimelineEvent is one entry of the cluster events timeline.
+type TimelineEvent struct {
+	Kind string    `json:"kind"`
+	Time time.Time `json:"time"`
+	// EndTime 持续性事件的结束时间（K8s 事件最后一次出现、计算执行完成、SLO 恢复）；进行中或瞬时事件为空
+	EndTime *time.Time `json:"end_time,omitempty"`
+	// Namespace 为空表示集群级事件（计算执行、单价变更、集群级配置变更）
+	Namespace string `json:"namespace,omitempty"`
+	Severity  string `json:"severity"` // info / warning / critical
+	Title     string `json:"title"`
+	Summary   string `json:"summary,omitempty"`
+	// Object the event is about, "Kind/name", e.g. "Deployment/checkout" or "Service/payment-api".
+	Object string `json:"object,omitempty"`
+	// Ref is the ID of the record in its source, e.g. the calculation run or price version ID.
+	Ref string `json:"ref,omitempty"`
+}
+
+// TimelineResponse is the response of GET /api/v1/timeline: the events of all sources in the
+// window, oldest first.
+type TimelineResponse struct {
+	Namespace string          `json:"namespace,omitempty"`
+	StartTime time.Time       `json:"start_time"`
+	EndTime   time.Time       `json:"end_time"`
+	Events    []TimelineEvent `json:"events"`
+	Total     int             `json:"total"`
+	// Truncated is set when only the latest limit events are returned.
+	Truncated bool `json:"truncated"`
+	// Unavailable lists the kinds whose source failed; the timeline holds the other kinds.
+	Unavailable []string `json:"unavailable"`
+}
+
+// ConfigChangeRequest is the body of POST /api/v1/timeline/config-changes, sent by deploy
+// pipelines once a change is applied.
+type ConfigChangeRequest struct {
+	ID         string    `json:"id"` // optional; re-sending an ID replaces the record
+	Namespace  string    `json:"namespace"`
+	Kind       string    `json:"kind" binding:"required"`
+	Name       string    `json:"name" binding:"required"`
+	ChangeType string    `json:"change_type" binding:"required"`
+	OldValue   string    `json:"old_value"`
+	NewValue   string    `json:"new_value"`
+	Source     string    `json:"source"`
+	Author     string    `json:"author"`
+	OccurredAt time.Time `json:"occurred_at"` // default now
+}
+
+// ConfigChange is a recorded config change.
+type ConfigChange struct {
+	ID         string    `json:"id"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"`
+	ChangeType string    `json:"change_type"`
+	OldValue   string    `json:"old_value,omitempty"`
+	NewValue   string    `json:"new_value,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// SLOViolationRequest is the body of POST /api/v1/timeline/slo-violations. Report the start of a
+// violation, then the same ID with recovered_at once it recovers.
+type SLOViolationRequest struct {
+	ID             string     `json:"id"`
+	Namespace      string     `json:"namespace" binding:"required"`
+	Service        string     `json:"service" binding:"required"`
+	ViolationType  string     `json:"violation_type" binding:"required"` // availability / latency / error_rate
+	ActualValue    float64    `json:"actual_value"`
+	ThresholdValue float64    `json:"threshold_value"`
+	Severity       string     `json:"severity"`    // warning / critical, default critical
+	ViolatedAt     time.Time  `json:"violated_at"` // default now
+	RecoveredAt    *time.Time `json:"recovered_at"`
+}
+
+// SLOViolationRecord is a recorded SLO violation.
+type SLOViolationRecord struct {
+	ID             string     `json:"id"`
+	Namespace      string     `json:"namespace"`
+	Service        string     `json:"service"`
+	ViolationType  string     `json:"violation_type"`
+	ActualValue    float64    `json:"actual_value"`
+	ThresholdValue float64    `json:"threshold_value"`
+	Severity       string     `json:"severity"`
+	ViolatedAt     time.Time  `json:"violated_at"`
+	RecoveredAt    *time.Time `json:"recovered_at,omitempty"`
+	// Impact is the blast radius of a violation start in the week before it (with a dependency graph).
+	Impact *DependencyImpact `json:"impact,omitempty"`
+}
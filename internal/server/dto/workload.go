@@ -0,0 +1,83 @@
+// Package dto defines Data Transfer Objects for HTTP API requests and responses.
+package dto
+
+import (
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// =============================================
+// Workload Cost Detail DTOs
+// =============================================
+
+// WorkloadCostDetailResponse is the response of GET /api/v1/workloads/:namespace/:name/costs:
+// everything the workload detail page needs in one payload.
+type WorkloadCostDetailResponse struct {
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	// Cost 窗口内合计；Total 即 Billable，CPU/Memory 为各自的 billable 成本
+	Cost  CostBreakdown `json:"cost"`
+	Grade string        `json:"grade"`
+	// CostPolicy 由 lighthouse.io/* 注解声明的成本策略；未配置策略解析时为 nil
+	CostPolicy *costmodel.CostPolicy `json:"cost_policy,omitempty"`
+	// Series 按小时的 billable（Cost）/ usage / waste 成本
+	Series          []GranularCostDataPoint    `json:"series"`
+	Resources       WorkloadResourceUsage      `json:"resources"`
+	GradeHistory    []GradeChangeEvent         `json:"grade_history"`
+	Recommendations []costmodel.Recommendation `json:"recommendations"`
+	// SuppressedRecommendations 因 CPU 限流或 SLO 风险被安全检查抑制的缩容建议（含 safety 说明）；未配置安全检查时为空
+	SuppressedRecommendations []costmodel.Recommendation `json:"suppressed_recommendations,omitempty"`
+	// ReliabilityTax 窗口内因驱逐、OOM、CrashLoop 重启而浪费的成本；未配置事件源时为 nil
+	ReliabilityTax *WorkloadReliabilityTax `json:"reliability_tax,omitempty"`
+	Timestamp      time.Time               `json:"timestamp"`
+}
+
+// WorkloadReliabilityTax is the spend wasted on restarting pods in the window (see
+// costmodel.ComputeReliabilityTax), next to the SLO of the service named like the workload.
+type WorkloadReliabilityTax struct {
+	Restarts      map[string]int `json:"restarts"` // cause (eviction, oom_kill, crash_loop) -> restarts in the window
+	WastedCost    float64        `json:"wasted_cost"`
+	Share         float64        `json:"share"`           // wasted / billable cost in %
+	PodHourlyCost float64        `json:"pod_hourly_cost"` // average billable cost of one pod-hour
+	// SLOStatus and ErrorBudgetRemaining are absent when the workload has no SLO
+	SLOStatus            string   `json:"slo_status,omitempty"`
+	ErrorBudgetRemaining *float64 `json:"error_budget_remaining,omitempty"`
+}
+
+// WorkloadResourceUsage is the latest hour's requests vs P95 usage. Utilization is P95/request in %.
+type WorkloadResourceUsage struct {
+	Timestamp      time.Time `json:"timestamp"`
+	CPURequest     float64   `json:"cpu_request"`
+	CPUUsageP95    float64   `json:"cpu_usage_p95"`
+	CPUUtilization float64   `json:"cpu_utilization"`
+	MemRequest     int64     `json:"mem_request"`
+	MemUsageP95    int64     `json:"mem_usage_p95"`
+	MemUtilization float64   `json:"mem_utilization"`
+}
+
+// WorkloadHistoryResponse is the response of GET /api/v1/workloads/:namespace/:name/history: one
+// point per UTC day with data, oldest first, read from the daily rollups (cost_daily_workload) and
+// the hourly stats of the days not yet downsampled.
+type WorkloadHistoryResponse struct {
+	Namespace string                 `json:"namespace"`
+	Name      string                 `json:"name"`
+	Days      int                    `json:"days"`
+	Start     time.Time              `json:"start"`
+	End       time.Time              `json:"end"`
+	Points    []WorkloadHistoryPoint `json:"points"`
+}
+
+// WorkloadHistoryPoint is the cost of a workload on one day; Efficiency is usage/billable in % and
+// Grade is graded from it with the workload's thresholds (empty without billable cost).
+type WorkloadHistoryPoint struct {
+	Date       time.Time `json:"date"`
+	Billable   float64   `json:"billable"`
+	Usage      float64   `json:"usage"`
+	Waste      float64   `json:"waste"`
+	Efficiency float64   `json:"efficiency"`
+	Grade      string    `json:"grade,omitempty"`
+}
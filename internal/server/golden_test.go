@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/config"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/service"
+)
+
+// update rewrites the golden files: go test ./internal/server -run TestGoldenResponses -update
+var update = flag.Bool("update", false, "rewrite testdata/golden from the current responses")
+
+// goldenCases are the endpoints the dashboard consumes. File names are relative to testdata/golden.
+var goldenCases = []struct {
+	file   string
+	path   string
+	status int
+}{
+	{"v1/cost_global.json", "/api/v1/cost/global", http.StatusOK},
+	{"v1/cost_namespaces.json", "/api/v1/cost/namespaces", http.StatusOK},
+	{"v1/cost_namespace_default.json", "/api/v1/cost/namespace/default", http.StatusOK},
+	{"v1/cost_drilldown_namespace_default.json", "/api/v1/cost/drilldown/namespace/default", http.StatusOK},
+	{"v1/workload_costs.json", "/api/v1/workloads/default/workload-0/costs", http.StatusOK},
+	{"v1/nodes_analysis.json", "/api/v1/nodes/analysis", http.StatusOK},
+	{"v1/slo_health.json", "/api/v1/slo/health", http.StatusOK},
+	{"v1/roi_dashboard.json", "/api/v1/roi/dashboard", http.StatusOK},
+	{"v1/not_found.json", "/api/v1/no-such-route", http.StatusNotFound},
+}
+
+// newGoldenServer wires the server to seeded mocks without latency, so responses only differ in
+// the fields scrubbed by normalizeGolden.
+func newGoldenServer() *HTTPServer {
+	cfg := &config.Config{
+		Env: config.EnvDevelopment,
+		Server: config.ServerConfig{
+			Port:         8080,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 30 * time.Second,
+		},
+	}
+	repoConfig := postgres.DefaultMockConfig()
+	repoConfig.LatencyMs = 0
+	repo := postgres.NewMockRepository(repoConfig)
+	k8sConfig := k8s.DefaultMockConfig()
+	k8sConfig.LatencyMs = 0
+
+	srv := NewHTTPServer(cfg, service.NewCostService(repo))
+	srv.SetWorkloadService(service.NewWorkloadService(repo))
+	srv.SetNodeAnalysisService(service.NewNodeAnalysisService(k8s.NewMockClient(k8sConfig), repo, 0.025, 0.01))
+	return srv
+}
+
+var (
+	goldenTimestamp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`)
+	goldenUUID      = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// normalizeGolden replaces values that legitimately change between runs (wall-clock timestamps,
+// generated IDs) with placeholders, and re-indents the JSON so golden diffs are readable.
+func normalizeGolden(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber() // 保留原始数字文本，舍入变化会体现在 diff 中
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("response is not JSON: %v\n%s", err, body)
+	}
+	out, err := json.MarshalIndent(scrubGolden(v), "", "  ")
+	if err != nil {
+		t.Fatalf("marshal normalized response: %v", err)
+	}
+	return append(out, '\n')
+}
+
+func scrubGolden(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = scrubGolden(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = scrubGolden(child)
+		}
+	case string:
+		switch {
+		case goldenTimestamp.MatchString(v):
+			return "<timestamp>"
+		case goldenUUID.MatchString(v):
+			return "<uuid>"
+		}
+	}
+	return v
+}
+
+// TestGoldenResponses compares the JSON of the main endpoints with testdata/golden, so field
+// renames and rounding changes show up as a failing diff before the dashboard breaks.
+// After an intended payload change, rerun with -update and review the golden diff.
+func TestGoldenResponses(t *testing.T) {
+	engine := newGoldenServer().Engine()
+	for _, tc := range goldenCases {
+		t.Run(strings.TrimSuffix(filepath.Base(tc.file), ".json"), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tc.status {
+				t.Fatalf("GET %s status = %d, want %d: %s", tc.path, w.Code, tc.status, w.Body.String())
+			}
+			got := normalizeGolden(t, w.Body.Bytes())
+			path := filepath.Join("testdata", "golden", tc.file)
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("GET %s differs from %s (rerun with -update if intended)\n--- got\n%s\n--- want\n%s", tc.path, path, got, want)
+			}
+		})
+	}
+}
@@ -2,18 +2,36 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/myxxhui/lighthouse-src/internal/config"
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/querybudget"
+	"github.com/myxxhui/lighthouse-src/internal/data/storage"
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+	"github.com/myxxhui/lighthouse-src/internal/server/apimetrics"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
 	"github.com/myxxhui/lighthouse-src/internal/server/middleware"
 	"github.com/myxxhui/lighthouse-src/internal/server/service"
+	"github.com/myxxhui/lighthouse-src/internal/worker/spool"
+	"github.com/myxxhui/lighthouse-src/pkg/authz"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -24,6 +42,51 @@ type HTTPServer struct {
 	engine      *gin.Engine
 	server      *http.Server
 	costService *service.CostService
+
+	grafanaService     *service.GrafanaService
+	metricsHandler     http.Handler
+	calculationService *service.CalculationRunService
+	spool              *spool.Spool
+	spoolHandlers      spool.Handlers
+	breakers           []*breaker.Set
+	degradedRepo       *storage.DegradedRepository
+	gradeService       *service.GradeHistoryService
+	workloadService    *service.WorkloadService
+	nodeService        *service.NodeAnalysisService
+	sloCostService     *service.SLOCostService
+	capacityService    *service.CapacityService
+	offHoursService    *service.OffHoursService
+	allocationPreview  *service.AllocationPreviewService
+	catalogService     *service.CatalogService
+	pricingService     *service.PricingService
+	integrityService   *service.SnapshotIntegrityService
+	guardrail          *service.SnapshotGuardrail
+	targetService      *service.EfficiencyTargetService
+	stateService       *service.StateService
+	datasetService     *service.DatasetExportService
+	fiscalService      *service.FiscalReportService
+	adoptionService    *service.RecommendationAdoptionService
+	manifestExport     *service.RecommendationExportService
+	accountService     *service.ServiceAccountService
+	authorizer         authz.Authorizer
+	exportService      *service.ExportJobService
+	roiComparison      *service.ROIComparisonService
+	regradeService     *service.RegradeService
+	alertRuleService   *service.AlertRuleService
+	notifyPolicy       *service.NotificationPolicyService
+	lineageService     *service.LineageService
+	freshness          *service.DataFreshnessService
+	timelineService    *service.TimelineService
+	dependencyService  *service.DependencyService
+	periodService      *service.PeriodCloseService
+	onboardingService  *service.OnboardingService
+	preferenceService  *service.PreferenceService
+	admissionService   *service.AdmissionService
+	costImportService  *service.CostImportService
+	apiMetrics         *apimetrics.Recorder
+	selfSLOService     *service.SelfSLOService
+	queryBudgets       *querybudget.Ledger
+	chaosDrills        *chaos.Controller
 }
 
 // NewHTTPServer creates a new HTTP server instance. Uses Mock data if costService is nil.
@@ -39,14 +102,20 @@ func NewHTTPServer(cfg *config.Config, costService *service.CostService) *HTTPSe
 
 	// Apply global middleware
 	engine.Use(middleware.RequestID())
+	engine.Use(middleware.Locale(cfg.I18n.Locale()))
 	engine.Use(middleware.Logger())
+	requests := apimetrics.NewRecorder()
+	engine.Use(middleware.RouteMetrics(requests))
 	engine.Use(middleware.Recovery())
+	engine.Use(middleware.RequestTimeout(cfg.Server.RequestDeadline()))
 	engine.Use(middleware.CORS())
+	engine.Use(middleware.Staleness())
 
 	srv := &HTTPServer{
 		config:      cfg,
 		engine:      engine,
 		costService: costService,
+		apiMetrics:  requests,
 	}
 
 	// Setup routes
@@ -60,11 +129,22 @@ func (s *HTTPServer) setupRoutes() {
 	// Health check endpoint
 	s.engine.GET("/health", s.healthCheck)
 
+	// Readiness with external data source circuit breaker state
+	s.engine.GET("/readyz", s.readyz)
+
+	// Prometheus metrics of Lighthouse-computed values
+	s.engine.GET("/metrics", s.metrics)
+
+	// Kubernetes admission webhooks estimating the cost of Deployments / StatefulSets; called by
+	// the API server, so they are outside /api/v1 and its API keys
+	s.engine.POST("/admission/validate", s.admissionReview(false))
+	s.engine.POST("/admission/mutate", s.admissionReview(true))
+
 	// API v1 routes
-	apiV1 := s.engine.Group("/api/v1")
+	apiV1 := s.engine.Group("/api/v1", s.authenticate, s.authorize, s.meterQueries)
 	{
 		// Cost routes - will be implemented by routes package
-		costGroup := apiV1.Group("/cost")
+		costGroup := apiV1.Group("/cost", s.dataAsOf, s.traceLineage)
 		s.registerCostRoutes(costGroup)
 
 		// SLO routes
@@ -74,6 +154,66 @@ func (s *HTTPServer) setupRoutes() {
 		// ROI routes
 		roiGroup := apiV1.Group("/roi")
 		s.registerROIRoutes(roiGroup)
+
+		// Workload cost detail
+		workloadGroup := apiV1.Group("/workloads", s.dataAsOf, s.traceLineage)
+		s.registerWorkloadRoutes(workloadGroup)
+
+		// Node (L2) cost and bin-packing analysis
+		nodeGroup := apiV1.Group("/nodes", s.dataAsOf, s.traceLineage)
+		s.registerNodeRoutes(nodeGroup)
+
+		// Capacity planning
+		capacityGroup := apiV1.Group("/capacity", s.dataAsOf, s.traceLineage)
+		s.registerCapacityRoutes(capacityGroup)
+
+		// Waste analyses (nights/weekends)
+		analysisGroup := apiV1.Group("/analysis", s.dataAsOf, s.traceLineage)
+		s.registerAnalysisRoutes(analysisGroup)
+
+		// Grafana JSON datasource routes
+		grafanaGroup := apiV1.Group("/grafana")
+		s.registerGrafanaRoutes(grafanaGroup)
+
+		// Cost calculation run tracking
+		calculationGroup := apiV1.Group("/calculations")
+		s.registerCalculationRoutes(calculationGroup)
+
+		// Unit price history and retroactive recalculation
+		pricingGroup := apiV1.Group("/pricing")
+		s.registerPricingRoutes(pricingGroup)
+
+		// Cost snapshot integrity verification and verified export
+		snapshotGroup := apiV1.Group("/snapshots")
+		s.registerSnapshotRoutes(snapshotGroup)
+
+		// Async export jobs (CSV / PDF files downloadable for a retention period)
+		exportGroup := apiV1.Group("/exports")
+		s.registerExportRoutes(exportGroup)
+
+		// User-defined alert rules
+		alertGroup := apiV1.Group("/alerts")
+		s.registerAlertRoutes(alertGroup)
+
+		// Lineage of cost query responses (X-Lighthouse-Lineage-Id)
+		lineageGroup := apiV1.Group("/lineage")
+		s.registerLineageRoutes(lineageGroup)
+
+		// Cluster events timeline of the cockpit
+		timelineGroup := apiV1.Group("/timeline")
+		s.registerTimelineRoutes(timelineGroup)
+
+		// Service dependency graph (tracing and static edges)
+		dependencyGroup := apiV1.Group("/dependencies")
+		s.registerDependencyRoutes(dependencyGroup)
+
+		// Per-user cockpit preferences and saved views
+		preferencesGroup := apiV1.Group("/preferences")
+		s.registerPreferenceRoutes(preferencesGroup)
+
+		// Admin: dead-letter spool of failed writes
+		adminGroup := apiV1.Group("/admin")
+		s.registerAdminRoutes(adminGroup)
 	}
 
 	// Swagger documentation - enable in non-production environments
@@ -83,10 +223,7 @@ func (s *HTTPServer) setupRoutes() {
 
 	// 404 handler
 	s.engine.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Not Found",
-			"code":  "NOT_FOUND",
-		})
+		c.JSON(http.StatusNotFound, errorBody(c, "Not Found", "NOT_FOUND"))
 	})
 }
 
@@ -95,21 +232,508 @@ func (s *HTTPServer) registerCostRoutes(group *gin.RouterGroup) {
 	// Global cost overview
 	group.GET("/global", s.globalCost)
 	// Namespace list (aggregated for frontend cost table)
-	group.GET("/namespaces", s.listNamespaces)
+	group.GET("/namespaces", paginate(listSpec{Sortable: true}), s.listNamespaces)
 	// Namespace cost
-	group.GET("/namespace/:namespace", s.namespaceCost)
+	group.GET("/namespace/:namespace", paginate(listSpec{DefaultLimit: defaultWorkloadPageLimit, Sortable: true}), s.namespaceCost)
+	// Cost time series at the planned resolution
+	group.GET("/series", paginate(listSpec{}), s.costSeries)
 	// Drilldown
 	group.GET("/drilldown/:level/:identifier", s.drilldownCost)
+	// Workload efficiency grade transitions
+	group.GET("/grades/history", paginate(listSpec{}), s.gradeHistory)
+	// Namespace efficiency targets
+	group.GET("/targets", paginate(listSpec{}), s.listEfficiencyTargets)
+	group.PUT("/targets/:namespace", s.setEfficiencyTarget)
+	group.GET("/targets/:namespace/tracking", paginate(listSpec{}), s.trackEfficiencyTarget)
+	// Namespace × day efficiency heatmap
+	group.GET("/efficiency/heatmap", s.efficiencyHeatmap)
+	// Fiscal period / quarter / year to date
+	group.GET("/fiscal-report", s.fiscalReport)
+	// Shared cost allocation rule change preview
+	group.POST("/allocation/preview", s.previewAllocation)
+}
+
+// registerWorkloadRoutes registers per-workload routes.
+func (s *HTTPServer) registerWorkloadRoutes(group *gin.RouterGroup) {
+	group.GET("/catalog", s.searchCatalog)
+	group.GET("/:namespace/:name/costs", paginate(listSpec{}), s.workloadCost)
+	group.GET("/:namespace/:name/history", s.workloadHistory)
+	group.GET("/:namespace/:name/impact", paginate(listSpec{}), s.workloadImpact)
+}
+
+// registerNodeRoutes registers node-level analysis routes.
+func (s *HTTPServer) registerNodeRoutes(group *gin.RouterGroup) {
+	group.GET("/analysis", s.nodeAnalysis)
+	group.GET("/consolidation-plan", s.nodeConsolidationPlan)
+	group.GET("/pools", paginate(listSpec{}), s.nodePoolAnalysis)
+}
+
+// registerCapacityRoutes registers capacity planning routes.
+func (s *HTTPServer) registerCapacityRoutes(group *gin.RouterGroup) {
+	group.GET("/projection", s.capacityProjection)
+	group.GET("/simulation", s.capacitySimulation)
+}
+
+// registerAnalysisRoutes registers waste analysis routes.
+func (s *HTTPServer) registerAnalysisRoutes(group *gin.RouterGroup) {
+	group.GET("/offhours", paginate(listSpec{}), s.offHoursAnalysis)
+}
+
+// registerPricingRoutes registers price history routes.
+func (s *HTTPServer) registerPricingRoutes(group *gin.RouterGroup) {
+	group.GET("/history", s.getPriceHistory)
+	group.PUT("/history", s.setPrice)
+	group.POST("/recalculate", s.recalculatePrices)
+	group.GET("/changes", s.listPriceChanges)
+	group.POST("/changes", s.proposePriceChange)
+	group.GET("/changes/:id", s.getPriceChange)
+	group.POST("/changes/:id/approve", s.approvePriceChange)
+	group.POST("/changes/:id/reject", s.rejectPriceChange)
+}
+
+// registerSnapshotRoutes registers cost snapshot integrity routes.
+func (s *HTTPServer) registerSnapshotRoutes(group *gin.RouterGroup) {
+	group.GET("", paginate(listSpec{}), s.listSnapshots)
+	group.GET("/:id", s.getSnapshot)
+	group.GET("/verify", s.verifySnapshots)
+	group.GET("/export", paginate(listSpec{}), s.exportSnapshots)
+	group.GET("/suspect", s.listSuspectSnapshots)
+	group.POST("/:id/confirm", s.confirmSnapshot)
+	group.GET("/grades", paginate(listSpec{}), s.snapshotGrades)
+}
+
+// registerExportRoutes registers async export job routes.
+func (s *HTTPServer) registerExportRoutes(group *gin.RouterGroup) {
+	group.GET("", s.listExportJobs)
+	group.POST("", s.createExportJob)
+	group.GET("/:id", s.getExportJob)
+	group.GET("/:id/download", s.downloadExportJob)
 }
 
 // registerSLORoutes registers SLO-related routes (temporary implementation).
 func (s *HTTPServer) registerSLORoutes(group *gin.RouterGroup) {
-	group.GET("/health", s.sloHealth)
+	group.GET("/health", paginate(listSpec{}), s.sloHealth)
+	group.GET("/cost", paginate(listSpec{}), s.sloCost)
+	// SLOs of Lighthouse's own API routes
+	group.GET("/self", s.selfSLO)
 }
 
 // registerROIRoutes registers ROI-related routes (temporary implementation).
 func (s *HTTPServer) registerROIRoutes(group *gin.RouterGroup) {
 	group.GET("/dashboard", s.roiDashboard)
+	// Adoption of issued right-sizing recommendations
+	group.GET("/recommendations/adoption", s.recommendationAdoption)
+	// Patch manifests of accepted recommendations, tracked until verified
+	group.POST("/recommendations/export", s.exportRecommendations)
+	group.GET("/optimizations", paginate(listSpec{}), s.listOptimizationRecords)
+	// Any day against any stored baseline (what-if analysis)
+	group.GET("/baselines/:id/compare", s.compareROIBaseline)
+}
+
+// registerGrafanaRoutes registers the Grafana simple-JSON / Infinity datasource contract.
+// Datasource URL: <lighthouse>/api/v1/grafana
+func (s *HTTPServer) registerGrafanaRoutes(group *gin.RouterGroup) {
+	// "Test connection" in Grafana issues GET /
+	group.GET("", s.grafanaTestConnection)
+	group.POST("/search", s.grafanaSearch)
+	group.POST("/query", s.grafanaQuery)
+	group.POST("/annotations", s.grafanaAnnotations)
+}
+
+// registerCalculationRoutes registers cost-pipeline run tracking routes.
+func (s *HTTPServer) registerCalculationRoutes(group *gin.RouterGroup) {
+	group.GET("/runs", paginate(listSpec{}), s.listCalculationRuns)
+	group.POST("/runs", s.triggerCalculationRun)
+	group.GET("/runs/:id", s.getCalculationRun)
+	group.POST("/runs/:id/retrigger", s.retriggerCalculationRun)
+}
+
+// registerAlertRoutes registers the alert rule, notification routing and silence routes.
+func (s *HTTPServer) registerAlertRoutes(group *gin.RouterGroup) {
+	group.GET("/rules", s.listAlertRules)
+	group.POST("/rules", s.createAlertRule)
+	group.GET("/rules/:id", s.getAlertRule)
+	group.PUT("/rules/:id", s.updateAlertRule)
+	group.DELETE("/rules/:id", s.deleteAlertRule)
+	group.POST("/rules/:id/evaluate", s.evaluateAlertRule)
+	group.GET("/routes", s.listNotificationRoutes)
+	group.POST("/routes", s.createNotificationRoute)
+	group.GET("/routes/:id", s.getNotificationRoute)
+	group.PUT("/routes/:id", s.updateNotificationRoute)
+	group.DELETE("/routes/:id", s.deleteNotificationRoute)
+	group.GET("/silences", s.listSilences)
+	group.POST("/silences", s.createSilence)
+	group.DELETE("/silences/:id", s.expireSilence)
+}
+
+// registerLineageRoutes registers the cost query lineage lookup.
+func (s *HTTPServer) registerLineageRoutes(group *gin.RouterGroup) {
+	group.GET("/:id", s.getLineage)
+}
+
+// registerTimelineRoutes registers the cluster events timeline and the endpoints reporting to it.
+func (s *HTTPServer) registerTimelineRoutes(group *gin.RouterGroup) {
+	group.GET("", paginate(listSpec{}), s.timeline)
+	group.POST("/config-changes", s.recordConfigChange)
+	group.POST("/slo-violations", s.recordSLOViolation)
+}
+
+// registerDependencyRoutes registers the service dependency graph and its ingestion.
+func (s *HTTPServer) registerDependencyRoutes(group *gin.RouterGroup) {
+	group.GET("", paginate(listSpec{}), s.listDependencies)
+	group.POST("/edges", s.ingestDependencies)
+}
+
+// registerPreferenceRoutes registers the preferences of the calling user.
+func (s *HTTPServer) registerPreferenceRoutes(group *gin.RouterGroup) {
+	group.GET("", s.getPreferences)
+	group.PUT("", s.updatePreferences)
+	group.POST("/views", s.savePreferenceView)
+	group.DELETE("/views/:id", s.deletePreferenceView)
+}
+
+// registerAdminRoutes registers operational routes.
+func (s *HTTPServer) registerAdminRoutes(group *gin.RouterGroup) {
+	group.GET("/spool", s.listSpool)
+	group.POST("/spool/flush", s.flushSpool)
+	group.GET("/spool/:id", s.getSpoolEntry)
+	group.DELETE("/spool/:id", s.deleteSpoolEntry)
+	group.GET("/state/export", s.exportState)
+	group.POST("/state/import", s.importState)
+	group.GET("/dataset/export", paginate(listSpec{}), s.exportDataset)
+	group.POST("/import/allocations", s.importAllocations)
+	group.POST("/regrade", s.regradeSnapshots)
+	group.GET("/regrade/view", s.getGradeView)
+	group.PUT("/regrade/view", s.setGradeView)
+	group.GET("/service-accounts", s.listServiceAccounts)
+	group.POST("/service-accounts", s.createServiceAccount)
+	group.GET("/service-accounts/:id", s.getServiceAccount)
+	group.PUT("/service-accounts/:id", s.updateServiceAccount)
+	group.DELETE("/service-accounts/:id", s.deleteServiceAccount)
+	group.POST("/service-accounts/:id/keys", s.createAPIKey)
+	group.DELETE("/service-accounts/:id/keys/:key_id", s.revokeAPIKey)
+	group.GET("/service-accounts/:id/usage", s.serviceAccountUsage)
+	group.GET("/query-budgets", s.listQueryBudgets)
+	group.GET("/chaos", s.getChaos)
+	group.POST("/chaos", s.startChaosDrill)
+	group.DELETE("/chaos", s.stopChaosDrill)
+	group.GET("/periods", paginate(listSpec{}), s.listAccountingPeriods)
+	group.POST("/periods/close", s.closeAccountingPeriod)
+	group.GET("/adjustments", paginate(listSpec{}), s.listAdjustments)
+	group.POST("/adjustments", s.createAdjustment)
+	group.POST("/adjustments/:id/approve", s.approveAdjustment)
+	group.GET("/onboarding", paginate(listSpec{}), s.listOnboardings)
+	group.POST("/onboarding", s.onboardNamespace)
+	group.GET("/onboarding/:namespace", s.getOnboarding)
+}
+
+// SetGrafanaService enables the Grafana datasource endpoints; without it they return empty results.
+func (s *HTTPServer) SetGrafanaService(grafanaService *service.GrafanaService) {
+	s.grafanaService = grafanaService
+}
+
+// SetMetricsHandler enables GET /metrics (e.g. exporter.New(...).Handler()).
+func (s *HTTPServer) SetMetricsHandler(h http.Handler) {
+	s.metricsHandler = h
+}
+
+// SetCalculationRunService enables the calculation run endpoints; without it they return 404.
+func (s *HTTPServer) SetCalculationRunService(calculationService *service.CalculationRunService) {
+	s.calculationService = calculationService
+}
+
+// SetGradeHistoryService enables GET /api/v1/cost/grades/history; without it the endpoint returns 404.
+func (s *HTTPServer) SetGradeHistoryService(gradeService *service.GradeHistoryService) {
+	s.gradeService = gradeService
+}
+
+// SetEfficiencyTargetService enables the /api/v1/cost/targets and /api/v1/cost/efficiency/heatmap
+// endpoints; without it they return 404.
+func (s *HTTPServer) SetEfficiencyTargetService(targetService *service.EfficiencyTargetService) {
+	s.targetService = targetService
+}
+
+// SetFiscalReportService enables GET /api/v1/cost/fiscal-report; without it the endpoint returns 404.
+func (s *HTTPServer) SetFiscalReportService(fiscalService *service.FiscalReportService) {
+	s.fiscalService = fiscalService
+}
+
+// SetWorkloadService enables GET /api/v1/workloads/:namespace/:name/costs and /history; without it they return 404.
+func (s *HTTPServer) SetWorkloadService(workloadService *service.WorkloadService) {
+	s.workloadService = workloadService
+}
+
+// SetCatalogService enables GET /api/v1/workloads/catalog; without it the endpoint returns 404.
+// The caller keeps the catalog refreshed (CatalogService.Run).
+func (s *HTTPServer) SetCatalogService(catalogService *service.CatalogService) {
+	s.catalogService = catalogService
+}
+
+// SetNodeAnalysisService enables GET /api/v1/nodes/analysis, /nodes/consolidation-plan and /nodes/pools;
+// without it they return 404.
+func (s *HTTPServer) SetNodeAnalysisService(nodeService *service.NodeAnalysisService) {
+	s.nodeService = nodeService
+}
+
+// SetSLOCostService enables GET /api/v1/slo/cost; without it the endpoint returns 404.
+func (s *HTTPServer) SetSLOCostService(sloCostService *service.SLOCostService) {
+	s.sloCostService = sloCostService
+}
+
+// SetCapacityService enables GET /api/v1/capacity/projection and /simulation; without it they return 404.
+func (s *HTTPServer) SetCapacityService(capacityService *service.CapacityService) {
+	s.capacityService = capacityService
+}
+
+// SetOffHoursService enables GET /api/v1/analysis/offhours; without it the endpoint returns 404.
+func (s *HTTPServer) SetOffHoursService(offHoursService *service.OffHoursService) {
+	s.offHoursService = offHoursService
+}
+
+// SetAllocationPreviewService enables POST /api/v1/cost/allocation/preview; without it the endpoint
+// returns 404.
+func (s *HTTPServer) SetAllocationPreviewService(allocationPreview *service.AllocationPreviewService) {
+	s.allocationPreview = allocationPreview
+}
+
+// SetPricingService enables the /api/v1/pricing endpoints; without it they return 404.
+func (s *HTTPServer) SetPricingService(pricingService *service.PricingService) {
+	s.pricingService = pricingService
+}
+
+// SetSnapshotIntegrityService enables the /api/v1/snapshots endpoints; without it they return 404.
+func (s *HTTPServer) SetSnapshotIntegrityService(integrityService *service.SnapshotIntegrityService) {
+	s.integrityService = integrityService
+}
+
+// SetSnapshotGuardrail enables the suspect snapshot endpoints; without it they return 404.
+func (s *HTTPServer) SetSnapshotGuardrail(guardrail *service.SnapshotGuardrail) {
+	s.guardrail = guardrail
+}
+
+// SetROIComparisonService enables GET /api/v1/roi/baselines/:id/compare; without it the endpoint returns 404.
+func (s *HTTPServer) SetROIComparisonService(roiComparison *service.ROIComparisonService) {
+	s.roiComparison = roiComparison
+}
+
+// SetRecommendationAdoptionService enables GET /api/v1/roi/recommendations/adoption and adds the
+// unrealized savings of issued recommendations to the ROI dashboard; without it the endpoint returns 404.
+func (s *HTTPServer) SetRecommendationAdoptionService(adoptionService *service.RecommendationAdoptionService) {
+	s.adoptionService = adoptionService
+}
+
+// SetRecommendationExportService enables POST /api/v1/roi/recommendations/export and
+// GET /api/v1/roi/optimizations; without it the endpoints return 404.
+func (s *HTTPServer) SetRecommendationExportService(exportService *service.RecommendationExportService) {
+	s.manifestExport = exportService
+}
+
+// SetServiceAccountService enables API key authentication of /api/v1 and the
+// /api/v1/admin/service-accounts endpoints; without it requests are not authenticated and the
+// endpoints return 404.
+func (s *HTTPServer) SetServiceAccountService(accountService *service.ServiceAccountService) {
+	s.accountService = accountService
+}
+
+// SetAuthorizer consults authorizer for every /api/v1 request once the API key is authenticated;
+// denied requests get 403. Errors of the authorizer reject the request with 503 unless
+// security.authz.fail_open is set.
+func (s *HTTPServer) SetAuthorizer(authorizer authz.Authorizer) {
+	s.authorizer = authorizer
+}
+
+// SetAlertRuleService enables the /api/v1/alerts/rules endpoints; without it they return 404.
+// Its Run must be started for rules to be evaluated on their interval.
+func (s *HTTPServer) SetAlertRuleService(alertRuleService *service.AlertRuleService) {
+	s.alertRuleService = alertRuleService
+}
+
+// SetNotificationPolicyService enables the /api/v1/alerts/routes and /api/v1/alerts/silences
+// endpoints; without it they return 404. Set it as the filter of the dispatcher to apply them.
+func (s *HTTPServer) SetNotificationPolicyService(policy *service.NotificationPolicyService) {
+	s.notifyPolicy = policy
+}
+
+// SetLineageService records the lineage of successful GET responses of the cost, workload, node,
+// capacity and analysis routes and enables /api/v1/lineage/:id; without it no lineage is recorded.
+func (s *HTTPServer) SetLineageService(lineage *service.LineageService) {
+	s.lineageService = lineage
+}
+
+// SetDataFreshnessService gates /readyz on the first successful data sync and adds
+// X-Lighthouse-Data-As-Of to cost query responses.
+func (s *HTTPServer) SetDataFreshnessService(freshness *service.DataFreshnessService) {
+	s.freshness = freshness
+}
+
+// SetTimelineService enables the /api/v1/timeline endpoints; without it they return 404.
+func (s *HTTPServer) SetTimelineService(timelineService *service.TimelineService) {
+	s.timelineService = timelineService
+}
+
+// SetDependencyService enables the /api/v1/dependencies endpoints and GET
+// /api/v1/workloads/:namespace/:name/impact; without it they return 404.
+func (s *HTTPServer) SetDependencyService(dependencyService *service.DependencyService) {
+	s.dependencyService = dependencyService
+}
+
+// SetPeriodCloseService enables the /api/v1/admin/periods and /api/v1/admin/adjustments endpoints;
+// without it they return 404.
+func (s *HTTPServer) SetPeriodCloseService(periodService *service.PeriodCloseService) {
+	s.periodService = periodService
+}
+
+// SetOnboardingService enables the /api/v1/admin/onboarding endpoints; without it they return 404.
+func (s *HTTPServer) SetOnboardingService(onboardingService *service.OnboardingService) {
+	s.onboardingService = onboardingService
+}
+
+// SetAdmissionService enables the /admission/validate and /admission/mutate webhooks; without it
+// they return 404.
+func (s *HTTPServer) SetAdmissionService(admissionService *service.AdmissionService) {
+	s.admissionService = admissionService
+}
+
+// SetPreferenceService enables the /api/v1/preferences endpoints; without it they return 404.
+func (s *HTTPServer) SetPreferenceService(preferenceService *service.PreferenceService) {
+	s.preferenceService = preferenceService
+}
+
+// SetExportJobService enables the /api/v1/exports endpoints; without it they return 404.
+// Its Run must be started for queued jobs to be processed.
+func (s *HTTPServer) SetExportJobService(exportService *service.ExportJobService) {
+	s.exportService = exportService
+}
+
+// SetRegradeService enables GET /api/v1/snapshots/grades and the /api/v1/admin/regrade endpoints;
+// without it they return 404.
+func (s *HTTPServer) SetRegradeService(regradeService *service.RegradeService) {
+	s.regradeService = regradeService
+}
+
+// SetStateService enables the /api/v1/admin/state endpoints; without it they return 404.
+func (s *HTTPServer) SetStateService(stateService *service.StateService) {
+	s.stateService = stateService
+}
+
+// SetDatasetExportService enables GET /api/v1/admin/dataset/export; without it the endpoint returns 404.
+func (s *HTTPServer) SetDatasetExportService(datasetService *service.DatasetExportService) {
+	s.datasetService = datasetService
+}
+
+// SetCostImportService enables POST /api/v1/admin/import/allocations; without it the endpoint returns 404.
+func (s *HTTPServer) SetCostImportService(costImportService *service.CostImportService) {
+	s.costImportService = costImportService
+}
+
+// SetSelfSLOService enables GET /api/v1/slo/self; without it the endpoint returns 404.
+func (s *HTTPServer) SetSelfSLOService(selfSLOService *service.SelfSLOService) {
+	s.selfSLOService = selfSLOService
+}
+
+// SetQueryBudgets accounts the Prometheus and database queries of every /api/v1 request to its
+// consumer in ledger, rejects the requests of consumers over quota with 429 and enables
+// GET /api/v1/admin/query-budgets; without it queries are not accounted and the endpoint returns 404.
+func (s *HTTPServer) SetQueryBudgets(ledger *querybudget.Ledger) {
+	s.queryBudgets = ledger
+}
+
+// SetChaos enables the /api/v1/admin/chaos endpoints, which start and stop resilience drills of
+// controller (the data clients must be wrapped with its chaos wrappers); without it, or unless
+// chaos.enabled is set in the configuration, they return 404.
+func (s *HTTPServer) SetChaos(controller *chaos.Controller) {
+	s.chaosDrills = controller
+}
+
+// SetSpool enables the spool admin endpoints; handlers are used by POST /admin/spool/flush.
+func (s *HTTPServer) SetSpool(sp *spool.Spool, handlers spool.Handlers) {
+	s.spool = sp
+	s.spoolHandlers = handlers
+}
+
+// SetBreakers exposes the circuit breakers of external data sources in /readyz.
+func (s *HTTPServer) SetBreakers(sets ...*breaker.Set) {
+	s.breakers = append(s.breakers, sets...)
+}
+
+// SetDegradedRepository exposes the repository degraded-mode state in /readyz.
+func (s *HTTPServer) SetDegradedRepository(repo *storage.DegradedRepository) {
+	s.degradedRepo = repo
+}
+
+// readyz handles GET /readyz. Open breakers or a degraded repository report "degraded" but keep 200:
+// the API still serves stored or cached results, and failing readiness would only move the load onto
+// the other replicas. Before the first successful data sync it reports "not_ready" with 503, so a
+// fresh deploy does not take traffic while it can only render zeros.
+func (s *HTTPServer) readyz(c *gin.Context) {
+	status := "ready"
+	breakers := []breaker.Status{}
+	for _, set := range s.breakers {
+		for _, st := range set.Statuses() {
+			if st.State != breaker.StateClosed {
+				status = "degraded"
+			}
+			breakers = append(breakers, st)
+		}
+	}
+	resp := gin.H{
+		"status":    status,
+		"breakers":  breakers,
+		"timestamp": time.Now().UTC(),
+	}
+	if s.degradedRepo != nil {
+		repoStatus := s.degradedRepo.Status()
+		if repoStatus.Degraded {
+			resp["status"] = "degraded"
+		}
+		resp["repository"] = repoStatus
+	}
+	if s.freshness != nil {
+		data, err := s.freshness.Status(c.Request.Context())
+		if err != nil {
+			// 读取失败不代表没有数据，已由存储降级状态体现
+			log.Printf("WARN: data freshness: %v", err)
+		} else {
+			resp["data"] = data
+			if !data.Synced {
+				resp["status"] = "not_ready"
+				c.JSON(http.StatusServiceUnavailable, resp)
+				return
+			}
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// metrics handles GET /metrics
+func (s *HTTPServer) metrics(c *gin.Context) {
+	if s.metricsHandler == nil {
+		writeNotConfigured(c, "metrics exporter")
+		return
+	}
+	s.metricsHandler.ServeHTTP(c.Writer, c.Request)
+}
+
+// admissionReview handles POST /admission/validate and /admission/mutate (AdmissionReview v1). Only
+// the mutating webhook patches the estimate onto the object.
+func (s *HTTPServer) admissionReview(mutate bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.admissionService == nil {
+			writeNotConfigured(c, "admission webhook")
+			return
+		}
+		var review dto.AdmissionReview
+		if err := c.ShouldBindJSON(&review); err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+			return
+		}
+		resp, err := s.admissionService.Review(c.Request.Context(), review, mutate)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
 }
 
 // healthCheck handles the health check endpoint.
@@ -122,11 +746,17 @@ func (s *HTTPServer) healthCheck(c *gin.Context) {
 }
 
 // globalCost handles GET /api/v1/cost/global
+// @Summary Global cost overview (L0 = sum of L1)
+// @Tags    Cost
+// @Produce json
+// @Success 200 {object} dto.GlobalCostResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/global [get]
 func (s *HTTPServer) globalCost(c *gin.Context) {
 	if s.costService != nil {
 		resp, err := s.costService.GetGlobalCost(c.Request.Context())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, resp)
@@ -144,30 +774,58 @@ func (s *HTTPServer) globalCost(c *gin.Context) {
 }
 
 // listNamespaces handles GET /api/v1/cost/namespaces
+// query: sort, order, limit (default all), offset or cursor; X-Total-Count is the number of namespaces before paging
+// @Summary Namespaces with cost summary
+// @Tags    Cost
+// @Produce json
+// @Param   sort query string false "sort field, default cost" Enums(cost,waste,efficiency,name)
+// @Param   order query string false "sort order, default desc" Enums(asc,desc)
+// @Param   limit query integer false "page size, max 500 (default all)"
+// @Param   offset query integer false "page offset"
+// @Param   cursor query string false "next_cursor of the previous page (instead of offset)"
+// @Success 200 {object} dto.NamespaceCostSummaryList
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/namespaces [get]
 func (s *HTTPServer) listNamespaces(c *gin.Context) {
 	if s.costService != nil {
-		list, err := s.costService.ListNamespaces(c.Request.Context())
+		q := listParamsOf(c)
+		list, total, err := s.costService.ListNamespaces(c.Request.Context(), q.PageOptions())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, list)
+		c.Header(HeaderTotalCount, strconv.Itoa(total))
+		c.JSON(http.StatusOK, dto.NamespaceCostSummaryList{Items: list, ListPage: q.listPage(len(list), total)})
 		return
 	}
-	c.JSON(http.StatusOK, []map[string]interface{}{
+	c.JSON(http.StatusOK, gin.H{"items": []map[string]interface{}{
 		{"name": "default", "cost": 5000.0, "grade": "Healthy", "pod_count": 10, "node_count": 0},
 		{"name": "kube-system", "cost": 3000.0, "grade": "Healthy", "pod_count": 5, "node_count": 0},
 		{"name": "monitoring", "cost": 2000.0, "grade": "Healthy", "pod_count": 3, "node_count": 0},
-	})
+	}, "total_estimate": 3})
 }
 
 // namespaceCost handles GET /api/v1/cost/namespace/:namespace
+// query: sort, order, limit (default 50), offset of the workloads
+// @Summary Cost breakdown of a namespace
+// @Tags    Cost
+// @Produce json
+// @Param   namespace path string true "namespace"
+// @Param   sort query string false "workload sort field, default cost" Enums(cost,waste,efficiency,name)
+// @Param   order query string false "workload sort order, default desc" Enums(asc,desc)
+// @Param   limit query integer false "workload page size, default 50, max 500"
+// @Param   offset query integer false "workload page offset"
+// @Success 200 {object} dto.NamespaceCostResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/namespace/{namespace} [get]
 func (s *HTTPServer) namespaceCost(c *gin.Context) {
 	namespace := c.Param("namespace")
 	if s.costService != nil {
-		resp, err := s.costService.GetNamespaceCost(c.Request.Context(), namespace)
+		resp, err := s.costService.GetNamespaceCost(c.Request.Context(), namespace, listParamsOf(c).PageOptions())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, resp)
@@ -181,6 +839,39 @@ func (s *HTTPServer) namespaceCost(c *gin.Context) {
 	})
 }
 
+// costSeries handles GET /api/v1/cost/series
+// query: start_time, end_time (RFC3339; default last 30 days), namespace, resolution (default planned by range)
+// @Summary Cost time series at a resolution planned from the range
+// @Tags    Cost
+// @Produce json
+// @Param   start_time query string false "range start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "range end (RFC3339)" Format(date-time)
+// @Param   namespace query string false "namespace, default all"
+// @Param   resolution query string false "granularity, default planned from the range (hourly up to 72h, daily up to 92 days, monthly beyond)" Enums(hourly,daily,monthly)
+// @Success 200 {object} dto.CostSeriesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/series [get]
+func (s *HTTPServer) costSeries(c *gin.Context) {
+	if s.costService == nil {
+		writeNotConfigured(c, "cost series")
+		return
+	}
+	window := listParamsOf(c)
+	resolution, err := costmodel.ParseResolution(c.Query("resolution"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := s.costService.CostSeries(c.Request.Context(), c.Query("namespace"), window.StartTime, window.EndTime, resolution)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // typeToLevel maps frontend type to backend level: namespace->L1, node->L2, workload->L3, pod->L4
 var typeToLevel = map[string]string{
 	"namespace": "L1", "node": "L2", "workload": "L3", "pod": "L4",
@@ -193,6 +884,14 @@ var levelToType = map[string]string{
 
 // drilldownCost handles GET /api/v1/cost/drilldown/:level/:identifier
 // level 接受 type (namespace/node/workload/pod) 或 L1/L2/L3/L4；query dimension=compute|storage|network，默认 compute
+// @Summary Cost drilldown by level
+// @Tags    Cost
+// @Produce json
+// @Param   level path string true "namespace/node/workload/pod or L1-L4"
+// @Param   identifier path string true "item id"
+// @Param   dimension query string false "cost dimension" Enums(compute,storage,network)
+// @Success 200 {object} object
+// @Router  /cost/drilldown/{level}/{identifier} [get]
 func (s *HTTPServer) drilldownCost(c *gin.Context) {
 	levelOrType := c.Param("level")
 	identifier := c.Param("identifier")
@@ -241,71 +940,3567 @@ func (s *HTTPServer) drilldownCost(c *gin.Context) {
 	})
 }
 
-// sloHealth handles GET /api/v1/slo/health - returns SLOStatus[] for frontend
-func (s *HTTPServer) sloHealth(c *gin.Context) {
-	// Mock SLO data matching frontend SLOStatus[] type
-	c.JSON(http.StatusOK, []gin.H{
-		{"serviceName": "api-gateway", "status": "healthy", "uptime": 99.95, "responseTime": 120, "errorRate": 0.01},
-		{"serviceName": "order-service", "status": "healthy", "uptime": 99.90, "responseTime": 85, "errorRate": 0.02},
-		{"serviceName": "payment-service", "status": "warning", "uptime": 99.50, "responseTime": 200, "errorRate": 0.15},
-	})
+// searchCatalog handles GET /api/v1/workloads/catalog
+// query: q (free text), namespace, team, grade, type, slo_status, limit (default 50), offset
+// @Summary Search the workload catalog
+// @Tags    Workload
+// @Produce json
+// @Param   q query string false "free text; every word must prefix-match the workload"
+// @Param   namespace query string false "namespace facet"
+// @Param   team query string false "owner team facet"
+// @Param   grade query string false "grade facet"
+// @Param   type query string false "workload type facet"
+// @Param   slo_status query string false "SLO status facet"
+// @Param   limit query integer false "page size, default 50, max 500"
+// @Param   offset query integer false "page offset"
+// @Success 200 {object} dto.CatalogSearchResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router  /workloads/catalog [get]
+func (s *HTTPServer) searchCatalog(c *gin.Context) {
+	if s.catalogService == nil {
+		writeNotConfigured(c, "workload catalog")
+		return
+	}
+	q := service.CatalogQuery{
+		Text:      c.Query("q"),
+		Namespace: c.Query("namespace"),
+		Team:      c.Query("team"),
+		Grade:     c.Query("grade"),
+		Type:      c.Query("type"),
+		SLOStatus: c.Query("slo_status"),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"limit", &q.Limit}, {"offset", &q.Offset}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid "+p.name, "VALIDATION_FAILED"))
+			return
+		}
+		*p.dst = n
+	}
+	resp, err := s.catalogService.Search(c.Request.Context(), q)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
-// roiDashboard handles GET /api/v1/roi/dashboard - returns summary + ROITrend[] for frontend
-func (s *HTTPServer) roiDashboard(c *gin.Context) {
-	// Mock ROI dashboard: summary (roi_percentage etc.) + trends array
-	trends := []gin.H{
-		{"date": "2025-01-15", "value": 1.2, "cost": 100000, "efficiency": 68},
-		{"date": "2025-01-22", "value": 1.35, "cost": 95000, "efficiency": 70},
-		{"date": "2025-02-01", "value": 1.45, "cost": 90000, "efficiency": 72},
-		{"date": "2025-02-15", "value": 1.5, "cost": 85000, "efficiency": 75},
+// workloadCost handles GET /api/v1/workloads/:namespace/:name/costs
+// query: start_time, end_time (RFC3339; default last 7 days)
+// @Summary Cost detail of a workload
+// @Tags    Workload
+// @Produce json
+// @Param   namespace path string true "namespace"
+// @Param   name path string true "workload name"
+// @Param   start_time query string false "window start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339)" Format(date-time)
+// @Success 200 {object} dto.WorkloadCostDetailResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /workloads/{namespace}/{name}/costs [get]
+func (s *HTTPServer) workloadCost(c *gin.Context) {
+	if s.workloadService == nil {
+		writeNotConfigured(c, "workload cost detail")
+		return
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"roi_percentage": 45.2,
-		"total_savings":  125000.0,
-		"status":         "good",
-		"trend":          "improving",
-		"trends":         trends,
-	})
+	window := listParamsOf(c)
+	resp, err := s.workloadService.GetWorkloadCost(c.Request.Context(), c.Param("namespace"), c.Param("name"), window.StartTime, window.EndTime)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
-// Start begins listening for HTTP requests.
-func (s *HTTPServer) Start() error {
-	addr := fmt.Sprintf(":%d", s.config.Server.Port)
-	s.server = &http.Server{
-		Addr:           addr,
-		Handler:        s.engine,
-		ReadTimeout:    s.config.Server.ReadTimeout,
-		WriteTimeout:   s.config.Server.WriteTimeout,
-		MaxHeaderBytes: 1 << 20, // 1MB
+// workloadHistory handles GET /api/v1/workloads/:namespace/:name/history
+// query: days (default 90, at most 366)
+// @Summary Daily cost, efficiency and grade series of a workload
+// @Tags    Workload
+// @Produce json
+// @Param   namespace path string true "namespace"
+// @Param   name path string true "workload name"
+// @Param   days query integer false "days of history including today, default 90, at most 366"
+// @Success 200 {object} dto.WorkloadHistoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /workloads/{namespace}/{name}/history [get]
+func (s *HTTPServer) workloadHistory(c *gin.Context) {
+	if s.workloadService == nil {
+		writeNotConfigured(c, "workload cost detail")
+		return
+	}
+	var days int
+	if v := c.Query("days"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid days", "VALIDATION_FAILED"))
+			return
+		}
+		days = d
+	}
+	resp, err := s.workloadService.GetWorkloadHistory(c.Request.Context(), c.Param("namespace"), c.Param("name"), days)
+	if err != nil {
+		writeError(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, resp)
+}
 
-	fmt.Printf("Starting HTTP server on %s\n", addr)
-	fmt.Printf("Environment: %s\n", s.config.Env)
-	if s.config.Env != config.EnvProduction {
-		fmt.Printf("Swagger UI: http://localhost%s/swagger/index.html\n", addr)
+// workloadImpact handles GET /api/v1/workloads/:namespace/:name/impact - the upstream services
+// depending on the workload and their spend
+// query: start_time, end_time (RFC3339; cost window, default last 7 days)
+// @Summary Upstream services and spend depending on a workload
+// @Tags    Workload
+// @Produce json
+// @Param   namespace path string true "namespace"
+// @Param   name path string true "workload (service) name"
+// @Param   start_time query string false "window start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339)" Format(date-time)
+// @Success 200 {object} dto.DependencyImpact
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /workloads/{namespace}/{name}/impact [get]
+func (s *HTTPServer) workloadImpact(c *gin.Context) {
+	svc := s.dependencyServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	window := listParamsOf(c)
+	resp, err := svc.Impact(c.Request.Context(), c.Param("namespace"), c.Param("name"), window.StartTime, window.EndTime)
+	if err != nil {
+		writeError(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, resp)
+}
 
-	return s.server.ListenAndServe()
+// nodeAnalysis handles GET /api/v1/nodes/analysis - per-node costs and consolidation candidates
+// query: target_utilization in (0, 1] (default 0.85)
+// @Summary Per-node costs and consolidation candidates
+// @Tags    Node
+// @Produce json
+// @Param   target_utilization query number false "target utilization in (0, 1], default 0.85"
+// @Success 200 {object} dto.NodeAnalysisResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /nodes/analysis [get]
+func (s *HTTPServer) nodeAnalysis(c *gin.Context) {
+	if s.nodeService == nil {
+		writeNotConfigured(c, "node analysis")
+		return
+	}
+	target, ok := bindTargetUtilization(c)
+	if !ok {
+		return
+	}
+	resp, err := s.nodeService.Analyze(c.Request.Context(), target)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
-// StartWithGracefulShutdown starts the server with graceful shutdown handling.
-func (s *HTTPServer) StartWithGracefulShutdown() error {
-	// Start server in a goroutine
-	serverErr := make(chan error, 1)
-	go func() {
-		if err := s.Start(); err != nil && err != http.ErrServerClosed {
-			serverErr <- err
-		}
-	}()
+// nodeConsolidationPlan handles GET /api/v1/nodes/consolidation-plan - the consolidation candidates
+// as a drain order checked against PodDisruptionBudgets, with per-step savings, risk and commands
+// query: target_utilization in (0, 1] (default 0.85)
+// @Summary Reviewable node drain plan with savings and risk
+// @Tags    Node
+// @Produce json
+// @Param   target_utilization query number false "target utilization in (0, 1], default 0.85"
+// @Success 200 {object} dto.NodeConsolidationPlan
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /nodes/consolidation-plan [get]
+func (s *HTTPServer) nodeConsolidationPlan(c *gin.Context) {
+	if s.nodeService == nil {
+		writeNotConfigured(c, "node analysis")
+		return
+	}
+	target, ok := bindTargetUtilization(c)
+	if !ok {
+		return
+	}
+	resp, err := s.nodeService.Plan(c.Request.Context(), target)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
 
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+// bindTargetUtilization parses the target_utilization query parameter (0 when absent); on error it
+// writes 400 and returns false.
+func bindTargetUtilization(c *gin.Context) (float64, bool) {
+	v := c.Query("target_utilization")
+	if v == "" {
+		return 0, true
+	}
+	target, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, "invalid target_utilization", "VALIDATION_FAILED"))
+		return 0, false
+	}
+	return target, true
+}
 
-	select {
-	case err := <-serverErr:
-		return err
+// nodePoolAnalysis handles GET /api/v1/nodes/pools - capacity and workload cost per node pool
+// query: start_time, end_time (RFC3339, default the last 24 hours)
+// @Summary Capacity and workload cost per node pool
+// @Tags    Node
+// @Produce json
+// @Param   start_time query string false "window start (RFC3339), default end_time - 24h"
+// @Param   end_time query string false "window end (RFC3339), default now"
+// @Success 200 {object} dto.NodePoolAnalysisResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /nodes/pools [get]
+func (s *HTTPServer) nodePoolAnalysis(c *gin.Context) {
+	if s.nodeService == nil {
+		writeNotConfigured(c, "node analysis")
+		return
+	}
+	q := listParamsOf(c)
+	resp, err := s.nodeService.Pools(c.Request.Context(), q.StartTime, q.EndTime)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// capacityProjection handles GET /api/v1/capacity/projection
+// query: horizon_days (default 90), target_utilization (default 0.85), node_delta, pool (default: largest pool)
+// @Summary Capacity projection of a node pool
+// @Tags    Capacity
+// @Produce json
+// @Param   horizon_days query integer false "projection horizon, default 90"
+// @Param   target_utilization query number false "target utilization, default 0.85"
+// @Param   node_delta query integer false "nodes added (negative: removed) in the scenario"
+// @Param   pool query string false "node pool, default the largest"
+// @Success 200 {object} dto.CapacityProjectionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /capacity/projection [get]
+func (s *HTTPServer) capacityProjection(c *gin.Context) {
+	if s.capacityService == nil {
+		writeNotConfigured(c, "capacity planning")
+		return
+	}
+	q := service.CapacityProjectionQuery{Pool: c.Query("pool")}
+	var err error
+	if v := c.Query("horizon_days"); v != "" {
+		if q.HorizonDays, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid horizon_days", "VALIDATION_FAILED"))
+			return
+		}
+	}
+	if v := c.Query("node_delta"); v != "" {
+		if q.NodeDelta, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid node_delta", "VALIDATION_FAILED"))
+			return
+		}
+	}
+	if v := c.Query("target_utilization"); v != "" {
+		if q.TargetUtilization, err = strconv.ParseFloat(v, 64); err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid target_utilization", "VALIDATION_FAILED"))
+			return
+		}
+	}
+	resp, err := s.capacityService.Project(c.Request.Context(), q)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// capacitySimulation handles GET /api/v1/capacity/simulation
+// query: request_change_percent (e.g. -20), target_utilization (default 0.85), pool (default: all pools)
+// @Summary Autoscaler simulation of a pod request change
+// @Tags    Capacity
+// @Produce json
+// @Param   request_change_percent query number false "change of every pod's requests in %, e.g. -20"
+// @Param   target_utilization query number false "target utilization, default 0.85"
+// @Param   pool query string false "node pool, default all pools"
+// @Success 200 {object} dto.CapacitySimulationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router  /capacity/simulation [get]
+func (s *HTTPServer) capacitySimulation(c *gin.Context) {
+	if s.capacityService == nil {
+		writeNotConfigured(c, "capacity planning")
+		return
+	}
+	q := service.CapacitySimulationQuery{Pool: c.Query("pool")}
+	var err error
+	if v := c.Query("request_change_percent"); v != "" {
+		if q.RequestChangePercent, err = strconv.ParseFloat(v, 64); err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid request_change_percent", "VALIDATION_FAILED"))
+			return
+		}
+	}
+	if v := c.Query("target_utilization"); v != "" {
+		if q.TargetUtilization, err = strconv.ParseFloat(v, 64); err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid target_utilization", "VALIDATION_FAILED"))
+			return
+		}
+	}
+	resp, err := s.capacityService.Simulate(c.Request.Context(), q)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// offHoursAnalysis handles GET /api/v1/analysis/offhours
+// query: days (default 7), namespace, limit (default: all workloads)
+// @Summary Off-hours (nights/weekends) idle cost and scaling candidates
+// @Tags    Analysis
+// @Produce json
+// @Param   days query integer false "window length, default 7"
+// @Param   namespace query string false "namespace"
+// @Param   limit query integer false "workloads with the highest idle off-hours cost to keep"
+// @Success 200 {object} dto.OffHoursAnalysisResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /analysis/offhours [get]
+func (s *HTTPServer) offHoursAnalysis(c *gin.Context) {
+	if s.offHoursService == nil {
+		writeNotConfigured(c, "off-hours analysis")
+		return
+	}
+	q := service.OffHoursQuery{Namespace: c.Query("namespace"), Limit: listParamsOf(c).Limit}
+	var err error
+	if v := c.Query("days"); v != "" {
+		if q.Days, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid days", "VALIDATION_FAILED"))
+			return
+		}
+	}
+	resp, err := s.offHoursService.Analyze(c.Request.Context(), q)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// pricingServiceOrAbort writes 404 and returns nil when price history is not configured.
+func (s *HTTPServer) pricingServiceOrAbort(c *gin.Context) *service.PricingService {
+	if s.pricingService == nil {
+		writeNotConfigured(c, "price history")
+	}
+	return s.pricingService
+}
+
+// getPriceHistory handles GET /api/v1/pricing/history
+// @Summary Unit price history
+// @Tags    Pricing
+// @Produce json
+// @Success 200 {object} dto.PriceHistoryResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /pricing/history [get]
+func (s *HTTPServer) getPriceHistory(c *gin.Context) {
+	svc := s.pricingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.History(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// setPrice handles PUT /api/v1/pricing/history (add a version or correct an existing effective_from)
+// @Summary Add or correct a unit price version
+// @Tags    Pricing
+// @Accept  json
+// @Produce json
+// @Param   request body dto.SetPriceRequest true "price version"
+// @Success 200 {object} dto.PriceChangeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /pricing/history [put]
+func (s *HTTPServer) setPrice(c *gin.Context) {
+	svc := s.pricingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.SetPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.SetPrice(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// recalculatePrices handles POST /api/v1/pricing/recalculate (re-price a window with the price history)
+// @Summary Re-price a window with the price history
+// @Tags    Pricing
+// @Accept  json
+// @Produce json
+// @Param   request body dto.TriggerCalculationRequest true "window"
+// @Success 201 {object} dto.CalculationRun
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /pricing/recalculate [post]
+func (s *HTTPServer) recalculatePrices(c *gin.Context) {
+	svc := s.pricingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.TriggerCalculationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	run, err := svc.Recalculate(c.Request.Context(), req.WindowStart, req.WindowEnd)
+	writeCalculationRunResult(c, run, err)
+}
+
+// listPriceChanges handles GET /api/v1/pricing/changes
+// @Summary List price changes
+// @Tags    Pricing
+// @Produce json
+// @Param   status query string false "draft, approved, effective or rejected"
+// @Success 200 {object} dto.PriceChangeListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /pricing/changes [get]
+func (s *HTTPServer) listPriceChanges(c *gin.Context) {
+	svc := s.pricingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.ListChanges(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// proposePriceChange handles POST /api/v1/pricing/changes (draft a price change for approval)
+// @Summary Propose a unit price change
+// @Tags    Pricing
+// @Accept  json
+// @Produce json
+// @Param   request body dto.SetPriceRequest true "proposed price version"
+// @Success 201 {object} dto.PriceChange
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /pricing/changes [post]
+func (s *HTTPServer) proposePriceChange(c *gin.Context) {
+	svc := s.pricingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.SetPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	change, err := svc.ProposeChange(c.Request.Context(), req, c.GetString("serviceAccount"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, change)
+}
+
+// getPriceChange handles GET /api/v1/pricing/changes/:id
+// @Summary Get a price change with its history
+// @Tags    Pricing
+// @Produce json
+// @Param   id path string true "price change id"
+// @Success 200 {object} dto.PriceChange
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /pricing/changes/{id} [get]
+func (s *HTTPServer) getPriceChange(c *gin.Context) {
+	svc := s.pricingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	change, err := svc.GetChange(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, change)
+}
+
+// approvePriceChange handles POST /api/v1/pricing/changes/:id/approve (needs an API key with the
+// pricing:approve scope; the service account of the key is the reviewer)
+// @Summary Approve a price change and write it to the price history
+// @Tags    Pricing
+// @Accept  json
+// @Produce json
+// @Param   id      path string                       true  "price change id"
+// @Param   request body dto.ReviewPriceChangeRequest false "review comment"
+// @Success 200 {object} dto.PriceChangeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /pricing/changes/{id}/approve [post]
+func (s *HTTPServer) approvePriceChange(c *gin.Context) {
+	svc := s.pricingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.ReviewPriceChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	reviewer := c.GetString("serviceAccount")
+	if reviewer == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, "reviewing a price change needs an api key with the pricing:approve scope", "UNAUTHENTICATED"))
+		return
+	}
+	resp, err := svc.ApproveChange(c.Request.Context(), c.Param("id"), reviewer, req.Comment)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// rejectPriceChange handles POST /api/v1/pricing/changes/:id/reject (needs an API key with the
+// pricing:approve scope; the service account of the key is the reviewer)
+// @Summary Reject a price change
+// @Tags    Pricing
+// @Accept  json
+// @Produce json
+// @Param   id      path string                       true  "price change id"
+// @Param   request body dto.ReviewPriceChangeRequest false "review comment"
+// @Success 200 {object} dto.PriceChange
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /pricing/changes/{id}/reject [post]
+func (s *HTTPServer) rejectPriceChange(c *gin.Context) {
+	svc := s.pricingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.ReviewPriceChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	reviewer := c.GetString("serviceAccount")
+	if reviewer == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, "reviewing a price change needs an api key with the pricing:approve scope", "UNAUTHENTICATED"))
+		return
+	}
+	change, err := svc.RejectChange(c.Request.Context(), c.Param("id"), reviewer, req.Comment)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, change)
+}
+
+// integrityServiceOrAbort writes 404 and returns nil when snapshot integrity is not configured.
+func (s *HTTPServer) integrityServiceOrAbort(c *gin.Context) *service.SnapshotIntegrityService {
+	if s.integrityService == nil {
+		writeNotConfigured(c, "snapshot integrity")
+	}
+	return s.integrityService
+}
+
+// verifySnapshots handles GET /api/v1/snapshots/verify?snapshot_id= (all snapshots without snapshot_id).
+// Tampering is reported in the body (verified=false), not as an error status.
+// @Summary Verify cost snapshots against the hash chain
+// @Tags    Snapshot
+// @Produce json
+// @Param   snapshot_id query string false "only this snapshot"
+// @Success 200 {object} dto.SnapshotVerificationResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /snapshots/verify [get]
+func (s *HTTPServer) verifySnapshots(c *gin.Context) {
+	svc := s.integrityServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.Verify(c.Request.Context(), c.Query("snapshot_id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// exportSnapshots handles GET /api/v1/snapshots/export?start_time=&end_time= - snapshots with content
+// hashes; 409 with the verification when any exported snapshot fails it.
+// @Summary Export verified cost snapshots
+// @Tags    Snapshot
+// @Produce json
+// @Param   start_time query string false "window start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339)" Format(date-time)
+// @Success 200 {object} dto.SnapshotExportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /snapshots/export [get]
+func (s *HTTPServer) exportSnapshots(c *gin.Context) {
+	svc := s.integrityServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	q := listParamsOf(c)
+	resp, verification, err := svc.Export(c.Request.Context(), q.StartTime, q.EndTime)
+	switch {
+	case errors.Is(err, service.ErrSnapshotIntegrity):
+		body := errorBody(c, err.Error(), "INTEGRITY_CHECK_FAILED")
+		body["verification"] = verification
+		c.JSON(http.StatusConflict, body)
+	case err != nil:
+		writeError(c, err)
+	default:
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// guardrailOrAbort writes 404 and returns nil when the snapshot guardrail is not configured.
+func (s *HTTPServer) guardrailOrAbort(c *gin.Context) *service.SnapshotGuardrail {
+	if s.guardrail == nil {
+		writeNotConfigured(c, "snapshot guardrail")
+	}
+	return s.guardrail
+}
+
+// listSuspectSnapshots handles GET /api/v1/snapshots/suspect - snapshots flagged by the rate-of-change guardrail
+// @Summary List suspect cost snapshots
+// @Tags    Snapshot
+// @Produce json
+// @Success 200 {object} dto.SuspectSnapshotListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /snapshots/suspect [get]
+func (s *HTTPServer) listSuspectSnapshots(c *gin.Context) {
+	svc := s.guardrailOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.ListSuspect(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// confirmSnapshot handles POST /api/v1/snapshots/:id/confirm - releases a suspect snapshot held for confirmation
+// @Summary Confirm a suspect cost snapshot
+// @Tags    Snapshot
+// @Accept  json
+// @Produce json
+// @Param   id path string true "snapshot id"
+// @Param   request body dto.ConfirmSnapshotRequest true "confirmation"
+// @Success 200 {object} dto.SuspectSnapshot
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /snapshots/{id}/confirm [post]
+func (s *HTTPServer) confirmSnapshot(c *gin.Context) {
+	svc := s.guardrailOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.ConfirmSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Confirm(c.Request.Context(), c.Param("id"), req.ConfirmedBy)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// regradeServiceOrAbort writes 404 and returns nil when regrading is not configured.
+func (s *HTTPServer) regradeServiceOrAbort(c *gin.Context) *service.RegradeService {
+	if s.regradeService == nil {
+		writeNotConfigured(c, "snapshot regrade")
+	}
+	return s.regradeService
+}
+
+// snapshotGrades handles GET /api/v1/snapshots/grades?start_time=&end_time=&view= - grade counts of
+// the snapshots in the view currently served (or in view, when given)
+// @Summary Grade counts of cost snapshots
+// @Tags    Snapshot
+// @Produce json
+// @Param   start_time query string false "window start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339)" Format(date-time)
+// @Param   view query string false "original / regraded, default the view currently served"
+// @Success 200 {object} dto.SnapshotGradesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /snapshots/grades [get]
+func (s *HTTPServer) snapshotGrades(c *gin.Context) {
+	svc := s.regradeServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	q := listParamsOf(c)
+	resp, err := svc.SnapshotGrades(c.Request.Context(), q.StartTime, q.EndTime, c.Query("view"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// costServiceOrAbort writes 404 and returns nil when no cost service is configured.
+func (s *HTTPServer) costServiceOrAbort(c *gin.Context) *service.CostService {
+	if s.costService == nil {
+		writeNotConfigured(c, "cost service")
+	}
+	return s.costService
+}
+
+// listSnapshots handles GET /api/v1/snapshots - cost snapshots with field selection, newest first
+// query: start_time, end_time (RFC3339), limit, offset, fields (default summary), include
+// @Summary List cost snapshots
+// @Tags    Snapshot
+// @Produce json
+// @Param   start_time query string false "window start (RFC3339)"
+// @Param   end_time query string false "window end (RFC3339)"
+// @Param   limit query int false "max snapshots"
+// @Param   offset query int false "snapshots to skip"
+// @Param   fields query string false "full, summary (default) or counts"
+// @Param   include query string false "comma-separated aggregated_results, resource_results"
+// @Success 200 {object} dto.CostSnapshotListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /snapshots [get]
+func (s *HTTPServer) listSnapshots(c *gin.Context) {
+	svc := s.costServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	q := listParamsOf(c)
+	fields, err := service.ParseSnapshotFields(c.Query("fields"), c.Query("include"), postgres.SnapshotFieldCounts|postgres.SnapshotFieldSummary)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp, err := svc.ListSnapshots(c.Request.Context(), service.SnapshotQuery{
+		StartTime: q.StartTime, EndTime: q.EndTime, Limit: q.Limit, Offset: q.Offset, Fields: fields,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// getSnapshot handles GET /api/v1/snapshots/:id - query: fields (default full), include
+// @Summary A cost snapshot with field selection
+// @Tags    Snapshot
+// @Produce json
+// @Param   id path string true "snapshot id"
+// @Param   fields query string false "full (default), summary or counts"
+// @Param   include query string false "comma-separated aggregated_results, resource_results"
+// @Success 200 {object} dto.CostSnapshot
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /snapshots/{id} [get]
+func (s *HTTPServer) getSnapshot(c *gin.Context) {
+	svc := s.costServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	fields, err := service.ParseSnapshotFields(c.Query("fields"), c.Query("include"), postgres.SnapshotFieldsAll)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp, err := svc.GetSnapshot(c.Request.Context(), c.Param("id"), fields)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// sloHealth handles GET /api/v1/slo/health - returns the SLOStatus items of the frontend
+// query: limit (default all), offset or cursor
+// @Summary SLO health of services
+// @Tags    SLO
+// @Produce json
+// @Param   limit query integer false "page size, max 500 (default all)"
+// @Param   offset query integer false "page offset"
+// @Param   cursor query string false "next_cursor of the previous page (instead of offset)"
+// @Success 200 {object} dto.SLOHealthList
+// @Failure 400 {object} dto.ErrorResponse
+// @Router  /slo/health [get]
+func (s *HTTPServer) sloHealth(c *gin.Context) {
+	// Mock SLO data matching frontend SLOStatus[] type
+	items, page := pageOf([]dto.SLOHealth{
+		{ServiceName: "api-gateway", Status: "healthy", Uptime: 99.95, ResponseTime: 120, ErrorRate: 0.01},
+		{ServiceName: "order-service", Status: "healthy", Uptime: 99.90, ResponseTime: 85, ErrorRate: 0.02},
+		{ServiceName: "payment-service", Status: "warning", Uptime: 99.50, ResponseTime: 200, ErrorRate: 0.15},
+	}, listParamsOf(c))
+	c.JSON(http.StatusOK, dto.SLOHealthList{Items: items, ListPage: page})
+}
+
+// sloCost handles GET /api/v1/slo/cost - what the availability target of each service costs in capacity
+// query: start_time, end_time (RFC3339, default the last 7 days)
+// @Summary Cost of SLO compliance per service
+// @Tags    SLO
+// @Produce json
+// @Param   start_time query string false "window start (RFC3339), default end_time - 7d"
+// @Param   end_time query string false "window end (RFC3339), default now"
+// @Success 200 {object} dto.SLOCostResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /slo/cost [get]
+func (s *HTTPServer) sloCost(c *gin.Context) {
+	if s.sloCostService == nil {
+		writeNotConfigured(c, "SLO cost analysis")
+		return
+	}
+	q := listParamsOf(c)
+	resp, err := s.sloCostService.Analyze(c.Request.Context(), q.StartTime, q.EndTime)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// selfSLO handles GET /api/v1/slo/self - availability and P95 latency SLOs of Lighthouse's own API
+// routes, evaluated from the request metrics over the configured window
+// @Summary SLOs of Lighthouse's own API routes
+// @Tags    SLO
+// @Produce json
+// @Success 200 {object} dto.SelfSLOResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /slo/self [get]
+func (s *HTTPServer) selfSLO(c *gin.Context) {
+	if s.selfSLOService == nil {
+		writeNotConfigured(c, "self SLOs")
+		return
+	}
+	resp, err := s.selfSLOService.Evaluate(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// roiDashboard handles GET /api/v1/roi/dashboard - returns summary + ROITrend[] for frontend; with
+// recommendation adoption configured also the adoption of issued recommendations and their unrealized savings
+// @Summary ROI summary and trends
+// @Tags    ROI
+// @Produce json
+// @Success 200 {object} object
+// @Router  /roi/dashboard [get]
+func (s *HTTPServer) roiDashboard(c *gin.Context) {
+	// Mock ROI dashboard: summary (roi_percentage etc.) + trends array
+	trends := []gin.H{
+		{"date": "2025-01-15", "value": 1.2, "cost": 100000, "efficiency": 68},
+		{"date": "2025-01-22", "value": 1.35, "cost": 95000, "efficiency": 70},
+		{"date": "2025-02-01", "value": 1.45, "cost": 90000, "efficiency": 72},
+		{"date": "2025-02-15", "value": 1.5, "cost": 85000, "efficiency": 75},
+	}
+	resp := gin.H{
+		"roi_percentage": 45.2,
+		"total_savings":  125000.0,
+		"status":         "good",
+		"trend":          "improving",
+		"trends":         trends,
+	}
+	if s.adoptionService != nil {
+		adoption, err := s.adoptionService.Adoption(c.Request.Context(), time.Time{})
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		resp["recommendation_adoption"] = adoption.Total
+		resp["unrealized_savings"] = adoption.Total.UnrealizedMonthlySavings
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// recommendationAdoption handles GET /api/v1/roi/recommendations/adoption
+// query: since (RFC3339, default 90 days ago)
+// @Summary Adoption rate of issued recommendations per team and their unrealized savings
+// @Tags    ROI
+// @Produce json
+// @Param   since query string false "issued since (RFC3339), default 90 days ago"
+// @Success 200 {object} dto.RecommendationAdoptionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /roi/recommendations/adoption [get]
+func (s *HTTPServer) recommendationAdoption(c *gin.Context) {
+	if s.adoptionService == nil {
+		writeNotConfigured(c, "recommendation adoption")
+		return
+	}
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid since, expected RFC3339", "VALIDATION_FAILED"))
+			return
+		}
+		since = t
+	}
+	resp, err := s.adoptionService.Adoption(c.Request.Context(), since)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// exportRecommendations handles POST /api/v1/roi/recommendations/export - the patch manifests of the
+// requested recommendations as a zip bundle (X-Bundle-ID names it); each recommendation is recorded
+// as an optimization awaiting verification.
+// @Summary Export accepted recommendations as Kubernetes patch manifests
+// @Tags    ROI
+// @Accept  json
+// @Produce application/zip
+// @Param   request body dto.ExportRecommendationsRequest true "recommendations to export"
+// @Success 200 "the manifest bundle (Content-Disposition names it)"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /roi/recommendations/export [post]
+func (s *HTTPServer) exportRecommendations(c *gin.Context) {
+	if s.manifestExport == nil {
+		writeNotConfigured(c, "recommendation export")
+		return
+	}
+	var req dto.ExportRecommendationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	bundle, err := s.manifestExport.Export(c.Request.Context(), req, c.GetString("serviceAccount"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bundle.FileName))
+	c.Header("X-Bundle-ID", bundle.ID)
+	c.Data(http.StatusOK, "application/zip", bundle.Data)
+}
+
+// listOptimizationRecords handles GET /api/v1/roi/optimizations
+// query: bundle_id, status (pending / verified, default both), limit (default all), offset or cursor
+// @Summary Optimization tracking records, e.g. of exported recommendations awaiting verification
+// @Tags    ROI
+// @Produce json
+// @Param   bundle_id query string false "manifest bundle ID (X-Bundle-ID of the export)"
+// @Param   status query string false "pending or verified"
+// @Param   limit query integer false "page size, max 500 (default all)"
+// @Param   offset query integer false "page offset"
+// @Param   cursor query string false "next_cursor of the previous page (instead of offset)"
+// @Success 200 {object} dto.OptimizationRecordListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /roi/optimizations [get]
+func (s *HTTPServer) listOptimizationRecords(c *gin.Context) {
+	if s.manifestExport == nil {
+		writeNotConfigured(c, "recommendation export")
+		return
+	}
+	resp, err := s.manifestExport.ListRecords(c.Request.Context(), c.Query("bundle_id"), c.Query("status"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp.Items, resp.ListPage = pageOf(resp.Items, listParamsOf(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// compareROIBaseline handles GET /api/v1/roi/baselines/:id/compare
+// query: date (YYYY-MM-DD, default yesterday)
+// @Summary Compare a day against any stored ROI baseline
+// @Tags    ROI
+// @Produce json
+// @Param   id path string true "ROI baseline ID"
+// @Param   date query string false "compared day (YYYY-MM-DD), default yesterday"
+// @Success 200 {object} dto.ROIBaselineComparisonResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /roi/baselines/{id}/compare [get]
+func (s *HTTPServer) compareROIBaseline(c *gin.Context) {
+	if s.roiComparison == nil {
+		writeNotConfigured(c, "ROI baseline comparison")
+		return
+	}
+	var date time.Time
+	if v := c.Query("date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid date, expected YYYY-MM-DD", "VALIDATION_FAILED"))
+			return
+		}
+		date = d
+	}
+	resp, err := s.roiComparison.Compare(c.Request.Context(), c.Param("id"), date)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// grafanaTestConnection handles GET /api/v1/grafana
+// @Summary Grafana datasource test connection
+// @Tags    Grafana
+// @Produce json
+// @Success 200 {object} object
+// @Router  /grafana [get]
+func (s *HTTPServer) grafanaTestConnection(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// grafanaSearch handles POST /api/v1/grafana/search
+// @Summary Grafana metric search
+// @Tags    Grafana
+// @Accept  json
+// @Produce json
+// @Param   request body dto.GrafanaSearchRequest false "search"
+// @Success 200 {array} string
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /grafana/search [post]
+func (s *HTTPServer) grafanaSearch(c *gin.Context) {
+	var req dto.GrafanaSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	if s.grafanaService == nil {
+		c.JSON(http.StatusOK, []string{})
+		return
+	}
+	targets, err := s.grafanaService.Search(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, targets)
+}
+
+// grafanaQuery handles POST /api/v1/grafana/query
+// @Summary Grafana time series / table query
+// @Tags    Grafana
+// @Accept  json
+// @Produce json
+// @Param   request body dto.GrafanaQueryRequest true "query"
+// @Success 200 {array} object
+// @Failure 400 {object} dto.ErrorResponse
+// @Router  /grafana/query [post]
+func (s *HTTPServer) grafanaQuery(c *gin.Context) {
+	var req dto.GrafanaQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	if s.grafanaService == nil {
+		c.JSON(http.StatusOK, []interface{}{})
+		return
+	}
+	results, err := s.grafanaService.Query(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// grafanaAnnotations handles POST /api/v1/grafana/annotations
+// @Summary Grafana annotations
+// @Tags    Grafana
+// @Accept  json
+// @Produce json
+// @Param   request body dto.GrafanaAnnotationRequest true "annotation query"
+// @Success 200 {array} dto.GrafanaAnnotation
+// @Failure 400 {object} dto.ErrorResponse
+// @Router  /grafana/annotations [post]
+func (s *HTTPServer) grafanaAnnotations(c *gin.Context) {
+	var req dto.GrafanaAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	if s.grafanaService == nil {
+		c.JSON(http.StatusOK, []dto.GrafanaAnnotation{})
+		return
+	}
+	annotations, err := s.grafanaService.Annotations(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	c.JSON(http.StatusOK, annotations)
+}
+
+// calculationServiceOrAbort writes 404 and returns nil when run tracking is not configured.
+func (s *HTTPServer) calculationServiceOrAbort(c *gin.Context) *service.CalculationRunService {
+	if s.calculationService == nil {
+		writeNotConfigured(c, "calculation run tracking")
+	}
+	return s.calculationService
+}
+
+// listCalculationRuns handles GET /api/v1/calculations/runs
+// query: status, trigger, start_time, end_time (RFC3339), limit, offset
+// @Summary Cost calculation runs
+// @Tags    Calculation
+// @Produce json
+// @Param   status query string false "run status"
+// @Param   trigger query string false "run trigger"
+// @Param   start_time query string false "window start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339)" Format(date-time)
+// @Param   limit query integer false "page size"
+// @Param   offset query integer false "page offset"
+// @Success 200 {object} dto.CalculationRunListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /calculations/runs [get]
+func (s *HTTPServer) listCalculationRuns(c *gin.Context) {
+	svc := s.calculationServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	page := listParamsOf(c)
+	filter := postgres.CalculationRunFilter{
+		Status:    c.Query("status"),
+		Trigger:   c.Query("trigger"),
+		StartTime: page.StartTime,
+		EndTime:   page.EndTime,
+		Limit:     page.Limit,
+		Offset:    page.Offset,
+	}
+	resp, err := svc.ListRuns(c.Request.Context(), filter)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HeaderTotalCount carries the number of results before paging of list endpoints returning an array.
+const HeaderTotalCount = "X-Total-Count"
+
+// defaultWorkloadPageLimit is the page size of workload lists when the request sets no limit.
+const defaultWorkloadPageLimit = 50
+
+// getCalculationRun handles GET /api/v1/calculations/runs/:id
+// @Summary A cost calculation run
+// @Tags    Calculation
+// @Produce json
+// @Param   id path string true "run id"
+// @Success 200 {object} dto.CalculationRun
+// @Failure 404 {object} dto.ErrorResponse
+// @Router  /calculations/runs/{id} [get]
+func (s *HTTPServer) getCalculationRun(c *gin.Context) {
+	svc := s.calculationServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	run, err := svc.GetRun(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// triggerCalculationRun handles POST /api/v1/calculations/runs (manual run of a window)
+// @Summary Run the cost pipeline for a window
+// @Tags    Calculation
+// @Accept  json
+// @Produce json
+// @Param   request body dto.TriggerCalculationRequest true "window"
+// @Success 201 {object} dto.CalculationRun
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /calculations/runs [post]
+func (s *HTTPServer) triggerCalculationRun(c *gin.Context) {
+	svc := s.calculationServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.TriggerCalculationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	run, err := svc.Execute(c.Request.Context(), service.TriggerManual, req.WindowStart, req.WindowEnd)
+	writeCalculationRunResult(c, run, err)
+}
+
+// retriggerCalculationRun handles POST /api/v1/calculations/runs/:id/retrigger
+// @Summary Re-run a failed calculation run
+// @Tags    Calculation
+// @Produce json
+// @Param   id path string true "run id"
+// @Success 201 {object} dto.CalculationRun
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /calculations/runs/{id}/retrigger [post]
+func (s *HTTPServer) retriggerCalculationRun(c *gin.Context) {
+	svc := s.calculationServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	run, err := svc.Retrigger(c.Request.Context(), c.Param("id"))
+	writeCalculationRunResult(c, run, err)
+}
+
+// writeCalculationRunResult 执行失败但已记录的 run 仍返回 run 本身（status=failed），便于调用方拿到 ID 后续重跑。
+func writeCalculationRunResult(c *gin.Context, run *dto.CalculationRun, err error) {
+	switch {
+	case run != nil:
+		c.JSON(http.StatusCreated, run)
+	default:
+		writeError(c, err)
+	}
+}
+
+// targetServiceOrAbort writes 404 and returns nil when efficiency targets are not configured.
+func (s *HTTPServer) targetServiceOrAbort(c *gin.Context) *service.EfficiencyTargetService {
+	if s.targetService == nil {
+		writeNotConfigured(c, "efficiency targets")
+	}
+	return s.targetService
+}
+
+// listEfficiencyTargets handles GET /api/v1/cost/targets
+// query: limit (default all), offset or cursor
+// @Summary List namespace efficiency targets
+// @Tags    Cost
+// @Produce json
+// @Param   limit query integer false "page size, max 500 (default all)"
+// @Param   offset query integer false "page offset"
+// @Param   cursor query string false "next_cursor of the previous page (instead of offset)"
+// @Success 200 {object} dto.EfficiencyTargetListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/targets [get]
+func (s *HTTPServer) listEfficiencyTargets(c *gin.Context) {
+	svc := s.targetServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.ListTargets(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp.Items, resp.ListPage = pageOf(resp.Items, listParamsOf(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// setEfficiencyTarget handles PUT /api/v1/cost/targets/:namespace
+// @Summary Set the efficiency target of a namespace
+// @Tags    Cost
+// @Accept  json
+// @Produce json
+// @Param   namespace path string true "namespace"
+// @Param   request body dto.SetEfficiencyTargetRequest true "target"
+// @Success 200 {object} dto.EfficiencyTarget
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/targets/{namespace} [put]
+func (s *HTTPServer) setEfficiencyTarget(c *gin.Context) {
+	svc := s.targetServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.SetEfficiencyTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.SetTarget(c.Request.Context(), c.Param("namespace"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// trackEfficiencyTarget handles GET /api/v1/cost/targets/:namespace/tracking?start_time=&end_time=
+// (default: last 30 days) - daily efficiency vs target, attainment rate and streaks
+// @Summary Track a namespace's efficiency against its target
+// @Tags    Cost
+// @Produce json
+// @Param   namespace path string true "namespace"
+// @Param   start_time query string false "window start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339)" Format(date-time)
+// @Success 200 {object} dto.EfficiencyTargetTrackingResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/targets/{namespace}/tracking [get]
+func (s *HTTPServer) trackEfficiencyTarget(c *gin.Context) {
+	svc := s.targetServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	q := listParamsOf(c)
+	resp, err := svc.Track(c.Request.Context(), c.Param("namespace"), q.StartTime, q.EndTime)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// efficiencyHeatmap handles GET /api/v1/cost/efficiency/heatmap
+// query: days (default 30), bucket_days (default 1), limit (default: all namespaces)
+// @Summary Efficiency heatmap of namespaces by day
+// @Tags    Cost
+// @Produce json
+// @Param   days query integer false "window length ending today, default 30"
+// @Param   bucket_days query integer false "days per bucket, default 1"
+// @Param   limit query integer false "namespaces with the highest billable cost to keep"
+// @Success 200 {object} dto.EfficiencyHeatmapResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/efficiency/heatmap [get]
+func (s *HTTPServer) efficiencyHeatmap(c *gin.Context) {
+	svc := s.targetServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var q service.EfficiencyHeatmapQuery
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"days", &q.Days}, {"bucket_days", &q.BucketDays}, {"limit", &q.Limit}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid "+p.name, "VALIDATION_FAILED"))
+			return
+		}
+		*p.dst = n
+	}
+	resp, err := svc.Heatmap(c.Request.Context(), q)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// fiscalReport handles GET /api/v1/cost/fiscal-report
+// query: unit (period / quarter / year, default period), date (YYYY-MM-DD, default today)
+// @Summary Cost of a fiscal period, quarter or year to date
+// @Tags    Cost
+// @Produce json
+// @Param   unit query string false "period, quarter or year; default period"
+// @Param   date query string false "a day of the span (YYYY-MM-DD), default today"
+// @Success 200 {object} dto.FiscalReportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/fiscal-report [get]
+func (s *HTTPServer) fiscalReport(c *gin.Context) {
+	if s.fiscalService == nil {
+		writeNotConfigured(c, "fiscal report")
+		return
+	}
+	var date time.Time
+	if v := c.Query("date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid date, expected YYYY-MM-DD", "VALIDATION_FAILED"))
+			return
+		}
+		date = d
+	}
+	resp, err := s.fiscalService.Report(c.Request.Context(), costmodel.FiscalUnit(c.Query("unit")), date)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// previewAllocation handles POST /api/v1/cost/allocation/preview (candidate shared cost rules vs the current ones)
+// @Summary Preview the per-namespace impact of candidate shared cost allocation rules
+// @Tags    Cost
+// @Accept  json
+// @Produce json
+// @Param   request body dto.AllocationPreviewRequest true "candidate rules and period"
+// @Success 200 {object} dto.AllocationPreviewResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/allocation/preview [post]
+func (s *HTTPServer) previewAllocation(c *gin.Context) {
+	if s.allocationPreview == nil {
+		writeNotConfigured(c, "allocation preview")
+		return
+	}
+	var req dto.AllocationPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := s.allocationPreview.Preview(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// gradeHistory handles GET /api/v1/cost/grades/history
+// query: namespace, workload, grade (to_grade), start_time, end_time (RFC3339), limit, offset
+// @Summary Workload efficiency grade transitions
+// @Tags    Cost
+// @Produce json
+// @Param   namespace query string false "namespace"
+// @Param   workload query string false "workload name"
+// @Param   grade query string false "grade transitioned to"
+// @Param   start_time query string false "window start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339)" Format(date-time)
+// @Param   limit query integer false "page size"
+// @Param   offset query integer false "page offset"
+// @Success 200 {object} dto.GradeHistoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /cost/grades/history [get]
+func (s *HTTPServer) gradeHistory(c *gin.Context) {
+	if s.gradeService == nil {
+		writeNotConfigured(c, "grade history")
+		return
+	}
+	page := listParamsOf(c)
+	resp, err := s.gradeService.History(c.Request.Context(), postgres.GradeChangeEventFilter{
+		Namespace:    c.Query("namespace"),
+		WorkloadName: c.Query("workload"),
+		ToGrade:      c.Query("grade"),
+		StartTime:    page.StartTime,
+		EndTime:      page.EndTime,
+		Limit:        page.Limit,
+		Offset:       page.Offset,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// spoolOrAbort writes 404 and returns nil when no spool is configured.
+func (s *HTTPServer) spoolOrAbort(c *gin.Context) *spool.Spool {
+	if s.spool == nil {
+		writeNotConfigured(c, "spool")
+	}
+	return s.spool
+}
+
+// listSpool handles GET /api/v1/admin/spool - stats and pending entries (without payloads)
+// @Summary Spool stats and pending entries
+// @Tags    Admin
+// @Produce json
+// @Success 200 {object} object
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/spool [get]
+func (s *HTTPServer) listSpool(c *gin.Context) {
+	sp := s.spoolOrAbort(c)
+	if sp == nil {
+		return
+	}
+	entries, err := sp.List()
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": sp.Stats(), "entries": entries})
+}
+
+// getSpoolEntry handles GET /api/v1/admin/spool/:id - one entry including its payload
+// @Summary A spool entry with its payload
+// @Tags    Admin
+// @Produce json
+// @Param   id path string true "entry id"
+// @Success 200 {object} object
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/spool/{id} [get]
+func (s *HTTPServer) getSpoolEntry(c *gin.Context) {
+	sp := s.spoolOrAbort(c)
+	if sp == nil {
+		return
+	}
+	entry, err := sp.Get(c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// flushSpool handles POST /api/v1/admin/spool/flush - replays all pending entries now
+// @Summary Replay all pending spool entries
+// @Tags    Admin
+// @Produce json
+// @Success 200 {object} object
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/spool/flush [post]
+func (s *HTTPServer) flushSpool(c *gin.Context) {
+	sp := s.spoolOrAbort(c)
+	if sp == nil {
+		return
+	}
+	result, err := sp.Replay(c.Request.Context(), s.spoolHandlers)
+	if err != nil {
+		body := errorBody(c, err.Error(), "")
+		body["result"] = result
+		c.JSON(http.StatusInternalServerError, body)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"result": result, "stats": sp.Stats()})
+}
+
+// deleteSpoolEntry handles DELETE /api/v1/admin/spool/:id - discards an entry without replaying it
+// @Summary Discard a spool entry
+// @Tags    Admin
+// @Produce json
+// @Param   id path string true "entry id"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/spool/{id} [delete]
+func (s *HTTPServer) deleteSpoolEntry(c *gin.Context) {
+	sp := s.spoolOrAbort(c)
+	if sp == nil {
+		return
+	}
+	if err := sp.Delete(c.Param("id")); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// stateServiceOrAbort writes 404 and returns nil when state export/import is not configured.
+func (s *HTTPServer) stateServiceOrAbort(c *gin.Context) *service.StateService {
+	if s.stateService == nil {
+		writeNotConfigured(c, "state export")
+	}
+	return s.stateService
+}
+
+// exportState handles GET /api/v1/admin/state/export - the application state bundle of this environment
+// @Summary Export the application state bundle
+// @Tags    Admin
+// @Produce json
+// @Success 200 {object} dto.StateBundle
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/state/export [get]
+func (s *HTTPServer) exportState(c *gin.Context) {
+	svc := s.stateServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	bundle, err := svc.Export(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, bundle)
+}
+
+// importState handles POST /api/v1/admin/state/import?dry_run=true - imports a bundle of another
+// environment; with dry_run it only reports the changes.
+// @Summary Import an application state bundle
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   dry_run query boolean false "only report the changes"
+// @Param   request body dto.StateBundle true "bundle"
+// @Success 200 {object} dto.StateImportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/state/import [post]
+func (s *HTTPServer) importState(c *gin.Context) {
+	svc := s.stateServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, "invalid dry_run", "VALIDATION_FAILED"))
+		return
+	}
+	var bundle dto.StateBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Import(c.Request.Context(), bundle, dryRun)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// maxCostImportBytes bounds the body of an allocation import.
+const maxCostImportBytes = 256 << 20
+
+// importAllocations handles POST /api/v1/admin/import/allocations?source=kubecost&format=json&dry_run=true
+// - imports a Kubecost / OpenCost allocation export (allocation API response or CSV export) as daily
+// namespace costs and hourly workload stats marked with their source; format defaults to csv for a
+// text/csv body and json otherwise.
+// @Summary Import Kubecost / OpenCost allocation history
+// @Tags    Admin
+// @Accept  json
+// @Accept  text/csv
+// @Produce json
+// @Param   source query string false "source of the export, default kubecost" Enums(kubecost,opencost)
+// @Param   format query string false "json (allocation API response) or csv (allocation CSV export)" Enums(json,csv)
+// @Param   dry_run query boolean false "only report the rows the import would write"
+// @Param   request body object true "allocation export"
+// @Success 200 {object} dto.CostImportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/import/allocations [post]
+func (s *HTTPServer) importAllocations(c *gin.Context) {
+	if s.costImportService == nil {
+		writeNotConfigured(c, "cost import")
+		return
+	}
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, "invalid dry_run", "VALIDATION_FAILED"))
+		return
+	}
+	format := c.Query("format")
+	if format == "" && c.ContentType() == "text/csv" {
+		format = service.CostImportFormatCSV
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCostImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, errorBody(c, fmt.Sprintf("export larger than %d bytes; import a shorter window at a time", maxCostImportBytes), "PAYLOAD_TOO_LARGE"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	req := service.CostImportRequest{Source: c.Query("source"), Format: format, DryRun: dryRun}
+	resp, err := s.costImportService.Import(c.Request.Context(), req, bytes.NewReader(body))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// exportDataset handles GET /api/v1/admin/dataset/export?start_time=&end_time=&anonymize=true&scale=
+// - stored daily namespace costs and hourly workload stats; anonymize replaces names with consistent
+// pseudonyms and scale multiplies every cost, for datasets shared with vendors or used in demos.
+// @Summary Export the cost dataset, optionally anonymized
+// @Tags    Admin
+// @Produce json
+// @Param   start_time query string false "window start (RFC3339), default end_time - 7 days" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339), default the current hour" Format(date-time)
+// @Param   anonymize query boolean false "replace namespace, workload, pod, node and cost center names with pseudonyms"
+// @Param   scale query number false "cost multiplier, default 1"
+// @Success 200 {object} dto.Dataset
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/dataset/export [get]
+func (s *HTTPServer) exportDataset(c *gin.Context) {
+	if s.datasetService == nil {
+		writeNotConfigured(c, "dataset export")
+		return
+	}
+	lq := listParamsOf(c)
+	q := service.DatasetQuery{StartTime: lq.StartTime, EndTime: lq.EndTime}
+	var err error
+	if q.Anonymize, err = strconv.ParseBool(c.DefaultQuery("anonymize", "false")); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, "invalid anonymize", "VALIDATION_FAILED"))
+		return
+	}
+	if v := c.Query("scale"); v != "" {
+		if q.Scale, err = strconv.ParseFloat(v, 64); err != nil || q.Scale <= 0 {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid scale", "VALIDATION_FAILED"))
+			return
+		}
+	}
+	ds, err := s.datasetService.Export(c.Request.Context(), q)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ds)
+}
+
+// regradeSnapshots handles POST /api/v1/admin/regrade - regrades the stored snapshots of the window
+// under new efficiency thresholds; the snapshots keep their original grades
+// @Summary Regrade cost snapshots under new efficiency thresholds
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   request body dto.RegradeRequest true "window and thresholds"
+// @Success 200 {object} dto.RegradeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/regrade [post]
+func (s *HTTPServer) regradeSnapshots(c *gin.Context) {
+	svc := s.regradeServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.RegradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Regrade(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// getGradeView handles GET /api/v1/admin/regrade/view
+// @Summary The view of snapshot grade counts currently served
+// @Tags    Admin
+// @Produce json
+// @Success 200 {object} dto.GradeView
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/regrade/view [get]
+func (s *HTTPServer) getGradeView(c *gin.Context) {
+	svc := s.regradeServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.View(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// setGradeView handles PUT /api/v1/admin/regrade/view - switches between the original and the regraded view
+// @Summary Switch the view of snapshot grade counts
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   request body dto.SetGradeViewRequest true "view"
+// @Success 200 {object} dto.GradeView
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/regrade/view [put]
+func (s *HTTPServer) setGradeView(c *gin.Context) {
+	svc := s.regradeServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.SetGradeViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.SetView(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// exportServiceOrAbort writes 404 and returns nil when export jobs are not configured.
+func (s *HTTPServer) exportServiceOrAbort(c *gin.Context) *service.ExportJobService {
+	if s.exportService == nil {
+		writeNotConfigured(c, "export jobs")
+	}
+	return s.exportService
+}
+
+// listExportJobs handles GET /api/v1/exports
+// @Summary List export jobs and the accepted formats
+// @Tags    Export
+// @Produce json
+// @Success 200 {object} dto.ExportJobListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router  /exports [get]
+func (s *HTTPServer) listExportJobs(c *gin.Context) {
+	svc := s.exportServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	c.JSON(http.StatusOK, svc.List(c.Request.Context()))
+}
+
+// createExportJob handles POST /api/v1/exports - queues an export of the cost dataset; poll
+// GET /api/v1/exports/:id for progress and download the file once it succeeded.
+// @Summary Create an async export job
+// @Tags    Export
+// @Accept  json
+// @Produce json
+// @Param   request body dto.CreateExportJobRequest true "export job"
+// @Success 202 {object} dto.ExportJob
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router  /exports [post]
+func (s *HTTPServer) createExportJob(c *gin.Context) {
+	svc := s.exportServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.CreateExportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	job, err := svc.Create(c.Request.Context(), req, c.GetString("serviceAccount"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Header("Location", "/api/v1/exports/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// getExportJob handles GET /api/v1/exports/:id - status and progress of an export job
+// @Summary Get an export job
+// @Tags    Export
+// @Produce json
+// @Param   id path string true "export job ID"
+// @Success 200 {object} dto.ExportJob
+// @Failure 404 {object} dto.ErrorResponse
+// @Router  /exports/{id} [get]
+func (s *HTTPServer) getExportJob(c *gin.Context) {
+	svc := s.exportServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	job, err := svc.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// downloadExportJob handles GET /api/v1/exports/:id/download - the file of a succeeded job; 409 while
+// the job is queued, running or failed and 410 once its retention has passed.
+// @Summary Download the file of an export job
+// @Tags    Export
+// @Produce text/csv,application/pdf
+// @Param   id path string true "export job ID"
+// @Success 200 "the export file (Content-Disposition names it)"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Router  /exports/{id}/download [get]
+func (s *HTTPServer) downloadExportJob(c *gin.Context) {
+	svc := s.exportServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	f, job, contentType, err := svc.Open(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, service.ErrExportExpired):
+		c.JSON(http.StatusGone, errorBody(c, err.Error(), "GONE"))
+		return
+	case err != nil:
+		writeError(c, err)
+		return
+	}
+	defer f.Close()
+	c.DataFromReader(http.StatusOK, job.SizeBytes, contentType, f, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", job.FileName),
+	})
+}
+
+// authenticate resolves the service account API key of /api/v1 requests (X-API-Key or Authorization:
+// Bearer) and enforces its scope ("<group>:read" for GET/HEAD, "<group>:write" otherwise, group being
+// the first path segment after /api/v1) and daily quota. Requests without a key pass unless
+// security.api_keys.required is set.
+func (s *HTTPServer) authenticate(c *gin.Context) {
+	secret := c.GetHeader("X-API-Key")
+	if v := c.GetHeader("Authorization"); secret == "" && strings.HasPrefix(v, "Bearer ") {
+		secret = strings.TrimSpace(strings.TrimPrefix(v, "Bearer "))
+	}
+	if secret == "" || s.accountService == nil {
+		if s.config.Security.APIKeys.Required {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, "api key required", "UNAUTHENTICATED"))
+			return
+		}
+		c.Next()
+		return
+	}
+	p, err := s.accountService.Authenticate(c.Request.Context(), secret, requestScope(c.Request))
+	if p != nil && p.DailyQuota > 0 {
+		remaining := int64(p.DailyQuota) - p.UsedToday
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(p.DailyQuota))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	}
+	switch {
+	case errors.Is(err, service.ErrUnauthenticated):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, err.Error(), "UNAUTHENTICATED"))
+		return
+	case errors.Is(err, service.ErrScopeDenied):
+		c.AbortWithStatusJSON(http.StatusForbidden, errorBody(c, err.Error(), "FORBIDDEN"))
+		return
+	case errors.Is(err, service.ErrQuotaExceeded):
+		now := time.Now().UTC()
+		c.Header("Retry-After", strconv.Itoa(int(now.Truncate(24*time.Hour).Add(24*time.Hour).Sub(now).Seconds())+1))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, errorBody(c, err.Error(), "QUOTA_EXCEEDED"))
+		return
+	case err != nil:
+		writeError(c, err)
+		c.Abort()
+		return
+	}
+	c.Set("serviceAccount", p.AccountName)
+	c.Set("apiKeyId", p.KeyID)
+	c.Next()
+}
+
+// meterQueries binds the request to its query budget consumer and rejects it with 429 when the
+// consumer has used up a quota of the current window. Admin endpoints are never rejected, so the
+// consumption stays inspectable.
+func (s *HTTPServer) meterQueries(c *gin.Context) {
+	if s.queryBudgets == nil {
+		c.Next()
+		return
+	}
+	consumer := queryBudgetConsumer(c)
+	if exceeded, end := s.queryBudgets.Check(consumer); exceeded != "" && !strings.HasPrefix(c.Request.URL.Path, "/api/v1/admin/") {
+		c.Header("Retry-After", strconv.Itoa(int(time.Until(end).Seconds())+1))
+		msg := fmt.Sprintf("%s query budget of %s exceeded", exceeded, consumer)
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, errorBody(c, msg, "QUERY_BUDGET_EXCEEDED"))
+		return
+	}
+	c.Request = c.Request.WithContext(querybudget.WithConsumer(c.Request.Context(), s.queryBudgets, consumer))
+	c.Next()
+}
+
+// queryBudgetConsumer returns the consumer queries are accounted to: the service account of the API
+// key, else "anonymous" (shared by all requests without a key).
+func queryBudgetConsumer(c *gin.Context) string {
+	if account := c.GetString("serviceAccount"); account != "" {
+		return account
+	}
+	return "anonymous"
+}
+
+// requestScope returns the scope a request needs, e.g. "cost:read" for GET /api/v1/cost/global.
+// Reviewing a change (POST .../approve or .../reject) needs the approve verb, e.g. "pricing:approve".
+func requestScope(r *http.Request) string {
+	group, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/"), "/")
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return group + ":read"
+	}
+	if r.Method == http.MethodPost && (strings.HasSuffix(r.URL.Path, "/approve") || strings.HasSuffix(r.URL.Path, "/reject")) {
+		return group + ":approve"
+	}
+	return group + ":write"
+}
+
+// authorize rejects anonymous requests to the admin endpoints, whose "admin:<verb>" scope authenticate
+// checked (see service.AdminScopeGroup), then asks the external authorizer whether the authenticated
+// subject may perform the request.
+func (s *HTTPServer) authorize(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, "/api/v1/"+service.AdminScopeGroup+"/") && c.GetString("serviceAccount") == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, "admin endpoints require an api key with an admin scope", "UNAUTHENTICATED"))
+		return
+	}
+	if s.authorizer == nil {
+		c.Next()
+		return
+	}
+	resource, action, _ := strings.Cut(requestScope(c.Request), ":")
+	req := authz.Request{
+		Subject:   c.GetString("serviceAccount"),
+		Resource:  resource,
+		Action:    action,
+		Namespace: c.Param("namespace"),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		KeyID:     c.GetString("apiKeyId"),
+	}
+	if req.Subject == "" {
+		req.Subject = authz.AnonymousSubject
+	}
+	if req.Namespace == "" {
+		req.Namespace = c.Query("namespace")
+	}
+	d, err := s.authorizer.Authorize(c.Request.Context(), req)
+	switch {
+	case err != nil && s.config.Security.Authz.FailOpen:
+		log.Printf("WARN: authz: %v; allowing %s %s for %s (fail open)", err, req.Method, req.Path, req.Subject)
+	case err != nil:
+		log.Printf("WARN: authz: %v", err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, errorBody(c, "authorization service unavailable", "UNAVAILABLE"))
+		return
+	case !d.Allowed:
+		msg := "denied by authorization policy"
+		if d.Reason != "" {
+			msg += ": " + d.Reason
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, errorBody(c, msg, "FORBIDDEN"))
+		return
+	}
+	c.Next()
+}
+
+// accountServiceOrAbort writes 404 and returns nil when service accounts are not configured.
+func (s *HTTPServer) accountServiceOrAbort(c *gin.Context) *service.ServiceAccountService {
+	if s.accountService == nil {
+		writeNotConfigured(c, "service accounts")
+	}
+	return s.accountService
+}
+
+// listServiceAccounts handles GET /api/v1/admin/service-accounts
+// @Summary List service accounts with their API keys and today's usage
+// @Tags    Admin
+// @Produce json
+// @Success 200 {object} dto.ServiceAccountListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/service-accounts [get]
+func (s *HTTPServer) listServiceAccounts(c *gin.Context) {
+	svc := s.accountServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.ListAccounts(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// createServiceAccount handles POST /api/v1/admin/service-accounts
+// @Summary Create a service account
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   request body dto.CreateServiceAccountRequest true "service account"
+// @Success 201 {object} dto.ServiceAccount
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/service-accounts [post]
+func (s *HTTPServer) createServiceAccount(c *gin.Context) {
+	svc := s.accountServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = c.GetString("serviceAccount")
+	}
+	resp, err := svc.CreateAccount(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// getServiceAccount handles GET /api/v1/admin/service-accounts/:id
+// @Summary Get a service account with its API keys and today's usage
+// @Tags    Admin
+// @Produce json
+// @Param   id path string true "service account id"
+// @Success 200 {object} dto.ServiceAccount
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/service-accounts/{id} [get]
+func (s *HTTPServer) getServiceAccount(c *gin.Context) {
+	svc := s.accountServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.GetAccount(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// updateServiceAccount handles PUT /api/v1/admin/service-accounts/:id (description, disabled)
+// @Summary Update or disable a service account
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   id path string true "service account id"
+// @Param   request body dto.UpdateServiceAccountRequest true "description and disabled flag"
+// @Success 200 {object} dto.ServiceAccount
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/service-accounts/{id} [put]
+func (s *HTTPServer) updateServiceAccount(c *gin.Context) {
+	svc := s.accountServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.UpdateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.UpdateAccount(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// deleteServiceAccount handles DELETE /api/v1/admin/service-accounts/:id
+// @Summary Delete a service account and its API keys
+// @Tags    Admin
+// @Produce json
+// @Param   id path string true "service account id"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/service-accounts/{id} [delete]
+func (s *HTTPServer) deleteServiceAccount(c *gin.Context) {
+	svc := s.accountServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	if err := svc.DeleteAccount(c.Request.Context(), c.Param("id")); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// createAPIKey handles POST /api/v1/admin/service-accounts/:id/keys - the secret is only returned here
+// @Summary Issue an API key for a service account
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   id path string true "service account id"
+// @Param   request body dto.CreateAPIKeyRequest true "scopes, daily quota and expiry"
+// @Success 201 {object} dto.CreatedAPIKey
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/service-accounts/{id}/keys [post]
+func (s *HTTPServer) createAPIKey(c *gin.Context) {
+	svc := s.accountServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.CreateKey(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// revokeAPIKey handles DELETE /api/v1/admin/service-accounts/:id/keys/:key_id
+// @Summary Revoke an API key
+// @Tags    Admin
+// @Produce json
+// @Param   id path string true "service account id"
+// @Param   key_id path string true "api key id"
+// @Success 200 {object} dto.APIKey
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/service-accounts/{id}/keys/{key_id} [delete]
+func (s *HTTPServer) revokeAPIKey(c *gin.Context) {
+	svc := s.accountServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.RevokeKey(c.Request.Context(), c.Param("id"), c.Param("key_id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// serviceAccountUsage handles GET /api/v1/admin/service-accounts/:id/usage?days=7
+// @Summary Daily request and quota rejection counts of a service account's API keys
+// @Tags    Admin
+// @Produce json
+// @Param   id path string true "service account id"
+// @Param   days query integer false "days ending today, default 7, at most 90"
+// @Success 200 {object} dto.APIKeyUsageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/service-accounts/{id}/usage [get]
+func (s *HTTPServer) serviceAccountUsage(c *gin.Context) {
+	svc := s.accountServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	days := 0
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid days", "VALIDATION_FAILED"))
+			return
+		}
+		days = n
+	}
+	resp, err := svc.Usage(c.Request.Context(), c.Param("id"), days)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// listQueryBudgets handles GET /api/v1/admin/query-budgets
+// @Summary Prometheus and database queries of every API consumer in the current query budget window
+// @Tags    Admin
+// @Produce json
+// @Success 200 {object} dto.QueryBudgetResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router  /admin/query-budgets [get]
+func (s *HTTPServer) listQueryBudgets(c *gin.Context) {
+	if s.queryBudgets == nil {
+		writeNotConfigured(c, "query budgets")
+		return
+	}
+	start, end, usage := s.queryBudgets.Usage()
+	resp := dto.QueryBudgetResponse{WindowStart: start, WindowEnd: end, Consumers: make([]dto.QueryBudgetConsumer, 0, len(usage))}
+	for _, u := range usage {
+		resp.Consumers = append(resp.Consumers, dto.QueryBudgetConsumer{
+			Consumer:               u.Consumer,
+			PrometheusQueries:      u.PrometheusQueries,
+			PrometheusQueryLimit:   u.Quota.PrometheusQueries,
+			PrometheusQuerySeconds: u.PrometheusTime.Seconds(),
+			DatabaseQueries:        u.DatabaseQueries,
+			DatabaseQueryLimit:     u.Quota.DatabaseQueries,
+			DatabaseQuerySeconds:   u.DatabaseTime.Seconds(),
+			Exceeded:               string(u.Exceeded()),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// chaosOrAbort writes 404 and returns nil when chaos drills are not configured.
+func (s *HTTPServer) chaosOrAbort(c *gin.Context) *chaos.Controller {
+	if s.chaosDrills == nil || !s.config.Chaos.Enabled {
+		writeNotConfigured(c, "chaos drills")
+		return nil
+	}
+	return s.chaosDrills
+}
+
+// getChaos handles GET /api/v1/admin/chaos
+// @Summary Configured chaos scenarios and the current or last resilience drill
+// @Tags    Admin
+// @Produce json
+// @Success 200 {object} dto.ChaosResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router  /admin/chaos [get]
+func (s *HTTPServer) getChaos(c *gin.Context) {
+	controller := s.chaosOrAbort(c)
+	if controller == nil {
+		return
+	}
+	drill, _ := controller.Last()
+	c.JSON(http.StatusOK, chaosResponse(controller, drill))
+}
+
+// startChaosDrill handles POST /api/v1/admin/chaos - injects the faults of a configured scenario into
+// the data clients until the duration has passed or the drill is stopped
+// @Summary Start a resilience drill
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   request body dto.StartChaosDrillRequest true "scenario and duration"
+// @Success 201 {object} dto.ChaosResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router  /admin/chaos [post]
+func (s *HTTPServer) startChaosDrill(c *gin.Context) {
+	controller := s.chaosOrAbort(c)
+	if controller == nil {
+		return
+	}
+	var req dto.StartChaosDrillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, "invalid duration: "+err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	drill, err := controller.Start(req.Scenario, duration, c.GetString("serviceAccount"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	log.Printf("WARN: chaos drill %q started by %q until %s", drill.Scenario, drill.StartedBy, drill.EndsAt.Format(time.RFC3339))
+	c.JSON(http.StatusCreated, chaosResponse(controller, drill))
+}
+
+// stopChaosDrill handles DELETE /api/v1/admin/chaos - ends the active drill early
+// @Summary Stop the active resilience drill
+// @Tags    Admin
+// @Produce json
+// @Success 200 {object} dto.ChaosResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router  /admin/chaos [delete]
+func (s *HTTPServer) stopChaosDrill(c *gin.Context) {
+	controller := s.chaosOrAbort(c)
+	if controller == nil {
+		return
+	}
+	drill, ok := controller.Stop()
+	if !ok {
+		c.JSON(http.StatusNotFound, errorBody(c, "no active chaos drill", "NOT_FOUND"))
+		return
+	}
+	log.Printf("chaos drill %q stopped by %q", drill.Scenario, c.GetString("serviceAccount"))
+	c.JSON(http.StatusOK, chaosResponse(controller, drill))
+}
+
+// chaosResponse builds the response of the chaos endpoints; drill is the zero Drill when none was started.
+func chaosResponse(controller *chaos.Controller, drill chaos.Drill) dto.ChaosResponse {
+	resp := dto.ChaosResponse{
+		Active:             controller.Active(),
+		MaxDurationSeconds: int64(controller.MaxDuration() / time.Second),
+		Scenarios:          make([]dto.ChaosScenario, 0, len(controller.Scenarios())),
+	}
+	for _, name := range controller.ScenarioNames() {
+		sc := dto.ChaosScenario{Name: name, Faults: make(map[string]dto.ChaosFault)}
+		for source, f := range controller.Scenarios()[name] {
+			sc.Faults[string(source)] = dto.ChaosFault(f)
+		}
+		resp.Scenarios = append(resp.Scenarios, sc)
+	}
+	if drill.Scenario == "" {
+		return resp
+	}
+	resp.Drill = &dto.ChaosDrill{
+		Scenario:  drill.Scenario,
+		StartedBy: drill.StartedBy,
+		StartedAt: drill.StartedAt,
+		EndsAt:    drill.EndsAt,
+		Injected:  make(map[string]dto.ChaosInjectCount, len(drill.Injected)),
+	}
+	if !drill.StoppedAt.IsZero() {
+		resp.Drill.StoppedAt = &drill.StoppedAt
+	}
+	for source, n := range drill.Injected {
+		resp.Drill.Injected[string(source)] = dto.ChaosInjectCount(n)
+	}
+	return resp
+}
+
+// alertRuleServiceOrAbort writes 404 and returns nil when alert rules are not configured.
+func (s *HTTPServer) alertRuleServiceOrAbort(c *gin.Context) *service.AlertRuleService {
+	if s.alertRuleService == nil {
+		writeNotConfigured(c, "alert rules")
+	}
+	return s.alertRuleService
+}
+
+// listAlertRules handles GET /api/v1/alerts/rules
+// @Summary List alert rules with their state and the metrics conditions may use
+// @Tags    Alerts
+// @Produce json
+// @Success 200 {object} dto.AlertRuleListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/rules [get]
+func (s *HTTPServer) listAlertRules(c *gin.Context) {
+	svc := s.alertRuleServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.List(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// createAlertRule handles POST /api/v1/alerts/rules
+// @Summary Create an alert rule
+// @Tags    Alerts
+// @Accept  json
+// @Produce json
+// @Param   request body dto.AlertRuleRequest true "condition, window, interval, severity and routing"
+// @Success 201 {object} dto.AlertRule
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/rules [post]
+func (s *HTTPServer) createAlertRule(c *gin.Context) {
+	svc := s.alertRuleServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = c.GetString("serviceAccount")
+	}
+	resp, err := svc.Create(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// getAlertRule handles GET /api/v1/alerts/rules/:id
+// @Summary Get an alert rule with its state
+// @Tags    Alerts
+// @Produce json
+// @Param   id path string true "alert rule id"
+// @Success 200 {object} dto.AlertRule
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/rules/{id} [get]
+func (s *HTTPServer) getAlertRule(c *gin.Context) {
+	svc := s.alertRuleServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// updateAlertRule handles PUT /api/v1/alerts/rules/:id
+// @Summary Replace the definition of an alert rule
+// @Tags    Alerts
+// @Accept  json
+// @Produce json
+// @Param   id path string true "alert rule id"
+// @Param   request body dto.AlertRuleRequest true "condition, window, interval, severity and routing"
+// @Success 200 {object} dto.AlertRule
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/rules/{id} [put]
+func (s *HTTPServer) updateAlertRule(c *gin.Context) {
+	svc := s.alertRuleServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Update(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// deleteAlertRule handles DELETE /api/v1/alerts/rules/:id
+// @Summary Delete an alert rule
+// @Tags    Alerts
+// @Produce json
+// @Param   id path string true "alert rule id"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/rules/{id} [delete]
+func (s *HTTPServer) deleteAlertRule(c *gin.Context) {
+	svc := s.alertRuleServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	if err := svc.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// evaluateAlertRule handles POST /api/v1/alerts/rules/:id/evaluate - evaluates now and notifies when the rule starts firing
+// @Summary Evaluate an alert rule now
+// @Tags    Alerts
+// @Produce json
+// @Param   id path string true "alert rule id"
+// @Success 200 {object} dto.AlertRuleEvaluation
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/rules/{id}/evaluate [post]
+func (s *HTTPServer) evaluateAlertRule(c *gin.Context) {
+	svc := s.alertRuleServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.Evaluate(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// notifyPolicyOrAbort writes 404 and returns nil when notification routing is not configured.
+func (s *HTTPServer) notifyPolicyOrAbort(c *gin.Context) *service.NotificationPolicyService {
+	if s.notifyPolicy == nil {
+		writeNotConfigured(c, "notification routing")
+	}
+	return s.notifyPolicy
+}
+
+// listNotificationRoutes handles GET /api/v1/alerts/routes
+// @Summary List notification routing rules in matching order
+// @Tags    Alerts
+// @Produce json
+// @Success 200 {object} dto.NotificationRouteListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/routes [get]
+func (s *HTTPServer) listNotificationRoutes(c *gin.Context) {
+	svc := s.notifyPolicyOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.ListRoutes(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// createNotificationRoute handles POST /api/v1/alerts/routes
+// @Summary Create a notification routing rule
+// @Tags    Alerts
+// @Accept  json
+// @Produce json
+// @Param   request body dto.NotificationRouteRequest true "label matchers, channels and priority"
+// @Success 201 {object} dto.NotificationRoute
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/routes [post]
+func (s *HTTPServer) createNotificationRoute(c *gin.Context) {
+	svc := s.notifyPolicyOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.NotificationRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = c.GetString("serviceAccount")
+	}
+	resp, err := svc.CreateRoute(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// getNotificationRoute handles GET /api/v1/alerts/routes/:id
+// @Summary Get a notification routing rule
+// @Tags    Alerts
+// @Produce json
+// @Param   id path string true "route id"
+// @Success 200 {object} dto.NotificationRoute
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/routes/{id} [get]
+func (s *HTTPServer) getNotificationRoute(c *gin.Context) {
+	svc := s.notifyPolicyOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.GetRoute(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// updateNotificationRoute handles PUT /api/v1/alerts/routes/:id
+// @Summary Replace a notification routing rule
+// @Tags    Alerts
+// @Accept  json
+// @Produce json
+// @Param   id path string true "route id"
+// @Param   request body dto.NotificationRouteRequest true "label matchers, channels and priority"
+// @Success 200 {object} dto.NotificationRoute
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/routes/{id} [put]
+func (s *HTTPServer) updateNotificationRoute(c *gin.Context) {
+	svc := s.notifyPolicyOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.NotificationRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.UpdateRoute(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// deleteNotificationRoute handles DELETE /api/v1/alerts/routes/:id
+// @Summary Delete a notification routing rule
+// @Tags    Alerts
+// @Produce json
+// @Param   id path string true "route id"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/routes/{id} [delete]
+func (s *HTTPServer) deleteNotificationRoute(c *gin.Context) {
+	svc := s.notifyPolicyOrAbort(c)
+	if svc == nil {
+		return
+	}
+	if err := svc.DeleteRoute(c.Request.Context(), c.Param("id")); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// listSilences handles GET /api/v1/alerts/silences - active and pending silences (all=true adds expired ones)
+// @Summary List notification silences
+// @Tags    Alerts
+// @Produce json
+// @Param   all query bool false "include expired silences"
+// @Success 200 {object} dto.SilenceListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/silences [get]
+func (s *HTTPServer) listSilences(c *gin.Context) {
+	svc := s.notifyPolicyOrAbort(c)
+	if svc == nil {
+		return
+	}
+	all := false
+	if v := c.Query("all"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid all", "VALIDATION_FAILED"))
+			return
+		}
+		all = b
+	}
+	resp, err := svc.ListSilences(c.Request.Context(), all)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// createSilence handles POST /api/v1/alerts/silences
+// @Summary Silence matching notifications for a time window
+// @Tags    Alerts
+// @Accept  json
+// @Produce json
+// @Param   request body dto.CreateSilenceRequest true "label matchers, time window and reason"
+// @Success 201 {object} dto.Silence
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/silences [post]
+func (s *HTTPServer) createSilence(c *gin.Context) {
+	svc := s.notifyPolicyOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.CreateSilenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = c.GetString("serviceAccount")
+	}
+	resp, err := svc.CreateSilence(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// expireSilence handles DELETE /api/v1/alerts/silences/:id - ends the silence now, keeping it for audit
+// @Summary End a notification silence
+// @Tags    Alerts
+// @Produce json
+// @Param   id path string true "silence id"
+// @Success 200 {object} dto.Silence
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /alerts/silences/{id} [delete]
+func (s *HTTPServer) expireSilence(c *gin.Context) {
+	svc := s.notifyPolicyOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.ExpireSilence(c.Request.Context(), c.Param("id"), c.GetString("serviceAccount"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// dataAsOf sets X-Lighthouse-Data-As-Of to the cut-off of the latest synced data. Responses served
+// from the degraded-mode cache overwrite it with the cache time (middleware.Staleness).
+func (s *HTTPServer) dataAsOf(c *gin.Context) {
+	if s.freshness != nil && c.Request.Method == http.MethodGet {
+		if data, err := s.freshness.Status(c.Request.Context()); err != nil {
+			log.Printf("WARN: data freshness: %v", err)
+		} else if data.AsOf != nil {
+			c.Header(middleware.HeaderDataAsOf, data.AsOf.Format(time.RFC3339))
+		}
+	}
+	c.Next()
+}
+
+// HeaderLineageID names the lineage record of a cost query response (GET /api/v1/lineage/:id).
+const HeaderLineageID = "X-Lighthouse-Lineage-Id"
+
+// traceLineage captures the data state before a cost query handler runs and stores it when the
+// response succeeds. Lineage failures are logged and never fail the query itself.
+func (s *HTTPServer) traceLineage(c *gin.Context) {
+	if s.lineageService == nil || c.Request.Method != http.MethodGet {
+		c.Next()
+		return
+	}
+	ctx := c.Request.Context()
+	lineage, err := s.lineageService.Capture(ctx, service.LineageRequest{
+		RequestID: c.GetString("requestId"),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+	})
+	if err != nil {
+		log.Printf("WARN: capture query lineage: %v", err)
+		c.Next()
+		return
+	}
+	w := &lineageWriter{ResponseWriter: c.Writer, id: lineage.ID}
+	c.Writer = w
+	c.Next()
+	if w.attached {
+		if err := s.lineageService.Save(ctx, lineage, w.Status()); err != nil {
+			log.Printf("WARN: %v", err)
+		}
+	}
+}
+
+// lineageWriter adds the lineage header right before a successful response header is written,
+// since the status is only known once the handler responds.
+type lineageWriter struct {
+	gin.ResponseWriter
+	id       string
+	attached bool
+}
+
+func (w *lineageWriter) attach() {
+	if w.ResponseWriter.Written() || w.Status() >= http.StatusBadRequest {
+		return
+	}
+	w.Header().Set(HeaderLineageID, w.id)
+	w.attached = true
+}
+
+func (w *lineageWriter) WriteHeaderNow() {
+	w.attach()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *lineageWriter) Write(data []byte) (int, error) {
+	w.attach()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *lineageWriter) WriteString(s string) (int, error) {
+	w.attach()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// getLineage handles GET /api/v1/lineage/:id - the id comes from the X-Lighthouse-Lineage-Id response header
+// @Summary Data lineage of a cost query response
+// @Tags    Lineage
+// @Produce json
+// @Param   id path string true "lineage id"
+// @Success 200 {object} dto.QueryLineage
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /lineage/{id} [get]
+func (s *HTTPServer) getLineage(c *gin.Context) {
+	if s.lineageService == nil {
+		writeNotConfigured(c, "query lineage")
+		return
+	}
+	resp, err := s.lineageService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// timelineServiceOrAbort writes 404 and returns nil when the timeline is not configured.
+func (s *HTTPServer) timelineServiceOrAbort(c *gin.Context) *service.TimelineService {
+	if s.timelineService == nil {
+		writeNotConfigured(c, "timeline")
+	}
+	return s.timelineService
+}
+
+// timeline handles GET /api/v1/timeline - K8s events, config changes, calculation runs, price
+// changes and SLO violations of a namespace in one ordered list
+// query: namespace, start_time, end_time (RFC3339, default the last 24h), kinds, limit
+// @Summary Cluster events timeline
+// @Tags    Timeline
+// @Produce json
+// @Param   namespace query string false "namespace (default: whole cluster)"
+// @Param   start_time query string false "window start (RFC3339)" Format(date-time)
+// @Param   end_time query string false "window end (RFC3339)" Format(date-time)
+// @Param   kinds query string false "comma-separated k8s_event, config_change, calculation_run, price_change, slo_violation"
+// @Param   limit query integer false "latest events returned (default 1000)"
+// @Success 200 {object} dto.TimelineResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /timeline [get]
+func (s *HTTPServer) timeline(c *gin.Context) {
+	svc := s.timelineServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	page := listParamsOf(c)
+	kinds, err := service.ParseTimelineKinds(c.Query("kinds"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp, err := svc.Timeline(c.Request.Context(), service.TimelineQuery{
+		Namespace: c.Query("namespace"),
+		StartTime: page.StartTime,
+		EndTime:   page.EndTime,
+		Kinds:     kinds,
+		Limit:     page.Limit,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// recordConfigChange handles POST /api/v1/timeline/config-changes (deploy pipeline hook)
+// @Summary Record a config change on the timeline
+// @Tags    Timeline
+// @Accept  json
+// @Produce json
+// @Param   request body dto.ConfigChangeRequest true "changed object and values"
+// @Success 201 {object} dto.ConfigChange
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /timeline/config-changes [post]
+func (s *HTTPServer) recordConfigChange(c *gin.Context) {
+	svc := s.timelineServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.ConfigChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	if req.Author == "" {
+		req.Author = c.GetString("serviceAccount")
+	}
+	resp, err := svc.RecordConfigChange(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// recordSLOViolation handles POST /api/v1/timeline/slo-violations (start of a violation, or its
+// recovery with the same id and recovered_at); with the dependency graph configured, the record of a
+// start carries the upstream services and spend depending on the service
+// @Summary Record an SLO violation on the timeline
+// @Tags    Timeline
+// @Accept  json
+// @Produce json
+// @Param   request body dto.SLOViolationRequest true "violated service, type and values"
+// @Success 201 {object} dto.SLOViolationRecord
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /timeline/slo-violations [post]
+func (s *HTTPServer) recordSLOViolation(c *gin.Context) {
+	svc := s.timelineServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.SLOViolationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.RecordSLOViolation(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// dependencyServiceOrAbort writes 404 and returns nil when the dependency graph is not configured.
+func (s *HTTPServer) dependencyServiceOrAbort(c *gin.Context) *service.DependencyService {
+	if s.dependencyService == nil {
+		writeNotConfigured(c, "dependency graph")
+	}
+	return s.dependencyService
+}
+
+// listDependencies handles GET /api/v1/dependencies - the live edges of the service dependency graph
+// query: limit (default all), offset or cursor
+// @Summary List service dependency edges
+// @Tags    Dependencies
+// @Produce json
+// @Param   limit query integer false "page size, max 500 (default all)"
+// @Param   offset query integer false "page offset"
+// @Param   cursor query string false "next_cursor of the previous page (instead of offset)"
+// @Success 200 {object} dto.DependencyEdgeList
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /dependencies [get]
+func (s *HTTPServer) listDependencies(c *gin.Context) {
+	svc := s.dependencyServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	edges, err := svc.Edges(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	var resp dto.DependencyEdgeList
+	resp.Items, resp.ListPage = pageOf(edges, listParamsOf(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// ingestDependencies handles POST /api/v1/dependencies/edges (edges observed by the tracing
+// pipeline, or the whole static dependency map)
+// @Summary Ingest service dependency edges
+// @Tags    Dependencies
+// @Accept  json
+// @Produce json
+// @Param   request body dto.DependencyIngestRequest true "source and edges"
+// @Success 200 {object} dto.DependencyIngestResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /dependencies/edges [post]
+func (s *HTTPServer) ingestDependencies(c *gin.Context) {
+	svc := s.dependencyServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.DependencyIngestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Ingest(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// periodServiceOrAbort writes 404 and returns nil when period closing is not configured.
+func (s *HTTPServer) periodServiceOrAbort(c *gin.Context) *service.PeriodCloseService {
+	if s.periodService == nil {
+		writeNotConfigured(c, "accounting periods")
+	}
+	return s.periodService
+}
+
+// listAccountingPeriods handles GET /api/v1/admin/periods - the closed fiscal periods, latest first
+// query: limit (default all), offset or cursor
+// @Summary List closed accounting periods
+// @Tags    Admin
+// @Produce json
+// @Param   limit query integer false "page size, max 500 (default all)"
+// @Param   offset query integer false "page offset"
+// @Param   cursor query string false "next_cursor of the previous page (instead of offset)"
+// @Success 200 {object} dto.AccountingPeriodList
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/periods [get]
+func (s *HTTPServer) listAccountingPeriods(c *gin.Context) {
+	svc := s.periodServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	periods, err := svc.Periods(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	var resp dto.AccountingPeriodList
+	resp.Items, resp.ListPage = pageOf(periods, listParamsOf(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// closeAccountingPeriod handles POST /api/v1/admin/periods/close?date=YYYY-MM-DD - closes the fiscal
+// period containing date (default the last period that ended); its daily costs become immutable
+// @Summary Close an accounting period
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   date    query string                           false "a day of the period (YYYY-MM-DD), default the last ended period"
+// @Param   request body  dto.CloseAccountingPeriodRequest false "note"
+// @Success 201 {object} dto.AccountingPeriod
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/periods/close [post]
+func (s *HTTPServer) closeAccountingPeriod(c *gin.Context) {
+	svc := s.periodServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var date time.Time
+	if v := c.Query("date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, "invalid date, expected YYYY-MM-DD", "VALIDATION_FAILED"))
+			return
+		}
+		date = d
+	}
+	var req dto.CloseAccountingPeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Close(c.Request.Context(), date, preferenceUser(c), req.Note)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// listAdjustments handles GET /api/v1/admin/adjustments?period=&namespace= - corrections recorded for
+// closed periods, oldest first
+// @Summary List adjustments of closed accounting periods
+// @Tags    Admin
+// @Produce json
+// @Param   period    query string  false "period label, e.g. FY2026 P09"
+// @Param   namespace query string  false "namespace"
+// @Param   limit     query integer false "page size, max 500 (default all)"
+// @Param   offset    query integer false "page offset"
+// @Param   cursor    query string  false "next_cursor of the previous page (instead of offset)"
+// @Success 200 {object} dto.CostAdjustmentList
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/adjustments [get]
+func (s *HTTPServer) listAdjustments(c *gin.Context) {
+	svc := s.periodServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	adjustments, err := svc.Adjustments(c.Request.Context(), c.Query("period"), c.Query("namespace"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	var resp dto.CostAdjustmentList
+	resp.Items, resp.ListPage = pageOf(adjustments, listParamsOf(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// createAdjustment handles POST /api/v1/admin/adjustments - records a manual correction of a day of
+// a closed period requested by the caller (the service account of the API key); it stays pending until
+// someone else approves it
+// @Summary Record a manual adjustment of a closed accounting period
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   request body dto.CreateAdjustmentRequest true "scope, amount and reason"
+// @Success 201 {object} dto.CostAdjustment
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/adjustments [post]
+func (s *HTTPServer) createAdjustment(c *gin.Context) {
+	svc := s.periodServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.CreateAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Adjust(c.Request.Context(), req, c.GetString("serviceAccount"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// approveAdjustment handles POST /api/v1/admin/adjustments/:id/approve (needs an API key with the
+// admin:approve scope) - the service account of the key approves a pending manual adjustment it did not
+// request; fiscal reports include it from then on
+// @Summary Approve a manual adjustment of a closed accounting period
+// @Tags    Admin
+// @Produce json
+// @Param   id path string true "adjustment id"
+// @Success 200 {object} dto.CostAdjustment
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/adjustments/{id}/approve [post]
+func (s *HTTPServer) approveAdjustment(c *gin.Context) {
+	svc := s.periodServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	approver := c.GetString("serviceAccount")
+	if approver == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, "approving an adjustment needs an api key with the admin:approve scope", "UNAUTHENTICATED"))
+		return
+	}
+	resp, err := svc.Approve(c.Request.Context(), c.Param("id"), approver)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// onboardingServiceOrAbort writes 404 and returns nil when onboarding is not configured.
+func (s *HTTPServer) onboardingServiceOrAbort(c *gin.Context) *service.OnboardingService {
+	if s.onboardingService == nil {
+		writeNotConfigured(c, "namespace onboarding")
+	}
+	return s.onboardingService
+}
+
+// onboardNamespace handles POST /api/v1/admin/onboarding - sets up a namespace in one call: owner
+// team and owners, budget alert, SLO alert templates, weekly report subscription and efficiency
+// target tracking; onboarding a namespace again replaces its settings
+// @Summary Onboard a namespace
+// @Tags    Admin
+// @Accept  json
+// @Produce json
+// @Param   request body dto.OnboardNamespaceRequest true "namespace, owner team and settings"
+// @Success 200 {object} dto.NamespaceOnboarding
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/onboarding [post]
+func (s *HTTPServer) onboardNamespace(c *gin.Context) {
+	svc := s.onboardingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.OnboardNamespaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Onboard(c.Request.Context(), req, preferenceUser(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// listOnboardings handles GET /api/v1/admin/onboarding - the onboarded namespaces
+// query: limit (default all), offset or cursor
+// @Summary List onboarded namespaces
+// @Tags    Admin
+// @Produce json
+// @Param   limit query integer false "page size, max 500 (default all)"
+// @Param   offset query integer false "page offset"
+// @Param   cursor query string false "next_cursor of the previous page (instead of offset)"
+// @Success 200 {object} dto.NamespaceOnboardingList
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/onboarding [get]
+func (s *HTTPServer) listOnboardings(c *gin.Context) {
+	svc := s.onboardingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	items, err := svc.List(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	var resp dto.NamespaceOnboardingList
+	resp.Items, resp.ListPage = pageOf(items, listParamsOf(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// getOnboarding handles GET /api/v1/admin/onboarding/:namespace
+// @Summary Get the onboarding of a namespace
+// @Tags    Admin
+// @Produce json
+// @Param   namespace path string true "namespace"
+// @Success 200 {object} dto.NamespaceOnboarding
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /admin/onboarding/{namespace} [get]
+func (s *HTTPServer) getOnboarding(c *gin.Context) {
+	svc := s.onboardingServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.Get(c.Request.Context(), c.Param("namespace"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// preferenceServiceOrAbort writes 404 and returns nil when preferences are not configured.
+func (s *HTTPServer) preferenceServiceOrAbort(c *gin.Context) *service.PreferenceService {
+	if s.preferenceService == nil {
+		writeNotConfigured(c, "preferences")
+	}
+	return s.preferenceService
+}
+
+// preferenceUser returns the identity preferences are stored under: the service account of the API
+// key, else the user forwarded by the auth proxy in front of the cockpit (X-Forwarded-User), else
+// "anonymous" (shared by all unauthenticated users).
+func preferenceUser(c *gin.Context) string {
+	if user := c.GetString("serviceAccount"); user != "" {
+		return user
+	}
+	if user := strings.TrimSpace(c.GetHeader("X-Forwarded-User")); user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+// getPreferences handles GET /api/v1/preferences
+// @Summary Get the preferences of the calling user (defaults when none are saved)
+// @Tags    Preferences
+// @Produce json
+// @Success 200 {object} dto.UserPreferences
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /preferences [get]
+func (s *HTTPServer) getPreferences(c *gin.Context) {
+	svc := s.preferenceServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	resp, err := svc.Get(c.Request.Context(), preferenceUser(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// updatePreferences handles PUT /api/v1/preferences - omitted fields keep their value
+// @Summary Update the preferences of the calling user
+// @Tags    Preferences
+// @Accept  json
+// @Produce json
+// @Param   request body dto.UpdatePreferencesRequest true "changed preferences"
+// @Success 200 {object} dto.UserPreferences
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /preferences [put]
+func (s *HTTPServer) updatePreferences(c *gin.Context) {
+	svc := s.preferenceServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.Update(c.Request.Context(), preferenceUser(c), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// savePreferenceView handles POST /api/v1/preferences/views - a view with the same name and page is replaced
+// @Summary Save a view (named page filters) of the calling user
+// @Tags    Preferences
+// @Accept  json
+// @Produce json
+// @Param   request body dto.SavedViewRequest true "view"
+// @Success 201 {object} dto.SavedView
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /preferences/views [post]
+func (s *HTTPServer) savePreferenceView(c *gin.Context) {
+	svc := s.preferenceServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	var req dto.SavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+		return
+	}
+	resp, err := svc.SaveView(c.Request.Context(), preferenceUser(c), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// deletePreferenceView handles DELETE /api/v1/preferences/views/:id
+// @Summary Delete a saved view of the calling user
+// @Tags    Preferences
+// @Param   id path string true "view ID"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router  /preferences/views/{id} [delete]
+func (s *HTTPServer) deletePreferenceView(c *gin.Context) {
+	svc := s.preferenceServiceOrAbort(c)
+	if svc == nil {
+		return
+	}
+	if err := svc.DeleteView(c.Request.Context(), preferenceUser(c), c.Param("id")); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// writeError responds with the status and code of err's dataerr kind (see dataerr.HTTPStatus);
+// handlers only special-case errors whose response carries more than the message.
+func writeError(c *gin.Context, err error) {
+	status, code := dataerr.HTTPStatus(err)
+	c.JSON(status, errorBody(c, err.Error(), code))
+}
+
+// errorBody is the body of an error response: error is the detail, code the error code (omitted
+// when empty) and message a user-facing explanation of the code in the negotiated locale.
+func errorBody(c *gin.Context, detail, code string) gin.H {
+	key := "error.INTERNAL_ERROR"
+	if code != "" {
+		key = "error." + code
+	}
+	body := gin.H{"error": detail, "message": i18n.T(i18n.FromContext(c.Request.Context()), key)}
+	if code != "" {
+		body["code"] = code
+	}
+	return body
+}
+
+// writeNotConfigured writes the 404 of an endpoint whose service is not configured on this instance.
+func writeNotConfigured(c *gin.Context, feature string) {
+	body := errorBody(c, feature+" not configured", "NOT_FOUND")
+	body["message"] = i18n.T(i18n.FromContext(c.Request.Context()), "error.NOT_CONFIGURED")
+	c.JSON(http.StatusNotFound, body)
+}
+
+// Start begins listening for HTTP requests.
+func (s *HTTPServer) Start() error {
+	addr := fmt.Sprintf(":%d", s.config.Server.Port)
+	s.server = &http.Server{
+		Addr:           addr,
+		Handler:        s.engine,
+		ReadTimeout:    s.config.Server.ReadTimeout,
+		WriteTimeout:   s.config.Server.WriteTimeout,
+		MaxHeaderBytes: 1 << 20, // 1MB
+	}
+
+	fmt.Printf("Starting HTTP server on %s\n", addr)
+	fmt.Printf("Environment: %s\n", s.config.Env)
+	if s.config.Env != config.EnvProduction {
+		fmt.Printf("Swagger UI: http://localhost%s/swagger/index.html\n", addr)
+	}
+
+	return s.server.ListenAndServe()
+}
+
+// StartWithGracefulShutdown starts the server with graceful shutdown handling.
+func (s *HTTPServer) StartWithGracefulShutdown() error {
+	// Start server in a goroutine
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := s.Start(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		return err
 	case <-quit:
 		fmt.Println("Shutting down server...")
 
@@ -334,3 +4529,8 @@ func (s *HTTPServer) Stop(ctx context.Context) error {
 func (s *HTTPServer) Engine() *gin.Engine {
 	return s.engine
 }
+
+// APIMetrics returns the per-route request counters of this server, for /metrics and self-SLOs.
+func (s *HTTPServer) APIMetrics() *apimetrics.Recorder {
+	return s.apiMetrics
+}
@@ -1,15 +1,31 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/myxxhui/lighthouse-src/internal/config"
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+	"github.com/myxxhui/lighthouse-src/internal/data/chaos"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
 	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/querybudget"
+	"github.com/myxxhui/lighthouse-src/internal/data/storage"
+	"github.com/myxxhui/lighthouse-src/internal/exporter"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/internal/server/middleware"
 	"github.com/myxxhui/lighthouse-src/internal/server/service"
+	"github.com/myxxhui/lighthouse-src/internal/worker/spool"
+	"github.com/myxxhui/lighthouse-src/pkg/authz"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -133,6 +149,68 @@ func TestSLOHealthRoute(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "status")
 }
 
+func TestListPagination(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.LatencyMs = 0
+	engine := NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(postgres.NewMockRepository(mockCfg))).Engine()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := adminRequest("GET", path, nil)
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	// 按 next_cursor 逐页读取，页数与 total_estimate 一致
+	var names []string
+	path := "/api/v1/cost/namespaces?limit=1&sort=name&order=asc"
+	for pages := 0; path != ""; pages++ {
+		w := get(path)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp dto.NamespaceCostSummaryList
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Items, 1)
+		assert.Equal(t, strconv.Itoa(resp.TotalEstimate), w.Header().Get(HeaderTotalCount))
+		for _, ns := range resp.Items {
+			names = append(names, ns.Name)
+		}
+		path = ""
+		if resp.NextCursor != "" {
+			path = "/api/v1/cost/namespaces?limit=1&sort=name&order=asc&cursor=" + resp.NextCursor
+		}
+		if !assert.Less(t, pages, resp.TotalEstimate) {
+			break
+		}
+	}
+	assert.Greater(t, len(names), 1)
+	assert.IsIncreasing(t, names)
+
+	w := get("/api/v1/slo/health?limit=2")
+	var health dto.SLOHealthList
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Len(t, health.Items, 2)
+	assert.Equal(t, 3, health.TotalEstimate)
+	w = get("/api/v1/slo/health?limit=2&cursor=" + health.NextCursor)
+	health = dto.SLOHealthList{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	if assert.Len(t, health.Items, 1) {
+		assert.Equal(t, "payment-service", health.Items[0].ServiceName)
+	}
+	assert.Empty(t, health.NextCursor, "last page")
+
+	for _, path := range []string{
+		"/api/v1/cost/namespaces?limit=501",
+		"/api/v1/cost/namespaces?sort=size",
+		"/api/v1/cost/namespaces?offset=1&cursor=" + encodeCursor(1),
+		"/api/v1/cost/namespaces?cursor=bogus",
+		"/api/v1/slo/health?offset=-1",
+		"/api/v1/slo/cost?start_time=2026-03-02T00:00:00Z&end_time=2026-03-01T00:00:00Z",
+	} {
+		w := get(path)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		assert.Contains(t, w.Body.String(), "VALIDATION_FAILED", path)
+	}
+}
+
 func TestROIDashboardRoute(t *testing.T) {
 	cfg := &config.Config{
 		Env: config.EnvDevelopment,
@@ -285,3 +363,2125 @@ func TestGlobalCostL0EqualsL1(t *testing.T) {
 	}
 	assert.InDelta(t, resp.TotalCost, sumL1, 0.01, "L0 total_cost must equal sum of L1 namespace costs (100%%), L0=%.2f sumL1=%.2f", resp.TotalCost, sumL1)
 }
+
+// TestGrafanaDatasourceRoutes verifies the Grafana simple-JSON contract (/search, /query, /annotations).
+func TestGrafanaDatasourceRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	srv.SetGrafanaService(service.NewGrafanaService(mockRepo, service.DefaultMockSLOStatus()))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/grafana", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/grafana/search", strings.NewReader(`{"target":"slo"}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "slo.status")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/grafana/query", strings.NewReader(`{"targets":[{"target":"cost.total"},{"target":"slo.status","type":"table"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	assert.Len(t, results, 2)
+	assert.Equal(t, "cost.total", results[0]["target"])
+	assert.Equal(t, "table", results[1]["type"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/grafana/annotations", strings.NewReader(`{"annotation":{"query":"slo"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "payment-service")
+}
+
+// TestMetricsRoute verifies /metrics serves the configured exporter in Prometheus text format.
+func TestMetricsRoute(t *testing.T) {
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, nil)
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	srv.SetMetricsHandler(exporter.New(mockRepo, service.DefaultMockSLOStatus(), exporter.Config{}).Handler())
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "# TYPE lighthouse_slo_error_budget_remaining gauge")
+}
+
+// TestCalculationRunRoutes verifies listing, inspecting and re-triggering calculation runs.
+func TestCalculationRunRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/calculations/runs", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	calls := 0
+	srv.SetCalculationRunService(service.NewCalculationRunService(mockRepo, func(ctx context.Context, run postgres.CalculationRun) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errors.New("boom")
+		}
+		return 5, nil
+	}))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/calculations/runs", strings.NewReader(`{"window_start":"2020-01-01T00:00:00Z","window_end":"2020-01-01T01:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var failed dto.CalculationRun
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &failed))
+	assert.Equal(t, postgres.CalculationRunFailed, failed.Status)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/calculations/runs/"+failed.ID, nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "boom")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/calculations/runs/"+failed.ID+"/retrigger", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var retry dto.CalculationRun
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &retry))
+	assert.Equal(t, failed.ID, retry.RetryOf)
+	assert.Equal(t, 5, retry.RowsWritten)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/calculations/runs/"+retry.ID+"/retrigger", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "succeeded runs are not retriable")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/calculations/runs?status=succeeded", nil)
+	engine.ServeHTTP(w, req)
+	var list dto.CalculationRunListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Total)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/calculations/runs/missing", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestSpoolAdminRoutes verifies inspecting, flushing and discarding spooled batches.
+func TestSpoolAdminRoutes(t *testing.T) {
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, nil)
+	enableAdmin(srv)
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := adminRequest("GET", "/api/v1/admin/spool", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	sp, err := spool.Open(t.TempDir(), 0)
+	assert.NoError(t, err)
+	replayed := 0
+	srv.SetSpool(sp, spool.Handlers{"stats": func(ctx context.Context, payload json.RawMessage) error {
+		replayed++
+		return nil
+	}})
+	_, _ = sp.Enqueue("stats", []int{1})
+	orphan, _ := sp.Enqueue("orphan", []int{2})
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/spool", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"pending":2`)
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/spool/"+orphan.ID, nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"payload":[2]`)
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("POST", "/api/v1/admin/spool/flush", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, replayed)
+	assert.Contains(t, w.Body.String(), `"skipped":1`)
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("DELETE", "/api/v1/admin/spool/"+orphan.ID, nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 0, sp.Stats().Pending)
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("DELETE", "/api/v1/admin/spool/"+orphan.ID, nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestReadyzBreakers verifies /readyz reports circuit breaker state.
+func TestReadyzBreakers(t *testing.T) {
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, nil)
+	set := breaker.NewSet("prometheus", breaker.Config{MinRequests: 1, OpenTimeout: time.Hour})
+	srv.SetBreakers(set)
+	engine := srv.Engine()
+
+	_ = set.Do("prom-a", func() error { return nil })
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"ready"`)
+
+	_ = set.Do("prom-a", func() error { return errors.New("down") })
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"degraded"`)
+	assert.Contains(t, w.Body.String(), `"state":"open"`)
+}
+
+// TestGradeHistoryRoute verifies the grade history endpoint and its query filters.
+func TestGradeHistoryRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/cost/grades/history", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	ctx := context.Background()
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	_ = mockRepo.SaveGradeChangeEvent(ctx, postgres.GradeChangeEvent{Namespace: "app", WorkloadName: "api", ToGrade: "Healthy", OccurredAt: t0})
+	_ = mockRepo.SaveGradeChangeEvent(ctx, postgres.GradeChangeEvent{Namespace: "app", WorkloadName: "api", FromGrade: "Healthy", ToGrade: "Zombie", OccurredAt: t0.Add(time.Hour)})
+	_ = mockRepo.SaveGradeChangeEvent(ctx, postgres.GradeChangeEvent{Namespace: "web", WorkloadName: "ui", ToGrade: "Risk", OccurredAt: t0})
+	srv.SetGradeHistoryService(service.NewGradeHistoryService(mockRepo))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/cost/grades/history?namespace=app&workload=api", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.GradeHistoryResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Total)
+	assert.Equal(t, "Zombie", resp.Events[1].ToGrade)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/cost/grades/history?start_time=2020-01-01T00:30:00Z", nil)
+	engine.ServeHTTP(w, req)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Total)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/cost/grades/history?limit=-1", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestWorkloadCostRoute verifies the workload cost detail endpoint.
+func TestWorkloadCostRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/workloads/shop/cart/costs", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	h0 := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	_ = mockRepo.SaveHourlyWorkloadStat(context.Background(), postgres.HourlyWorkloadStat{
+		Namespace: "shop", WorkloadName: "cart", Timestamp: h0, CPURequest: 1, CPUUsageP95: 0.5, TotalBillableCost: 1, TotalUsageCost: 0.5,
+	})
+	srv.SetWorkloadService(service.NewWorkloadService(mockRepo))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/workloads/shop/cart/costs?start_time=2020-05-01T00:00:00Z&end_time=2020-05-01T06:00:00Z", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.WorkloadCostDetailResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Series, 1)
+	assert.Equal(t, "Healthy", resp.Grade)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/workloads/shop/missing/costs?start_time=2020-05-01T00:00:00Z&end_time=2020-05-01T06:00:00Z", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/workloads/shop/cart/costs?start_time=bogus", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWorkloadHistoryRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/workloads/shop/cart/history", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	_ = mockRepo.SaveHourlyWorkloadStat(context.Background(), postgres.HourlyWorkloadStat{
+		Namespace: "shop", WorkloadName: "cart", Timestamp: time.Now().UTC().Add(-time.Hour), TotalBillableCost: 1, TotalUsageCost: 0.5,
+	})
+	workloads := service.NewWorkloadService(mockRepo)
+	workloads.SetDailyRollups(mockRepo)
+	srv.SetWorkloadService(workloads)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/workloads/shop/cart/history?days=7", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.WorkloadHistoryResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 7, resp.Days)
+	if assert.NotEmpty(t, resp.Points) {
+		assert.Equal(t, "Healthy", resp.Points[len(resp.Points)-1].Grade)
+	}
+
+	cases := map[string]int{
+		"cart/history?days=abc": http.StatusBadRequest,
+		"cart/history?days=400": http.StatusBadRequest,
+		"missing/history":       http.StatusNotFound,
+	}
+	for q, code := range cases {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/workloads/shop/"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, q)
+	}
+}
+
+func TestNodeAnalysisRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/nodes/analysis", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	k8sCfg := k8s.DefaultMockConfig()
+	k8sCfg.LatencyMs = 0
+	k8sCfg.ErrorRate = 0
+	srv.SetNodeAnalysisService(service.NewNodeAnalysisService(k8s.NewMockClient(k8sCfg), mockRepo, 0.025, 0.01))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/nodes/analysis?target_utilization=0.8", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.NodeAnalysisResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Nodes, len(k8sCfg.Nodes))
+	assert.Equal(t, 0.8, resp.TargetUtilization)
+
+	for _, q := range []string{"target_utilization=abc", "target_utilization=1.5"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/nodes/analysis?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestNodeConsolidationPlanRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/nodes/consolidation-plan", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	k8sCfg := k8s.DefaultMockConfig()
+	k8sCfg.LatencyMs = 0
+	k8sCfg.ErrorRate = 0
+	client := k8s.NewMockClient(k8sCfg)
+	nodeAnalysis := service.NewNodeAnalysisService(client, mockRepo, 0.025, 0.01)
+	nodeAnalysis.SetDisruptionBudgets(client, client)
+	srv.SetNodeAnalysisService(nodeAnalysis)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/nodes/consolidation-plan?target_utilization=0.8", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.NodeConsolidationPlan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.DisruptionBudgetsChecked)
+	assert.NotNil(t, resp.Steps)
+	assert.NotNil(t, resp.Blocked)
+
+	for _, q := range []string{"target_utilization=abc", "target_utilization=1.5"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/nodes/consolidation-plan?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestNodePoolAnalysisRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/nodes/pools", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	k8sCfg := k8s.DefaultMockConfig()
+	k8sCfg.LatencyMs = 0
+	k8sCfg.ErrorRate = 0
+	srv.SetNodeAnalysisService(service.NewNodeAnalysisService(k8s.NewMockClient(k8sCfg), mockRepo, 0.025, 0.01))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/nodes/pools", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.NodePoolAnalysisResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	nodes := 0
+	for _, p := range resp.Pools {
+		nodes += p.NodeCount
+	}
+	assert.Equal(t, len(k8sCfg.Nodes), nodes, "every node belongs to one pool")
+
+	for _, q := range []string{"start_time=bogus", "start_time=2020-01-02T00:00:00Z&end_time=2020-01-01T00:00:00Z"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/nodes/pools?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestSLOCostRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/slo/cost", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetSLOCostService(service.NewSLOCostService(mockRepo, service.DefaultMockSLOStatus(), 0.025, 0.01, 99.9, 500))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/slo/cost", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.SLOCostResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, len(service.DefaultMockSLOStatus()), len(resp.Services)+len(resp.Unmatched))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/slo/cost?end_time=bogus", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAlertRuleRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/alerts/rules", "").Code, "not configured")
+	srv.SetAlertRuleService(service.NewAlertRuleService(mockRepo, mockRepo))
+
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/v1/alerts/rules", `{"name":"bad","condition":"cost >> 1"}`).Code)
+	w := do("POST", "/api/v1/alerts/rules", `{"name":"waste","condition":"waste_ratio > 40","window":"48h","channels":["slack"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var rule dto.AlertRule
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rule))
+	assert.Equal(t, "48h0m0s", rule.Window)
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/alerts/rules", `{"name":"waste","condition":"cost > 1"}`).Code)
+
+	w = do("PUT", "/api/v1/alerts/rules/"+rule.ID, `{"name":"waste","condition":"waste_ratio > 50","enabled":false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rule))
+	assert.False(t, rule.Enabled)
+
+	w = do("POST", "/api/v1/alerts/rules/"+rule.ID+"/evaluate", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var eval dto.AlertRuleEvaluation
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &eval))
+	assert.Len(t, eval.Clauses, 1)
+
+	w = do("GET", "/api/v1/alerts/rules", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list dto.AlertRuleListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, eval.State, list.Rules[0].State)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/v1/alerts/rules/"+rule.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/alerts/rules/"+rule.ID, "").Code)
+}
+
+func TestNotificationPolicyRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/alerts/silences", "").Code, "not configured")
+	srv.SetNotificationPolicyService(service.NewNotificationPolicyService(mockRepo))
+
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/v1/alerts/routes", `{"name":"r"}`).Code)
+	w := do("POST", "/api/v1/alerts/routes", `{"name":"critical","matchers":{"severity":"critical"},"channels":["pagerduty"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var route dto.NotificationRoute
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &route))
+	w = do("PUT", "/api/v1/alerts/routes/"+route.ID, `{"name":"critical","matchers":{"severity":"critical"},"channels":["opsgenie"],"priority":5}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &route))
+	assert.Equal(t, []string{"opsgenie"}, route.Channels)
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/v1/alerts/routes/"+route.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/alerts/routes/"+route.ID, "").Code)
+
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/v1/alerts/silences", `{"matchers":{"team":"x"}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/v1/alerts/silences", `{"matchers":{"team":"x"},"reason":"r","duration":"forever"}`).Code)
+	w = do("POST", "/api/v1/alerts/silences", `{"matchers":{"team":"x"},"reason":"migration","duration":"1h"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var silence dto.Silence
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &silence))
+	assert.True(t, silence.Active)
+
+	w = do("GET", "/api/v1/alerts/silences", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list dto.SilenceListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Total)
+
+	w = do("DELETE", "/api/v1/alerts/silences/"+silence.ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &silence))
+	assert.False(t, silence.Active)
+	assert.NoError(t, json.Unmarshal(do("GET", "/api/v1/alerts/silences", "").Body.Bytes(), &list))
+	assert.Equal(t, 0, list.Total)
+	assert.NoError(t, json.Unmarshal(do("GET", "/api/v1/alerts/silences?all=true", "").Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/api/v1/alerts/silences?all=maybe", "").Code)
+}
+
+func TestLineageRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("GET", "/api/v1/cost/global")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderLineageID), "no lineage without the service")
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/lineage/x").Code)
+
+	lineage := service.NewLineageService(mockRepo, mockRepo)
+	lineage.SetCalculationRuns(mockRepo)
+	srv.SetLineageService(lineage)
+
+	w = do("GET", "/api/v1/cost/namespaces?limit=5")
+	assert.Equal(t, http.StatusOK, w.Code)
+	id := w.Header().Get(HeaderLineageID)
+	assert.NotEmpty(t, id)
+	w = do("GET", "/api/v1/lineage/"+id)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got dto.QueryLineage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "/api/v1/cost/namespaces?limit=5", got.Path)
+	assert.Equal(t, http.StatusOK, got.Status)
+	assert.NotNil(t, got.Snapshot)
+
+	// failed queries carry no lineage
+	w = do("GET", "/api/v1/cost/efficiency/heatmap?days=many")
+	assert.GreaterOrEqual(t, w.Code, http.StatusBadRequest)
+	assert.Empty(t, w.Header().Get(HeaderLineageID))
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/lineage/missing").Code)
+}
+
+func TestSnapshotQueryRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	engine := NewHTTPServer(cfg, service.NewCostService(mockRepo)).Engine()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := adminRequest("GET", path, nil)
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/snapshots?limit=1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list dto.CostSnapshotListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Total)
+	snap := list.Snapshots[0]
+	assert.Equal(t, []string{"counts", "summary"}, snap.Fields, "list defaults to summary")
+	assert.NotNil(t, snap.Summary)
+	assert.Nil(t, snap.ResourceResults)
+	assert.NotContains(t, w.Body.String(), "resource_results")
+
+	w = get("/api/v1/snapshots/" + snap.ID + "?fields=counts")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var one dto.CostSnapshot
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &one))
+	assert.NotNil(t, one.Counts)
+	assert.Nil(t, one.Summary)
+
+	w = get("/api/v1/snapshots/" + snap.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	one = dto.CostSnapshot{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &one))
+	assert.Len(t, one.Fields, 4, "a single snapshot defaults to full")
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/snapshots?fields=everything").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/snapshots?include=pods").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/snapshots/missing").Code)
+	assert.Contains(t, get("/api/v1/snapshots/suspect").Body.String(), "guardrail not configured", "static routes still win over :id")
+}
+
+// TestReadyzDataFreshness verifies /readyz waits for the first data sync and cost responses carry the data cut-off.
+func TestReadyzDataFreshness(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	freshness := service.NewDataFreshnessService(mockRepo)
+	freshness.SetCalculationRuns(mockRepo)
+	freshness.SetCacheTTL(0)
+	srv.SetDataFreshnessService(freshness)
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"not_ready"`)
+	assert.Contains(t, w.Body.String(), `"synced":false`)
+
+	windowEnd := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	_ = mockRepo.SaveCalculationRun(context.Background(), postgres.CalculationRun{ID: "run-1", Status: postgres.CalculationRunSucceeded, WindowEnd: windowEnd, StartedAt: windowEnd})
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"ready"`)
+	assert.Contains(t, w.Body.String(), `"calculation_run_id":"run-1"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/cost/global", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, "2020-06-01T00:00:00Z", w.Header().Get(middleware.HeaderDataAsOf))
+	assert.Empty(t, w.Header().Get(middleware.HeaderStale))
+}
+
+func TestAccountingPeriodRoutes(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	srv := NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(mockRepo))
+	enableAdmin(srv)
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := adminRequest("GET", "/api/v1/admin/periods", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetPeriodCloseService(service.NewPeriodCloseService(mockRepo, costmodel.FiscalCalendar{}))
+	for q, code := range map[string]int{"date=2020-07-10": http.StatusCreated, "date=bogus": http.StatusBadRequest, "date=2999-01-01": http.StatusBadRequest} {
+		w = httptest.NewRecorder()
+		req, _ = adminRequest("POST", "/api/v1/admin/periods/close?"+q, strings.NewReader(`{"note":"July close"}`))
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, q+": "+w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("POST", "/api/v1/admin/periods/close?date=2020-07-31", http.NoBody)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code, "already closed")
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/periods", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var periods dto.AccountingPeriodList
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &periods))
+	if assert.Len(t, periods.Items, 1) {
+		assert.Equal(t, "FY2020 P07", periods.Items[0].Label)
+		assert.Equal(t, "July close", periods.Items[0].Note)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/adjustments?period=FY2020+P07", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"items":[]`)
+
+	var pending dto.CostAdjustment
+	for body, code := range map[string]int{
+		`{"date":"2020-07-12","namespace":"shop","amount":-5,"reason":"credit note"}`: http.StatusCreated,
+		`{"date":"2020-07-12","namespace":"shop","amount":-5}`:                        http.StatusBadRequest,
+		`{"date":"2020-08-12","namespace":"shop","amount":-5,"reason":"credit note"}`: http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		req, _ = adminRequest("POST", "/api/v1/admin/adjustments", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, body+": "+w.Body.String())
+		if w.Code == http.StatusCreated {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &pending))
+		}
+	}
+	assert.Equal(t, postgres.AdjustmentPending, pending.Status)
+
+	// 批准人取自 API key 的服务账号，须持 admin:approve scope；请求体中的身份不被采信
+	approve := func(key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/admin/adjustments/"+pending.ID+"/approve", strings.NewReader(`{"approver":"cfo"}`))
+		req.Header.Set("X-Forwarded-User", "cfo")
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusUnauthorized, approve("").Code, "approval needs an authenticated principal")
+	accounts := service.NewServiceAccountService(mockRepo)
+	accounts.SetBootstrapKey("bootstrap-key-0123456789abcdef")
+	srv.SetServiceAccountService(accounts)
+	keyWith := func(name, scope string) string {
+		w := httptest.NewRecorder()
+		req, _ := adminRequest("POST", "/api/v1/admin/service-accounts", strings.NewReader(`{"name":"`+name+`"}`))
+		req.Header.Set("X-API-Key", "bootstrap-key-0123456789abcdef")
+		engine.ServeHTTP(w, req)
+		var account dto.ServiceAccount
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &account))
+		w = httptest.NewRecorder()
+		req, _ = adminRequest("POST", "/api/v1/admin/service-accounts/"+account.ID+"/keys", strings.NewReader(`{"scopes":["`+scope+`"]}`))
+		req.Header.Set("X-API-Key", "bootstrap-key-0123456789abcdef")
+		engine.ServeHTTP(w, req)
+		var created dto.CreatedAPIKey
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created.Secret
+	}
+	assert.Equal(t, http.StatusForbidden, approve(keyWith("ops", "admin:write")).Code, "write does not imply approve")
+	w = approve(keyWith("finance", "admin:approve"))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var approved dto.CostAdjustment
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &approved))
+	assert.Equal(t, postgres.AdjustmentApproved, approved.Status)
+	assert.Equal(t, "finance", approved.Approver)
+	assert.Equal(t, http.StatusConflict, approve(keyWith("cfo", "admin:approve")).Code, "already approved")
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/adjustments?period=FY2020+P07", nil)
+	engine.ServeHTTP(w, req)
+	var adjustments dto.CostAdjustmentList
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &adjustments))
+	if assert.Len(t, adjustments.Items, 1) {
+		assert.Equal(t, postgres.AdjustmentSourceManual, adjustments.Items[0].Source)
+		assert.Equal(t, "finance", adjustments.Items[0].Approver)
+	}
+}
+
+func TestOnboardingRoutes(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	srv := NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(mockRepo))
+	enableAdmin(srv)
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := adminRequest("GET", "/api/v1/admin/onboarding", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	onboarding := service.NewOnboardingService(mockRepo, service.NewEfficiencyTargetService(mockRepo))
+	onboarding.SetAlertRules(service.NewAlertRuleService(mockRepo, mockRepo))
+	srv.SetOnboardingService(onboarding)
+	for body, code := range map[string]int{
+		`{"namespace":"shop","team":"commerce","owners":["ann@example.com"],"monthly_budget":500}`: http.StatusOK,
+		`{"namespace":"shop"}`:                                               http.StatusBadRequest,
+		`{"namespace":"Shop!","team":"commerce"}`:                            http.StatusBadRequest,
+		`{"namespace":"shop","team":"commerce","slo_templates":["latency"]}`: http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		req, _ = adminRequest("POST", "/api/v1/admin/onboarding", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, body+": "+w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/onboarding/shop", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var rec dto.NamespaceOnboarding
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rec))
+	assert.Equal(t, "commerce", rec.Team)
+	assert.Equal(t, []string{"ann@example.com"}, rec.ReportRecipients)
+	assert.Len(t, rec.Steps, 6) // one slo step per template
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/onboarding/search", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/onboarding?limit=10", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list dto.NamespaceOnboardingList
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Items, 1)
+}
+
+func TestDependencyRoutes(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	srv := NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/workloads/shop/payments/impact", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetDependencyService(service.NewDependencyService(mockRepo, mockRepo, 0))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/dependencies/edges", strings.NewReader(`{"source":"tracing","edges":[
+		{"caller_namespace":"shop","caller":"frontend","callee":"checkout","calls_per_minute":50},
+		{"caller_namespace":"shop","caller":"checkout","callee":"payments","calls_per_minute":20}]}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"accepted":2`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/dependencies/edges", strings.NewReader(`{"source":"guess","edges":[]}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/dependencies?limit=1", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var edges dto.DependencyEdgeList
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &edges))
+	assert.Len(t, edges.Items, 1)
+	assert.Equal(t, 2, edges.TotalEstimate)
+	assert.NotEmpty(t, edges.NextCursor)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/workloads/shop/payments/impact", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var impact dto.DependencyImpact
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &impact))
+	if assert.Len(t, impact.Upstream, 2) {
+		assert.Equal(t, "checkout", impact.Upstream[0].Service)
+		assert.Equal(t, "frontend", impact.Upstream[1].Service)
+		assert.Equal(t, 2, impact.Upstream[1].Depth)
+	}
+}
+
+func TestTimelineRoutes(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/timeline", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	timeline := service.NewTimelineService(mockRepo)
+	timeline.SetCalculationRuns(mockRepo)
+	srv.SetTimelineService(timeline)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/timeline/config-changes", strings.NewReader(`{"namespace":"shop","kind":"Deployment","name":"cart","change_type":"helm_upgrade","new_value":"chart 2.1.0","occurred_at":"2020-07-01T10:00:00Z"}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/timeline/slo-violations", strings.NewReader(`{"namespace":"shop","service":"cart","violation_type":"availability","actual_value":99.2,"threshold_value":99.9,"violated_at":"2020-07-01T10:30:00Z"}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/timeline/slo-violations", strings.NewReader(`{"namespace":"shop","service":"cart","violation_type":"availability","severity":"page"}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/timeline?namespace=shop&start_time=2020-07-01T00:00:00Z&end_time=2020-07-02T00:00:00Z", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.TimelineResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Events, 2) {
+		assert.Equal(t, dto.TimelineKindConfigChange, resp.Events[0].Kind)
+		assert.Equal(t, "Deployment/cart", resp.Events[0].Object)
+		assert.Equal(t, dto.TimelineKindSLOViolation, resp.Events[1].Kind)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/timeline?kinds=deploys", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuthorizerHook(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	accounts := service.NewServiceAccountService(mockRepo)
+	accounts.SetBootstrapKey("bootstrap-key-0123456789abcdef")
+	srv.SetServiceAccountService(accounts)
+	srv.SetTimelineService(service.NewTimelineService(mockRepo))
+	engine := srv.Engine()
+
+	var seen []authz.Request
+	var iamDown bool
+	srv.SetAuthorizer(authz.AuthorizerFunc(func(_ context.Context, req authz.Request) (authz.Decision, error) {
+		seen = append(seen, req)
+		if iamDown {
+			return authz.Decision{}, errors.New("connection refused")
+		}
+		if req.Action == authz.ActionWrite && req.Subject == authz.AnonymousSubject {
+			return authz.Decision{Reason: "anonymous writes are not allowed"}, nil
+		}
+		return authz.Decision{Allowed: true}, nil
+	}))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/timeline?namespace=shop", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/timeline/config-changes", strings.NewReader(`{"kind":"Deployment","name":"cart","change_type":"scale"}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "anonymous writes are not allowed")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/timeline/config-changes", strings.NewReader(`{"kind":"Deployment","name":"cart","change_type":"scale"}`))
+	req.Header.Set("X-API-Key", "bootstrap-key-0123456789abcdef")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	if assert.Len(t, seen, 3) {
+		assert.Equal(t, authz.Request{Subject: authz.AnonymousSubject, Resource: "timeline", Action: authz.ActionRead, Namespace: "shop", Method: "GET", Path: "/api/v1/timeline"}, seen[0])
+		assert.NotEqual(t, authz.AnonymousSubject, seen[2].Subject)
+		assert.Equal(t, authz.ActionWrite, seen[2].Action)
+	}
+
+	// Authorizer errors fail closed unless fail_open is set
+	iamDown = true
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/timeline", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	cfg.Security.Authz.FailOpen = true
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/timeline", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Routes outside /api/v1 are not authorized
+	seen = nil
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/health", nil)
+	engine.ServeHTTP(w, req)
+	assert.Empty(t, seen)
+}
+
+func TestPreferenceRoutes(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/preferences", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetPreferenceService(service.NewPreferenceService(mockRepo))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/v1/preferences", strings.NewReader(`{"default_namespaces":["shop"],"currency":"usd"}`))
+	req.Header.Set("X-Forwarded-User", "alice")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/preferences/views", strings.NewReader(`{"name":"shop waste","page":"/cost/namespaces","filters":{"namespace":"shop"}}`))
+	req.Header.Set("X-Forwarded-User", "alice")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var view dto.SavedView
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/preferences", nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var prefs dto.UserPreferences
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+	assert.Equal(t, "alice", prefs.User)
+	assert.Equal(t, []string{"shop"}, prefs.DefaultNamespaces)
+	assert.Equal(t, "USD", prefs.Currency)
+	assert.Len(t, prefs.SavedViews, 1)
+
+	// Another identity sees its own defaults
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/preferences", nil)
+	engine.ServeHTTP(w, req)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+	assert.Equal(t, "anonymous", prefs.User)
+	assert.Empty(t, prefs.SavedViews)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/v1/preferences", strings.NewReader(`{"units":{"cpu":"vcpu"}}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v1/preferences/views/"+view.ID, nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v1/preferences/views/"+view.ID, nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdmissionWebhookRoutes(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	cfg.Security.APIKeys.Required = true
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+	body := `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":"u1","kind":{"group":"apps","version":"v1","kind":"Deployment"},
+		"namespace":"shop","name":"api","operation":"CREATE","object":{"metadata":{"name":"api"},"spec":{"replicas":1,"template":{"spec":{"containers":[{"resources":{"requests":{"cpu":"1"}}}]}}}}}}`
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admission/mutate", strings.NewReader(body))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetAdmissionService(service.NewAdmissionService(mockRepo, 0.1, 0.01))
+
+	// Called by the API server without an API key
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/admission/mutate", strings.NewReader(body))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var review dto.AdmissionReview
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &review))
+	if assert.NotNil(t, review.Response) {
+		assert.True(t, review.Response.Allowed)
+		assert.Equal(t, "u1", review.Response.UID)
+		assert.Contains(t, string(review.Response.Patch), `"73.00"`)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/admission/validate", strings.NewReader(body))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "patch")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/admission/validate", strings.NewReader(`{"apiVersion":"admission.k8s.io/v1"}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCostImportRoute(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	srv := NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(mockRepo))
+	enableAdmin(srv)
+	engine := srv.Engine()
+	csv := "namespace,controller,window start,window end,cpu cost\nshop,api,2020-05-01T00:00:00Z,2020-05-01T02:00:00Z,4\n"
+	post := func(query, contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := adminRequest("POST", "/api/v1/admin/import/allocations"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, post("", "text/csv", csv).Code)
+
+	srv.SetCostImportService(service.NewCostImportService(mockRepo))
+	w := post("?dry_run=true", "text/csv", csv)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp dto.CostImportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "csv", resp.Format)
+	assert.Equal(t, 2, resp.HourlyRows)
+	assert.True(t, resp.DryRun)
+
+	w = post("?source=opencost&format=json", "application/json", `[{"pay": {"window": {"start": "2020-05-01T00:00:00Z", "end": "2020-05-01T01:00:00Z"}, "cpuCost": 1}}]`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "opencost", resp.Source)
+	assert.Equal(t, 1, resp.DailyRows)
+
+	assert.Equal(t, http.StatusBadRequest, post("?source=cloudhealth", "text/csv", csv).Code)
+	assert.Equal(t, http.StatusBadRequest, post("?dry_run=maybe", "text/csv", csv).Code)
+	assert.Equal(t, http.StatusBadRequest, post("", "application/json", csv).Code)
+}
+
+func TestSelfSLORoute(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	srv := NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(postgres.NewMockRepository(mockCfg)))
+	engine := srv.Engine()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := adminRequest("GET", path, nil)
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/slo/self").Code)
+
+	srv.SetSelfSLOService(service.NewSelfSLOService(srv.APIMetrics(), 99.9, 200))
+	get("/api/v1/slo/health")
+	get("/api/v1/no-such-route")
+	w := get("/api/v1/slo/self")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp dto.SelfSLOResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	routes := map[string]dto.SelfSLORoute{}
+	for _, r := range resp.Routes {
+		routes[r.Method+" "+r.Route] = r
+	}
+	assert.Equal(t, int64(1), routes["GET /api/v1/slo/health"].Requests)
+	assert.Equal(t, int64(1), routes["GET /api/v1/slo/self"].Requests, "the first 404 counts, not yet the request being served")
+	assert.Len(t, routes, 2, "unmatched requests are not recorded")
+	assert.Equal(t, "healthy", resp.Status)
+}
+
+func TestQueryBudgets(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.LatencyMs = 0
+	srv := NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(storage.NewBudgetRepository(postgres.NewMockRepository(mockCfg))))
+	enableAdmin(srv)
+	engine := srv.Engine()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := adminRequest("GET", path, nil)
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/admin/query-budgets").Code)
+
+	srv.SetQueryBudgets(querybudget.NewLedger(time.Hour, querybudget.Quota{DatabaseQueries: 1}, nil))
+	w := get("/api/v1/cost/global")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = get("/api/v1/cost/global")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "QUERY_BUDGET_EXCEEDED")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	w = get("/api/v1/admin/query-budgets")
+	assert.Equal(t, http.StatusOK, w.Code, "admin endpoints stay available over quota")
+	var resp dto.QueryBudgetResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Consumers, 1) {
+		c := resp.Consumers[0]
+		assert.Equal(t, "anonymous", c.Consumer)
+		assert.GreaterOrEqual(t, c.DatabaseQueries, int64(1))
+		assert.Equal(t, 1, c.DatabaseQueryLimit)
+		assert.Equal(t, "database", c.Exceeded)
+	}
+	assert.Equal(t, time.Hour, resp.WindowEnd.Sub(resp.WindowStart))
+}
+
+func TestChaosDrills(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.LatencyMs = 0
+	drills := chaos.NewController(map[string]chaos.Scenario{"database-outage": {chaos.Database: {ErrorRate: 1}}}, time.Hour)
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(storage.NewChaosRepository(postgres.NewMockRepository(mockCfg), drills)))
+	enableAdmin(srv)
+	engine := srv.Engine()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := adminRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/admin/chaos", "").Code)
+	srv.SetChaos(drills)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/admin/chaos", "").Code, "chaos.enabled is not set")
+	cfg.Chaos.Enabled = true
+
+	req, _ := http.NewRequest("POST", "/api/v1/admin/chaos", strings.NewReader(`{"scenario":"database-outage","duration":"5m"}`))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "drills need an admin key")
+
+	w = do("GET", "/api/v1/admin/chaos", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp dto.ChaosResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Active)
+	assert.Nil(t, resp.Drill)
+	assert.Equal(t, int64(3600), resp.MaxDurationSeconds)
+	if assert.Len(t, resp.Scenarios, 1) {
+		assert.Equal(t, 1.0, resp.Scenarios[0].Faults["database"].ErrorRate)
+	}
+
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/cost/global", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/v1/admin/chaos", `{"scenario":"database-outage","duration":"2h"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/v1/admin/chaos", `{"scenario":"database-outage","duration":"soon"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/v1/admin/chaos", `{"scenario":"missing","duration":"5m"}`).Code)
+
+	w = do("POST", "/api/v1/admin/chaos", `{"scenario":"database-outage","duration":"5m"}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/v1/admin/chaos", `{"scenario":"database-outage","duration":"5m"}`).Code)
+	w = do("GET", "/api/v1/cost/global", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "the drill fails the database like an outage: %s", w.Body.String())
+
+	w = do("DELETE", "/api/v1/admin/chaos", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp = dto.ChaosResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Active)
+	if assert.NotNil(t, resp.Drill) {
+		assert.NotNil(t, resp.Drill.StoppedAt)
+		assert.Equal(t, int64(1), resp.Drill.Injected["database"].Errors)
+	}
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/api/v1/admin/chaos", "").Code)
+	assert.Equal(t, http.StatusOK, do("GET", "/api/v1/cost/global", "").Code)
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	cfg := &config.Config{Env: config.EnvDevelopment, I18n: config.I18nConfig{DefaultLocale: "zh-CN"}}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	srv.SetCostImportService(service.NewCostImportService(mockRepo))
+	enableAdmin(srv)
+	engine := srv.Engine()
+	do := func(method, path, acceptLanguage string) (*httptest.ResponseRecorder, map[string]string) {
+		w := httptest.NewRecorder()
+		req, _ := adminRequest(method, path, nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		engine.ServeHTTP(w, req)
+		var body map[string]string
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+
+	w, body := do("GET", "/api/v1/slo/self", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "zh-CN", w.Header().Get("Content-Language"), "default locale applies without Accept-Language")
+	assert.Equal(t, "self SLOs not configured", body["error"], "the detail stays untranslated")
+	assert.Equal(t, "该功能未在此 Lighthouse 实例上启用。", body["message"])
+
+	w, body = do("POST", "/api/v1/admin/import/allocations?dry_run=maybe", "en-GB,en;q=0.9")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Equal(t, "VALIDATION_FAILED", body["code"])
+	assert.Equal(t, "The request is invalid; check its parameters.", body["message"])
+}
+
+func TestCapacitySimulationRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/capacity/simulation", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	k8sCfg := k8s.DefaultMockConfig()
+	k8sCfg.LatencyMs = 0
+	client := k8s.NewMockClient(k8sCfg)
+	capacity := service.NewCapacityService(client, mockRepo, 0.025, 0.01)
+	srv.SetCapacityService(capacity)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/capacity/simulation", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "no pod lister")
+
+	capacity.SetPodLister(client)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/capacity/simulation?request_change_percent=-20", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.CapacitySimulationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, -20.0, resp.RequestChangePercent)
+	assert.GreaterOrEqual(t, resp.NodeReduction, 0)
+	assert.NotEmpty(t, resp.Pools)
+
+	cases := map[string]int{
+		"request_change_percent=abc":  http.StatusBadRequest,
+		"request_change_percent=-100": http.StatusBadRequest,
+		"target_utilization=2":        http.StatusBadRequest,
+		"pool=bogus":                  http.StatusNotFound,
+	}
+	for q, code := range cases {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/capacity/simulation?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, q)
+	}
+}
+
+func TestCapacityProjectionRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/capacity/projection", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	k8sCfg := k8s.DefaultMockConfig()
+	k8sCfg.LatencyMs = 0
+	srv.SetCapacityService(service.NewCapacityService(k8s.NewMockClient(k8sCfg), mockRepo, 0.025, 0.01))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/capacity/projection?horizon_days=30&node_delta=1", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.CapacityProjectionResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Series, 31)
+	if assert.NotNil(t, resp.Scenario) {
+		assert.Equal(t, resp.Baseline.NodeCount+1, resp.Scenario.NodeCount)
+	}
+
+	cases := map[string]int{
+		"horizon_days=abc":        http.StatusBadRequest,
+		"horizon_days=10000":      http.StatusBadRequest,
+		"node_delta=1&pool=bogus": http.StatusNotFound,
+	}
+	for q, code := range cases {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/capacity/projection?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, q)
+	}
+}
+
+func TestEfficiencyHeatmapRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/cost/efficiency/heatmap", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetEfficiencyTargetService(service.NewEfficiencyTargetService(mockRepo))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/cost/efficiency/heatmap?days=14&bucket_days=7&limit=3", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.EfficiencyHeatmapResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 7, resp.BucketDays)
+	assert.Len(t, resp.Buckets, 2)
+	assert.True(t, len(resp.Rows) <= 3, "limit")
+	for _, r := range resp.Rows {
+		assert.Len(t, r.Cells, 2, r.Namespace)
+	}
+
+	for _, q := range []string{"days=abc", "days=1000", "bucket_days=0x", "days=7&bucket_days=8"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/cost/efficiency/heatmap?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestOffHoursAnalysisRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/analysis/offhours", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetOffHoursService(service.NewOffHoursService(mockRepo, costmodel.DefaultBusinessHours(), 0))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/analysis/offhours?days=3&limit=5", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.OffHoursAnalysisResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "UTC", resp.BusinessHours.Timezone)
+	assert.True(t, resp.Total <= 5, "limit")
+
+	for _, q := range []string{"days=abc", "days=100", "limit=x", "limit=-1"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/analysis/offhours?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestAllocationPreviewRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+	body := `{"rules":[{"name":"lb","billing":"fixed","monthly_cost":300,"allocation_key":"billable"}]}`
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/cost/allocation/preview", strings.NewReader(body))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	current := []costmodel.SharedResource{{Name: "lb", Billing: costmodel.SharedBillingFixed, MonthlyCost: 300, AllocationKey: costmodel.AllocateEvenly}}
+	srv.SetAllocationPreviewService(service.NewAllocationPreviewService(mockRepo, current, nil))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/cost/allocation/preview", strings.NewReader(body))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.AllocationPreviewResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 30, resp.Days)
+	assert.InDelta(t, resp.CurrentSharedCost, resp.CandidateSharedCost, 0.1, "same resource, different key")
+
+	for _, b := range []string{`{`, `{"rules":[{"name":"lb","billing":"fixed","allocation_key":"random"}]}`} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/cost/allocation/preview", strings.NewReader(b))
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, b)
+	}
+}
+
+func TestWorkloadCatalogRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/workloads/catalog", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	catalog := service.NewCatalogService(mockRepo, service.DefaultMockSLOStatus(), nil)
+	srv.SetCatalogService(catalog)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/workloads/catalog", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "not refreshed")
+
+	assert.NoError(t, catalog.Refresh(context.Background()))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/workloads/catalog?limit=2", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.CatalogSearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, len(resp.Workloads) <= 2, "limit")
+
+	for _, q := range []string{"limit=x", "offset=-1"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/workloads/catalog?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestDatasetExportRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	enableAdmin(srv)
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := adminRequest("GET", "/api/v1/admin/dataset/export", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetDatasetExportService(service.NewDatasetExportService(mockRepo, nil))
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/dataset/export?anonymize=true&scale=2", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var ds dto.Dataset
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &ds))
+	assert.True(t, ds.Anonymized)
+	assert.Equal(t, 2.0, ds.Scale)
+
+	for _, q := range []string{"anonymize=maybe", "scale=0", "scale=x", "start_time=x", "start_time=2026-01-01T00:00:00Z&end_time=2026-03-01T00:00:00Z"} {
+		w = httptest.NewRecorder()
+		req, _ = adminRequest("GET", "/api/v1/admin/dataset/export?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestExportJobRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/exports", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	exports := service.NewExportJobService(service.NewDatasetExportService(mockRepo, nil), t.TempDir(), time.Hour)
+	srv.SetExportJobService(exports)
+	for _, body := range []string{`{"format":"csv"}`, `{"kind":"namespace_costs","format":"xlsx"}`} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/exports", strings.NewReader(body))
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/exports", strings.NewReader(`{"kind":"namespace_costs","format":"csv"}`))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var job dto.ExportJob
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, "/api/v1/exports/"+job.ID, w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/exports/"+job.ID+"/download", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code, "queued job has no file")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go exports.Run(ctx, 1, nil)
+	assert.Eventually(t, func() bool {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/exports/"+job.ID, nil)
+		engine.ServeHTTP(w, req)
+		return json.Unmarshal(w.Body.Bytes(), &job) == nil && job.Status == service.ExportJobSucceeded
+	}, 5*time.Second, 10*time.Millisecond)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", job.DownloadURL, nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), job.FileName)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "date,namespace,billable_cost"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/exports/missing", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestROIBaselineCompareRoute(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/roi/baselines/jan/compare", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	ctx := context.Background()
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_ = mockRepo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "payments", Date: jan, BillableCost: 1000, WasteCost: 400})
+	_ = mockRepo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "payments", Date: jan.AddDate(0, 2, 0), BillableCost: 700, WasteCost: 100})
+	_ = mockRepo.SaveROIBaseline(ctx, postgres.ROIBaseline{ID: "jan", Name: "january", TimePeriodStart: jan, TimePeriodEnd: jan.AddDate(0, 0, 1)})
+	srv.SetROIComparisonService(service.NewROIComparisonService(mockRepo))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/roi/baselines/jan/compare?date=2026-03-01", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.ROIBaselineComparisonResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 300.0, resp.Comparison.CostSavingsAmount)
+	assert.Equal(t, 75.0, resp.Comparison.ResourceRecoveryRate)
+
+	for path, code := range map[string]int{
+		"/api/v1/roi/baselines/jan/compare?date=03-01":        http.StatusBadRequest,
+		"/api/v1/roi/baselines/jan/compare?date=2999-01-01":   http.StatusBadRequest,
+		"/api/v1/roi/baselines/other/compare?date=2026-03-01": http.StatusNotFound,
+	} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", path, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, path)
+	}
+}
+
+func TestRegradeRoutes(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockRepo := postgres.NewMockRepository(mockCfg)
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	enableAdmin(srv)
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/snapshots/grades", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	_ = mockRepo.SaveCostSnapshot(context.Background(), postgres.CostSnapshot{ID: "s1", Timestamp: time.Now().Add(-time.Hour),
+		ResourceResults: []costmodel.CostResult{{OverallEfficiencyScore: 12}, {OverallEfficiencyScore: 60}}, OverProvisionedCount: 1, HealthyCount: 1})
+	srv.SetRegradeService(service.NewRegradeService(mockRepo, mockRepo, costmodel.DefaultGradeThresholds))
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("POST", "/api/v1/admin/regrade", strings.NewReader(`{"thresholds":{"zombie":15,"over_provisioned":40,"risk":90}}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var job dto.RegradeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, 1, job.Changed)
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("PUT", "/api/v1/admin/regrade/view", strings.NewReader(`{"view":"regraded"}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/snapshots/grades", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var grades dto.SnapshotGradesResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &grades))
+	assert.Equal(t, "regraded", grades.View)
+	assert.Equal(t, dto.GradeCounts{Zombie: 1, Healthy: 1}, grades.Total)
+
+	for _, tc := range []struct{ method, path, body string }{
+		{"GET", "/api/v1/snapshots/grades?view=latest", ""},
+		{"PUT", "/api/v1/admin/regrade/view", `{"view":"latest"}`},
+		{"POST", "/api/v1/admin/regrade", `{"thresholds":{"zombie":50,"over_provisioned":40,"risk":90}}`},
+	} {
+		w = httptest.NewRecorder()
+		req, _ = adminRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, tc.path)
+	}
+}
+
+func TestRecommendationAdoptionRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/roi/recommendations/adoption", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	_ = mockRepo.SaveIssuedRecommendation(context.Background(), postgres.IssuedRecommendation{Team: "pay", Namespace: "payments", WorkloadName: "ledger",
+		Action: "downsize", Resource: "cpu", EstimatedMonthlySavings: 100, Status: "open", IssuedAt: time.Now().Add(-time.Hour)})
+	srv.SetRecommendationAdoptionService(service.NewRecommendationAdoptionService(mockRepo))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/roi/recommendations/adoption", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.RecommendationAdoptionResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Total.Issued)
+	assert.Equal(t, 100.0, resp.Total.UnrealizedMonthlySavings)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/roi/dashboard", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"unrealized_savings":100`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/roi/recommendations/adoption?since=yesterday", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRecommendationExportRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	body := `{"recommendation_ids":["rec-ledger"]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/roi/recommendations/export", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	_ = mockRepo.SaveIssuedRecommendation(context.Background(), postgres.IssuedRecommendation{ID: "rec-ledger", Team: "pay", Namespace: "payments", WorkloadName: "ledger",
+		Action: "downsize", Resource: "cpu", BaselineRequest: 4, RecommendedRequest: 2, EstimatedMonthlySavings: 100, Status: "open", IssuedAt: time.Now().Add(-time.Hour)})
+	srv.SetRecommendationExportService(service.NewRecommendationExportService(mockRepo, mockRepo))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/roi/recommendations/export", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".zip")
+	bundleID := w.Header().Get("X-Bundle-ID")
+	assert.NotEmpty(t, bundleID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/roi/optimizations?status=pending&bundle_id="+bundleID, nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var records dto.OptimizationRecordListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+	if assert.Len(t, records.Items, 1) {
+		assert.Equal(t, "rec-ledger", records.Items[0].RecommendationID)
+		assert.False(t, records.Items[0].Verified)
+	}
+
+	for _, body := range []string{`{"recommendation_ids":[]}`, `{"recommendation_ids":["rec-unknown"]}`} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/roi/recommendations/export", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestServiceAccountRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	cfg.Security.APIKeys.Required = true
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+	do := func(method, path, key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/cost/global", "", "").Code, "key required")
+	assert.Equal(t, http.StatusOK, do("GET", "/health", "", "").Code, "health is not behind the key")
+
+	accounts := service.NewServiceAccountService(mockRepo)
+	accounts.SetBootstrapKey("bootstrap-key-0123456789abcdef")
+	srv.SetServiceAccountService(accounts)
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/cost/global", "lh_wrong", "").Code)
+
+	w := do("POST", "/api/v1/admin/service-accounts", "bootstrap-key-0123456789abcdef", `{"name":"ci"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var account dto.ServiceAccount
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &account))
+	assert.Equal(t, "bootstrap", account.CreatedBy)
+
+	w = do("POST", "/api/v1/admin/service-accounts/"+account.ID+"/keys", "bootstrap-key-0123456789abcdef", `{"name":"deploy","scopes":["cost:read"],"daily_quota":1}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created dto.CreatedAPIKey
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = do("GET", "/api/v1/cost/global", created.Secret, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/admin/service-accounts", created.Secret, "").Code)
+	w = do("GET", "/api/v1/cost/global", created.Secret, "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	w = do("GET", "/api/v1/admin/service-accounts/"+account.ID+"/usage", "bootstrap-key-0123456789abcdef", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total_rejected":1`)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/v1/admin/service-accounts/"+account.ID+"/keys", "bootstrap-key-0123456789abcdef", `{"scopes":["everything"]}`).Code)
+	assert.Equal(t, http.StatusOK, do("DELETE", "/api/v1/admin/service-accounts/"+account.ID+"/keys/"+created.Key.ID, "bootstrap-key-0123456789abcdef", "").Code)
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/v1/admin/service-accounts/"+account.ID, "bootstrap-key-0123456789abcdef", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/v1/admin/service-accounts/"+account.ID, "bootstrap-key-0123456789abcdef", "").Code)
+}
+
+func TestFiscalReportRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/cost/fiscal-report", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetFiscalReportService(service.NewFiscalReportService(mockRepo, costmodel.FiscalCalendar{Pattern: costmodel.Fiscal445}))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/cost/fiscal-report?unit=quarter", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.FiscalReportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "quarter", resp.Unit)
+	assert.Equal(t, 91, resp.Days)
+
+	for _, q := range []string{"unit=month", "date=2026-13-01", "date=2999-01-01"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/cost/fiscal-report?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestCostSeriesRoute(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	srv := NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/cost/series?start_time=2025-03-01T00:00:00Z&end_time=2026-03-01T00:00:00Z", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.CostSeriesResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "monthly", resp.Resolution)
+	assert.True(t, resp.Planned)
+
+	for _, q := range []string{"resolution=weekly", "resolution=hourly&start_time=2025-03-01T00:00:00Z&end_time=2026-03-01T00:00:00Z"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/cost/series?"+q, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestPricingRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/pricing/history", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetPricingService(service.NewPricingService(mockRepo, mockRepo, mockRepo, 0.025, 0.01))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/v1/pricing/history",
+		strings.NewReader(`{"effective_from":"2020-01-01T00:00:00Z","cpu_price_per_core_hour":0.03,"mem_price_per_gb_hour":0.012}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var change dto.PriceChangeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &change))
+	assert.NotNil(t, change.AffectedStart)
+	assert.Nil(t, change.Recalculation)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/v1/pricing/history",
+		strings.NewReader(`{"effective_from":"2020-01-01T00:00:00Z","cpu_price_per_core_hour":-1,"mem_price_per_gb_hour":0.012}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/pricing/history", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var history dto.PriceHistoryResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Len(t, history.Versions, 1)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/pricing/recalculate",
+		strings.NewReader(`{"window_start":"2020-01-01T00:00:00Z","window_end":"2020-01-02T00:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestPricingChangeRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	pricing := service.NewPricingService(mockRepo, mockRepo, mockRepo, 0.025, 0.01)
+	pricing.SetApproval(mockRepo, true, false)
+	srv.SetPricingService(pricing)
+	engine := srv.Engine()
+
+	body := `{"effective_from":"2020-01-01T00:00:00Z","cpu_price_per_core_hour":0.03,"mem_price_per_gb_hour":0.012}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/v1/pricing/history", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code, "direct change with approval required")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/pricing/changes", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var draft dto.PriceChange
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &draft))
+	assert.Equal(t, "draft", draft.Status)
+
+	// 匿名调用方不能审批（否则可以批准自己提交的变更）
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/pricing/changes/"+draft.ID+"/approve", strings.NewReader(""))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "anonymous reviewer")
+
+	enableAdmin(srv)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/pricing/changes/"+draft.ID+"/approve", strings.NewReader(""))
+	req.Header.Set("X-API-Key", testAdminKey)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var approved dto.PriceChangeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &approved))
+	assert.Equal(t, draft.ID, approved.Version.ChangeID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/pricing/changes/"+draft.ID+"/reject", strings.NewReader(`{"comment":"too late"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", testAdminKey)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code, "already approved")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/pricing/changes?status=effective", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list dto.PriceChangeListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Changes, 1)
+
+	req, _ = http.NewRequest("POST", "/api/v1/pricing/changes/"+draft.ID+"/approve", nil)
+	assert.Equal(t, "pricing:approve", requestScope(req))
+	req, _ = http.NewRequest("POST", "/api/v1/pricing/changes", nil)
+	assert.Equal(t, "pricing:write", requestScope(req))
+}
+
+func TestSnapshotIntegrityRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/snapshots/verify", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetSnapshotIntegrityService(service.NewSnapshotIntegrityService(mockRepo, mockRepo))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/snapshots/verify", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var verification dto.SnapshotVerificationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &verification))
+	assert.True(t, verification.Verified)
+	assert.NotEmpty(t, verification.HeadHash)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/snapshots/verify?snapshot_id=missing", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/snapshots/export?start_time=2000-01-01T00:00:00Z", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var export dto.SnapshotExportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+	assert.True(t, export.Verification.Verified)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/snapshots/export?start_time=bad", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStateRoutes(t *testing.T) {
+	mockRepo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(mockRepo))
+	enableAdmin(srv)
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := adminRequest("GET", "/api/v1/admin/state/export", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "not configured")
+
+	srv.SetStateService(service.NewStateService(mockRepo, mockRepo, service.StateConfig{Environment: "staging"}))
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("GET", "/api/v1/admin/state/export", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	bundle := w.Body.String()
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("POST", "/api/v1/admin/state/import?dry_run=true", strings.NewReader(bundle))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.StateImportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.DryRun)
+	assert.Empty(t, resp.Changes, "importing an environment's own bundle changes nothing")
+
+	w = httptest.NewRecorder()
+	req, _ = adminRequest("POST", "/api/v1/admin/state/import", strings.NewReader(`{"version":99}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestErrorMapping(t *testing.T) {
+	failing := postgres.DefaultMockConfig()
+	failing.ErrorRate = 1
+	cfg := &config.Config{Env: config.EnvDevelopment}
+	srv := NewHTTPServer(cfg, service.NewCostService(postgres.NewMockRepository(failing)))
+	srv.SetCalculationRunService(service.NewCalculationRunService(postgres.NewMockRepository(postgres.DefaultMockConfig()), nil))
+	engine := srv.Engine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/cost/global", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "injected repository failures are transient")
+	var body dto.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "UNAVAILABLE", body.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/calculations/runs/missing/retrigger", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "NOT_FOUND", body.Code)
+}
+
+// testAdminKey is the bootstrap key set by enableAdmin; the admin endpoints reject anonymous requests.
+const testAdminKey = "bootstrap-key-0123456789abcdef"
+
+// enableAdmin lets requests authenticated with testAdminKey (see adminRequest) reach the admin endpoints of srv.
+func enableAdmin(srv *HTTPServer) {
+	accounts := service.NewServiceAccountService(postgres.NewMockRepository(postgres.DefaultMockConfig()))
+	accounts.SetBootstrapKey(testAdminKey)
+	srv.SetServiceAccountService(accounts)
+}
+
+// adminRequest is http.NewRequest, authenticated with testAdminKey when url is an admin endpoint.
+func adminRequest(method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err == nil && strings.HasPrefix(url, "/api/v1/admin/") {
+		req.Header.Set("X-API-Key", testAdminKey)
+	}
+	return req, err
+}
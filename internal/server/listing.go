@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// listParamsKey is the gin context key of the listParams parsed by paginate.
+const listParamsKey = "listParams"
+
+// cursorPrefix prefixes the offset encoded in a list cursor.
+const cursorPrefix = "offset:"
+
+// listSpec is the paging policy of a list endpoint.
+type listSpec struct {
+	// DefaultLimit is the page size of requests without limit; 0 returns all results.
+	DefaultLimit int
+	// Sortable endpoints accept sort and order (costmodel sort fields); others ignore them.
+	Sortable bool
+}
+
+// listParams is the normalized list query of a request: the time window, the page and the sort.
+type listParams struct {
+	StartTime, EndTime time.Time
+	Limit, Offset      int
+	Sort               costmodel.SortField
+	Order              costmodel.SortOrder
+}
+
+// PageOptions returns the page and sort of p for the aggregated cost lists.
+func (p listParams) PageOptions() costmodel.PageOptions {
+	return costmodel.PageOptions{Sort: p.Sort, Order: p.Order, Offset: p.Offset, Limit: p.Limit}
+}
+
+// listPage returns the envelope fields of a response returning n items of total.
+func (p listParams) listPage(n, total int) dto.ListPage {
+	page := dto.ListPage{TotalEstimate: total}
+	if next := p.Offset + n; n > 0 && next < total {
+		page.NextCursor = encodeCursor(next)
+	}
+	return page
+}
+
+// paginate parses the list query of every list endpoint the same way — start_time and end_time
+// (RFC3339), limit (spec.DefaultLimit when absent, at most costmodel.MaxPageLimit), offset or the
+// cursor of a previous response, and for sortable lists sort and order — and stores the result for
+// listParamsOf. On invalid input it aborts with 400.
+func paginate(spec listSpec) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, err := parseListParams(c, spec)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, err.Error(), "VALIDATION_FAILED"))
+			return
+		}
+		c.Set(listParamsKey, p)
+		c.Next()
+	}
+}
+
+// listParamsOf returns the list query paginate parsed for c (the zero listParams without paginate).
+func listParamsOf(c *gin.Context) listParams {
+	p, _ := c.Get(listParamsKey)
+	params, _ := p.(listParams)
+	return params
+}
+
+func parseListParams(c *gin.Context, spec listSpec) (listParams, error) {
+	p := listParams{Limit: spec.DefaultLimit}
+	var err error
+	for _, t := range []struct {
+		name string
+		dst  *time.Time
+	}{{"start_time", &p.StartTime}, {"end_time", &p.EndTime}} {
+		if v := c.Query(t.name); v != "" {
+			if *t.dst, err = time.Parse(time.RFC3339, v); err != nil {
+				return p, fmt.Errorf("invalid %s: %v", t.name, err)
+			}
+		}
+	}
+	if !p.StartTime.IsZero() && !p.EndTime.IsZero() && !p.EndTime.After(p.StartTime) {
+		return p, errors.New("end_time must be after start_time")
+	}
+	if v := c.Query("limit"); v != "" {
+		if p.Limit, err = strconv.Atoi(v); err != nil || p.Limit < 0 || p.Limit > costmodel.MaxPageLimit {
+			return p, fmt.Errorf("invalid limit: must be within 0..%d", costmodel.MaxPageLimit)
+		}
+	}
+	offset, cursor := c.Query("offset"), c.Query("cursor")
+	switch {
+	case offset != "" && cursor != "":
+		return p, errors.New("offset and cursor are mutually exclusive")
+	case offset != "":
+		if p.Offset, err = strconv.Atoi(offset); err != nil || p.Offset < 0 {
+			return p, errors.New("invalid offset")
+		}
+	case cursor != "":
+		if p.Offset, err = decodeCursor(cursor); err != nil {
+			return p, err
+		}
+	}
+	if spec.Sortable {
+		p.Sort, p.Order = costmodel.SortField(c.Query("sort")), costmodel.SortOrder(c.Query("order"))
+		if err := p.PageOptions().Validate(); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// encodeCursor returns the opaque cursor of the page starting at offset.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of a cursor made by encodeCursor.
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(b), cursorPrefix) {
+		if offset, err := strconv.Atoi(strings.TrimPrefix(string(b), cursorPrefix)); err == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, errors.New("invalid cursor")
+}
+
+// pageOf returns the items of the page p selects from all items of an in-memory list, and the
+// envelope fields of the response.
+func pageOf[T any](items []T, p listParams) ([]T, dto.ListPage) {
+	total := len(items)
+	start := min(p.Offset, total)
+	end := total
+	if p.Limit > 0 {
+		end = min(start+p.Limit, total)
+	}
+	page := make([]T, 0, end-start)
+	page = append(page, items[start:end]...)
+	return page, p.listPage(len(page), total)
+}
@@ -2,12 +2,17 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"math/rand"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/storage"
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
 )
 
 // RequestID adds a unique request ID to each request.
@@ -23,6 +28,18 @@ func RequestID() gin.HandlerFunc {
 	}
 }
 
+// Locale negotiates the language of the response from the Accept-Language header (fallback when
+// none matches) and stores it in the request context (i18n.FromContext) for localized messages.
+func Locale(fallback i18n.Locale) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"), fallback)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Writer.Header().Set("Content-Language", string(locale))
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
 // Logger logs HTTP requests.
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -71,6 +88,23 @@ func formatLog(timestamp time.Time, status int, latency time.Duration, clientIP,
 	return base + "\n"
 }
 
+// RequestObserver records served requests, e.g. *apimetrics.Recorder.
+type RequestObserver interface {
+	ObserveRequest(method, path string, status int, latency time.Duration)
+}
+
+// RouteMetrics reports each request to observer with its route pattern, so /api/v1/workloads/a and
+// /api/v1/workloads/b count as one route. Requests matching no route are not recorded.
+func RouteMetrics(observer RequestObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		if route := c.FullPath(); route != "" {
+			observer.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+		}
+	}
+}
+
 // Recovery recovers from panics and returns a 500 error.
 func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -79,7 +113,7 @@ func Recovery() gin.HandlerFunc {
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 					"error":   "Internal Server Error",
 					"code":    "INTERNAL_ERROR",
-					"message": "An unexpected error occurred",
+					"message": i18n.T(i18n.FromContext(c.Request.Context()), "error.INTERNAL_ERROR"),
 				})
 			}
 		}()
@@ -104,6 +138,54 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
+// Stale data headers set when a response was served from the degraded-mode cache.
+const (
+	HeaderStale      = "X-Lighthouse-Stale"
+	HeaderDataAsOf   = "X-Lighthouse-Data-As-Of"
+	staleHeaderValue = "true"
+)
+
+// Staleness marks responses built from cached data while the repository is down
+// (storage.DegradedRepository) with X-Lighthouse-Stale and X-Lighthouse-Data-As-Of.
+func Staleness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, st := storage.WithStaleness(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &staleWriter{ResponseWriter: c.Writer, staleness: st}
+		c.Next()
+	}
+}
+
+// staleWriter adds the stale headers right before the response header is written,
+// since handlers only know about staleness after reading from the repository.
+type staleWriter struct {
+	gin.ResponseWriter
+	staleness *storage.Staleness
+}
+
+func (w *staleWriter) setHeaders() {
+	if w.ResponseWriter.Written() || !w.staleness.Stale() {
+		return
+	}
+	w.Header().Set(HeaderStale, staleHeaderValue)
+	w.Header().Set(HeaderDataAsOf, w.staleness.AsOf().UTC().Format(time.RFC3339))
+}
+
+func (w *staleWriter) WriteHeaderNow() {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *staleWriter) Write(data []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *staleWriter) WriteString(s string) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
+
 // RateLimiter limits requests per IP (simplified version).
 func RateLimiter(maxRequests int, window time.Duration) gin.HandlerFunc {
 	// In a real implementation, you would use a token bucket or sliding window
@@ -125,12 +207,80 @@ func Auth() gin.HandlerFunc {
 	}
 }
 
-// RequestTimeout sets a timeout for the request.
+// CodeTimeout is the error code of responses replaced by RequestTimeout.
+const CodeTimeout = "TIMEOUT"
+
+// timeoutBody is the body of a 504 replacing a response, with the message in the request's locale.
+func timeoutBody(ctx context.Context) []byte {
+	body, _ := json.Marshal(map[string]string{
+		"error":   "request deadline exceeded",
+		"code":    CodeTimeout,
+		"message": i18n.T(i18n.FromContext(ctx), "error."+CodeTimeout),
+	})
+	return body
+}
+
+// RequestTimeout gives each request a context deadline of timeout, which services and data clients
+// observe through c.Request.Context(). A 5xx written after the deadline passed is replaced with
+// 504 Gateway Timeout, as is a handler returning without a response. timeout <= 0 sets no deadline.
 func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Gin already supports timeout via context, but we can add custom handling
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+
 		c.Next()
+
+		if !c.Writer.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.Abort()
+			c.Data(http.StatusGatewayTimeout, "application/json; charset=utf-8", timeoutBody(ctx))
+		}
+	}
+}
+
+// timeoutWriter turns the error a handler writes for an expired deadline (usually a 500 carrying
+// context.DeadlineExceeded) into a 504, dropping the handler's body.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code >= http.StatusInternalServerError && !w.Written() && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+		code = http.StatusGatewayTimeout
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.timedOut {
+		return w.writeTimeout(len(data))
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.timedOut {
+		return w.writeTimeout(len(s))
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) writeTimeout(n int) (int, error) {
+	if !w.Written() {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if _, err := w.ResponseWriter.Write(timeoutBody(w.ctx)); err != nil {
+			return 0, err
+		}
 	}
+	return n, nil
 }
 
 // RandomDelay adds random delay for testing (optional).
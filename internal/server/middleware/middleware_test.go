@@ -1,11 +1,18 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/storage"
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+	"github.com/myxxhui/lighthouse-src/internal/server/apimetrics"
 )
 
 func TestRequestID(t *testing.T) {
@@ -41,3 +48,119 @@ func TestRecovery(t *testing.T) {
 		t.Errorf("expected 500 after panic, got %d", rec.Code)
 	}
 }
+
+func TestStaleness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := storage.NewDegradedRepository(postgres.NewMockRepository(postgres.DefaultMockConfig()), 0)
+	r := gin.New()
+	r.Use(Staleness())
+	r.GET("/", func(c *gin.Context) {
+		costs, err := repo.AggregateDailyNamespaceCosts(c.Request.Context(), time.Now().AddDate(0, 0, -7), time.Now())
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, costs)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Header().Get(HeaderStale) != "" {
+		t.Error("fresh response must not be marked stale")
+	}
+
+	failing := postgres.DefaultMockConfig()
+	failing.ErrorRate = 1
+	repo.Repository = postgres.NewMockRepository(failing)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(HeaderStale) != "true" || rec.Header().Get(HeaderDataAsOf) == "" {
+		t.Errorf("degraded response: code=%d headers=%v", rec.Code, rec.Header())
+	}
+}
+
+func TestLocale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Locale(i18n.English), Recovery())
+	r.GET("/boom", func(c *gin.Context) { panic("boom") })
+
+	for header, want := range map[string]i18n.Locale{"": i18n.English, "zh-CN,zh;q=0.9,en;q=0.8": i18n.Chinese, "fr, en;q=0.5": i18n.English} {
+		req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+		req.Header.Set("Accept-Language", header)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Content-Language"); got != string(want) {
+			t.Errorf("%q: Content-Language = %q, want %q", header, got, want)
+		}
+		var body map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["message"] != i18n.T(want, "error.INTERNAL_ERROR") || body["code"] != "INTERNAL_ERROR" {
+			t.Errorf("%q: body = %s", header, rec.Body)
+		}
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	slow := postgres.DefaultMockConfig()
+	slow.LatencyMs = 1000
+	repo := postgres.NewMockRepository(slow)
+	r := gin.New()
+	r.Use(RequestTimeout(20 * time.Millisecond))
+	r.GET("/slow", func(c *gin.Context) {
+		if _, err := repo.AggregateDailyNamespaceCosts(c.Request.Context(), time.Now().AddDate(0, 0, -7), time.Now()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/silent", func(c *gin.Context) { <-c.Request.Context().Done() })
+	r.GET("/fast", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	for path, want := range map[string]int{"/slow": http.StatusGatewayTimeout, "/silent": http.StatusGatewayTimeout, "/fast": http.StatusOK} {
+		start := time.Now()
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: code = %d, want %d", path, rec.Code, want)
+		}
+		if want == http.StatusGatewayTimeout && rec.Body.String() != string(timeoutBody(context.Background())) {
+			t.Errorf("%s: body = %s", path, rec.Body)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("%s took %v; the mock latency should stop at the deadline", path, elapsed)
+		}
+	}
+
+	r = gin.New()
+	r.Use(RequestTimeout(0))
+	r.GET("/", func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); ok {
+			t.Error("timeout 0 must not set a deadline")
+		}
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestRouteMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := apimetrics.NewRecorder()
+	r := gin.New()
+	r.Use(RouteMetrics(rec), Recovery())
+	r.GET("/workloads/:id", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/boom", func(c *gin.Context) { panic("boom") })
+	for _, path := range []string{"/workloads/a", "/workloads/b", "/boom", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	snapshot := rec.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("routes = %v, want the two registered routes", apimetrics.SortedRoutes(snapshot))
+	}
+	if c := snapshot[apimetrics.Route{Method: "GET", Path: "/workloads/:id"}]; c.Requests != 2 || c.Errors != 0 {
+		t.Errorf("/workloads/:id = %+v, want 2 requests without errors", c)
+	}
+	if c := snapshot[apimetrics.Route{Method: "GET", Path: "/boom"}]; c.Requests != 1 || c.Errors != 1 {
+		t.Errorf("/boom = %+v, want the recovered panic counted as an error", c)
+	}
+}
@@ -0,0 +1,262 @@
+// Package service admission_service.go: 准入阶段成本估算 webhook。按 Deployment / StatefulSet 的副本数与容器 requests
+// 估算月度成本并写入注解（lighthouse.io/estimated-monthly-cost），可在 namespace 或团队月度预算将被超出时告警或拒绝。
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Budget actions of the admission webhook.
+const (
+	AdmissionBudgetNone   = "none"
+	AdmissionBudgetWarn   = "warn"
+	AdmissionBudgetReject = "reject"
+)
+
+// admissionRunRateWindow is the window of the current monthly run rate a new estimate is added to.
+const admissionRunRateWindow = 30 * 24 * time.Hour
+
+// ErrInvalidAdmissionReview is returned for a review without request.
+var ErrInvalidAdmissionReview = dataerr.Validation("invalid admission review")
+
+// admissionWorkload is the subset of a Deployment / StatefulSet the estimate reads.
+type admissionWorkload struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int32 `json:"replicas"`
+		Template struct {
+			Spec struct {
+				Containers     []admissionContainer `json:"containers"`
+				InitContainers []admissionContainer `json:"initContainers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+type admissionContainer struct {
+	Resources struct {
+		Requests map[string]string `json:"requests"`
+	} `json:"resources"`
+}
+
+// AdmissionService answers admission reviews of Deployments and StatefulSets with their estimated
+// monthly cost: replicas × (CPU cores × CPU price + memory GiB × memory price) × costmodel.HoursPerMonth,
+// using the pod's effective requests (sum of containers, at least the largest init container).
+type AdmissionService struct {
+	repo             postgres.Repository
+	cpuPrice         float64
+	memPrice         float64
+	teams            map[string]TeamBudget
+	namespaceBudgets map[string]float64
+	action           string
+	now              func() time.Time
+}
+
+// NewAdmissionService creates an AdmissionService with the unit prices per core-hour and GiB-hour.
+func NewAdmissionService(repo postgres.Repository, cpuPricePerCoreHour, memPricePerGBHour float64) *AdmissionService {
+	return &AdmissionService{repo: repo, cpuPrice: cpuPricePerCoreHour, memPrice: memPricePerGBHour, action: AdmissionBudgetWarn, now: time.Now}
+}
+
+// SetBudgets sets the monthly budgets checked on admission: per team (its namespaces together)
+// and per namespace.
+func (s *AdmissionService) SetBudgets(teams map[string]TeamBudget, namespaces map[string]float64) {
+	s.teams = teams
+	s.namespaceBudgets = namespaces
+}
+
+// SetBudgetAction sets what happens when an admission would exceed a budget: none, warn (default;
+// the object is admitted with a warning) or reject.
+func (s *AdmissionService) SetBudgetAction(action string) error {
+	switch action {
+	case AdmissionBudgetNone, AdmissionBudgetWarn, AdmissionBudgetReject:
+		s.action = action
+		return nil
+	case "":
+		s.action = AdmissionBudgetWarn
+		return nil
+	}
+	return fmt.Errorf("invalid admission budget action %q (want none, warn or reject)", action)
+}
+
+// Review estimates the monthly cost of the admitted workload. With mutate set the response patches
+// the estimate onto the object (mutating webhooks); validating webhooks must not return patches.
+// Objects other than Deployments and StatefulSets, and deletions, are admitted unchanged. Failing
+// to read the budgets admits the object with a warning rather than blocking deploys.
+func (s *AdmissionService) Review(ctx context.Context, review dto.AdmissionReview, mutate bool) (*dto.AdmissionReview, error) {
+	req := review.Request
+	if req == nil {
+		return nil, fmt.Errorf("%w: request is missing", ErrInvalidAdmissionReview)
+	}
+	resp := &dto.AdmissionResponse{UID: req.UID, Allowed: true}
+	out := &dto.AdmissionReview{APIVersion: review.APIVersion, Kind: review.Kind, Response: resp}
+	if out.APIVersion == "" {
+		out.APIVersion, out.Kind = "admission.k8s.io/v1", "AdmissionReview"
+	}
+	if (req.Kind.Kind != "Deployment" && req.Kind.Kind != "StatefulSet") || (req.Operation != "CREATE" && req.Operation != "UPDATE") {
+		return out, nil
+	}
+
+	var obj admissionWorkload
+	if err := json.Unmarshal(req.Object, &obj); err != nil {
+		return nil, fmt.Errorf("%w: object: %v", ErrInvalidAdmissionReview, err)
+	}
+	estimate, warnings := s.estimate(obj)
+	resp.Warnings = append(resp.Warnings, warnings...)
+	resp.Warnings = append(resp.Warnings, fmt.Sprintf("lighthouse: estimated monthly cost of %s %s/%s is %.2f", req.Kind.Kind, req.Namespace, req.Name, estimate))
+
+	// UPDATE 只按增量检查预算，旧规格的成本已计入运行速率
+	delta := estimate
+	if req.Operation == "UPDATE" && len(req.OldObject) > 0 {
+		var old admissionWorkload
+		if err := json.Unmarshal(req.OldObject, &old); err == nil {
+			previous, _ := s.estimate(old)
+			delta -= previous
+		}
+	}
+	if s.action != AdmissionBudgetNone && delta > 0 {
+		exceeded, err := s.exceededBudgets(ctx, req.Namespace, delta)
+		switch {
+		case err != nil:
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("lighthouse: budget check skipped: %v", err))
+		case len(exceeded) > 0 && s.action == AdmissionBudgetReject:
+			resp.Allowed = false
+			resp.Result = &dto.AdmissionStatus{Code: 403, Message: "lighthouse: " + strings.Join(exceeded, "; ")}
+			return out, nil
+		default:
+			for _, msg := range exceeded {
+				resp.Warnings = append(resp.Warnings, "lighthouse: "+msg)
+			}
+		}
+	}
+
+	if mutate {
+		patch, err := estimatePatch(obj.Metadata.Annotations, estimate)
+		if err != nil {
+			return nil, err
+		}
+		patchType := "JSONPatch"
+		resp.Patch, resp.PatchType = patch, &patchType
+	}
+	return out, nil
+}
+
+// estimate returns the monthly cost of obj and warnings about requests that could not be read.
+func (s *AdmissionService) estimate(obj admissionWorkload) (float64, []string) {
+	var warnings []string
+	requests := func(c admissionContainer) (cpu, mem float64) {
+		if q, ok := c.Resources.Requests["cpu"]; ok {
+			v, err := k8s.ParseCPUCores(q)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("lighthouse: cpu request %q ignored: %v", q, err))
+			}
+			cpu = v
+		}
+		if q, ok := c.Resources.Requests["memory"]; ok {
+			v, err := k8s.ParseMemoryGiB(q)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("lighthouse: memory request %q ignored: %v", q, err))
+			}
+			mem = v
+		}
+		return cpu, mem
+	}
+	var cpu, mem float64
+	for _, c := range obj.Spec.Template.Spec.Containers {
+		c, m := requests(c)
+		cpu += c
+		mem += m
+	}
+	for _, c := range obj.Spec.Template.Spec.InitContainers {
+		c, m := requests(c)
+		if c > cpu {
+			cpu = c
+		}
+		if m > mem {
+			mem = m
+		}
+	}
+	replicas := 1.0
+	if obj.Spec.Replicas != nil {
+		replicas = float64(*obj.Spec.Replicas)
+	}
+	return roundAlertValue(replicas * (cpu*s.cpuPrice + mem*s.memPrice) * costmodel.HoursPerMonth), warnings
+}
+
+// exceededBudgets returns a message per budget of namespace (its own and its teams') that the
+// current monthly run rate plus delta would exceed.
+func (s *AdmissionService) exceededBudgets(ctx context.Context, namespace string, delta float64) ([]string, error) {
+	type budget struct {
+		label      string
+		namespaces []string
+		limit      float64
+	}
+	var budgets []budget
+	if limit := s.namespaceBudgets[namespace]; limit > 0 {
+		budgets = append(budgets, budget{"namespace " + namespace, []string{namespace}, limit})
+	}
+	teams := make([]string, 0, len(s.teams))
+	for name := range s.teams {
+		teams = append(teams, name)
+	}
+	sort.Strings(teams)
+	for _, name := range teams {
+		t := s.teams[name]
+		if t.MonthlyBudget <= 0 {
+			continue
+		}
+		for _, ns := range t.Namespaces {
+			if ns == namespace {
+				budgets = append(budgets, budget{"team " + name, t.Namespaces, t.MonthlyBudget})
+				break
+			}
+		}
+	}
+
+	end := s.now().UTC()
+	start := end.Add(-admissionRunRateWindow)
+	var exceeded []string
+	for _, b := range budgets {
+		spent := 0.0
+		for _, ns := range b.namespaces {
+			costs, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{Namespace: ns, StartDate: start, EndDate: end})
+			if err != nil {
+				return nil, fmt.Errorf("list daily costs of %s: %w", ns, err)
+			}
+			for _, c := range costs {
+				spent += c.BillableCost
+			}
+		}
+		if projected := spent + delta; projected > b.limit {
+			exceeded = append(exceeded, fmt.Sprintf("%s would reach %.2f of its monthly budget %.2f (run rate %.2f + %.2f)", b.label, roundAlertValue(projected), b.limit, roundAlertValue(spent), delta))
+		}
+	}
+	return exceeded, nil
+}
+
+// estimatePatch returns the JSON patch setting the estimate annotation.
+func estimatePatch(annotations map[string]string, estimate float64) ([]byte, error) {
+	value := strconv.FormatFloat(estimate, 'f', 2, 64)
+	var ops []map[string]interface{}
+	if annotations == nil {
+		ops = append(ops, map[string]interface{}{"op": "add", "path": "/metadata/annotations", "value": map[string]string{k8s.AnnotationEstimatedMonthlyCost: value}})
+	} else {
+		// JSON pointer escaping: "~" -> "~0", "/" -> "~1"
+		key := strings.NewReplacer("~", "~0", "/", "~1").Replace(k8s.AnnotationEstimatedMonthlyCost)
+		ops = append(ops, map[string]interface{}{"op": "add", "path": "/metadata/annotations/" + key, "value": value})
+	}
+	return json.Marshal(ops)
+}
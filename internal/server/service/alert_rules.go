@@ -0,0 +1,703 @@
+// Package service alert_rules.go: 统一告警规则引擎——用户以条件 DSL（notifier.ParseCondition）组合成本、浪费、
+// 效率、僵尸、预算与 SLO 指标上的告警，按规则的求值周期与时间窗口求值，状态转为 firing 时按告警类型与渠道路由通知。
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/eventbus"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// States of an alert rule after an evaluation.
+const (
+	AlertStateOK     = "ok"
+	AlertStateFiring = "firing"
+	AlertStateError  = "error"
+)
+
+const (
+	defaultAlertWindow    = 24 * time.Hour
+	defaultAlertInterval  = time.Hour
+	minAlertInterval      = time.Minute
+	maxAlertWindow        = 90 * 24 * time.Hour
+	defaultAlertRuleTick  = time.Minute
+	alertRuleDedupKeyBase = "lighthouse/alert-rule/"
+)
+
+// ErrInvalidAlertRule is returned for an invalid alert rule request.
+var ErrInvalidAlertRule = dataerr.Validation("invalid alert rule")
+
+// AlertRuleStore persists alert rules. *postgres.MockRepository satisfies this interface.
+type AlertRuleStore interface {
+	SaveAlertRule(ctx context.Context, rule postgres.AlertRule) (postgres.AlertRule, error)
+	GetAlertRule(ctx context.Context, id string) (*postgres.AlertRule, error)
+	ListAlertRules(ctx context.Context) ([]postgres.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, id string) error
+}
+
+// EventPublisher publishes domain events. *eventbus.Bus satisfies this interface.
+type EventPublisher interface {
+	Publish(payloads ...eventbus.Payload) error
+}
+
+// TeamBudget is the monthly budget of a team's namespaces.
+type TeamBudget struct {
+	Namespaces    []string
+	MonthlyBudget float64
+}
+
+// alertMetrics are the metrics of the condition DSL. Hourly metrics cover the rule window.
+var alertMetrics = []dto.AlertMetric{
+	{Name: "cost", Description: "billable cost over the window", Labels: workloadLabels},
+	{Name: "waste", Description: "waste cost over the window", Labels: workloadLabels},
+	{Name: "waste_ratio", Description: "waste in % of the billable cost over the window", Labels: workloadLabels},
+	{Name: "cost_change", Description: "billable cost change in % from the preceding window of the same length", Labels: workloadLabels},
+	{Name: "cpu_utilization", Description: "CPU usage (P95) in % of requests over the window", Labels: workloadLabels},
+	{Name: "mem_utilization", Description: "memory usage (P95) in % of requests over the window", Labels: workloadLabels},
+	{Name: "zombie_count", Description: "workloads graded zombie over the window", Labels: workloadLabels},
+	{Name: "budget_used", Description: "month-to-date billable cost in % of the monthly budget (highest team without a team label)", Labels: []string{"team"}},
+	{Name: "slo_error_budget_remaining", Description: "remaining error budget in % (lowest matching service)", Labels: sloLabels},
+	{Name: "slo_availability", Description: "availability in % (lowest matching service)", Labels: sloLabels},
+}
+
+var (
+	workloadLabels = []string{"namespace", "workload", "node_pool"}
+	sloLabels      = []string{"namespace", "service"}
+	alertChannels  = []string{"slack", "dingtalk", "wecom", "pagerduty", "opsgenie"}
+	alertTypes     = []notifier.AlertType{notifier.AlertTypeRule, notifier.AlertTypeBudgetBreach, notifier.AlertTypeSLOViolation, notifier.AlertTypeAnomaly}
+)
+
+// AlertRuleService manages alert rules and evaluates them.
+type AlertRuleService struct {
+	store    AlertRuleStore
+	repo     postgres.Repository
+	slo      SLOStatusProvider
+	budgets  map[string]TeamBudget
+	calendar costmodel.AccountingCalendar
+	notifier SnapshotAlertNotifier
+	events   EventPublisher
+	currency string
+	exceeded map[string]string // team -> budget month already published as exceeded
+	mu       sync.Mutex        // serializes evaluations so a rule is not evaluated twice at once
+	now      func() time.Time
+}
+
+// NewAlertRuleService creates an AlertRuleService over the cost data of repo.
+func NewAlertRuleService(store AlertRuleStore, repo postgres.Repository) *AlertRuleService {
+	return &AlertRuleService{store: store, repo: repo, now: time.Now}
+}
+
+// SetSLOStatus enables the slo_* metrics.
+func (s *AlertRuleService) SetSLOStatus(slo SLOStatusProvider) {
+	s.slo = slo
+}
+
+// SetTeamBudgets enables the budget_used metric for the teams with a monthly budget.
+func (s *AlertRuleService) SetTeamBudgets(budgets map[string]TeamBudget) {
+	s.budgets = budgets
+}
+
+// SetCalendar sets the accounting time zone of the budget month (default UTC).
+func (s *AlertRuleService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+// SetNotifier sends the alerts of firing rules; without it rules are only evaluated.
+func (s *AlertRuleService) SetNotifier(n SnapshotAlertNotifier) {
+	s.notifier = n
+}
+
+// SetPublisher publishes an eventbus.BudgetExceeded, in currency, when a team's month-to-date cost
+// reaches its monthly budget (see CheckBudgets).
+func (s *AlertRuleService) SetPublisher(events EventPublisher, currency string) {
+	s.events, s.currency = events, currency
+}
+
+// Create validates and stores a new alert rule.
+func (s *AlertRuleService) Create(ctx context.Context, req dto.AlertRuleRequest) (*dto.AlertRule, error) {
+	rule, err := s.ruleOf(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUniqueName(ctx, rule.Name, ""); err != nil {
+		return nil, err
+	}
+	rule.CreatedBy = req.CreatedBy
+	rule.CreatedAt = s.now().UTC()
+	rule.UpdatedAt = rule.CreatedAt
+	saved, err := s.store.SaveAlertRule(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("save alert rule: %w", err)
+	}
+	return toDTOAlertRule(saved), nil
+}
+
+// List returns all alert rules sorted by name and the metrics conditions may use.
+func (s *AlertRuleService) List(ctx context.Context) (*dto.AlertRuleListResponse, error) {
+	rules, err := s.store.ListAlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list alert rules: %w", err)
+	}
+	resp := &dto.AlertRuleListResponse{Rules: make([]dto.AlertRule, 0, len(rules)), Total: len(rules), Metrics: alertMetrics}
+	for _, r := range rules {
+		resp.Rules = append(resp.Rules, *toDTOAlertRule(r))
+	}
+	return resp, nil
+}
+
+// Get returns an alert rule.
+func (s *AlertRuleService) Get(ctx context.Context, id string) (*dto.AlertRule, error) {
+	rule, err := s.store.GetAlertRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toDTOAlertRule(*rule), nil
+}
+
+// Update replaces the definition of an alert rule. A changed condition or window resets its state,
+// so a rule that keeps firing under the new definition alerts again.
+func (s *AlertRuleService) Update(ctx context.Context, id string, req dto.AlertRuleRequest) (*dto.AlertRule, error) {
+	existing, err := s.store.GetAlertRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := s.ruleOf(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUniqueName(ctx, rule.Name, id); err != nil {
+		return nil, err
+	}
+	rule.ID, rule.CreatedBy, rule.CreatedAt = existing.ID, existing.CreatedBy, existing.CreatedAt
+	rule.UpdatedAt = s.now().UTC()
+	if rule.Condition == existing.Condition && rule.Window == existing.Window {
+		rule.LastEvaluatedAt, rule.LastState, rule.LastError, rule.FiringSince = existing.LastEvaluatedAt, existing.LastState, existing.LastError, existing.FiringSince
+	}
+	saved, err := s.store.SaveAlertRule(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("save alert rule: %w", err)
+	}
+	return toDTOAlertRule(saved), nil
+}
+
+// Delete deletes an alert rule.
+func (s *AlertRuleService) Delete(ctx context.Context, id string) error {
+	return s.store.DeleteAlertRule(ctx, id)
+}
+
+// Evaluate evaluates an alert rule now, whether enabled and due or not, and records the result.
+func (s *AlertRuleService) Evaluate(ctx context.Context, id string) (*dto.AlertRuleEvaluation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule, err := s.store.GetAlertRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, *rule)
+}
+
+// EvaluateDue evaluates the enabled rules whose interval has elapsed since their last evaluation and
+// returns how many were evaluated. A rule failing to evaluate is recorded in its state, not returned.
+func (s *AlertRuleService) EvaluateDue(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules, err := s.store.ListAlertRules(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list alert rules: %w", err)
+	}
+	now := s.now()
+	n := 0
+	for _, r := range rules {
+		if !r.Enabled || (r.LastEvaluatedAt != nil && now.Sub(*r.LastEvaluatedAt) < r.Interval) {
+			continue
+		}
+		if _, err := s.evaluate(ctx, r); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Run evaluates due rules every tick (default one minute) until ctx is cancelled.
+func (s *AlertRuleService) Run(ctx context.Context, tick time.Duration, onError func(error)) {
+	if tick <= 0 {
+		tick = defaultAlertRuleTick
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		if _, err := s.EvaluateDue(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// evaluate evaluates rule over the window ending now, notifies when it starts firing and saves its
+// state. Only a failure to save the state is returned; a metric error puts the rule in the error
+// state (keeping FiringSince, so a firing rule does not alert again once its metrics are back).
+func (s *AlertRuleService) evaluate(ctx context.Context, rule postgres.AlertRule) (*dto.AlertRuleEvaluation, error) {
+	now := s.now().UTC()
+	out := &dto.AlertRuleEvaluation{RuleID: rule.ID, EvaluatedAt: now, WindowStart: now.Add(-rule.Window), Clauses: []dto.AlertClauseResult{}}
+	firing, results, err := s.check(ctx, rule, now)
+	rule.LastEvaluatedAt = &now
+	rule.LastError = ""
+	switch {
+	case err != nil:
+		rule.LastState, rule.LastError = AlertStateError, err.Error()
+	case firing:
+		if rule.FiringSince == nil {
+			rule.FiringSince = &now
+			if s.notifier != nil {
+				out.Notified = true
+				switch nerr := s.notifier.Notify(ctx, alertOf(rule, results, now)); {
+				case errors.Is(nerr, notifier.ErrSilenced):
+					out.Notified, out.Silenced = false, true
+				case nerr != nil:
+					out.NotifyError = nerr.Error()
+					rule.LastError = "notify: " + nerr.Error()
+				}
+			}
+		}
+		rule.LastState = AlertStateFiring
+	default:
+		rule.LastState, rule.FiringSince = AlertStateOK, nil
+	}
+	for _, r := range results {
+		out.Clauses = append(out.Clauses, dto.AlertClauseResult{Clause: r.Comparison.String(), Value: r.Value, HasData: r.HasData, Matched: r.Matched})
+	}
+	out.Firing, out.State = firing, rule.LastState
+	if err != nil {
+		out.Error = err.Error()
+	}
+	if _, err := s.store.SaveAlertRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("save alert rule state: %w", err)
+	}
+	return out, nil
+}
+
+func (s *AlertRuleService) check(ctx context.Context, rule postgres.AlertRule, now time.Time) (bool, []notifier.ComparisonResult, error) {
+	cond, err := notifier.ParseCondition(rule.Condition)
+	if err != nil {
+		return false, nil, err
+	}
+	type value struct {
+		v  float64
+		ok bool
+	}
+	cache := make(map[string]value) // 同一指标与标签在多个子句中只查询一次
+	resolve := func(metric string, labels map[string]string) (float64, bool, error) {
+		key := notifier.Comparison{Metric: metric, Labels: labels}.String()
+		if c, ok := cache[key]; ok {
+			return c.v, c.ok, nil
+		}
+		v, ok, err := s.metric(ctx, metric, labels, now.Add(-rule.Window), now)
+		if err != nil {
+			return 0, false, err
+		}
+		cache[key] = value{v, ok}
+		return v, ok, nil
+	}
+	return cond.Evaluate(resolve)
+}
+
+// metric returns the value of a metric over start..end; ok is false without data.
+func (s *AlertRuleService) metric(ctx context.Context, metric string, labels map[string]string, start, end time.Time) (float64, bool, error) {
+	switch metric {
+	case "budget_used":
+		return s.budgetUsed(ctx, labels["team"], end)
+	case "slo_error_budget_remaining", "slo_availability":
+		return s.sloMetric(ctx, metric, labels)
+	case "cost_change":
+		cur, err := s.hourlyStats(ctx, labels, start, end)
+		if err != nil {
+			return 0, false, err
+		}
+		prev, err := s.hourlyStats(ctx, labels, start.Add(-end.Sub(start)), start)
+		if err != nil {
+			return 0, false, err
+		}
+		before, after := sumStats(prev, func(st postgres.HourlyWorkloadStat) float64 { return st.TotalBillableCost }), sumStats(cur, func(st postgres.HourlyWorkloadStat) float64 { return st.TotalBillableCost })
+		if before <= 0 {
+			return 0, false, nil
+		}
+		return roundAlertValue((after - before) / before * 100), true, nil
+	}
+	stats, err := s.hourlyStats(ctx, labels, start, end)
+	if err != nil || len(stats) == 0 {
+		return 0, false, err
+	}
+	ratio := func(num, den func(postgres.HourlyWorkloadStat) float64) (float64, bool, error) {
+		d := sumStats(stats, den)
+		if d <= 0 {
+			return 0, false, nil
+		}
+		return roundAlertValue(sumStats(stats, num) / d * 100), true, nil
+	}
+	switch metric {
+	case "cost":
+		return roundAlertValue(sumStats(stats, func(st postgres.HourlyWorkloadStat) float64 { return st.TotalBillableCost })), true, nil
+	case "waste":
+		return roundAlertValue(sumStats(stats, func(st postgres.HourlyWorkloadStat) float64 { return st.TotalWasteCost })), true, nil
+	case "waste_ratio":
+		return ratio(func(st postgres.HourlyWorkloadStat) float64 { return st.TotalWasteCost }, func(st postgres.HourlyWorkloadStat) float64 { return st.TotalBillableCost })
+	case "cpu_utilization":
+		return ratio(func(st postgres.HourlyWorkloadStat) float64 { return st.CPUUsageP95 }, func(st postgres.HourlyWorkloadStat) float64 { return st.CPURequest })
+	case "mem_utilization":
+		return ratio(func(st postgres.HourlyWorkloadStat) float64 { return float64(st.MemUsageP95) }, func(st postgres.HourlyWorkloadStat) float64 { return float64(st.MemRequest) })
+	case "zombie_count":
+		return float64(zombieCount(stats)), true, nil
+	}
+	return 0, false, fmt.Errorf("unknown metric %s", metric)
+}
+
+func (s *AlertRuleService) hourlyStats(ctx context.Context, labels map[string]string, start, end time.Time) ([]postgres.HourlyWorkloadStat, error) {
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{
+		Namespace: labels["namespace"], WorkloadName: labels["workload"], NodePool: labels["node_pool"], StartTime: start, EndTime: end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list hourly workload stats: %w", err)
+	}
+	return stats, nil
+}
+
+// CheckBudgets publishes an eventbus.BudgetExceeded for every team whose month-to-date cost has
+// reached its monthly budget, once per team and budget month (again after a restart; consumers
+// dedupe as for any event), and returns how many were published. Without a publisher it does nothing.
+func (s *AlertRuleService) CheckBudgets(ctx context.Context) (int, error) {
+	if s.events == nil {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	today := s.calendar.Date(s.now())
+	period := today.Format("2006-01")
+	names := make([]string, 0, len(s.budgets))
+	for name := range s.budgets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	n := 0
+	for _, name := range names {
+		b := s.budgets[name]
+		if b.MonthlyBudget <= 0 || s.exceeded[name] == period {
+			continue
+		}
+		spent, err := s.teamSpent(ctx, b, today)
+		if err != nil {
+			return n, err
+		}
+		if spent < b.MonthlyBudget {
+			continue
+		}
+		event := eventbus.BudgetExceeded{Scope: name, Period: period, BudgetAmount: b.MonthlyBudget, ActualAmount: roundAlertValue(spent), Currency: s.currency}
+		if err := s.events.Publish(event); err != nil {
+			return n, fmt.Errorf("publish budget exceeded for %s: %w", name, err)
+		}
+		if s.exceeded == nil {
+			s.exceeded = make(map[string]string)
+		}
+		s.exceeded[name] = period
+		n++
+	}
+	return n, nil
+}
+
+// teamSpent returns the billable cost of a team's namespaces from the start of today's month.
+func (s *AlertRuleService) teamSpent(ctx context.Context, b TeamBudget, today time.Time) (float64, error) {
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	spent := 0.0
+	for _, ns := range b.Namespaces {
+		costs, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{Namespace: ns, StartDate: monthStart, EndDate: today})
+		if err != nil {
+			return 0, fmt.Errorf("list daily costs of %s: %w", ns, err)
+		}
+		for _, c := range costs {
+			spent += c.BillableCost
+		}
+	}
+	return spent, nil
+}
+
+// budgetUsed returns the month-to-date billable cost of a team in % of its monthly budget, or the
+// highest usage of the teams with a budget when team is empty.
+func (s *AlertRuleService) budgetUsed(ctx context.Context, team string, now time.Time) (float64, bool, error) {
+	today := s.calendar.Date(now)
+	best, found := 0.0, false
+	for name, b := range s.budgets {
+		if (team != "" && name != team) || b.MonthlyBudget <= 0 {
+			continue
+		}
+		spent, err := s.teamSpent(ctx, b, today)
+		if err != nil {
+			return 0, false, err
+		}
+		if used := spent / b.MonthlyBudget * 100; !found || used > best {
+			best, found = used, true
+		}
+	}
+	return roundAlertValue(best), found, nil
+}
+
+// sloMetric returns the lowest value of an SLO metric of the matching services.
+func (s *AlertRuleService) sloMetric(ctx context.Context, metric string, labels map[string]string) (float64, bool, error) {
+	if s.slo == nil {
+		return 0, false, nil
+	}
+	services, err := s.slo.ListSLOStatus(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("list SLO status: %w", err)
+	}
+	lowest, found := 0.0, false
+	for _, svc := range services {
+		if (labels["namespace"] != "" && svc.Namespace != labels["namespace"]) || (labels["service"] != "" && svc.Service != labels["service"]) {
+			continue
+		}
+		v := svc.Availability
+		if metric == "slo_error_budget_remaining" {
+			v = svc.ErrorBudgetRemaining
+		}
+		if !found || v < lowest {
+			lowest, found = v, true
+		}
+	}
+	return lowest, found, nil
+}
+
+// ruleOf validates req and returns the rule it defines (without identity and state).
+func (s *AlertRuleService) ruleOf(req dto.AlertRuleRequest) (postgres.AlertRule, error) {
+	rule := postgres.AlertRule{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Window:      defaultAlertWindow,
+		Interval:    defaultAlertInterval,
+		Severity:    req.Severity,
+		AlertType:   req.AlertType,
+		Channels:    req.Channels,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+	if rule.Name == "" {
+		return rule, fmt.Errorf("%w: name is required", ErrInvalidAlertRule)
+	}
+	cond, err := notifier.ParseCondition(req.Condition)
+	if err != nil {
+		return rule, fmt.Errorf("%w: %v", ErrInvalidAlertRule, err)
+	}
+	for _, cmp := range cond.Comparisons() {
+		if err := checkAlertMetric(cmp); err != nil {
+			return rule, err
+		}
+	}
+	rule.Condition = cond.String()
+	if req.Window != "" {
+		if rule.Window, err = time.ParseDuration(req.Window); err != nil || rule.Window < time.Hour || rule.Window > maxAlertWindow {
+			return rule, fmt.Errorf("%w: window must be a duration between 1h and %s", ErrInvalidAlertRule, maxAlertWindow)
+		}
+	}
+	if req.Interval != "" {
+		if rule.Interval, err = time.ParseDuration(req.Interval); err != nil || rule.Interval < minAlertInterval {
+			return rule, fmt.Errorf("%w: interval must be a duration of at least %s", ErrInvalidAlertRule, minAlertInterval)
+		}
+	}
+	switch notifier.Severity(rule.Severity) {
+	case "":
+		rule.Severity = string(notifier.SeverityWarning)
+	case notifier.SeverityInfo, notifier.SeverityWarning, notifier.SeverityCritical:
+	default:
+		return rule, fmt.Errorf("%w: severity must be info, warning or critical", ErrInvalidAlertRule)
+	}
+	if rule.AlertType == "" {
+		rule.AlertType = string(notifier.AlertTypeRule)
+	}
+	if !containsAlertType(notifier.AlertType(rule.AlertType)) {
+		return rule, fmt.Errorf("%w: alert_type must be one of %v", ErrInvalidAlertRule, alertTypes)
+	}
+	for _, ch := range rule.Channels {
+		if !containsString(alertChannels, ch) {
+			return rule, fmt.Errorf("%w: unknown channel %q (want one of %s)", ErrInvalidAlertRule, ch, strings.Join(alertChannels, ", "))
+		}
+	}
+	return rule, nil
+}
+
+func (s *AlertRuleService) checkUniqueName(ctx context.Context, name, id string) error {
+	rules, err := s.store.ListAlertRules(ctx)
+	if err != nil {
+		return fmt.Errorf("list alert rules: %w", err)
+	}
+	for _, r := range rules {
+		if r.Name == name && r.ID != id {
+			return dataerr.Conflict("alert rule %q already exists", name)
+		}
+	}
+	return nil
+}
+
+func checkAlertMetric(cmp notifier.Comparison) error {
+	for _, m := range alertMetrics {
+		if m.Name != cmp.Metric {
+			continue
+		}
+		for label := range cmp.Labels {
+			if !containsString(m.Labels, label) {
+				return fmt.Errorf("%w: metric %s has no label %s (labels: %s)", ErrInvalidAlertRule, cmp.Metric, label, strings.Join(m.Labels, ", "))
+			}
+		}
+		return nil
+	}
+	names := make([]string, 0, len(alertMetrics))
+	for _, m := range alertMetrics {
+		names = append(names, m.Name)
+	}
+	return fmt.Errorf("%w: unknown metric %s (metrics: %s)", ErrInvalidAlertRule, cmp.Metric, strings.Join(names, ", "))
+}
+
+func containsAlertType(t notifier.AlertType) bool {
+	for _, at := range alertTypes {
+		if at == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sumStats(stats []postgres.HourlyWorkloadStat, value func(postgres.HourlyWorkloadStat) float64) float64 {
+	sum := 0.0
+	for _, st := range stats {
+		sum += value(st)
+	}
+	return sum
+}
+
+// zombieCount counts the workloads graded zombie by their cost-weighted utilization over stats.
+func zombieCount(stats []postgres.HourlyWorkloadStat) int {
+	type usage struct{ cpuReq, cpuUse, memReq, memUse, cpuCost, memCost float64 }
+	workloads := make(map[string]*usage)
+	for _, st := range stats {
+		key := st.Namespace + "/" + st.WorkloadName
+		w, ok := workloads[key]
+		if !ok {
+			w = &usage{}
+			workloads[key] = w
+		}
+		w.cpuReq += st.CPURequest
+		w.cpuUse += st.CPUUsageP95
+		w.memReq += float64(st.MemRequest)
+		w.memUse += float64(st.MemUsageP95)
+		w.cpuCost += st.CPUBillableCost
+		w.memCost += st.MemBillableCost
+	}
+	zombies := 0
+	for _, w := range workloads {
+		cpuScore, memScore := 100.0, 100.0
+		if w.cpuReq > 0 {
+			cpuScore = w.cpuUse / w.cpuReq * 100
+		}
+		if w.memReq > 0 {
+			memScore = w.memUse / w.memReq * 100
+		}
+		score := 100.0
+		if total := w.cpuCost + w.memCost; total > 0 {
+			score = (cpuScore*w.cpuCost + memScore*w.memCost) / total
+		}
+		if costmodel.DefaultGradeThresholds.Grade(score) == costmodel.GradeZombie {
+			zombies++
+		}
+	}
+	return zombies
+}
+
+func roundAlertValue(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func alertOf(rule postgres.AlertRule, results []notifier.ComparisonResult, now time.Time) notifier.Alert {
+	summary := rule.Description
+	if summary == "" {
+		summary = rule.Condition
+	}
+	fields := make([]notifier.Field, 0, len(results)+1)
+	for _, r := range results {
+		if r.HasData {
+			fields = append(fields, notifier.Field{Name: r.Comparison.String(), Value: fmt.Sprintf("%g", r.Value)})
+		}
+	}
+	fields = append(fields, notifier.Field{Name: "window", Value: rule.Window.String()})
+	// 所有子句都指向同一 namespace / team 时带上该标签，供路由规则与静默匹配
+	labels := map[string]string{notifier.LabelRule: rule.Name}
+	for _, name := range []string{notifier.LabelNamespace, notifier.LabelTeam} {
+		value, same := "", true
+		for _, r := range results {
+			v := r.Labels[name]
+			if v == "" || (value != "" && v != value) {
+				same = false
+				break
+			}
+			value = v
+		}
+		if same && value != "" {
+			labels[name] = value
+		}
+	}
+	return notifier.Alert{
+		Type:      notifier.AlertType(rule.AlertType),
+		Severity:  notifier.Severity(rule.Severity),
+		Title:     "Alert rule " + rule.Name + " is firing",
+		Summary:   summary,
+		Fields:    fields,
+		DedupKey:  alertRuleDedupKeyBase + rule.ID,
+		Timestamp: now,
+		Channels:  rule.Channels,
+		Labels:    labels,
+	}
+}
+
+func toDTOAlertRule(r postgres.AlertRule) *dto.AlertRule {
+	channels := append([]string{}, r.Channels...)
+	sort.Strings(channels)
+	return &dto.AlertRule{
+		ID:              r.ID,
+		Name:            r.Name,
+		Description:     r.Description,
+		Condition:       r.Condition,
+		Window:          r.Window.String(),
+		Interval:        r.Interval.String(),
+		Severity:        r.Severity,
+		AlertType:       r.AlertType,
+		Channels:        channels,
+		Enabled:         r.Enabled,
+		CreatedBy:       r.CreatedBy,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+		State:           r.LastState,
+		LastEvaluatedAt: r.LastEvaluatedAt,
+		LastError:       r.LastError,
+		FiringSince:     r.FiringSince,
+	}
+}
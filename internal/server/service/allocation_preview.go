@@ -0,0 +1,208 @@
+// Package service allocation_preview.go: 共享成本分摊规则变更前的影响预览——把候选规则与当前规则
+// 分别套用到过去一段时间，按 namespace 给出分摊成本差异，供 FinOps 在生效前评审。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+const (
+	defaultAllocationPreviewDays = 30
+	maxAllocationPreviewDays     = 92
+)
+
+// ErrInvalidAllocationPreview is returned for invalid candidate rules or an invalid period.
+var ErrInvalidAllocationPreview = dataerr.Validation("invalid allocation preview")
+
+// SharedUsageSource measures shared resources, as for the daily ETL. prometheus.SharedUsageClient
+// satisfies this interface.
+type SharedUsageSource interface {
+	GetSharedResourceUsage(ctx context.Context, resource, key string, startTime, endTime time.Time) (units float64, byNamespace map[string]float64, err error)
+}
+
+// AllocationPreviewService compares the shared cost allocation of candidate rules with the current
+// rules over a past period. Both rule sets are recomputed from the stored daily namespace costs and
+// the same usage measurements, so the deltas come from the rules only and not from rules that
+// changed while the period was rolled up.
+type AllocationPreviewService struct {
+	repo     postgres.Repository
+	current  []costmodel.SharedResource
+	usage    SharedUsageSource
+	calendar costmodel.AccountingCalendar
+	now      func() time.Time
+}
+
+// NewAllocationPreviewService creates an AllocationPreviewService for the current rules. usage may
+// be nil when no rule (current or candidate) is metered or allocated by traffic or capacity.
+func NewAllocationPreviewService(repo postgres.Repository, current []costmodel.SharedResource, usage SharedUsageSource) *AllocationPreviewService {
+	return &AllocationPreviewService{repo: repo, current: current, usage: usage, now: time.Now}
+}
+
+// SetCalendar sets the accounting time zone the days are measured in, as for the daily ETL (default UTC).
+func (s *AllocationPreviewService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+// usageKey identifies one usage measurement, shared by the rule sets.
+type usageKey struct {
+	resource, key string
+	day           time.Time
+}
+
+type usageResult struct {
+	units    float64
+	measured map[string]float64
+}
+
+// Preview applies req.Rules and the current rules to every day of the period and returns the
+// shared cost of each namespace under both. Days default to the 30 days before today.
+func (s *AllocationPreviewService) Preview(ctx context.Context, req dto.AllocationPreviewRequest) (*dto.AllocationPreviewResponse, error) {
+	names := make(map[string]bool, len(req.Rules))
+	for _, r := range req.Rules {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAllocationPreview, err)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("%w: duplicate shared resource %s", ErrInvalidAllocationPreview, r.Name)
+		}
+		names[r.Name] = true
+	}
+	today := s.calendar.Date(s.now())
+	start, end := req.StartDate.UTC().Truncate(24*time.Hour), req.EndDate.UTC().Truncate(24*time.Hour)
+	if req.EndDate.IsZero() {
+		end = today.AddDate(0, 0, -1)
+	}
+	if req.StartDate.IsZero() {
+		start = end.AddDate(0, 0, -(defaultAllocationPreviewDays - 1))
+	}
+	days := int(end.Sub(start)/(24*time.Hour)) + 1
+	if days < 1 || days > maxAllocationPreviewDays {
+		return nil, fmt.Errorf("%w: period must be 1..%d days with start_date before end_date", ErrInvalidAllocationPreview, maxAllocationPreviewDays)
+	}
+	if !end.Before(today) {
+		return nil, fmt.Errorf("%w: end_date must be before today", ErrInvalidAllocationPreview)
+	}
+
+	rows, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{StartDate: start, EndDate: end})
+	if err != nil {
+		return nil, fmt.Errorf("list daily namespace costs: %w", err)
+	}
+	// 与日 ETL 一致：只有当天有工作负载的 namespace 计入 billable / even 的分摊对象
+	billable := make(map[time.Time]map[string]float64, days)
+	billableTotal := make(map[string]float64)
+	for _, row := range rows {
+		if row.WorkloadCount == 0 && row.BillableCost == 0 {
+			continue
+		}
+		day := row.Date.UTC().Truncate(24 * time.Hour)
+		if billable[day] == nil {
+			billable[day] = make(map[string]float64)
+		}
+		billable[day][row.Namespace] += row.BillableCost
+		billableTotal[row.Namespace] += row.BillableCost
+	}
+
+	measurements := make(map[usageKey]usageResult)
+	allocate := func(rules []costmodel.SharedResource) (byNamespace, byResource map[string]float64, err error) {
+		byNamespace, byResource = make(map[string]float64), make(map[string]float64)
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			for _, r := range rules {
+				var u usageResult
+				if r.NeedsUsage() {
+					if s.usage == nil {
+						return nil, nil, fmt.Errorf("%w: shared resource %s needs a usage source, none is configured", ErrInvalidAllocationPreview, r.Name)
+					}
+					k := usageKey{r.Name, string(r.AllocationKey), day}
+					var ok bool
+					if u, ok = measurements[k]; !ok {
+						dayStart, dayEnd := s.calendar.Bounds(day)
+						u.units, u.measured, err = s.usage.GetSharedResourceUsage(ctx, r.Name, k.key, dayStart, dayEnd)
+						if err != nil {
+							return nil, nil, fmt.Errorf("usage of shared resource %s: %w", r.Name, err)
+						}
+						measurements[k] = u
+					}
+				}
+				for ns, c := range costmodel.AllocateShared(r.DailyCost(day, u.units), r.Weights(u.measured, billable[day])) {
+					byNamespace[ns] += c
+					byResource[r.Name] += c
+				}
+			}
+		}
+		return byNamespace, byResource, nil
+	}
+	current, currentResources, err := allocate(s.current)
+	if err != nil {
+		return nil, err
+	}
+	candidate, candidateResources, err := allocate(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.AllocationPreviewResponse{StartDate: start, EndDate: end, Days: days}
+	for _, name := range unionKeys(currentResources, candidateResources) {
+		resp.Resources = append(resp.Resources, dto.AllocationPreviewResource{
+			Name:          name,
+			CurrentCost:   roundCost(currentResources[name]),
+			CandidateCost: roundCost(candidateResources[name]),
+		})
+		resp.CurrentSharedCost += currentResources[name]
+		resp.CandidateSharedCost += candidateResources[name]
+	}
+	resp.CurrentSharedCost = roundCost(resp.CurrentSharedCost)
+	resp.CandidateSharedCost = roundCost(resp.CandidateSharedCost)
+	for _, ns := range unionKeys(current, candidate) {
+		n := dto.AllocationPreviewNamespace{
+			Namespace:           ns,
+			BillableCost:        roundCost(billableTotal[ns]),
+			CurrentSharedCost:   roundCost(current[ns]),
+			CandidateSharedCost: roundCost(candidate[ns]),
+			Delta:               roundCost(candidate[ns] - current[ns]),
+		}
+		if current[ns] > 0 {
+			pct := roundCost((candidate[ns] - current[ns]) / current[ns] * 100)
+			n.DeltaPercent = &pct
+		}
+		resp.Namespaces = append(resp.Namespaces, n)
+	}
+	sort.SliceStable(resp.Namespaces, func(i, j int) bool {
+		a, b := math.Abs(resp.Namespaces[i].Delta), math.Abs(resp.Namespaces[j].Delta)
+		if a != b {
+			return a > b
+		}
+		return resp.Namespaces[i].Namespace < resp.Namespaces[j].Namespace
+	})
+	if resp.Resources == nil {
+		resp.Resources = []dto.AllocationPreviewResource{}
+	}
+	if resp.Namespaces == nil {
+		resp.Namespaces = []dto.AllocationPreviewNamespace{}
+	}
+	resp.Total = len(resp.Namespaces)
+	return resp, nil
+}
+
+// unionKeys returns the keys of a and b, sorted.
+func unionKeys(a, b map[string]float64) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,297 @@
+// Package service calculation_service.go: 成本计算流水线执行记录（CalculationRun）与失败窗口重跑。
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Calculation run triggers.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerRetry    = "retry"
+)
+
+// ErrRunNotRetriable is returned when re-triggering a run that did not fail.
+var ErrRunNotRetriable = dataerr.Validation("only failed or partial calculation runs can be re-triggered")
+
+// ErrInvalidWindow is returned for an empty or inverted calculation window.
+var ErrInvalidWindow = dataerr.Validation("calculation window end must be after start")
+
+// CalculationRunStore persists calculation runs (cost_calculation_run).
+// *postgres.MockRepository satisfies this interface.
+type CalculationRunStore interface {
+	SaveCalculationRun(ctx context.Context, run postgres.CalculationRun) error
+	GetCalculationRun(ctx context.Context, id string) (*postgres.CalculationRun, error)
+	ListCalculationRuns(ctx context.Context, filter postgres.CalculationRunFilter) ([]postgres.CalculationRun, error)
+}
+
+// CalculationPipeline computes costs for run.WindowStart..run.WindowEnd and returns the rows written.
+// run.Scopes, when set, restricts the pipeline to those scopes (targeted retry).
+type CalculationPipeline func(ctx context.Context, run postgres.CalculationRun) (int, error)
+
+// ScopedError is implemented by pipeline errors where only some scopes failed and the results of
+// the other scopes were persisted, e.g. *etl.PartialFailure.
+type ScopedError interface {
+	error
+	FailedScopes() []string
+}
+
+// CalculationRunService executes the pipeline and records every execution, so failures are
+// queryable and re-triggerable instead of only appearing in logs.
+type CalculationRunService struct {
+	store    CalculationRunStore
+	pipeline CalculationPipeline
+	now      func() time.Time
+}
+
+// NewCalculationRunService creates a CalculationRunService.
+func NewCalculationRunService(store CalculationRunStore, pipeline CalculationPipeline) *CalculationRunService {
+	return &CalculationRunService{store: store, pipeline: pipeline, now: time.Now}
+}
+
+// Execute runs the pipeline for the window and records the outcome. The returned run is always
+// non-nil once recorded; err is the pipeline (or store) error.
+func (s *CalculationRunService) Execute(ctx context.Context, trigger string, windowStart, windowEnd time.Time) (*dto.CalculationRun, error) {
+	return s.execute(ctx, postgres.CalculationRun{Trigger: trigger, WindowStart: windowStart, WindowEnd: windowEnd})
+}
+
+// Retrigger re-runs the window of a failed or partial run as a new run linked via RetryOf.
+// When the previous run recorded failed scopes only those are retried.
+func (s *CalculationRunService) Retrigger(ctx context.Context, id string) (*dto.CalculationRun, error) {
+	prev, err := s.store.GetCalculationRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Status != postgres.CalculationRunFailed && prev.Status != postgres.CalculationRunPartial {
+		return nil, fmt.Errorf("%w: run %s is %s", ErrRunNotRetriable, id, prev.Status)
+	}
+	scopes := prev.FailedScopes
+	if len(scopes) == 0 {
+		scopes = prev.Scopes
+	}
+	return s.execute(ctx, postgres.CalculationRun{
+		Trigger:     TriggerRetry,
+		WindowStart: prev.WindowStart,
+		WindowEnd:   prev.WindowEnd,
+		RetryOf:     prev.ID,
+		Scopes:      append([]string(nil), scopes...),
+	})
+}
+
+// GetRun returns a single run.
+func (s *CalculationRunService) GetRun(ctx context.Context, id string) (*dto.CalculationRun, error) {
+	run, err := s.store.GetCalculationRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := toDTOCalculationRun(*run)
+	return &out, nil
+}
+
+// ListRuns lists runs, newest first.
+func (s *CalculationRunService) ListRuns(ctx context.Context, filter postgres.CalculationRunFilter) (*dto.CalculationRunListResponse, error) {
+	runs, err := s.store.ListCalculationRuns(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.CalculationRunListResponse{Runs: make([]dto.CalculationRun, 0, len(runs))}
+	for _, r := range runs {
+		resp.Runs = append(resp.Runs, toDTOCalculationRun(r))
+	}
+	resp.Total = len(resp.Runs)
+	return resp, nil
+}
+
+func (s *CalculationRunService) execute(ctx context.Context, run postgres.CalculationRun) (*dto.CalculationRun, error) {
+	if !run.WindowEnd.After(run.WindowStart) {
+		return nil, ErrInvalidWindow
+	}
+	run.ID = uuid.New().String()
+	run.Status = postgres.CalculationRunRunning
+	run.StartedAt = s.now()
+	if err := s.store.SaveCalculationRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("record calculation run: %w", err)
+	}
+
+	var sanitized costmodel.SanitizeStats
+	rows, pipelineErr := s.pipeline(costmodel.WithSanitizeStats(ctx, &sanitized), run)
+	run.FinishedAt = s.now()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	run.RowsWritten = rows
+	run.ZeroedValues, run.DroppedRows = sanitized.ZeroedValues, sanitized.DroppedRows
+	run.Status = postgres.CalculationRunSucceeded
+	if pipelineErr != nil {
+		run.Status = postgres.CalculationRunFailed
+		run.Error = pipelineErr.Error()
+		var scoped ScopedError
+		if errors.As(pipelineErr, &scoped) {
+			run.FailedScopes = scoped.FailedScopes()
+			if rows > 0 {
+				run.Status = postgres.CalculationRunPartial
+			}
+		}
+	}
+	// 用独立 context 落库，避免调用方取消导致执行结果丢失（失败记录恰恰最需要保存）。
+	if err := s.store.SaveCalculationRun(context.WithoutCancel(ctx), run); err != nil {
+		return nil, fmt.Errorf("record calculation run: %w", err)
+	}
+	out := toDTOCalculationRun(run)
+	if pipelineErr != nil {
+		return &out, fmt.Errorf("calculation run %s failed: %w", run.ID, pipelineErr)
+	}
+	return &out, nil
+}
+
+func toDTOCalculationRun(r postgres.CalculationRun) dto.CalculationRun {
+	return dto.CalculationRun{
+		ID:           r.ID,
+		Trigger:      r.Trigger,
+		WindowStart:  r.WindowStart,
+		WindowEnd:    r.WindowEnd,
+		Status:       r.Status,
+		RowsWritten:  r.RowsWritten,
+		StartedAt:    r.StartedAt,
+		FinishedAt:   r.FinishedAt,
+		DurationMs:   r.DurationMs,
+		Error:        r.Error,
+		RetryOf:      r.RetryOf,
+		Scopes:       r.Scopes,
+		FailedScopes: r.FailedScopes,
+		ZeroedValues: r.ZeroedValues,
+		DroppedRows:  r.DroppedRows,
+	}
+}
+
+// NewSnapshotPipeline returns a pipeline that aggregates hourly workload stats of the window into a
+// CostSnapshot (CalculationID = run ID). Rows written is the number of snapshots saved.
+// guard, when non-nil, checks the snapshot before it is saved. nonFinite decides what happens to
+// stats with NaN/Inf costs; handled values are counted on the run (see CalculationRunService).
+func NewSnapshotPipeline(repo postgres.Repository, guard *SnapshotGuardrail, nonFinite costmodel.NonFinitePolicy) CalculationPipeline {
+	return func(ctx context.Context, run postgres.CalculationRun) (int, error) {
+		snapshot := postgres.CostSnapshot{
+			ID:             "snapshot-" + run.ID,
+			CalculationID:  run.ID,
+			Timestamp:      run.WindowEnd,
+			TimeRangeStart: run.WindowStart,
+			TimeRangeEnd:   run.WindowEnd,
+		}
+		if err := aggregateSnapshot(ctx, repo, &snapshot, nonFinite); err != nil {
+			return 0, err
+		}
+		if err := guard.Check(ctx, &snapshot); err != nil {
+			return 0, err
+		}
+		if err := repo.SaveCostSnapshot(ctx, snapshot); err != nil {
+			return 0, fmt.Errorf("save cost snapshot: %w", err)
+		}
+		return 1, nil
+	}
+}
+
+// GradeObserver records workload grade changes from hourly stats. *etl.GradeTracker satisfies this interface.
+type GradeObserver interface {
+	Observe(ctx context.Context, stats []postgres.HourlyWorkloadStat) ([]postgres.GradeChangeEvent, error)
+}
+
+// WithGradeTracking returns pipeline followed by grading the workloads of the run window with
+// grades, so scheduled and re-triggered runs record grade transitions (workload_grade_event). A
+// failure to record grades fails the run; its rows are already written and a retry re-grades the
+// window without duplicating events.
+func WithGradeTracking(pipeline CalculationPipeline, repo postgres.Repository, grades GradeObserver) CalculationPipeline {
+	return func(ctx context.Context, run postgres.CalculationRun) (int, error) {
+		rows, err := pipeline(ctx, run)
+		if err != nil {
+			return rows, err
+		}
+		// 窗口按 [start, end) 取，边界上的小时只计入下一个窗口
+		stats, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: run.WindowStart, EndTime: run.WindowEnd.Add(-time.Nanosecond)})
+		if err != nil {
+			return rows, fmt.Errorf("list hourly workload stats for grades: %w", err)
+		}
+		if _, err := grades.Observe(ctx, stats); err != nil {
+			return rows, err
+		}
+		return rows, nil
+	}
+}
+
+// SnapshotMetadataPriceVersions is the snapshot metadata key listing the price versions that priced
+// the snapshot's time range, as PriceVersion.Label ("<id>@<revision>"); SnapshotPriceConfig stands for
+// the part priced with the global prices from configuration.
+const (
+	SnapshotMetadataPriceVersions = "price_versions"
+	SnapshotPriceConfig           = "config"
+)
+
+// aggregateSnapshot (re)computes the cost totals of snapshot from the hourly workload stats of its time range.
+// When repo keeps a price history, the snapshot is annotated with the price versions of its time range.
+// Stats with NaN/Inf costs are handled by nonFinite and counted into costmodel.SanitizeStatsFrom(ctx).
+func aggregateSnapshot(ctx context.Context, repo postgres.Repository, snapshot *postgres.CostSnapshot, nonFinite costmodel.NonFinitePolicy) error {
+	stats, err := repo.AggregateHourlyWorkloadStats(ctx, snapshot.TimeRangeStart, snapshot.TimeRangeEnd)
+	if err != nil {
+		return fmt.Errorf("aggregate hourly workload stats: %w", err)
+	}
+	snapshot.TotalBillableCost, snapshot.TotalUsageCost, snapshot.TotalWasteCost = 0, 0, 0
+	snapshot.OverallEfficiencyScore = 0
+	for _, st := range stats {
+		keep, err := costmodel.SanitizeFields(nonFinite, costmodel.SanitizeStatsFrom(ctx),
+			costmodel.NonFiniteField{Name: "total_billable_cost", Value: &st.TotalBillableCost},
+			costmodel.NonFiniteField{Name: "total_usage_cost", Value: &st.TotalUsageCost},
+			costmodel.NonFiniteField{Name: "total_waste_cost", Value: &st.TotalWasteCost},
+		)
+		if err != nil {
+			return fmt.Errorf("stats of %s/%s: %w", st.Namespace, st.WorkloadName, err)
+		}
+		if !keep {
+			continue
+		}
+		snapshot.TotalBillableCost += st.TotalBillableCost
+		snapshot.TotalUsageCost += st.TotalUsageCost
+		snapshot.TotalWasteCost += st.TotalWasteCost
+	}
+	if snapshot.TotalBillableCost > 0 {
+		snapshot.OverallEfficiencyScore = snapshot.TotalUsageCost / snapshot.TotalBillableCost * 100
+	}
+	if prices, ok := repo.(PriceHistoryStore); ok {
+		versions, err := prices.ListPriceVersions(ctx)
+		if err != nil {
+			return fmt.Errorf("list price versions: %w", err)
+		}
+		if snapshot.Metadata == nil {
+			snapshot.Metadata = make(map[string]interface{})
+		}
+		snapshot.Metadata[SnapshotMetadataPriceVersions] = priceVersionLabels(versions, snapshot.TimeRangeStart, snapshot.TimeRangeEnd)
+	}
+	return nil
+}
+
+// priceVersionLabels returns the labels of the versions in effect during start..end in effective order,
+// preceded by SnapshotPriceConfig when the window starts before the first version.
+func priceVersionLabels(versions []postgres.PriceVersion, start, end time.Time) []string {
+	sort.Slice(versions, func(i, j int) bool { return versions[i].EffectiveFrom.Before(versions[j].EffectiveFrom) })
+	labels := []string{}
+	if len(versions) == 0 || versions[0].EffectiveFrom.After(start) {
+		labels = append(labels, SnapshotPriceConfig)
+	}
+	for i, v := range versions {
+		if !v.EffectiveFrom.Before(end) {
+			break
+		}
+		if i+1 < len(versions) && !versions[i+1].EffectiveFrom.After(start) {
+			continue // 窗口开始前已被下一版本取代
+		}
+		labels = append(labels, v.Label())
+	}
+	return labels
+}
@@ -0,0 +1,483 @@
+// Package service capacity_service.go: 集群容量规划（按 namespace 请求量增长趋势预测 CPU/内存余量耗尽时间，并模拟增减节点）。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// DefaultNodePoolLabel is the generic node label grouping nodes into pools, the last of
+// costmodel.DefaultNodePoolLabels; nodes without any pool label form "default".
+const DefaultNodePoolLabel = "node-type"
+
+const (
+	defaultCapacityHistoryDays = 14
+	defaultCapacityHorizonDays = 90
+	maxCapacityHorizonDays     = 730
+	maxRequestChangePercent    = 1000
+)
+
+var (
+	// ErrUnknownNodePool is returned when the scenario pool does not exist.
+	ErrUnknownNodePool = dataerr.NotFound("unknown node pool")
+	// ErrInvalidCapacityQuery is returned for an out-of-range horizon or a node delta emptying a pool.
+	ErrInvalidCapacityQuery = dataerr.Validation("invalid capacity projection query")
+	// ErrPodsUnavailable is returned by Simulate when no pod lister is set.
+	ErrPodsUnavailable = dataerr.Unavailable("pod requests unavailable")
+)
+
+// CapacityProjectionQuery parameterizes a projection. Zero values use the defaults: 90 days
+// horizon, DefaultConsolidationTargetUtilization and the pool with the most nodes.
+type CapacityProjectionQuery struct {
+	HorizonDays       int
+	TargetUtilization float64
+	// NodeDelta nodes of Pool's average allocatable size are added (negative: removed) in the scenario.
+	NodeDelta int
+	Pool      string
+}
+
+// CapacitySimulationQuery parameterizes an autoscaler simulation. Zero TargetUtilization uses
+// DefaultConsolidationTargetUtilization; an empty Pool simulates every pool.
+type CapacitySimulationQuery struct {
+	// RequestChangePercent scales the requests of every pod, e.g. -20 for "reduce requests by 20%".
+	RequestChangePercent float64
+	TargetUtilization    float64
+	Pool                 string
+}
+
+// PodLister lists the pods of a namespace; *k8s.MockClient satisfies it.
+type PodLister interface {
+	GetNamespaces(ctx context.Context) ([]k8s.Namespace, error)
+	GetPods(ctx context.Context, namespace, deployment string) ([]k8s.Pod, error)
+}
+
+// CapacityService projects cluster requests against node pool capacity.
+type CapacityService struct {
+	nodes       NodeLister
+	pods        PodLister
+	repo        postgres.Repository
+	cpuPrice    float64 // per core hour
+	memPrice    float64 // per GiB hour
+	poolLabels  []string
+	historyDays int
+	now         func() time.Time
+}
+
+// NewCapacityService creates a CapacityService priced with the given unit prices.
+func NewCapacityService(nodes NodeLister, repo postgres.Repository, cpuPricePerCoreHour, memPricePerGBHour float64) *CapacityService {
+	return &CapacityService{
+		nodes:       nodes,
+		repo:        repo,
+		cpuPrice:    cpuPricePerCoreHour,
+		memPrice:    memPricePerGBHour,
+		historyDays: defaultCapacityHistoryDays,
+		now:         time.Now,
+	}
+}
+
+// SetPodLister enables Simulate, which bin-packs the requests of the listed pods.
+func (s *CapacityService) SetPodLister(pods PodLister) {
+	s.pods = pods
+}
+
+// SetNodePoolLabels sets the node labels naming the pool of a node (default costmodel.DefaultNodePoolLabels).
+func (s *CapacityService) SetNodePoolLabels(labels []string) {
+	s.poolLabels = labels
+}
+
+type capacityPool struct {
+	name     string
+	nodes    int
+	cpu, mem float64 // allocatable cores / bytes
+}
+
+// nsGrowth is one namespace's current daily-average requests and fitted growth per day.
+type nsGrowth struct {
+	cpu, mem           float64
+	cpuSlope, memSlope float64
+}
+
+// Project returns the capacity projection. Each namespace's daily average requests over the last
+// 14 days are fitted with a linear trend and extrapolated from its latest day (never below zero);
+// the cluster exhausts a resource on the first day its projected requests exceed allocatable*target.
+func (s *CapacityService) Project(ctx context.Context, q CapacityProjectionQuery) (*dto.CapacityProjectionResponse, error) {
+	if q.HorizonDays == 0 {
+		q.HorizonDays = defaultCapacityHorizonDays
+	}
+	if q.HorizonDays < 0 || q.HorizonDays > maxCapacityHorizonDays {
+		return nil, fmt.Errorf("%w: horizon_days must be in 1..%d", ErrInvalidCapacityQuery, maxCapacityHorizonDays)
+	}
+	if q.TargetUtilization == 0 {
+		q.TargetUtilization = DefaultConsolidationTargetUtilization
+	}
+	if q.TargetUtilization < 0 || q.TargetUtilization > 1 {
+		return nil, ErrInvalidTargetUtilization
+	}
+
+	nodes, err := s.nodes.GetNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pools := s.pools(nodes)
+
+	now := s.now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{
+		StartTime: today.AddDate(0, 0, -s.historyDays),
+		EndTime:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	growth := namespaceGrowth(stats)
+
+	resp := &dto.CapacityProjectionResponse{
+		GeneratedAt:       now,
+		HistoryDays:       s.historyDays,
+		HorizonDays:       q.HorizonDays,
+		TargetUtilization: q.TargetUtilization,
+		Pools:             make([]dto.CapacityPool, 0, len(pools)),
+		Namespaces:        make([]dto.NamespaceGrowth, 0, len(growth)),
+		Series:            make([]dto.CapacityProjectionPoint, 0, q.HorizonDays+1),
+	}
+	var total capacityPool
+	for _, p := range pools {
+		total.nodes += p.nodes
+		total.cpu += p.cpu
+		total.mem += p.mem
+		resp.Pools = append(resp.Pools, dto.CapacityPool{
+			Name:           p.name,
+			NodeCount:      p.nodes,
+			AllocatableCPU: p.cpu,
+			AllocatableMem: int64(p.mem),
+			MonthlyCost:    s.hourlyCost(p.cpu, p.mem) * costmodel.HoursPerMonth,
+		})
+	}
+	names := make([]string, 0, len(growth))
+	for ns := range growth {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	for _, ns := range names {
+		g := growth[ns]
+		resp.Namespaces = append(resp.Namespaces, dto.NamespaceGrowth{
+			Namespace:       ns,
+			CPURequest:      g.cpu,
+			MemRequest:      int64(g.mem),
+			CPUGrowthPerDay: g.cpuSlope,
+			MemGrowthPerDay: g.memSlope,
+		})
+	}
+
+	// 按天预测集群请求量
+	cpuReq := make([]float64, q.HorizonDays+1)
+	memReq := make([]float64, q.HorizonDays+1)
+	for d := 0; d <= q.HorizonDays; d++ {
+		for _, g := range growth {
+			if v := g.cpu + g.cpuSlope*float64(d); v > 0 {
+				cpuReq[d] += v
+			}
+			if v := g.mem + g.memSlope*float64(d); v > 0 {
+				memReq[d] += v
+			}
+		}
+	}
+
+	resp.Baseline = s.scenario(total, today, cpuReq, memReq, q.TargetUtilization)
+	for d := range cpuReq {
+		resp.Series = append(resp.Series, dto.CapacityProjectionPoint{
+			Date:           today.AddDate(0, 0, d),
+			CPURequest:     cpuReq[d],
+			MemRequest:     int64(memReq[d]),
+			CPUUtilization: percentOf(cpuReq[d], total.cpu),
+			MemUtilization: percentOf(memReq[d], total.mem),
+		})
+	}
+
+	if q.NodeDelta != 0 {
+		pool, err := choosePool(pools, q.Pool)
+		if err != nil {
+			return nil, err
+		}
+		if pool.nodes+q.NodeDelta < 0 || total.nodes+q.NodeDelta <= 0 {
+			return nil, fmt.Errorf("%w: pool %s has %d nodes", ErrInvalidCapacityQuery, pool.name, pool.nodes)
+		}
+		avgCPU, avgMem := pool.cpu/float64(pool.nodes), pool.mem/float64(pool.nodes)
+		adjusted := capacityPool{
+			nodes: total.nodes + q.NodeDelta,
+			cpu:   total.cpu + avgCPU*float64(q.NodeDelta),
+			mem:   total.mem + avgMem*float64(q.NodeDelta),
+		}
+		scenario := s.scenario(adjusted, today, cpuReq, memReq, q.TargetUtilization)
+		scenario.Pool = pool.name
+		scenario.NodeDelta = q.NodeDelta
+		scenario.MonthlyCostDelta = scenario.MonthlyCost - resp.Baseline.MonthlyCost
+		resp.Scenario = &scenario
+	}
+	return resp, nil
+}
+
+// Simulate estimates the nodes and billable cost a cluster autoscaler would run after changing all
+// pod requests by q.RequestChangePercent. The running and pending pods are bin-packed onto the
+// average node of their pool (costmodel.AutoscalerModel, at least one node per pool with pods) once
+// with current and once with changed requests; pods not bound to a known node count in the largest pool.
+func (s *CapacityService) Simulate(ctx context.Context, q CapacitySimulationQuery) (*dto.CapacitySimulationResponse, error) {
+	if q.RequestChangePercent <= -100 || q.RequestChangePercent > maxRequestChangePercent {
+		return nil, fmt.Errorf("%w: request_change_percent must be in (-100, %d]", ErrInvalidCapacityQuery, maxRequestChangePercent)
+	}
+	if q.TargetUtilization == 0 {
+		q.TargetUtilization = DefaultConsolidationTargetUtilization
+	}
+	if q.TargetUtilization < 0 || q.TargetUtilization > 1 {
+		return nil, ErrInvalidTargetUtilization
+	}
+	if s.pods == nil {
+		return nil, ErrPodsUnavailable
+	}
+
+	nodes, err := s.nodes.GetNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pools := s.pools(nodes)
+	if _, err := choosePool(pools, q.Pool); err != nil {
+		return nil, err
+	}
+	poolOf := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		poolOf[n.Name] = costmodel.NodePoolOf(n.Labels, s.poolLabels)
+	}
+	pods, err := s.podRequests(ctx, poolOf, pools[0].name)
+	if err != nil {
+		return nil, err
+	}
+
+	factor := 1 + q.RequestChangePercent/100
+	model := costmodel.AutoscalerModel{TargetUtilization: q.TargetUtilization, MinNodes: 1}
+	resp := &dto.CapacitySimulationResponse{
+		GeneratedAt:          s.now().UTC(),
+		RequestChangePercent: q.RequestChangePercent,
+		TargetUtilization:    q.TargetUtilization,
+		Pools:                make([]dto.CapacitySimulationPool, 0, len(pools)),
+	}
+	var cpu, mem float64
+	for _, p := range pools {
+		if q.Pool != "" && p.name != q.Pool {
+			continue
+		}
+		node := costmodel.NodeTemplate{CPU: p.cpu / float64(p.nodes), Mem: p.mem / float64(p.nodes)}
+		sp := dto.CapacitySimulationPool{
+			Name:               p.name,
+			Pods:               len(pods[p.name]),
+			NodeCPU:            node.CPU,
+			NodeMem:            int64(node.Mem),
+			NodeMonthlyCost:    s.hourlyCost(node.CPU, node.Mem) * costmodel.HoursPerMonth,
+			NodeCount:          p.nodes,
+			BaselineNodeCount:  p.nodes,
+			SimulatedNodeCount: p.nodes,
+		}
+		if sp.Pods > 0 {
+			sp.BaselineNodeCount, sp.OversizedPods = model.Nodes(node, pods[p.name])
+			sp.SimulatedNodeCount, _ = model.Nodes(node, costmodel.ScalePodRequests(pods[p.name], factor))
+		}
+		for _, pod := range pods[p.name] {
+			cpu += pod.CPU
+			mem += pod.Mem
+		}
+		sp.NodeReduction = sp.BaselineNodeCount - sp.SimulatedNodeCount
+		sp.MonthlySavings = float64(sp.NodeReduction) * sp.NodeMonthlyCost
+		resp.NodeCount += sp.NodeCount
+		resp.BaselineNodeCount += sp.BaselineNodeCount
+		resp.SimulatedNodeCount += sp.SimulatedNodeCount
+		resp.NodeReduction += sp.NodeReduction
+		resp.MonthlyCost += float64(sp.NodeCount) * sp.NodeMonthlyCost
+		resp.MonthlySavings += sp.MonthlySavings
+		resp.Pools = append(resp.Pools, sp)
+	}
+	resp.SimulatedMonthlyCost = resp.MonthlyCost - resp.MonthlySavings
+	resp.CPURequest, resp.SimulatedCPURequest = cpu, cpu*factor
+	resp.MemRequest, resp.SimulatedMemRequest = int64(mem), int64(mem*factor)
+	resp.RequestCostDelta = s.hourlyCost(cpu*(factor-1), mem*(factor-1)) * costmodel.HoursPerMonth
+	return resp, nil
+}
+
+// podRequests returns the container requests of the running and pending pods by node pool.
+func (s *CapacityService) podRequests(ctx context.Context, poolOf map[string]string, fallbackPool string) (map[string][]costmodel.PodRequest, error) {
+	namespaces, err := s.pods.GetNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]costmodel.PodRequest)
+	for _, ns := range namespaces {
+		pods, err := s.pods.GetPods(ctx, ns.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pods {
+			if p.Phase == "Succeeded" || p.Phase == "Failed" {
+				continue
+			}
+			var req costmodel.PodRequest
+			for _, c := range p.Containers {
+				cpu, _ := k8s.ParseQuantity(c.Resources.Requests["cpu"])
+				mem, _ := k8s.ParseQuantity(c.Resources.Requests["memory"])
+				req.CPU += cpu
+				req.Mem += mem
+			}
+			pool, ok := poolOf[p.NodeName]
+			if !ok {
+				pool = fallbackPool
+			}
+			out[pool] = append(out[pool], req)
+		}
+	}
+	return out, nil
+}
+
+// pools groups nodes by pool (costmodel.NodePoolOf), largest pool first.
+func (s *CapacityService) pools(nodes []k8s.Node) []capacityPool {
+	byName := make(map[string]*capacityPool)
+	for _, n := range nodes {
+		name := costmodel.NodePoolOf(n.Labels, s.poolLabels)
+		p, ok := byName[name]
+		if !ok {
+			p = &capacityPool{name: name}
+			byName[name] = p
+		}
+		cpu, _ := k8s.ParseQuantity(n.Allocatable["cpu"])
+		mem, _ := k8s.ParseQuantity(n.Allocatable["memory"])
+		p.nodes++
+		p.cpu += cpu
+		p.mem += mem
+	}
+	pools := make([]capacityPool, 0, len(byName))
+	for _, p := range byName {
+		pools = append(pools, *p)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].nodes != pools[j].nodes {
+			return pools[i].nodes > pools[j].nodes
+		}
+		return pools[i].name < pools[j].name
+	})
+	return pools
+}
+
+func choosePool(pools []capacityPool, name string) (capacityPool, error) {
+	for _, p := range pools {
+		if name == "" || p.name == name {
+			return p, nil
+		}
+	}
+	if name == "" {
+		return capacityPool{}, fmt.Errorf("%w: cluster has no nodes", ErrUnknownNodePool)
+	}
+	return capacityPool{}, fmt.Errorf("%w: %s", ErrUnknownNodePool, name)
+}
+
+// scenario evaluates capacity c against the projected daily requests.
+func (s *CapacityService) scenario(c capacityPool, today time.Time, cpuReq, memReq []float64, target float64) dto.CapacityScenario {
+	sc := dto.CapacityScenario{
+		NodeCount:      c.nodes,
+		AllocatableCPU: c.cpu,
+		AllocatableMem: int64(c.mem),
+		MonthlyCost:    s.hourlyCost(c.cpu, c.mem) * costmodel.HoursPerMonth,
+		CPUUtilization: percentOf(cpuReq[0], c.cpu),
+		MemUtilization: percentOf(memReq[0], c.mem),
+	}
+	sc.CPUExhaustionDate = exhaustionDate(today, cpuReq, c.cpu*target)
+	sc.MemExhaustionDate = exhaustionDate(today, memReq, c.mem*target)
+	return sc
+}
+
+func (s *CapacityService) hourlyCost(cpu, mem float64) float64 {
+	return cpu*s.cpuPrice + mem/(1<<30)*s.memPrice
+}
+
+// exhaustionDate returns the first day the requests exceed limit, or nil within the horizon.
+func exhaustionDate(today time.Time, requests []float64, limit float64) *time.Time {
+	for d, v := range requests {
+		if v > limit {
+			t := today.AddDate(0, 0, d)
+			return &t
+		}
+	}
+	return nil
+}
+
+func percentOf(v, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return v / total * 100
+}
+
+// namespaceGrowth averages each namespace's hourly requests per day and fits their daily trend.
+// Days without stats for a namespace count as zero requests, so new namespaces show as growth.
+func namespaceGrowth(stats []postgres.HourlyWorkloadStat) map[string]nsGrowth {
+	type hourKey struct {
+		ns   string
+		hour time.Time
+	}
+	type dayKey struct {
+		ns  string
+		day time.Time
+	}
+	hourly := make(map[hourKey][2]float64)
+	var first, last time.Time
+	for _, st := range stats {
+		hour := st.Timestamp.UTC().Truncate(time.Hour)
+		k := hourKey{st.Namespace, hour}
+		v := hourly[k]
+		v[0] += st.CPURequest
+		v[1] += float64(st.MemRequest)
+		hourly[k] = v
+		if day := hour.Truncate(24 * time.Hour); first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day := hour.Truncate(24 * time.Hour); day.After(last) {
+			last = day
+		}
+	}
+	if first.IsZero() {
+		return map[string]nsGrowth{}
+	}
+
+	daily := make(map[dayKey][3]float64) // cpu sum, mem sum, hours
+	for k, v := range hourly {
+		dk := dayKey{k.ns, k.hour.Truncate(24 * time.Hour)}
+		d := daily[dk]
+		d[0] += v[0]
+		d[1] += v[1]
+		d[2]++
+		daily[dk] = d
+	}
+	days := int(last.Sub(first)/(24*time.Hour)) + 1
+	series := make(map[string][2][]float64)
+	for k, d := range daily {
+		s, ok := series[k.ns]
+		if !ok {
+			s = [2][]float64{make([]float64, days), make([]float64, days)}
+			series[k.ns] = s
+		}
+		i := int(k.day.Sub(first) / (24 * time.Hour))
+		s[0][i] = d[0] / d[2]
+		s[1][i] = d[1] / d[2]
+	}
+
+	growth := make(map[string]nsGrowth, len(series))
+	for ns, s := range series {
+		cpuSlope, _ := costmodel.LinearTrend(s[0])
+		memSlope, _ := costmodel.LinearTrend(s[1])
+		growth[ns] = nsGrowth{cpu: s[0][days-1], mem: s[1][days-1], cpuSlope: cpuSlope, memSlope: memSlope}
+	}
+	return growth
+}
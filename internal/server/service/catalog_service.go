@@ -0,0 +1,371 @@
+// Package service catalog_service.go: 工作负载目录——汇总每个工作负载的最新状态（归属团队、等级、月度成本、
+// 最近建议、SLO 状态），以内存索引支持全文与分面检索，由同步任务定期刷新。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+const (
+	// catalogWindowDays is how far back a refresh looks for hourly stats; workloads without stats in
+	// the window drop out of the catalog.
+	catalogWindowDays      = 7
+	defaultCatalogInterval = 5 * time.Minute
+	maxCatalogLimit        = 500
+)
+
+// Catalog facets, in response order.
+const (
+	CatalogFacetNamespace = "namespace"
+	CatalogFacetTeam      = "team"
+	CatalogFacetGrade     = "grade"
+	CatalogFacetType      = "type"
+	CatalogFacetSLOStatus = "slo_status"
+)
+
+var (
+	// ErrCatalogNotReady is returned by Search before the first successful refresh.
+	ErrCatalogNotReady = dataerr.Unavailable("workload catalog not refreshed yet")
+	// ErrInvalidCatalogQuery is returned for a negative or too large limit or offset.
+	ErrInvalidCatalogQuery = dataerr.Validation("invalid catalog query")
+)
+
+// CatalogQuery searches the catalog. Text terms must each prefix-match a word of the workload
+// (namespace, name, type, team, grade, recommendation, SLO status); facet fields match exactly.
+// Zero values match everything; Limit 0 returns 50 workloads.
+type CatalogQuery struct {
+	Text      string
+	Namespace string
+	Team      string
+	Grade     string
+	Type      string
+	SLOStatus string
+	Limit     int
+	Offset    int
+}
+
+// CatalogService keeps an in-memory index of the latest state of every workload. It is safe for
+// concurrent use; Refresh swaps the whole index, so searches never see a half-built one.
+type CatalogService struct {
+	repo   postgres.Repository
+	slo    SLOStatusProvider
+	grades GradeHistoryStore
+	teams  map[string]string // namespace -> team
+	now    func() time.Time
+
+	mu          sync.RWMutex
+	index       *catalogIndex
+	refreshedAt time.Time
+}
+
+// catalogIndex is an immutable snapshot of the catalog with its word index.
+type catalogIndex struct {
+	workloads []dto.CatalogWorkload // sorted by monthly cost, descending
+	postings  map[string][]int      // word -> workload positions, ascending
+	words     []string              // sorted keys of postings, for prefix lookup
+}
+
+// NewCatalogService creates a CatalogService. teams maps team names to their namespaces (a
+// namespace claimed by several teams goes to the first name in sorted order); sloProvider may be
+// nil.
+func NewCatalogService(repo postgres.Repository, sloProvider SLOStatusProvider, teams map[string][]string) *CatalogService {
+	names := make([]string, 0, len(teams))
+	for name := range teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	owners := make(map[string]string)
+	for _, name := range names {
+		for _, ns := range teams[name] {
+			if _, ok := owners[ns]; !ok {
+				owners[ns] = name
+			}
+		}
+	}
+	return &CatalogService{repo: repo, slo: sloProvider, teams: owners, now: time.Now}
+}
+
+// SetGradeHistory makes the catalog report the latest recorded grade transition instead of the
+// grade of the window's efficiency.
+func (s *CatalogService) SetGradeHistory(grades GradeHistoryStore) {
+	s.grades = grades
+}
+
+// catalogAcc accumulates the hourly stats of one workload.
+type catalogAcc struct {
+	w               dto.CatalogWorkload
+	billable, usage float64
+	hours           map[time.Time]bool
+	latest          time.Time
+	latestUsage     costmodel.WorkloadUsage
+}
+
+// Refresh rebuilds the catalog from the hourly stats of the last 7 days.
+func (s *CatalogService) Refresh(ctx context.Context) error {
+	end := s.now().UTC()
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: end.AddDate(0, 0, -catalogWindowDays), EndTime: end})
+	if err != nil {
+		return fmt.Errorf("workload catalog: list hourly workload stats: %w", err)
+	}
+	type key struct{ namespace, name string }
+	accs := make(map[key]*catalogAcc)
+	for _, st := range stats {
+		k := key{st.Namespace, st.WorkloadName}
+		a := accs[k]
+		if a == nil {
+			a = &catalogAcc{w: dto.CatalogWorkload{Namespace: st.Namespace, Name: st.WorkloadName, Type: st.WorkloadType}, hours: make(map[time.Time]bool)}
+			accs[k] = a
+		}
+		hour := st.Timestamp.UTC().Truncate(time.Hour)
+		a.hours[hour] = true
+		a.billable += st.TotalBillableCost
+		a.usage += st.TotalUsageCost
+		if hour.After(a.latest) {
+			a.latest, a.latestUsage = hour, costmodel.WorkloadUsage{}
+		}
+		if hour.Equal(a.latest) {
+			a.latestUsage.CPURequest += st.CPURequest
+			a.latestUsage.CPUUsageP95 += st.CPUUsageP95
+			a.latestUsage.MemRequest += st.MemRequest
+			a.latestUsage.MemUsageP95 += st.MemUsageP95
+			a.latestUsage.CPUHourlyCost += st.CPUBillableCost
+			a.latestUsage.MemHourlyCost += st.MemBillableCost
+		}
+	}
+
+	latestGrade := make(map[key]postgres.GradeChangeEvent)
+	if s.grades != nil {
+		events, err := s.grades.ListGradeChangeEvents(ctx, postgres.GradeChangeEventFilter{})
+		if err != nil {
+			return fmt.Errorf("workload catalog: list grade changes: %w", err)
+		}
+		for _, e := range events {
+			k := key{e.Namespace, e.WorkloadName}
+			if prev, ok := latestGrade[k]; !ok || !e.OccurredAt.Before(prev.OccurredAt) {
+				latestGrade[k] = e
+			}
+		}
+	}
+	sloStatus := make(map[key]string)
+	if s.slo != nil {
+		services, err := s.slo.ListSLOStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("workload catalog: list SLO status: %w", err)
+		}
+		for _, svc := range services {
+			sloStatus[key{svc.Namespace, svc.Service}] = string(svc.Status)
+		}
+	}
+
+	workloads := make([]dto.CatalogWorkload, 0, len(accs))
+	for k, a := range accs {
+		w := a.w
+		w.Team = s.teams[k.namespace]
+		w.LastSeen = a.latest
+		w.SLOStatus = sloStatus[k]
+		w.MonthlyCost = roundCost(a.billable / float64(len(a.hours)) * costmodel.HoursPerMonth)
+		if a.billable > 0 {
+			w.EfficiencyScore = roundCost(a.usage / a.billable * 100)
+		}
+		if e, ok := latestGrade[k]; ok {
+			w.Grade = e.ToGrade
+		} else {
+			w.Grade = string(costmodel.CostPolicy{}.Thresholds().Grade(w.EfficiencyScore))
+		}
+		for _, r := range costmodel.Recommend(costmodel.EfficiencyGrade(w.Grade), a.latestUsage) {
+			if w.LastRecommendation == nil || math.Abs(r.EstimatedMonthlySavings) > math.Abs(w.LastRecommendation.EstimatedMonthlySavings) {
+				r := r
+				w.LastRecommendation = &r
+			}
+		}
+		workloads = append(workloads, w)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		a, b := workloads[i], workloads[j]
+		if a.MonthlyCost != b.MonthlyCost {
+			return a.MonthlyCost > b.MonthlyCost
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	index := &catalogIndex{workloads: workloads, postings: make(map[string][]int)}
+	for i, w := range workloads {
+		seen := make(map[string]bool)
+		for _, word := range catalogWords(w) {
+			if !seen[word] {
+				seen[word] = true
+				index.postings[word] = append(index.postings[word], i)
+			}
+		}
+	}
+	index.words = make([]string, 0, len(index.postings))
+	for word := range index.postings {
+		index.words = append(index.words, word)
+	}
+	sort.Strings(index.words)
+
+	s.mu.Lock()
+	s.index, s.refreshedAt = index, end
+	s.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the catalog now and then every interval (default 5 minutes) until ctx is done.
+// Every replica keeps its own index, so Run is not a leader-elected scheduler job.
+func (s *CatalogService) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = defaultCatalogInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Refresh(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Search returns the page of workloads matching q and the facet counts of all matches.
+func (s *CatalogService) Search(ctx context.Context, q CatalogQuery) (*dto.CatalogSearchResponse, error) {
+	if q.Limit < 0 || q.Limit > maxCatalogLimit || q.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit must be in 0..%d and offset not negative", ErrInvalidCatalogQuery, maxCatalogLimit)
+	}
+	if q.Limit == 0 {
+		q.Limit = 50
+	}
+	s.mu.RLock()
+	index, refreshedAt := s.index, s.refreshedAt
+	s.mu.RUnlock()
+	if index == nil {
+		return nil, ErrCatalogNotReady
+	}
+
+	candidates := index.match(q.Text)
+	resp := &dto.CatalogSearchResponse{Workloads: []dto.CatalogWorkload{}, RefreshedAt: refreshedAt}
+	counts := make(map[string]map[string]int)
+	for _, i := range candidates {
+		w := index.workloads[i]
+		facets := catalogFacets(w)
+		if !facetMatches(facets[CatalogFacetNamespace], q.Namespace) || !facetMatches(facets[CatalogFacetTeam], q.Team) ||
+			!facetMatches(facets[CatalogFacetGrade], q.Grade) || !facetMatches(facets[CatalogFacetType], q.Type) ||
+			!facetMatches(facets[CatalogFacetSLOStatus], q.SLOStatus) {
+			continue
+		}
+		for name, v := range facets {
+			if v == "" {
+				continue
+			}
+			if counts[name] == nil {
+				counts[name] = make(map[string]int)
+			}
+			counts[name][v]++
+		}
+		if resp.Total >= q.Offset && len(resp.Workloads) < q.Limit {
+			resp.Workloads = append(resp.Workloads, w)
+		}
+		resp.Total++
+	}
+	resp.Facets = make(map[string][]dto.CatalogFacetCount, len(counts))
+	for name, values := range counts {
+		list := make([]dto.CatalogFacetCount, 0, len(values))
+		for v, n := range values {
+			list = append(list, dto.CatalogFacetCount{Value: v, Count: n})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Count != list[j].Count {
+				return list[i].Count > list[j].Count
+			}
+			return list[i].Value < list[j].Value
+		})
+		resp.Facets[name] = list
+	}
+	return resp, nil
+}
+
+// match returns the positions of the workloads matching every term of text, ascending.
+func (idx *catalogIndex) match(text string) []int {
+	terms := tokenize(text)
+	if len(terms) == 0 {
+		all := make([]int, len(idx.workloads))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+	var result map[int]bool
+	for _, term := range terms {
+		hits := make(map[int]bool)
+		for i := sort.SearchStrings(idx.words, term); i < len(idx.words) && strings.HasPrefix(idx.words[i], term); i++ {
+			for _, pos := range idx.postings[idx.words[i]] {
+				if result == nil || result[pos] {
+					hits[pos] = true
+				}
+			}
+		}
+		result = hits
+		if len(result) == 0 {
+			return nil
+		}
+	}
+	out := make([]int, 0, len(result))
+	for pos := range result {
+		out = append(out, pos)
+	}
+	sort.Ints(out)
+	return out
+}
+
+func catalogFacets(w dto.CatalogWorkload) map[string]string {
+	return map[string]string{
+		CatalogFacetNamespace: w.Namespace,
+		CatalogFacetTeam:      w.Team,
+		CatalogFacetGrade:     w.Grade,
+		CatalogFacetType:      w.Type,
+		CatalogFacetSLOStatus: w.SLOStatus,
+	}
+}
+
+func facetMatches(value, want string) bool {
+	return want == "" || strings.EqualFold(value, want)
+}
+
+// catalogWords returns the searchable words of w.
+func catalogWords(w dto.CatalogWorkload) []string {
+	text := []string{w.Namespace, w.Name, w.Type, w.Team, w.Grade, w.SLOStatus}
+	if w.LastRecommendation != nil {
+		text = append(text, string(w.LastRecommendation.Action))
+	}
+	// 完整名称也作为一个词，"app-prod" 既能按整体也能按 "prod" 前缀命中
+	words := append([]string{}, tokenize(strings.Join(text, " "))...)
+	for _, t := range []string{w.Namespace, w.Name} {
+		words = append(words, strings.ToLower(t))
+	}
+	return words
+}
+
+// tokenize lowercases s and splits it into letter/digit runs.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
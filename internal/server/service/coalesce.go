@@ -0,0 +1,144 @@
+// Package service coalesce.go: 相同查询的请求合并——看板同时发出的多个相同聚合查询只计算一次，
+// 并发的调用方共享同一结果（singleflight，按规范化后的查询参数作为键）。
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// QueryCoalescer shares one computation among concurrent identical queries: while a query is in
+// flight, callers of the same query and parameters wait for its result instead of running it
+// again. Results are shared between the callers and must not be modified.
+//
+// The computation runs in the context of the caller that started it, so its database and
+// Prometheus queries are charged to that caller's query budget (querybudget.WithConsumer) only;
+// the callers served by it are not charged.
+type QueryCoalescer struct {
+	group   singleflight.Group
+	mu      sync.Mutex
+	stats   map[string]*CoalesceStats
+	timeout time.Duration
+}
+
+// DefaultCoalesceTimeout caps a shared computation whose caller has no deadline, see SetTimeout.
+const DefaultCoalesceTimeout = 30 * time.Second
+
+// CoalesceStats counts the calls of one query and the computations they ran; the difference is
+// the calls served by another caller's computation.
+type CoalesceStats struct {
+	Calls      int64
+	Executions int64
+}
+
+// NewQueryCoalescer creates a QueryCoalescer.
+func NewQueryCoalescer() *QueryCoalescer {
+	return &QueryCoalescer{stats: make(map[string]*CoalesceStats), timeout: DefaultCoalesceTimeout}
+}
+
+// SetTimeout caps every shared computation to d (default DefaultCoalesceTimeout), in addition to
+// the deadline of the caller that started it; d <= 0 keeps only the caller's deadline. Not safe
+// for use concurrently with queries.
+func (c *QueryCoalescer) SetTimeout(d time.Duration) {
+	c.timeout = d
+}
+
+// Stats returns the counts per query name.
+func (c *QueryCoalescer) Stats() map[string]CoalesceStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]CoalesceStats, len(c.stats))
+	for q, st := range c.stats {
+		out[q] = *st
+	}
+	return out
+}
+
+func (c *QueryCoalescer) count(query string, execution bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st, ok := c.stats[query]
+	if !ok {
+		st = &CoalesceStats{}
+		c.stats[query] = st
+	}
+	if execution {
+		st.Executions++
+	} else {
+		st.Calls++
+	}
+}
+
+// coalesce runs fn for query with the normalized params, or waits for the identical call in
+// flight. fn runs detached from the caller's cancellation so a caller going away does not fail
+// the others, but keeps its deadline (capped by the coalescer timeout) so it cannot run unbounded;
+// a cancelled caller stops waiting and gets the context error. A nil c runs fn directly.
+func coalesce[T any](ctx context.Context, c *QueryCoalescer, query string, params []string, fn func(ctx context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return fn(ctx)
+	}
+	c.count(query, false)
+	key := query + "?" + strings.Join(params, "&")
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		c.count(query, true)
+		fctx, cancel := c.detach(ctx)
+		defer cancel()
+		return fn(fctx)
+	})
+	var zero T
+	select {
+	case r := <-ch:
+		if r.Err != nil {
+			return zero, r.Err
+		}
+		return r.Val.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// detach returns ctx without its cancellation but with its deadline, or now+timeout if earlier.
+func (c *QueryCoalescer) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if c.timeout > 0 {
+		if capped := time.Now().Add(c.timeout); !ok || capped.Before(deadline) {
+			deadline, ok = capped, true
+		}
+	}
+	detached := context.WithoutCancel(ctx)
+	if !ok {
+		return detached, func() {}
+	}
+	return context.WithDeadline(detached, deadline)
+}
+
+// pageKey returns the normalized coalescing parameters of page (the defaults spelled out).
+func pageKey(page costmodel.PageOptions) []string {
+	if page.Sort == "" {
+		page.Sort = costmodel.SortByCost
+	}
+	if page.Order == "" {
+		page.Order = costmodel.OrderDesc
+	}
+	return []string{
+		"sort=" + string(page.Sort),
+		"order=" + string(page.Order),
+		"offset=" + strconv.Itoa(page.Offset),
+		"limit=" + strconv.Itoa(page.Limit),
+	}
+}
+
+// timeKey returns the normalized coalescing parameter of t: UTC, "" for the zero time (the default).
+func timeKey(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
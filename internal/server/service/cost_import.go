@@ -0,0 +1,360 @@
+// Package service cost_import.go: Kubecost / OpenCost 分配数据导入，供迁移时保留历史。导出按小时拆分为
+// HourlyWorkloadStat（按 controller 聚合或更细的分配）并按会计日汇总为 DailyNamespaceCost，行上记录导入来源；
+// Lighthouse 自身计算的行不被覆盖，重复导入替换上次导入的行。
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/kubecost"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Import sources, stored as the Source of the imported rows.
+const (
+	CostImportSourceKubecost = "kubecost"
+	CostImportSourceOpenCost = "opencost"
+)
+
+// Import formats.
+const (
+	CostImportFormatJSON = "json" // allocation API response
+	CostImportFormatCSV  = "csv"  // allocation CSV export
+)
+
+// maxCostImportRows bounds the hourly rows of one import; longer histories are imported in parts.
+const maxCostImportRows = 1_000_000
+
+// ErrInvalidCostImport is returned for an unknown source or format and for exports that cannot be
+// placed on hours and days.
+var ErrInvalidCostImport = dataerr.Validation("invalid cost import")
+
+// CostImportRequest describes an allocation export to import.
+type CostImportRequest struct {
+	Source string // kubecost (default) / opencost
+	Format string // json (default) / csv
+	DryRun bool
+}
+
+// CostImportService imports Kubecost / OpenCost allocation exports as daily namespace costs and
+// hourly workload stats.
+type CostImportService struct {
+	repo     postgres.Repository
+	calendar costmodel.AccountingCalendar
+	now      func() time.Time
+}
+
+// NewCostImportService creates a CostImportService.
+func NewCostImportService(repo postgres.Repository) *CostImportService {
+	return &CostImportService{repo: repo, now: time.Now}
+}
+
+// SetCalendar sets the accounting time zone the daily rows are labelled in (default UTC).
+func (s *CostImportService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+type importedHour struct {
+	stat                           postgres.HourlyWorkloadStat
+	memRequest, memUsage, memWaste float64
+	allocations                    int
+}
+
+type importedDay struct {
+	cost                   postgres.DailyNamespaceCost
+	pods, nodes, workloads map[string]bool
+}
+
+// Import parses the export in body and, unless req.DryRun, saves its rows in one transaction.
+//
+// Allocations spread evenly over the hours of their window (at most a day, i.e. step=1d or finer);
+// allocations with a controller also become hourly workload rows, summed per workload and hour.
+// CPU and memory cost is split into usage and waste by the usage / request averages of the
+// allocation, which also stand in for the P95 usage the export does not have. Shared cost is
+// imported as the namespace's SharedCost; GPU, volume, network and load balancer cost is reported
+// but not imported.
+func (s *CostImportService) Import(ctx context.Context, req CostImportRequest, body io.Reader) (*dto.CostImportResponse, error) {
+	if req.Source == "" {
+		req.Source = CostImportSourceKubecost
+	}
+	if req.Format == "" {
+		req.Format = CostImportFormatJSON
+	}
+	if req.Source != CostImportSourceKubecost && req.Source != CostImportSourceOpenCost {
+		return nil, fmt.Errorf("%w: unknown source %q, want %s or %s", ErrInvalidCostImport, req.Source, CostImportSourceKubecost, CostImportSourceOpenCost)
+	}
+	var allocs []kubecost.Allocation
+	var err error
+	switch req.Format {
+	case CostImportFormatJSON:
+		allocs, err = kubecost.ParseAllocationJSON(body)
+	case CostImportFormatCSV:
+		allocs, err = kubecost.ParseAllocationCSV(body)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q, want %s or %s", ErrInvalidCostImport, req.Format, CostImportFormatJSON, CostImportFormatCSV)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(allocs) == 0 {
+		return nil, fmt.Errorf("%w: export has no allocations", ErrInvalidCostImport)
+	}
+
+	resp := &dto.CostImportResponse{Source: req.Source, Format: req.Format, DryRun: req.DryRun, Allocations: len(allocs), Namespaces: []string{}}
+	hours := make(map[string]*importedHour)
+	days := make(map[string]*importedDay)
+	for _, a := range allocs {
+		if a.Unallocated() {
+			resp.Unallocated++
+			continue
+		}
+		window := a.End.Sub(a.Start)
+		if window > 24*time.Hour {
+			return nil, fmt.Errorf("%w: allocation %s spans %s; export with step=1d or finer", ErrInvalidCostImport, a.Name, window)
+		}
+		if resp.StartTime.IsZero() || a.Start.Before(resp.StartTime) {
+			resp.StartTime = a.Start
+		}
+		if a.End.After(resp.EndTime) {
+			resp.EndTime = a.End
+		}
+		resp.ImportedCost += a.CPUCost + a.RAMCost
+		resp.SharedCost += a.SharedCost
+		resp.OtherCost += a.OtherCost()
+		cpuEff := allocationEfficiency(a.CPUCoreUsageAverage, a.CPUCoreRequestAverage)
+		memEff := allocationEfficiency(a.RAMByteUsageAverage, a.RAMByteRequestAverage)
+
+		for h := a.Start.Truncate(time.Hour); h.Before(a.End); h = h.Add(time.Hour) {
+			from, to := h, h.Add(time.Hour)
+			if a.Start.After(from) {
+				from = a.Start
+			}
+			if a.End.Before(to) {
+				to = a.End
+			}
+			share := float64(to.Sub(from)) / float64(window)     // of the allocation's cost
+			inHour := float64(to.Sub(from)) / float64(time.Hour) // of the hour, for averages
+			cpuCost, memCost := a.CPUCost*share, a.RAMCost*share
+			cpuUsage, memUsage := cpuCost*cpuEff, memCost*memEff
+
+			date := s.calendar.Date(h)
+			dayKey := a.Namespace + "/" + date.Format("2006-01-02")
+			d, ok := days[dayKey]
+			if !ok {
+				d = &importedDay{
+					cost:      postgres.DailyNamespaceCost{Namespace: a.Namespace, Date: date, Source: req.Source},
+					pods:      make(map[string]bool),
+					nodes:     make(map[string]bool),
+					workloads: make(map[string]bool),
+				}
+				days[dayKey] = d
+			}
+			d.cost.BillableCost += cpuCost + memCost
+			d.cost.UsageCost += cpuUsage + memUsage
+			d.cost.WasteCost += cpuCost + memCost - cpuUsage - memUsage
+			d.cost.SharedCost += a.SharedCost * share
+			if a.Pod != "" {
+				d.pods[a.Pod] = true
+			}
+			if a.Node != "" {
+				d.nodes[a.Node] = true
+			}
+			if a.Controller != "" {
+				d.workloads[a.Controller] = true
+			}
+
+			if a.Controller == "" {
+				continue
+			}
+			hourKey := fmt.Sprintf("%s/%s/%d", a.Namespace, a.Controller, h.Unix())
+			w, ok := hours[hourKey]
+			if !ok {
+				if len(hours) >= maxCostImportRows {
+					return nil, fmt.Errorf("%w: more than %d hourly rows; import a shorter window at a time", ErrInvalidCostImport, maxCostImportRows)
+				}
+				w = &importedHour{stat: postgres.HourlyWorkloadStat{
+					Namespace:    a.Namespace,
+					WorkloadName: a.Controller,
+					WorkloadType: importedWorkloadKind(a.ControllerKind),
+					Timestamp:    h,
+					Source:       req.Source,
+				}}
+				hours[hourKey] = w
+			}
+			w.addNames(a.Pod, a.Node)
+			st := &w.stat
+			st.CPURequest += a.CPUCoreRequestAverage * inHour
+			st.CPUUsageP95 += a.CPUCoreUsageAverage * inHour
+			w.memRequest += a.RAMByteRequestAverage * inHour
+			w.memUsage += a.RAMByteUsageAverage * inHour
+			st.CPUBillableCost += cpuCost
+			st.CPUUsageCost += cpuUsage
+			st.CPUWasteCost += cpuCost - cpuUsage
+			st.MemBillableCost += memCost
+			st.MemUsageCost += memUsage
+			w.memWaste += memCost - memUsage
+			st.TotalBillableCost += cpuCost + memCost
+			st.TotalUsageCost += cpuUsage + memUsage
+			st.TotalWasteCost += cpuCost + memCost - cpuUsage - memUsage
+		}
+	}
+	if len(days) == 0 {
+		return resp, nil
+	}
+
+	native, err := s.nativeRows(ctx, resp.StartTime, resp.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	var dailyRows []postgres.DailyNamespaceCost
+	namespaces := make(map[string]bool)
+	for key, d := range days {
+		namespaces[d.cost.Namespace] = true
+		if native[key] {
+			resp.KeptRows++
+			continue
+		}
+		c := d.cost
+		c.PodCount, c.NodeCount, c.WorkloadCount = len(d.pods), len(d.nodes), len(d.workloads)
+		if c.BillableCost > 0 {
+			c.EfficiencyScore = c.UsageCost / c.BillableCost * 100
+		}
+		c.CreatedAt = now
+		dailyRows = append(dailyRows, c)
+	}
+	var hourlyRows []postgres.HourlyWorkloadStat
+	for key, w := range hours {
+		if native[key] {
+			resp.KeptRows++
+			continue
+		}
+		st := w.stat
+		st.MemRequest = int64(math.Round(w.memRequest))
+		st.MemUsageP95 = int64(math.Round(w.memUsage))
+		st.MemWasteCost = int64(math.Round(w.memWaste))
+		hourlyRows = append(hourlyRows, st)
+	}
+	sort.Slice(dailyRows, func(i, j int) bool {
+		if !dailyRows[i].Date.Equal(dailyRows[j].Date) {
+			return dailyRows[i].Date.Before(dailyRows[j].Date)
+		}
+		return dailyRows[i].Namespace < dailyRows[j].Namespace
+	})
+	sort.Slice(hourlyRows, func(i, j int) bool {
+		a, b := hourlyRows[i], hourlyRows[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.WorkloadName < b.WorkloadName
+	})
+	for ns := range namespaces {
+		resp.Namespaces = append(resp.Namespaces, ns)
+	}
+	sort.Strings(resp.Namespaces)
+	resp.DailyRows, resp.HourlyRows = len(dailyRows), len(hourlyRows)
+	if req.DryRun {
+		return resp, nil
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+	repo := tx.Repository()
+	for _, c := range dailyRows {
+		if err := repo.SaveDailyNamespaceCost(ctx, c); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("save daily cost %s %s: %w", c.Namespace, c.Date.Format("2006-01-02"), err)
+		}
+	}
+	for _, st := range hourlyRows {
+		if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("save hourly stat %s/%s %s: %w", st.Namespace, st.WorkloadName, st.Timestamp.Format(time.RFC3339), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit import: %w", err)
+	}
+	return resp, nil
+}
+
+// nativeRows returns the keys (as used by Import) of the rows Lighthouse calculated itself in the
+// window; rows of earlier imports are not included and get replaced.
+func (s *CostImportService) nativeRows(ctx context.Context, start, end time.Time) (map[string]bool, error) {
+	native := make(map[string]bool)
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: start.Truncate(time.Hour), EndTime: end})
+	if err != nil {
+		return nil, fmt.Errorf("list hourly stats: %w", err)
+	}
+	for _, st := range stats {
+		if st.Source == "" {
+			native[fmt.Sprintf("%s/%s/%d", st.Namespace, st.WorkloadName, st.Timestamp.Truncate(time.Hour).Unix())] = true
+		}
+	}
+	costs, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{StartDate: s.calendar.Date(start), EndDate: s.calendar.Date(end)})
+	if err != nil {
+		return nil, fmt.Errorf("list daily costs: %w", err)
+	}
+	for _, c := range costs {
+		if c.Source == "" {
+			native[c.Namespace+"/"+c.Date.UTC().Format("2006-01-02")] = true
+		}
+	}
+	return native, nil
+}
+
+// addNames keeps the pod and node of an hourly row while all its allocations agree on them.
+func (w *importedHour) addNames(pod, node string) {
+	if w.allocations == 0 {
+		w.stat.PodName, w.stat.NodeName = pod, node
+	}
+	if w.stat.PodName != pod {
+		w.stat.PodName = ""
+	}
+	if w.stat.NodeName != node {
+		w.stat.NodeName = ""
+	}
+	w.allocations++
+}
+
+// allocationEfficiency is the used share of the billed resource: usage / request, capped at 1. An
+// allocation without requests is billed by usage and fully used.
+func allocationEfficiency(usage, request float64) float64 {
+	if request <= 0 {
+		return 1
+	}
+	return math.Min(usage/request, 1)
+}
+
+// importedWorkloadKind maps exported controller kinds ("deployment") to Kubernetes kinds.
+func importedWorkloadKind(kind string) string {
+	switch strings.ToLower(kind) {
+	case "deployment":
+		return "Deployment"
+	case "statefulset":
+		return "StatefulSet"
+	case "daemonset":
+		return "DaemonSet"
+	case "replicaset":
+		return "ReplicaSet"
+	case "job":
+		return "Job"
+	case "cronjob":
+		return "CronJob"
+	}
+	return kind
+}
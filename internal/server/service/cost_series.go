@@ -0,0 +1,112 @@
+// Package service cost_series.go: 成本时间序列的查询计划——按时间范围选择粒度与数据表（48 小时读小时统计、
+// 30 天读日汇总、12 个月将日汇总折叠为月），响应中标明实际粒度。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// DefaultCostSeriesRange is the range of a cost series without a start time.
+const DefaultCostSeriesRange = 30 * 24 * time.Hour
+
+// Tables cost series are read from (CostSeriesResponse.Source).
+const (
+	CostSeriesSourceHourly = "cost_hourly_workload"
+	CostSeriesSourceDaily  = "cost_daily_namespace"
+)
+
+// ErrInvalidCostSeries is returned for an invalid range or resolution.
+var ErrInvalidCostSeries = dataerr.Validation("invalid cost series query")
+
+// SetCalendar sets the accounting time zone of the days and months of cost series (default UTC),
+// as for the daily ETL.
+func (s *CostService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+// CostSeries returns the cost of namespace ("" for all) over start..end. The zero end is now and the
+// zero start DefaultCostSeriesRange before end. Without a requested resolution the planner picks one
+// by the length of the range (costmodel.PlanResolution): hourly series read the hourly workload
+// stats, daily and monthly series the daily namespace rollups, so a year is a dozen points instead
+// of thousands of hours.
+func (s *CostService) CostSeries(ctx context.Context, namespace string, start, end time.Time, resolution costmodel.Resolution) (*dto.CostSeriesResponse, error) {
+	params := []string{"namespace=" + namespace, "start=" + timeKey(start), "end=" + timeKey(end), "resolution=" + string(resolution)}
+	return coalesce(ctx, s.flight, "cost_series", params, func(ctx context.Context) (*dto.CostSeriesResponse, error) {
+		return s.costSeries(ctx, namespace, start, end, resolution)
+	})
+}
+
+// costSeries computes CostSeries.
+func (s *CostService) costSeries(ctx context.Context, namespace string, start, end time.Time, resolution costmodel.Resolution) (*dto.CostSeriesResponse, error) {
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if start.IsZero() {
+		start = end.Add(-DefaultCostSeriesRange)
+	}
+	start, end = start.UTC(), end.UTC()
+	if !end.After(start) {
+		return nil, fmt.Errorf("%w: end_time must be after start_time", ErrInvalidCostSeries)
+	}
+	resp := &dto.CostSeriesResponse{Namespace: namespace, Start: start, End: end, TimeZone: s.calendar.String()}
+	if resolution == "" {
+		resolution, resp.Planned = costmodel.PlanResolution(start, end), true
+	} else if err := resolution.CheckRange(start, end); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCostSeries, err)
+	}
+	resp.Resolution = string(resolution)
+	resp.StepSeconds = int64(resolution.Step() / time.Second)
+
+	points := make(map[time.Time]*dto.GranularCostDataPoint)
+	add := func(t time.Time, billable, usage, waste float64) {
+		bucket := resolution.Bucket(t)
+		p, ok := points[bucket]
+		if !ok {
+			p = &dto.GranularCostDataPoint{Timestamp: bucket}
+			points[bucket] = p
+		}
+		p.Cost += billable
+		p.Usage += usage
+		p.Waste += waste
+	}
+	if resolution == costmodel.ResolutionHourly {
+		resp.Source = CostSeriesSourceHourly
+		stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: namespace, StartTime: start, EndTime: end})
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range stats {
+			if st.Timestamp.Before(end) {
+				add(st.Timestamp, st.TotalBillableCost, st.TotalUsageCost, st.TotalWasteCost)
+			}
+		}
+	} else {
+		resp.Source = CostSeriesSourceDaily
+		costs, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{
+			Namespace: namespace,
+			StartDate: s.calendar.Date(start),
+			EndDate:   s.calendar.Date(end.Add(-time.Nanosecond)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range costs {
+			add(c.Date, c.BillableCost, c.UsageCost, c.WasteCost)
+		}
+	}
+
+	resp.Points = make([]dto.GranularCostDataPoint, 0, len(points))
+	for _, p := range points {
+		resp.Points = append(resp.Points, *p)
+	}
+	sort.Slice(resp.Points, func(i, j int) bool { return resp.Points[i].Timestamp.Before(resp.Points[j].Timestamp) })
+	return resp, nil
+}
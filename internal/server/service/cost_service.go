@@ -3,8 +3,11 @@ package service
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
 	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
 	"github.com/myxxhui/lighthouse-src/internal/server/dto"
 	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
@@ -12,12 +15,123 @@ import (
 
 // CostService provides cost-related business logic using Mock data and costmodel.
 type CostService struct {
-	repo postgres.Repository
+	repo       postgres.Repository
+	lifecycles NamespaceLifecycleReader
+	metadata   MetadataSource
+	calendar   costmodel.AccountingCalendar
+	flight     *QueryCoalescer
+	nonFinite  costmodel.NonFinitePolicy
 }
 
-// NewCostService creates a new CostService with the given repository.
+// NamespaceLifecycleReader lists namespace lifecycle records (namespace_lifecycle).
+// *postgres.MockRepository satisfies this interface.
+type NamespaceLifecycleReader interface {
+	ListNamespaceLifecycles(ctx context.Context) ([]postgres.NamespaceLifecycle, error)
+}
+
+// MetadataSource reads the K8s metadata (app, owner, tier, environment) of namespaces and workloads.
+// *k8s.MetadataResolver satisfies this interface (and caches the reads).
+type MetadataSource interface {
+	NamespaceMetadata(ctx context.Context) (map[string]k8s.WorkloadMetadata, error)
+	WorkloadMetadata(ctx context.Context, namespace string) (map[string]k8s.WorkloadMetadata, error)
+}
+
+// NewCostService creates a new CostService with the given repository. Concurrent identical cost
+// queries (e.g. the panels of one dashboard) share one computation, see Coalescer.
 func NewCostService(repo postgres.Repository) *CostService {
-	return &CostService{repo: repo}
+	return &CostService{repo: repo, flight: NewQueryCoalescer()}
+}
+
+// Coalescer returns the coalescer of the cost queries, e.g. for its metrics.
+func (s *CostService) Coalescer() *QueryCoalescer {
+	return s.flight
+}
+
+// SetNamespaceLifecycles enables annotating terminated namespaces in cost views.
+func (s *CostService) SetNamespaceLifecycles(lifecycles NamespaceLifecycleReader) {
+	s.lifecycles = lifecycles
+}
+
+// SetNonFinitePolicy sets what happens to rows with NaN/Inf costs in the aggregated cost views
+// (Business.CostCalculation.NonFinitePolicy; default costmodel.NonFiniteZero).
+func (s *CostService) SetNonFinitePolicy(policy costmodel.NonFinitePolicy) {
+	s.nonFinite = policy
+}
+
+// aggregateOptions returns the options of the cost aggregations: NaN/Inf values are handled by
+// policy and counted into the SanitizeStats of ctx, if any, then the remaining invalid rows
+// (missing namespace, negative cost) are quarantined into report.
+func aggregateOptions(ctx context.Context, policy costmodel.NonFinitePolicy, report *costmodel.ValidationReport) []costmodel.AggregateOption {
+	return []costmodel.AggregateOption{
+		costmodel.WithNonFinitePolicy(policy, costmodel.SanitizeStatsFrom(ctx)),
+		costmodel.WithValidation(costmodel.ValidationQuarantine, report),
+	}
+}
+
+// SetMetadata enables joining K8s labels/annotations onto namespace and workload cost results.
+func (s *CostService) SetMetadata(metadata MetadataSource) {
+	s.metadata = metadata
+}
+
+// namespaceMetadata returns the metadata of every namespace. 元数据仅用于展示，K8s 不可用时返回空结果
+// 而不影响成本视图。
+func (s *CostService) namespaceMetadata(ctx context.Context) map[string]k8s.WorkloadMetadata {
+	if s.metadata == nil {
+		return nil
+	}
+	m, err := s.metadata.NamespaceMetadata(ctx)
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// workloadMetadata returns the metadata of the workloads of namespace; see namespaceMetadata.
+func (s *CostService) workloadMetadata(ctx context.Context, namespace string) map[string]k8s.WorkloadMetadata {
+	if s.metadata == nil {
+		return nil
+	}
+	m, err := s.metadata.WorkloadMetadata(ctx, namespace)
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// toResourceMetadata converts K8s metadata to its DTO, or nil if nothing was found.
+func toResourceMetadata(m k8s.WorkloadMetadata) *dto.ResourceMetadata {
+	if m.IsZero() {
+		return nil
+	}
+	return &dto.ResourceMetadata{App: m.App, Owner: m.Owner, Tier: m.Tier, Environment: m.Environment}
+}
+
+// deletedNamespaces returns namespace -> deleted_at of terminated namespaces. 生命周期仅用于标注，
+// 读取失败时返回空结果而不影响成本视图。
+func deletedNamespaces(ctx context.Context, lifecycles NamespaceLifecycleReader) map[string]time.Time {
+	if lifecycles == nil {
+		return nil
+	}
+	records, err := lifecycles.ListNamespaceLifecycles(ctx)
+	if err != nil {
+		return nil
+	}
+	deleted := make(map[string]time.Time)
+	for _, r := range records {
+		if r.Terminated() {
+			deleted[r.Namespace] = *r.DeletedAt
+		}
+	}
+	return deleted
+}
+
+// deletedAt returns a copy of the deletion time of namespace, or nil if it is active.
+func deletedAt(deleted map[string]time.Time, namespace string) *time.Time {
+	at, ok := deleted[namespace]
+	if !ok {
+		return nil
+	}
+	return &at
 }
 
 // toCostmodelDailyNamespaceCost converts postgres.DailyNamespaceCost to costmodel.DailyNamespaceCost.
@@ -28,6 +142,7 @@ func toCostmodelDailyNamespaceCost(p postgres.DailyNamespaceCost) costmodel.Dail
 		BillableCost:  p.BillableCost,
 		UsageCost:     p.UsageCost,
 		WasteCost:     p.WasteCost,
+		SharedCost:    p.SharedCost,
 		PodCount:      p.PodCount,
 		NodeCount:     p.NodeCount,
 		WorkloadCount: p.WorkloadCount,
@@ -37,6 +152,11 @@ func toCostmodelDailyNamespaceCost(p postgres.DailyNamespaceCost) costmodel.Dail
 // GetGlobalCost returns L0 aggregated cost using L1 (namespace) data from Mock.
 // L0 is computed from L1 by costmodel.AggregateGlobal; no direct Prometheus query.
 func (s *CostService) GetGlobalCost(ctx context.Context) (*dto.GlobalCostResponse, error) {
+	return coalesce(ctx, s.flight, "global_cost", nil, s.globalCost)
+}
+
+// globalCost computes GetGlobalCost.
+func (s *CostService) globalCost(ctx context.Context) (*dto.GlobalCostResponse, error) {
 	now := time.Now()
 	start := now.AddDate(0, 0, -7)
 
@@ -51,50 +171,36 @@ func (s *CostService) GetGlobalCost(ctx context.Context) (*dto.GlobalCostRespons
 		modelCosts = append(modelCosts, toCostmodelDailyNamespaceCost(c))
 	}
 
-	_, err = costmodel.AggregateGlobal(modelCosts)
+	// 无效行（缺 namespace、负成本）隔离后单独计数，不让一行脏数据拖垮整个全域视图
+	var report costmodel.ValidationReport
+	_, err = costmodel.AggregateGlobal(modelCosts, costmodel.WithNonFinitePolicy(s.nonFinite, nil), costmodel.WithValidation(costmodel.ValidationQuarantine, nil))
 	if err != nil {
 		return nil, err
 	}
 
-	breakdown, err := costmodel.CalculateDomainBreakdown(modelCosts)
+	breakdown, err := costmodel.CalculateDomainBreakdown(modelCosts, aggregateOptions(ctx, s.nonFinite, &report)...)
 	if err != nil {
 		return nil, err
 	}
 
+	deleted := deletedNamespaces(ctx, s.lifecycles)
+	metadata := s.namespaceMetadata(ctx)
 	namespaces := make([]dto.NamespaceCostSummary, 0, len(breakdown))
 	domainBreakdown := make([]dto.DomainBreakdownItem, 0, len(breakdown))
 	var sumL1, sumOptimizable float64
 	for _, b := range breakdown {
-		eff := 0.0
-		if b.BillableCost > 0 {
-			eff = (b.UsageCost / b.BillableCost) * 100
-		}
-		grade := ""
-		switch {
-		case eff < 10:
-			grade = "Zombie"
-		case eff < 40:
-			grade = "OverProvisioned"
-		case eff < 90:
-			grade = "Healthy"
-		default:
-			grade = "Risk"
-		}
-		nsCost := b.BillableCost + b.UsageCost + b.WasteCost
-		sumL1 += nsCost
+		summary := toNamespaceCostSummary(b, deleted)
+		summary.Metadata = toResourceMetadata(metadata[b.DomainName])
+		sumL1 += summary.Cost
 		sumOptimizable += b.WasteCost
-		namespaces = append(namespaces, dto.NamespaceCostSummary{
-			Name:      b.DomainName,
-			Cost:      nsCost,
-			Grade:     grade,
-			PodCount:  b.PodCount,
-			NodeCount: 0,
-		})
+		namespaces = append(namespaces, summary)
 		domainBreakdown = append(domainBreakdown, dto.DomainBreakdownItem{
 			Domain:           b.DomainName,
-			Cost:             nsCost,
+			Cost:             summary.Cost,
 			OptimizableSpace: b.WasteCost,
-			Efficiency:       eff,
+			Efficiency:       summary.Efficiency,
+			Terminated:       summary.Terminated,
+			DeletedAt:        summary.DeletedAt,
 		})
 	}
 	globalEff := 0.0
@@ -109,6 +215,7 @@ func (s *CostService) GetGlobalCost(ctx context.Context) (*dto.GlobalCostRespons
 		GlobalEfficiency: globalEff,
 		DomainBreakdown:  domainBreakdown,
 		Namespaces:       namespaces,
+		QuarantinedRows:  len(report.InvalidRows()),
 		Timestamp:        time.Now().UTC(),
 	}, nil
 }
@@ -120,17 +227,95 @@ func (s *CostService) MixedQueryTimeSeries(ctx context.Context, start, end time.
 	return nil, nil
 }
 
-// ListNamespaces returns all namespaces with cost summary for the frontend cost table.
-func (s *CostService) ListNamespaces(ctx context.Context) ([]dto.NamespaceCostSummary, error) {
-	resp, err := s.GetGlobalCost(ctx)
+// toNamespaceCostSummary converts a domain breakdown item to the namespace row of the cost table.
+func toNamespaceCostSummary(b costmodel.DomainBreakdownItem, deleted map[string]time.Time) dto.NamespaceCostSummary {
+	eff := 0.0
+	if b.BillableCost > 0 {
+		eff = (b.UsageCost / b.BillableCost) * 100
+	}
+	grade := ""
+	switch {
+	case eff < 10:
+		grade = "Zombie"
+	case eff < 40:
+		grade = "OverProvisioned"
+	case eff < 90:
+		grade = "Healthy"
+	default:
+		grade = "Risk"
+	}
+	terminatedAt := deletedAt(deleted, b.DomainName)
+	return dto.NamespaceCostSummary{
+		Name:       b.DomainName,
+		Cost:       b.BillableCost + b.UsageCost + b.WasteCost,
+		WasteCost:  b.WasteCost,
+		Efficiency: eff,
+		Grade:      grade,
+		PodCount:   b.PodCount,
+		NodeCount:  0,
+		Terminated: terminatedAt != nil,
+		DeletedAt:  terminatedAt,
+	}
+}
+
+// ListNamespaces returns one page of the namespaces of the last 7 days with cost summary for the
+// frontend cost table, ordered by page, and the number of namespaces before paging.
+func (s *CostService) ListNamespaces(ctx context.Context, page costmodel.PageOptions) ([]dto.NamespaceCostSummary, int, error) {
+	if err := page.Validate(); err != nil {
+		return nil, 0, dataerr.Validation("%v", err)
+	}
+	type result struct {
+		namespaces []dto.NamespaceCostSummary
+		total      int
+	}
+	r, err := coalesce(ctx, s.flight, "list_namespaces", pageKey(page), func(ctx context.Context) (result, error) {
+		namespaces, total, err := s.listNamespaces(ctx, page)
+		return result{namespaces, total}, err
+	})
+	return r.namespaces, r.total, err
+}
+
+// listNamespaces computes ListNamespaces.
+func (s *CostService) listNamespaces(ctx context.Context, page costmodel.PageOptions) ([]dto.NamespaceCostSummary, int, error) {
+	now := time.Now()
+	costs, err := s.repo.AggregateDailyNamespaceCosts(ctx, now.AddDate(0, 0, -7), now)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	modelCosts := make([]costmodel.DailyNamespaceCost, 0, len(costs))
+	for _, c := range costs {
+		modelCosts = append(modelCosts, toCostmodelDailyNamespaceCost(c))
+	}
+	breakdown, err := costmodel.CalculateDomainBreakdown(modelCosts, aggregateOptions(ctx, s.nonFinite, nil)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, total := costmodel.PageDomainBreakdown(breakdown, page)
+	deleted := deletedNamespaces(ctx, s.lifecycles)
+	metadata := s.namespaceMetadata(ctx)
+	namespaces := make([]dto.NamespaceCostSummary, 0, len(items))
+	for _, b := range items {
+		summary := toNamespaceCostSummary(b, deleted)
+		summary.Metadata = toResourceMetadata(metadata[b.DomainName])
+		namespaces = append(namespaces, summary)
 	}
-	return resp.Namespaces, nil
+	return namespaces, total, nil
 }
 
-// GetNamespaceCost returns L1 cost for a namespace.
-func (s *CostService) GetNamespaceCost(ctx context.Context, namespace string) (*dto.NamespaceCostResponse, error) {
+// GetNamespaceCost returns L1 cost for a namespace and one page of its workloads (L3) over the last
+// 7 days, ordered by workloads (a zero Limit returns every workload).
+func (s *CostService) GetNamespaceCost(ctx context.Context, namespace string, workloads costmodel.PageOptions) (*dto.NamespaceCostResponse, error) {
+	if err := workloads.Validate(); err != nil {
+		return nil, dataerr.Validation("%v", err)
+	}
+	params := append([]string{"namespace=" + namespace}, pageKey(workloads)...)
+	return coalesce(ctx, s.flight, "namespace_cost", params, func(ctx context.Context) (*dto.NamespaceCostResponse, error) {
+		return s.namespaceCost(ctx, namespace, workloads)
+	})
+}
+
+// namespaceCost computes GetNamespaceCost.
+func (s *CostService) namespaceCost(ctx context.Context, namespace string, workloads costmodel.PageOptions) (*dto.NamespaceCostResponse, error) {
 	now := time.Now()
 	start := now.AddDate(0, 0, -7)
 
@@ -153,6 +338,12 @@ func (s *CostService) GetNamespaceCost(ctx context.Context, namespace string) (*
 		efficiency = (totalUsage / totalBillable) * 100
 	}
 
+	page, total, err := s.namespaceWorkloads(ctx, namespace, start, now, workloads)
+	if err != nil {
+		return nil, err
+	}
+
+	terminatedAt := deletedAt(deletedNamespaces(ctx, s.lifecycles), namespace)
 	return &dto.NamespaceCostResponse{
 		Namespace: namespace,
 		Cost: dto.CostBreakdown{
@@ -162,6 +353,66 @@ func (s *CostService) GetNamespaceCost(ctx context.Context, namespace string) (*
 			Waste:      totalWaste,
 			Efficiency: efficiency,
 		},
-		Timestamp: time.Now().UTC(),
+		Workloads:     page,
+		WorkloadTotal: total,
+		Timestamp:     time.Now().UTC(),
+		Terminated:    terminatedAt != nil,
+		DeletedAt:     terminatedAt,
 	}, nil
 }
+
+// namespaceWorkloads aggregates the hourly stats of namespace in start..end by workload and returns
+// the requested page and the number of workloads.
+func (s *CostService) namespaceWorkloads(ctx context.Context, namespace string, start, end time.Time, page costmodel.PageOptions) ([]dto.WorkloadCost, int, error) {
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: namespace, StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, 0, err
+	}
+	modelStats := make([]costmodel.HourlyWorkloadStat, 0, len(stats))
+	types := make(map[string]string)
+	pods := make(map[string]map[string]bool)
+	for _, st := range stats {
+		id := st.Namespace + "/" + st.WorkloadName
+		types[id] = st.WorkloadType
+		if pods[id] == nil {
+			pods[id] = make(map[string]bool)
+		}
+		if st.PodName != "" {
+			pods[id][st.PodName] = true
+		}
+		modelStats = append(modelStats, costmodel.HourlyWorkloadStat{
+			Namespace:         st.Namespace,
+			WorkloadName:      st.WorkloadName,
+			WorkloadType:      st.WorkloadType,
+			Timestamp:         st.Timestamp,
+			TotalBillableCost: st.TotalBillableCost,
+			TotalUsageCost:    st.TotalUsageCost,
+			TotalWasteCost:    st.TotalWasteCost,
+		})
+	}
+	aggregated, err := costmodel.AggregateByWorkload(modelStats, aggregateOptions(ctx, s.nonFinite, nil)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	results, total := costmodel.PageAggregatedResults(aggregated, page)
+	var metadata map[string]k8s.WorkloadMetadata
+	if len(results) > 0 {
+		metadata = s.workloadMetadata(ctx, namespace)
+	}
+	out := make([]dto.WorkloadCost, 0, len(results))
+	for _, r := range results {
+		name := strings.TrimPrefix(r.Identifier, namespace+"/")
+		out = append(out, dto.WorkloadCost{
+			Name:       name,
+			Type:       types[r.Identifier],
+			Cost:       r.TotalBillableCost,
+			WasteCost:  r.TotalWasteCost,
+			Efficiency: r.EfficiencyScore,
+			PodCount:   len(pods[r.Identifier]),
+			Grade:      string(costmodel.DefaultGradeThresholds.Grade(r.EfficiencyScore)),
+			Namespace:  namespace,
+			Metadata:   toResourceMetadata(metadata[name]),
+		})
+	}
+	return out, total, nil
+}
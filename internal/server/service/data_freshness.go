@@ -0,0 +1,96 @@
+// Package service data_freshness.go: 数据新鲜度。全新部署时存储为空，看板只会渲染 0；首次成功同步前 /readyz
+// 报告未就绪，之后成本查询响应携带数据截止时间（X-Lighthouse-Data-As-Of）。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// DefaultFreshnessCacheTTL is how long a freshness status is reused before the repository is read again.
+const DefaultFreshnessCacheTTL = 30 * time.Second
+
+// DataFreshnessService reports whether data has been synced and how recent it is. Calculation runs
+// are optional; without them only confirmed cost snapshots count. Safe for concurrent use.
+type DataFreshnessService struct {
+	repo postgres.Repository
+	runs CalculationRunStore
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu     sync.Mutex
+	cached *dto.DataFreshness
+}
+
+// NewDataFreshnessService creates a DataFreshnessService.
+func NewDataFreshnessService(repo postgres.Repository) *DataFreshnessService {
+	return &DataFreshnessService{repo: repo, ttl: DefaultFreshnessCacheTTL, now: time.Now}
+}
+
+// SetCalculationRuns counts successful and partial calculation runs as data syncs.
+func (s *DataFreshnessService) SetCalculationRuns(runs CalculationRunStore) {
+	s.runs = runs
+}
+
+// SetCacheTTL sets how long a status is reused; <= 0 reads the repository on every call.
+func (s *DataFreshnessService) SetCacheTTL(ttl time.Duration) {
+	s.ttl = ttl
+}
+
+// Status returns the current data freshness. Errors are not cached.
+func (s *DataFreshnessService) Status(ctx context.Context) (dto.DataFreshness, error) {
+	now := s.now().UTC()
+	s.mu.Lock()
+	if s.cached != nil && s.ttl > 0 && now.Sub(s.cached.CheckedAt) < s.ttl {
+		out := *s.cached
+		s.mu.Unlock()
+		return out, nil
+	}
+	s.mu.Unlock()
+
+	out := dto.DataFreshness{CheckedAt: now}
+	if s.runs != nil {
+		run, err := latestFinishedRun(ctx, s.runs)
+		if err != nil {
+			return out, err
+		}
+		if run != nil {
+			asOf := run.WindowEnd.UTC()
+			out.Synced, out.AsOf, out.Source, out.CalculationRunID = true, &asOf, dto.FreshnessSourceCalculationRun, run.ID
+		}
+	}
+	snapshots, err := s.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{Limit: 1, ExcludeUnconfirmed: true, Fields: postgres.SnapshotFieldCounts})
+	if err != nil {
+		return out, fmt.Errorf("list cost snapshots: %w", err)
+	}
+	if len(snapshots) > 0 && (out.AsOf == nil || snapshots[0].Timestamp.After(*out.AsOf)) {
+		asOf := snapshots[0].Timestamp.UTC()
+		out.Synced, out.AsOf, out.Source = true, &asOf, dto.FreshnessSourceCostSnapshot
+	}
+
+	s.mu.Lock()
+	s.cached = &out
+	s.mu.Unlock()
+	return out, nil
+}
+
+// latestFinishedRun returns the most recently started run whose results were written, or nil.
+func latestFinishedRun(ctx context.Context, runs CalculationRunStore) (*postgres.CalculationRun, error) {
+	var latest *postgres.CalculationRun
+	for _, status := range []string{postgres.CalculationRunSucceeded, postgres.CalculationRunPartial} {
+		found, err := runs.ListCalculationRuns(ctx, postgres.CalculationRunFilter{Status: status, Limit: 1})
+		if err != nil {
+			return nil, fmt.Errorf("list calculation runs: %w", err)
+		}
+		if len(found) > 0 && (latest == nil || found[0].StartedAt.After(latest.StartedAt)) {
+			run := found[0]
+			latest = &run
+		}
+	}
+	return latest, nil
+}
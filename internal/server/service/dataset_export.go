@@ -0,0 +1,173 @@
+// Package service dataset_export.go: 成本数据集导出，可选匿名化——namespace / workload / pod / node /
+// cost center 替换为一致的假名并可整体缩放成本，用于把真实形态的数据交给供应商或做公开演示。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/pseudonym"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+const (
+	defaultDatasetWindow = 7 * 24 * time.Hour
+	maxDatasetWindow     = 31 * 24 * time.Hour
+)
+
+// ErrInvalidDatasetExport is returned for an invalid window or scale.
+var ErrInvalidDatasetExport = dataerr.Validation("invalid dataset export")
+
+// DatasetQuery selects the window of a dataset export and how it is anonymized.
+type DatasetQuery struct {
+	StartTime time.Time // default: EndTime - 7 days
+	EndTime   time.Time // default: the current hour
+	Anonymize bool
+	Scale     float64 // cost multiplier, 0 = 1
+}
+
+// DatasetExportService exports the stored daily namespace costs and hourly workload stats.
+type DatasetExportService struct {
+	repo     postgres.Repository
+	key      []byte
+	calendar costmodel.AccountingCalendar
+	now      func() time.Time
+}
+
+// NewDatasetExportService creates a DatasetExportService. key keys the pseudonyms of anonymized
+// exports: with a key the same name gets the same pseudonym in every export, so successive demo
+// datasets line up; without one each export uses a random key.
+func NewDatasetExportService(repo postgres.Repository, key []byte) *DatasetExportService {
+	return &DatasetExportService{repo: repo, key: key, now: time.Now}
+}
+
+// SetCalendar sets the accounting time zone of the daily rows (default UTC): the daily rows exported
+// are those of the accounting days the window overlaps.
+func (s *DatasetExportService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+// Resolve fills in the default window and scale of q and validates them.
+func (s *DatasetExportService) Resolve(q DatasetQuery) (DatasetQuery, error) {
+	if q.EndTime.IsZero() {
+		q.EndTime = s.now().Truncate(time.Hour)
+	}
+	if q.StartTime.IsZero() {
+		q.StartTime = q.EndTime.Add(-defaultDatasetWindow)
+	}
+	q.StartTime, q.EndTime = q.StartTime.UTC(), q.EndTime.UTC()
+	if !q.EndTime.After(q.StartTime) || q.EndTime.Sub(q.StartTime) > maxDatasetWindow {
+		return q, fmt.Errorf("%w: window must be at most %d days with start_time before end_time", ErrInvalidDatasetExport, int(maxDatasetWindow.Hours()/24))
+	}
+	if q.Scale == 0 {
+		q.Scale = 1
+	}
+	if q.Scale < 0 || math.IsInf(q.Scale, 0) || math.IsNaN(q.Scale) {
+		return q, fmt.Errorf("%w: scale must be a positive number", ErrInvalidDatasetExport)
+	}
+	return q, nil
+}
+
+// Export returns the dataset of q.StartTime..q.EndTime (at most 31 days).
+func (s *DatasetExportService) Export(ctx context.Context, q DatasetQuery) (*dto.Dataset, error) {
+	q, err := s.Resolve(q)
+	if err != nil {
+		return nil, err
+	}
+	start, end, scale := q.StartTime, q.EndTime, q.Scale
+	names := func(kind, name string) string { return name }
+	workloads := func(namespace, workload string) string { return workload }
+	pods := func(namespace, workload, pod string) string { return pod }
+	if q.Anonymize {
+		p, err := pseudonym.New(s.key)
+		if err != nil {
+			return nil, err
+		}
+		names, workloads, pods = p.Name, p.Workload, p.Pod
+	}
+
+	days, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{StartDate: s.calendar.Date(start), EndDate: s.calendar.Date(end.Add(-time.Nanosecond))})
+	if err != nil {
+		return nil, fmt.Errorf("list daily namespace costs: %w", err)
+	}
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, fmt.Errorf("list hourly workload stats: %w", err)
+	}
+
+	ds := &dto.Dataset{
+		Version:             dto.DatasetVersion,
+		ExportedAt:          s.now().UTC(),
+		StartTime:           start,
+		EndTime:             end,
+		Anonymized:          q.Anonymize,
+		Scale:               scale,
+		DailyNamespaceCosts: []dto.DatasetNamespaceDay{},
+		HourlyWorkloadStats: []dto.DatasetWorkloadHour{},
+	}
+	for _, d := range days {
+		ds.DailyNamespaceCosts = append(ds.DailyNamespaceCosts, dto.DatasetNamespaceDay{
+			Namespace:       names(pseudonym.KindNamespace, d.Namespace),
+			Date:            d.Date.UTC(),
+			BillableCost:    d.BillableCost * scale,
+			UsageCost:       d.UsageCost * scale,
+			WasteCost:       d.WasteCost * scale,
+			SharedCost:      d.SharedCost * scale,
+			PodCount:        d.PodCount,
+			NodeCount:       d.NodeCount,
+			WorkloadCount:   d.WorkloadCount,
+			EfficiencyScore: d.EfficiencyScore,
+		})
+	}
+	for _, st := range stats {
+		if !st.Timestamp.Before(end) {
+			continue
+		}
+		ds.HourlyWorkloadStats = append(ds.HourlyWorkloadStats, dto.DatasetWorkloadHour{
+			Namespace:         names(pseudonym.KindNamespace, st.Namespace),
+			WorkloadName:      workloads(st.Namespace, st.WorkloadName),
+			WorkloadType:      st.WorkloadType,
+			NodeName:          names(pseudonym.KindNode, st.NodeName),
+			PodName:           pods(st.Namespace, st.WorkloadName, st.PodName),
+			CostCenter:        names(pseudonym.KindCostCenter, st.CostCenter),
+			Timestamp:         st.Timestamp.UTC(),
+			CPURequest:        st.CPURequest,
+			CPUUsageP95:       st.CPUUsageP95,
+			MemRequest:        st.MemRequest,
+			MemUsageP95:       st.MemUsageP95,
+			CPUBillableCost:   st.CPUBillableCost * scale,
+			MemBillableCost:   st.MemBillableCost * scale,
+			TotalBillableCost: st.TotalBillableCost * scale,
+			TotalUsageCost:    st.TotalUsageCost * scale,
+			TotalWasteCost:    st.TotalWasteCost * scale,
+		})
+	}
+	// 按假名排序，避免行序泄露原始名称的字母序
+	sort.SliceStable(ds.DailyNamespaceCosts, func(i, j int) bool {
+		a, b := ds.DailyNamespaceCosts[i], ds.DailyNamespaceCosts[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Namespace < b.Namespace
+	})
+	sort.SliceStable(ds.HourlyWorkloadStats, func(i, j int) bool {
+		a, b := ds.HourlyWorkloadStats[i], ds.HourlyWorkloadStats[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.WorkloadName != b.WorkloadName {
+			return a.WorkloadName < b.WorkloadName
+		}
+		return a.PodName < b.PodName
+	})
+	return ds, nil
+}
@@ -0,0 +1,253 @@
+// Package service dependency_service.go: 服务依赖图——接收链路追踪上报与静态配置的依赖边，沿反向边回答
+// “哪些上游服务、多少支出依赖这个降级的工作负载”，供 SLO 违约记录与工作负载成本视图使用。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// DefaultDependencyTracingTTL is how long a tracing edge stays in the graph after it was last seen.
+const DefaultDependencyTracingTTL = 24 * time.Hour
+
+// ErrInvalidDependencies is returned for an unknown source or an incomplete or self-referencing edge.
+var ErrInvalidDependencies = dataerr.Validation("invalid dependency edges")
+
+// DependencyStore persists the service dependency graph. *postgres.MockRepository satisfies this
+// interface.
+type DependencyStore interface {
+	SaveDependencyEdges(ctx context.Context, edges []postgres.DependencyEdge) error
+	ListDependencyEdges(ctx context.Context, filter postgres.DependencyEdgeFilter) ([]postgres.DependencyEdge, error)
+	DeleteDependencyEdges(ctx context.Context, filter postgres.DependencyEdgeFilter) (int, error)
+}
+
+// DependencyService ingests service dependency edges and analyses the impact of a degraded
+// workload on the services depending on it.
+type DependencyService struct {
+	store      DependencyStore
+	repo       postgres.Repository
+	tracingTTL time.Duration
+	now        func() time.Time
+}
+
+// NewDependencyService creates a DependencyService; costs of the impact analysis come from the
+// hourly workload stats of repo. Tracing edges not seen for tracingTTL (<= 0:
+// DefaultDependencyTracingTTL) leave the graph.
+func NewDependencyService(store DependencyStore, repo postgres.Repository, tracingTTL time.Duration) *DependencyService {
+	if tracingTTL <= 0 {
+		tracingTTL = DefaultDependencyTracingTTL
+	}
+	return &DependencyService{store: store, repo: repo, tracingTTL: tracingTTL, now: time.Now}
+}
+
+// Ingest saves the reported edges. A static map replaces the previous static edges; a tracing
+// report refreshes the edges it contains and drops the tracing edges that expired.
+func (s *DependencyService) Ingest(ctx context.Context, req dto.DependencyIngestRequest) (*dto.DependencyIngestResponse, error) {
+	if req.Source != postgres.DependencySourceTracing && req.Source != postgres.DependencySourceStatic {
+		return nil, fmt.Errorf("%w: source must be tracing or static", ErrInvalidDependencies)
+	}
+	observedAt := req.ObservedAt.UTC()
+	if req.ObservedAt.IsZero() {
+		observedAt = s.now().UTC()
+	}
+	edges := make([]postgres.DependencyEdge, 0, len(req.Edges))
+	for i, in := range req.Edges {
+		e := postgres.DependencyEdge{
+			CallerNamespace: in.CallerNamespace,
+			Caller:          in.Caller,
+			CalleeNamespace: in.CalleeNamespace,
+			Callee:          in.Callee,
+			Source:          req.Source,
+			CallsPerMinute:  in.CallsPerMinute,
+			LastSeen:        observedAt,
+		}
+		if e.CallerNamespace == "" {
+			e.CallerNamespace = e.CalleeNamespace
+		}
+		if e.CalleeNamespace == "" {
+			e.CalleeNamespace = e.CallerNamespace
+		}
+		switch {
+		case e.CallerNamespace == "" || e.Caller == "" || e.Callee == "":
+			return nil, fmt.Errorf("%w: edge %d needs a namespace, caller and callee", ErrInvalidDependencies, i)
+		case e.CallerNamespace == e.CalleeNamespace && e.Caller == e.Callee:
+			return nil, fmt.Errorf("%w: edge %d calls itself", ErrInvalidDependencies, i)
+		case e.CallsPerMinute < 0:
+			return nil, fmt.Errorf("%w: edge %d has negative calls_per_minute", ErrInvalidDependencies, i)
+		}
+		edges = append(edges, e)
+	}
+	if err := s.store.SaveDependencyEdges(ctx, edges); err != nil {
+		return nil, fmt.Errorf("save dependency edges: %w", err)
+	}
+	stale := postgres.DependencyEdgeFilter{Source: req.Source, SeenBefore: observedAt}
+	if req.Source == postgres.DependencySourceTracing {
+		stale.SeenBefore = s.now().Add(-s.tracingTTL)
+	}
+	removed, err := s.store.DeleteDependencyEdges(ctx, stale)
+	if err != nil {
+		return nil, fmt.Errorf("delete stale dependency edges: %w", err)
+	}
+	return &dto.DependencyIngestResponse{Source: req.Source, Accepted: len(edges), Removed: removed}, nil
+}
+
+// Edges returns the live edges of the graph: the static map and the tracing edges seen within the
+// TTL, by caller and callee.
+func (s *DependencyService) Edges(ctx context.Context) ([]dto.DependencyEdge, error) {
+	edges, err := s.liveEdges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.DependencyEdge, 0, len(edges))
+	for _, e := range edges {
+		out = append(out, dto.DependencyEdge{
+			CallerNamespace: e.CallerNamespace,
+			Caller:          e.Caller,
+			CalleeNamespace: e.CalleeNamespace,
+			Callee:          e.Callee,
+			Source:          e.Source,
+			CallsPerMinute:  e.CallsPerMinute,
+			LastSeen:        e.LastSeen,
+		})
+	}
+	return out, nil
+}
+
+func (s *DependencyService) liveEdges(ctx context.Context) ([]postgres.DependencyEdge, error) {
+	edges, err := s.store.ListDependencyEdges(ctx, postgres.DependencyEdgeFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list dependency edges: %w", err)
+	}
+	cutoff := s.now().Add(-s.tracingTTL)
+	live := edges[:0]
+	for _, e := range edges {
+		if e.Source == postgres.DependencySourceTracing && e.LastSeen.Before(cutoff) {
+			continue
+		}
+		live = append(live, e)
+	}
+	return live, nil
+}
+
+// serviceRef identifies a service of the dependency graph.
+type serviceRef struct{ namespace, service string }
+
+func (r serviceRef) String() string { return r.namespace + "/" + r.service }
+
+// Impact returns the services depending on the workload namespace/service — its callers, their
+// callers and so on, nearest first — with the monthly cost of each extrapolated from the hourly
+// stats over start..end (default the last 7 days). Services are matched to the workloads named
+// like them, as for SLO costs.
+func (s *DependencyService) Impact(ctx context.Context, namespace, service string, start, end time.Time) (*dto.DependencyImpact, error) {
+	if end.IsZero() {
+		end = s.now()
+	}
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -7)
+	}
+	if !end.After(start) {
+		return nil, ErrInvalidWindow
+	}
+	edges, err := s.liveEdges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	target := serviceRef{namespace, service}
+	callers := make(map[serviceRef][]serviceRef)
+	downstream := make(map[string]bool)
+	for _, e := range edges {
+		caller, callee := serviceRef{e.CallerNamespace, e.Caller}, serviceRef{e.CalleeNamespace, e.Callee}
+		callers[callee] = append(callers[callee], caller)
+		if caller == target {
+			downstream[callee.String()] = true
+		}
+	}
+
+	// Breadth-first over the reverse edges, so every upstream service gets its shortest path.
+	resp := &dto.DependencyImpact{
+		Namespace: namespace, Service: service, StartTime: start, EndTime: end,
+		Upstream: []dto.UpstreamService{}, Downstream: []string{}, Unmatched: []string{},
+	}
+	seen := map[serviceRef]bool{target: true}
+	queue := []serviceRef{target}
+	for depth := 1; len(queue) > 0; depth++ {
+		var next []serviceRef
+		for _, callee := range queue {
+			for _, caller := range callers[callee] {
+				if seen[caller] {
+					continue
+				}
+				seen[caller] = true
+				next = append(next, caller)
+				resp.Upstream = append(resp.Upstream, dto.UpstreamService{Namespace: caller.namespace, Service: caller.service, Depth: depth, Via: callee.String()})
+			}
+		}
+		queue = next
+	}
+	for d := range downstream {
+		resp.Downstream = append(resp.Downstream, d)
+	}
+	sort.Strings(resp.Downstream)
+
+	monthly, err := s.monthlyCosts(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	resp.MonthlyCost = monthly[target]
+	for i := range resp.Upstream {
+		u := &resp.Upstream[i]
+		cost, ok := monthly[serviceRef{u.Namespace, u.Service}]
+		if !ok {
+			resp.Unmatched = append(resp.Unmatched, u.Namespace+"/"+u.Service)
+		}
+		u.MonthlyCost = cost
+		resp.UpstreamMonthlyCost += cost
+	}
+	resp.UpstreamMonthlyCost = roundCost(resp.UpstreamMonthlyCost)
+	sort.SliceStable(resp.Upstream, func(i, j int) bool {
+		a, b := resp.Upstream[i], resp.Upstream[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		if a.MonthlyCost != b.MonthlyCost {
+			return a.MonthlyCost > b.MonthlyCost
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Service < b.Service
+	})
+	sort.Strings(resp.Unmatched)
+	return resp, nil
+}
+
+// monthlyCosts returns the monthly cost of every workload with stats in start..end: its billable
+// cost per observed hour times costmodel.HoursPerMonth.
+func (s *DependencyService) monthlyCosts(ctx context.Context, start, end time.Time) (map[serviceRef]float64, error) {
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, fmt.Errorf("list hourly workload stats: %w", err)
+	}
+	billable := make(map[serviceRef]float64)
+	hours := make(map[serviceRef]map[time.Time]bool)
+	for _, st := range stats {
+		k := serviceRef{st.Namespace, st.WorkloadName}
+		if hours[k] == nil {
+			hours[k] = make(map[time.Time]bool)
+		}
+		hours[k][st.Timestamp.UTC().Truncate(time.Hour)] = true
+		billable[k] += st.TotalBillableCost
+	}
+	monthly := make(map[serviceRef]float64, len(billable))
+	for k, cost := range billable {
+		monthly[k] = roundCost(cost / float64(len(hours[k])) * costmodel.HoursPerMonth)
+	}
+	return monthly, nil
+}
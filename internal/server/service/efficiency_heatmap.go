@@ -0,0 +1,142 @@
+// Package service efficiency_heatmap.go: namespace × 日期的效率矩阵，服务端按桶聚合 cost_daily_namespace，
+// 驾驶舱日历热力图只拿到矩阵而不是原始行。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+const (
+	defaultHeatmapDays = 30
+	maxHeatmapDays     = 366
+)
+
+// ErrInvalidHeatmapQuery is returned for out-of-range days, bucket_days or limit.
+var ErrInvalidHeatmapQuery = dataerr.Validation("invalid efficiency heatmap query")
+
+// EfficiencyHeatmapQuery parameterizes a heatmap. Zero values use the defaults: the last 30 days,
+// one-day buckets and all namespaces.
+type EfficiencyHeatmapQuery struct {
+	Days       int
+	BucketDays int
+	// Limit keeps the namespaces with the highest billable cost in the window.
+	Limit int
+}
+
+// Heatmap returns the efficiency of every namespace in each bucket of the last q.Days days
+// (today included). A cell is usage / billable cost summed over the bucket, so a bucket is not
+// skewed by cheap days; buckets are aligned to the window start, the last one may be shorter.
+func (s *EfficiencyTargetService) Heatmap(ctx context.Context, q EfficiencyHeatmapQuery) (*dto.EfficiencyHeatmapResponse, error) {
+	if q.Days == 0 {
+		q.Days = defaultHeatmapDays
+	}
+	if q.BucketDays == 0 {
+		q.BucketDays = 1
+	}
+	if q.Days < 0 || q.Days > maxHeatmapDays {
+		return nil, fmt.Errorf("%w: days must be in 1..%d", ErrInvalidHeatmapQuery, maxHeatmapDays)
+	}
+	if q.BucketDays < 0 || q.BucketDays > q.Days {
+		return nil, fmt.Errorf("%w: bucket_days must be in 1..%d", ErrInvalidHeatmapQuery, q.Days)
+	}
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("%w: limit must not be negative", ErrInvalidHeatmapQuery)
+	}
+
+	end := s.calendar.Date(s.now())
+	start := end.AddDate(0, 0, -(q.Days - 1))
+	costs, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{StartDate: start, EndDate: end})
+	if err != nil {
+		return nil, fmt.Errorf("list daily namespace costs: %w", err)
+	}
+
+	buckets := (q.Days + q.BucketDays - 1) / q.BucketDays
+	type cell struct{ billable, usage float64 }
+	type row struct {
+		cells    []cell
+		seen     []bool
+		billable float64
+		usage    float64
+	}
+	rows := make(map[string]*row)
+	for _, c := range costs {
+		day := c.Date.UTC().Truncate(24 * time.Hour)
+		if day.Before(start) || day.After(end) {
+			continue
+		}
+		r, ok := rows[c.Namespace]
+		if !ok {
+			r = &row{cells: make([]cell, buckets), seen: make([]bool, buckets)}
+			rows[c.Namespace] = r
+		}
+		i := int(day.Sub(start)/(24*time.Hour)) / q.BucketDays
+		r.cells[i].billable += c.BillableCost
+		r.cells[i].usage += c.UsageCost
+		r.seen[i] = true
+		r.billable += c.BillableCost
+		r.usage += c.UsageCost
+	}
+
+	namespaces := make([]string, 0, len(rows))
+	for ns := range rows {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		bi, bj := rows[namespaces[i]].billable, rows[namespaces[j]].billable
+		if bi != bj {
+			return bi > bj
+		}
+		return namespaces[i] < namespaces[j]
+	})
+	if q.Limit > 0 && len(namespaces) > q.Limit {
+		namespaces = namespaces[:q.Limit]
+	}
+
+	targets, err := s.ListTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targetOf := make(map[string]float64, len(targets.Items))
+	for _, t := range targets.Items {
+		targetOf[t.Namespace] = t.Target
+	}
+
+	resp := &dto.EfficiencyHeatmapResponse{
+		WindowStart: start,
+		WindowEnd:   end,
+		BucketDays:  q.BucketDays,
+		Buckets:     make([]time.Time, buckets),
+		Rows:        make([]dto.EfficiencyHeatmapRow, 0, len(namespaces)),
+	}
+	for i := range resp.Buckets {
+		resp.Buckets[i] = start.AddDate(0, 0, i*q.BucketDays)
+	}
+	for _, ns := range namespaces {
+		r := rows[ns]
+		out := dto.EfficiencyHeatmapRow{
+			Namespace:  ns,
+			Efficiency: costmodel.DailyEfficiency(r.billable, r.usage),
+			Cells:      make([]*float64, buckets),
+		}
+		for i, c := range r.cells {
+			if r.seen[i] {
+				e := costmodel.DailyEfficiency(c.billable, c.usage)
+				out.Cells[i] = &e
+			}
+		}
+		if target, ok := targetOf[ns]; ok {
+			out.Target = &target
+		}
+		resp.Rows = append(resp.Rows, out)
+	}
+	resp.Total = len(resp.Rows)
+	return resp, nil
+}
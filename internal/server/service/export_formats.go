@@ -0,0 +1,186 @@
+// Package service export_formats.go: 导出任务的文件格式——CSV 与 PDF 表格报表；Parquet 等需要外部编码库的格式
+// 由部署方实现 ExportFormat 后经 ExportJobService.RegisterFormat 注册。
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportTable is the tabular result an export job renders.
+type ExportTable struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+}
+
+// ExportFormat renders an export table as a file.
+type ExportFormat interface {
+	// Extension is the file name extension without the dot, e.g. "csv".
+	Extension() string
+	ContentType() string
+	// Write renders t to w, calling progress with the number of rows written so far.
+	Write(w io.Writer, t *ExportTable, progress func(rows int)) error
+}
+
+// CSVFormat writes a header line with the column names and one line per row.
+type CSVFormat struct{}
+
+func (CSVFormat) Extension() string   { return "csv" }
+func (CSVFormat) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (CSVFormat) Write(w io.Writer, t *ExportTable, progress func(rows int)) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	for i, row := range t.Rows {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		progress(i + 1)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// PDF page layout: A4 landscape in points, Courier (every glyph is 0.6 em wide).
+const (
+	pdfPageWidth   = 842
+	pdfPageHeight  = 595
+	pdfMargin      = 36
+	pdfMaxFontSize = 8.0
+	pdfMinFontSize = 4.0
+	pdfMaxColumn   = 28 // characters; longer cells are cut
+)
+
+// PDFFormat writes the table as a paginated report: the title and the column header repeat on every
+// page, and the font shrinks (down to 4 pt) so that all columns fit the page width. Only printable
+// ASCII is rendered; other characters are replaced with '?'.
+type PDFFormat struct{}
+
+func (PDFFormat) Extension() string   { return "pdf" }
+func (PDFFormat) ContentType() string { return "application/pdf" }
+
+func (PDFFormat) Write(w io.Writer, t *ExportTable, progress func(rows int)) error {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = min(len(c), pdfMaxColumn)
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], min(len(cell), pdfMaxColumn))
+			}
+		}
+	}
+	line := func(cells []string) string {
+		var b strings.Builder
+		for i, wd := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			if len(cell) > wd {
+				cell = cell[:wd-1] + "~"
+			}
+			fmt.Fprintf(&b, "%-*s  ", wd, cell)
+		}
+		return strings.TrimRight(b.String(), " ")
+	}
+	header := line(t.Columns)
+	fontSize := pdfMaxFontSize
+	if chars := float64(len(header)); chars*0.6*fontSize > pdfPageWidth-2*pdfMargin {
+		fontSize = max(pdfMinFontSize, (pdfPageWidth-2*pdfMargin)/(chars*0.6))
+	}
+	leading := fontSize * 1.25
+	perPage := int((pdfPageHeight-2*pdfMargin)/leading) - 4 // title, blank, header, rule
+	pages := max(1, (len(t.Rows)+perPage-1)/perPage)
+
+	pw := &pdfWriter{w: w}
+	pw.object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, pages)
+	for p := range kids {
+		kids[p] = fmt.Sprintf("%d 0 R", 4+2*p)
+	}
+	pw.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages))
+	pw.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	rule := strings.Repeat("-", len(header))
+	for p := 0; p < pages; p++ {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %.2f Tf\n%.2f TL\n%d %.2f Td\n", fontSize, leading, pdfMargin, pdfPageHeight-pdfMargin-fontSize)
+		text := func(s string) { fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(s)) }
+		text(fmt.Sprintf("%s    page %d/%d", t.Title, p+1, pages))
+		text("")
+		text(header)
+		text(rule)
+		end := min(len(t.Rows), (p+1)*perPage)
+		for i := p * perPage; i < end; i++ {
+			text(line(t.Rows[i]))
+			progress(i + 1)
+		}
+		if len(t.Rows) == 0 {
+			text("(no rows)")
+		}
+		content.WriteString("ET")
+		pw.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*p))
+		pw.object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+	return pw.close()
+}
+
+// pdfWriter writes numbered objects (1, 2, ...) and the cross-reference table of their offsets.
+type pdfWriter struct {
+	w       io.Writer
+	n       int64
+	offsets []int64
+	err     error
+}
+
+func (pw *pdfWriter) write(s string) {
+	if pw.err != nil {
+		return
+	}
+	n, err := io.WriteString(pw.w, s)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+func (pw *pdfWriter) object(body string) {
+	if pw.n == 0 {
+		pw.write("%PDF-1.4\n")
+	}
+	pw.offsets = append(pw.offsets, pw.n)
+	pw.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", len(pw.offsets), body))
+}
+
+func (pw *pdfWriter) close() error {
+	xref := pw.n
+	pw.write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1))
+	for _, off := range pw.offsets {
+		pw.write(fmt.Sprintf("%010d 00000 n \n", off))
+	}
+	pw.write(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, xref))
+	return pw.err
+}
+
+// pdfEscape escapes a PDF string literal, replacing characters outside printable ASCII with '?'.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
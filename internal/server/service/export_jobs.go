@@ -0,0 +1,406 @@
+// Package service export_jobs.go: 异步导出任务——POST 创建任务后立即返回，后台 worker 生成文件并上报进度，
+// 文件在保留期内可下载；避免大窗口导出超出同步请求的超时。
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// Export job statuses.
+const (
+	ExportJobQueued    = "queued"
+	ExportJobRunning   = "running"
+	ExportJobSucceeded = "succeeded"
+	ExportJobFailed    = "failed"
+	ExportJobExpired   = "expired" // 保留期已过，文件已删除
+)
+
+// Export job kinds: the table of the cost dataset that is exported.
+const (
+	ExportKindNamespaceCosts = "namespace_costs"
+	ExportKindWorkloadStats  = "workload_stats"
+)
+
+const (
+	// DefaultExportRetention is how long the file of a succeeded job stays downloadable.
+	DefaultExportRetention = 24 * time.Hour
+	defaultExportWorkers   = 2
+	exportQueueSize        = 64
+	exportCleanupInterval  = time.Minute
+)
+
+var (
+	// ErrInvalidExportJob is returned for an unknown kind or format.
+	ErrInvalidExportJob = dataerr.Validation("invalid export job")
+	// ErrExportNotReady is returned when downloading a job that has not succeeded.
+	ErrExportNotReady = dataerr.Conflict("export job has no file to download")
+	// ErrExportExpired is returned when downloading a job whose retention has passed.
+	ErrExportExpired = dataerr.NotFound("export file has expired")
+	// ErrExportQueueFull is returned when too many jobs are waiting for a worker.
+	ErrExportQueueFull = dataerr.Unavailable("export queue is full, retry later")
+)
+
+// exportJob is a job with what its worker needs; the embedded DTO is guarded by ExportJobService.mu.
+type exportJob struct {
+	dto.ExportJob
+	query  DatasetQuery
+	format ExportFormat
+	path   string
+}
+
+// ExportJobService runs exports in the background. Jobs live in memory (a restart forgets them,
+// their files are removed with the next restart's cleanup of the export directory); files are
+// written to the export directory and deleted once their retention has passed.
+type ExportJobService struct {
+	datasets  *DatasetExportService
+	dir       string
+	retention time.Duration
+	formats   map[string]ExportFormat
+	queue     chan string
+
+	mu   sync.Mutex
+	jobs map[string]*exportJob
+	now  func() time.Time
+}
+
+// NewExportJobService creates an ExportJobService exporting the tables of datasets to files in dir
+// ("" = <tmp>/lighthouse-exports) kept for retention (0 = DefaultExportRetention). CSV and PDF are
+// registered; other formats such as Parquet are added with RegisterFormat.
+func NewExportJobService(datasets *DatasetExportService, dir string, retention time.Duration) *ExportJobService {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "lighthouse-exports")
+	}
+	if retention <= 0 {
+		retention = DefaultExportRetention
+	}
+	return &ExportJobService{
+		datasets:  datasets,
+		dir:       dir,
+		retention: retention,
+		formats:   map[string]ExportFormat{"csv": CSVFormat{}, "pdf": PDFFormat{}},
+		queue:     make(chan string, exportQueueSize),
+		jobs:      make(map[string]*exportJob),
+		now:       time.Now,
+	}
+}
+
+// RegisterFormat adds (or replaces) the format accepted as name in export requests.
+func (s *ExportJobService) RegisterFormat(name string, format ExportFormat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.formats[strings.ToLower(name)] = format
+}
+
+// Create validates req and queues the job; the returned job is queued until a worker of Run picks it up.
+func (s *ExportJobService) Create(ctx context.Context, req dto.CreateExportJobRequest, createdBy string) (*dto.ExportJob, error) {
+	if req.Kind != ExportKindNamespaceCosts && req.Kind != ExportKindWorkloadStats {
+		return nil, fmt.Errorf("%w: kind must be %s or %s", ErrInvalidExportJob, ExportKindNamespaceCosts, ExportKindWorkloadStats)
+	}
+	name := strings.ToLower(req.Format)
+	s.mu.Lock()
+	format, ok := s.formats[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported format %q (%s)", ErrInvalidExportJob, req.Format, strings.Join(s.formatNames(), ", "))
+	}
+	q, err := s.datasets.Resolve(DatasetQuery{StartTime: req.StartTime, EndTime: req.EndTime, Anonymize: req.Anonymize, Scale: req.Scale})
+	if err != nil {
+		return nil, err
+	}
+
+	job := &exportJob{
+		ExportJob: dto.ExportJob{
+			ID:         uuid.New().String(),
+			Kind:       req.Kind,
+			Format:     name,
+			StartTime:  q.StartTime,
+			EndTime:    q.EndTime,
+			Anonymized: q.Anonymize,
+			Status:     ExportJobQueued,
+			CreatedBy:  createdBy,
+			CreatedAt:  s.now().UTC(),
+		},
+		query:  q,
+		format: format,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case s.queue <- job.ID:
+	default:
+		return nil, ErrExportQueueFull
+	}
+	s.jobs[job.ID] = job
+	out := job.ExportJob
+	return &out, nil
+}
+
+// Get returns a job with its progress.
+func (s *ExportJobService) Get(ctx context.Context, id string) (*dto.ExportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, dataerr.NotFound("export job not found: %s", id)
+	}
+	out := job.ExportJob
+	return &out, nil
+}
+
+// List returns the jobs, newest first, and the accepted formats.
+func (s *ExportJobService) List(ctx context.Context) *dto.ExportJobListResponse {
+	s.mu.Lock()
+	resp := &dto.ExportJobListResponse{Jobs: make([]dto.ExportJob, 0, len(s.jobs))}
+	for _, job := range s.jobs {
+		resp.Jobs = append(resp.Jobs, job.ExportJob)
+	}
+	s.mu.Unlock()
+	sort.Slice(resp.Jobs, func(i, j int) bool {
+		if !resp.Jobs[i].CreatedAt.Equal(resp.Jobs[j].CreatedAt) {
+			return resp.Jobs[i].CreatedAt.After(resp.Jobs[j].CreatedAt)
+		}
+		return resp.Jobs[i].ID < resp.Jobs[j].ID
+	})
+	resp.Total = len(resp.Jobs)
+	resp.Formats = s.formatNames()
+	return resp
+}
+
+// Open returns the file of a succeeded job, the job and the content type of its format.
+// The caller closes the file.
+func (s *ExportJobService) Open(ctx context.Context, id string) (*os.File, *dto.ExportJob, string, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, nil, "", dataerr.NotFound("export job not found: %s", id)
+	}
+	out, path, contentType := job.ExportJob, job.path, job.format.ContentType()
+	s.mu.Unlock()
+	switch out.Status {
+	case ExportJobSucceeded:
+	case ExportJobExpired:
+		return nil, &out, "", fmt.Errorf("%w: job %s expired at %s", ErrExportExpired, id, out.ExpiresAt.Format(time.RFC3339))
+	default:
+		return nil, &out, "", fmt.Errorf("%w: job %s is %s", ErrExportNotReady, id, out.Status)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &out, "", fmt.Errorf("open export file: %w", err)
+	}
+	return f, &out, contentType, nil
+}
+
+// Run removes leftover files of a previous process, then processes queued jobs with workers
+// goroutines (0 = 2) and expires files past their retention until ctx is done. Jobs and files are
+// local to the replica, so Run is not a leader-elected scheduler job.
+func (s *ExportJobService) Run(ctx context.Context, workers int, onError func(error)) {
+	if workers <= 0 {
+		workers = defaultExportWorkers
+	}
+	if err := s.removeStaleFiles(); err != nil && onError != nil {
+		onError(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-s.queue:
+					s.process(ctx, id)
+				}
+			}
+		}()
+	}
+	ticker := time.NewTicker(exportCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			s.Cleanup()
+		}
+	}
+}
+
+// Cleanup deletes the files of succeeded jobs past their retention (the jobs become expired) and
+// forgets finished jobs one more retention period later.
+func (s *ExportJobService) Cleanup() {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, job := range s.jobs {
+		if job.ExpiresAt == nil || now.Before(*job.ExpiresAt) {
+			continue
+		}
+		if job.Status == ExportJobSucceeded {
+			os.Remove(job.path)
+			job.Status = ExportJobExpired
+			job.DownloadURL = ""
+		}
+		if !now.Before(job.ExpiresAt.Add(s.retention)) {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// process produces the file of job id; failures are recorded on the job.
+func (s *ExportJobService) process(ctx context.Context, id string) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	started := s.now().UTC()
+	job.Status, job.StartedAt = ExportJobRunning, &started
+	job.path = filepath.Join(s.dir, "export-"+id+"."+job.format.Extension())
+	kind, q, format, path := job.Kind, job.query, job.format, job.path
+	s.mu.Unlock()
+
+	size, err := s.produce(ctx, id, kind, q, format, path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finished := s.now().UTC()
+	expires := finished.Add(s.retention)
+	job.FinishedAt, job.ExpiresAt = &finished, &expires
+	if err != nil {
+		os.Remove(path)
+		job.Status, job.Error = ExportJobFailed, err.Error()
+		return
+	}
+	job.Status, job.Progress, job.SizeBytes = ExportJobSucceeded, 100, size
+	job.FileName = fmt.Sprintf("%s_%s_%s.%s", kind, q.StartTime.Format("20060102T1504"), q.EndTime.Format("20060102T1504"), format.Extension())
+	job.DownloadURL = "/api/v1/exports/" + id + "/download"
+}
+
+// produce writes the table of the job to path and returns the file size.
+func (s *ExportJobService) produce(ctx context.Context, id, kind string, q DatasetQuery, format ExportFormat, path string) (int64, error) {
+	ds, err := s.datasets.Export(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	table := datasetTable(kind, ds)
+	s.mu.Lock()
+	s.jobs[id].TotalRows = len(table.Rows)
+	s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return 0, fmt.Errorf("create export directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return 0, fmt.Errorf("create export file: %w", err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	progress := func(rows int) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		job := s.jobs[id]
+		job.RowsWritten = rows
+		if job.TotalRows > 0 {
+			job.Progress = min(99, rows*100/job.TotalRows) // 100 once the file is complete
+		}
+	}
+	if err := format.Write(w, table, progress); err != nil {
+		return 0, fmt.Errorf("write %s: %w", format.Extension(), err)
+	}
+	if err := w.Flush(); err != nil {
+		return 0, fmt.Errorf("write export file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), f.Close()
+}
+
+// removeStaleFiles deletes export files left by a previous process, whose jobs are forgotten.
+func (s *ExportJobService) removeStaleFiles() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "export-*"))
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := make(map[string]bool, len(s.jobs))
+	for _, job := range s.jobs {
+		current[job.path] = true
+	}
+	for _, p := range paths {
+		if !current[p] {
+			if err := os.Remove(p); err != nil {
+				return fmt.Errorf("remove stale export file: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ExportJobService) formatNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.formats))
+	for name := range s.formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// datasetTable returns the kind table of ds; costs are rounded to 4 decimals.
+func datasetTable(kind string, ds *dto.Dataset) *ExportTable {
+	num := func(v float64) string { return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64) }
+	window := ds.StartTime.Format(time.RFC3339) + " - " + ds.EndTime.Format(time.RFC3339)
+	if kind == ExportKindWorkloadStats {
+		t := &ExportTable{
+			Title: "Hourly workload stats " + window,
+			Columns: []string{"timestamp", "namespace", "workload_name", "workload_type", "pod_name", "node_name", "cost_center",
+				"cpu_request", "cpu_usage_p95", "mem_request", "mem_usage_p95",
+				"cpu_billable_cost", "mem_billable_cost", "total_billable_cost", "total_usage_cost", "total_waste_cost"},
+			Rows: make([][]string, 0, len(ds.HourlyWorkloadStats)),
+		}
+		for _, st := range ds.HourlyWorkloadStats {
+			t.Rows = append(t.Rows, []string{
+				st.Timestamp.Format(time.RFC3339), st.Namespace, st.WorkloadName, st.WorkloadType, st.PodName, st.NodeName, st.CostCenter,
+				num(st.CPURequest), num(st.CPUUsageP95), strconv.FormatInt(st.MemRequest, 10), strconv.FormatInt(st.MemUsageP95, 10),
+				num(st.CPUBillableCost), num(st.MemBillableCost), num(st.TotalBillableCost), num(st.TotalUsageCost), num(st.TotalWasteCost),
+			})
+		}
+		return t
+	}
+	t := &ExportTable{
+		Title:   "Daily namespace costs " + window,
+		Columns: []string{"date", "namespace", "billable_cost", "usage_cost", "waste_cost", "shared_cost", "pod_count", "node_count", "workload_count", "efficiency_score"},
+		Rows:    make([][]string, 0, len(ds.DailyNamespaceCosts)),
+	}
+	for _, d := range ds.DailyNamespaceCosts {
+		t.Rows = append(t.Rows, []string{
+			d.Date.Format("2006-01-02"), d.Namespace, num(d.BillableCost), num(d.UsageCost), num(d.WasteCost), num(d.SharedCost),
+			strconv.Itoa(d.PodCount), strconv.Itoa(d.NodeCount), strconv.Itoa(d.WorkloadCount), num(d.EfficiencyScore),
+		})
+	}
+	return t
+}
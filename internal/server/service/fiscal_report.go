@@ -0,0 +1,153 @@
+// Package service fiscal_report.go: 按财务期间（期间 / 季度 / 财年，见 costmodel.FiscalCalendar）汇总成本，
+// 使“本月至今”“本季度”与公司账务口径一致；已关账期间的更正单列并计入调整后合计。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// ErrInvalidFiscalReport is returned for an unknown unit or a span that has not started.
+var ErrInvalidFiscalReport = dataerr.Validation("invalid fiscal report query")
+
+// FiscalReportService reports the daily namespace costs of fiscal spans.
+type FiscalReportService struct {
+	repo     postgres.Repository
+	fiscal   costmodel.FiscalCalendar
+	calendar costmodel.AccountingCalendar
+	adjust   AdjustmentLister
+	now      func() time.Time
+}
+
+// AdjustmentLister lists the adjustments of closed periods. *postgres.MockRepository satisfies this
+// interface.
+type AdjustmentLister interface {
+	ListAdjustments(ctx context.Context, filter postgres.AdjustmentFilter) ([]postgres.Adjustment, error)
+}
+
+// NewFiscalReportService creates a FiscalReportService for the fiscal calendar.
+func NewFiscalReportService(repo postgres.Repository, fiscal costmodel.FiscalCalendar) *FiscalReportService {
+	return &FiscalReportService{repo: repo, fiscal: fiscal, now: time.Now}
+}
+
+// SetCalendar sets the accounting time zone of "today" (default UTC).
+func (s *FiscalReportService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+// SetAdjustments makes reports include the adjustments of closed periods: the approved daily namespace
+// cost corrections dated within the span are listed and added to the adjusted total.
+func (s *FiscalReportService) SetAdjustments(adjust AdjustmentLister) {
+	s.adjust = adjust
+}
+
+// Report returns the cost of the fiscal span of unit (default period) containing the day labelled
+// date (default today) through today, compared with the whole previous span.
+func (s *FiscalReportService) Report(ctx context.Context, unit costmodel.FiscalUnit, date time.Time) (*dto.FiscalReportResponse, error) {
+	switch unit {
+	case "":
+		unit = costmodel.FiscalUnitPeriod
+	case costmodel.FiscalUnitPeriod, costmodel.FiscalUnitQuarter, costmodel.FiscalUnitYear:
+	default:
+		return nil, fmt.Errorf("%w: unit must be period, quarter or year", ErrInvalidFiscalReport)
+	}
+	today := s.calendar.Date(s.now())
+	if date.IsZero() {
+		date = today
+	}
+	span := s.fiscal.Span(date, unit)
+	if span.Start.After(today) {
+		return nil, fmt.Errorf("%w: %s has not started", ErrInvalidFiscalReport, span.Label())
+	}
+	asOf := span.End.AddDate(0, 0, -1)
+	if asOf.After(today) {
+		asOf = today
+	}
+	previous := s.fiscal.Span(span.Start.AddDate(0, 0, -1), unit)
+
+	costs, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{StartDate: previous.Start, EndDate: asOf})
+	if err != nil {
+		return nil, fmt.Errorf("list daily namespace costs: %w", err)
+	}
+	resp := &dto.FiscalReportResponse{
+		Unit:          string(unit),
+		Label:         span.Label(),
+		StartDate:     span.Start,
+		EndDate:       span.End,
+		AsOf:          asOf,
+		Days:          span.Days(),
+		DaysElapsed:   int(asOf.Sub(span.Start)/(24*time.Hour)) + 1,
+		PreviousLabel: previous.Label(),
+	}
+	byNamespace := make(map[string]*dto.FiscalReportNamespace)
+	for _, c := range costs {
+		n, ok := byNamespace[c.Namespace]
+		if !ok {
+			n = &dto.FiscalReportNamespace{Namespace: c.Namespace}
+			byNamespace[c.Namespace] = n
+		}
+		if c.Date.Before(span.Start) {
+			n.PreviousBillableCost += c.BillableCost
+			resp.PreviousBillableCost += c.BillableCost
+			continue
+		}
+		n.BillableCost += c.BillableCost
+		n.SharedCost += c.SharedCost
+		resp.BillableCost += c.BillableCost
+		resp.UsageCost += c.UsageCost
+		resp.WasteCost += c.WasteCost
+		resp.SharedCost += c.SharedCost
+	}
+	resp.AdjustmentItems = []dto.CostAdjustment{}
+	if s.adjust != nil {
+		adjustments, err := s.adjust.ListAdjustments(ctx, postgres.AdjustmentFilter{
+			Record: postgres.AdjustmentRecordDailyNamespaceCost, StartDate: span.Start, EndDate: asOf,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list adjustments: %w", err)
+		}
+		for _, a := range adjustments {
+			if a.Pending() {
+				continue
+			}
+			n, ok := byNamespace[a.Namespace]
+			if !ok {
+				n = &dto.FiscalReportNamespace{Namespace: a.Namespace}
+				byNamespace[a.Namespace] = n
+			}
+			n.Adjustments += a.Amount
+			resp.Adjustments += a.Amount
+			resp.AdjustmentItems = append(resp.AdjustmentItems, adjustmentDTO(a))
+		}
+		sort.SliceStable(resp.AdjustmentItems, func(i, j int) bool {
+			return resp.AdjustmentItems[i].Date.Before(resp.AdjustmentItems[j].Date)
+		})
+	}
+	resp.AdjustedTotal = roundCost(resp.BillableCost + resp.SharedCost + resp.Adjustments)
+	resp.Adjustments = roundCost(resp.Adjustments)
+	resp.Projected = roundCost(resp.BillableCost / float64(resp.DaysElapsed) * float64(resp.Days))
+	resp.BillableCost, resp.UsageCost = roundCost(resp.BillableCost), roundCost(resp.UsageCost)
+	resp.WasteCost, resp.SharedCost = roundCost(resp.WasteCost), roundCost(resp.SharedCost)
+	resp.PreviousBillableCost = roundCost(resp.PreviousBillableCost)
+	resp.Namespaces = make([]dto.FiscalReportNamespace, 0, len(byNamespace))
+	for _, n := range byNamespace {
+		n.BillableCost, n.SharedCost, n.PreviousBillableCost = roundCost(n.BillableCost), roundCost(n.SharedCost), roundCost(n.PreviousBillableCost)
+		n.Adjustments = roundCost(n.Adjustments)
+		resp.Namespaces = append(resp.Namespaces, *n)
+	}
+	sort.Slice(resp.Namespaces, func(i, j int) bool {
+		a, b := resp.Namespaces[i], resp.Namespaces[j]
+		if a.BillableCost != b.BillableCost {
+			return a.BillableCost > b.BillableCost
+		}
+		return a.Namespace < b.Namespace
+	})
+	return resp, nil
+}
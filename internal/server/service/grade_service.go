@@ -0,0 +1,51 @@
+// Package service grade_service.go: 工作负载效率等级变更历史查询。
+package service
+
+import (
+	"context"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// GradeHistoryStore lists grade change events (workload_grade_event).
+// *postgres.MockRepository satisfies this interface.
+type GradeHistoryStore interface {
+	ListGradeChangeEvents(ctx context.Context, filter postgres.GradeChangeEventFilter) ([]postgres.GradeChangeEvent, error)
+}
+
+// GradeHistoryService serves the grade transitions recorded by etl.GradeTracker.
+type GradeHistoryService struct {
+	store GradeHistoryStore
+}
+
+// NewGradeHistoryService creates a GradeHistoryService.
+func NewGradeHistoryService(store GradeHistoryStore) *GradeHistoryService {
+	return &GradeHistoryService{store: store}
+}
+
+// History lists grade change events, oldest first.
+func (s *GradeHistoryService) History(ctx context.Context, filter postgres.GradeChangeEventFilter) (*dto.GradeHistoryResponse, error) {
+	events, err := s.store.ListGradeChangeEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.GradeHistoryResponse{Events: make([]dto.GradeChangeEvent, 0, len(events))}
+	for _, e := range events {
+		resp.Events = append(resp.Events, toDTOGradeChangeEvent(e))
+	}
+	resp.Total = len(resp.Events)
+	return resp, nil
+}
+
+func toDTOGradeChangeEvent(e postgres.GradeChangeEvent) dto.GradeChangeEvent {
+	return dto.GradeChangeEvent{
+		Namespace:       e.Namespace,
+		WorkloadName:    e.WorkloadName,
+		WorkloadType:    e.WorkloadType,
+		FromGrade:       e.FromGrade,
+		ToGrade:         e.ToGrade,
+		EfficiencyScore: e.EfficiencyScore,
+		OccurredAt:      e.OccurredAt,
+	}
+}
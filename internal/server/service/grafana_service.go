@@ -0,0 +1,366 @@
+// Package service grafana_service.go: Grafana JSON datasource 协议（/search、/query、/annotations）的数据组装。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// Grafana target names. Namespace/service specific targets append ".<name>".
+const (
+	GrafanaTargetCostTotal        = "cost.total"
+	GrafanaTargetCostNamespace    = "cost.namespace"
+	GrafanaTargetEfficiencyTotal  = "efficiency.total"
+	GrafanaTargetEfficiencyNS     = "efficiency.namespace"
+	GrafanaTargetSLOStatus        = "slo.status"
+	GrafanaTargetSLOAvailability  = "slo.availability"
+	GrafanaAnnotationROIBaselines = "roi_baselines"
+	GrafanaAnnotationSLO          = "slo"
+	GrafanaAnnotationNamespaces   = "namespace_lifecycle"
+)
+
+// SLOServiceStatus is the current SLO status of one service.
+type SLOServiceStatus struct {
+	Service      string        `json:"service"`
+	Namespace    string        `json:"namespace"`
+	Status       slo.SLOStatus `json:"status"`
+	Availability float64       `json:"availability"`
+	LatencyP95Ms float64       `json:"latency_p95_ms"`
+	ErrorRate    float64       `json:"error_rate"`
+
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"` // 剩余错误预算百分比
+	// AvailabilityTarget 可用性目标（%）；0 取配置的默认目标（business.slo.availability_threshold）
+	AvailabilityTarget float64 `json:"availability_target,omitempty"`
+}
+
+// SLOStatusProvider supplies current SLO status per service.
+type SLOStatusProvider interface {
+	ListSLOStatus(ctx context.Context) ([]SLOServiceStatus, error)
+}
+
+// StaticSLOStatusProvider serves a fixed SLO status list (Mock data until the SLO engine lands).
+type StaticSLOStatusProvider []SLOServiceStatus
+
+// ListSLOStatus implements SLOStatusProvider.
+func (p StaticSLOStatusProvider) ListSLOStatus(ctx context.Context) ([]SLOServiceStatus, error) {
+	return p, nil
+}
+
+// DefaultMockSLOStatus returns the same services as the /api/v1/slo/health mock.
+func DefaultMockSLOStatus() StaticSLOStatusProvider {
+	return StaticSLOStatusProvider{
+		{Service: "api-gateway", Namespace: "default", Status: slo.SLOStatusHealthy, Availability: 99.95, LatencyP95Ms: 120, ErrorRate: 0.01, ErrorBudgetRemaining: 85},
+		{Service: "order-service", Namespace: "app-prod", Status: slo.SLOStatusHealthy, Availability: 99.90, LatencyP95Ms: 85, ErrorRate: 0.02, ErrorBudgetRemaining: 60},
+		{Service: "payment-service", Namespace: "app-prod", Status: slo.SLOStatusWarning, Availability: 99.50, LatencyP95Ms: 200, ErrorRate: 0.15, ErrorBudgetRemaining: 12},
+	}
+}
+
+// GrafanaService serves Lighthouse data in the Grafana simple-JSON datasource format.
+type GrafanaService struct {
+	repo       postgres.Repository
+	slo        SLOStatusProvider
+	lifecycles NamespaceLifecycleReader
+	now        func() time.Time
+}
+
+// NewGrafanaService creates a GrafanaService. sloProvider may be nil (SLO targets are then empty).
+func NewGrafanaService(repo postgres.Repository, sloProvider SLOStatusProvider) *GrafanaService {
+	return &GrafanaService{repo: repo, slo: sloProvider, now: time.Now}
+}
+
+// SetNamespaceLifecycles enables the namespace_lifecycle annotation, which marks when namespaces
+// were created and deleted so drops in cost.namespace.* series are explained.
+func (s *GrafanaService) SetNamespaceLifecycles(lifecycles NamespaceLifecycleReader) {
+	s.lifecycles = lifecycles
+}
+
+// Search returns the available targets containing the given substring.
+func (s *GrafanaService) Search(ctx context.Context, req dto.GrafanaSearchRequest) ([]string, error) {
+	targets := []string{GrafanaTargetCostTotal, GrafanaTargetEfficiencyTotal}
+
+	end := s.now()
+	costs, err := s.repo.AggregateDailyNamespaceCosts(ctx, end.AddDate(0, 0, -30), end)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make(map[string]struct{})
+	for _, c := range costs {
+		namespaces[c.Namespace] = struct{}{}
+	}
+	for _, ns := range sortedKeys(namespaces) {
+		targets = append(targets, GrafanaTargetCostNamespace+"."+ns, GrafanaTargetEfficiencyNS+"."+ns)
+	}
+
+	if s.slo != nil {
+		statuses, err := s.slo.ListSLOStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, GrafanaTargetSLOStatus)
+		for _, st := range statuses {
+			targets = append(targets, GrafanaTargetSLOAvailability+"."+st.Service)
+		}
+	}
+
+	if req.Target == "" {
+		return targets, nil
+	}
+	filtered := make([]string, 0, len(targets))
+	for _, t := range targets {
+		if strings.Contains(t, req.Target) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+// Query resolves each target into a time series or table. Results keep the target order.
+func (s *GrafanaService) Query(ctx context.Context, req dto.GrafanaQueryRequest) ([]interface{}, error) {
+	from, to := req.Range.From, req.Range.To
+	if to.IsZero() {
+		to = s.now()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -7)
+	}
+
+	var costs []postgres.DailyNamespaceCost
+	loadCosts := func() error {
+		if costs != nil {
+			return nil
+		}
+		list, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{StartDate: from, EndDate: to})
+		if err != nil {
+			return err
+		}
+		costs = list
+		if costs == nil {
+			costs = []postgres.DailyNamespaceCost{}
+		}
+		return nil
+	}
+
+	results := make([]interface{}, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		switch {
+		case t.Target == GrafanaTargetSLOStatus:
+			table, err := s.sloTable(ctx)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, table)
+
+		case strings.HasPrefix(t.Target, GrafanaTargetSLOAvailability+"."):
+			series, err := s.sloAvailabilitySeries(ctx, t.Target, to)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, series)
+
+		case t.Target == GrafanaTargetCostTotal, t.Target == GrafanaTargetEfficiencyTotal,
+			strings.HasPrefix(t.Target, GrafanaTargetCostNamespace+"."),
+			strings.HasPrefix(t.Target, GrafanaTargetEfficiencyNS+"."):
+			if err := loadCosts(); err != nil {
+				return nil, err
+			}
+			results = append(results, costSeries(t.Target, costs))
+
+		default:
+			return nil, fmt.Errorf("unknown grafana target: %s", t.Target)
+		}
+	}
+	return results, nil
+}
+
+// Annotations returns ROI baseline periods, non-healthy SLO services or namespace
+// creation/deletion events within the range.
+func (s *GrafanaService) Annotations(ctx context.Context, req dto.GrafanaAnnotationRequest) ([]dto.GrafanaAnnotation, error) {
+	query := req.Annotation.Query
+	if query == "" {
+		query = GrafanaAnnotationROIBaselines
+	}
+
+	var out []dto.GrafanaAnnotation
+	switch query {
+	case GrafanaAnnotationROIBaselines:
+		baselines, err := s.repo.ListROIBaselines(ctx, postgres.ROIBaselineFilter{StartDate: req.Range.From, EndDate: req.Range.To})
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range baselines {
+			out = append(out, dto.GrafanaAnnotation{
+				Annotation: req.Annotation,
+				Time:       b.TimePeriodStart.UnixMilli(),
+				TimeEnd:    b.TimePeriodEnd.UnixMilli(),
+				Title:      "ROI baseline: " + b.Name,
+				Text:       b.Description,
+				Tags:       []string{"roi", b.BaselineType},
+			})
+		}
+	case GrafanaAnnotationSLO:
+		if s.slo == nil {
+			return []dto.GrafanaAnnotation{}, nil
+		}
+		statuses, err := s.slo.ListSLOStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		now := s.now().UnixMilli()
+		for _, st := range statuses {
+			if st.Status == slo.SLOStatusHealthy {
+				continue
+			}
+			out = append(out, dto.GrafanaAnnotation{
+				Annotation: req.Annotation,
+				Time:       now,
+				Title:      fmt.Sprintf("SLO %s: %s", st.Status, st.Service),
+				Text:       fmt.Sprintf("availability %.2f%%, p95 %.0fms, error rate %.2f%%", st.Availability, st.LatencyP95Ms, st.ErrorRate),
+				Tags:       []string{"slo", string(st.Status), st.Namespace},
+			})
+		}
+	case GrafanaAnnotationNamespaces:
+		if s.lifecycles == nil {
+			return []dto.GrafanaAnnotation{}, nil
+		}
+		records, err := s.lifecycles.ListNamespaceLifecycles(ctx)
+		if err != nil {
+			return nil, err
+		}
+		inRange := func(t time.Time) bool {
+			return (req.Range.From.IsZero() || !t.Before(req.Range.From)) && (req.Range.To.IsZero() || !t.After(req.Range.To))
+		}
+		for _, r := range records {
+			if inRange(r.FirstSeen) {
+				out = append(out, dto.GrafanaAnnotation{
+					Annotation: req.Annotation,
+					Time:       r.FirstSeen.UnixMilli(),
+					Title:      "Namespace created: " + r.Namespace,
+					Tags:       []string{"namespace", "created", r.Namespace},
+				})
+			}
+			if r.Terminated() && inRange(*r.DeletedAt) {
+				out = append(out, dto.GrafanaAnnotation{
+					Annotation: req.Annotation,
+					Time:       r.DeletedAt.UnixMilli(),
+					Title:      "Namespace terminated: " + r.Namespace,
+					Text:       "costs after this point are no longer reported; history is kept",
+					Tags:       []string{"namespace", "terminated", r.Namespace},
+				})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown grafana annotation query: %s", query)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Time < out[j].Time })
+	if out == nil {
+		out = []dto.GrafanaAnnotation{}
+	}
+	return out, nil
+}
+
+func (s *GrafanaService) sloTable(ctx context.Context) (dto.GrafanaTable, error) {
+	table := dto.GrafanaTable{
+		Type: "table",
+		Columns: []dto.GrafanaTableColumn{
+			{Text: "service", Type: "string"},
+			{Text: "namespace", Type: "string"},
+			{Text: "status", Type: "string"},
+			{Text: "availability", Type: "number"},
+			{Text: "latency_p95_ms", Type: "number"},
+			{Text: "error_rate", Type: "number"},
+		},
+		Rows: [][]interface{}{},
+	}
+	if s.slo == nil {
+		return table, nil
+	}
+	statuses, err := s.slo.ListSLOStatus(ctx)
+	if err != nil {
+		return table, err
+	}
+	for _, st := range statuses {
+		table.Rows = append(table.Rows, []interface{}{st.Service, st.Namespace, string(st.Status), st.Availability, st.LatencyP95Ms, st.ErrorRate})
+	}
+	return table, nil
+}
+
+// sloAvailabilitySeries returns the current availability as a single point at the range end.
+func (s *GrafanaService) sloAvailabilitySeries(ctx context.Context, target string, at time.Time) (dto.GrafanaTimeSeries, error) {
+	series := dto.GrafanaTimeSeries{Target: target, Datapoints: [][2]float64{}}
+	if s.slo == nil {
+		return series, nil
+	}
+	statuses, err := s.slo.ListSLOStatus(ctx)
+	if err != nil {
+		return series, err
+	}
+	service := strings.TrimPrefix(target, GrafanaTargetSLOAvailability+".")
+	for _, st := range statuses {
+		if st.Service == service {
+			series.Datapoints = append(series.Datapoints, [2]float64{st.Availability, float64(at.UnixMilli())})
+		}
+	}
+	return series, nil
+}
+
+// costSeries builds a daily series (billable cost or efficiency %) for a cost/efficiency target.
+func costSeries(target string, costs []postgres.DailyNamespaceCost) dto.GrafanaTimeSeries {
+	efficiency := strings.HasPrefix(target, "efficiency.")
+	namespace := ""
+	if i := strings.Index(target, ".namespace."); i >= 0 {
+		namespace = target[i+len(".namespace."):]
+	}
+
+	type day struct{ billable, usage float64 }
+	days := make(map[int64]*day)
+	for _, c := range costs {
+		if namespace != "" && c.Namespace != namespace {
+			continue
+		}
+		ts := time.Date(c.Date.Year(), c.Date.Month(), c.Date.Day(), 0, 0, 0, 0, time.UTC).UnixMilli()
+		d, ok := days[ts]
+		if !ok {
+			d = &day{}
+			days[ts] = d
+		}
+		d.billable += c.BillableCost
+		d.usage += c.UsageCost
+	}
+
+	keys := make([]int64, 0, len(days))
+	for ts := range days {
+		keys = append(keys, ts)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	series := dto.GrafanaTimeSeries{Target: target, Datapoints: make([][2]float64, 0, len(keys))}
+	for _, ts := range keys {
+		d := days[ts]
+		value := d.billable
+		if efficiency {
+			value = 0
+			if d.billable > 0 {
+				value = d.usage / d.billable * 100
+			}
+		}
+		series.Datapoints = append(series.Datapoints, [2]float64{value, float64(ts)})
+	}
+	return series
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+// Package service integrity_service.go: 成本快照完整性校验（内容哈希 + 追加式哈希链）与带校验的快照导出。
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+var (
+	// ErrSnapshotNotFound is returned when verifying a snapshot that neither exists nor was ever recorded.
+	ErrSnapshotNotFound = dataerr.NotFound("cost snapshot not found")
+	// ErrSnapshotIntegrity is returned by Export when a snapshot in the window fails verification.
+	ErrSnapshotIntegrity = dataerr.Conflict("cost snapshot integrity check failed")
+)
+
+// SnapshotHashChainStore reads the snapshot hash chain written by the repository on every snapshot
+// save and delete. *postgres.MockRepository satisfies this interface.
+type SnapshotHashChainStore interface {
+	ListSnapshotHashChain(ctx context.Context) ([]postgres.SnapshotHashRecord, error)
+}
+
+// SnapshotIntegrityService verifies cost snapshots against their content hashes and the hash chain.
+type SnapshotIntegrityService struct {
+	repo  postgres.Repository
+	chain SnapshotHashChainStore
+	now   func() time.Time
+}
+
+// NewSnapshotIntegrityService creates a SnapshotIntegrityService.
+func NewSnapshotIntegrityService(repo postgres.Repository, chain SnapshotHashChainStore) *SnapshotIntegrityService {
+	return &SnapshotIntegrityService{repo: repo, chain: chain, now: time.Now}
+}
+
+// Verify checks all snapshots, or only snapshotID when set. A broken chain is reported in either
+// case since it makes every later record untrustworthy.
+func (s *SnapshotIntegrityService) Verify(ctx context.Context, snapshotID string) (*dto.SnapshotVerificationResponse, error) {
+	snapshots, v, err := s.verifyAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snapshotID == "" {
+		return s.toResponse("", v, nil), nil
+	}
+	known := false
+	for _, snap := range snapshots {
+		known = known || snap.ID == snapshotID
+	}
+	for _, issue := range v.Issues {
+		known = known || issue.SnapshotID == snapshotID
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, snapshotID)
+	}
+	return s.toResponse(snapshotID, v, map[string]bool{snapshotID: true}), nil
+}
+
+// Export returns the snapshots taken in start..end (zero start: from the first one, zero end: now)
+// with their content hashes after verifying them. When any of them (or the chain) fails verification
+// it returns the verification and ErrSnapshotIntegrity instead, so tampered figures never leave the
+// system unflagged.
+func (s *SnapshotIntegrityService) Export(ctx context.Context, start, end time.Time) (*dto.SnapshotExportResponse, *dto.SnapshotVerificationResponse, error) {
+	if end.IsZero() {
+		end = s.now()
+	}
+	if !end.After(start) {
+		return nil, nil, ErrInvalidWindow
+	}
+	snapshots, v, err := s.verifyAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := &dto.SnapshotExportResponse{WindowStart: start, WindowEnd: end, Snapshots: []dto.ExportedSnapshot{}}
+	ids := make(map[string]bool)
+	for _, snap := range snapshots {
+		if snap.Timestamp.Before(start) || !snap.Timestamp.Before(end) {
+			continue
+		}
+		ids[snap.ID] = true
+		raw, err := json.Marshal(snap)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal cost snapshot %s: %w", snap.ID, err)
+		}
+		resp.Snapshots = append(resp.Snapshots, dto.ExportedSnapshot{ID: snap.ID, ContentHash: snap.ContentHash, Snapshot: raw})
+	}
+	for _, issue := range v.Issues {
+		if issue.Problem == postgres.IntegrityMissing {
+			ids[issue.SnapshotID] = true // its timestamp is gone with it; it may have been in the window
+		}
+	}
+	verification := s.toResponse("", v, ids)
+	if !verification.Verified {
+		return nil, verification, ErrSnapshotIntegrity
+	}
+	sort.Slice(resp.Snapshots, func(i, j int) bool { return resp.Snapshots[i].ID < resp.Snapshots[j].ID })
+	resp.Verification = *verification
+	resp.ExportedAt = verification.VerifiedAt
+	return resp, verification, nil
+}
+
+func (s *SnapshotIntegrityService) verifyAll(ctx context.Context) ([]postgres.CostSnapshot, postgres.SnapshotVerification, error) {
+	snapshots, err := s.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{})
+	if err != nil {
+		return nil, postgres.SnapshotVerification{}, fmt.Errorf("list cost snapshots: %w", err)
+	}
+	chain, err := s.chain.ListSnapshotHashChain(ctx)
+	if err != nil {
+		return nil, postgres.SnapshotVerification{}, fmt.Errorf("list snapshot hash chain: %w", err)
+	}
+	return snapshots, postgres.VerifySnapshotChain(snapshots, chain), nil
+}
+
+// toResponse converts v; a non-nil scope keeps only issues of those snapshots plus chain breaks.
+func (s *SnapshotIntegrityService) toResponse(snapshotID string, v postgres.SnapshotVerification, scope map[string]bool) *dto.SnapshotVerificationResponse {
+	resp := &dto.SnapshotVerificationResponse{
+		SnapshotID:  snapshotID,
+		Snapshots:   v.Snapshots,
+		ChainLength: v.ChainLength,
+		HeadHash:    v.HeadHash,
+		Issues:      []dto.SnapshotIntegrityIssue{},
+		VerifiedAt:  s.now().UTC(),
+	}
+	if scope != nil {
+		resp.Snapshots = len(scope)
+	}
+	for _, issue := range v.Issues {
+		if scope != nil && !scope[issue.SnapshotID] && issue.Problem != postgres.IntegrityChainBroken {
+			continue
+		}
+		resp.Issues = append(resp.Issues, dto.SnapshotIntegrityIssue{
+			SnapshotID: issue.SnapshotID,
+			Seq:        issue.Seq,
+			Problem:    issue.Problem,
+			Detail:     issue.Detail,
+		})
+	}
+	resp.Verified = len(resp.Issues) == 0
+	return resp
+}
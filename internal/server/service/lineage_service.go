@@ -0,0 +1,147 @@
+// Package service lineage_service.go: 成本查询数据血缘。每个成功的成本查询响应记录其所用的快照、计算执行、
+// 单价版本与构建版本，财务对某个数字存疑时按响应头中的 ID 回溯。
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// LineageStore persists cost query lineage records (cost_query_lineage).
+// *postgres.MockRepository satisfies this interface.
+type LineageStore interface {
+	SaveQueryLineage(ctx context.Context, lineage postgres.QueryLineage) error
+	GetQueryLineage(ctx context.Context, id string) (*postgres.QueryLineage, error)
+}
+
+// LineageRequest identifies the request a lineage record is captured for.
+type LineageRequest struct {
+	RequestID string
+	Method    string
+	Path      string // including the query string
+}
+
+// LineageService captures and serves cost query lineage. Calculation runs and price history are
+// optional; without them the record only names the snapshot and the code version.
+type LineageService struct {
+	store  LineageStore
+	repo   postgres.Repository
+	runs   CalculationRunStore
+	prices PriceHistoryStore
+	code   postgres.CodeVersion
+	now    func() time.Time
+}
+
+// NewLineageService creates a LineageService.
+func NewLineageService(store LineageStore, repo postgres.Repository) *LineageService {
+	return &LineageService{store: store, repo: repo, code: postgres.CodeVersion{Version: "dev", GitCommit: "unknown"}, now: time.Now}
+}
+
+// SetCalculationRuns records the latest finished calculation run in each lineage.
+func (s *LineageService) SetCalculationRuns(runs CalculationRunStore) {
+	s.runs = runs
+}
+
+// SetPriceHistory records the price version that priced the latest run's window in each lineage.
+func (s *LineageService) SetPriceHistory(prices PriceHistoryStore) {
+	s.prices = prices
+}
+
+// SetCodeVersion sets the build recorded in each lineage; empty fields keep the defaults.
+func (s *LineageService) SetCodeVersion(code postgres.CodeVersion) {
+	if code.Version != "" {
+		s.code.Version = code.Version
+	}
+	if code.GitCommit != "" {
+		s.code.GitCommit = code.GitCommit
+	}
+	s.code.BuildTime = code.BuildTime
+}
+
+// Capture reads the data state a response is about to be built from. The record gets its ID here
+// so it can be announced before the response body; Save stores it once the response succeeded.
+func (s *LineageService) Capture(ctx context.Context, req LineageRequest) (postgres.QueryLineage, error) {
+	lineage := postgres.QueryLineage{
+		ID:        uuid.New().String(),
+		RequestID: req.RequestID,
+		Method:    req.Method,
+		Path:      req.Path,
+		ServedAt:  s.now().UTC(),
+		Code:      s.code,
+	}
+	snapshots, err := s.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{Limit: 1, ExcludeUnconfirmed: true, Fields: postgres.SnapshotFieldSummary})
+	if err != nil {
+		return lineage, fmt.Errorf("list cost snapshots: %w", err)
+	}
+	if len(snapshots) > 0 {
+		snap := snapshots[0]
+		lineage.Snapshot = &postgres.LineageSnapshot{ID: snap.ID, CalculationID: snap.CalculationID, Timestamp: snap.Timestamp, ContentHash: snap.ContentHash}
+	}
+	priceAt := lineage.ServedAt
+	if s.runs != nil {
+		run, err := latestFinishedRun(ctx, s.runs)
+		if err != nil {
+			return lineage, err
+		}
+		if run != nil {
+			lineage.CalculationRun = run
+			priceAt = run.WindowEnd.Add(-time.Nanosecond)
+		}
+	}
+	if s.prices != nil {
+		versions, err := s.prices.ListPriceVersions(ctx)
+		if err != nil {
+			return lineage, fmt.Errorf("list price versions: %w", err)
+		}
+		for i := range versions {
+			if !versions[i].EffectiveFrom.After(priceAt) {
+				v := versions[i]
+				lineage.PriceVersion = &v
+			}
+		}
+	}
+	return lineage, nil
+}
+
+// Save stores a captured lineage with the response status.
+func (s *LineageService) Save(ctx context.Context, lineage postgres.QueryLineage, status int) error {
+	lineage.Status = status
+	if err := s.store.SaveQueryLineage(ctx, lineage); err != nil {
+		return fmt.Errorf("save query lineage: %w", err)
+	}
+	return nil
+}
+
+// Get returns a lineage record by ID.
+func (s *LineageService) Get(ctx context.Context, id string) (*dto.QueryLineage, error) {
+	lineage, err := s.store.GetQueryLineage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := &dto.QueryLineage{
+		ID:        lineage.ID,
+		RequestID: lineage.RequestID,
+		Method:    lineage.Method,
+		Path:      lineage.Path,
+		Status:    lineage.Status,
+		ServedAt:  lineage.ServedAt,
+		Code:      dto.CodeVersion{Version: lineage.Code.Version, GitCommit: lineage.Code.GitCommit, BuildTime: lineage.Code.BuildTime},
+	}
+	if snap := lineage.Snapshot; snap != nil {
+		out.Snapshot = &dto.LineageSnapshot{ID: snap.ID, CalculationID: snap.CalculationID, Timestamp: snap.Timestamp, ContentHash: snap.ContentHash}
+	}
+	if lineage.CalculationRun != nil {
+		run := toDTOCalculationRun(*lineage.CalculationRun)
+		out.CalculationRun = &run
+	}
+	if lineage.PriceVersion != nil {
+		v := toDTOPriceVersion(*lineage.PriceVersion)
+		out.PriceVersion = &v
+	}
+	return out, nil
+}
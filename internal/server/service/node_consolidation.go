@@ -0,0 +1,206 @@
+// Package service node_consolidation.go: 节点缩容计划——在装箱分析的缩容候选之上给出可审阅的排空顺序，
+// 经 K8s 客户端核对 PodDisruptionBudget（不允许驱逐的节点不排空），逐步估算节省与风险并附上执行命令。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// Risk levels of a drain step.
+const (
+	DrainRiskLow    = "low"
+	DrainRiskMedium = "medium"
+	DrainRiskHigh   = "high"
+)
+
+// SetDisruptionBudgets makes consolidation plans check the PodDisruptionBudgets of the pods running
+// on each candidate node; without it plans are marked unverified.
+func (s *NodeAnalysisService) SetDisruptionBudgets(pods PodLister, budgets k8s.DisruptionBudgetLister) {
+	s.pods, s.budgets = pods, budgets
+}
+
+// Plan returns the consolidation candidates of Analyze as a drain order. With disruption budgets
+// set, a node running a pod whose budget allows no disruption is not drained (draining it would
+// hang) and the candidates are chosen again without it. Each step is rated:
+//   - high: the largest pod of the node requests more than any kept node has room for, so the
+//     aggregate estimate may not hold pod by pod;
+//   - medium: a budget covers more pods of the node than it allows to be evicted at once, so the
+//     drain waits for replacements to become ready; or budgets were not checked;
+//   - low otherwise.
+func (s *NodeAnalysisService) Plan(ctx context.Context, targetUtilization float64) (*dto.NodeConsolidationPlan, error) {
+	snap, order, err := s.snapshot(ctx, targetUtilization)
+	if err != nil {
+		return nil, err
+	}
+	plan := &dto.NodeConsolidationPlan{
+		Timestamp:                snap.Timestamp,
+		TargetUtilization:        snap.TargetUtilization,
+		DisruptionBudgetsChecked: s.budgets != nil && s.pods != nil,
+		Steps:                    []dto.NodeDrainStep{},
+		Blocked:                  []dto.NodeConsolidationBlock{},
+	}
+	if snap.Timestamp.IsZero() {
+		return plan, nil
+	}
+
+	candidates := consolidationCandidates(order, plan.TargetUtilization, nil)
+	budgetsByNode := make(map[string][]dto.NodeDrainBudget)
+	podsByNode := make(map[string]int)
+	if plan.DisruptionBudgetsChecked {
+		if budgetsByNode, podsByNode, err = s.nodeBudgets(ctx); err != nil {
+			return nil, err
+		}
+		// 跳过被阻塞的节点后重新选择候选，直到候选中不再有被阻塞的节点
+		skip := make(map[string]bool)
+		for blocked := true; blocked; {
+			blocked = false
+			for _, c := range candidates {
+				var reasons []string
+				for _, b := range budgetsByNode[c.Name] {
+					if b.DisruptionsAllowed <= 0 {
+						reasons = append(reasons, fmt.Sprintf("PodDisruptionBudget %s/%s allows no disruption of its %d pod(s) on the node", b.Namespace, b.Name, b.PodsOnNode))
+					}
+				}
+				if len(reasons) > 0 {
+					skip[c.Name], blocked = true, true
+					plan.Blocked = append(plan.Blocked, dto.NodeConsolidationBlock{Node: c.Name, Reasons: reasons})
+				}
+			}
+			if blocked {
+				candidates = consolidationCandidates(order, plan.TargetUtilization, skip)
+			}
+		}
+	}
+
+	byName := make(map[string]*nodeUsage, len(order))
+	for _, u := range order {
+		byName[u.item.Name] = u
+	}
+	drained := make(map[string]bool)
+	var movedCPU, movedMem float64
+	for _, c := range candidates {
+		n := byName[c.Name]
+		drained[c.Name] = true
+		movedCPU += n.reqCPU
+		movedMem += n.reqMem
+		step := dto.NodeDrainStep{
+			Step:              c.Rank,
+			Node:              c.Name,
+			PodCount:          c.PodCount,
+			HourlyCost:        roundCost(c.HourlyCost),
+			MonthlySavings:    roundCost(c.EstimatedMonthlySavings),
+			Risk:              DrainRiskLow,
+			RiskReasons:       []string{},
+			DisruptionBudgets: budgetsByNode[c.Name],
+			Commands: []string{
+				"kubectl cordon " + c.Name,
+				"kubectl drain " + c.Name + " --ignore-daemonsets --delete-emptydir-data",
+			},
+		}
+		if live, ok := podsByNode[c.Name]; ok {
+			step.PodCount = live
+		}
+		if step.DisruptionBudgets == nil {
+			step.DisruptionBudgets = []dto.NodeDrainBudget{}
+		}
+		plan.EstimatedMonthlySavings += c.EstimatedMonthlySavings
+		step.CumulativeMonthlySavings = roundCost(plan.EstimatedMonthlySavings)
+
+		// 保留节点承接已排空节点的请求后的请求率，及单个节点的最大剩余空间
+		var allocCPU, allocMem, reqCPU, reqMem, roomCPU, roomMem float64
+		for _, o := range order {
+			if drained[o.item.Name] || o.allocCPU <= 0 || o.allocMem <= 0 {
+				continue
+			}
+			allocCPU += o.allocCPU
+			allocMem += o.allocMem
+			reqCPU += o.reqCPU
+			reqMem += o.reqMem
+			roomCPU = max(roomCPU, o.allocCPU*plan.TargetUtilization-o.reqCPU)
+			roomMem = max(roomMem, o.allocMem*plan.TargetUtilization-o.reqMem)
+		}
+		step.RemainingCPURequestRatio = roundCost((reqCPU + movedCPU) / allocCPU * 100)
+		step.RemainingMemRequestRatio = roundCost((reqMem + movedMem) / allocMem * 100)
+
+		var podCPU, podMem float64
+		for _, req := range n.podReqs {
+			podCPU, podMem = max(podCPU, req[0]), max(podMem, req[1])
+		}
+		if podCPU > roomCPU || podMem > roomMem {
+			step.Risk = DrainRiskHigh
+			step.RiskReasons = append(step.RiskReasons, "the largest pod of the node requests more than any kept node has room for")
+		}
+		for _, b := range step.DisruptionBudgets {
+			if b.PodsOnNode > int(b.DisruptionsAllowed) {
+				step.RiskReasons = append(step.RiskReasons, fmt.Sprintf("PodDisruptionBudget %s/%s allows %d of its %d pod(s) on the node to be evicted at once", b.Namespace, b.Name, b.DisruptionsAllowed, b.PodsOnNode))
+				if step.Risk == DrainRiskLow {
+					step.Risk = DrainRiskMedium
+				}
+			}
+		}
+		if !plan.DisruptionBudgetsChecked {
+			step.RiskReasons = append(step.RiskReasons, "pod disruption budgets not checked")
+			if step.Risk == DrainRiskLow {
+				step.Risk = DrainRiskMedium
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	plan.EstimatedMonthlySavings = roundCost(plan.EstimatedMonthlySavings)
+	return plan, nil
+}
+
+// nodeBudgets returns, by node, the disruption budgets covering its running pods, and its number
+// of running pods.
+func (s *NodeAnalysisService) nodeBudgets(ctx context.Context) (map[string][]dto.NodeDrainBudget, map[string]int, error) {
+	budgets, err := s.budgets.GetPodDisruptionBudgets(ctx, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("list pod disruption budgets: %w", err)
+	}
+	namespaces, err := s.pods.GetNamespaces(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list namespaces: %w", err)
+	}
+	type key struct {
+		node   string
+		budget int // index in budgets
+	}
+	covered := make(map[key]int)
+	pods := make(map[string]int)
+	for _, ns := range namespaces {
+		list, err := s.pods.GetPods(ctx, ns.Name, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("list pods of %s: %w", ns.Name, err)
+		}
+		for _, p := range list {
+			if p.NodeName == "" || p.Phase == "Succeeded" || p.Phase == "Failed" {
+				continue
+			}
+			pods[p.NodeName]++
+			for i, b := range budgets {
+				if b.Covers(p.Namespace, p.Labels) {
+					covered[key{p.NodeName, i}]++
+				}
+			}
+		}
+	}
+	byNode := make(map[string][]dto.NodeDrainBudget)
+	for k, n := range covered {
+		b := budgets[k.budget]
+		byNode[k.node] = append(byNode[k.node], dto.NodeDrainBudget{Namespace: b.Namespace, Name: b.Name, PodsOnNode: n, DisruptionsAllowed: b.DisruptionsAllowed})
+	}
+	for _, list := range byNode {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Namespace != list[j].Namespace {
+				return list[i].Namespace < list[j].Namespace
+			}
+			return list[i].Name < list[j].Name
+		})
+	}
+	return byNode, pods, nil
+}
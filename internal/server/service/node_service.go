@@ -0,0 +1,363 @@
+// Package service node_service.go: L2 节点分析（可分配 vs 请求 vs 使用成本、装箱效率与节点缩容候选）。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// DefaultConsolidationTargetUtilization is the request ratio remaining nodes may be packed up to
+// when absorbing drained nodes.
+const DefaultConsolidationTargetUtilization = 0.85
+
+// ErrInvalidTargetUtilization is returned for a target utilization outside (0, 1].
+var ErrInvalidTargetUtilization = dataerr.Validation("target utilization must be in (0, 1]")
+
+// NodeLister lists cluster nodes. k8s.Client satisfies this interface.
+type NodeLister interface {
+	GetNodes(ctx context.Context) ([]k8s.Node, error)
+}
+
+// NodeAnalysisService joins node allocatable resources with the latest hourly workload stats.
+type NodeAnalysisService struct {
+	nodes      NodeLister
+	repo       postgres.Repository
+	cpuPrice   float64 // per core hour
+	memPrice   float64 // per GiB hour
+	lookback   time.Duration
+	poolLabels []string
+	pods       PodLister
+	budgets    k8s.DisruptionBudgetLister
+	now        func() time.Time
+	nonFinite  costmodel.NonFinitePolicy
+}
+
+// NewNodeAnalysisService creates a NodeAnalysisService priced with the given unit prices.
+func NewNodeAnalysisService(nodes NodeLister, repo postgres.Repository, cpuPricePerCoreHour, memPricePerGBHour float64) *NodeAnalysisService {
+	return &NodeAnalysisService{
+		nodes:    nodes,
+		repo:     repo,
+		cpuPrice: cpuPricePerCoreHour,
+		memPrice: memPricePerGBHour,
+		lookback: 24 * time.Hour,
+		now:      time.Now,
+	}
+}
+
+// SetNodePoolLabels sets the node labels naming the pool of a node (default costmodel.DefaultNodePoolLabels).
+func (s *NodeAnalysisService) SetNodePoolLabels(labels []string) {
+	s.poolLabels = labels
+}
+
+// SetNonFinitePolicy sets what happens to stats with NaN/Inf costs in the node pool view
+// (Business.CostCalculation.NonFinitePolicy; default costmodel.NonFiniteZero).
+func (s *NodeAnalysisService) SetNonFinitePolicy(policy costmodel.NonFinitePolicy) {
+	s.nonFinite = policy
+}
+
+// nodeUsage accumulates one node's figures in base units (cores / bytes).
+type nodeUsage struct {
+	item               dto.NodeCostItem
+	allocCPU, allocMem float64
+	reqCPU, reqMem     float64
+	pods               map[string]bool
+	podReqs            map[string][2]float64 // namespace/pod -> cpu, memory requests
+}
+
+// Analyze returns per-node costs and consolidation candidates. targetUtilization (0 uses
+// DefaultConsolidationTargetUtilization) caps how full remaining nodes may get.
+//
+// Candidates are chosen greedily from the emptiest node: a node is drained when the headroom of
+// the nodes still kept (allocatable*target - requested) covers its requests plus those of the nodes
+// already drained, for both CPU and memory. This is an aggregate estimate, not a pod-level schedule.
+func (s *NodeAnalysisService) Analyze(ctx context.Context, targetUtilization float64) (*dto.NodeAnalysisResponse, error) {
+	resp, order, err := s.snapshot(ctx, targetUtilization)
+	if err != nil || resp.Timestamp.IsZero() {
+		return resp, err // 无统计数据时所有节点都显示为空，不给出缩容建议
+	}
+	resp.Candidates = consolidationCandidates(order, resp.TargetUtilization, nil)
+	for _, c := range resp.Candidates {
+		resp.EstimatedMonthlySavings += c.EstimatedMonthlySavings
+	}
+	return resp, nil
+}
+
+// snapshot returns the per-node figures of the latest hour of stats, and the nodes in listing order.
+func (s *NodeAnalysisService) snapshot(ctx context.Context, targetUtilization float64) (*dto.NodeAnalysisResponse, []*nodeUsage, error) {
+	if targetUtilization == 0 {
+		targetUtilization = DefaultConsolidationTargetUtilization
+	}
+	if targetUtilization < 0 || targetUtilization > 1 {
+		return nil, nil, ErrInvalidTargetUtilization
+	}
+	nodes, err := s.nodes.GetNodes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	end := s.now()
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: end.Add(-s.lookback), EndTime: end})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp := &dto.NodeAnalysisResponse{
+		TargetUtilization: targetUtilization,
+		Nodes:             make([]dto.NodeCostItem, 0, len(nodes)),
+		Candidates:        []dto.NodeConsolidationCandidate{},
+	}
+	byName := make(map[string]*nodeUsage, len(nodes))
+	order := make([]*nodeUsage, 0, len(nodes))
+	for _, n := range nodes {
+		// 无法解析的 allocatable 按 0 处理，该节点不参与缩容评估
+		cpu, _ := k8s.ParseQuantity(n.Allocatable["cpu"])
+		mem, _ := k8s.ParseQuantity(n.Allocatable["memory"])
+		u := &nodeUsage{item: dto.NodeCostItem{Name: n.Name}, allocCPU: cpu, allocMem: mem, pods: make(map[string]bool), podReqs: make(map[string][2]float64)}
+		byName[n.Name] = u
+		order = append(order, u)
+	}
+
+	// 只取最新一小时的统计，代表节点当前的请求与使用
+	for _, st := range stats {
+		if h := st.Timestamp.UTC().Truncate(time.Hour); h.After(resp.Timestamp) {
+			resp.Timestamp = h
+		}
+	}
+	for _, st := range stats {
+		u, ok := byName[st.NodeName]
+		if !ok || !st.Timestamp.UTC().Truncate(time.Hour).Equal(resp.Timestamp) {
+			continue
+		}
+		u.reqCPU += st.CPURequest
+		u.reqMem += float64(st.MemRequest)
+		u.item.UsedCPU += st.CPUUsageP95
+		u.item.UsedMem += st.MemUsageP95
+		pod := st.Namespace + "/" + st.PodName
+		u.pods[pod] = true
+		req := u.podReqs[pod]
+		u.podReqs[pod] = [2]float64{req[0] + st.CPURequest, req[1] + float64(st.MemRequest)}
+	}
+
+	var cluster nodeUsage
+	for _, u := range order {
+		s.fill(u)
+		resp.Nodes = append(resp.Nodes, u.item)
+		cluster.allocCPU += u.allocCPU
+		cluster.allocMem += u.allocMem
+		cluster.reqCPU += u.reqCPU
+		cluster.reqMem += u.reqMem
+		cluster.item.UsedCPU += u.item.UsedCPU
+		cluster.item.UsedMem += u.item.UsedMem
+		cluster.item.PodCount += u.item.PodCount
+	}
+	cluster.item.Name = "cluster"
+	s.fill(&cluster)
+	resp.Cluster = cluster.item
+	return resp, order, nil
+}
+
+// Pools returns the capacity and the workload costs of each node pool over start..end (default the
+// last 24 hours), largest cost first. Stats recorded without a pool are attributed to the current
+// pool of their node when it still exists, otherwise to costmodel.UnassignedNodePool.
+func (s *NodeAnalysisService) Pools(ctx context.Context, start, end time.Time) (*dto.NodePoolAnalysisResponse, error) {
+	if end.IsZero() {
+		end = s.now()
+	}
+	if start.IsZero() {
+		start = end.Add(-s.lookback)
+	}
+	if !end.After(start) {
+		return nil, ErrInvalidWindow
+	}
+	nodes, err := s.nodes.GetNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, fmt.Errorf("list hourly workload stats: %w", err)
+	}
+
+	const gib = 1 << 30
+	poolOf := make(map[string]string, len(nodes))
+	byName := make(map[string]*dto.NodePoolCostItem)
+	pool := func(name string) *dto.NodePoolCostItem {
+		p, ok := byName[name]
+		if !ok {
+			p = &dto.NodePoolCostItem{Name: name, Nodes: []string{}}
+			byName[name] = p
+		}
+		return p
+	}
+	for _, n := range nodes {
+		name := costmodel.NodePoolOf(n.Labels, s.poolLabels)
+		poolOf[n.Name] = name
+		p := pool(name)
+		cpu, _ := k8s.ParseQuantity(n.Allocatable["cpu"])
+		mem, _ := k8s.ParseQuantity(n.Allocatable["memory"])
+		p.NodeCount++
+		p.Nodes = append(p.Nodes, n.Name)
+		p.AllocatableCPU += cpu
+		p.AllocatableMem += int64(mem)
+		p.HourlyAllocatableCost += cpu*s.cpuPrice + mem/gib*s.memPrice
+	}
+
+	modelStats := make([]costmodel.HourlyWorkloadStat, 0, len(stats))
+	workloads := make(map[string]map[string]bool)
+	for _, st := range stats {
+		name := st.NodePool
+		if name == "" {
+			name = poolOf[st.NodeName]
+		}
+		if name == "" {
+			name = costmodel.UnassignedNodePool
+		}
+		if workloads[name] == nil {
+			workloads[name] = make(map[string]bool)
+		}
+		workloads[name][st.Namespace+"/"+st.WorkloadName] = true
+		modelStats = append(modelStats, costmodel.HourlyWorkloadStat{
+			Namespace:         st.Namespace,
+			WorkloadName:      st.WorkloadName,
+			WorkloadType:      st.WorkloadType,
+			NodeName:          st.NodeName,
+			PodName:           st.PodName,
+			Timestamp:         st.Timestamp,
+			CPURequest:        st.CPURequest,
+			CPUUsageP95:       st.CPUUsageP95,
+			MemRequest:        st.MemRequest,
+			MemUsageP95:       st.MemUsageP95,
+			CPUBillableCost:   st.CPUBillableCost,
+			CPUUsageCost:      st.CPUUsageCost,
+			CPUWasteCost:      st.CPUWasteCost,
+			MemBillableCost:   st.MemBillableCost,
+			MemUsageCost:      st.MemUsageCost,
+			MemWasteCost:      float64(st.MemWasteCost),
+			TotalBillableCost: st.TotalBillableCost,
+			TotalUsageCost:    st.TotalUsageCost,
+			TotalWasteCost:    st.TotalWasteCost,
+			NodePool:          name,
+		})
+	}
+	aggregated, err := costmodel.AggregateByNodePool(modelStats, aggregateOptions(ctx, s.nonFinite, nil)...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate by node pool: %w", err)
+	}
+
+	resp := &dto.NodePoolAnalysisResponse{StartTime: start, EndTime: end, Pools: make([]dto.NodePoolCostItem, 0, len(byName))}
+	for name, agg := range aggregated {
+		p := pool(name)
+		p.BillableCost = agg.TotalBillableCost
+		p.UsageCost = agg.TotalUsageCost
+		p.WasteCost = agg.TotalWasteCost
+		p.EfficiencyScore = agg.EfficiencyScore
+		p.WorkloadCount = len(workloads[name])
+		resp.TotalBillableCost += agg.TotalBillableCost
+	}
+	resp.TotalBillableCost = math.Round(resp.TotalBillableCost*100) / 100
+	for _, p := range byName {
+		if resp.TotalBillableCost > 0 {
+			p.CostPercentage = math.Round(p.BillableCost/resp.TotalBillableCost*10000) / 100
+		}
+		sort.Strings(p.Nodes)
+		resp.Pools = append(resp.Pools, *p)
+	}
+	sort.Slice(resp.Pools, func(i, j int) bool {
+		if resp.Pools[i].BillableCost != resp.Pools[j].BillableCost {
+			return resp.Pools[i].BillableCost > resp.Pools[j].BillableCost
+		}
+		return resp.Pools[i].Name < resp.Pools[j].Name
+	})
+	return resp, nil
+}
+
+// fill derives the cost and ratio fields of u.item from its base-unit totals.
+func (s *NodeAnalysisService) fill(u *nodeUsage) {
+	const gib = 1 << 30
+	u.item.AllocatableCPU = u.allocCPU
+	u.item.AllocatableMem = int64(u.allocMem)
+	u.item.RequestedCPU = u.reqCPU
+	u.item.RequestedMem = int64(u.reqMem)
+	if u.pods != nil {
+		u.item.PodCount = len(u.pods)
+	}
+	u.item.AllocatableCost = u.allocCPU*s.cpuPrice + u.allocMem/gib*s.memPrice
+	u.item.RequestedCost = u.reqCPU*s.cpuPrice + u.reqMem/gib*s.memPrice
+	u.item.UsedCost = u.item.UsedCPU*s.cpuPrice + float64(u.item.UsedMem)/gib*s.memPrice
+	u.item.UnallocatedCost = u.item.AllocatableCost - u.item.RequestedCost
+	if u.allocCPU > 0 {
+		u.item.CPURequestRatio = u.reqCPU / u.allocCPU * 100
+	}
+	if u.allocMem > 0 {
+		u.item.MemRequestRatio = u.reqMem / u.allocMem * 100
+	}
+	u.item.BinPackingEfficiency = u.item.CPURequestRatio
+	if u.item.MemRequestRatio > u.item.BinPackingEfficiency {
+		u.item.BinPackingEfficiency = u.item.MemRequestRatio
+	}
+}
+
+// consolidationCandidates drains nodes greedily from the emptiest while the kept nodes can absorb
+// them. Nodes in skip are never drained but keep absorbing.
+func consolidationCandidates(nodes []*nodeUsage, target float64, skip map[string]bool) []dto.NodeConsolidationCandidate {
+	sorted := make([]*nodeUsage, 0, len(nodes))
+	for _, u := range nodes {
+		if u.allocCPU > 0 && u.allocMem > 0 {
+			sorted = append(sorted, u)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].item.BinPackingEfficiency != sorted[j].item.BinPackingEfficiency {
+			return sorted[i].item.BinPackingEfficiency < sorted[j].item.BinPackingEfficiency
+		}
+		return sorted[i].item.Name < sorted[j].item.Name
+	})
+
+	drained := make(map[*nodeUsage]bool)
+	var movedCPU, movedMem float64
+	candidates := []dto.NodeConsolidationCandidate{}
+	for _, n := range sorted {
+		if skip[n.item.Name] {
+			continue
+		}
+		var headCPU, headMem float64
+		kept := 0
+		for _, o := range sorted {
+			if o == n || drained[o] {
+				continue
+			}
+			kept++
+			if h := o.allocCPU*target - o.reqCPU; h > 0 {
+				headCPU += h
+			}
+			if h := o.allocMem*target - o.reqMem; h > 0 {
+				headMem += h
+			}
+		}
+		if kept == 0 || headCPU < movedCPU+n.reqCPU || headMem < movedMem+n.reqMem {
+			continue
+		}
+		drained[n] = true
+		movedCPU += n.reqCPU
+		movedMem += n.reqMem
+		candidates = append(candidates, dto.NodeConsolidationCandidate{
+			Rank:                    len(candidates) + 1,
+			Name:                    n.item.Name,
+			PodCount:                n.item.PodCount,
+			RequestedCPU:            n.item.RequestedCPU,
+			RequestedMem:            n.item.RequestedMem,
+			BinPackingEfficiency:    n.item.BinPackingEfficiency,
+			HourlyCost:              n.item.AllocatableCost,
+			EstimatedMonthlySavings: n.item.AllocatableCost * costmodel.HoursPerMonth,
+		})
+	}
+	return candidates
+}
@@ -0,0 +1,320 @@
+// Package service notification_policy.go: 通知投递前的路由与静默——路由规则按告警的级别、团队、namespace 等标签
+// 选择渠道，静默在有效期内屏蔽匹配的告警并记录原因，避免启用告警后值班频道被淹没。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+const maxSilenceDuration = 30 * 24 * time.Hour
+
+// ErrInvalidNotificationPolicy is returned for an invalid routing rule or silence request.
+var ErrInvalidNotificationPolicy = dataerr.Validation("invalid notification route or silence")
+
+// NotificationPolicyStore persists notification routes and silences. *postgres.MockRepository
+// satisfies this interface.
+type NotificationPolicyStore interface {
+	SaveNotificationRoute(ctx context.Context, route postgres.NotificationRoute) (postgres.NotificationRoute, error)
+	GetNotificationRoute(ctx context.Context, id string) (*postgres.NotificationRoute, error)
+	ListNotificationRoutes(ctx context.Context) ([]postgres.NotificationRoute, error)
+	DeleteNotificationRoute(ctx context.Context, id string) error
+	SaveNotificationSilence(ctx context.Context, silence postgres.NotificationSilence) (postgres.NotificationSilence, error)
+	GetNotificationSilence(ctx context.Context, id string) (*postgres.NotificationSilence, error)
+	ListNotificationSilences(ctx context.Context, activeAt time.Time) ([]postgres.NotificationSilence, error)
+}
+
+// NotificationPolicyService manages notification routes and silences and applies them to alerts
+// before dispatch (it implements notifier.Filter).
+type NotificationPolicyService struct {
+	store NotificationPolicyStore
+	teams map[string][]string // team -> namespaces
+	now   func() time.Time
+}
+
+// NewNotificationPolicyService creates a NotificationPolicyService.
+func NewNotificationPolicyService(store NotificationPolicyStore) *NotificationPolicyService {
+	return &NotificationPolicyService{store: store, now: time.Now}
+}
+
+// SetTeams labels alerts of a team's namespaces with the team, so routes and silences can match it.
+func (s *NotificationPolicyService) SetTeams(teams map[string][]string) {
+	s.teams = teams
+}
+
+// Filter implements notifier.Filter: an alert matching an active silence returns an error wrapping
+// notifier.ErrSilenced; otherwise an alert without channels of its own is sent to the channels of
+// the first matching route (by priority), or to the routes of its type when none matches.
+func (s *NotificationPolicyService) Filter(ctx context.Context, alert notifier.Alert) (notifier.Alert, error) {
+	labels := notifier.AlertLabels(alert)
+	if _, ok := labels[notifier.LabelTeam]; !ok {
+		if team := s.teamOf(labels[notifier.LabelNamespace]); team != "" {
+			labels[notifier.LabelTeam] = team
+			alert.Labels = copyLabels(alert.Labels)
+			alert.Labels[notifier.LabelTeam] = team
+		}
+	}
+	silences, err := s.store.ListNotificationSilences(ctx, s.now())
+	if err != nil {
+		return alert, fmt.Errorf("list notification silences: %w", err)
+	}
+	for _, sl := range silences {
+		if notifier.MatchLabels(sl.Matchers, labels) {
+			return alert, fmt.Errorf("%w by %s until %s: %s", notifier.ErrSilenced, sl.ID, sl.EndsAt.UTC().Format(time.RFC3339), sl.Reason)
+		}
+	}
+	if len(alert.Channels) > 0 {
+		return alert, nil
+	}
+	routes, err := s.store.ListNotificationRoutes(ctx)
+	if err != nil {
+		return alert, fmt.Errorf("list notification routes: %w", err)
+	}
+	for _, r := range routes {
+		if notifier.MatchLabels(r.Matchers, labels) {
+			alert.Channels = append([]string{}, r.Channels...)
+			break
+		}
+	}
+	return alert, nil
+}
+
+// CreateRoute validates and stores a routing rule.
+func (s *NotificationPolicyService) CreateRoute(ctx context.Context, req dto.NotificationRouteRequest) (*dto.NotificationRoute, error) {
+	route, err := s.routeOf(ctx, req, "")
+	if err != nil {
+		return nil, err
+	}
+	route.CreatedBy = req.CreatedBy
+	route.CreatedAt = s.now().UTC()
+	route.UpdatedAt = route.CreatedAt
+	saved, err := s.store.SaveNotificationRoute(ctx, route)
+	if err != nil {
+		return nil, fmt.Errorf("save notification route: %w", err)
+	}
+	return toDTONotificationRoute(saved), nil
+}
+
+// ListRoutes returns the routing rules in matching order.
+func (s *NotificationPolicyService) ListRoutes(ctx context.Context) (*dto.NotificationRouteListResponse, error) {
+	routes, err := s.store.ListNotificationRoutes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list notification routes: %w", err)
+	}
+	resp := &dto.NotificationRouteListResponse{Routes: make([]dto.NotificationRoute, 0, len(routes)), Total: len(routes)}
+	for _, r := range routes {
+		resp.Routes = append(resp.Routes, *toDTONotificationRoute(r))
+	}
+	return resp, nil
+}
+
+// GetRoute returns a routing rule.
+func (s *NotificationPolicyService) GetRoute(ctx context.Context, id string) (*dto.NotificationRoute, error) {
+	route, err := s.store.GetNotificationRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toDTONotificationRoute(*route), nil
+}
+
+// UpdateRoute replaces a routing rule.
+func (s *NotificationPolicyService) UpdateRoute(ctx context.Context, id string, req dto.NotificationRouteRequest) (*dto.NotificationRoute, error) {
+	existing, err := s.store.GetNotificationRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	route, err := s.routeOf(ctx, req, id)
+	if err != nil {
+		return nil, err
+	}
+	route.ID, route.CreatedBy, route.CreatedAt = existing.ID, existing.CreatedBy, existing.CreatedAt
+	route.UpdatedAt = s.now().UTC()
+	saved, err := s.store.SaveNotificationRoute(ctx, route)
+	if err != nil {
+		return nil, fmt.Errorf("save notification route: %w", err)
+	}
+	return toDTONotificationRoute(saved), nil
+}
+
+// DeleteRoute deletes a routing rule.
+func (s *NotificationPolicyService) DeleteRoute(ctx context.Context, id string) error {
+	return s.store.DeleteNotificationRoute(ctx, id)
+}
+
+// CreateSilence validates and stores a silence.
+func (s *NotificationPolicyService) CreateSilence(ctx context.Context, req dto.CreateSilenceRequest) (*dto.Silence, error) {
+	if err := checkMatchers(req.Matchers); err != nil {
+		return nil, err
+	}
+	if len(req.Matchers) == 0 {
+		return nil, fmt.Errorf("%w: a silence needs at least one matcher", ErrInvalidNotificationPolicy)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidNotificationPolicy)
+	}
+	now := s.now().UTC()
+	start := now
+	if req.StartsAt != nil {
+		start = req.StartsAt.UTC()
+	}
+	var end time.Time
+	switch {
+	case req.EndsAt != nil && req.Duration != "":
+		return nil, fmt.Errorf("%w: set ends_at or duration, not both", ErrInvalidNotificationPolicy)
+	case req.EndsAt != nil:
+		end = req.EndsAt.UTC()
+	case req.Duration != "":
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid duration %q", ErrInvalidNotificationPolicy, req.Duration)
+		}
+		end = start.Add(d)
+	default:
+		return nil, fmt.Errorf("%w: ends_at or duration is required", ErrInvalidNotificationPolicy)
+	}
+	if !end.After(start) || !end.After(now) || end.Sub(start) > maxSilenceDuration {
+		return nil, fmt.Errorf("%w: a silence must end in the future, after it starts and within %s", ErrInvalidNotificationPolicy, maxSilenceDuration)
+	}
+	saved, err := s.store.SaveNotificationSilence(ctx, postgres.NotificationSilence{
+		Matchers: req.Matchers, StartsAt: start, EndsAt: end, Reason: reason, CreatedBy: req.CreatedBy, CreatedAt: now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save notification silence: %w", err)
+	}
+	return s.toDTOSilence(saved), nil
+}
+
+// ListSilences returns the active and pending silences, soonest end first; all includes the expired ones.
+func (s *NotificationPolicyService) ListSilences(ctx context.Context, all bool) (*dto.SilenceListResponse, error) {
+	silences, err := s.store.ListNotificationSilences(ctx, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("list notification silences: %w", err)
+	}
+	now := s.now()
+	resp := &dto.SilenceListResponse{Silences: []dto.Silence{}}
+	for _, sl := range silences {
+		if all || now.Before(sl.EndsAt) {
+			resp.Silences = append(resp.Silences, *s.toDTOSilence(sl))
+		}
+	}
+	resp.Total = len(resp.Silences)
+	return resp, nil
+}
+
+// ExpireSilence ends a silence now; expiring an ended silence changes nothing.
+func (s *NotificationPolicyService) ExpireSilence(ctx context.Context, id, expiredBy string) (*dto.Silence, error) {
+	silence, err := s.store.GetNotificationSilence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if now.Before(silence.EndsAt) {
+		silence.EndsAt, silence.ExpiredBy = now, expiredBy
+		if silence.StartsAt.After(now) {
+			silence.StartsAt = now
+		}
+		if *silence, err = s.store.SaveNotificationSilence(ctx, *silence); err != nil {
+			return nil, fmt.Errorf("save notification silence: %w", err)
+		}
+	}
+	return s.toDTOSilence(*silence), nil
+}
+
+func (s *NotificationPolicyService) routeOf(ctx context.Context, req dto.NotificationRouteRequest, id string) (postgres.NotificationRoute, error) {
+	route := postgres.NotificationRoute{Name: strings.TrimSpace(req.Name), Matchers: req.Matchers, Channels: req.Channels, Priority: req.Priority}
+	if route.Name == "" {
+		return route, fmt.Errorf("%w: name is required", ErrInvalidNotificationPolicy)
+	}
+	if err := checkMatchers(route.Matchers); err != nil {
+		return route, err
+	}
+	if len(route.Channels) == 0 {
+		return route, fmt.Errorf("%w: at least one channel is required", ErrInvalidNotificationPolicy)
+	}
+	for _, ch := range route.Channels {
+		if !containsString(alertChannels, ch) {
+			return route, fmt.Errorf("%w: unknown channel %q (want one of %s)", ErrInvalidNotificationPolicy, ch, strings.Join(alertChannels, ", "))
+		}
+	}
+	routes, err := s.store.ListNotificationRoutes(ctx)
+	if err != nil {
+		return route, fmt.Errorf("list notification routes: %w", err)
+	}
+	for _, r := range routes {
+		if r.Name == route.Name && r.ID != id {
+			return route, dataerr.Conflict("notification route %q already exists", route.Name)
+		}
+	}
+	return route, nil
+}
+
+func (s *NotificationPolicyService) teamOf(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	names := make([]string, 0, len(s.teams))
+	for name := range s.teams {
+		names = append(names, name)
+	}
+	sort.Strings(names) // 一个 namespace 配置在多个团队时结果稳定
+	for _, name := range names {
+		if containsString(s.teams[name], namespace) {
+			return name
+		}
+	}
+	return ""
+}
+
+func (s *NotificationPolicyService) toDTOSilence(sl postgres.NotificationSilence) *dto.Silence {
+	now := s.now()
+	return &dto.Silence{
+		ID:        sl.ID,
+		Matchers:  copyLabels(sl.Matchers),
+		StartsAt:  sl.StartsAt,
+		EndsAt:    sl.EndsAt,
+		Reason:    sl.Reason,
+		CreatedBy: sl.CreatedBy,
+		CreatedAt: sl.CreatedAt,
+		ExpiredBy: sl.ExpiredBy,
+		Active:    !now.Before(sl.StartsAt) && now.Before(sl.EndsAt),
+	}
+}
+
+func checkMatchers(matchers map[string]string) error {
+	for k, v := range matchers {
+		if strings.TrimSpace(k) == "" || v == "" {
+			return fmt.Errorf("%w: matcher names and values must not be empty (use * to require a label)", ErrInvalidNotificationPolicy)
+		}
+	}
+	return nil
+}
+
+func copyLabels(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in)+1)
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+func toDTONotificationRoute(r postgres.NotificationRoute) *dto.NotificationRoute {
+	return &dto.NotificationRoute{
+		ID:        r.ID,
+		Name:      r.Name,
+		Matchers:  copyLabels(r.Matchers),
+		Channels:  append([]string{}, r.Channels...),
+		Priority:  r.Priority,
+		CreatedBy: r.CreatedBy,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
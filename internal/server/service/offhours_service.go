@@ -0,0 +1,164 @@
+// Package service offhours_service.go: 工作时间 vs 夜间/周末的小时成本拆分，统计非工作时间近乎空闲时
+// 仍在计费的成本，并给出缩容到零或定时伸缩的候选。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+const (
+	// 默认一周，工作日与周末都能覆盖到
+	defaultOffHoursDays = 7
+	maxOffHoursDays     = 90
+)
+
+// ErrInvalidOffHoursQuery is returned for out-of-range days or limit.
+var ErrInvalidOffHoursQuery = dataerr.Validation("invalid off-hours analysis query")
+
+// OffHoursQuery parameterizes an off-hours analysis. Zero values use the defaults: the last 7 days
+// of all namespaces and all workloads.
+type OffHoursQuery struct {
+	Days      int
+	Namespace string
+	Limit     int
+}
+
+// OffHoursService analyzes hourly workload stats by business hours vs nights and weekends.
+type OffHoursService struct {
+	repo            postgres.Repository
+	hours           costmodel.BusinessHours
+	idleUtilization float64
+	now             func() time.Time
+}
+
+// NewOffHoursService creates an OffHoursService. idleUtilization <= 0 uses
+// costmodel.DefaultIdleUtilization.
+func NewOffHoursService(repo postgres.Repository, hours costmodel.BusinessHours, idleUtilization float64) *OffHoursService {
+	if idleUtilization <= 0 {
+		idleUtilization = costmodel.DefaultIdleUtilization
+	}
+	if hours.Location == nil {
+		hours.Location = time.UTC
+	}
+	return &OffHoursService{repo: repo, hours: hours, idleUtilization: idleUtilization, now: time.Now}
+}
+
+// workloadHour accumulates the pods of one workload in one hour.
+type workloadHour struct {
+	cpuRequest, cpuUsage float64
+	memRequest, memUsage int64
+	billable             float64
+}
+
+// Analyze profiles every workload over the last q.Days days. Pods of a workload are summed per
+// hour before the utilization of the hour is taken, so one busy replica keeps the hour active.
+func (s *OffHoursService) Analyze(ctx context.Context, q OffHoursQuery) (*dto.OffHoursAnalysisResponse, error) {
+	if q.Days == 0 {
+		q.Days = defaultOffHoursDays
+	}
+	if q.Days < 0 || q.Days > maxOffHoursDays {
+		return nil, fmt.Errorf("%w: days must be in 1..%d", ErrInvalidOffHoursQuery, maxOffHoursDays)
+	}
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("%w: limit must not be negative", ErrInvalidOffHoursQuery)
+	}
+	end := s.now().UTC()
+	start := end.AddDate(0, 0, -q.Days)
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: q.Namespace, StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, fmt.Errorf("list hourly workload stats: %w", err)
+	}
+
+	type workloadKey struct{ namespace, name string }
+	hours := make(map[workloadKey]map[time.Time]*workloadHour)
+	types := make(map[workloadKey]string)
+	for _, st := range stats {
+		k := workloadKey{st.Namespace, st.WorkloadName}
+		if hours[k] == nil {
+			hours[k] = make(map[time.Time]*workloadHour)
+			types[k] = st.WorkloadType
+		}
+		ts := st.Timestamp.UTC().Truncate(time.Hour)
+		h := hours[k][ts]
+		if h == nil {
+			h = &workloadHour{}
+			hours[k][ts] = h
+		}
+		h.cpuRequest += st.CPURequest
+		h.cpuUsage += st.CPUUsageP95
+		h.memRequest += st.MemRequest
+		h.memUsage += st.MemUsageP95
+		h.billable += st.TotalBillableCost
+	}
+
+	resp := &dto.OffHoursAnalysisResponse{
+		WindowStart:     start,
+		WindowEnd:       end,
+		BusinessHours:   dto.BusinessHoursState{Timezone: s.hours.Location.String(), StartHour: s.hours.StartHour, EndHour: s.hours.EndHour},
+		IdleUtilization: s.idleUtilization,
+		Workloads:       make([]dto.OffHoursWorkload, 0, len(hours)),
+	}
+	var total float64
+	for k, byHour := range hours {
+		samples := make([]costmodel.HourSample, 0, len(byHour))
+		for ts, h := range byHour {
+			samples = append(samples, costmodel.HourSample{
+				Timestamp:    ts,
+				BillableCost: h.billable,
+				Utilization:  costmodel.HourUtilization(h.cpuRequest, h.cpuUsage, h.memRequest, h.memUsage),
+			})
+		}
+		w := dto.OffHoursWorkload{
+			Namespace:    k.namespace,
+			WorkloadName: k.name,
+			WorkloadType: types[k],
+			Profile:      costmodel.AnalyzeOffHours(samples, s.hours, s.idleUtilization),
+		}
+		if rec, ok := costmodel.RecommendOffHours(w.Profile); ok {
+			w.Recommendation = &rec
+			resp.Summary.Candidates++
+			resp.Summary.EstimatedMonthlySavings += rec.EstimatedMonthlySavings
+		}
+		resp.Summary.BusinessCost += w.Profile.BusinessCost
+		resp.Summary.OffHoursCost += w.Profile.OffHoursCost
+		resp.Summary.IdleOffHoursCost += w.Profile.IdleOffHoursCost
+		total += w.Profile.BusinessCost + w.Profile.OffHoursCost
+		resp.Workloads = append(resp.Workloads, w)
+	}
+	if total > 0 {
+		resp.Summary.IdleOffHoursCostShare = roundCost(resp.Summary.IdleOffHoursCost / total * 100)
+	}
+	resp.Summary.BusinessCost = roundCost(resp.Summary.BusinessCost)
+	resp.Summary.OffHoursCost = roundCost(resp.Summary.OffHoursCost)
+	resp.Summary.IdleOffHoursCost = roundCost(resp.Summary.IdleOffHoursCost)
+	resp.Summary.EstimatedMonthlySavings = roundCost(resp.Summary.EstimatedMonthlySavings)
+
+	sort.Slice(resp.Workloads, func(i, j int) bool {
+		a, b := resp.Workloads[i], resp.Workloads[j]
+		if a.Profile.IdleOffHoursCost != b.Profile.IdleOffHoursCost {
+			return a.Profile.IdleOffHoursCost > b.Profile.IdleOffHoursCost
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.WorkloadName < b.WorkloadName
+	})
+	if q.Limit > 0 && len(resp.Workloads) > q.Limit {
+		resp.Workloads = resp.Workloads[:q.Limit]
+	}
+	resp.Total = len(resp.Workloads)
+	return resp, nil
+}
+
+func roundCost(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,454 @@
+// Package service onboarding_service.go: namespace 自助接入——一次调用完成归属映射、默认预算告警、SLO 告警模板、
+// 周报订阅与效率目标跟踪。接入记录保存在 metadata（namespace_onboarding/<namespace>），重复接入按新请求收敛。
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// onboardingKeyPrefix is the metadata key prefix of the onboarding records: namespace_onboarding/<namespace>.
+const onboardingKeyPrefix = "namespace_onboarding/"
+
+const (
+	defaultOnboardingTarget       = 60
+	defaultOnboardingAvailability = 99.9
+	// onboardingBudgetWindow approximates a month with the rolling window of the budget rule.
+	onboardingBudgetWindow = "720h"
+)
+
+var (
+	// ErrInvalidOnboarding is returned for an invalid onboarding request.
+	ErrInvalidOnboarding = dataerr.Validation("invalid onboarding request")
+	// ErrOnboardingNotFound is returned for a namespace that was never onboarded.
+	ErrOnboardingNotFound = dataerr.NotFound("namespace onboarding not found")
+)
+
+// namespacePattern is a Kubernetes namespace name (RFC 1123 label).
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// sloTemplate is an SLO alert rule created for onboarded namespaces.
+type sloTemplate struct {
+	name        string
+	description string
+	metric      string
+	severity    notifier.Severity
+	threshold   func(s *OnboardingService) float64
+}
+
+// sloTemplates are the SLO alert templates, by name.
+var sloTemplates = []sloTemplate{
+	{name: "availability", description: "availability below the SLO threshold", metric: "slo_availability",
+		severity: notifier.SeverityWarning, threshold: func(s *OnboardingService) float64 { return s.availability }},
+	{name: "error_budget", description: "less than 20% of the error budget left", metric: "slo_error_budget_remaining",
+		severity: notifier.SeverityCritical, threshold: func(*OnboardingService) float64 { return 20 }},
+}
+
+// OnboardingDefaults are the settings of an onboarding request that omits them.
+type OnboardingDefaults struct {
+	MonthlyBudget    float64  // <= 0: no budget alert
+	EfficiencyTarget float64  // 0: 60
+	SLOTemplates     []string // nil: all templates
+}
+
+// OnboardingService onboards namespaces: it records the owner team, owners and report recipients,
+// keeps a budget alert rule and the SLO template rules per namespace and sets the efficiency target
+// that enables target tracking. It resolves the report recipients of onboarded namespaces
+// (notifier.OwnershipResolver), falling back to the configured owners.
+type OnboardingService struct {
+	repo         postgres.Repository
+	targets      *EfficiencyTargetService
+	alerts       *AlertRuleService // nil: the budget and SLO steps are skipped
+	fallback     notifier.OwnershipResolver
+	defaults     OnboardingDefaults
+	availability float64
+	now          func() time.Time
+}
+
+// NewOnboardingService creates an OnboardingService storing its records in repo.
+func NewOnboardingService(repo postgres.Repository, targets *EfficiencyTargetService) *OnboardingService {
+	return &OnboardingService{repo: repo, targets: targets, availability: defaultOnboardingAvailability, now: time.Now}
+}
+
+// SetAlertRules enables the budget and SLO steps.
+func (s *OnboardingService) SetAlertRules(alerts *AlertRuleService) {
+	s.alerts = alerts
+}
+
+// SetDefaults sets the settings of requests that omit them; unknown SLO templates are rejected.
+func (s *OnboardingService) SetDefaults(d OnboardingDefaults) error {
+	if d.EfficiencyTarget < 0 || d.EfficiencyTarget > 100 {
+		return fmt.Errorf("%w: default efficiency target must be between 0 and 100", ErrInvalidOnboarding)
+	}
+	for _, name := range d.SLOTemplates {
+		if _, ok := sloTemplateOf(name); !ok {
+			return fmt.Errorf("%w: unknown SLO template %q", ErrInvalidOnboarding, name)
+		}
+	}
+	s.defaults = d
+	return nil
+}
+
+// SetAvailabilityThreshold sets the availability (%) of the availability template (default 99.9).
+func (s *OnboardingService) SetAvailabilityThreshold(pct float64) {
+	if pct > 0 && pct <= 100 {
+		s.availability = pct
+	}
+}
+
+// SetFallbackOwnership resolves the report recipients of namespaces that were not onboarded.
+func (s *OnboardingService) SetFallbackOwnership(owners notifier.OwnershipResolver) {
+	s.fallback = owners
+}
+
+// Onboard sets up namespace as requested by actor and records the onboarding. Steps are idempotent:
+// when one fails, the error is returned and onboarding again completes the remaining ones.
+func (s *OnboardingService) Onboard(ctx context.Context, req dto.OnboardNamespaceRequest, actor string) (*dto.NamespaceOnboarding, error) {
+	rec, err := s.recordOf(req)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.load(ctx, rec.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	rec.OnboardedBy, rec.OnboardedAt, rec.UpdatedAt = actor, now, now
+	status := dto.OnboardingStatusCreated
+	if previous != nil {
+		rec.OnboardedBy, rec.OnboardedAt = previous.OnboardedBy, previous.OnboardedAt
+		status = dto.OnboardingStatusUpdated
+	}
+	rec.Steps = []dto.OnboardingStep{{Step: dto.OnboardingStepOwnership, Status: status,
+		Detail: fmt.Sprintf("team %s, %d owner(s)", rec.Team, len(rec.Owners))}}
+
+	ruleSteps, err := s.syncAlertRules(ctx, rec, actor)
+	if err != nil {
+		return nil, err
+	}
+	rec.Steps = append(rec.Steps, ruleSteps...)
+
+	reports := dto.OnboardingStep{Step: dto.OnboardingStepReports, Status: status,
+		Detail: fmt.Sprintf("weekly report to %d recipient(s)", len(rec.ReportRecipients))}
+	if len(rec.ReportRecipients) == 0 {
+		reports.Status, reports.Detail = dto.OnboardingStatusSkipped, "no recipients"
+	}
+	rec.Steps = append(rec.Steps, reports)
+
+	tracking, err := s.enableTracking(ctx, rec, actor)
+	if err != nil {
+		return nil, err
+	}
+	rec.Steps = append(rec.Steps, tracking)
+
+	if err := s.save(ctx, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Get returns the onboarding of namespace.
+func (s *OnboardingService) Get(ctx context.Context, namespace string) (*dto.NamespaceOnboarding, error) {
+	rec, err := s.load(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("%w: %s", ErrOnboardingNotFound, namespace)
+	}
+	return rec, nil
+}
+
+// List returns the onboarded namespaces sorted by namespace.
+func (s *OnboardingService) List(ctx context.Context) ([]dto.NamespaceOnboarding, error) {
+	list, err := s.repo.ListMetadata(ctx, postgres.MetadataFilter{KeyPrefix: onboardingKeyPrefix})
+	if err != nil {
+		return nil, fmt.Errorf("list namespace onboardings: %w", err)
+	}
+	out := make([]dto.NamespaceOnboarding, 0, len(list))
+	for _, m := range list {
+		rec, err := decodeOnboarding(m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out, nil
+}
+
+// OwnersFor implements notifier.OwnershipResolver: the report recipients of an onboarded namespace,
+// otherwise those of the fallback resolver.
+func (s *OnboardingService) OwnersFor(ctx context.Context, namespace string) ([]string, error) {
+	rec, err := s.load(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil && len(rec.ReportRecipients) > 0 {
+		return rec.ReportRecipients, nil
+	}
+	if s.fallback == nil {
+		return nil, nil
+	}
+	return s.fallback.OwnersFor(ctx, namespace)
+}
+
+// recordOf validates req and fills in the defaults.
+func (s *OnboardingService) recordOf(req dto.OnboardNamespaceRequest) (*dto.NamespaceOnboarding, error) {
+	rec := &dto.NamespaceOnboarding{
+		Namespace:        strings.TrimSpace(req.Namespace),
+		Team:             strings.TrimSpace(req.Team),
+		Owners:           trimmedStrings(req.Owners),
+		MonthlyBudget:    s.defaults.MonthlyBudget,
+		SLOTemplates:     req.SLOTemplates,
+		ReportRecipients: trimmedStrings(req.ReportRecipients),
+		EfficiencyTarget: s.defaults.EfficiencyTarget,
+		Channels:         req.Channels,
+	}
+	if !namespacePattern.MatchString(rec.Namespace) {
+		return nil, fmt.Errorf("%w: namespace %q is not a valid Kubernetes namespace name", ErrInvalidOnboarding, req.Namespace)
+	}
+	if rec.Team == "" {
+		return nil, fmt.Errorf("%w: team is required", ErrInvalidOnboarding)
+	}
+	if req.MonthlyBudget != nil {
+		rec.MonthlyBudget = *req.MonthlyBudget
+	}
+	if rec.MonthlyBudget < 0 {
+		return nil, fmt.Errorf("%w: monthly_budget must not be negative", ErrInvalidOnboarding)
+	}
+	if req.EfficiencyTarget != nil {
+		rec.EfficiencyTarget = *req.EfficiencyTarget
+	}
+	if rec.EfficiencyTarget == 0 {
+		rec.EfficiencyTarget = defaultOnboardingTarget
+	}
+	if rec.EfficiencyTarget < 0 || rec.EfficiencyTarget > 100 {
+		return nil, fmt.Errorf("%w: efficiency_target must be between 0 and 100", ErrInvalidOnboarding)
+	}
+	if rec.SLOTemplates == nil {
+		rec.SLOTemplates = s.defaults.SLOTemplates
+		if rec.SLOTemplates == nil {
+			for _, t := range sloTemplates {
+				rec.SLOTemplates = append(rec.SLOTemplates, t.name)
+			}
+		}
+	}
+	for _, name := range rec.SLOTemplates {
+		if _, ok := sloTemplateOf(name); !ok {
+			return nil, fmt.Errorf("%w: unknown SLO template %q", ErrInvalidOnboarding, name)
+		}
+	}
+	if req.ReportRecipients == nil {
+		rec.ReportRecipients = rec.Owners
+	}
+	for _, addr := range append(append([]string(nil), rec.Owners...), rec.ReportRecipients...) {
+		if !strings.Contains(addr, "@") {
+			return nil, fmt.Errorf("%w: %q is not an e-mail address", ErrInvalidOnboarding, addr)
+		}
+	}
+	for _, ch := range rec.Channels {
+		if !containsString(alertChannels, ch) {
+			return nil, fmt.Errorf("%w: unknown channel %q (want one of %s)", ErrInvalidOnboarding, ch, strings.Join(alertChannels, ", "))
+		}
+	}
+	if rec.Owners == nil {
+		rec.Owners = []string{}
+	}
+	if rec.ReportRecipients == nil {
+		rec.ReportRecipients = []string{}
+	}
+	if rec.SLOTemplates == nil {
+		rec.SLOTemplates = []string{}
+	}
+	return rec, nil
+}
+
+// syncAlertRules creates or updates the budget and SLO template rules of rec and deletes the ones
+// no longer requested. Rules are found by name, onboarding/<namespace>/<rule>.
+func (s *OnboardingService) syncAlertRules(ctx context.Context, rec *dto.NamespaceOnboarding, actor string) ([]dto.OnboardingStep, error) {
+	if s.alerts == nil {
+		return []dto.OnboardingStep{
+			{Step: dto.OnboardingStepBudget, Status: dto.OnboardingStatusSkipped, Detail: "alert rules are not configured"},
+			{Step: dto.OnboardingStepSLO, Status: dto.OnboardingStatusSkipped, Detail: "alert rules are not configured"},
+		}, nil
+	}
+	existing, err := s.alerts.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]dto.AlertRule, len(existing.Rules))
+	for _, r := range existing.Rules {
+		byName[r.Name] = r
+	}
+	prefix := "onboarding/" + rec.Namespace + "/"
+
+	budget := dto.OnboardingStep{Step: dto.OnboardingStepBudget, Status: dto.OnboardingStatusSkipped, Detail: "no monthly budget"}
+	if rec.MonthlyBudget > 0 {
+		req := dto.AlertRuleRequest{
+			Name:        prefix + "budget",
+			Description: fmt.Sprintf("cost of namespace %s over its monthly budget", rec.Namespace),
+			Condition:   fmt.Sprintf("cost{namespace=%q} > %g", rec.Namespace, rec.MonthlyBudget),
+			Window:      onboardingBudgetWindow,
+			AlertType:   string(notifier.AlertTypeBudgetBreach),
+			Channels:    rec.Channels,
+			CreatedBy:   actor,
+		}
+		if budget, err = s.putRule(ctx, dto.OnboardingStepBudget, byName, req); err != nil {
+			return nil, err
+		}
+		budget.Detail = fmt.Sprintf("30-day cost above %g", rec.MonthlyBudget)
+	} else if rule, ok := byName[prefix+"budget"]; ok {
+		if err := s.alerts.Delete(ctx, rule.ID); err != nil {
+			return nil, fmt.Errorf("delete budget rule of %s: %w", rec.Namespace, err)
+		}
+		budget = dto.OnboardingStep{Step: dto.OnboardingStepBudget, Status: dto.OnboardingStatusRemoved, ResourceID: rule.ID}
+	}
+	steps := []dto.OnboardingStep{budget}
+
+	for _, t := range sloTemplates {
+		name := prefix + "slo-" + t.name
+		if !containsString(rec.SLOTemplates, t.name) {
+			if rule, ok := byName[name]; ok {
+				if err := s.alerts.Delete(ctx, rule.ID); err != nil {
+					return nil, fmt.Errorf("delete SLO rule %s: %w", name, err)
+				}
+				steps = append(steps, dto.OnboardingStep{Step: dto.OnboardingStepSLO, Status: dto.OnboardingStatusRemoved, ResourceID: rule.ID, Detail: t.name})
+			}
+			continue
+		}
+		req := dto.AlertRuleRequest{
+			Name:        name,
+			Description: fmt.Sprintf("namespace %s: %s", rec.Namespace, t.description),
+			Condition:   fmt.Sprintf("%s{namespace=%q} < %g", t.metric, rec.Namespace, t.threshold(s)),
+			Severity:    string(t.severity),
+			AlertType:   string(notifier.AlertTypeSLOViolation),
+			Channels:    rec.Channels,
+			CreatedBy:   actor,
+		}
+		step, err := s.putRule(ctx, dto.OnboardingStepSLO, byName, req)
+		if err != nil {
+			return nil, err
+		}
+		step.Detail = t.name
+		steps = append(steps, step)
+	}
+	if len(steps) == 1 {
+		steps = append(steps, dto.OnboardingStep{Step: dto.OnboardingStepSLO, Status: dto.OnboardingStatusSkipped, Detail: "no SLO templates"})
+	}
+	return steps, nil
+}
+
+// putRule creates the alert rule req, or updates the existing rule of the same name.
+func (s *OnboardingService) putRule(ctx context.Context, step string, byName map[string]dto.AlertRule, req dto.AlertRuleRequest) (dto.OnboardingStep, error) {
+	if rule, ok := byName[req.Name]; ok {
+		updated, err := s.alerts.Update(ctx, rule.ID, req)
+		if err != nil {
+			return dto.OnboardingStep{}, fmt.Errorf("update alert rule %s: %w", req.Name, err)
+		}
+		return dto.OnboardingStep{Step: step, Status: dto.OnboardingStatusUpdated, ResourceID: updated.ID}, nil
+	}
+	created, err := s.alerts.Create(ctx, req)
+	if err != nil {
+		return dto.OnboardingStep{}, fmt.Errorf("create alert rule %s: %w", req.Name, err)
+	}
+	return dto.OnboardingStep{Step: step, Status: dto.OnboardingStatusCreated, ResourceID: created.ID}, nil
+}
+
+// enableTracking sets the efficiency target of rec, which enables its target tracking.
+func (s *OnboardingService) enableTracking(ctx context.Context, rec *dto.NamespaceOnboarding, actor string) (dto.OnboardingStep, error) {
+	step := dto.OnboardingStep{Step: dto.OnboardingStepTracking, Status: dto.OnboardingStatusCreated}
+	if s.targets == nil {
+		step.Status, step.Detail = dto.OnboardingStatusSkipped, "efficiency targets are not configured"
+		return step, nil
+	}
+	if _, ok, err := s.targets.EfficiencyTarget(ctx, rec.Namespace); err != nil {
+		return step, err
+	} else if ok {
+		step.Status = dto.OnboardingStatusUpdated
+	}
+	if _, err := s.targets.SetTarget(ctx, rec.Namespace, dto.SetEfficiencyTargetRequest{Target: rec.EfficiencyTarget, SetBy: actor}); err != nil {
+		return step, err
+	}
+	step.Detail = fmt.Sprintf("efficiency target %g", rec.EfficiencyTarget)
+	return step, nil
+}
+
+// load returns the onboarding record of namespace, nil when there is none.
+func (s *OnboardingService) load(ctx context.Context, namespace string) (*dto.NamespaceOnboarding, error) {
+	m, err := s.repo.GetMetadata(ctx, onboardingKeyPrefix+namespace)
+	if errors.Is(err, dataerr.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get onboarding of %s: %w", namespace, err)
+	}
+	return decodeOnboarding(*m)
+}
+
+func (s *OnboardingService) save(ctx context.Context, rec *dto.NamespaceOnboarding) error {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+	m := postgres.Metadata{
+		Key:         onboardingKeyPrefix + rec.Namespace,
+		Value:       value,
+		Description: "onboarding of namespace " + rec.Namespace,
+		CreatedBy:   rec.OnboardedBy,
+	}
+	if err := s.repo.SaveMetadata(ctx, m); err != nil {
+		return fmt.Errorf("save onboarding of %s: %w", rec.Namespace, err)
+	}
+	return nil
+}
+
+func decodeOnboarding(m postgres.Metadata) (*dto.NamespaceOnboarding, error) {
+	raw, err := json.Marshal(m.Value)
+	if err != nil {
+		return nil, fmt.Errorf("decode onboarding %s: %w", m.Key, err)
+	}
+	var rec dto.NamespaceOnboarding
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("decode onboarding %s: %w", m.Key, err)
+	}
+	return &rec, nil
+}
+
+func sloTemplateOf(name string) (sloTemplate, bool) {
+	for _, t := range sloTemplates {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return sloTemplate{}, false
+}
+
+// trimmedStrings returns the non-empty trimmed values of list; nil stays nil.
+func trimmedStrings(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+// Package service period_close.go: 月度关账——管理员关闭一个财务期间后，期间内的日汇总（含共享成本分摊）与
+// 小时统计不可再写，基于它们的报表随之固定；重算不改动原记录而是记为更正（postgres.Adjustment），满足财务审计。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// ErrInvalidPeriodClose is returned for closing a period that has not ended.
+var ErrInvalidPeriodClose = dataerr.Validation("invalid period close")
+
+// ErrInvalidAdjustment is returned for an incomplete manual adjustment or an approval without an approver.
+var ErrInvalidAdjustment = dataerr.Validation("invalid adjustment")
+
+// ErrSelfApprovedAdjustment is returned when the requester of a manual adjustment approves it.
+var ErrSelfApprovedAdjustment = dataerr.Conflict("adjustments must be approved by someone other than the requester")
+
+// AccountingPeriodStore persists closed accounting periods and the adjustments recorded for them.
+// *postgres.MockRepository satisfies this interface.
+type AccountingPeriodStore interface {
+	CloseAccountingPeriod(ctx context.Context, period postgres.AccountingPeriod) error
+	ListAccountingPeriods(ctx context.Context) ([]postgres.AccountingPeriod, error)
+	ListAdjustments(ctx context.Context, filter postgres.AdjustmentFilter) ([]postgres.Adjustment, error)
+	SaveAdjustment(ctx context.Context, adj postgres.Adjustment) (postgres.Adjustment, error)
+	GetAdjustment(ctx context.Context, id string) (*postgres.Adjustment, error)
+	ApproveAdjustment(ctx context.Context, id, approver string) (postgres.Adjustment, error)
+}
+
+// PeriodCloseService closes fiscal periods (the "months" of the fiscal calendar), records manual
+// adjustments of closed periods and lists the closed periods and their adjustments.
+type PeriodCloseService struct {
+	store    AccountingPeriodStore
+	fiscal   costmodel.FiscalCalendar
+	calendar costmodel.AccountingCalendar
+	now      func() time.Time
+}
+
+// NewPeriodCloseService creates a PeriodCloseService for the fiscal calendar.
+func NewPeriodCloseService(store AccountingPeriodStore, fiscal costmodel.FiscalCalendar) *PeriodCloseService {
+	return &PeriodCloseService{store: store, fiscal: fiscal, now: time.Now}
+}
+
+// SetCalendar sets the accounting time zone the periods are measured in, as for the daily ETL
+// (default UTC).
+func (s *PeriodCloseService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+// Close closes the fiscal period containing the day labelled date (default the last period that
+// ended). The period must have ended; closing is final, later corrections are adjustments.
+func (s *PeriodCloseService) Close(ctx context.Context, date time.Time, closedBy, note string) (*dto.AccountingPeriod, error) {
+	today := s.calendar.Date(s.now())
+	if date.IsZero() {
+		date = s.fiscal.Span(today, costmodel.FiscalUnitPeriod).Start.AddDate(0, 0, -1)
+	}
+	span := s.fiscal.Span(date, costmodel.FiscalUnitPeriod)
+	if span.End.After(today) {
+		return nil, fmt.Errorf("%w: %s has not ended", ErrInvalidPeriodClose, span.Label())
+	}
+	period := postgres.AccountingPeriod{
+		Label:     span.Label(),
+		StartDate: span.Start,
+		EndDate:   span.End,
+		ClosedBy:  closedBy,
+		ClosedAt:  s.now().UTC(),
+		Note:      note,
+	}
+	period.StartTime, _ = s.calendar.Bounds(span.Start)
+	period.EndTime, _ = s.calendar.Bounds(span.End)
+	if err := s.store.CloseAccountingPeriod(ctx, period); err != nil {
+		return nil, fmt.Errorf("close %s: %w", period.Label, err)
+	}
+	out := periodDTO(period, 0)
+	return &out, nil
+}
+
+// Periods returns the closed periods, latest first, with the number of adjustments of each.
+func (s *PeriodCloseService) Periods(ctx context.Context) ([]dto.AccountingPeriod, error) {
+	periods, err := s.store.ListAccountingPeriods(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list accounting periods: %w", err)
+	}
+	adjustments, err := s.store.ListAdjustments(ctx, postgres.AdjustmentFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list adjustments: %w", err)
+	}
+	counts := make(map[string]int)
+	for _, a := range adjustments {
+		counts[a.Period]++
+	}
+	out := make([]dto.AccountingPeriod, 0, len(periods))
+	for _, p := range periods {
+		out = append(out, periodDTO(p, counts[p.Label]))
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartDate.After(out[j].StartDate) })
+	return out, nil
+}
+
+// Adjustments returns the adjustments of a period and namespace (empty: all), oldest first.
+func (s *PeriodCloseService) Adjustments(ctx context.Context, period, namespace string) ([]dto.CostAdjustment, error) {
+	adjustments, err := s.store.ListAdjustments(ctx, postgres.AdjustmentFilter{Period: period, Namespace: namespace})
+	if err != nil {
+		return nil, fmt.Errorf("list adjustments: %w", err)
+	}
+	out := make([]dto.CostAdjustment, 0, len(adjustments))
+	for _, a := range adjustments {
+		out = append(out, adjustmentDTO(a))
+	}
+	return out, nil
+}
+
+// Adjust records a manual correction of the cost of a namespace (or one workload of it) on a day
+// of a closed period, requested by createdBy. It is pending, and left out of reports, until approved
+// (Approve). Open periods are corrected by recalculating them; a wrong adjustment is reversed by another one.
+func (s *PeriodCloseService) Adjust(ctx context.Context, req dto.CreateAdjustmentRequest, createdBy string) (*dto.CostAdjustment, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidAdjustment)
+	case req.Namespace == "":
+		return nil, fmt.Errorf("%w: namespace is required", ErrInvalidAdjustment)
+	case req.Amount == 0:
+		return nil, fmt.Errorf("%w: amount must not be zero", ErrInvalidAdjustment)
+	case req.Reason == "":
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidAdjustment)
+	}
+	adj, err := s.store.SaveAdjustment(ctx, postgres.Adjustment{
+		Record:    postgres.AdjustmentRecordDailyNamespaceCost,
+		Date:      date,
+		Namespace: req.Namespace,
+		Workload:  req.Workload,
+		Amount:    req.Amount,
+		Source:    postgres.AdjustmentSourceManual,
+		Reason:    req.Reason,
+		CreatedBy: createdBy,
+		Status:    postgres.AdjustmentPending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save adjustment: %w", err)
+	}
+	out := adjustmentDTO(adj)
+	return &out, nil
+}
+
+// Approve approves a pending manual adjustment; approver is the authenticated caller and must differ
+// from the requester. From then on reports include it.
+func (s *PeriodCloseService) Approve(ctx context.Context, id, approver string) (*dto.CostAdjustment, error) {
+	if approver == "" {
+		return nil, fmt.Errorf("%w: approver is required", ErrInvalidAdjustment)
+	}
+	adj, err := s.store.GetAdjustment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if adj.CreatedBy == approver {
+		return nil, ErrSelfApprovedAdjustment
+	}
+	approved, err := s.store.ApproveAdjustment(ctx, id, approver)
+	if err != nil {
+		return nil, err
+	}
+	out := adjustmentDTO(approved)
+	return &out, nil
+}
+
+func adjustmentDTO(a postgres.Adjustment) dto.CostAdjustment {
+	status := postgres.AdjustmentApproved
+	if a.Pending() {
+		status = postgres.AdjustmentPending
+	}
+	return dto.CostAdjustment{
+		ID:         a.ID,
+		Period:     a.Period,
+		Record:     a.Record,
+		Date:       a.Date,
+		Namespace:  a.Namespace,
+		Workload:   a.Workload,
+		Amount:     a.Amount,
+		Source:     a.Source,
+		Reason:     a.Reason,
+		CreatedBy:  a.CreatedBy,
+		Approver:   a.Approver,
+		CreatedAt:  a.CreatedAt,
+		Status:     status,
+		ApprovedAt: a.ApprovedAt,
+	}
+}
+
+func periodDTO(p postgres.AccountingPeriod, adjustments int) dto.AccountingPeriod {
+	return dto.AccountingPeriod{
+		Label:           p.Label,
+		StartDate:       p.StartDate,
+		EndDate:         p.EndDate,
+		ClosedBy:        p.ClosedBy,
+		ClosedAt:        p.ClosedAt,
+		Note:            p.Note,
+		AdjustmentCount: adjustments,
+	}
+}
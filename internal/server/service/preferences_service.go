@@ -0,0 +1,308 @@
+// Package service preferences_service.go: 用户偏好（默认 namespace、币种与显示单位、收藏的工作负载、保存的视图），
+// 按用户身份保存在 metadata（user_preferences/<user>），代替驾驶舱前端的 local storage。
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// userPreferencesKeyPrefix is the metadata key prefix of the preferences: user_preferences/<user>.
+const userPreferencesKeyPrefix = "user_preferences/"
+
+// DefaultPreferenceCurrency is the currency of users who have not chosen one.
+const DefaultPreferenceCurrency = "CNY"
+
+// Limits of one user's preferences, keeping a metadata row small.
+const (
+	maxPreferenceNamespaces = 100
+	maxFavoriteWorkloads    = 100
+	maxSavedViews           = 50
+	maxSavedViewFilters     = 50
+	maxSavedViewNameLength  = 100
+)
+
+var (
+	// ErrInvalidPreferences is returned for preferences outside the accepted values or limits.
+	ErrInvalidPreferences = dataerr.Validation("invalid preferences")
+	// ErrSavedViewNotFound is returned when deleting an unknown saved view.
+	ErrSavedViewNotFound = dataerr.NotFound("saved view not found")
+)
+
+var (
+	cpuDisplayUnits    = map[string]bool{"cores": true, "millicores": true}
+	memoryDisplayUnits = map[string]bool{"GiB": true, "MiB": true, "GB": true, "MB": true}
+)
+
+// storedPreferences is the metadata value of a user's preferences.
+type storedPreferences struct {
+	DefaultNamespaces []string               `json:"default_namespaces"`
+	Currency          string                 `json:"currency"`
+	Units             dto.DisplayUnits       `json:"units"`
+	FavoriteWorkloads []dto.FavoriteWorkload `json:"favorite_workloads"`
+	SavedViews        []dto.SavedView        `json:"saved_views"`
+}
+
+// PreferenceService stores the cockpit preferences of each user in metadata.
+type PreferenceService struct {
+	repo     postgres.Repository
+	currency string
+	now      func() time.Time
+
+	// mu serializes the read-modify-write of updates.
+	mu sync.Mutex
+}
+
+// NewPreferenceService creates a PreferenceService.
+func NewPreferenceService(repo postgres.Repository) *PreferenceService {
+	return &PreferenceService{repo: repo, currency: DefaultPreferenceCurrency, now: time.Now}
+}
+
+// SetDefaultCurrency sets the currency of users who have not chosen one (default CNY).
+func (s *PreferenceService) SetDefaultCurrency(currency string) {
+	if currency = strings.ToUpper(strings.TrimSpace(currency)); currency != "" {
+		s.currency = currency
+	}
+}
+
+// Get returns the preferences of user, or the defaults when none are saved.
+func (s *PreferenceService) Get(ctx context.Context, user string) (*dto.UserPreferences, error) {
+	p, updatedAt, err := s.load(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDTO(user, p, updatedAt), nil
+}
+
+// Update changes the fields set in req and keeps the others.
+func (s *PreferenceService) Update(ctx context.Context, user string, req dto.UpdatePreferencesRequest) (*dto.UserPreferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, _, err := s.load(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if req.DefaultNamespaces != nil {
+		if p.DefaultNamespaces, err = normalizeNamespaces(*req.DefaultNamespaces); err != nil {
+			return nil, err
+		}
+	}
+	if req.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*req.Currency))
+		if !validCurrencyCode(currency) {
+			return nil, fmt.Errorf("%w: currency %q is not an ISO 4217 code", ErrInvalidPreferences, *req.Currency)
+		}
+		p.Currency = currency
+	}
+	if req.Units != nil {
+		if req.Units.CPU != "" && !cpuDisplayUnits[req.Units.CPU] {
+			return nil, fmt.Errorf("%w: cpu unit %q (want cores or millicores)", ErrInvalidPreferences, req.Units.CPU)
+		}
+		if req.Units.Memory != "" && !memoryDisplayUnits[req.Units.Memory] {
+			return nil, fmt.Errorf("%w: memory unit %q (want GiB, MiB, GB or MB)", ErrInvalidPreferences, req.Units.Memory)
+		}
+		p.Units = *req.Units
+	}
+	if req.FavoriteWorkloads != nil {
+		if p.FavoriteWorkloads, err = normalizeFavorites(*req.FavoriteWorkloads); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.save(ctx, user, p); err != nil {
+		return nil, err
+	}
+	updatedAt := s.now().UTC()
+	return s.toDTO(user, p, &updatedAt), nil
+}
+
+// SaveView saves a view of user. A view with the same name and page is replaced, keeping its ID.
+func (s *PreferenceService) SaveView(ctx context.Context, user string, req dto.SavedViewRequest) (*dto.SavedView, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxSavedViewNameLength {
+		return nil, fmt.Errorf("%w: view name must be 1-%d characters", ErrInvalidPreferences, maxSavedViewNameLength)
+	}
+	if len(req.Filters) > maxSavedViewFilters {
+		return nil, fmt.Errorf("%w: at most %d filters per view", ErrInvalidPreferences, maxSavedViewFilters)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, _, err := s.load(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	view := dto.SavedView{ID: uuid.New().String(), Name: name, Page: req.Page, Filters: req.Filters, CreatedAt: s.now().UTC()}
+	if view.Filters == nil {
+		view.Filters = map[string]string{}
+	}
+	replaced := false
+	for i, v := range p.SavedViews {
+		if v.Name == view.Name && v.Page == view.Page {
+			view.ID, view.CreatedAt = v.ID, v.CreatedAt
+			p.SavedViews[i] = view
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		if len(p.SavedViews) >= maxSavedViews {
+			return nil, fmt.Errorf("%w: at most %d saved views", ErrInvalidPreferences, maxSavedViews)
+		}
+		p.SavedViews = append(p.SavedViews, view)
+	}
+	if err := s.save(ctx, user, p); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// DeleteView deletes the saved view id of user.
+func (s *PreferenceService) DeleteView(ctx context.Context, user, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, _, err := s.load(ctx, user)
+	if err != nil {
+		return err
+	}
+	for i, v := range p.SavedViews {
+		if v.ID == id {
+			p.SavedViews = append(p.SavedViews[:i], p.SavedViews[i+1:]...)
+			return s.save(ctx, user, p)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrSavedViewNotFound, id)
+}
+
+// load returns the stored preferences of user; updatedAt is nil when none are saved.
+func (s *PreferenceService) load(ctx context.Context, user string) (storedPreferences, *time.Time, error) {
+	var p storedPreferences
+	m, err := s.repo.GetMetadata(ctx, userPreferencesKeyPrefix+user)
+	if errors.Is(err, dataerr.ErrNotFound) {
+		return p, nil, nil
+	}
+	if err != nil {
+		return p, nil, fmt.Errorf("get preferences of %s: %w", user, err)
+	}
+	raw, err := json.Marshal(m.Value)
+	if err != nil {
+		return p, nil, fmt.Errorf("decode preferences of %s: %w", user, err)
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return p, nil, fmt.Errorf("decode preferences of %s: %w", user, err)
+	}
+	updatedAt := m.UpdatedAt
+	return p, &updatedAt, nil
+}
+
+func (s *PreferenceService) save(ctx context.Context, user string, p storedPreferences) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+	m := postgres.Metadata{
+		Key:         userPreferencesKeyPrefix + user,
+		Value:       value,
+		Description: "cockpit preferences of " + user,
+		CreatedBy:   user,
+	}
+	if err := s.repo.SaveMetadata(ctx, m); err != nil {
+		return fmt.Errorf("save preferences of %s: %w", user, err)
+	}
+	return nil
+}
+
+// toDTO fills the defaults of the fields the user has not set.
+func (s *PreferenceService) toDTO(user string, p storedPreferences, updatedAt *time.Time) *dto.UserPreferences {
+	resp := &dto.UserPreferences{
+		User:              user,
+		DefaultNamespaces: p.DefaultNamespaces,
+		Currency:          p.Currency,
+		Units:             p.Units,
+		FavoriteWorkloads: p.FavoriteWorkloads,
+		SavedViews:        p.SavedViews,
+		UpdatedAt:         updatedAt,
+	}
+	if resp.DefaultNamespaces == nil {
+		resp.DefaultNamespaces = []string{}
+	}
+	if resp.Currency == "" {
+		resp.Currency = s.currency
+	}
+	if resp.Units.CPU == "" {
+		resp.Units.CPU = "cores"
+	}
+	if resp.Units.Memory == "" {
+		resp.Units.Memory = "GiB"
+	}
+	if resp.FavoriteWorkloads == nil {
+		resp.FavoriteWorkloads = []dto.FavoriteWorkload{}
+	}
+	if resp.SavedViews == nil {
+		resp.SavedViews = []dto.SavedView{}
+	}
+	return resp
+}
+
+// normalizeNamespaces trims and de-duplicates namespaces, keeping their order.
+func normalizeNamespaces(namespaces []string) ([]string, error) {
+	if len(namespaces) > maxPreferenceNamespaces {
+		return nil, fmt.Errorf("%w: at most %d default namespaces", ErrInvalidPreferences, maxPreferenceNamespaces)
+	}
+	out := make([]string, 0, len(namespaces))
+	seen := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		ns = strings.TrimSpace(ns)
+		if ns == "" {
+			return nil, fmt.Errorf("%w: empty namespace", ErrInvalidPreferences)
+		}
+		if !seen[ns] {
+			seen[ns] = true
+			out = append(out, ns)
+		}
+	}
+	return out, nil
+}
+
+// normalizeFavorites de-duplicates favorite workloads, keeping their order.
+func normalizeFavorites(favorites []dto.FavoriteWorkload) ([]dto.FavoriteWorkload, error) {
+	if len(favorites) > maxFavoriteWorkloads {
+		return nil, fmt.Errorf("%w: at most %d favorite workloads", ErrInvalidPreferences, maxFavoriteWorkloads)
+	}
+	out := make([]dto.FavoriteWorkload, 0, len(favorites))
+	seen := make(map[dto.FavoriteWorkload]bool, len(favorites))
+	for _, f := range favorites {
+		if f.Namespace == "" || f.Name == "" {
+			return nil, fmt.Errorf("%w: favorite workload needs namespace and name", ErrInvalidPreferences)
+		}
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func validCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,218 @@
+// Package service pricing_approval.go: 单价变更审批流程（草稿 → 批准/驳回 → 生效），批准后才写入单价历史。
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// PriceChangeStatusEffective is the DTO status of an approved change whose effective time has passed.
+const PriceChangeStatusEffective = "effective"
+
+// Price change actions recorded in the change history.
+const (
+	PriceChangeActionProposed = "proposed"
+	PriceChangeActionApproved = "approved"
+	PriceChangeActionRejected = "rejected"
+)
+
+var (
+	// ErrPriceApprovalRequired is returned by SetPrice when price changes must be proposed and approved.
+	ErrPriceApprovalRequired = dataerr.Conflict("price changes require approval: propose the change with POST /api/v1/pricing/changes")
+	// ErrPriceChangeReviewed is returned when approving or rejecting a change that is no longer a draft.
+	ErrPriceChangeReviewed = dataerr.Conflict("price change already reviewed")
+	// ErrSelfApproval is returned when the requester of a price change reviews it.
+	ErrSelfApproval = dataerr.Conflict("price changes must be reviewed by someone other than the requester")
+	// ErrReviewerRequired is returned when a price change is reviewed without an authenticated reviewer.
+	ErrReviewerRequired = dataerr.Validation("price changes must be reviewed by an authenticated service account")
+)
+
+// PriceChangeStore persists price change requests (cost_price_change).
+// *postgres.MockRepository satisfies this interface.
+type PriceChangeStore interface {
+	SavePriceChange(ctx context.Context, change postgres.PriceChange) (postgres.PriceChange, error)
+	GetPriceChange(ctx context.Context, id string) (*postgres.PriceChange, error)
+	ListPriceChanges(ctx context.Context) ([]postgres.PriceChange, error)
+}
+
+// SetApproval enables the price change workflow. With required, SetPrice is refused and every change
+// must be proposed and approved; allowSelf lets the requester approve their own change.
+func (s *PricingService) SetApproval(store PriceChangeStore, required, allowSelf bool) {
+	s.changes = store
+	s.requireApproval = required
+	s.allowSelfApproval = allowSelf
+}
+
+// ProposeChange records a draft price change by requester; the price history is unchanged until it
+// is approved.
+func (s *PricingService) ProposeChange(ctx context.Context, req dto.SetPriceRequest, requester string) (*dto.PriceChange, error) {
+	store, err := s.changeStore()
+	if err != nil {
+		return nil, err
+	}
+	change := postgres.PriceChange{
+		Status:              postgres.PriceChangeDraft,
+		EffectiveFrom:       req.EffectiveFrom.UTC(),
+		CPUPricePerCoreHour: req.CPUPricePerCoreHour,
+		MemPricePerGBHour:   req.MemPricePerGBHour,
+		Note:                req.Note,
+		Recalculate:         req.Recalculate,
+		RequestedBy:         requester,
+		CreatedAt:           s.now().UTC(),
+	}
+	if err := changeVersion(change).UnitPrice().Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrice, err)
+	}
+	change.History = []postgres.PriceChangeEvent{{Action: PriceChangeActionProposed, Actor: requester, Comment: req.Note, At: change.CreatedAt}}
+	saved, err := store.SavePriceChange(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+	out := s.toDTOPriceChange(saved)
+	return &out, nil
+}
+
+// ListChanges returns the price changes, newest first; status filters by DTO status ("" for all).
+func (s *PricingService) ListChanges(ctx context.Context, status string) (*dto.PriceChangeListResponse, error) {
+	store, err := s.changeStore()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := store.ListPriceChanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.PriceChangeListResponse{Changes: make([]dto.PriceChange, 0, len(changes))}
+	for _, c := range changes {
+		out := s.toDTOPriceChange(c)
+		if status == "" || out.Status == status {
+			resp.Changes = append(resp.Changes, out)
+		}
+	}
+	return resp, nil
+}
+
+// GetChange returns a price change with its history.
+func (s *PricingService) GetChange(ctx context.Context, id string) (*dto.PriceChange, error) {
+	store, err := s.changeStore()
+	if err != nil {
+		return nil, err
+	}
+	change, err := store.GetPriceChange(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := s.toDTOPriceChange(*change)
+	return &out, nil
+}
+
+// ApproveChange approves a draft price change by approver and writes it to the price history, exactly
+// as SetPrice would (including the recalculation the requester asked for). The response carries the
+// updated change.
+func (s *PricingService) ApproveChange(ctx context.Context, id, approver, comment string) (*dto.PriceChangeResponse, error) {
+	change, err := s.reviewable(ctx, id, approver)
+	if err != nil {
+		return nil, err
+	}
+	version := changeVersion(*change)
+	version.ChangeID = change.ID
+	resp, err := s.apply(ctx, version, change.Recalculate)
+	if err != nil {
+		return nil, err
+	}
+	change.Status = postgres.PriceChangeApproved
+	change.PriceVersionID = resp.Version.ID
+	change.History = append(change.History, postgres.PriceChangeEvent{Action: PriceChangeActionApproved, Actor: approver, Comment: comment, At: s.now().UTC()})
+	saved, err := s.changes.SavePriceChange(ctx, *change)
+	if err != nil {
+		return nil, fmt.Errorf("price version %s saved but price change %s not updated: %w", resp.Version.ID, change.ID, err)
+	}
+	out := s.toDTOPriceChange(saved)
+	resp.Change = &out
+	return resp, nil
+}
+
+// RejectChange rejects a draft price change by reviewer; the price history is unchanged.
+func (s *PricingService) RejectChange(ctx context.Context, id, reviewer, comment string) (*dto.PriceChange, error) {
+	change, err := s.reviewable(ctx, id, reviewer)
+	if err != nil {
+		return nil, err
+	}
+	change.Status = postgres.PriceChangeRejected
+	change.History = append(change.History, postgres.PriceChangeEvent{Action: PriceChangeActionRejected, Actor: reviewer, Comment: comment, At: s.now().UTC()})
+	saved, err := s.changes.SavePriceChange(ctx, *change)
+	if err != nil {
+		return nil, err
+	}
+	out := s.toDTOPriceChange(saved)
+	return &out, nil
+}
+
+// reviewable returns the change if it is a draft that reviewer may review. reviewer is the
+// authenticated caller and is required, otherwise anyone could review their own change.
+func (s *PricingService) reviewable(ctx context.Context, id, reviewer string) (*postgres.PriceChange, error) {
+	if reviewer == "" {
+		return nil, ErrReviewerRequired
+	}
+	store, err := s.changeStore()
+	if err != nil {
+		return nil, err
+	}
+	change, err := store.GetPriceChange(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != postgres.PriceChangeDraft {
+		return nil, fmt.Errorf("%w: %s is %s", ErrPriceChangeReviewed, change.ID, change.Status)
+	}
+	if !s.allowSelfApproval && reviewer == change.RequestedBy {
+		return nil, ErrSelfApproval
+	}
+	return change, nil
+}
+
+func (s *PricingService) changeStore() (PriceChangeStore, error) {
+	if s.changes == nil {
+		return nil, dataerr.NotFound("price change workflow is not configured")
+	}
+	return s.changes, nil
+}
+
+// changeVersion is the price version a change writes when approved.
+func changeVersion(c postgres.PriceChange) postgres.PriceVersion {
+	return postgres.PriceVersion{
+		EffectiveFrom:       c.EffectiveFrom,
+		CPUPricePerCoreHour: c.CPUPricePerCoreHour,
+		MemPricePerGBHour:   c.MemPricePerGBHour,
+		Note:                c.Note,
+	}
+}
+
+func (s *PricingService) toDTOPriceChange(c postgres.PriceChange) dto.PriceChange {
+	status := c.Status
+	if status == postgres.PriceChangeApproved && !c.EffectiveFrom.After(s.now()) {
+		status = PriceChangeStatusEffective
+	}
+	history := make([]dto.PriceChangeEvent, 0, len(c.History))
+	for _, e := range c.History {
+		history = append(history, dto.PriceChangeEvent{Action: e.Action, Actor: e.Actor, Comment: e.Comment, At: e.At})
+	}
+	return dto.PriceChange{
+		ID:                  c.ID,
+		Status:              status,
+		EffectiveFrom:       c.EffectiveFrom,
+		CPUPricePerCoreHour: c.CPUPricePerCoreHour,
+		MemPricePerGBHour:   c.MemPricePerGBHour,
+		Note:                c.Note,
+		Recalculate:         c.Recalculate,
+		RequestedBy:         c.RequestedBy,
+		PriceVersionID:      c.PriceVersionID,
+		History:             history,
+		CreatedAt:           c.CreatedAt,
+		UpdatedAt:           c.UpdatedAt,
+	}
+}
@@ -0,0 +1,261 @@
+// Package service pricing_service.go: 单价历史维护与更正历史单价后的追溯重算（小时统计重新计价、快照重建）。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// TriggerRecalculation is the trigger of runs that re-price an already calculated window.
+const TriggerRecalculation = "recalculation"
+
+// ErrInvalidPrice is returned for a price version with a missing effective time or non-positive prices.
+var ErrInvalidPrice = dataerr.Validation("invalid price version")
+
+// PriceHistoryStore persists unit price versions (cost_price_history).
+// *postgres.MockRepository satisfies this interface.
+type PriceHistoryStore interface {
+	SavePriceVersion(ctx context.Context, version postgres.PriceVersion) error
+	ListPriceVersions(ctx context.Context) ([]postgres.PriceVersion, error)
+}
+
+// PricingService maintains the price history and recalculates windows priced with a corrected version.
+// Windows before the first version are priced with the global prices from configuration.
+// With SetApproval, price changes go through a draft→approve workflow (see pricing_approval.go).
+type PricingService struct {
+	store    PriceHistoryStore
+	runs     *CalculationRunService
+	fallback costmodel.UnitPrice
+	now      func() time.Time
+
+	nonFinite costmodel.NonFinitePolicy // applied to the stats of rebuilt snapshots
+
+	changes           PriceChangeStore // nil: no approval workflow
+	requireApproval   bool
+	allowSelfApproval bool
+}
+
+// NewPricingService creates a PricingService. Recalculations are recorded in runStore like any
+// other calculation run (trigger "recalculation").
+func NewPricingService(store PriceHistoryStore, repo postgres.Repository, runStore CalculationRunStore, cpuPricePerCoreHour, memPricePerGBHour float64) *PricingService {
+	fallback := costmodel.UnitPrice{CPUPricePerCoreHour: cpuPricePerCoreHour, MemPricePerGBHour: memPricePerGBHour}
+	s := &PricingService{
+		store:    store,
+		fallback: fallback,
+		now:      time.Now,
+	}
+	s.runs = NewCalculationRunService(runStore, func(ctx context.Context, run postgres.CalculationRun) (int, error) {
+		return NewRecalculationPipeline(repo, store, fallback, s.nonFinite)(ctx, run)
+	})
+	return s
+}
+
+// SetNonFinitePolicy sets what happens to NaN/Inf costs when recalculation rebuilds snapshots
+// (Business.CostCalculation.NonFinitePolicy; default costmodel.NonFiniteZero).
+func (s *PricingService) SetNonFinitePolicy(policy costmodel.NonFinitePolicy) {
+	s.nonFinite = policy
+}
+
+// History returns all price versions and the one currently in effect.
+func (s *PricingService) History(ctx context.Context) (*dto.PriceHistoryResponse, error) {
+	versions, err := s.store.ListPriceVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.PriceHistoryResponse{Versions: make([]dto.PriceVersion, 0, len(versions))}
+	now := s.now()
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, toDTOPriceVersion(v))
+		if !v.EffectiveFrom.After(now) {
+			current := toDTOPriceVersion(v)
+			resp.Current = &current
+		}
+	}
+	return resp, nil
+}
+
+// SetPrice adds a price version or corrects the one with the same effective time. The affected
+// window runs from its effective time to the next version (or now); with req.Recalculate it is
+// recalculated immediately, otherwise the caller can do so later with Recalculate.
+// When approval is required, direct changes are refused with ErrPriceApprovalRequired.
+func (s *PricingService) SetPrice(ctx context.Context, req dto.SetPriceRequest) (*dto.PriceChangeResponse, error) {
+	if s.requireApproval {
+		return nil, ErrPriceApprovalRequired
+	}
+	version := postgres.PriceVersion{
+		EffectiveFrom:       req.EffectiveFrom.UTC(),
+		CPUPricePerCoreHour: req.CPUPricePerCoreHour,
+		MemPricePerGBHour:   req.MemPricePerGBHour,
+		Note:                req.Note,
+	}
+	if err := version.UnitPrice().Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrice, err)
+	}
+	return s.apply(ctx, version, req.Recalculate)
+}
+
+// apply saves version and, for a version effective in the past, reports (and with recalculate
+// re-prices) the affected window.
+func (s *PricingService) apply(ctx context.Context, version postgres.PriceVersion, recalculate bool) (*dto.PriceChangeResponse, error) {
+	if err := s.store.SavePriceVersion(ctx, version); err != nil {
+		return nil, err
+	}
+	versions, err := s.store.ListPriceVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.PriceChangeResponse{Version: toDTOPriceVersion(version)}
+	for _, v := range versions {
+		if v.EffectiveFrom.Equal(version.EffectiveFrom) {
+			resp.Version = toDTOPriceVersion(v)
+		}
+	}
+
+	now := s.now()
+	if !version.EffectiveFrom.Before(now) {
+		return resp, nil // 未来生效，尚无已计算的数据受影响
+	}
+	end, ok := postgres.PriceHistoryOf(versions).EffectiveUntil(version.EffectiveFrom)
+	if !ok || end.After(now) {
+		end = now
+	}
+	start := version.EffectiveFrom
+	resp.AffectedStart, resp.AffectedEnd = &start, &end
+	if recalculate {
+		run, err := s.Recalculate(ctx, start, end)
+		if run == nil {
+			return nil, err
+		}
+		resp.Recalculation = run // 重算失败时 run.Status 为 failed，可再次发起
+	}
+	return resp, nil
+}
+
+// Recalculate re-prices start..end with the price history and rebuilds the snapshots overlapping it.
+func (s *PricingService) Recalculate(ctx context.Context, start, end time.Time) (*dto.CalculationRun, error) {
+	return s.runs.Execute(ctx, TriggerRecalculation, start, end)
+}
+
+// NewRecalculationPipeline returns a pipeline that re-prices the hourly workload stats of the window
+// (run.Scopes restricts it to those namespaces) with the price in effect at each hour, then rebuilds
+// the cost snapshots overlapping the window. Rows written counts re-priced stats plus rebuilt snapshots.
+// Stats of closed accounting periods keep their price; the differences are recorded as adjustments.
+// nonFinite applies to the stats summed into the rebuilt snapshots, as in NewSnapshotPipeline.
+func NewRecalculationPipeline(repo postgres.Repository, prices PriceHistoryStore, fallback costmodel.UnitPrice, nonFinite costmodel.NonFinitePolicy) CalculationPipeline {
+	return func(ctx context.Context, run postgres.CalculationRun) (int, error) {
+		ctx = postgres.WithRecalculation(ctx, "recalculation "+run.ID)
+		versions, err := prices.ListPriceVersions(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("list price versions: %w", err)
+		}
+		history := postgres.PriceHistoryOf(versions)
+		namespaces := run.Scopes
+		if len(namespaces) == 0 {
+			namespaces = []string{""}
+		}
+
+		rows := 0
+		for _, ns := range namespaces {
+			stats, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: ns, StartTime: run.WindowStart, EndTime: run.WindowEnd})
+			if err != nil {
+				return rows, fmt.Errorf("list hourly workload stats: %w", err)
+			}
+			for _, st := range stats {
+				if !st.Timestamp.Before(run.WindowEnd) {
+					continue
+				}
+				price, ok := history.At(st.Timestamp)
+				if !ok {
+					price = fallback
+				}
+				repriced, changed := repriceHourlyStat(st, price)
+				if !changed {
+					continue
+				}
+				if err := repo.SaveHourlyWorkloadStat(ctx, repriced); err != nil {
+					return rows, fmt.Errorf("save hourly workload stat: %w", err)
+				}
+				rows++
+			}
+		}
+
+		snapshots, err := repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{StartTime: run.WindowStart})
+		if err != nil {
+			return rows, fmt.Errorf("list cost snapshots: %w", err)
+		}
+		for _, snap := range snapshots {
+			if !snap.TimeRangeStart.Before(run.WindowEnd) || !snap.TimeRangeEnd.After(run.WindowStart) {
+				continue
+			}
+			if err := aggregateSnapshot(ctx, repo, &snap, nonFinite); err != nil {
+				return rows, err
+			}
+			if snap.Metadata == nil {
+				snap.Metadata = make(map[string]interface{})
+			}
+			snap.Metadata["recalculated_by"] = run.ID
+			snap.UpdatedAt = time.Now().UTC()
+			if err := repo.SaveCostSnapshot(ctx, snap); err != nil {
+				return rows, fmt.Errorf("save cost snapshot: %w", err)
+			}
+			rows++
+		}
+		return rows, nil
+	}
+}
+
+// repriceHourlyStat scales the CPU and memory costs of st to price. The price the row was computed
+// with is derived from the row itself (billable/request, or usage/P95 without requests), so
+// adjustments made at calculation time such as waste exclusion are preserved.
+func repriceHourlyStat(st postgres.HourlyWorkloadStat, price costmodel.UnitPrice) (postgres.HourlyWorkloadStat, bool) {
+	const gib = 1 << 30
+	cpu := priceScale(st.CPUBillableCost, st.CPURequest, st.CPUUsageCost, st.CPUUsageP95, price.CPUPricePerCoreHour)
+	mem := priceScale(st.MemBillableCost, float64(st.MemRequest)/gib, st.MemUsageCost, float64(st.MemUsageP95)/gib, price.MemPricePerGBHour)
+	if math.Abs(cpu-1) < 1e-9 && math.Abs(mem-1) < 1e-9 {
+		return st, false
+	}
+	st.CPUBillableCost *= cpu
+	st.CPUUsageCost *= cpu
+	st.CPUWasteCost *= cpu
+	st.MemBillableCost *= mem
+	st.MemUsageCost *= mem
+	st.MemWasteCost = int64(math.Round(float64(st.MemWasteCost) * mem))
+	st.TotalBillableCost = st.CPUBillableCost + st.MemBillableCost
+	st.TotalUsageCost = st.CPUUsageCost + st.MemUsageCost
+	st.TotalWasteCost = st.TotalBillableCost - st.TotalUsageCost
+	return st, true
+}
+
+// priceScale returns newPrice divided by the unit price the stored costs imply; 1 when nothing is priced.
+func priceScale(billable, request, usage, p95, newPrice float64) float64 {
+	var old float64
+	switch {
+	case billable > 0 && request > 0:
+		old = billable / request
+	case usage > 0 && p95 > 0:
+		old = usage / p95
+	default:
+		return 1
+	}
+	return newPrice / old
+}
+
+func toDTOPriceVersion(v postgres.PriceVersion) dto.PriceVersion {
+	return dto.PriceVersion{
+		ID:                  v.ID,
+		EffectiveFrom:       v.EffectiveFrom,
+		CPUPricePerCoreHour: v.CPUPricePerCoreHour,
+		MemPricePerGBHour:   v.MemPricePerGBHour,
+		Note:                v.Note,
+		Revision:            v.Revision,
+		ChangeID:            v.ChangeID,
+		UpdatedAt:           v.UpdatedAt,
+	}
+}
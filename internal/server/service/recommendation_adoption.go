@@ -0,0 +1,118 @@
+// Package service recommendation_adoption.go: 已下发建议的采纳率（按团队）与“已建议未实现”的节省，数据来自 etl.RecommendationReconciler。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+const (
+	defaultAdoptionWindow = 90 * 24 * time.Hour
+	maxTopUnrealized      = 10
+)
+
+// RecommendationLister lists issued recommendations (workload_recommendation).
+// *postgres.MockRepository satisfies this interface.
+type RecommendationLister interface {
+	ListIssuedRecommendations(ctx context.Context, filter postgres.IssuedRecommendationFilter) ([]postgres.IssuedRecommendation, error)
+}
+
+// RecommendationAdoptionService reports the adoption of issued recommendations.
+type RecommendationAdoptionService struct {
+	store RecommendationLister
+	now   func() time.Time
+}
+
+// NewRecommendationAdoptionService creates a RecommendationAdoptionService.
+func NewRecommendationAdoptionService(store RecommendationLister) *RecommendationAdoptionService {
+	return &RecommendationAdoptionService{store: store, now: time.Now}
+}
+
+// Adoption summarizes the recommendations issued since since (default: the last 90 days).
+func (s *RecommendationAdoptionService) Adoption(ctx context.Context, since time.Time) (*dto.RecommendationAdoptionResponse, error) {
+	if since.IsZero() {
+		since = s.now().Add(-defaultAdoptionWindow)
+	}
+	recs, err := s.store.ListIssuedRecommendations(ctx, postgres.IssuedRecommendationFilter{IssuedAfter: since})
+	if err != nil {
+		return nil, fmt.Errorf("list issued recommendations: %w", err)
+	}
+	resp := &dto.RecommendationAdoptionResponse{Since: since.UTC(), Teams: []dto.RecommendationAdoption{}, TopUnrealized: []dto.UnrealizedRecommendation{}}
+	teams := make(map[string]*dto.RecommendationAdoption)
+	for _, r := range recs {
+		t, ok := teams[r.Team]
+		if !ok {
+			t = &dto.RecommendationAdoption{Team: r.Team}
+			teams[r.Team] = t
+		}
+		unrealized := 0.0
+		if r.EstimatedMonthlySavings > 0 {
+			unrealized = r.EstimatedMonthlySavings - r.RealizedMonthlySavings
+		}
+		for _, a := range []*dto.RecommendationAdoption{t, &resp.Total} {
+			a.Issued++
+			switch costmodel.AdoptionStatus(r.Status) {
+			case costmodel.AdoptionApplied:
+				a.Applied++
+			case costmodel.AdoptionPartial:
+				a.Partial++
+			default:
+				a.Open++
+			}
+			if r.EstimatedMonthlySavings > 0 {
+				a.EstimatedMonthlySavings += r.EstimatedMonthlySavings
+				a.RealizedMonthlySavings += r.RealizedMonthlySavings
+				a.UnrealizedMonthlySavings += unrealized
+			}
+		}
+		if unrealized > 0 {
+			resp.TopUnrealized = append(resp.TopUnrealized, dto.UnrealizedRecommendation{
+				Team:                     r.Team,
+				Namespace:                r.Namespace,
+				Workload:                 r.WorkloadName,
+				Action:                   r.Action,
+				Resource:                 r.Resource,
+				RecommendedRequest:       r.RecommendedRequest,
+				CurrentRequest:           r.CurrentRequest,
+				Status:                   r.Status,
+				IssuedAt:                 r.IssuedAt.UTC(),
+				UnrealizedMonthlySavings: roundCost(unrealized),
+			})
+		}
+	}
+	for _, t := range teams {
+		finishAdoption(t)
+		resp.Teams = append(resp.Teams, *t)
+	}
+	finishAdoption(&resp.Total)
+	sort.Slice(resp.Teams, func(i, j int) bool {
+		a, b := resp.Teams[i], resp.Teams[j]
+		if a.UnrealizedMonthlySavings != b.UnrealizedMonthlySavings {
+			return a.UnrealizedMonthlySavings > b.UnrealizedMonthlySavings
+		}
+		return a.Team < b.Team
+	})
+	sort.SliceStable(resp.TopUnrealized, func(i, j int) bool {
+		return resp.TopUnrealized[i].UnrealizedMonthlySavings > resp.TopUnrealized[j].UnrealizedMonthlySavings
+	})
+	if len(resp.TopUnrealized) > maxTopUnrealized {
+		resp.TopUnrealized = resp.TopUnrealized[:maxTopUnrealized]
+	}
+	return resp, nil
+}
+
+// finishAdoption computes the adoption rate and rounds the savings.
+func finishAdoption(a *dto.RecommendationAdoption) {
+	if a.Issued > 0 {
+		a.AdoptionRate = roundCost(float64(a.Applied) / float64(a.Issued) * 100)
+	}
+	a.EstimatedMonthlySavings = roundCost(a.EstimatedMonthlySavings)
+	a.RealizedMonthlySavings = roundCost(a.RealizedMonthlySavings)
+	a.UnrealizedMonthlySavings = roundCost(a.UnrealizedMonthlySavings)
+}
@@ -0,0 +1,356 @@
+// Package service recommendation_export.go: 将选定的已下发建议导出为 Kubernetes 补丁清单包（Kustomize overlay 或
+// kubectl patch 脚本），并为每条建议写入一条待验证的优化跟踪记录（roi.OptimizationTrackingRecord）。
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Manifest bundle formats (ExportRecommendationsRequest.Format).
+const (
+	ManifestFormatKustomize = "kustomize" // patches plus a kustomization.yaml: kubectl apply -k
+	ManifestFormatKubectl   = "kubectl"   // patches plus an apply.sh running kubectl patch --type=json
+)
+
+// Optimization types and statuses of the tracking records written for exported recommendations.
+const (
+	OptimizationTypeZombieCleanup = "zombie_cleanup"
+	OptimizationTypeRightsizing   = "resource_rightsizing"
+
+	OptimizationStatusPending  = "pending"
+	OptimizationStatusVerified = "verified"
+)
+
+// maxExportRecommendations bounds the recommendations of one bundle.
+const maxExportRecommendations = 100
+
+// ErrInvalidRecommendationExport is returned for an export of unknown, applied or too many recommendations.
+var ErrInvalidRecommendationExport = dataerr.Validation("invalid recommendation export")
+
+// OptimizationRecordStore persists optimization tracking records (optimization_tracking).
+// *postgres.MockRepository satisfies this interface.
+type OptimizationRecordStore interface {
+	SaveOptimizationRecord(ctx context.Context, rec roi.OptimizationTrackingRecord) (roi.OptimizationTrackingRecord, error)
+	ListOptimizationRecords(ctx context.Context, filter postgres.OptimizationRecordFilter) ([]roi.OptimizationTrackingRecord, error)
+}
+
+// RecommendationExportService turns accepted recommendations into patch manifests and tracks them
+// until the reconciliation verifies the change.
+type RecommendationExportService struct {
+	recs    RecommendationLister
+	records OptimizationRecordStore
+	pods    PodLister
+	now     func() time.Time
+}
+
+// NewRecommendationExportService creates a RecommendationExportService.
+func NewRecommendationExportService(recs RecommendationLister, records OptimizationRecordStore) *RecommendationExportService {
+	return &RecommendationExportService{recs: recs, records: records, now: time.Now}
+}
+
+// SetPodLister sets the cluster the running pods of the exported workloads are read from: the
+// workload-level recommendations are divided by their pods and the patches target the container
+// with the largest request. Without it every workload counts as one pod patched in its first container.
+func (s *RecommendationExportService) SetPodLister(pods PodLister) {
+	s.pods = pods
+}
+
+// ManifestBundle is a generated manifest bundle: a zip archive of patches and the tracking records
+// written for it.
+type ManifestBundle struct {
+	ID       string
+	FileName string
+	Data     []byte
+	Records  []roi.OptimizationTrackingRecord
+}
+
+// Export generates the patches of the requested recommendations as a zip archive and records one
+// OptimizationTrackingRecord per recommendation, awaiting verification, implemented by actor.
+//
+// Recommendations are workload totals (the requests of all its pods), so a right-sizing patch sets
+// the per-pod request: the recommended request divided by the running pods of the workload (see
+// SetPodLister). Patches are JSON 6902 patches; decommissions scale the workload to zero.
+func (s *RecommendationExportService) Export(ctx context.Context, req dto.ExportRecommendationsRequest, actor string) (*ManifestBundle, error) {
+	format := req.Format
+	if format == "" {
+		format = ManifestFormatKustomize
+	}
+	if format != ManifestFormatKustomize && format != ManifestFormatKubectl {
+		return nil, fmt.Errorf("%w: format must be %s or %s", ErrInvalidRecommendationExport, ManifestFormatKustomize, ManifestFormatKubectl)
+	}
+	kind := req.WorkloadKind
+	if kind == "" {
+		kind = "Deployment"
+	}
+	if kind != "Deployment" && kind != "StatefulSet" {
+		return nil, fmt.Errorf("%w: workload_kind must be Deployment or StatefulSet", ErrInvalidRecommendationExport)
+	}
+	recs, err := s.selected(ctx, req.RecommendationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	bundle := &ManifestBundle{ID: "bundle-" + uuid.New().String()}
+	bundle.FileName = fmt.Sprintf("lighthouse-recommendations-%s.zip", now.Format("20060102-150405"))
+	patches := make([]manifestPatch, 0, len(recs))
+	for _, r := range recs {
+		target, err := s.target(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, newManifestPatch(r, kind, target))
+	}
+	bundle.Data, err = writeManifestBundle(patches, format, bundle.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("write manifest bundle: %w", err)
+	}
+	for _, p := range patches {
+		rec, err := s.records.SaveOptimizationRecord(ctx, p.record(bundle.ID, actor, now))
+		if err != nil {
+			return nil, fmt.Errorf("record optimization of %s: %w", p.rec.ID, err)
+		}
+		bundle.Records = append(bundle.Records, rec)
+	}
+	return bundle, nil
+}
+
+// ListRecords returns the optimization tracking records of bundleID ("" for all); status is
+// pending, verified or "" for both.
+func (s *RecommendationExportService) ListRecords(ctx context.Context, bundleID, status string) (*dto.OptimizationRecordListResponse, error) {
+	if status != "" && status != OptimizationStatusPending && status != OptimizationStatusVerified {
+		return nil, dataerr.Validation("status must be %s or %s", OptimizationStatusPending, OptimizationStatusVerified)
+	}
+	records, err := s.records.ListOptimizationRecords(ctx, postgres.OptimizationRecordFilter{BundleID: bundleID, Unverified: status == OptimizationStatusPending})
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.OptimizationRecordListResponse{Items: make([]roi.OptimizationTrackingRecord, 0, len(records))}
+	for _, r := range records {
+		if status != OptimizationStatusVerified || r.Verified {
+			resp.Items = append(resp.Items, r)
+		}
+	}
+	resp.TotalEstimate = len(resp.Items)
+	return resp, nil
+}
+
+// selected returns the recommendations of ids in request order; unknown and already applied
+// recommendations are rejected.
+func (s *RecommendationExportService) selected(ctx context.Context, ids []string) ([]postgres.IssuedRecommendation, error) {
+	if len(ids) > maxExportRecommendations {
+		return nil, fmt.Errorf("%w: at most %d recommendations per bundle", ErrInvalidRecommendationExport, maxExportRecommendations)
+	}
+	all, err := s.recs.ListIssuedRecommendations(ctx, postgres.IssuedRecommendationFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list issued recommendations: %w", err)
+	}
+	byID := make(map[string]postgres.IssuedRecommendation, len(all))
+	for _, r := range all {
+		byID[r.ID] = r
+	}
+	out := make([]postgres.IssuedRecommendation, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		r, ok := byID[id]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: unknown recommendation %s", ErrInvalidRecommendationExport, id)
+		case r.Status == string(costmodel.AdoptionApplied):
+			return nil, fmt.Errorf("%w: recommendation %s is already applied", ErrInvalidRecommendationExport, id)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// patchTarget is the container a recommendation is applied to.
+type patchTarget struct {
+	pods      int    // running pods of the workload
+	container int    // index of the container in the pod template
+	name      string // name of the container, "" when unknown
+}
+
+// target returns the running pods of the recommendation's workload and its container with the
+// largest request of the resource; one pod and the first container without a pod lister or pods.
+func (s *RecommendationExportService) target(ctx context.Context, r postgres.IssuedRecommendation) (patchTarget, error) {
+	t := patchTarget{pods: 1}
+	if s.pods == nil {
+		return t, nil
+	}
+	pods, err := s.pods.GetPods(ctx, r.Namespace, r.WorkloadName)
+	if err != nil {
+		return t, fmt.Errorf("list pods of %s/%s: %w", r.Namespace, r.WorkloadName, err)
+	}
+	running := 0
+	var first *k8s.Pod
+	for i := range pods {
+		if pods[i].Phase == "Running" {
+			running++
+			if first == nil {
+				first = &pods[i]
+			}
+		}
+	}
+	if first == nil {
+		return t, nil
+	}
+	t.pods = running
+	largest := -1.0
+	for i, c := range first.Containers {
+		v, _ := k8s.ParseQuantity(c.Resources.Requests[r.Resource])
+		if v > largest {
+			largest, t.container, t.name = v, i, c.Name
+		}
+	}
+	return t, nil
+}
+
+// manifestPatch is the patch of one recommendation.
+type manifestPatch struct {
+	rec    postgres.IssuedRecommendation
+	kind   string
+	target patchTarget
+	path   string // JSON pointer of the patched field
+	value  string // new value of the field
+	before string // current value, for the comments of the patch
+	file   string // path of the patch in the bundle
+}
+
+func newManifestPatch(r postgres.IssuedRecommendation, kind string, target patchTarget) manifestPatch {
+	p := manifestPatch{rec: r, kind: kind, target: target}
+	p.file = fmt.Sprintf("patches/%s-%s-%s.yaml", r.Namespace, r.WorkloadName, r.Resource)
+	pods := float64(target.pods)
+	switch {
+	case r.Action == string(costmodel.ActionDecommission):
+		p.path, p.value, p.before = "/spec/replicas", "0", fmt.Sprint(target.pods)
+	case r.Resource == "memory":
+		p.path = fmt.Sprintf("/spec/template/spec/containers/%d/resources/requests/memory", target.container)
+		p.value, p.before = k8s.FormatMemory(r.RecommendedRequest/pods), k8s.FormatMemory(r.BaselineRequest/pods)
+	default:
+		p.path = fmt.Sprintf("/spec/template/spec/containers/%d/resources/requests/cpu", target.container)
+		p.value, p.before = k8s.FormatCPU(r.RecommendedRequest/pods), k8s.FormatCPU(r.BaselineRequest/pods)
+	}
+	return p
+}
+
+// yaml returns the patch file: a JSON 6902 patch in YAML.
+func (p manifestPatch) yaml() string {
+	var b strings.Builder
+	if p.path == "/spec/replicas" {
+		fmt.Fprintf(&b, "# %s %s/%s (%s): replicas %s -> 0\n", p.rec.Action, p.rec.Namespace, p.rec.WorkloadName, p.kind, p.before)
+	} else {
+		fmt.Fprintf(&b, "# %s %s of %s/%s (%s): %s -> %s per pod of %d\n", p.rec.Action, p.rec.Resource, p.rec.Namespace, p.rec.WorkloadName, p.kind, p.before, p.value, p.target.pods)
+		if p.target.name != "" {
+			fmt.Fprintf(&b, "# container %d (%s) of the running pods\n", p.target.container, p.target.name)
+		} else {
+			b.WriteString("# pods unknown: the first container is patched, adjust the path for multi-container pods\n")
+		}
+	}
+	fmt.Fprintf(&b, "# recommendation %s, estimated monthly savings %.2f\n", p.rec.ID, p.rec.EstimatedMonthlySavings)
+	if p.path == "/spec/replicas" {
+		b.WriteString("- op: add\n  path: /spec/replicas\n  value: 0\n")
+	} else {
+		fmt.Fprintf(&b, "- op: add\n  path: %s\n  value: %q\n", p.path, p.value)
+	}
+	return b.String()
+}
+
+// record returns the tracking record of the patch, awaiting verification.
+func (p manifestPatch) record(bundleID, actor string, now time.Time) roi.OptimizationTrackingRecord {
+	r := p.rec
+	rec := roi.OptimizationTrackingRecord{
+		OptimizationType:   OptimizationTypeRightsizing,
+		TargetResourceID:   fmt.Sprintf("%s/%s/%s", r.Namespace, p.kind, r.WorkloadName),
+		TargetResourceType: "workload",
+		RecommendationID:   r.ID,
+		BundleID:           bundleID,
+		BeforeState:        map[string]interface{}{"resource": r.Resource, "request": r.BaselineRequest, "pods": p.target.pods},
+		AfterState:         map[string]interface{}{"resource": r.Resource, "request": r.RecommendedRequest},
+		ProjectedSavings:   math.Round(r.EstimatedMonthlySavings*12*100) / 100,
+		ResourcesRecovered: map[string]float64{},
+		ImplementationDate: now,
+		ImplementedBy:      actor,
+	}
+	switch {
+	case r.Action == string(costmodel.ActionDecommission):
+		rec.OptimizationType = OptimizationTypeZombieCleanup
+		rec.AfterState["pods"] = 0
+	case r.Resource == "memory" && r.BaselineRequest > r.RecommendedRequest:
+		rec.ResourcesRecovered["memory"] = (r.BaselineRequest - r.RecommendedRequest) / (1 << 30)
+	case r.Resource == "cpu" && r.BaselineRequest > r.RecommendedRequest:
+		rec.ResourcesRecovered["cpu"] = r.BaselineRequest - r.RecommendedRequest
+	}
+	return rec
+}
+
+// writeManifestBundle writes the patches and, per format, the kustomization.yaml or apply.sh.
+func writeManifestBundle(patches []manifestPatch, format, bundleID string, now time.Time) ([]byte, error) {
+	patches = append([]manifestPatch(nil), patches...)
+	sort.SliceStable(patches, func(i, j int) bool { return patches[i].file < patches[j].file })
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name, content string) error {
+		h := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now}
+		h.SetMode(0o644)
+		if strings.HasSuffix(name, ".sh") {
+			h.SetMode(0o755)
+		}
+		w, err := zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(content))
+		return err
+	}
+	header := fmt.Sprintf("# Generated by Lighthouse at %s (bundle %s)\n", now.Format(time.RFC3339), bundleID)
+	var index strings.Builder
+	index.WriteString(header)
+	if format == ManifestFormatKustomize {
+		index.WriteString("# List the base manifests of the patched workloads under resources, then: kubectl apply -k .\n")
+		index.WriteString("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n  # - ../base\npatches:\n")
+	} else {
+		index.WriteString("#!/bin/sh\nset -e\ncd \"$(dirname \"$0\")\"\n")
+	}
+	for _, p := range patches {
+		if err := add(p.file, p.yaml()); err != nil {
+			return nil, err
+		}
+		if format == ManifestFormatKustomize {
+			fmt.Fprintf(&index, "  - path: %s\n    target:\n      kind: %s\n      namespace: %s\n      name: %s\n", p.file, p.kind, p.rec.Namespace, p.rec.WorkloadName)
+		} else {
+			fmt.Fprintf(&index, "kubectl -n %s patch %s %s --type=json --patch-file %s\n", p.rec.Namespace, strings.ToLower(p.kind), p.rec.WorkloadName, p.file)
+		}
+	}
+	name := "kustomization.yaml"
+	if format == ManifestFormatKubectl {
+		name = "apply.sh"
+	}
+	if err := add(name, index.String()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
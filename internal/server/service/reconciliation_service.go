@@ -0,0 +1,156 @@
+// Package service reconciliation_service.go: 建模成本 vs 云账单对账。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// DefaultDriftThresholdPercent is the discrepancy above which a period is flagged.
+const DefaultDriftThresholdPercent = 10.0
+
+// BillSummaryLister lists stored account bill summaries (cost_bill_account_summary).
+// *postgres.MockRepository satisfies this interface.
+type BillSummaryLister interface {
+	ListBillAccountSummaries(ctx context.Context, accountID string) ([]postgres.BillAccountSummary, error)
+}
+
+// ReconciliationConfig controls drift detection.
+type ReconciliationConfig struct {
+	// DriftThresholdPercent flags a category when |discrepancy| exceeds it. <= 0 uses the default.
+	DriftThresholdPercent float64
+	// ModeledCategories are the bill categories Lighthouse models; only these are checked for drift.
+	// Empty defaults to ["compute"], since node cost is derived from CPU/memory pricing.
+	ModeledCategories []string
+}
+
+// CategoryReconciliation is the modeled vs invoiced comparison for one bill category.
+type CategoryReconciliation struct {
+	Category           string  `json:"category"`
+	ModeledCost        float64 `json:"modeled_cost"`
+	InvoicedCost       float64 `json:"invoiced_cost"`
+	CoveragePercent    float64 `json:"coverage_percent"`    // modeled / invoiced * 100
+	DiscrepancyPercent float64 `json:"discrepancy_percent"` // (modeled - invoiced) / invoiced * 100
+	Drift              bool    `json:"drift"`
+}
+
+// PeriodReconciliation is the reconciliation result for one account billing period.
+type PeriodReconciliation struct {
+	AccountID   string                   `json:"account_id"`
+	PeriodType  string                   `json:"period_type"`
+	PeriodStart time.Time                `json:"period_start"`
+	PeriodEnd   time.Time                `json:"period_end"`
+	Currency    string                   `json:"currency"`
+	Categories  []CategoryReconciliation `json:"categories"`
+	Drift       bool                     `json:"drift"`
+}
+
+// ReconciliationService compares Lighthouse-modeled node cost with invoiced cloud bills.
+type ReconciliationService struct {
+	repo   postgres.Repository
+	bills  BillSummaryLister
+	config ReconciliationConfig
+}
+
+// NewReconciliationService creates a ReconciliationService.
+func NewReconciliationService(repo postgres.Repository, bills BillSummaryLister, config ReconciliationConfig) *ReconciliationService {
+	if config.DriftThresholdPercent <= 0 {
+		config.DriftThresholdPercent = DefaultDriftThresholdPercent
+	}
+	if len(config.ModeledCategories) == 0 {
+		config.ModeledCategories = []string{"compute"}
+	}
+	return &ReconciliationService{repo: repo, bills: bills, config: config}
+}
+
+// Reconcile returns one result per stored bill period of the account, newest first.
+func (s *ReconciliationService) Reconcile(ctx context.Context, accountID string) ([]PeriodReconciliation, error) {
+	summaries, err := s.bills.ListBillAccountSummaries(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PeriodReconciliation, 0, len(summaries))
+	for _, summary := range summaries {
+		r, err := s.ReconcilePeriod(ctx, summary)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *r)
+	}
+	return results, nil
+}
+
+// ReconcilePeriod compares one bill summary with the modeled cost of the same period.
+// Modeled cost is the billable cost of hourly workload stats within [PeriodStart, PeriodEnd].
+func (s *ReconciliationService) ReconcilePeriod(ctx context.Context, summary postgres.BillAccountSummary) (*PeriodReconciliation, error) {
+	// PeriodEnd 为账期最后一天，包含当天全部小时
+	end := summary.PeriodEnd.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	stats, err := s.repo.AggregateHourlyWorkloadStats(ctx, summary.PeriodStart, end)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate modeled cost: %w", err)
+	}
+	var modeledCompute float64
+	for _, st := range stats {
+		modeledCompute += st.TotalBillableCost
+	}
+
+	modeled := make(map[string]float64, len(s.config.ModeledCategories))
+	for _, c := range s.config.ModeledCategories {
+		modeled[c] = 0
+	}
+	if _, ok := modeled["compute"]; ok {
+		modeled["compute"] = modeledCompute
+	}
+
+	categories := make(map[string]struct{})
+	for c := range summary.ByCategory {
+		categories[c] = struct{}{}
+	}
+	for c := range modeled {
+		categories[c] = struct{}{}
+	}
+	names := make([]string, 0, len(categories))
+	for c := range categories {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+
+	result := &PeriodReconciliation{
+		AccountID:   summary.AccountID,
+		PeriodType:  summary.PeriodType,
+		PeriodStart: summary.PeriodStart,
+		PeriodEnd:   summary.PeriodEnd,
+		Currency:    summary.Currency,
+		Categories:  make([]CategoryReconciliation, 0, len(names)),
+	}
+	for _, c := range names {
+		m, isModeled := modeled[c]
+		cr := compareCategory(c, m, summary.ByCategory[c])
+		cr.Drift = isModeled && math.Abs(cr.DiscrepancyPercent) > s.config.DriftThresholdPercent
+		if cr.Drift {
+			result.Drift = true
+		}
+		result.Categories = append(result.Categories, cr)
+	}
+	return result, nil
+}
+
+// compareCategory computes coverage and discrepancy. With no invoiced amount, any modeled
+// cost is treated as 100% discrepancy.
+func compareCategory(category string, modeled, invoiced float64) CategoryReconciliation {
+	cr := CategoryReconciliation{Category: category, ModeledCost: modeled, InvoicedCost: invoiced}
+	switch {
+	case invoiced > 0:
+		cr.CoveragePercent = modeled / invoiced * 100
+		cr.DiscrepancyPercent = (modeled - invoiced) / invoiced * 100
+	case modeled > 0:
+		cr.DiscrepancyPercent = 100
+	}
+	return cr
+}
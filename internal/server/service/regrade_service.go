@@ -0,0 +1,248 @@
+// Package service regrade_service.go: 效率阈值调整后的历史快照重评级。按新阈值重算快照中每个资源的等级与各等级数量，
+// 结果单独保存（原快照及其哈希链不变），快照等级接口按开关提供原始或重评级视图，避免新旧阈值的僵尸/健康数量混在一起。
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Views of snapshot grade counts.
+const (
+	GradeViewOriginal = "original"
+	GradeViewRegraded = "regraded"
+)
+
+// gradeViewKey is the metadata key of the grade view set at runtime.
+const gradeViewKey = "grade_view"
+
+// ErrInvalidRegrade is returned for invalid regrade thresholds or an unknown grade view.
+var ErrInvalidRegrade = dataerr.Validation("invalid regrade request")
+
+// SnapshotRegradeStore persists the regraded views of cost snapshots (cost_snapshot_regrade).
+// *postgres.MockRepository satisfies this interface.
+type SnapshotRegradeStore interface {
+	SaveSnapshotRegrade(ctx context.Context, regrade postgres.SnapshotRegrade) error
+	GetSnapshotRegrade(ctx context.Context, snapshotID string) (*postgres.SnapshotRegrade, error)
+}
+
+// RegradeService regrades stored cost snapshots under new efficiency thresholds and serves their
+// grade counts in the original or the regraded view.
+type RegradeService struct {
+	repo        postgres.Repository
+	store       SnapshotRegradeStore
+	thresholds  costmodel.GradeThresholds
+	defaultView string
+	now         func() time.Time
+}
+
+// NewRegradeService creates a RegradeService; thresholds are the default thresholds of a regrade.
+func NewRegradeService(repo postgres.Repository, store SnapshotRegradeStore, thresholds costmodel.GradeThresholds) *RegradeService {
+	return &RegradeService{repo: repo, store: store, thresholds: thresholds, defaultView: GradeViewOriginal, now: time.Now}
+}
+
+// SetDefaultView sets the view served until one is set with SetView (default original).
+func (s *RegradeService) SetDefaultView(view string) error {
+	if err := validateGradeView(view); err != nil {
+		return err
+	}
+	s.defaultView = view
+	return nil
+}
+
+// View returns the view currently served.
+func (s *RegradeService) View(ctx context.Context) (*dto.GradeView, error) {
+	m, err := s.repo.GetMetadata(ctx, gradeViewKey)
+	if errors.Is(err, dataerr.ErrNotFound) {
+		return &dto.GradeView{View: s.defaultView}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get grade view: %w", err)
+	}
+	view, _ := m.Value["view"].(string)
+	if validateGradeView(view) != nil {
+		return &dto.GradeView{View: s.defaultView}, nil
+	}
+	return &dto.GradeView{View: view, SetBy: m.CreatedBy, UpdatedAt: m.UpdatedAt}, nil
+}
+
+// SetView switches the view served; it is kept in metadata and survives restarts.
+func (s *RegradeService) SetView(ctx context.Context, req dto.SetGradeViewRequest) (*dto.GradeView, error) {
+	if err := validateGradeView(req.View); err != nil {
+		return nil, err
+	}
+	m := postgres.Metadata{
+		Key:         gradeViewKey,
+		Value:       map[string]interface{}{"view": req.View},
+		Description: "view of snapshot grade counts (original / regraded)",
+		CreatedBy:   req.SetBy,
+	}
+	if err := s.repo.SaveMetadata(ctx, m); err != nil {
+		return nil, fmt.Errorf("save grade view: %w", err)
+	}
+	return &dto.GradeView{View: req.View, SetBy: req.SetBy, UpdatedAt: s.now().UTC()}, nil
+}
+
+// Regrade recomputes the grade of every resource result of the snapshots in the window with the
+// thresholds of req (default the configured ones) and saves the regraded view of each snapshot,
+// replacing an earlier regrade. The snapshots themselves are not modified.
+func (s *RegradeService) Regrade(ctx context.Context, req dto.RegradeRequest) (*dto.RegradeResponse, error) {
+	thresholds := s.thresholds
+	if req.Thresholds != nil {
+		thresholds = *req.Thresholds
+	}
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRegrade, err)
+	}
+	if !req.StartTime.IsZero() && !req.EndTime.IsZero() && !req.EndTime.After(req.StartTime) {
+		return nil, ErrInvalidWindow
+	}
+	snapshots, err := s.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{StartTime: req.StartTime, EndTime: req.EndTime})
+	if err != nil {
+		return nil, fmt.Errorf("list cost snapshots: %w", err)
+	}
+	sortSnapshotsByTime(snapshots)
+
+	resp := &dto.RegradeResponse{
+		JobID:      "regrade-" + uuid.New().String(),
+		DryRun:     req.DryRun,
+		Thresholds: thresholds,
+		Snapshots:  []dto.SnapshotRegradeResult{},
+		StartedAt:  s.now().UTC(),
+	}
+	for _, snap := range snapshots {
+		if len(snap.ResourceResults) == 0 {
+			resp.Skipped++
+			continue
+		}
+		regrade := regradeSnapshot(snap, thresholds)
+		regrade.JobID = resp.JobID
+		regrade.RegradedBy = req.RegradedBy
+		regrade.RegradedAt = resp.StartedAt
+		if !req.DryRun {
+			if err := s.store.SaveSnapshotRegrade(ctx, regrade); err != nil {
+				return nil, fmt.Errorf("save regrade of snapshot %s: %w", snap.ID, err)
+			}
+		}
+		result := dto.SnapshotRegradeResult{
+			SnapshotID: snap.ID,
+			Timestamp:  snap.Timestamp,
+			Resources:  len(snap.ResourceResults),
+			Original:   snapshotGradeCounts(snap),
+			Regraded:   regradeGradeCounts(regrade),
+		}
+		result.Changed = result.Original != result.Regraded
+		if result.Changed {
+			resp.Changed++
+		}
+		resp.Regraded++
+		resp.Original = addGradeCounts(resp.Original, result.Original)
+		resp.Total = addGradeCounts(resp.Total, result.Regraded)
+		resp.Snapshots = append(resp.Snapshots, result)
+	}
+	resp.FinishedAt = s.now().UTC()
+	return resp, nil
+}
+
+// SnapshotGrades returns the grade counts of the snapshots in start..end (zero: unbounded) in view
+// (empty: the view currently served), oldest first. Snapshots awaiting confirmation are left out.
+func (s *RegradeService) SnapshotGrades(ctx context.Context, start, end time.Time, view string) (*dto.SnapshotGradesResponse, error) {
+	if view == "" {
+		current, err := s.View(ctx)
+		if err != nil {
+			return nil, err
+		}
+		view = current.View
+	}
+	if err := validateGradeView(view); err != nil {
+		return nil, err
+	}
+	snapshots, err := s.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{StartTime: start, EndTime: end, ExcludeUnconfirmed: true, Fields: postgres.SnapshotFieldCounts})
+	if err != nil {
+		return nil, fmt.Errorf("list cost snapshots: %w", err)
+	}
+	sortSnapshotsByTime(snapshots)
+
+	resp := &dto.SnapshotGradesResponse{View: view, Snapshots: make([]dto.SnapshotGrades, 0, len(snapshots))}
+	for _, snap := range snapshots {
+		g := dto.SnapshotGrades{SnapshotID: snap.ID, Timestamp: snap.Timestamp, Counts: snapshotGradeCounts(snap)}
+		if view == GradeViewRegraded {
+			regrade, err := s.store.GetSnapshotRegrade(ctx, snap.ID)
+			switch {
+			case errors.Is(err, dataerr.ErrNotFound):
+			case err != nil:
+				return nil, fmt.Errorf("get regrade of snapshot %s: %w", snap.ID, err)
+			default:
+				thresholds := regrade.Thresholds
+				g.Regraded = true
+				g.Thresholds = &thresholds
+				g.Counts = regradeGradeCounts(*regrade)
+			}
+		}
+		resp.Total = addGradeCounts(resp.Total, g.Counts)
+		resp.Snapshots = append(resp.Snapshots, g)
+	}
+	return resp, nil
+}
+
+// regradeSnapshot grades every resource result of snap with thresholds.
+func regradeSnapshot(snap postgres.CostSnapshot, thresholds costmodel.GradeThresholds) postgres.SnapshotRegrade {
+	regrade := postgres.SnapshotRegrade{
+		SnapshotID: snap.ID,
+		Thresholds: thresholds,
+		Grades:     make([]costmodel.EfficiencyGrade, len(snap.ResourceResults)),
+	}
+	for i, r := range snap.ResourceResults {
+		grade := thresholds.Grade(r.OverallEfficiencyScore)
+		regrade.Grades[i] = grade
+		switch grade {
+		case costmodel.GradeZombie:
+			regrade.ZombieCount++
+		case costmodel.GradeOverProvisioned:
+			regrade.OverProvisionedCount++
+		case costmodel.GradeRisk:
+			regrade.RiskCount++
+		default:
+			regrade.HealthyCount++
+		}
+	}
+	return regrade
+}
+
+func validateGradeView(view string) error {
+	switch view {
+	case GradeViewOriginal, GradeViewRegraded:
+		return nil
+	}
+	return fmt.Errorf("%w: view must be %s or %s, got %q", ErrInvalidRegrade, GradeViewOriginal, GradeViewRegraded, view)
+}
+
+func sortSnapshotsByTime(snapshots []postgres.CostSnapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Timestamp.Before(snapshots[j].Timestamp) })
+}
+
+func snapshotGradeCounts(s postgres.CostSnapshot) dto.GradeCounts {
+	return dto.GradeCounts{Zombie: s.ZombieCount, OverProvisioned: s.OverProvisionedCount, Healthy: s.HealthyCount, Risk: s.RiskCount}
+}
+
+func regradeGradeCounts(r postgres.SnapshotRegrade) dto.GradeCounts {
+	return dto.GradeCounts{Zombie: r.ZombieCount, OverProvisioned: r.OverProvisionedCount, Healthy: r.HealthyCount, Risk: r.RiskCount}
+}
+
+func addGradeCounts(a, b dto.GradeCounts) dto.GradeCounts {
+	return dto.GradeCounts{
+		Zombie:          a.Zombie + b.Zombie,
+		OverProvisioned: a.OverProvisioned + b.OverProvisioned,
+		Healthy:         a.Healthy + b.Healthy,
+		Risk:            a.Risk + b.Risk,
+	}
+}
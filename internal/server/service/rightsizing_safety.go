@@ -0,0 +1,92 @@
+// Package service rightsizing_safety.go: 规格缩减建议的安全检查信号——Pod 近期 CPU 限流率与同名服务的 SLO 状态，
+// 由 costmodel.CheckSafety 决定建议照常提出、标记复核或抑制。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// DefaultSafetyLookback is how far back throttling is measured before requests are reduced.
+const DefaultSafetyLookback = 24 * time.Hour
+
+// ThrottlingSource queries the CPU throttling of pods. prometheus.Client satisfies this interface.
+type ThrottlingSource interface {
+	GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]prometheus.ThrottlingMetric, error)
+}
+
+// RightsizingSafety gathers the safety signals of workloads and checks their reduction recommendations.
+type RightsizingSafety struct {
+	throttling ThrottlingSource
+	slo        SLOStatusProvider
+	limits     costmodel.SafetyLimits
+	lookback   time.Duration
+	now        func() time.Time
+}
+
+// NewRightsizingSafety creates a RightsizingSafety; either source may be nil to skip its check.
+func NewRightsizingSafety(throttling ThrottlingSource, slo SLOStatusProvider, limits costmodel.SafetyLimits) *RightsizingSafety {
+	return &RightsizingSafety{throttling: throttling, slo: slo, limits: limits, lookback: DefaultSafetyLookback, now: time.Now}
+}
+
+// Signals returns the safety signals of a workload: the average throttling rate of the given pods
+// over the lookback (nil without pods or samples) and the SLO of the service named like the workload.
+func (s *RightsizingSafety) Signals(ctx context.Context, namespace, workload string, pods []string) (costmodel.SafetySignals, error) {
+	var signals costmodel.SafetySignals
+	if s.throttling != nil && len(pods) > 0 {
+		end := s.now()
+		var sum float64
+		var n int
+		for _, pod := range pods {
+			metrics, err := s.throttling.GetThrottlingMetrics(ctx, namespace, pod, end.Add(-s.lookback), end)
+			if err != nil {
+				return signals, fmt.Errorf("query throttling of %s/%s: %w", namespace, pod, err)
+			}
+			for _, m := range metrics {
+				sum += m.ThrottlingRate
+				n++
+			}
+		}
+		if n > 0 {
+			rate := math.Round(sum/float64(n)*100) / 100
+			signals.CPUThrottlingRate = &rate
+		}
+	}
+	if s.slo != nil {
+		services, err := s.slo.ListSLOStatus(ctx)
+		if err != nil {
+			return signals, fmt.Errorf("list SLO status: %w", err)
+		}
+		for _, svc := range services {
+			if svc.Namespace == namespace && svc.Service == workload {
+				budget := svc.ErrorBudgetRemaining
+				signals.SLOStatus, signals.ErrorBudgetRemaining = string(svc.Status), &budget
+				break
+			}
+		}
+	}
+	return signals, nil
+}
+
+// Check runs the safety checks on recs (see costmodel.CheckSafety). Signals are only gathered when
+// recs contain a reduction.
+func (s *RightsizingSafety) Check(ctx context.Context, namespace, workload string, pods []string, recs []costmodel.Recommendation) (proposed, suppressed []costmodel.Recommendation, err error) {
+	reduces := false
+	for _, r := range recs {
+		reduces = reduces || r.Action == costmodel.ActionDownsize || r.Action == costmodel.ActionDecommission
+	}
+	if !reduces {
+		return recs, nil, nil
+	}
+	signals, err := s.Signals(ctx, namespace, workload, pods)
+	if err != nil {
+		return nil, nil, err
+	}
+	proposed, suppressed = costmodel.CheckSafety(recs, signals, s.limits)
+	return proposed, suppressed, nil
+}
@@ -0,0 +1,206 @@
+// Package service roi_comparison.go: 任意一天对任意已存 ROI 基线的按需对比（不限于当前生效基线），
+// 用于“如果从一月开始优化”之类的假设分析。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/roi"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// ErrInvalidROIComparison is returned for a comparison date in the future.
+var ErrInvalidROIComparison = dataerr.Validation("invalid ROI comparison")
+
+// ROIComparisonService compares the metrics of a day against stored ROI baselines.
+type ROIComparisonService struct {
+	repo     postgres.Repository
+	calendar costmodel.AccountingCalendar
+	now      func() time.Time
+}
+
+// NewROIComparisonService creates a ROIComparisonService.
+func NewROIComparisonService(repo postgres.Repository) *ROIComparisonService {
+	return &ROIComparisonService{repo: repo, now: time.Now}
+}
+
+// SetCalendar sets the accounting time zone of the compared days (default UTC).
+func (s *ROIComparisonService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+// Compare returns the DailyComparison of the day labelled date (default yesterday, the last complete
+// day) against baseline id. The baseline snapshot takes the metrics pinned in the baseline and
+// derives the others from the daily averages over its period.
+func (s *ROIComparisonService) Compare(ctx context.Context, id string, date time.Time) (*dto.ROIBaselineComparisonResponse, error) {
+	today := s.calendar.Date(s.now())
+	if date.IsZero() {
+		date = today.AddDate(0, 0, -1)
+	}
+	date = date.UTC().Truncate(24 * time.Hour)
+	if date.After(today) {
+		return nil, fmt.Errorf("%w: date %s is in the future", ErrInvalidROIComparison, date.Format("2006-01-02"))
+	}
+	baseline, err := s.repo.GetROIBaseline(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current, days, err := s.snapshot(ctx, date, date)
+	if err != nil {
+		return nil, err
+	}
+	if days == 0 {
+		return nil, dataerr.NotFound("no cost data for %s", date.Format("2006-01-02"))
+	}
+	current.SnapshotID = date.Format("2006-01-02")
+	current.Timestamp = date
+
+	first := s.calendar.Date(baseline.TimePeriodStart)
+	last := first
+	if baseline.TimePeriodEnd.After(baseline.TimePeriodStart) {
+		last = s.calendar.Date(baseline.TimePeriodEnd.Add(-time.Nanosecond))
+	}
+	derived, baselineDays, err := s.snapshot(ctx, first, last)
+	if err != nil {
+		return nil, err
+	}
+	base, pinned := pinBaseline(derived, baseline.Metrics)
+	if baselineDays == 0 && len(pinned) == 0 {
+		return nil, dataerr.NotFound("baseline %s has no pinned metrics and no cost data in its period", id)
+	}
+	base.SnapshotID = baseline.ID
+	base.Timestamp = baseline.TimePeriodStart
+
+	return &dto.ROIBaselineComparisonResponse{
+		BaselineID:          baseline.ID,
+		BaselineName:        baseline.Name,
+		BaselineType:        baseline.BaselineType,
+		BaselinePeriodStart: baseline.TimePeriodStart,
+		BaselinePeriodEnd:   baseline.TimePeriodEnd,
+		PinnedMetrics:       pinned,
+		Baseline:            base,
+		Current:             current,
+		Comparison:          roi.Compare(base, current, date),
+	}, nil
+}
+
+// pinBaseline overrides the fields of snap pinned in metrics and returns the pinned keys, sorted.
+func pinBaseline(snap roi.BaselineSnapshot, metrics map[string]float64) (roi.BaselineSnapshot, []string) {
+	pinned := []string{}
+	for key, v := range metrics {
+		switch key {
+		case roi.MetricCPUUtilization:
+			snap.CPUUtilization = v
+		case roi.MetricMemUtilization:
+			snap.MemUtilization = v
+		case roi.MetricTotalWasteAmount:
+			snap.TotalWasteAmount = v
+		case roi.MetricTotalBillableCost:
+			snap.TotalBillableCost = v
+		case roi.MetricNodeCount:
+			snap.NodeCount = int(math.Round(v))
+		case roi.MetricZombieAssetCount:
+			snap.ZombieAssetCount = int(math.Round(v))
+		default:
+			continue
+		}
+		pinned = append(pinned, key)
+	}
+	sort.Strings(pinned)
+	return snap, pinned
+}
+
+// snapshot returns the daily averages of the accounting days first..last (inclusive) that have
+// namespace costs, and the number of those days. Utilizations are P95 usage over requests (%) of
+// the whole window; nodes and zombie workloads (graded by the default thresholds) are counted per day.
+func (s *ROIComparisonService) snapshot(ctx context.Context, first, last time.Time) (roi.BaselineSnapshot, int, error) {
+	var snap roi.BaselineSnapshot
+	costs, err := s.repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{StartDate: first, EndDate: last})
+	if err != nil {
+		return snap, 0, fmt.Errorf("list daily namespace costs: %w", err)
+	}
+	days := make(map[time.Time]bool)
+	for _, c := range costs {
+		days[c.Date.UTC().Truncate(24*time.Hour)] = true
+		snap.TotalBillableCost += c.BillableCost
+		snap.TotalWasteAmount += c.WasteCost
+	}
+	if len(days) == 0 {
+		return snap, 0, nil
+	}
+	n := float64(len(days))
+	snap.TotalBillableCost = math.Round(snap.TotalBillableCost/n*100) / 100
+	snap.TotalWasteAmount = math.Round(snap.TotalWasteAmount/n*100) / 100
+
+	start, _ := s.calendar.Bounds(first)
+	_, end := s.calendar.Bounds(last)
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: start, EndTime: end})
+	if err != nil {
+		return snap, 0, fmt.Errorf("list hourly workload stats: %w", err)
+	}
+	type workloadDay struct {
+		cpuReq, cpuUse, memReq, memUse, cpuCost, memCost float64
+	}
+	var cpuReq, cpuUse, memReq, memUse float64
+	nodes := make(map[string]bool)
+	workloads := make(map[string]*workloadDay)
+	for _, st := range stats {
+		if !st.Timestamp.Before(end) {
+			continue
+		}
+		day := s.calendar.Date(st.Timestamp).Format("2006-01-02")
+		cpuReq += st.CPURequest
+		cpuUse += st.CPUUsageP95
+		memReq += float64(st.MemRequest)
+		memUse += float64(st.MemUsageP95)
+		if st.NodeName != "" {
+			nodes[day+"/"+st.NodeName] = true
+		}
+		key := day + "/" + st.Namespace + "/" + st.WorkloadName
+		w, ok := workloads[key]
+		if !ok {
+			w = &workloadDay{}
+			workloads[key] = w
+		}
+		w.cpuReq += st.CPURequest
+		w.cpuUse += st.CPUUsageP95
+		w.memReq += float64(st.MemRequest)
+		w.memUse += float64(st.MemUsageP95)
+		w.cpuCost += st.CPUBillableCost
+		w.memCost += st.MemBillableCost
+	}
+	if cpuReq > 0 {
+		snap.CPUUtilization = math.Round(cpuUse/cpuReq*10000) / 100
+	}
+	if memReq > 0 {
+		snap.MemUtilization = math.Round(memUse/memReq*10000) / 100
+	}
+	zombies := 0
+	for _, w := range workloads {
+		cpuScore, memScore := 100.0, 100.0
+		if w.cpuReq > 0 {
+			cpuScore = w.cpuUse / w.cpuReq * 100
+		}
+		if w.memReq > 0 {
+			memScore = w.memUse / w.memReq * 100
+		}
+		score := 100.0
+		if total := w.cpuCost + w.memCost; total > 0 {
+			score = (cpuScore*w.cpuCost + memScore*w.memCost) / total
+		}
+		if costmodel.DefaultGradeThresholds.Grade(score) == costmodel.GradeZombie {
+			zombies++
+		}
+	}
+	snap.NodeCount = int(math.Round(float64(len(nodes)) / n))
+	snap.ZombieAssetCount = int(math.Round(float64(zombies) / n))
+	return snap, len(days), nil
+}
@@ -0,0 +1,215 @@
+// Package service self_slo.go: Lighthouse 自身 API 的 SLO——按路由从请求计数（即 /metrics 中的
+// lighthouse_http_* 指标）自动定义可用性与 P95 延迟 SLO，用 biz/slo 的同一评估逻辑计算错误预算与状态，
+// 供自观测页面与 SLOStatusProvider 使用。
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/biz/slo"
+	"github.com/myxxhui/lighthouse-src/internal/server/apimetrics"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// SelfSLONamespace is the namespace of the self-SLOs in SLOServiceStatus; the service is "METHOD route".
+const SelfSLONamespace = "lighthouse"
+
+const (
+	defaultSelfSLOWindow   = 24 * time.Hour
+	defaultSelfSLOInterval = time.Minute
+)
+
+// SelfSLOTarget overrides the default targets for one route; zero fields keep the default.
+type SelfSLOTarget struct {
+	AvailabilityTarget float64 // %
+	LatencyTargetMs    float64 // P95
+}
+
+type selfSLOSnapshot struct {
+	at     time.Time
+	counts map[apimetrics.Route]apimetrics.Counts
+}
+
+// SelfSLOService evaluates the SLOs of the API routes recorded by an apimetrics.Recorder over a
+// sliding window. Run keeps periodic snapshots of the counters; the window is the increase since
+// the snapshot taken a window ago (since start while the server is younger than the window).
+type SelfSLOService struct {
+	rec                *apimetrics.Recorder
+	availabilityTarget float64
+	latencyTargetMs    float64
+	routes             map[string]SelfSLOTarget
+	window             time.Duration
+	started            time.Time
+	now                func() time.Time
+
+	mu        sync.Mutex
+	snapshots []selfSLOSnapshot // oldest first
+}
+
+// NewSelfSLOService creates a SelfSLOService for the routes of rec with default targets
+// availabilityTarget (%) and latencyTargetMs (P95) and a 24h window.
+func NewSelfSLOService(rec *apimetrics.Recorder, availabilityTarget, latencyTargetMs float64) *SelfSLOService {
+	return &SelfSLOService{
+		rec:                rec,
+		availabilityTarget: availabilityTarget,
+		latencyTargetMs:    latencyTargetMs,
+		window:             defaultSelfSLOWindow,
+		started:            time.Now(),
+		now:                time.Now,
+	}
+}
+
+// SetRouteTargets sets per-route targets keyed by "METHOD /path" (the registered path pattern).
+func (s *SelfSLOService) SetRouteTargets(targets map[string]SelfSLOTarget) {
+	s.routes = targets
+}
+
+// SetWindow sets the evaluation window (default 24h).
+func (s *SelfSLOService) SetWindow(window time.Duration) {
+	if window > 0 {
+		s.window = window
+	}
+}
+
+// Snapshot records the current counters and drops snapshots no longer needed as a window start.
+func (s *SelfSLOService) Snapshot() {
+	now := s.now()
+	snap := selfSLOSnapshot{at: now, counts: s.rec.Snapshot()}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots = append(s.snapshots, snap)
+	// Keep the newest snapshot at or before the window start and everything after it
+	cutoff := now.Add(-s.window)
+	drop := 0
+	for drop+1 < len(s.snapshots) && !s.snapshots[drop+1].at.After(cutoff) {
+		drop++
+	}
+	s.snapshots = s.snapshots[drop:]
+}
+
+// Run takes a snapshot now and then every interval (default 1 minute) until ctx is done; the
+// interval is the resolution of the window start.
+func (s *SelfSLOService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSelfSLOInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.Snapshot()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate returns the SLO evaluation of every route that served a request since start, worst first.
+func (s *SelfSLOService) Evaluate(ctx context.Context) (*dto.SelfSLOResponse, error) {
+	end := s.now()
+	start, base := s.started, map[apimetrics.Route]apimetrics.Counts(nil)
+	cutoff := end.Add(-s.window)
+	s.mu.Lock()
+	for _, snap := range s.snapshots {
+		if snap.at.After(cutoff) {
+			break
+		}
+		start, base = snap.at, snap.counts
+	}
+	s.mu.Unlock()
+	if start.Before(cutoff) {
+		start = cutoff
+	}
+
+	current := s.rec.Snapshot()
+	resp := &dto.SelfSLOResponse{
+		WindowStart:        start,
+		WindowEnd:          end,
+		AvailabilityTarget: s.availabilityTarget,
+		LatencyTargetMs:    s.latencyTargetMs,
+		Status:             string(slo.SLOStatusHealthy),
+		Routes:             []dto.SelfSLORoute{},
+	}
+	statuses := make([]slo.SLOStatus, 0, len(current))
+	for _, route := range apimetrics.SortedRoutes(current) {
+		r := s.evaluateRoute(route, current[route].Sub(base[route]), start, end)
+		resp.Routes = append(resp.Routes, r)
+		statuses = append(statuses, slo.SLOStatus(r.Status))
+	}
+	resp.Status = string(slo.WorstStatus(statuses...))
+	sort.SliceStable(resp.Routes, func(i, j int) bool {
+		a, b := resp.Routes[i], resp.Routes[j]
+		if sa, sb := slo.StatusSeverity(slo.SLOStatus(a.Status)), slo.StatusSeverity(slo.SLOStatus(b.Status)); sa != sb {
+			return sa > sb
+		}
+		return a.ErrorBudgetRemaining < b.ErrorBudgetRemaining
+	})
+	return resp, nil
+}
+
+func (s *SelfSLOService) evaluateRoute(route apimetrics.Route, c apimetrics.Counts, start, end time.Time) dto.SelfSLORoute {
+	target := s.routes[route.String()]
+	if target.AvailabilityTarget == 0 {
+		target.AvailabilityTarget = s.availabilityTarget
+	}
+	if target.LatencyTargetMs == 0 {
+		target.LatencyTargetMs = s.latencyTargetMs
+	}
+
+	availability := slo.EvaluateAvailability(start, end, int64(c.Requests), int64(c.Errors), target.AvailabilityTarget)
+	h := slo.LatencyHistogram{Count: int64(c.Requests), Sum: c.Sum * 1000}
+	for i, bound := range apimetrics.LatencyBuckets {
+		h.Bounds = append(h.Bounds, bound*1000)
+		h.Counts = append(h.Counts, int64(c.Buckets[i]))
+	}
+	latency := slo.EvaluateLatency(start, end, h, target.LatencyTargetMs)
+	latencyRemaining := slo.LatencyBudgetRemaining(latency)
+
+	return dto.SelfSLORoute{
+		Method:                      route.Method,
+		Route:                       route.Path,
+		Status:                      string(slo.WorstStatus(availability.ComplianceStatus, latency.ComplianceStatus)),
+		Requests:                    int64(c.Requests),
+		Errors:                      int64(c.Errors),
+		Availability:                availability.AvailabilityPercentage,
+		AvailabilityTarget:          target.AvailabilityTarget,
+		LatencyP50Ms:                latency.P50,
+		LatencyP95Ms:                latency.P95,
+		LatencyP99Ms:                latency.P99,
+		LatencyTargetMs:             target.LatencyTargetMs,
+		SlowRequests:                latency.ViolationCount,
+		AvailabilityBudgetRemaining: availability.ErrorBudgetRemaining,
+		LatencyBudgetRemaining:      latencyRemaining,
+		ErrorBudgetRemaining:        math.Min(availability.ErrorBudgetRemaining, latencyRemaining),
+	}
+}
+
+// ListSLOStatus implements SLOStatusProvider with one status per route in namespace "lighthouse".
+func (s *SelfSLOService) ListSLOStatus(ctx context.Context) ([]SLOServiceStatus, error) {
+	resp, err := s.Evaluate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SLOServiceStatus, 0, len(resp.Routes))
+	for _, r := range resp.Routes {
+		st := SLOServiceStatus{
+			Service:              r.Method + " " + r.Route,
+			Namespace:            SelfSLONamespace,
+			Status:               slo.SLOStatus(r.Status),
+			Availability:         r.Availability,
+			LatencyP95Ms:         r.LatencyP95Ms,
+			ErrorBudgetRemaining: r.ErrorBudgetRemaining,
+			AvailabilityTarget:   r.AvailabilityTarget,
+		}
+		if r.Requests > 0 {
+			st.ErrorRate = float64(r.Errors) / float64(r.Requests) * 100
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
@@ -0,0 +1,393 @@
+// Package service service_accounts.go: 机器服务账号与 API key——按 key 的权限范围（scope）与每日配额、用量统计，
+// 让 CI 任务与其他服务各自持有可吊销的凭据，而不是共用一个。
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// APIKeySecretPrefix starts every API key secret, so leaked keys are easy to spot and scan for.
+const APIKeySecretPrefix = "lh_"
+
+const maxUsageDays = 90
+
+var (
+	// ErrInvalidServiceAccount is returned for an invalid account or key request.
+	ErrInvalidServiceAccount = dataerr.Validation("invalid service account request")
+	// ErrUnauthenticated is returned for an unknown, revoked or expired key or a disabled account.
+	ErrUnauthenticated = errors.New("invalid or revoked api key")
+	// ErrScopeDenied is returned when the key lacks the scope of the request.
+	ErrScopeDenied = errors.New("api key lacks the required scope")
+	// ErrQuotaExceeded is returned when the key has used up its daily quota.
+	ErrQuotaExceeded = errors.New("api key daily quota exceeded")
+)
+
+// ServiceAccountStore persists service accounts, their keys and the key usage.
+// *postgres.MockRepository satisfies this interface.
+type ServiceAccountStore interface {
+	SaveServiceAccount(ctx context.Context, account postgres.ServiceAccount) (postgres.ServiceAccount, error)
+	GetServiceAccount(ctx context.Context, id string) (*postgres.ServiceAccount, error)
+	ListServiceAccounts(ctx context.Context) ([]postgres.ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, id string) error
+	SaveAPIKey(ctx context.Context, key postgres.APIKey) (postgres.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, secretHash string) (*postgres.APIKey, error)
+	ListAPIKeys(ctx context.Context, accountID string) ([]postgres.APIKey, error)
+	IncrementAPIKeyUsage(ctx context.Context, keyID string, date time.Time, quota int) (postgres.APIKeyUsage, bool, error)
+	ListAPIKeyUsage(ctx context.Context, keyIDs []string, startDate, endDate time.Time) ([]postgres.APIKeyUsage, error)
+}
+
+// Principal is the caller an API key authenticated.
+type Principal struct {
+	AccountID   string
+	AccountName string
+	KeyID       string
+	DailyQuota  int   // 0: unlimited
+	UsedToday   int64 // requests allowed today, including this one
+}
+
+// ServiceAccountService manages service accounts and API keys and authenticates API requests.
+type ServiceAccountService struct {
+	store        ServiceAccountStore
+	bootstrapKey string
+	now          func() time.Time
+}
+
+// NewServiceAccountService creates a ServiceAccountService.
+func NewServiceAccountService(store ServiceAccountStore) *ServiceAccountService {
+	return &ServiceAccountService{store: store, now: time.Now}
+}
+
+// SetBootstrapKey sets a key with every scope and no quota, used to create the first service accounts
+// when API keys are required.
+func (s *ServiceAccountService) SetBootstrapKey(key string) {
+	s.bootstrapKey = key
+}
+
+// CreateAccount creates a service account.
+func (s *ServiceAccountService) CreateAccount(ctx context.Context, req dto.CreateServiceAccountRequest) (*dto.ServiceAccount, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidServiceAccount)
+	}
+	existing, err := s.store.ListServiceAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list service accounts: %w", err)
+	}
+	for _, a := range existing {
+		if a.Name == name {
+			return nil, dataerr.Conflict("service account %q already exists", name)
+		}
+	}
+	now := s.now().UTC()
+	account, err := s.store.SaveServiceAccount(ctx, postgres.ServiceAccount{
+		Name: name, Description: req.Description, CreatedBy: req.CreatedBy, CreatedAt: now, UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save service account: %w", err)
+	}
+	return toDTOServiceAccount(account, nil, nil), nil
+}
+
+// ListAccounts returns all service accounts with their keys and today's usage, sorted by name.
+func (s *ServiceAccountService) ListAccounts(ctx context.Context) (*dto.ServiceAccountListResponse, error) {
+	accounts, err := s.store.ListServiceAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list service accounts: %w", err)
+	}
+	keys, err := s.store.ListAPIKeys(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	today, err := s.usageToday(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	byAccount := make(map[string][]postgres.APIKey)
+	for _, k := range keys {
+		byAccount[k.AccountID] = append(byAccount[k.AccountID], k)
+	}
+	resp := &dto.ServiceAccountListResponse{Accounts: make([]dto.ServiceAccount, 0, len(accounts)), Total: len(accounts)}
+	for _, a := range accounts {
+		resp.Accounts = append(resp.Accounts, *toDTOServiceAccount(a, byAccount[a.ID], today))
+	}
+	return resp, nil
+}
+
+// GetAccount returns a service account with its keys and today's usage.
+func (s *ServiceAccountService) GetAccount(ctx context.Context, id string) (*dto.ServiceAccount, error) {
+	account, err := s.store.GetServiceAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.store.ListAPIKeys(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	today, err := s.usageToday(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	return toDTOServiceAccount(*account, keys, today), nil
+}
+
+// UpdateAccount replaces the description and disabled flag of a service account. Disabling an account
+// rejects all its keys until it is enabled again.
+func (s *ServiceAccountService) UpdateAccount(ctx context.Context, id string, req dto.UpdateServiceAccountRequest) (*dto.ServiceAccount, error) {
+	account, err := s.store.GetServiceAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	account.Description = req.Description
+	account.Disabled = req.Disabled
+	account.UpdatedAt = s.now().UTC()
+	if _, err := s.store.SaveServiceAccount(ctx, *account); err != nil {
+		return nil, fmt.Errorf("save service account: %w", err)
+	}
+	return s.GetAccount(ctx, id)
+}
+
+// DeleteAccount deletes a service account and its keys.
+func (s *ServiceAccountService) DeleteAccount(ctx context.Context, id string) error {
+	return s.store.DeleteServiceAccount(ctx, id)
+}
+
+// CreateKey issues an API key for a service account. The secret is only returned here.
+func (s *ServiceAccountService) CreateKey(ctx context.Context, accountID string, req dto.CreateAPIKeyRequest) (*dto.CreatedAPIKey, error) {
+	if _, err := s.store.GetServiceAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidServiceAccount)
+	}
+	for _, scope := range req.Scopes {
+		if !validScope(scope) {
+			return nil, fmt.Errorf("%w: invalid scope %q (want <group>:<read|write|approve|*>, e.g. cost:read, or *)", ErrInvalidServiceAccount, scope)
+		}
+	}
+	if req.DailyQuota < 0 {
+		return nil, fmt.Errorf("%w: daily_quota must not be negative", ErrInvalidServiceAccount)
+	}
+	now := s.now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidServiceAccount)
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generate api key: %w", err)
+	}
+	secret := APIKeySecretPrefix + hex.EncodeToString(buf)
+	key, err := s.store.SaveAPIKey(ctx, postgres.APIKey{
+		AccountID:  accountID,
+		Name:       req.Name,
+		Prefix:     secret[:len(APIKeySecretPrefix)+8],
+		SecretHash: hashSecret(secret),
+		Scopes:     req.Scopes,
+		DailyQuota: req.DailyQuota,
+		CreatedAt:  now,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save api key: %w", err)
+	}
+	return &dto.CreatedAPIKey{Key: toDTOAPIKey(key, 0), Secret: secret}, nil
+}
+
+// RevokeKey revokes an API key of a service account; revoking twice keeps the first time.
+func (s *ServiceAccountService) RevokeKey(ctx context.Context, accountID, keyID string) (*dto.APIKey, error) {
+	keys, err := s.store.ListAPIKeys(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	for _, k := range keys {
+		if k.ID != keyID {
+			continue
+		}
+		if k.RevokedAt == nil {
+			now := s.now().UTC()
+			k.RevokedAt = &now
+			if k, err = s.store.SaveAPIKey(ctx, k); err != nil {
+				return nil, fmt.Errorf("save api key: %w", err)
+			}
+		}
+		out := toDTOAPIKey(k, 0)
+		return &out, nil
+	}
+	return nil, dataerr.NotFound("api key %s not found in service account %s", keyID, accountID)
+}
+
+// Usage returns the daily usage of the keys of a service account over the last days (default 7, at most 90).
+func (s *ServiceAccountService) Usage(ctx context.Context, accountID string, days int) (*dto.APIKeyUsageResponse, error) {
+	if days == 0 {
+		days = 7
+	}
+	if days < 0 || days > maxUsageDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidServiceAccount, maxUsageDays)
+	}
+	if _, err := s.store.GetServiceAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	keys, err := s.store.ListAPIKeys(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	names := make(map[string]string, len(keys))
+	ids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names[k.ID] = k.Name
+		ids = append(ids, k.ID)
+	}
+	end := s.now().UTC().Truncate(24 * time.Hour)
+	start := end.AddDate(0, 0, 1-days)
+	usage, err := s.store.ListAPIKeyUsage(ctx, ids, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("list api key usage: %w", err)
+	}
+	resp := &dto.APIKeyUsageResponse{AccountID: accountID, StartDate: start, EndDate: end, Usage: make([]dto.APIKeyUsageDay, 0, len(usage))}
+	for _, u := range usage {
+		resp.Usage = append(resp.Usage, dto.APIKeyUsageDay{KeyID: u.KeyID, KeyName: names[u.KeyID], Date: u.Date, Requests: u.Requests, Rejected: u.Rejected})
+		resp.TotalRequests += u.Requests
+		resp.TotalRejected += u.Rejected
+	}
+	return resp, nil
+}
+
+// Authenticate resolves an API key secret, checks that it grants scope (e.g. "cost:read") and counts
+// the request against its daily quota. Errors are ErrUnauthenticated, ErrScopeDenied, ErrQuotaExceeded
+// or a store error.
+func (s *ServiceAccountService) Authenticate(ctx context.Context, secret, scope string) (*Principal, error) {
+	if s.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.bootstrapKey)) == 1 {
+		return &Principal{AccountName: "bootstrap"}, nil
+	}
+	if !strings.HasPrefix(secret, APIKeySecretPrefix) {
+		return nil, ErrUnauthenticated
+	}
+	key, err := s.store.GetAPIKeyByHash(ctx, hashSecret(secret))
+	if errors.Is(err, dataerr.ErrNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, ErrUnauthenticated
+	}
+	account, err := s.store.GetServiceAccount(ctx, key.AccountID)
+	if errors.Is(err, dataerr.ErrNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	if account.Disabled {
+		return nil, ErrUnauthenticated
+	}
+	if !ScopeAllows(key.Scopes, scope) {
+		return nil, fmt.Errorf("%w: %s", ErrScopeDenied, scope)
+	}
+	usage, allowed, err := s.store.IncrementAPIKeyUsage(ctx, key.ID, now, key.DailyQuota)
+	if err != nil {
+		return nil, err
+	}
+	p := &Principal{AccountID: account.ID, AccountName: account.Name, KeyID: key.ID, DailyQuota: key.DailyQuota, UsedToday: usage.Requests}
+	if !allowed {
+		return p, fmt.Errorf("%w: %d requests per day", ErrQuotaExceeded, key.DailyQuota)
+	}
+	return p, nil
+}
+
+// AdminScopeGroup is the scope group of the /api/v1/admin endpoints (service accounts, state import,
+// chaos drills, ...). Group wildcards do not cover it, so admin access is always granted explicitly.
+const AdminScopeGroup = "admin"
+
+// ScopeAllows reports whether scopes grant required ("<group>:<read|write|approve>"). A scope matches when
+// both parts are equal or "*"; "*" alone grants everything and write implies read. approve is the
+// reviewer role (e.g. pricing:approve for price changes) and is not implied by write. The admin group
+// needs an explicit "admin:<verb>" scope: "*" and "*:<verb>" do not match it.
+func ScopeAllows(scopes []string, required string) bool {
+	group, verb, _ := strings.Cut(required, ":")
+	for _, scope := range scopes {
+		if scope == "*" && group != AdminScopeGroup {
+			return true
+		}
+		g, v, _ := strings.Cut(scope, ":")
+		if (g == group || (g == "*" && group != AdminScopeGroup)) && (v == "*" || v == verb || (v == "write" && verb == "read")) {
+			return true
+		}
+	}
+	return false
+}
+
+func validScope(scope string) bool {
+	if scope == "*" {
+		return true
+	}
+	g, v, ok := strings.Cut(scope, ":")
+	return ok && g != "" && !strings.ContainsAny(g, ": ") && (v == "read" || v == "write" || v == "approve" || v == "*")
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// usageToday returns the requests allowed today per key.
+func (s *ServiceAccountService) usageToday(ctx context.Context, keys []postgres.APIKey) (map[string]int64, error) {
+	ids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, k.ID)
+	}
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	usage, err := s.store.ListAPIKeyUsage(ctx, ids, today, today)
+	if err != nil {
+		return nil, fmt.Errorf("list api key usage: %w", err)
+	}
+	out := make(map[string]int64, len(usage))
+	for _, u := range usage {
+		out[u.KeyID] = u.Requests
+	}
+	return out, nil
+}
+
+func toDTOServiceAccount(a postgres.ServiceAccount, keys []postgres.APIKey, today map[string]int64) *dto.ServiceAccount {
+	out := &dto.ServiceAccount{
+		ID:          a.ID,
+		Name:        a.Name,
+		Description: a.Description,
+		Disabled:    a.Disabled,
+		CreatedBy:   a.CreatedBy,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+		Keys:        make([]dto.APIKey, 0, len(keys)),
+	}
+	for _, k := range keys {
+		out.Keys = append(out.Keys, toDTOAPIKey(k, today[k.ID]))
+	}
+	return out
+}
+
+func toDTOAPIKey(k postgres.APIKey, requestsToday int64) dto.APIKey {
+	return dto.APIKey{
+		ID:            k.ID,
+		Name:          k.Name,
+		Prefix:        k.Prefix,
+		Scopes:        append([]string{}, k.Scopes...),
+		DailyQuota:    k.DailyQuota,
+		CreatedAt:     k.CreatedAt,
+		ExpiresAt:     k.ExpiresAt,
+		RevokedAt:     k.RevokedAt,
+		RequestsToday: requestsToday,
+	}
+}
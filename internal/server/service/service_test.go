@@ -1,11 +1,31 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
 	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+	"github.com/myxxhui/lighthouse-src/internal/eventbus"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/internal/server/apimetrics"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
 )
 
 func TestNewCostService(t *testing.T) {
@@ -47,3 +67,3662 @@ func TestCostService_MixedQueryTimeSeries(t *testing.T) {
 		t.Errorf("Phase3 placeholder expected nil, got len=%d", len(pts))
 	}
 }
+
+func TestCostService_Paging(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for i, c := range []struct {
+		ns              string
+		billable, usage float64
+	}{{"a", 100, 90}, {"b", 300, 60}, {"c", 200, 180}} {
+		if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: c.ns, Date: now.AddDate(0, 0, -1), BillableCost: c.billable, UsageCost: c.usage, WasteCost: c.billable - c.usage}); err != nil {
+			t.Fatalf("SaveDailyNamespaceCost: %v", err)
+		}
+		for h := 1; h <= 2; h++ {
+			st := postgres.HourlyWorkloadStat{Namespace: "a", WorkloadName: fmt.Sprintf("w%d", i), WorkloadType: "Deployment", PodName: fmt.Sprintf("w%d-%d", i, h),
+				Timestamp: now.Add(-time.Duration(h) * time.Hour), TotalBillableCost: c.billable, TotalUsageCost: c.usage, TotalWasteCost: c.billable - c.usage}
+			if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+				t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+			}
+		}
+	}
+	svc := NewCostService(repo)
+
+	list, total, err := svc.ListNamespaces(ctx, costmodel.PageOptions{Sort: costmodel.SortByWaste, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListNamespaces: %v", err)
+	}
+	if total != 3 || len(list) != 2 || list[0].Name != "b" || list[1].Name != "c" || list[0].WasteCost != 240 {
+		t.Errorf("by waste = %+v (total %d), want b, c of 3", list, total)
+	}
+	list, _, _ = svc.ListNamespaces(ctx, costmodel.PageOptions{Sort: costmodel.SortByName, Order: costmodel.OrderAsc, Offset: 2})
+	if len(list) != 1 || list[0].Name != "c" {
+		t.Errorf("by name, offset 2 = %+v, want c", list)
+	}
+	if _, _, err := svc.ListNamespaces(ctx, costmodel.PageOptions{Sort: "pods"}); err == nil {
+		t.Error("unknown sort field should be rejected")
+	}
+
+	resp, err := svc.GetNamespaceCost(ctx, "a", costmodel.PageOptions{Sort: costmodel.SortByEfficiency, Order: costmodel.OrderAsc, Limit: 1})
+	if err != nil {
+		t.Fatalf("GetNamespaceCost: %v", err)
+	}
+	if resp.WorkloadTotal != 3 || len(resp.Workloads) != 1 {
+		t.Fatalf("workloads = %+v (total %d), want 1 of 3", resp.Workloads, resp.WorkloadTotal)
+	}
+	if w := resp.Workloads[0]; w.Name != "w1" || w.Cost != 600 || w.PodCount != 2 || w.Grade != string(costmodel.GradeOverProvisioned) {
+		t.Errorf("least efficient workload = %+v, want w1 (600, 2 pods, OverProvisioned)", w)
+	}
+}
+
+// stubMetadata is a MetadataSource with fixed metadata.
+type stubMetadata struct {
+	namespaces map[string]k8s.WorkloadMetadata
+	workloads  map[string]k8s.WorkloadMetadata
+	err        error
+}
+
+func (m stubMetadata) NamespaceMetadata(context.Context) (map[string]k8s.WorkloadMetadata, error) {
+	return m.namespaces, m.err
+}
+
+func (m stubMetadata) WorkloadMetadata(context.Context, string) (map[string]k8s.WorkloadMetadata, error) {
+	return m.workloads, m.err
+}
+
+func TestCostService_Metadata(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "pay", Date: now.AddDate(0, 0, -1), BillableCost: 10, UsageCost: 5, WasteCost: 5}); err != nil {
+		t.Fatalf("SaveDailyNamespaceCost: %v", err)
+	}
+	for _, w := range []string{"api", "cron"} {
+		st := postgres.HourlyWorkloadStat{Namespace: "pay", WorkloadName: w, WorkloadType: "Deployment", Timestamp: now.Add(-time.Hour), TotalBillableCost: 1, TotalUsageCost: 1}
+		if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	svc := NewCostService(repo)
+	svc.SetMetadata(stubMetadata{
+		namespaces: map[string]k8s.WorkloadMetadata{"pay": {Owner: "team-pay", Environment: "production"}},
+		workloads:  map[string]k8s.WorkloadMetadata{"api": {App: "pay-api", Owner: "team-pay", Tier: "backend", Environment: "production"}},
+	})
+
+	list, _, err := svc.ListNamespaces(ctx, costmodel.PageOptions{})
+	if err != nil {
+		t.Fatalf("ListNamespaces: %v", err)
+	}
+	if len(list) != 1 || list[0].Metadata == nil || list[0].Metadata.Owner != "team-pay" {
+		t.Errorf("namespaces = %+v, want pay owned by team-pay", list)
+	}
+	resp, err := svc.GetNamespaceCost(ctx, "pay", costmodel.PageOptions{Sort: costmodel.SortByName, Order: costmodel.OrderAsc})
+	if err != nil {
+		t.Fatalf("GetNamespaceCost: %v", err)
+	}
+	if len(resp.Workloads) != 2 || resp.Workloads[0].Metadata == nil || resp.Workloads[0].Metadata.Tier != "backend" || resp.Workloads[1].Metadata != nil {
+		t.Errorf("workloads = %+v, want metadata on api only", resp.Workloads)
+	}
+
+	// K8s 不可用时成本结果不受影响，只是没有元数据
+	svc.SetMetadata(stubMetadata{err: errors.New("k8s down")})
+	resp, err = svc.GetNamespaceCost(ctx, "pay", costmodel.PageOptions{})
+	if err != nil || len(resp.Workloads) != 2 || resp.Workloads[0].Metadata != nil {
+		t.Errorf("without K8s: %+v, %v", resp, err)
+	}
+}
+
+func TestCostService_CostSeries(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	ctx := context.Background()
+	end := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for d := 1; d <= 365; d++ {
+		if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "pay", Date: end.AddDate(0, 0, -d), BillableCost: 10, UsageCost: 6, WasteCost: 4}); err != nil {
+			t.Fatalf("SaveDailyNamespaceCost: %v", err)
+		}
+	}
+	for h := 1; h <= 48; h++ {
+		st := postgres.HourlyWorkloadStat{Namespace: "pay", WorkloadName: "api", Timestamp: end.Add(-time.Duration(h) * time.Hour), TotalBillableCost: 1, TotalUsageCost: 0.5, TotalWasteCost: 0.5}
+		if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	svc := NewCostService(repo)
+
+	cases := []struct {
+		start  time.Time
+		want   costmodel.Resolution
+		source string
+		points int
+	}{
+		{end.Add(-48 * time.Hour), costmodel.ResolutionHourly, CostSeriesSourceHourly, 48},
+		{end.AddDate(0, 0, -30), costmodel.ResolutionDaily, CostSeriesSourceDaily, 30},
+		{end.AddDate(0, -12, 0), costmodel.ResolutionMonthly, CostSeriesSourceDaily, 12},
+	}
+	for _, tc := range cases {
+		resp, err := svc.CostSeries(ctx, "pay", tc.start, end, "")
+		if err != nil {
+			t.Fatalf("CostSeries(%s): %v", end.Sub(tc.start), err)
+		}
+		if resp.Resolution != string(tc.want) || !resp.Planned || resp.Source != tc.source || len(resp.Points) != tc.points {
+			t.Errorf("CostSeries(%s) = %s from %s with %d points, want %s from %s with %d", end.Sub(tc.start),
+				resp.Resolution, resp.Source, len(resp.Points), tc.want, tc.source, tc.points)
+		}
+	}
+
+	// 二月 28 天，各 10
+	resp, _ := svc.CostSeries(ctx, "pay", end.AddDate(0, -12, 0), end, "")
+	if last := resp.Points[len(resp.Points)-1]; !last.Timestamp.Equal(end.AddDate(0, -1, 0)) || last.Cost != 280 || last.Waste != 112 {
+		t.Errorf("last monthly point = %+v, want February with cost 280", last)
+	}
+	if resp, err := svc.CostSeries(ctx, "pay", end.AddDate(0, 0, -2), end, costmodel.ResolutionDaily); err != nil || resp.Planned || len(resp.Points) != 2 {
+		t.Errorf("requested daily = %+v, %v", resp, err)
+	}
+	if _, err := svc.CostSeries(ctx, "pay", end.AddDate(-1, 0, 0), end, costmodel.ResolutionHourly); !errors.Is(err, ErrInvalidCostSeries) {
+		t.Errorf("hourly over a year: err = %v, want ErrInvalidCostSeries", err)
+	}
+}
+
+func TestCostService_TerminatedNamespaces(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	now := time.Now().UTC()
+	deletedAt := now.Add(-2 * time.Hour)
+	if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "gone-ns", Date: now.AddDate(0, 0, -1), BillableCost: 100, UsageCost: 40}); err != nil {
+		t.Fatalf("SaveDailyNamespaceCost: %v", err)
+	}
+	_ = repo.SaveNamespaceLifecycle(ctx, postgres.NamespaceLifecycle{Namespace: "gone-ns", FirstSeen: now.AddDate(0, 0, -3), LastSeen: deletedAt, DeletedAt: &deletedAt})
+
+	svc := NewCostService(repo)
+	svc.SetNamespaceLifecycles(repo)
+	resp, err := svc.GetGlobalCost(ctx)
+	if err != nil {
+		t.Fatalf("GetGlobalCost: %v", err)
+	}
+	found := false
+	for _, ns := range resp.Namespaces {
+		if ns.Name != "gone-ns" {
+			if ns.Terminated {
+				t.Errorf("namespace %s should not be terminated", ns.Name)
+			}
+			continue
+		}
+		found = true
+		if !ns.Terminated || ns.DeletedAt == nil || !ns.DeletedAt.Equal(deletedAt) {
+			t.Errorf("gone-ns should be annotated as terminated at %v, got %+v", deletedAt, ns)
+		}
+	}
+	if !found {
+		t.Fatal("terminated namespace must stay in the breakdown")
+	}
+
+	nsResp, err := svc.GetNamespaceCost(ctx, "gone-ns", costmodel.PageOptions{})
+	if err != nil {
+		t.Fatalf("GetNamespaceCost: %v", err)
+	}
+	if !nsResp.Terminated || nsResp.Cost.Billable != 100 {
+		t.Errorf("unexpected namespace cost response: %+v", nsResp)
+	}
+
+	grafana := NewGrafanaService(repo, nil)
+	grafana.SetNamespaceLifecycles(repo)
+	anns, err := grafana.Annotations(ctx, dto.GrafanaAnnotationRequest{
+		Range:      dto.GrafanaRange{From: now.Add(-24 * time.Hour), To: now},
+		Annotation: dto.GrafanaAnnotationQuery{Query: GrafanaAnnotationNamespaces},
+	})
+	if err != nil {
+		t.Fatalf("Annotations: %v", err)
+	}
+	if len(anns) != 1 || anns[0].Time != deletedAt.UnixMilli() || anns[0].Tags[1] != "terminated" {
+		t.Errorf("expected one terminated annotation in range, got %+v", anns)
+	}
+}
+
+func TestWorkloadService_GetWorkloadCost(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	h0 := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	const gib = 1 << 30
+	for i := 0; i < 3; i++ {
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "shop", WorkloadName: "cart", WorkloadType: "Deployment", Timestamp: h0.Add(time.Duration(i) * time.Hour),
+			CPURequest: 4, CPUUsageP95: 1, MemRequest: 8 * gib, MemUsageP95: 2 * gib,
+			CPUBillableCost: 0.4, MemBillableCost: 0.1, TotalBillableCost: 0.5, TotalUsageCost: 0.15, TotalWasteCost: 0.35,
+		})
+	}
+	_ = repo.SaveGradeChangeEvent(ctx, postgres.GradeChangeEvent{Namespace: "shop", WorkloadName: "cart", ToGrade: "OverProvisioned", OccurredAt: h0})
+
+	svc := NewWorkloadService(repo)
+	svc.SetGradeHistory(repo)
+	resp, err := svc.GetWorkloadCost(ctx, "shop", "cart", h0, h0.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("GetWorkloadCost: %v", err)
+	}
+	if len(resp.Series) != 3 || !resp.Series[0].Timestamp.Equal(h0) || resp.Series[2].Waste != 0.35 {
+		t.Errorf("unexpected hourly series: %+v", resp.Series)
+	}
+	if resp.Cost.Billable != 1.5 || resp.Cost.Total != resp.Cost.Billable || resp.Cost.Efficiency < 29.9 || resp.Cost.Efficiency > 30.1 {
+		t.Errorf("unexpected totals: %+v", resp.Cost)
+	}
+	if resp.Resources.CPUUtilization != 25 || !resp.Resources.Timestamp.Equal(h0.Add(2*time.Hour)) {
+		t.Errorf("unexpected resource usage: %+v", resp.Resources)
+	}
+	if resp.Grade != "OverProvisioned" || len(resp.GradeHistory) != 1 {
+		t.Errorf("unexpected grade %q / history %+v", resp.Grade, resp.GradeHistory)
+	}
+	if len(resp.Recommendations) != 2 || resp.Recommendations[0].Action != "downsize" {
+		t.Errorf("expected cpu+memory downsize recommendations, got %+v", resp.Recommendations)
+	}
+
+	if _, err := svc.GetWorkloadCost(ctx, "shop", "missing", h0, h0.Add(time.Hour)); !errors.Is(err, ErrWorkloadNotFound) {
+		t.Errorf("expected ErrWorkloadNotFound, got %v", err)
+	}
+	if _, err := svc.GetWorkloadCost(ctx, "shop", "cart", h0, h0); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("expected ErrInvalidWindow, got %v", err)
+	}
+}
+
+func TestWorkloadService_GetWorkloadHistory(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	now := time.Date(2020, 5, 10, 12, 0, 0, 0, time.UTC)
+	save := func(ts time.Time, billable, usage float64) {
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "shop", WorkloadName: "cart", WorkloadType: "Deployment", Timestamp: ts,
+			TotalBillableCost: billable, TotalUsageCost: usage, TotalWasteCost: billable - usage,
+		})
+	}
+	save(time.Date(2020, 4, 20, 3, 0, 0, 0, time.UTC), 1, 1) // 窗口外
+	save(time.Date(2020, 5, 1, 3, 0, 0, 0, time.UTC), 0.5, 0.15)
+	save(time.Date(2020, 5, 1, 4, 0, 0, 0, time.UTC), 0.5, 0.15)
+	if _, err := repo.DownsampleHourlyWorkloadStats(ctx, time.Date(2020, 5, 8, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("DownsampleHourlyWorkloadStats: %v", err)
+	}
+	save(time.Date(2020, 5, 1, 5, 0, 0, 0, time.UTC), 1, 0.7) // 降采样后迟到的小时
+	save(time.Date(2020, 5, 9, 1, 0, 0, 0, time.UTC), 1, 0.05)
+	save(time.Date(2020, 5, 10, 1, 0, 0, 0, time.UTC), 1, 0.95)
+
+	svc := NewWorkloadService(repo)
+	svc.now = func() time.Time { return now }
+	svc.SetDailyRollups(repo)
+	resp, err := svc.GetWorkloadHistory(ctx, "shop", "cart", 10)
+	if err != nil {
+		t.Fatalf("GetWorkloadHistory: %v", err)
+	}
+	if !resp.Start.Equal(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)) || len(resp.Points) != 3 {
+		t.Fatalf("unexpected history: %+v", resp)
+	}
+	first := resp.Points[0]
+	if first.Billable != 2 || math.Abs(first.Efficiency-50) > 1e-9 || first.Grade != "Healthy" {
+		t.Errorf("rollup merged with the late hour: %+v", first)
+	}
+	if resp.Points[1].Grade != "Zombie" || resp.Points[2].Grade != "Risk" || !resp.Points[2].Date.Equal(now.Truncate(24*time.Hour)) {
+		t.Errorf("unexpected recent points: %+v", resp.Points[1:])
+	}
+
+	if _, err := svc.GetWorkloadHistory(ctx, "shop", "missing", 0); !errors.Is(err, ErrWorkloadNotFound) {
+		t.Errorf("expected ErrWorkloadNotFound, got %v", err)
+	}
+	if _, err := svc.GetWorkloadHistory(ctx, "shop", "cart", MaxWorkloadHistoryDays+1); !errors.Is(err, ErrInvalidHistoryDays) {
+		t.Errorf("expected ErrInvalidHistoryDays, got %v", err)
+	}
+}
+
+// fakeEvents serves a fixed event list for any resource.
+type fakeEvents []k8s.Event
+
+func (f fakeEvents) GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]k8s.Event, error) {
+	return f, nil
+}
+
+func TestWorkloadService_ReliabilityTax(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	h0 := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "shop", WorkloadName: "cart", WorkloadType: "Deployment", PodName: "cart-0",
+			Timestamp: h0.Add(time.Duration(i) * time.Hour), TotalBillableCost: 0.5, TotalUsageCost: 0.2,
+		})
+	}
+	svc := NewWorkloadService(repo)
+	resp, err := svc.GetWorkloadCost(ctx, "shop", "cart", h0, h0.Add(3*time.Hour))
+	if err != nil || resp.ReliabilityTax != nil {
+		t.Fatalf("without an event source: tax = %+v, err = %v", resp.ReliabilityTax, err)
+	}
+
+	svc.SetReliabilitySources(fakeEvents{
+		{Reason: "Evicted", Count: 3, LastTimestamp: h0.Add(time.Hour)},
+		{Reason: "OOMKilling", Count: 6, LastTimestamp: h0.Add(2 * time.Hour)},
+		{Reason: "BackOff", Count: 50, LastTimestamp: h0.Add(-time.Hour)}, // before the window
+		{Reason: "Scheduled", Count: 1, LastTimestamp: h0.Add(time.Hour)},
+	}, StaticSLOStatusProvider{{Service: "cart", Namespace: "shop", Status: "warning", ErrorBudgetRemaining: 12}})
+	resp, err = svc.GetWorkloadCost(ctx, "shop", "cart", h0, h0.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("GetWorkloadCost: %v", err)
+	}
+	// 3 × 10 min + 6 × 5 min = 1 pod-hour at 0.5
+	tax := resp.ReliabilityTax
+	if tax == nil || tax.WastedCost != 0.5 || tax.Share != 33.33 || tax.PodHourlyCost != 0.5 {
+		t.Fatalf("tax = %+v", tax)
+	}
+	if tax.Restarts[string(costmodel.RestartEviction)] != 3 || tax.Restarts[string(costmodel.RestartOOMKill)] != 6 || len(tax.Restarts) != 2 {
+		t.Errorf("restarts = %v", tax.Restarts)
+	}
+	if tax.SLOStatus != "warning" || tax.ErrorBudgetRemaining == nil || *tax.ErrorBudgetRemaining != 12 {
+		t.Errorf("SLO = %q / %v", tax.SLOStatus, tax.ErrorBudgetRemaining)
+	}
+}
+
+// fakeThrottling serves fixed throttling samples (%) per pod and records the pods queried.
+type fakeThrottling struct {
+	rates   map[string][]float64
+	queried []string
+}
+
+func (f *fakeThrottling) GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]prometheus.ThrottlingMetric, error) {
+	f.queried = append(f.queried, pod)
+	var metrics []prometheus.ThrottlingMetric
+	for _, rate := range f.rates[pod] {
+		metrics = append(metrics, prometheus.ThrottlingMetric{Namespace: namespace, Pod: pod, ThrottlingRate: rate})
+	}
+	return metrics, nil
+}
+
+func TestWorkloadService_RightsizingSafety(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	h0 := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	const gib = 1 << 30
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+		Namespace: "shop", WorkloadName: "cart", WorkloadType: "Deployment", PodName: "cart-0", Timestamp: h0,
+		CPURequest: 2, CPUUsageP95: 0.5, MemRequest: 4 * gib, MemUsageP95: gib,
+		CPUBillableCost: 0.2, MemBillableCost: 0.05, TotalBillableCost: 0.25, TotalUsageCost: 0.075, TotalWasteCost: 0.175,
+	})
+	throttling := &fakeThrottling{rates: map[string][]float64{"cart-0": {20, 40}}}
+	svc := NewWorkloadService(repo)
+	svc.SetRightsizingSafety(NewRightsizingSafety(throttling, StaticSLOStatusProvider{}, costmodel.SafetyLimits{}))
+
+	// 近期平均限流 30%：CPU 缩容被抑制，内存缩容照常提出
+	resp, err := svc.GetWorkloadCost(ctx, "shop", "cart", h0, h0.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetWorkloadCost: %v", err)
+	}
+	if len(throttling.queried) != 1 || throttling.queried[0] != "cart-0" {
+		t.Errorf("queried throttling of %v, want the pod of the latest hour", throttling.queried)
+	}
+	if len(resp.Recommendations) != 1 || resp.Recommendations[0].Resource != "memory" || resp.Recommendations[0].Safety.Status != costmodel.SafetyPassed {
+		t.Errorf("recommendations = %+v, want memory downsize only", resp.Recommendations)
+	}
+	if len(resp.SuppressedRecommendations) != 1 {
+		t.Fatalf("suppressed = %+v, want the cpu downsize", resp.SuppressedRecommendations)
+	}
+	if s := resp.SuppressedRecommendations[0].Safety; s == nil || s.Status != costmodel.SafetySuppressed || s.CPUThrottlingRate == nil || *s.CPUThrottlingRate != 30 {
+		t.Errorf("cpu safety = %+v, want suppressed at 30%%", s)
+	}
+
+	// SLO 预算不足时两项都标记复核，限流率低时不抑制
+	throttling.rates = map[string][]float64{"cart-0": {1}}
+	svc.SetRightsizingSafety(NewRightsizingSafety(throttling, StaticSLOStatusProvider{
+		{Service: "cart", Namespace: "shop", Status: "healthy", ErrorBudgetRemaining: 10},
+	}, costmodel.SafetyLimits{}))
+	resp, err = svc.GetWorkloadCost(ctx, "shop", "cart", h0, h0.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetWorkloadCost: %v", err)
+	}
+	if len(resp.Recommendations) != 2 || len(resp.SuppressedRecommendations) != 0 {
+		t.Fatalf("recommendations = %+v, suppressed = %+v", resp.Recommendations, resp.SuppressedRecommendations)
+	}
+	for _, r := range resp.Recommendations {
+		if r.Safety == nil || r.Safety.Status != costmodel.SafetyFlagged || *r.Safety.ErrorBudgetRemaining != 10 {
+			t.Errorf("%s safety = %+v, want flagged for the error budget", r.Resource, r.Safety)
+		}
+	}
+}
+
+func TestSLOCostService_Analyze(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	now := time.Date(2020, 5, 8, 0, 0, 0, 0, time.UTC)
+	const gib = 1 << 30
+	// checkout：每小时 sketch 100 个样本，其中 1 个 4 核尖峰；search：无 sketch，按小时 P95 计
+	for i := 0; i < 24; i++ {
+		h := now.Add(-time.Duration(i+1) * time.Hour)
+		cpu := costmodel.NewUsageSketch(0)
+		for j := 0; j < 99; j++ {
+			cpu.Add(1)
+		}
+		cpu.Add(4)
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "shop", WorkloadName: "checkout", Timestamp: h, CPURequest: 6, CPUUsageP95: 1, MemRequest: gib, MemUsageP95: gib / 2,
+			CPUUsageSketch: cpu, MemUsageSketch: costmodel.SketchOf(gib / 2),
+		})
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "shop", WorkloadName: "search", Timestamp: h, CPURequest: 2, CPUUsageP95: 1,
+		})
+	}
+	slo := StaticSLOStatusProvider{
+		{Service: "checkout", Namespace: "shop", Status: "healthy", Availability: 99.95, LatencyP95Ms: 450, AvailabilityTarget: 99.9},
+		{Service: "search", Namespace: "shop", Status: "healthy", LatencyP95Ms: 100},
+		{Service: "gone", Namespace: "shop"},
+	}
+	svc := NewSLOCostService(repo, slo, 0.1, 0.01, 99, 500)
+	svc.now = func() time.Time { return now }
+
+	resp, err := svc.Analyze(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(resp.Services) != 2 || len(resp.Unmatched) != 1 || resp.Unmatched[0] != "shop/gone" {
+		t.Fatalf("services = %+v, unmatched = %v", resp.Services, resp.Unmatched)
+	}
+	checkout := resp.Services[0]
+	if checkout.Service != "checkout" || checkout.Samples != SLOCostSamplesSketch || checkout.Cost.Nines != 3 || !checkout.LatencyBound {
+		t.Fatalf("unexpected checkout: %+v", checkout)
+	}
+	// 99.9% 目标需要覆盖 4 核尖峰：相对典型需求（1 核）多 3 核
+	if c := checkout.Cost; math.Abs(c.Target.CPU-4) > 0.1 || math.Abs(c.SLOHeadroomMonthlyCost-3*0.1*costmodel.HoursPerMonth) > 2 {
+		t.Errorf("checkout target %+v, headroom %v", c.Target, c.SLOHeadroomMonthlyCost)
+	}
+	// 请求 6 核中 2 核、1 GiB 内存中 0.5 GiB 不为 SLO 所需
+	if c := checkout.Cost; math.Abs(c.ExcessMonthlyCost-(2*0.1+0.5*0.01)*costmodel.HoursPerMonth) > 2 {
+		t.Errorf("checkout excess = %v", c.ExcessMonthlyCost)
+	}
+	search := resp.Services[1]
+	if search.Samples != SLOCostSamplesHourlyP95 || search.Cost.AvailabilityTarget != 99 || search.LatencyBound || search.Cost.SLOHeadroomMonthlyCost != 0 {
+		t.Errorf("unexpected search: %+v", search)
+	}
+
+	if _, err := svc.Analyze(ctx, now, now.Add(-time.Hour)); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("inverted window: got %v, want ErrInvalidWindow", err)
+	}
+}
+
+func TestAlertRuleService(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	now := time.Date(2020, 5, 8, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 24; i++ {
+		h := now.Add(-time.Duration(i+1) * time.Hour)
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "shop", WorkloadName: "checkout", Timestamp: h, CPURequest: 4, CPUUsageP95: 1,
+			CPUBillableCost: 10, TotalBillableCost: 10, TotalWasteCost: 6,
+		})
+	}
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "shop", Date: time.Date(2020, 5, 3, 0, 0, 0, 0, time.UTC), BillableCost: 450})
+	alerts := &recordingAlerts{}
+	svc := NewAlertRuleService(repo, repo)
+	svc.SetSLOStatus(StaticSLOStatusProvider{{Service: "checkout", Namespace: "shop", Availability: 99.5, ErrorBudgetRemaining: 12}})
+	svc.SetTeamBudgets(map[string]TeamBudget{"commerce": {Namespaces: []string{"shop"}, MonthlyBudget: 500}})
+	svc.SetNotifier(alerts)
+	svc.now = func() time.Time { return now }
+
+	for _, req := range []dto.AlertRuleRequest{
+		{Name: "x", Condition: "cost >"},
+		{Name: "x", Condition: "unknown_metric > 1"},
+		{Name: "x", Condition: `budget_used{namespace="shop"} > 1`},
+		{Name: "x", Condition: "cost > 1", Window: "10m"},
+		{Name: "x", Condition: "cost > 1", Severity: "fatal"},
+		{Name: "x", Condition: "cost > 1", Channels: []string{"fax"}},
+	} {
+		if _, err := svc.Create(ctx, req); !errors.Is(err, ErrInvalidAlertRule) {
+			t.Errorf("Create(%+v): err = %v, want invalid", req, err)
+		}
+	}
+	rule, err := svc.Create(ctx, dto.AlertRuleRequest{
+		Name:      "shop-waste",
+		Condition: `cost{namespace="shop"}>200 and waste_ratio{namespace="shop"}>=50 or slo_error_budget_remaining<5`,
+		Severity:  "critical",
+		Channels:  []string{"wecom"},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if rule.Window != "24h0m0s" || rule.Interval != "1h0m0s" || rule.AlertType != "alert_rule" || !rule.Enabled ||
+		rule.Condition != `cost{namespace="shop"} > 200 and waste_ratio{namespace="shop"} >= 50 or slo_error_budget_remaining < 5` {
+		t.Errorf("created rule = %+v", rule)
+	}
+	if _, err := svc.Create(ctx, dto.AlertRuleRequest{Name: "shop-waste", Condition: "cost > 1"}); !errors.Is(err, dataerr.ErrConflict) {
+		t.Errorf("duplicate name: err = %v", err)
+	}
+
+	// 24 小时成本 240 > 200，浪费率 60% >= 50：开始触发并通知一次
+	eval, err := svc.Evaluate(ctx, rule.ID)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if !eval.Firing || eval.State != AlertStateFiring || !eval.Notified || len(eval.Clauses) != 3 || eval.Clauses[0].Value != 240 || eval.Clauses[1].Value != 60 {
+		t.Errorf("evaluation = %+v", eval)
+	}
+	if len(alerts.alerts) != 1 || alerts.alerts[0].Severity != notifier.SeverityCritical || alerts.alerts[0].Channels[0] != "wecom" ||
+		alerts.alerts[0].DedupKey != "lighthouse/alert-rule/"+rule.ID {
+		t.Errorf("alerts = %+v", alerts.alerts)
+	}
+	// 持续触发不重复通知；到期判断按 interval
+	if n, err := svc.EvaluateDue(ctx); err != nil || n != 0 {
+		t.Errorf("EvaluateDue before interval = %d, %v; want 0", n, err)
+	}
+	now = now.Add(time.Hour)
+	if n, err := svc.EvaluateDue(ctx); err != nil || n != 1 || len(alerts.alerts) != 1 {
+		t.Errorf("EvaluateDue after interval = %d, %v, alerts %d; want 1 evaluation and no new alert", n, err, len(alerts.alerts))
+	}
+
+	// 收紧阈值后不再触发；条件变化重置状态
+	updated, err := svc.Update(ctx, rule.ID, dto.AlertRuleRequest{Name: "shop-waste", Condition: `cost{namespace="shop"} > 1000`})
+	if err != nil || updated.State != "" || updated.CreatedAt != rule.CreatedAt {
+		t.Fatalf("Update = %+v, %v", updated, err)
+	}
+	if eval, _ = svc.Evaluate(ctx, rule.ID); eval.Firing || eval.State != AlertStateOK {
+		t.Errorf("after update: evaluation = %+v", eval)
+	}
+
+	// 预算、僵尸与 SLO 指标
+	other, err := svc.Create(ctx, dto.AlertRuleRequest{Name: "budget", Condition: `budget_used{team="commerce"} >= 90 and zombie_count{namespace="shop"} == 0 and slo_availability{service="checkout"} < 99.9`})
+	if err != nil {
+		t.Fatalf("Create budget rule: %v", err)
+	}
+	if eval, _ = svc.Evaluate(ctx, other.ID); !eval.Firing || eval.Clauses[0].Value != 90 {
+		t.Errorf("budget rule evaluation = %+v", eval)
+	}
+
+	// 预算超支事件：达到预算时每月每团队发布一次
+	events := &recordingEvents{}
+	svc.SetPublisher(events, "CNY")
+	if n, err := svc.CheckBudgets(ctx); err != nil || n != 0 {
+		t.Errorf("CheckBudgets within budget = %d, %v; want 0", n, err)
+	}
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "shop", Date: time.Date(2020, 5, 4, 0, 0, 0, 0, time.UTC), BillableCost: 60})
+	for i := 0; i < 2; i++ {
+		if _, err := svc.CheckBudgets(ctx); err != nil {
+			t.Fatalf("CheckBudgets: %v", err)
+		}
+	}
+	if len(events.payloads) != 1 {
+		t.Fatalf("published %d events, want 1", len(events.payloads))
+	}
+	if ev, ok := events.payloads[0].(eventbus.BudgetExceeded); !ok || ev.Scope != "commerce" || ev.Period != "2020-05" || ev.ActualAmount != 510 || ev.BudgetAmount != 500 || ev.Currency != "CNY" {
+		t.Errorf("budget event = %+v", events.payloads[0])
+	}
+
+	list, err := svc.List(ctx)
+	if err != nil || list.Total != 2 || len(list.Metrics) == 0 {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if err := svc.Delete(ctx, other.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := svc.Get(ctx, other.ID); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("Get after delete: err = %v", err)
+	}
+}
+
+func TestNotificationPolicyService(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	now := time.Date(2020, 5, 8, 12, 0, 0, 0, time.UTC)
+	svc := NewNotificationPolicyService(repo)
+	svc.SetTeams(map[string][]string{"commerce": {"shop"}})
+	svc.now = func() time.Time { return now }
+
+	for _, req := range []dto.NotificationRouteRequest{
+		{Name: "x", Channels: []string{"fax"}},
+		{Name: "x", Channels: []string{"slack"}, Matchers: map[string]string{"": "v"}},
+	} {
+		if _, err := svc.CreateRoute(ctx, req); !errors.Is(err, ErrInvalidNotificationPolicy) {
+			t.Errorf("CreateRoute(%+v): err = %v, want invalid", req, err)
+		}
+	}
+	if _, err := svc.CreateRoute(ctx, dto.NotificationRouteRequest{Name: "fallback", Channels: []string{"slack"}, Priority: 100}); err != nil {
+		t.Fatalf("CreateRoute fallback: %v", err)
+	}
+	if _, err := svc.CreateRoute(ctx, dto.NotificationRouteRequest{
+		Name: "commerce-critical", Channels: []string{"pagerduty", "wecom"}, Priority: 10,
+		Matchers: map[string]string{"team": "commerce", "severity": "critical"},
+	}); err != nil {
+		t.Fatalf("CreateRoute commerce: %v", err)
+	}
+	if _, err := svc.CreateRoute(ctx, dto.NotificationRouteRequest{Name: "fallback", Channels: []string{"wecom"}}); !errors.Is(err, dataerr.ErrConflict) {
+		t.Errorf("duplicate route name: err = %v, want conflict", err)
+	}
+	routes, _ := svc.ListRoutes(ctx)
+	if routes.Total != 2 || routes.Routes[0].Name != "commerce-critical" {
+		t.Fatalf("routes = %+v, want commerce-critical first", routes.Routes)
+	}
+
+	alert, err := svc.Filter(ctx, notifier.Alert{Type: notifier.AlertTypeRule, Severity: notifier.SeverityCritical, Labels: map[string]string{"namespace": "shop"}})
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	if alert.Labels["team"] != "commerce" || !reflect.DeepEqual(alert.Channels, []string{"pagerduty", "wecom"}) {
+		t.Errorf("filtered alert labels=%v channels=%v, want commerce routed to pagerduty+wecom", alert.Labels, alert.Channels)
+	}
+	alert, _ = svc.Filter(ctx, notifier.Alert{Type: notifier.AlertTypeRule, Severity: notifier.SeverityWarning, Labels: map[string]string{"namespace": "shop"}})
+	if !reflect.DeepEqual(alert.Channels, []string{"slack"}) {
+		t.Errorf("warning alert channels = %v, want fallback slack", alert.Channels)
+	}
+	alert, _ = svc.Filter(ctx, notifier.Alert{Type: notifier.AlertTypeRule, Channels: []string{"dingtalk"}})
+	if !reflect.DeepEqual(alert.Channels, []string{"dingtalk"}) {
+		t.Errorf("explicit channels = %v, want kept", alert.Channels)
+	}
+
+	later := now.Add(time.Hour)
+	for _, req := range []dto.CreateSilenceRequest{
+		{Matchers: map[string]string{"team": "commerce"}, Duration: "2h"},
+		{Matchers: map[string]string{}, Reason: "r", Duration: "2h"},
+		{Matchers: map[string]string{"team": "commerce"}, Reason: "r"},
+		{Matchers: map[string]string{"team": "commerce"}, Reason: "r", Duration: "-1h"},
+		{Matchers: map[string]string{"team": "commerce"}, Reason: "r", Duration: "800h"},
+		{Matchers: map[string]string{"team": "commerce"}, Reason: "r", Duration: "1h", EndsAt: &later},
+	} {
+		if _, err := svc.CreateSilence(ctx, req); !errors.Is(err, ErrInvalidNotificationPolicy) {
+			t.Errorf("CreateSilence(%+v): err = %v, want invalid", req, err)
+		}
+	}
+	active, err := svc.CreateSilence(ctx, dto.CreateSilenceRequest{Matchers: map[string]string{"team": "commerce"}, Reason: "shop migration", Duration: "2h", CreatedBy: "ops"})
+	if err != nil {
+		t.Fatalf("CreateSilence: %v", err)
+	}
+	if !active.Active || !active.EndsAt.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("silence = %+v, want active until +2h", active)
+	}
+	pending, err := svc.CreateSilence(ctx, dto.CreateSilenceRequest{Matchers: map[string]string{"severity": "info"}, Reason: "maintenance", StartsAt: &later, Duration: "1h"})
+	if err != nil {
+		t.Fatalf("CreateSilence pending: %v", err)
+	}
+	if pending.Active {
+		t.Error("future silence reported active")
+	}
+
+	if _, err := svc.Filter(ctx, notifier.Alert{Type: notifier.AlertTypeRule, Labels: map[string]string{"namespace": "shop"}}); !errors.Is(err, notifier.ErrSilenced) {
+		t.Errorf("shop alert: err = %v, want silenced", err)
+	}
+	if _, err := svc.Filter(ctx, notifier.Alert{Type: notifier.AlertTypeRule, Labels: map[string]string{"namespace": "infra"}}); err != nil {
+		t.Errorf("infra alert: err = %v, pending silence must not apply yet", err)
+	}
+
+	expired, err := svc.ExpireSilence(ctx, active.ID, "alice")
+	if err != nil {
+		t.Fatalf("ExpireSilence: %v", err)
+	}
+	if expired.Active || expired.ExpiredBy != "alice" || !expired.EndsAt.Equal(now) {
+		t.Errorf("expired silence = %+v", expired)
+	}
+	if _, err := svc.Filter(ctx, notifier.Alert{Type: notifier.AlertTypeRule, Labels: map[string]string{"namespace": "shop"}}); err != nil {
+		t.Errorf("shop alert after expiry: err = %v", err)
+	}
+	list, _ := svc.ListSilences(ctx, false)
+	if list.Total != 1 || list.Silences[0].ID != pending.ID {
+		t.Errorf("active silences = %+v, want only the pending one", list.Silences)
+	}
+	if list, _ = svc.ListSilences(ctx, true); list.Total != 2 {
+		t.Errorf("all silences total = %d, want 2", list.Total)
+	}
+	if _, err := svc.ExpireSilence(ctx, "missing", "alice"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("ExpireSilence missing: err = %v, want not found", err)
+	}
+}
+
+func TestLineageService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	day := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{CalculationID: "calc-1", Timestamp: day, TotalBillableCost: 100})
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{CalculationID: "calc-2", Timestamp: day.Add(time.Hour), TotalBillableCost: 900, RequiresConfirmation: true})
+	_ = repo.SaveCalculationRun(ctx, postgres.CalculationRun{ID: "run-old", Status: postgres.CalculationRunSucceeded, WindowStart: day.Add(-2 * time.Hour), WindowEnd: day.Add(-time.Hour), StartedAt: day.Add(-time.Hour)})
+	_ = repo.SaveCalculationRun(ctx, postgres.CalculationRun{ID: "run-partial", Status: postgres.CalculationRunPartial, WindowStart: day.Add(-time.Hour), WindowEnd: day, StartedAt: day, FailedScopes: []string{"shop"}})
+	_ = repo.SaveCalculationRun(ctx, postgres.CalculationRun{ID: "run-failed", Status: postgres.CalculationRunFailed, WindowStart: day, WindowEnd: day.Add(time.Hour), StartedAt: day.Add(time.Hour)})
+	_ = repo.SavePriceVersion(ctx, postgres.PriceVersion{EffectiveFrom: day.AddDate(0, -1, 0), CPUPricePerCoreHour: 0.04, MemPricePerGBHour: 0.004})
+	_ = repo.SavePriceVersion(ctx, postgres.PriceVersion{EffectiveFrom: day, CPUPricePerCoreHour: 0.05, MemPricePerGBHour: 0.005})
+
+	svc := NewLineageService(repo, repo)
+	svc.now = func() time.Time { return day.Add(2 * time.Hour) }
+	bare, err := svc.Capture(ctx, LineageRequest{Method: "GET", Path: "/api/v1/cost/global"})
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if bare.Snapshot == nil || bare.Snapshot.CalculationID != "calc-1" || bare.Snapshot.ContentHash == "" {
+		t.Errorf("snapshot = %+v, want the confirmed calc-1 snapshot with its hash", bare.Snapshot)
+	}
+	if bare.CalculationRun != nil || bare.PriceVersion != nil || bare.Code.Version != "dev" {
+		t.Errorf("lineage without runs/prices = %+v", bare)
+	}
+
+	svc.SetCalculationRuns(repo)
+	svc.SetPriceHistory(repo)
+	svc.SetCodeVersion(postgres.CodeVersion{Version: "v1.2.0", GitCommit: "abc123"})
+	lineage, err := svc.Capture(ctx, LineageRequest{RequestID: "req-1", Method: "GET", Path: "/api/v1/cost/namespaces?days=7"})
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if lineage.CalculationRun == nil || lineage.CalculationRun.ID != "run-partial" {
+		t.Fatalf("calculation run = %+v, want the latest run with written results", lineage.CalculationRun)
+	}
+	// the partial run's window ends when the second version takes effect, so the first one priced it
+	if lineage.PriceVersion == nil || lineage.PriceVersion.CPUPricePerCoreHour != 0.04 {
+		t.Errorf("price version = %+v, want the one effective during the run window", lineage.PriceVersion)
+	}
+	if err := svc.Save(ctx, lineage, 200); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// a later price correction must not rewrite the recorded lineage
+	_ = repo.SavePriceVersion(ctx, postgres.PriceVersion{EffectiveFrom: day.AddDate(0, -1, 0), CPUPricePerCoreHour: 0.03, MemPricePerGBHour: 0.003})
+
+	got, err := svc.Get(ctx, lineage.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.RequestID != "req-1" || got.Status != 200 || got.Path != "/api/v1/cost/namespaces?days=7" {
+		t.Errorf("lineage = %+v", got)
+	}
+	if got.PriceVersion.CPUPricePerCoreHour != 0.04 || got.CalculationRun.Status != postgres.CalculationRunPartial || len(got.CalculationRun.FailedScopes) != 1 {
+		t.Errorf("recorded price/run = %+v / %+v", got.PriceVersion, got.CalculationRun)
+	}
+	if got.Code.Version != "v1.2.0" || got.Code.GitCommit != "abc123" {
+		t.Errorf("code = %+v", got.Code)
+	}
+	if _, err := svc.Get(ctx, "missing"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("Get missing: err = %v, want not found", err)
+	}
+}
+
+func TestCostService_SnapshotFieldSelection(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	day := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{
+			ID: fmt.Sprintf("snap-%d", i), CalculationID: "calc", Timestamp: day.Add(time.Duration(i) * time.Hour),
+			TotalBillableCost: 100, HealthyCount: 3, ZombieCount: 1,
+			ResourceResults:   []costmodel.CostResult{{TotalBillableCost: 60}, {TotalBillableCost: 40}},
+			AggregatedResults: map[costmodel.AggregationLevel][]costmodel.AggregationResult{costmodel.LevelNamespace: {{Identifier: "shop"}}},
+		})
+	}
+	svc := NewCostService(repo)
+
+	for _, tc := range []struct{ fields, include string }{{"all", ""}, {"summary", "resources"}} {
+		if _, err := ParseSnapshotFields(tc.fields, tc.include, 0); !errors.Is(err, ErrInvalidSnapshotFields) {
+			t.Errorf("ParseSnapshotFields(%q, %q): err = %v, want invalid", tc.fields, tc.include, err)
+		}
+	}
+	fields, err := ParseSnapshotFields("counts", "aggregated_results", postgres.SnapshotFieldsAll)
+	if err != nil || fields != postgres.SnapshotFieldCounts|postgres.SnapshotFieldAggregated {
+		t.Fatalf("ParseSnapshotFields = %v, %v", fields, err)
+	}
+
+	list, err := svc.ListSnapshots(ctx, SnapshotQuery{Limit: 2, Fields: fields})
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	if list.Total != 2 || list.Snapshots[0].ID != "snap-2" {
+		t.Fatalf("snapshots = %+v, want the 2 newest", list.Snapshots)
+	}
+	got := list.Snapshots[0]
+	if got.Counts == nil || got.Counts.Healthy != 3 || got.Summary != nil || got.ResourceResults != nil {
+		t.Errorf("counts+aggregated snapshot = %+v", got)
+	}
+	if len(got.AggregatedResults["namespace"]) != 1 || !reflect.DeepEqual(got.Fields, []string{"counts", "aggregated_results"}) {
+		t.Errorf("aggregated = %v, fields = %v", got.AggregatedResults, got.Fields)
+	}
+
+	one, err := svc.GetSnapshot(ctx, "snap-0", postgres.SnapshotFieldSummary)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	if one.Summary == nil || one.Summary.TotalBillableCost != 100 || one.Counts != nil || one.AggregatedResults != nil {
+		t.Errorf("summary snapshot = %+v", one)
+	}
+	if one, _ = svc.GetSnapshot(ctx, "snap-0", postgres.SnapshotFieldsAll); len(one.ResourceResults) != 2 || one.Summary == nil || one.Counts == nil {
+		t.Errorf("full snapshot = %+v", one)
+	}
+	if _, err := svc.GetSnapshot(ctx, "missing", postgres.SnapshotFieldsAll); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("GetSnapshot missing: err = %v, want not found", err)
+	}
+}
+
+func TestDataFreshnessService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	day := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	now := day.Add(3 * time.Hour)
+
+	svc := NewDataFreshnessService(repo)
+	svc.SetCalculationRuns(repo)
+	svc.now = func() time.Time { return now }
+	status, err := svc.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Synced || status.AsOf != nil {
+		t.Fatalf("empty repository: %+v, want not synced", status)
+	}
+
+	// a failed run and an unconfirmed snapshot are not a successful sync
+	_ = repo.SaveCalculationRun(ctx, postgres.CalculationRun{ID: "run-failed", Status: postgres.CalculationRunFailed, WindowEnd: day, StartedAt: day})
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{CalculationID: "calc-1", Timestamp: day, RequiresConfirmation: true})
+	now = now.Add(DefaultFreshnessCacheTTL)
+	if status, _ = svc.Status(ctx); status.Synced {
+		t.Fatalf("failed run only: %+v, want not synced", status)
+	}
+
+	_ = repo.SaveCalculationRun(ctx, postgres.CalculationRun{ID: "run-ok", Status: postgres.CalculationRunSucceeded, WindowEnd: day.Add(time.Hour), StartedAt: day.Add(time.Hour)})
+	if status, _ = svc.Status(ctx); status.Synced {
+		t.Errorf("status within the cache TTL = %+v, want the cached one", status)
+	}
+	now = now.Add(DefaultFreshnessCacheTTL)
+	status, _ = svc.Status(ctx)
+	if !status.Synced || status.Source != dto.FreshnessSourceCalculationRun || status.CalculationRunID != "run-ok" || !status.AsOf.Equal(day.Add(time.Hour)) {
+		t.Errorf("after run: %+v, want synced as of the run window end", status)
+	}
+
+	// without runs, a confirmed snapshot (e.g. a populated mock scenario) counts as synced
+	bare := NewDataFreshnessService(postgres.NewMockRepository(postgres.DefaultMockConfig()))
+	if status, _ = bare.Status(ctx); !status.Synced || status.Source != dto.FreshnessSourceCostSnapshot {
+		t.Errorf("populated mock: %+v, want synced from a snapshot", status)
+	}
+}
+
+func TestStaleDataWatchdog(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	alerts := &recordingAlerts{}
+	w := NewStaleDataWatchdog(repo, time.Hour, 0)
+	w.SetNotifier(alerts)
+	w.now = func() time.Time { return now }
+
+	sources, err := w.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sources) != 2 || sources[0].NewestAt != nil || sources[0].Stale || len(alerts.alerts) != 0 {
+		t.Fatalf("empty repository: %+v, alerts %d; want no data and no alert", sources, len(alerts.alerts))
+	}
+
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "shop", WorkloadName: "api", Timestamp: now.Add(-4 * time.Hour)})
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{CalculationID: "calc-1", Timestamp: now.Add(-time.Hour), RequiresConfirmation: true})
+	sources, _ = w.Check(ctx)
+	if !sources[0].Stale || sources[0].Age != 4*time.Hour || sources[0].Threshold != 3*time.Hour {
+		t.Errorf("hourly stats = %+v, want stale at 4h against 3h", sources[0])
+	}
+	if sources[1].Stale || sources[1].NewestAt == nil {
+		t.Errorf("snapshots = %+v, want the unconfirmed snapshot counted and fresh", sources[1])
+	}
+	if len(alerts.alerts) != 1 || alerts.alerts[0].Type != notifier.AlertTypeStaleData || alerts.alerts[0].Labels["source"] != FreshnessSourceHourlyStats {
+		t.Fatalf("alerts = %+v, want one stale_data alert for hourly stats", alerts.alerts)
+	}
+
+	// still stale: no repeated alert; recovered then stale again: a new episode
+	now = now.Add(time.Hour)
+	_, _ = w.Check(ctx)
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "shop", WorkloadName: "api", Timestamp: now})
+	_, _ = w.Check(ctx)
+	if len(alerts.alerts) != 1 {
+		t.Errorf("alerts = %d, want 1 within one stale episode", len(alerts.alerts))
+	}
+	now = now.Add(4 * time.Hour)
+	alerts.err = errors.New("webhook down")
+	if _, err := w.Check(ctx); err == nil {
+		t.Error("expected the notify error")
+	}
+	alerts.err = nil
+	_, _ = w.Check(ctx)
+	// both sources are stale now; the failed hourly stats alert is retried once
+	if len(alerts.alerts) != 5 || w.Alerts() != 3 {
+		t.Errorf("alerts sent %d, counted %d; want 5 attempts and 3 delivered", len(alerts.alerts), w.Alerts())
+	}
+}
+
+// failingEvents is an event source that is down.
+type failingEvents struct{}
+
+func (failingEvents) GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]k8s.Event, error) {
+	return nil, errors.New("apiserver unavailable")
+}
+
+func TestTimelineService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	t0 := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	svc := NewTimelineService(repo)
+	svc.SetCalculationRuns(repo)
+	svc.SetPriceHistory(repo)
+	svc.SetEventSource(fakeEvents{
+		{Name: "cart.1", Namespace: "shop", Type: "Warning", Reason: "OOMKilling", Message: "Memory cgroup out of memory", Count: 3,
+			FirstTimestamp: t0.Add(-30 * time.Minute), LastTimestamp: t0.Add(-10 * time.Minute), InvolvedObject: k8s.ObjectReference{Kind: "Pod", Name: "cart-0"}},
+		{Name: "old.1", Namespace: "shop", Reason: "Scheduled", FirstTimestamp: t0.Add(-48 * time.Hour), LastTimestamp: t0.Add(-47 * time.Hour)},
+	})
+	svc.now = func() time.Time { return t0 }
+
+	if _, err := svc.RecordConfigChange(ctx, dto.ConfigChangeRequest{ID: "change-cart", Namespace: "shop", Kind: "Deployment", Name: "cart", ChangeType: "image_update",
+		OldValue: "cart:1.0", NewValue: "cart:1.1", Author: "ci", OccurredAt: t0.Add(-time.Hour)}); err != nil {
+		t.Fatalf("RecordConfigChange: %v", err)
+	}
+	if _, err := svc.RecordConfigChange(ctx, dto.ConfigChangeRequest{ID: "change-limits", Namespace: "pay", Kind: "ConfigMap", Name: "limits", ChangeType: "configmap_update", OccurredAt: t0.Add(-time.Hour)}); err != nil {
+		t.Fatalf("RecordConfigChange: %v", err)
+	}
+	events := &recordingEvents{}
+	svc.SetPublisher(events)
+	violation, err := svc.RecordSLOViolation(ctx, dto.SLOViolationRequest{Namespace: "shop", Service: "cart", ViolationType: "latency",
+		ActualValue: 450, ThresholdValue: 300, ViolatedAt: t0.Add(-26 * time.Hour)})
+	if err != nil {
+		t.Fatalf("RecordSLOViolation: %v", err)
+	}
+	if len(events.payloads) != 1 {
+		t.Fatalf("published %d events, want 1", len(events.payloads))
+	}
+	if ev, ok := events.payloads[0].(eventbus.SLOViolated); !ok || ev.Service != "cart" || ev.ActualValue != 450 || !ev.ViolationTime.Equal(t0.Add(-26*time.Hour)) {
+		t.Errorf("violation event = %+v", events.payloads[0])
+	}
+	_ = repo.SaveCalculationRun(ctx, postgres.CalculationRun{ID: "run-1", Trigger: "schedule", Status: postgres.CalculationRunFailed, Error: "prometheus timeout",
+		StartedAt: t0.Add(-2 * time.Hour), FinishedAt: t0.Add(-2*time.Hour + time.Minute)})
+	_ = repo.SaveCalculationRun(ctx, postgres.CalculationRun{ID: "run-2", Status: postgres.CalculationRunSucceeded, Scopes: []string{"pay"}, StartedAt: t0.Add(-time.Hour)})
+	_ = repo.SavePriceVersion(ctx, postgres.PriceVersion{ID: "price-1", EffectiveFrom: t0.Add(-72 * time.Hour), CPUPricePerCoreHour: 0.03, MemPricePerGBHour: 0.004})
+	_ = repo.SavePriceVersion(ctx, postgres.PriceVersion{ID: "price-2", EffectiveFrom: t0.Add(-3 * time.Hour), CPUPricePerCoreHour: 0.04, MemPricePerGBHour: 0.004})
+
+	resp, err := svc.Timeline(ctx, TimelineQuery{Namespace: "shop"})
+	if err != nil {
+		t.Fatalf("Timeline: %v", err)
+	}
+	var got []string
+	for _, e := range resp.Events {
+		got = append(got, e.Kind+":"+e.Severity)
+	}
+	// the ongoing violation started before the window; the other namespace's change and scoped run are left out
+	want := []string{"slo_violation:critical", "price_change:info", "calculation_run:critical", "config_change:info", "k8s_event:warning"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("timeline = %v, want %v", got, want)
+	}
+	if e := resp.Events[1]; !strings.Contains(e.Summary, "0.03 -> 0.04") {
+		t.Errorf("price change summary = %q, want the previous and new CPU price", e.Summary)
+	}
+	if e := resp.Events[4]; e.EndTime == nil || !e.EndTime.Equal(t0.Add(-10*time.Minute)) || e.Object != "Pod/cart-0" || !strings.Contains(e.Summary, "(x3)") {
+		t.Errorf("k8s event = %+v", e)
+	}
+
+	// recovery before the window leaves the violation out
+	recovered := t0.Add(-25 * time.Hour)
+	if _, err := svc.RecordSLOViolation(ctx, dto.SLOViolationRequest{ID: violation.ID, Namespace: "shop", Service: "cart", ViolationType: "latency",
+		ViolatedAt: t0.Add(-26 * time.Hour), RecoveredAt: &recovered}); err != nil {
+		t.Fatalf("RecordSLOViolation recovery: %v", err)
+	}
+	resp, _ = svc.Timeline(ctx, TimelineQuery{Namespace: "shop", Kinds: []string{dto.TimelineKindSLOViolation}})
+	if resp.Total != 0 {
+		t.Errorf("recovered violation: %+v, want none", resp.Events)
+	}
+	if len(events.payloads) != 1 {
+		t.Errorf("recovery published an event: %+v", events.payloads)
+	}
+
+	// cluster timeline, limited to the latest events, with the event source down
+	svc.SetEventSource(failingEvents{})
+	resp, err = svc.Timeline(ctx, TimelineQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("Timeline: %v", err)
+	}
+	if !resp.Truncated || resp.Total != 2 || resp.Events[0].Ref != "change-cart" || resp.Events[1].Ref != "change-limits" {
+		t.Errorf("limited timeline = %+v, want the config changes of both namespaces", resp)
+	}
+	if len(resp.Unavailable) != 1 || resp.Unavailable[0] != dto.TimelineKindK8sEvent {
+		t.Errorf("unavailable = %v, want k8s_event", resp.Unavailable)
+	}
+
+	if _, err := ParseTimelineKinds("price_change,deploys"); !errors.Is(err, ErrInvalidTimelineKind) {
+		t.Errorf("unknown kind: err = %v", err)
+	}
+	if _, err := svc.RecordSLOViolation(ctx, dto.SLOViolationRequest{Namespace: "shop", Service: "cart", ViolationType: "throughput"}); !errors.Is(err, ErrInvalidTimelineRecord) {
+		t.Errorf("unknown violation type: err = %v", err)
+	}
+	if _, err := svc.Timeline(ctx, TimelineQuery{StartTime: t0, EndTime: t0}); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("empty window: err = %v", err)
+	}
+}
+
+func TestPreferenceService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	svc := NewPreferenceService(postgres.NewMockRepository(mockCfg))
+	svc.SetDefaultCurrency("usd")
+	svc.now = func() time.Time { return time.Date(2020, 9, 1, 8, 0, 0, 0, time.UTC) }
+
+	// Defaults before anything is saved
+	got, err := svc.Get(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Currency != "USD" || got.Units.CPU != "cores" || got.Units.Memory != "GiB" || got.UpdatedAt != nil || len(got.SavedViews) != 0 {
+		t.Errorf("defaults = %+v", got)
+	}
+
+	namespaces := []string{"shop", " pay ", "shop"}
+	currency := "cny"
+	favorites := []dto.FavoriteWorkload{{Namespace: "shop", Name: "cart"}, {Namespace: "shop", Name: "cart"}}
+	got, err = svc.Update(ctx, "alice", dto.UpdatePreferencesRequest{DefaultNamespaces: &namespaces, Currency: &currency, FavoriteWorkloads: &favorites})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if fmt.Sprint(got.DefaultNamespaces) != "[shop pay]" || got.Currency != "CNY" || len(got.FavoriteWorkloads) != 1 || got.UpdatedAt == nil {
+		t.Errorf("updated = %+v", got)
+	}
+
+	// Omitted fields keep their value
+	units := dto.DisplayUnits{CPU: "millicores", Memory: "MiB"}
+	if _, err := svc.Update(ctx, "alice", dto.UpdatePreferencesRequest{Units: &units}); err != nil {
+		t.Fatalf("Update units: %v", err)
+	}
+	view, err := svc.SaveView(ctx, "alice", dto.SavedViewRequest{Name: "waste", Page: "/cost/namespaces", Filters: map[string]string{"grade": "waste"}})
+	if err != nil {
+		t.Fatalf("SaveView: %v", err)
+	}
+	again, err := svc.SaveView(ctx, "alice", dto.SavedViewRequest{Name: "waste", Page: "/cost/namespaces", Filters: map[string]string{"grade": "risk"}})
+	if err != nil || again.ID != view.ID {
+		t.Fatalf("SaveView same name = %+v, %v; want replaced %s", again, err, view.ID)
+	}
+	got, err = svc.Get(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Currency != "CNY" || got.Units != units || fmt.Sprint(got.DefaultNamespaces) != "[shop pay]" {
+		t.Errorf("after units update = %+v", got)
+	}
+	if len(got.SavedViews) != 1 || got.SavedViews[0].Filters["grade"] != "risk" {
+		t.Errorf("saved views = %+v", got.SavedViews)
+	}
+
+	// Users are isolated
+	if bob, err := svc.Get(ctx, "bob"); err != nil || bob.UpdatedAt != nil || len(bob.SavedViews) != 0 {
+		t.Errorf("bob = %+v, %v; want defaults", bob, err)
+	}
+	if err := svc.DeleteView(ctx, "bob", view.ID); !errors.Is(err, ErrSavedViewNotFound) {
+		t.Errorf("DeleteView of other user = %v, want ErrSavedViewNotFound", err)
+	}
+	if err := svc.DeleteView(ctx, "alice", view.ID); err != nil {
+		t.Fatalf("DeleteView: %v", err)
+	}
+	if got, _ := svc.Get(ctx, "alice"); len(got.SavedViews) != 0 {
+		t.Errorf("views after delete = %+v", got.SavedViews)
+	}
+
+	badCurrency := "euro"
+	badUnits := dto.DisplayUnits{CPU: "vcpu"}
+	empty := []string{""}
+	for name, req := range map[string]dto.UpdatePreferencesRequest{
+		"currency":  {Currency: &badCurrency},
+		"units":     {Units: &badUnits},
+		"namespace": {DefaultNamespaces: &empty},
+	} {
+		if _, err := svc.Update(ctx, "alice", req); !errors.Is(err, ErrInvalidPreferences) {
+			t.Errorf("invalid %s: err = %v, want ErrInvalidPreferences", name, err)
+		}
+	}
+	if _, err := svc.SaveView(ctx, "alice", dto.SavedViewRequest{Name: "  "}); !errors.Is(err, ErrInvalidPreferences) {
+		t.Errorf("blank view name: err = %v, want ErrInvalidPreferences", err)
+	}
+}
+
+func TestAdmissionService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	now := time.Date(2020, 10, 20, 12, 0, 0, 0, time.UTC)
+	for d := 1; d <= 10; d++ {
+		if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "shop", Date: now.AddDate(0, 0, -d).Truncate(24 * time.Hour), BillableCost: 80}); err != nil {
+			t.Fatalf("SaveDailyNamespaceCost: %v", err)
+		}
+	}
+	// 1 core-hour = 0.1, 1 GiB-hour = 0.01
+	svc := NewAdmissionService(repo, 0.1, 0.01)
+	svc.now = func() time.Time { return now }
+	svc.SetBudgets(map[string]TeamBudget{"commerce": {Namespaces: []string{"shop", "cart"}, MonthlyBudget: 1000}}, map[string]float64{"shop": 5000})
+
+	deployment := func(replicas int, cpu, mem string, annotations string) json.RawMessage {
+		return json.RawMessage(fmt.Sprintf(`{"metadata":{"name":"api"%s},"spec":{"replicas":%d,"template":{"spec":{
+			"initContainers":[{"resources":{"requests":{"cpu":"4"}}}],
+			"containers":[{"resources":{"requests":{"cpu":%q,"memory":%q}}},{"resources":{"requests":{"cpu":"500m"}}}]}}}}`, annotations, replicas, cpu, mem))
+	}
+	review := func(op string, obj, old json.RawMessage) dto.AdmissionReview {
+		return dto.AdmissionReview{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview", Request: &dto.AdmissionRequest{
+			UID: "uid-1", Kind: dto.AdmissionGroupVersion{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Namespace: "shop", Name: "api", Operation: op, Object: obj, OldObject: old,
+		}}
+	}
+
+	// 2 replicas x (max(1.5 cores, init 4 cores) x 0.1 + 2 GiB x 0.01) x 730 = 613.20
+	out, err := svc.Review(ctx, review("CREATE", deployment(2, "1", "2Gi", ""), nil), true)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	resp := out.Response
+	if !resp.Allowed || resp.UID != "uid-1" || out.APIVersion != "admission.k8s.io/v1" {
+		t.Fatalf("response = %+v", resp)
+	}
+	if resp.PatchType == nil || string(resp.Patch) != `[{"op":"add","path":"/metadata/annotations","value":{"lighthouse.io/estimated-monthly-cost":"613.20"}}]` {
+		t.Errorf("patch = %s", resp.Patch)
+	}
+	// run rate 800 + 613.20 exceeds the team budget (1000) but not the namespace budget (5000)
+	if len(resp.Warnings) != 2 || !strings.Contains(resp.Warnings[1], "team commerce would reach 1413.20 of its monthly budget 1000.00") {
+		t.Errorf("warnings = %q", resp.Warnings)
+	}
+
+	// Existing annotations get a single escaped key; validating webhooks get no patch
+	out, err = svc.Review(ctx, review("CREATE", deployment(1, "1", "1Gi", `,"annotations":{"team":"x"}`), nil), true)
+	if err != nil || !strings.Contains(string(out.Response.Patch), `"path":"/metadata/annotations/lighthouse.io~1estimated-monthly-cost"`) {
+		t.Errorf("patch with annotations = %s, %v", out.Response.Patch, err)
+	}
+	if out, _ = svc.Review(ctx, review("CREATE", deployment(1, "1", "1Gi", ""), nil), false); out.Response.Patch != nil {
+		t.Errorf("validating response has a patch: %s", out.Response.Patch)
+	}
+
+	// Reject: an UPDATE is checked by its increase only
+	if err := svc.SetBudgetAction(AdmissionBudgetReject); err != nil {
+		t.Fatalf("SetBudgetAction: %v", err)
+	}
+	out, _ = svc.Review(ctx, review("UPDATE", deployment(2, "1", "2Gi", ""), deployment(1, "1", "2Gi", "")), true)
+	if out.Response.Allowed || out.Response.Result == nil || out.Response.Result.Code != 403 || out.Response.Patch != nil {
+		t.Errorf("over-budget update = %+v", out.Response)
+	}
+	out, _ = svc.Review(ctx, review("UPDATE", deployment(1, "1", "2Gi", ""), deployment(2, "1", "2Gi", "")), true)
+	if !out.Response.Allowed {
+		t.Errorf("scale-down rejected: %+v", out.Response.Result)
+	}
+
+	// Other kinds and deletions pass unchanged
+	other := review("CREATE", json.RawMessage(`{}`), nil)
+	other.Request.Kind.Kind = "ConfigMap"
+	if out, _ = svc.Review(ctx, other, true); !out.Response.Allowed || out.Response.Patch != nil || len(out.Response.Warnings) != 0 {
+		t.Errorf("configmap response = %+v", out.Response)
+	}
+	if _, err := svc.Review(ctx, dto.AdmissionReview{}, true); !errors.Is(err, ErrInvalidAdmissionReview) {
+		t.Errorf("review without request: err = %v", err)
+	}
+	if err := svc.SetBudgetAction("block"); err == nil {
+		t.Error("SetBudgetAction(block): want error")
+	}
+}
+
+func TestCostImportService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	day := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	// Rows Lighthouse calculated itself are kept
+	if err := repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "shop", WorkloadName: "api", Timestamp: day.Add(3 * time.Hour), TotalBillableCost: 99}); err != nil {
+		t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+	}
+	if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "pay", Date: day, BillableCost: 99}); err != nil {
+		t.Fatalf("SaveDailyNamespaceCost: %v", err)
+	}
+	svc := NewCostImportService(repo)
+
+	pod := func(name string) string {
+		return fmt.Sprintf(`"shop/%[1]s": {"name": "shop/%[1]s",
+			"properties": {"namespace": "shop", "controller": "api", "controllerKind": "deployment", "pod": %[1]q, "node": "n1"},
+			"window": {"start": "2020-05-01T00:00:00Z", "end": "2020-05-02T00:00:00Z"},
+			"cpuCoreRequestAverage": 1, "cpuCoreUsageAverage": 0.5, "cpuCost": 24,
+			"ramByteRequestAverage": 2147483648, "ramByteUsageAverage": 2147483648, "ramCost": 4.8,
+			"pvCost": 1, "sharedCost": 2.4}`, name)
+	}
+	export := `{"code": 200, "data": [{` + pod("api-0") + `,` + pod("api-1") + `,
+		"pay": {"name": "pay", "properties": {"namespace": "pay"}, "window": {"start": "2020-05-01T00:00:00Z", "end": "2020-05-02T00:00:00Z"}, "cpuCost": 10},
+		"__idle__": {"name": "__idle__", "window": {"start": "2020-05-01T00:00:00Z", "end": "2020-05-02T00:00:00Z"}, "cpuCost": 50}}]}`
+
+	dry, err := svc.Import(ctx, CostImportRequest{DryRun: true}, strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("Import(dry run): %v", err)
+	}
+	if stats, _ := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{}); len(stats) != 1 {
+		t.Errorf("dry run saved rows: %d hourly stats", len(stats))
+	}
+	resp, err := svc.Import(ctx, CostImportRequest{}, strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	resp.DryRun = true
+	if !reflect.DeepEqual(dry, resp) {
+		t.Errorf("dry run = %+v, import = %+v", dry, resp)
+	}
+	if resp.Source != CostImportSourceKubecost || resp.Allocations != 4 || resp.Unallocated != 1 || fmt.Sprint(resp.Namespaces) != "[pay shop]" {
+		t.Errorf("resp = %+v", resp)
+	}
+	// 24 shop/api hours and 2 daily rows, of which hour 3 and the pay day are Lighthouse rows
+	if resp.HourlyRows != 23 || resp.DailyRows != 1 || resp.KeptRows != 2 {
+		t.Errorf("rows = %d hourly, %d daily, %d kept", resp.HourlyRows, resp.DailyRows, resp.KeptRows)
+	}
+	if math.Abs(resp.ImportedCost-67.6) > 1e-9 || math.Abs(resp.SharedCost-4.8) > 1e-9 || resp.OtherCost != 2 {
+		t.Errorf("cost = imported %v, shared %v, other %v", resp.ImportedCost, resp.SharedCost, resp.OtherCost)
+	}
+
+	stats, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "shop", EndTime: day})
+	if err != nil || len(stats) != 1 {
+		t.Fatalf("hour 0 = %+v, %v", stats, err)
+	}
+	// Two pods on one node: requests and costs are summed, the pod name is dropped
+	st := stats[0]
+	if st.Source != "kubecost" || st.WorkloadType != "Deployment" || st.PodName != "" || st.NodeName != "n1" || st.CPURequest != 2 || st.MemRequest != 4<<30 {
+		t.Errorf("hour 0 = %+v", st)
+	}
+	if math.Abs(st.CPUBillableCost-2) > 1e-9 || math.Abs(st.CPUUsageCost-1) > 1e-9 || st.MemWasteCost != 0 || math.Abs(st.TotalBillableCost-2.4) > 1e-9 {
+		t.Errorf("hour 0 cost = %+v", st)
+	}
+	if kept, _ := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: day.Add(3 * time.Hour), EndTime: day.Add(3 * time.Hour)}); len(kept) != 1 || kept[0].TotalBillableCost != 99 || kept[0].Source != "" {
+		t.Errorf("Lighthouse hour = %+v", kept)
+	}
+	shop, err := repo.GetDailyNamespaceCost(ctx, "shop", day)
+	if err != nil {
+		t.Fatalf("GetDailyNamespaceCost: %v", err)
+	}
+	if shop.Source != "kubecost" || math.Abs(shop.BillableCost-57.6) > 1e-9 || math.Abs(shop.UsageCost-33.6) > 1e-9 || math.Abs(shop.SharedCost-4.8) > 1e-9 || shop.PodCount != 2 || shop.NodeCount != 1 || shop.WorkloadCount != 1 {
+		t.Errorf("shop day = %+v", shop)
+	}
+	if pay, _ := repo.GetDailyNamespaceCost(ctx, "pay", day); pay == nil || pay.BillableCost != 99 {
+		t.Errorf("Lighthouse pay day = %+v", pay)
+	}
+
+	// Re-importing replaces the imported rows
+	if again, err := svc.Import(ctx, CostImportRequest{}, strings.NewReader(export)); err != nil || again.HourlyRows != 23 || again.KeptRows != 2 {
+		t.Errorf("re-import = %+v, %v", again, err)
+	}
+	if stats, _ := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "shop"}); len(stats) != 24 {
+		t.Errorf("after re-import: %d shop hours, want 24", len(stats))
+	}
+
+	// CSV, days in the accounting time zone: Shanghai midnight is 16:00 UTC
+	calendar, err := costmodel.NewAccountingCalendar("Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("NewAccountingCalendar: %v", err)
+	}
+	svc.SetCalendar(calendar)
+	csv := "Name,Window Start,Window End,CPU Cost\nops,2020-05-01T12:00:00Z,2020-05-02T12:00:00Z,24\n"
+	resp, err = svc.Import(ctx, CostImportRequest{Source: CostImportSourceOpenCost, Format: CostImportFormatCSV}, strings.NewReader(csv))
+	if err != nil || resp.DailyRows != 2 || resp.HourlyRows != 0 {
+		t.Fatalf("csv import = %+v, %v", resp, err)
+	}
+	for date, want := range map[int]float64{1: 4, 2: 20} {
+		c, err := repo.GetDailyNamespaceCost(ctx, "ops", time.Date(2020, 5, date, 0, 0, 0, 0, time.UTC))
+		if err != nil || math.Abs(c.BillableCost-want) > 1e-9 || c.Source != "opencost" {
+			t.Errorf("ops May %d = %+v, %v; want billable %v", date, c, err, want)
+		}
+	}
+
+	for name, req := range map[string]struct {
+		req  CostImportRequest
+		body string
+	}{
+		"source":  {CostImportRequest{Source: "cloudhealth"}, export},
+		"format":  {CostImportRequest{Format: "xml"}, export},
+		"weekly":  {CostImportRequest{Format: CostImportFormatCSV}, "namespace,start,end,cpu cost\nshop,2020-05-01,2020-05-08,7\n"},
+		"no rows": {CostImportRequest{Format: CostImportFormatCSV}, "namespace,start,end\n"},
+		"parse":   {CostImportRequest{}, "{"},
+	} {
+		if _, err := svc.Import(ctx, req.req, strings.NewReader(req.body)); !errors.Is(err, dataerr.ErrValidation) {
+			t.Errorf("%s: err = %v, want a validation error", name, err)
+		}
+	}
+}
+
+func TestSelfSLOService(t *testing.T) {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	rec := apimetrics.NewRecorder()
+	svc := NewSelfSLOService(rec, 99.9, 200)
+	svc.started = start
+	svc.now = func() time.Time { return now }
+	svc.SetWindow(time.Hour)
+	svc.SetRouteTargets(map[string]SelfSLOTarget{"GET /api/v1/slow": {LatencyTargetMs: 1000}})
+
+	// Requests before the window start are not evaluated
+	for i := 0; i < 100; i++ {
+		rec.ObserveRequest("GET", "/api/v1/cost/global", 500, time.Millisecond)
+	}
+	svc.Snapshot()
+	now = start.Add(2 * time.Hour)
+	for i := 0; i < 1000; i++ {
+		status := 200
+		if i < 10 {
+			status = 503
+		}
+		rec.ObserveRequest("GET", "/api/v1/cost/global", status, 10*time.Millisecond)
+	}
+	for i := 0; i < 20; i++ {
+		rec.ObserveRequest("GET", "/api/v1/slow", 200, 300*time.Millisecond)
+	}
+
+	resp, err := svc.Evaluate(context.Background())
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if !resp.WindowStart.Equal(start.Add(time.Hour)) || resp.Status != "critical" || len(resp.Routes) != 2 {
+		t.Fatalf("response = %+v", resp)
+	}
+	global, slow := resp.Routes[0], resp.Routes[1]
+	if global.Route != "/api/v1/cost/global" || global.Requests != 1000 || global.Errors != 10 || global.Availability != 99 || global.Status != "critical" {
+		t.Errorf("global = %+v", global)
+	}
+	if slow.Status != "healthy" || slow.LatencyTargetMs != 1000 || slow.SlowRequests != 0 || slow.LatencyP95Ms <= 250 || slow.ErrorBudgetRemaining != 100 {
+		t.Errorf("slow = %+v", slow)
+	}
+
+	statuses, err := svc.ListSLOStatus(context.Background())
+	if err != nil || len(statuses) != 2 {
+		t.Fatalf("ListSLOStatus = %v, %v", statuses, err)
+	}
+	if st := statuses[0]; st.Service != "GET /api/v1/cost/global" || st.Namespace != SelfSLONamespace || st.ErrorRate != 1 {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestReconciliationService_Reconcile(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	jan := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// 2020-01: modeled 950 vs invoiced 1000 (-5%); 2020-02: modeled 500 vs invoiced 1000 (-50%)
+	stats := []postgres.HourlyWorkloadStat{
+		{Namespace: "app", WorkloadName: "api", Timestamp: jan.Add(2 * time.Hour), TotalBillableCost: 500},
+		{Namespace: "app", WorkloadName: "web", Timestamp: jan.AddDate(0, 0, 30).Add(23 * time.Hour), TotalBillableCost: 450},
+		{Namespace: "app", WorkloadName: "api", Timestamp: feb.Add(time.Hour), TotalBillableCost: 500},
+	}
+	for _, st := range stats {
+		if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	for _, start := range []time.Time{jan, feb} {
+		if err := repo.SaveBillAccountSummary(ctx, postgres.BillAccountSummary{
+			AccountID:   "acct-1",
+			PeriodType:  "month",
+			PeriodStart: start,
+			PeriodEnd:   start.AddDate(0, 1, -1),
+			TotalAmount: 1200,
+			Currency:    "CNY",
+			ByCategory:  map[string]float64{"compute": 1000, "storage": 200},
+		}); err != nil {
+			t.Fatalf("SaveBillAccountSummary: %v", err)
+		}
+	}
+
+	svc := NewReconciliationService(repo, repo, ReconciliationConfig{})
+	results, err := svc.Reconcile(ctx, "acct-1")
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 periods, got %d", len(results))
+	}
+
+	// newest first
+	febResult, janResult := results[0], results[1]
+	if !febResult.Drift || janResult.Drift {
+		t.Errorf("drift flags: feb=%v jan=%v, want true/false", febResult.Drift, janResult.Drift)
+	}
+	for _, c := range janResult.Categories {
+		switch c.Category {
+		case "compute":
+			if c.ModeledCost != 950 || c.CoveragePercent != 95 || c.DiscrepancyPercent != -5 {
+				t.Errorf("unexpected compute reconciliation: %+v", c)
+			}
+		case "storage":
+			if c.Drift {
+				t.Errorf("unmodeled category should not be flagged: %+v", c)
+			}
+		}
+	}
+}
+
+func TestGrafanaService_QueryAndSearch(t *testing.T) {
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	ctx := context.Background()
+	day1 := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	for _, c := range []postgres.DailyNamespaceCost{
+		{Namespace: "grafana-a", Date: day1, BillableCost: 100, UsageCost: 50},
+		{Namespace: "grafana-b", Date: day1, BillableCost: 300, UsageCost: 150},
+		{Namespace: "grafana-a", Date: day2, BillableCost: 200, UsageCost: 200},
+	} {
+		if err := repo.SaveDailyNamespaceCost(ctx, c); err != nil {
+			t.Fatalf("SaveDailyNamespaceCost: %v", err)
+		}
+	}
+
+	svc := NewGrafanaService(repo, DefaultMockSLOStatus())
+	results, err := svc.Query(ctx, dto.GrafanaQueryRequest{
+		Range: dto.GrafanaRange{From: day1, To: day2},
+		Targets: []dto.GrafanaQueryTarget{
+			{Target: GrafanaTargetCostTotal},
+			{Target: GrafanaTargetEfficiencyNS + ".grafana-a"},
+			{Target: GrafanaTargetSLOStatus, Type: "table"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	total := results[0].(dto.GrafanaTimeSeries)
+	if len(total.Datapoints) != 2 || total.Datapoints[0][0] != 400 || total.Datapoints[0][1] != float64(day1.UnixMilli()) {
+		t.Errorf("unexpected cost.total series: %+v", total.Datapoints)
+	}
+	eff := results[1].(dto.GrafanaTimeSeries)
+	if len(eff.Datapoints) != 2 || eff.Datapoints[0][0] != 50 || eff.Datapoints[1][0] != 100 {
+		t.Errorf("unexpected efficiency series: %+v", eff.Datapoints)
+	}
+	table := results[2].(dto.GrafanaTable)
+	if table.Type != "table" || len(table.Rows) != 3 {
+		t.Errorf("unexpected slo table: %+v", table)
+	}
+
+	if _, err := svc.Query(ctx, dto.GrafanaQueryRequest{Targets: []dto.GrafanaQueryTarget{{Target: "bogus"}}}); err == nil {
+		t.Error("expected error for unknown target")
+	}
+
+	targets, err := svc.Search(ctx, dto.GrafanaSearchRequest{Target: "slo."})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(targets) != 4 {
+		t.Errorf("expected slo.status + 3 availability targets, got %v", targets)
+	}
+
+	anns, err := svc.Annotations(ctx, dto.GrafanaAnnotationRequest{Annotation: dto.GrafanaAnnotationQuery{Query: GrafanaAnnotationSLO}})
+	if err != nil {
+		t.Fatalf("Annotations: %v", err)
+	}
+	if len(anns) != 1 || anns[0].Tags[1] != "warning" {
+		t.Errorf("expected one warning SLO annotation, got %+v", anns)
+	}
+}
+
+func TestCalculationRunService_RecordsSanitizedValues(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	svc := NewCalculationRunService(repo, func(ctx context.Context, run postgres.CalculationRun) (int, error) {
+		stats := costmodel.SanitizeStatsFrom(ctx)
+		if stats == nil {
+			return 0, errors.New("no sanitize stats attached")
+		}
+		stats.ZeroedValues, stats.DroppedRows = 4, 1
+		return 2, nil
+	})
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	run, err := svc.Execute(ctx, TriggerManual, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if run.ZeroedValues != 4 || run.DroppedRows != 1 {
+		t.Errorf("run = %+v, want 4 zeroed values and 1 dropped row", run)
+	}
+	stored, err := svc.GetRun(ctx, run.ID)
+	if err != nil || stored.ZeroedValues != 4 || stored.DroppedRows != 1 {
+		t.Errorf("stored run = %+v, %v", stored, err)
+	}
+}
+
+func TestCalculationRunService_ExecuteAndRetrigger(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	fail := true
+	pipeline := func(ctx context.Context, run postgres.CalculationRun) (int, error) {
+		if fail {
+			return 0, errors.New("prometheus unavailable")
+		}
+		return 3, nil
+	}
+	svc := NewCalculationRunService(repo, pipeline)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	failed, err := svc.Execute(ctx, TriggerSchedule, start, end)
+	if err == nil || failed == nil {
+		t.Fatalf("Execute: want recorded failed run and error, got %v, %v", failed, err)
+	}
+	if failed.Status != postgres.CalculationRunFailed || failed.Error != "prometheus unavailable" {
+		t.Errorf("failed run = %+v", failed)
+	}
+	if _, err := svc.Execute(ctx, TriggerManual, end, start); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("inverted window: err = %v, want ErrInvalidWindow", err)
+	}
+
+	fail = false
+	retry, err := svc.Retrigger(ctx, failed.ID)
+	if err != nil {
+		t.Fatalf("Retrigger: %v", err)
+	}
+	if retry.Trigger != TriggerRetry || retry.RetryOf != failed.ID || retry.Status != postgres.CalculationRunSucceeded || retry.RowsWritten != 3 {
+		t.Errorf("retry run = %+v", retry)
+	}
+	if !retry.WindowStart.Equal(start) || !retry.WindowEnd.Equal(end) {
+		t.Errorf("retry window = %v..%v, want %v..%v", retry.WindowStart, retry.WindowEnd, start, end)
+	}
+	if _, err := svc.Retrigger(ctx, retry.ID); !errors.Is(err, ErrRunNotRetriable) {
+		t.Errorf("retrigger succeeded run: err = %v, want ErrRunNotRetriable", err)
+	}
+
+	list, err := svc.ListRuns(ctx, postgres.CalculationRunFilter{Status: postgres.CalculationRunFailed})
+	if err != nil {
+		t.Fatalf("ListRuns: %v", err)
+	}
+	if list.Total != 1 || list.Runs[0].ID != failed.ID {
+		t.Errorf("failed runs = %+v, want only %s", list.Runs, failed.ID)
+	}
+}
+
+func TestSnapshotPipeline(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	svc := NewCalculationRunService(repo, NewSnapshotPipeline(repo, nil, costmodel.NonFiniteZero))
+	end := time.Now()
+	run, err := svc.Execute(ctx, TriggerManual, end.Add(-24*time.Hour), end)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if run.RowsWritten != 1 {
+		t.Errorf("RowsWritten = %d, want 1", run.RowsWritten)
+	}
+	snapshot, err := repo.GetCostSnapshot(ctx, "snapshot-"+run.ID)
+	if err != nil {
+		t.Fatalf("GetCostSnapshot: %v", err)
+	}
+	if snapshot.CalculationID != run.ID {
+		t.Errorf("CalculationID = %q, want %q", snapshot.CalculationID, run.ID)
+	}
+}
+
+func TestSnapshotPipelineNonFinite(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, st := range []postgres.HourlyWorkloadStat{
+		{Namespace: "ns1", WorkloadName: "api", Timestamp: start, TotalBillableCost: 10, TotalUsageCost: 6, TotalWasteCost: 4},
+		{Namespace: "ns1", WorkloadName: "worker", Timestamp: start, TotalBillableCost: math.NaN()},
+	} {
+		if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+
+	run, err := NewCalculationRunService(repo, NewSnapshotPipeline(repo, nil, costmodel.NonFiniteDrop)).
+		Execute(ctx, TriggerManual, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	if run.DroppedRows != 1 {
+		t.Errorf("drop: DroppedRows = %d, want 1", run.DroppedRows)
+	}
+	snapshot, err := repo.GetCostSnapshot(ctx, "snapshot-"+run.ID)
+	if err != nil {
+		t.Fatalf("GetCostSnapshot: %v", err)
+	}
+	if snapshot.TotalBillableCost != 10 {
+		t.Errorf("drop: TotalBillableCost = %v, want 10", snapshot.TotalBillableCost)
+	}
+
+	run, err = NewCalculationRunService(repo, NewSnapshotPipeline(repo, nil, costmodel.NonFiniteFail)).
+		Execute(ctx, TriggerManual, start, start.Add(time.Hour))
+	if !errors.Is(err, costmodel.ErrNonFinite) {
+		t.Errorf("fail: err = %v, want ErrNonFinite", err)
+	}
+	if run == nil || run.Status != postgres.CalculationRunFailed {
+		t.Errorf("fail: run = %+v, want a failed run", run)
+	}
+}
+
+type recordingEvents struct {
+	payloads []eventbus.Payload
+}
+
+func (r *recordingEvents) Publish(payloads ...eventbus.Payload) error {
+	r.payloads = append(r.payloads, payloads...)
+	return nil
+}
+
+type recordingGrades struct {
+	stats [][]postgres.HourlyWorkloadStat
+}
+
+func (r *recordingGrades) Observe(ctx context.Context, stats []postgres.HourlyWorkloadStat) ([]postgres.GradeChangeEvent, error) {
+	r.stats = append(r.stats, stats)
+	return nil, nil
+}
+
+func TestWithGradeTracking(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, ts := range []time.Time{start, start.Add(time.Hour)} {
+		if err := repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "ns1", WorkloadName: "api", Timestamp: ts, TotalBillableCost: 10, TotalUsageCost: 6}); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+
+	grades := &recordingGrades{}
+	runs := NewCalculationRunService(repo, WithGradeTracking(NewSnapshotPipeline(repo, nil, costmodel.NonFiniteZero), repo, grades))
+	if _, err := runs.Execute(ctx, TriggerSchedule, start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	// 只评级窗口内的小时，窗口终点的小时属于下一个窗口
+	if len(grades.stats) != 1 || len(grades.stats[0]) != 1 || !grades.stats[0][0].Timestamp.Equal(start) {
+		t.Errorf("observed stats = %+v, want the one hour of the window", grades.stats)
+	}
+
+	failing := func(ctx context.Context, run postgres.CalculationRun) (int, error) { return 0, errors.New("boom") }
+	if _, err := NewCalculationRunService(repo, WithGradeTracking(failing, repo, grades)).Execute(ctx, TriggerSchedule, start, start.Add(time.Hour)); err == nil {
+		t.Error("failing pipeline: want error")
+	}
+	if len(grades.stats) != 1 {
+		t.Errorf("failing pipeline graded the window: %d observations", len(grades.stats))
+	}
+}
+
+type recordingAlerts struct {
+	alerts []notifier.Alert
+	err    error
+}
+
+func (r *recordingAlerts) Notify(ctx context.Context, alert notifier.Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return r.err
+}
+
+func TestSnapshotGuardrail(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	alerts := &recordingAlerts{}
+	guard := NewSnapshotGuardrail(repo, 50, true)
+	guard.SetNotifier(alerts)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	save := func(id string, hour int, cost float64) postgres.CostSnapshot {
+		t.Helper()
+		s := postgres.CostSnapshot{ID: id, Timestamp: start.Add(time.Duration(hour) * time.Hour), TotalBillableCost: cost}
+		if err := guard.Check(ctx, &s); err != nil {
+			t.Fatalf("Check(%s): %v", id, err)
+		}
+		if err := repo.SaveCostSnapshot(ctx, s); err != nil {
+			t.Fatalf("SaveCostSnapshot(%s): %v", id, err)
+		}
+		return s
+	}
+
+	if s := save("guard-1", 0, 100); s.Suspect {
+		t.Errorf("first snapshot has nothing to compare with, got suspect: %s", s.SuspectReason)
+	}
+	if s := save("guard-2", 1, 120); s.Suspect {
+		t.Errorf("+20%% is within the limit, got suspect: %s", s.SuspectReason)
+	}
+	typo := save("guard-3", 2, 1200)
+	if !typo.Suspect || !typo.AwaitingConfirmation() || len(alerts.alerts) != 1 {
+		t.Fatalf("+900%% snapshot = %+v, alerts %d; want suspect awaiting confirmation and one alert", typo, len(alerts.alerts))
+	}
+	if a := alerts.alerts[0]; a.Type != notifier.AlertTypeAnomaly || a.DedupKey == "" {
+		t.Errorf("alert = %+v", a)
+	}
+	// 上一可信快照是 guard-2，未确认的 guard-3 不作为基准
+	if s := save("guard-4", 3, 125); s.Suspect {
+		t.Errorf("snapshot after an unconfirmed suspect was compared with it: %s", s.SuspectReason)
+	}
+
+	visible, err := repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{EndTime: start.Add(3 * time.Hour), ExcludeUnconfirmed: true})
+	if err != nil {
+		t.Fatalf("ListCostSnapshots: %v", err)
+	}
+	for _, s := range visible {
+		if s.ID == typo.ID {
+			t.Error("snapshot awaiting confirmation must be excluded")
+		}
+	}
+
+	confirmed, err := guard.Confirm(ctx, typo.ID, "finops")
+	if err != nil || confirmed.ConfirmedBy != "finops" || confirmed.ConfirmedAt == nil {
+		t.Fatalf("Confirm = %+v, %v", confirmed, err)
+	}
+	if _, err := guard.Confirm(ctx, typo.ID, "finops"); !errors.Is(err, ErrSnapshotNotAwaitingConfirmation) {
+		t.Errorf("second Confirm: err = %v, want ErrSnapshotNotAwaitingConfirmation", err)
+	}
+	if _, err := guard.Confirm(ctx, "guard-1", "finops"); !errors.Is(err, ErrSnapshotNotAwaitingConfirmation) {
+		t.Errorf("Confirm of a normal snapshot: err = %v, want ErrSnapshotNotAwaitingConfirmation", err)
+	}
+	suspect, err := guard.ListSuspect(ctx)
+	if err != nil || suspect.Total != 1 || suspect.Snapshots[0].ID != typo.ID || suspect.Snapshots[0].ConfirmedBy != "finops" {
+		t.Errorf("ListSuspect = %+v, %v", suspect, err)
+	}
+
+	alerts.err = errors.New("webhook down")
+	if s := save("guard-5", 4, 5); !s.Suspect || s.Metadata["guardrail_alert_error"] != "webhook down" {
+		t.Errorf("alert failure must not block the save and be recorded: %+v", s)
+	}
+}
+
+func TestSnapshotGuardrail_Disabled(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "off-1", Timestamp: start, TotalBillableCost: 1})
+	s := postgres.CostSnapshot{ID: "off-2", Timestamp: start.Add(time.Hour), TotalBillableCost: 1000}
+	if err := NewSnapshotGuardrail(repo, 0, true).Check(ctx, &s); err != nil || s.Suspect {
+		t.Errorf("disabled guardrail flagged %+v, %v", s, err)
+	}
+}
+
+func TestPricingService_CorrectPastPrice(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for h := 0; h < 2; h++ {
+		// 1 core + 1 GiB requested, half used, priced 0.1 / 0.01
+		if err := repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "pricing", WorkloadName: "api", Timestamp: start.Add(time.Duration(h) * time.Hour),
+			CPURequest: 1, CPUUsageP95: 0.5, MemRequest: 1 << 30, MemUsageP95: 1 << 29,
+			CPUBillableCost: 0.1, CPUUsageCost: 0.05, CPUWasteCost: 0.05,
+			MemBillableCost: 0.01, MemUsageCost: 0.005,
+			TotalBillableCost: 0.11, TotalUsageCost: 0.055, TotalWasteCost: 0.055,
+		}); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	snapshot := postgres.CostSnapshot{ID: "snap-pricing", TimeRangeStart: start, TimeRangeEnd: start.Add(2 * time.Hour), Timestamp: start.Add(2 * time.Hour)}
+	if err := repo.SaveCostSnapshot(ctx, snapshot); err != nil {
+		t.Fatalf("SaveCostSnapshot: %v", err)
+	}
+
+	svc := NewPricingService(repo, repo, repo, 0.1, 0.01)
+	svc.now = func() time.Time { return start.AddDate(0, 0, 7) }
+	if _, err := svc.SetPrice(ctx, dto.SetPriceRequest{EffectiveFrom: start, CPUPricePerCoreHour: 0, MemPricePerGBHour: 0.01}); !errors.Is(err, ErrInvalidPrice) {
+		t.Errorf("zero cpu price: err = %v, want ErrInvalidPrice", err)
+	}
+	if _, err := svc.SetPrice(ctx, dto.SetPriceRequest{EffectiveFrom: start.Add(2 * time.Hour), CPUPricePerCoreHour: 0.3, MemPricePerGBHour: 0.03}); err != nil {
+		t.Fatalf("SetPrice: %v", err)
+	}
+	// 更正 01:00 起生效的单价（翻倍）并立即重算：只影响 01:00~02:00
+	resp, err := svc.SetPrice(ctx, dto.SetPriceRequest{EffectiveFrom: start.Add(time.Hour), CPUPricePerCoreHour: 0.2, MemPricePerGBHour: 0.02, Recalculate: true})
+	if err != nil {
+		t.Fatalf("SetPrice: %v", err)
+	}
+	if resp.AffectedStart == nil || !resp.AffectedStart.Equal(start.Add(time.Hour)) || !resp.AffectedEnd.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("affected window = %v..%v, want 01:00..02:00", resp.AffectedStart, resp.AffectedEnd)
+	}
+	if resp.Recalculation == nil || resp.Recalculation.Trigger != TriggerRecalculation || resp.Recalculation.Status != postgres.CalculationRunSucceeded {
+		t.Fatalf("recalculation run = %+v", resp.Recalculation)
+	}
+
+	first, _ := repo.GetHourlyWorkloadStat(ctx, "pricing", "api", start)
+	second, _ := repo.GetHourlyWorkloadStat(ctx, "pricing", "api", start.Add(time.Hour))
+	if math.Abs(first.TotalBillableCost-0.11) > 1e-9 {
+		t.Errorf("hour before the corrected version = %v, want unchanged 0.11", first.TotalBillableCost)
+	}
+	if math.Abs(second.TotalBillableCost-0.22) > 1e-9 || math.Abs(second.TotalWasteCost-0.11) > 1e-9 {
+		t.Errorf("re-priced hour = billable %v waste %v, want 0.22 / 0.11", second.TotalBillableCost, second.TotalWasteCost)
+	}
+	rebuilt, err := repo.GetCostSnapshot(ctx, "snap-pricing")
+	if err != nil {
+		t.Fatalf("GetCostSnapshot: %v", err)
+	}
+	if math.Abs(rebuilt.TotalBillableCost-0.33) > 1e-9 || rebuilt.Metadata["recalculated_by"] != resp.Recalculation.ID {
+		t.Errorf("rebuilt snapshot = %v (metadata %v), want 0.33 recalculated by %s", rebuilt.TotalBillableCost, rebuilt.Metadata, resp.Recalculation.ID)
+	}
+	// 00:00~01:00 按配置单价，01:00~02:00 按更正后的版本；02:00 起的版本不在窗口内
+	wantVersions := []string{SnapshotPriceConfig, resp.Version.ID + "@1"}
+	if got, _ := rebuilt.Metadata[SnapshotMetadataPriceVersions].([]string); !reflect.DeepEqual(got, wantVersions) {
+		t.Errorf("snapshot price versions = %v, want %v", rebuilt.Metadata[SnapshotMetadataPriceVersions], wantVersions)
+	}
+
+	history, err := svc.History(ctx)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history.Versions) != 2 || history.Current == nil || history.Current.CPUPricePerCoreHour != 0.3 {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestPricingService_ApprovalWorkflow(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewPricingService(repo, repo, repo, 0.1, 0.01)
+	svc.now = func() time.Time { return start.AddDate(0, 0, 7) }
+	if _, err := svc.ProposeChange(ctx, dto.SetPriceRequest{EffectiveFrom: start}, "alice"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("ProposeChange without workflow: err = %v, want not found", err)
+	}
+	svc.SetApproval(repo, true, false)
+
+	req := dto.SetPriceRequest{EffectiveFrom: start, CPUPricePerCoreHour: 0.2, MemPricePerGBHour: 0.02, Note: "new contract"}
+	if _, err := svc.SetPrice(ctx, req); !errors.Is(err, ErrPriceApprovalRequired) {
+		t.Errorf("SetPrice with approval required: err = %v", err)
+	}
+	draft, err := svc.ProposeChange(ctx, req, "alice")
+	if err != nil {
+		t.Fatalf("ProposeChange: %v", err)
+	}
+	if history, _ := svc.History(ctx); draft.Status != postgres.PriceChangeDraft || len(history.Versions) != 0 {
+		t.Fatalf("draft = %+v, price history = %+v; want a draft and no version yet", draft, history)
+	}
+	if _, err := svc.ApproveChange(ctx, draft.ID, "alice", ""); !errors.Is(err, ErrSelfApproval) {
+		t.Errorf("self approval: err = %v", err)
+	}
+	for _, review := range []func(context.Context, string, string, string) error{
+		func(ctx context.Context, id, reviewer, comment string) error {
+			_, err := svc.ApproveChange(ctx, id, reviewer, comment)
+			return err
+		},
+		func(ctx context.Context, id, reviewer, comment string) error {
+			_, err := svc.RejectChange(ctx, id, reviewer, comment)
+			return err
+		},
+	} {
+		if err := review(ctx, draft.ID, "", ""); !errors.Is(err, ErrReviewerRequired) {
+			t.Errorf("anonymous review: err = %v, want ErrReviewerRequired", err)
+		}
+	}
+
+	resp, err := svc.ApproveChange(ctx, draft.ID, "bob", "matches the invoice")
+	if err != nil {
+		t.Fatalf("ApproveChange: %v", err)
+	}
+	if resp.Change == nil || resp.Change.Status != PriceChangeStatusEffective || resp.Change.PriceVersionID != resp.Version.ID || resp.Version.ChangeID != draft.ID {
+		t.Errorf("approved change = %+v, version = %+v", resp.Change, resp.Version)
+	}
+	if h := resp.Change.History; len(h) != 2 || h[1].Action != PriceChangeActionApproved || h[1].Actor != "bob" {
+		t.Errorf("change history = %+v", h)
+	}
+	if _, err := svc.RejectChange(ctx, draft.ID, "bob", ""); !errors.Is(err, ErrPriceChangeReviewed) {
+		t.Errorf("reject after approval: err = %v", err)
+	}
+
+	future, _ := svc.ProposeChange(ctx, dto.SetPriceRequest{EffectiveFrom: start.AddDate(0, 1, 0), CPUPricePerCoreHour: 0.3, MemPricePerGBHour: 0.03}, "alice")
+	if resp, err := svc.ApproveChange(ctx, future.ID, "bob", ""); err != nil || resp.Change.Status != postgres.PriceChangeApproved {
+		t.Errorf("future change = %+v, %v; want approved, not yet effective", resp, err)
+	}
+	rejected, _ := svc.ProposeChange(ctx, req, "alice")
+	if out, err := svc.RejectChange(ctx, rejected.ID, "bob", "wrong currency"); err != nil || out.Status != postgres.PriceChangeRejected {
+		t.Errorf("RejectChange = %+v, %v", out, err)
+	}
+	if list, _ := svc.ListChanges(ctx, postgres.PriceChangeRejected); len(list.Changes) != 1 || list.Changes[0].ID != rejected.ID {
+		t.Errorf("rejected changes = %+v", list)
+	}
+	if history, _ := svc.History(ctx); len(history.Versions) != 2 {
+		t.Errorf("price history = %+v, want the two approved versions", history.Versions)
+	}
+}
+
+type scopeErr []string
+
+func (e scopeErr) Error() string          { return "scopes failed" }
+func (e scopeErr) FailedScopes() []string { return e }
+
+func TestCalculationRunService_PartialFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	var seenScopes []string
+	svc := NewCalculationRunService(repo, func(ctx context.Context, run postgres.CalculationRun) (int, error) {
+		seenScopes = run.Scopes
+		if len(run.Scopes) == 0 {
+			return 10, scopeErr{"batch"}
+		}
+		return 2, nil
+	})
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	partial, err := svc.Execute(ctx, TriggerSchedule, start, start.Add(time.Hour))
+	if err == nil {
+		t.Fatal("Execute: want error for partial run")
+	}
+	if partial.Status != postgres.CalculationRunPartial || partial.RowsWritten != 10 || len(partial.FailedScopes) != 1 {
+		t.Fatalf("partial run = %+v", partial)
+	}
+
+	retry, err := svc.Retrigger(ctx, partial.ID)
+	if err != nil {
+		t.Fatalf("Retrigger: %v", err)
+	}
+	if len(seenScopes) != 1 || seenScopes[0] != "batch" {
+		t.Errorf("retry scopes = %v, want [batch]", seenScopes)
+	}
+	if retry.Status != postgres.CalculationRunSucceeded || len(retry.Scopes) != 1 {
+		t.Errorf("retry run = %+v", retry)
+	}
+}
+
+type staticNodes []k8s.Node
+
+func (n staticNodes) GetNodes(ctx context.Context) ([]k8s.Node, error) { return n, nil }
+
+func TestNodeAnalysisService_Consolidation(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 7, 1, 10, 30, 0, 0, time.UTC)
+	node := func(name string) k8s.Node {
+		return k8s.Node{Name: name, Allocatable: map[string]string{"cpu": "10", "memory": "40Gi"}}
+	}
+	const gib = 1 << 30
+	for _, st := range []postgres.HourlyWorkloadStat{
+		{Namespace: "app", WorkloadName: "db", PodName: "db-0", NodeName: "node-1", CPURequest: 8, MemRequest: 32 * gib, CPUUsageP95: 6},
+		{Namespace: "app", WorkloadName: "web", PodName: "web-1", NodeName: "node-2", CPURequest: 1, MemRequest: 4 * gib},
+		{Namespace: "app", WorkloadName: "api", PodName: "api-1", NodeName: "node-3", CPURequest: 2, MemRequest: 8 * gib},
+		// 更早一小时的数据不参与计算
+		{Namespace: "app", WorkloadName: "old", PodName: "old-1", NodeName: "node-2", CPURequest: 9, Timestamp: now.Add(-2 * time.Hour)},
+	} {
+		if st.Timestamp.IsZero() {
+			st.Timestamp = now.Truncate(time.Hour)
+		}
+		_ = repo.SaveHourlyWorkloadStat(ctx, st)
+	}
+	svc := NewNodeAnalysisService(staticNodes{node("node-1"), node("node-2"), node("node-3")}, repo, 0.025, 0.01)
+	svc.now = func() time.Time { return now }
+
+	resp, err := svc.Analyze(ctx, 0)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(resp.Nodes) != 3 || resp.Nodes[1].BinPackingEfficiency != 10 || resp.Nodes[0].BinPackingEfficiency != 80 {
+		t.Fatalf("unexpected nodes: %+v", resp.Nodes)
+	}
+	if resp.Cluster.RequestedCPU != 11 || resp.Cluster.PodCount != 3 {
+		t.Errorf("unexpected cluster summary: %+v", resp.Cluster)
+	}
+	// node-2 可被 node-3 吸收；之后 node-1 的余量不足以再腾空 node-3
+	if len(resp.Candidates) != 1 || resp.Candidates[0].Name != "node-2" {
+		t.Fatalf("expected node-2 as the only candidate, got %+v", resp.Candidates)
+	}
+	if want := (10*0.025 + 40*0.01) * 730; math.Abs(resp.EstimatedMonthlySavings-want) > 1e-9 {
+		t.Errorf("savings: want %v, got %v", want, resp.EstimatedMonthlySavings)
+	}
+
+	if _, err := svc.Analyze(ctx, 1.5); !errors.Is(err, ErrInvalidTargetUtilization) {
+		t.Errorf("expected ErrInvalidTargetUtilization, got %v", err)
+	}
+}
+
+type staticBudgets []k8s.PodDisruptionBudget
+
+func (b staticBudgets) GetPodDisruptionBudgets(ctx context.Context, namespace string) ([]k8s.PodDisruptionBudget, error) {
+	return b, nil
+}
+
+func TestNodeAnalysisService_Plan(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 7, 1, 10, 30, 0, 0, time.UTC)
+	const gib = 1 << 30
+	var pods staticPods
+	for _, st := range []postgres.HourlyWorkloadStat{
+		{Namespace: "app", WorkloadName: "db", PodName: "db-0", NodeName: "node-1", CPURequest: 6, MemRequest: 24 * gib},
+		{Namespace: "app", WorkloadName: "web", PodName: "web-0", NodeName: "node-2", CPURequest: 1, MemRequest: 4 * gib},
+		{Namespace: "app", WorkloadName: "api", PodName: "api-0", NodeName: "node-3", CPURequest: 1, MemRequest: 4 * gib},
+		{Namespace: "app", WorkloadName: "api-canary", PodName: "api-1", NodeName: "node-3", CPURequest: 1, MemRequest: 4 * gib},
+		{Namespace: "app", WorkloadName: "cache", PodName: "cache-0", NodeName: "node-4", CPURequest: 1.5, MemRequest: 6 * gib},
+	} {
+		st.Timestamp = now.Truncate(time.Hour)
+		if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+		pods = append(pods, k8s.Pod{Name: st.PodName, Namespace: st.Namespace, NodeName: st.NodeName, Phase: "Running", Labels: map[string]string{"app": strings.TrimSuffix(st.WorkloadName, "-canary")}})
+	}
+	var nodes staticNodes
+	for i := 1; i <= 4; i++ {
+		nodes = append(nodes, k8s.Node{Name: fmt.Sprintf("node-%d", i), Allocatable: map[string]string{"cpu": "10", "memory": "40Gi"}})
+	}
+	svc := NewNodeAnalysisService(nodes, repo, 0.025, 0.01)
+	svc.now = func() time.Time { return now }
+	nodeSavings := (10*0.025 + 40*0.01) * 730
+
+	// 未核对 PDB：按装箱效率排空 node-2、node-4，风险为 medium
+	plan, err := svc.Plan(ctx, 0)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if plan.DisruptionBudgetsChecked || len(plan.Steps) != 2 || plan.Steps[0].Node != "node-2" || plan.Steps[1].Node != "node-4" {
+		t.Fatalf("unexpected unchecked plan: %+v", plan)
+	}
+	if plan.Steps[0].Risk != DrainRiskMedium {
+		t.Errorf("unchecked step risk = %s, want medium", plan.Steps[0].Risk)
+	}
+
+	// web 的 PDB 不允许驱逐：node-2 被阻塞，重新选择后排空 node-4、node-3
+	svc.SetDisruptionBudgets(pods, staticBudgets{
+		{Namespace: "app", Name: "web", Selector: map[string]string{"app": "web"}, DisruptionsAllowed: 0},
+		{Namespace: "app", Name: "api", Selector: map[string]string{"app": "api"}, DisruptionsAllowed: 1},
+		{Namespace: "other", Name: "cache", Selector: map[string]string{"app": "cache"}, DisruptionsAllowed: 0},
+	})
+	plan, err = svc.Plan(ctx, 0)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if !plan.DisruptionBudgetsChecked || len(plan.Blocked) != 1 || plan.Blocked[0].Node != "node-2" {
+		t.Fatalf("expected node-2 blocked, got %+v", plan.Blocked)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[0].Node != "node-4" || plan.Steps[1].Node != "node-3" {
+		t.Fatalf("expected drain order node-4, node-3, got %+v", plan.Steps)
+	}
+	first, second := plan.Steps[0], plan.Steps[1]
+	if first.Risk != DrainRiskLow || len(first.DisruptionBudgets) != 0 {
+		t.Errorf("unexpected first step: %+v", first)
+	}
+	// api 的 PDB 覆盖 node-3 上的 2 个 Pod 但只允许一次驱逐一个
+	if second.Risk != DrainRiskMedium || len(second.DisruptionBudgets) != 1 || second.DisruptionBudgets[0].PodsOnNode != 2 || second.PodCount != 2 {
+		t.Errorf("unexpected second step: %+v", second)
+	}
+	if first.RemainingCPURequestRatio != 35 || second.RemainingCPURequestRatio != 52.5 {
+		t.Errorf("remaining cpu request ratios = %v, %v; want 35, 52.5", first.RemainingCPURequestRatio, second.RemainingCPURequestRatio)
+	}
+	if math.Abs(second.CumulativeMonthlySavings-2*nodeSavings) > 1e-9 || math.Abs(plan.EstimatedMonthlySavings-2*nodeSavings) > 1e-9 {
+		t.Errorf("savings: want %v, got %v / %v", 2*nodeSavings, second.CumulativeMonthlySavings, plan.EstimatedMonthlySavings)
+	}
+	if len(first.Commands) != 2 || first.Commands[0] != "kubectl cordon node-4" {
+		t.Errorf("unexpected commands: %v", first.Commands)
+	}
+
+	if _, err := svc.Plan(ctx, 1.5); !errors.Is(err, ErrInvalidTargetUtilization) {
+		t.Errorf("expected ErrInvalidTargetUtilization, got %v", err)
+	}
+}
+
+func TestNodeAnalysisService_Pools(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 8, 10, 12, 0, 0, 0, time.UTC)
+	hour := now.Add(-time.Hour)
+	for _, st := range []postgres.HourlyWorkloadStat{
+		{Namespace: "app", WorkloadName: "api", NodePool: "general", NodeName: "node-1", Timestamp: hour, TotalBillableCost: 6, TotalUsageCost: 3, TotalWasteCost: 3},
+		{Namespace: "app", WorkloadName: "web", NodeName: "node-2", Timestamp: hour, TotalBillableCost: 2, TotalUsageCost: 2},
+		{Namespace: "ml", WorkloadName: "train", NodePool: "gpu", NodeName: "node-3", Timestamp: hour, TotalBillableCost: 10, TotalUsageCost: 9, TotalWasteCost: 1},
+		{Namespace: "old", WorkloadName: "batch", NodeName: "gone", Timestamp: hour, TotalBillableCost: 2, TotalUsageCost: 1, TotalWasteCost: 1},
+		{Namespace: "app", WorkloadName: "api", NodePool: "general", Timestamp: now.Add(-48 * time.Hour), TotalBillableCost: 100},
+	} {
+		if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	node := func(name, pool string) k8s.Node {
+		return k8s.Node{Name: name, Labels: map[string]string{"karpenter.sh/nodepool": pool}, Allocatable: map[string]string{"cpu": "4", "memory": "16Gi"}}
+	}
+	svc := NewNodeAnalysisService(staticNodes{node("node-1", "general"), node("node-2", "general"), node("node-3", "gpu")}, repo, 0.025, 0.01)
+	svc.now = func() time.Time { return now }
+
+	resp, err := svc.Pools(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Pools: %v", err)
+	}
+	if !resp.StartTime.Equal(now.Add(-24*time.Hour)) || resp.TotalBillableCost != 20 {
+		t.Fatalf("unexpected window or total: %v..%v, %v", resp.StartTime, resp.EndTime, resp.TotalBillableCost)
+	}
+	if len(resp.Pools) != 3 {
+		t.Fatalf("want pools gpu, general, unassigned; got %+v", resp.Pools)
+	}
+	gpu, general, unassigned := resp.Pools[0], resp.Pools[1], resp.Pools[2]
+	if gpu.Name != "gpu" || gpu.BillableCost != 10 || gpu.CostPercentage != 50 || gpu.NodeCount != 1 {
+		t.Errorf("unexpected gpu pool: %+v", gpu)
+	}
+	// node-2 的统计未记录节点池，按节点当前所属池归入 general
+	if general.Name != "general" || general.BillableCost != 8 || general.WorkloadCount != 2 || general.NodeCount != 2 ||
+		general.AllocatableCPU != 8 || math.Abs(general.EfficiencyScore-62.5) > 1e-9 {
+		t.Errorf("unexpected general pool: %+v", general)
+	}
+	if math.Abs(general.HourlyAllocatableCost-2*(4*0.025+16*0.01)) > 1e-9 {
+		t.Errorf("general hourly allocatable cost = %v", general.HourlyAllocatableCost)
+	}
+	if unassigned.Name != costmodel.UnassignedNodePool || unassigned.BillableCost != 2 || unassigned.NodeCount != 0 {
+		t.Errorf("unexpected unassigned pool: %+v", unassigned)
+	}
+
+	if _, err := svc.Pools(ctx, now, now.Add(-time.Hour)); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("inverted window: got %v, want ErrInvalidWindow", err)
+	}
+}
+
+func TestCapacityService_Projection(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 8, 10, 12, 0, 0, 0, time.UTC)
+	today := now.Truncate(24 * time.Hour)
+	const gib = 1 << 30
+	for i, cpu := range []float64{2, 3, 4} {
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "app", WorkloadName: "api", Timestamp: today.AddDate(0, 0, i-2), CPURequest: cpu, MemRequest: 2 * gib,
+		})
+	}
+	node := func(name string) k8s.Node {
+		return k8s.Node{Name: name, Labels: map[string]string{DefaultNodePoolLabel: "compute"}, Allocatable: map[string]string{"cpu": "4", "memory": "16Gi"}}
+	}
+	svc := NewCapacityService(staticNodes{node("node-1"), node("node-2")}, repo, 0.025, 0.01)
+	svc.now = func() time.Time { return now }
+
+	resp, err := svc.Project(ctx, CapacityProjectionQuery{HorizonDays: 30, NodeDelta: 1})
+	if err != nil {
+		t.Fatalf("Project: %v", err)
+	}
+	if len(resp.Namespaces) != 1 || resp.Namespaces[0].CPURequest != 4 || math.Abs(resp.Namespaces[0].CPUGrowthPerDay-1) > 1e-9 {
+		t.Fatalf("unexpected namespace growth: %+v", resp.Namespaces)
+	}
+	// 8 核 * 0.85 = 6.8，4+d 在第 3 天超出
+	if d := resp.Baseline.CPUExhaustionDate; d == nil || !d.Equal(today.AddDate(0, 0, 3)) {
+		t.Errorf("baseline cpu exhaustion: got %v", d)
+	}
+	if resp.Baseline.MemExhaustionDate != nil {
+		t.Errorf("memory is flat and should not exhaust, got %v", resp.Baseline.MemExhaustionDate)
+	}
+	if resp.Scenario == nil || resp.Scenario.Pool != "compute" || resp.Scenario.NodeCount != 3 {
+		t.Fatalf("unexpected scenario: %+v", resp.Scenario)
+	}
+	// 12 核 * 0.85 = 10.2，第 7 天超出
+	if d := resp.Scenario.CPUExhaustionDate; d == nil || !d.Equal(today.AddDate(0, 0, 7)) {
+		t.Errorf("scenario cpu exhaustion: got %v", d)
+	}
+	if want := (4*0.025 + 16*0.01) * 730; math.Abs(resp.Scenario.MonthlyCostDelta-want) > 1e-9 {
+		t.Errorf("monthly cost delta: want %v, got %v", want, resp.Scenario.MonthlyCostDelta)
+	}
+	if len(resp.Series) != 31 {
+		t.Errorf("expected 31 daily points, got %d", len(resp.Series))
+	}
+
+	if _, err := svc.Project(ctx, CapacityProjectionQuery{NodeDelta: 1, Pool: "gpu"}); !errors.Is(err, ErrUnknownNodePool) {
+		t.Errorf("expected ErrUnknownNodePool, got %v", err)
+	}
+	if _, err := svc.Project(ctx, CapacityProjectionQuery{NodeDelta: -2}); !errors.Is(err, ErrInvalidCapacityQuery) {
+		t.Errorf("removing every node should be rejected, got %v", err)
+	}
+}
+
+// staticPods lists the same pods for the single namespace "app".
+type staticPods []k8s.Pod
+
+func (p staticPods) GetNamespaces(ctx context.Context) ([]k8s.Namespace, error) {
+	return []k8s.Namespace{{Name: "app"}}, nil
+}
+
+func (p staticPods) GetPods(ctx context.Context, namespace, deployment string) ([]k8s.Pod, error) {
+	return p, nil
+}
+
+func TestCapacityService_Simulate(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	var nodes staticNodes
+	for i := 0; i < 4; i++ {
+		nodes = append(nodes, k8s.Node{Name: fmt.Sprintf("node-%d", i), Labels: map[string]string{DefaultNodePoolLabel: "compute"}, Allocatable: map[string]string{"cpu": "4", "memory": "16Gi"}})
+	}
+	nodes = append(nodes, k8s.Node{Name: "gpu-0", Labels: map[string]string{DefaultNodePoolLabel: "gpu"}, Allocatable: map[string]string{"cpu": "8", "memory": "32Gi"}})
+	var pods staticPods
+	for i := 0; i < 12; i++ {
+		pod := k8s.Pod{Name: fmt.Sprintf("api-%d", i), NodeName: fmt.Sprintf("node-%d", i%4), Phase: "Running", Containers: []k8s.Container{
+			{Name: "app", Resources: k8s.ContainerResources{Requests: map[string]string{"cpu": "750m", "memory": "1536Mi"}}},
+			{Name: "sidecar", Resources: k8s.ContainerResources{Requests: map[string]string{"cpu": "250m", "memory": "512Mi"}}},
+		}}
+		if i == 11 {
+			pod.NodeName = "" // Pending，归入最大的池
+			pod.Phase = "Pending"
+		}
+		pods = append(pods, pod)
+	}
+	pods = append(pods, k8s.Pod{Name: "job", NodeName: "gpu-0", Phase: "Succeeded", Containers: []k8s.Container{
+		{Name: "job", Resources: k8s.ContainerResources{Requests: map[string]string{"cpu": "8"}}},
+	}})
+	svc := NewCapacityService(nodes, repo, 0.025, 0.01)
+	if _, err := svc.Simulate(ctx, CapacitySimulationQuery{}); !errors.Is(err, ErrPodsUnavailable) {
+		t.Fatalf("expected ErrPodsUnavailable without a pod lister, got %v", err)
+	}
+	svc.SetPodLister(pods)
+
+	resp, err := svc.Simulate(ctx, CapacitySimulationQuery{RequestChangePercent: -20})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	// 4*0.85=3.4 核：当前每节点 3 个 1 核 pod 需 4 个节点，减少 20% 后每节点 4 个只需 3 个
+	if resp.NodeCount != 5 || resp.BaselineNodeCount != 5 || resp.SimulatedNodeCount != 4 || resp.NodeReduction != 1 {
+		t.Fatalf("unexpected node counts: %+v", resp)
+	}
+	nodeCost := (4*0.025 + 16*0.01) * 730
+	if math.Abs(resp.MonthlySavings-nodeCost) > 1e-9 || math.Abs(resp.SimulatedMonthlyCost-(resp.MonthlyCost-nodeCost)) > 1e-9 {
+		t.Errorf("unexpected savings: %v of %v", resp.MonthlySavings, resp.MonthlyCost)
+	}
+	if resp.CPURequest != 12 || math.Abs(resp.SimulatedCPURequest-9.6) > 1e-9 {
+		t.Errorf("unexpected requests: %v -> %v", resp.CPURequest, resp.SimulatedCPURequest)
+	}
+	if want := (-2.4*0.025 - 4.8*0.01) * 730; math.Abs(resp.RequestCostDelta-want) > 1e-9 {
+		t.Errorf("request cost delta: want %v, got %v", want, resp.RequestCostDelta)
+	}
+	if len(resp.Pools) != 2 || resp.Pools[1].Name != "gpu" || resp.Pools[1].Pods != 0 || resp.Pools[1].SimulatedNodeCount != 1 {
+		t.Errorf("pools without pods should keep their nodes: %+v", resp.Pools)
+	}
+
+	if resp, err := svc.Simulate(ctx, CapacitySimulationQuery{Pool: "gpu"}); err != nil || len(resp.Pools) != 1 || resp.NodeReduction != 0 {
+		t.Errorf("pool filter: %+v, %v", resp, err)
+	}
+	if _, err := svc.Simulate(ctx, CapacitySimulationQuery{Pool: "bogus"}); !errors.Is(err, ErrUnknownNodePool) {
+		t.Errorf("expected ErrUnknownNodePool, got %v", err)
+	}
+	if _, err := svc.Simulate(ctx, CapacitySimulationQuery{RequestChangePercent: -100}); !errors.Is(err, ErrInvalidCapacityQuery) {
+		t.Errorf("removing every request should be rejected, got %v", err)
+	}
+}
+
+// tamperedRepository returns one snapshot with an altered total, as if the row was edited in the database.
+type tamperedRepository struct {
+	*postgres.MockRepository
+	id string
+}
+
+func (r tamperedRepository) ListCostSnapshots(ctx context.Context, filter postgres.CostSnapshotFilter) ([]postgres.CostSnapshot, error) {
+	snapshots, err := r.MockRepository.ListCostSnapshots(ctx, filter)
+	for i := range snapshots {
+		if snapshots[i].ID == r.id {
+			snapshots[i].TotalBillableCost *= 0.5
+		}
+	}
+	return snapshots, err
+}
+
+func TestSnapshotIntegrityService_VerifyAndExport(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	day := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"snap-1", "snap-2"} {
+		if err := repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: id, Timestamp: day.Add(time.Duration(i) * time.Hour), TotalBillableCost: 10}); err != nil {
+			t.Fatalf("SaveCostSnapshot: %v", err)
+		}
+	}
+
+	svc := NewSnapshotIntegrityService(repo, repo)
+	all, err := svc.Verify(ctx, "")
+	if err != nil || !all.Verified {
+		t.Fatalf("Verify all = %+v, %v; want verified", all, err)
+	}
+	if _, err := svc.Verify(ctx, "no-such-snapshot"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("Verify unknown snapshot error = %v, want ErrSnapshotNotFound", err)
+	}
+	export, _, err := svc.Export(ctx, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(export.Snapshots) != 2 || export.Snapshots[0].ID != "snap-1" || export.Snapshots[0].ContentHash == "" {
+		t.Fatalf("exported %+v, want snap-1 and snap-2 with hashes", export.Snapshots)
+	}
+	var exported postgres.CostSnapshot
+	if err := json.Unmarshal(export.Snapshots[0].Snapshot, &exported); err != nil {
+		t.Fatalf("unmarshal exported snapshot: %v", err)
+	}
+	if hash, _ := postgres.SnapshotContentHash(exported); hash != export.Snapshots[0].ContentHash {
+		t.Error("a recipient re-hashing the exported snapshot should get its content hash")
+	}
+
+	tampered := NewSnapshotIntegrityService(tamperedRepository{MockRepository: repo, id: "snap-2"}, repo)
+	one, err := tampered.Verify(ctx, "snap-2")
+	if err != nil || one.Verified || len(one.Issues) != 1 || one.Issues[0].Problem != postgres.IntegrityContentMismatch {
+		t.Errorf("Verify tampered snapshot = %+v, %v; want one content_mismatch", one, err)
+	}
+	if other, _ := tampered.Verify(ctx, "snap-1"); !other.Verified {
+		t.Errorf("untouched snapshot should still verify: %+v", other.Issues)
+	}
+	if _, verification, err := tampered.Export(ctx, day, day.Add(24*time.Hour)); !errors.Is(err, ErrSnapshotIntegrity) || verification == nil || verification.Verified {
+		t.Errorf("Export with a tampered snapshot error = %v, want ErrSnapshotIntegrity with the failed verification", err)
+	}
+	if _, _, err := tampered.Export(ctx, day, day.Add(time.Hour)); err != nil {
+		t.Errorf("Export of a window without the tampered snapshot: %v", err)
+	}
+}
+
+func TestStateService_ExportImport(t *testing.T) {
+	ctx := context.Background()
+	staging := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	day := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	if err := staging.SaveROIBaseline(ctx, postgres.ROIBaseline{ID: "roi-staging", Name: "Q1", BaselineType: "cost",
+		TimePeriodStart: day, TimePeriodEnd: day.AddDate(0, 1, 0), Metrics: map[string]float64{"total_cost": 100}}); err != nil {
+		t.Fatalf("SaveROIBaseline: %v", err)
+	}
+	if err := staging.SavePriceVersion(ctx, postgres.PriceVersion{EffectiveFrom: day, CPUPricePerCoreHour: 0.05, MemPricePerGBHour: 0.005}); err != nil {
+		t.Fatalf("SavePriceVersion: %v", err)
+	}
+	bundle, err := NewStateService(staging, staging, StateConfig{Environment: "staging",
+		SLO: &dto.SLOConfigState{AvailabilityThreshold: 99.9, LatencyP95ThresholdMs: 300}}).Export(ctx)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("marshal bundle: %v", err)
+	}
+	var decoded dto.StateBundle
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal bundle: %v", err)
+	}
+
+	prod := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	svc := NewStateService(prod, prod, StateConfig{Environment: "prod",
+		SLO: &dto.SLOConfigState{AvailabilityThreshold: 99.95, LatencyP95ThresholdMs: 300}})
+	changeOf := func(resp *dto.StateImportResponse, section, key string) string {
+		for _, c := range resp.Changes {
+			if c.Section == section && c.Key == key {
+				return c.Action
+			}
+		}
+		return dto.StateActionUnchanged
+	}
+	preview, err := svc.Import(ctx, decoded, true)
+	if err != nil {
+		t.Fatalf("Import dry run: %v", err)
+	}
+	priceKey := day.Format(time.RFC3339)
+	if changeOf(preview, StateSectionROIBaselines, "roi-staging") != dto.StateActionCreate || changeOf(preview, StateSectionPriceVersions, priceKey) != dto.StateActionCreate {
+		t.Fatalf("dry run changes = %+v, want baseline and price created", preview.Changes)
+	}
+	if len(preview.ConfigChanges) != 1 || preview.ConfigChanges[0].Section != StateSectionSLO || preview.ConfigChanges[0].Action != dto.StateActionUpdate {
+		t.Errorf("config changes = %+v, want the SLO update only", preview.ConfigChanges)
+	}
+	if b, _ := prod.GetROIBaseline(ctx, "roi-staging"); b != nil {
+		t.Error("dry run should not save the baseline")
+	}
+
+	if _, err := svc.Import(ctx, decoded, false); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if b, err := prod.GetROIBaseline(ctx, "roi-staging"); err != nil || b == nil || b.Metrics["total_cost"] != 100 {
+		t.Fatalf("imported baseline = %+v, %v", b, err)
+	}
+	again, err := svc.Import(ctx, decoded, true)
+	if err != nil {
+		t.Fatalf("Import again: %v", err)
+	}
+	if len(again.Changes) != 0 {
+		t.Errorf("re-import changes = %+v, want none", again.Changes)
+	}
+
+	decoded.Version = 99
+	if _, err := svc.Import(ctx, decoded, true); !errors.Is(err, ErrInvalidStateBundle) {
+		t.Errorf("unknown version error = %v, want ErrInvalidStateBundle", err)
+	}
+}
+
+func TestEfficiencyTargetService(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	svc := NewEfficiencyTargetService(repo)
+	end := time.Date(2020, 5, 10, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return end }
+	for day, usage := range []float64{50, 65, 70, 30, 80, 90} { // 5/5..5/10
+		_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "targets", Date: end.AddDate(0, 0, day-5), BillableCost: 100, UsageCost: usage})
+	}
+
+	if _, err := svc.SetTarget(ctx, "targets", dto.SetEfficiencyTargetRequest{Target: 120}); !errors.Is(err, ErrInvalidEfficiencyTarget) {
+		t.Errorf("target 120: err = %v, want ErrInvalidEfficiencyTarget", err)
+	}
+	if _, err := svc.Track(ctx, "targets", time.Time{}, time.Time{}); !errors.Is(err, ErrEfficiencyTargetNotFound) {
+		t.Errorf("Track without target: err = %v, want ErrEfficiencyTargetNotFound", err)
+	}
+	if _, err := svc.SetTarget(ctx, "targets", dto.SetEfficiencyTargetRequest{Target: 60, SetBy: "team-a"}); err != nil {
+		t.Fatalf("SetTarget: %v", err)
+	}
+
+	list, err := svc.ListTargets(ctx)
+	if err != nil || list.TotalEstimate != 1 || list.Items[0].Namespace != "targets" || list.Items[0].Target != 60 || list.Items[0].SetBy != "team-a" {
+		t.Errorf("ListTargets = %+v, %v", list, err)
+	}
+	resp, err := svc.Track(ctx, "targets", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Track: %v", err)
+	}
+	if len(resp.Days) != 6 || !resp.Days[0].Date.Before(resp.Days[5].Date) || resp.Days[0].Met || !resp.Days[1].Met {
+		t.Errorf("Days = %+v", resp.Days)
+	}
+	if a := resp.Attainment; a.DaysAtTarget != 4 || a.CurrentStreak != 2 || a.LongestStreak != 2 || a.AverageEfficiency != 64.17 {
+		t.Errorf("Attainment = %+v", a)
+	}
+}
+
+func TestOnboardingService(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	alerts := NewAlertRuleService(repo, repo)
+	svc := NewOnboardingService(repo, NewEfficiencyTargetService(repo))
+	svc.SetAlertRules(alerts)
+	svc.SetAvailabilityThreshold(99.5)
+	svc.SetFallbackOwnership(notifier.StaticOwnership{"*": {"finops@example.com"}})
+	floatPtr := func(v float64) *float64 { return &v }
+	if err := svc.SetDefaults(OnboardingDefaults{SLOTemplates: []string{"latency"}}); !errors.Is(err, ErrInvalidOnboarding) {
+		t.Errorf("unknown default template: err = %v", err)
+	}
+	if err := svc.SetDefaults(OnboardingDefaults{MonthlyBudget: 800}); err != nil {
+		t.Fatalf("SetDefaults: %v", err)
+	}
+
+	for _, req := range []dto.OnboardNamespaceRequest{
+		{Namespace: "Payments", Team: "pay"},
+		{Namespace: "payments"},
+		{Namespace: "payments", Team: "pay", Owners: []string{"alice"}},
+		{Namespace: "payments", Team: "pay", SLOTemplates: []string{"latency"}},
+		{Namespace: "payments", Team: "pay", Channels: []string{"fax"}},
+		{Namespace: "payments", Team: "pay", EfficiencyTarget: floatPtr(120)},
+	} {
+		if _, err := svc.Onboard(ctx, req, "admin"); !errors.Is(err, ErrInvalidOnboarding) {
+			t.Errorf("Onboard(%+v): err = %v, want invalid", req, err)
+		}
+	}
+
+	rec, err := svc.Onboard(ctx, dto.OnboardNamespaceRequest{Namespace: "payments", Team: "pay", Owners: []string{"alice@example.com"}}, "admin")
+	if err != nil {
+		t.Fatalf("Onboard: %v", err)
+	}
+	if rec.MonthlyBudget != 800 || rec.EfficiencyTarget != 60 || len(rec.SLOTemplates) != 2 || rec.ReportRecipients[0] != "alice@example.com" {
+		t.Errorf("defaults not applied: %+v", rec)
+	}
+	statuses := map[string][]string{}
+	for _, step := range rec.Steps {
+		statuses[step.Step] = append(statuses[step.Step], step.Status)
+	}
+	want := map[string][]string{"ownership": {"created"}, "budget": {"created"}, "slo": {"created", "created"}, "report_subscription": {"created"}, "tracking": {"created"}}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("steps = %v, want %v", statuses, want)
+	}
+	rules, _ := alerts.List(ctx)
+	conditions := map[string]string{}
+	for _, r := range rules.Rules {
+		conditions[r.Name] = r.Condition
+	}
+	if conditions["onboarding/payments/budget"] != `cost{namespace="payments"} > 800` ||
+		conditions["onboarding/payments/slo-availability"] != `slo_availability{namespace="payments"} < 99.5` {
+		t.Errorf("rules = %v", conditions)
+	}
+	if target, ok, _ := svc.targets.EfficiencyTarget(ctx, "payments"); !ok || target != 60 {
+		t.Errorf("efficiency target = %v, %v", target, ok)
+	}
+	if owners, _ := svc.OwnersFor(ctx, "payments"); fmt.Sprint(owners) != "[alice@example.com]" {
+		t.Errorf("OwnersFor(payments) = %v", owners)
+	}
+	if owners, _ := svc.OwnersFor(ctx, "search"); fmt.Sprint(owners) != "[finops@example.com]" {
+		t.Errorf("OwnersFor(search) = %v, want the fallback", owners)
+	}
+
+	// 再次接入：预算 0 删除预算告警，仅保留 availability 模板，其余设置按新请求更新
+	rec, err = svc.Onboard(ctx, dto.OnboardNamespaceRequest{
+		Namespace: "payments", Team: "payments-core", MonthlyBudget: floatPtr(0), SLOTemplates: []string{"availability"},
+		ReportRecipients: []string{"reports@example.com"}, EfficiencyTarget: floatPtr(75),
+	}, "bob")
+	if err != nil {
+		t.Fatalf("Onboard again: %v", err)
+	}
+	statuses = map[string][]string{}
+	for _, step := range rec.Steps {
+		statuses[step.Step] = append(statuses[step.Step], step.Status)
+	}
+	want = map[string][]string{"ownership": {"updated"}, "budget": {"removed"}, "slo": {"updated", "removed"}, "report_subscription": {"updated"}, "tracking": {"updated"}}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("steps = %v, want %v", statuses, want)
+	}
+	if rules, _ := alerts.List(ctx); len(rules.Rules) != 1 {
+		t.Errorf("rules after re-onboarding = %+v", rules.Rules)
+	}
+	got, err := svc.Get(ctx, "payments")
+	if err != nil || got.Team != "payments-core" || got.OnboardedBy != "admin" || got.EfficiencyTarget != 75 {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+	if _, err := svc.Get(ctx, "search"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("Get(search): err = %v", err)
+	}
+	if list, err := svc.List(ctx); err != nil || len(list) != 1 {
+		t.Errorf("List = %v, %v", list, err)
+	}
+}
+
+func TestEfficiencyTargetService_Heatmap(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	svc := NewEfficiencyTargetService(repo)
+	today := time.Date(2020, 5, 10, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return today.Add(15 * time.Hour) }
+	save := func(ns string, daysAgo int, billable, usage float64) {
+		_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: ns, Date: today.AddDate(0, 0, -daysAgo), BillableCost: billable, UsageCost: usage})
+	}
+	save("heat-big", 0, 300, 150)
+	save("heat-big", 1, 100, 10)
+	save("heat-big", 4, 100, 80)
+	save("heat-big", 5, 100, 80) // 窗口外
+	save("heat-small", 3, 50, 40)
+	if _, err := svc.SetTarget(ctx, "heat-small", dto.SetEfficiencyTargetRequest{Target: 70}); err != nil {
+		t.Fatalf("SetTarget: %v", err)
+	}
+
+	resp, err := svc.Heatmap(ctx, EfficiencyHeatmapQuery{Days: 5, BucketDays: 2})
+	if err != nil {
+		t.Fatalf("Heatmap: %v", err)
+	}
+	if !resp.WindowStart.Equal(today.AddDate(0, 0, -4)) || !resp.WindowEnd.Equal(today) || len(resp.Buckets) != 3 || !resp.Buckets[2].Equal(today) {
+		t.Fatalf("window = %v..%v, buckets %v", resp.WindowStart, resp.WindowEnd, resp.Buckets)
+	}
+	if resp.Total != 2 || resp.Rows[0].Namespace != "heat-big" || resp.Rows[1].Namespace != "heat-small" {
+		t.Fatalf("rows = %+v", resp.Rows)
+	}
+	cellsOf := func(r dto.EfficiencyHeatmapRow) []interface{} {
+		out := make([]interface{}, len(r.Cells))
+		for i, c := range r.Cells {
+			if c != nil {
+				out[i] = *c
+			}
+		}
+		return out
+	}
+	// buckets: 5/6-5/7, 5/8-5/9, 5/10 (shorter)
+	if got := cellsOf(resp.Rows[0]); got[0] != 80.0 || got[1] != 10.0 || got[2] != 50.0 {
+		t.Errorf("heat-big cells = %v", got)
+	}
+	if r := resp.Rows[0]; r.Efficiency != 48.0 || r.Target != nil {
+		t.Errorf("heat-big = %+v", r)
+	}
+	if r := resp.Rows[1]; cellsOf(r)[0] != 80.0 || r.Cells[1] != nil || r.Cells[2] != nil || r.Target == nil || *r.Target != 70 {
+		t.Errorf("heat-small = %+v", r)
+	}
+
+	if resp, err := svc.Heatmap(ctx, EfficiencyHeatmapQuery{Days: 5, Limit: 1}); err != nil || resp.Total != 1 || len(resp.Buckets) != 5 {
+		t.Errorf("limit 1 = %+v, %v", resp, err)
+	}
+	// 17:00Z 在上海已是 5/11，窗口随会计日后移
+	shanghai, _ := costmodel.NewAccountingCalendar("Asia/Shanghai")
+	svc.SetCalendar(shanghai)
+	svc.now = func() time.Time { return today.Add(17 * time.Hour) }
+	if resp, err := svc.Heatmap(ctx, EfficiencyHeatmapQuery{Days: 5}); err != nil || !resp.WindowEnd.Equal(today.AddDate(0, 0, 1)) {
+		t.Errorf("Shanghai window = %+v, %v, want it to end on 5/11", resp, err)
+	}
+	for _, q := range []EfficiencyHeatmapQuery{{Days: 400}, {Days: 5, BucketDays: 6}, {Limit: -1}} {
+		if _, err := svc.Heatmap(ctx, q); !errors.Is(err, ErrInvalidHeatmapQuery) {
+			t.Errorf("Heatmap(%+v): err = %v, want ErrInvalidHeatmapQuery", q, err)
+		}
+	}
+}
+
+func TestOffHoursService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	monday := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewOffHoursService(repo, costmodel.DefaultBusinessHours(), 0)
+	svc.now = func() time.Time { return monday.AddDate(0, 0, 7) }
+	hours := costmodel.DefaultBusinessHours()
+	for h := 0; h < 7*24; h++ {
+		ts := monday.Add(time.Duration(h) * time.Hour)
+		business := hours.Contains(ts)
+		// batch 白天忙、夜间空闲；preview 全天空闲；api 全天忙
+		batch := 0.0
+		if business {
+			batch = 1.5
+		}
+		for _, st := range []postgres.HourlyWorkloadStat{
+			{Namespace: "offhours", WorkloadName: "batch", PodName: "batch-0", CPURequest: 2, CPUUsageP95: batch, TotalBillableCost: 2},
+			{Namespace: "offhours", WorkloadName: "preview", PodName: "preview-0", CPURequest: 1, CPUUsageP95: 0.01, TotalBillableCost: 0.5},
+			{Namespace: "offhours", WorkloadName: "api", PodName: "api-0", CPURequest: 1, CPUUsageP95: 0.7, TotalBillableCost: 3},
+		} {
+			st.Timestamp = ts
+			_ = repo.SaveHourlyWorkloadStat(ctx, st)
+		}
+	}
+
+	resp, err := svc.Analyze(ctx, OffHoursQuery{Namespace: "offhours"})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if resp.Total != 3 || resp.Workloads[0].WorkloadName != "batch" || resp.Workloads[1].WorkloadName != "preview" || resp.Workloads[2].WorkloadName != "api" {
+		t.Fatalf("workloads = %+v", resp.Workloads)
+	}
+	batch := resp.Workloads[0]
+	if batch.Profile.BusinessHours != 45 || batch.Profile.IdleBusinessHours != 0 || batch.Profile.IdleOffHoursCost != 246 {
+		t.Errorf("batch profile = %+v", batch.Profile)
+	}
+	if batch.Recommendation == nil || batch.Recommendation.Action != costmodel.ActionScheduledScaling {
+		t.Errorf("batch recommendation = %+v", batch.Recommendation)
+	}
+	if r := resp.Workloads[1].Recommendation; r == nil || r.Action != costmodel.ActionScaleToZero {
+		t.Errorf("preview recommendation = %+v", r)
+	}
+	if resp.Workloads[2].Recommendation != nil {
+		t.Errorf("api recommendation = %+v", resp.Workloads[2].Recommendation)
+	}
+	if s := resp.Summary; s.Candidates != 2 || s.IdleOffHoursCost != 307.5 || s.BusinessCost != 247.5 || s.EstimatedMonthlySavings != 1433.93 {
+		t.Errorf("summary = %+v", s)
+	}
+
+	if resp, err := svc.Analyze(ctx, OffHoursQuery{Namespace: "offhours", Limit: 1}); err != nil || resp.Total != 1 || resp.Summary.Candidates != 2 {
+		t.Errorf("limit 1 = %+v, %v", resp, err)
+	}
+	for _, q := range []OffHoursQuery{{Days: 91}, {Limit: -1}} {
+		if _, err := svc.Analyze(ctx, q); !errors.Is(err, ErrInvalidOffHoursQuery) {
+			t.Errorf("Analyze(%+v): err = %v, want ErrInvalidOffHoursQuery", q, err)
+		}
+	}
+}
+
+// fakeSharedUsage measures every shared resource the same way and counts the queries.
+type fakeSharedUsage struct {
+	units    float64
+	measured map[string]float64
+	calls    int
+}
+
+func (f *fakeSharedUsage) GetSharedResourceUsage(ctx context.Context, resource, key string, startTime, endTime time.Time) (float64, map[string]float64, error) {
+	f.calls++
+	return f.units, f.measured, nil
+}
+
+func TestAllocationPreviewService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	day := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "a", Date: day.AddDate(0, 0, i), BillableCost: 30, WorkloadCount: 1})
+		_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "b", Date: day.AddDate(0, 0, i), BillableCost: 10, WorkloadCount: 1})
+	}
+	lb := costmodel.SharedResource{Name: "lb", Billing: costmodel.SharedBillingFixed, MonthlyCost: 300, AllocationKey: costmodel.AllocateEvenly}
+	usage := &fakeSharedUsage{units: 10, measured: map[string]float64{"c": 1}}
+	svc := NewAllocationPreviewService(repo, []costmodel.SharedResource{lb}, usage)
+	svc.now = func() time.Time { return day.AddDate(0, 0, 10) }
+
+	candidate := lb
+	candidate.AllocationKey = costmodel.AllocateByBillable
+	nfs := costmodel.SharedResource{Name: "nfs", Billing: costmodel.SharedBillingMetered, UnitPrice: 1, AllocationKey: costmodel.AllocateByTraffic}
+	resp, err := svc.Preview(ctx, dto.AllocationPreviewRequest{
+		Rules:     []costmodel.SharedResource{candidate, nfs},
+		StartDate: day,
+		EndDate:   day.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("Preview: %v", err)
+	}
+	if resp.Days != 2 || resp.CurrentSharedCost != 20 || resp.CandidateSharedCost != 40 || len(resp.Resources) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if usage.calls != 2 {
+		t.Errorf("usage queried %d times, want once per metered resource and day", usage.calls)
+	}
+	want := []struct {
+		ns              string
+		current, delta  float64
+		hasDeltaPercent bool
+	}{{"c", 0, 20, false}, {"a", 10, 5, true}, {"b", 10, -5, true}}
+	if len(resp.Namespaces) != len(want) {
+		t.Fatalf("namespaces = %+v", resp.Namespaces)
+	}
+	for i, w := range want {
+		n := resp.Namespaces[i]
+		if n.Namespace != w.ns || n.CurrentSharedCost != w.current || n.Delta != w.delta || (n.DeltaPercent != nil) != w.hasDeltaPercent {
+			t.Errorf("namespace %d = %+v, want %+v", i, n, w)
+		}
+	}
+	if p := resp.Namespaces[1].DeltaPercent; p == nil || *p != 50 {
+		t.Errorf("a delta percent = %v, want 50", p)
+	}
+
+	for _, req := range []dto.AllocationPreviewRequest{
+		{Rules: []costmodel.SharedResource{{Name: "x", Billing: "free", AllocationKey: costmodel.AllocateEvenly}}},
+		{Rules: []costmodel.SharedResource{lb, lb}},
+		{StartDate: day.AddDate(0, 0, 1), EndDate: day},
+		{StartDate: day, EndDate: day.AddDate(0, 0, 10)},
+	} {
+		if _, err := svc.Preview(ctx, req); !errors.Is(err, ErrInvalidAllocationPreview) {
+			t.Errorf("Preview(%+v): err = %v, want ErrInvalidAllocationPreview", req, err)
+		}
+	}
+	noUsage := NewAllocationPreviewService(repo, nil, nil)
+	if _, err := noUsage.Preview(ctx, dto.AllocationPreviewRequest{Rules: []costmodel.SharedResource{nfs}}); !errors.Is(err, ErrInvalidAllocationPreview) {
+		t.Errorf("metered rule without usage source: err = %v", err)
+	}
+}
+
+func TestCatalogService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	now := time.Date(2020, 6, 8, 0, 0, 0, 0, time.UTC)
+	for h := 1; h <= 10; h++ {
+		ts := now.Add(-time.Duration(h) * time.Hour)
+		for _, st := range []postgres.HourlyWorkloadStat{
+			{Namespace: "app-prod", WorkloadName: "order-service", WorkloadType: "Deployment", PodName: "order-0", CPURequest: 1, CPUUsageP95: 0.8, CPUBillableCost: 1, TotalBillableCost: 1, TotalUsageCost: 0.8},
+			{Namespace: "app-prod", WorkloadName: "report-batch", WorkloadType: "CronJob", PodName: "batch-0", CPURequest: 4, CPUUsageP95: 0.1, CPUBillableCost: 2, TotalBillableCost: 2, TotalUsageCost: 0.05},
+			{Namespace: "sandbox", WorkloadName: "demo", WorkloadType: "Deployment", PodName: "demo-0", CPURequest: 1, CPUUsageP95: 0.5, CPUBillableCost: 0.1, TotalBillableCost: 0.1, TotalUsageCost: 0.05},
+		} {
+			st.Timestamp = ts
+			_ = repo.SaveHourlyWorkloadStat(ctx, st)
+		}
+	}
+	svc := NewCatalogService(repo, DefaultMockSLOStatus(), map[string][]string{"payments": {"app-prod"}})
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.Search(ctx, CatalogQuery{}); !errors.Is(err, ErrCatalogNotReady) {
+		t.Fatalf("search before refresh: err = %v", err)
+	}
+	if err := svc.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	all, err := svc.Search(ctx, CatalogQuery{})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if all.Total != 3 || all.Workloads[0].Name != "report-batch" || all.Workloads[0].MonthlyCost != 1460 {
+		t.Fatalf("workloads = %+v", all.Workloads)
+	}
+	batch := all.Workloads[0]
+	if batch.Team != "payments" || batch.Grade != string(costmodel.GradeZombie) || batch.LastRecommendation == nil || batch.LastRecommendation.Action != costmodel.ActionDecommission {
+		t.Errorf("report-batch = %+v", batch)
+	}
+	if order := all.Workloads[1]; order.SLOStatus != "healthy" || order.LastRecommendation != nil {
+		t.Errorf("order-service = %+v", order)
+	}
+	if f := all.Facets[CatalogFacetNamespace]; len(f) != 2 || f[0] != (dto.CatalogFacetCount{Value: "app-prod", Count: 2}) {
+		t.Errorf("namespace facet = %+v", f)
+	}
+
+	cases := []struct {
+		q    CatalogQuery
+		want []string
+	}{
+		{CatalogQuery{Text: "ord"}, []string{"order-service"}},
+		{CatalogQuery{Text: "prod decomm"}, []string{"report-batch"}},
+		{CatalogQuery{Text: "app-prod"}, []string{"report-batch", "order-service"}},
+		{CatalogQuery{Type: "deployment"}, []string{"order-service", "demo"}},
+		{CatalogQuery{Team: "payments", SLOStatus: "healthy"}, []string{"order-service"}},
+		{CatalogQuery{Text: "nothing"}, nil},
+		{CatalogQuery{Limit: 1, Offset: 1}, []string{"order-service"}},
+	}
+	for _, c := range cases {
+		resp, err := svc.Search(ctx, c.q)
+		if err != nil {
+			t.Fatalf("Search(%+v): %v", c.q, err)
+		}
+		var got []string
+		for _, w := range resp.Workloads {
+			got = append(got, w.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(c.want, ",") {
+			t.Errorf("Search(%+v) = %v, want %v", c.q, got, c.want)
+		}
+	}
+	if _, err := svc.Search(ctx, CatalogQuery{Limit: 501}); !errors.Is(err, ErrInvalidCatalogQuery) {
+		t.Errorf("limit 501: err = %v", err)
+	}
+}
+
+func TestDatasetExportService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "payments", Date: day, BillableCost: 100, WasteCost: 40, WorkloadCount: 1})
+	for h := 0; h < 2; h++ {
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "payments", WorkloadName: "ledger", WorkloadType: "Deployment",
+			NodeName: "ip-10-0-0-1", PodName: "ledger-7d9f-x1", Timestamp: day.Add(time.Duration(h) * time.Hour), CPURequest: 2, TotalBillableCost: 4, TotalWasteCost: 1})
+	}
+	svc := NewDatasetExportService(repo, []byte("key"))
+	svc.now = func() time.Time { return day.Add(24 * time.Hour) }
+
+	plain, err := svc.Export(ctx, DatasetQuery{})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if plain.Anonymized || len(plain.DailyNamespaceCosts) != 1 || len(plain.HourlyWorkloadStats) != 2 || plain.HourlyWorkloadStats[0].Namespace != "payments" {
+		t.Fatalf("plain = %+v", plain)
+	}
+
+	anon, err := svc.Export(ctx, DatasetQuery{Anonymize: true, Scale: 0.5})
+	if err != nil {
+		t.Fatalf("Export anonymized: %v", err)
+	}
+	raw, _ := json.Marshal(anon)
+	for _, name := range []string{"payments", "ledger", "ip-10-0-0-1"} {
+		if strings.Contains(string(raw), name) {
+			t.Errorf("anonymized dataset contains %q", name)
+		}
+	}
+	d, h := anon.DailyNamespaceCosts[0], anon.HourlyWorkloadStats
+	if d.Namespace != h[0].Namespace || h[0].WorkloadName != h[1].WorkloadName || h[0].PodName != h[1].PodName {
+		t.Errorf("pseudonyms are not consistent: %+v / %+v", d, h)
+	}
+	if d.BillableCost != 50 || d.WasteCost != 20 || h[0].TotalBillableCost != 2 || h[0].CPURequest != 2 {
+		t.Errorf("costs should be scaled and quantities kept: %+v / %+v", d, h[0])
+	}
+	again, _ := svc.Export(ctx, DatasetQuery{Anonymize: true})
+	if again.DailyNamespaceCosts[0].Namespace != d.Namespace {
+		t.Error("pseudonyms should be stable across exports with a key")
+	}
+
+	for _, q := range []DatasetQuery{{Scale: -1}, {StartTime: day, EndTime: day}, {StartTime: day.AddDate(0, -2, 0), EndTime: day}} {
+		if _, err := svc.Export(ctx, q); !errors.Is(err, ErrInvalidDatasetExport) {
+			t.Errorf("Export(%+v) err = %v, want ErrInvalidDatasetExport", q, err)
+		}
+	}
+}
+
+func TestExportJobService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "payments", Date: day, BillableCost: 100.123456, WasteCost: 40, WorkloadCount: 1})
+	datasets := NewDatasetExportService(repo, nil)
+	datasets.now = func() time.Time { return day.Add(24 * time.Hour) }
+	svc := NewExportJobService(datasets, t.TempDir(), time.Hour)
+	now := day.Add(24 * time.Hour)
+	svc.now = func() time.Time { return now }
+
+	for _, req := range []dto.CreateExportJobRequest{{Kind: "bills", Format: "csv"}, {Kind: ExportKindNamespaceCosts, Format: "parquet"}, {Kind: ExportKindNamespaceCosts, Format: "csv", Scale: -1}} {
+		if _, err := svc.Create(ctx, req, ""); !errors.Is(err, dataerr.ErrValidation) {
+			t.Errorf("Create(%+v) err = %v, want a validation error", req, err)
+		}
+	}
+
+	job, err := svc.Create(ctx, dto.CreateExportJobRequest{Kind: ExportKindNamespaceCosts, Format: "CSV"}, "ci")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if job.Status != ExportJobQueued || job.Format != "csv" || job.CreatedBy != "ci" {
+		t.Fatalf("job = %+v", job)
+	}
+	if _, _, _, err := svc.Open(ctx, job.ID); !errors.Is(err, ErrExportNotReady) {
+		t.Errorf("Open queued job err = %v, want ErrExportNotReady", err)
+	}
+	svc.process(ctx, <-svc.queue)
+	got, _ := svc.Get(ctx, job.ID)
+	if got.Status != ExportJobSucceeded || got.Progress != 100 || got.TotalRows != 1 || got.RowsWritten != 1 || got.ExpiresAt == nil || !got.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("finished job = %+v", got)
+	}
+	f, _, contentType, err := svc.Open(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	raw, _ := io.ReadAll(f)
+	f.Close()
+	want := "date,namespace,billable_cost,usage_cost,waste_cost,shared_cost,pod_count,node_count,workload_count,efficiency_score\n2026-03-01,payments,100.1235,0,40,0,0,0,1,0\n"
+	if string(raw) != want || !strings.HasPrefix(contentType, "text/csv") || got.SizeBytes != int64(len(want)) {
+		t.Errorf("file = %q (%s, %d bytes)", raw, contentType, got.SizeBytes)
+	}
+
+	pdf, _ := svc.Create(ctx, dto.CreateExportJobRequest{Kind: ExportKindWorkloadStats, Format: "pdf"}, "")
+	svc.process(ctx, <-svc.queue)
+	if got, _ := svc.Get(ctx, pdf.ID); got.Status != ExportJobSucceeded || got.TotalRows != 0 || !strings.HasSuffix(got.FileName, ".pdf") {
+		t.Errorf("empty pdf job = %+v", got)
+	}
+	if list := svc.List(ctx); list.Total != 2 || strings.Join(list.Formats, ",") != "csv,pdf" {
+		t.Errorf("List = %+v", list)
+	}
+
+	now = now.Add(time.Hour)
+	svc.Cleanup()
+	if _, _, _, err := svc.Open(ctx, job.ID); !errors.Is(err, ErrExportExpired) {
+		t.Errorf("Open after retention err = %v, want ErrExportExpired", err)
+	}
+	if got, _ := svc.Get(ctx, job.ID); got.Status != ExportJobExpired || got.DownloadURL != "" {
+		t.Errorf("expired job = %+v", got)
+	}
+	now = now.Add(time.Hour)
+	svc.Cleanup()
+	if _, err := svc.Get(ctx, job.ID); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("expired job should be forgotten one retention later, err = %v", err)
+	}
+}
+
+func TestPDFFormat(t *testing.T) {
+	table := &ExportTable{Title: "Costs (test)", Columns: []string{"namespace", "cost"}}
+	for i := 0; i < 120; i++ {
+		table.Rows = append(table.Rows, []string{"ns-" + strings.Repeat("x", i%40), "1.5"})
+	}
+	var buf bytes.Buffer
+	rows := 0
+	if err := (PDFFormat{}).Write(&buf, table, func(n int) { rows = n }); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	if rows != 120 || !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") || !strings.Contains(out, `(Costs \(test\)    page 1/3) Tj`) {
+		t.Fatalf("rows = %d, pdf = %.200q", rows, out)
+	}
+	// every cross-reference entry points at its object
+	xref := out[strings.LastIndex(out, "\nxref\n")+1:]
+	for i, line := range strings.Split(xref, "\n")[3:10] {
+		off, _ := strconv.Atoi(line[:10])
+		if !strings.HasPrefix(out[off:], strconv.Itoa(i+1)+" 0 obj") {
+			t.Errorf("xref entry %d = %d does not point at the object", i+1, off)
+		}
+	}
+}
+
+func TestROIComparisonService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	d := func(m time.Month, day int) time.Time { return time.Date(2026, m, day, 0, 0, 0, 0, time.UTC) }
+	day := func(date time.Time, billable, waste float64, cpuUse float64, nodes ...string) {
+		_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "payments", Date: date, BillableCost: billable, WasteCost: waste})
+		for i, node := range nodes {
+			_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "payments", WorkloadName: fmt.Sprintf("w%d", i), NodeName: node,
+				Timestamp: date.Add(time.Hour), CPURequest: 10, CPUUsageP95: cpuUse, CPUBillableCost: 1})
+		}
+	}
+	// January: two days, 3 workloads on 3 nodes, all zombies (5% CPU)
+	day(d(1, 1), 1000, 400, 0.5, "n1", "n2", "n3")
+	day(d(1, 2), 800, 200, 0.5, "n1", "n2", "n3")
+	// March 1: 1 node, healthy workloads
+	day(d(3, 1), 600, 60, 5, "n1")
+	_ = repo.SaveROIBaseline(ctx, postgres.ROIBaseline{ID: "jan", Name: "january", BaselineType: "historical", TimePeriodStart: d(1, 1), TimePeriodEnd: d(1, 3)})
+	_ = repo.SaveROIBaseline(ctx, postgres.ROIBaseline{ID: "target", Name: "target", TimePeriodStart: d(6, 1), TimePeriodEnd: d(7, 1),
+		Metrics: map[string]float64{"total_billable_cost": 500, "node_count": 2, "efficiency_score": 0.8}})
+	svc := NewROIComparisonService(repo)
+	svc.now = func() time.Time { return d(3, 2).Add(12 * time.Hour) }
+
+	resp, err := svc.Compare(ctx, "jan", time.Time{})
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	b, c := resp.Baseline, resp.Comparison
+	if b.TotalBillableCost != 900 || b.TotalWasteAmount != 300 || b.NodeCount != 3 || b.ZombieAssetCount != 3 || b.CPUUtilization != 5 || len(resp.PinnedMetrics) != 0 {
+		t.Errorf("baseline = %+v", b)
+	}
+	if !c.Date.Equal(d(3, 1)) || c.CostSavingsAmount != 300 || c.WasteReductionAmount != 240 || c.ResourceRecoveryRate != 80 ||
+		c.NodeReductionCount != 2 || c.ZombieCleanupCount != 3 || c.CPUUtilizationImprovement != 45 || c.BaselineID != "jan" {
+		t.Errorf("comparison = %+v", c)
+	}
+
+	pinned, err := svc.Compare(ctx, "target", d(3, 1))
+	if err != nil {
+		t.Fatalf("Compare pinned: %v", err)
+	}
+	if strings.Join(pinned.PinnedMetrics, ",") != "node_count,total_billable_cost" || pinned.Comparison.CostSavingsAmount != -100 || pinned.Comparison.NodeReductionCount != 1 {
+		t.Errorf("pinned comparison = %+v / %+v", pinned.PinnedMetrics, pinned.Comparison)
+	}
+
+	if _, err := svc.Compare(ctx, "jan", d(3, 5)); !errors.Is(err, ErrInvalidROIComparison) {
+		t.Errorf("future date err = %v", err)
+	}
+	if _, err := svc.Compare(ctx, "jan", d(2, 1)); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("day without data err = %v", err)
+	}
+	if _, err := svc.Compare(ctx, "missing", d(3, 1)); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("missing baseline err = %v", err)
+	}
+}
+
+func TestRegradeService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	d := func(day int) time.Time { return time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC) }
+	results := func(scores ...float64) []costmodel.CostResult {
+		var rs []costmodel.CostResult
+		for _, score := range scores {
+			rs = append(rs, costmodel.CostResult{OverallEfficiencyScore: score, OverallGrade: costmodel.DefaultGradeThresholds.Grade(score)})
+		}
+		return rs
+	}
+	// graded with the default thresholds (10 / 40 / 90): 12 and 35 are OverProvisioned, 50 Healthy
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "s1", Timestamp: d(1), ResourceResults: results(5, 12, 35, 50),
+		ZombieCount: 1, OverProvisionedCount: 2, HealthyCount: 1})
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "s2", Timestamp: d(2), ResourceResults: results(60, 95),
+		HealthyCount: 1, RiskCount: 1})
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "s3", Timestamp: d(3), ZombieCount: 4})
+	before, _ := repo.GetCostSnapshot(ctx, "s1")
+
+	svc := NewRegradeService(repo, repo, costmodel.GradeThresholds{Zombie: 15, OverProvisioned: 40, Risk: 90})
+	dry, err := svc.Regrade(ctx, dto.RegradeRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("Regrade dry run: %v", err)
+	}
+	if dry.Regraded != 2 || dry.Changed != 1 || dry.Skipped != 1 || dry.Total != (dto.GradeCounts{Zombie: 2, OverProvisioned: 1, Healthy: 2, Risk: 1}) {
+		t.Errorf("dry run = %+v", dry)
+	}
+	if _, err := repo.GetSnapshotRegrade(ctx, "s1"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("dry run saved a regrade: %v", err)
+	}
+
+	resp, err := svc.Regrade(ctx, dto.RegradeRequest{RegradedBy: "ops"})
+	if err != nil {
+		t.Fatalf("Regrade: %v", err)
+	}
+	if resp.Regraded != 2 || resp.Changed != 1 || resp.Snapshots[0].SnapshotID != "s1" || !resp.Snapshots[0].Changed || resp.Snapshots[1].Changed {
+		t.Errorf("regrade = %+v", resp)
+	}
+	after, _ := repo.GetCostSnapshot(ctx, "s1")
+	if after.ZombieCount != 1 || after.ResourceResults[1].OverallGrade != costmodel.GradeOverProvisioned || after.ContentHash != before.ContentHash {
+		t.Errorf("original snapshot modified: %+v", after)
+	}
+
+	original, err := svc.SnapshotGrades(ctx, time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("SnapshotGrades: %v", err)
+	}
+	if original.View != GradeViewOriginal || original.Total.Zombie != 5 || original.Snapshots[0].Regraded {
+		t.Errorf("original view = %+v", original)
+	}
+	view, err := svc.SetView(ctx, dto.SetGradeViewRequest{View: GradeViewRegraded, SetBy: "ops"})
+	if err != nil || view.View != GradeViewRegraded {
+		t.Fatalf("SetView = %+v, %v", view, err)
+	}
+	regraded, err := svc.SnapshotGrades(ctx, time.Time{}, d(2), "")
+	if err != nil {
+		t.Fatalf("SnapshotGrades regraded: %v", err)
+	}
+	if regraded.View != GradeViewRegraded || len(regraded.Snapshots) != 2 || !regraded.Snapshots[0].Regraded ||
+		regraded.Snapshots[0].Counts != (dto.GradeCounts{Zombie: 2, OverProvisioned: 1, Healthy: 1}) || regraded.Snapshots[0].Thresholds.Zombie != 15 {
+		t.Errorf("regraded view = %+v", regraded)
+	}
+	if got, _ := svc.SnapshotGrades(ctx, d(3), time.Time{}, ""); len(got.Snapshots) != 1 || got.Snapshots[0].Regraded || got.Total.Zombie != 4 {
+		t.Errorf("snapshot without resource results = %+v", got)
+	}
+
+	if _, err := svc.Regrade(ctx, dto.RegradeRequest{Thresholds: &costmodel.GradeThresholds{Zombie: 50, OverProvisioned: 40, Risk: 90}}); !errors.Is(err, ErrInvalidRegrade) {
+		t.Errorf("invalid thresholds err = %v", err)
+	}
+	if _, err := svc.SetView(ctx, dto.SetGradeViewRequest{View: "latest"}); !errors.Is(err, ErrInvalidRegrade) {
+		t.Errorf("invalid view err = %v", err)
+	}
+	if err := svc.SetDefaultView("latest"); !errors.Is(err, ErrInvalidRegrade) {
+		t.Errorf("invalid default view err = %v", err)
+	}
+}
+
+func TestFiscalReportService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	d := func(m time.Month, day int) time.Time { return time.Date(2026, m, day, 0, 0, 0, 0, time.UTC) }
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "payments", Date: d(3, 15), BillableCost: 300})
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "payments", Date: d(4, 2), BillableCost: 100, SharedCost: 10})
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "search", Date: d(5, 9), BillableCost: 50, WasteCost: 20})
+	svc := NewFiscalReportService(repo, costmodel.FiscalCalendar{StartMonth: time.April})
+	svc.now = func() time.Time { return d(5, 10).Add(12 * time.Hour) }
+
+	resp, err := svc.Report(ctx, costmodel.FiscalUnitQuarter, time.Time{})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if resp.Label != "FY2027 Q1" || resp.PreviousLabel != "FY2026 Q4" || resp.Days != 91 || resp.DaysElapsed != 40 {
+		t.Errorf("span = %+v", resp)
+	}
+	if resp.BillableCost != 150 || resp.WasteCost != 20 || resp.SharedCost != 10 || resp.PreviousBillableCost != 300 || resp.Projected != 341.25 {
+		t.Errorf("costs = %+v", resp)
+	}
+	if len(resp.Namespaces) != 2 || resp.Namespaces[0].Namespace != "payments" || resp.Namespaces[0].PreviousBillableCost != 300 {
+		t.Errorf("namespaces = %+v", resp.Namespaces)
+	}
+
+	// 已结束的期间按全期计算
+	march, err := svc.Report(ctx, "", d(3, 1))
+	if err != nil {
+		t.Fatalf("Report March: %v", err)
+	}
+	if march.Label != "FY2026 P12" || march.DaysElapsed != 31 || march.BillableCost != 300 || march.Projected != 300 {
+		t.Errorf("March = %+v", march)
+	}
+
+	if _, err := svc.Report(ctx, "month", time.Time{}); !errors.Is(err, ErrInvalidFiscalReport) {
+		t.Errorf("unknown unit: err = %v", err)
+	}
+	if _, err := svc.Report(ctx, costmodel.FiscalUnitQuarter, d(8, 1)); !errors.Is(err, ErrInvalidFiscalReport) {
+		t.Errorf("future quarter: err = %v", err)
+	}
+}
+
+func TestRecommendationAdoptionService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, r := range []postgres.IssuedRecommendation{
+		{Team: "pay", Namespace: "payments", WorkloadName: "ledger", Action: "downsize", Resource: "cpu", EstimatedMonthlySavings: 100, Status: "partial", RealizedMonthlySavings: 40},
+		{Team: "pay", Namespace: "payments", WorkloadName: "idle", Action: "decommission", Resource: "workload", EstimatedMonthlySavings: 50, Status: "applied", RealizedMonthlySavings: 50},
+		{Team: "pay", Namespace: "payments", WorkloadName: "hot", Action: "upsize", Resource: "memory", EstimatedMonthlySavings: -30, Status: "open"},
+		{Team: "search", Namespace: "search", WorkloadName: "index", Action: "downsize", Resource: "memory", EstimatedMonthlySavings: 200, Status: "open"},
+		{Team: "search", Namespace: "search", WorkloadName: "old", Action: "decommission", Resource: "workload", EstimatedMonthlySavings: 10, Status: "open", IssuedAt: at.AddDate(-1, 0, 0)},
+	} {
+		if r.IssuedAt.IsZero() {
+			r.IssuedAt = at
+		}
+		_ = repo.SaveIssuedRecommendation(ctx, r)
+	}
+	svc := NewRecommendationAdoptionService(repo)
+	svc.now = func() time.Time { return at.AddDate(0, 0, 10) }
+
+	resp, err := svc.Adoption(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("Adoption: %v", err)
+	}
+	if resp.Total.Issued != 4 || resp.Total.Applied != 1 || resp.Total.AdoptionRate != 25 || resp.Total.UnrealizedMonthlySavings != 260 {
+		t.Errorf("total = %+v", resp.Total)
+	}
+	if len(resp.Teams) != 2 || resp.Teams[0].Team != "search" || resp.Teams[1].AdoptionRate != 33.33 || resp.Teams[1].RealizedMonthlySavings != 90 {
+		t.Errorf("teams = %+v", resp.Teams)
+	}
+	if len(resp.TopUnrealized) != 2 || resp.TopUnrealized[0].Workload != "index" || resp.TopUnrealized[1].UnrealizedMonthlySavings != 60 {
+		t.Errorf("top unrealized = %+v", resp.TopUnrealized)
+	}
+}
+
+// gatedRepo blocks AggregateDailyNamespaceCosts until release is closed and counts the calls.
+type gatedRepo struct {
+	*postgres.MockRepository
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (r *gatedRepo) AggregateDailyNamespaceCosts(ctx context.Context, start, end time.Time) ([]postgres.DailyNamespaceCost, error) {
+	r.calls.Add(1)
+	<-r.release
+	return r.MockRepository.AggregateDailyNamespaceCosts(ctx, start, end)
+}
+
+func TestCostService_CoalescesIdenticalQueries(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.LatencyMs = 0
+	repo := &gatedRepo{MockRepository: postgres.NewMockRepository(mockCfg), release: make(chan struct{})}
+	svc := NewCostService(repo)
+
+	const callers = 8
+	results := make(chan *dto.GlobalCostResponse, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := svc.GetGlobalCost(context.Background())
+			if err != nil {
+				t.Errorf("GetGlobalCost: %v", err)
+			}
+			results <- resp
+		}()
+	}
+	// 被取消的调用方不再等待，也不会取消其他调用方共享的计算
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancelDone := make(chan error, 1)
+	go func() {
+		_, err := svc.GetGlobalCost(cancelled)
+		cancelDone <- err
+	}()
+	for svc.Coalescer().Stats()["global_cost"].Calls < callers+1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-cancelDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller error = %v, want context.Canceled", err)
+	}
+	close(repo.release)
+	wg.Wait()
+	close(results)
+
+	var first *dto.GlobalCostResponse
+	for resp := range results {
+		if first == nil {
+			first = resp
+		}
+		if resp != first {
+			t.Fatal("concurrent identical queries got different results, want one shared result")
+		}
+	}
+	if n := repo.calls.Load(); n != 1 {
+		t.Errorf("aggregation ran %d times for %d concurrent identical queries, want 1", n, callers+1)
+	}
+	if st := svc.Coalescer().Stats()["global_cost"]; st.Calls != callers+1 || st.Executions != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+
+	// 查询结束后再次调用重新计算；不同的参数不合并
+	if _, err := svc.GetGlobalCost(context.Background()); err != nil || repo.calls.Load() != 2 {
+		t.Errorf("sequential query: calls = %d, err = %v; want a new computation", repo.calls.Load(), err)
+	}
+	if pageKey(costmodel.PageOptions{})[0] != pageKey(costmodel.PageOptions{Sort: costmodel.SortByCost})[0] || timeKey(time.Time{}) != "" {
+		t.Error("default page options must share the key of the spelled-out defaults")
+	}
+}
+
+func TestCoalesceKeepsDeadline(t *testing.T) {
+	c := NewQueryCoalescer()
+	deadlineOf := func(ctx context.Context) (time.Time, error) {
+		d, ok := ctx.Deadline()
+		if !ok {
+			return time.Time{}, errors.New("no deadline")
+		}
+		return d, nil
+	}
+
+	// 发起计算的调用方的时限保留下来，取消则不传递
+	want := time.Now().Add(time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), want)
+	defer cancel()
+	if got, err := coalesce(ctx, c, "deadline", nil, deadlineOf); err != nil || !got.Equal(want) {
+		t.Errorf("deadline = %v, %v; want the caller's %v", got, err, want)
+	}
+
+	// 没有时限的调用方受 coalescer 的上限约束
+	c.SetTimeout(10 * time.Millisecond)
+	_, err := coalesce(context.Background(), c, "capped", nil, func(ctx context.Context) (struct{}, error) {
+		<-ctx.Done()
+		return struct{}{}, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("uncapped computation error = %v, want context.DeadlineExceeded", err)
+	}
+	c.SetTimeout(0)
+	if _, err := coalesce(context.Background(), c, "unbounded", nil, deadlineOf); err == nil {
+		t.Error("SetTimeout(0) must keep only the caller's (absent) deadline")
+	}
+}
+
+func TestRecommendationExportService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, r := range []postgres.IssuedRecommendation{
+		{ID: "rec-ledger", Team: "pay", Namespace: "payments", WorkloadName: "ledger", Action: "downsize", Resource: "cpu", BaselineRequest: 4, RecommendedRequest: 1, EstimatedMonthlySavings: 100, Status: "open"},
+		{ID: "rec-idle", Team: "pay", Namespace: "payments", WorkloadName: "idle", Action: "decommission", Resource: "workload", BaselineRequest: 1, EstimatedMonthlySavings: 50, Status: "partial"},
+		{ID: "rec-done", Team: "pay", Namespace: "payments", WorkloadName: "done", Action: "downsize", Resource: "memory", Status: "applied"},
+	} {
+		_ = repo.SaveIssuedRecommendation(ctx, r)
+	}
+	svc := NewRecommendationExportService(repo, repo)
+	svc.now = func() time.Time { return now }
+	var pods staticPods
+	for _, phase := range []string{"Running", "Running", "Pending"} {
+		pods = append(pods, k8s.Pod{Phase: phase, Containers: []k8s.Container{
+			{Name: "proxy", Resources: k8s.ContainerResources{Requests: map[string]string{"cpu": "100m"}}},
+			{Name: "app", Resources: k8s.ContainerResources{Requests: map[string]string{"cpu": "1900m"}}},
+		}})
+	}
+
+	for _, req := range []dto.ExportRecommendationsRequest{
+		{RecommendationIDs: []string{"rec-ledger", "rec-done"}},
+		{RecommendationIDs: []string{"rec-unknown"}},
+		{RecommendationIDs: []string{"rec-ledger"}, Format: "helm"},
+		{RecommendationIDs: []string{"rec-ledger"}, WorkloadKind: "DaemonSet"},
+	} {
+		if _, err := svc.Export(ctx, req, "ci"); !errors.Is(err, ErrInvalidRecommendationExport) {
+			t.Errorf("Export(%+v) error = %v, want ErrInvalidRecommendationExport", req, err)
+		}
+	}
+
+	// 未设置 Pod 来源：按一个 Pod 修改第一个容器
+	single, err := svc.Export(ctx, dto.ExportRecommendationsRequest{RecommendationIDs: []string{"rec-ledger"}, Format: ManifestFormatKubectl, WorkloadKind: "StatefulSet"}, "ci")
+	if err != nil {
+		t.Fatalf("Export kubectl: %v", err)
+	}
+	zr, _ := zip.NewReader(bytes.NewReader(single.Data), int64(len(single.Data)))
+	if len(zr.File) != 2 || zr.File[1].Name != "apply.sh" || zr.File[1].Mode()&0o100 == 0 {
+		t.Errorf("kubectl bundle files = %v", zr.File)
+	}
+	if rc, err := zr.File[0].Open(); err == nil {
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		if !strings.Contains(string(b), "containers/0/resources/requests/cpu\n  value: \"1\"") {
+			t.Errorf("patch without pods = %s", b)
+		}
+	}
+
+	svc.SetPodLister(pods)
+	bundle, err := svc.Export(ctx, dto.ExportRecommendationsRequest{RecommendationIDs: []string{"rec-ledger", "rec-idle", "rec-ledger"}}, "ci")
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	zr, err = zip.NewReader(bytes.NewReader(bundle.Data), int64(len(bundle.Data)))
+	if err != nil {
+		t.Fatalf("bundle is not a zip archive: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	if len(files) != 3 || !strings.Contains(files["kustomization.yaml"], "path: patches/payments-idle-workload.yaml") {
+		t.Fatalf("bundle files = %v", files)
+	}
+	// 1 核的目标分摊到 2 个运行中的 Pod，修改请求量最大的容器（app）
+	if p := files["patches/payments-ledger-cpu.yaml"]; !strings.Contains(p, "path: /spec/template/spec/containers/1/resources/requests/cpu\n  value: \"500m\"") {
+		t.Errorf("cpu patch = %s", p)
+	}
+	if p := files["patches/payments-idle-workload.yaml"]; !strings.Contains(p, "path: /spec/replicas\n  value: 0") {
+		t.Errorf("decommission patch = %s", p)
+	}
+	if len(bundle.Records) != 2 || bundle.Records[0].OptimizationType != OptimizationTypeRightsizing || bundle.Records[0].ProjectedSavings != 1200 ||
+		bundle.Records[0].ResourcesRecovered["cpu"] != 3 || bundle.Records[1].OptimizationType != OptimizationTypeZombieCleanup || bundle.Records[1].ImplementedBy != "ci" {
+		t.Errorf("records = %+v", bundle.Records)
+	}
+
+	pending, err := svc.ListRecords(ctx, bundle.ID, OptimizationStatusPending)
+	if err != nil || len(pending.Items) != 2 || pending.Items[0].Verified {
+		t.Fatalf("pending records = %+v, %v", pending, err)
+	}
+	if verified, _ := svc.ListRecords(ctx, "", OptimizationStatusVerified); len(verified.Items) != 0 {
+		t.Errorf("verified records = %+v, want none", verified.Items)
+	}
+	if _, err := svc.ListRecords(ctx, "", "done"); err == nil {
+		t.Error("ListRecords(status=done): want a validation error")
+	}
+}
+
+func TestServiceAccountService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc := NewServiceAccountService(repo)
+	svc.now = func() time.Time { return now }
+
+	account, err := svc.CreateAccount(ctx, dto.CreateServiceAccountRequest{Name: "ci", CreatedBy: "alice"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if _, err := svc.CreateAccount(ctx, dto.CreateServiceAccountRequest{Name: "ci"}); !errors.Is(err, dataerr.ErrConflict) {
+		t.Errorf("duplicate name: err = %v", err)
+	}
+	for _, scopes := range [][]string{nil, {"cost"}, {"cost:delete"}} {
+		if _, err := svc.CreateKey(ctx, account.ID, dto.CreateAPIKeyRequest{Scopes: scopes}); !errors.Is(err, ErrInvalidServiceAccount) {
+			t.Errorf("scopes %v: err = %v", scopes, err)
+		}
+	}
+	created, err := svc.CreateKey(ctx, account.ID, dto.CreateAPIKeyRequest{Name: "deploy", Scopes: []string{"cost:read", "roi:*"}, DailyQuota: 2})
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if !strings.HasPrefix(created.Secret, APIKeySecretPrefix) || !strings.HasPrefix(created.Secret, created.Key.Prefix) {
+		t.Errorf("created = %+v", created)
+	}
+
+	p, err := svc.Authenticate(ctx, created.Secret, "cost:read")
+	if err != nil || p.AccountName != "ci" || p.UsedToday != 1 {
+		t.Fatalf("Authenticate = %+v, %v", p, err)
+	}
+	if _, err := svc.Authenticate(ctx, created.Secret, "cost:write"); !errors.Is(err, ErrScopeDenied) {
+		t.Errorf("cost:write: err = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, created.Secret, "roi:read"); err != nil {
+		t.Errorf("roi:read: err = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, created.Secret, "cost:read"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("third request: err = %v, want quota exceeded", err)
+	}
+	usage, err := svc.Usage(ctx, account.ID, 0)
+	if err != nil || usage.TotalRequests != 2 || usage.TotalRejected != 1 || len(usage.Usage) != 1 || usage.Usage[0].KeyName != "deploy" {
+		t.Errorf("Usage = %+v, %v", usage, err)
+	}
+	got, _ := svc.GetAccount(ctx, account.ID)
+	if len(got.Keys) != 1 || got.Keys[0].RequestsToday != 2 {
+		t.Errorf("account = %+v", got)
+	}
+
+	// 次日配额重置；停用账号与吊销 key 后认证失败
+	now = now.Add(24 * time.Hour)
+	if _, err := svc.Authenticate(ctx, created.Secret, "cost:read"); err != nil {
+		t.Errorf("next day: err = %v", err)
+	}
+	if _, err := svc.UpdateAccount(ctx, account.ID, dto.UpdateServiceAccountRequest{Disabled: true}); err != nil {
+		t.Fatalf("UpdateAccount: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, created.Secret, "cost:read"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("disabled account: err = %v", err)
+	}
+	_, _ = svc.UpdateAccount(ctx, account.ID, dto.UpdateServiceAccountRequest{})
+	if _, err := svc.RevokeKey(ctx, account.ID, created.Key.ID); err != nil {
+		t.Fatalf("RevokeKey: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, created.Secret, "cost:read"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("revoked key: err = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, "lh_unknown", "cost:read"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("unknown key: err = %v", err)
+	}
+
+	svc.SetBootstrapKey("bootstrap-key-0123456789abcdef")
+	if p, err := svc.Authenticate(ctx, "bootstrap-key-0123456789abcdef", "admin:write"); err != nil || p.AccountName != "bootstrap" {
+		t.Errorf("bootstrap = %+v, %v", p, err)
+	}
+
+	if !ScopeAllows([]string{"*:read"}, "pricing:read") || ScopeAllows([]string{"*:read"}, "pricing:write") || !ScopeAllows([]string{"*"}, "pricing:write") {
+		t.Error("ScopeAllows wildcards")
+	}
+	if ScopeAllows([]string{"*"}, "admin:write") || ScopeAllows([]string{"*:write"}, "admin:read") || !ScopeAllows([]string{"admin:*"}, "admin:write") {
+		t.Error("admin scope must be explicit")
+	}
+}
+
+func TestDependencyService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	now := time.Date(2020, 8, 3, 0, 0, 0, 0, time.UTC)
+	// $1/h for 10 hours of frontend and checkout, $2/h for 10 hours of payments: a month of 730 hours each
+	for h := 1; h <= 10; h++ {
+		for w, cost := range map[string]float64{"frontend": 1, "checkout": 1, "payments": 2} {
+			_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "shop", WorkloadName: w, Timestamp: now.Add(-time.Duration(h) * time.Hour), TotalBillableCost: cost})
+		}
+	}
+	svc := NewDependencyService(repo, repo, time.Hour)
+	svc.now = func() time.Time { return now }
+
+	// frontend -> checkout -> payments -> ledger (static), mobile-bff -> checkout (tracing), payments -> checkout (cycle)
+	if _, err := svc.Ingest(ctx, dto.DependencyIngestRequest{Source: postgres.DependencySourceStatic, ObservedAt: now.Add(-48 * time.Hour), Edges: []dto.DependencyEdgeInput{
+		{CallerNamespace: "shop", Caller: "frontend", Callee: "checkout"},
+		{CallerNamespace: "shop", Caller: "checkout", Callee: "payments"},
+		{CallerNamespace: "shop", Caller: "payments", CalleeNamespace: "finance", Callee: "ledger"},
+	}}); err != nil {
+		t.Fatalf("Ingest static: %v", err)
+	}
+	if _, err := svc.Ingest(ctx, dto.DependencyIngestRequest{Source: postgres.DependencySourceTracing, Edges: []dto.DependencyEdgeInput{
+		{CallerNamespace: "edge", Caller: "mobile-bff", CalleeNamespace: "shop", Callee: "checkout", CallsPerMinute: 120},
+		{CallerNamespace: "shop", Caller: "payments", Callee: "checkout", CallsPerMinute: 3},
+	}}); err != nil {
+		t.Fatalf("Ingest tracing: %v", err)
+	}
+
+	impact, err := svc.Impact(ctx, "shop", "payments", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Impact: %v", err)
+	}
+	var got []string
+	for _, u := range impact.Upstream {
+		got = append(got, fmt.Sprintf("%s/%s@%d via %s $%.0f", u.Namespace, u.Service, u.Depth, u.Via, u.MonthlyCost))
+	}
+	want := []string{"shop/checkout@1 via shop/payments $730", "shop/frontend@2 via shop/checkout $730", "edge/mobile-bff@2 via shop/checkout $0"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("upstream = %v, want %v", got, want)
+	}
+	if impact.MonthlyCost != 1460 || impact.UpstreamMonthlyCost != 1460 {
+		t.Errorf("monthly cost = %v, upstream %v, want 1460 and 1460", impact.MonthlyCost, impact.UpstreamMonthlyCost)
+	}
+	if strings.Join(impact.Downstream, ",") != "finance/ledger,shop/checkout" || strings.Join(impact.Unmatched, ",") != "edge/mobile-bff" {
+		t.Errorf("downstream = %v, unmatched = %v", impact.Downstream, impact.Unmatched)
+	}
+
+	// tracing edges expire after the TTL; a new static map replaces the old one
+	svc.now = func() time.Time { return now.Add(2 * time.Hour) }
+	resp, err := svc.Ingest(ctx, dto.DependencyIngestRequest{Source: postgres.DependencySourceStatic, Edges: []dto.DependencyEdgeInput{
+		{CallerNamespace: "shop", Caller: "frontend", Callee: "payments"},
+	}})
+	if err != nil || resp.Accepted != 1 || resp.Removed != 3 {
+		t.Fatalf("Ingest static again = %+v, %v; want 1 accepted and 3 removed", resp, err)
+	}
+	edges, err := svc.Edges(ctx)
+	if err != nil || len(edges) != 1 || edges[0].Caller != "frontend" || edges[0].Callee != "payments" {
+		t.Errorf("Edges = %+v, %v; want only the new static edge", edges, err)
+	}
+
+	for _, req := range []dto.DependencyIngestRequest{
+		{Source: "guess"},
+		{Source: postgres.DependencySourceTracing, Edges: []dto.DependencyEdgeInput{{Caller: "a", Callee: "b"}}},
+		{Source: postgres.DependencySourceTracing, Edges: []dto.DependencyEdgeInput{{CallerNamespace: "shop", Caller: "a", Callee: "a"}}},
+	} {
+		if _, err := svc.Ingest(ctx, req); !errors.Is(err, ErrInvalidDependencies) {
+			t.Errorf("Ingest(%+v) = %v, want ErrInvalidDependencies", req, err)
+		}
+	}
+
+	// the record of a violation start carries the impact
+	timeline := NewTimelineService(repo)
+	timeline.SetDependencies(svc)
+	violation, err := timeline.RecordSLOViolation(ctx, dto.SLOViolationRequest{Namespace: "shop", Service: "payments", ViolationType: "availability", ViolatedAt: now})
+	if err != nil {
+		t.Fatalf("RecordSLOViolation: %v", err)
+	}
+	if violation.Impact == nil || len(violation.Impact.Upstream) != 1 || violation.Impact.UpstreamMonthlyCost != 730 {
+		t.Errorf("violation impact = %+v, want frontend at $730", violation.Impact)
+	}
+}
+
+func TestPeriodCloseService(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	hour := time.Date(2020, 7, 10, 9, 0, 0, 0, time.UTC)
+	day := hour.Truncate(24 * time.Hour)
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "shop", WorkloadName: "api", Timestamp: hour, CPURequest: 2, CPUBillableCost: 0.06, TotalBillableCost: 0.06})
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "shop", Date: day, BillableCost: 0.06})
+
+	svc := NewPeriodCloseService(repo, costmodel.FiscalCalendar{})
+	svc.now = func() time.Time { return time.Date(2020, 8, 5, 12, 0, 0, 0, time.UTC) }
+	if _, err := svc.Close(ctx, time.Date(2020, 8, 3, 0, 0, 0, 0, time.UTC), "finance", ""); !errors.Is(err, ErrInvalidPeriodClose) {
+		t.Errorf("closing the current period: want ErrInvalidPeriodClose, got %v", err)
+	}
+	period, err := svc.Close(ctx, time.Time{}, "finance", "July close")
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if period.Label != "FY2020 P07" || !period.StartDate.Equal(time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)) || period.ClosedBy != "finance" {
+		t.Fatalf("closed period = %+v, want July 2020", period)
+	}
+	if _, err := svc.Close(ctx, day, "finance", ""); !errors.Is(err, postgres.ErrPeriodClosed) {
+		t.Errorf("closing twice: want ErrPeriodClosed, got %v", err)
+	}
+	if err := repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "shop", Date: day, BillableCost: 1}); !errors.Is(err, postgres.ErrPeriodClosed) {
+		t.Errorf("write to the closed period: want ErrPeriodClosed, got %v", err)
+	}
+
+	// 追溯重算不改动已关账的小时统计，差额记为更正
+	_ = repo.SavePriceVersion(ctx, postgres.PriceVersion{EffectiveFrom: day.AddDate(0, -1, 0), CPUPricePerCoreHour: 0.04, MemPricePerGBHour: 0.004})
+	recalculate := NewRecalculationPipeline(repo, repo, costmodel.UnitPrice{}, costmodel.NonFiniteZero)
+	if _, err := recalculate(ctx, postgres.CalculationRun{ID: "run-1", WindowStart: day, WindowEnd: day.AddDate(0, 0, 1)}); err != nil {
+		t.Fatalf("recalculation: %v", err)
+	}
+	if st, _ := repo.GetHourlyWorkloadStat(ctx, "shop", "api", hour); st.TotalBillableCost != 0.06 {
+		t.Errorf("closed hour re-priced to %v", st.TotalBillableCost)
+	}
+	adjustments, err := svc.Adjustments(ctx, "FY2020 P07", "shop")
+	if err != nil {
+		t.Fatalf("Adjustments: %v", err)
+	}
+	if len(adjustments) != 1 || math.Abs(adjustments[0].Amount-0.02) > 1e-9 || adjustments[0].Reason != "recalculation run-1" || adjustments[0].Workload != "api" {
+		t.Fatalf("adjustments = %+v, want one of 0.02 for shop/api", adjustments)
+	}
+	periods, err := svc.Periods(ctx)
+	if err != nil {
+		t.Fatalf("Periods: %v", err)
+	}
+	if len(periods) != 1 || periods[0].AdjustmentCount != 1 || periods[0].Note != "July close" {
+		t.Errorf("periods = %+v", periods)
+	}
+
+	// 手工更正：须有原因，只能作用于已关账期间，由另一人批准后生效
+	if _, err := svc.Adjust(ctx, dto.CreateAdjustmentRequest{Date: "2020-07-12", Namespace: "shop", Amount: 1}, "ops"); !errors.Is(err, ErrInvalidAdjustment) {
+		t.Errorf("adjustment without reason: want ErrInvalidAdjustment, got %v", err)
+	}
+	if _, err := svc.Adjust(ctx, dto.CreateAdjustmentRequest{Date: "2020-08-02", Namespace: "shop", Amount: 1, Reason: "x"}, "ops"); !errors.Is(err, dataerr.ErrValidation) {
+		t.Errorf("adjustment of an open period: want validation error, got %v", err)
+	}
+	manual, err := svc.Adjust(ctx, dto.CreateAdjustmentRequest{Date: "2020-07-12", Namespace: "shop", Amount: -1.25, Reason: "duplicate invoice"}, "ops")
+	if err != nil {
+		t.Fatalf("Adjust: %v", err)
+	}
+	if manual.Period != "FY2020 P07" || manual.Source != postgres.AdjustmentSourceManual || manual.CreatedBy != "ops" || manual.Status != postgres.AdjustmentPending || manual.Approver != "" {
+		t.Errorf("manual adjustment = %+v", manual)
+	}
+
+	// 待批准的更正不计入报表
+	fiscal := NewFiscalReportService(repo, costmodel.FiscalCalendar{})
+	fiscal.now = svc.now
+	fiscal.SetAdjustments(repo)
+	report, err := fiscal.Report(ctx, costmodel.FiscalUnitPeriod, day)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if report.Adjustments != 0 || len(report.AdjustmentItems) != 0 {
+		t.Errorf("pending adjustment reported: %v %+v", report.Adjustments, report.AdjustmentItems)
+	}
+
+	if _, err := svc.Approve(ctx, manual.ID, "ops"); !errors.Is(err, ErrSelfApprovedAdjustment) {
+		t.Errorf("self-approval: want ErrSelfApprovedAdjustment, got %v", err)
+	}
+	if _, err := svc.Approve(ctx, manual.ID, ""); !errors.Is(err, ErrInvalidAdjustment) {
+		t.Errorf("approval without approver: want ErrInvalidAdjustment, got %v", err)
+	}
+	if _, err := svc.Approve(ctx, "missing", "finance"); !errors.Is(err, dataerr.ErrNotFound) {
+		t.Errorf("approving a missing adjustment: want not found, got %v", err)
+	}
+	approved, err := svc.Approve(ctx, manual.ID, "finance")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if approved.Status != postgres.AdjustmentApproved || approved.Approver != "finance" || approved.ApprovedAt == nil {
+		t.Errorf("approved adjustment = %+v", approved)
+	}
+	if _, err := svc.Approve(ctx, manual.ID, "cfo"); !errors.Is(err, dataerr.ErrConflict) {
+		t.Errorf("approving twice: want conflict, got %v", err)
+	}
+
+	// 财务报表单列已批准的更正并计入调整后合计
+	report, err = fiscal.Report(ctx, costmodel.FiscalUnitPeriod, day)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if report.BillableCost != 0.06 || report.Adjustments != -1.25 || report.AdjustedTotal != -1.19 || len(report.AdjustmentItems) != 1 {
+		t.Errorf("report billable %v adjustments %v adjusted %v items %+v", report.BillableCost, report.Adjustments, report.AdjustedTotal, report.AdjustmentItems)
+	}
+	if len(report.Namespaces) != 1 || report.Namespaces[0].Adjustments != -1.25 {
+		t.Errorf("report namespaces = %+v", report.Namespaces)
+	}
+}
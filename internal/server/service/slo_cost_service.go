@@ -0,0 +1,164 @@
+// Package service slo_cost_service.go: SLO 达标成本分析——把各服务的可用性目标与其工作负载的使用量分布关联，
+// 估算“每个 9 要花多少钱”：为目标保留的超出典型需求的余量成本、多加/放宽一个 9 的成本变化，供产品负责人权衡 SLO 严格度与支出。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// Samples of the usage quantiles of an SLO cost.
+const (
+	SLOCostSamplesSketch    = "sketch"
+	SLOCostSamplesHourlyP95 = "hourly_p95"
+)
+
+// latencyBoundShare is the share of the latency target from which a service counts as latency bound.
+const latencyBoundShare = 0.8
+
+// SLOCostService joins SLO targets with the usage of the workloads serving them.
+type SLOCostService struct {
+	repo     postgres.Repository
+	slo      SLOStatusProvider
+	cpuPrice float64 // per core hour
+	memPrice float64 // per GiB hour
+	// defaultTarget (%) and latencyTarget (ms) apply to services without targets of their own.
+	defaultTarget float64
+	latencyTarget float64
+	now           func() time.Time
+}
+
+// NewSLOCostService creates an SLOCostService priced with the given unit prices; availabilityTarget
+// (%) is the target of services without one, latencyTargetMs the P95 latency target.
+func NewSLOCostService(repo postgres.Repository, slo SLOStatusProvider, cpuPricePerCoreHour, memPricePerGBHour, availabilityTarget, latencyTargetMs float64) *SLOCostService {
+	return &SLOCostService{
+		repo:          repo,
+		slo:           slo,
+		cpuPrice:      cpuPricePerCoreHour,
+		memPrice:      memPricePerGBHour,
+		defaultTarget: availabilityTarget,
+		latencyTarget: latencyTargetMs,
+		now:           time.Now,
+	}
+}
+
+// Analyze returns the SLO cost of every SLO service over start..end (default the last 7 days),
+// highest SLO headroom cost first. The usage quantiles come from the merged usage sketches of the
+// workload named like the service when every hour has a single row with sketches, otherwise from
+// the hourly P95 totals (coarser: a tail shorter than an hour is not seen). Requests are those of
+// the latest hour.
+func (s *SLOCostService) Analyze(ctx context.Context, start, end time.Time) (*dto.SLOCostResponse, error) {
+	if end.IsZero() {
+		end = s.now()
+	}
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -7)
+	}
+	if !end.After(start) {
+		return nil, ErrInvalidWindow
+	}
+	services, err := s.slo.ListSLOStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list SLO status: %w", err)
+	}
+
+	resp := &dto.SLOCostResponse{StartTime: start, EndTime: end, Services: []dto.SLOServiceCost{}, Unmatched: []string{}}
+	for _, svc := range services {
+		stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{
+			Namespace: svc.Namespace, WorkloadName: svc.Service, StartTime: start, EndTime: end,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list hourly workload stats of %s/%s: %w", svc.Namespace, svc.Service, err)
+		}
+		if len(stats) == 0 {
+			resp.Unmatched = append(resp.Unmatched, svc.Namespace+"/"+svc.Service)
+			continue
+		}
+		in, samples := sloCostInput(stats)
+		in.CPUPricePerCoreHour, in.MemPricePerGBHour = s.cpuPrice, s.memPrice
+		in.AvailabilityTarget = svc.AvailabilityTarget
+		if in.AvailabilityTarget <= 0 {
+			in.AvailabilityTarget = s.defaultTarget
+		}
+		item := dto.SLOServiceCost{
+			Namespace:       svc.Namespace,
+			Service:         svc.Service,
+			Status:          string(svc.Status),
+			Availability:    svc.Availability,
+			LatencyP95Ms:    svc.LatencyP95Ms,
+			LatencyTargetMs: s.latencyTarget,
+			LatencyBound:    s.latencyTarget > 0 && svc.LatencyP95Ms > s.latencyTarget*latencyBoundShare,
+			Samples:         samples,
+			Cost:            costmodel.ComputeSLOCost(in),
+		}
+		resp.TotalSLOHeadroomMonthlyCost += item.Cost.SLOHeadroomMonthlyCost
+		resp.TotalExcessMonthlyCost += item.Cost.ExcessMonthlyCost
+		resp.Services = append(resp.Services, item)
+	}
+	resp.TotalSLOHeadroomMonthlyCost = math.Round(resp.TotalSLOHeadroomMonthlyCost*100) / 100
+	resp.TotalExcessMonthlyCost = math.Round(resp.TotalExcessMonthlyCost*100) / 100
+	sort.SliceStable(resp.Services, func(i, j int) bool {
+		return resp.Services[i].Cost.SLOHeadroomMonthlyCost > resp.Services[j].Cost.SLOHeadroomMonthlyCost
+	})
+	return resp, nil
+}
+
+// sloCostInput builds the usage distribution and the latest requests of a workload from its stats.
+func sloCostInput(stats []postgres.HourlyWorkloadStat) (costmodel.SLOCostInput, string) {
+	type hourUsage struct {
+		rows           int
+		cpu, mem       float64
+		cpuSk, memSk   *costmodel.UsageSketch
+		cpuReq, memReq float64
+	}
+	hours := make(map[time.Time]*hourUsage)
+	var latest time.Time
+	for _, st := range stats {
+		h := st.Timestamp.UTC().Truncate(time.Hour)
+		u, ok := hours[h]
+		if !ok {
+			u = &hourUsage{}
+			hours[h] = u
+		}
+		u.rows++
+		u.cpu += st.CPUUsageP95
+		u.mem += float64(st.MemUsageP95)
+		u.cpuSk, u.memSk = st.CPUUsageSketch, st.MemUsageSketch
+		u.cpuReq += st.CPURequest
+		u.memReq += float64(st.MemRequest)
+		if h.After(latest) {
+			latest = h
+		}
+	}
+	var in costmodel.SLOCostInput
+	in.CPURequest, in.MemRequest = hours[latest].cpuReq, hours[latest].memReq
+
+	var cpuSketches, memSketches []*costmodel.UsageSketch
+	single := true
+	for _, u := range hours {
+		single = single && u.rows == 1
+		cpuSketches = append(cpuSketches, u.cpuSk)
+		memSketches = append(memSketches, u.memSk)
+	}
+	if single {
+		cpu, cpuOK := costmodel.MergeSketches(cpuSketches)
+		mem, memOK := costmodel.MergeSketches(memSketches)
+		if cpuOK && memOK {
+			in.CPU, in.Mem = cpu, mem
+			return in, SLOCostSamplesSketch
+		}
+	}
+	in.CPU, in.Mem = costmodel.NewUsageSketch(0), costmodel.NewUsageSketch(0)
+	for _, u := range hours {
+		in.CPU.Add(u.cpu)
+		in.Mem.Add(u.mem)
+	}
+	return in, SLOCostSamplesHourlyP95
+}
@@ -0,0 +1,156 @@
+// Package service snapshot_guardrail.go: 快照落库前的变化率检查。总可计费成本相对上一快照偏离超过阈值时
+// 标记为可疑并告警，可选地要求人工确认后才进入看板与事件流，避免单价配置笔误产生的离谱数字直接对外。
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// ErrSnapshotNotAwaitingConfirmation is returned when confirming a snapshot that is not held back.
+var ErrSnapshotNotAwaitingConfirmation = dataerr.Conflict("cost snapshot is not awaiting confirmation")
+
+// SnapshotAlertNotifier delivers guardrail alerts. *notifier.Dispatcher satisfies this interface.
+type SnapshotAlertNotifier interface {
+	Notify(ctx context.Context, alert notifier.Alert) error
+}
+
+// SnapshotGuardrail compares a snapshot with the previous trusted one before it is persisted.
+type SnapshotGuardrail struct {
+	repo                postgres.Repository
+	maxChangePercent    float64
+	requireConfirmation bool
+	notifier            SnapshotAlertNotifier
+	now                 func() time.Time
+}
+
+// NewSnapshotGuardrail creates a SnapshotGuardrail. maxChangePercent <= 0 disables the check;
+// requireConfirmation holds suspect snapshots back until Confirm.
+func NewSnapshotGuardrail(repo postgres.Repository, maxChangePercent float64, requireConfirmation bool) *SnapshotGuardrail {
+	return &SnapshotGuardrail{repo: repo, maxChangePercent: maxChangePercent, requireConfirmation: requireConfirmation, now: time.Now}
+}
+
+// SetNotifier sends an anomaly alert for every suspect snapshot; without it snapshots are only marked.
+func (g *SnapshotGuardrail) SetNotifier(n SnapshotAlertNotifier) {
+	g.notifier = n
+}
+
+// Check marks snapshot as suspect when its total billable cost deviates more than the configured
+// percentage from the previous trusted snapshot (the latest earlier one that is not an unconfirmed
+// suspect), so a bad snapshot does not make the next correct one look suspect as well. Alert
+// delivery failures do not block the save; they are recorded in the snapshot metadata.
+func (g *SnapshotGuardrail) Check(ctx context.Context, snapshot *postgres.CostSnapshot) error {
+	if g == nil || g.maxChangePercent <= 0 {
+		return nil
+	}
+	prev, err := g.previous(ctx, snapshot)
+	if err != nil || prev == nil || prev.TotalBillableCost <= 0 {
+		return err
+	}
+	change := (snapshot.TotalBillableCost - prev.TotalBillableCost) / prev.TotalBillableCost * 100
+	if math.Abs(change) <= g.maxChangePercent {
+		return nil
+	}
+	snapshot.Suspect = true
+	snapshot.SuspectReason = fmt.Sprintf("total billable cost %.2f changed %+.1f%% from %.2f in snapshot %s (limit ±%.1f%%)",
+		snapshot.TotalBillableCost, change, prev.TotalBillableCost, prev.ID, g.maxChangePercent)
+	snapshot.RequiresConfirmation = g.requireConfirmation
+	if g.notifier == nil {
+		return nil
+	}
+	if err := g.notifier.Notify(ctx, g.alert(*snapshot)); err != nil {
+		if snapshot.Metadata == nil {
+			snapshot.Metadata = make(map[string]interface{})
+		}
+		snapshot.Metadata["guardrail_alert_error"] = err.Error()
+	}
+	return nil
+}
+
+func (g *SnapshotGuardrail) previous(ctx context.Context, snapshot *postgres.CostSnapshot) (*postgres.CostSnapshot, error) {
+	candidates, err := g.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{EndTime: snapshot.Timestamp, Fields: postgres.SnapshotFieldSummary})
+	if err != nil {
+		return nil, fmt.Errorf("list previous cost snapshots: %w", err)
+	}
+	for i, c := range candidates { // newest first
+		if c.ID == snapshot.ID || (c.Suspect && c.ConfirmedAt == nil) {
+			continue
+		}
+		return &candidates[i], nil
+	}
+	return nil, nil
+}
+
+func (g *SnapshotGuardrail) alert(snapshot postgres.CostSnapshot) notifier.Alert {
+	summary := snapshot.SuspectReason
+	if snapshot.RequiresConfirmation {
+		summary += "; held back from dashboards until confirmed"
+	}
+	return notifier.Alert{
+		Type:     notifier.AlertTypeAnomaly,
+		Severity: notifier.SeverityWarning,
+		Title:    "Suspect cost snapshot " + snapshot.ID,
+		Summary:  summary,
+		Fields: []notifier.Field{
+			{Name: "snapshot_id", Value: snapshot.ID},
+			{Name: "time_range", Value: snapshot.TimeRangeStart.UTC().Format(time.RFC3339) + " - " + snapshot.TimeRangeEnd.UTC().Format(time.RFC3339)},
+		},
+		DedupKey:  "lighthouse/snapshot/suspect/" + snapshot.ID,
+		Timestamp: g.now(),
+	}
+}
+
+// ListSuspect returns the suspect snapshots, newest first, including confirmed ones.
+func (g *SnapshotGuardrail) ListSuspect(ctx context.Context) (*dto.SuspectSnapshotListResponse, error) {
+	snapshots, err := g.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{Fields: postgres.SnapshotFieldSummary})
+	if err != nil {
+		return nil, fmt.Errorf("list cost snapshots: %w", err)
+	}
+	resp := &dto.SuspectSnapshotListResponse{Snapshots: []dto.SuspectSnapshot{}}
+	for _, s := range snapshots {
+		if s.Suspect {
+			resp.Snapshots = append(resp.Snapshots, toDTOSuspectSnapshot(s))
+		}
+	}
+	resp.Total = len(resp.Snapshots)
+	return resp, nil
+}
+
+// Confirm releases a snapshot awaiting confirmation. The snapshot is saved again, so the
+// confirmation is sealed in the hash chain and repositories publishing snapshots see it.
+func (g *SnapshotGuardrail) Confirm(ctx context.Context, id, confirmedBy string) (*dto.SuspectSnapshot, error) {
+	snapshot, err := g.repo.GetCostSnapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !snapshot.AwaitingConfirmation() {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotAwaitingConfirmation, id)
+	}
+	at := g.now().UTC()
+	snapshot.ConfirmedBy, snapshot.ConfirmedAt = confirmedBy, &at
+	if err := g.repo.SaveCostSnapshot(ctx, *snapshot); err != nil {
+		return nil, fmt.Errorf("save confirmed cost snapshot: %w", err)
+	}
+	out := toDTOSuspectSnapshot(*snapshot)
+	return &out, nil
+}
+
+func toDTOSuspectSnapshot(s postgres.CostSnapshot) dto.SuspectSnapshot {
+	return dto.SuspectSnapshot{
+		ID:                   s.ID,
+		CalculationID:        s.CalculationID,
+		Timestamp:            s.Timestamp,
+		TotalBillableCost:    s.TotalBillableCost,
+		Reason:               s.SuspectReason,
+		RequiresConfirmation: s.RequiresConfirmation,
+		ConfirmedBy:          s.ConfirmedBy,
+		ConfirmedAt:          s.ConfirmedAt,
+	}
+}
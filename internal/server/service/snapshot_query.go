@@ -0,0 +1,151 @@
+// Package service snapshot_query.go: 按字段组读取成本快照。ResourceResults / AggregatedResults 占快照的绝大部分，
+// 只需要总额或等级计数的查询在存储层就不读取它们（postgres.CostSnapshotFilter.Fields）。
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// ErrInvalidSnapshotFields is returned for an unknown fields or include value.
+var ErrInvalidSnapshotFields = dataerr.Validation("invalid snapshot field selection")
+
+// aggregationLevelNames are the keys of dto.CostSnapshot.AggregatedResults.
+var aggregationLevelNames = map[costmodel.AggregationLevel]string{
+	costmodel.LevelPod:       "pod",
+	costmodel.LevelWorkload:  "workload",
+	costmodel.LevelNamespace: "namespace",
+	costmodel.LevelNode:      "node",
+	costmodel.LevelCluster:   "cluster",
+	costmodel.LevelNodePool:  "node_pool",
+}
+
+// ParseSnapshotFields turns the fields (full / summary / counts) and include (comma-separated
+// aggregated_results, resource_results) query parameters into the field groups to read. An empty
+// fields value selects def.
+func ParseSnapshotFields(fields, include string, def postgres.SnapshotFields) (postgres.SnapshotFields, error) {
+	var out postgres.SnapshotFields
+	switch fields {
+	case "":
+		out = def
+	case dto.SnapshotFieldsFull:
+		out = postgres.SnapshotFieldsAll
+	case dto.SnapshotFieldsSummary:
+		out = postgres.SnapshotFieldCounts | postgres.SnapshotFieldSummary
+	case dto.SnapshotFieldsCounts:
+		out = postgres.SnapshotFieldCounts
+	default:
+		return 0, fmt.Errorf("%w: fields must be %s, %s or %s", ErrInvalidSnapshotFields, dto.SnapshotFieldsFull, dto.SnapshotFieldsSummary, dto.SnapshotFieldsCounts)
+	}
+	for _, part := range strings.Split(include, ",") {
+		switch strings.TrimSpace(part) {
+		case "":
+		case dto.SnapshotIncludeAggregated:
+			out |= postgres.SnapshotFieldAggregated
+		case dto.SnapshotIncludeResources:
+			out |= postgres.SnapshotFieldResources
+		default:
+			return 0, fmt.Errorf("%w: include must list %s or %s", ErrInvalidSnapshotFields, dto.SnapshotIncludeAggregated, dto.SnapshotIncludeResources)
+		}
+	}
+	return out, nil
+}
+
+// SnapshotQuery selects cost snapshots by timestamp (both ends inclusive, zero: unbounded).
+type SnapshotQuery struct {
+	StartTime, EndTime time.Time
+	Limit, Offset      int
+	Fields             postgres.SnapshotFields
+}
+
+// ListSnapshots returns the snapshots in the window, newest first, including those awaiting
+// confirmation, with only the selected field groups.
+func (s *CostService) ListSnapshots(ctx context.Context, q SnapshotQuery) (*dto.CostSnapshotListResponse, error) {
+	if !q.StartTime.IsZero() && !q.EndTime.IsZero() && q.EndTime.Before(q.StartTime) {
+		return nil, ErrInvalidWindow
+	}
+	snapshots, err := s.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{
+		StartTime: q.StartTime, EndTime: q.EndTime, Limit: q.Limit, Offset: q.Offset, Fields: q.Fields,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list cost snapshots: %w", err)
+	}
+	resp := &dto.CostSnapshotListResponse{Snapshots: make([]dto.CostSnapshot, 0, len(snapshots))}
+	for _, snap := range snapshots {
+		resp.Snapshots = append(resp.Snapshots, toDTOCostSnapshot(snap, q.Fields))
+	}
+	resp.Total = len(resp.Snapshots)
+	return resp, nil
+}
+
+// GetSnapshot returns one snapshot with only the selected field groups.
+func (s *CostService) GetSnapshot(ctx context.Context, id string, fields postgres.SnapshotFields) (*dto.CostSnapshot, error) {
+	snapshots, err := s.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{ID: id, Fields: fields})
+	if err != nil {
+		return nil, fmt.Errorf("get cost snapshot: %w", err)
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, id)
+	}
+	out := toDTOCostSnapshot(snapshots[0], fields)
+	return &out, nil
+}
+
+func toDTOCostSnapshot(snap postgres.CostSnapshot, fields postgres.SnapshotFields) dto.CostSnapshot {
+	if fields == 0 {
+		fields = postgres.SnapshotFieldsAll
+	}
+	out := dto.CostSnapshot{
+		ID:             snap.ID,
+		CalculationID:  snap.CalculationID,
+		Timestamp:      snap.Timestamp,
+		TimeRangeStart: snap.TimeRangeStart,
+		TimeRangeEnd:   snap.TimeRangeEnd,
+		Fields:         []string{},
+	}
+	if fields&postgres.SnapshotFieldCounts != 0 {
+		counts := snapshotGradeCounts(snap)
+		out.Counts = &counts
+		out.Fields = append(out.Fields, dto.SnapshotFieldsCounts)
+	}
+	if fields&postgres.SnapshotFieldSummary != 0 {
+		out.Summary = &dto.SnapshotSummary{
+			TotalBillableCost:      snap.TotalBillableCost,
+			TotalUsageCost:         snap.TotalUsageCost,
+			TotalWasteCost:         snap.TotalWasteCost,
+			OverallEfficiencyScore: snap.OverallEfficiencyScore,
+			ContentHash:            snap.ContentHash,
+			Suspect:                snap.Suspect,
+			RequiresConfirmation:   snap.RequiresConfirmation,
+			ConfirmedBy:            snap.ConfirmedBy,
+			ConfirmedAt:            snap.ConfirmedAt,
+			Metadata:               snap.Metadata,
+			CreatedAt:              snap.CreatedAt,
+			UpdatedAt:              snap.UpdatedAt,
+		}
+		out.Fields = append(out.Fields, dto.SnapshotFieldsSummary)
+	}
+	if fields&postgres.SnapshotFieldAggregated != 0 {
+		out.AggregatedResults = make(map[string][]costmodel.AggregationResult, len(snap.AggregatedResults))
+		for level, results := range snap.AggregatedResults {
+			name, ok := aggregationLevelNames[level]
+			if !ok {
+				name = fmt.Sprintf("level_%d", int(level))
+			}
+			out.AggregatedResults[name] = results
+		}
+		out.Fields = append(out.Fields, dto.SnapshotIncludeAggregated)
+	}
+	if fields&postgres.SnapshotFieldResources != 0 {
+		out.ResourceResults = snap.ResourceResults
+		out.Fields = append(out.Fields, dto.SnapshotIncludeResources)
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+// Package service stale_data_watchdog.go: 数据新鲜度看门狗。跟踪最新小时级工作负载统计与成本快照的时间，
+// 超过 N 倍计算间隔即告警，避免采集或计算管道静默停滞数天无人发现；同时为 /metrics 提供新鲜度 SLI。
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+)
+
+// Data sources tracked by the stale-data watchdog.
+const (
+	FreshnessSourceHourlyStats = "hourly_workload_stats"
+	FreshnessSourceSnapshots   = "cost_snapshots"
+)
+
+// DefaultStaleDataFactor is the multiple of the calculation interval after which data is stale.
+const DefaultStaleDataFactor = 3
+
+// SourceFreshness is the age of the newest row of one data source. NewestAt is nil while the source
+// has no data yet; such a source is not stale (readiness covers a fresh deploy).
+type SourceFreshness struct {
+	Source    string
+	NewestAt  *time.Time
+	Age       time.Duration
+	Threshold time.Duration
+	Stale     bool
+}
+
+// StaleDataWatchdog checks the newest hourly workload stat and cost snapshot against
+// factor × calculation interval and notifies once per stale episode of a source. Safe for concurrent use.
+type StaleDataWatchdog struct {
+	repo      postgres.Repository
+	interval  time.Duration
+	threshold time.Duration
+	notifier  SnapshotAlertNotifier
+	now       func() time.Time
+
+	mu      sync.Mutex
+	alerted map[string]bool // sources notified in their current stale episode
+	alerts  int
+}
+
+// NewStaleDataWatchdog creates a StaleDataWatchdog; factor <= 0 uses DefaultStaleDataFactor. A zero
+// calculation interval never reports stale data.
+func NewStaleDataWatchdog(repo postgres.Repository, calculationInterval time.Duration, factor float64) *StaleDataWatchdog {
+	if factor <= 0 {
+		factor = DefaultStaleDataFactor
+	}
+	return &StaleDataWatchdog{
+		repo:      repo,
+		interval:  calculationInterval,
+		threshold: time.Duration(float64(calculationInterval) * factor),
+		now:       time.Now,
+		alerted:   make(map[string]bool),
+	}
+}
+
+// SetNotifier sends an alert when a source becomes stale; without it staleness is only exported as metrics.
+func (w *StaleDataWatchdog) SetNotifier(n SnapshotAlertNotifier) {
+	w.notifier = n
+}
+
+// Threshold is the age after which a source is stale.
+func (w *StaleDataWatchdog) Threshold() time.Duration {
+	return w.threshold
+}
+
+// Alerts is the number of stale-data alerts sent since start.
+func (w *StaleDataWatchdog) Alerts() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.alerts
+}
+
+// Measure reads the newest row of each source without notifying.
+func (w *StaleDataWatchdog) Measure(ctx context.Context) ([]SourceFreshness, error) {
+	now := w.now().UTC()
+	out := make([]SourceFreshness, 0, 2)
+	stats, err := w.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Limit: 1})
+	if err != nil {
+		return nil, fmt.Errorf("list hourly workload stats: %w", err)
+	}
+	var newest *time.Time
+	if len(stats) > 0 {
+		newest = &stats[0].Timestamp
+	}
+	out = append(out, w.freshness(FreshnessSourceHourlyStats, newest, now))
+
+	// 快照是否待确认与管道是否停滞无关，待确认的快照也计入
+	snapshots, err := w.repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{Limit: 1, Fields: postgres.SnapshotFieldCounts})
+	if err != nil {
+		return nil, fmt.Errorf("list cost snapshots: %w", err)
+	}
+	newest = nil
+	if len(snapshots) > 0 {
+		newest = &snapshots[0].Timestamp
+	}
+	out = append(out, w.freshness(FreshnessSourceSnapshots, newest, now))
+	return out, nil
+}
+
+func (w *StaleDataWatchdog) freshness(source string, newest *time.Time, now time.Time) SourceFreshness {
+	f := SourceFreshness{Source: source, Threshold: w.threshold}
+	if newest != nil {
+		at := newest.UTC()
+		f.NewestAt = &at
+		f.Age = now.Sub(at)
+		if f.Age < 0 {
+			f.Age = 0
+		}
+		f.Stale = w.threshold > 0 && f.Age > w.threshold
+	}
+	return f
+}
+
+// Check measures the sources and notifies for each one that became stale since the last check.
+// A failed notification is retried on the next check.
+func (w *StaleDataWatchdog) Check(ctx context.Context) ([]SourceFreshness, error) {
+	sources, err := w.Measure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []error
+	for _, f := range sources {
+		if !f.Stale {
+			delete(w.alerted, f.Source)
+			continue
+		}
+		if w.alerted[f.Source] || w.notifier == nil {
+			continue
+		}
+		if err := w.notifier.Notify(ctx, w.alertOf(f)); err != nil && !errors.Is(err, notifier.ErrSilenced) {
+			errs = append(errs, fmt.Errorf("notify stale %s: %w", f.Source, err))
+			continue
+		}
+		w.alerted[f.Source] = true
+		w.alerts++
+	}
+	return sources, errors.Join(errs...)
+}
+
+func (w *StaleDataWatchdog) alertOf(f SourceFreshness) notifier.Alert {
+	return notifier.Alert{
+		Type:     notifier.AlertTypeStaleData,
+		Severity: notifier.SeverityWarning,
+		Title:    "no new " + f.Source,
+		Summary:  fmt.Sprintf("newest %s is %s old, more than %s", f.Source, f.Age.Truncate(time.Minute), f.Threshold),
+		Fields: []notifier.Field{
+			{Name: "newest", Value: f.NewestAt.Format(time.RFC3339)},
+			{Name: "calculation interval", Value: w.interval.String()},
+		},
+		DedupKey:  "stale-data/" + f.Source,
+		Timestamp: w.now().UTC(),
+		Labels:    map[string]string{"source": f.Source},
+	}
+}
+
+// Run checks every tick (default the calculation interval) until ctx is cancelled.
+func (w *StaleDataWatchdog) Run(ctx context.Context, tick time.Duration, onError func(error)) {
+	if tick <= 0 {
+		tick = w.interval
+	}
+	if tick <= 0 {
+		tick = time.Hour
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Check(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Package service state_service.go: 应用状态导出/导入（ROI 基线、单价历史、SLO、团队预算与归属），
+// 用于环境克隆（staging -> prod），导入支持 dry-run 差异预览。
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// ErrInvalidStateBundle is returned for a bundle of an unknown version or with invalid entries.
+var ErrInvalidStateBundle = dataerr.Validation("invalid state bundle")
+
+// Sections of a state bundle.
+const (
+	StateSectionROIBaselines  = "roi_baselines"
+	StateSectionPriceVersions = "price_versions"
+	StateSectionSLO           = "slo"
+	StateSectionTeams         = "teams"
+	StateSectionOwners        = "owners"
+)
+
+// StateConfig is the configuration-managed part of the application state of this environment.
+type StateConfig struct {
+	Environment string
+	SLO         *dto.SLOConfigState
+	Teams       map[string]dto.TeamState
+	Owners      map[string][]string
+}
+
+// StateService exports the application state as a bundle and imports bundles of other environments.
+// Import is additive: entries only present in the target are kept.
+type StateService struct {
+	repo   postgres.Repository
+	prices PriceHistoryStore // nil: the environment has no price history
+	config StateConfig
+	now    func() time.Time
+}
+
+// NewStateService creates a StateService; prices may be nil.
+func NewStateService(repo postgres.Repository, prices PriceHistoryStore, config StateConfig) *StateService {
+	return &StateService{repo: repo, prices: prices, config: config, now: time.Now}
+}
+
+// Export returns the state bundle of this environment.
+func (s *StateService) Export(ctx context.Context) (*dto.StateBundle, error) {
+	bundle := &dto.StateBundle{
+		Version:       dto.StateBundleVersion,
+		Environment:   s.config.Environment,
+		ExportedAt:    s.now().UTC(),
+		ROIBaselines:  []dto.ROIBaselineState{},
+		PriceVersions: []dto.PriceVersion{},
+		SLO:           s.config.SLO,
+		Teams:         s.config.Teams,
+		Owners:        s.config.Owners,
+	}
+	baselines, err := s.repo.ListROIBaselines(ctx, postgres.ROIBaselineFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list ROI baselines: %w", err)
+	}
+	for _, b := range baselines {
+		bundle.ROIBaselines = append(bundle.ROIBaselines, toROIBaselineState(b))
+	}
+	sort.Slice(bundle.ROIBaselines, func(i, j int) bool { return bundle.ROIBaselines[i].ID < bundle.ROIBaselines[j].ID })
+	if s.prices != nil {
+		versions, err := s.prices.ListPriceVersions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list price versions: %w", err)
+		}
+		for _, v := range versions {
+			bundle.PriceVersions = append(bundle.PriceVersions, toDTOPriceVersion(v))
+		}
+	}
+	return bundle, nil
+}
+
+// Import diffs bundle against this environment and, unless dryRun, saves the created and updated
+// ROI baselines and price versions. Imported prices apply to windows calculated afterwards; past
+// windows are re-priced with POST /api/v1/pricing/recalculate. Differences in the configuration
+// sections are reported in ConfigChanges only.
+func (s *StateService) Import(ctx context.Context, bundle dto.StateBundle, dryRun bool) (*dto.StateImportResponse, error) {
+	if bundle.Version != dto.StateBundleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidStateBundle, bundle.Version)
+	}
+	resp := &dto.StateImportResponse{DryRun: dryRun, Changes: []dto.StateChange{}, ConfigChanges: []dto.StateChange{}, Summary: map[string]int{}}
+
+	existing, err := s.repo.ListROIBaselines(ctx, postgres.ROIBaselineFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list ROI baselines: %w", err)
+	}
+	baselines := make(map[string]postgres.ROIBaseline, len(existing))
+	for _, b := range existing {
+		baselines[b.ID] = b
+	}
+	var saveBaselines []postgres.ROIBaseline
+	for _, want := range bundle.ROIBaselines {
+		if want.ID == "" {
+			return nil, fmt.Errorf("%w: ROI baseline %q has no id", ErrInvalidStateBundle, want.Name)
+		}
+		want.TimePeriodStart, want.TimePeriodEnd = want.TimePeriodStart.UTC(), want.TimePeriodEnd.UTC()
+		current, ok := baselines[want.ID]
+		var before interface{}
+		if ok {
+			before = toROIBaselineState(current)
+		}
+		change := diffEntry(StateSectionROIBaselines, want.ID, before, want)
+		s.record(resp, change)
+		if change.Action == dto.StateActionCreate || change.Action == dto.StateActionUpdate {
+			b := fromROIBaselineState(want)
+			b.CreatedAt = current.CreatedAt
+			saveBaselines = append(saveBaselines, b)
+		}
+	}
+
+	var saveVersions []postgres.PriceVersion
+	if len(bundle.PriceVersions) > 0 && s.prices == nil {
+		s.record(resp, dto.StateChange{Section: StateSectionPriceVersions, Key: "*", Action: dto.StateActionSkipped,
+			Detail: "price history is not configured in this environment"})
+	} else if s.prices != nil {
+		versions, err := s.prices.ListPriceVersions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list price versions: %w", err)
+		}
+		byTime := make(map[int64]postgres.PriceVersion, len(versions))
+		for _, v := range versions {
+			byTime[v.EffectiveFrom.Unix()] = v
+		}
+		for _, want := range bundle.PriceVersions {
+			v := postgres.PriceVersion{EffectiveFrom: want.EffectiveFrom.UTC(), CPUPricePerCoreHour: want.CPUPricePerCoreHour,
+				MemPricePerGBHour: want.MemPricePerGBHour, Note: want.Note}
+			if err := v.UnitPrice().Validate(); err != nil {
+				return nil, fmt.Errorf("%w: price version %s: %v", ErrInvalidStateBundle, want.EffectiveFrom.Format(time.RFC3339), err)
+			}
+			var before interface{}
+			if current, ok := byTime[v.EffectiveFrom.Unix()]; ok {
+				before = priceState(toDTOPriceVersion(current))
+			}
+			change := diffEntry(StateSectionPriceVersions, v.EffectiveFrom.Format(time.RFC3339), before, priceState(want))
+			s.record(resp, change)
+			if change.Action == dto.StateActionCreate || change.Action == dto.StateActionUpdate {
+				saveVersions = append(saveVersions, v)
+			}
+		}
+	}
+
+	if bundle.SLO != nil {
+		var before interface{}
+		if s.config.SLO != nil {
+			before = *s.config.SLO
+		}
+		s.recordConfig(resp, diffEntry(StateSectionSLO, "slo", before, *bundle.SLO))
+	}
+	for _, name := range sortedKeys(bundle.Teams) {
+		var before interface{}
+		if current, ok := s.config.Teams[name]; ok {
+			before = current
+		}
+		s.recordConfig(resp, diffEntry(StateSectionTeams, name, before, bundle.Teams[name]))
+	}
+	for _, ns := range sortedKeys(bundle.Owners) {
+		var before interface{}
+		if current, ok := s.config.Owners[ns]; ok {
+			before = current
+		}
+		s.recordConfig(resp, diffEntry(StateSectionOwners, ns, before, bundle.Owners[ns]))
+	}
+
+	if dryRun {
+		return resp, nil
+	}
+	for _, b := range saveBaselines {
+		if err := s.repo.SaveROIBaseline(ctx, b); err != nil {
+			return nil, fmt.Errorf("save ROI baseline %s: %w", b.ID, err)
+		}
+	}
+	for _, v := range saveVersions {
+		if err := s.prices.SavePriceVersion(ctx, v); err != nil {
+			return nil, fmt.Errorf("save price version %s: %w", v.EffectiveFrom.Format(time.RFC3339), err)
+		}
+	}
+	return resp, nil
+}
+
+func (s *StateService) record(resp *dto.StateImportResponse, change dto.StateChange) {
+	resp.Summary[change.Action]++
+	if change.Action != dto.StateActionUnchanged {
+		resp.Changes = append(resp.Changes, change)
+	}
+}
+
+func (s *StateService) recordConfig(resp *dto.StateImportResponse, change dto.StateChange) {
+	if change.Action != dto.StateActionUnchanged {
+		resp.ConfigChanges = append(resp.ConfigChanges, change)
+	}
+}
+
+// diffEntry compares the JSON forms of before (nil when absent) and after.
+func diffEntry(section, key string, before, after interface{}) dto.StateChange {
+	change := dto.StateChange{Section: section, Key: key, Before: before, After: after}
+	switch {
+	case before == nil:
+		change.Action = dto.StateActionCreate
+	case sameJSON(before, after):
+		change.Action = dto.StateActionUnchanged
+		change.Before, change.After = nil, nil
+	default:
+		change.Action = dto.StateActionUpdate
+	}
+	return change
+}
+
+func sameJSON(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// priceState drops the fields of a price version that differ between environments.
+func priceState(v dto.PriceVersion) dto.PriceVersion {
+	return dto.PriceVersion{EffectiveFrom: v.EffectiveFrom.UTC(), CPUPricePerCoreHour: v.CPUPricePerCoreHour,
+		MemPricePerGBHour: v.MemPricePerGBHour, Note: v.Note}
+}
+
+func toROIBaselineState(b postgres.ROIBaseline) dto.ROIBaselineState {
+	return dto.ROIBaselineState{
+		ID:              b.ID,
+		Name:            b.Name,
+		Description:     b.Description,
+		BaselineType:    b.BaselineType,
+		TimePeriodStart: b.TimePeriodStart.UTC(),
+		TimePeriodEnd:   b.TimePeriodEnd.UTC(),
+		Metrics:         b.Metrics,
+		ReferenceData:   b.ReferenceData,
+		CreatedBy:       b.CreatedBy,
+	}
+}
+
+func fromROIBaselineState(b dto.ROIBaselineState) postgres.ROIBaseline {
+	return postgres.ROIBaseline{
+		ID:              b.ID,
+		Name:            b.Name,
+		Description:     b.Description,
+		BaselineType:    b.BaselineType,
+		TimePeriodStart: b.TimePeriodStart,
+		TimePeriodEnd:   b.TimePeriodEnd,
+		Metrics:         b.Metrics,
+		ReferenceData:   b.ReferenceData,
+		CreatedBy:       b.CreatedBy,
+	}
+}
@@ -0,0 +1,157 @@
+// Package service target_service.go: namespace 效率目标（保存在 metadata）与实际效率的逐日对比、达标率与连续达标天数。
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// efficiencyTargetKeyPrefix is the metadata key prefix of the targets: efficiency_target/<namespace>.
+const efficiencyTargetKeyPrefix = "efficiency_target/"
+
+// defaultTargetWindow is the tracking window when no start time is given.
+const defaultTargetWindow = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidEfficiencyTarget is returned for a target outside 0-100.
+	ErrInvalidEfficiencyTarget = dataerr.Validation("efficiency target must be between 0 and 100")
+	// ErrEfficiencyTargetNotFound is returned when tracking a namespace without a target.
+	ErrEfficiencyTargetNotFound = dataerr.NotFound("efficiency target not found")
+)
+
+// EfficiencyTargetService stores per-namespace efficiency targets and tracks the daily efficiency
+// (usage / billable cost of cost_daily_namespace) against them.
+type EfficiencyTargetService struct {
+	repo     postgres.Repository
+	calendar costmodel.AccountingCalendar
+	now      func() time.Time
+}
+
+// NewEfficiencyTargetService creates an EfficiencyTargetService.
+func NewEfficiencyTargetService(repo postgres.Repository) *EfficiencyTargetService {
+	return &EfficiencyTargetService{repo: repo, now: time.Now}
+}
+
+// SetCalendar sets the accounting time zone of "today" and of the days of a tracking window (default UTC).
+func (s *EfficiencyTargetService) SetCalendar(calendar costmodel.AccountingCalendar) {
+	s.calendar = calendar
+}
+
+// SetTarget sets (or replaces) the efficiency target of a namespace.
+func (s *EfficiencyTargetService) SetTarget(ctx context.Context, namespace string, req dto.SetEfficiencyTargetRequest) (*dto.EfficiencyTarget, error) {
+	if req.Target <= 0 || req.Target > 100 {
+		return nil, fmt.Errorf("%w: got %v", ErrInvalidEfficiencyTarget, req.Target)
+	}
+	m := postgres.Metadata{
+		Key:         efficiencyTargetKeyPrefix + namespace,
+		Value:       map[string]interface{}{"namespace": namespace, "target": req.Target},
+		Description: "efficiency target of namespace " + namespace,
+		CreatedBy:   req.SetBy,
+	}
+	if err := s.repo.SaveMetadata(ctx, m); err != nil {
+		return nil, fmt.Errorf("save efficiency target: %w", err)
+	}
+	return &dto.EfficiencyTarget{Namespace: namespace, Target: req.Target, SetBy: req.SetBy, UpdatedAt: s.now().UTC()}, nil
+}
+
+// ListTargets returns the targets of all namespaces, sorted by namespace.
+func (s *EfficiencyTargetService) ListTargets(ctx context.Context) (*dto.EfficiencyTargetListResponse, error) {
+	list, err := s.repo.ListMetadata(ctx, postgres.MetadataFilter{KeyPrefix: efficiencyTargetKeyPrefix})
+	if err != nil {
+		return nil, fmt.Errorf("list efficiency targets: %w", err)
+	}
+	resp := &dto.EfficiencyTargetListResponse{Items: []dto.EfficiencyTarget{}}
+	for _, m := range list {
+		if t, ok := toDTOEfficiencyTarget(m); ok {
+			resp.Items = append(resp.Items, t)
+		}
+	}
+	sort.Slice(resp.Items, func(i, j int) bool { return resp.Items[i].Namespace < resp.Items[j].Namespace })
+	resp.TotalEstimate = len(resp.Items)
+	return resp, nil
+}
+
+// EfficiencyTarget returns the target of namespace; ok is false when none is set.
+func (s *EfficiencyTargetService) EfficiencyTarget(ctx context.Context, namespace string) (target float64, ok bool, err error) {
+	m, err := s.repo.GetMetadata(ctx, efficiencyTargetKeyPrefix+namespace)
+	if errors.Is(err, dataerr.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get efficiency target of %s: %w", namespace, err)
+	}
+	t, ok := toDTOEfficiencyTarget(*m)
+	return t.Target, ok, nil
+}
+
+// Track compares the daily efficiency of namespace in start..end with its target (zero end: now,
+// zero start: 30 days before end).
+func (s *EfficiencyTargetService) Track(ctx context.Context, namespace string, start, end time.Time) (*dto.EfficiencyTargetTrackingResponse, error) {
+	if end.IsZero() {
+		end = s.now()
+	}
+	if start.IsZero() {
+		start = end.Add(-defaultTargetWindow)
+	}
+	if !end.After(start) {
+		return nil, ErrInvalidWindow
+	}
+	target, ok, err := s.EfficiencyTarget(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEfficiencyTargetNotFound, namespace)
+	}
+	points, err := dailyEfficiencyPoints(ctx, s.repo, namespace, s.calendar.Date(start), s.calendar.Date(end))
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.EfficiencyTargetTrackingResponse{
+		Namespace:   namespace,
+		WindowStart: start,
+		WindowEnd:   end,
+		Days:        make([]dto.EfficiencyTargetDay, 0, len(points)),
+		Attainment:  costmodel.TrackEfficiencyTarget(points, target),
+	}
+	for _, p := range points {
+		resp.Days = append(resp.Days, dto.EfficiencyTargetDay{Date: p.Date, Efficiency: p.Efficiency, Target: target, Met: p.Efficiency >= target})
+	}
+	return resp, nil
+}
+
+// dailyEfficiencyPoints returns the daily efficiency of namespace on the days labelled start..end, oldest first.
+func dailyEfficiencyPoints(ctx context.Context, repo postgres.Repository, namespace string, start, end time.Time) ([]costmodel.EfficiencyPoint, error) {
+	costs, err := repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{Namespace: namespace, StartDate: start, EndDate: end})
+	if err != nil {
+		return nil, fmt.Errorf("list daily costs of %s: %w", namespace, err)
+	}
+	points := make([]costmodel.EfficiencyPoint, 0, len(costs))
+	for _, c := range costs {
+		points = append(points, costmodel.EfficiencyPoint{Date: c.Date, Efficiency: costmodel.DailyEfficiency(c.BillableCost, c.UsageCost)})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	return points, nil
+}
+
+func toDTOEfficiencyTarget(m postgres.Metadata) (dto.EfficiencyTarget, bool) {
+	target, ok := m.Value["target"].(float64)
+	if !ok {
+		return dto.EfficiencyTarget{}, false
+	}
+	return dto.EfficiencyTarget{
+		Namespace: strings.TrimPrefix(m.Key, efficiencyTargetKeyPrefix),
+		Target:    target,
+		SetBy:     m.CreatedBy,
+		UpdatedAt: m.UpdatedAt,
+	}, true
+}
@@ -0,0 +1,504 @@
+// Package service timeline_service.go: 集群事件时间线。把 K8s 事件、配置变更、计算执行、单价变更与 SLO 违约合并为
+// 按时间排序的单一事件流，驾驶舱“成本突增前后发生了什么”视图只需一个数据源。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/eventbus"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+)
+
+// Timeline defaults: the window ending now and the number of latest events returned.
+const (
+	DefaultTimelineWindow = 24 * time.Hour
+	DefaultTimelineLimit  = 1000
+)
+
+// ErrInvalidTimelineKind is returned for an unknown kinds value.
+var ErrInvalidTimelineKind = dataerr.Validation("invalid timeline kind")
+
+// ErrInvalidTimelineRecord is returned for a config change or SLO violation that cannot be recorded.
+var ErrInvalidTimelineRecord = dataerr.Validation("invalid timeline record")
+
+// timelineKinds are the event kinds in the order their sources are read.
+var timelineKinds = []string{
+	dto.TimelineKindK8sEvent,
+	dto.TimelineKindConfigChange,
+	dto.TimelineKindCalculationRun,
+	dto.TimelineKindPriceChange,
+	dto.TimelineKindSLOViolation,
+}
+
+// sloViolationTypes are the violation types of slo.SLOViolationEvent.
+var sloViolationTypes = map[string]bool{"availability": true, "latency": true, "error_rate": true}
+
+// TimelineStore persists the config changes and SLO violations reported to the timeline
+// (config_change, slo_violation). *postgres.MockRepository satisfies this interface.
+type TimelineStore interface {
+	SaveConfigChange(ctx context.Context, change postgres.ConfigChange) error
+	ListConfigChanges(ctx context.Context, filter postgres.ConfigChangeFilter) ([]postgres.ConfigChange, error)
+	SaveSLOViolation(ctx context.Context, violation postgres.SLOViolation) error
+	ListSLOViolations(ctx context.Context, filter postgres.SLOViolationFilter) ([]postgres.SLOViolation, error)
+}
+
+// ParseTimelineKinds turns the comma-separated kinds query parameter into event kinds; empty
+// selects all kinds.
+func ParseTimelineKinds(kinds string) ([]string, error) {
+	if strings.TrimSpace(kinds) == "" {
+		return timelineKinds, nil
+	}
+	var out []string
+	for _, part := range strings.Split(kinds, ",") {
+		kind := strings.TrimSpace(part)
+		if kind == "" {
+			continue
+		}
+		known := false
+		for _, k := range timelineKinds {
+			known = known || k == kind
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: %s (want %s)", ErrInvalidTimelineKind, kind, strings.Join(timelineKinds, ", "))
+		}
+		out = append(out, kind)
+	}
+	return out, nil
+}
+
+// TimelineQuery selects the timeline of a namespace ("" for the whole cluster) over a window.
+type TimelineQuery struct {
+	Namespace          string
+	StartTime, EndTime time.Time // default: DefaultTimelineWindow ending now
+	Kinds              []string  // default: all kinds
+	Limit              int       // default DefaultTimelineLimit
+}
+
+// TimelineService merges cluster events into one ordered timeline. K8s events, calculation runs and
+// price history are optional; without them their kinds are left out.
+type TimelineService struct {
+	store  TimelineStore
+	events EventSource
+	runs   CalculationRunStore
+	prices PriceHistoryStore
+	deps   *DependencyService
+	pub    EventPublisher
+	now    func() time.Time
+}
+
+// NewTimelineService creates a TimelineService over the reported config changes and SLO violations.
+func NewTimelineService(store TimelineStore) *TimelineService {
+	return &TimelineService{store: store, now: time.Now}
+}
+
+// SetEventSource adds the K8s events of the namespace (of all namespaces for the cluster timeline).
+func (s *TimelineService) SetEventSource(events EventSource) {
+	s.events = events
+}
+
+// SetCalculationRuns adds calculation runs; runs scoped to other namespaces are left out of a
+// namespace timeline.
+func (s *TimelineService) SetCalculationRuns(runs CalculationRunStore) {
+	s.runs = runs
+}
+
+// SetPriceHistory adds price changes, the versions that took effect within the window.
+func (s *TimelineService) SetPriceHistory(prices PriceHistoryStore) {
+	s.prices = prices
+}
+
+// SetDependencies adds the dependency impact of the violated service to recorded violation starts,
+// the evidence of what else the violation affects.
+func (s *TimelineService) SetDependencies(deps *DependencyService) {
+	s.deps = deps
+}
+
+// SetPublisher publishes an eventbus.SLOViolated for every recorded violation start (not for recoveries).
+func (s *TimelineService) SetPublisher(pub EventPublisher) {
+	s.pub = pub
+}
+
+// Timeline returns the events of the selected kinds in the window, oldest first. A failing source
+// does not fail the timeline: its kind is listed in Unavailable.
+func (s *TimelineService) Timeline(ctx context.Context, q TimelineQuery) (*dto.TimelineResponse, error) {
+	if q.EndTime.IsZero() {
+		q.EndTime = s.now().UTC()
+	}
+	if q.StartTime.IsZero() {
+		q.StartTime = q.EndTime.Add(-DefaultTimelineWindow)
+	}
+	if !q.EndTime.After(q.StartTime) {
+		return nil, ErrInvalidWindow
+	}
+	if len(q.Kinds) == 0 {
+		q.Kinds = timelineKinds
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultTimelineLimit
+	}
+
+	resp := &dto.TimelineResponse{Namespace: q.Namespace, StartTime: q.StartTime, EndTime: q.EndTime, Events: []dto.TimelineEvent{}, Unavailable: []string{}}
+	for _, kind := range q.Kinds {
+		var events []dto.TimelineEvent
+		var err error
+		switch kind {
+		case dto.TimelineKindK8sEvent:
+			if s.events == nil {
+				continue
+			}
+			events, err = s.k8sEvents(ctx, q)
+		case dto.TimelineKindConfigChange:
+			events, err = s.configChanges(ctx, q)
+		case dto.TimelineKindCalculationRun:
+			if s.runs == nil {
+				continue
+			}
+			events, err = s.calculationRuns(ctx, q)
+		case dto.TimelineKindPriceChange:
+			if s.prices == nil {
+				continue
+			}
+			events, err = s.priceChanges(ctx, q)
+		case dto.TimelineKindSLOViolation:
+			events, err = s.sloViolations(ctx, q)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			resp.Unavailable = append(resp.Unavailable, kind)
+			continue
+		}
+		resp.Events = append(resp.Events, events...)
+	}
+
+	sort.SliceStable(resp.Events, func(i, j int) bool {
+		a, b := resp.Events[i], resp.Events[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Ref < b.Ref
+	})
+	if len(resp.Events) > q.Limit {
+		resp.Events = resp.Events[len(resp.Events)-q.Limit:]
+		resp.Truncated = true
+	}
+	resp.Total = len(resp.Events)
+	return resp, nil
+}
+
+// inWindow reports whether [start, end] overlaps the query window; a zero end is an instant.
+func (q TimelineQuery) inWindow(start, end time.Time) bool {
+	if end.IsZero() || end.Before(start) {
+		end = start
+	}
+	return !start.After(q.EndTime) && !end.Before(q.StartTime)
+}
+
+func (s *TimelineService) k8sEvents(ctx context.Context, q TimelineQuery) ([]dto.TimelineEvent, error) {
+	events, err := s.events.GetEvents(ctx, q.Namespace, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("get k8s events: %w", err)
+	}
+	var out []dto.TimelineEvent
+	for _, e := range events {
+		first, last := e.FirstTimestamp, e.LastTimestamp
+		if first.IsZero() {
+			first = last
+		}
+		if first.IsZero() || !q.inWindow(first, last) {
+			continue
+		}
+		ev := dto.TimelineEvent{
+			Kind:      dto.TimelineKindK8sEvent,
+			Time:      first,
+			Namespace: e.Namespace,
+			Severity:  string(notifier.SeverityInfo),
+			Title:     e.Reason,
+			Summary:   e.Message,
+			Object:    timelineObject(e.InvolvedObject.Kind, e.InvolvedObject.Name),
+			Ref:       e.Name,
+		}
+		if e.Type == "Warning" {
+			ev.Severity = string(notifier.SeverityWarning)
+		}
+		if last.After(first) {
+			end := last
+			ev.EndTime = &end
+		}
+		if e.Count > 1 {
+			ev.Summary = fmt.Sprintf("%s (x%d)", e.Message, e.Count)
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+func (s *TimelineService) configChanges(ctx context.Context, q TimelineQuery) ([]dto.TimelineEvent, error) {
+	changes, err := s.store.ListConfigChanges(ctx, postgres.ConfigChangeFilter{Namespace: q.Namespace, StartTime: q.StartTime, EndTime: q.EndTime})
+	if err != nil {
+		return nil, fmt.Errorf("list config changes: %w", err)
+	}
+	out := make([]dto.TimelineEvent, 0, len(changes))
+	for _, c := range changes {
+		var details []string
+		if c.OldValue != "" || c.NewValue != "" {
+			details = append(details, fmt.Sprintf("%s -> %s", c.OldValue, c.NewValue))
+		}
+		if c.Author != "" {
+			details = append(details, "by "+c.Author)
+		}
+		if c.Source != "" {
+			details = append(details, "via "+c.Source)
+		}
+		out = append(out, dto.TimelineEvent{
+			Kind:      dto.TimelineKindConfigChange,
+			Time:      c.OccurredAt,
+			Namespace: c.Namespace,
+			Severity:  string(notifier.SeverityInfo),
+			Title:     c.ChangeType,
+			Summary:   strings.Join(details, " "),
+			Object:    timelineObject(c.Kind, c.Name),
+			Ref:       c.ID,
+		})
+	}
+	return out, nil
+}
+
+func (s *TimelineService) calculationRuns(ctx context.Context, q TimelineQuery) ([]dto.TimelineEvent, error) {
+	runs, err := s.runs.ListCalculationRuns(ctx, postgres.CalculationRunFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list calculation runs: %w", err)
+	}
+	var out []dto.TimelineEvent
+	for _, r := range runs {
+		if !q.inWindow(r.StartedAt, r.FinishedAt) || !runCovers(r, q.Namespace) {
+			continue
+		}
+		ev := dto.TimelineEvent{
+			Kind:     dto.TimelineKindCalculationRun,
+			Time:     r.StartedAt,
+			Severity: string(notifier.SeverityInfo),
+			Title:    fmt.Sprintf("Calculation run %s", r.Status),
+			Summary: fmt.Sprintf("%s run of %s - %s, %d rows", r.Trigger,
+				r.WindowStart.UTC().Format(time.RFC3339), r.WindowEnd.UTC().Format(time.RFC3339), r.RowsWritten),
+			Ref: r.ID,
+		}
+		switch r.Status {
+		case postgres.CalculationRunFailed:
+			ev.Severity = string(notifier.SeverityCritical)
+		case postgres.CalculationRunPartial:
+			ev.Severity = string(notifier.SeverityWarning)
+		}
+		if r.Error != "" {
+			ev.Summary += ": " + r.Error
+		}
+		if r.FinishedAt.After(r.StartedAt) {
+			end := r.FinishedAt
+			ev.EndTime = &end
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+// runCovers reports whether run computed namespace: unscoped runs cover every namespace.
+func runCovers(run postgres.CalculationRun, namespace string) bool {
+	if namespace == "" || len(run.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range run.Scopes {
+		if scope == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *TimelineService) priceChanges(ctx context.Context, q TimelineQuery) ([]dto.TimelineEvent, error) {
+	versions, err := s.prices.ListPriceVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list price versions: %w", err)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].EffectiveFrom.Before(versions[j].EffectiveFrom) })
+	var out []dto.TimelineEvent
+	for i, v := range versions {
+		if !q.inWindow(v.EffectiveFrom, time.Time{}) {
+			continue
+		}
+		summary := fmt.Sprintf("CPU %.4g per core hour, memory %.4g per GiB hour", v.CPUPricePerCoreHour, v.MemPricePerGBHour)
+		if i > 0 {
+			prev := versions[i-1]
+			summary = fmt.Sprintf("CPU %.4g -> %.4g per core hour, memory %.4g -> %.4g per GiB hour",
+				prev.CPUPricePerCoreHour, v.CPUPricePerCoreHour, prev.MemPricePerGBHour, v.MemPricePerGBHour)
+		}
+		if v.Note != "" {
+			summary += ": " + v.Note
+		}
+		out = append(out, dto.TimelineEvent{
+			Kind:     dto.TimelineKindPriceChange,
+			Time:     v.EffectiveFrom,
+			Severity: string(notifier.SeverityInfo),
+			Title:    "Price change",
+			Summary:  summary,
+			Ref:      v.ID,
+		})
+	}
+	return out, nil
+}
+
+func (s *TimelineService) sloViolations(ctx context.Context, q TimelineQuery) ([]dto.TimelineEvent, error) {
+	violations, err := s.store.ListSLOViolations(ctx, postgres.SLOViolationFilter{Namespace: q.Namespace, StartTime: q.StartTime, EndTime: q.EndTime})
+	if err != nil {
+		return nil, fmt.Errorf("list SLO violations: %w", err)
+	}
+	out := make([]dto.TimelineEvent, 0, len(violations))
+	for _, v := range violations {
+		ev := dto.TimelineEvent{
+			Kind:      dto.TimelineKindSLOViolation,
+			Time:      v.ViolatedAt,
+			EndTime:   v.RecoveredAt,
+			Namespace: v.Namespace,
+			Severity:  v.Severity,
+			Title:     fmt.Sprintf("SLO %s violated", v.ViolationType),
+			Summary:   fmt.Sprintf("%.4g (threshold %.4g)", v.ActualValue, v.ThresholdValue),
+			Object:    timelineObject("Service", v.Service),
+			Ref:       v.ID,
+		}
+		if ev.Severity == "" {
+			ev.Severity = string(notifier.SeverityCritical)
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+func timelineObject(kind, name string) string {
+	switch {
+	case name == "":
+		return kind
+	case kind == "":
+		return name
+	}
+	return kind + "/" + name
+}
+
+// RecordConfigChange records a config change reported by a deploy pipeline.
+func (s *TimelineService) RecordConfigChange(ctx context.Context, req dto.ConfigChangeRequest) (*dto.ConfigChange, error) {
+	change := postgres.ConfigChange{
+		ID:         req.ID,
+		Namespace:  req.Namespace,
+		Kind:       req.Kind,
+		Name:       req.Name,
+		ChangeType: req.ChangeType,
+		OldValue:   req.OldValue,
+		NewValue:   req.NewValue,
+		Source:     req.Source,
+		Author:     req.Author,
+		OccurredAt: req.OccurredAt.UTC(),
+	}
+	if req.OccurredAt.IsZero() {
+		change.OccurredAt = s.now().UTC()
+	}
+	if change.ID == "" {
+		change.ID = uuid.New().String()
+	}
+	if err := s.store.SaveConfigChange(ctx, change); err != nil {
+		return nil, fmt.Errorf("save config change: %w", err)
+	}
+	return &dto.ConfigChange{
+		ID:         change.ID,
+		Namespace:  change.Namespace,
+		Kind:       change.Kind,
+		Name:       change.Name,
+		ChangeType: change.ChangeType,
+		OldValue:   change.OldValue,
+		NewValue:   change.NewValue,
+		Source:     change.Source,
+		Author:     change.Author,
+		OccurredAt: change.OccurredAt,
+	}, nil
+}
+
+// RecordSLOViolation records the start of an SLO violation, or its recovery when the ID of a
+// recorded violation is sent again with recovered_at. With dependencies set, the record of a start
+// carries the upstream services and spend depending on the violated service.
+func (s *TimelineService) RecordSLOViolation(ctx context.Context, req dto.SLOViolationRequest) (*dto.SLOViolationRecord, error) {
+	if !sloViolationTypes[req.ViolationType] {
+		return nil, fmt.Errorf("%w: violation_type must be availability, latency or error_rate", ErrInvalidTimelineRecord)
+	}
+	severity := req.Severity
+	switch severity {
+	case "":
+		severity = string(notifier.SeverityCritical)
+	case string(notifier.SeverityWarning), string(notifier.SeverityCritical):
+	default:
+		return nil, fmt.Errorf("%w: severity must be warning or critical", ErrInvalidTimelineRecord)
+	}
+	violation := postgres.SLOViolation{
+		ID:             req.ID,
+		Namespace:      req.Namespace,
+		Service:        req.Service,
+		ViolationType:  req.ViolationType,
+		ActualValue:    req.ActualValue,
+		ThresholdValue: req.ThresholdValue,
+		Severity:       severity,
+		ViolatedAt:     req.ViolatedAt.UTC(),
+	}
+	if req.ViolatedAt.IsZero() {
+		violation.ViolatedAt = s.now().UTC()
+	}
+	if req.RecoveredAt != nil {
+		if req.RecoveredAt.Before(violation.ViolatedAt) {
+			return nil, fmt.Errorf("%w: recovered_at is before violated_at", ErrInvalidTimelineRecord)
+		}
+		recovered := req.RecoveredAt.UTC()
+		violation.RecoveredAt = &recovered
+	}
+	if violation.ID == "" {
+		violation.ID = uuid.New().String()
+	}
+	if err := s.store.SaveSLOViolation(ctx, violation); err != nil {
+		return nil, fmt.Errorf("save SLO violation: %w", err)
+	}
+	if s.pub != nil && violation.RecoveredAt == nil {
+		// 违约已落库，outbox 满时仅丢失推送
+		_ = s.pub.Publish(eventbus.SLOViolated{
+			Namespace:      violation.Namespace,
+			Service:        violation.Service,
+			ViolationType:  violation.ViolationType,
+			ActualValue:    violation.ActualValue,
+			ThresholdValue: violation.ThresholdValue,
+			ViolationTime:  violation.ViolatedAt,
+		})
+	}
+	record := &dto.SLOViolationRecord{
+		ID:             violation.ID,
+		Namespace:      violation.Namespace,
+		Service:        violation.Service,
+		ViolationType:  violation.ViolationType,
+		ActualValue:    violation.ActualValue,
+		ThresholdValue: violation.ThresholdValue,
+		Severity:       violation.Severity,
+		ViolatedAt:     violation.ViolatedAt,
+		RecoveredAt:    violation.RecoveredAt,
+	}
+	if s.deps != nil && violation.RecoveredAt == nil {
+		// The violation is recorded even when the graph or the costs cannot be read.
+		if impact, err := s.deps.Impact(ctx, violation.Namespace, violation.Service, time.Time{}, violation.ViolatedAt); err == nil {
+			record.Impact = impact
+		}
+	}
+	return record, nil
+}
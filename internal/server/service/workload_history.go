@@ -0,0 +1,124 @@
+// Package service workload_history.go: 工作负载按天的成本与等级序列（最长一年），供迷你趋势图使用；
+// 读取日汇总，只有尚未降采样的最近几天才读取小时统计。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+const (
+	// DefaultWorkloadHistoryDays is the history length when none is requested.
+	DefaultWorkloadHistoryDays = 90
+	// MaxWorkloadHistoryDays caps the history at a year.
+	MaxWorkloadHistoryDays = 366
+)
+
+// ErrInvalidHistoryDays is returned for a history length outside 1..MaxWorkloadHistoryDays.
+var ErrInvalidHistoryDays = dataerr.Validation("invalid history days")
+
+// DailyWorkloadStatLister lists the daily rollups of downsampled hourly stats (cost_daily_workload).
+// *postgres.MockRepository satisfies this interface.
+type DailyWorkloadStatLister interface {
+	ListDailyWorkloadStats(ctx context.Context, filter postgres.DailyWorkloadStatFilter) ([]postgres.DailyWorkloadStat, error)
+}
+
+// SetDailyRollups makes workload history read the daily rollups; without it the history only
+// covers the hours still kept at full resolution.
+func (s *WorkloadService) SetDailyRollups(rollups DailyWorkloadStatLister) {
+	s.rollups = rollups
+}
+
+// GetWorkloadHistory returns the daily billable, usage and waste cost, efficiency and grade of a
+// workload over the last days UTC days (0: DefaultWorkloadHistoryDays), today included. Hourly
+// stats are only read from the latest rollup day on, folded into days like the downsampler does.
+func (s *WorkloadService) GetWorkloadHistory(ctx context.Context, namespace, name string, days int) (*dto.WorkloadHistoryResponse, error) {
+	if days == 0 {
+		days = DefaultWorkloadHistoryDays
+	}
+	if days < 0 || days > MaxWorkloadHistoryDays {
+		return nil, fmt.Errorf("%w: days must be in 1..%d", ErrInvalidHistoryDays, MaxWorkloadHistoryDays)
+	}
+	end := s.now().UTC()
+	start := end.Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+
+	byDate := make(map[time.Time]*postgres.DailyWorkloadStat)
+	hourlyFrom := start
+	if s.rollups != nil {
+		rollups, err := s.rollups.ListDailyWorkloadStats(ctx, postgres.DailyWorkloadStatFilter{
+			Namespace:    namespace,
+			WorkloadName: name,
+			StartTime:    start,
+			EndTime:      end,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range rollups {
+			d := rollups[i]
+			byDate[d.Date] = &d
+			// 降采样日之后才可能还有小时统计（含降采样后迟到的小时）
+			if !d.Date.Before(hourlyFrom) {
+				hourlyFrom = d.Date
+			}
+		}
+	}
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{
+		Namespace:    namespace,
+		WorkloadName: name,
+		StartTime:    hourlyFrom,
+		EndTime:      end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		hour := postgres.DailyWorkloadStatOf(st)
+		if d, ok := byDate[hour.Date]; ok {
+			d.Merge(hour)
+			continue
+		}
+		byDate[hour.Date] = &hour
+	}
+	if len(byDate) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrWorkloadNotFound, namespace, name)
+	}
+
+	var policy costmodel.CostPolicy
+	if s.policies != nil {
+		if policy, err = s.policies.PolicyFor(ctx, namespace, name); err != nil {
+			return nil, err
+		}
+	}
+	thresholds := policy.Thresholds()
+	resp := &dto.WorkloadHistoryResponse{
+		Namespace: namespace,
+		Name:      name,
+		Days:      days,
+		Start:     start,
+		End:       end,
+		Points:    make([]dto.WorkloadHistoryPoint, 0, len(byDate)),
+	}
+	for date, d := range byDate {
+		p := dto.WorkloadHistoryPoint{
+			Date:     date,
+			Billable: d.TotalBillableCost,
+			Usage:    d.TotalUsageCost,
+			Waste:    d.TotalWasteCost,
+		}
+		if p.Billable > 0 {
+			p.Efficiency = p.Usage / p.Billable * 100
+			p.Grade = string(thresholds.Grade(p.Efficiency))
+		}
+		resp.Points = append(resp.Points, p)
+	}
+	sort.Slice(resp.Points, func(i, j int) bool { return resp.Points[i].Date.Before(resp.Points[j].Date) })
+	return resp, nil
+}
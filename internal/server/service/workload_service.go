@@ -0,0 +1,260 @@
+// Package service workload_service.go: 单个工作负载的成本详情（小时序列、请求 vs P95、等级历史与优化建议）。
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/server/dto"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// ErrWorkloadNotFound is returned when a workload has no hourly stats in the window.
+var ErrWorkloadNotFound = dataerr.NotFound("workload not found")
+
+// CostPolicyResolver resolves the effective cost policy of a workload. *k8s.CostPolicyResolver
+// satisfies this interface.
+type CostPolicyResolver interface {
+	PolicyFor(ctx context.Context, namespace, workload string) (costmodel.CostPolicy, error)
+}
+
+// EventSource lists Kubernetes events of a resource. k8s.Client satisfies this interface.
+type EventSource interface {
+	GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]k8s.Event, error)
+}
+
+// WorkloadService assembles workload cost details from hourly workload stats.
+type WorkloadService struct {
+	repo     postgres.Repository
+	grades   GradeHistoryStore
+	policies CostPolicyResolver
+	events   EventSource
+	slo      SLOStatusProvider
+	safety   *RightsizingSafety
+	rollups  DailyWorkloadStatLister
+	now      func() time.Time
+}
+
+// NewWorkloadService creates a WorkloadService.
+func NewWorkloadService(repo postgres.Repository) *WorkloadService {
+	return &WorkloadService{repo: repo, now: time.Now}
+}
+
+// SetGradeHistory enables grade history in workload details; without it the current grade is
+// derived from the window's efficiency alone.
+func (s *WorkloadService) SetGradeHistory(grades GradeHistoryStore) {
+	s.grades = grades
+}
+
+// SetCostPolicies makes workload details honor cost policy annotations: grade thresholds
+// overrides and suppression of downsize/decommission for workloads excluded from waste.
+func (s *WorkloadService) SetCostPolicies(policies CostPolicyResolver) {
+	s.policies = policies
+}
+
+// SetReliabilitySources enables the reliability tax in workload details: restarts are counted from
+// the eviction, OOM-kill and crash-loop events of events, and slo (optional) adds the SLO status of
+// the service named like the workload.
+func (s *WorkloadService) SetReliabilitySources(events EventSource, slo SLOStatusProvider) {
+	s.events, s.slo = events, slo
+}
+
+// SetRightsizingSafety makes workload details check reduction recommendations against recent CPU
+// throttling and the SLO before proposing them; suppressed ones are listed apart.
+func (s *WorkloadService) SetRightsizingSafety(safety *RightsizingSafety) {
+	s.safety = safety
+}
+
+// GetWorkloadCost returns the cost detail of one workload for start..end (default: the last 7 days).
+func (s *WorkloadService) GetWorkloadCost(ctx context.Context, namespace, name string, start, end time.Time) (*dto.WorkloadCostDetailResponse, error) {
+	if end.IsZero() {
+		end = s.now()
+	}
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -7)
+	}
+	if !end.After(start) {
+		return nil, ErrInvalidWindow
+	}
+	stats, err := s.repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{
+		Namespace:    namespace,
+		WorkloadName: name,
+		StartTime:    start,
+		EndTime:      end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrWorkloadNotFound, namespace, name)
+	}
+
+	resp := &dto.WorkloadCostDetailResponse{
+		Namespace:       namespace,
+		Name:            name,
+		WindowStart:     start,
+		WindowEnd:       end,
+		GradeHistory:    []dto.GradeChangeEvent{},
+		Recommendations: []costmodel.Recommendation{},
+		Timestamp:       time.Now().UTC(),
+	}
+
+	// 同一小时可能有多个 pod 的记录，按小时合并
+	hours := make(map[time.Time]*dto.GranularCostDataPoint)
+	var latest time.Time
+	for _, st := range stats {
+		if resp.Type == "" {
+			resp.Type = st.WorkloadType
+		}
+		hour := st.Timestamp.UTC().Truncate(time.Hour)
+		p, ok := hours[hour]
+		if !ok {
+			p = &dto.GranularCostDataPoint{Timestamp: hour}
+			hours[hour] = p
+		}
+		p.Cost += st.TotalBillableCost
+		p.Usage += st.TotalUsageCost
+		p.Waste += st.TotalWasteCost
+		if hour.After(latest) {
+			latest = hour
+		}
+
+		resp.Cost.CPU += st.CPUBillableCost
+		resp.Cost.Memory += st.MemBillableCost
+		resp.Cost.Billable += st.TotalBillableCost
+		resp.Cost.Usage += st.TotalUsageCost
+		resp.Cost.Waste += st.TotalWasteCost
+	}
+	resp.Cost.Total = resp.Cost.Billable
+	if resp.Cost.Billable > 0 {
+		resp.Cost.Efficiency = resp.Cost.Usage / resp.Cost.Billable * 100
+	}
+	resp.Series = make([]dto.GranularCostDataPoint, 0, len(hours))
+	for _, p := range hours {
+		resp.Series = append(resp.Series, *p)
+	}
+	sort.Slice(resp.Series, func(i, j int) bool { return resp.Series[i].Timestamp.Before(resp.Series[j].Timestamp) })
+
+	usage := costmodel.WorkloadUsage{}
+	resp.Resources.Timestamp = latest
+	var pods []string
+	for _, st := range stats {
+		if !st.Timestamp.UTC().Truncate(time.Hour).Equal(latest) {
+			continue
+		}
+		if st.PodName != "" {
+			pods = append(pods, st.PodName)
+		}
+		resp.Resources.CPURequest += st.CPURequest
+		resp.Resources.CPUUsageP95 += st.CPUUsageP95
+		resp.Resources.MemRequest += st.MemRequest
+		resp.Resources.MemUsageP95 += st.MemUsageP95
+		usage.CPUHourlyCost += st.CPUBillableCost
+		usage.MemHourlyCost += st.MemBillableCost
+	}
+	if resp.Resources.CPURequest > 0 {
+		resp.Resources.CPUUtilization = resp.Resources.CPUUsageP95 / resp.Resources.CPURequest * 100
+	}
+	if resp.Resources.MemRequest > 0 {
+		resp.Resources.MemUtilization = float64(resp.Resources.MemUsageP95) / float64(resp.Resources.MemRequest) * 100
+	}
+
+	if s.grades != nil {
+		events, err := s.grades.ListGradeChangeEvents(ctx, postgres.GradeChangeEventFilter{Namespace: namespace, WorkloadName: name})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			resp.GradeHistory = append(resp.GradeHistory, toDTOGradeChangeEvent(e))
+		}
+	}
+	var policy costmodel.CostPolicy
+	if s.policies != nil {
+		policy, err = s.policies.PolicyFor(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		resp.CostPolicy = &policy
+	}
+	if n := len(resp.GradeHistory); n > 0 {
+		resp.Grade = resp.GradeHistory[n-1].ToGrade
+	} else if resp.Cost.Billable > 0 {
+		resp.Grade = string(policy.Thresholds().Grade(resp.Cost.Efficiency))
+	}
+
+	if s.events != nil {
+		if resp.ReliabilityTax, err = s.reliabilityTax(ctx, namespace, name, resp.Type, start, end, resp.Cost.Billable, len(stats)); err != nil {
+			return nil, err
+		}
+	}
+
+	usage.CPURequest = resp.Resources.CPURequest
+	usage.CPUUsageP95 = resp.Resources.CPUUsageP95
+	usage.MemRequest = resp.Resources.MemRequest
+	usage.MemUsageP95 = resp.Resources.MemUsageP95
+	recs := costmodel.RecommendWithPolicy(costmodel.EfficiencyGrade(resp.Grade), usage, policy)
+	if s.safety != nil {
+		if recs, resp.SuppressedRecommendations, err = s.safety.Check(ctx, namespace, name, pods, recs); err != nil {
+			return nil, err
+		}
+	}
+	if len(recs) > 0 {
+		resp.Recommendations = recs
+	}
+	return resp, nil
+}
+
+// reliabilityTax prices the restarts of the workload in start..end. Every hourly stat is one
+// pod-hour, so billable/podHours is the average cost of a pod-hour; an aggregated event counts
+// Count times when it was last seen in the window.
+func (s *WorkloadService) reliabilityTax(ctx context.Context, namespace, name, kind string, start, end time.Time, billable float64, podHours int) (*dto.WorkloadReliabilityTax, error) {
+	events, err := s.events.GetEvents(ctx, namespace, kind, name)
+	if err != nil {
+		return nil, fmt.Errorf("list events of %s/%s: %w", namespace, name, err)
+	}
+	restarts := make(map[costmodel.RestartCause]int)
+	for _, e := range events {
+		cause, ok := costmodel.RestartCauseOf(e.Reason)
+		if !ok || e.LastTimestamp.Before(start) || e.LastTimestamp.After(end) {
+			continue
+		}
+		n := int(e.Count)
+		if n < 1 {
+			n = 1
+		}
+		restarts[cause] += n
+	}
+	var podHourly float64
+	if podHours > 0 {
+		podHourly = billable / float64(podHours)
+	}
+	tax := costmodel.ComputeReliabilityTax(restarts, podHourly, billable, nil)
+	out := &dto.WorkloadReliabilityTax{
+		Restarts:      make(map[string]int, len(tax.Restarts)),
+		WastedCost:    tax.WastedCost,
+		Share:         tax.Share,
+		PodHourlyCost: roundCost(podHourly),
+	}
+	for cause, n := range tax.Restarts {
+		out.Restarts[string(cause)] = n
+	}
+	if s.slo != nil {
+		services, err := s.slo.ListSLOStatus(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list SLO status: %w", err)
+		}
+		for _, svc := range services {
+			if svc.Namespace == namespace && svc.Service == name {
+				budget := svc.ErrorBudgetRemaining
+				out.SLOStatus, out.ErrorBudgetRemaining = string(svc.Status), &budget
+				break
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,124 @@
+// Package etl provides ETL workers for hourly and daily aggregation.
+// bill_worker.go: cloud bill ingestion into cost_bill_account_summary plus per-node pricing overrides.
+package etl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/cloudbilling"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// BillSummaryStore persists account-level bill summaries (cost_bill_account_summary).
+// *postgres.MockRepository satisfies this interface.
+type BillSummaryStore interface {
+	SaveBillAccountSummary(ctx context.Context, s postgres.BillAccountSummary) error
+}
+
+// NodePricingMapper maps instance bills onto cluster nodes, e.g. aliyun.BuildNodePricingOverrides.
+type NodePricingMapper func(bills []cloudbilling.InstanceBill, nodes []k8s.Node, cpuPricePerCoreHour, memPricePerGBHour float64) ([]cloudbilling.NodePricingOverride, error)
+
+// BillIngestWorker pulls a billing cycle from the cloud provider, stores the account summary
+// and derives node-level pricing overrides for the cost model.
+type BillIngestWorker struct {
+	Fetcher   cloudbilling.CloudBillingFetcher
+	K8s       k8s.Client
+	Store     BillSummaryStore
+	MapNodes  NodePricingMapper
+	AccountID string
+
+	// Global prices from Business.CostCalculation, used to split instance cost into CPU/memory.
+	CPUPricePerCoreHour float64
+	MemPricePerGBHour   float64
+
+	// PeriodType of the billing cycles Job ingests: "day" or "month" (default).
+	PeriodType string
+
+	now func() time.Time
+}
+
+// BillIngestResult is the outcome of one ingestion run.
+type BillIngestResult struct {
+	Summary   postgres.BillAccountSummary        `json:"summary"`
+	Overrides []cloudbilling.NodePricingOverride `json:"overrides"`
+}
+
+// Run ingests the given billing cycle ("2025-01" or "2025-01-01").
+// Node overrides are only computed when the fetcher supports instance bills and K8s/MapNodes are set.
+func (w *BillIngestWorker) Run(ctx context.Context, billingCycle string) (*BillIngestResult, error) {
+	if w.Fetcher == nil {
+		return nil, fmt.Errorf("bill ingest: no cloud billing fetcher configured")
+	}
+
+	resp, err := w.Fetcher.FetchAccountSummary(ctx, cloudbilling.FetchAccountSummaryRequest{BillingCycle: billingCycle})
+	if err != nil {
+		return nil, fmt.Errorf("bill ingest: fetch account summary: %w", err)
+	}
+	summary, err := cloudbilling.ToBillAccountSummary(w.AccountID, resp)
+	if err != nil {
+		return nil, fmt.Errorf("bill ingest: %w", err)
+	}
+	if w.Store != nil {
+		if err := w.Store.SaveBillAccountSummary(ctx, summary); err != nil {
+			return nil, fmt.Errorf("bill ingest: save account summary: %w", err)
+		}
+	}
+
+	result := &BillIngestResult{Summary: summary}
+
+	instanceFetcher, ok := w.Fetcher.(cloudbilling.InstanceBillFetcher)
+	if !ok || w.K8s == nil || w.MapNodes == nil {
+		return result, nil
+	}
+	bills, err := instanceFetcher.FetchInstanceBills(ctx, cloudbilling.FetchInstanceBillsRequest{BillingCycle: billingCycle})
+	if err != nil {
+		return nil, fmt.Errorf("bill ingest: fetch instance bills: %w", err)
+	}
+	nodes, err := w.K8s.GetNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("bill ingest: list nodes: %w", err)
+	}
+	result.Overrides, err = w.MapNodes(bills, nodes, w.CPUPricePerCoreHour, w.MemPricePerGBHour)
+	if err != nil {
+		return nil, fmt.Errorf("bill ingest: map node pricing: %w", err)
+	}
+	return result, nil
+}
+
+// Job returns bill ingestion as a scheduler job (daily by default). Each run ingests the current
+// billing cycle and the previous one, whose bill keeps changing until the provider settles it;
+// summaries are upserted per account period, so re-ingesting a cycle replaces its figures.
+func (w *BillIngestWorker) Job(interval time.Duration) Job {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return Job{
+		Name:     "cloud-bill-ingest",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			for _, cycle := range w.cycles() {
+				if _, err := w.Run(ctx, cycle); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// cycles returns the previous and the current billing cycle (UTC), oldest first.
+func (w *BillIngestWorker) cycles() []string {
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+	t := now().UTC()
+	if w.PeriodType == "day" {
+		return []string{t.AddDate(0, 0, -1).Format("2006-01-02"), t.Format("2006-01-02")}
+	}
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return []string{month.AddDate(0, -1, 0).Format("2006-01"), month.Format("2006-01")}
+}
@@ -0,0 +1,81 @@
+package etl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/cloudbilling"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+type fakeBillFetcher struct{}
+
+func (fakeBillFetcher) FetchAccountSummary(ctx context.Context, req cloudbilling.FetchAccountSummaryRequest) (*cloudbilling.FetchAccountSummaryResponse, error) {
+	return &cloudbilling.FetchAccountSummaryResponse{
+		BillingCycle: req.BillingCycle,
+		TotalAmount:  1000,
+		Currency:     "CNY",
+		ByCategory:   map[string]float64{"compute": 800, "storage": 200},
+	}, nil
+}
+
+func (fakeBillFetcher) FetchInstanceBills(ctx context.Context, req cloudbilling.FetchInstanceBillsRequest) ([]cloudbilling.InstanceBill, error) {
+	return []cloudbilling.InstanceBill{{InstanceID: "node-1", BillingCycle: req.BillingCycle, Amount: 744, Category: "compute"}}, nil
+}
+
+func TestBillIngestWorker_Run(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	var mapped int
+	w := &BillIngestWorker{
+		Fetcher:   fakeBillFetcher{},
+		K8s:       k8s.NewMockClient(k8s.MockConfig{Nodes: []string{"node-1"}, RandomSeed: 1}),
+		Store:     repo,
+		AccountID: "acct-1",
+		MapNodes: func(bills []cloudbilling.InstanceBill, nodes []k8s.Node, cpu, mem float64) ([]cloudbilling.NodePricingOverride, error) {
+			mapped = len(bills)
+			return []cloudbilling.NodePricingOverride{{NodeName: nodes[0].Name}}, nil
+		},
+		CPUPricePerCoreHour: 0.025,
+		MemPricePerGBHour:   0.01,
+	}
+
+	result, err := w.Run(ctx, "2025-01")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if mapped != 1 || len(result.Overrides) != 1 {
+		t.Errorf("expected instance bills to be mapped to 1 override, got %d", len(result.Overrides))
+	}
+
+	saved, err := repo.GetBillAccountSummary(ctx, "acct-1", "month", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("summary not stored: %v", err)
+	}
+	if saved.TotalAmount != 1000 {
+		t.Errorf("TotalAmount = %v, want 1000", saved.TotalAmount)
+	}
+}
+
+func TestBillIngestWorker_Job(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	w := &BillIngestWorker{Fetcher: fakeBillFetcher{}, Store: repo, AccountID: "acct-1",
+		now: func() time.Time { return time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC) }}
+	if err := w.Job(0).Run(ctx); err != nil {
+		t.Fatalf("Job.Run() error = %v", err)
+	}
+	// 跨年：上一账期为 2024-12
+	for _, start := range []time.Time{time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)} {
+		if _, err := repo.GetBillAccountSummary(ctx, "acct-1", "month", start); err != nil {
+			t.Errorf("summary of %s not stored: %v", start.Format("2006-01"), err)
+		}
+	}
+
+	w.PeriodType = "day"
+	if got := w.cycles(); got[0] != "2025-01-02" || got[1] != "2025-01-03" {
+		t.Errorf("daily cycles = %v", got)
+	}
+}
@@ -0,0 +1,356 @@
+// Package etl provides ETL workers for hourly and daily aggregation.
+// canary.go: 管道合成监控。定期把一条合成指标经小时级 ETL 计算写入存储，再通过 HTTP API 读回并与预期成本比对，
+// 导出端到端延迟与正确性，并在失败时告警，在用户看到过期看板之前发现集成故障。
+package etl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/pkg/client"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// The canary workload. Its stats are written at CanaryHour, outside every dashboard window, so they
+// never show up in cost views; the value changes on every check so a stale read-back is detected.
+const (
+	CanaryNamespace = "lighthouse-canary"
+	CanaryWorkload  = "pipeline-canary"
+)
+
+// CanaryHour is the hour the canary stats are written at.
+var CanaryHour = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Canary stages, in pipeline order.
+const (
+	CanaryStageCalculate = "calculate" // synthetic metric -> hourly ETL -> repository
+	CanaryStageReadBack  = "read_back" // API read-back and comparison
+)
+
+// DefaultCanaryInterval is the default time between two canary checks.
+const DefaultCanaryInterval = 5 * time.Minute
+
+// canaryTolerance is the largest difference between expected and read-back cost (costs are rounded
+// to 6 decimals by CalculateCost).
+const canaryTolerance = 1e-5
+
+// CanaryNotifier sends canary alerts. *notifier.Dispatcher satisfies this interface.
+type CanaryNotifier interface {
+	Notify(ctx context.Context, alert notifier.Alert) error
+}
+
+// CanaryResult is the outcome of one canary check.
+type CanaryResult struct {
+	At      time.Time
+	Latency time.Duration            // end to end
+	Stages  map[string]time.Duration // by CanaryStage*; a failed stage and the stages after it are missing
+	// Stage is the failed stage and Err its error; both are empty when the check passed.
+	Stage string
+	Err   error
+	// SlowerThan is set when the check passed but took longer than PipelineCanary.MaxLatency.
+	SlowerThan time.Duration
+}
+
+// OK reports whether the check passed within the latency limit.
+func (r CanaryResult) OK() bool {
+	return r.Err == nil && r.SlowerThan == 0
+}
+
+// PipelineCanary runs a synthetic end-to-end check of the cost pipeline: a fake metric of the canary
+// workload is priced by HourlyWorker, saved to Store and read back through the API, and the
+// read-back cost must equal the cost of the metric. Safe for concurrent use.
+type PipelineCanary struct {
+	Store HourlyStatStore
+	// API reads the canary workload back (GET /workloads/{namespace}/{name}/costs).
+	API *client.Client
+
+	// Prices and calculator of the hourly worker (Business.CostCalculation).
+	CPUPricePerCoreHour float64
+	MemPricePerGBHour   float64
+	Calculator          costmodel.CostCalculator
+
+	// MaxLatency, when > 0, fails checks taking longer end to end.
+	MaxLatency time.Duration
+	// Notifier, when set, is alerted once per failure episode; without it failures are only metrics.
+	Notifier CanaryNotifier
+
+	now func() time.Time
+
+	mu          sync.Mutex
+	last        *CanaryResult
+	lastSuccess time.Time
+	runs        int
+	failures    int
+	alerts      int
+	alerted     bool // notified in the current failure episode
+}
+
+// Check runs one canary check, records it and notifies when the pipeline starts failing. The
+// returned error is the notification error; the check outcome is in the result.
+func (c *PipelineCanary) Check(ctx context.Context) (CanaryResult, error) {
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	start := now()
+	r := CanaryResult{At: start.UTC(), Stages: make(map[string]time.Duration, 2)}
+	metric := canaryMetric(start)
+
+	stageStart := start
+	stage := func(name string, err error) bool {
+		if err != nil {
+			r.Stage, r.Err = name, err
+			return false
+		}
+		t := now()
+		r.Stages[name] = t.Sub(stageStart)
+		stageStart = t
+		return true
+	}
+	want, err := c.calculate(ctx, metric)
+	if stage(CanaryStageCalculate, err) {
+		stage(CanaryStageReadBack, c.readBack(ctx, want))
+	}
+	r.Latency = now().Sub(start)
+	if r.Err == nil && c.MaxLatency > 0 && r.Latency > c.MaxLatency {
+		r.SlowerThan = c.MaxLatency
+	}
+	return r, c.record(ctx, r)
+}
+
+// canaryMetric returns the synthetic metric of a check at t: a 2-core / 4 GiB request whose usage
+// varies with t, so every check writes a different cost.
+func canaryMetric(t time.Time) costmodel.ResourceMetric {
+	frac := float64(t.UnixNano()%1e9) / 1e9
+	return costmodel.ResourceMetric{
+		Timestamp:   CanaryHour,
+		CPURequest:  2,
+		CPUUsageP95: 0.2 + 1.6*frac,
+		MemRequest:  4 << 30,
+		MemUsageP95: int64((1 + 2*frac) * (1 << 30)),
+	}
+}
+
+// calculate runs the hourly worker over the canary hour with metric as the only Prometheus sample
+// and returns the cost the read-back must show.
+func (c *PipelineCanary) calculate(ctx context.Context, metric costmodel.ResourceMetric) (costmodel.CostResult, error) {
+	want, err := costmodel.CalculateWithPolicy(c.Calculator, metric, c.CPUPricePerCoreHour, c.MemPricePerGBHour, costmodel.CostPolicy{})
+	if err != nil {
+		return want, fmt.Errorf("calculate expected cost: %w", err)
+	}
+	source := canarySource{metric: metric}
+	worker := &HourlyWorker{
+		K8s:                 source,
+		Prometheus:          source,
+		Store:               c.Store,
+		CPUPricePerCoreHour: c.CPUPricePerCoreHour,
+		MemPricePerGBHour:   c.MemPricePerGBHour,
+		Calculator:          c.Calculator,
+	}
+	rows, err := worker.Run(ctx, postgres.CalculationRun{WindowStart: CanaryHour, WindowEnd: CanaryHour.Add(time.Hour)})
+	if err != nil {
+		return want, err
+	}
+	if rows != 1 {
+		return want, fmt.Errorf("hourly etl wrote %d canary rows, want 1", rows)
+	}
+	return want, nil
+}
+
+// readBack reads the canary workload through the API and compares its cost with want.
+func (c *PipelineCanary) readBack(ctx context.Context, want costmodel.CostResult) error {
+	if c.API == nil {
+		return errors.New("no API client configured")
+	}
+	got, err := c.API.WorkloadCost(ctx, CanaryNamespace, CanaryWorkload, client.WorkloadCostParams{StartTime: CanaryHour, EndTime: CanaryHour.Add(time.Hour)})
+	if err != nil {
+		return err
+	}
+	for _, v := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"billable", got.Cost.Billable, want.TotalBillableCost},
+		{"usage", got.Cost.Usage, want.TotalUsageCost},
+		{"waste", got.Cost.Waste, want.TotalWasteCost},
+	} {
+		if math.Abs(v.got-v.want) > canaryTolerance {
+			return fmt.Errorf("read-back %s cost %v, want %v", v.name, v.got, v.want)
+		}
+	}
+	return nil
+}
+
+// record stores r and notifies on the first failed check of an episode. A failed notification is
+// retried on the next failed check.
+func (c *PipelineCanary) record(ctx context.Context, r CanaryResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = &r
+	c.runs++
+	if r.OK() {
+		c.lastSuccess = r.At
+		c.alerted = false
+		return nil
+	}
+	c.failures++
+	if c.alerted || c.Notifier == nil {
+		return nil
+	}
+	if err := c.Notifier.Notify(ctx, c.alertOf(r)); err != nil && !errors.Is(err, notifier.ErrSilenced) {
+		return fmt.Errorf("notify canary failure: %w", err)
+	}
+	c.alerted = true
+	c.alerts++
+	return nil
+}
+
+func (c *PipelineCanary) alertOf(r CanaryResult) notifier.Alert {
+	summary := fmt.Sprintf("pipeline check took %s, more than %s", r.Latency.Truncate(time.Millisecond), r.SlowerThan)
+	fields := []notifier.Field{{Name: "latency", Value: r.Latency.Truncate(time.Millisecond).String()}}
+	if r.Err != nil {
+		summary = fmt.Sprintf("%s stage failed: %v", r.Stage, r.Err)
+		fields = append(fields, notifier.Field{Name: "stage", Value: r.Stage})
+	}
+	if !c.lastSuccess.IsZero() {
+		fields = append(fields, notifier.Field{Name: "last success", Value: c.lastSuccess.Format(time.RFC3339)})
+	}
+	return notifier.Alert{
+		Type:      notifier.AlertTypePipelineCanary,
+		Severity:  notifier.SeverityCritical,
+		Title:     "cost pipeline canary failed",
+		Summary:   summary,
+		Fields:    fields,
+		DedupKey:  "pipeline-canary",
+		Timestamp: r.At,
+	}
+}
+
+// CanaryStats are the counters of a PipelineCanary since start.
+type CanaryStats struct {
+	Last        *CanaryResult // nil before the first check
+	LastSuccess time.Time     // zero before the first passed check
+	Runs        int
+	Failures    int
+	Alerts      int
+}
+
+// Stats returns the last result and the counters.
+func (c *PipelineCanary) Stats() CanaryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := CanaryStats{LastSuccess: c.lastSuccess, Runs: c.runs, Failures: c.failures, Alerts: c.alerts}
+	if c.last != nil {
+		last := *c.last
+		s.Last = &last
+	}
+	return s
+}
+
+// Run checks every tick (default DefaultCanaryInterval) until ctx is cancelled.
+func (c *PipelineCanary) Run(ctx context.Context, tick time.Duration, onError func(error)) {
+	if tick <= 0 {
+		tick = DefaultCanaryInterval
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		if _, err := c.Check(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Job returns the check as a scheduler job (default DefaultCanaryInterval). Failed checks are
+// recorded by the canary itself; only a notification error fails the job.
+func (c *PipelineCanary) Job(interval time.Duration) Job {
+	if interval <= 0 {
+		interval = DefaultCanaryInterval
+	}
+	return Job{
+		Name:     "pipeline-canary",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, err := c.Check(ctx)
+			return err
+		},
+	}
+}
+
+// canarySource is the K8s and Prometheus source of the canary: one namespace with one deployment
+// whose only sample is metric.
+type canarySource struct {
+	metric costmodel.ResourceMetric
+}
+
+var (
+	_ k8s.Client        = canarySource{}
+	_ prometheus.Client = canarySource{}
+)
+
+func (s canarySource) GetNamespaces(ctx context.Context) ([]k8s.Namespace, error) {
+	return []k8s.Namespace{{Name: CanaryNamespace, Status: "Active"}}, nil
+}
+
+func (s canarySource) GetDeployments(ctx context.Context, namespace string) ([]k8s.Deployment, error) {
+	if namespace != CanaryNamespace {
+		return nil, nil
+	}
+	return []k8s.Deployment{{Name: CanaryWorkload, Namespace: CanaryNamespace, Replicas: 1, AvailableReplicas: 1}}, nil
+}
+
+func (s canarySource) GetPods(ctx context.Context, namespace, deployment string) ([]k8s.Pod, error) {
+	return nil, nil
+}
+
+func (s canarySource) GetNodes(ctx context.Context) ([]k8s.Node, error) {
+	return nil, nil
+}
+
+func (s canarySource) GetEvents(ctx context.Context, namespace, resourceType, resourceName string) ([]k8s.Event, error) {
+	return nil, nil
+}
+
+func (s canarySource) GetResourceQuotas(ctx context.Context, namespace string) ([]k8s.ResourceQuota, error) {
+	return nil, nil
+}
+
+func (s canarySource) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	if namespace != CanaryNamespace || workload != CanaryWorkload {
+		return nil, nil
+	}
+	return []costmodel.ResourceMetric{s.metric}, nil
+}
+
+func (s canarySource) GetNodeMetrics(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	return nil, nil
+}
+
+func (s canarySource) GetClusterMetrics(ctx context.Context, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	return nil, nil
+}
+
+func (s canarySource) GetThrottlingMetrics(ctx context.Context, namespace, pod string, startTime, endTime time.Time) ([]prometheus.ThrottlingMetric, error) {
+	return nil, nil
+}
+
+func (s canarySource) GetSaturationMetrics(ctx context.Context, resourceType string, startTime, endTime time.Time) ([]prometheus.SaturationMetric, error) {
+	return nil, nil
+}
+
+func (s canarySource) HealthCheck(ctx context.Context) error {
+	return nil
+}
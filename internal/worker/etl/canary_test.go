@@ -0,0 +1,90 @@
+package etl
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/config"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/internal/server"
+	"github.com/myxxhui/lighthouse-src/internal/server/service"
+	"github.com/myxxhui/lighthouse-src/pkg/client"
+)
+
+// droppingStore acknowledges writes without saving them, like a lost write.
+type droppingStore struct{}
+
+func (droppingStore) SaveHourlyWorkloadStat(ctx context.Context, stat postgres.HourlyWorkloadStat) error {
+	return nil
+}
+
+type recordingNotifier struct{ alerts []notifier.Alert }
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert notifier.Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestPipelineCanary(t *testing.T) {
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	srv := server.NewHTTPServer(&config.Config{Env: config.EnvDevelopment}, service.NewCostService(repo))
+	srv.SetWorkloadService(service.NewWorkloadService(repo))
+	api := httptest.NewServer(srv.Engine())
+	defer api.Close()
+
+	notify := &recordingNotifier{}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 100_000_000, time.UTC)
+	canary := &PipelineCanary{
+		Store:               repo,
+		API:                 client.New(api.URL),
+		CPUPricePerCoreHour: 0.05,
+		MemPricePerGBHour:   0.01,
+		Notifier:            notify,
+		now: func() time.Time {
+			now = now.Add(10 * time.Millisecond)
+			return now
+		},
+	}
+	ctx := context.Background()
+
+	r, err := canary.Check(ctx)
+	if err != nil || !r.OK() {
+		t.Fatalf("Check = %+v, %v; want a passed check", r, err)
+	}
+	if len(r.Stages) != 2 || r.Latency != 30*time.Millisecond {
+		t.Errorf("stages %v, latency %v", r.Stages, r.Latency)
+	}
+
+	// 写入丢失：API 读回的是上一次检查的成本
+	canary.Store = droppingStore{}
+	for i := 0; i < 2; i++ {
+		r, err = canary.Check(ctx)
+		if err != nil || r.OK() || r.Stage != CanaryStageReadBack || !strings.Contains(r.Err.Error(), "read-back usage cost") {
+			t.Fatalf("lost write: Check = %+v, %v; want a read-back failure", r, err)
+		}
+	}
+	if len(notify.alerts) != 1 || notify.alerts[0].Type != notifier.AlertTypePipelineCanary {
+		t.Errorf("alerts = %+v, want one pipeline_canary alert per failure episode", notify.alerts)
+	}
+
+	canary.Store = repo
+	canary.MaxLatency = 20 * time.Millisecond
+	if r, _ = canary.Check(ctx); r.Err != nil || r.OK() || r.SlowerThan != 20*time.Millisecond {
+		t.Errorf("slow check = %+v, want a latency failure", r)
+	}
+	canary.MaxLatency = 0
+	if r, _ = canary.Check(ctx); !r.OK() {
+		t.Errorf("recovered check = %+v", r)
+	}
+	stats := canary.Stats()
+	if stats.Runs != 5 || stats.Failures != 3 || stats.Alerts != 1 || !stats.LastSuccess.Equal(r.At) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
@@ -0,0 +1,230 @@
+// Package etl provides ETL workers for hourly and daily aggregation.
+// cost_annotator.go: 成本注解控制器，定期把工作负载近 30 天成本与效率等级写回 K8s 注解
+// （lighthouse.io/monthly-cost、lighthouse.io/grade），供 kubectl 用户与其他控制器在集群内读取。
+package etl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// DefaultCostAnnotationWindow is the window of the monthly cost annotation.
+const DefaultCostAnnotationWindow = 30 * 24 * time.Hour
+
+// DailyWorkloadStatLister lists the daily rollups of downsampled hourly stats (cost_daily_workload).
+// *postgres.MockRepository satisfies this interface.
+type DailyWorkloadStatLister interface {
+	ListDailyWorkloadStats(ctx context.Context, filter postgres.DailyWorkloadStatFilter) ([]postgres.DailyWorkloadStat, error)
+}
+
+// GradeEventLister lists grade change events (workload_grade_event).
+// *postgres.MockRepository satisfies this interface.
+type GradeEventLister interface {
+	ListGradeChangeEvents(ctx context.Context, filter postgres.GradeChangeEventFilter) ([]postgres.GradeChangeEvent, error)
+}
+
+// CostAnnotationWorker writes the billable cost of the last Window and the efficiency grade of each
+// workload with stats in that window onto the workload as annotations (k8s.AnnotationMonthlyCost,
+// k8s.AnnotationGrade, k8s.AnnotationCostUpdatedAt). The grade is the latest recorded grade change
+// when Grades is set, else graded from the window's efficiency. Unchanged values are not patched
+// again, so a pass only writes workloads whose cost or grade moved.
+type CostAnnotationWorker struct {
+	Repo      postgres.Repository
+	Annotator k8s.WorkloadAnnotator
+	// Rollups, when set, adds the daily rollups of hourly stats already downsampled, so the cost
+	// covers the whole window even when it is longer than the hourly retention.
+	Rollups DailyWorkloadStatLister
+	Grades  GradeEventLister
+	// Thresholds grade workloads without grade history; zero uses the default thresholds.
+	Thresholds costmodel.GradeThresholds
+	// Namespaces restricts the workloads annotated; empty annotates all.
+	Namespaces []string
+	Window     time.Duration // default DefaultCostAnnotationWindow
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	written map[string]costAnnotation
+}
+
+// costAnnotation is the value last written to a workload.
+type costAnnotation struct {
+	cost  string
+	grade string
+}
+
+// CostAnnotationResult is the outcome of one pass.
+type CostAnnotationResult struct {
+	Annotated int `json:"annotated"`
+	Unchanged int `json:"unchanged"`
+	Missing   int `json:"missing"` // workloads with stats that no longer exist in the cluster
+}
+
+type workloadCost struct {
+	namespace, name, kind string
+	billable, usage       float64
+}
+
+// Run executes one pass. A workload that fails to be annotated aborts the pass; workloads that no
+// longer exist are skipped.
+func (w *CostAnnotationWorker) Run(ctx context.Context) (*CostAnnotationResult, error) {
+	if w.Repo == nil || w.Annotator == nil {
+		return nil, fmt.Errorf("cost annotations: repo and annotator are required")
+	}
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+	end := now().UTC()
+	window := w.Window
+	if window <= 0 {
+		window = DefaultCostAnnotationWindow
+	}
+	costs, err := w.workloadCosts(ctx, end.Add(-window), end)
+	if err != nil {
+		return nil, err
+	}
+	thresholds := w.Thresholds
+	if thresholds == (costmodel.GradeThresholds{}) {
+		thresholds = costmodel.DefaultGradeThresholds
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.written == nil {
+		w.written = make(map[string]costAnnotation)
+	}
+	result := &CostAnnotationResult{}
+	for _, wc := range costs {
+		key := wc.namespace + "/" + wc.name
+		value := costAnnotation{cost: strconv.FormatFloat(wc.billable, 'f', 2, 64)}
+		if value.grade, err = w.grade(ctx, wc, thresholds); err != nil {
+			return result, err
+		}
+		if w.written[key] == value {
+			result.Unchanged++
+			continue
+		}
+		kind := wc.kind
+		if kind == "" {
+			kind = "Deployment"
+		}
+		err := w.Annotator.AnnotateWorkload(ctx, wc.namespace, kind, wc.name, map[string]string{
+			k8s.AnnotationMonthlyCost:   value.cost,
+			k8s.AnnotationGrade:         value.grade,
+			k8s.AnnotationCostUpdatedAt: end.Format(time.RFC3339),
+		})
+		if errors.Is(err, dataerr.ErrNotFound) {
+			result.Missing++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("cost annotations: annotate %s %s: %w", kind, key, err)
+		}
+		w.written[key] = value
+		result.Annotated++
+	}
+	return result, nil
+}
+
+// workloadCosts sums the hourly stats (and daily rollups) of start..end per workload, sorted by key.
+func (w *CostAnnotationWorker) workloadCosts(ctx context.Context, start, end time.Time) ([]*workloadCost, error) {
+	include := func(string) bool { return true }
+	if len(w.Namespaces) > 0 {
+		set := make(map[string]bool, len(w.Namespaces))
+		for _, ns := range w.Namespaces {
+			set[ns] = true
+		}
+		include = func(ns string) bool { return set[ns] }
+	}
+	costs := make(map[string]*workloadCost)
+	add := func(namespace, name, kind string, billable, usage float64) {
+		if !include(namespace) {
+			return
+		}
+		key := namespace + "/" + name
+		wc, ok := costs[key]
+		if !ok {
+			wc = &workloadCost{namespace: namespace, name: name}
+			costs[key] = wc
+		}
+		if wc.kind == "" {
+			wc.kind = kind
+		}
+		wc.billable += billable
+		wc.usage += usage
+	}
+
+	stats, err := w.Repo.AggregateHourlyWorkloadStats(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("cost annotations: aggregate hourly stats: %w", err)
+	}
+	for _, st := range stats {
+		add(st.Namespace, st.WorkloadName, st.WorkloadType, st.TotalBillableCost, st.TotalUsageCost)
+	}
+	if w.Rollups != nil {
+		days, err := w.Rollups.ListDailyWorkloadStats(ctx, postgres.DailyWorkloadStatFilter{StartTime: start, EndTime: end})
+		if err != nil {
+			return nil, fmt.Errorf("cost annotations: list daily rollups: %w", err)
+		}
+		for _, d := range days {
+			add(d.Namespace, d.WorkloadName, d.WorkloadType, d.TotalBillableCost, d.TotalUsageCost)
+		}
+	}
+
+	keys := make([]string, 0, len(costs))
+	for k := range costs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]*workloadCost, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, costs[k])
+	}
+	return out, nil
+}
+
+// grade returns the latest recorded grade of the workload, else its grade over the window.
+func (w *CostAnnotationWorker) grade(ctx context.Context, wc *workloadCost, thresholds costmodel.GradeThresholds) (string, error) {
+	if w.Grades != nil {
+		events, err := w.Grades.ListGradeChangeEvents(ctx, postgres.GradeChangeEventFilter{Namespace: wc.namespace, WorkloadName: wc.name})
+		if err != nil {
+			return "", fmt.Errorf("cost annotations: grade history of %s/%s: %w", wc.namespace, wc.name, err)
+		}
+		var latest *postgres.GradeChangeEvent
+		for i := range events {
+			if latest == nil || events[i].OccurredAt.After(latest.OccurredAt) {
+				latest = &events[i]
+			}
+		}
+		if latest != nil {
+			return latest.ToGrade, nil
+		}
+	}
+	if wc.billable <= 0 {
+		return "", nil // 无请求量的工作负载不评级，清除旧等级
+	}
+	return string(thresholds.Grade(wc.usage / wc.billable * 100)), nil
+}
+
+// Job returns the pass as a scheduler job.
+func (w *CostAnnotationWorker) Job(interval time.Duration) Job {
+	return Job{
+		Name:     "cost-annotations",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, err := w.Run(ctx)
+			return err
+		},
+	}
+}
@@ -0,0 +1,125 @@
+package etl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// recordingAnnotator records the annotations written per "namespace/kind/name"; workloads in
+// missing return NotFound.
+type recordingAnnotator struct {
+	written map[string]map[string]string
+	calls   int
+	missing map[string]bool
+}
+
+func (a *recordingAnnotator) AnnotateWorkload(_ context.Context, namespace, kind, name string, annotations map[string]string) error {
+	a.calls++
+	if a.missing[namespace+"/"+name] {
+		return dataerr.NotFound("%s %s/%s not found", kind, namespace, name)
+	}
+	if a.written == nil {
+		a.written = make(map[string]map[string]string)
+	}
+	a.written[namespace+"/"+kind+"/"+name] = annotations
+	return nil
+}
+
+func TestCostAnnotationWorker(t *testing.T) {
+	ctx := context.Background()
+	mockCfg := postgres.DefaultMockConfig()
+	mockCfg.Scenario = "empty"
+	mockCfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(mockCfg)
+	now := time.Date(2020, 8, 31, 12, 0, 0, 0, time.UTC)
+	save := func(ns, name, kind string, at time.Time, billable, usage float64) {
+		t.Helper()
+		stat := postgres.HourlyWorkloadStat{Namespace: ns, WorkloadName: name, WorkloadType: kind, Timestamp: at, TotalBillableCost: billable, TotalUsageCost: usage}
+		if err := repo.SaveHourlyWorkloadStat(ctx, stat); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	// cart: 100 billable over two hours, 50% efficient; api: 5% efficient; old is outside the window
+	save("shop", "cart", "Deployment", now.Add(-2*time.Hour), 60, 30)
+	save("shop", "cart", "Deployment", now.Add(-time.Hour), 40, 20)
+	save("shop", "db", "StatefulSet", now.Add(-time.Hour), 20, 1)
+	save("ops", "old", "Deployment", now.Add(-40*24*time.Hour), 10, 10)
+	save("ops", "gone", "Deployment", now.Add(-time.Hour), 10, 10)
+
+	annotator := &recordingAnnotator{missing: map[string]bool{"ops/gone": true}}
+	w := &CostAnnotationWorker{Repo: repo, Annotator: annotator, now: func() time.Time { return now }}
+	res, err := w.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Annotated != 2 || res.Missing != 1 || res.Unchanged != 0 {
+		t.Errorf("first pass = %+v, want 2 annotated, 1 missing", res)
+	}
+	cart := annotator.written["shop/Deployment/cart"]
+	if cart[k8s.AnnotationMonthlyCost] != "100.00" || cart[k8s.AnnotationGrade] != string(costmodel.GradeHealthy) || cart[k8s.AnnotationCostUpdatedAt] != "2020-08-31T12:00:00Z" {
+		t.Errorf("cart annotations = %v", cart)
+	}
+	if db := annotator.written["shop/StatefulSet/db"]; db[k8s.AnnotationGrade] != string(costmodel.GradeZombie) {
+		t.Errorf("db annotations = %v, want zombie grade", db)
+	}
+	if _, ok := annotator.written["ops/Deployment/old"]; ok {
+		t.Error("workload outside the window was annotated")
+	}
+
+	// Nothing changed: no patches except the missing workload being retried
+	calls := annotator.calls
+	if res, err = w.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Unchanged != 2 || annotator.calls != calls+1 {
+		t.Errorf("second pass = %+v with %d calls, want 2 unchanged and only the missing workload retried", res, annotator.calls-calls)
+	}
+
+	// The recorded grade wins over the window's efficiency
+	if err := repo.SaveGradeChangeEvent(ctx, postgres.GradeChangeEvent{ID: "g1", Namespace: "shop", WorkloadName: "cart", ToGrade: string(costmodel.GradeOverProvisioned), OccurredAt: now.Add(-30 * time.Minute)}); err != nil {
+		t.Fatalf("SaveGradeChangeEvent: %v", err)
+	}
+	w.Grades = repo
+	w.Namespaces = []string{"shop"}
+	if res, err = w.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Annotated != 1 || res.Unchanged != 1 || res.Missing != 0 {
+		t.Errorf("third pass = %+v, want cart re-annotated and ops skipped", res)
+	}
+	if got := annotator.written["shop/Deployment/cart"][k8s.AnnotationGrade]; got != string(costmodel.GradeOverProvisioned) {
+		t.Errorf("cart grade = %q, want recorded OverProvisioned", got)
+	}
+}
+
+func TestCostAnnotationWorker_MockClient(t *testing.T) {
+	ctx := context.Background()
+	cfg := k8s.DefaultMockConfig()
+	cfg.LatencyMs = 0
+	cfg.Namespaces = []string{"shop"}
+	client := k8s.NewMockClient(cfg)
+	if err := client.AnnotateWorkload(ctx, "shop", "Deployment", "shop-deployment-1", map[string]string{k8s.AnnotationGrade: string(costmodel.GradeRisk)}); err != nil {
+		t.Fatalf("AnnotateWorkload: %v", err)
+	}
+	deployments, err := client.GetDeployments(ctx, "shop")
+	if err != nil {
+		t.Fatalf("GetDeployments: %v", err)
+	}
+	for _, d := range deployments {
+		if got := d.Annotations[k8s.AnnotationGrade]; (d.Name == "shop-deployment-1") != (got == string(costmodel.GradeRisk)) {
+			t.Errorf("%s grade annotation = %q", d.Name, got)
+		}
+	}
+	if err := client.AnnotateWorkload(ctx, "shop", "Deployment", "shop-deployment-1", map[string]string{k8s.AnnotationGrade: ""}); err != nil {
+		t.Fatalf("AnnotateWorkload: %v", err)
+	}
+	if deployments, _ = client.GetDeployments(ctx, "shop"); deployments[0].Annotations[k8s.AnnotationGrade] != "" {
+		t.Error("empty value did not remove the annotation")
+	}
+}
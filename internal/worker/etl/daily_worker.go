@@ -1,10 +1,178 @@
 // Package etl provides ETL workers for hourly and daily aggregation.
-// daily_worker.go: placeholder for L2 daily namespace ETL (cost_daily_namespace).
+// daily_worker.go: L2 daily namespace ETL（cost_hourly_workload -> cost_daily_namespace），含集群共享资源成本分摊。
 package etl
 
-// DailyWorker runs daily aggregation from signal plane to control plane.
-// Phase1: placeholder only; no business logic.
-type DailyWorker struct{}
+import (
+	"context"
+	"fmt"
+	"time"
 
-// Run is a placeholder. Implementation in Phase2.
-func (w *DailyWorker) Run() error { return nil }
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// SharedUsageSource measures shared resources. prometheus.SharedUsageClient satisfies this interface.
+type SharedUsageSource interface {
+	GetSharedResourceUsage(ctx context.Context, resource, key string, startTime, endTime time.Time) (units float64, byNamespace map[string]float64, err error)
+}
+
+// DailyWorker rolls the hourly workload stats of a day up into one DailyNamespaceCost per namespace
+// and allocates the daily cost of each shared resource to namespaces as SharedCost.
+type DailyWorker struct {
+	Repo postgres.Repository
+
+	// Shared resources to allocate; Usage is required when any of them is metered or allocated by
+	// traffic or capacity.
+	Shared []costmodel.SharedResource
+	Usage  SharedUsageSource
+
+	// Calendar is the accounting time zone (Business.AccountingTimeZone): a day covers local midnight
+	// to local midnight and rows are labelled with the local date. The zero value is UTC.
+	Calendar costmodel.AccountingCalendar
+
+	now func() time.Time
+}
+
+// DailyResult is the outcome of one daily run.
+type DailyResult struct {
+	Day        time.Time `json:"day"`
+	Namespaces int       `json:"namespaces"`
+	SharedCost float64   `json:"shared_cost"` // allocated; shared cost without any target namespace is dropped
+}
+
+// Run aggregates the accounting day labelled day (its UTC date, see costmodel.AccountingCalendar) and
+// saves the namespace costs. Namespaces that only receive shared cost (e.g. storage of a scaled-down
+// namespace) get a row as well. Re-running a day of a closed accounting period is a recalculation:
+// its rows stay unchanged and the differences are recorded as adjustments.
+func (w *DailyWorker) Run(ctx context.Context, day time.Time) (*DailyResult, error) {
+	if w.Repo == nil {
+		return nil, fmt.Errorf("daily etl: no repository configured")
+	}
+	for _, r := range w.Shared {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("daily etl: %w", err)
+		}
+	}
+	date := day.UTC().Truncate(24 * time.Hour)
+	start, end := w.Calendar.Bounds(date)
+	stats, err := w.Repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, fmt.Errorf("daily etl: list hourly stats: %w", err)
+	}
+
+	type acc struct {
+		cost                   postgres.DailyNamespaceCost
+		pods, nodes, workloads map[string]bool
+		sources                map[string]bool
+	}
+	byNamespace := make(map[string]*acc)
+	get := func(ns string) *acc {
+		a, ok := byNamespace[ns]
+		if !ok {
+			a = &acc{
+				cost:      postgres.DailyNamespaceCost{Namespace: ns, Date: date},
+				pods:      make(map[string]bool),
+				nodes:     make(map[string]bool),
+				workloads: make(map[string]bool),
+				sources:   make(map[string]bool),
+			}
+			byNamespace[ns] = a
+		}
+		return a
+	}
+	for _, st := range stats {
+		if !st.Timestamp.Before(end) {
+			continue
+		}
+		a := get(st.Namespace)
+		a.cost.BillableCost += st.TotalBillableCost
+		a.cost.UsageCost += st.TotalUsageCost
+		a.cost.WasteCost += st.TotalWasteCost
+		if st.PodName != "" {
+			a.pods[st.PodName] = true
+		}
+		if st.NodeName != "" {
+			a.nodes[st.NodeName] = true
+		}
+		a.workloads[st.WorkloadName] = true
+		a.sources[st.Source] = true
+	}
+
+	result := &DailyResult{Day: date}
+	billable := make(map[string]float64, len(byNamespace))
+	for ns, a := range byNamespace {
+		billable[ns] = a.cost.BillableCost
+	}
+	for _, r := range w.Shared {
+		cost, weights, err := w.sharedWeights(ctx, r, date, start, end, billable)
+		if err != nil {
+			return nil, err
+		}
+		for ns, c := range costmodel.AllocateShared(cost, weights) {
+			get(ns).cost.SharedCost += c
+			result.SharedCost += c
+		}
+	}
+
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+	ctx = postgres.WithRecalculation(ctx, "daily rollup of "+date.Format("2006-01-02"))
+	for _, a := range byNamespace {
+		c := a.cost
+		c.PodCount, c.NodeCount, c.WorkloadCount = len(a.pods), len(a.nodes), len(a.workloads)
+		// 全部来自同一导入来源的小时行汇总出的日行保留来源；混有自身计算的行时视为自身计算
+		if len(a.sources) == 1 {
+			for source := range a.sources {
+				c.Source = source
+			}
+		}
+		if c.BillableCost > 0 {
+			c.EfficiencyScore = c.UsageCost / c.BillableCost * 100
+		}
+		c.CreatedAt = now().UTC()
+		if err := w.Repo.SaveDailyNamespaceCost(ctx, c); err != nil {
+			return nil, fmt.Errorf("daily etl: save %s: %w", c.Namespace, err)
+		}
+		result.Namespaces++
+	}
+	return result, nil
+}
+
+// Job returns the daily run of the previous accounting day as a scheduler job.
+func (w *DailyWorker) Job(interval time.Duration) Job {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return Job{
+		Name:     "daily-namespace-cost",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			now := time.Now
+			if w.now != nil {
+				now = w.now
+			}
+			_, err := w.Run(ctx, w.Calendar.Date(now()).AddDate(0, 0, -1))
+			return err
+		},
+	}
+}
+
+// sharedWeights returns the daily cost of r and the allocation weight of each eligible namespace
+// (see costmodel.SharedResource.Weights).
+func (w *DailyWorker) sharedWeights(ctx context.Context, r costmodel.SharedResource, date, start, end time.Time, billable map[string]float64) (float64, map[string]float64, error) {
+	var units float64
+	var measured map[string]float64
+	if r.NeedsUsage() {
+		if w.Usage == nil {
+			return 0, nil, fmt.Errorf("daily etl: shared resource %s needs a usage source", r.Name)
+		}
+		var err error
+		units, measured, err = w.Usage.GetSharedResourceUsage(ctx, r.Name, string(r.AllocationKey), start, end)
+		if err != nil {
+			return 0, nil, fmt.Errorf("daily etl: usage of shared resource %s: %w", r.Name, err)
+		}
+	}
+	return r.DailyCost(date, units), r.Weights(measured, billable), nil
+}
@@ -0,0 +1,129 @@
+package etl
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// fixedUsage returns the same usage for every shared resource.
+type fixedUsage struct {
+	units       float64
+	byNamespace map[string]float64
+}
+
+func (f fixedUsage) GetSharedResourceUsage(ctx context.Context, resource, key string, startTime, endTime time.Time) (float64, map[string]float64, error) {
+	return f.units, f.byNamespace, nil
+}
+
+func TestDailyWorker_SharedCostAllocation(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	day := time.Date(2020, 4, 10, 0, 0, 0, 0, time.UTC) // 30 days in April
+	for ns, cost := range map[string]float64{"web": 30, "batch": 10} {
+		for h := 0; h < 2; h++ {
+			if err := repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+				Namespace: ns, WorkloadName: "app", PodName: "app-0", NodeName: "node-1",
+				Timestamp:         day.Add(time.Duration(h) * time.Hour),
+				TotalBillableCost: cost / 2, TotalUsageCost: cost / 4, TotalWasteCost: cost / 4,
+			}); err != nil {
+				t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+			}
+		}
+	}
+	w := &DailyWorker{
+		Repo: repo,
+		Shared: []costmodel.SharedResource{
+			{Name: "lb", Billing: costmodel.SharedBillingFixed, MonthlyCost: 300, AllocationKey: costmodel.AllocateByTraffic},
+			{Name: "nfs", Billing: costmodel.SharedBillingMetered, UnitPrice: 0.5, AllocationKey: costmodel.AllocateByBillable},
+		},
+		// traffic 3:1 web/batch; 8 metered units
+		Usage: fixedUsage{units: 8, byNamespace: map[string]float64{"web": 3, "batch": 1}},
+	}
+	result, err := w.Run(ctx, day.Add(15*time.Hour))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Namespaces != 2 || math.Abs(result.SharedCost-14) > 1e-9 {
+		t.Errorf("result = %+v, want 2 namespaces and 14 shared cost (10 lb + 4 nfs)", result)
+	}
+
+	web, err := repo.GetDailyNamespaceCost(ctx, "web", day)
+	if err != nil {
+		t.Fatalf("GetDailyNamespaceCost: %v", err)
+	}
+	// lb: 10/day * 3/4 = 7.5; nfs: 4 * 30/40 = 3
+	if math.Abs(web.BillableCost-30) > 1e-9 || math.Abs(web.SharedCost-10.5) > 1e-9 {
+		t.Errorf("web = billable %v shared %v, want 30 / 10.5", web.BillableCost, web.SharedCost)
+	}
+	if web.EfficiencyScore != 50 || web.PodCount != 1 || web.WorkloadCount != 1 {
+		t.Errorf("web = %+v", web)
+	}
+
+	w.Shared = []costmodel.SharedResource{{Name: "lb", Billing: costmodel.SharedBillingFixed, MonthlyCost: 300, AllocationKey: costmodel.AllocateByTraffic}}
+	w.Usage = nil
+	if _, err := w.Run(ctx, day); err == nil {
+		t.Error("traffic allocation without a usage source should fail")
+	}
+}
+
+func TestDailyWorker_AccountingTimeZone(t *testing.T) {
+	ctx := context.Background()
+	cfg := postgres.DefaultMockConfig()
+	cfg.Scenario = "empty"
+	repo := postgres.NewMockRepository(cfg)
+	day := time.Date(2020, 4, 10, 0, 0, 0, 0, time.UTC)
+	// Shanghai (UTC+8): Apr 10 is 2020-04-09T16:00Z..2020-04-10T16:00Z
+	for _, ts := range []time.Time{day.Add(-9 * time.Hour), day.Add(-7 * time.Hour), day.Add(15 * time.Hour), day.Add(17 * time.Hour)} {
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "web", WorkloadName: "app", Timestamp: ts, TotalBillableCost: 1})
+	}
+	cal, _ := costmodel.NewAccountingCalendar("Asia/Shanghai")
+	w := &DailyWorker{Repo: repo, Calendar: cal, now: func() time.Time { return day.Add(17 * time.Hour) }}
+	if err := w.Job(0).Run(ctx); err != nil {
+		t.Fatalf("Job: %v", err)
+	}
+	got, err := repo.GetDailyNamespaceCost(ctx, "web", day)
+	if err != nil {
+		t.Fatalf("GetDailyNamespaceCost: %v", err)
+	}
+	if got.BillableCost != 2 || !got.Date.Equal(day) {
+		t.Errorf("Apr 10 = %+v, want the 2 hours of the Shanghai day labelled %v", got, day)
+	}
+}
+
+func TestDailyWorker_ImportedSource(t *testing.T) {
+	ctx := context.Background()
+	cfg := postgres.DefaultMockConfig()
+	cfg.Scenario = "empty"
+	cfg.LatencyMs = 0
+	repo := postgres.NewMockRepository(cfg)
+	day := time.Date(2020, 4, 10, 0, 0, 0, 0, time.UTC)
+	for i, st := range []postgres.HourlyWorkloadStat{
+		{Namespace: "legacy", WorkloadName: "app", Source: "kubecost"},
+		{Namespace: "legacy", WorkloadName: "job", Source: "kubecost"},
+		{Namespace: "mixed", WorkloadName: "app", Source: "kubecost"},
+		{Namespace: "mixed", WorkloadName: "job"},
+	} {
+		st.Timestamp = day.Add(time.Duration(i) * time.Hour)
+		st.TotalBillableCost = 1
+		if err := repo.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			t.Fatalf("SaveHourlyWorkloadStat: %v", err)
+		}
+	}
+	if _, err := (&DailyWorker{Repo: repo}).Run(ctx, day); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for ns, want := range map[string]string{"legacy": "kubecost", "mixed": ""} {
+		c, err := repo.GetDailyNamespaceCost(ctx, ns, day)
+		if err != nil {
+			t.Fatalf("GetDailyNamespaceCost(%s): %v", ns, err)
+		}
+		if c.Source != want {
+			t.Errorf("%s source = %q, want %q", ns, c.Source, want)
+		}
+	}
+}
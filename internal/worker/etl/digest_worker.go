@@ -0,0 +1,458 @@
+// Package etl provides ETL workers for hourly and daily aggregation.
+// digest_worker.go: 按团队生成周度优化建议摘要（僵尸工作负载、规格调整建议与预计月度节省、预算状态）并投递。
+package etl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/eventbus"
+	"github.com/myxxhui/lighthouse-src/internal/i18n"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// DigestTeam is the scope of one team's digest.
+type DigestTeam struct {
+	Name       string
+	Namespaces []string
+	// Recipients of the digest email; empty resolves the owners of each namespace.
+	Recipients []string
+	// MonthlyBudget enables the budget status section when > 0.
+	MonthlyBudget float64
+	// Locale of the digest; empty uses the worker's Locale.
+	Locale i18n.Locale
+}
+
+// DigestSender delivers team digests. *notifier.DigestSender satisfies this interface.
+type DigestSender interface {
+	SendDigests(ctx context.Context, digests []notifier.TeamDigest) (int, error)
+}
+
+// CostPolicySource resolves the cost policies of a namespace's workloads. *k8s.CostPolicyResolver
+// satisfies this interface.
+type CostPolicySource interface {
+	NamespacePolicies(ctx context.Context, namespace string) (costmodel.CostPolicy, map[string]costmodel.CostPolicy, error)
+}
+
+// EfficiencyTargetSource resolves the efficiency target of a namespace. *service.EfficiencyTargetService
+// satisfies this interface.
+type EfficiencyTargetSource interface {
+	EfficiencyTarget(ctx context.Context, namespace string) (target float64, ok bool, err error)
+}
+
+// RecommendationSafetyChecker checks reduction recommendations against the recent throttling of the
+// workload's pods and its SLO. *service.RightsizingSafety satisfies this interface.
+type RecommendationSafetyChecker interface {
+	Check(ctx context.Context, namespace, workload string, pods []string, recs []costmodel.Recommendation) (proposed, suppressed []costmodel.Recommendation, err error)
+}
+
+// DigestWorker builds a weekly digest per team from the last 7 days of hourly workload stats
+// (grade by efficiency, costmodel.Recommend on the latest hour) and month-to-date daily namespace costs.
+type DigestWorker struct {
+	Repo   postgres.Repository
+	Teams  []DigestTeam
+	Owners notifier.OwnershipResolver
+	Sender DigestSender
+	// Policies, when set, applies grade threshold overrides and waste exclusion to the recommendations.
+	Policies CostPolicySource
+	// Targets, when set, adds the efficiency target attainment of the window per namespace.
+	Targets EfficiencyTargetSource
+	// Recommendations, when set, records the recommendations of the digests sent for
+	// RecommendationReconciler to track their adoption.
+	Recommendations RecommendationStore
+	// Publisher, when set with Recommendations, receives an eventbus.RecommendationIssued for every
+	// recommendation recorded for the first time (re-issues of a pending one are not published again).
+	Publisher EventPublisher
+	// Safety, when set, leaves out reductions suppressed by the safety checks and notes the
+	// rationale of flagged ones in their reason.
+	Safety RecommendationSafetyChecker
+
+	Currency     string
+	DashboardURL string
+	// Locale is the language of digests whose team sets none; empty is i18n.Default.
+	Locale i18n.Locale
+
+	// Calendar is the accounting time zone the budget month is closed in; the zero value is UTC.
+	Calendar costmodel.AccountingCalendar
+	// Fiscal defines the budget month and quarter (fiscal periods); the zero value is the Gregorian calendar.
+	Fiscal costmodel.FiscalCalendar
+
+	now func() time.Time
+}
+
+// DigestResult is the outcome of one digest run.
+type DigestResult struct {
+	Teams           int `json:"teams"`
+	Sent            int `json:"sent"`
+	Recommendations int `json:"recommendations"`
+}
+
+// Run builds and sends the digests of all teams.
+func (w *DigestWorker) Run(ctx context.Context) (*DigestResult, error) {
+	if w.Sender == nil {
+		return nil, fmt.Errorf("digest: no sender configured")
+	}
+	digests, err := w.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &DigestResult{Teams: len(digests)}
+	for _, d := range digests {
+		result.Recommendations += len(d.Zombies) + len(d.Rightsizing)
+	}
+	result.Sent, err = w.Sender.SendDigests(ctx, digests)
+	if err != nil {
+		return result, err
+	}
+	if w.Recommendations != nil {
+		if err := w.recordIssued(ctx, digests); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// recordIssued saves the recommendations of digests as issued. A recommendation still pending for
+// the same workload resource keeps its baseline and issue time; its target and savings are updated.
+func (w *DigestWorker) recordIssued(ctx context.Context, digests []notifier.TeamDigest) error {
+	if len(digests) == 0 {
+		return nil
+	}
+	existing, err := w.Recommendations.ListIssuedRecommendations(ctx, postgres.IssuedRecommendationFilter{})
+	if err != nil {
+		return fmt.Errorf("digest: list issued recommendations: %w", err)
+	}
+	pending := make(map[string]postgres.IssuedRecommendation)
+	ids := make(map[string]string, len(existing))
+	for _, r := range existing {
+		key := r.Namespace + "/" + r.WorkloadName + "/" + r.Resource
+		ids[key] = r.ID
+		if r.Status != string(costmodel.AdoptionApplied) {
+			pending[key] = r
+		}
+	}
+	issuedAt := digests[0].PeriodEnd
+	for _, d := range digests {
+		for _, item := range append(append([]notifier.DigestItem(nil), d.Zombies...), d.Rightsizing...) {
+			key := item.Namespace + "/" + item.Workload + "/" + item.Resource
+			rec := postgres.IssuedRecommendation{
+				// 同一工作负载资源的记录（含已采纳的）沿用原 ID，与存储按该键更新保持一致
+				ID:              ids[key],
+				Team:            d.Team,
+				Namespace:       item.Namespace,
+				WorkloadName:    item.Workload,
+				Resource:        item.Resource,
+				BaselineRequest: item.CurrentRequest,
+				IssuedAt:        issuedAt,
+				Status:          string(costmodel.AdoptionOpen),
+			}
+			if rec.ID == "" {
+				rec.ID = "rec-" + uuid.New().String()
+			}
+			prev, reissued := pending[key]
+			if reissued {
+				rec = prev
+				rec.Team = d.Team
+			}
+			rec.Action = item.Action
+			rec.RecommendedRequest = item.RecommendedRequest
+			rec.EstimatedMonthlySavings = item.MonthlySavings
+			if err := w.Recommendations.SaveIssuedRecommendation(ctx, rec); err != nil {
+				return fmt.Errorf("digest: record recommendation for %s/%s: %w", item.Namespace, item.Workload, err)
+			}
+			if w.Publisher != nil && !reissued {
+				// 建议已落库，outbox 满时仅丢失推送
+				_ = w.Publisher.Publish(eventbus.RecommendationIssued{
+					RecommendationID: rec.ID,
+					Namespace:        rec.Namespace,
+					WorkloadName:     rec.WorkloadName,
+					Action:           rec.Action,
+					Description:      item.Reason,
+					EstimatedSavings: rec.EstimatedMonthlySavings,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// Build returns the digests of all teams, sorted by team name.
+func (w *DigestWorker) Build(ctx context.Context) ([]notifier.TeamDigest, error) {
+	if w.Repo == nil {
+		return nil, fmt.Errorf("digest: no repository configured")
+	}
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+	end := now().UTC()
+	start := end.AddDate(0, 0, -7)
+
+	teams := append([]DigestTeam(nil), w.Teams...)
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	digests := make([]notifier.TeamDigest, 0, len(teams))
+	for _, team := range teams {
+		d := notifier.TeamDigest{
+			Team:          team.Name,
+			Namespaces:    team.Namespaces,
+			PeriodStart:   start,
+			PeriodEnd:     end,
+			Currency:      w.Currency,
+			DashboardLink: w.DashboardURL,
+			Locale:        w.Locale,
+		}
+		if team.Locale != "" {
+			d.Locale = team.Locale
+		}
+		recipients, err := w.recipients(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+		d.Recipients = recipients
+
+		for _, ns := range team.Namespaces {
+			stats, err := w.Repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: ns, StartTime: start, EndTime: end})
+			if err != nil {
+				return nil, fmt.Errorf("digest: list stats of %s: %w", ns, err)
+			}
+			var nsPolicy costmodel.CostPolicy
+			var policies map[string]costmodel.CostPolicy
+			if w.Policies != nil {
+				if nsPolicy, policies, err = w.Policies.NamespacePolicies(ctx, ns); err != nil {
+					return nil, fmt.Errorf("digest: resolve cost policies of %s: %w", ns, err)
+				}
+			}
+			var check func(workload string, pods []string, recs []costmodel.Recommendation) ([]costmodel.Recommendation, error)
+			if w.Safety != nil {
+				check = func(workload string, pods []string, recs []costmodel.Recommendation) ([]costmodel.Recommendation, error) {
+					proposed, _, err := w.Safety.Check(ctx, ns, workload, pods, recs)
+					return proposed, err
+				}
+			}
+			zombies, rightsizing, err := digestItems(ns, stats, func(workload string) costmodel.CostPolicy {
+				if p, ok := policies[workload]; ok {
+					return p
+				}
+				return nsPolicy
+			}, check)
+			if err != nil {
+				return nil, fmt.Errorf("digest: safety checks of %s: %w", ns, err)
+			}
+			d.Zombies = append(d.Zombies, zombies...)
+			d.Rightsizing = append(d.Rightsizing, rightsizing...)
+		}
+		sortBySavings(d.Zombies)
+		sortBySavings(d.Rightsizing)
+
+		if w.Targets != nil {
+			if d.Targets, err = w.targetStatus(ctx, team, start, end); err != nil {
+				return nil, err
+			}
+		}
+		if team.MonthlyBudget > 0 {
+			budget, err := w.budgetStatus(ctx, team, end)
+			if err != nil {
+				return nil, err
+			}
+			d.Budget = budget
+		}
+		digests = append(digests, d)
+	}
+	return digests, nil
+}
+
+// Job returns the digest run as a scheduler job.
+func (w *DigestWorker) Job(interval time.Duration) Job {
+	if interval <= 0 {
+		interval = 7 * 24 * time.Hour
+	}
+	return Job{
+		Name:     "recommendation-digest",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, err := w.Run(ctx)
+			return err
+		},
+	}
+}
+
+// recipients returns the team's recipients, or the de-duplicated owners of its namespaces.
+func (w *DigestWorker) recipients(ctx context.Context, team DigestTeam) ([]string, error) {
+	if len(team.Recipients) > 0 || w.Owners == nil {
+		return team.Recipients, nil
+	}
+	seen := make(map[string]bool)
+	var out []string
+	for _, ns := range team.Namespaces {
+		owners, err := w.Owners.OwnersFor(ctx, ns)
+		if err != nil {
+			return nil, fmt.Errorf("digest: resolve owners for %s: %w", ns, err)
+		}
+		for _, o := range owners {
+			if !seen[o] {
+				seen[o] = true
+				out = append(out, o)
+			}
+		}
+	}
+	return out, nil
+}
+
+// targetStatus tracks the daily efficiency of the team's namespaces that have a target.
+func (w *DigestWorker) targetStatus(ctx context.Context, team DigestTeam, start, end time.Time) ([]notifier.TargetStatus, error) {
+	var out []notifier.TargetStatus
+	for _, ns := range team.Namespaces {
+		target, ok, err := w.Targets.EfficiencyTarget(ctx, ns)
+		if err != nil {
+			return nil, fmt.Errorf("digest: efficiency target of %s: %w", ns, err)
+		}
+		if !ok {
+			continue
+		}
+		costs, err := w.Repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{Namespace: ns, StartDate: start, EndDate: end})
+		if err != nil {
+			return nil, fmt.Errorf("digest: list daily costs of %s: %w", ns, err)
+		}
+		points := make([]costmodel.EfficiencyPoint, 0, len(costs))
+		for _, c := range costs {
+			points = append(points, costmodel.EfficiencyPoint{Date: c.Date, Efficiency: costmodel.DailyEfficiency(c.BillableCost, c.UsageCost)})
+		}
+		a := costmodel.TrackEfficiencyTarget(points, target)
+		out = append(out, notifier.TargetStatus{
+			Namespace:         ns,
+			Target:            target,
+			AverageEfficiency: a.AverageEfficiency,
+			AttainmentRate:    a.AttainmentRate,
+			CurrentStreak:     a.CurrentStreak,
+		})
+	}
+	return out, nil
+}
+
+// budgetStatus compares the billable cost of the fiscal period (month) to date with the budget; the
+// period-end projection extrapolates the average daily cost of the elapsed days.
+func (w *DigestWorker) budgetStatus(ctx context.Context, team DigestTeam, now time.Time) (*notifier.BudgetStatus, error) {
+	today := w.Calendar.Date(now)
+	period := w.Fiscal.Span(today, costmodel.FiscalUnitPeriod)
+	quarter := w.Fiscal.Span(today, costmodel.FiscalUnitQuarter)
+	status := &notifier.BudgetStatus{Period: period.Label(), MonthlyBudget: team.MonthlyBudget, Status: notifier.BudgetStatusOK}
+	for _, ns := range team.Namespaces {
+		costs, err := w.Repo.ListDailyNamespaceCosts(ctx, postgres.DailyNamespaceCostFilter{Namespace: ns, StartDate: quarter.Start, EndDate: today})
+		if err != nil {
+			return nil, fmt.Errorf("digest: list daily costs of %s: %w", ns, err)
+		}
+		for _, c := range costs {
+			status.QuarterToDate += c.BillableCost
+			if !c.Date.Before(period.Start) {
+				status.MonthToDate += c.BillableCost
+			}
+		}
+	}
+	periodBegins, _ := w.Calendar.Bounds(period.Start)
+	elapsed := now.Sub(periodBegins).Hours() / 24
+	if elapsed < 1 {
+		elapsed = 1
+	}
+	status.Projected = status.MonthToDate / elapsed * float64(period.Days())
+	switch {
+	case status.MonthToDate > status.MonthlyBudget:
+		status.Status = notifier.BudgetStatusExceeded
+	case status.Projected > status.MonthlyBudget:
+		status.Status = notifier.BudgetStatusAtRisk
+	}
+	return status, nil
+}
+
+// digestItems grades each workload of a namespace over the window and turns its recommendations
+// into digest items under its cost policy: decommission goes to zombies, the rest to right-sizing.
+// check (optional) filters the recommendations of a workload given the pods of its latest hour.
+func digestItems(namespace string, stats []postgres.HourlyWorkloadStat, policyOf func(workload string) costmodel.CostPolicy,
+	check func(workload string, pods []string, recs []costmodel.Recommendation) ([]costmodel.Recommendation, error)) (zombies, rightsizing []notifier.DigestItem, err error) {
+	type acc struct {
+		billable, usage float64
+		latest          time.Time
+		rows            []postgres.HourlyWorkloadStat
+	}
+	byWorkload := make(map[string]*acc)
+	for _, st := range stats {
+		a, ok := byWorkload[st.WorkloadName]
+		if !ok {
+			a = &acc{}
+			byWorkload[st.WorkloadName] = a
+		}
+		a.billable += st.TotalBillableCost
+		a.usage += st.TotalUsageCost
+		if h := st.Timestamp.UTC().Truncate(time.Hour); h.After(a.latest) {
+			a.latest = h
+		}
+		a.rows = append(a.rows, st)
+	}
+	names := make([]string, 0, len(byWorkload))
+	for name := range byWorkload {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		a := byWorkload[name]
+		if a.billable <= 0 {
+			continue
+		}
+		// 与工作负载详情一致：等级取窗口效率，规格建议基于最新一小时的请求与 P95
+		var usage costmodel.WorkloadUsage
+		var pods []string
+		for _, st := range a.rows {
+			if !st.Timestamp.UTC().Truncate(time.Hour).Equal(a.latest) {
+				continue
+			}
+			if st.PodName != "" {
+				pods = append(pods, st.PodName)
+			}
+			usage.CPURequest += st.CPURequest
+			usage.CPUUsageP95 += st.CPUUsageP95
+			usage.MemRequest += st.MemRequest
+			usage.MemUsageP95 += st.MemUsageP95
+			usage.CPUHourlyCost += st.CPUBillableCost
+			usage.MemHourlyCost += st.MemBillableCost
+		}
+		policy := policyOf(name)
+		grade := policy.Thresholds().Grade(a.usage / a.billable * 100)
+		recs := costmodel.RecommendWithPolicy(grade, usage, policy)
+		if check != nil {
+			if recs, err = check(name, pods, recs); err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, rec := range recs {
+			item := notifier.DigestItem{
+				Namespace:          namespace,
+				Workload:           name,
+				Action:             string(rec.Action),
+				Resource:           rec.Resource,
+				CurrentRequest:     rec.CurrentRequest,
+				RecommendedRequest: rec.RecommendedRequest,
+				MonthlySavings:     rec.EstimatedMonthlySavings,
+				Reason:             rec.Reason,
+			}
+			if rec.Safety != nil && rec.Safety.Status == costmodel.SafetyFlagged {
+				item.Reason += "; review first: " + strings.Join(rec.Safety.Reasons, "; ")
+			}
+			if rec.Action == costmodel.ActionDecommission {
+				zombies = append(zombies, item)
+			} else {
+				rightsizing = append(rightsizing, item)
+			}
+		}
+	}
+	return zombies, rightsizing, nil
+}
+
+func sortBySavings(items []notifier.DigestItem) {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].MonthlySavings > items[j].MonthlySavings })
+}
@@ -0,0 +1,163 @@
+package etl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/notifier"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+type recordingDigestSender struct {
+	digests []notifier.TeamDigest
+}
+
+func (s *recordingDigestSender) SendDigests(ctx context.Context, digests []notifier.TeamDigest) (int, error) {
+	s.digests = append(s.digests, digests...)
+	return len(digests), nil
+}
+
+func TestDigestWorker_Run(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 9, 15, 12, 0, 0, 0, time.UTC)
+	hour := now.Add(-2 * time.Hour).Truncate(time.Hour)
+	for _, st := range []postgres.HourlyWorkloadStat{
+		// 5% 效率 -> Zombie
+		{Namespace: "pay", WorkloadName: "idle", Timestamp: hour, CPURequest: 1, CPUBillableCost: 1, TotalBillableCost: 1, TotalUsageCost: 0.05},
+		// 30% 效率 -> OverProvisioned，CPU 4 -> 1.2
+		{Namespace: "pay", WorkloadName: "fat", Timestamp: hour, CPURequest: 4, CPUUsageP95: 1, CPUBillableCost: 1, TotalBillableCost: 1, TotalUsageCost: 0.3},
+		{Namespace: "pay", WorkloadName: "ok", Timestamp: hour, CPURequest: 1, CPUUsageP95: 0.6, CPUBillableCost: 1, TotalBillableCost: 1, TotalUsageCost: 0.6},
+	} {
+		_ = repo.SaveHourlyWorkloadStat(ctx, st)
+	}
+	for day := 1; day <= 14; day++ {
+		_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "pay", Date: time.Date(2020, 9, day, 0, 0, 0, 0, time.UTC), BillableCost: 50})
+	}
+
+	sender := &recordingDigestSender{}
+	w := &DigestWorker{
+		Repo: repo,
+		Teams: []DigestTeam{
+			{Name: "payments", Namespaces: []string{"pay"}, MonthlyBudget: 1000},
+			{Name: "analytics", Namespaces: []string{"bi"}, Recipients: []string{"bi@example.com"}},
+		},
+		Owners: notifier.StaticOwnership{"pay": {"pay-owner@example.com"}},
+		Sender: sender,
+		now:    func() time.Time { return now },
+	}
+	res, err := w.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Teams != 2 || res.Sent != 2 || res.Recommendations != 2 {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	pay := sender.digests[1]
+	if pay.Team != "payments" || len(pay.Recipients) != 1 || pay.Recipients[0] != "pay-owner@example.com" {
+		t.Fatalf("unexpected payments digest: %+v", pay)
+	}
+	if len(pay.Zombies) != 1 || pay.Zombies[0].Workload != "idle" {
+		t.Errorf("expected idle as zombie, got %+v", pay.Zombies)
+	}
+	if len(pay.Rightsizing) != 1 || pay.Rightsizing[0].Workload != "fat" || pay.Rightsizing[0].Action != "downsize" {
+		t.Errorf("expected fat to be downsized, got %+v", pay.Rightsizing)
+	}
+	// 月初至今 700，按日均推算月底约 1448，超过 1000 的预算
+	if b := pay.Budget; b == nil || b.MonthToDate != 700 || b.Status != notifier.BudgetStatusAtRisk || b.Period != "FY2020 P09" {
+		t.Errorf("unexpected budget status: %+v", pay.Budget)
+	}
+	if sender.digests[0].Budget != nil {
+		t.Errorf("teams without budget must not report budget status")
+	}
+
+	// 4-4-5（周一为周首）：FY2020 P09 为 8/24 ~ 9/27，8 月底的成本计入本期
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "pay", Date: time.Date(2020, 8, 28, 0, 0, 0, 0, time.UTC), BillableCost: 100})
+	_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "pay", Date: time.Date(2020, 6, 20, 0, 0, 0, 0, time.UTC), BillableCost: 100})
+	w.Fiscal = costmodel.FiscalCalendar{Pattern: costmodel.Fiscal445, WeekStart: time.Monday}
+	sender.digests = nil
+	if _, err := w.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if b := sender.digests[1].Budget; b == nil || b.Period != "FY2020 P09" || b.MonthToDate != 800 || b.QuarterToDate != 800 {
+		t.Errorf("fiscal budget status = %+v, want 800 in P09 and Q3 (6/29 ~ 9/27)", b)
+	}
+}
+
+// suppressAll suppresses every reduction of the workloads in critical, as a violated SLO does.
+type suppressAll map[string]bool
+
+func (s suppressAll) Check(ctx context.Context, namespace, workload string, pods []string, recs []costmodel.Recommendation) ([]costmodel.Recommendation, []costmodel.Recommendation, error) {
+	status := ""
+	if s[workload] {
+		status = "critical"
+	}
+	proposed, suppressed := costmodel.CheckSafety(recs, costmodel.SafetySignals{SLOStatus: status}, costmodel.SafetyLimits{})
+	return proposed, suppressed, nil
+}
+
+func TestDigestWorker_SafetyChecks(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 9, 15, 12, 0, 0, 0, time.UTC)
+	hour := now.Add(-2 * time.Hour).Truncate(time.Hour)
+	for _, name := range []string{"fat", "fragile"} {
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "pay", WorkloadName: name, Timestamp: hour, CPURequest: 4, CPUUsageP95: 1, CPUBillableCost: 1, TotalBillableCost: 1, TotalUsageCost: 0.3,
+		})
+	}
+	sender := &recordingDigestSender{}
+	w := &DigestWorker{
+		Repo:   repo,
+		Teams:  []DigestTeam{{Name: "payments", Namespaces: []string{"pay"}, Recipients: []string{"pay@example.com"}}},
+		Sender: sender,
+		Safety: suppressAll{"fragile": true},
+		now:    func() time.Time { return now },
+	}
+	res, err := w.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Recommendations != 1 || len(sender.digests[0].Rightsizing) != 1 || sender.digests[0].Rightsizing[0].Workload != "fat" {
+		t.Errorf("rightsizing = %+v, want fragile suppressed", sender.digests[0].Rightsizing)
+	}
+}
+
+type staticTargets map[string]float64
+
+func (s staticTargets) EfficiencyTarget(ctx context.Context, namespace string) (float64, bool, error) {
+	target, ok := s[namespace]
+	return target, ok, nil
+}
+
+func TestDigestWorker_Targets(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 9, 15, 12, 0, 0, 0, time.UTC)
+	// 9/9..9/15：前 4 天 40%，后 3 天 70%
+	for day := 9; day <= 15; day++ {
+		usage := 40.0
+		if day > 12 {
+			usage = 70
+		}
+		_ = repo.SaveDailyNamespaceCost(ctx, postgres.DailyNamespaceCost{Namespace: "pay", Date: time.Date(2020, 9, day, 0, 0, 0, 0, time.UTC), BillableCost: 100, UsageCost: usage})
+	}
+
+	w := &DigestWorker{
+		Repo:    repo,
+		Teams:   []DigestTeam{{Name: "payments", Namespaces: []string{"pay", "ledger"}}},
+		Targets: staticTargets{"pay": 60},
+		now:     func() time.Time { return now },
+	}
+	digests, err := w.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	want := notifier.TargetStatus{Namespace: "pay", Target: 60, AverageEfficiency: 52.86, AttainmentRate: 42.86, CurrentStreak: 3}
+	if got := digests[0].Targets; len(got) != 1 || got[0] != want {
+		t.Errorf("Targets = %+v, want only %+v", got, want)
+	}
+}
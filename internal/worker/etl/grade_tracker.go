@@ -0,0 +1,165 @@
+// Package etl provides ETL workers for hourly and daily aggregation.
+// grade_tracker.go: 工作负载效率等级跟踪，等级变化时记录变更事件（带迟滞，避免指标噪声导致每小时来回跳变）。
+package etl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/eventbus"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// GradeEventStore persists grade change events (workload_grade_event).
+// *postgres.MockRepository satisfies this interface.
+type GradeEventStore interface {
+	SaveGradeChangeEvent(ctx context.Context, event postgres.GradeChangeEvent) error
+	ListGradeChangeEvents(ctx context.Context, filter postgres.GradeChangeEventFilter) ([]postgres.GradeChangeEvent, error)
+}
+
+// EventPublisher publishes domain events. *eventbus.Bus satisfies this interface.
+type EventPublisher interface {
+	Publish(payloads ...eventbus.Payload) error
+}
+
+// GradeTracker grades each workload from its hourly stats and records a GradeChangeEvent whenever the
+// grade changes. Grades use costmodel.GradeWithHysteresis, so a score hovering around a boundary keeps
+// its previous grade. The last grade per workload is cached and loaded from the store on first use.
+type GradeTracker struct {
+	Store GradeEventStore
+	// Publisher, when set, receives an eventbus.GradeChanged for every transition (not for first grades)
+	// and an eventbus.ZombieDetected whenever a workload is graded Zombie, including its first grade.
+	Publisher EventPublisher
+	// Hysteresis is the score margin in percentage points; 0 uses costmodel.DefaultGradeHysteresis.
+	Hysteresis float64
+
+	mu      sync.Mutex
+	current map[string]gradeState
+}
+
+type gradeState struct {
+	grade string
+	at    time.Time
+}
+
+type workloadScore struct {
+	namespace, name, kind string
+	billable, usage       float64
+	at                    time.Time
+}
+
+// Observe grades the workloads in stats (one score per workload over all its rows, timestamped with
+// its latest row) and returns the events recorded. Observations not newer than the last recorded
+// event of a workload are ignored, so re-running an old window never rewrites grade history.
+func (t *GradeTracker) Observe(ctx context.Context, stats []postgres.HourlyWorkloadStat) ([]postgres.GradeChangeEvent, error) {
+	return t.ObserveWithPolicies(ctx, stats, nil)
+}
+
+// ObserveWithPolicies is Observe grading each workload with the thresholds of its cost policy,
+// keyed by "namespace/workload"; workloads without a policy use the default thresholds.
+func (t *GradeTracker) ObserveWithPolicies(ctx context.Context, stats []postgres.HourlyWorkloadStat, policies map[string]costmodel.CostPolicy) ([]postgres.GradeChangeEvent, error) {
+	if t.Store == nil {
+		return nil, fmt.Errorf("grade tracker: no store configured")
+	}
+	scores := make(map[string]*workloadScore)
+	for _, st := range stats {
+		key := st.Namespace + "/" + st.WorkloadName
+		ws, ok := scores[key]
+		if !ok {
+			ws = &workloadScore{namespace: st.Namespace, name: st.WorkloadName, kind: st.WorkloadType}
+			scores[key] = ws
+		}
+		ws.billable += st.TotalBillableCost
+		ws.usage += st.TotalUsageCost
+		if st.Timestamp.After(ws.at) {
+			ws.at = st.Timestamp
+		}
+	}
+	keys := make([]string, 0, len(scores))
+	for k := range scores {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	margin := t.Hysteresis
+	if margin <= 0 {
+		margin = costmodel.DefaultGradeHysteresis
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == nil {
+		t.current = make(map[string]gradeState)
+	}
+
+	var recorded []postgres.GradeChangeEvent
+	for _, key := range keys {
+		ws := scores[key]
+		if ws.billable <= 0 {
+			continue // 无请求量的工作负载不评级
+		}
+		prev, err := t.lastGrade(ctx, key, ws)
+		if err != nil {
+			return recorded, err
+		}
+		if !prev.at.IsZero() && !ws.at.After(prev.at) {
+			continue
+		}
+		score := ws.usage / ws.billable * 100
+		thresholds := policies[key].Thresholds()
+		grade := string(thresholds.GradeWithHysteresis(score, costmodel.EfficiencyGrade(prev.grade), margin))
+		if grade == prev.grade {
+			continue
+		}
+		event := postgres.GradeChangeEvent{
+			ID:              uuid.New().String(),
+			Namespace:       ws.namespace,
+			WorkloadName:    ws.name,
+			WorkloadType:    ws.kind,
+			FromGrade:       prev.grade,
+			ToGrade:         grade,
+			EfficiencyScore: score,
+			OccurredAt:      ws.at,
+		}
+		if err := t.Store.SaveGradeChangeEvent(ctx, event); err != nil {
+			return recorded, fmt.Errorf("grade tracker: save event for %s: %w", key, err)
+		}
+		t.current[key] = gradeState{grade: grade, at: ws.at}
+		recorded = append(recorded, event)
+		if t.Publisher != nil {
+			// 事件已落库，outbox 满时仅丢失推送，历史可通过 API 查询。
+			if event.FromGrade != "" {
+				_ = t.Publisher.Publish(eventbus.GradeChangedFrom(event))
+			}
+			if grade == string(costmodel.GradeZombie) {
+				_ = t.Publisher.Publish(eventbus.ZombieDetected{
+					Namespace: ws.namespace, WorkloadName: ws.name, WorkloadType: ws.kind,
+					WasteCost: ws.billable - ws.usage, EfficiencyScore: score,
+				})
+			}
+		}
+	}
+	return recorded, nil
+}
+
+// lastGrade returns the cached grade of a workload, loading its latest event on a cache miss.
+// Caller holds t.mu.
+func (t *GradeTracker) lastGrade(ctx context.Context, key string, ws *workloadScore) (gradeState, error) {
+	if st, ok := t.current[key]; ok {
+		return st, nil
+	}
+	events, err := t.Store.ListGradeChangeEvents(ctx, postgres.GradeChangeEventFilter{Namespace: ws.namespace, WorkloadName: ws.name})
+	if err != nil {
+		return gradeState{}, fmt.Errorf("grade tracker: load history of %s: %w", key, err)
+	}
+	var st gradeState
+	if n := len(events); n > 0 {
+		st = gradeState{grade: events[n-1].ToGrade, at: events[n-1].OccurredAt}
+	}
+	t.current[key] = st
+	return st, nil
+}
@@ -0,0 +1,109 @@
+package etl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/eventbus"
+)
+
+type recordingPublisher struct {
+	payloads []eventbus.Payload
+}
+
+func (p *recordingPublisher) Publish(payloads ...eventbus.Payload) error {
+	p.payloads = append(p.payloads, payloads...)
+	return nil
+}
+
+func gradeStat(at time.Time, efficiency float64) postgres.HourlyWorkloadStat {
+	return postgres.HourlyWorkloadStat{
+		Namespace:         "app",
+		WorkloadName:      "api",
+		WorkloadType:      "Deployment",
+		Timestamp:         at,
+		TotalBillableCost: 100,
+		TotalUsageCost:    efficiency,
+	}
+}
+
+func TestGradeTracker_Hysteresis(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	pub := &recordingPublisher{}
+	tracker := &GradeTracker{Store: repo, Publisher: pub}
+	h0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		at         time.Time
+		efficiency float64
+		wantGrade  string // "" = no event
+	}{
+		{h0, 50, "Healthy"},             // 首次评级
+		{h0.Add(time.Hour), 38, ""},     // 边界附近抖动，保持 Healthy
+		{h0.Add(2 * time.Hour), 44, ""}, // 回到 Healthy 区间
+		{h0.Add(3 * time.Hour), 30, "OverProvisioned"},
+		{h0.Add(time.Hour), 5, ""}, // 旧窗口重算不改写历史
+	}
+	for i, step := range steps {
+		events, err := tracker.Observe(ctx, []postgres.HourlyWorkloadStat{gradeStat(step.at, step.efficiency)})
+		if err != nil {
+			t.Fatalf("step %d: Observe: %v", i, err)
+		}
+		switch {
+		case step.wantGrade == "" && len(events) != 0:
+			t.Errorf("step %d: expected no event, got %+v", i, events)
+		case step.wantGrade != "" && (len(events) != 1 || events[0].ToGrade != step.wantGrade):
+			t.Errorf("step %d: expected transition to %s, got %+v", i, step.wantGrade, events)
+		}
+	}
+
+	history, _ := repo.ListGradeChangeEvents(ctx, postgres.GradeChangeEventFilter{Namespace: "app", WorkloadName: "api"})
+	if len(history) != 2 || history[1].FromGrade != "Healthy" || history[1].ToGrade != "OverProvisioned" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+	if len(pub.payloads) != 1 {
+		t.Fatalf("expected only the transition to be published, got %d events", len(pub.payloads))
+	}
+	if ev, ok := pub.payloads[0].(eventbus.GradeChanged); !ok || ev.FromGrade != "Healthy" || ev.ToGrade != "OverProvisioned" {
+		t.Errorf("unexpected published event: %+v", pub.payloads[0])
+	}
+
+	// 新实例（如重启后）从历史加载当前等级，继续按迟滞判断。
+	restarted := &GradeTracker{Store: repo}
+	events, err := restarted.Observe(ctx, []postgres.HourlyWorkloadStat{gradeStat(h0.Add(4*time.Hour), 42)})
+	if err != nil {
+		t.Fatalf("Observe after restart: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("42%% should stay OverProvisioned after restart, got %+v", events)
+	}
+}
+
+func TestGradeTracker_PublishesZombieDetected(t *testing.T) {
+	ctx := context.Background()
+	pub := &recordingPublisher{}
+	tracker := &GradeTracker{Store: postgres.NewMockRepository(postgres.DefaultMockConfig()), Publisher: pub}
+	h0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 首次评级即为 Zombie：只发布 ZombieDetected（首次评级没有 GradeChanged）
+	if _, err := tracker.Observe(ctx, []postgres.HourlyWorkloadStat{gradeStat(h0, 4)}); err != nil {
+		t.Fatalf("Observe: %v", err)
+	}
+	if len(pub.payloads) != 1 {
+		t.Fatalf("published %d events, want 1", len(pub.payloads))
+	}
+	zombie, ok := pub.payloads[0].(eventbus.ZombieDetected)
+	if !ok || zombie.Namespace != "app" || zombie.WorkloadName != "api" || zombie.WasteCost != 96 || zombie.EfficiencyScore != 4 {
+		t.Errorf("unexpected event: %+v", pub.payloads[0])
+	}
+	// 仍为 Zombie 时不重复发布
+	if _, err := tracker.Observe(ctx, []postgres.HourlyWorkloadStat{gradeStat(h0.Add(time.Hour), 3)}); err != nil {
+		t.Fatalf("Observe: %v", err)
+	}
+	if len(pub.payloads) != 1 {
+		t.Errorf("unchanged grade published %d events, want 1", len(pub.payloads))
+	}
+}
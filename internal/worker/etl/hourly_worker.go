@@ -1,10 +1,297 @@
 // Package etl provides ETL workers for hourly and daily aggregation.
-// hourly_worker.go: placeholder for L2 hourly workload ETL (cost_hourly_workload).
+// hourly_worker.go: L2 hourly workload ETL (Prometheus -> cost_hourly_workload) with per-namespace error isolation.
 package etl
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+	"github.com/myxxhui/lighthouse-src/internal/worker/spool"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// SpoolKindHourlyWorkloadStats is the spool kind of hourly stat batches whose save failed.
+const SpoolKindHourlyWorkloadStats = "hourly_workload_stats"
+
+// HourlyStatStore persists hourly workload stats (cost_hourly_workload).
+// *postgres.MockRepository satisfies this interface.
+type HourlyStatStore interface {
+	SaveHourlyWorkloadStat(ctx context.Context, stat postgres.HourlyWorkloadStat) error
+}
+
+// PriceHistorySource lists unit price versions (cost_price_history).
+// *postgres.MockRepository satisfies this interface.
+type PriceHistorySource interface {
+	ListPriceVersions(ctx context.Context) ([]postgres.PriceVersion, error)
+}
+
 // HourlyWorker runs hourly aggregation from signal plane to control plane.
-// Phase1: placeholder only; no business logic.
-type HourlyWorker struct{}
+// Each namespace is an independent scope: a failing Prometheus query only loses that namespace,
+// results of the other namespaces are still persisted.
+type HourlyWorker struct {
+	K8s        k8s.Client
+	Prometheus prometheus.Client
+	Store      HourlyStatStore
+
+	// Global prices from Business.CostCalculation.
+	CPUPricePerCoreHour float64
+	MemPricePerGBHour   float64
+	// Prices, when set, prices each window with the version in effect at its start; the global
+	// prices apply to windows before the first version.
+	Prices PriceHistorySource
+
+	// Spool, when set, keeps stats whose save failed on disk; they are replayed at the start of
+	// the next Run instead of being dropped.
+	Spool *spool.Spool
+
+	// Grades, when set, records workload grade changes from the stats of each namespace.
+	Grades *GradeTracker
+
+	// NodePoolLabels, when set, records the node pool of each workload: the pool (the first of these
+	// labels set on the node, see costmodel.NodePoolOf) of the nodes running most of its pods.
+	// Failing to list nodes or pods leaves the pool empty; it never fails the run.
+	NodePoolLabels []string
+
+	// Calculator prices the metrics (Business.CostCalculation.Calculator, see costmodel.NewCalculator);
+	// nil is costmodel.DefaultCalculator.
+	Calculator costmodel.CostCalculator
+
+	// NonFinite decides what happens to metrics with NaN/Inf values (Business.CostCalculation.NonFinitePolicy).
+	// Handled values are counted into costmodel.SanitizeStatsFrom(ctx) when the caller attached one.
+	NonFinite costmodel.NonFinitePolicy
+}
+
+// ScopeFailure is the error of a single scope (namespace).
+type ScopeFailure struct {
+	Scope string
+	Err   error
+}
+
+// PartialFailure is returned when some scopes failed. Rows of the other scopes were written.
+type PartialFailure struct {
+	Failures []ScopeFailure
+}
+
+func (e *PartialFailure) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		parts = append(parts, f.Scope+": "+f.Err.Error())
+	}
+	return fmt.Sprintf("%d scope(s) failed: %s", len(e.Failures), strings.Join(parts, "; "))
+}
+
+// FailedScopes returns the failed scopes, sorted.
+func (e *PartialFailure) FailedScopes() []string {
+	scopes := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		scopes = append(scopes, f.Scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// Run computes hourly workload stats for run.WindowStart..run.WindowEnd, honoring the cost policy
+// annotations of namespaces and deployments (see k8s.ParseCostPolicy). When run.Scopes is set
+// only those namespaces are processed (targeted retry). Returns the rows written and, if any
+// namespace failed, a *PartialFailure; listing namespaces failing aborts the whole run.
+// The signature matches service.CalculationPipeline.
+func (w *HourlyWorker) Run(ctx context.Context, run postgres.CalculationRun) (int, error) {
+	if w.K8s == nil || w.Prometheus == nil || w.Store == nil {
+		return 0, fmt.Errorf("hourly etl: k8s, prometheus and store are required")
+	}
+	if w.Spool != nil {
+		// 回放失败的批次仍留在 spool 中，不影响本次计算。
+		_, _ = w.Spool.Replay(ctx, w.SpoolHandlers())
+	}
+	// namespace 注解提供其工作负载的默认成本策略，定向重跑时同样需要
+	list, err := w.K8s.GetNamespaces(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("hourly etl: list namespaces: %w", err)
+	}
+	nsPolicies := make(map[string]costmodel.CostPolicy, len(list))
+	for _, ns := range list {
+		nsPolicies[ns.Name], _ = k8s.ParseCostPolicy(ns.Annotations)
+	}
+	price, err := w.priceAt(ctx, run.WindowStart)
+	if err != nil {
+		return 0, err
+	}
+	poolOf := w.nodePools(ctx)
+	namespaces := run.Scopes
+	if len(namespaces) == 0 {
+		for _, ns := range list {
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+
+	rows := 0
+	var failure PartialFailure
+	for _, ns := range namespaces {
+		if err := ctx.Err(); err != nil {
+			return rows, err
+		}
+		n, err := w.runNamespace(ctx, ns, nsPolicies[ns], price, poolOf, run)
+		rows += n
+		if err != nil {
+			failure.Failures = append(failure.Failures, ScopeFailure{Scope: ns, Err: err})
+		}
+	}
+	if len(failure.Failures) > 0 {
+		return rows, &failure
+	}
+	return rows, nil
+}
+
+// priceAt returns the unit price in effect at t.
+func (w *HourlyWorker) priceAt(ctx context.Context, t time.Time) (costmodel.UnitPrice, error) {
+	fallback := costmodel.UnitPrice{CPUPricePerCoreHour: w.CPUPricePerCoreHour, MemPricePerGBHour: w.MemPricePerGBHour}
+	if w.Prices == nil {
+		return fallback, nil
+	}
+	versions, err := w.Prices.ListPriceVersions(ctx)
+	if err != nil {
+		return fallback, fmt.Errorf("hourly etl: list price versions: %w", err)
+	}
+	if price, ok := postgres.PriceHistoryOf(versions).At(t); ok {
+		return price, nil
+	}
+	return fallback, nil
+}
+
+// nodePools returns the pool of each node when NodePoolLabels is set, nil otherwise.
+func (w *HourlyWorker) nodePools(ctx context.Context) map[string]string {
+	if len(w.NodePoolLabels) == 0 {
+		return nil
+	}
+	nodes, err := w.K8s.GetNodes(ctx)
+	if err != nil {
+		return nil
+	}
+	poolOf := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		poolOf[n.Name] = costmodel.NodePoolOf(n.Labels, w.NodePoolLabels)
+	}
+	return poolOf
+}
+
+// workloadPool returns the pool of the nodes running most pods of a deployment, "" when unknown.
+func (w *HourlyWorker) workloadPool(ctx context.Context, namespace, deployment string, poolOf map[string]string) string {
+	if len(poolOf) == 0 {
+		return ""
+	}
+	pods, err := w.K8s.GetPods(ctx, namespace, deployment)
+	if err != nil {
+		return ""
+	}
+	nodes := make([]string, 0, len(pods))
+	for _, p := range pods {
+		nodes = append(nodes, p.NodeName)
+	}
+	return costmodel.MajorityNodePool(nodes, poolOf)
+}
+
+// runNamespace processes one namespace. Stats are computed for all workloads first and only
+// saved when every query succeeded, so a failed namespace leaves no partial rows behind.
+// If a save fails and Spool is set, the unsaved remainder is spooled and the namespace counts as done.
+func (w *HourlyWorker) runNamespace(ctx context.Context, namespace string, nsPolicy costmodel.CostPolicy, price costmodel.UnitPrice, poolOf map[string]string, run postgres.CalculationRun) (int, error) {
+	deployments, err := w.K8s.GetDeployments(ctx, namespace)
+	if err != nil {
+		return 0, fmt.Errorf("list deployments: %w", err)
+	}
+	var stats []postgres.HourlyWorkloadStat
+	policies := make(map[string]costmodel.CostPolicy, len(deployments))
+	for _, d := range deployments {
+		// 非法注解值被忽略，不影响成本计算
+		override, _ := k8s.ParseCostPolicy(d.Annotations)
+		policy := nsPolicy.Merge(override)
+		policies[namespace+"/"+d.Name] = policy
+		metrics, err := w.Prometheus.GetResourceMetrics(ctx, namespace, d.Name, "", run.WindowStart, run.WindowEnd)
+		if err != nil {
+			return 0, fmt.Errorf("query metrics for %s: %w", d.Name, err)
+		}
+		pool := w.workloadPool(ctx, namespace, d.Name, poolOf)
+		for _, m := range metrics {
+			keep, err := costmodel.SanitizeMetric(&m, w.NonFinite, costmodel.SanitizeStatsFrom(ctx))
+			if err != nil {
+				return 0, fmt.Errorf("metrics of %s at %s: %w", d.Name, m.Timestamp.Format(time.RFC3339), err)
+			}
+			if !keep {
+				continue
+			}
+			cost, err := costmodel.CalculateWithPolicy(w.Calculator, m, price.CPUPricePerCoreHour, price.MemPricePerGBHour, policy)
+			if err != nil {
+				return 0, fmt.Errorf("calculate cost for %s: %w", d.Name, err)
+			}
+			stats = append(stats, postgres.HourlyWorkloadStat{
+				Namespace:         namespace,
+				WorkloadName:      d.Name,
+				WorkloadType:      "Deployment",
+				Timestamp:         m.Timestamp,
+				CPURequest:        m.CPURequest,
+				CPUUsageP95:       m.CPUUsageP95,
+				MemRequest:        m.MemRequest,
+				MemUsageP95:       m.MemUsageP95,
+				CPUBillableCost:   cost.CPUBillableCost,
+				CPUUsageCost:      cost.CPUUsageCost,
+				CPUWasteCost:      cost.CPUWasteCost,
+				MemBillableCost:   cost.MemBillableCost,
+				MemUsageCost:      cost.MemUsageCost,
+				MemWasteCost:      int64(math.Round(cost.MemWasteCost)),
+				TotalBillableCost: cost.TotalBillableCost,
+				TotalUsageCost:    cost.TotalUsageCost,
+				TotalWasteCost:    cost.TotalWasteCost,
+				CostCenter:        policy.CostCenter,
+				NodePool:          pool,
+				CPUUsageSketch:    m.CPUUsageSketch,
+				MemUsageSketch:    m.MemUsageSketch,
+			})
+		}
+	}
+	for i, st := range stats {
+		if err := w.Store.SaveHourlyWorkloadStat(ctx, st); err != nil {
+			if w.Spool == nil {
+				return i, fmt.Errorf("save hourly stat: %w", err)
+			}
+			if _, serr := w.Spool.Enqueue(SpoolKindHourlyWorkloadStats, stats[i:]); serr != nil {
+				return i, fmt.Errorf("save hourly stat: %w (spool: %v)", err, serr)
+			}
+			return i, nil
+		}
+	}
+	if w.Grades != nil {
+		if _, err := w.Grades.ObserveWithPolicies(ctx, stats, policies); err != nil {
+			return len(stats), err
+		}
+	}
+	return len(stats), nil
+}
+
+// SpoolHandlers returns the replay handlers for batches spooled by this worker.
+func (w *HourlyWorker) SpoolHandlers() spool.Handlers {
+	return spool.Handlers{SpoolKindHourlyWorkloadStats: ReplayHourlyWorkloadStats(w.Store)}
+}
 
-// Run is a placeholder. Implementation in Phase2.
-func (w *HourlyWorker) Run() error { return nil }
+// ReplayHourlyWorkloadStats returns a spool handler that saves a spooled batch of hourly stats.
+// Saves are upserts keyed by (hour, namespace, workload), so replaying a batch twice is safe.
+func ReplayHourlyWorkloadStats(store HourlyStatStore) spool.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var stats []postgres.HourlyWorkloadStat
+		if err := json.Unmarshal(payload, &stats); err != nil {
+			return fmt.Errorf("decode hourly stats: %w", err)
+		}
+		for _, st := range stats {
+			if err := store.SaveHourlyWorkloadStat(ctx, st); err != nil {
+				return fmt.Errorf("save hourly stat: %w", err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,365 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
+	"github.com/myxxhui/lighthouse-src/internal/worker/spool"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// flakyPrometheus fails every query for the namespaces in failing.
+type flakyPrometheus struct {
+	prometheus.Client
+	failing map[string]bool
+	queried map[string]int
+}
+
+func (f *flakyPrometheus) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	f.queried[namespace]++
+	if f.failing[namespace] {
+		return nil, errors.New("query timeout")
+	}
+	return f.Client.GetResourceMetrics(ctx, namespace, workload, pod, startTime, endTime)
+}
+
+func TestHourlyWorker_PartialFailure(t *testing.T) {
+	ctx := context.Background()
+	k8sConfig := k8s.DefaultMockConfig()
+	k8sConfig.Namespaces = []string{"app", "batch", "web"}
+	k8sConfig.LatencyMs = 0
+	promConfig := prometheus.DefaultMockConfig()
+	promConfig.LatencyMs = 0
+	prom := &flakyPrometheus{
+		Client:  prometheus.NewMockClient(promConfig),
+		failing: map[string]bool{"batch": true},
+		queried: map[string]int{},
+	}
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	w := &HourlyWorker{
+		K8s:                 k8s.NewMockClient(k8sConfig),
+		Prometheus:          prom,
+		Store:               repo,
+		CPUPricePerCoreHour: 0.1,
+		MemPricePerGBHour:   0.01,
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	run := postgres.CalculationRun{WindowStart: start, WindowEnd: start.Add(time.Hour)}
+
+	rows, err := w.Run(ctx, run)
+	var partial *PartialFailure
+	if !errors.As(err, &partial) {
+		t.Fatalf("Run error = %v, want *PartialFailure", err)
+	}
+	if got := partial.FailedScopes(); len(got) != 1 || got[0] != "batch" {
+		t.Errorf("FailedScopes = %v, want [batch]", got)
+	}
+	if rows == 0 {
+		t.Error("successful namespaces should still write rows")
+	}
+	stats, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "batch", StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("ListHourlyWorkloadStats: %v", err)
+	}
+	if len(stats) != 0 {
+		t.Errorf("failed namespace wrote %d rows, want 0", len(stats))
+	}
+
+	// Targeted retry only touches the failed scope.
+	prom.failing = nil
+	prom.queried = map[string]int{}
+	run.Scopes = partial.FailedScopes()
+	if _, err := w.Run(ctx, run); err != nil {
+		t.Fatalf("retry Run: %v", err)
+	}
+	if prom.queried["app"] != 0 || prom.queried["web"] != 0 || prom.queried["batch"] == 0 {
+		t.Errorf("retry queried %v, want only batch", prom.queried)
+	}
+}
+
+func TestHourlyWorker_Calculator(t *testing.T) {
+	ctx := context.Background()
+	k8sConfig := k8s.DefaultMockConfig()
+	k8sConfig.Namespaces = []string{"app"}
+	k8sConfig.LatencyMs = 0
+	promConfig := prometheus.DefaultMockConfig()
+	promConfig.LatencyMs = 0
+	calc, err := costmodel.NewCalculator(costmodel.MinimumChargeCalculatorName, map[string]interface{}{"min_cpu_cores": 64})
+	if err != nil {
+		t.Fatalf("NewCalculator: %v", err)
+	}
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	w := &HourlyWorker{
+		K8s:                 k8s.NewMockClient(k8sConfig),
+		Prometheus:          prometheus.NewMockClient(promConfig),
+		Store:               repo,
+		CPUPricePerCoreHour: 0.1,
+		MemPricePerGBHour:   0.01,
+		Calculator:          calc,
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := w.Run(ctx, postgres.CalculationRun{WindowStart: start, WindowEnd: start.Add(time.Hour)}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	stats, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "app", StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil || len(stats) == 0 {
+		t.Fatalf("ListHourlyWorkloadStats: %d rows, %v", len(stats), err)
+	}
+	for _, st := range stats {
+		if math.Abs(st.CPUBillableCost-6.4) > 1e-6 {
+			t.Errorf("%s CPU billable = %v, want the 64 core minimum charge 6.4", st.WorkloadName, st.CPUBillableCost)
+		}
+	}
+}
+
+// flakyStore fails saves while down is set.
+type flakyStore struct {
+	HourlyStatStore
+	down bool
+}
+
+func (f *flakyStore) SaveHourlyWorkloadStat(ctx context.Context, stat postgres.HourlyWorkloadStat) error {
+	if f.down {
+		return errors.New("connection refused")
+	}
+	return f.HourlyStatStore.SaveHourlyWorkloadStat(ctx, stat)
+}
+
+func TestHourlyWorker_SpoolsFailedWrites(t *testing.T) {
+	ctx := context.Background()
+	k8sConfig := k8s.DefaultMockConfig()
+	k8sConfig.Namespaces = []string{"app"}
+	k8sConfig.LatencyMs = 0
+	promConfig := prometheus.DefaultMockConfig()
+	promConfig.LatencyMs = 0
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	store := &flakyStore{HourlyStatStore: repo, down: true}
+	sp, err := spool.Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("spool.Open: %v", err)
+	}
+	w := &HourlyWorker{
+		K8s:                 k8s.NewMockClient(k8sConfig),
+		Prometheus:          prometheus.NewMockClient(promConfig),
+		Store:               store,
+		CPUPricePerCoreHour: 0.1,
+		MemPricePerGBHour:   0.01,
+		Spool:               sp,
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	run := postgres.CalculationRun{WindowStart: start, WindowEnd: start.Add(time.Hour)}
+
+	rows, err := w.Run(ctx, run)
+	if err != nil || rows != 0 {
+		t.Fatalf("Run with store down = %d, %v; want 0 rows spooled without error", rows, err)
+	}
+	if st := sp.Stats(); st.Pending != 1 {
+		t.Fatalf("spool pending = %d, want 1", st.Pending)
+	}
+
+	// Next cycle replays the spooled batch before computing.
+	store.down = false
+	if _, err := w.Run(ctx, postgres.CalculationRun{WindowStart: start.Add(time.Hour), WindowEnd: start.Add(2 * time.Hour)}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if st := sp.Stats(); st.Pending != 0 || st.Replayed != 1 {
+		t.Errorf("spool stats = %+v, want replayed", st)
+	}
+	stats, _ := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "app", StartTime: start, EndTime: start.Add(time.Hour)})
+	if len(stats) == 0 {
+		t.Error("spooled stats of the first window were not replayed into the store")
+	}
+}
+
+func TestHourlyWorker_CostPolicyAnnotations(t *testing.T) {
+	ctx := context.Background()
+	k8sConfig := k8s.DefaultMockConfig()
+	k8sConfig.Namespaces = []string{"dr"}
+	k8sConfig.LatencyMs = 0
+	k8sConfig.Annotations = map[string]map[string]string{
+		"dr": {k8s.AnnotationExcludeFromWaste: "true", k8s.AnnotationCostCenter: "cc-1001"},
+	}
+	promConfig := prometheus.DefaultMockConfig()
+	promConfig.LatencyMs = 0
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	w := &HourlyWorker{
+		K8s:                 k8s.NewMockClient(k8sConfig),
+		Prometheus:          prometheus.NewMockClient(promConfig),
+		Store:               repo,
+		CPUPricePerCoreHour: 0.1,
+		MemPricePerGBHour:   0.01,
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := w.Run(ctx, postgres.CalculationRun{WindowStart: start, WindowEnd: start.Add(time.Hour)}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	stats, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "dr", StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil || len(stats) == 0 {
+		t.Fatalf("ListHourlyWorkloadStats = %d rows, %v", len(stats), err)
+	}
+	for _, st := range stats {
+		if st.TotalWasteCost != 0 || st.TotalUsageCost != st.TotalBillableCost {
+			t.Errorf("%s: waste = %v, usage = %v, billable = %v; want no waste", st.WorkloadName, st.TotalWasteCost, st.TotalUsageCost, st.TotalBillableCost)
+		}
+		if st.CostCenter != "cc-1001" {
+			t.Errorf("%s: cost center = %q, want cc-1001", st.WorkloadName, st.CostCenter)
+		}
+	}
+}
+
+// nanPrometheus corrupts the CPU usage of every metric of the namespaces in corrupt.
+type nanPrometheus struct {
+	prometheus.Client
+	corrupt map[string]bool
+}
+
+func (n *nanPrometheus) GetResourceMetrics(ctx context.Context, namespace, workload, pod string, startTime, endTime time.Time) ([]costmodel.ResourceMetric, error) {
+	metrics, err := n.Client.GetResourceMetrics(ctx, namespace, workload, pod, startTime, endTime)
+	if n.corrupt[namespace] {
+		for i := range metrics {
+			metrics[i].CPUUsageP95 = math.NaN()
+		}
+	}
+	return metrics, err
+}
+
+func TestHourlyWorker_NonFinitePolicy(t *testing.T) {
+	k8sConfig := k8s.DefaultMockConfig()
+	k8sConfig.Namespaces = []string{"app", "batch"}
+	k8sConfig.LatencyMs = 0
+	promConfig := prometheus.DefaultMockConfig()
+	promConfig.LatencyMs = 0
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	run := postgres.CalculationRun{WindowStart: start, WindowEnd: start.Add(time.Hour)}
+
+	newWorker := func(policy costmodel.NonFinitePolicy) (*HourlyWorker, *postgres.MockRepository) {
+		repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+		return &HourlyWorker{
+			K8s:                 k8s.NewMockClient(k8sConfig),
+			Prometheus:          &nanPrometheus{Client: prometheus.NewMockClient(promConfig), corrupt: map[string]bool{"batch": true}},
+			Store:               repo,
+			CPUPricePerCoreHour: 0.1,
+			MemPricePerGBHour:   0.01,
+			NonFinite:           policy,
+		}, repo
+	}
+	batchRows := func(repo *postgres.MockRepository) []postgres.HourlyWorkloadStat {
+		stats, err := repo.ListHourlyWorkloadStats(context.Background(), postgres.HourlyWorkloadStatFilter{Namespace: "batch", StartTime: start, EndTime: start.Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("ListHourlyWorkloadStats: %v", err)
+		}
+		return stats
+	}
+
+	t.Run("zero", func(t *testing.T) {
+		w, repo := newWorker(costmodel.NonFiniteZero)
+		var sanitized costmodel.SanitizeStats
+		if _, err := w.Run(costmodel.WithSanitizeStats(context.Background(), &sanitized), run); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		stats := batchRows(repo)
+		// 同一小时的多条指标 upsert 为一行，因此置 0 的值不少于落库行数
+		if len(stats) == 0 || sanitized.ZeroedValues < len(stats) || sanitized.DroppedRows != 0 {
+			t.Fatalf("sanitized = %+v for %d batch rows, want every corrupt value zeroed", sanitized, len(stats))
+		}
+		for _, st := range stats {
+			if math.IsNaN(st.TotalUsageCost) || st.CPUUsageP95 != 0 {
+				t.Errorf("stat %s kept a non-finite value: %+v", st.WorkloadName, st)
+			}
+		}
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		w, repo := newWorker(costmodel.NonFiniteDrop)
+		var sanitized costmodel.SanitizeStats
+		if _, err := w.Run(costmodel.WithSanitizeStats(context.Background(), &sanitized), run); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if n := len(batchRows(repo)); n != 0 || sanitized.DroppedRows == 0 {
+			t.Errorf("batch rows = %d, sanitized = %+v, want every corrupt row dropped", n, sanitized)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		w, _ := newWorker(costmodel.NonFiniteFail)
+		_, err := w.Run(context.Background(), run)
+		var partial *PartialFailure
+		if !errors.As(err, &partial) {
+			t.Fatalf("Run error = %v, want *PartialFailure", err)
+		}
+		if len(partial.Failures) != 1 || partial.Failures[0].Scope != "batch" || !errors.Is(partial.Failures[0].Err, costmodel.ErrNonFinite) {
+			t.Errorf("failures = %+v, want batch failing with ErrNonFinite", partial.Failures)
+		}
+	})
+}
+
+// pooledK8s labels node-1..node-3 "general" and node-4 "gpu".
+type pooledK8s struct {
+	k8s.Client
+}
+
+func (p *pooledK8s) GetNodes(ctx context.Context) ([]k8s.Node, error) {
+	nodes, err := p.Client.GetNodes(ctx)
+	for i := range nodes {
+		pool := "general"
+		if nodes[i].Name == "node-4" {
+			pool = "gpu"
+		}
+		nodes[i].Labels = map[string]string{"eks.amazonaws.com/nodegroup": pool}
+	}
+	return nodes, err
+}
+
+func TestHourlyWorker_NodePools(t *testing.T) {
+	ctx := context.Background()
+	k8sConfig := k8s.DefaultMockConfig()
+	k8sConfig.Namespaces = []string{"app"}
+	k8sConfig.LatencyMs = 0
+	promConfig := prometheus.DefaultMockConfig()
+	promConfig.LatencyMs = 0
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	w := &HourlyWorker{
+		K8s:                 &pooledK8s{Client: k8s.NewMockClient(k8sConfig)},
+		Prometheus:          prometheus.NewMockClient(promConfig),
+		Store:               repo,
+		CPUPricePerCoreHour: 0.1,
+		MemPricePerGBHour:   0.01,
+		NodePoolLabels:      costmodel.DefaultNodePoolLabels,
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := w.Run(ctx, postgres.CalculationRun{WindowStart: start, WindowEnd: start.Add(time.Hour)}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	stats, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "app", StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil || len(stats) == 0 {
+		t.Fatalf("ListHourlyWorkloadStats = %d rows, %v", len(stats), err)
+	}
+	for _, st := range stats {
+		if st.NodePool != "general" && st.NodePool != "gpu" {
+			t.Errorf("%s: node pool = %q, want general or gpu", st.WorkloadName, st.NodePool)
+		}
+	}
+	pooled, err := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{NodePool: stats[0].NodePool, StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil || len(pooled) == 0 {
+		t.Fatalf("ListHourlyWorkloadStats(pool %s) = %d rows, %v", stats[0].NodePool, len(pooled), err)
+	}
+
+	// 未配置节点池标签时不查询节点，池为空
+	w.NodePoolLabels = nil
+	next := start.Add(time.Hour)
+	if _, err := w.Run(ctx, postgres.CalculationRun{WindowStart: next, WindowEnd: next.Add(time.Hour)}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	stats, _ = repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "app", StartTime: next, EndTime: next.Add(time.Hour)})
+	for _, st := range stats {
+		if st.NodePool != "" {
+			t.Errorf("%s: node pool = %q without pool labels, want empty", st.WorkloadName, st.NodePool)
+		}
+	}
+}
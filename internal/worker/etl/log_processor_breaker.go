@@ -0,0 +1,20 @@
+// Package etl log_processor_breaker.go: circuit-breaker wrapper for the ClickHouse Log Processor.
+package etl
+
+import (
+	"context"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/breaker"
+)
+
+// BreakerLogProcessor wraps a LogProcessor with the breaker of target (ClickHouse cluster/host).
+type BreakerLogProcessor struct {
+	Processor LogProcessor
+	Target    string
+	Breakers  *breaker.Set
+}
+
+// Process implements LogProcessor; returns an error wrapping breaker.ErrOpen while the circuit is open.
+func (p *BreakerLogProcessor) Process(ctx context.Context, batch LogBatch) error {
+	return p.Breakers.Do(p.Target, func() error { return p.Processor.Process(ctx, batch) })
+}
@@ -0,0 +1,21 @@
+// Package etl log_processor_retry.go: retry wrapper for the ClickHouse Log Processor.
+package etl
+
+import (
+	"context"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/retry"
+)
+
+// RetryLogProcessor wraps a LogProcessor with a retrier; wrap it inside BreakerLogProcessor.
+// A batch is retried as a whole, so the processor must tolerate re-inserting a partially
+// written batch (ClickHouse insert deduplication).
+type RetryLogProcessor struct {
+	Processor LogProcessor
+	Retrier   *retry.Retrier
+}
+
+// Process implements LogProcessor.
+func (p *RetryLogProcessor) Process(ctx context.Context, batch LogBatch) error {
+	return p.Retrier.Do(ctx, func() error { return p.Processor.Process(ctx, batch) })
+}
@@ -0,0 +1,111 @@
+// Package etl provides ETL workers for hourly and daily aggregation.
+// namespace_sync.go: K8s namespace 同步任务，维护 namespace_lifecycle（first_seen / last_seen / deleted_at）。
+package etl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// NamespaceLifecycleStore persists namespace lifecycle records (namespace_lifecycle).
+// *postgres.MockRepository satisfies this interface.
+type NamespaceLifecycleStore interface {
+	SaveNamespaceLifecycle(ctx context.Context, lifecycle postgres.NamespaceLifecycle) error
+	ListNamespaceLifecycles(ctx context.Context) ([]postgres.NamespaceLifecycle, error)
+}
+
+// NamespaceSyncWorker compares the namespaces in the cluster with the stored lifecycle records:
+// new namespaces get FirstSeen, existing ones LastSeen, and known namespaces that are gone are
+// marked deleted. A namespace that reappears is un-deleted and keeps its original FirstSeen.
+type NamespaceSyncWorker struct {
+	K8s   k8s.Client
+	Store NamespaceLifecycleStore
+
+	now func() time.Time
+}
+
+// NamespaceSyncResult is the outcome of one sync pass.
+type NamespaceSyncResult struct {
+	Created  int `json:"created"`
+	Restored int `json:"restored"` // previously deleted namespaces seen again
+	Deleted  int `json:"deleted"`
+	Active   int `json:"active"`
+}
+
+// Run executes one sync pass. Listing namespaces failing aborts the pass without marking anything
+// deleted, so a K8s API outage never terminates every namespace.
+func (w *NamespaceSyncWorker) Run(ctx context.Context) (*NamespaceSyncResult, error) {
+	if w.K8s == nil || w.Store == nil {
+		return nil, fmt.Errorf("namespace sync: k8s and store are required")
+	}
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+	ts := now().UTC()
+
+	namespaces, err := w.K8s.GetNamespaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("namespace sync: list namespaces: %w", err)
+	}
+	records, err := w.Store.ListNamespaceLifecycles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("namespace sync: list lifecycles: %w", err)
+	}
+	known := make(map[string]postgres.NamespaceLifecycle, len(records))
+	for _, r := range records {
+		known[r.Namespace] = r
+	}
+
+	result := &NamespaceSyncResult{}
+	seen := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		seen[ns.Name] = true
+		rec, ok := known[ns.Name]
+		switch {
+		case !ok:
+			rec = postgres.NamespaceLifecycle{Namespace: ns.Name, FirstSeen: ts}
+			if !ns.CreationTimestamp.IsZero() && ns.CreationTimestamp.Before(ts) {
+				rec.FirstSeen = ns.CreationTimestamp.UTC()
+			}
+			result.Created++
+		case rec.Terminated():
+			rec.DeletedAt = nil
+			result.Restored++
+		}
+		rec.LastSeen = ts
+		if err := w.Store.SaveNamespaceLifecycle(ctx, rec); err != nil {
+			return result, fmt.Errorf("namespace sync: save %s: %w", ns.Name, err)
+		}
+		result.Active++
+	}
+
+	for _, rec := range records {
+		if seen[rec.Namespace] || rec.Terminated() {
+			continue
+		}
+		deletedAt := ts
+		rec.DeletedAt = &deletedAt
+		if err := w.Store.SaveNamespaceLifecycle(ctx, rec); err != nil {
+			return result, fmt.Errorf("namespace sync: mark %s deleted: %w", rec.Namespace, err)
+		}
+		result.Deleted++
+	}
+	return result, nil
+}
+
+// Job returns the sync pass as a scheduler job.
+func (w *NamespaceSyncWorker) Job(interval time.Duration) Job {
+	return Job{
+		Name:     "namespace-sync",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, err := w.Run(ctx)
+			return err
+		},
+	}
+}
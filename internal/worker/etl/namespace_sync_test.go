@@ -0,0 +1,88 @@
+package etl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+func newNamespaceMock(namespaces ...string) k8s.Client {
+	cfg := k8s.DefaultMockConfig()
+	cfg.LatencyMs = 0
+	cfg.Namespaces = namespaces
+	return k8s.NewMockClient(cfg)
+}
+
+func TestNamespaceSyncWorker_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	w := &NamespaceSyncWorker{K8s: newNamespaceMock("app-prod", "legacy"), Store: repo, now: func() time.Time { return now }}
+
+	res, err := w.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Created != 2 || res.Active != 2 || res.Deleted != 0 {
+		t.Errorf("first sync: unexpected result %+v", res)
+	}
+
+	// legacy 被删除
+	now = now.Add(time.Hour)
+	w.K8s = newNamespaceMock("app-prod")
+	if res, err = w.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Deleted != 1 || res.Created != 0 {
+		t.Errorf("second sync: unexpected result %+v", res)
+	}
+	records, _ := repo.ListNamespaceLifecycles(ctx)
+	byName := make(map[string]postgres.NamespaceLifecycle)
+	for _, r := range records {
+		byName[r.Namespace] = r
+	}
+	legacy := byName["legacy"]
+	if !legacy.Terminated() || !legacy.DeletedAt.Equal(now) {
+		t.Errorf("legacy should be deleted at %v, got %+v", now, legacy)
+	}
+	if !byName["app-prod"].LastSeen.Equal(now) || byName["app-prod"].Terminated() {
+		t.Errorf("app-prod should be active and last seen at %v, got %+v", now, byName["app-prod"])
+	}
+
+	// 重新创建后恢复，first_seen 保持不变
+	now = now.Add(time.Hour)
+	w.K8s = newNamespaceMock("app-prod", "legacy")
+	if res, err = w.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Restored != 1 {
+		t.Errorf("third sync: expected legacy restored, got %+v", res)
+	}
+	records, _ = repo.ListNamespaceLifecycles(ctx)
+	for _, r := range records {
+		if r.Namespace == "legacy" && (r.Terminated() || !r.FirstSeen.Equal(legacy.FirstSeen)) {
+			t.Errorf("restored legacy: unexpected record %+v", r)
+		}
+	}
+}
+
+func TestNamespaceSyncWorker_ListFailureKeepsRecords(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	_ = repo.SaveNamespaceLifecycle(ctx, postgres.NamespaceLifecycle{Namespace: "app-prod", FirstSeen: time.Now()})
+
+	cfg := k8s.DefaultMockConfig()
+	cfg.LatencyMs = 0
+	cfg.ErrorRate = 1
+	w := &NamespaceSyncWorker{K8s: k8s.NewMockClient(cfg), Store: repo}
+	if _, err := w.Run(ctx); err == nil {
+		t.Fatal("expected error when listing namespaces fails")
+	}
+	records, _ := repo.ListNamespaceLifecycles(ctx)
+	if len(records) != 1 || records[0].Terminated() {
+		t.Errorf("namespaces must not be marked deleted on K8s errors, got %+v", records)
+	}
+}
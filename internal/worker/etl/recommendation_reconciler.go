@@ -0,0 +1,166 @@
+// Package etl provides ETL workers for hourly and daily aggregation.
+// recommendation_reconciler.go: 已下发建议的采纳对账——每日对比工作负载当前请求量与建议目标，更新采纳进度与已实现节省。
+package etl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
+)
+
+// DefaultRecommendationLookback is how long issued recommendations keep being reconciled.
+const DefaultRecommendationLookback = 90 * 24 * time.Hour
+
+// RecommendationStore persists issued recommendations (workload_recommendation).
+// *postgres.MockRepository satisfies this interface.
+type RecommendationStore interface {
+	SaveIssuedRecommendation(ctx context.Context, rec postgres.IssuedRecommendation) error
+	ListIssuedRecommendations(ctx context.Context, filter postgres.IssuedRecommendationFilter) ([]postgres.IssuedRecommendation, error)
+}
+
+// RecommendationReconciler compares the current requests of each workload (its latest hour of the
+// last day) with the recommendations issued to it. Applied recommendations are re-checked too, so
+// requests drifting back reopen them. A workload without stats in the last day is gone, which
+// realizes decommission and downsize recommendations.
+type RecommendationReconciler struct {
+	Repo  postgres.Repository
+	Store RecommendationStore
+	// Lookback limits reconciliation to recommendations issued within it; 0 uses DefaultRecommendationLookback.
+	Lookback time.Duration
+
+	now func() time.Time
+}
+
+// RecommendationReconcileResult is the outcome of one reconciliation pass.
+type RecommendationReconcileResult struct {
+	Checked int `json:"checked"`
+	Applied int `json:"applied"`
+	Partial int `json:"partial"`
+	Open    int `json:"open"`
+}
+
+// Run reconciles the recommendations issued within the lookback.
+func (r *RecommendationReconciler) Run(ctx context.Context) (*RecommendationReconcileResult, error) {
+	if r.Repo == nil || r.Store == nil {
+		return nil, fmt.Errorf("recommendation reconcile: repository and store are required")
+	}
+	now := time.Now
+	if r.now != nil {
+		now = r.now
+	}
+	ts := now().UTC()
+	lookback := r.Lookback
+	if lookback <= 0 {
+		lookback = DefaultRecommendationLookback
+	}
+	recs, err := r.Store.ListIssuedRecommendations(ctx, postgres.IssuedRecommendationFilter{IssuedAfter: ts.Add(-lookback)})
+	if err != nil {
+		return nil, fmt.Errorf("recommendation reconcile: list recommendations: %w", err)
+	}
+
+	result := &RecommendationReconcileResult{}
+	requests := make(map[string]*workloadRequests)
+	for _, rec := range recs {
+		key := rec.Namespace + "/" + rec.WorkloadName
+		cur, ok := requests[key]
+		if !ok {
+			if cur, err = r.currentRequests(ctx, rec.Namespace, rec.WorkloadName, ts); err != nil {
+				return result, err
+			}
+			requests[key] = cur
+		}
+		var fraction float64
+		var status costmodel.AdoptionStatus
+		switch {
+		case cur == nil:
+			rec.CurrentRequest = 0
+			fraction, status = 0, costmodel.AdoptionOpen
+			if rec.Action != string(costmodel.ActionUpsize) {
+				fraction, status = 1, costmodel.AdoptionApplied
+			}
+		case rec.Action == string(costmodel.ActionDecommission):
+			// 缩容到零（请求量清零）视为已下线
+			rec.CurrentRequest = cur.cpu
+			fraction, status = 0, costmodel.AdoptionOpen
+			if cur.cpu == 0 && cur.mem == 0 {
+				fraction, status = 1, costmodel.AdoptionApplied
+			}
+		default:
+			rec.CurrentRequest = cur.cpu
+			if rec.Resource == "memory" {
+				rec.CurrentRequest = cur.mem
+			}
+			fraction, status = costmodel.Adoption(rec.BaselineRequest, rec.RecommendedRequest, rec.CurrentRequest)
+		}
+		rec.AdoptedFraction = fraction
+		rec.Status = string(status)
+		rec.RealizedMonthlySavings = math.Round(rec.EstimatedMonthlySavings*fraction*100) / 100
+		rec.CheckedAt = ts
+		if err := r.Store.SaveIssuedRecommendation(ctx, rec); err != nil {
+			return result, fmt.Errorf("recommendation reconcile: save %s/%s: %w", rec.Namespace, rec.WorkloadName, err)
+		}
+		result.Checked++
+		switch status {
+		case costmodel.AdoptionApplied:
+			result.Applied++
+		case costmodel.AdoptionPartial:
+			result.Partial++
+		default:
+			result.Open++
+		}
+	}
+	return result, nil
+}
+
+// Job returns the reconciliation pass as a scheduler job (daily by default).
+func (r *RecommendationReconciler) Job(interval time.Duration) Job {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return Job{
+		Name:     "recommendation-reconcile",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, err := r.Run(ctx)
+			return err
+		},
+	}
+}
+
+// workloadRequests are the summed requests of a workload's pods in one hour.
+type workloadRequests struct {
+	cpu float64 // cores
+	mem float64 // bytes
+}
+
+// currentRequests returns the requests of the workload's latest hour within the last day; nil when
+// the workload has no stats in it.
+func (r *RecommendationReconciler) currentRequests(ctx context.Context, namespace, workload string, now time.Time) (*workloadRequests, error) {
+	stats, err := r.Repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{
+		Namespace: namespace, WorkloadName: workload, StartTime: now.Add(-24 * time.Hour), EndTime: now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("recommendation reconcile: list stats of %s/%s: %w", namespace, workload, err)
+	}
+	var latest time.Time
+	for _, st := range stats {
+		if h := st.Timestamp.UTC().Truncate(time.Hour); h.After(latest) {
+			latest = h
+		}
+	}
+	if latest.IsZero() {
+		return nil, nil
+	}
+	cur := &workloadRequests{}
+	for _, st := range stats {
+		if st.Timestamp.UTC().Truncate(time.Hour).Equal(latest) {
+			cur.cpu += st.CPURequest
+			cur.mem += float64(st.MemRequest)
+		}
+	}
+	return cur, nil
+}
@@ -0,0 +1,92 @@
+package etl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/eventbus"
+)
+
+func TestRecommendationReconciler(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	issued := time.Date(2020, 9, 15, 12, 0, 0, 0, time.UTC)
+	hour := issued.Add(-2 * time.Hour)
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "pay", WorkloadName: "idle", Timestamp: hour, CPURequest: 1, CPUBillableCost: 1, TotalBillableCost: 1, TotalUsageCost: 0.05})
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "pay", WorkloadName: "fat", Timestamp: hour, CPURequest: 4, CPUUsageP95: 1, CPUBillableCost: 1, TotalBillableCost: 1, TotalUsageCost: 0.3})
+
+	pub := &recordingPublisher{}
+	digest := &DigestWorker{
+		Repo:            repo,
+		Teams:           []DigestTeam{{Name: "payments", Namespaces: []string{"pay"}, Recipients: []string{"pay@example.com"}}},
+		Sender:          &recordingDigestSender{},
+		Recommendations: repo,
+		Publisher:       pub,
+		now:             func() time.Time { return issued },
+	}
+	if _, err := digest.Run(ctx); err != nil {
+		t.Fatalf("digest Run: %v", err)
+	}
+	recs, _ := repo.ListIssuedRecommendations(ctx, postgres.IssuedRecommendationFilter{Team: "payments"})
+	if len(recs) != 2 || recs[0].Status != "open" || !recs[0].IssuedAt.Equal(issued) {
+		t.Fatalf("issued = %+v", recs)
+	}
+	if len(pub.payloads) != 2 {
+		t.Fatalf("published %d events, want one per new recommendation", len(pub.payloads))
+	}
+	for i, p := range pub.payloads {
+		ev, ok := p.(eventbus.RecommendationIssued)
+		if !ok || (ev.RecommendationID != recs[0].ID && ev.RecommendationID != recs[1].ID) {
+			t.Errorf("event %d = %+v, want the id of a stored recommendation", i, p)
+		}
+	}
+
+	// 第二天：idle 已删除，fat 的 CPU 请求从 4 降到 2.6（建议 1.2，完成一半）
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "pay", WorkloadName: "fat", Timestamp: issued.Add(22 * time.Hour), CPURequest: 2.6})
+	now := issued.Add(24 * time.Hour)
+	r := &RecommendationReconciler{Repo: repo, Store: repo, now: func() time.Time { return now }}
+	res, err := r.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Checked != 2 || res.Applied != 1 || res.Partial != 1 {
+		t.Errorf("result = %+v", res)
+	}
+	byWorkload := make(map[string]postgres.IssuedRecommendation)
+	recs, _ = repo.ListIssuedRecommendations(ctx, postgres.IssuedRecommendationFilter{})
+	for _, rec := range recs {
+		byWorkload[rec.WorkloadName] = rec
+	}
+	fat := byWorkload["fat"]
+	if fat.Status != "partial" || fat.CurrentRequest != 2.6 || fat.AdoptedFraction != 0.5 || fat.RealizedMonthlySavings <= 0 || !fat.CheckedAt.Equal(now) {
+		t.Errorf("fat = %+v", fat)
+	}
+	if idle := byWorkload["idle"]; idle.Status != "applied" || idle.RealizedMonthlySavings != idle.EstimatedMonthlySavings {
+		t.Errorf("idle = %+v", idle)
+	}
+
+	// 请求量回涨：已部分采纳的建议重新变为 open
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "pay", WorkloadName: "fat", Timestamp: issued.Add(23 * time.Hour), CPURequest: 4})
+	if res, _ := r.Run(ctx); res.Open != 1 {
+		t.Errorf("after drift = %+v, want fat open again", res)
+	}
+
+	// 再次下发保留原基线与下发时间
+	digest.now = func() time.Time { return now }
+	if _, err := digest.Run(ctx); err != nil {
+		t.Fatalf("digest Run: %v", err)
+	}
+	recs, _ = repo.ListIssuedRecommendations(ctx, postgres.IssuedRecommendationFilter{WorkloadName: "fat"})
+	if len(recs) != 1 || !recs[0].IssuedAt.Equal(issued) || recs[0].BaselineRequest != 4 {
+		t.Errorf("re-issued = %+v", recs)
+	}
+	// 仍待采纳的 fat 不重复发布；已采纳的 idle 再次出现在窗口内，作为新建议发布
+	if len(pub.payloads) != 3 {
+		t.Fatalf("published %d events in total, want 3", len(pub.payloads))
+	}
+	if ev := pub.payloads[2].(eventbus.RecommendationIssued); ev.WorkloadName != "idle" {
+		t.Errorf("re-issue published %+v, want only idle", ev)
+	}
+}
@@ -0,0 +1,125 @@
+// Package etl provides ETL workers for hourly and daily aggregation.
+// retention_worker.go: moves expired cost snapshots from PostgreSQL into the object-store archive,
+// downsamples hourly workload stats past their full-resolution retention into daily workload rollups,
+// and purges archived objects past the archive retention.
+package etl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/objectstore"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+)
+
+// RetentionWorker enforces Retention.Postgres.CostHistory: snapshots older than SnapshotRetention
+// are archived (if not already) and deleted from the repository. Archived objects older than
+// ArchiveRetention are purged; zero keeps archives forever.
+//
+// With HourlyStats set, hourly workload stats older than HourlyStatRetention (Retention.Postgres.HourlyStats)
+// are rolled up into daily workload aggregates and the raw rows deleted. The cutoff is aligned to a UTC day
+// so a day is always downsampled as a whole.
+type RetentionWorker struct {
+	Repo                postgres.Repository
+	Archiver            *objectstore.Archiver
+	HourlyStats         HourlyStatDownsampler
+	SnapshotRetention   time.Duration
+	ArchiveRetention    time.Duration
+	HourlyStatRetention time.Duration
+
+	now func() time.Time
+}
+
+// HourlyStatDownsampler rolls hourly workload stats into daily workload aggregates.
+// *postgres.MockRepository satisfies this interface.
+type HourlyStatDownsampler interface {
+	DownsampleHourlyWorkloadStats(ctx context.Context, before time.Time) (postgres.DownsampleResult, error)
+}
+
+// RetentionResult is the outcome of one retention run.
+type RetentionResult struct {
+	Archived      int `json:"archived"`
+	Deleted       int `json:"deleted"`
+	Purged        int `json:"purged"`
+	Rollups       int `json:"rollups"`        // daily workload rows written
+	HourlyDeleted int `json:"hourly_deleted"` // hourly workload rows downsampled and removed
+}
+
+// Run executes one retention pass.
+func (w *RetentionWorker) Run(ctx context.Context) (*RetentionResult, error) {
+	if w.Repo == nil {
+		return nil, fmt.Errorf("retention: no repository configured")
+	}
+	if w.SnapshotRetention <= 0 {
+		return nil, fmt.Errorf("retention: snapshot retention must be positive")
+	}
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+	cutoff := now().Add(-w.SnapshotRetention)
+	result := &RetentionResult{}
+
+	expired, err := w.Repo.ListCostSnapshots(ctx, postgres.CostSnapshotFilter{EndTime: cutoff})
+	if err != nil {
+		return nil, fmt.Errorf("retention: list cost snapshots: %w", err)
+	}
+	for _, snapshot := range expired {
+		if !snapshot.Timestamp.Before(cutoff) {
+			continue
+		}
+		if w.Archiver != nil {
+			if _, err := w.Archiver.RestoreSnapshot(ctx, snapshot.ID); errors.Is(err, objectstore.ErrNotFound) {
+				if err := w.Archiver.ArchiveSnapshot(ctx, snapshot); err != nil {
+					return result, fmt.Errorf("retention: %w", err)
+				}
+				result.Archived++
+			} else if err != nil {
+				return result, fmt.Errorf("retention: %w", err)
+			}
+		}
+		if err := w.Repo.DeleteCostSnapshot(ctx, snapshot.ID); err != nil {
+			return result, fmt.Errorf("retention: delete cost snapshot %s: %w", snapshot.ID, err)
+		}
+		result.Deleted++
+	}
+
+	if w.HourlyStats != nil && w.HourlyStatRetention > 0 {
+		before := now().Add(-w.HourlyStatRetention).UTC().Truncate(24 * time.Hour)
+		downsampled, err := w.HourlyStats.DownsampleHourlyWorkloadStats(ctx, before)
+		if err != nil {
+			return result, fmt.Errorf("retention: downsample hourly workload stats: %w", err)
+		}
+		result.Rollups = downsampled.Rollups
+		result.HourlyDeleted = downsampled.Deleted
+	}
+
+	if w.Archiver != nil && w.ArchiveRetention > 0 {
+		archiveCutoff := now().Add(-w.ArchiveRetention)
+		for _, kind := range []string{objectstore.KindCostSnapshot, objectstore.KindEvidenceChain} {
+			n, err := w.Archiver.PurgeExpired(ctx, kind, archiveCutoff)
+			result.Purged += n
+			if err != nil {
+				return result, fmt.Errorf("retention: purge %s: %w", kind, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Job returns the retention pass as a scheduler job (default daily).
+func (w *RetentionWorker) Job(interval time.Duration) Job {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return Job{
+		Name:     "retention",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, err := w.Run(ctx)
+			return err
+		},
+	}
+}
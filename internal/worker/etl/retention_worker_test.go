@@ -0,0 +1,114 @@
+package etl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/objectstore"
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/worker/lock"
+)
+
+func TestRetentionWorker_Run(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	store := objectstore.NewMockClient()
+	archiver := objectstore.NewArchiver(store, "lighthouse")
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "old-1", Timestamp: now.AddDate(0, 0, -400)})
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "old-2", Timestamp: now.AddDate(0, 0, -380)})
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "fresh", Timestamp: now.AddDate(0, 0, -1)})
+	// old-2 was already archived on creation.
+	_ = archiver.ArchiveSnapshot(ctx, postgres.CostSnapshot{ID: "old-2"})
+
+	w := &RetentionWorker{
+		Repo:              repo,
+		Archiver:          archiver,
+		SnapshotRetention: 365 * 24 * time.Hour,
+		now:               func() time.Time { return now },
+	}
+	result, err := w.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Archived != 1 || result.Deleted != 2 {
+		t.Errorf("result = %+v, want archived=1 deleted=2", result)
+	}
+	if _, err := repo.GetCostSnapshot(ctx, "old-1"); err == nil {
+		t.Error("expired snapshot should be deleted from repository")
+	}
+	if _, err := repo.GetCostSnapshot(ctx, "fresh"); err != nil {
+		t.Errorf("fresh snapshot should be kept: %v", err)
+	}
+	if _, err := archiver.RestoreSnapshot(ctx, "old-1"); err != nil {
+		t.Errorf("expired snapshot should be archived: %v", err)
+	}
+}
+
+func TestRetentionWorker_DownsamplesHourlyStats(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	now := time.Date(2020, 6, 20, 9, 0, 0, 0, time.UTC)
+	day := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	for h := 0; h < 4; h++ {
+		_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{
+			Namespace: "shop", WorkloadName: "api", PodName: "api-" + string(rune('a'+h)),
+			Timestamp:  day.Add(time.Duration(h) * time.Hour),
+			CPURequest: float64(h + 1), CPUUsageP95: float64(h) / 2, TotalBillableCost: 1.5,
+		})
+	}
+	// 6-06 09:00 is inside the 14-day window once the cutoff is aligned to 6-06 00:00.
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "shop", WorkloadName: "api", Timestamp: now.AddDate(0, 0, -14), TotalBillableCost: 1})
+
+	w := &RetentionWorker{
+		Repo:                repo,
+		HourlyStats:         repo,
+		SnapshotRetention:   365 * 24 * time.Hour,
+		HourlyStatRetention: 14 * 24 * time.Hour,
+		now:                 func() time.Time { return now },
+	}
+	result, err := w.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Rollups != 1 || result.HourlyDeleted != 4 {
+		t.Errorf("result = %+v, want rollups=1 hourly_deleted=4", result)
+	}
+	days, err := repo.ListDailyWorkloadStats(ctx, postgres.DailyWorkloadStatFilter{Namespace: "shop"})
+	if err != nil || len(days) != 1 {
+		t.Fatalf("daily stats = %+v, %v; want one rollup", days, err)
+	}
+	got := days[0]
+	if !got.Date.Equal(day) || got.Hours != 4 || got.CPURequest != 2.5 || got.CPUUsageP95 != 1.5 || got.TotalBillableCost != 6 {
+		t.Errorf("rollup = %+v, want 4 hours, cpu request 2.5, p95 1.5, billable 6", got)
+	}
+	left, _ := repo.ListHourlyWorkloadStats(ctx, postgres.HourlyWorkloadStatFilter{Namespace: "shop"})
+	if len(left) != 1 || !left[0].Timestamp.Equal(now.AddDate(0, 0, -14)) {
+		t.Errorf("remaining hourly stats = %+v, want only the one inside the window", left)
+	}
+
+	// A late hour for an already downsampled day merges into the existing rollup.
+	_ = repo.SaveHourlyWorkloadStat(ctx, postgres.HourlyWorkloadStat{Namespace: "shop", WorkloadName: "api", Timestamp: day.Add(5 * time.Hour), CPURequest: 2.5, TotalBillableCost: 1.5})
+	if _, err := w.Run(ctx); err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	days, _ = repo.ListDailyWorkloadStats(ctx, postgres.DailyWorkloadStatFilter{Namespace: "shop"})
+	if len(days) != 1 || days[0].Hours != 5 || days[0].TotalBillableCost != 7.5 {
+		t.Errorf("after late hour: %+v, want 5 hours and billable 7.5", days)
+	}
+}
+
+func TestRetentionWorker_Job(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMockRepository(postgres.DefaultMockConfig())
+	_ = repo.SaveCostSnapshot(ctx, postgres.CostSnapshot{ID: "old", Timestamp: time.Now().AddDate(-2, 0, 0)})
+
+	w := &RetentionWorker{Repo: repo, SnapshotRetention: 365 * 24 * time.Hour}
+	s := &Scheduler{Locker: lock.NewMemoryBackend().Locker("a"), Jobs: []Job{w.Job(0)}}
+	s.Tick(ctx)
+	if _, err := repo.GetCostSnapshot(ctx, "old"); err == nil {
+		t.Error("the scheduled retention job should delete the expired snapshot")
+	}
+}
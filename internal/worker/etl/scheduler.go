@@ -1,10 +1,200 @@
 // Package etl provides ETL workers for hourly and daily aggregation.
-// scheduler.go: placeholder for ETL job scheduling.
+// scheduler.go: leader-elected job scheduler; jobs run once per interval slot across all replicas.
 package etl
 
-// Scheduler coordinates hourly and daily ETL runs.
-// Phase1: placeholder only; no business logic.
-type Scheduler struct{}
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
 
-// Start is a placeholder. Implementation in Phase2.
-func (s *Scheduler) Start() error { return nil }
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/worker/lock"
+)
+
+// Job is a periodic task. It runs once per Interval-aligned slot (e.g. every full hour).
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// RunRecorder stores the last completed slot per job so a replica taking over leadership
+// neither repeats a finished slot nor skips one the previous leader did not complete.
+type RunRecorder interface {
+	LastRun(ctx context.Context, job string) (time.Time, error)
+	RecordRun(ctx context.Context, job string, slot time.Time) error
+}
+
+// Scheduler runs Jobs only while holding the leader lock. Every PollInterval it (re)acquires the
+// lock; when the leader dies its lock is released and another replica takes over on its next poll.
+// A slot is recorded only after the job succeeds, so a crash mid-run causes the slot to be re-run
+// by the new leader (at-least-once on failure, exactly-once otherwise).
+type Scheduler struct {
+	Locker       lock.Locker
+	LockName     string      // default "etl-scheduler"
+	Recorder     RunRecorder // default in-process (not shared across replicas)
+	Jobs         []Job
+	PollInterval time.Duration // default 5s
+	OnError      func(job string, err error)
+
+	mu       sync.Mutex
+	leader   bool
+	recorder RunRecorder
+	now      func() time.Time
+}
+
+// Start runs the scheduling loop until ctx is cancelled, then releases leadership.
+func (s *Scheduler) Start(ctx context.Context) error {
+	if s.Locker == nil {
+		return fmt.Errorf("scheduler: no locker configured")
+	}
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer func() {
+		_ = s.Locker.Release(context.Background(), s.lockName())
+		s.setLeader(false)
+	}()
+
+	for {
+		s.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick performs one scheduling pass: acquire/renew leadership, then run due jobs.
+func (s *Scheduler) Tick(ctx context.Context) {
+	leader, err := s.Locker.Acquire(ctx, s.lockName())
+	if err != nil {
+		s.reportError("leader-election", err)
+	}
+	s.setLeader(leader && err == nil)
+	if !s.IsLeader() {
+		return
+	}
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	recorder := s.runRecorder()
+	for _, job := range s.Jobs {
+		if job.Interval <= 0 || job.Run == nil {
+			continue
+		}
+		slot := now().Truncate(job.Interval)
+		last, err := recorder.LastRun(ctx, job.Name)
+		if err != nil {
+			s.reportError(job.Name, err)
+			continue
+		}
+		if !slot.After(last) {
+			continue
+		}
+		if err := job.Run(ctx); err != nil {
+			s.reportError(job.Name, err)
+			continue
+		}
+		if err := recorder.RecordRun(ctx, job.Name, slot); err != nil {
+			s.reportError(job.Name, err)
+		}
+	}
+}
+
+// IsLeader reports whether this replica held the leader lock on the last tick.
+func (s *Scheduler) IsLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader
+}
+
+func (s *Scheduler) setLeader(v bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leader = v
+}
+
+func (s *Scheduler) lockName() string {
+	if s.LockName == "" {
+		return "etl-scheduler"
+	}
+	return s.LockName
+}
+
+func (s *Scheduler) runRecorder() RunRecorder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Recorder != nil {
+		return s.Recorder
+	}
+	if s.recorder == nil {
+		s.recorder = &memoryRunRecorder{last: make(map[string]time.Time)}
+	}
+	return s.recorder
+}
+
+func (s *Scheduler) reportError(job string, err error) {
+	if s.OnError != nil {
+		s.OnError(job, err)
+	}
+}
+
+type memoryRunRecorder struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func (r *memoryRunRecorder) LastRun(ctx context.Context, job string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last[job], nil
+}
+
+func (r *memoryRunRecorder) RecordRun(ctx context.Context, job string, slot time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last[job] = slot
+	return nil
+}
+
+// MetadataRunRecorder persists last-run slots in the shared metadata table
+// (key "scheduler/last_run/<job>"), so all replicas see the same history.
+type MetadataRunRecorder struct {
+	Repo postgres.Repository
+}
+
+func (r *MetadataRunRecorder) key(job string) string { return "scheduler/last_run/" + job }
+
+// LastRun implements RunRecorder; a job that never ran returns the zero time.
+func (r *MetadataRunRecorder) LastRun(ctx context.Context, job string) (time.Time, error) {
+	list, err := r.Repo.ListMetadata(ctx, postgres.MetadataFilter{KeyPrefix: r.key(job)})
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, m := range list {
+		if m.Key != r.key(job) {
+			continue
+		}
+		s, _ := m.Value["slot"].(string)
+		return time.Parse(time.RFC3339, s)
+	}
+	return time.Time{}, nil
+}
+
+// RecordRun implements RunRecorder.
+func (r *MetadataRunRecorder) RecordRun(ctx context.Context, job string, slot time.Time) error {
+	return r.Repo.SaveMetadata(ctx, postgres.Metadata{
+		Key:         r.key(job),
+		Value:       map[string]interface{}{"slot": slot.UTC().Format(time.RFC3339)},
+		Description: "last completed scheduler slot",
+		CreatedBy:   "scheduler",
+	})
+}
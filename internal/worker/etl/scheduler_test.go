@@ -0,0 +1,76 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
+	"github.com/myxxhui/lighthouse-src/internal/worker/lock"
+)
+
+func TestScheduler_RunsOnceAcrossReplicas(t *testing.T) {
+	ctx := context.Background()
+	backend := lock.NewMemoryBackend()
+	recorder := &MetadataRunRecorder{Repo: postgres.NewMockRepository(postgres.DefaultMockConfig())}
+	now := time.Date(2020, 1, 1, 10, 5, 0, 0, time.UTC)
+
+	runs := 0
+	fail := false
+	job := Job{Name: "hourly", Interval: time.Hour, Run: func(ctx context.Context) error {
+		if fail {
+			return errors.New("boom")
+		}
+		runs++
+		return nil
+	}}
+	newReplica := func(id string) *Scheduler {
+		return &Scheduler{Locker: backend.Locker(id), Recorder: recorder, Jobs: []Job{job}, now: func() time.Time { return now }}
+	}
+	a, b := newReplica("a"), newReplica("b")
+
+	a.Tick(ctx)
+	b.Tick(ctx)
+	if !a.IsLeader() || b.IsLeader() || runs != 1 {
+		t.Fatalf("leader a=%v b=%v runs=%d", a.IsLeader(), b.IsLeader(), runs)
+	}
+	a.Tick(ctx) // same slot: no re-run
+	if runs != 1 {
+		t.Fatalf("slot re-run, runs=%d", runs)
+	}
+
+	// Leader crashes while the 11:00 slot fails; b takes over and completes it.
+	now = now.Add(time.Hour)
+	fail = true
+	a.Tick(ctx)
+	backend.Expire("a")
+	fail = false
+	b.Tick(ctx)
+	if !b.IsLeader() || runs != 2 {
+		t.Fatalf("takeover: leader b=%v runs=%d", b.IsLeader(), runs)
+	}
+	b.Tick(ctx)
+	if runs != 2 {
+		t.Errorf("new leader re-ran completed slot, runs=%d", runs)
+	}
+}
+
+func TestScheduler_StartReleasesLock(t *testing.T) {
+	backend := lock.NewMemoryBackend()
+	s := &Scheduler{Locker: backend.Locker("a"), PollInterval: time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Start(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	if backend.Holder("etl-scheduler") != "a" {
+		t.Fatal("scheduler should hold the leader lock")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if backend.Holder("etl-scheduler") != "" {
+		t.Error("lock should be released on shutdown")
+	}
+}
@@ -0,0 +1,74 @@
+// Package lock 多副本间的分布式锁，用于保证定时任务（小时计算、保留清理等）在所有副本中只执行一次。
+// 锁以会话为单位持有：持有者进程或连接失效后锁自动释放，其他副本可接管。
+package lock
+
+import (
+	"context"
+	"sync"
+)
+
+// Locker 分布式锁。Acquire 幂等：已持有时再次调用会校验锁仍然有效（用于周期性续约/探活）。
+type Locker interface {
+	// Acquire 尝试获取锁，不阻塞；返回 false 表示锁被其他持有者占用。
+	Acquire(ctx context.Context, name string) (bool, error)
+	// Release 释放锁；未持有时为 no-op。
+	Release(ctx context.Context, name string) error
+}
+
+// MemoryBackend 进程内锁表，用于单副本部署与测试（通过 Locker(holder) 模拟多个副本）。
+type MemoryBackend struct {
+	mu      sync.Mutex
+	holders map[string]string // lock name -> holder
+}
+
+// NewMemoryBackend 创建进程内锁表。
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{holders: make(map[string]string)}
+}
+
+// Locker 返回以 holder 身份操作锁表的 Locker。
+func (b *MemoryBackend) Locker(holder string) Locker {
+	return &memoryLocker{backend: b, holder: holder}
+}
+
+// Expire 释放 holder 持有的全部锁，模拟副本崩溃或连接断开。
+func (b *MemoryBackend) Expire(holder string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for name, h := range b.holders {
+		if h == holder {
+			delete(b.holders, name)
+		}
+	}
+}
+
+// Holder 返回当前持有者（测试用）。
+func (b *MemoryBackend) Holder(name string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.holders[name]
+}
+
+type memoryLocker struct {
+	backend *MemoryBackend
+	holder  string
+}
+
+func (l *memoryLocker) Acquire(ctx context.Context, name string) (bool, error) {
+	l.backend.mu.Lock()
+	defer l.backend.mu.Unlock()
+	if h, ok := l.backend.holders[name]; ok && h != l.holder {
+		return false, nil
+	}
+	l.backend.holders[name] = l.holder
+	return true, nil
+}
+
+func (l *memoryLocker) Release(ctx context.Context, name string) error {
+	l.backend.mu.Lock()
+	defer l.backend.mu.Unlock()
+	if l.backend.holders[name] == l.holder {
+		delete(l.backend.holders, name)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBackend()
+	a, c := b.Locker("a"), b.Locker("c")
+
+	if ok, _ := a.Acquire(ctx, "job"); !ok {
+		t.Fatal("a should acquire")
+	}
+	if ok, _ := a.Acquire(ctx, "job"); !ok {
+		t.Fatal("re-acquire by holder should succeed")
+	}
+	if ok, _ := c.Acquire(ctx, "job"); ok {
+		t.Fatal("c must not acquire a held lock")
+	}
+	b.Expire("a")
+	if ok, _ := c.Acquire(ctx, "job"); !ok {
+		t.Fatal("c should take over after a expires")
+	}
+	_ = a.Release(ctx, "job") // not holder: no-op
+	if b.Holder("job") != "c" {
+		t.Errorf("holder = %q", b.Holder("job"))
+	}
+}
+
+// fakePG is a minimal database/sql driver emulating session-level advisory locks.
+type fakePG struct {
+	mu    sync.Mutex
+	locks map[int64]*fakeConn
+}
+
+func (d *fakePG) Open(name string) (driver.Conn, error) { return &fakeConn{db: d}, nil }
+
+type fakeConn struct{ db *fakePG }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	for k, holder := range c.db.locks {
+		if holder == c {
+			delete(c.db.locks, k)
+		}
+	}
+	return nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	key := args[0].Value.(int64)
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	var result bool
+	switch query {
+	case "SELECT pg_try_advisory_lock($1)":
+		if holder, ok := c.db.locks[key]; !ok || holder == c {
+			c.db.locks[key] = c
+			result = true
+		}
+	case "SELECT pg_advisory_unlock($1)":
+		if c.db.locks[key] == c {
+			delete(c.db.locks, key)
+			result = true
+		}
+	}
+	return &boolRows{v: result}, nil
+}
+
+type boolRows struct {
+	v    bool
+	done bool
+}
+
+func (r *boolRows) Columns() []string { return []string{"result"} }
+func (r *boolRows) Close() error      { return nil }
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.v
+	return nil
+}
+
+func TestPGAdvisoryLocker(t *testing.T) {
+	ctx := context.Background()
+	sql.Register("fakepg-lock-test", &fakePG{locks: make(map[int64]*fakeConn)})
+	db, err := sql.Open("fakepg-lock-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a, b := NewPGAdvisoryLocker(db, ""), NewPGAdvisoryLocker(db, "")
+	if a.Key("job") != b.Key("job") || a.Key("job") == a.Key("other") {
+		t.Fatal("lock keys must be stable per name")
+	}
+	if ok, err := a.Acquire(ctx, "job"); !ok || err != nil {
+		t.Fatalf("a.Acquire = %v, %v", ok, err)
+	}
+	if ok, _ := a.Acquire(ctx, "job"); !ok {
+		t.Fatal("holder re-acquire should succeed")
+	}
+	if ok, _ := b.Acquire(ctx, "job"); ok {
+		t.Fatal("b must not acquire while a holds the lock")
+	}
+	if err := a.Release(ctx, "job"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if ok, _ := b.Acquire(ctx, "job"); !ok {
+		t.Fatal("b should acquire after release")
+	}
+}
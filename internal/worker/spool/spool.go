@@ -0,0 +1,320 @@
+// Package spool provides a local on-disk dead-letter queue for repository writes that failed
+// mid-run. Failed batches are persisted as one JSON file each and replayed on the next cycle,
+// so computed stats are not silently dropped when PostgreSQL is briefly unavailable.
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/myxxhui/lighthouse-src/internal/data/dataerr"
+)
+
+// ErrFull is returned by Enqueue when the spool already holds MaxEntries batches.
+var ErrFull = dataerr.Unavailable("spool is full")
+
+// ErrNotFound is returned for unknown entry IDs.
+var ErrNotFound = dataerr.NotFound("spool entry not found")
+
+// Entry is one spooled batch.
+type Entry struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Attempts    int             `json:"attempts"`
+	LastAttempt time.Time       `json:"last_attempt,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	Size        int             `json:"size"` // payload bytes
+	Payload     json.RawMessage `json:"payload,omitempty"`
+}
+
+// Handler replays the payload of one entry. Returning nil removes the entry from the spool;
+// handlers must be idempotent because a batch may be replayed more than once.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Handlers maps entry kinds to their replay handler.
+type Handlers map[string]Handler
+
+// Stats are cumulative counters since Open plus the current number of pending entries.
+type Stats struct {
+	Pending        int    `json:"pending"`
+	Enqueued       uint64 `json:"enqueued"`
+	Replayed       uint64 `json:"replayed"`
+	ReplayFailures uint64 `json:"replay_failures"`
+	Dropped        uint64 `json:"dropped"` // rejected because the spool was full
+}
+
+// ReplayResult summarizes one Replay call.
+type ReplayResult struct {
+	Replayed int `json:"replayed"`
+	Failed   int `json:"failed"`
+	Skipped  int `json:"skipped"` // no handler registered for the kind
+}
+
+// Spool is a directory of pending batches. Safe for concurrent use within one process.
+type Spool struct {
+	dir        string
+	maxEntries int
+	now        func() time.Time
+
+	mu  sync.Mutex
+	seq uint64
+
+	enqueued       atomic.Uint64
+	replayed       atomic.Uint64
+	replayFailures atomic.Uint64
+	dropped        atomic.Uint64
+}
+
+// Open opens (creating if needed) a spool directory. maxEntries <= 0 means unlimited.
+func Open(dir string, maxEntries int) (*Spool, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("spool: directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("spool: create directory: %w", err)
+	}
+	return &Spool{dir: dir, maxEntries: maxEntries, now: time.Now}, nil
+}
+
+// Dir returns the spool directory.
+func (s *Spool) Dir() string { return s.dir }
+
+// Enqueue persists v (JSON-encoded) as a new entry of kind.
+func (s *Spool) Enqueue(kind string, v interface{}) (*Entry, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("spool: encode %s: %w", kind, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxEntries > 0 {
+		ids, err := s.ids()
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) >= s.maxEntries {
+			s.dropped.Add(1)
+			return nil, ErrFull
+		}
+	}
+	s.seq++
+	now := s.now()
+	// 文件名按时间排序即为入队顺序；seq 避免同一纳秒内冲突。
+	entry := &Entry{
+		ID:        fmt.Sprintf("%020d-%04d-%s", now.UnixNano(), s.seq%10000, sanitize(kind)),
+		Kind:      kind,
+		CreatedAt: now,
+		Size:      len(payload),
+		Payload:   payload,
+	}
+	if err := s.write(entry); err != nil {
+		return nil, err
+	}
+	s.enqueued.Add(1)
+	return entry, nil
+}
+
+// List returns pending entries oldest first, without payloads.
+func (s *Spool) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		e, err := s.read(id)
+		if err != nil {
+			return nil, err
+		}
+		e.Payload = nil
+		entries = append(entries, *e)
+	}
+	return entries, nil
+}
+
+// Oldest returns up to n pending entries oldest first, including payloads. Only the returned
+// entries are read, so consumers can page through a large spool in order.
+func (s *Spool) Oldest(n int) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	if n >= 0 && n < len(ids) {
+		ids = ids[:n]
+	}
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		e, err := s.read(id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, nil
+}
+
+// Get returns one entry including its payload.
+func (s *Spool) Get(id string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(id)
+}
+
+// Delete discards an entry without replaying it.
+func (s *Spool) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return fmt.Errorf("spool: delete %s: %w", id, err)
+	}
+	return nil
+}
+
+// Replay hands every pending entry to the handler of its kind, oldest first. Successful entries
+// are removed; failed ones stay with Attempts/LastError updated and are retried on the next call.
+// Replay stops early only when ctx is done.
+func (s *Spool) Replay(ctx context.Context, handlers Handlers) (ReplayResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result ReplayResult
+	ids, err := s.ids()
+	if err != nil {
+		return result, err
+	}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		e, err := s.read(id)
+		if err != nil {
+			return result, err
+		}
+		h, ok := handlers[e.Kind]
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		if herr := h(ctx, e.Payload); herr != nil {
+			e.Attempts++
+			e.LastAttempt = s.now()
+			e.LastError = herr.Error()
+			if err := s.write(e); err != nil {
+				return result, err
+			}
+			result.Failed++
+			s.replayFailures.Add(1)
+			continue
+		}
+		if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return result, fmt.Errorf("spool: remove %s: %w", id, err)
+		}
+		result.Replayed++
+		s.replayed.Add(1)
+	}
+	return result, nil
+}
+
+// Stats returns the counters and the current number of pending entries.
+func (s *Spool) Stats() Stats {
+	s.mu.Lock()
+	ids, _ := s.ids()
+	s.mu.Unlock()
+	return Stats{
+		Pending:        len(ids),
+		Enqueued:       s.enqueued.Load(),
+		Replayed:       s.replayed.Load(),
+		ReplayFailures: s.replayFailures.Load(),
+		Dropped:        s.dropped.Load(),
+	}
+}
+
+func (s *Spool) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+// ids lists entry IDs sorted oldest first. Caller holds s.mu.
+func (s *Spool) ids() ([]string, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("spool: read directory: %w", err)
+	}
+	var ids []string
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *Spool) read(id string) (*Entry, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("spool: read %s: %w", id, err)
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("spool: decode %s: %w", id, err)
+	}
+	return &e, nil
+}
+
+// write stores the entry atomically (temp file + rename), so a crash never leaves a torn batch.
+func (s *Spool) write(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("spool: encode entry %s: %w", e.ID, err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("spool: write %s: %w", e.ID, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("spool: write %s: %w", e.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("spool: write %s: %w", e.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(e.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("spool: write %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+func sanitize(kind string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, kind)
+}
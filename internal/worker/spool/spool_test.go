@@ -0,0 +1,110 @@
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSpool_EnqueueReplay(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	first, err := s.Enqueue("stats", []int{1, 2})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := s.Enqueue("stats", []int{3}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := s.Enqueue("unknown", "x"); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	entries, err := s.List()
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("List = %d entries, %v; want 3", len(entries), err)
+	}
+	if entries[0].ID != first.ID || entries[0].Payload != nil {
+		t.Errorf("List[0] = %+v, want oldest entry without payload", entries[0])
+	}
+	oldest, err := s.Oldest(2)
+	if err != nil || len(oldest) != 2 || oldest[0].ID != first.ID || string(oldest[0].Payload) != "[1,2]" {
+		t.Errorf("Oldest(2) = %+v, %v; want the two oldest entries with payloads", oldest, err)
+	}
+
+	var saved []int
+	fail := true
+	handlers := Handlers{"stats": func(ctx context.Context, payload json.RawMessage) error {
+		if fail {
+			return errors.New("db down")
+		}
+		var v []int
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return err
+		}
+		saved = append(saved, v...)
+		return nil
+	}}
+
+	result, err := s.Replay(ctx, handlers)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if result != (ReplayResult{Failed: 2, Skipped: 1}) {
+		t.Errorf("failing replay = %+v", result)
+	}
+	e, err := s.Get(first.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if e.Attempts != 1 || e.LastError != "db down" || string(e.Payload) != "[1,2]" {
+		t.Errorf("entry after failed replay = %+v", e)
+	}
+
+	fail = false
+	result, err = s.Replay(ctx, handlers)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if result.Replayed != 2 || len(saved) != 3 || saved[0] != 1 {
+		t.Errorf("replay = %+v, saved %v", result, saved)
+	}
+	st := s.Stats()
+	if st.Pending != 1 || st.Enqueued != 3 || st.Replayed != 2 || st.ReplayFailures != 2 {
+		t.Errorf("Stats = %+v", st)
+	}
+
+	entries, _ = s.List()
+	if err := s.Delete(entries[0].ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete(entries[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete missing: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSpool_PersistsAcrossOpenAndLimit(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open(dir, 1)
+	if _, err := s.Enqueue("stats", 1); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := s.Enqueue("stats", 2); !errors.Is(err, ErrFull) {
+		t.Errorf("Enqueue over limit: err = %v, want ErrFull", err)
+	}
+	if s.Stats().Dropped != 1 {
+		t.Errorf("Dropped = %d, want 1", s.Stats().Dropped)
+	}
+
+	reopened, err := Open(dir, 1)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if entries, _ := reopened.List(); len(entries) != 1 {
+		t.Errorf("reopened spool has %d entries, want 1", len(entries))
+	}
+}
@@ -0,0 +1,56 @@
+// Package authz defines the authorization hook the API server consults for every /api/v1 request
+// on top of the built-in service account scopes, so an enterprise can enforce the decisions of its
+// central IAM without forking the server. The hook is either an HTTP callout (Webhook) or a Go
+// plugin (OpenPlugin) implementing Authorizer.
+package authz
+
+import (
+	"context"
+)
+
+// Actions of a Request.
+const (
+	ActionRead  = "read"
+	ActionWrite = "write"
+)
+
+// AnonymousSubject is the subject of requests without an API key.
+const AnonymousSubject = "anonymous"
+
+// Request describes who wants to do what.
+type Request struct {
+	// Subject is the service account name, or AnonymousSubject.
+	Subject string `json:"subject"`
+	// Resource is the API group, the first path segment after /api/v1, e.g. "cost" or "admin".
+	Resource string `json:"resource"`
+	// Action is ActionRead for GET/HEAD and ActionWrite otherwise.
+	Action string `json:"action"`
+	// Namespace is the Kubernetes namespace the request is about, if any (path parameter or
+	// ?namespace=).
+	Namespace string `json:"namespace,omitempty"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	// KeyID is the ID of the API key used, if any.
+	KeyID string `json:"key_id,omitempty"`
+}
+
+// Decision is the answer to a Request.
+type Decision struct {
+	Allowed bool `json:"allowed"`
+	// Reason is returned to the caller when the request is denied.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Authorizer decides whether a request may proceed. An error means no decision could be made; the
+// server then applies its fail-open / fail-closed policy.
+type Authorizer interface {
+	Authorize(ctx context.Context, req Request) (Decision, error)
+}
+
+// AuthorizerFunc adapts a function to Authorizer.
+type AuthorizerFunc func(ctx context.Context, req Request) (Decision, error)
+
+// Authorize implements Authorizer.
+func (f AuthorizerFunc) Authorize(ctx context.Context, req Request) (Decision, error) {
+	return f(ctx, req)
+}
@@ -0,0 +1,104 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch {
+		case req.Resource == "broken":
+			http.Error(w, "iam down", http.StatusBadGateway)
+		case req.Subject == "finops-bot" && req.Action == ActionRead:
+			json.NewEncoder(w).Encode(Decision{Allowed: true})
+		default:
+			json.NewEncoder(w).Encode(Decision{Reason: "team " + req.Namespace + " is read-only"})
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	w := NewWebhook(WebhookConfig{URL: srv.URL, Token: "s3cret", CacheTTL: time.Minute})
+	now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	read := Request{Subject: "finops-bot", Resource: "cost", Action: ActionRead, Method: http.MethodGet, Path: "/api/v1/cost/global"}
+	d, err := w.Authorize(ctx, read)
+	if err != nil || !d.Allowed {
+		t.Fatalf("read = %+v, %v; want allowed", d, err)
+	}
+	write := Request{Subject: "finops-bot", Resource: "targets", Action: ActionWrite, Namespace: "shop", Method: http.MethodPost, Path: "/api/v1/targets"}
+	d, err = w.Authorize(ctx, write)
+	if err != nil || d.Allowed || d.Reason != "team shop is read-only" {
+		t.Fatalf("write = %+v, %v; want denied with reason", d, err)
+	}
+	if _, err := w.Authorize(ctx, Request{Resource: "broken"}); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("broken = %v, want status 502 error", err)
+	}
+
+	// Decisions are cached until the TTL passes; errors are not
+	before := atomic.LoadInt32(&calls)
+	w.Authorize(ctx, read)
+	w.Authorize(ctx, write)
+	if got := atomic.LoadInt32(&calls); got != before {
+		t.Errorf("cached decisions made %d calls", got-before)
+	}
+	w.Authorize(ctx, Request{Resource: "broken"})
+	now = now.Add(2 * time.Minute)
+	w.Authorize(ctx, read)
+	if got := atomic.LoadInt32(&calls); got != before+2 {
+		t.Errorf("calls after error and expiry = %d, want %d", got, before+2)
+	}
+
+	if _, err := NewWebhook(WebhookConfig{URL: srv.URL}).Authorize(ctx, read); err == nil {
+		t.Error("missing token: want error")
+	}
+}
+
+func TestFromSymbol(t *testing.T) {
+	allow := AuthorizerFunc(func(context.Context, Request) (Decision, error) { return Decision{Allowed: true}, nil })
+	var nilVar Authorizer
+	var setVar Authorizer = allow
+	cases := []struct {
+		name    string
+		sym     interface{}
+		wantErr bool
+	}{
+		{"var", &setVar, false},
+		{"nil var", &nilVar, true},
+		{"constructor", func() (Authorizer, error) { return allow, nil }, false},
+		{"nil constructor result", func() (Authorizer, error) { return nil, nil }, true},
+		{"value", allow, false},
+		{"wrong type", func() {}, true},
+	}
+	for _, tc := range cases {
+		a, err := fromSymbol(tc.sym)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if err == nil {
+			if d, _ := a.Authorize(context.Background(), Request{}); !d.Allowed {
+				t.Errorf("%s: authorizer not the exported one", tc.name)
+			}
+		}
+	}
+}
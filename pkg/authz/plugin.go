@@ -0,0 +1,54 @@
+package authz
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// PluginSymbol is the symbol OpenPlugin looks up. The plugin (go build -buildmode=plugin, built
+// with the same Go version and module versions as the server) exports either
+//
+//	var Authorizer authz.Authorizer = ...
+//
+// or
+//
+//	func Authorizer() (authz.Authorizer, error)
+const PluginSymbol = "Authorizer"
+
+// OpenPlugin loads an Authorizer from the Go plugin at path. Go plugins need cgo and are only
+// supported on Linux, FreeBSD and macOS.
+func OpenPlugin(path string) (Authorizer, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("authz plugin: %w", err)
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("authz plugin: %w", err)
+	}
+	return fromSymbol(sym)
+}
+
+// fromSymbol converts the looked-up plugin symbol to an Authorizer.
+func fromSymbol(sym plugin.Symbol) (Authorizer, error) {
+	switch v := sym.(type) {
+	case *Authorizer:
+		if *v == nil {
+			return nil, fmt.Errorf("authz plugin: %s is nil", PluginSymbol)
+		}
+		return *v, nil
+	case func() (Authorizer, error):
+		a, err := v()
+		if err != nil {
+			return nil, fmt.Errorf("authz plugin: %w", err)
+		}
+		if a == nil {
+			return nil, fmt.Errorf("authz plugin: %s returned nil", PluginSymbol)
+		}
+		return a, nil
+	case Authorizer:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("authz plugin: %s has type %T, want authz.Authorizer or func() (authz.Authorizer, error)", PluginSymbol, sym)
+	}
+}
@@ -0,0 +1,112 @@
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxCachedDecisions bounds the webhook decision cache; it is cleared when full.
+const maxCachedDecisions = 10000
+
+// WebhookConfig configures the HTTP callout.
+type WebhookConfig struct {
+	URL string
+	// Token is sent as "Authorization: Bearer <token>" when set.
+	Token   string
+	Timeout time.Duration // default 2s
+	// CacheTTL caches decisions per identical Request; 0 disables caching. Errors are not cached.
+	CacheTTL time.Duration
+}
+
+// Webhook POSTs each Request as JSON to an HTTP endpoint, which answers 200 with a Decision.
+type Webhook struct {
+	config WebhookConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[Request]cachedDecision
+}
+
+type cachedDecision struct {
+	decision Decision
+	expires  time.Time
+}
+
+// NewWebhook creates the HTTP callout authorizer.
+func NewWebhook(config WebhookConfig) *Webhook {
+	if config.Timeout <= 0 {
+		config.Timeout = 2 * time.Second
+	}
+	return &Webhook{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		cache:  make(map[Request]cachedDecision),
+	}
+}
+
+// Authorize implements Authorizer.
+func (w *Webhook) Authorize(ctx context.Context, req Request) (Decision, error) {
+	if d, ok := w.cached(req); ok {
+		return d, nil
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Decision{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, fmt.Errorf("authz webhook: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if w.config.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+w.config.Token)
+	}
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return Decision{}, fmt.Errorf("authz webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Decision{}, fmt.Errorf("authz webhook: status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	var d Decision
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&d); err != nil {
+		return Decision{}, fmt.Errorf("authz webhook: decode decision: %w", err)
+	}
+	w.store(req, d)
+	return d, nil
+}
+
+func (w *Webhook) cached(req Request) (Decision, bool) {
+	if w.config.CacheTTL <= 0 {
+		return Decision{}, false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	c, ok := w.cache[req]
+	if !ok || !w.now().Before(c.expires) {
+		return Decision{}, false
+	}
+	return c.decision, true
+}
+
+func (w *Webhook) store(req Request, d Decision) {
+	if w.config.CacheTTL <= 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.cache) >= maxCachedDecisions {
+		w.cache = make(map[Request]cachedDecision)
+	}
+	w.cache[req] = cachedDecision{decision: d, expires: w.now().Add(w.config.CacheTTL)}
+}
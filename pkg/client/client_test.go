@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Operations(t *testing.T) {
+	var gotQuery, gotKey string
+	var gotBody StateBundle
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-API-Key")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/pricing/history":
+			w.Write([]byte(`{"versions":[{"id":"price-1","cpu_price_per_core_hour":0.05,"mem_price_per_gb_hour":0.005}]}`))
+		case "POST /api/v1/admin/state/import":
+			gotQuery = r.URL.RawQuery
+			json.NewDecoder(r.Body).Decode(&gotBody)
+			w.Write([]byte(`{"dry_run":true,"changes":[],"config_changes":[],"summary":{"unchanged":1}}`))
+		case "GET /api/v1/workloads/team a/api/costs":
+			gotQuery = r.URL.RawQuery
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"workload not found","code":"NOT_FOUND"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	c := New(srv.URL+"/", WithHeader("X-API-Key", "secret"))
+	ctx := context.Background()
+
+	history, err := c.GetPriceHistory(ctx)
+	if err != nil {
+		t.Fatalf("GetPriceHistory: %v", err)
+	}
+	if len(history.Versions) != 1 || history.Versions[0].CPUPricePerCoreHour != 0.05 {
+		t.Errorf("history = %+v", history)
+	}
+	if gotKey != "secret" {
+		t.Errorf("X-API-Key = %q, want the configured header", gotKey)
+	}
+
+	resp, err := c.ImportState(ctx, StateBundle{Version: 1, Environment: "staging"}, ImportStateParams{DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportState: %v", err)
+	}
+	if !resp.DryRun || resp.Summary["unchanged"] != 1 || gotQuery != "dry_run=true" || gotBody.Environment != "staging" {
+		t.Errorf("ImportState = %+v, query %q, body %+v", resp, gotQuery, gotBody)
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = c.WorkloadCost(ctx, "team a", "api", WorkloadCostParams{StartTime: start})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "NOT_FOUND" || apiErr.Message != "workload not found" || !IsNotFound(err) {
+		t.Errorf("WorkloadCost error = %v, want NOT_FOUND APIError", err)
+	}
+	if gotQuery != "start_time=2020-01-01T00%3A00%3A00Z" {
+		t.Errorf("query = %q", gotQuery)
+	}
+}
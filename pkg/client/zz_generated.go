@@ -0,0 +1,4316 @@
+// Code generated by cmd/apigen. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// basePath is the route prefix of all operations.
+const basePath = "/api/v1"
+
+// ApproveAdjustment calls POST /admin/adjustments/{id}/approve: Approve a manual adjustment of a
+// closed accounting period.
+func (c *Client) ApproveAdjustment(ctx context.Context, id string) (*CostAdjustment, error) {
+	var out CostAdjustment
+	if err := c.do(ctx, "POST", "/admin/adjustments/"+url.PathEscape(id)+"/approve", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ApprovePriceChange calls POST /pricing/changes/{id}/approve: Approve a price change and write it
+// to the price history.
+func (c *Client) ApprovePriceChange(ctx context.Context, id string, body ReviewPriceChangeRequest) (*PriceChangeResponse, error) {
+	var out PriceChangeResponse
+	if err := c.do(ctx, "POST", "/pricing/changes/"+url.PathEscape(id)+"/approve", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CapacityProjectionParams holds the query parameters of GET /capacity/projection; zero values are not sent.
+type CapacityProjectionParams struct {
+	// projection horizon, default 90
+	HorizonDays int
+	// target utilization, default 0.85
+	TargetUtilization float64
+	// nodes added (negative: removed) in the scenario
+	NodeDelta int
+	// node pool, default the largest
+	Pool string
+}
+
+func (p CapacityProjectionParams) values() url.Values {
+	q := url.Values{}
+	if p.HorizonDays != 0 {
+		q.Set("horizon_days", strconv.Itoa(p.HorizonDays))
+	}
+	if p.TargetUtilization != 0 {
+		q.Set("target_utilization", strconv.FormatFloat(p.TargetUtilization, 'g', -1, 64))
+	}
+	if p.NodeDelta != 0 {
+		q.Set("node_delta", strconv.Itoa(p.NodeDelta))
+	}
+	if p.Pool != "" {
+		q.Set("pool", p.Pool)
+	}
+	return q
+}
+
+// CapacityProjection calls GET /capacity/projection: Capacity projection of a node pool.
+func (c *Client) CapacityProjection(ctx context.Context, params CapacityProjectionParams) (*CapacityProjectionResponse, error) {
+	var out CapacityProjectionResponse
+	if err := c.do(ctx, "GET", "/capacity/projection", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CapacitySimulationParams holds the query parameters of GET /capacity/simulation; zero values are not sent.
+type CapacitySimulationParams struct {
+	// change of every pod's requests in %, e.g. -20
+	RequestChangePercent float64
+	// target utilization, default 0.85
+	TargetUtilization float64
+	// node pool, default all pools
+	Pool string
+}
+
+func (p CapacitySimulationParams) values() url.Values {
+	q := url.Values{}
+	if p.RequestChangePercent != 0 {
+		q.Set("request_change_percent", strconv.FormatFloat(p.RequestChangePercent, 'g', -1, 64))
+	}
+	if p.TargetUtilization != 0 {
+		q.Set("target_utilization", strconv.FormatFloat(p.TargetUtilization, 'g', -1, 64))
+	}
+	if p.Pool != "" {
+		q.Set("pool", p.Pool)
+	}
+	return q
+}
+
+// CapacitySimulation calls GET /capacity/simulation: Autoscaler simulation of a pod request change.
+func (c *Client) CapacitySimulation(ctx context.Context, params CapacitySimulationParams) (*CapacitySimulationResponse, error) {
+	var out CapacitySimulationResponse
+	if err := c.do(ctx, "GET", "/capacity/simulation", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CloseAccountingPeriodParams holds the query parameters of POST /admin/periods/close; zero values are not sent.
+type CloseAccountingPeriodParams struct {
+	// a day of the period (YYYY-MM-DD), default the last ended period
+	Date string
+}
+
+func (p CloseAccountingPeriodParams) values() url.Values {
+	q := url.Values{}
+	if p.Date != "" {
+		q.Set("date", p.Date)
+	}
+	return q
+}
+
+// CloseAccountingPeriod calls POST /admin/periods/close: Close an accounting period.
+func (c *Client) CloseAccountingPeriod(ctx context.Context, body CloseAccountingPeriodRequest, params CloseAccountingPeriodParams) (*AccountingPeriod, error) {
+	var out AccountingPeriod
+	if err := c.do(ctx, "POST", "/admin/periods/close", params.values(), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CompareROIBaselineParams holds the query parameters of GET /roi/baselines/{id}/compare; zero values are not sent.
+type CompareROIBaselineParams struct {
+	// compared day (YYYY-MM-DD), default yesterday
+	Date string
+}
+
+func (p CompareROIBaselineParams) values() url.Values {
+	q := url.Values{}
+	if p.Date != "" {
+		q.Set("date", p.Date)
+	}
+	return q
+}
+
+// CompareROIBaseline calls GET /roi/baselines/{id}/compare: Compare a day against any stored ROI
+// baseline.
+func (c *Client) CompareROIBaseline(ctx context.Context, id string, params CompareROIBaselineParams) (*ROIBaselineComparisonResponse, error) {
+	var out ROIBaselineComparisonResponse
+	if err := c.do(ctx, "GET", "/roi/baselines/"+url.PathEscape(id)+"/compare", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ConfirmSnapshot calls POST /snapshots/{id}/confirm: Confirm a suspect cost snapshot.
+func (c *Client) ConfirmSnapshot(ctx context.Context, id string, body ConfirmSnapshotRequest) (*SuspectSnapshot, error) {
+	var out SuspectSnapshot
+	if err := c.do(ctx, "POST", "/snapshots/"+url.PathEscape(id)+"/confirm", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CostSeriesParams holds the query parameters of GET /cost/series; zero values are not sent.
+type CostSeriesParams struct {
+	// range start (RFC3339)
+	StartTime time.Time
+	// range end (RFC3339)
+	EndTime time.Time
+	// namespace, default all
+	Namespace string
+	// granularity, default planned from the range (hourly up to 72h, daily up to 92 days, monthly
+	// beyond)
+	Resolution string
+}
+
+func (p CostSeriesParams) values() url.Values {
+	q := url.Values{}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	if p.Namespace != "" {
+		q.Set("namespace", p.Namespace)
+	}
+	if p.Resolution != "" {
+		q.Set("resolution", p.Resolution)
+	}
+	return q
+}
+
+// CostSeries calls GET /cost/series: Cost time series at a resolution planned from the range.
+func (c *Client) CostSeries(ctx context.Context, params CostSeriesParams) (*CostSeriesResponse, error) {
+	var out CostSeriesResponse
+	if err := c.do(ctx, "GET", "/cost/series", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAPIKey calls POST /admin/service-accounts/{id}/keys: Issue an API key for a service
+// account.
+func (c *Client) CreateAPIKey(ctx context.Context, id string, body CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	var out CreatedAPIKey
+	if err := c.do(ctx, "POST", "/admin/service-accounts/"+url.PathEscape(id)+"/keys", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAdjustment calls POST /admin/adjustments: Record a manual adjustment of a closed accounting
+// period.
+func (c *Client) CreateAdjustment(ctx context.Context, body CreateAdjustmentRequest) (*CostAdjustment, error) {
+	var out CostAdjustment
+	if err := c.do(ctx, "POST", "/admin/adjustments", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAlertRule calls POST /alerts/rules: Create an alert rule.
+func (c *Client) CreateAlertRule(ctx context.Context, body AlertRuleRequest) (*AlertRule, error) {
+	var out AlertRule
+	if err := c.do(ctx, "POST", "/alerts/rules", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateExportJob calls POST /exports: Create an async export job.
+func (c *Client) CreateExportJob(ctx context.Context, body CreateExportJobRequest) (*ExportJob, error) {
+	var out ExportJob
+	if err := c.do(ctx, "POST", "/exports", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateNotificationRoute calls POST /alerts/routes: Create a notification routing rule.
+func (c *Client) CreateNotificationRoute(ctx context.Context, body NotificationRouteRequest) (*NotificationRoute, error) {
+	var out NotificationRoute
+	if err := c.do(ctx, "POST", "/alerts/routes", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateServiceAccount calls POST /admin/service-accounts: Create a service account.
+func (c *Client) CreateServiceAccount(ctx context.Context, body CreateServiceAccountRequest) (*ServiceAccount, error) {
+	var out ServiceAccount
+	if err := c.do(ctx, "POST", "/admin/service-accounts", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateSilence calls POST /alerts/silences: Silence matching notifications for a time window.
+func (c *Client) CreateSilence(ctx context.Context, body CreateSilenceRequest) (*Silence, error) {
+	var out Silence
+	if err := c.do(ctx, "POST", "/alerts/silences", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAlertRule calls DELETE /alerts/rules/{id}: Delete an alert rule.
+func (c *Client) DeleteAlertRule(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/alerts/rules/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// DeleteNotificationRoute calls DELETE /alerts/routes/{id}: Delete a notification routing rule.
+func (c *Client) DeleteNotificationRoute(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/alerts/routes/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// DeletePreferenceView calls DELETE /preferences/views/{id}: Delete a saved view of the calling
+// user.
+func (c *Client) DeletePreferenceView(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/preferences/views/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// DeleteServiceAccount calls DELETE /admin/service-accounts/{id}: Delete a service account and its
+// API keys.
+func (c *Client) DeleteServiceAccount(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/admin/service-accounts/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// DeleteSpoolEntry calls DELETE /admin/spool/{id}: Discard a spool entry.
+func (c *Client) DeleteSpoolEntry(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/admin/spool/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// DownloadExportJob calls GET /exports/{id}/download: Download the file of an export job.
+func (c *Client) DownloadExportJob(ctx context.Context, id string) error {
+	return c.do(ctx, "GET", "/exports/"+url.PathEscape(id)+"/download", nil, nil, nil)
+}
+
+// DrilldownCostParams holds the query parameters of GET /cost/drilldown/{level}/{identifier}; zero values are not sent.
+type DrilldownCostParams struct {
+	// cost dimension
+	Dimension string
+}
+
+func (p DrilldownCostParams) values() url.Values {
+	q := url.Values{}
+	if p.Dimension != "" {
+		q.Set("dimension", p.Dimension)
+	}
+	return q
+}
+
+// DrilldownCost calls GET /cost/drilldown/{level}/{identifier}: Cost drilldown by level.
+func (c *Client) DrilldownCost(ctx context.Context, level string, identifier string, params DrilldownCostParams) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(ctx, "GET", "/cost/drilldown/"+url.PathEscape(level)+"/"+url.PathEscape(identifier), params.values(), nil, &out)
+	return out, err
+}
+
+// EfficiencyHeatmapParams holds the query parameters of GET /cost/efficiency/heatmap; zero values are not sent.
+type EfficiencyHeatmapParams struct {
+	// window length ending today, default 30
+	Days int
+	// days per bucket, default 1
+	BucketDays int
+	// namespaces with the highest billable cost to keep
+	Limit int
+}
+
+func (p EfficiencyHeatmapParams) values() url.Values {
+	q := url.Values{}
+	if p.Days != 0 {
+		q.Set("days", strconv.Itoa(p.Days))
+	}
+	if p.BucketDays != 0 {
+		q.Set("bucket_days", strconv.Itoa(p.BucketDays))
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// EfficiencyHeatmap calls GET /cost/efficiency/heatmap: Efficiency heatmap of namespaces by day.
+func (c *Client) EfficiencyHeatmap(ctx context.Context, params EfficiencyHeatmapParams) (*EfficiencyHeatmapResponse, error) {
+	var out EfficiencyHeatmapResponse
+	if err := c.do(ctx, "GET", "/cost/efficiency/heatmap", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EvaluateAlertRule calls POST /alerts/rules/{id}/evaluate: Evaluate an alert rule now.
+func (c *Client) EvaluateAlertRule(ctx context.Context, id string) (*AlertRuleEvaluation, error) {
+	var out AlertRuleEvaluation
+	if err := c.do(ctx, "POST", "/alerts/rules/"+url.PathEscape(id)+"/evaluate", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExpireSilence calls DELETE /alerts/silences/{id}: End a notification silence.
+func (c *Client) ExpireSilence(ctx context.Context, id string) (*Silence, error) {
+	var out Silence
+	if err := c.do(ctx, "DELETE", "/alerts/silences/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportDatasetParams holds the query parameters of GET /admin/dataset/export; zero values are not sent.
+type ExportDatasetParams struct {
+	// window start (RFC3339), default end_time - 7 days
+	StartTime time.Time
+	// window end (RFC3339), default the current hour
+	EndTime time.Time
+	// replace namespace, workload, pod, node and cost center names with pseudonyms
+	Anonymize bool
+	// cost multiplier, default 1
+	Scale float64
+}
+
+func (p ExportDatasetParams) values() url.Values {
+	q := url.Values{}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	if p.Anonymize {
+		q.Set("anonymize", strconv.FormatBool(p.Anonymize))
+	}
+	if p.Scale != 0 {
+		q.Set("scale", strconv.FormatFloat(p.Scale, 'g', -1, 64))
+	}
+	return q
+}
+
+// ExportDataset calls GET /admin/dataset/export: Export the cost dataset, optionally anonymized.
+func (c *Client) ExportDataset(ctx context.Context, params ExportDatasetParams) (*Dataset, error) {
+	var out Dataset
+	if err := c.do(ctx, "GET", "/admin/dataset/export", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportRecommendations calls POST /roi/recommendations/export: Export accepted recommendations as
+// Kubernetes patch manifests.
+func (c *Client) ExportRecommendations(ctx context.Context, body ExportRecommendationsRequest) error {
+	return c.do(ctx, "POST", "/roi/recommendations/export", nil, body, nil)
+}
+
+// ExportSnapshotsParams holds the query parameters of GET /snapshots/export; zero values are not sent.
+type ExportSnapshotsParams struct {
+	// window start (RFC3339)
+	StartTime time.Time
+	// window end (RFC3339)
+	EndTime time.Time
+}
+
+func (p ExportSnapshotsParams) values() url.Values {
+	q := url.Values{}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	return q
+}
+
+// ExportSnapshots calls GET /snapshots/export: Export verified cost snapshots.
+func (c *Client) ExportSnapshots(ctx context.Context, params ExportSnapshotsParams) (*SnapshotExportResponse, error) {
+	var out SnapshotExportResponse
+	if err := c.do(ctx, "GET", "/snapshots/export", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportState calls GET /admin/state/export: Export the application state bundle.
+func (c *Client) ExportState(ctx context.Context) (*StateBundle, error) {
+	var out StateBundle
+	if err := c.do(ctx, "GET", "/admin/state/export", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FiscalReportParams holds the query parameters of GET /cost/fiscal-report; zero values are not sent.
+type FiscalReportParams struct {
+	// period, quarter or year; default period
+	Unit string
+	// a day of the span (YYYY-MM-DD), default today
+	Date string
+}
+
+func (p FiscalReportParams) values() url.Values {
+	q := url.Values{}
+	if p.Unit != "" {
+		q.Set("unit", p.Unit)
+	}
+	if p.Date != "" {
+		q.Set("date", p.Date)
+	}
+	return q
+}
+
+// FiscalReport calls GET /cost/fiscal-report: Cost of a fiscal period, quarter or year to date.
+func (c *Client) FiscalReport(ctx context.Context, params FiscalReportParams) (*FiscalReportResponse, error) {
+	var out FiscalReportResponse
+	if err := c.do(ctx, "GET", "/cost/fiscal-report", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FlushSpool calls POST /admin/spool/flush: Replay all pending spool entries.
+func (c *Client) FlushSpool(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(ctx, "POST", "/admin/spool/flush", nil, nil, &out)
+	return out, err
+}
+
+// GetAlertRule calls GET /alerts/rules/{id}: Get an alert rule with its state.
+func (c *Client) GetAlertRule(ctx context.Context, id string) (*AlertRule, error) {
+	var out AlertRule
+	if err := c.do(ctx, "GET", "/alerts/rules/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCalculationRun calls GET /calculations/runs/{id}: A cost calculation run.
+func (c *Client) GetCalculationRun(ctx context.Context, id string) (*CalculationRun, error) {
+	var out CalculationRun
+	if err := c.do(ctx, "GET", "/calculations/runs/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetChaos calls GET /admin/chaos: Configured chaos scenarios and the current or last resilience
+// drill.
+func (c *Client) GetChaos(ctx context.Context) (*ChaosResponse, error) {
+	var out ChaosResponse
+	if err := c.do(ctx, "GET", "/admin/chaos", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetExportJob calls GET /exports/{id}: Get an export job.
+func (c *Client) GetExportJob(ctx context.Context, id string) (*ExportJob, error) {
+	var out ExportJob
+	if err := c.do(ctx, "GET", "/exports/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetGradeView calls GET /admin/regrade/view: The view of snapshot grade counts currently served.
+func (c *Client) GetGradeView(ctx context.Context) (*GradeView, error) {
+	var out GradeView
+	if err := c.do(ctx, "GET", "/admin/regrade/view", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLineage calls GET /lineage/{id}: Data lineage of a cost query response.
+func (c *Client) GetLineage(ctx context.Context, id string) (*QueryLineage, error) {
+	var out QueryLineage
+	if err := c.do(ctx, "GET", "/lineage/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetNotificationRoute calls GET /alerts/routes/{id}: Get a notification routing rule.
+func (c *Client) GetNotificationRoute(ctx context.Context, id string) (*NotificationRoute, error) {
+	var out NotificationRoute
+	if err := c.do(ctx, "GET", "/alerts/routes/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOnboarding calls GET /admin/onboarding/{namespace}: Get the onboarding of a namespace.
+func (c *Client) GetOnboarding(ctx context.Context, namespace string) (*NamespaceOnboarding, error) {
+	var out NamespaceOnboarding
+	if err := c.do(ctx, "GET", "/admin/onboarding/"+url.PathEscape(namespace), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPreferences calls GET /preferences: Get the preferences of the calling user (defaults when
+// none are saved).
+func (c *Client) GetPreferences(ctx context.Context) (*UserPreferences, error) {
+	var out UserPreferences
+	if err := c.do(ctx, "GET", "/preferences", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPriceChange calls GET /pricing/changes/{id}: Get a price change with its history.
+func (c *Client) GetPriceChange(ctx context.Context, id string) (*PriceChange, error) {
+	var out PriceChange
+	if err := c.do(ctx, "GET", "/pricing/changes/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPriceHistory calls GET /pricing/history: Unit price history.
+func (c *Client) GetPriceHistory(ctx context.Context) (*PriceHistoryResponse, error) {
+	var out PriceHistoryResponse
+	if err := c.do(ctx, "GET", "/pricing/history", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetServiceAccount calls GET /admin/service-accounts/{id}: Get a service account with its API keys
+// and today's usage.
+func (c *Client) GetServiceAccount(ctx context.Context, id string) (*ServiceAccount, error) {
+	var out ServiceAccount
+	if err := c.do(ctx, "GET", "/admin/service-accounts/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSnapshotParams holds the query parameters of GET /snapshots/{id}; zero values are not sent.
+type GetSnapshotParams struct {
+	// full (default), summary or counts
+	Fields string
+	// comma-separated aggregated_results, resource_results
+	Include string
+}
+
+func (p GetSnapshotParams) values() url.Values {
+	q := url.Values{}
+	if p.Fields != "" {
+		q.Set("fields", p.Fields)
+	}
+	if p.Include != "" {
+		q.Set("include", p.Include)
+	}
+	return q
+}
+
+// GetSnapshot calls GET /snapshots/{id}: A cost snapshot with field selection.
+func (c *Client) GetSnapshot(ctx context.Context, id string, params GetSnapshotParams) (*CostSnapshot, error) {
+	var out CostSnapshot
+	if err := c.do(ctx, "GET", "/snapshots/"+url.PathEscape(id), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSpoolEntry calls GET /admin/spool/{id}: A spool entry with its payload.
+func (c *Client) GetSpoolEntry(ctx context.Context, id string) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(ctx, "GET", "/admin/spool/"+url.PathEscape(id), nil, nil, &out)
+	return out, err
+}
+
+// GlobalCost calls GET /cost/global: Global cost overview (L0 = sum of L1).
+func (c *Client) GlobalCost(ctx context.Context) (*GlobalCostResponse, error) {
+	var out GlobalCostResponse
+	if err := c.do(ctx, "GET", "/cost/global", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GradeHistoryParams holds the query parameters of GET /cost/grades/history; zero values are not sent.
+type GradeHistoryParams struct {
+	// namespace
+	Namespace string
+	// workload name
+	Workload string
+	// grade transitioned to
+	Grade string
+	// window start (RFC3339)
+	StartTime time.Time
+	// window end (RFC3339)
+	EndTime time.Time
+	// page size
+	Limit int
+	// page offset
+	Offset int
+}
+
+func (p GradeHistoryParams) values() url.Values {
+	q := url.Values{}
+	if p.Namespace != "" {
+		q.Set("namespace", p.Namespace)
+	}
+	if p.Workload != "" {
+		q.Set("workload", p.Workload)
+	}
+	if p.Grade != "" {
+		q.Set("grade", p.Grade)
+	}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	return q
+}
+
+// GradeHistory calls GET /cost/grades/history: Workload efficiency grade transitions.
+func (c *Client) GradeHistory(ctx context.Context, params GradeHistoryParams) (*GradeHistoryResponse, error) {
+	var out GradeHistoryResponse
+	if err := c.do(ctx, "GET", "/cost/grades/history", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GrafanaAnnotations calls POST /grafana/annotations: Grafana annotations.
+func (c *Client) GrafanaAnnotations(ctx context.Context, body GrafanaAnnotationRequest) ([]GrafanaAnnotation, error) {
+	var out []GrafanaAnnotation
+	err := c.do(ctx, "POST", "/grafana/annotations", nil, body, &out)
+	return out, err
+}
+
+// GrafanaQuery calls POST /grafana/query: Grafana time series / table query.
+func (c *Client) GrafanaQuery(ctx context.Context, body GrafanaQueryRequest) ([]json.RawMessage, error) {
+	var out []json.RawMessage
+	err := c.do(ctx, "POST", "/grafana/query", nil, body, &out)
+	return out, err
+}
+
+// GrafanaSearch calls POST /grafana/search: Grafana metric search.
+func (c *Client) GrafanaSearch(ctx context.Context, body GrafanaSearchRequest) ([]string, error) {
+	var out []string
+	err := c.do(ctx, "POST", "/grafana/search", nil, body, &out)
+	return out, err
+}
+
+// GrafanaTestConnection calls GET /grafana: Grafana datasource test connection.
+func (c *Client) GrafanaTestConnection(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(ctx, "GET", "/grafana", nil, nil, &out)
+	return out, err
+}
+
+// ImportAllocationsParams holds the query parameters of POST /admin/import/allocations; zero values are not sent.
+type ImportAllocationsParams struct {
+	// source of the export, default kubecost
+	Source string
+	// json (allocation API response) or csv (allocation CSV export)
+	Format string
+	// only report the rows the import would write
+	DryRun bool
+}
+
+func (p ImportAllocationsParams) values() url.Values {
+	q := url.Values{}
+	if p.Source != "" {
+		q.Set("source", p.Source)
+	}
+	if p.Format != "" {
+		q.Set("format", p.Format)
+	}
+	if p.DryRun {
+		q.Set("dry_run", strconv.FormatBool(p.DryRun))
+	}
+	return q
+}
+
+// ImportAllocations calls POST /admin/import/allocations: Import Kubecost / OpenCost allocation
+// history.
+func (c *Client) ImportAllocations(ctx context.Context, body json.RawMessage, params ImportAllocationsParams) (*CostImportResponse, error) {
+	var out CostImportResponse
+	if err := c.do(ctx, "POST", "/admin/import/allocations", params.values(), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ImportStateParams holds the query parameters of POST /admin/state/import; zero values are not sent.
+type ImportStateParams struct {
+	// only report the changes
+	DryRun bool
+}
+
+func (p ImportStateParams) values() url.Values {
+	q := url.Values{}
+	if p.DryRun {
+		q.Set("dry_run", strconv.FormatBool(p.DryRun))
+	}
+	return q
+}
+
+// ImportState calls POST /admin/state/import: Import an application state bundle.
+func (c *Client) ImportState(ctx context.Context, body StateBundle, params ImportStateParams) (*StateImportResponse, error) {
+	var out StateImportResponse
+	if err := c.do(ctx, "POST", "/admin/state/import", params.values(), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// IngestDependencies calls POST /dependencies/edges: Ingest service dependency edges.
+func (c *Client) IngestDependencies(ctx context.Context, body DependencyIngestRequest) (*DependencyIngestResponse, error) {
+	var out DependencyIngestResponse
+	if err := c.do(ctx, "POST", "/dependencies/edges", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAccountingPeriodsParams holds the query parameters of GET /admin/periods; zero values are not sent.
+type ListAccountingPeriodsParams struct {
+	// page size, max 500 (default all)
+	Limit int
+	// page offset
+	Offset int
+	// next_cursor of the previous page (instead of offset)
+	Cursor string
+}
+
+func (p ListAccountingPeriodsParams) values() url.Values {
+	q := url.Values{}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// ListAccountingPeriods calls GET /admin/periods: List closed accounting periods.
+func (c *Client) ListAccountingPeriods(ctx context.Context, params ListAccountingPeriodsParams) (*AccountingPeriodList, error) {
+	var out AccountingPeriodList
+	if err := c.do(ctx, "GET", "/admin/periods", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAdjustmentsParams holds the query parameters of GET /admin/adjustments; zero values are not sent.
+type ListAdjustmentsParams struct {
+	// period label, e.g. FY2026 P09
+	Period string
+	// namespace
+	Namespace string
+	// page size, max 500 (default all)
+	Limit int
+	// page offset
+	Offset int
+	// next_cursor of the previous page (instead of offset)
+	Cursor string
+}
+
+func (p ListAdjustmentsParams) values() url.Values {
+	q := url.Values{}
+	if p.Period != "" {
+		q.Set("period", p.Period)
+	}
+	if p.Namespace != "" {
+		q.Set("namespace", p.Namespace)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// ListAdjustments calls GET /admin/adjustments: List adjustments of closed accounting periods.
+func (c *Client) ListAdjustments(ctx context.Context, params ListAdjustmentsParams) (*CostAdjustmentList, error) {
+	var out CostAdjustmentList
+	if err := c.do(ctx, "GET", "/admin/adjustments", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAlertRules calls GET /alerts/rules: List alert rules with their state and the metrics
+// conditions may use.
+func (c *Client) ListAlertRules(ctx context.Context) (*AlertRuleListResponse, error) {
+	var out AlertRuleListResponse
+	if err := c.do(ctx, "GET", "/alerts/rules", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListCalculationRunsParams holds the query parameters of GET /calculations/runs; zero values are not sent.
+type ListCalculationRunsParams struct {
+	// run status
+	Status string
+	// run trigger
+	Trigger string
+	// window start (RFC3339)
+	StartTime time.Time
+	// window end (RFC3339)
+	EndTime time.Time
+	// page size
+	Limit int
+	// page offset
+	Offset int
+}
+
+func (p ListCalculationRunsParams) values() url.Values {
+	q := url.Values{}
+	if p.Status != "" {
+		q.Set("status", p.Status)
+	}
+	if p.Trigger != "" {
+		q.Set("trigger", p.Trigger)
+	}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	return q
+}
+
+// ListCalculationRuns calls GET /calculations/runs: Cost calculation runs.
+func (c *Client) ListCalculationRuns(ctx context.Context, params ListCalculationRunsParams) (*CalculationRunListResponse, error) {
+	var out CalculationRunListResponse
+	if err := c.do(ctx, "GET", "/calculations/runs", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListDependenciesParams holds the query parameters of GET /dependencies; zero values are not sent.
+type ListDependenciesParams struct {
+	// page size, max 500 (default all)
+	Limit int
+	// page offset
+	Offset int
+	// next_cursor of the previous page (instead of offset)
+	Cursor string
+}
+
+func (p ListDependenciesParams) values() url.Values {
+	q := url.Values{}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// ListDependencies calls GET /dependencies: List service dependency edges.
+func (c *Client) ListDependencies(ctx context.Context, params ListDependenciesParams) (*DependencyEdgeList, error) {
+	var out DependencyEdgeList
+	if err := c.do(ctx, "GET", "/dependencies", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListEfficiencyTargetsParams holds the query parameters of GET /cost/targets; zero values are not sent.
+type ListEfficiencyTargetsParams struct {
+	// page size, max 500 (default all)
+	Limit int
+	// page offset
+	Offset int
+	// next_cursor of the previous page (instead of offset)
+	Cursor string
+}
+
+func (p ListEfficiencyTargetsParams) values() url.Values {
+	q := url.Values{}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// ListEfficiencyTargets calls GET /cost/targets: List namespace efficiency targets.
+func (c *Client) ListEfficiencyTargets(ctx context.Context, params ListEfficiencyTargetsParams) (*EfficiencyTargetListResponse, error) {
+	var out EfficiencyTargetListResponse
+	if err := c.do(ctx, "GET", "/cost/targets", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListExportJobs calls GET /exports: List export jobs and the accepted formats.
+func (c *Client) ListExportJobs(ctx context.Context) (*ExportJobListResponse, error) {
+	var out ExportJobListResponse
+	if err := c.do(ctx, "GET", "/exports", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListNamespacesParams holds the query parameters of GET /cost/namespaces; zero values are not sent.
+type ListNamespacesParams struct {
+	// sort field, default cost
+	Sort string
+	// sort order, default desc
+	Order string
+	// page size, max 500 (default all)
+	Limit int
+	// page offset
+	Offset int
+	// next_cursor of the previous page (instead of offset)
+	Cursor string
+}
+
+func (p ListNamespacesParams) values() url.Values {
+	q := url.Values{}
+	if p.Sort != "" {
+		q.Set("sort", p.Sort)
+	}
+	if p.Order != "" {
+		q.Set("order", p.Order)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// ListNamespaces calls GET /cost/namespaces: Namespaces with cost summary.
+func (c *Client) ListNamespaces(ctx context.Context, params ListNamespacesParams) (*NamespaceCostSummaryList, error) {
+	var out NamespaceCostSummaryList
+	if err := c.do(ctx, "GET", "/cost/namespaces", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListNotificationRoutes calls GET /alerts/routes: List notification routing rules in matching
+// order.
+func (c *Client) ListNotificationRoutes(ctx context.Context) (*NotificationRouteListResponse, error) {
+	var out NotificationRouteListResponse
+	if err := c.do(ctx, "GET", "/alerts/routes", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListOnboardingsParams holds the query parameters of GET /admin/onboarding; zero values are not sent.
+type ListOnboardingsParams struct {
+	// page size, max 500 (default all)
+	Limit int
+	// page offset
+	Offset int
+	// next_cursor of the previous page (instead of offset)
+	Cursor string
+}
+
+func (p ListOnboardingsParams) values() url.Values {
+	q := url.Values{}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// ListOnboardings calls GET /admin/onboarding: List onboarded namespaces.
+func (c *Client) ListOnboardings(ctx context.Context, params ListOnboardingsParams) (*NamespaceOnboardingList, error) {
+	var out NamespaceOnboardingList
+	if err := c.do(ctx, "GET", "/admin/onboarding", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListOptimizationRecordsParams holds the query parameters of GET /roi/optimizations; zero values are not sent.
+type ListOptimizationRecordsParams struct {
+	// manifest bundle ID (X-Bundle-ID of the export)
+	BundleID string
+	// pending or verified
+	Status string
+	// page size, max 500 (default all)
+	Limit int
+	// page offset
+	Offset int
+	// next_cursor of the previous page (instead of offset)
+	Cursor string
+}
+
+func (p ListOptimizationRecordsParams) values() url.Values {
+	q := url.Values{}
+	if p.BundleID != "" {
+		q.Set("bundle_id", p.BundleID)
+	}
+	if p.Status != "" {
+		q.Set("status", p.Status)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// ListOptimizationRecords calls GET /roi/optimizations: Optimization tracking records, e.g. of
+// exported recommendations awaiting verification.
+func (c *Client) ListOptimizationRecords(ctx context.Context, params ListOptimizationRecordsParams) (*OptimizationRecordListResponse, error) {
+	var out OptimizationRecordListResponse
+	if err := c.do(ctx, "GET", "/roi/optimizations", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListPriceChangesParams holds the query parameters of GET /pricing/changes; zero values are not sent.
+type ListPriceChangesParams struct {
+	// draft, approved, effective or rejected
+	Status string
+}
+
+func (p ListPriceChangesParams) values() url.Values {
+	q := url.Values{}
+	if p.Status != "" {
+		q.Set("status", p.Status)
+	}
+	return q
+}
+
+// ListPriceChanges calls GET /pricing/changes: List price changes.
+func (c *Client) ListPriceChanges(ctx context.Context, params ListPriceChangesParams) (*PriceChangeListResponse, error) {
+	var out PriceChangeListResponse
+	if err := c.do(ctx, "GET", "/pricing/changes", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListQueryBudgets calls GET /admin/query-budgets: Prometheus and database queries of every API
+// consumer in the current query budget window.
+func (c *Client) ListQueryBudgets(ctx context.Context) (*QueryBudgetResponse, error) {
+	var out QueryBudgetResponse
+	if err := c.do(ctx, "GET", "/admin/query-budgets", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListServiceAccounts calls GET /admin/service-accounts: List service accounts with their API keys
+// and today's usage.
+func (c *Client) ListServiceAccounts(ctx context.Context) (*ServiceAccountListResponse, error) {
+	var out ServiceAccountListResponse
+	if err := c.do(ctx, "GET", "/admin/service-accounts", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSilencesParams holds the query parameters of GET /alerts/silences; zero values are not sent.
+type ListSilencesParams struct {
+	// include expired silences
+	All bool
+}
+
+func (p ListSilencesParams) values() url.Values {
+	q := url.Values{}
+	if p.All {
+		q.Set("all", strconv.FormatBool(p.All))
+	}
+	return q
+}
+
+// ListSilences calls GET /alerts/silences: List notification silences.
+func (c *Client) ListSilences(ctx context.Context, params ListSilencesParams) (*SilenceListResponse, error) {
+	var out SilenceListResponse
+	if err := c.do(ctx, "GET", "/alerts/silences", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSnapshotsParams holds the query parameters of GET /snapshots; zero values are not sent.
+type ListSnapshotsParams struct {
+	// window start (RFC3339)
+	StartTime string
+	// window end (RFC3339)
+	EndTime string
+	// max snapshots
+	Limit int
+	// snapshots to skip
+	Offset int
+	// full, summary (default) or counts
+	Fields string
+	// comma-separated aggregated_results, resource_results
+	Include string
+}
+
+func (p ListSnapshotsParams) values() url.Values {
+	q := url.Values{}
+	if p.StartTime != "" {
+		q.Set("start_time", p.StartTime)
+	}
+	if p.EndTime != "" {
+		q.Set("end_time", p.EndTime)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Fields != "" {
+		q.Set("fields", p.Fields)
+	}
+	if p.Include != "" {
+		q.Set("include", p.Include)
+	}
+	return q
+}
+
+// ListSnapshots calls GET /snapshots: List cost snapshots.
+func (c *Client) ListSnapshots(ctx context.Context, params ListSnapshotsParams) (*CostSnapshotListResponse, error) {
+	var out CostSnapshotListResponse
+	if err := c.do(ctx, "GET", "/snapshots", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSpool calls GET /admin/spool: Spool stats and pending entries.
+func (c *Client) ListSpool(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(ctx, "GET", "/admin/spool", nil, nil, &out)
+	return out, err
+}
+
+// ListSuspectSnapshots calls GET /snapshots/suspect: List suspect cost snapshots.
+func (c *Client) ListSuspectSnapshots(ctx context.Context) (*SuspectSnapshotListResponse, error) {
+	var out SuspectSnapshotListResponse
+	if err := c.do(ctx, "GET", "/snapshots/suspect", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NamespaceCostParams holds the query parameters of GET /cost/namespace/{namespace}; zero values are not sent.
+type NamespaceCostParams struct {
+	// workload sort field, default cost
+	Sort string
+	// workload sort order, default desc
+	Order string
+	// workload page size, default 50, max 500
+	Limit int
+	// workload page offset
+	Offset int
+}
+
+func (p NamespaceCostParams) values() url.Values {
+	q := url.Values{}
+	if p.Sort != "" {
+		q.Set("sort", p.Sort)
+	}
+	if p.Order != "" {
+		q.Set("order", p.Order)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	return q
+}
+
+// NamespaceCost calls GET /cost/namespace/{namespace}: Cost breakdown of a namespace.
+func (c *Client) NamespaceCost(ctx context.Context, namespace string, params NamespaceCostParams) (*NamespaceCostResponse, error) {
+	var out NamespaceCostResponse
+	if err := c.do(ctx, "GET", "/cost/namespace/"+url.PathEscape(namespace), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NodeAnalysisParams holds the query parameters of GET /nodes/analysis; zero values are not sent.
+type NodeAnalysisParams struct {
+	// target utilization in (0, 1], default 0.85
+	TargetUtilization float64
+}
+
+func (p NodeAnalysisParams) values() url.Values {
+	q := url.Values{}
+	if p.TargetUtilization != 0 {
+		q.Set("target_utilization", strconv.FormatFloat(p.TargetUtilization, 'g', -1, 64))
+	}
+	return q
+}
+
+// NodeAnalysis calls GET /nodes/analysis: Per-node costs and consolidation candidates.
+func (c *Client) NodeAnalysis(ctx context.Context, params NodeAnalysisParams) (*NodeAnalysisResponse, error) {
+	var out NodeAnalysisResponse
+	if err := c.do(ctx, "GET", "/nodes/analysis", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NodeConsolidationPlanParams holds the query parameters of GET /nodes/consolidation-plan; zero values are not sent.
+type NodeConsolidationPlanParams struct {
+	// target utilization in (0, 1], default 0.85
+	TargetUtilization float64
+}
+
+func (p NodeConsolidationPlanParams) values() url.Values {
+	q := url.Values{}
+	if p.TargetUtilization != 0 {
+		q.Set("target_utilization", strconv.FormatFloat(p.TargetUtilization, 'g', -1, 64))
+	}
+	return q
+}
+
+// NodeConsolidationPlan calls GET /nodes/consolidation-plan: Reviewable node drain plan with
+// savings and risk.
+func (c *Client) NodeConsolidationPlan(ctx context.Context, params NodeConsolidationPlanParams) (*NodeConsolidationPlan, error) {
+	var out NodeConsolidationPlan
+	if err := c.do(ctx, "GET", "/nodes/consolidation-plan", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NodePoolAnalysisParams holds the query parameters of GET /nodes/pools; zero values are not sent.
+type NodePoolAnalysisParams struct {
+	// window start (RFC3339), default end_time - 24h
+	StartTime string
+	// window end (RFC3339), default now
+	EndTime string
+}
+
+func (p NodePoolAnalysisParams) values() url.Values {
+	q := url.Values{}
+	if p.StartTime != "" {
+		q.Set("start_time", p.StartTime)
+	}
+	if p.EndTime != "" {
+		q.Set("end_time", p.EndTime)
+	}
+	return q
+}
+
+// NodePoolAnalysis calls GET /nodes/pools: Capacity and workload cost per node pool.
+func (c *Client) NodePoolAnalysis(ctx context.Context, params NodePoolAnalysisParams) (*NodePoolAnalysisResponse, error) {
+	var out NodePoolAnalysisResponse
+	if err := c.do(ctx, "GET", "/nodes/pools", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OffHoursAnalysisParams holds the query parameters of GET /analysis/offhours; zero values are not sent.
+type OffHoursAnalysisParams struct {
+	// window length, default 7
+	Days int
+	// namespace
+	Namespace string
+	// workloads with the highest idle off-hours cost to keep
+	Limit int
+}
+
+func (p OffHoursAnalysisParams) values() url.Values {
+	q := url.Values{}
+	if p.Days != 0 {
+		q.Set("days", strconv.Itoa(p.Days))
+	}
+	if p.Namespace != "" {
+		q.Set("namespace", p.Namespace)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// OffHoursAnalysis calls GET /analysis/offhours: Off-hours (nights/weekends) idle cost and scaling
+// candidates.
+func (c *Client) OffHoursAnalysis(ctx context.Context, params OffHoursAnalysisParams) (*OffHoursAnalysisResponse, error) {
+	var out OffHoursAnalysisResponse
+	if err := c.do(ctx, "GET", "/analysis/offhours", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OnboardNamespace calls POST /admin/onboarding: Onboard a namespace.
+func (c *Client) OnboardNamespace(ctx context.Context, body OnboardNamespaceRequest) (*NamespaceOnboarding, error) {
+	var out NamespaceOnboarding
+	if err := c.do(ctx, "POST", "/admin/onboarding", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PreviewAllocation calls POST /cost/allocation/preview: Preview the per-namespace impact of
+// candidate shared cost allocation rules.
+func (c *Client) PreviewAllocation(ctx context.Context, body AllocationPreviewRequest) (*AllocationPreviewResponse, error) {
+	var out AllocationPreviewResponse
+	if err := c.do(ctx, "POST", "/cost/allocation/preview", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProposePriceChange calls POST /pricing/changes: Propose a unit price change.
+func (c *Client) ProposePriceChange(ctx context.Context, body SetPriceRequest) (*PriceChange, error) {
+	var out PriceChange
+	if err := c.do(ctx, "POST", "/pricing/changes", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecalculatePrices calls POST /pricing/recalculate: Re-price a window with the price history.
+func (c *Client) RecalculatePrices(ctx context.Context, body TriggerCalculationRequest) (*CalculationRun, error) {
+	var out CalculationRun
+	if err := c.do(ctx, "POST", "/pricing/recalculate", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecommendationAdoptionParams holds the query parameters of GET /roi/recommendations/adoption; zero values are not sent.
+type RecommendationAdoptionParams struct {
+	// issued since (RFC3339), default 90 days ago
+	Since string
+}
+
+func (p RecommendationAdoptionParams) values() url.Values {
+	q := url.Values{}
+	if p.Since != "" {
+		q.Set("since", p.Since)
+	}
+	return q
+}
+
+// RecommendationAdoption calls GET /roi/recommendations/adoption: Adoption rate of issued
+// recommendations per team and their unrealized savings.
+func (c *Client) RecommendationAdoption(ctx context.Context, params RecommendationAdoptionParams) (*RecommendationAdoptionResponse, error) {
+	var out RecommendationAdoptionResponse
+	if err := c.do(ctx, "GET", "/roi/recommendations/adoption", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecordConfigChange calls POST /timeline/config-changes: Record a config change on the timeline.
+func (c *Client) RecordConfigChange(ctx context.Context, body ConfigChangeRequest) (*ConfigChange, error) {
+	var out ConfigChange
+	if err := c.do(ctx, "POST", "/timeline/config-changes", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecordSLOViolation calls POST /timeline/slo-violations: Record an SLO violation on the timeline.
+func (c *Client) RecordSLOViolation(ctx context.Context, body SLOViolationRequest) (*SLOViolationRecord, error) {
+	var out SLOViolationRecord
+	if err := c.do(ctx, "POST", "/timeline/slo-violations", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RegradeSnapshots calls POST /admin/regrade: Regrade cost snapshots under new efficiency
+// thresholds.
+func (c *Client) RegradeSnapshots(ctx context.Context, body RegradeRequest) (*RegradeResponse, error) {
+	var out RegradeResponse
+	if err := c.do(ctx, "POST", "/admin/regrade", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RejectPriceChange calls POST /pricing/changes/{id}/reject: Reject a price change.
+func (c *Client) RejectPriceChange(ctx context.Context, id string, body ReviewPriceChangeRequest) (*PriceChange, error) {
+	var out PriceChange
+	if err := c.do(ctx, "POST", "/pricing/changes/"+url.PathEscape(id)+"/reject", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RetriggerCalculationRun calls POST /calculations/runs/{id}/retrigger: Re-run a failed calculation
+// run.
+func (c *Client) RetriggerCalculationRun(ctx context.Context, id string) (*CalculationRun, error) {
+	var out CalculationRun
+	if err := c.do(ctx, "POST", "/calculations/runs/"+url.PathEscape(id)+"/retrigger", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeAPIKey calls DELETE /admin/service-accounts/{id}/keys/{key_id}: Revoke an API key.
+func (c *Client) RevokeAPIKey(ctx context.Context, id string, keyID string) (*APIKey, error) {
+	var out APIKey
+	if err := c.do(ctx, "DELETE", "/admin/service-accounts/"+url.PathEscape(id)+"/keys/"+url.PathEscape(keyID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ROIDashboard calls GET /roi/dashboard: ROI summary and trends.
+func (c *Client) ROIDashboard(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(ctx, "GET", "/roi/dashboard", nil, nil, &out)
+	return out, err
+}
+
+// SavePreferenceView calls POST /preferences/views: Save a view (named page filters) of the calling
+// user.
+func (c *Client) SavePreferenceView(ctx context.Context, body SavedViewRequest) (*SavedView, error) {
+	var out SavedView
+	if err := c.do(ctx, "POST", "/preferences/views", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchCatalogParams holds the query parameters of GET /workloads/catalog; zero values are not sent.
+type SearchCatalogParams struct {
+	// free text; every word must prefix-match the workload
+	Q string
+	// namespace facet
+	Namespace string
+	// owner team facet
+	Team string
+	// grade facet
+	Grade string
+	// workload type facet
+	Type string
+	// SLO status facet
+	SLOStatus string
+	// page size, default 50, max 500
+	Limit int
+	// page offset
+	Offset int
+}
+
+func (p SearchCatalogParams) values() url.Values {
+	q := url.Values{}
+	if p.Q != "" {
+		q.Set("q", p.Q)
+	}
+	if p.Namespace != "" {
+		q.Set("namespace", p.Namespace)
+	}
+	if p.Team != "" {
+		q.Set("team", p.Team)
+	}
+	if p.Grade != "" {
+		q.Set("grade", p.Grade)
+	}
+	if p.Type != "" {
+		q.Set("type", p.Type)
+	}
+	if p.SLOStatus != "" {
+		q.Set("slo_status", p.SLOStatus)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	return q
+}
+
+// SearchCatalog calls GET /workloads/catalog: Search the workload catalog.
+func (c *Client) SearchCatalog(ctx context.Context, params SearchCatalogParams) (*CatalogSearchResponse, error) {
+	var out CatalogSearchResponse
+	if err := c.do(ctx, "GET", "/workloads/catalog", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SelfSLO calls GET /slo/self: SLOs of Lighthouse's own API routes.
+func (c *Client) SelfSLO(ctx context.Context) (*SelfSLOResponse, error) {
+	var out SelfSLOResponse
+	if err := c.do(ctx, "GET", "/slo/self", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ServiceAccountUsageParams holds the query parameters of GET /admin/service-accounts/{id}/usage; zero values are not sent.
+type ServiceAccountUsageParams struct {
+	// days ending today, default 7, at most 90
+	Days int
+}
+
+func (p ServiceAccountUsageParams) values() url.Values {
+	q := url.Values{}
+	if p.Days != 0 {
+		q.Set("days", strconv.Itoa(p.Days))
+	}
+	return q
+}
+
+// ServiceAccountUsage calls GET /admin/service-accounts/{id}/usage: Daily request and quota
+// rejection counts of a service account's API keys.
+func (c *Client) ServiceAccountUsage(ctx context.Context, id string, params ServiceAccountUsageParams) (*APIKeyUsageResponse, error) {
+	var out APIKeyUsageResponse
+	if err := c.do(ctx, "GET", "/admin/service-accounts/"+url.PathEscape(id)+"/usage", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetEfficiencyTarget calls PUT /cost/targets/{namespace}: Set the efficiency target of a
+// namespace.
+func (c *Client) SetEfficiencyTarget(ctx context.Context, namespace string, body SetEfficiencyTargetRequest) (*EfficiencyTarget, error) {
+	var out EfficiencyTarget
+	if err := c.do(ctx, "PUT", "/cost/targets/"+url.PathEscape(namespace), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetGradeView calls PUT /admin/regrade/view: Switch the view of snapshot grade counts.
+func (c *Client) SetGradeView(ctx context.Context, body SetGradeViewRequest) (*GradeView, error) {
+	var out GradeView
+	if err := c.do(ctx, "PUT", "/admin/regrade/view", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetPrice calls PUT /pricing/history: Add or correct a unit price version.
+func (c *Client) SetPrice(ctx context.Context, body SetPriceRequest) (*PriceChangeResponse, error) {
+	var out PriceChangeResponse
+	if err := c.do(ctx, "PUT", "/pricing/history", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SLOCostParams holds the query parameters of GET /slo/cost; zero values are not sent.
+type SLOCostParams struct {
+	// window start (RFC3339), default end_time - 7d
+	StartTime string
+	// window end (RFC3339), default now
+	EndTime string
+}
+
+func (p SLOCostParams) values() url.Values {
+	q := url.Values{}
+	if p.StartTime != "" {
+		q.Set("start_time", p.StartTime)
+	}
+	if p.EndTime != "" {
+		q.Set("end_time", p.EndTime)
+	}
+	return q
+}
+
+// SLOCost calls GET /slo/cost: Cost of SLO compliance per service.
+func (c *Client) SLOCost(ctx context.Context, params SLOCostParams) (*SLOCostResponse, error) {
+	var out SLOCostResponse
+	if err := c.do(ctx, "GET", "/slo/cost", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SLOHealthParams holds the query parameters of GET /slo/health; zero values are not sent.
+type SLOHealthParams struct {
+	// page size, max 500 (default all)
+	Limit int
+	// page offset
+	Offset int
+	// next_cursor of the previous page (instead of offset)
+	Cursor string
+}
+
+func (p SLOHealthParams) values() url.Values {
+	q := url.Values{}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// SLOHealth calls GET /slo/health: SLO health of services.
+func (c *Client) SLOHealth(ctx context.Context, params SLOHealthParams) (*SLOHealthList, error) {
+	var out SLOHealthList
+	if err := c.do(ctx, "GET", "/slo/health", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SnapshotGradesParams holds the query parameters of GET /snapshots/grades; zero values are not sent.
+type SnapshotGradesParams struct {
+	// window start (RFC3339)
+	StartTime time.Time
+	// window end (RFC3339)
+	EndTime time.Time
+	// original / regraded, default the view currently served
+	View string
+}
+
+func (p SnapshotGradesParams) values() url.Values {
+	q := url.Values{}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	if p.View != "" {
+		q.Set("view", p.View)
+	}
+	return q
+}
+
+// SnapshotGrades calls GET /snapshots/grades: Grade counts of cost snapshots.
+func (c *Client) SnapshotGrades(ctx context.Context, params SnapshotGradesParams) (*SnapshotGradesResponse, error) {
+	var out SnapshotGradesResponse
+	if err := c.do(ctx, "GET", "/snapshots/grades", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartChaosDrill calls POST /admin/chaos: Start a resilience drill.
+func (c *Client) StartChaosDrill(ctx context.Context, body StartChaosDrillRequest) (*ChaosResponse, error) {
+	var out ChaosResponse
+	if err := c.do(ctx, "POST", "/admin/chaos", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StopChaosDrill calls DELETE /admin/chaos: Stop the active resilience drill.
+func (c *Client) StopChaosDrill(ctx context.Context) (*ChaosResponse, error) {
+	var out ChaosResponse
+	if err := c.do(ctx, "DELETE", "/admin/chaos", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TimelineParams holds the query parameters of GET /timeline; zero values are not sent.
+type TimelineParams struct {
+	// namespace (default: whole cluster)
+	Namespace string
+	// window start (RFC3339)
+	StartTime time.Time
+	// window end (RFC3339)
+	EndTime time.Time
+	// comma-separated k8s_event, config_change, calculation_run, price_change, slo_violation
+	Kinds string
+	// latest events returned (default 1000)
+	Limit int
+}
+
+func (p TimelineParams) values() url.Values {
+	q := url.Values{}
+	if p.Namespace != "" {
+		q.Set("namespace", p.Namespace)
+	}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	if p.Kinds != "" {
+		q.Set("kinds", p.Kinds)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// Timeline calls GET /timeline: Cluster events timeline.
+func (c *Client) Timeline(ctx context.Context, params TimelineParams) (*TimelineResponse, error) {
+	var out TimelineResponse
+	if err := c.do(ctx, "GET", "/timeline", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TrackEfficiencyTargetParams holds the query parameters of GET /cost/targets/{namespace}/tracking; zero values are not sent.
+type TrackEfficiencyTargetParams struct {
+	// window start (RFC3339)
+	StartTime time.Time
+	// window end (RFC3339)
+	EndTime time.Time
+}
+
+func (p TrackEfficiencyTargetParams) values() url.Values {
+	q := url.Values{}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	return q
+}
+
+// TrackEfficiencyTarget calls GET /cost/targets/{namespace}/tracking: Track a namespace's
+// efficiency against its target.
+func (c *Client) TrackEfficiencyTarget(ctx context.Context, namespace string, params TrackEfficiencyTargetParams) (*EfficiencyTargetTrackingResponse, error) {
+	var out EfficiencyTargetTrackingResponse
+	if err := c.do(ctx, "GET", "/cost/targets/"+url.PathEscape(namespace)+"/tracking", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TriggerCalculationRun calls POST /calculations/runs: Run the cost pipeline for a window.
+func (c *Client) TriggerCalculationRun(ctx context.Context, body TriggerCalculationRequest) (*CalculationRun, error) {
+	var out CalculationRun
+	if err := c.do(ctx, "POST", "/calculations/runs", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateAlertRule calls PUT /alerts/rules/{id}: Replace the definition of an alert rule.
+func (c *Client) UpdateAlertRule(ctx context.Context, id string, body AlertRuleRequest) (*AlertRule, error) {
+	var out AlertRule
+	if err := c.do(ctx, "PUT", "/alerts/rules/"+url.PathEscape(id), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateNotificationRoute calls PUT /alerts/routes/{id}: Replace a notification routing rule.
+func (c *Client) UpdateNotificationRoute(ctx context.Context, id string, body NotificationRouteRequest) (*NotificationRoute, error) {
+	var out NotificationRoute
+	if err := c.do(ctx, "PUT", "/alerts/routes/"+url.PathEscape(id), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdatePreferences calls PUT /preferences: Update the preferences of the calling user.
+func (c *Client) UpdatePreferences(ctx context.Context, body UpdatePreferencesRequest) (*UserPreferences, error) {
+	var out UserPreferences
+	if err := c.do(ctx, "PUT", "/preferences", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateServiceAccount calls PUT /admin/service-accounts/{id}: Update or disable a service account.
+func (c *Client) UpdateServiceAccount(ctx context.Context, id string, body UpdateServiceAccountRequest) (*ServiceAccount, error) {
+	var out ServiceAccount
+	if err := c.do(ctx, "PUT", "/admin/service-accounts/"+url.PathEscape(id), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// VerifySnapshotsParams holds the query parameters of GET /snapshots/verify; zero values are not sent.
+type VerifySnapshotsParams struct {
+	// only this snapshot
+	SnapshotID string
+}
+
+func (p VerifySnapshotsParams) values() url.Values {
+	q := url.Values{}
+	if p.SnapshotID != "" {
+		q.Set("snapshot_id", p.SnapshotID)
+	}
+	return q
+}
+
+// VerifySnapshots calls GET /snapshots/verify: Verify cost snapshots against the hash chain.
+func (c *Client) VerifySnapshots(ctx context.Context, params VerifySnapshotsParams) (*SnapshotVerificationResponse, error) {
+	var out SnapshotVerificationResponse
+	if err := c.do(ctx, "GET", "/snapshots/verify", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WorkloadCostParams holds the query parameters of GET /workloads/{namespace}/{name}/costs; zero values are not sent.
+type WorkloadCostParams struct {
+	// window start (RFC3339)
+	StartTime time.Time
+	// window end (RFC3339)
+	EndTime time.Time
+}
+
+func (p WorkloadCostParams) values() url.Values {
+	q := url.Values{}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	return q
+}
+
+// WorkloadCost calls GET /workloads/{namespace}/{name}/costs: Cost detail of a workload.
+func (c *Client) WorkloadCost(ctx context.Context, namespace string, name string, params WorkloadCostParams) (*WorkloadCostDetailResponse, error) {
+	var out WorkloadCostDetailResponse
+	if err := c.do(ctx, "GET", "/workloads/"+url.PathEscape(namespace)+"/"+url.PathEscape(name)+"/costs", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WorkloadHistoryParams holds the query parameters of GET /workloads/{namespace}/{name}/history; zero values are not sent.
+type WorkloadHistoryParams struct {
+	// days of history including today, default 90, at most 366
+	Days int
+}
+
+func (p WorkloadHistoryParams) values() url.Values {
+	q := url.Values{}
+	if p.Days != 0 {
+		q.Set("days", strconv.Itoa(p.Days))
+	}
+	return q
+}
+
+// WorkloadHistory calls GET /workloads/{namespace}/{name}/history: Daily cost, efficiency and grade
+// series of a workload.
+func (c *Client) WorkloadHistory(ctx context.Context, namespace string, name string, params WorkloadHistoryParams) (*WorkloadHistoryResponse, error) {
+	var out WorkloadHistoryResponse
+	if err := c.do(ctx, "GET", "/workloads/"+url.PathEscape(namespace)+"/"+url.PathEscape(name)+"/history", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WorkloadImpactParams holds the query parameters of GET /workloads/{namespace}/{name}/impact; zero values are not sent.
+type WorkloadImpactParams struct {
+	// window start (RFC3339)
+	StartTime time.Time
+	// window end (RFC3339)
+	EndTime time.Time
+}
+
+func (p WorkloadImpactParams) values() url.Values {
+	q := url.Values{}
+	if !p.StartTime.IsZero() {
+		q.Set("start_time", p.StartTime.Format(time.RFC3339))
+	}
+	if !p.EndTime.IsZero() {
+		q.Set("end_time", p.EndTime.Format(time.RFC3339))
+	}
+	return q
+}
+
+// WorkloadImpact calls GET /workloads/{namespace}/{name}/impact: Upstream services and spend
+// depending on a workload.
+func (c *Client) WorkloadImpact(ctx context.Context, namespace string, name string, params WorkloadImpactParams) (*DependencyImpact, error) {
+	var out DependencyImpact
+	if err := c.do(ctx, "GET", "/workloads/"+url.PathEscape(namespace)+"/"+url.PathEscape(name)+"/impact", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AggregationResult represents the result of aggregating costs at a specific level.
+type AggregationResult struct {
+	Level         int        `json:"level"`
+	Identifier    string     `json:"identifier"`
+	TotalCost     CostResult `json:"total_cost"`
+	ResourceCount int        `json:"resource_count"`
+	Timestamp     time.Time  `json:"timestamp"`
+}
+
+// CostPolicy is the declarative cost exception of a workload (usually parsed from annotations). The
+// zero value is the default behavior.
+type CostPolicy struct {
+	// ExcludeFromWaste treats the whole request as used (e.g. DR standby): waste is zero, the workload
+	// grades Healthy and no decommission/downsize is recommended.
+	ExcludeFromWaste bool `json:"exclude_from_waste,omitempty"`
+	// CostCenter overrides the namespace for chargeback.
+	CostCenter      string           `json:"cost_center,omitempty"`
+	GradeThresholds *GradeThresholds `json:"grade_thresholds,omitempty"`
+}
+
+// CostResult represents the calculated cost results for a single resource.
+type CostResult struct {
+	// CPU costs
+	CPUBillableCost    float64 `json:"cpu_billable_cost"`
+	CPUUsageCost       float64 `json:"cpu_usage_cost"`
+	CPUWasteCost       float64 `json:"cpu_waste_cost"`
+	CPUEfficiencyScore float64 `json:"cpu_efficiency_score"`
+	// Memory costs
+	MemBillableCost    float64 `json:"mem_billable_cost"`
+	MemUsageCost       float64 `json:"mem_usage_cost"`
+	MemWasteCost       float64 `json:"mem_waste_cost"`
+	MemEfficiencyScore float64 `json:"mem_efficiency_score"`
+	// Total costs
+	TotalBillableCost      float64 `json:"total_billable_cost"`
+	TotalUsageCost         float64 `json:"total_usage_cost"`
+	TotalWasteCost         float64 `json:"total_waste_cost"`
+	OverallEfficiencyScore float64 `json:"overall_efficiency_score"`
+	// Efficiency grade
+	OverallGrade string `json:"overall_grade"`
+}
+
+// GradeThresholds are the efficiency score boundaries (percent) between grades: score < Zombie is
+// Zombie, < OverProvisioned is OverProvisioned, > Risk is Risk, otherwise Healthy.
+type GradeThresholds struct {
+	Zombie          float64 `json:"zombie"`
+	OverProvisioned float64 `json:"over_provisioned"`
+	Risk            float64 `json:"risk"`
+}
+
+// OffHoursProfile splits a workload's hours into business hours and off-hours. Utilizations are
+// mean dominant utilization in %; idle costs are billable cost of idle hours.
+type OffHoursProfile struct {
+	BusinessHours       int     `json:"business_hours"`
+	OffHours            int     `json:"off_hours"`
+	BusinessCost        float64 `json:"business_cost"`
+	OffHoursCost        float64 `json:"off_hours_cost"`
+	IdleBusinessHours   int     `json:"idle_business_hours"`
+	IdleBusinessCost    float64 `json:"idle_business_cost"`
+	IdleOffHours        int     `json:"idle_off_hours"`
+	IdleOffHoursCost    float64 `json:"idle_off_hours_cost"`
+	BusinessUtilization float64 `json:"business_utilization"`
+	OffHoursUtilization float64 `json:"off_hours_utilization"`
+	// off-hours cost / total cost, %
+	OffHoursCostShare float64 `json:"off_hours_cost_share"`
+	// idle off-hours cost / total cost, %
+	IdleOffHoursCostShare float64 `json:"idle_off_hours_cost_share"`
+}
+
+// Recommendation is one optimization proposal. Resource is "cpu", "memory" or "workload";
+// EstimatedMonthlySavings is negative for upsizing (extra cost). Safety is set on reductions that
+// went through CheckSafety.
+type Recommendation struct {
+	Action                  string                `json:"action"`
+	Resource                string                `json:"resource"`
+	CurrentRequest          float64               `json:"current_request"`
+	RecommendedRequest      float64               `json:"recommended_request"`
+	EstimatedMonthlySavings float64               `json:"estimated_monthly_savings"`
+	Reason                  string                `json:"reason"`
+	Safety                  *RecommendationSafety `json:"safety,omitempty"`
+}
+
+// RecommendationSafety records the safety checks of a reduction: the outcome, why, and the signals
+// it was decided on.
+type RecommendationSafety struct {
+	Status               string   `json:"status"`
+	Reasons              []string `json:"reasons,omitempty"`
+	CPUThrottlingRate    *float64 `json:"cpu_throttling_rate,omitempty"`
+	SLOStatus            string   `json:"slo_status,omitempty"`
+	ErrorBudgetRemaining *float64 `json:"error_budget_remaining,omitempty"`
+}
+
+// SLOCapacityLevel is the capacity serving usage at one availability level and its monthly cost.
+type SLOCapacityLevel struct {
+	// %; 50 is the typical demand (P50)
+	Availability float64 `json:"availability"`
+	// cores
+	CPU float64 `json:"cpu"`
+	// bytes
+	Mem         float64 `json:"mem"`
+	MonthlyCost float64 `json:"monthly_cost"`
+	// MarginalMonthlyCost is the cost over the previous level (the cost of this nine).
+	MarginalMonthlyCost float64 `json:"marginal_monthly_cost"`
+}
+
+// SLOCost is what the availability target of a service costs.
+type SLOCost struct {
+	AvailabilityTarget float64          `json:"availability_target"`
+	Nines              float64          `json:"nines"`
+	Baseline           SLOCapacityLevel `json:"baseline"`
+	// Levels are the whole nines (90%, 99%, ...) up to one past the target.
+	Levels []SLOCapacityLevel `json:"levels"`
+	Target SLOCapacityLevel   `json:"target"`
+	// RequestedMonthlyCost prices the current requests.
+	RequestedMonthlyCost float64 `json:"requested_monthly_cost"`
+	// SLOHeadroomMonthlyCost is the capacity kept above typical demand for the target.
+	SLOHeadroomMonthlyCost float64 `json:"slo_headroom_monthly_cost"`
+	// ExcessMonthlyCost is requested capacity beyond what the target needs (not explained by the SLO).
+	ExcessMonthlyCost float64 `json:"excess_monthly_cost"`
+	// NextNineMonthlyCost is the extra capacity for one more nine; RelaxNineMonthlySavings what
+	// dropping one nine releases (down to typical demand).
+	NextNineMonthlyCost     float64 `json:"next_nine_monthly_cost"`
+	RelaxNineMonthlySavings float64 `json:"relax_nine_monthly_savings"`
+}
+
+// SharedResource is a cluster-shared resource whose cost is allocated to namespaces.
+type SharedResource struct {
+	Name string `json:"name"`
+	// e.g. "load_balancer", "storage"; informational
+	Kind    string `json:"kind"`
+	Billing string `json:"billing"`
+	// fixed billing
+	MonthlyCost float64 `json:"monthly_cost,omitempty"`
+	// metered billing, per unit
+	UnitPrice     float64 `json:"unit_price,omitempty"`
+	AllocationKey string  `json:"allocation_key"`
+	// Namespaces restricts the allocation to these namespaces; empty means all namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// TargetAttainment summarizes daily efficiency against a target. A day meets the target when its
+// efficiency is at or above it; a day without data breaks a streak.
+type TargetAttainment struct {
+	Target float64 `json:"target"`
+	// days with data
+	Days int `json:"days"`
+	// days meeting the target
+	DaysAtTarget      int     `json:"days_at_target"`
+	AttainmentRate    float64 `json:"attainment_rate"`
+	AverageEfficiency float64 `json:"average_efficiency"`
+	// consecutive days meeting the target, ending on the last day
+	CurrentStreak int `json:"current_streak"`
+	LongestStreak int `json:"longest_streak"`
+}
+
+// APIKey is an API key of a service account; the secret is never returned after creation.
+type APIKey struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// first characters of the secret, to recognize the key
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	// requests per UTC day, 0 = unlimited
+	DailyQuota    int        `json:"daily_quota"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RequestsToday int64      `json:"requests_today"`
+}
+
+// APIKeyUsageDay is the usage of one key on one UTC day.
+type APIKeyUsageDay struct {
+	KeyID    string    `json:"key_id"`
+	KeyName  string    `json:"key_name,omitempty"`
+	Date     time.Time `json:"date"`
+	Requests int64     `json:"requests"`
+	// over the daily quota
+	Rejected int64 `json:"rejected"`
+}
+
+// APIKeyUsageResponse is the response of GET /api/v1/admin/service-accounts/:id/usage.
+type APIKeyUsageResponse struct {
+	AccountID     string    `json:"account_id"`
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	TotalRequests int64     `json:"total_requests"`
+	TotalRejected int64     `json:"total_rejected"`
+	// days without requests are omitted
+	Usage []APIKeyUsageDay `json:"usage"`
+}
+
+// AccountingPeriod is a closed fiscal period: its daily costs, allocations and the reports built
+// from them no longer change; recalculations are recorded as adjustments instead.
+type AccountingPeriod struct {
+	Label     string    `json:"label"`
+	StartDate time.Time `json:"start_date"`
+	// exclusive
+	EndDate  time.Time `json:"end_date"`
+	ClosedBy string    `json:"closed_by,omitempty"`
+	ClosedAt time.Time `json:"closed_at"`
+	Note     string    `json:"note,omitempty"`
+	// adjustments recorded since the close
+	AdjustmentCount int `json:"adjustment_count"`
+}
+
+// AccountingPeriodList is the response of GET /api/v1/admin/periods: the closed periods, latest
+// first.
+type AccountingPeriodList struct {
+	Items []AccountingPeriod `json:"items"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists, an
+	// estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// AlertClauseResult is the value of one clause of a condition.
+type AlertClauseResult struct {
+	Clause string  `json:"clause"`
+	Value  float64 `json:"value"`
+	// false: no data in the window, the clause does not hold
+	HasData bool `json:"has_data"`
+	Matched bool `json:"matched"`
+}
+
+// AlertMetric describes a metric of the condition DSL and the labels it can be filtered by.
+type AlertMetric struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+}
+
+// AlertRule is a user-defined alert: a condition over cost, efficiency, budget and SLO metrics,
+// evaluated every interval over the trailing window and routed to notification channels.
+type AlertRule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Condition in canonical form, e.g. `cost{namespace="payments"} > 500 and waste_ratio >= 30`.
+	Condition string `json:"condition"`
+	// Go duration, e.g. "24h"
+	Window string `json:"window"`
+	// Go duration, e.g. "1h"
+	Interval  string    `json:"interval"`
+	Severity  string    `json:"severity"`
+	AlertType string    `json:"alert_type"`
+	Channels  []string  `json:"channels"`
+	Enabled   bool      `json:"enabled"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// State of the latest evaluation: ok, firing or error; empty before the first one.
+	State           string     `json:"state,omitempty"`
+	LastEvaluatedAt *time.Time `json:"last_evaluated_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	FiringSince     *time.Time `json:"firing_since,omitempty"`
+}
+
+// AlertRuleEvaluation is the result of evaluating an alert rule.
+type AlertRuleEvaluation struct {
+	RuleID      string    `json:"rule_id"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+	WindowStart time.Time `json:"window_start"`
+	Firing      bool      `json:"firing"`
+	State       string    `json:"state"`
+	// the metrics could not be evaluated
+	Error string `json:"error,omitempty"`
+	// an alert was sent (the rule started firing)
+	Notified bool `json:"notified"`
+	// the alert matched an active silence and was not sent
+	Silenced    bool                `json:"silenced"`
+	NotifyError string              `json:"notify_error,omitempty"`
+	Clauses     []AlertClauseResult `json:"clauses"`
+}
+
+// AlertRuleListResponse is the response of GET /api/v1/alerts/rules.
+type AlertRuleListResponse struct {
+	Rules []AlertRule `json:"rules"`
+	Total int         `json:"total"`
+	// Metrics are the metrics conditions may use.
+	Metrics []AlertMetric `json:"metrics"`
+}
+
+// AlertRuleRequest is the body of POST /api/v1/alerts/rules and PUT /api/v1/alerts/rules/:id.
+// Clauses `metric{label="value", ...} op threshold` (op: > >= < <= == !=) are joined by and / or,
+// and binding tighter than or.
+type AlertRuleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Condition   string `json:"condition"`
+	// default "24h"
+	Window string `json:"window"`
+	// default "1h", at least "1m"
+	Interval string `json:"interval"`
+	// info / warning / critical, default warning
+	Severity string `json:"severity"`
+	// routing type, default alert_rule
+	AlertType string `json:"alert_type"`
+	// Channels restrict delivery to these channels (slack, dingtalk, wecom, pagerduty, opsgenie) of
+	// the alert type's route; empty sends to all of them.
+	Channels []string `json:"channels"`
+	// default true
+	Enabled *bool `json:"enabled"`
+	// default: the service account of the caller's key
+	CreatedBy string `json:"created_by"`
+}
+
+// AllocationPreviewNamespace is the shared cost of a namespace under both rule sets.
+type AllocationPreviewNamespace struct {
+	Namespace           string  `json:"namespace"`
+	BillableCost        float64 `json:"billable_cost"`
+	CurrentSharedCost   float64 `json:"current_shared_cost"`
+	CandidateSharedCost float64 `json:"candidate_shared_cost"`
+	Delta               float64 `json:"delta"`
+	// relative to current; absent when current is zero
+	DeltaPercent *float64 `json:"delta_percent,omitempty"`
+}
+
+// AllocationPreviewRequest is the body of POST /api/v1/cost/allocation/preview: a candidate set of
+// shared cost rules replacing the current ones, applied to start_date..end_date (UTC days,
+// inclusive; default the 30 days before today).
+type AllocationPreviewRequest struct {
+	Rules     []SharedResource `json:"rules"`
+	StartDate time.Time        `json:"start_date,omitempty"`
+	EndDate   time.Time        `json:"end_date,omitempty"`
+}
+
+// AllocationPreviewResource is the allocated cost of one shared resource under both rule sets; a
+// resource missing from one set has zero cost there.
+type AllocationPreviewResource struct {
+	Name          string  `json:"name"`
+	CurrentCost   float64 `json:"current_cost"`
+	CandidateCost float64 `json:"candidate_cost"`
+}
+
+// AllocationPreviewResponse compares the shared cost allocated to each namespace by the current and
+// the candidate rules. Namespaces are sorted by absolute delta, descending.
+type AllocationPreviewResponse struct {
+	StartDate           time.Time                    `json:"start_date"`
+	EndDate             time.Time                    `json:"end_date"`
+	Days                int                          `json:"days"`
+	CurrentSharedCost   float64                      `json:"current_shared_cost"`
+	CandidateSharedCost float64                      `json:"candidate_shared_cost"`
+	Resources           []AllocationPreviewResource  `json:"resources"`
+	Namespaces          []AllocationPreviewNamespace `json:"namespaces"`
+	Total               int                          `json:"total"`
+}
+
+// BusinessHoursState describes the weekday hours [start_hour, end_hour) counted as business hours.
+type BusinessHoursState struct {
+	Timezone  string `json:"timezone"`
+	StartHour int    `json:"start_hour"`
+	EndHour   int    `json:"end_hour"`
+}
+
+// CalculationRun is one execution of the cost calculation pipeline.
+type CalculationRun struct {
+	ID string `json:"id"`
+	// schedule / manual / retry
+	Trigger     string    `json:"trigger"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	// running / succeeded / partial / failed
+	Status      string    `json:"status"`
+	RowsWritten int       `json:"rows_written"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	Error       string    `json:"error,omitempty"`
+	RetryOf     string    `json:"retry_of,omitempty"`
+	// Scopes 本次执行范围（namespace），为空表示全量；FailedScopes
+	// 失败且可定向重跑的范围。
+	Scopes       []string `json:"scopes,omitempty"`
+	FailedScopes []string `json:"failed_scopes,omitempty"`
+	// ZeroedValues / DroppedRows 计算中 NaN/Inf 被置 0 的值与被丢弃的指标行数（见
+	// non_finite_policy）。
+	ZeroedValues int `json:"zeroed_values,omitempty"`
+	DroppedRows  int `json:"dropped_rows,omitempty"`
+}
+
+// CalculationRunListResponse is the response of GET /api/v1/calculations/runs.
+type CalculationRunListResponse struct {
+	Runs  []CalculationRun `json:"runs"`
+	Total int              `json:"total"`
+}
+
+// CapacityPool is a group of nodes sharing the node pool label.
+type CapacityPool struct {
+	Name           string  `json:"name"`
+	NodeCount      int     `json:"node_count"`
+	AllocatableCPU float64 `json:"allocatable_cpu"`
+	AllocatableMem int64   `json:"allocatable_mem"`
+	MonthlyCost    float64 `json:"monthly_cost"`
+}
+
+// CapacityProjectionPoint is the projected cluster requests of one day (baseline capacity).
+type CapacityProjectionPoint struct {
+	Date           time.Time `json:"date"`
+	CPURequest     float64   `json:"cpu_request"`
+	MemRequest     int64     `json:"mem_request"`
+	CPUUtilization float64   `json:"cpu_utilization"`
+	MemUtilization float64   `json:"mem_utilization"`
+}
+
+// CapacityProjectionResponse is the response of GET /api/v1/capacity/projection. Requests are
+// projected per namespace from their daily trend; utilization is requested/allocatable in %.
+type CapacityProjectionResponse struct {
+	GeneratedAt       time.Time                 `json:"generated_at"`
+	HistoryDays       int                       `json:"history_days"`
+	HorizonDays       int                       `json:"horizon_days"`
+	TargetUtilization float64                   `json:"target_utilization"`
+	Pools             []CapacityPool            `json:"pools"`
+	Namespaces        []NamespaceGrowth         `json:"namespaces"`
+	Baseline          CapacityScenario          `json:"baseline"`
+	Scenario          *CapacityScenario         `json:"scenario,omitempty"`
+	Series            []CapacityProjectionPoint `json:"series"`
+}
+
+// CapacityScenario is the cluster capacity, cost and exhaustion dates for a node count. Exhaustion
+// dates are nil when requests stay below allocatable*target within the horizon.
+type CapacityScenario struct {
+	Pool              string     `json:"pool,omitempty"`
+	NodeDelta         int        `json:"node_delta"`
+	NodeCount         int        `json:"node_count"`
+	AllocatableCPU    float64    `json:"allocatable_cpu"`
+	AllocatableMem    int64      `json:"allocatable_mem"`
+	MonthlyCost       float64    `json:"monthly_cost"`
+	MonthlyCostDelta  float64    `json:"monthly_cost_delta"`
+	CPUUtilization    float64    `json:"cpu_utilization"`
+	MemUtilization    float64    `json:"mem_utilization"`
+	CPUExhaustionDate *time.Time `json:"cpu_exhaustion_date"`
+	MemExhaustionDate *time.Time `json:"mem_exhaustion_date"`
+}
+
+// CapacitySimulationPool is the autoscaler simulation of one node pool.
+type CapacitySimulationPool struct {
+	Name               string  `json:"name"`
+	Pods               int     `json:"pods"`
+	OversizedPods      int     `json:"oversized_pods"`
+	NodeCPU            float64 `json:"node_cpu"`
+	NodeMem            int64   `json:"node_mem"`
+	NodeMonthlyCost    float64 `json:"node_monthly_cost"`
+	NodeCount          int     `json:"node_count"`
+	BaselineNodeCount  int     `json:"baseline_node_count"`
+	SimulatedNodeCount int     `json:"simulated_node_count"`
+	NodeReduction      int     `json:"node_reduction"`
+	MonthlySavings     float64 `json:"monthly_savings"`
+}
+
+// CapacitySimulationResponse is the response of GET /api/v1/capacity/simulation. Running and
+// pending pods are bin-packed onto each pool's average node (allocatable*target per node) with
+// current and changed requests; savings are the billable cost of the nodes removed. Pools without
+// pods keep their nodes.
+type CapacitySimulationResponse struct {
+	GeneratedAt          time.Time `json:"generated_at"`
+	RequestChangePercent float64   `json:"request_change_percent"`
+	TargetUtilization    float64   `json:"target_utilization"`
+	NodeCount            int       `json:"node_count"`
+	// BaselineNodeCount 为按当前请求装箱所需节点数，与 NodeCount
+	// 的差异是已可整合的部分； NodeReduction = BaselineNodeCount -
+	// SimulatedNodeCount，只计入请求变化带来的节点减少
+	BaselineNodeCount   int     `json:"baseline_node_count"`
+	SimulatedNodeCount  int     `json:"simulated_node_count"`
+	NodeReduction       int     `json:"node_reduction"`
+	CPURequest          float64 `json:"cpu_request"`
+	SimulatedCPURequest float64 `json:"simulated_cpu_request"`
+	MemRequest          int64   `json:"mem_request"`
+	SimulatedMemRequest int64   `json:"simulated_mem_request"`
+	// RequestCostDelta
+	// 为请求变化按单价折算的月成本（负数为减少），仅供与节点节省对比
+	RequestCostDelta     float64                  `json:"request_cost_delta"`
+	MonthlyCost          float64                  `json:"monthly_cost"`
+	SimulatedMonthlyCost float64                  `json:"simulated_monthly_cost"`
+	MonthlySavings       float64                  `json:"monthly_savings"`
+	Pools                []CapacitySimulationPool `json:"pools"`
+}
+
+// CatalogFacetCount is the number of matching workloads with one facet value.
+type CatalogFacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// CatalogSearchResponse is the response of GET /api/v1/workloads/catalog. Workloads are sorted by
+// monthly cost, descending; Total counts all matches before paging and Facets are computed over
+// them (facets: namespace, team, grade, type, slo_status).
+type CatalogSearchResponse struct {
+	Workloads   []CatalogWorkload              `json:"workloads"`
+	Facets      map[string][]CatalogFacetCount `json:"facets"`
+	Total       int                            `json:"total"`
+	RefreshedAt time.Time                      `json:"refreshed_at"`
+}
+
+// CatalogWorkload is the latest known state of one workload.
+type CatalogWorkload struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	// owner team; absent when no team claims the namespace
+	Team            string  `json:"team,omitempty"`
+	Grade           string  `json:"grade"`
+	EfficiencyScore float64 `json:"efficiency_score"`
+	// billable cost of the observed hours extrapolated to a month
+	MonthlyCost        float64         `json:"monthly_cost"`
+	LastRecommendation *Recommendation `json:"last_recommendation,omitempty"`
+	// absent when the workload has no SLO
+	SLOStatus string    `json:"slo_status,omitempty"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// ChaosDrill is a started drill and what it injected so far, by data source.
+type ChaosDrill struct {
+	Scenario  string                      `json:"scenario"`
+	StartedBy string                      `json:"started_by,omitempty"`
+	StartedAt time.Time                   `json:"started_at"`
+	EndsAt    time.Time                   `json:"ends_at"`
+	StoppedAt *time.Time                  `json:"stopped_at,omitempty"`
+	Injected  map[string]ChaosInjectCount `json:"injected"`
+}
+
+// ChaosFault is the failure injected into the calls of one data source, with the knobs of the mock
+// configs.
+type ChaosFault struct {
+	ErrorRate       float64 `json:"error_rate"`
+	LatencyMs       int     `json:"latency_ms"`
+	LatencyJitterMs int     `json:"latency_jitter_ms"`
+	TailLatencyRate float64 `json:"tail_latency_rate"`
+	TailLatencyMs   int     `json:"tail_latency_ms"`
+}
+
+// ChaosInjectCount is the calls of a data source a drill delayed or failed, and the failures among
+// them.
+type ChaosInjectCount struct {
+	Calls  int64 `json:"calls"`
+	Errors int64 `json:"errors"`
+}
+
+// ChaosResponse is the response of the /api/v1/admin/chaos endpoints: the configured scenarios and
+// the current (or last) resilience drill.
+type ChaosResponse struct {
+	Active             bool            `json:"active"`
+	MaxDurationSeconds int64           `json:"max_duration_seconds"`
+	Scenarios          []ChaosScenario `json:"scenarios"`
+	Drill              *ChaosDrill     `json:"drill,omitempty"`
+}
+
+// ChaosScenario is a configured scenario: the fault injected into each data source (prometheus /
+// kubernetes / database).
+type ChaosScenario struct {
+	Name   string                `json:"name"`
+	Faults map[string]ChaosFault `json:"faults"`
+}
+
+// CloseAccountingPeriodRequest is the body of POST /api/v1/admin/periods/close.
+type CloseAccountingPeriodRequest struct {
+	Note string `json:"note"`
+}
+
+// CodeVersion is the build of the server that aggregated the figures.
+type CodeVersion struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time,omitempty"`
+}
+
+// ConfigChange is a recorded config change.
+type ConfigChange struct {
+	ID         string    `json:"id"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"`
+	ChangeType string    `json:"change_type"`
+	OldValue   string    `json:"old_value,omitempty"`
+	NewValue   string    `json:"new_value,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// ConfigChangeRequest is the body of POST /api/v1/timeline/config-changes, sent by deploy pipelines
+// once a change is applied.
+type ConfigChangeRequest struct {
+	// optional; re-sending an ID replaces the record
+	ID         string `json:"id"`
+	Namespace  string `json:"namespace"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	ChangeType string `json:"change_type"`
+	OldValue   string `json:"old_value"`
+	NewValue   string `json:"new_value"`
+	Source     string `json:"source"`
+	Author     string `json:"author"`
+	// default now
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// ConfirmSnapshotRequest is the body of POST /api/v1/snapshots/{id}/confirm.
+type ConfirmSnapshotRequest struct {
+	ConfirmedBy string `json:"confirmed_by"`
+}
+
+// CostAdjustment is a correction of a closed period: the difference between a recalculated value
+// and the value stored when the period was closed, or a manual correction with its reason and
+// approver. Pending manual corrections are listed but not included in reports.
+type CostAdjustment struct {
+	ID     string `json:"id"`
+	Period string `json:"period"`
+	// daily_namespace_cost / hourly_workload_stat
+	Record    string    `json:"record"`
+	Date      time.Time `json:"date"`
+	Namespace string    `json:"namespace"`
+	Workload  string    `json:"workload,omitempty"`
+	Amount    float64   `json:"amount"`
+	// recalculation / manual
+	Source    string    `json:"source"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Approver  string    `json:"approver,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// pending / approved
+	Status     string     `json:"status"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+}
+
+// CostAdjustmentList is the response of GET /api/v1/admin/adjustments.
+type CostAdjustmentList struct {
+	Items []CostAdjustment `json:"items"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists, an
+	// estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// CostBreakdown provides detailed cost breakdown.
+type CostBreakdown struct {
+	Total      float64 `json:"total"`
+	CPU        float64 `json:"cpu"`
+	Memory     float64 `json:"memory"`
+	Storage    float64 `json:"storage"`
+	Network    float64 `json:"network"`
+	Billable   float64 `json:"billable"`
+	Usage      float64 `json:"usage"`
+	Waste      float64 `json:"waste"`
+	Efficiency float64 `json:"efficiency"`
+}
+
+// CostImportResponse is the response of POST /api/v1/admin/import/allocations: what an import of
+// Kubecost / OpenCost allocation data wrote (or, with dry_run, would write).
+type CostImportResponse struct {
+	// kubecost / opencost, stored as the source of each row
+	Source string `json:"source"`
+	// json / csv
+	Format    string    `json:"format"`
+	DryRun    bool      `json:"dry_run"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// Allocations parsed; Unallocated of them are idle / unallocated aggregates, which are skipped.
+	Allocations int      `json:"allocations"`
+	Unallocated int      `json:"unallocated"`
+	Namespaces  []string `json:"namespaces"`
+	// Rows written; rows of earlier imports are replaced.
+	DailyRows  int `json:"daily_rows"`
+	HourlyRows int `json:"hourly_rows"`
+	// KeptRows are rows Lighthouse calculated itself, which the import does not overwrite.
+	KeptRows int `json:"kept_rows"`
+	// ImportedCost is the CPU and memory cost imported and SharedCost the shared cost allocated to the
+	// namespaces; OtherCost is the GPU, volume, network and load balancer cost of the export, which is
+	// outside the Lighthouse cost model and not imported.
+	ImportedCost float64 `json:"imported_cost"`
+	SharedCost   float64 `json:"shared_cost"`
+	OtherCost    float64 `json:"other_cost"`
+}
+
+// CostSeriesResponse is the response of GET /api/v1/cost/series: billable (Cost), usage and waste
+// cost over time, oldest first, at the resolution the query planner chose for the range (or the
+// requested one), so clients know the granularity they received.
+type CostSeriesResponse struct {
+	// 为空表示全部 namespace
+	Namespace string    `json:"namespace,omitempty"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	// Resolution 数据粒度（hourly / daily / monthly）；Planned 为 true
+	// 表示由查询计划按时间范围选择
+	Resolution string `json:"resolution"`
+	Planned    bool   `json:"planned"`
+	// 名义步长，月按 30 天
+	StepSeconds int64 `json:"step_seconds"`
+	// Source 读取的表；TimeZone 天/月边界所用的核算时区
+	Source   string                  `json:"source"`
+	TimeZone string                  `json:"time_zone"`
+	Points   []GranularCostDataPoint `json:"points"`
+}
+
+// CostSnapshot is a cost snapshot read with field selection; only the groups listed in Fields were
+// read, absent groups are omitted rather than zero.
+type CostSnapshot struct {
+	ID             string    `json:"id"`
+	CalculationID  string    `json:"calculation_id"`
+	Timestamp      time.Time `json:"timestamp"`
+	TimeRangeStart time.Time `json:"time_range_start"`
+	TimeRangeEnd   time.Time `json:"time_range_end"`
+	// Fields 本次读取的字段组：counts / summary / aggregated_results / resource_results
+	Fields  []string         `json:"fields"`
+	Counts  *GradeCounts     `json:"counts,omitempty"`
+	Summary *SnapshotSummary `json:"summary,omitempty"`
+	// AggregatedResults 按聚合层级（pod / workload / namespace / node / cluster / node_pool）
+	AggregatedResults map[string][]AggregationResult `json:"aggregated_results,omitempty"`
+	ResourceResults   []CostResult                   `json:"resource_results,omitempty"`
+}
+
+// CostSnapshotListResponse is the response of GET /api/v1/snapshots.
+type CostSnapshotListResponse struct {
+	// newest first
+	Snapshots []CostSnapshot `json:"snapshots"`
+	Total     int            `json:"total"`
+}
+
+// CreateAPIKeyRequest is the body of POST /api/v1/admin/service-accounts/:id/keys. Scopes are
+// "<group>:<read|write|*>" where group is the first path segment after /api/v1 (cost, roi, admin,
+// ...) or "*"; read covers GET requests, write the others.
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	DailyQuota int        `json:"daily_quota"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// CreateAdjustmentRequest is the body of POST /api/v1/admin/adjustments: a manual correction of the
+// cost of a namespace (optionally one workload of it) on a day of a closed period. Amount is added
+// to the cost of the day (negative: a credit) once someone other than the requester approves it
+// (POST /api/v1/admin/adjustments/:id/approve).
+type CreateAdjustmentRequest struct {
+	// YYYY-MM-DD
+	Date      string  `json:"date"`
+	Namespace string  `json:"namespace"`
+	Workload  string  `json:"workload"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+}
+
+// CreateExportJobRequest is the body of POST /api/v1/exports. The window, anonymize and scale
+// follow GET /api/v1/admin/dataset/export.
+type CreateExportJobRequest struct {
+	// namespace_costs / workload_stats
+	Kind string `json:"kind"`
+	// csv / pdf, or a format registered by the deployment (e.g. parquet)
+	Format string `json:"format"`
+	// default end_time - 7 days
+	StartTime time.Time `json:"start_time,omitempty"`
+	// default the current hour
+	EndTime   time.Time `json:"end_time,omitempty"`
+	Anonymize bool      `json:"anonymize,omitempty"`
+	Scale     float64   `json:"scale,omitempty"`
+}
+
+// CreateServiceAccountRequest is the body of POST /api/v1/admin/service-accounts.
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// default: the service account of the caller's key
+	CreatedBy string `json:"created_by"`
+}
+
+// CreateSilenceRequest is the body of POST /api/v1/alerts/silences. The end is EndsAt or StartsAt
+// plus Duration.
+type CreateSilenceRequest struct {
+	Matchers map[string]string `json:"matchers"`
+	// default now
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	// Go duration, e.g. "2h"
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+	// default: the service account of the caller's key
+	CreatedBy string `json:"created_by"`
+}
+
+// CreatedAPIKey is the response of key creation, the only time the secret is returned.
+type CreatedAPIKey struct {
+	Key    APIKey `json:"key"`
+	Secret string `json:"secret"`
+}
+
+// Dataset is the stored cost data of a window, for vendors and demos. When Anonymized, namespace,
+// workload, pod, node and cost center names are consistent pseudonyms (the same name has the same
+// pseudonym everywhere in the dataset). Every cost is multiplied by Scale (1 unless requested);
+// resource quantities, timestamps and the ratios between costs are kept, so the cost shapes are
+// those of the source.
+type Dataset struct {
+	Version             int                   `json:"version"`
+	ExportedAt          time.Time             `json:"exported_at"`
+	StartTime           time.Time             `json:"start_time"`
+	EndTime             time.Time             `json:"end_time"`
+	Anonymized          bool                  `json:"anonymized"`
+	Scale               float64               `json:"scale"`
+	DailyNamespaceCosts []DatasetNamespaceDay `json:"daily_namespace_costs"`
+	HourlyWorkloadStats []DatasetWorkloadHour `json:"hourly_workload_stats"`
+}
+
+// DatasetNamespaceDay is the daily cost of one namespace.
+type DatasetNamespaceDay struct {
+	Namespace       string    `json:"namespace"`
+	Date            time.Time `json:"date"`
+	BillableCost    float64   `json:"billable_cost"`
+	UsageCost       float64   `json:"usage_cost"`
+	WasteCost       float64   `json:"waste_cost"`
+	SharedCost      float64   `json:"shared_cost"`
+	PodCount        int       `json:"pod_count"`
+	NodeCount       int       `json:"node_count"`
+	WorkloadCount   int       `json:"workload_count"`
+	EfficiencyScore float64   `json:"efficiency_score"`
+}
+
+// DatasetWorkloadHour is the hourly stat of one workload (pod).
+type DatasetWorkloadHour struct {
+	Namespace         string    `json:"namespace"`
+	WorkloadName      string    `json:"workload_name"`
+	WorkloadType      string    `json:"workload_type"`
+	NodeName          string    `json:"node_name,omitempty"`
+	PodName           string    `json:"pod_name,omitempty"`
+	CostCenter        string    `json:"cost_center,omitempty"`
+	Timestamp         time.Time `json:"timestamp"`
+	CPURequest        float64   `json:"cpu_request"`
+	CPUUsageP95       float64   `json:"cpu_usage_p95"`
+	MemRequest        int64     `json:"mem_request"`
+	MemUsageP95       int64     `json:"mem_usage_p95"`
+	CPUBillableCost   float64   `json:"cpu_billable_cost"`
+	MemBillableCost   float64   `json:"mem_billable_cost"`
+	TotalBillableCost float64   `json:"total_billable_cost"`
+	TotalUsageCost    float64   `json:"total_usage_cost"`
+	TotalWasteCost    float64   `json:"total_waste_cost"`
+}
+
+// DependencyEdge is an edge of the service dependency graph.
+type DependencyEdge struct {
+	CallerNamespace string    `json:"caller_namespace"`
+	Caller          string    `json:"caller"`
+	CalleeNamespace string    `json:"callee_namespace"`
+	Callee          string    `json:"callee"`
+	Source          string    `json:"source"`
+	CallsPerMinute  float64   `json:"calls_per_minute"`
+	LastSeen        time.Time `json:"last_seen"`
+}
+
+// DependencyEdgeInput is one reported dependency: caller calls callee. Namespaces default to each
+// other, so edges within a namespace need only one of them.
+type DependencyEdgeInput struct {
+	CallerNamespace string  `json:"caller_namespace"`
+	Caller          string  `json:"caller"`
+	CalleeNamespace string  `json:"callee_namespace"`
+	Callee          string  `json:"callee"`
+	CallsPerMinute  float64 `json:"calls_per_minute"`
+}
+
+// DependencyEdgeList is the response of GET /api/v1/dependencies: the live edges of the graph.
+type DependencyEdgeList struct {
+	Items []DependencyEdge `json:"items"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists, an
+	// estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// DependencyImpact is the blast radius of a degraded workload: the services that call it directly
+// or transitively, and what they cost. Monthly costs are extrapolated from the billable cost of the
+// workloads named like the services over the window.
+type DependencyImpact struct {
+	Namespace   string    `json:"namespace"`
+	Service     string    `json:"service"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	MonthlyCost float64   `json:"monthly_cost"`
+	// UpstreamMonthlyCost sums the monthly cost of Upstream: the spend depending on the workload.
+	UpstreamMonthlyCost float64           `json:"upstream_monthly_cost"`
+	Upstream            []UpstreamService `json:"upstream"`
+	// Downstream lists the services the workload calls directly ("namespace/service").
+	Downstream []string `json:"downstream"`
+	// Unmatched lists upstream services without workload stats in the window (namespace/service).
+	Unmatched []string `json:"unmatched"`
+}
+
+// DependencyIngestRequest is the body of POST /api/v1/dependencies/edges. The tracing pipeline
+// reports the calls it observed (source tracing); a static dependency map is sent whole (source
+// static) and replaces the previous one.
+type DependencyIngestRequest struct {
+	// tracing / static
+	Source string `json:"source"`
+	// default now
+	ObservedAt time.Time             `json:"observed_at"`
+	Edges      []DependencyEdgeInput `json:"edges"`
+}
+
+// DependencyIngestResponse is the result of an ingestion: the edges saved and the edges of the
+// source removed (the previous static map, or tracing edges not seen within the TTL).
+type DependencyIngestResponse struct {
+	Source   string `json:"source"`
+	Accepted int    `json:"accepted"`
+	Removed  int    `json:"removed"`
+}
+
+// DisplayUnits are the units quantities are displayed in.
+type DisplayUnits struct {
+	// cores / millicores
+	CPU string `json:"cpu"`
+	// GiB / MiB / GB / MB
+	Memory string `json:"memory"`
+}
+
+// DomainBreakdownItem represents a domain in the cost breakdown pie chart.
+type DomainBreakdownItem struct {
+	Domain           string  `json:"domain"`
+	Cost             float64 `json:"cost"`
+	OptimizableSpace float64 `json:"optimizable_space"`
+	Efficiency       float64 `json:"efficiency"`
+	// 同 NamespaceCostSummary.Terminated
+	Terminated bool       `json:"terminated,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// EfficiencyHeatmapResponse is the response of GET /api/v1/cost/efficiency/heatmap. Rows are sorted
+// by billable cost in the window, descending.
+type EfficiencyHeatmapResponse struct {
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	BucketDays  int       `json:"bucket_days"`
+	// start date of each bucket
+	Buckets []time.Time            `json:"buckets"`
+	Rows    []EfficiencyHeatmapRow `json:"rows"`
+	Total   int                    `json:"total"`
+}
+
+// EfficiencyHeatmapRow is the efficiency of one namespace in each bucket of the heatmap.
+type EfficiencyHeatmapRow struct {
+	Namespace string `json:"namespace"`
+	// over the whole window
+	Efficiency float64 `json:"efficiency"`
+	// efficiency target, when set
+	Target *float64 `json:"target,omitempty"`
+	// one per bucket; null: no data
+	Cells []float64 `json:"cells"`
+}
+
+// EfficiencyTarget is the efficiency target of a namespace.
+type EfficiencyTarget struct {
+	Namespace string    `json:"namespace"`
+	Target    float64   `json:"target"`
+	SetBy     string    `json:"set_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EfficiencyTargetDay is the actual efficiency of one day against the target.
+type EfficiencyTargetDay struct {
+	Date       time.Time `json:"date"`
+	Efficiency float64   `json:"efficiency"`
+	Target     float64   `json:"target"`
+	Met        bool      `json:"met"`
+}
+
+// EfficiencyTargetListResponse is the response of GET /api/v1/cost/targets.
+type EfficiencyTargetListResponse struct {
+	Items []EfficiencyTarget `json:"items"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists, an
+	// estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// EfficiencyTargetTrackingResponse is the response of GET
+// /api/v1/cost/targets/{namespace}/tracking.
+type EfficiencyTargetTrackingResponse struct {
+	Namespace   string                `json:"namespace"`
+	WindowStart time.Time             `json:"window_start"`
+	WindowEnd   time.Time             `json:"window_end"`
+	Days        []EfficiencyTargetDay `json:"days"`
+	Attainment  TargetAttainment      `json:"attainment"`
+}
+
+// ErrorResponse represents a standard error response.
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ExportJob is an async export. The file of a succeeded job is downloadable from DownloadURL until
+// ExpiresAt, after which the job is expired and the file deleted.
+type ExportJob struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Format     string    `json:"format"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Anonymized bool      `json:"anonymized"`
+	// queued / running / succeeded / failed / expired
+	Status string `json:"status"`
+	// percent
+	Progress    int        `json:"progress"`
+	TotalRows   int        `json:"total_rows"`
+	RowsWritten int        `json:"rows_written"`
+	FileName    string     `json:"file_name,omitempty"`
+	SizeBytes   int64      `json:"size_bytes,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// ExportJobListResponse is the response of GET /api/v1/exports.
+type ExportJobListResponse struct {
+	Jobs  []ExportJob `json:"jobs"`
+	Total int         `json:"total"`
+	// formats accepted by POST /api/v1/exports
+	Formats []string `json:"formats"`
+}
+
+// ExportRecommendationsRequest is the body of POST /api/v1/roi/recommendations/export: the issued
+// recommendations to export as Kubernetes patch manifests.
+type ExportRecommendationsRequest struct {
+	RecommendationIDs []string `json:"recommendation_ids"`
+	// kustomize (default: overlay with kustomization.yaml) / kubectl (patch files and apply.sh)
+	Format string `json:"format,omitempty"`
+	// kind of the patched workloads: Deployment (default) / StatefulSet
+	WorkloadKind string `json:"workload_kind,omitempty"`
+}
+
+// ExportedSnapshot is one snapshot of an export; Snapshot is the stored snapshot JSON whose content
+// hash (postgres.SnapshotContentHash) must equal ContentHash.
+type ExportedSnapshot struct {
+	ID          string          `json:"id"`
+	ContentHash string          `json:"content_hash"`
+	Snapshot    json.RawMessage `json:"snapshot"`
+}
+
+// FavoriteWorkload is a workload pinned by the user.
+type FavoriteWorkload struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// FiscalReportNamespace is the cost of one namespace in a fiscal report.
+type FiscalReportNamespace struct {
+	Namespace            string  `json:"namespace"`
+	BillableCost         float64 `json:"billable_cost"`
+	SharedCost           float64 `json:"shared_cost"`
+	Adjustments          float64 `json:"adjustments"`
+	PreviousBillableCost float64 `json:"previous_billable_cost"`
+}
+
+// FiscalReportResponse is the response of GET /api/v1/cost/fiscal-report: the cost of a fiscal
+// period, quarter or year to date (through AsOf) and of the whole previous one. Dates are day
+// labels of the accounting time zone; EndDate is exclusive.
+type FiscalReportResponse struct {
+	// period / quarter / year
+	Unit string `json:"unit"`
+	// e.g. FY2026 Q2
+	Label        string    `json:"label"`
+	StartDate    time.Time `json:"start_date"`
+	EndDate      time.Time `json:"end_date"`
+	AsOf         time.Time `json:"as_of"`
+	Days         int       `json:"days"`
+	DaysElapsed  int       `json:"days_elapsed"`
+	BillableCost float64   `json:"billable_cost"`
+	UsageCost    float64   `json:"usage_cost"`
+	WasteCost    float64   `json:"waste_cost"`
+	SharedCost   float64   `json:"shared_cost"`
+	// Projected extrapolates the average daily billable cost to date to the whole span
+	Projected            float64 `json:"projected"`
+	PreviousLabel        string  `json:"previous_label"`
+	PreviousBillableCost float64 `json:"previous_billable_cost"`
+	// Adjustments sums the corrections of closed periods dated within the span to date; the costs
+	// above are the values stored at close. AdjustedTotal is BillableCost + SharedCost + Adjustments.
+	Adjustments   float64 `json:"adjustments"`
+	AdjustedTotal float64 `json:"adjusted_total"`
+	// by date
+	AdjustmentItems []CostAdjustment `json:"adjustment_items"`
+	// by billable cost, descending
+	Namespaces []FiscalReportNamespace `json:"namespaces"`
+}
+
+// GlobalCostResponse represents the response for global cost overview.
+type GlobalCostResponse struct {
+	TotalCost        float64                `json:"total_cost"`
+	TotalOptimizable float64                `json:"total_optimizable"`
+	GlobalEfficiency float64                `json:"global_efficiency"`
+	DomainBreakdown  []DomainBreakdownItem  `json:"domain_breakdown"`
+	Namespaces       []NamespaceCostSummary `json:"namespaces"`
+	// input rows left out by validation
+	QuarantinedRows int       `json:"quarantined_rows,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// GradeChangeEvent is one efficiency grade transition of a workload. FromGrade is empty for the
+// first grade assigned to the workload.
+type GradeChangeEvent struct {
+	Namespace       string    `json:"namespace"`
+	WorkloadName    string    `json:"workload_name"`
+	WorkloadType    string    `json:"workload_type"`
+	FromGrade       string    `json:"from_grade,omitempty"`
+	ToGrade         string    `json:"to_grade"`
+	EfficiencyScore float64   `json:"efficiency_score"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// GradeCounts is the number of resources per efficiency grade.
+type GradeCounts struct {
+	Zombie          int `json:"zombie"`
+	OverProvisioned int `json:"over_provisioned"`
+	Healthy         int `json:"healthy"`
+	Risk            int `json:"risk"`
+}
+
+// GradeHistoryResponse is the response of GET /api/v1/cost/grades/history.
+type GradeHistoryResponse struct {
+	Events []GradeChangeEvent `json:"events"`
+	Total  int                `json:"total"`
+}
+
+// GradeView is the view of snapshot grade counts served by GET /api/v1/snapshots/grades.
+type GradeView struct {
+	// original / regraded
+	View  string `json:"view"`
+	SetBy string `json:"set_by,omitempty"`
+	// zero while the configured default applies
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// GrafanaAnnotation is one annotation event; Time is unix ms.
+type GrafanaAnnotation struct {
+	Annotation GrafanaAnnotationQuery `json:"annotation"`
+	Time       int64                  `json:"time"`
+	TimeEnd    int64                  `json:"timeEnd,omitempty"`
+	Title      string                 `json:"title"`
+	Text       string                 `json:"text"`
+	Tags       []string               `json:"tags"`
+}
+
+// GrafanaAnnotationQuery is the annotation definition configured in Grafana.
+type GrafanaAnnotationQuery struct {
+	Name       string `json:"name"`
+	Datasource string `json:"datasource"`
+	Enable     bool   `json:"enable"`
+	Query      string `json:"query"`
+}
+
+// GrafanaAnnotationRequest is the body of POST /annotations.
+type GrafanaAnnotationRequest struct {
+	Range      GrafanaRange           `json:"range"`
+	Annotation GrafanaAnnotationQuery `json:"annotation"`
+}
+
+// GrafanaQueryRequest is the body of POST /query.
+type GrafanaQueryRequest struct {
+	Range         GrafanaRange         `json:"range"`
+	IntervalMs    int64                `json:"intervalMs"`
+	MaxDataPoints int                  `json:"maxDataPoints"`
+	Targets       []GrafanaQueryTarget `json:"targets"`
+}
+
+// GrafanaQueryTarget is one query of a panel.
+type GrafanaQueryTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+	// "timeserie" (default) or "table"
+	Type string `json:"type"`
+}
+
+// GrafanaRange is the dashboard time range sent by Grafana.
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// GrafanaSearchRequest is the body of POST /search.
+type GrafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+// GranularCostDataPoint represents a time-series data point for cost.
+type GranularCostDataPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Cost      float64   `json:"cost"`
+	Usage     float64   `json:"usage"`
+	Waste     float64   `json:"waste"`
+}
+
+// LineageSnapshot is the cost snapshot a lineage record refers to.
+type LineageSnapshot struct {
+	ID            string    `json:"id"`
+	CalculationID string    `json:"calculation_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	ContentHash   string    `json:"content_hash,omitempty"`
+}
+
+// NamespaceCostResponse represents the response for namespace cost details.
+type NamespaceCostResponse struct {
+	Namespace string         `json:"namespace"`
+	Cost      CostBreakdown  `json:"cost"`
+	Workloads []WorkloadCost `json:"workloads"`
+	// 分页前的工作负载数
+	WorkloadTotal int               `json:"workload_total"`
+	Nodes         []NodeCostSummary `json:"nodes"`
+	Timestamp     time.Time         `json:"timestamp"`
+	// 已删除 namespace 的历史成本仍可查询，Terminated 标注其状态
+	Terminated bool       `json:"terminated,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// NamespaceCostSummary represents a summary of cost for a namespace.
+type NamespaceCostSummary struct {
+	Name      string  `json:"name"`
+	Cost      float64 `json:"cost"`
+	WasteCost float64 `json:"waste_cost"`
+	// usage / billable, %
+	Efficiency float64 `json:"efficiency"`
+	Grade      string  `json:"grade"`
+	PodCount   int     `json:"pod_count"`
+	NodeCount  int     `json:"node_count"`
+	// Terminated 该 namespace
+	// 已从集群删除（namespace_lifecycle.deleted_at），成本仅来自历史数据
+	Terminated bool              `json:"terminated,omitempty"`
+	DeletedAt  *time.Time        `json:"deleted_at,omitempty"`
+	Metadata   *ResourceMetadata `json:"metadata,omitempty"`
+}
+
+// NamespaceCostSummaryList is the response of GET /api/v1/cost/namespaces.
+type NamespaceCostSummaryList struct {
+	Items []NamespaceCostSummary `json:"items"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists, an
+	// estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// NamespaceGrowth is the current requests of a namespace and their fitted daily growth.
+type NamespaceGrowth struct {
+	Namespace       string  `json:"namespace"`
+	CPURequest      float64 `json:"cpu_request"`
+	MemRequest      int64   `json:"mem_request"`
+	CPUGrowthPerDay float64 `json:"cpu_growth_per_day"`
+	MemGrowthPerDay float64 `json:"mem_growth_per_day"`
+}
+
+// NamespaceOnboarding is the onboarding of a namespace: the settings it was onboarded with and the
+// steps of its latest onboarding.
+type NamespaceOnboarding struct {
+	Namespace        string           `json:"namespace"`
+	Team             string           `json:"team"`
+	Owners           []string         `json:"owners"`
+	MonthlyBudget    float64          `json:"monthly_budget"`
+	SLOTemplates     []string         `json:"slo_templates"`
+	ReportRecipients []string         `json:"report_recipients"`
+	EfficiencyTarget float64          `json:"efficiency_target"`
+	Channels         []string         `json:"channels,omitempty"`
+	OnboardedBy      string           `json:"onboarded_by,omitempty"`
+	OnboardedAt      time.Time        `json:"onboarded_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Steps            []OnboardingStep `json:"steps"`
+}
+
+// NamespaceOnboardingList is the response of GET /api/v1/admin/onboarding, sorted by namespace.
+type NamespaceOnboardingList struct {
+	Items []NamespaceOnboarding `json:"items"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists, an
+	// estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// NodeAnalysisResponse is the response of GET /api/v1/nodes/analysis. Costs are hourly;
+// EstimatedMonthlySavings sums the consolidation candidates.
+type NodeAnalysisResponse struct {
+	// Timestamp
+	// 计算所用的小时（最新一小时的工作负载统计）；无统计数据时为零值
+	Timestamp               time.Time                    `json:"timestamp"`
+	TargetUtilization       float64                      `json:"target_utilization"`
+	Cluster                 NodeCostItem                 `json:"cluster"`
+	Nodes                   []NodeCostItem               `json:"nodes"`
+	Candidates              []NodeConsolidationCandidate `json:"candidates"`
+	EstimatedMonthlySavings float64                      `json:"estimated_monthly_savings"`
+}
+
+// NodeConsolidationBlock is a consolidation candidate that cannot be drained now.
+type NodeConsolidationBlock struct {
+	Node    string   `json:"node"`
+	Reasons []string `json:"reasons"`
+}
+
+// NodeConsolidationCandidate is a node whose requests fit on the remaining nodes, in drain order.
+type NodeConsolidationCandidate struct {
+	Rank                    int     `json:"rank"`
+	Name                    string  `json:"name"`
+	PodCount                int     `json:"pod_count"`
+	RequestedCPU            float64 `json:"requested_cpu"`
+	RequestedMem            int64   `json:"requested_mem"`
+	BinPackingEfficiency    float64 `json:"bin_packing_efficiency"`
+	HourlyCost              float64 `json:"hourly_cost"`
+	EstimatedMonthlySavings float64 `json:"estimated_monthly_savings"`
+}
+
+// NodeConsolidationPlan is the response of GET /api/v1/nodes/consolidation-plan: the consolidation
+// candidates as a reviewable drain order, each step with its savings, its risk and the commands to
+// run. Nodes whose pods PodDisruptionBudgets would not let go are left out and listed in Blocked.
+type NodeConsolidationPlan struct {
+	Timestamp         time.Time `json:"timestamp"`
+	TargetUtilization float64   `json:"target_utilization"`
+	// DisruptionBudgetsChecked is false when the cluster's PodDisruptionBudgets could not be
+	// consulted.
+	DisruptionBudgetsChecked bool                     `json:"disruption_budgets_checked"`
+	Steps                    []NodeDrainStep          `json:"steps"`
+	Blocked                  []NodeConsolidationBlock `json:"blocked"`
+	EstimatedMonthlySavings  float64                  `json:"estimated_monthly_savings"`
+}
+
+// NodeCostItem is the allocatable vs requested vs used picture of a node (or the whole cluster).
+// BinPackingEfficiency is the dominant request ratio max(cpu, memory) in %.
+type NodeCostItem struct {
+	Name           string  `json:"name"`
+	PodCount       int     `json:"pod_count"`
+	AllocatableCPU float64 `json:"allocatable_cpu"`
+	AllocatableMem int64   `json:"allocatable_mem"`
+	RequestedCPU   float64 `json:"requested_cpu"`
+	RequestedMem   int64   `json:"requested_mem"`
+	// sum of workload P95
+	UsedCPU              float64 `json:"used_cpu"`
+	UsedMem              int64   `json:"used_mem"`
+	AllocatableCost      float64 `json:"allocatable_cost"`
+	RequestedCost        float64 `json:"requested_cost"`
+	UsedCost             float64 `json:"used_cost"`
+	UnallocatedCost      float64 `json:"unallocated_cost"`
+	CPURequestRatio      float64 `json:"cpu_request_ratio"`
+	MemRequestRatio      float64 `json:"mem_request_ratio"`
+	BinPackingEfficiency float64 `json:"bin_packing_efficiency"`
+}
+
+// NodeCostSummary represents cost summary for a node.
+type NodeCostSummary struct {
+	Name           string  `json:"name"`
+	TotalCost      float64 `json:"total_cost"`
+	UtilizationCPU float64 `json:"utilization_cpu"`
+	UtilizationMem float64 `json:"utilization_mem"`
+	PodCount       int     `json:"pod_count"`
+}
+
+// NodeDrainBudget is a PodDisruptionBudget covering pods of a drained node.
+type NodeDrainBudget struct {
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	PodsOnNode         int    `json:"pods_on_node"`
+	DisruptionsAllowed int    `json:"disruptions_allowed"`
+}
+
+// NodeDrainStep is one node of the drain order.
+type NodeDrainStep struct {
+	Step                     int     `json:"step"`
+	Node                     string  `json:"node"`
+	PodCount                 int     `json:"pod_count"`
+	HourlyCost               float64 `json:"hourly_cost"`
+	MonthlySavings           float64 `json:"monthly_savings"`
+	CumulativeMonthlySavings float64 `json:"cumulative_monthly_savings"`
+	// Request ratios (%) of the nodes kept after the step, carrying the requests of the drained nodes.
+	RemainingCPURequestRatio float64 `json:"remaining_cpu_request_ratio"`
+	RemainingMemRequestRatio float64 `json:"remaining_mem_request_ratio"`
+	// low / medium / high
+	Risk        string   `json:"risk"`
+	RiskReasons []string `json:"risk_reasons"`
+	// DisruptionBudgets are the budgets covering pods of the node.
+	DisruptionBudgets []NodeDrainBudget `json:"disruption_budgets"`
+	Commands          []string          `json:"commands"`
+}
+
+// NodePoolAnalysisResponse is the response of GET /api/v1/nodes/pools: capacity and cost per node
+// pool, the granularity of capacity decisions. Pools come from the current nodes; workload costs
+// recorded without a pool are reported under "unassigned".
+type NodePoolAnalysisResponse struct {
+	StartTime         time.Time          `json:"start_time"`
+	EndTime           time.Time          `json:"end_time"`
+	TotalBillableCost float64            `json:"total_billable_cost"`
+	Pools             []NodePoolCostItem `json:"pools"`
+}
+
+// NodePoolCostItem is the capacity and the workload costs of one node pool. Allocatable figures are
+// current; costs are summed over the window.
+type NodePoolCostItem struct {
+	Name                  string   `json:"name"`
+	NodeCount             int      `json:"node_count"`
+	Nodes                 []string `json:"nodes"`
+	AllocatableCPU        float64  `json:"allocatable_cpu"`
+	AllocatableMem        int64    `json:"allocatable_mem"`
+	HourlyAllocatableCost float64  `json:"hourly_allocatable_cost"`
+	WorkloadCount         int      `json:"workload_count"`
+	BillableCost          float64  `json:"billable_cost"`
+	UsageCost             float64  `json:"usage_cost"`
+	WasteCost             float64  `json:"waste_cost"`
+	EfficiencyScore       float64  `json:"efficiency_score"`
+	CostPercentage        float64  `json:"cost_percentage"`
+}
+
+// NotificationRoute sends the alerts whose labels match all matchers to its channels only. Alerts
+// are labelled with alert_type, severity and, where known, namespace, team and rule; a matcher
+// value "*" only requires the label. The matching route of lowest priority wins; alerts naming
+// their own channels (alert rules with channels) are not rerouted.
+type NotificationRoute struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Matchers  map[string]string `json:"matchers"`
+	Channels  []string          `json:"channels"`
+	Priority  int               `json:"priority"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// NotificationRouteListResponse is the response of GET /api/v1/alerts/routes, in matching order.
+type NotificationRouteListResponse struct {
+	Routes []NotificationRoute `json:"routes"`
+	Total  int                 `json:"total"`
+}
+
+// NotificationRouteRequest is the body of POST /api/v1/alerts/routes and PUT
+// /api/v1/alerts/routes/:id.
+type NotificationRouteRequest struct {
+	Name string `json:"name"`
+	// empty matches every alert
+	Matchers map[string]string `json:"matchers"`
+	Channels []string          `json:"channels"`
+	Priority int               `json:"priority"`
+	// default: the service account of the caller's key
+	CreatedBy string `json:"created_by"`
+}
+
+// OffHoursAnalysisResponse is the response of GET /api/v1/analysis/offhours. Workloads are sorted
+// by idle off-hours cost, descending.
+type OffHoursAnalysisResponse struct {
+	WindowStart   time.Time          `json:"window_start"`
+	WindowEnd     time.Time          `json:"window_end"`
+	BusinessHours BusinessHoursState `json:"business_hours"`
+	// dominant usage/request ratio at or below which an hour is idle
+	IdleUtilization float64            `json:"idle_utilization"`
+	Summary         OffHoursSummary    `json:"summary"`
+	Workloads       []OffHoursWorkload `json:"workloads"`
+	Total           int                `json:"total"`
+}
+
+// OffHoursSummary sums the workloads of the analysis (before limit).
+type OffHoursSummary struct {
+	BusinessCost     float64 `json:"business_cost"`
+	OffHoursCost     float64 `json:"off_hours_cost"`
+	IdleOffHoursCost float64 `json:"idle_off_hours_cost"`
+	// idle off-hours cost / total cost, %
+	IdleOffHoursCostShare   float64 `json:"idle_off_hours_cost_share"`
+	Candidates              int     `json:"candidates"`
+	EstimatedMonthlySavings float64 `json:"estimated_monthly_savings"`
+}
+
+// OffHoursWorkload is the business hours vs off-hours profile of one workload.
+type OffHoursWorkload struct {
+	Namespace      string          `json:"namespace"`
+	WorkloadName   string          `json:"workload_name"`
+	WorkloadType   string          `json:"workload_type,omitempty"`
+	Profile        OffHoursProfile `json:"profile"`
+	Recommendation *Recommendation `json:"recommendation,omitempty"`
+}
+
+// OnboardNamespaceRequest is the body of POST /api/v1/admin/onboarding. Omitted settings take the
+// defaults of business.onboarding; onboarding a namespace again replaces its settings.
+type OnboardNamespaceRequest struct {
+	Namespace string `json:"namespace"`
+	Team      string `json:"team"`
+	// e-mail addresses
+	Owners []string `json:"owners"`
+	// MonthlyBudget is the budget of the namespace's rolling 30-day cost; 0 creates no budget alert.
+	MonthlyBudget *float64 `json:"monthly_budget"`
+	// SLOTemplates are the SLO alert templates to enable (availability, error_budget); omitted: the
+	// defaults, empty: none.
+	SLOTemplates []string `json:"slo_templates"`
+	// ReportRecipients subscribe to the weekly report of the namespace; omitted: the owners.
+	ReportRecipients []string `json:"report_recipients"`
+	// EfficiencyTarget is the efficiency score (0-100] tracked for the namespace.
+	EfficiencyTarget *float64 `json:"efficiency_target"`
+	// Channels restrict the budget and SLO alerts to these channels; empty sends to all of them.
+	Channels []string `json:"channels"`
+}
+
+// OnboardingStep is the outcome of one onboarding step.
+type OnboardingStep struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+	// e.g. the ID of the alert rule
+	ResourceID string `json:"resource_id,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// OptimizationRecordListResponse is the response of GET /api/v1/roi/optimizations.
+type OptimizationRecordListResponse struct {
+	Items []OptimizationTrackingRecord `json:"items"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists, an
+	// estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// PriceChange is a proposed unit price version and its review. Status is draft, rejected, approved
+// (written to the price history, effective later) or effective (approved and in effect).
+type PriceChange struct {
+	ID                  string    `json:"id"`
+	Status              string    `json:"status"`
+	EffectiveFrom       time.Time `json:"effective_from"`
+	CPUPricePerCoreHour float64   `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64   `json:"mem_price_per_gb_hour"`
+	Note                string    `json:"note,omitempty"`
+	Recalculate         bool      `json:"recalculate"`
+	RequestedBy         string    `json:"requested_by,omitempty"`
+	PriceVersionID      string    `json:"price_version_id,omitempty"`
+	// 按时间正序
+	History   []PriceChangeEvent `json:"history"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// PriceChangeEvent is a step of a price change: proposed, approved or rejected.
+type PriceChangeEvent struct {
+	Action  string    `json:"action"`
+	Actor   string    `json:"actor,omitempty"`
+	Comment string    `json:"comment,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// PriceChangeListResponse is the response of GET /api/v1/pricing/changes.
+type PriceChangeListResponse struct {
+	// 最新的在前
+	Changes []PriceChange `json:"changes"`
+}
+
+// PriceChangeResponse is the response of PUT /api/v1/pricing/history.
+type PriceChangeResponse struct {
+	Version PriceVersion `json:"version"`
+	// AffectedStart..AffectedEnd
+	// 已计算的数据中使用该价格的窗口；未来生效的版本没有受影响窗口
+	AffectedStart *time.Time      `json:"affected_start,omitempty"`
+	AffectedEnd   *time.Time      `json:"affected_end,omitempty"`
+	Recalculation *CalculationRun `json:"recalculation,omitempty"`
+	Change        *PriceChange    `json:"change,omitempty"`
+}
+
+// PriceHistoryResponse is the response of GET /api/v1/pricing/history.
+type PriceHistoryResponse struct {
+	// 按生效时间正序
+	Versions []PriceVersion `json:"versions"`
+	Current  *PriceVersion  `json:"current,omitempty"`
+}
+
+// PriceVersion is a CPU/memory unit price effective from EffectiveFrom until the next version.
+type PriceVersion struct {
+	ID                  string    `json:"id"`
+	EffectiveFrom       time.Time `json:"effective_from"`
+	CPUPricePerCoreHour float64   `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64   `json:"mem_price_per_gb_hour"`
+	Note                string    `json:"note,omitempty"`
+	// Revision 该版本的更正次数（首次保存为 1）；ChangeID
+	// 批准该版本的单价变更申请
+	Revision  int       `json:"revision"`
+	ChangeID  string    `json:"change_id,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QueryBudgetConsumer is the consumption of one API consumer (service account, or "anonymous").
+// Limits of 0 are unlimited.
+type QueryBudgetConsumer struct {
+	Consumer               string  `json:"consumer"`
+	PrometheusQueries      int64   `json:"prometheus_queries"`
+	PrometheusQueryLimit   int     `json:"prometheus_query_limit"`
+	PrometheusQuerySeconds float64 `json:"prometheus_query_seconds"`
+	DatabaseQueries        int64   `json:"database_queries"`
+	DatabaseQueryLimit     int     `json:"database_query_limit"`
+	DatabaseQuerySeconds   float64 `json:"database_query_seconds"`
+	// Exceeded is the data source whose quota is used up ("prometheus" / "database"); the consumer's
+	// requests are rejected until the window ends.
+	Exceeded string `json:"exceeded,omitempty"`
+}
+
+// QueryBudgetResponse is the response of GET /api/v1/admin/query-budgets: the Prometheus and
+// database queries of every API consumer in the current accounting window, by consumer.
+type QueryBudgetResponse struct {
+	WindowStart time.Time             `json:"window_start"`
+	WindowEnd   time.Time             `json:"window_end"`
+	Consumers   []QueryBudgetConsumer `json:"consumers"`
+}
+
+// QueryLineage is the response of GET /api/v1/lineage/:id: the data and code that produced a cost
+// query response, captured when it was served (its X-Lighthouse-Lineage-Id header).
+type QueryLineage struct {
+	ID        string `json:"id"`
+	RequestID string `json:"request_id,omitempty"`
+	Method    string `json:"method"`
+	// including the query string
+	Path           string           `json:"path"`
+	Status         int              `json:"status"`
+	ServedAt       time.Time        `json:"served_at"`
+	Snapshot       *LineageSnapshot `json:"snapshot,omitempty"`
+	CalculationRun *CalculationRun  `json:"calculation_run,omitempty"`
+	PriceVersion   *PriceVersion    `json:"price_version,omitempty"`
+	Code           CodeVersion      `json:"code"`
+}
+
+// ROIBaselineComparisonResponse is the response of GET /api/v1/roi/baselines/:id/compare: the
+// metrics of Date compared against a stored baseline. Baseline fields not pinned in the baseline's
+// metrics are derived from the data of its period (daily averages); PinnedMetrics lists the others.
+type ROIBaselineComparisonResponse struct {
+	BaselineID          string           `json:"baseline_id"`
+	BaselineName        string           `json:"baseline_name"`
+	BaselineType        string           `json:"baseline_type"`
+	BaselinePeriodStart time.Time        `json:"baseline_period_start"`
+	BaselinePeriodEnd   time.Time        `json:"baseline_period_end"`
+	PinnedMetrics       []string         `json:"pinned_metrics"`
+	Baseline            BaselineSnapshot `json:"baseline"`
+	Current             BaselineSnapshot `json:"current"`
+	Comparison          DailyComparison  `json:"comparison"`
+}
+
+// ROIBaselineState is an ROI baseline without its storage timestamps.
+type ROIBaselineState struct {
+	ID              string                     `json:"id"`
+	Name            string                     `json:"name"`
+	Description     string                     `json:"description,omitempty"`
+	BaselineType    string                     `json:"baseline_type"`
+	TimePeriodStart time.Time                  `json:"time_period_start"`
+	TimePeriodEnd   time.Time                  `json:"time_period_end"`
+	Metrics         map[string]float64         `json:"metrics,omitempty"`
+	ReferenceData   map[string]json.RawMessage `json:"reference_data,omitempty"`
+	CreatedBy       string                     `json:"created_by,omitempty"`
+}
+
+// RecommendationAdoption summarizes the adoption of a set of issued recommendations. Savings only
+// count recommendations that save money (decommission and downsize).
+type RecommendationAdoption struct {
+	Team    string `json:"team,omitempty"`
+	Issued  int    `json:"issued"`
+	Applied int    `json:"applied"`
+	Partial int    `json:"partial"`
+	Open    int    `json:"open"`
+	// applied / issued, %
+	AdoptionRate             float64 `json:"adoption_rate"`
+	EstimatedMonthlySavings  float64 `json:"estimated_monthly_savings"`
+	RealizedMonthlySavings   float64 `json:"realized_monthly_savings"`
+	UnrealizedMonthlySavings float64 `json:"unrealized_monthly_savings"`
+}
+
+// RecommendationAdoptionResponse is the response of GET /api/v1/roi/recommendations/adoption: how
+// many of the recommendations issued since Since were applied, per team, and the savings they left
+// unrealized.
+type RecommendationAdoptionResponse struct {
+	Since time.Time              `json:"since"`
+	Total RecommendationAdoption `json:"total"`
+	// by unrealized savings, descending
+	Teams []RecommendationAdoption `json:"teams"`
+	// TopUnrealized are the recommendations with the highest unrealized savings (at most 10)
+	TopUnrealized []UnrealizedRecommendation `json:"top_unrealized"`
+}
+
+// RegradeRequest is the body of POST /api/v1/admin/regrade. Snapshots are selected by timestamp; an
+// empty window selects all of them.
+type RegradeRequest struct {
+	StartTime  time.Time        `json:"start_time,omitempty"`
+	EndTime    time.Time        `json:"end_time,omitempty"`
+	Thresholds *GradeThresholds `json:"thresholds,omitempty"`
+	// compute without saving
+	DryRun     bool   `json:"dry_run,omitempty"`
+	RegradedBy string `json:"regraded_by,omitempty"`
+}
+
+// RegradeResponse is the response of POST /api/v1/admin/regrade. Snapshots without resource results
+// cannot be regraded and are counted in Skipped.
+type RegradeResponse struct {
+	JobID      string                  `json:"job_id"`
+	DryRun     bool                    `json:"dry_run"`
+	Thresholds GradeThresholds         `json:"thresholds"`
+	Regraded   int                     `json:"regraded"`
+	Changed    int                     `json:"changed"`
+	Skipped    int                     `json:"skipped"`
+	Original   GradeCounts             `json:"original"`
+	Total      GradeCounts             `json:"total"`
+	Snapshots  []SnapshotRegradeResult `json:"snapshots"`
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt time.Time               `json:"finished_at"`
+}
+
+// ResourceMetadata is the K8s metadata of a namespace or workload joined onto cost results at query
+// time; workloads inherit the fields they do not set from their namespace.
+type ResourceMetadata struct {
+	App         string `json:"app,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Tier        string `json:"tier,omitempty"`
+	Environment string `json:"environment,omitempty"`
+}
+
+// ReviewPriceChangeRequest is the body of POST /api/v1/pricing/changes/:id/approve and /reject.
+type ReviewPriceChangeRequest struct {
+	Comment string `json:"comment"`
+}
+
+// SLOConfigState holds the SLO thresholds of an environment.
+type SLOConfigState struct {
+	AvailabilityThreshold float64 `json:"availability_threshold"`
+	LatencyP95ThresholdMs int     `json:"latency_p95_threshold_ms"`
+}
+
+// SLOCostResponse is the response of GET /api/v1/slo/cost: what the availability target of each
+// service costs in capacity, from its usage quantiles over the window. Monthly costs are at the
+// configured unit prices.
+type SLOCostResponse struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// TotalSLOHeadroomMonthlyCost sums the capacity kept above typical demand for the targets.
+	TotalSLOHeadroomMonthlyCost float64          `json:"total_slo_headroom_monthly_cost"`
+	TotalExcessMonthlyCost      float64          `json:"total_excess_monthly_cost"`
+	Services                    []SLOServiceCost `json:"services"`
+	// Unmatched lists SLO services without workload stats in the window (namespace/service).
+	Unmatched []string `json:"unmatched"`
+}
+
+// SLOHealth is the health of one service as the dashboard shows it.
+type SLOHealth struct {
+	ServiceName string `json:"serviceName"`
+	// healthy / warning / critical
+	Status string `json:"status"`
+	// %
+	Uptime       float64 `json:"uptime"`
+	ResponseTime float64 `json:"responseTime"`
+	ErrorRate    float64 `json:"errorRate"`
+}
+
+// SLOHealthList is the response of GET /api/v1/slo/health.
+type SLOHealthList struct {
+	Items []SLOHealth `json:"items"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalEstimate is the number of results before paging; exact for the current in-memory lists, an
+	// estimate once a source only counts approximately.
+	TotalEstimate int `json:"total_estimate"`
+}
+
+// SLOServiceCost is the cost of the SLO of one service (the workload named like it).
+type SLOServiceCost struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	Status    string `json:"status"`
+	// Availability is the measured availability (%), next to the target in Cost.
+	Availability float64 `json:"availability"`
+	LatencyP95Ms float64 `json:"latency_p95_ms"`
+	// LatencyTargetMs is the P95 latency target; LatencyBound is set when the measured P95 uses more
+	// than 80% of it, i.e. the headroom also keeps latency in target and relaxing nines risks it.
+	LatencyTargetMs float64 `json:"latency_target_ms"`
+	LatencyBound    bool    `json:"latency_bound"`
+	// Samples is where the quantiles come from: "sketch" (merged usage sketches) or "hourly_p95".
+	Samples string  `json:"samples"`
+	Cost    SLOCost `json:"cost"`
+}
+
+// SLOViolationRecord is a recorded SLO violation.
+type SLOViolationRecord struct {
+	ID             string            `json:"id"`
+	Namespace      string            `json:"namespace"`
+	Service        string            `json:"service"`
+	ViolationType  string            `json:"violation_type"`
+	ActualValue    float64           `json:"actual_value"`
+	ThresholdValue float64           `json:"threshold_value"`
+	Severity       string            `json:"severity"`
+	ViolatedAt     time.Time         `json:"violated_at"`
+	RecoveredAt    *time.Time        `json:"recovered_at,omitempty"`
+	Impact         *DependencyImpact `json:"impact,omitempty"`
+}
+
+// SLOViolationRequest is the body of POST /api/v1/timeline/slo-violations. Report the start of a
+// violation, then the same ID with recovered_at once it recovers.
+type SLOViolationRequest struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	// availability / latency / error_rate
+	ViolationType  string  `json:"violation_type"`
+	ActualValue    float64 `json:"actual_value"`
+	ThresholdValue float64 `json:"threshold_value"`
+	// warning / critical, default critical
+	Severity string `json:"severity"`
+	// default now
+	ViolatedAt  time.Time  `json:"violated_at"`
+	RecoveredAt *time.Time `json:"recovered_at"`
+}
+
+// SavedView is a named set of filters of a cockpit page.
+type SavedView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Page is the cockpit route the view belongs to, e.g. "/cost/namespaces".
+	Page string `json:"page,omitempty"`
+	// Filters are the query parameters of the page, e.g. {"namespace": "shop", "grade": "waste"}.
+	Filters   map[string]string `json:"filters"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// SavedViewRequest is the body of POST /api/v1/preferences/views.
+type SavedViewRequest struct {
+	Name    string            `json:"name"`
+	Page    string            `json:"page"`
+	Filters map[string]string `json:"filters"`
+}
+
+// SelfSLOResponse is the response of GET /api/v1/slo/self: the availability and latency SLOs of
+// Lighthouse's own API routes over the evaluation window, worst route first.
+type SelfSLOResponse struct {
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	// Default targets; routes may override them in server.self_slo.routes.
+	AvailabilityTarget float64 `json:"availability_target"`
+	// P95
+	LatencyTargetMs float64 `json:"latency_target_ms"`
+	// worst route status: healthy / warning / critical
+	Status string         `json:"status"`
+	Routes []SelfSLORoute `json:"routes"`
+}
+
+// SelfSLORoute is the SLO evaluation of one API route.
+type SelfSLORoute struct {
+	Method string `json:"method"`
+	// registered path pattern, e.g. /api/v1/workloads/:id
+	Route    string `json:"route"`
+	Status   string `json:"status"`
+	Requests int64  `json:"requests"`
+	// 5xx responses
+	Errors int64 `json:"errors"`
+	// %
+	Availability       float64 `json:"availability"`
+	AvailabilityTarget float64 `json:"availability_target"`
+	// Latency quantiles are estimated from the request latency histogram.
+	LatencyP50Ms    float64 `json:"latency_p50_ms"`
+	LatencyP95Ms    float64 `json:"latency_p95_ms"`
+	LatencyP99Ms    float64 `json:"latency_p99_ms"`
+	LatencyTargetMs float64 `json:"latency_target_ms"`
+	// slower than the latency target
+	SlowRequests int64 `json:"slow_requests"`
+	// Remaining error budgets (%); ErrorBudgetRemaining is the lower of both.
+	AvailabilityBudgetRemaining float64 `json:"availability_budget_remaining"`
+	LatencyBudgetRemaining      float64 `json:"latency_budget_remaining"`
+	ErrorBudgetRemaining        float64 `json:"error_budget_remaining"`
+}
+
+// ServiceAccount is a machine identity (CI job, other service) with its API keys.
+type ServiceAccount struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Disabled    bool      `json:"disabled"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Keys        []APIKey  `json:"keys"`
+}
+
+// ServiceAccountListResponse is the response of GET /api/v1/admin/service-accounts.
+type ServiceAccountListResponse struct {
+	Accounts []ServiceAccount `json:"accounts"`
+	Total    int              `json:"total"`
+}
+
+// SetEfficiencyTargetRequest is the body of PUT /api/v1/cost/targets/{namespace}.
+type SetEfficiencyTargetRequest struct {
+	// efficiency score 0-100
+	Target float64 `json:"target"`
+	SetBy  string  `json:"set_by,omitempty"`
+}
+
+// SetGradeViewRequest is the body of PUT /api/v1/admin/regrade/view.
+type SetGradeViewRequest struct {
+	// original / regraded
+	View  string `json:"view"`
+	SetBy string `json:"set_by,omitempty"`
+}
+
+// SetPriceRequest is the body of PUT /api/v1/pricing/history. An existing EffectiveFrom corrects
+// that version; Recalculate re-prices the affected window right away.
+type SetPriceRequest struct {
+	EffectiveFrom       time.Time `json:"effective_from"`
+	CPUPricePerCoreHour float64   `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64   `json:"mem_price_per_gb_hour"`
+	Note                string    `json:"note"`
+	Recalculate         bool      `json:"recalculate"`
+}
+
+// Silence mutes the alerts whose labels match all matchers from StartsAt until EndsAt.
+type Silence struct {
+	ID        string            `json:"id"`
+	Matchers  map[string]string `json:"matchers"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    time.Time         `json:"ends_at"`
+	Reason    string            `json:"reason"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	// who ended it early
+	ExpiredBy string `json:"expired_by,omitempty"`
+	Active    bool   `json:"active"`
+}
+
+// SilenceListResponse is the response of GET /api/v1/alerts/silences.
+type SilenceListResponse struct {
+	Silences []Silence `json:"silences"`
+	Total    int       `json:"total"`
+}
+
+// SnapshotExportResponse is the response of GET /api/v1/snapshots/export. It is only produced when
+// the exported snapshots verify; otherwise the endpoint returns 409 with the verification.
+type SnapshotExportResponse struct {
+	WindowStart  time.Time                    `json:"window_start"`
+	WindowEnd    time.Time                    `json:"window_end"`
+	Snapshots    []ExportedSnapshot           `json:"snapshots"`
+	Verification SnapshotVerificationResponse `json:"verification"`
+	ExportedAt   time.Time                    `json:"exported_at"`
+}
+
+// SnapshotGrades is the grade counts of a snapshot in a view. In the regraded view, snapshots not
+// regraded yet keep their original counts (Regraded false).
+type SnapshotGrades struct {
+	SnapshotID string           `json:"snapshot_id"`
+	Timestamp  time.Time        `json:"timestamp"`
+	Regraded   bool             `json:"regraded"`
+	Thresholds *GradeThresholds `json:"thresholds,omitempty"`
+	Counts     GradeCounts      `json:"counts"`
+}
+
+// SnapshotGradesResponse is the response of GET /api/v1/snapshots/grades.
+type SnapshotGradesResponse struct {
+	View      string           `json:"view"`
+	Snapshots []SnapshotGrades `json:"snapshots"`
+	Total     GradeCounts      `json:"total"`
+}
+
+// SnapshotIntegrityIssue is one problem found while verifying snapshots against the hash chain.
+type SnapshotIntegrityIssue struct {
+	SnapshotID string `json:"snapshot_id,omitempty"`
+	Seq        int64  `json:"seq,omitempty"`
+	// chain_broken / content_mismatch / not_in_chain / missing
+	Problem string `json:"problem"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// SnapshotRegradeResult compares the stored grade counts of a snapshot with its regraded counts.
+type SnapshotRegradeResult struct {
+	SnapshotID string      `json:"snapshot_id"`
+	Timestamp  time.Time   `json:"timestamp"`
+	Resources  int         `json:"resources"`
+	Original   GradeCounts `json:"original"`
+	Regraded   GradeCounts `json:"regraded"`
+	Changed    bool        `json:"changed"`
+}
+
+// SnapshotSummary is the headline part of a cost snapshot.
+type SnapshotSummary struct {
+	TotalBillableCost      float64                    `json:"total_billable_cost"`
+	TotalUsageCost         float64                    `json:"total_usage_cost"`
+	TotalWasteCost         float64                    `json:"total_waste_cost"`
+	OverallEfficiencyScore float64                    `json:"overall_efficiency_score"`
+	ContentHash            string                     `json:"content_hash,omitempty"`
+	Suspect                bool                       `json:"suspect,omitempty"`
+	RequiresConfirmation   bool                       `json:"requires_confirmation,omitempty"`
+	ConfirmedBy            string                     `json:"confirmed_by,omitempty"`
+	ConfirmedAt            *time.Time                 `json:"confirmed_at,omitempty"`
+	Metadata               map[string]json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt              time.Time                  `json:"created_at"`
+	UpdatedAt              time.Time                  `json:"updated_at"`
+}
+
+// SnapshotVerificationResponse is the response of GET /api/v1/snapshots/verify.
+type SnapshotVerificationResponse struct {
+	// 为空表示校验全部快照
+	SnapshotID  string                   `json:"snapshot_id,omitempty"`
+	Verified    bool                     `json:"verified"`
+	Snapshots   int                      `json:"snapshots"`
+	ChainLength int                      `json:"chain_length"`
+	HeadHash    string                   `json:"head_hash"`
+	Issues      []SnapshotIntegrityIssue `json:"issues"`
+	VerifiedAt  time.Time                `json:"verified_at"`
+}
+
+// StartChaosDrillRequest is the body of POST /api/v1/admin/chaos.
+type StartChaosDrillRequest struct {
+	Scenario string `json:"scenario"`
+	// Go duration, e.g. "15m"; at most the configured max_duration
+	Duration string `json:"duration"`
+}
+
+// StateBundle is the application state of an environment, for cloning it into another one (e.g.
+// staging -> prod). ROI baselines and price versions are stored data; SLO, teams and owners are
+// configuration of the source environment.
+type StateBundle struct {
+	Version       int                `json:"version"`
+	Environment   string             `json:"environment,omitempty"`
+	ExportedAt    time.Time          `json:"exported_at"`
+	ROIBaselines  []ROIBaselineState `json:"roi_baselines"`
+	PriceVersions []PriceVersion     `json:"price_versions"`
+	SLO           *SLOConfigState    `json:"slo,omitempty"`
+	// 团队名 -> namespace、接收人与月度预算
+	Teams map[string]TeamState `json:"teams,omitempty"`
+	// namespace -> 归属人，"*" 为兜底
+	Owners map[string][]string `json:"owners,omitempty"`
+}
+
+// StateChange is one difference between a bundle and the target environment.
+type StateChange struct {
+	// roi_baselines / price_versions / slo / teams / owners
+	Section string          `json:"section"`
+	Key     string          `json:"key"`
+	Action  string          `json:"action"`
+	Before  json.RawMessage `json:"before,omitempty"`
+	After   json.RawMessage `json:"after,omitempty"`
+	Detail  string          `json:"detail,omitempty"`
+}
+
+// StateImportResponse is the response of POST /api/v1/admin/state/import. Changes are applied
+// unless DryRun; ConfigChanges are never applied and must be made in the target's configuration.
+type StateImportResponse struct {
+	DryRun        bool          `json:"dry_run"`
+	Changes       []StateChange `json:"changes"`
+	ConfigChanges []StateChange `json:"config_changes"`
+	// action -> count over stored sections
+	Summary map[string]int `json:"summary"`
+}
+
+// SuspectSnapshot is a snapshot flagged by the rate-of-change guardrail.
+type SuspectSnapshot struct {
+	ID                   string     `json:"id"`
+	CalculationID        string     `json:"calculation_id,omitempty"`
+	Timestamp            time.Time  `json:"timestamp"`
+	TotalBillableCost    float64    `json:"total_billable_cost"`
+	Reason               string     `json:"reason"`
+	RequiresConfirmation bool       `json:"requires_confirmation"`
+	ConfirmedBy          string     `json:"confirmed_by,omitempty"`
+	ConfirmedAt          *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// SuspectSnapshotListResponse is the response of GET /api/v1/snapshots/suspect.
+type SuspectSnapshotListResponse struct {
+	Snapshots []SuspectSnapshot `json:"snapshots"`
+	Total     int               `json:"total"`
+}
+
+// TeamState is a team of the weekly digest: its namespaces, recipients and monthly budget.
+type TeamState struct {
+	Namespaces    []string `json:"namespaces"`
+	Recipients    []string `json:"recipients,omitempty"`
+	MonthlyBudget float64  `json:"monthly_budget,omitempty"`
+}
+
+// TimelineEvent is one entry of the cluster events timeline.
+type TimelineEvent struct {
+	Kind string    `json:"kind"`
+	Time time.Time `json:"time"`
+	// EndTime 持续性事件的结束时间（K8s 事件最后一次出现、计算执行完成、SLO
+	// 恢复）；进行中或瞬时事件为空
+	EndTime *time.Time `json:"end_time,omitempty"`
+	// Namespace 为空表示集群级事件（计算执行、单价变更、集群级配置变更）
+	Namespace string `json:"namespace,omitempty"`
+	// info / warning / critical
+	Severity string `json:"severity"`
+	Title    string `json:"title"`
+	Summary  string `json:"summary,omitempty"`
+	// Object the event is about, "Kind/name", e.g. "Deployment/checkout" or "Service/payment-api".
+	Object string `json:"object,omitempty"`
+	// Ref is the ID of the record in its source, e.g. the calculation run or price version ID.
+	Ref string `json:"ref,omitempty"`
+}
+
+// TimelineResponse is the response of GET /api/v1/timeline: the events of all sources in the
+// window, oldest first.
+type TimelineResponse struct {
+	Namespace string          `json:"namespace,omitempty"`
+	StartTime time.Time       `json:"start_time"`
+	EndTime   time.Time       `json:"end_time"`
+	Events    []TimelineEvent `json:"events"`
+	Total     int             `json:"total"`
+	// Truncated is set when only the latest limit events are returned.
+	Truncated bool `json:"truncated"`
+	// Unavailable lists the kinds whose source failed; the timeline holds the other kinds.
+	Unavailable []string `json:"unavailable"`
+}
+
+// TriggerCalculationRequest is the body of POST /api/v1/calculations/runs (manual run).
+type TriggerCalculationRequest struct {
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+}
+
+// UnrealizedRecommendation is an issued recommendation not (fully) applied yet.
+type UnrealizedRecommendation struct {
+	Team                     string    `json:"team"`
+	Namespace                string    `json:"namespace"`
+	Workload                 string    `json:"workload"`
+	Action                   string    `json:"action"`
+	Resource                 string    `json:"resource"`
+	RecommendedRequest       float64   `json:"recommended_request"`
+	CurrentRequest           float64   `json:"current_request"`
+	Status                   string    `json:"status"`
+	IssuedAt                 time.Time `json:"issued_at"`
+	UnrealizedMonthlySavings float64   `json:"unrealized_monthly_savings"`
+}
+
+// UpdatePreferencesRequest is the body of PUT /api/v1/preferences. Omitted fields keep their value;
+// saved views are managed with /api/v1/preferences/views.
+type UpdatePreferencesRequest struct {
+	DefaultNamespaces []string           `json:"default_namespaces"`
+	Currency          *string            `json:"currency"`
+	Units             *DisplayUnits      `json:"units"`
+	FavoriteWorkloads []FavoriteWorkload `json:"favorite_workloads"`
+}
+
+// UpdateServiceAccountRequest is the body of PUT /api/v1/admin/service-accounts/:id.
+type UpdateServiceAccountRequest struct {
+	Description string `json:"description"`
+	Disabled    bool   `json:"disabled"`
+}
+
+// UpstreamService is a service depending on the degraded workload.
+type UpstreamService struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	// Depth is the number of calls between the service and the workload; 1 calls it directly.
+	Depth int `json:"depth"`
+	// Via is the service it calls on the shortest path to the workload ("namespace/service").
+	Via         string  `json:"via"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+// UserPreferences are the cockpit settings of one user, returned by GET /api/v1/preferences.
+type UserPreferences struct {
+	// User is the identity the preferences are stored under (service account, or the user forwarded by
+	// the auth proxy).
+	User string `json:"user"`
+	// DefaultNamespaces preselects the namespace filter of the cockpit; empty means all.
+	DefaultNamespaces []string `json:"default_namespaces"`
+	// ISO 4217 code, e.g. "CNY"
+	Currency          string             `json:"currency"`
+	Units             DisplayUnits       `json:"units"`
+	FavoriteWorkloads []FavoriteWorkload `json:"favorite_workloads"`
+	SavedViews        []SavedView        `json:"saved_views"`
+	// UpdatedAt is empty while the user has not saved any preference (defaults are returned).
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// WorkloadCost represents cost for a specific workload.
+type WorkloadCost struct {
+	Name string `json:"name"`
+	// Deployment, StatefulSet, etc.
+	Type string `json:"type"`
+	// billable cost of the window
+	Cost      float64 `json:"cost"`
+	WasteCost float64 `json:"waste_cost"`
+	// usage / billable, %
+	Efficiency float64           `json:"efficiency"`
+	PodCount   int               `json:"pod_count"`
+	Grade      string            `json:"grade"`
+	Namespace  string            `json:"namespace"`
+	Metadata   *ResourceMetadata `json:"metadata,omitempty"`
+}
+
+// WorkloadCostDetailResponse is the response of GET /api/v1/workloads/:namespace/:name/costs:
+// everything the workload detail page needs in one payload.
+type WorkloadCostDetailResponse struct {
+	Namespace   string        `json:"namespace"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	WindowStart time.Time     `json:"window_start"`
+	WindowEnd   time.Time     `json:"window_end"`
+	Cost        CostBreakdown `json:"cost"`
+	Grade       string        `json:"grade"`
+	CostPolicy  *CostPolicy   `json:"cost_policy,omitempty"`
+	// Series 按小时的 billable（Cost）/ usage / waste 成本
+	Series          []GranularCostDataPoint `json:"series"`
+	Resources       WorkloadResourceUsage   `json:"resources"`
+	GradeHistory    []GradeChangeEvent      `json:"grade_history"`
+	Recommendations []Recommendation        `json:"recommendations"`
+	// SuppressedRecommendations 因 CPU 限流或 SLO 风险被安全检查抑制的缩容建议（含
+	// safety 说明）；未配置安全检查时为空
+	SuppressedRecommendations []Recommendation        `json:"suppressed_recommendations,omitempty"`
+	ReliabilityTax            *WorkloadReliabilityTax `json:"reliability_tax,omitempty"`
+	Timestamp                 time.Time               `json:"timestamp"`
+}
+
+// WorkloadHistoryPoint is the cost of a workload on one day; Efficiency is usage/billable in % and
+// Grade is graded from it with the workload's thresholds (empty without billable cost).
+type WorkloadHistoryPoint struct {
+	Date       time.Time `json:"date"`
+	Billable   float64   `json:"billable"`
+	Usage      float64   `json:"usage"`
+	Waste      float64   `json:"waste"`
+	Efficiency float64   `json:"efficiency"`
+	Grade      string    `json:"grade,omitempty"`
+}
+
+// WorkloadHistoryResponse is the response of GET /api/v1/workloads/:namespace/:name/history: one
+// point per UTC day with data, oldest first, read from the daily rollups (cost_daily_workload) and
+// the hourly stats of the days not yet downsampled.
+type WorkloadHistoryResponse struct {
+	Namespace string                 `json:"namespace"`
+	Name      string                 `json:"name"`
+	Days      int                    `json:"days"`
+	Start     time.Time              `json:"start"`
+	End       time.Time              `json:"end"`
+	Points    []WorkloadHistoryPoint `json:"points"`
+}
+
+// WorkloadReliabilityTax is the spend wasted on restarting pods in the window (see
+// costmodel.ComputeReliabilityTax), next to the SLO of the service named like the workload.
+type WorkloadReliabilityTax struct {
+	// cause (eviction, oom_kill, crash_loop) -> restarts in the window
+	Restarts   map[string]int `json:"restarts"`
+	WastedCost float64        `json:"wasted_cost"`
+	// wasted / billable cost in %
+	Share float64 `json:"share"`
+	// average billable cost of one pod-hour
+	PodHourlyCost float64 `json:"pod_hourly_cost"`
+	// SLOStatus and ErrorBudgetRemaining are absent when the workload has no SLO
+	SLOStatus            string   `json:"slo_status,omitempty"`
+	ErrorBudgetRemaining *float64 `json:"error_budget_remaining,omitempty"`
+}
+
+// WorkloadResourceUsage is the latest hour's requests vs P95 usage. Utilization is P95/request in
+// %.
+type WorkloadResourceUsage struct {
+	Timestamp      time.Time `json:"timestamp"`
+	CPURequest     float64   `json:"cpu_request"`
+	CPUUsageP95    float64   `json:"cpu_usage_p95"`
+	CPUUtilization float64   `json:"cpu_utilization"`
+	MemRequest     int64     `json:"mem_request"`
+	MemUsageP95    int64     `json:"mem_usage_p95"`
+	MemUtilization float64   `json:"mem_utilization"`
+}
+
+// BaselineSnapshot represents the Day 0 baseline snapshot for ROI tracking.
+type BaselineSnapshot struct {
+	// Snapshot metadata
+	SnapshotID string `json:"snapshot_id"`
+	// Resource utilization metrics
+	CPUUtilization float64 `json:"cpu_utilization"`
+	MemUtilization float64 `json:"mem_utilization"`
+	// Cost metrics
+	TotalWasteAmount  float64 `json:"total_waste_amount"`
+	TotalBillableCost float64 `json:"total_billable_cost"`
+	// Node metrics
+	NodeCount int `json:"node_count"`
+	// Zombie asset count
+	ZombieAssetCount int `json:"zombie_asset_count"`
+	// Snapshot timestamp
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DailyComparison represents the daily comparison against the baseline.
+type DailyComparison struct {
+	// Comparison metadata
+	Date time.Time `json:"date"`
+	// Baseline reference
+	BaselineID string `json:"baseline_id"`
+	// Current metrics
+	CurrentCPUUtilization    float64 `json:"current_cpu_utilization"`
+	CurrentMemUtilization    float64 `json:"current_mem_utilization"`
+	CurrentTotalWasteAmount  float64 `json:"current_total_waste_amount"`
+	CurrentTotalBillableCost float64 `json:"current_total_billable_cost"`
+	CurrentNodeCount         int     `json:"current_node_count"`
+	CurrentZombieAssetCount  int     `json:"current_zombie_asset_count"`
+	// Comparison results
+	CPUUtilizationImprovement float64 `json:"cpu_utilization_improvement"`
+	// percentage points
+	MemUtilizationImprovement float64 `json:"mem_utilization_improvement"`
+	WasteReductionAmount      float64 `json:"waste_reduction_amount"`
+	CostSavingsAmount         float64 `json:"cost_savings_amount"`
+	NodeReductionCount        int     `json:"node_reduction_count"`
+	ZombieCleanupCount        int     `json:"zombie_cleanup_count"`
+	// Efficiency gains
+	ResourceRecoveryRate float64 `json:"resource_recovery_rate"`
+}
+
+// OptimizationTrackingRecord tracks individual optimization actions.
+type OptimizationTrackingRecord struct {
+	// Record identifier
+	RecordID string `json:"record_id"`
+	// Optimization type
+	OptimizationType string `json:"optimization_type"`
+	// Target resource
+	TargetResourceID string `json:"target_resource_id"`
+	// "pod", "namespace", "node", "storage_class"
+	TargetResourceType string `json:"target_resource_type"`
+	// Origin: the issued recommendation and the manifest bundle it was exported in (empty when
+	// recorded by hand)
+	RecommendationID string `json:"recommendation_id,omitempty"`
+	BundleID         string `json:"bundle_id,omitempty"`
+	// Before and after state
+	BeforeState map[string]json.RawMessage `json:"before_state"`
+	AfterState  map[string]json.RawMessage `json:"after_state"`
+	// Savings achieved
+	ImmediateSavings float64 `json:"immediate_savings"`
+	// Projected annual savings
+	ProjectedSavings float64 `json:"projected_savings"`
+	// Resources recovered (CPU, Memory, etc.)
+	ResourcesRecovered map[string]float64 `json:"resources_recovered"`
+	// Implementation details
+	ImplementationDate time.Time `json:"implementation_date"`
+	ImplementedBy      string    `json:"implemented_by,omitempty"`
+	// "low", "medium", "high"
+	ImplementationEffort string `json:"implementation_effort,omitempty"`
+	// Verification
+	Verified         bool      `json:"verified"`
+	VerificationDate time.Time `json:"verification_date,omitempty"`
+	VerifiedBy       string    `json:"verified_by,omitempty"`
+	// Impact assessment
+	RiskLevel string `json:"risk_level,omitempty"`
+	// "positive", "neutral", "negative"
+	BusinessImpact string `json:"business_impact,omitempty"`
+	// "improved", "neutral", "degraded"
+	PerformanceImpact string `json:"performance_impact,omitempty"`
+}
@@ -13,7 +13,11 @@ import (
 //
 // Input: []DailyNamespaceCost (data from daily_namespace_costs table)
 // Output: GlobalAggregatedResult with total billable cost, total waste, and global efficiency
-func AggregateGlobal(costs []DailyNamespaceCost) (GlobalAggregatedResult, error) {
+func AggregateGlobal(costs []DailyNamespaceCost, opts ...AggregateOption) (GlobalAggregatedResult, error) {
+	costs, err := validated(costs, opts, sanitizeCost, validateCostInput)
+	if err != nil {
+		return GlobalAggregatedResult{}, err
+	}
 	if len(costs) == 0 {
 		return GlobalAggregatedResult{
 			Timestamp: time.Now(),
@@ -28,11 +32,8 @@ func AggregateGlobal(costs []DailyNamespaceCost) (GlobalAggregatedResult, error)
 		totalWaste += cost.WasteCost
 	}
 
-	// Calculate global efficiency: (total usage / total billable) * 100%
-	var globalEfficiency float64
-	if totalBillable > 0 {
-		globalEfficiency = (totalUsage / totalBillable) * 100.0
-	}
+	// Calculate global efficiency: (total usage / total billable) * 100%, usage capped at billable
+	globalEfficiency := calculateEfficiencyScore(totalBillable, totalUsage)
 
 	// Round to 2 decimal places for financial precision
 	totalBillable = roundFinancial(totalBillable)
@@ -52,7 +53,11 @@ func AggregateGlobal(costs []DailyNamespaceCost) (GlobalAggregatedResult, error)
 //
 // Input: []DailyNamespaceCost (data from daily_namespace_costs table)
 // Output: []DomainBreakdownItem with cost percentages for each namespace
-func CalculateDomainBreakdown(costs []DailyNamespaceCost) ([]DomainBreakdownItem, error) {
+func CalculateDomainBreakdown(costs []DailyNamespaceCost, opts ...AggregateOption) ([]DomainBreakdownItem, error) {
+	costs, err := validated(costs, opts, sanitizeCost, validateCostInput)
+	if err != nil {
+		return nil, err
+	}
 	if len(costs) == 0 {
 		return []DomainBreakdownItem{}, nil
 	}
@@ -106,9 +111,12 @@ func CalculateDomainBreakdown(costs []DailyNamespaceCost) ([]DomainBreakdownItem
 		})
 	}
 
-	// Sort by cost percentage descending
+	// Sort by cost percentage descending; ties by name so the order does not depend on map iteration
 	sort.Slice(breakdown, func(i, j int) bool {
-		return breakdown[i].CostPercentage > breakdown[j].CostPercentage
+		if breakdown[i].CostPercentage != breakdown[j].CostPercentage {
+			return breakdown[i].CostPercentage > breakdown[j].CostPercentage
+		}
+		return breakdown[i].DomainName < breakdown[j].DomainName
 	})
 
 	return breakdown, nil
@@ -118,7 +126,11 @@ func CalculateDomainBreakdown(costs []DailyNamespaceCost) ([]DomainBreakdownItem
 //
 // Input: []HourlyWorkloadStat (data from hourly_workload_stats table)
 // Output: map[string]AggregatedResult keyed by namespace name
-func AggregateByNamespace(stats []HourlyWorkloadStat) (map[string]AggregatedResult, error) {
+func AggregateByNamespace(stats []HourlyWorkloadStat, opts ...AggregateOption) (map[string]AggregatedResult, error) {
+	stats, err := validated(stats, opts, sanitizeWorkloadStat, validateWorkloadStatInput)
+	if err != nil {
+		return nil, err
+	}
 	if len(stats) == 0 {
 		return make(map[string]AggregatedResult), nil
 	}
@@ -206,11 +218,66 @@ func AggregateByNode(costs []CostResult, nodeNames []string) (map[string]Aggrega
 	return result, nil
 }
 
+// AggregateByNodePool aggregates hourly workload stats by node pool. Stats without a pool are
+// grouped under UnassignedNodePool.
+//
+// Input: []HourlyWorkloadStat (data from hourly_workload_stats table)
+// Output: map[string]AggregatedResult keyed by node pool name
+func AggregateByNodePool(stats []HourlyWorkloadStat, opts ...AggregateOption) (map[string]AggregatedResult, error) {
+	stats, err := validated(stats, opts, sanitizeWorkloadStat, validateWorkloadStatInput)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return make(map[string]AggregatedResult), nil
+	}
+
+	poolAggregates := make(map[string]*aggregateData)
+
+	for _, stat := range stats {
+		pool := stat.NodePool
+		if pool == "" {
+			pool = UnassignedNodePool
+		}
+		if _, exists := poolAggregates[pool]; !exists {
+			poolAggregates[pool] = &aggregateData{}
+		}
+
+		agg := poolAggregates[pool]
+		agg.totalBillable += stat.TotalBillableCost
+		agg.totalUsage += stat.TotalUsageCost
+		agg.totalWaste += stat.TotalWasteCost
+		agg.resourceCount++
+	}
+
+	result := make(map[string]AggregatedResult)
+
+	for pool, agg := range poolAggregates {
+		efficiencyScore := calculateEfficiencyScore(agg.totalBillable, agg.totalUsage)
+
+		result[pool] = AggregatedResult{
+			Identifier:        pool,
+			TotalBillableCost: roundFinancial(agg.totalBillable),
+			TotalUsageCost:    roundFinancial(agg.totalUsage),
+			TotalWasteCost:    roundFinancial(agg.totalWaste),
+			EfficiencyScore:   roundPercentage(efficiencyScore),
+			ResourceCount:     agg.resourceCount,
+			Timestamp:         time.Now(),
+		}
+	}
+
+	return result, nil
+}
+
 // AggregateByWorkload aggregates hourly workload stats by workload (L3).
 //
 // Input: []HourlyWorkloadStat (data from hourly_workload_stats table)
 // Output: map[string]AggregatedResult keyed by workload identifier (namespace/workloadName)
-func AggregateByWorkload(stats []HourlyWorkloadStat) (map[string]AggregatedResult, error) {
+func AggregateByWorkload(stats []HourlyWorkloadStat, opts ...AggregateOption) (map[string]AggregatedResult, error) {
+	stats, err := validated(stats, opts, sanitizeWorkloadStat, validateWorkloadStatInput)
+	if err != nil {
+		return nil, err
+	}
 	if len(stats) == 0 {
 		return make(map[string]AggregatedResult), nil
 	}
@@ -228,6 +295,8 @@ func AggregateByWorkload(stats []HourlyWorkloadStat) (map[string]AggregatedResul
 		agg.totalUsage += stat.TotalUsageCost
 		agg.totalWaste += stat.TotalWasteCost
 		agg.resourceCount++
+		agg.cpuSketches = append(agg.cpuSketches, stat.CPUUsageSketch)
+		agg.memSketches = append(agg.memSketches, stat.MemUsageSketch)
 	}
 
 	// Convert to AggregatedResult map
@@ -236,7 +305,7 @@ func AggregateByWorkload(stats []HourlyWorkloadStat) (map[string]AggregatedResul
 	for workloadID, agg := range workloadAggregates {
 		efficiencyScore := calculateEfficiencyScore(agg.totalBillable, agg.totalUsage)
 
-		result[workloadID] = AggregatedResult{
+		aggregated := AggregatedResult{
 			Identifier:        workloadID,
 			TotalBillableCost: roundFinancial(agg.totalBillable),
 			TotalUsageCost:    roundFinancial(agg.totalUsage),
@@ -245,6 +314,15 @@ func AggregateByWorkload(stats []HourlyWorkloadStat) (map[string]AggregatedResul
 			ResourceCount:     agg.resourceCount,
 			Timestamp:         time.Now(),
 		}
+		// P95 over the whole window comes from the merged hourly sketches; averaging hourly P95s
+		// understates bursty workloads. Left 0 when any hour was recorded without a sketch.
+		if merged, ok := MergeSketches(agg.cpuSketches); ok {
+			aggregated.CPUUsageP95 = merged.Quantile(0.95)
+		}
+		if merged, ok := MergeSketches(agg.memSketches); ok {
+			aggregated.MemUsageP95 = merged.Quantile(0.95)
+		}
+		result[workloadID] = aggregated
 	}
 
 	return result, nil
@@ -306,6 +384,8 @@ type aggregateData struct {
 	totalUsage    float64
 	totalWaste    float64
 	resourceCount int
+	cpuSketches   []*UsageSketch
+	memSketches   []*UsageSketch
 }
 
 // calculateEfficiencyScore calculates efficiency score from billable and usage costs
@@ -323,52 +403,14 @@ func calculateEfficiencyScore(billable, usage float64) float64 {
 	return (usage / billable) * 100.0
 }
 
-// roundFinancial rounds a float64 to financial precision (2 decimal places)
+// roundFinancial rounds a float64 to financial precision (2 decimal places). NaN/Inf are returned
+// as is: inputs are sanitized at ingestion and by WithNonFinitePolicy, so a non-finite result must
+// surface instead of being reported as a zero cost.
 func roundFinancial(value float64) float64 {
-	if math.IsNaN(value) || math.IsInf(value, 0) {
-		return 0.0
-	}
-
 	return math.Round(value*100) / 100
 }
 
-// roundPercentage rounds a percentage value (2 decimal places)
+// roundPercentage rounds a percentage value (2 decimal places); NaN/Inf are returned as is, see roundFinancial.
 func roundPercentage(value float64) float64 {
-	if math.IsNaN(value) || math.IsInf(value, 0) {
-		return 0.0
-	}
-
 	return math.Round(value*100) / 100
 }
-
-// validateCostInput validates cost inputs for negative values
-func validateCostInput(costs []DailyNamespaceCost) error {
-	for _, cost := range costs {
-		if cost.BillableCost < 0 {
-			return errors.New("billable cost cannot be negative")
-		}
-		if cost.UsageCost < 0 {
-			return errors.New("usage cost cannot be negative")
-		}
-		if cost.WasteCost < 0 {
-			return errors.New("waste cost cannot be negative")
-		}
-	}
-	return nil
-}
-
-// validateWorkloadStatInput validates workload stat inputs for negative values
-func validateWorkloadStatInput(stats []HourlyWorkloadStat) error {
-	for _, stat := range stats {
-		if stat.TotalBillableCost < 0 {
-			return errors.New("total billable cost cannot be negative")
-		}
-		if stat.TotalUsageCost < 0 {
-			return errors.New("total usage cost cannot be negative")
-		}
-		if stat.TotalWasteCost < 0 {
-			return errors.New("total waste cost cannot be negative")
-		}
-	}
-	return nil
-}
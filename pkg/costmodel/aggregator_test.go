@@ -135,13 +135,7 @@ func TestAggregateGlobal(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Skip validation error tests for AggregateGlobal (it doesn't validate)
-			if tt.wantErr && tt.name == "negative costs should be rejected (test via validation)" {
-				// AggregateGlobal doesn't validate, so this test would pass
-				return
-			}
-
-			got, err := AggregateGlobal(tt.costs)
+			got, err := AggregateGlobal(tt.costs, WithValidation(ValidationReject, nil))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AggregateGlobal() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -732,6 +726,118 @@ func TestAggregateByWorkload(t *testing.T) {
 	}
 }
 
+// TestAggregateByNodePool tests node pool aggregation; stats without a pool fall under UnassignedNodePool
+func TestAggregateByNodePool(t *testing.T) {
+	now := time.Now()
+	stats := []HourlyWorkloadStat{
+		{Namespace: "ns1", WorkloadName: "api", NodePool: "general", Timestamp: now, TotalBillableCost: 60, TotalUsageCost: 30, TotalWasteCost: 30},
+		{Namespace: "ns2", WorkloadName: "web", NodePool: "general", Timestamp: now, TotalBillableCost: 40, TotalUsageCost: 30, TotalWasteCost: 10},
+		{Namespace: "ns1", WorkloadName: "train", NodePool: "gpu", Timestamp: now, TotalBillableCost: 100, TotalUsageCost: 90, TotalWasteCost: 10},
+		{Namespace: "ns3", WorkloadName: "legacy", Timestamp: now, TotalBillableCost: 10, TotalUsageCost: 5, TotalWasteCost: 5},
+	}
+	result, err := AggregateByNodePool(stats)
+	if err != nil {
+		t.Fatalf("AggregateByNodePool() error = %v", err)
+	}
+	if len(result) != 3 {
+		t.Fatalf("expected 3 pools, got %d", len(result))
+	}
+	general := result["general"]
+	if general.TotalBillableCost != 100 || general.TotalWasteCost != 40 || general.ResourceCount != 2 {
+		t.Errorf("general: got %+v", general)
+	}
+	if math.Abs(general.EfficiencyScore-60.0) > 0.1 { // (60/100)*100 = 60%
+		t.Errorf("general: expected efficiency 60%%, got %v", general.EfficiencyScore)
+	}
+	if gpu := result["gpu"]; math.Abs(gpu.EfficiencyScore-90.0) > 0.1 {
+		t.Errorf("gpu: expected efficiency 90%%, got %v", gpu.EfficiencyScore)
+	}
+	if unassigned, ok := result[UnassignedNodePool]; !ok || unassigned.TotalBillableCost != 10 {
+		t.Errorf("stats without a pool: got %+v (present %v), want billable 10 under %q", unassigned, ok, UnassignedNodePool)
+	}
+
+	empty, err := AggregateByNodePool(nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("AggregateByNodePool(nil) = %v, %v; want empty map", empty, err)
+	}
+}
+
+func TestNodePoolOf(t *testing.T) {
+	labels := map[string]string{"node-type": "compute", "karpenter.sh/nodepool": "spot"}
+	if got := NodePoolOf(labels, nil); got != "spot" {
+		t.Errorf("NodePoolOf(default labels) = %q, want spot (managed pool label first)", got)
+	}
+	if got := NodePoolOf(labels, []string{"node-type"}); got != "compute" {
+		t.Errorf("NodePoolOf(node-type) = %q, want compute", got)
+	}
+	if got := NodePoolOf(map[string]string{"app": "x"}, nil); got != DefaultNodePool {
+		t.Errorf("NodePoolOf(unlabeled) = %q, want %q", got, DefaultNodePool)
+	}
+
+	poolOf := map[string]string{"n1": "a", "n2": "b", "n3": "b"}
+	if got := MajorityNodePool([]string{"n1", "n2", "n3", "gone"}, poolOf); got != "b" {
+		t.Errorf("MajorityNodePool = %q, want b", got)
+	}
+	if got := MajorityNodePool([]string{"n1", "n2"}, poolOf); got != "a" {
+		t.Errorf("MajorityNodePool(tie) = %q, want a", got)
+	}
+	if got := MajorityNodePool([]string{"gone"}, poolOf); got != "" {
+		t.Errorf("MajorityNodePool(unknown nodes) = %q, want empty", got)
+	}
+}
+
+// TestAggregateByWorkload_MergedPercentile checks that the window P95 comes from the merged hourly
+// sketches: one busy hour among quiet ones dominates the true P95, which averaging hourly P95s hides.
+func TestAggregateByWorkload_MergedPercentile(t *testing.T) {
+	var quietSamples, busySamples []float64
+	for i := 0; i < 60; i++ {
+		quietSamples = append(quietSamples, 0.2+0.001*float64(i))
+		busySamples = append(busySamples, 4+0.01*float64(i))
+	}
+	var stats []HourlyWorkloadStat
+	var cpuSamples []float64
+	for h := 0; h < 10; h++ {
+		samples := quietSamples
+		if h >= 8 {
+			samples = busySamples
+		}
+		cpuSamples = append(cpuSamples, samples...)
+		stats = append(stats, HourlyWorkloadStat{
+			Namespace:      "prod",
+			WorkloadName:   "api",
+			CPUUsageP95:    exactQuantile(samples, 0.95),
+			CPUUsageSketch: SketchOf(samples...),
+			MemUsageSketch: SketchOf(1 << 30),
+		})
+	}
+
+	result, err := AggregateByWorkload(stats)
+	if err != nil {
+		t.Fatalf("AggregateByWorkload: %v", err)
+	}
+	got := result["prod/api"]
+	want := exactQuantile(cpuSamples, 0.95)
+	if math.Abs(got.CPUUsageP95-want) > want*DefaultSketchAccuracy {
+		t.Errorf("CPUUsageP95 = %v, want %v", got.CPUUsageP95, want)
+	}
+	var avg float64
+	for _, st := range stats {
+		avg += st.CPUUsageP95 / float64(len(stats))
+	}
+	if got.CPUUsageP95 < 2*avg {
+		t.Errorf("CPUUsageP95 = %v should be far above the average of hourly P95s %v", got.CPUUsageP95, avg)
+	}
+	if math.Abs(got.MemUsageP95-(1<<30)) > (1<<30)*DefaultSketchAccuracy {
+		t.Errorf("MemUsageP95 = %v, want 1GiB", got.MemUsageP95)
+	}
+
+	stats[0].CPUUsageSketch = nil
+	result, _ = AggregateByWorkload(stats)
+	if p := result["prod/api"].CPUUsageP95; p != 0 {
+		t.Errorf("CPUUsageP95 with an hour missing its sketch = %v, want 0", p)
+	}
+}
+
 // TestAggregateByPod tests L4 pod aggregation
 func TestAggregateByPod(t *testing.T) {
 	tests := []struct {
@@ -876,13 +982,17 @@ func TestHelperFunctions(t *testing.T) {
 			{123.454, 123.45},
 			{0.0, 0.0},
 			{-123.456, -123.46},
-			{math.NaN(), 0.0},
-			{math.Inf(1), 0.0},
+			{math.NaN(), math.NaN()},
+			{math.Inf(1), math.Inf(1)},
 		}
 
 		for _, tt := range tests {
 			got := roundFinancial(tt.input)
-			if math.IsNaN(tt.expected) {
+			if math.IsInf(tt.expected, 0) {
+				if got != tt.expected {
+					t.Errorf("roundFinancial(%v) = %v, want %v", tt.input, got, tt.expected)
+				}
+			} else if math.IsNaN(tt.expected) {
 				if !math.IsNaN(got) {
 					t.Errorf("roundFinancial(%v) = %v, want NaN", tt.input, got)
 				}
@@ -0,0 +1,85 @@
+// Package costmodel autoscaler.go: a simple cluster autoscaler model bin-packing pod requests onto
+// node templates, so what-if request changes translate into node counts rather than request deltas.
+package costmodel
+
+import "sort"
+
+// NodeTemplate is the allocatable capacity (cores / bytes) of the nodes the autoscaler adds to a pool.
+type NodeTemplate struct {
+	CPU float64
+	Mem float64
+}
+
+// PodRequest is the CPU (cores) and memory (bytes) requests of one pod.
+type PodRequest struct {
+	CPU float64
+	Mem float64
+}
+
+// AutoscalerModel packs pods onto nodes of a template the way a cluster autoscaler sizes a pool.
+// Headroom rules: a node is filled up to TargetUtilization of its allocatable (0 or >1: 1) and a
+// pool with pods keeps at least MinNodes nodes.
+type AutoscalerModel struct {
+	TargetUtilization float64
+	MinNodes          int
+}
+
+// ScalePodRequests returns the pods with their requests multiplied by factor (e.g. 0.8 for "20% less").
+func ScalePodRequests(pods []PodRequest, factor float64) []PodRequest {
+	out := make([]PodRequest, len(pods))
+	for i, p := range pods {
+		out[i] = PodRequest{CPU: p.CPU * factor, Mem: p.Mem * factor}
+	}
+	return out
+}
+
+// Nodes returns the nodes of template t needed by pods, packed first-fit decreasing by their
+// dominant share of the node. A pod above the headroom of an empty node gets a node of its own
+// (it runs today, so the node is assumed to fit it) and is counted in oversized.
+func (m AutoscalerModel) Nodes(t NodeTemplate, pods []PodRequest) (nodes, oversized int) {
+	if len(pods) == 0 {
+		return 0, 0
+	}
+	target := m.TargetUtilization
+	if target <= 0 || target > 1 {
+		target = 1
+	}
+	capCPU, capMem := t.CPU*target, t.Mem*target
+	share := func(p PodRequest) float64 {
+		return max(ratio(p.CPU, t.CPU), ratio(p.Mem, t.Mem))
+	}
+	sorted := append([]PodRequest(nil), pods...)
+	sort.SliceStable(sorted, func(i, j int) bool { return share(sorted[i]) > share(sorted[j]) })
+
+	// 每个节点剩余的可用容量（已扣除余量）
+	type free struct{ cpu, mem float64 }
+	var bins []free
+	for _, p := range sorted {
+		if p.CPU > capCPU || p.Mem > capMem {
+			oversized++
+			nodes++
+			continue
+		}
+		placed := false
+		for i := range bins {
+			if bins[i].cpu >= p.CPU && bins[i].mem >= p.Mem {
+				bins[i].cpu -= p.CPU
+				bins[i].mem -= p.Mem
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			bins = append(bins, free{cpu: capCPU - p.CPU, mem: capMem - p.Mem})
+		}
+	}
+	nodes += len(bins)
+	return max(nodes, m.MinNodes), oversized
+}
+
+func ratio(v, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return v / total
+}
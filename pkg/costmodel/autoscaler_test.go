@@ -0,0 +1,36 @@
+package costmodel
+
+import "testing"
+
+func TestAutoscalerModelNodes(t *testing.T) {
+	const gib = 1 << 30
+	node := NodeTemplate{CPU: 4, Mem: 16 * gib}
+	pods := make([]PodRequest, 10)
+	for i := range pods {
+		pods[i] = PodRequest{CPU: 1, Mem: 2 * gib}
+	}
+	m := AutoscalerModel{TargetUtilization: 0.85}
+
+	// 4*0.85=3.4 核，每节点最多 3 个 1 核 pod
+	if n, over := m.Nodes(node, pods); n != 4 || over != 0 {
+		t.Errorf("baseline: got %d nodes (%d oversized), want 4", n, over)
+	}
+	// 请求减少 20%：0.8 核，每节点 4 个
+	if n, _ := m.Nodes(node, ScalePodRequests(pods, 0.8)); n != 3 {
+		t.Errorf("20%% less: got %d nodes, want 3", n)
+	}
+	// 内存成为主导维度
+	memHeavy := []PodRequest{{CPU: 0.1, Mem: 10 * gib}, {CPU: 0.1, Mem: 10 * gib}, {CPU: 0.1, Mem: 3 * gib}}
+	if n, _ := m.Nodes(node, memHeavy); n != 2 {
+		t.Errorf("memory bound: got %d nodes, want 2", n)
+	}
+	if n, over := m.Nodes(node, []PodRequest{{CPU: 3.8, Mem: gib}, {CPU: 1, Mem: gib}}); n != 2 || over != 1 {
+		t.Errorf("oversized pod: got %d nodes (%d oversized), want 2 (1)", n, over)
+	}
+	if n, _ := (AutoscalerModel{MinNodes: 2}).Nodes(node, pods[:1]); n != 2 {
+		t.Errorf("min nodes: got %d, want 2", n)
+	}
+	if n, _ := m.Nodes(node, nil); n != 0 {
+		t.Errorf("no pods: got %d nodes, want 0", n)
+	}
+}
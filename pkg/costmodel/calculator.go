@@ -3,6 +3,7 @@ package costmodel
 
 import (
 	"errors"
+	"fmt"
 	"math"
 )
 
@@ -72,6 +73,16 @@ func CalculateCost(rm ResourceMetric, corePrice, memPrice float64) (CostResult,
 
 // validateInputs validates the input parameters.
 func validateInputs(rm ResourceMetric, corePrice, memPrice float64) error {
+	// NaN/Inf 比较结果恒为 false，必须在符号检查之前显式拒绝
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{{"CPU request", rm.CPURequest}, {"CPU usage", rm.CPUUsageP95}, {"CPU price", corePrice}, {"memory price", memPrice}} {
+		if !isFinite(f.value) {
+			return fmt.Errorf("%w: %s is %v", ErrNonFinite, f.name, f.value)
+		}
+	}
+
 	// Validate resource metrics
 	if rm.CPURequest < 0 {
 		return errors.New("CPU request cannot be negative")
@@ -186,25 +197,23 @@ func calcOverallEfficiencyScore(cpuScore, memScore, cpuBillable, memBillable flo
 // Special case: When score is 100% and there are no resource requests,
 // it should be considered Healthy.
 func gradeByScore(score float64) EfficiencyGrade {
-	// Handle special case: 100% efficiency (usually means no request)
-	if score == 100.0 {
-		return GradeHealthy
-	}
-
-	switch {
-	case score < 10.0:
-		return GradeZombie
-	case score < 40.0:
-		return GradeOverProvisioned
-	case score >= 40.0 && score <= 70.0:
-		return GradeHealthy
-	case score > 90.0:
-		return GradeRisk
-	default:
-		// For scores between 70% and 90%, we consider them Healthy
-		// as they're within reasonable utilization range
-		return GradeHealthy
-	}
+	return DefaultGradeThresholds.Grade(score)
+}
+
+// GradeByScore returns the efficiency grade of an efficiency score (0-100).
+func GradeByScore(score float64) EfficiencyGrade {
+	return gradeByScore(score)
+}
+
+// DefaultGradeHysteresis is the default score margin (percentage points) for GradeWithHysteresis.
+const DefaultGradeHysteresis = 5.0
+
+// GradeWithHysteresis grades score like gradeByScore, but keeps the previous grade as long as the
+// score stays inside the previous grade's band widened by margin on both sides. A workload at 39-41%
+// therefore does not flap between OverProvisioned and Healthy every hour on noisy metrics.
+// An empty or unknown previous grade, or the 100% special case, yields the plain grade.
+func GradeWithHysteresis(score float64, previous EfficiencyGrade, margin float64) EfficiencyGrade {
+	return DefaultGradeThresholds.GradeWithHysteresis(score, previous, margin)
 }
 
 // roundToPrecision rounds a float64 value to the specified number of decimal places.
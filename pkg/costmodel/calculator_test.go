@@ -465,6 +465,36 @@ func TestGradeByScore(t *testing.T) {
 	}
 }
 
+// TestGradeWithHysteresis tests that grades only change once the score leaves the widened band.
+func TestGradeWithHysteresis(t *testing.T) {
+	testCases := []struct {
+		name     string
+		score    float64
+		previous EfficiencyGrade
+		expected EfficiencyGrade
+	}{
+		{"无历史等级: 直接评级", 38.0, "", GradeOverProvisioned},
+		{"Healthy 在边界内抖动", 38.0, GradeHealthy, GradeHealthy},
+		{"Healthy 超出边界", 34.0, GradeHealthy, GradeOverProvisioned},
+		{"OverProvisioned 在边界内抖动", 43.0, GradeOverProvisioned, GradeOverProvisioned},
+		{"OverProvisioned 回到健康", 46.0, GradeOverProvisioned, GradeHealthy},
+		{"Zombie 轻微回升", 12.0, GradeZombie, GradeZombie},
+		{"Risk 轻微回落", 88.0, GradeRisk, GradeRisk},
+		{"Healthy 进入 Risk", 96.0, GradeHealthy, GradeRisk},
+		{"满分特例不受历史影响", 100.0, GradeRisk, GradeHealthy},
+		{"未知历史等级", 38.0, GradeUnknown, GradeOverProvisioned},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := GradeWithHysteresis(tc.score, tc.previous, DefaultGradeHysteresis)
+			if result != tc.expected {
+				t.Errorf("GradeWithHysteresis(%.1f, %s) = %v, 期望 %v", tc.score, tc.previous, result, tc.expected)
+			}
+		})
+	}
+}
+
 // TestEfficiencyScoreFunctions tests the individual efficiency score calculation functions.
 func TestEfficiencyScoreFunctions(t *testing.T) {
 	t.Run("CPU效率分计算", func(t *testing.T) {
@@ -0,0 +1,53 @@
+// Package costmodel calendar.go: the accounting calendar — which instants belong to which
+// accounting day when the books are closed in a time zone other than UTC.
+package costmodel
+
+import (
+	"fmt"
+	"time"
+)
+
+// AccountingCalendar maps instants to accounting days of a time zone. A day is labelled like a DATE
+// column: its date at UTC midnight (e.g. 2026-03-01T00:00:00Z), whatever the zone; the day itself
+// covers local midnight to local midnight (23 or 25 hours on DST changes). The zero value is UTC.
+type AccountingCalendar struct {
+	Location *time.Location
+}
+
+// NewAccountingCalendar returns the calendar of an IANA time zone name ("" and "UTC" are UTC).
+func NewAccountingCalendar(zone string) (AccountingCalendar, error) {
+	if zone == "" {
+		return AccountingCalendar{}, nil
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return AccountingCalendar{}, fmt.Errorf("accounting time zone %q: %w", zone, err)
+	}
+	return AccountingCalendar{Location: loc}, nil
+}
+
+// String returns the name of the zone.
+func (c AccountingCalendar) String() string {
+	return c.location().String()
+}
+
+// Date returns the label of the accounting day t falls in.
+func (c AccountingCalendar) Date(t time.Time) time.Time {
+	y, m, d := t.In(c.location()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Bounds returns the instants [start, end) of the accounting day labelled date (only its UTC
+// year, month and day are used).
+func (c AccountingCalendar) Bounds(date time.Time) (start, end time.Time) {
+	y, m, d := date.UTC().Date()
+	loc := c.location()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc).UTC(), time.Date(y, m, d+1, 0, 0, 0, 0, loc).UTC()
+}
+
+func (c AccountingCalendar) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
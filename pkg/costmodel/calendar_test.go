@@ -0,0 +1,34 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccountingCalendar(t *testing.T) {
+	sh, err := NewAccountingCalendar("Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("NewAccountingCalendar: %v", err)
+	}
+	mar1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// 2026-02-28T17:00Z is 01:00 on Mar 1 in Shanghai
+	if got := sh.Date(time.Date(2026, 2, 28, 17, 0, 0, 0, time.UTC)); !got.Equal(mar1) {
+		t.Errorf("Date = %v, want %v", got, mar1)
+	}
+	if got := (AccountingCalendar{}).Date(time.Date(2026, 2, 28, 17, 0, 0, 0, time.UTC)); !got.Equal(mar1.AddDate(0, 0, -1)) {
+		t.Errorf("UTC Date = %v, want Feb 28", got)
+	}
+	start, end := sh.Bounds(mar1)
+	if !start.Equal(time.Date(2026, 2, 28, 16, 0, 0, 0, time.UTC)) || end.Sub(start) != 24*time.Hour {
+		t.Errorf("Bounds = %v..%v", start, end)
+	}
+
+	ny, _ := NewAccountingCalendar("America/New_York")
+	if start, end := ny.Bounds(time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)); end.Sub(start) != 23*time.Hour {
+		t.Errorf("DST day = %v, want 23h", end.Sub(start))
+	}
+	if _, err := NewAccountingCalendar("Mars/Olympus"); err == nil {
+		t.Error("unknown zone should be rejected")
+	}
+}
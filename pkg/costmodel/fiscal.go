@@ -0,0 +1,198 @@
+// Package costmodel fiscal.go: fiscal calendars — fiscal years that start in any month and
+// retail-style 4-4-5 week calendars — so "month" and "quarter" in reports and budgets follow the
+// company's books rather than the Gregorian calendar.
+package costmodel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FiscalPattern is how the periods (fiscal months) of a quarter are laid out.
+type FiscalPattern string
+
+const (
+	FiscalMonths FiscalPattern = "months" // calendar months from the fiscal year start (default)
+	Fiscal445    FiscalPattern = "4-4-5"  // periods of 4, 4 and 5 weeks
+	Fiscal454    FiscalPattern = "4-5-4"
+	Fiscal544    FiscalPattern = "5-4-4"
+)
+
+// FiscalUnit is the length of a fiscal span.
+type FiscalUnit string
+
+const (
+	FiscalUnitPeriod  FiscalUnit = "period" // a fiscal month
+	FiscalUnitQuarter FiscalUnit = "quarter"
+	FiscalUnitYear    FiscalUnit = "year"
+)
+
+// FiscalCalendar defines the fiscal year. With a week pattern the year starts on the WeekStart
+// day nearest to the 1st of StartMonth, so it has 52 or 53 whole weeks; the 53rd week is added
+// to the last period. The zero value is the Gregorian calendar.
+type FiscalCalendar struct {
+	StartMonth time.Month    // first month of the fiscal year; 0 = January
+	Pattern    FiscalPattern // "" = FiscalMonths
+	WeekStart  time.Weekday  // week patterns only
+}
+
+// FiscalSpan is a fiscal period, quarter or year. Start and End are day labels (see
+// AccountingCalendar), End is exclusive. Quarter is 0 for a year and Period is 0 for a quarter or
+// a year.
+type FiscalSpan struct {
+	Unit    FiscalUnit `json:"unit"`
+	Year    int        `json:"year"` // named after the calendar year the fiscal year ends in
+	Quarter int        `json:"quarter,omitempty"`
+	Period  int        `json:"period,omitempty"`
+	Start   time.Time  `json:"start"`
+	End     time.Time  `json:"end"`
+}
+
+// Label returns e.g. "FY2026", "FY2026 Q2" or "FY2026 P05".
+func (s FiscalSpan) Label() string {
+	switch s.Unit {
+	case FiscalUnitQuarter:
+		return fmt.Sprintf("FY%d Q%d", s.Year, s.Quarter)
+	case FiscalUnitPeriod:
+		return fmt.Sprintf("FY%d P%02d", s.Year, s.Period)
+	}
+	return fmt.Sprintf("FY%d", s.Year)
+}
+
+// Days returns the number of days of the span.
+func (s FiscalSpan) Days() int {
+	return int(s.End.Sub(s.Start) / (24 * time.Hour))
+}
+
+// NewFiscalCalendar returns the fiscal calendar starting in startMonth (1-12, 0 = January) with
+// pattern ("" = months) and weeks starting on weekStart (e.g. "sunday", "" = Sunday).
+func NewFiscalCalendar(startMonth int, pattern, weekStart string) (FiscalCalendar, error) {
+	f := FiscalCalendar{StartMonth: time.Month(startMonth), Pattern: FiscalPattern(pattern)}
+	if weekStart != "" {
+		f.WeekStart = -1
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(weekStart, d.String()) {
+				f.WeekStart = d
+			}
+		}
+	}
+	if err := f.Validate(); err != nil {
+		return FiscalCalendar{}, err
+	}
+	return f, nil
+}
+
+// Validate checks the start month and the pattern.
+func (f FiscalCalendar) Validate() error {
+	if f.StartMonth < 0 || f.StartMonth > 12 {
+		return fmt.Errorf("fiscal start month must be 1-12, got %d", f.StartMonth)
+	}
+	if _, ok := f.weeks(); !ok {
+		return fmt.Errorf("unknown fiscal pattern %q (months, 4-4-5, 4-5-4, 5-4-4)", f.Pattern)
+	}
+	if f.WeekStart < time.Sunday || f.WeekStart > time.Saturday {
+		return fmt.Errorf("invalid fiscal week start %d", f.WeekStart)
+	}
+	return nil
+}
+
+// Span returns the fiscal span of unit containing the day labelled date.
+func (f FiscalCalendar) Span(date time.Time, unit FiscalUnit) FiscalSpan {
+	date = date.UTC().Truncate(24 * time.Hour)
+	year := f.yearOf(date)
+	start, end := f.yearBounds(year)
+	if unit == FiscalUnitYear {
+		return FiscalSpan{Unit: unit, Year: year, Start: start, End: end}
+	}
+	var period FiscalSpan
+	for p := 1; p <= 12; p++ {
+		pStart, pEnd := f.periodBounds(start, end, p)
+		if date.Before(pEnd) {
+			period = FiscalSpan{Unit: FiscalUnitPeriod, Year: year, Quarter: (p-1)/3 + 1, Period: p, Start: pStart, End: pEnd}
+			break
+		}
+	}
+	if unit == FiscalUnitQuarter {
+		first := (period.Quarter-1)*3 + 1
+		qStart, _ := f.periodBounds(start, end, first)
+		_, qEnd := f.periodBounds(start, end, first+2)
+		return FiscalSpan{Unit: unit, Year: year, Quarter: period.Quarter, Start: qStart, End: qEnd}
+	}
+	return period
+}
+
+// weeks returns the weeks of the periods of a quarter; nil for calendar months.
+func (f FiscalCalendar) weeks() ([]int, bool) {
+	switch f.Pattern {
+	case "", FiscalMonths:
+		return nil, true
+	case Fiscal445:
+		return []int{4, 4, 5}, true
+	case Fiscal454:
+		return []int{4, 5, 4}, true
+	case Fiscal544:
+		return []int{5, 4, 4}, true
+	}
+	return nil, false
+}
+
+func (f FiscalCalendar) startMonth() time.Month {
+	if f.StartMonth == 0 {
+		return time.January
+	}
+	return f.StartMonth
+}
+
+// yearBounds returns the first day of the fiscal year and of the next one.
+func (f FiscalCalendar) yearBounds(year int) (start, end time.Time) {
+	return f.yearStart(year), f.yearStart(year + 1)
+}
+
+func (f FiscalCalendar) yearStart(year int) time.Time {
+	m := f.startMonth()
+	calendarYear := year
+	if m != time.January {
+		calendarYear-- // FY2027 starting in April begins in April 2026
+	}
+	anchor := time.Date(calendarYear, m, 1, 0, 0, 0, 0, time.UTC)
+	if weeks, _ := f.weeks(); weeks == nil {
+		return anchor
+	}
+	offset := (int(f.WeekStart) - int(anchor.Weekday()) + 7) % 7
+	if offset > 3 {
+		offset -= 7
+	}
+	return anchor.AddDate(0, 0, offset)
+}
+
+func (f FiscalCalendar) yearOf(date time.Time) int {
+	year := date.Year()
+	if m := f.startMonth(); m != time.January && date.Month() >= m {
+		year++
+	}
+	for date.Before(f.yearStart(year)) {
+		year--
+	}
+	for !date.Before(f.yearStart(year + 1)) {
+		year++
+	}
+	return year
+}
+
+// periodBounds returns the bounds of period p (1-12) of the fiscal year start..end.
+func (f FiscalCalendar) periodBounds(start, end time.Time, p int) (time.Time, time.Time) {
+	weeks, _ := f.weeks()
+	if weeks == nil {
+		return start.AddDate(0, p-1, 0), start.AddDate(0, p, 0)
+	}
+	before := 0
+	for i := 1; i < p; i++ {
+		before += weeks[(i-1)%3]
+	}
+	pStart := start.AddDate(0, 0, before*7)
+	if p == 12 {
+		return pStart, end // the 53rd week, if any
+	}
+	return pStart, pStart.AddDate(0, 0, weeks[(p-1)%3]*7)
+}
@@ -0,0 +1,69 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFiscalCalendar_Months(t *testing.T) {
+	d := func(y int, m time.Month, day int) time.Time { return time.Date(y, m, day, 0, 0, 0, 0, time.UTC) }
+
+	gregorian := FiscalCalendar{}
+	if s := gregorian.Span(d(2026, 3, 15), FiscalUnitPeriod); s.Label() != "FY2026 P03" || !s.Start.Equal(d(2026, 3, 1)) || !s.End.Equal(d(2026, 4, 1)) {
+		t.Errorf("period = %+v", s)
+	}
+	if s := gregorian.Span(d(2026, 3, 15), FiscalUnitQuarter); s.Label() != "FY2026 Q1" || !s.Start.Equal(d(2026, 1, 1)) || !s.End.Equal(d(2026, 4, 1)) {
+		t.Errorf("quarter = %+v", s)
+	}
+
+	april := FiscalCalendar{StartMonth: time.April}
+	if s := april.Span(d(2026, 3, 31), FiscalUnitPeriod); s.Label() != "FY2026 P12" || s.Quarter != 4 {
+		t.Errorf("March = %+v, want the last period of FY2026", s)
+	}
+	if s := april.Span(d(2026, 4, 1), FiscalUnitYear); s.Label() != "FY2027" || !s.Start.Equal(d(2026, 4, 1)) || !s.End.Equal(d(2027, 4, 1)) || s.Days() != 365 {
+		t.Errorf("year = %+v", s)
+	}
+	if s := april.Span(d(2026, 8, 20), FiscalUnitQuarter); s.Label() != "FY2027 Q2" || !s.Start.Equal(d(2026, 7, 1)) {
+		t.Errorf("quarter = %+v", s)
+	}
+}
+
+func TestFiscalCalendar_Weeks(t *testing.T) {
+	d := func(y int, m time.Month, day int) time.Time { return time.Date(y, m, day, 0, 0, 0, 0, time.UTC) }
+
+	// 2026-02-01 is a Sunday; Feb 1, 2027 is a Monday, so FY2028 starts on Jan 31, 2027
+	retail := FiscalCalendar{StartMonth: time.February, Pattern: Fiscal445, WeekStart: time.Sunday}
+	if err := retail.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if s := retail.Span(d(2026, 4, 1), FiscalUnitPeriod); s.Label() != "FY2027 P03" || !s.Start.Equal(d(2026, 3, 29)) || s.Days() != 35 {
+		t.Errorf("5-week period = %+v", s)
+	}
+	if s := retail.Span(d(2026, 2, 10), FiscalUnitQuarter); !s.Start.Equal(d(2026, 2, 1)) || s.Days() != 91 {
+		t.Errorf("quarter = %+v, want 13 weeks", s)
+	}
+	if s := retail.Span(d(2027, 1, 30), FiscalUnitYear); s.Year != 2027 || !s.End.Equal(d(2027, 1, 31)) || s.Days() != 364 {
+		t.Errorf("year = %+v, want 52 weeks", s)
+	}
+
+	// Monday nearest to Jan 1: Dec 29, 2025 .. Jan 4, 2027 is a 53-week year
+	long := FiscalCalendar{Pattern: Fiscal544, WeekStart: time.Monday}
+	if s := long.Span(d(2025, 12, 30), FiscalUnitYear); s.Year != 2026 || s.Days() != 371 {
+		t.Errorf("53-week year = %+v", s)
+	}
+	if s := long.Span(d(2027, 1, 1), FiscalUnitPeriod); s.Period != 12 || s.Days() != 35 {
+		t.Errorf("last period = %+v, want 4 weeks plus the 53rd", s)
+	}
+
+	for _, f := range []FiscalCalendar{{StartMonth: 13}, {Pattern: "4-4-4"}, {WeekStart: 7}} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", f)
+		}
+	}
+	if f, err := NewFiscalCalendar(2, "4-4-5", "Sunday"); err != nil || f != retail {
+		t.Errorf("NewFiscalCalendar = %+v, %v", f, err)
+	}
+	if _, err := NewFiscalCalendar(1, "4-4-5", "someday"); err == nil {
+		t.Error("unknown week start should be rejected")
+	}
+}
@@ -0,0 +1,25 @@
+// Package costmodel forecast.go: simple trend fitting used for growth and capacity projections.
+package costmodel
+
+// LinearTrend fits values[i] ≈ intercept + slope*i by least squares, i being the sample index
+// (e.g. days). Fewer than two samples give a flat trend at the only value (or zero).
+func LinearTrend(values []float64) (slope, intercept float64) {
+	n := float64(len(values))
+	switch len(values) {
+	case 0:
+		return 0, 0
+	case 1:
+		return 0, values[0]
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range values {
+		x := float64(i)
+		sumX += x
+		sumY += v
+		sumXY += x * v
+		sumXX += x * x
+	}
+	slope = (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept = (sumY - slope*sumX) / n
+	return slope, intercept
+}
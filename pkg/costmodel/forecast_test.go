@@ -0,0 +1,18 @@
+package costmodel
+
+import "testing"
+
+func TestLinearTrend(t *testing.T) {
+	slope, intercept := LinearTrend([]float64{10, 12, 14, 16})
+	if !FloatEquals(slope, 2, 1e-9) || !FloatEquals(intercept, 10, 1e-9) {
+		t.Errorf("expected slope 2 intercept 10, got %v %v", slope, intercept)
+	}
+	// 噪声数据取最小二乘
+	slope, _ = LinearTrend([]float64{1, 3, 2, 4})
+	if !FloatEquals(slope, 0.8, 1e-9) {
+		t.Errorf("expected slope 0.8, got %v", slope)
+	}
+	if slope, intercept = LinearTrend([]float64{5}); slope != 0 || intercept != 5 {
+		t.Errorf("single sample: got %v %v", slope, intercept)
+	}
+}
@@ -0,0 +1,245 @@
+package costmodel
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Property and fuzz tests for the invariants the table tests only spot-check:
+// billable = usage + waste (for usage within the request), efficiency ∈ [0, 100], aggregation of
+// parts equals the whole, and results independent of input order. `go test` runs the seed corpus;
+// use -fuzz to explore.
+
+const gb = 1 << 30
+
+// costEpsilon bounds the drift between a total and the sum of its parts after rounding each of
+// them (6 decimals in CalculateCost, 2 in the aggregators).
+func costEpsilon(total float64, decimals, terms int) float64 {
+	return float64(terms)*0.5*math.Pow(10, -float64(decimals)) + 1e-9*math.Max(1, math.Abs(total))
+}
+
+func checkDualCost(t *testing.T, name string, billable, usage, waste float64) {
+	t.Helper()
+	if usage < 0 || waste < 0 {
+		t.Errorf("%s: usage %v / waste %v must not be negative", name, usage, waste)
+	}
+	if diff := math.Abs(billable - (usage + waste)); diff > costEpsilon(billable, 6, 3) {
+		t.Errorf("%s: billable %v != usage %v + waste %v (diff %g)", name, billable, usage, waste, diff)
+	}
+}
+
+// checkBurstCost checks a resource used beyond its request: the usage is charged in full (above
+// billable) and there is no waste.
+func checkBurstCost(t *testing.T, name string, billable, usage, waste float64) {
+	t.Helper()
+	if waste != 0 || usage+costEpsilon(usage, 6, 2) < billable {
+		t.Errorf("%s: over request: billable %v, usage %v, waste %v; want usage >= billable and no waste", name, billable, usage, waste)
+	}
+}
+
+func checkScore(t *testing.T, name string, score float64) {
+	t.Helper()
+	if math.IsNaN(score) || score < 0 || score > 100 {
+		t.Errorf("%s = %v, want within [0, 100]", name, score)
+	}
+}
+
+func FuzzCalculateCost(f *testing.F) {
+	f.Add(2.0, 1.0, int64(4*gb), int64(2*gb), 0.025, 0.01)
+	f.Add(0.0, 0.5, int64(0), int64(gb), 0.025, 0.01) // zero requests with nonzero usage
+	f.Add(1.0, 3.0, int64(gb), int64(8*gb), 0.025, 0.01)
+	f.Add(0.0, 0.0, int64(0), int64(0), 0.025, 0.01)
+	f.Add(1e-9, 1e-12, int64(1), int64(0), 1e-6, 1e-6)
+	f.Add(5000.0, 4999.0, int64(1<<50), int64(1<<49), 3.5, 0.9)
+
+	f.Fuzz(func(t *testing.T, cpuReq, cpuUse float64, memReq, memUse int64, corePrice, memPrice float64) {
+		valid := cpuReq >= 0 && cpuUse >= 0 && memReq >= 0 && memUse >= 0 &&
+			corePrice > 0 && memPrice > 0 && isFinite(corePrice) && isFinite(memPrice)
+		rm := ResourceMetric{CPURequest: cpuReq, CPUUsageP95: cpuUse, MemRequest: memReq, MemUsageP95: memUse}
+		got, err := CalculateCost(rm, corePrice, memPrice)
+		if !valid || !isFinite(cpuReq) || !isFinite(cpuUse) {
+			if err == nil && (!isFinite(cpuReq) || !isFinite(cpuUse)) {
+				t.Fatalf("CalculateCost(%+v) accepted a non-finite metric", rm)
+			}
+			return
+		}
+		// 超出 float64 安全范围的组合（溢出为 Inf）不在不变量范围内
+		if cpuReq*corePrice > 1e12 || cpuUse*corePrice > 1e12 || float64(memReq)/gb*memPrice > 1e12 || float64(memUse)/gb*memPrice > 1e12 {
+			return
+		}
+		if err != nil {
+			t.Fatalf("CalculateCost(%+v, %v, %v) = %v", rm, corePrice, memPrice, err)
+		}
+
+		cpuBurst, memBurst := cpuUse > cpuReq, memUse > memReq
+		if cpuBurst {
+			checkBurstCost(t, "cpu", got.CPUBillableCost, got.CPUUsageCost, got.CPUWasteCost)
+		} else {
+			checkDualCost(t, "cpu", got.CPUBillableCost, got.CPUUsageCost, got.CPUWasteCost)
+		}
+		if memBurst {
+			checkBurstCost(t, "mem", got.MemBillableCost, got.MemUsageCost, got.MemWasteCost)
+		} else {
+			checkDualCost(t, "mem", got.MemBillableCost, got.MemUsageCost, got.MemWasteCost)
+		}
+		if !cpuBurst && !memBurst {
+			checkDualCost(t, "total", got.TotalBillableCost, got.TotalUsageCost, got.TotalWasteCost)
+		} else if diff := math.Abs(got.TotalBillableCost - (got.TotalUsageCost + got.TotalWasteCost)); diff > costEpsilon(got.TotalBillableCost, 6, 3) {
+			// Burst usage makes the total waste negative, but the parts still add up
+			t.Errorf("total: billable %v != usage %v + waste %v", got.TotalBillableCost, got.TotalUsageCost, got.TotalWasteCost)
+		}
+		checkScore(t, "CPUEfficiencyScore", got.CPUEfficiencyScore)
+		checkScore(t, "MemEfficiencyScore", got.MemEfficiencyScore)
+		checkScore(t, "OverallEfficiencyScore", got.OverallEfficiencyScore)
+		// The grade follows the unrounded weighted score, so a reported 100 may grade as Risk
+		score := calcOverallEfficiencyScore(calcCPUEfficiencyScore(cpuReq, cpuUse), calcMemEfficiencyScore(memReq, memUse),
+			calcCPUBillable(cpuReq, corePrice), calcMemBillable(memReq, memPrice))
+		if got.OverallGrade != DefaultGradeThresholds.Grade(score) {
+			t.Errorf("grade %s does not match score %v", got.OverallGrade, score)
+		}
+	})
+}
+
+// randomDailyCosts returns n consistent daily namespace costs over a few namespaces, with ties
+// and zero-cost rows mixed in.
+func randomDailyCosts(r *rand.Rand, n int) []DailyNamespaceCost {
+	namespaces := []string{"api", "batch", "web", "infra", "ml"}
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	costs := make([]DailyNamespaceCost, n)
+	for i := range costs {
+		billable := float64(r.Intn(5)) * 25 // 整数倍，制造并列
+		if r.Intn(3) > 0 {
+			billable = r.Float64() * 1000
+		}
+		usage := billable * r.Float64()
+		costs[i] = DailyNamespaceCost{
+			Namespace:    namespaces[r.Intn(len(namespaces))],
+			Date:         day.AddDate(0, 0, r.Intn(7)),
+			BillableCost: billable,
+			UsageCost:    usage,
+			WasteCost:    billable - usage,
+			PodCount:     r.Intn(20),
+		}
+	}
+	return costs
+}
+
+func TestAggregationInvariants(t *testing.T) {
+	r := rand.New(rand.NewSource(20240101))
+	for iter := 0; iter < 200; iter++ {
+		costs := randomDailyCosts(r, 1+r.Intn(40))
+
+		global, err := AggregateGlobal(costs)
+		if err != nil {
+			t.Fatalf("AggregateGlobal: %v", err)
+		}
+		breakdown, err := CalculateDomainBreakdown(costs)
+		if err != nil {
+			t.Fatalf("CalculateDomainBreakdown: %v", err)
+		}
+		checkScore(t, "GlobalEfficiency", global.GlobalEfficiency)
+
+		var sumBillable, sumWaste, sumPercentage float64
+		for _, item := range breakdown {
+			sumBillable += item.BillableCost
+			sumWaste += item.WasteCost
+			sumPercentage += item.CostPercentage
+			checkScore(t, "CostPercentage", item.CostPercentage)
+		}
+		eps := costEpsilon(global.TotalBillableCost, 2, len(breakdown)+1)
+		if math.Abs(sumBillable-global.TotalBillableCost) > eps {
+			t.Errorf("iter %d: breakdown billable %v != global %v", iter, sumBillable, global.TotalBillableCost)
+		}
+		if math.Abs(sumWaste-global.TotalWaste) > eps {
+			t.Errorf("iter %d: breakdown waste %v != global %v", iter, sumWaste, global.TotalWaste)
+		}
+		if global.TotalBillableCost > 0 && math.Abs(sumPercentage-100) > 0.005*float64(len(breakdown)+1) {
+			t.Errorf("iter %d: breakdown percentages sum to %v, want 100", iter, sumPercentage)
+		}
+	}
+}
+
+func TestAggregationOrderIndependent(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for iter := 0; iter < 100; iter++ {
+		costs := randomDailyCosts(r, 2+r.Intn(30))
+		want, err := CalculateDomainBreakdown(costs)
+		if err != nil {
+			t.Fatalf("CalculateDomainBreakdown: %v", err)
+		}
+		for i := 1; i < len(want); i++ {
+			if want[i-1].CostPercentage < want[i].CostPercentage {
+				t.Fatalf("iter %d: breakdown not sorted by cost percentage: %+v", iter, want)
+			}
+		}
+
+		shuffled := append([]DailyNamespaceCost(nil), costs...)
+		r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		got, err := CalculateDomainBreakdown(shuffled)
+		if err != nil {
+			t.Fatalf("CalculateDomainBreakdown: %v", err)
+		}
+		// 求和顺序不同会在末位产生浮点差，舍入后应一致
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("iter %d: breakdown depends on input order:\n got %+v\nwant %+v", iter, got, want)
+		}
+	}
+}
+
+func TestWorkloadAggregationInvariants(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	namespaces := []string{"api", "batch", "web"}
+	workloads := []string{"a", "b", "c", "d"}
+	for iter := 0; iter < 100; iter++ {
+		stats := make([]HourlyWorkloadStat, 1+r.Intn(60))
+		for i := range stats {
+			billable := r.Float64() * 10
+			usage := billable * r.Float64()
+			stats[i] = HourlyWorkloadStat{
+				Namespace:         namespaces[r.Intn(len(namespaces))],
+				WorkloadName:      workloads[r.Intn(len(workloads))],
+				TotalBillableCost: billable,
+				TotalUsageCost:    usage,
+				TotalWasteCost:    billable - usage,
+			}
+		}
+
+		byNamespace, err := AggregateByNamespace(stats)
+		if err != nil {
+			t.Fatalf("AggregateByNamespace: %v", err)
+		}
+		byWorkload, err := AggregateByWorkload(stats)
+		if err != nil {
+			t.Fatalf("AggregateByWorkload: %v", err)
+		}
+
+		sums := make(map[string]*AggregatedResult)
+		terms := make(map[string]int)
+		for _, w := range byWorkload {
+			checkScore(t, "workload EfficiencyScore", w.EfficiencyScore)
+			ns := w.Identifier[:len(w.Identifier)-2] // "<namespace>/<single-letter workload>"
+			if sums[ns] == nil {
+				sums[ns] = &AggregatedResult{}
+			}
+			sums[ns].TotalBillableCost += w.TotalBillableCost
+			sums[ns].TotalUsageCost += w.TotalUsageCost
+			sums[ns].ResourceCount += w.ResourceCount
+			terms[ns]++
+		}
+		for ns, whole := range byNamespace {
+			checkScore(t, "namespace EfficiencyScore", whole.EfficiencyScore)
+			parts := sums[ns]
+			eps := costEpsilon(whole.TotalBillableCost, 2, terms[ns]+1)
+			if parts == nil || math.Abs(parts.TotalBillableCost-whole.TotalBillableCost) > eps ||
+				math.Abs(parts.TotalUsageCost-whole.TotalUsageCost) > eps || parts.ResourceCount != whole.ResourceCount {
+				t.Errorf("iter %d: workloads of %s sum to %+v, namespace is %+v", iter, ns, parts, whole)
+			}
+		}
+		if len(sums) != len(byNamespace) {
+			t.Errorf("iter %d: %d namespaces from workloads, %d from AggregateByNamespace", iter, len(sums), len(byNamespace))
+		}
+	}
+}
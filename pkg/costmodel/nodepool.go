@@ -0,0 +1,60 @@
+// Package costmodel nodepool.go: node pools (groups of nodes sharing a pool label), the dimension
+// capacity decisions are made in; single nodes come and go with the autoscaler.
+package costmodel
+
+// DefaultNodePool is the pool of nodes without any of the pool labels.
+const DefaultNodePool = "default"
+
+// UnassignedNodePool groups workload stats recorded without a node pool (e.g. before pools were
+// tracked, or when the nodes of the workload were unknown).
+const UnassignedNodePool = "unassigned"
+
+// DefaultNodePoolLabels are the node labels naming the pool of a node, in order of precedence: the
+// managed node pool labels of ACK, GKE, EKS, AKS and Karpenter, then the generic node-type label.
+var DefaultNodePoolLabels = []string{
+	"alibabacloud.com/nodepool-id",
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"kubernetes.azure.com/agentpool",
+	"karpenter.sh/nodepool",
+	"node-type",
+}
+
+// NodePool is a group of nodes sharing a pool label value.
+type NodePool struct {
+	Name  string   `json:"name"`
+	Nodes []string `json:"nodes"`
+}
+
+// NodePoolOf returns the pool named by the first of keys (DefaultNodePoolLabels when empty) set in
+// the node labels, or DefaultNodePool.
+func NodePoolOf(labels map[string]string, keys []string) string {
+	if len(keys) == 0 {
+		keys = DefaultNodePoolLabels
+	}
+	for _, k := range keys {
+		if v := labels[k]; v != "" {
+			return v
+		}
+	}
+	return DefaultNodePool
+}
+
+// MajorityNodePool returns the pool of most of nodes (ties: the smallest name) given the pool of
+// each node name, or "" when none of the nodes is known. A workload whose pods span pools is
+// attributed to the pool running most of them.
+func MajorityNodePool(nodes []string, poolOf map[string]string) string {
+	counts := make(map[string]int)
+	for _, n := range nodes {
+		if p, ok := poolOf[n]; ok {
+			counts[p]++
+		}
+	}
+	best := ""
+	for p, c := range counts {
+		if best == "" || c > counts[best] || (c == counts[best] && p < best) {
+			best = p
+		}
+	}
+	return best
+}
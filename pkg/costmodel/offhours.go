@@ -0,0 +1,160 @@
+// Package costmodel offhours.go: business hours vs nights/weekends split of hourly cost, the cost
+// spent while a workload is near idle off-hours, and scale-to-zero / scheduled scaling proposals.
+package costmodel
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultIdleUtilization is the dominant usage/request ratio at or below which an hour counts as idle.
+	DefaultIdleUtilization = 0.05
+	// offHoursIdleShare is the share of idle off-hours above which scheduled scaling is proposed;
+	// above it in business hours as well the workload is proposed for scale-to-zero.
+	offHoursIdleShare = 0.8
+)
+
+const (
+	// ActionScaleToZero proposes scaling a workload idle around the clock to zero (on demand).
+	ActionScaleToZero RecommendationAction = "scale_to_zero"
+	// ActionScheduledScaling proposes scaling a workload down on nights and weekends.
+	ActionScheduledScaling RecommendationAction = "scheduled_scaling"
+)
+
+// BusinessHours are the weekday (Monday-Friday) hours [StartHour, EndHour) in Location; all other
+// hours are off-hours. A nil Location is UTC.
+type BusinessHours struct {
+	Location  *time.Location
+	StartHour int
+	EndHour   int
+}
+
+// DefaultBusinessHours returns 09:00-18:00 UTC on weekdays.
+func DefaultBusinessHours() BusinessHours {
+	return BusinessHours{Location: time.UTC, StartHour: 9, EndHour: 18}
+}
+
+// Contains reports whether the hour starting at t is a business hour.
+func (b BusinessHours) Contains(t time.Time) bool {
+	loc := b.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	return t.Hour() >= b.StartHour && t.Hour() < b.EndHour
+}
+
+// HourSample is the billable cost of a workload in one hour and its dominant utilization
+// (max of CPU and memory P95 usage / request, 0-1).
+type HourSample struct {
+	Timestamp    time.Time
+	BillableCost float64
+	Utilization  float64
+}
+
+// HourUtilization returns the dominant usage/request ratio; a resource without request is ignored.
+func HourUtilization(cpuRequest, cpuUsageP95 float64, memRequest, memUsageP95 int64) float64 {
+	var u float64
+	if cpuRequest > 0 {
+		u = cpuUsageP95 / cpuRequest
+	}
+	if memRequest > 0 && float64(memUsageP95)/float64(memRequest) > u {
+		u = float64(memUsageP95) / float64(memRequest)
+	}
+	return u
+}
+
+// OffHoursProfile splits a workload's hours into business hours and off-hours. Utilizations are
+// mean dominant utilization in %; idle costs are billable cost of idle hours.
+type OffHoursProfile struct {
+	BusinessHours         int     `json:"business_hours"`
+	OffHours              int     `json:"off_hours"`
+	BusinessCost          float64 `json:"business_cost"`
+	OffHoursCost          float64 `json:"off_hours_cost"`
+	IdleBusinessHours     int     `json:"idle_business_hours"`
+	IdleBusinessCost      float64 `json:"idle_business_cost"`
+	IdleOffHours          int     `json:"idle_off_hours"`
+	IdleOffHoursCost      float64 `json:"idle_off_hours_cost"`
+	BusinessUtilization   float64 `json:"business_utilization"`
+	OffHoursUtilization   float64 `json:"off_hours_utilization"`
+	OffHoursCostShare     float64 `json:"off_hours_cost_share"`      // off-hours cost / total cost, %
+	IdleOffHoursCostShare float64 `json:"idle_off_hours_cost_share"` // idle off-hours cost / total cost, %
+}
+
+// AnalyzeOffHours builds the profile of samples (one per hour). idleUtilization <= 0 uses
+// DefaultIdleUtilization.
+func AnalyzeOffHours(samples []HourSample, hours BusinessHours, idleUtilization float64) OffHoursProfile {
+	if idleUtilization <= 0 {
+		idleUtilization = DefaultIdleUtilization
+	}
+	var p OffHoursProfile
+	var businessUtil, offUtil float64
+	for _, s := range samples {
+		idle := s.Utilization <= idleUtilization
+		if hours.Contains(s.Timestamp) {
+			p.BusinessHours++
+			p.BusinessCost += s.BillableCost
+			businessUtil += s.Utilization
+			if idle {
+				p.IdleBusinessHours++
+				p.IdleBusinessCost += s.BillableCost
+			}
+			continue
+		}
+		p.OffHours++
+		p.OffHoursCost += s.BillableCost
+		offUtil += s.Utilization
+		if idle {
+			p.IdleOffHours++
+			p.IdleOffHoursCost += s.BillableCost
+		}
+	}
+	if p.BusinessHours > 0 {
+		p.BusinessUtilization = roundPercentage(businessUtil / float64(p.BusinessHours) * 100)
+	}
+	if p.OffHours > 0 {
+		p.OffHoursUtilization = roundPercentage(offUtil / float64(p.OffHours) * 100)
+	}
+	if total := p.BusinessCost + p.OffHoursCost; total > 0 {
+		p.OffHoursCostShare = roundPercentage(p.OffHoursCost / total * 100)
+		p.IdleOffHoursCostShare = roundPercentage(p.IdleOffHoursCost / total * 100)
+	}
+	p.BusinessCost = roundFinancial(p.BusinessCost)
+	p.OffHoursCost = roundFinancial(p.OffHoursCost)
+	p.IdleBusinessCost = roundFinancial(p.IdleBusinessCost)
+	p.IdleOffHoursCost = roundFinancial(p.IdleOffHoursCost)
+	return p
+}
+
+// RecommendOffHours proposes scale-to-zero when at least 80% of both business hours and off-hours
+// are idle, and scheduled scaling when only the off-hours are. Savings extrapolate the idle cost
+// of the observed hours to a month; a profile without off-hours gets no proposal.
+func RecommendOffHours(p OffHoursProfile) (Recommendation, bool) {
+	observed := p.BusinessHours + p.OffHours
+	if p.OffHours == 0 || float64(p.IdleOffHours)/float64(p.OffHours) < offHoursIdleShare {
+		return Recommendation{}, false
+	}
+	monthly := func(cost float64) float64 {
+		return roundToPrecision(cost/float64(observed)*HoursPerMonth, 2)
+	}
+	if p.BusinessHours > 0 && float64(p.IdleBusinessHours)/float64(p.BusinessHours) >= offHoursIdleShare {
+		return Recommendation{
+			Action:                  ActionScaleToZero,
+			Resource:                "workload",
+			EstimatedMonthlySavings: monthly(p.IdleBusinessCost + p.IdleOffHoursCost),
+			Reason: fmt.Sprintf("idle in %d of %d business hours and %d of %d off-hours; scale to zero and start on demand",
+				p.IdleBusinessHours, p.BusinessHours, p.IdleOffHours, p.OffHours),
+		}, true
+	}
+	return Recommendation{
+		Action:                  ActionScheduledScaling,
+		Resource:                "workload",
+		EstimatedMonthlySavings: monthly(p.IdleOffHoursCost),
+		Reason: fmt.Sprintf("idle in %d of %d off-hours but busy in business hours (%.0f%% utilization); scale down on nights and weekends",
+			p.IdleOffHours, p.OffHours, p.BusinessUtilization),
+	}, true
+}
@@ -0,0 +1,84 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusinessHoursContains(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	b := BusinessHours{Location: shanghai, StartHour: 9, EndHour: 18}
+	cases := map[time.Time]bool{
+		time.Date(2024, 3, 4, 1, 0, 0, 0, time.UTC):  true,  // Mon 09:00 CST
+		time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC): false, // Mon 18:00 CST
+		time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC):  false, // Mon 08:00 CST
+		time.Date(2024, 3, 9, 3, 0, 0, 0, time.UTC):  false, // Sat 11:00 CST
+		time.Date(2024, 3, 3, 23, 0, 0, 0, time.UTC): false, // Mon 07:00 CST
+		time.Date(2024, 3, 8, 9, 0, 0, 0, time.UTC):  true,  // Fri 17:00 CST
+	}
+	for ts, want := range cases {
+		if got := b.Contains(ts); got != want {
+			t.Errorf("Contains(%v) = %v, want %v", ts, got, want)
+		}
+	}
+	if (BusinessHours{StartHour: 9, EndHour: 18}).Contains(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)) != true {
+		t.Error("nil location should be UTC")
+	}
+}
+
+func TestHourUtilization(t *testing.T) {
+	if got := HourUtilization(2, 0.5, 1000, 500); got != 0.5 {
+		t.Errorf("memory dominant: got %v, want 0.5", got)
+	}
+	if got := HourUtilization(0, 1, 0, 1); got != 0 {
+		t.Errorf("no requests: got %v, want 0", got)
+	}
+}
+
+// week returns one sample per hour of the week starting Monday 2024-03-04 00:00 UTC.
+func week(utilization func(business bool) float64) []HourSample {
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	hours := DefaultBusinessHours()
+	samples := make([]HourSample, 0, 168)
+	for h := 0; h < 168; h++ {
+		ts := start.Add(time.Duration(h) * time.Hour)
+		samples = append(samples, HourSample{Timestamp: ts, BillableCost: 1, Utilization: utilization(hours.Contains(ts))})
+	}
+	return samples
+}
+
+func TestAnalyzeOffHours(t *testing.T) {
+	p := AnalyzeOffHours(week(func(business bool) float64 {
+		if business {
+			return 0.6
+		}
+		return 0.01
+	}), DefaultBusinessHours(), 0)
+	if p.BusinessHours != 45 || p.OffHours != 123 || p.IdleOffHours != 123 || p.IdleBusinessHours != 0 {
+		t.Fatalf("hours: %+v", p)
+	}
+	if p.BusinessCost != 45 || p.IdleOffHoursCost != 123 || p.BusinessUtilization != 60 || p.OffHoursUtilization != 1 {
+		t.Errorf("costs: %+v", p)
+	}
+	if p.OffHoursCostShare != 73.21 || p.IdleOffHoursCostShare != 73.21 {
+		t.Errorf("shares: %+v", p)
+	}
+
+	rec, ok := RecommendOffHours(p)
+	if !ok || rec.Action != ActionScheduledScaling || rec.EstimatedMonthlySavings != 534.46 {
+		t.Errorf("busy by day: got %+v, %v", rec, ok)
+	}
+
+	idle := AnalyzeOffHours(week(func(bool) float64 { return 0.02 }), DefaultBusinessHours(), 0)
+	if rec, ok := RecommendOffHours(idle); !ok || rec.Action != ActionScaleToZero || rec.EstimatedMonthlySavings != 730 {
+		t.Errorf("always idle: got %+v, %v", rec, ok)
+	}
+
+	busy := AnalyzeOffHours(week(func(bool) float64 { return 0.5 }), DefaultBusinessHours(), 0)
+	if _, ok := RecommendOffHours(busy); ok {
+		t.Error("busy around the clock should get no proposal")
+	}
+	if _, ok := RecommendOffHours(OffHoursProfile{}); ok {
+		t.Error("empty profile should get no proposal")
+	}
+}
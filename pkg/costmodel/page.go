@@ -0,0 +1,131 @@
+// Package costmodel page.go: server-side ordering and paging of aggregation results, so list endpoints
+// return one sorted page instead of every namespace / workload of large clusters.
+package costmodel
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortField is the field aggregation results are ordered by.
+type SortField string
+
+const (
+	SortByCost       SortField = "cost"       // billable cost
+	SortByWaste      SortField = "waste"      // waste cost
+	SortByEfficiency SortField = "efficiency" // usage / billable
+	SortByName       SortField = "name"       // identifier
+)
+
+// SortOrder is the direction of a SortField.
+type SortOrder string
+
+const (
+	OrderDesc SortOrder = "desc"
+	OrderAsc  SortOrder = "asc"
+)
+
+// MaxPageLimit bounds PageOptions.Limit.
+const MaxPageLimit = 500
+
+// PageOptions orders and pages aggregation results. The zero value is every result by cost,
+// descending. Ties are broken by identifier, so consecutive pages neither repeat nor skip results.
+type PageOptions struct {
+	Sort   SortField // "" = SortByCost
+	Order  SortOrder // "" = OrderDesc
+	Offset int
+	Limit  int // 0 = all remaining results
+}
+
+// Validate checks the sort field, the order and the page bounds.
+func (o PageOptions) Validate() error {
+	switch o.Sort {
+	case "", SortByCost, SortByWaste, SortByEfficiency, SortByName:
+	default:
+		return fmt.Errorf("sort must be cost, waste, efficiency or name, got %q", o.Sort)
+	}
+	switch o.Order {
+	case "", OrderDesc, OrderAsc:
+	default:
+		return fmt.Errorf("order must be asc or desc, got %q", o.Order)
+	}
+	if o.Offset < 0 || o.Limit < 0 || o.Limit > MaxPageLimit {
+		return fmt.Errorf("offset must be >= 0 and limit within 0..%d", MaxPageLimit)
+	}
+	return nil
+}
+
+// rankKey holds the sortable fields of one result.
+type rankKey struct {
+	name                    string
+	cost, waste, efficiency float64
+}
+
+// page returns the indexes of keys in the order of o, restricted to the page, and the total count.
+func (o PageOptions) page(keys []rankKey) ([]int, int) {
+	idx := make([]int, len(keys))
+	for i := range idx {
+		idx[i] = i
+	}
+	value := func(k rankKey) float64 {
+		switch o.Sort {
+		case SortByWaste:
+			return k.waste
+		case SortByEfficiency:
+			return k.efficiency
+		}
+		return k.cost
+	}
+	asc := o.Order == OrderAsc
+	sort.Slice(idx, func(i, j int) bool {
+		a, b := keys[idx[i]], keys[idx[j]]
+		if o.Sort != SortByName {
+			if va, vb := value(a), value(b); va != vb {
+				return (va < vb) == asc
+			}
+			return a.name < b.name
+		}
+		return (a.name < b.name) == asc
+	})
+	total := len(idx)
+	if o.Offset >= total {
+		return nil, total
+	}
+	idx = idx[o.Offset:]
+	if o.Limit > 0 && o.Limit < len(idx) {
+		idx = idx[:o.Limit]
+	}
+	return idx, total
+}
+
+// PageAggregatedResults orders results (e.g. of AggregateByNamespace or AggregateByWorkload) by o
+// and returns the requested page and the number of results before paging.
+func PageAggregatedResults(results map[string]AggregatedResult, o PageOptions) ([]AggregatedResult, int) {
+	all := make([]AggregatedResult, 0, len(results))
+	keys := make([]rankKey, 0, len(results))
+	for _, r := range results {
+		all = append(all, r)
+		keys = append(keys, rankKey{name: r.Identifier, cost: r.TotalBillableCost, waste: r.TotalWasteCost, efficiency: r.EfficiencyScore})
+	}
+	idx, total := o.page(keys)
+	page := make([]AggregatedResult, 0, len(idx))
+	for _, i := range idx {
+		page = append(page, all[i])
+	}
+	return page, total
+}
+
+// PageDomainBreakdown orders the items of CalculateDomainBreakdown by o and returns the requested page
+// and the number of items before paging.
+func PageDomainBreakdown(items []DomainBreakdownItem, o PageOptions) ([]DomainBreakdownItem, int) {
+	keys := make([]rankKey, len(items))
+	for i, it := range items {
+		keys[i] = rankKey{name: it.DomainName, cost: it.BillableCost, waste: it.WasteCost, efficiency: calculateEfficiencyScore(it.BillableCost, it.UsageCost)}
+	}
+	idx, total := o.page(keys)
+	page := make([]DomainBreakdownItem, 0, len(idx))
+	for _, i := range idx {
+		page = append(page, items[i])
+	}
+	return page, total
+}
@@ -0,0 +1,77 @@
+package costmodel
+
+import "testing"
+
+func TestPageAggregatedResults(t *testing.T) {
+	results := map[string]AggregatedResult{
+		"ns/a": {Identifier: "ns/a", TotalBillableCost: 10, TotalWasteCost: 1, EfficiencyScore: 90},
+		"ns/b": {Identifier: "ns/b", TotalBillableCost: 30, TotalWasteCost: 24, EfficiencyScore: 20},
+		"ns/c": {Identifier: "ns/c", TotalBillableCost: 20, TotalWasteCost: 2, EfficiencyScore: 90},
+		"ns/d": {Identifier: "ns/d", TotalBillableCost: 20, TotalWasteCost: 15, EfficiencyScore: 25},
+	}
+	ids := func(rs []AggregatedResult) string {
+		s := ""
+		for _, r := range rs {
+			s += r.Identifier[3:]
+		}
+		return s
+	}
+	cases := []struct {
+		opts PageOptions
+		want string
+	}{
+		{PageOptions{}, "bcda"}, // 同成本按名称
+		{PageOptions{Sort: SortByWaste, Limit: 2}, "bd"},
+		{PageOptions{Sort: SortByEfficiency, Order: OrderAsc}, "bdac"},
+		{PageOptions{Sort: SortByName, Order: OrderDesc, Offset: 1, Limit: 2}, "cb"},
+		{PageOptions{Offset: 3, Limit: 5}, "a"},
+		{PageOptions{Offset: 4}, ""},
+	}
+	for _, tc := range cases {
+		page, total := PageAggregatedResults(results, tc.opts)
+		if got := ids(page); got != tc.want || total != 4 {
+			t.Errorf("PageAggregatedResults(%+v) = %s (total %d), want %s (total 4)", tc.opts, got, total, tc.want)
+		}
+	}
+
+	// consecutive pages cover every result exactly once
+	seen := make(map[string]bool)
+	for offset := 0; offset < 4; offset += 3 {
+		page, _ := PageAggregatedResults(results, PageOptions{Sort: SortByEfficiency, Offset: offset, Limit: 3})
+		for _, r := range page {
+			if seen[r.Identifier] {
+				t.Errorf("%s on two pages", r.Identifier)
+			}
+			seen[r.Identifier] = true
+		}
+	}
+	if len(seen) != 4 {
+		t.Errorf("pages covered %d results, want 4", len(seen))
+	}
+}
+
+func TestPageDomainBreakdown(t *testing.T) {
+	items := []DomainBreakdownItem{
+		{DomainName: "a", BillableCost: 100, UsageCost: 90, WasteCost: 10},
+		{DomainName: "b", BillableCost: 300, UsageCost: 60, WasteCost: 240},
+		{DomainName: "c", BillableCost: 200, UsageCost: 180, WasteCost: 20},
+	}
+	page, total := PageDomainBreakdown(items, PageOptions{Sort: SortByEfficiency, Limit: 1})
+	// a and c are both 90% efficient: ties order by name
+	if total != 3 || len(page) != 1 || page[0].DomainName != "a" {
+		t.Errorf("most efficient = %+v (total %d), want a of 3", page, total)
+	}
+}
+
+func TestPageOptionsValidate(t *testing.T) {
+	for _, o := range []PageOptions{{}, {Sort: SortByWaste, Order: OrderAsc, Offset: 10, Limit: MaxPageLimit}} {
+		if err := o.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", o, err)
+		}
+	}
+	for _, o := range []PageOptions{{Sort: "pods"}, {Order: "up"}, {Offset: -1}, {Limit: MaxPageLimit + 1}} {
+		if err := o.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", o)
+		}
+	}
+}
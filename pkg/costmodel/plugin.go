@@ -0,0 +1,169 @@
+// Package costmodel plugin.go: pluggable cost calculators, so an organization's own chargeback formula
+// (tiered rates, minimum charges, ...) can replace CalculateCost without forking. A calculator
+// registers under a name (usually from an init function, like database/sql drivers) and is selected
+// with business.cost_calculation.calculator.
+package costmodel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// DefaultCalculatorName is the name of the built-in calculator (CalculateCost).
+const DefaultCalculatorName = "standard"
+
+// ErrUnknownCalculator is returned by NewCalculator for a name that was never registered.
+var ErrUnknownCalculator = errors.New("unknown cost calculator")
+
+// CostCalculator prices the resource metrics of one workload-hour. Results must keep the invariants
+// of CalculateCost (billable = usage + waste per resource and in total, no negative cost); the grade is
+// re-derived from OverallEfficiencyScore by the cost policy, see CalculateWithPolicy.
+type CostCalculator interface {
+	CalculateCost(rm ResourceMetric, corePrice, memPrice float64) (CostResult, error)
+}
+
+// CalculatorFunc adapts a function to CostCalculator.
+type CalculatorFunc func(rm ResourceMetric, corePrice, memPrice float64) (CostResult, error)
+
+// CalculateCost calls f.
+func (f CalculatorFunc) CalculateCost(rm ResourceMetric, corePrice, memPrice float64) (CostResult, error) {
+	return f(rm, corePrice, memPrice)
+}
+
+// DefaultCalculator is the built-in calculator: request-based billable cost, P95-based usage cost.
+var DefaultCalculator CostCalculator = CalculatorFunc(CalculateCost)
+
+// CalculatorFactory creates a calculator from its options (business.cost_calculation.calculator_options,
+// as decoded from YAML). It should reject unknown or invalid options.
+type CalculatorFactory func(options map[string]interface{}) (CostCalculator, error)
+
+var (
+	calculatorsMu sync.RWMutex
+	calculators   = map[string]CalculatorFactory{
+		DefaultCalculatorName: func(options map[string]interface{}) (CostCalculator, error) {
+			if len(options) > 0 {
+				return nil, fmt.Errorf("calculator %q takes no options", DefaultCalculatorName)
+			}
+			return DefaultCalculator, nil
+		},
+		MinimumChargeCalculatorName: newMinimumChargeCalculator,
+	}
+)
+
+// RegisterCalculator registers a calculator factory under name; registering a name twice panics
+// (like database/sql drivers, to surface initialization errors).
+func RegisterCalculator(name string, factory CalculatorFactory) {
+	calculatorsMu.Lock()
+	defer calculatorsMu.Unlock()
+	if factory == nil {
+		panic("costmodel: RegisterCalculator factory is nil")
+	}
+	if _, dup := calculators[name]; dup {
+		panic("costmodel: RegisterCalculator called twice for calculator " + name)
+	}
+	calculators[name] = factory
+}
+
+// Calculators returns the registered calculator names, sorted.
+func Calculators() []string {
+	calculatorsMu.RLock()
+	defer calculatorsMu.RUnlock()
+	names := make([]string, 0, len(calculators))
+	for name := range calculators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewCalculator creates the calculator registered under name with options; an empty name is
+// DefaultCalculatorName.
+func NewCalculator(name string, options map[string]interface{}) (CostCalculator, error) {
+	if name == "" {
+		name = DefaultCalculatorName
+	}
+	calculatorsMu.RLock()
+	factory, ok := calculators[name]
+	calculatorsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (registered: %v)", ErrUnknownCalculator, name, Calculators())
+	}
+	calc, err := factory(options)
+	if err != nil {
+		return nil, fmt.Errorf("cost calculator %q: %w", name, err)
+	}
+	return calc, nil
+}
+
+// checkResult rejects results that break the cost invariants, so a faulty calculator fails the
+// calculation instead of corrupting waste and ROI figures. The tolerance covers rounding each term
+// to 6 decimals (as CalculateCost does).
+func checkResult(r CostResult) error {
+	epsilon := 2e-6 + 1e-9*math.Abs(r.TotalBillableCost)
+	for _, c := range []struct {
+		name                   string
+		billable, usage, waste float64
+	}{
+		{"cpu", r.CPUBillableCost, r.CPUUsageCost, r.CPUWasteCost},
+		{"memory", r.MemBillableCost, r.MemUsageCost, r.MemWasteCost},
+		{"total", r.TotalBillableCost, r.TotalUsageCost, r.TotalWasteCost},
+	} {
+		if c.usage < -epsilon || c.waste < -epsilon || math.Abs(c.billable-c.usage-c.waste) > epsilon {
+			return fmt.Errorf("%s cost breaks billable = usage + waste: %v != %v + %v", c.name, c.billable, c.usage, c.waste)
+		}
+	}
+	if math.Abs(r.TotalBillableCost-r.CPUBillableCost-r.MemBillableCost) > epsilon {
+		return fmt.Errorf("total billable cost %v is not cpu %v + memory %v", r.TotalBillableCost, r.CPUBillableCost, r.MemBillableCost)
+	}
+	return nil
+}
+
+// MinimumChargeCalculatorName is the built-in calculator that bills every workload at least a minimum
+// request, e.g. for internal chargeback with a per-workload floor.
+const MinimumChargeCalculatorName = "minimum_charge"
+
+// newMinimumChargeCalculator creates the minimum_charge calculator. Options: min_cpu_cores and
+// min_mem_gb, the request billed at least per workload-hour. The unused part of the floor is waste.
+func newMinimumChargeCalculator(options map[string]interface{}) (CostCalculator, error) {
+	var minCPU, minMemGB float64
+	for key, v := range options {
+		f, ok := toFloat(v)
+		if !ok || f < 0 {
+			return nil, fmt.Errorf("option %s must be a non-negative number, got %v", key, v)
+		}
+		switch key {
+		case "min_cpu_cores":
+			minCPU = f
+		case "min_mem_gb":
+			minMemGB = f
+		default:
+			return nil, fmt.Errorf("unknown option %s", key)
+		}
+	}
+	minMem := int64(math.Round(minMemGB * 1024 * 1024 * 1024))
+	return CalculatorFunc(func(rm ResourceMetric, corePrice, memPrice float64) (CostResult, error) {
+		rm.CPURequest = math.Max(rm.CPURequest, minCPU)
+		rm.MemRequest = max(rm.MemRequest, minMem)
+		return CalculateCost(rm, corePrice, memPrice)
+	}), nil
+}
+
+// toFloat converts the numbers YAML and JSON decode to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
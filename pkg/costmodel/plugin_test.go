@@ -0,0 +1,83 @@
+package costmodel
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewCalculator(t *testing.T) {
+	rm := ResourceMetric{CPURequest: 0.1, CPUUsageP95: 0.05, MemRequest: 1 << 28, MemUsageP95: 1 << 27}
+	std, err := NewCalculator("", nil)
+	if err != nil {
+		t.Fatalf("NewCalculator(\"\"): %v", err)
+	}
+	want, _ := CalculateCost(rm, 0.1, 0.01)
+	if got, _ := std.CalculateCost(rm, 0.1, 0.01); got != want {
+		t.Errorf("standard calculator = %+v, want %+v", got, want)
+	}
+
+	// 不足下限的工作负载按下限计费，下限中未用的部分计为浪费
+	floor, err := NewCalculator(MinimumChargeCalculatorName, map[string]interface{}{"min_cpu_cores": 0.5, "min_mem_gb": 1})
+	if err != nil {
+		t.Fatalf("NewCalculator(minimum_charge): %v", err)
+	}
+	got, err := CalculateWithPolicy(floor, rm, 0.1, 0.01, CostPolicy{})
+	if err != nil {
+		t.Fatalf("CalculateWithPolicy: %v", err)
+	}
+	if !FloatEquals(got.CPUBillableCost, 0.05, 1e-9) || !FloatEquals(got.MemBillableCost, 0.01, 1e-9) {
+		t.Errorf("minimum charge billable = %v / %v, want 0.05 / 0.01", got.CPUBillableCost, got.MemBillableCost)
+	}
+	if !FloatEquals(got.CPUUsageCost, want.CPUUsageCost, 1e-9) || got.TotalWasteCost <= want.TotalWasteCost {
+		t.Errorf("minimum charge usage %v, waste %v; want usage %v and more waste than %v", got.CPUUsageCost, got.TotalWasteCost, want.CPUUsageCost, want.TotalWasteCost)
+	}
+
+	for name, options := range map[string]map[string]interface{}{
+		DefaultCalculatorName:       {"rate": 1},
+		MinimumChargeCalculatorName: {"min_cpu_cores": "1"},
+		"tiered":                    nil,
+	} {
+		if _, err := NewCalculator(name, options); err == nil {
+			t.Errorf("NewCalculator(%q, %v) = nil error", name, options)
+		}
+	}
+	if _, err := NewCalculator("tiered", nil); !errors.Is(err, ErrUnknownCalculator) {
+		t.Errorf("unknown calculator error = %v, want ErrUnknownCalculator", err)
+	}
+}
+
+func TestRegisterCalculator(t *testing.T) {
+	// 每核时固定加收 0.01 的内部服务费（计入 billable 与 usage，不改变浪费）
+	RegisterCalculator("test_surcharge", func(options map[string]interface{}) (CostCalculator, error) {
+		return CalculatorFunc(func(rm ResourceMetric, corePrice, memPrice float64) (CostResult, error) {
+			return CalculateCost(rm, corePrice+0.01, memPrice)
+		}), nil
+	})
+	calc, err := NewCalculator("test_surcharge", nil)
+	if err != nil {
+		t.Fatalf("NewCalculator: %v", err)
+	}
+	rm := ResourceMetric{CPURequest: 2, CPUUsageP95: 1}
+	if got, _ := CalculateWithPolicy(calc, rm, 0.1, 0.01, CostPolicy{}); !FloatEquals(got.CPUBillableCost, 0.22, 1e-9) {
+		t.Errorf("surcharged billable = %v, want 0.22", got.CPUBillableCost)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a calculator twice should panic")
+		}
+	}()
+	RegisterCalculator(DefaultCalculatorName, func(map[string]interface{}) (CostCalculator, error) { return DefaultCalculator, nil })
+}
+
+func TestCalculateWithPolicyRejectsBrokenResults(t *testing.T) {
+	broken := CalculatorFunc(func(rm ResourceMetric, corePrice, memPrice float64) (CostResult, error) {
+		r, err := CalculateCost(rm, corePrice, memPrice)
+		r.TotalWasteCost = 0 // billable != usage + waste
+		return r, err
+	})
+	rm := ResourceMetric{CPURequest: 2, CPUUsageP95: 0.5}
+	if _, err := CalculateWithPolicy(broken, rm, 0.1, 0.01, CostPolicy{}); err == nil {
+		t.Error("expected a result breaking billable = usage + waste to be rejected")
+	}
+}
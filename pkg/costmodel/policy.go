@@ -0,0 +1,151 @@
+// Package costmodel policy.go: per-workload cost policies (waste exclusion, cost center, grade thresholds).
+package costmodel
+
+import (
+	"fmt"
+	"math"
+)
+
+// GradeThresholds are the efficiency score boundaries (percent) between grades:
+// score < Zombie is Zombie, < OverProvisioned is OverProvisioned, > Risk is Risk, otherwise Healthy.
+type GradeThresholds struct {
+	Zombie          float64 `json:"zombie"`
+	OverProvisioned float64 `json:"over_provisioned"`
+	Risk            float64 `json:"risk"`
+}
+
+// DefaultGradeThresholds are the thresholds of the specification (10 / 40 / 90).
+var DefaultGradeThresholds = GradeThresholds{Zombie: 10, OverProvisioned: 40, Risk: 90}
+
+// Validate checks 0 <= Zombie <= OverProvisioned <= Risk <= 100.
+func (t GradeThresholds) Validate() error {
+	if t.Zombie < 0 || t.Zombie > t.OverProvisioned || t.OverProvisioned > t.Risk || t.Risk > 100 {
+		return fmt.Errorf("grade thresholds must satisfy 0 <= zombie <= over_provisioned <= risk <= 100, got %v/%v/%v",
+			t.Zombie, t.OverProvisioned, t.Risk)
+	}
+	return nil
+}
+
+// Grade returns the grade of score. A score of exactly 100 (usually no requests) is Healthy.
+func (t GradeThresholds) Grade(score float64) EfficiencyGrade {
+	if score == 100.0 {
+		return GradeHealthy
+	}
+	switch {
+	case score < t.Zombie:
+		return GradeZombie
+	case score < t.OverProvisioned:
+		return GradeOverProvisioned
+	case score > t.Risk:
+		return GradeRisk
+	default:
+		return GradeHealthy
+	}
+}
+
+// GradeWithHysteresis is GradeWithHysteresis with these thresholds.
+func (t GradeThresholds) GradeWithHysteresis(score float64, previous EfficiencyGrade, margin float64) EfficiencyGrade {
+	grade := t.Grade(score)
+	if grade == previous || score == 100.0 || margin <= 0 {
+		return grade
+	}
+	band, ok := t.bands()[previous]
+	if !ok {
+		return grade
+	}
+	if score >= band[0]-margin && score <= band[1]+margin {
+		return previous
+	}
+	return grade
+}
+
+// bands are the score ranges of Grade: [lo, hi). Healthy includes Risk itself.
+func (t GradeThresholds) bands() map[EfficiencyGrade][2]float64 {
+	return map[EfficiencyGrade][2]float64{
+		GradeZombie:          {math.Inf(-1), t.Zombie},
+		GradeOverProvisioned: {t.Zombie, t.OverProvisioned},
+		GradeHealthy:         {t.OverProvisioned, t.Risk},
+		GradeRisk:            {t.Risk, math.Inf(1)},
+	}
+}
+
+// CostPolicy is the declarative cost exception of a workload (usually parsed from annotations).
+// The zero value is the default behavior.
+type CostPolicy struct {
+	// ExcludeFromWaste treats the whole request as used (e.g. DR standby): waste is zero, the workload
+	// grades Healthy and no decommission/downsize is recommended.
+	ExcludeFromWaste bool `json:"exclude_from_waste,omitempty"`
+	// CostCenter overrides the namespace for chargeback.
+	CostCenter string `json:"cost_center,omitempty"`
+	// GradeThresholds overrides DefaultGradeThresholds when set.
+	GradeThresholds *GradeThresholds `json:"grade_thresholds,omitempty"`
+}
+
+// Thresholds returns the effective grade thresholds of the policy.
+func (p CostPolicy) Thresholds() GradeThresholds {
+	if p.GradeThresholds != nil {
+		return *p.GradeThresholds
+	}
+	return DefaultGradeThresholds
+}
+
+// Merge returns p overridden by the fields set in override (workload policy over namespace policy).
+func (p CostPolicy) Merge(override CostPolicy) CostPolicy {
+	if override.ExcludeFromWaste {
+		p.ExcludeFromWaste = true
+	}
+	if override.CostCenter != "" {
+		p.CostCenter = override.CostCenter
+	}
+	if override.GradeThresholds != nil {
+		p.GradeThresholds = override.GradeThresholds
+	}
+	return p
+}
+
+// CalculateCostWithPolicy is CalculateCost honoring policy: excluded workloads have usage equal to
+// billable (so billable = usage + waste still holds) and the grade uses the policy thresholds.
+func CalculateCostWithPolicy(rm ResourceMetric, corePrice, memPrice float64, policy CostPolicy) (CostResult, error) {
+	return CalculateWithPolicy(DefaultCalculator, rm, corePrice, memPrice, policy)
+}
+
+// CalculateWithPolicy is CalculateCostWithPolicy with calc (nil: DefaultCalculator) pricing the
+// metrics. Results of calc that break the cost invariants are rejected.
+func CalculateWithPolicy(calc CostCalculator, rm ResourceMetric, corePrice, memPrice float64, policy CostPolicy) (CostResult, error) {
+	if calc == nil {
+		calc = DefaultCalculator
+	}
+	result, err := calc.CalculateCost(rm, corePrice, memPrice)
+	if err != nil {
+		return result, err
+	}
+	if err := checkResult(result); err != nil {
+		return CostResult{}, err
+	}
+	if policy.ExcludeFromWaste {
+		result.CPUUsageCost, result.CPUWasteCost = result.CPUBillableCost, 0
+		result.MemUsageCost, result.MemWasteCost = result.MemBillableCost, 0
+		result.TotalUsageCost, result.TotalWasteCost = result.TotalBillableCost, 0
+		result.CPUEfficiencyScore, result.MemEfficiencyScore, result.OverallEfficiencyScore = 100, 100, 100
+	}
+	result.OverallGrade = policy.Thresholds().Grade(result.OverallEfficiencyScore)
+	return result, nil
+}
+
+// RecommendWithPolicy is Recommend honoring policy: excluded workloads only get upsize proposals.
+func RecommendWithPolicy(grade EfficiencyGrade, usage WorkloadUsage, policy CostPolicy) []Recommendation {
+	recs := Recommend(grade, usage)
+	if !policy.ExcludeFromWaste {
+		return recs
+	}
+	kept := recs[:0]
+	for _, r := range recs {
+		if r.Action == ActionUpsize {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
@@ -0,0 +1,67 @@
+package costmodel
+
+import "testing"
+
+func TestGradeThresholds(t *testing.T) {
+	strict := GradeThresholds{Zombie: 5, OverProvisioned: 20, Risk: 95}
+	if err := strict.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := (GradeThresholds{Zombie: 50, OverProvisioned: 20, Risk: 95}).Validate(); err == nil {
+		t.Error("expected unordered thresholds to be rejected")
+	}
+	cases := []struct {
+		score    float64
+		expected EfficiencyGrade
+	}{
+		{4, GradeZombie},
+		{8, GradeOverProvisioned}, // 默认阈值下为 Zombie
+		{30, GradeHealthy},        // 默认阈值下为 OverProvisioned
+		{93, GradeHealthy},        // 默认阈值下为 Risk
+		{96, GradeRisk},
+	}
+	for _, tc := range cases {
+		if got := strict.Grade(tc.score); got != tc.expected {
+			t.Errorf("Grade(%.0f) = %v, 期望 %v", tc.score, got, tc.expected)
+		}
+	}
+	if got := strict.GradeWithHysteresis(22, GradeOverProvisioned, DefaultGradeHysteresis); got != GradeOverProvisioned {
+		t.Errorf("hysteresis should use the overridden band, got %v", got)
+	}
+}
+
+func TestCalculateCostWithPolicy(t *testing.T) {
+	rm := ResourceMetric{CPURequest: 4, CPUUsageP95: 0.2, MemRequest: 4 << 30, MemUsageP95: 1 << 28}
+	plain, _ := CalculateCost(rm, 0.1, 0.01)
+	if plain.OverallGrade != GradeZombie {
+		t.Fatalf("expected a zombie without policy, got %v", plain.OverallGrade)
+	}
+
+	excluded, err := CalculateCostWithPolicy(rm, 0.1, 0.01, CostPolicy{ExcludeFromWaste: true})
+	if err != nil {
+		t.Fatalf("CalculateCostWithPolicy: %v", err)
+	}
+	if excluded.TotalWasteCost != 0 || excluded.TotalUsageCost != excluded.TotalBillableCost || excluded.OverallGrade != GradeHealthy {
+		t.Errorf("excluded workload should have no waste, got %+v", excluded)
+	}
+
+	lenient, _ := CalculateCostWithPolicy(rm, 0.1, 0.01, CostPolicy{GradeThresholds: &GradeThresholds{Zombie: 1, OverProvisioned: 3, Risk: 90}})
+	if lenient.OverallGrade != GradeHealthy || lenient.TotalWasteCost != plain.TotalWasteCost {
+		t.Errorf("threshold override should only change the grade, got %+v", lenient)
+	}
+}
+
+func TestRecommendWithPolicy(t *testing.T) {
+	usage := WorkloadUsage{CPURequest: 4, CPUUsageP95: 1, CPUHourlyCost: 0.4}
+	if recs := RecommendWithPolicy(GradeOverProvisioned, usage, CostPolicy{}); len(recs) != 1 {
+		t.Fatalf("expected a downsize without policy, got %+v", recs)
+	}
+	policy := CostPolicy{ExcludeFromWaste: true}
+	if recs := RecommendWithPolicy(GradeZombie, usage, policy); len(recs) != 0 {
+		t.Errorf("excluded workloads must not be decommissioned, got %+v", recs)
+	}
+	risk := WorkloadUsage{CPURequest: 1, CPUUsageP95: 0.98, CPUHourlyCost: 0.1}
+	if recs := RecommendWithPolicy(GradeRisk, risk, policy); len(recs) != 1 || recs[0].Action != ActionUpsize {
+		t.Errorf("excluded workloads still get upsize proposals, got %+v", recs)
+	}
+}
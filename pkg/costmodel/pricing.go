@@ -0,0 +1,61 @@
+// Package costmodel pricing.go: unit price history, so each window is priced with the price in effect at the time.
+package costmodel
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// UnitPrice is a CPU/memory price effective from EffectiveFrom until the next price takes effect.
+type UnitPrice struct {
+	EffectiveFrom       time.Time `json:"effective_from"`
+	CPUPricePerCoreHour float64   `json:"cpu_price_per_core_hour"`
+	MemPricePerGBHour   float64   `json:"mem_price_per_gb_hour"`
+}
+
+// Validate checks that both prices are positive and the effective time is set.
+func (p UnitPrice) Validate() error {
+	if p.EffectiveFrom.IsZero() {
+		return fmt.Errorf("effective_from is required")
+	}
+	if p.CPUPricePerCoreHour <= 0 || p.MemPricePerGBHour <= 0 {
+		return fmt.Errorf("prices must be positive, got cpu=%v mem=%v", p.CPUPricePerCoreHour, p.MemPricePerGBHour)
+	}
+	return nil
+}
+
+// PriceHistory is a set of unit prices; order does not matter.
+type PriceHistory []UnitPrice
+
+// At returns the price in effect at t: the one with the latest EffectiveFrom not after t.
+// ok is false when no price was in effect yet.
+func (h PriceHistory) At(t time.Time) (price UnitPrice, ok bool) {
+	for _, p := range h {
+		if p.EffectiveFrom.After(t) {
+			continue
+		}
+		if !ok || p.EffectiveFrom.After(price.EffectiveFrom) {
+			price, ok = p, true
+		}
+	}
+	return price, ok
+}
+
+// EffectiveUntil returns when the price effective from `from` is superseded, i.e. the earliest
+// EffectiveFrom after it; ok is false when it is still in effect.
+func (h PriceHistory) EffectiveUntil(from time.Time) (until time.Time, ok bool) {
+	for _, p := range h {
+		if p.EffectiveFrom.After(from) && (!ok || p.EffectiveFrom.Before(until)) {
+			until, ok = p.EffectiveFrom, true
+		}
+	}
+	return until, ok
+}
+
+// Sorted returns a copy ordered by EffectiveFrom.
+func (h PriceHistory) Sorted() PriceHistory {
+	out := append(PriceHistory(nil), h...)
+	sort.Slice(out, func(i, j int) bool { return out[i].EffectiveFrom.Before(out[j].EffectiveFrom) })
+	return out
+}
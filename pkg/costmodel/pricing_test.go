@@ -0,0 +1,36 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriceHistory_At(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	h := PriceHistory{
+		{EffectiveFrom: mar, CPUPricePerCoreHour: 0.2, MemPricePerGBHour: 0.02},
+		{EffectiveFrom: jan, CPUPricePerCoreHour: 0.1, MemPricePerGBHour: 0.01},
+	}
+	if _, ok := h.At(jan.Add(-time.Hour)); ok {
+		t.Error("no price should be in effect before the first version")
+	}
+	if p, ok := h.At(jan); !ok || p.CPUPricePerCoreHour != 0.1 {
+		t.Errorf("At(jan) = %+v, %v; want the january price", p, ok)
+	}
+	if p, _ := h.At(mar.Add(-time.Hour)); p.CPUPricePerCoreHour != 0.1 {
+		t.Errorf("price before march = %v, want 0.1", p.CPUPricePerCoreHour)
+	}
+	if p, _ := h.At(mar.AddDate(1, 0, 0)); p.CPUPricePerCoreHour != 0.2 {
+		t.Errorf("price after march = %v, want 0.2", p.CPUPricePerCoreHour)
+	}
+	if until, ok := h.EffectiveUntil(jan); !ok || !until.Equal(mar) {
+		t.Errorf("EffectiveUntil(jan) = %v, %v; want march", until, ok)
+	}
+	if _, ok := h.EffectiveUntil(mar); ok {
+		t.Error("the latest price should still be in effect")
+	}
+	if s := h.Sorted(); !s[0].EffectiveFrom.Equal(jan) {
+		t.Errorf("Sorted()[0] = %v, want january", s[0].EffectiveFrom)
+	}
+}
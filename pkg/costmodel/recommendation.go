@@ -0,0 +1,134 @@
+// Package costmodel recommendation.go: rightsizing and decommission recommendations derived from grades.
+package costmodel
+
+import (
+	"fmt"
+	"math"
+)
+
+// HoursPerMonth is the average number of hours in a month used for monthly estimates.
+const HoursPerMonth = 730.0
+
+// DefaultRightsizingHeadroom is the buffer added on top of P95 usage when recommending requests.
+const DefaultRightsizingHeadroom = 0.2
+
+// RecommendationAction is the kind of optimization proposed for a workload.
+type RecommendationAction string
+
+const (
+	// ActionDecommission proposes removing an idle (Zombie) workload.
+	ActionDecommission RecommendationAction = "decommission"
+	// ActionDownsize proposes lowering requests of an over-provisioned workload.
+	ActionDownsize RecommendationAction = "downsize"
+	// ActionUpsize proposes raising requests of a workload at OOM/throttling risk.
+	ActionUpsize RecommendationAction = "upsize"
+)
+
+// WorkloadUsage is the current request/usage picture of a workload used for recommendations.
+// Hourly costs are the billable cost of one hour at the current requests.
+type WorkloadUsage struct {
+	CPURequest          float64 // cores
+	CPUUsageP95         float64 // cores
+	MemRequest          int64   // bytes
+	MemUsageP95         int64   // bytes
+	CPUHourlyCost       float64
+	MemHourlyCost       float64
+	RightsizingHeadroom float64 // 0 uses DefaultRightsizingHeadroom
+}
+
+// Recommendation is one optimization proposal. Resource is "cpu", "memory" or "workload";
+// EstimatedMonthlySavings is negative for upsizing (extra cost). Safety is set on reductions that
+// went through CheckSafety.
+type Recommendation struct {
+	Action                  RecommendationAction  `json:"action"`
+	Resource                string                `json:"resource"`
+	CurrentRequest          float64               `json:"current_request"`
+	RecommendedRequest      float64               `json:"recommended_request"`
+	EstimatedMonthlySavings float64               `json:"estimated_monthly_savings"`
+	Reason                  string                `json:"reason"`
+	Safety                  *RecommendationSafety `json:"safety,omitempty"`
+}
+
+// Recommend derives recommendations from the grade and current usage:
+// Zombie -> decommission; OverProvisioned -> downsize requests to P95 plus headroom;
+// Risk -> upsize requests to P95 plus headroom. Healthy workloads get none.
+func Recommend(grade EfficiencyGrade, usage WorkloadUsage) []Recommendation {
+	headroom := usage.RightsizingHeadroom
+	if headroom <= 0 {
+		headroom = DefaultRightsizingHeadroom
+	}
+	switch grade {
+	case GradeZombie:
+		return []Recommendation{{
+			Action:                  ActionDecommission,
+			Resource:                "workload",
+			EstimatedMonthlySavings: roundToPrecision((usage.CPUHourlyCost+usage.MemHourlyCost)*HoursPerMonth, 2),
+			Reason:                  "utilization below 10% of requests; scale to zero or remove",
+		}}
+	case GradeOverProvisioned, GradeRisk:
+		var recs []Recommendation
+		if r, ok := rightsize("cpu", usage.CPURequest, usage.CPUUsageP95, headroom, usage.CPUHourlyCost); ok {
+			recs = append(recs, r)
+		}
+		if r, ok := rightsize("memory", float64(usage.MemRequest), float64(usage.MemUsageP95), headroom, usage.MemHourlyCost); ok {
+			recs = append(recs, r)
+		}
+		return recs
+	default:
+		return nil
+	}
+}
+
+// rightsize proposes P95*(1+headroom) as the new request when it differs from the current one by
+// more than 10%. Savings scale the hourly cost linearly with the request.
+func rightsize(resource string, request, p95, headroom, hourlyCost float64) (Recommendation, bool) {
+	if request <= 0 || p95 <= 0 {
+		return Recommendation{}, false
+	}
+	target := p95 * (1 + headroom)
+	if math.Abs(target-request)/request <= 0.1 {
+		return Recommendation{}, false
+	}
+	action := ActionDownsize
+	if target > request {
+		action = ActionUpsize
+	}
+	if resource == "memory" {
+		target = math.Ceil(target)
+	} else {
+		target = roundToPrecision(target, 3)
+	}
+	return Recommendation{
+		Action:                  action,
+		Resource:                resource,
+		CurrentRequest:          request,
+		RecommendedRequest:      target,
+		EstimatedMonthlySavings: roundToPrecision(hourlyCost*(1-target/request)*HoursPerMonth, 2),
+		Reason:                  fmt.Sprintf("p95 usage is %.0f%% of request; target p95 + %.0f%% headroom", p95/request*100, headroom*100),
+	}, true
+}
+
+// AdoptionStatus is how far an issued recommendation has been applied.
+type AdoptionStatus string
+
+const (
+	AdoptionOpen    AdoptionStatus = "open"    // requests unchanged (or moved away from the target)
+	AdoptionPartial AdoptionStatus = "partial" // moved towards the target
+	AdoptionApplied AdoptionStatus = "applied" // within 10% of the change, or past the target
+)
+
+// Adoption returns the fraction in [0, 1] of the change from baseline (the request when the
+// recommendation was issued) to recommended that the current request has realized, and its status.
+func Adoption(baseline, recommended, current float64) (float64, AdoptionStatus) {
+	fraction := 1.0
+	if change := recommended - baseline; change != 0 {
+		fraction = math.Max(0, math.Min(1, (current-baseline)/change))
+	}
+	switch {
+	case fraction >= 0.9:
+		return fraction, AdoptionApplied
+	case fraction > 0:
+		return fraction, AdoptionPartial
+	}
+	return fraction, AdoptionOpen
+}
@@ -0,0 +1,67 @@
+package costmodel
+
+import "testing"
+
+func TestRecommend(t *testing.T) {
+	const gib = 1 << 30
+	usage := WorkloadUsage{
+		CPURequest:    4,
+		CPUUsageP95:   1,
+		MemRequest:    8 * gib,
+		MemUsageP95:   2 * gib,
+		CPUHourlyCost: 0.4,
+		MemHourlyCost: 0.08,
+	}
+
+	recs := Recommend(GradeOverProvisioned, usage)
+	if len(recs) != 2 {
+		t.Fatalf("expected cpu and memory recommendations, got %+v", recs)
+	}
+	cpu := recs[0]
+	if cpu.Action != ActionDownsize || cpu.Resource != "cpu" || !FloatEquals(cpu.RecommendedRequest, 1.2, 0.001) {
+		t.Errorf("unexpected cpu recommendation: %+v", cpu)
+	}
+	// 4 -> 1.2 cores saves 70% of 0.4/h
+	if !FloatEquals(cpu.EstimatedMonthlySavings, 0.4*0.7*HoursPerMonth, 0.01) {
+		t.Errorf("cpu savings = %.2f", cpu.EstimatedMonthlySavings)
+	}
+
+	zombie := Recommend(GradeZombie, usage)
+	if len(zombie) != 1 || zombie[0].Action != ActionDecommission || !FloatEquals(zombie[0].EstimatedMonthlySavings, 0.48*HoursPerMonth, 0.01) {
+		t.Errorf("unexpected zombie recommendation: %+v", zombie)
+	}
+
+	risk := Recommend(GradeRisk, WorkloadUsage{CPURequest: 1, CPUUsageP95: 0.98, CPUHourlyCost: 0.1})
+	if len(risk) != 1 || risk[0].Action != ActionUpsize || risk[0].EstimatedMonthlySavings >= 0 {
+		t.Errorf("unexpected risk recommendation: %+v", risk)
+	}
+
+	if recs := Recommend(GradeHealthy, usage); len(recs) != 0 {
+		t.Errorf("healthy workloads get no recommendations, got %+v", recs)
+	}
+	// 目标与当前请求相差不足 10% 时不建议调整
+	if recs := Recommend(GradeOverProvisioned, WorkloadUsage{CPURequest: 1.2, CPUUsageP95: 0.95}); len(recs) != 0 {
+		t.Errorf("expected no recommendation within 10%%, got %+v", recs)
+	}
+}
+
+func TestAdoption(t *testing.T) {
+	cases := []struct {
+		baseline, recommended, current float64
+		fraction                       float64
+		status                         AdoptionStatus
+	}{
+		{4, 1.2, 4, 0, AdoptionOpen},
+		{4, 1.2, 5, 0, AdoptionOpen}, // 反向调整
+		{4, 1.2, 2.6, 0.5, AdoptionPartial},
+		{4, 1.2, 1.4, 2.6 / 2.8, AdoptionApplied},
+		{4, 1.2, 1, 1, AdoptionApplied}, // 超过目标
+		{1, 1.5, 1.5, 1, AdoptionApplied},
+	}
+	for _, c := range cases {
+		fraction, status := Adoption(c.baseline, c.recommended, c.current)
+		if !FloatEquals(fraction, c.fraction, 0.001) || status != c.status {
+			t.Errorf("Adoption(%v, %v, %v) = %v, %s; want %v, %s", c.baseline, c.recommended, c.current, fraction, status, c.fraction, c.status)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package costmodel reliability.go: the "reliability tax" of a workload — spend wasted on pods that
+// are evicted, OOM-killed or crash-looping and have to restart before they serve again.
+package costmodel
+
+import (
+	"math"
+	"time"
+)
+
+// RestartCause is why a pod had to restart.
+type RestartCause string
+
+const (
+	RestartEviction RestartCause = "eviction"   // evicted by the kubelet (node pressure) or preempted
+	RestartOOMKill  RestartCause = "oom_kill"   // container killed for exceeding its memory limit
+	RestartCrash    RestartCause = "crash_loop" // container exited and is restarted with back-off
+)
+
+// DefaultRestartPenalties is how long a pod is billed without serving after one restart: an
+// evicted pod is rescheduled, pulled and warmed up, an OOM-killed or crashed container restarts in
+// place (back-off included).
+var DefaultRestartPenalties = map[RestartCause]time.Duration{
+	RestartEviction: 10 * time.Minute,
+	RestartOOMKill:  5 * time.Minute,
+	RestartCrash:    5 * time.Minute,
+}
+
+// RestartCauseOf classifies a Kubernetes event reason; ok is false for reasons that are not restarts.
+func RestartCauseOf(reason string) (cause RestartCause, ok bool) {
+	switch reason {
+	case "Evicted", "Preempted", "Preempting":
+		return RestartEviction, true
+	case "OOMKilling", "OOMKilled":
+		return RestartOOMKill, true
+	case "BackOff", "CrashLoopBackOff":
+		return RestartCrash, true
+	}
+	return "", false
+}
+
+// ReliabilityTax is the restart waste of a workload over a window. Share is WastedCost relative
+// to the billable cost of the window in %.
+type ReliabilityTax struct {
+	Restarts   map[RestartCause]int `json:"restarts"`
+	WastedCost float64              `json:"wasted_cost"`
+	Share      float64              `json:"share"`
+}
+
+// ComputeReliabilityTax prices restarts at podHourlyCost for the penalty of their cause (nil
+// penalties use DefaultRestartPenalties). billableCost is the cost of the window the restarts
+// happened in; the waste never exceeds it.
+func ComputeReliabilityTax(restarts map[RestartCause]int, podHourlyCost, billableCost float64, penalties map[RestartCause]time.Duration) ReliabilityTax {
+	if penalties == nil {
+		penalties = DefaultRestartPenalties
+	}
+	tax := ReliabilityTax{Restarts: make(map[RestartCause]int, len(restarts))}
+	for cause, n := range restarts {
+		if n <= 0 {
+			continue
+		}
+		tax.Restarts[cause] = n
+		if podHourlyCost > 0 {
+			tax.WastedCost += float64(n) * penalties[cause].Hours() * podHourlyCost
+		}
+	}
+	if tax.WastedCost > billableCost {
+		tax.WastedCost = math.Max(billableCost, 0)
+	}
+	if billableCost > 0 {
+		tax.Share = roundToPrecision(tax.WastedCost/billableCost*100, 2)
+	}
+	tax.WastedCost = roundToPrecision(tax.WastedCost, 2)
+	return tax
+}
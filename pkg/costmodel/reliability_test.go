@@ -0,0 +1,29 @@
+package costmodel
+
+import "testing"
+
+func TestRestartCauseOf(t *testing.T) {
+	cases := map[string]RestartCause{"Evicted": RestartEviction, "OOMKilling": RestartOOMKill, "BackOff": RestartCrash, "CrashLoopBackOff": RestartCrash}
+	for reason, want := range cases {
+		if got, ok := RestartCauseOf(reason); !ok || got != want {
+			t.Errorf("RestartCauseOf(%q) = %q, %v; want %q", reason, got, ok, want)
+		}
+	}
+	if _, ok := RestartCauseOf("Scheduled"); ok {
+		t.Error("Scheduled is not a restart")
+	}
+}
+
+func TestComputeReliabilityTax(t *testing.T) {
+	// 6 evictions × 10 min + 12 OOM kills × 5 min = 2 pod-hours at 1.5/h
+	tax := ComputeReliabilityTax(map[RestartCause]int{RestartEviction: 6, RestartOOMKill: 12, RestartCrash: 0}, 1.5, 100, nil)
+	if tax.WastedCost != 3 || tax.Share != 3 || len(tax.Restarts) != 2 {
+		t.Errorf("tax = %+v", tax)
+	}
+	if capped := ComputeReliabilityTax(map[RestartCause]int{RestartCrash: 1000}, 10, 50, nil); capped.WastedCost != 50 || capped.Share != 100 {
+		t.Errorf("waste should be capped at the billable cost, got %+v", capped)
+	}
+	if none := ComputeReliabilityTax(nil, 1, 0, nil); none.WastedCost != 0 || none.Share != 0 {
+		t.Errorf("no restarts = %+v", none)
+	}
+}
@@ -0,0 +1,88 @@
+// Package costmodel resolution.go: the query planner of cost time series — which granularity (and so
+// which rollup table) serves a time range, so long ranges read days or months instead of every hour.
+package costmodel
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resolution is the granularity of a cost time series.
+type Resolution string
+
+const (
+	ResolutionHourly  Resolution = "hourly"  // one point per hour, from the hourly workload stats
+	ResolutionDaily   Resolution = "daily"   // one point per accounting day, from the daily rollups
+	ResolutionMonthly Resolution = "monthly" // one point per accounting month, folded from the daily rollups
+)
+
+// Ranges up to which PlanResolution picks the finer resolutions; longer ranges are monthly.
+const (
+	MaxHourlyRange = 72 * time.Hour
+	MaxDailyRange  = 92 * 24 * time.Hour
+)
+
+// MaxSeriesPoints bounds the points of a series with an explicitly requested resolution
+// (about six weeks of hours or two and a half years of days).
+const MaxSeriesPoints = 1000
+
+// PlanResolution returns the resolution a series over start..end is served at: hourly up to
+// MaxHourlyRange (e.g. 48 hours), daily up to MaxDailyRange (e.g. 30 days), monthly beyond
+// (e.g. 12 months).
+func PlanResolution(start, end time.Time) Resolution {
+	switch span := end.Sub(start); {
+	case span <= MaxHourlyRange:
+		return ResolutionHourly
+	case span <= MaxDailyRange:
+		return ResolutionDaily
+	default:
+		return ResolutionMonthly
+	}
+}
+
+// ParseResolution parses a requested resolution; "" is returned as is (let the planner decide).
+func ParseResolution(s string) (Resolution, error) {
+	switch r := Resolution(s); r {
+	case "", ResolutionHourly, ResolutionDaily, ResolutionMonthly:
+		return r, nil
+	}
+	return "", fmt.Errorf("resolution must be hourly, daily or monthly, got %q", s)
+}
+
+// Step returns the nominal length of one point (a month counts as 30 days).
+func (r Resolution) Step() time.Duration {
+	switch r {
+	case ResolutionHourly:
+		return time.Hour
+	case ResolutionDaily:
+		return 24 * time.Hour
+	default:
+		return 30 * 24 * time.Hour
+	}
+}
+
+// CheckRange returns an error when a series over start..end at r would exceed MaxSeriesPoints.
+func (r Resolution) CheckRange(start, end time.Time) error {
+	if n := int(end.Sub(start) / r.Step()); n > MaxSeriesPoints {
+		return fmt.Errorf("%s resolution over %s would return %d points (at most %d); use a coarser resolution or a shorter range",
+			r, end.Sub(start), n, MaxSeriesPoints)
+	}
+	return nil
+}
+
+// Bucket returns the timestamp of the point t belongs to: the hour for hourly, the accounting day
+// label for daily and the first day of its month for monthly. For daily and monthly series t is
+// already an accounting day label (see AccountingCalendar).
+func (r Resolution) Bucket(t time.Time) time.Time {
+	t = t.UTC()
+	switch r {
+	case ResolutionHourly:
+		return t.Truncate(time.Hour)
+	case ResolutionDaily:
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	default:
+		y, m, _ := t.Date()
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
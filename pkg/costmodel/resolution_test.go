@@ -0,0 +1,47 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanResolution(t *testing.T) {
+	end := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		start time.Time
+		want  Resolution
+	}{
+		{end.Add(-48 * time.Hour), ResolutionHourly},
+		{end.Add(-MaxHourlyRange), ResolutionHourly},
+		{end.AddDate(0, 0, -30), ResolutionDaily},
+		{end.AddDate(0, -12, 0), ResolutionMonthly},
+	}
+	for _, tc := range cases {
+		if got := PlanResolution(tc.start, end); got != tc.want {
+			t.Errorf("PlanResolution(%s) = %s, want %s", end.Sub(tc.start), got, tc.want)
+		}
+	}
+
+	if err := ResolutionHourly.CheckRange(end.AddDate(0, -12, 0), end); err == nil {
+		t.Error("hourly over a year: want an error")
+	}
+	if err := ResolutionDaily.CheckRange(end.AddDate(-1, 0, 0), end); err != nil {
+		t.Errorf("daily over a year: %v", err)
+	}
+	if _, err := ParseResolution("weekly"); err == nil {
+		t.Error("ParseResolution(weekly): want an error")
+	}
+}
+
+func TestResolutionBucket(t *testing.T) {
+	ts := time.Date(2026, 3, 14, 15, 30, 0, 0, time.UTC)
+	for r, want := range map[Resolution]time.Time{
+		ResolutionHourly:  time.Date(2026, 3, 14, 15, 0, 0, 0, time.UTC),
+		ResolutionDaily:   time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		ResolutionMonthly: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if got := r.Bucket(ts); !got.Equal(want) {
+			t.Errorf("%s bucket = %v, want %v", r, got, want)
+		}
+	}
+}
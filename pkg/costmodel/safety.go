@@ -0,0 +1,111 @@
+// Package costmodel safety.go: safety checks of right-sizing — before requests are reduced, the
+// recent CPU throttling and the SLO of the workload decide whether the reduction is proposed as is,
+// flagged for review or suppressed. The rationale travels with the recommendation.
+package costmodel
+
+import "fmt"
+
+// SafetyStatus is the outcome of the safety checks of a reduction.
+type SafetyStatus string
+
+const (
+	SafetyPassed     SafetyStatus = "passed"     // no risk signal
+	SafetyFlagged    SafetyStatus = "flagged"    // proposed, but review the signals before applying
+	SafetySuppressed SafetyStatus = "suppressed" // not proposed
+)
+
+// SafetySignals are the recent reliability signals of a workload.
+type SafetySignals struct {
+	// CPUThrottlingRate is the average share (%) of CFS periods its containers were throttled in;
+	// nil when not measured.
+	CPUThrottlingRate *float64
+	// SLOStatus is the status of its SLO (healthy / warning / critical); empty when it has none.
+	SLOStatus string
+	// ErrorBudgetRemaining is the remaining error budget (%) of its SLO; nil when it has none.
+	ErrorBudgetRemaining *float64
+}
+
+// SafetyLimits are the thresholds of the safety checks; zero fields use DefaultSafetyLimits.
+type SafetyLimits struct {
+	ThrottlingFlagRate     float64 // CPU throttling (%) from which CPU reductions are flagged
+	ThrottlingSuppressRate float64 // CPU throttling (%) from which CPU reductions are suppressed
+	MinErrorBudget         float64 // remaining error budget (%) below which reductions are flagged
+}
+
+// DefaultSafetyLimits flag CPU reductions from 5% throttling, suppress them from 25%, and flag any
+// reduction once less than 20% of the error budget is left.
+var DefaultSafetyLimits = SafetyLimits{ThrottlingFlagRate: 5, ThrottlingSuppressRate: 25, MinErrorBudget: 20}
+
+func (l SafetyLimits) withDefaults() SafetyLimits {
+	if l.ThrottlingFlagRate <= 0 {
+		l.ThrottlingFlagRate = DefaultSafetyLimits.ThrottlingFlagRate
+	}
+	if l.ThrottlingSuppressRate <= 0 {
+		l.ThrottlingSuppressRate = DefaultSafetyLimits.ThrottlingSuppressRate
+	}
+	if l.MinErrorBudget <= 0 {
+		l.MinErrorBudget = DefaultSafetyLimits.MinErrorBudget
+	}
+	return l
+}
+
+// RecommendationSafety records the safety checks of a reduction: the outcome, why, and the signals
+// it was decided on.
+type RecommendationSafety struct {
+	Status               SafetyStatus `json:"status"`
+	Reasons              []string     `json:"reasons,omitempty"`
+	CPUThrottlingRate    *float64     `json:"cpu_throttling_rate,omitempty"`
+	SLOStatus            string       `json:"slo_status,omitempty"`
+	ErrorBudgetRemaining *float64     `json:"error_budget_remaining,omitempty"`
+}
+
+// CheckSafety runs the safety checks on the reductions (downsize, decommission) of recs and records
+// them in their Safety; upsizing is never held back. A critical SLO suppresses every reduction, a
+// warning SLO or a low error budget flags it. CPU throttling applies to CPU downsizing and
+// decommissioning (a throttled workload is not idle): flagged from ThrottlingFlagRate, suppressed
+// from ThrottlingSuppressRate. Suppressed recommendations are returned apart from the proposed ones.
+func CheckSafety(recs []Recommendation, signals SafetySignals, limits SafetyLimits) (proposed, suppressed []Recommendation) {
+	limits = limits.withDefaults()
+	for _, r := range recs {
+		if r.Action != ActionDownsize && r.Action != ActionDecommission {
+			proposed = append(proposed, r)
+			continue
+		}
+		safety := &RecommendationSafety{
+			Status:               SafetyPassed,
+			CPUThrottlingRate:    signals.CPUThrottlingRate,
+			SLOStatus:            signals.SLOStatus,
+			ErrorBudgetRemaining: signals.ErrorBudgetRemaining,
+		}
+		raise := func(status SafetyStatus, reason string) {
+			if status == SafetySuppressed || safety.Status == SafetyPassed {
+				safety.Status = status
+			}
+			safety.Reasons = append(safety.Reasons, reason)
+		}
+		switch signals.SLOStatus {
+		case "critical":
+			raise(SafetySuppressed, "SLO is violated; do not reduce capacity until it recovers")
+		case "warning":
+			raise(SafetyFlagged, "SLO is degraded")
+		}
+		if b := signals.ErrorBudgetRemaining; b != nil && *b < limits.MinErrorBudget {
+			raise(SafetyFlagged, fmt.Sprintf("only %.0f%% of the error budget is left", *b))
+		}
+		if t := signals.CPUThrottlingRate; t != nil && r.Resource != "memory" {
+			switch {
+			case *t >= limits.ThrottlingSuppressRate:
+				raise(SafetySuppressed, fmt.Sprintf("CPU throttled in %.1f%% of periods; fewer cores would throttle it further", *t))
+			case *t >= limits.ThrottlingFlagRate:
+				raise(SafetyFlagged, fmt.Sprintf("CPU throttled in %.1f%% of periods", *t))
+			}
+		}
+		r.Safety = safety
+		if safety.Status == SafetySuppressed {
+			suppressed = append(suppressed, r)
+		} else {
+			proposed = append(proposed, r)
+		}
+	}
+	return proposed, suppressed
+}
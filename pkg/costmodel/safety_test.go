@@ -0,0 +1,62 @@
+package costmodel
+
+import "testing"
+
+func TestCheckSafety(t *testing.T) {
+	const gib = 1 << 30
+	usage := WorkloadUsage{CPURequest: 4, CPUUsageP95: 1, MemRequest: 8 * gib, MemUsageP95: 2 * gib, CPUHourlyCost: 0.4, MemHourlyCost: 0.08}
+	recs := Recommend(GradeOverProvisioned, usage)
+	rate := func(v float64) *float64 { return &v }
+
+	proposed, suppressed := CheckSafety(recs, SafetySignals{}, SafetyLimits{})
+	if len(proposed) != 2 || len(suppressed) != 0 {
+		t.Fatalf("no signals: proposed %d, suppressed %d; want 2, 0", len(proposed), len(suppressed))
+	}
+	for _, r := range proposed {
+		if r.Safety == nil || r.Safety.Status != SafetyPassed || len(r.Safety.Reasons) != 0 {
+			t.Errorf("%s: safety = %+v, want passed without reasons", r.Resource, r.Safety)
+		}
+	}
+	if recs[0].Safety != nil {
+		t.Error("CheckSafety must not modify its input")
+	}
+
+	// 只有 CPU 受限流影响：内存缩容照常提出
+	proposed, suppressed = CheckSafety(recs, SafetySignals{CPUThrottlingRate: rate(30)}, SafetyLimits{})
+	if len(proposed) != 1 || proposed[0].Resource != "memory" || proposed[0].Safety.Status != SafetyPassed {
+		t.Errorf("heavy throttling: proposed %+v, want memory only", proposed)
+	}
+	if len(suppressed) != 1 || suppressed[0].Resource != "cpu" || suppressed[0].Safety.Status != SafetySuppressed ||
+		*suppressed[0].Safety.CPUThrottlingRate != 30 || len(suppressed[0].Safety.Reasons) != 1 {
+		t.Errorf("heavy throttling: suppressed %+v, want cpu with rationale", suppressed)
+	}
+	proposed, _ = CheckSafety(recs, SafetySignals{CPUThrottlingRate: rate(8)}, SafetyLimits{})
+	if proposed[0].Safety.Status != SafetyFlagged {
+		t.Errorf("moderate throttling: cpu safety = %+v, want flagged", proposed[0].Safety)
+	}
+	proposed, _ = CheckSafety(recs, SafetySignals{CPUThrottlingRate: rate(8)}, SafetyLimits{ThrottlingFlagRate: 10})
+	if proposed[0].Safety.Status != SafetyPassed {
+		t.Errorf("throttling below custom limit: cpu safety = %+v, want passed", proposed[0].Safety)
+	}
+
+	// SLO 信号作用于所有缩容；限流已抑制时不会被降级为 flagged
+	budget := 8.0
+	proposed, suppressed = CheckSafety(recs, SafetySignals{SLOStatus: "warning", ErrorBudgetRemaining: &budget, CPUThrottlingRate: rate(40)}, SafetyLimits{})
+	if len(proposed) != 1 || proposed[0].Safety.Status != SafetyFlagged || len(proposed[0].Safety.Reasons) != 2 {
+		t.Errorf("warning SLO: memory safety = %+v, want flagged for SLO and budget", proposed)
+	}
+	if len(suppressed) != 1 || suppressed[0].Safety.Status != SafetySuppressed || len(suppressed[0].Safety.Reasons) != 3 {
+		t.Errorf("warning SLO with throttling: cpu = %+v, want suppressed", suppressed)
+	}
+	proposed, suppressed = CheckSafety(Recommend(GradeZombie, usage), SafetySignals{SLOStatus: "critical"}, SafetyLimits{})
+	if len(proposed) != 0 || len(suppressed) != 1 || suppressed[0].Action != ActionDecommission {
+		t.Errorf("critical SLO: proposed %+v, suppressed %+v; want decommission suppressed", proposed, suppressed)
+	}
+
+	// 扩容不受安全检查影响
+	upsize := Recommend(GradeRisk, WorkloadUsage{CPURequest: 1, CPUUsageP95: 0.98, CPUHourlyCost: 0.1})
+	proposed, suppressed = CheckSafety(upsize, SafetySignals{SLOStatus: "critical", CPUThrottlingRate: rate(50)}, SafetyLimits{})
+	if len(proposed) != 1 || len(suppressed) != 0 || proposed[0].Safety != nil {
+		t.Errorf("upsize: proposed %+v, suppressed %+v; want upsize untouched", proposed, suppressed)
+	}
+}
@@ -0,0 +1,145 @@
+// Package costmodel sanitize.go: detection of NaN/Inf values at metric ingestion and at the
+// aggregation boundary (WithNonFinitePolicy), with a configurable policy and counters.
+package costmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrNonFinite is wrapped by the errors returned for NaN/Inf inputs.
+var ErrNonFinite = errors.New("non-finite value")
+
+// NonFinitePolicy selects what happens to a metric with a NaN/Inf value.
+type NonFinitePolicy int
+
+const (
+	// NonFiniteZero replaces the value with 0 and counts it as sanitized (default).
+	NonFiniteZero NonFinitePolicy = iota
+	// NonFiniteDrop skips the metric row and counts it as dropped.
+	NonFiniteDrop
+	// NonFiniteFail fails the calculation with an ErrNonFinite error.
+	NonFiniteFail
+)
+
+// ParseNonFinitePolicy parses "zero", "drop" or "fail"; an empty string is NonFiniteZero.
+func ParseNonFinitePolicy(s string) (NonFinitePolicy, error) {
+	switch s {
+	case "", "zero":
+		return NonFiniteZero, nil
+	case "drop":
+		return NonFiniteDrop, nil
+	case "fail":
+		return NonFiniteFail, nil
+	}
+	return NonFiniteZero, fmt.Errorf("unknown non-finite policy %q (want zero, drop or fail)", s)
+}
+
+func (p NonFinitePolicy) String() string {
+	switch p {
+	case NonFiniteDrop:
+		return "drop"
+	case NonFiniteFail:
+		return "fail"
+	}
+	return "zero"
+}
+
+// SanitizeStats counts the NaN/Inf values handled during a calculation. It is not safe for
+// concurrent use.
+type SanitizeStats struct {
+	ZeroedValues int `json:"zeroed_values"` // values replaced by 0 (NonFiniteZero)
+	DroppedRows  int `json:"dropped_rows"`  // metric rows skipped (NonFiniteDrop)
+}
+
+type sanitizeStatsKey struct{}
+
+// WithSanitizeStats returns a ctx whose calculations record into stats (see SanitizeStatsFrom).
+func WithSanitizeStats(ctx context.Context, stats *SanitizeStats) context.Context {
+	return context.WithValue(ctx, sanitizeStatsKey{}, stats)
+}
+
+// SanitizeStatsFrom returns the stats attached by WithSanitizeStats, or nil.
+func SanitizeStatsFrom(ctx context.Context) *SanitizeStats {
+	stats, _ := ctx.Value(sanitizeStatsKey{}).(*SanitizeStats)
+	return stats
+}
+
+// SanitizeMetric checks the float fields of rm for NaN/Inf and applies policy. It reports whether
+// the row should be used; stats, when non-nil, records zeroed values and dropped rows.
+func SanitizeMetric(rm *ResourceMetric, policy NonFinitePolicy, stats *SanitizeStats) (bool, error) {
+	return SanitizeFields(policy, stats,
+		NonFiniteField{"cpu_request", &rm.CPURequest},
+		NonFiniteField{"cpu_usage_p95", &rm.CPUUsageP95},
+	)
+}
+
+// NonFiniteField is a float field of an input row checked by SanitizeFields.
+type NonFiniteField struct {
+	Name  string // JSON name of the field, used in the ErrNonFinite error
+	Value *float64
+}
+
+// SanitizeFields applies policy to the NaN/Inf fields of one row, like SanitizeMetric does for a
+// metric. Callers outside this package use it for rows of their own types.
+func SanitizeFields(policy NonFinitePolicy, stats *SanitizeStats, fields ...NonFiniteField) (bool, error) {
+	bad := 0
+	for _, f := range fields {
+		if isFinite(*f.Value) {
+			continue
+		}
+		switch policy {
+		case NonFiniteFail:
+			return false, fmt.Errorf("%w: %s is %v", ErrNonFinite, f.Name, *f.Value)
+		case NonFiniteZero:
+			*f.Value = 0
+		}
+		bad++
+	}
+	if bad == 0 {
+		return true, nil
+	}
+	if policy == NonFiniteDrop {
+		if stats != nil {
+			stats.DroppedRows++
+		}
+		return false, nil
+	}
+	if stats != nil {
+		stats.ZeroedValues += bad
+	}
+	return true, nil
+}
+
+// sanitizeCost is SanitizeFields over the costs of a daily namespace cost row.
+func sanitizeCost(c *DailyNamespaceCost, policy NonFinitePolicy, stats *SanitizeStats) (bool, error) {
+	return SanitizeFields(policy, stats,
+		NonFiniteField{"billable_cost", &c.BillableCost},
+		NonFiniteField{"usage_cost", &c.UsageCost},
+		NonFiniteField{"waste_cost", &c.WasteCost},
+		NonFiniteField{"shared_cost", &c.SharedCost},
+	)
+}
+
+// sanitizeWorkloadStat is SanitizeFields over the request, usage and cost fields of an hourly workload stat.
+func sanitizeWorkloadStat(st *HourlyWorkloadStat, policy NonFinitePolicy, stats *SanitizeStats) (bool, error) {
+	return SanitizeFields(policy, stats,
+		NonFiniteField{"cpu_request", &st.CPURequest},
+		NonFiniteField{"cpu_usage_p95", &st.CPUUsageP95},
+		NonFiniteField{"cpu_billable_cost", &st.CPUBillableCost},
+		NonFiniteField{"cpu_usage_cost", &st.CPUUsageCost},
+		NonFiniteField{"cpu_waste_cost", &st.CPUWasteCost},
+		NonFiniteField{"mem_billable_cost", &st.MemBillableCost},
+		NonFiniteField{"mem_usage_cost", &st.MemUsageCost},
+		NonFiniteField{"mem_waste_cost", &st.MemWasteCost},
+		NonFiniteField{"total_billable_cost", &st.TotalBillableCost},
+		NonFiniteField{"total_usage_cost", &st.TotalUsageCost},
+		NonFiniteField{"total_waste_cost", &st.TotalWasteCost},
+	)
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
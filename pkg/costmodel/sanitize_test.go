@@ -0,0 +1,124 @@
+package costmodel
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSanitizeMetric(t *testing.T) {
+	corrupt := func() ResourceMetric {
+		return ResourceMetric{CPURequest: math.Inf(1), CPUUsageP95: math.NaN(), MemRequest: 1 << 30}
+	}
+
+	var stats SanitizeStats
+	rm := corrupt()
+	if keep, err := SanitizeMetric(&rm, NonFiniteZero, &stats); !keep || err != nil {
+		t.Fatalf("zero: keep = %v, err = %v", keep, err)
+	}
+	if rm.CPURequest != 0 || rm.CPUUsageP95 != 0 || stats.ZeroedValues != 2 {
+		t.Errorf("zero: metric = %+v, stats = %+v", rm, stats)
+	}
+
+	rm = corrupt()
+	if keep, err := SanitizeMetric(&rm, NonFiniteDrop, &stats); keep || err != nil {
+		t.Fatalf("drop: keep = %v, err = %v", keep, err)
+	}
+	if stats.DroppedRows != 1 || stats.ZeroedValues != 2 {
+		t.Errorf("drop: stats = %+v", stats)
+	}
+
+	rm = corrupt()
+	if _, err := SanitizeMetric(&rm, NonFiniteFail, &stats); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("fail: err = %v, want ErrNonFinite", err)
+	}
+
+	rm = ResourceMetric{CPURequest: 2, CPUUsageP95: 1}
+	if keep, err := SanitizeMetric(&rm, NonFiniteFail, nil); !keep || err != nil {
+		t.Errorf("finite metric: keep = %v, err = %v", keep, err)
+	}
+}
+
+func TestWithNonFinitePolicy(t *testing.T) {
+	costs := func() []DailyNamespaceCost {
+		return []DailyNamespaceCost{
+			{Namespace: "ns1", BillableCost: 100, UsageCost: 60, WasteCost: 40},
+			{Namespace: "ns2", BillableCost: math.NaN(), UsageCost: math.Inf(1)},
+		}
+	}
+
+	var stats SanitizeStats
+	input := costs()
+	got, err := AggregateGlobal(input, WithNonFinitePolicy(NonFiniteZero, &stats))
+	if err != nil {
+		t.Fatalf("zero: %v", err)
+	}
+	if got.TotalBillableCost != 100 || stats.ZeroedValues != 2 {
+		t.Errorf("zero: billable = %v, stats = %+v", got.TotalBillableCost, stats)
+	}
+	if !math.IsNaN(input[1].BillableCost) {
+		t.Error("zero policy modified the caller's rows")
+	}
+
+	stats = SanitizeStats{}
+	breakdown, err := CalculateDomainBreakdown(costs(), WithNonFinitePolicy(NonFiniteDrop, &stats))
+	if err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	if len(breakdown) != 1 || stats.DroppedRows != 1 {
+		t.Errorf("drop: breakdown = %+v, stats = %+v", breakdown, stats)
+	}
+
+	_, err = AggregateByWorkload([]HourlyWorkloadStat{{Namespace: "ns1", WorkloadName: "api", TotalBillableCost: math.NaN()}},
+		WithNonFinitePolicy(NonFiniteFail, nil))
+	if !errors.Is(err, ErrNonFinite) {
+		t.Errorf("fail: err = %v, want ErrNonFinite", err)
+	}
+
+	// 未配置策略时 NaN 不再被取整函数吞成 0
+	got, err = AggregateGlobal(costs())
+	if err != nil {
+		t.Fatalf("no policy: %v", err)
+	}
+	if !math.IsNaN(got.TotalBillableCost) {
+		t.Errorf("no policy: billable = %v, want NaN", got.TotalBillableCost)
+	}
+}
+
+func TestParseNonFinitePolicy(t *testing.T) {
+	for _, s := range []string{"zero", "drop", "fail"} {
+		p, err := ParseNonFinitePolicy(s)
+		if err != nil || p.String() != s {
+			t.Errorf("ParseNonFinitePolicy(%q) = %v, %v", s, p, err)
+		}
+	}
+	if p, err := ParseNonFinitePolicy(""); err != nil || p != NonFiniteZero {
+		t.Errorf("empty policy = %v, %v, want zero", p, err)
+	}
+	if _, err := ParseNonFinitePolicy("ignore"); err == nil {
+		t.Error("unknown policy should fail")
+	}
+}
+
+func TestNonFiniteDetection(t *testing.T) {
+	if _, err := CalculateCost(ResourceMetric{CPURequest: math.NaN(), MemRequest: 1 << 30}, 0.1, 0.01); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("CalculateCost(NaN request) err = %v, want ErrNonFinite", err)
+	}
+	if _, err := CalculateCost(ResourceMetric{CPURequest: 1}, math.Inf(1), 0.01); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("CalculateCost(Inf price) err = %v, want ErrNonFinite", err)
+	}
+
+	report := validateCostInput([]DailyNamespaceCost{{Namespace: "ns1", BillableCost: math.NaN()}})
+	if len(report.Issues) != 1 || report.Issues[0].Reason != "must be finite" {
+		t.Errorf("issues = %+v, want billable_cost must be finite", report.Issues)
+	}
+
+	stats := &SanitizeStats{}
+	if got := SanitizeStatsFrom(WithSanitizeStats(context.Background(), stats)); got != stats {
+		t.Errorf("SanitizeStatsFrom = %p, want %p", got, stats)
+	}
+	if SanitizeStatsFrom(context.Background()) != nil {
+		t.Error("SanitizeStatsFrom without stats should be nil")
+	}
+}
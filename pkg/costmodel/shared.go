@@ -0,0 +1,136 @@
+// Package costmodel shared.go: cluster-shared resources (ingress LBs, shared NFS/Ceph) and their allocation to namespaces.
+package costmodel
+
+import (
+	"fmt"
+	"time"
+)
+
+// SharedBilling is how a shared resource is billed.
+type SharedBilling string
+
+const (
+	// SharedBillingFixed is a fixed monthly cost, spread evenly over the days of the month.
+	SharedBillingFixed SharedBilling = "fixed"
+	// SharedBillingMetered is metered units (e.g. GB transferred, GB-months stored) times UnitPrice.
+	SharedBillingMetered SharedBilling = "metered"
+)
+
+// SharedAllocationKey is the measure a shared cost is split by.
+type SharedAllocationKey string
+
+const (
+	AllocateByTraffic  SharedAllocationKey = "traffic"  // bytes served per namespace (load balancers)
+	AllocateByCapacity SharedAllocationKey = "capacity" // provisioned capacity per namespace (shared storage)
+	AllocateByBillable SharedAllocationKey = "billable" // namespace billable compute cost
+	AllocateEvenly     SharedAllocationKey = "even"     // equal share per namespace
+)
+
+// SharedResource is a cluster-shared resource whose cost is allocated to namespaces.
+type SharedResource struct {
+	Name          string              `json:"name"`
+	Kind          string              `json:"kind"` // e.g. "load_balancer", "storage"; informational
+	Billing       SharedBilling       `json:"billing"`
+	MonthlyCost   float64             `json:"monthly_cost,omitempty"` // fixed billing
+	UnitPrice     float64             `json:"unit_price,omitempty"`   // metered billing, per unit
+	AllocationKey SharedAllocationKey `json:"allocation_key"`
+	// Namespaces restricts the allocation to these namespaces; empty means all namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// Validate checks the billing mode, its price and the allocation key.
+func (r SharedResource) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("shared resource name is required")
+	}
+	switch r.Billing {
+	case SharedBillingFixed:
+		if r.MonthlyCost < 0 {
+			return fmt.Errorf("shared resource %s: monthly_cost must not be negative", r.Name)
+		}
+	case SharedBillingMetered:
+		if r.UnitPrice < 0 {
+			return fmt.Errorf("shared resource %s: unit_price must not be negative", r.Name)
+		}
+	default:
+		return fmt.Errorf("shared resource %s: unknown billing %q", r.Name, r.Billing)
+	}
+	switch r.AllocationKey {
+	case AllocateByTraffic, AllocateByCapacity, AllocateByBillable, AllocateEvenly:
+		return nil
+	default:
+		return fmt.Errorf("shared resource %s: unknown allocation key %q", r.Name, r.AllocationKey)
+	}
+}
+
+// DailyCost returns the cost of the resource for day: the fixed monthly cost divided by the days of
+// that month, or the metered units of the day times UnitPrice.
+func (r SharedResource) DailyCost(day time.Time, meteredUnits float64) float64 {
+	if r.Billing == SharedBillingMetered {
+		return meteredUnits * r.UnitPrice
+	}
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	return r.MonthlyCost / float64(daysInMonth)
+}
+
+// NeedsUsage reports whether the resource needs measured usage: metered billing or a traffic or
+// capacity allocation key.
+func (r SharedResource) NeedsUsage() bool {
+	return r.Billing == SharedBillingMetered || r.AllocationKey == AllocateByTraffic || r.AllocationKey == AllocateByCapacity
+}
+
+// Weights returns the allocation weight of each eligible namespace. Traffic and capacity weigh the
+// namespaces in measured (by usage source), billable and even the namespaces in billable (compute
+// cost of the day); r.Namespaces replaces that set when configured.
+func (r SharedResource) Weights(measured, billable map[string]float64) map[string]float64 {
+	weights := make(map[string]float64)
+	switch r.AllocationKey {
+	case AllocateByTraffic, AllocateByCapacity:
+		for ns, v := range measured {
+			weights[ns] = v
+		}
+	case AllocateByBillable:
+		for ns, v := range billable {
+			weights[ns] = v
+		}
+	case AllocateEvenly:
+		for ns := range billable {
+			weights[ns] = 1
+		}
+	}
+	if len(r.Namespaces) > 0 {
+		restricted := make(map[string]float64, len(r.Namespaces))
+		for _, ns := range r.Namespaces {
+			restricted[ns] = weights[ns]
+			if r.AllocationKey == AllocateEvenly {
+				restricted[ns] = 1
+			}
+		}
+		weights = restricted
+	}
+	return weights
+}
+
+// AllocateShared splits cost across namespaces in proportion to weights. Negative weights count as
+// zero; when all weights are zero the cost is split evenly. No namespaces leaves the cost unallocated.
+func AllocateShared(cost float64, weights map[string]float64) map[string]float64 {
+	out := make(map[string]float64, len(weights))
+	if len(weights) == 0 || cost == 0 {
+		return out
+	}
+	var total float64
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	for ns, w := range weights {
+		switch {
+		case total == 0:
+			out[ns] = cost / float64(len(weights))
+		case w > 0:
+			out[ns] = cost * w / total
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSharedResource_DailyCost(t *testing.T) {
+	feb := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC) // 29 days
+	lb := SharedResource{Name: "ingress", Billing: SharedBillingFixed, MonthlyCost: 290, AllocationKey: AllocateByTraffic}
+	if err := lb.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if got := lb.DailyCost(feb, 0); !FloatEquals(got, 10, 1e-9) {
+		t.Errorf("fixed daily cost = %v, want 10", got)
+	}
+	nfs := SharedResource{Name: "nfs", Billing: SharedBillingMetered, UnitPrice: 0.1, AllocationKey: AllocateByCapacity}
+	if got := nfs.DailyCost(feb, 50); !FloatEquals(got, 5, 1e-9) {
+		t.Errorf("metered daily cost = %v, want 5", got)
+	}
+	if err := (SharedResource{Name: "x", Billing: SharedBillingFixed, AllocationKey: "bytes"}).Validate(); err == nil {
+		t.Error("unknown allocation key should be rejected")
+	}
+}
+
+func TestAllocateShared(t *testing.T) {
+	got := AllocateShared(100, map[string]float64{"a": 3, "b": 1, "c": 0})
+	if !FloatEquals(got["a"], 75, 1e-9) || !FloatEquals(got["b"], 25, 1e-9) || got["c"] != 0 {
+		t.Errorf("proportional allocation = %v", got)
+	}
+	got = AllocateShared(90, map[string]float64{"a": 0, "b": 0, "c": 0})
+	if !FloatEquals(got["a"], 30, 1e-9) || !FloatEquals(got["c"], 30, 1e-9) {
+		t.Errorf("zero weights should split evenly, got %v", got)
+	}
+	if got := AllocateShared(10, nil); len(got) != 0 {
+		t.Errorf("no namespaces should leave the cost unallocated, got %v", got)
+	}
+}
+
+func TestSharedResource_Weights(t *testing.T) {
+	measured := map[string]float64{"web": 4, "api": 1}
+	billable := map[string]float64{"web": 10, "batch": 30}
+	cases := []struct {
+		r    SharedResource
+		want map[string]float64
+	}{
+		{SharedResource{AllocationKey: AllocateByTraffic}, map[string]float64{"web": 4, "api": 1}},
+		{SharedResource{AllocationKey: AllocateByBillable}, map[string]float64{"web": 10, "batch": 30}},
+		{SharedResource{AllocationKey: AllocateEvenly}, map[string]float64{"web": 1, "batch": 1}},
+		{SharedResource{AllocationKey: AllocateByCapacity, Namespaces: []string{"api", "idle"}}, map[string]float64{"api": 1, "idle": 0}},
+		{SharedResource{AllocationKey: AllocateEvenly, Namespaces: []string{"idle"}}, map[string]float64{"idle": 1}},
+	}
+	for _, c := range cases {
+		got := c.r.Weights(measured, billable)
+		if len(got) != len(c.want) {
+			t.Errorf("%s %v: got %v, want %v", c.r.AllocationKey, c.r.Namespaces, got, c.want)
+			continue
+		}
+		for ns, w := range c.want {
+			if v, ok := got[ns]; !ok || v != w {
+				t.Errorf("%s %v: got %v, want %v", c.r.AllocationKey, c.r.Namespaces, got, c.want)
+				break
+			}
+		}
+	}
+	if (SharedResource{Billing: SharedBillingFixed, AllocationKey: AllocateEvenly}).NeedsUsage() {
+		t.Error("fixed, evenly allocated resource should not need usage")
+	}
+	if !(SharedResource{Billing: SharedBillingMetered, AllocationKey: AllocateEvenly}).NeedsUsage() {
+		t.Error("metered resource should need usage")
+	}
+}
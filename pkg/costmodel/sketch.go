@@ -0,0 +1,135 @@
+// Package costmodel sketch.go: mergeable usage sketches, so percentiles over many hours are computed
+// from the merged distribution instead of averaging hourly P95s.
+package costmodel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultSketchAccuracy is the relative accuracy of sketches built by NewUsageSketch(0).
+const DefaultSketchAccuracy = 0.01
+
+// UsageSketch is a relative-error quantile sketch (DDSketch-style log buckets) of usage samples.
+// Every quantile is within Accuracy relative error of the true sample value, and two sketches with
+// the same accuracy merge exactly, so an hourly sketch per workload can be combined over any window.
+// The zero value is not usable; use NewUsageSketch.
+type UsageSketch struct {
+	Accuracy float64        `json:"accuracy"`
+	Bins     map[int]uint64 `json:"bins"`
+	Zero     uint64         `json:"zero"` // samples <= 0
+	Count    uint64         `json:"count"`
+	Min      float64        `json:"min"`
+	Max      float64        `json:"max"`
+}
+
+// NewUsageSketch creates an empty sketch; accuracy <= 0 uses DefaultSketchAccuracy.
+func NewUsageSketch(accuracy float64) *UsageSketch {
+	if accuracy <= 0 || accuracy >= 1 {
+		accuracy = DefaultSketchAccuracy
+	}
+	return &UsageSketch{Accuracy: accuracy, Bins: make(map[int]uint64)}
+}
+
+// SketchOf returns a sketch of samples with the default accuracy.
+func SketchOf(samples ...float64) *UsageSketch {
+	s := NewUsageSketch(0)
+	for _, v := range samples {
+		s.Add(v)
+	}
+	return s
+}
+
+func (s *UsageSketch) gamma() float64 { return (1 + s.Accuracy) / (1 - s.Accuracy) }
+
+// Add records one sample.
+func (s *UsageSketch) Add(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	if s.Count == 0 || v < s.Min {
+		s.Min = v
+	}
+	if s.Count == 0 || v > s.Max {
+		s.Max = v
+	}
+	s.Count++
+	if v <= 0 {
+		s.Zero++
+		return
+	}
+	if s.Bins == nil {
+		s.Bins = make(map[int]uint64)
+	}
+	s.Bins[int(math.Ceil(math.Log(v)/math.Log(s.gamma())))]++
+}
+
+// Merge adds the samples of other to s. Both sketches must have the same accuracy.
+func (s *UsageSketch) Merge(other *UsageSketch) error {
+	if other == nil || other.Count == 0 {
+		return nil
+	}
+	if s.Accuracy != other.Accuracy {
+		return fmt.Errorf("cannot merge sketches with accuracy %v and %v", s.Accuracy, other.Accuracy)
+	}
+	if s.Count == 0 || other.Min < s.Min {
+		s.Min = other.Min
+	}
+	if s.Count == 0 || other.Max > s.Max {
+		s.Max = other.Max
+	}
+	if s.Bins == nil {
+		s.Bins = make(map[int]uint64, len(other.Bins))
+	}
+	for k, n := range other.Bins {
+		s.Bins[k] += n
+	}
+	s.Zero += other.Zero
+	s.Count += other.Count
+	return nil
+}
+
+// Quantile returns the q-quantile (0..1) of the recorded samples, 0 for an empty sketch.
+func (s *UsageSketch) Quantile(q float64) float64 {
+	if s == nil || s.Count == 0 {
+		return 0
+	}
+	q = math.Max(0, math.Min(1, q))
+	rank := uint64(q * float64(s.Count-1))
+	if rank < s.Zero {
+		return math.Min(0, s.Max)
+	}
+	keys := make([]int, 0, len(s.Bins))
+	for k := range s.Bins {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	seen := s.Zero
+	g := s.gamma()
+	for _, k := range keys {
+		seen += s.Bins[k]
+		if seen > rank {
+			v := 2 * math.Pow(g, float64(k)) / (g + 1)
+			return math.Max(s.Min, math.Min(s.Max, v))
+		}
+	}
+	return s.Max
+}
+
+// MergeSketches merges sketches into a new one. ok is false for no sketches, differing accuracies or
+// any nil sketch (a row recorded without one), since the merged percentile would miss those samples.
+func MergeSketches(sketches []*UsageSketch) (merged *UsageSketch, ok bool) {
+	for _, sk := range sketches {
+		if sk == nil {
+			return nil, false
+		}
+		if merged == nil {
+			merged = NewUsageSketch(sk.Accuracy)
+		}
+		if err := merged.Merge(sk); err != nil {
+			return nil, false
+		}
+	}
+	return merged, merged != nil
+}
@@ -0,0 +1,61 @@
+package costmodel
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func exactQuantile(samples []float64, q float64) float64 {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+func TestUsageSketch_QuantileAccuracy(t *testing.T) {
+	var samples []float64
+	for i := 1; i <= 1000; i++ {
+		samples = append(samples, 0.05*float64(i*i%997+1))
+	}
+	s := SketchOf(samples...)
+	for _, q := range []float64{0, 0.5, 0.9, 0.95, 0.99, 1} {
+		want := exactQuantile(samples, q)
+		if got := s.Quantile(q); math.Abs(got-want) > want*DefaultSketchAccuracy {
+			t.Errorf("Quantile(%v) = %v, want %v within %v", q, got, want, DefaultSketchAccuracy)
+		}
+	}
+	if s.Count != 1000 {
+		t.Errorf("Count = %d, want 1000", s.Count)
+	}
+	if got := NewUsageSketch(0).Quantile(0.95); got != 0 {
+		t.Errorf("empty sketch Quantile = %v, want 0", got)
+	}
+}
+
+func TestUsageSketch_Merge(t *testing.T) {
+	quiet := []float64{0.1, 0.1, 0.2, 0.2, 0.2, 0.3, 0, 0.1}
+	busy := []float64{2, 3, 2.5, 4, 3.5, 2, 2, 3}
+
+	merged, ok := MergeSketches([]*UsageSketch{SketchOf(quiet...), SketchOf(busy...)})
+	if !ok {
+		t.Fatal("MergeSketches: want ok")
+	}
+	union := SketchOf(append(append([]float64(nil), quiet...), busy...)...)
+	for _, q := range []float64{0.25, 0.5, 0.95} {
+		if merged.Quantile(q) != union.Quantile(q) {
+			t.Errorf("merged Quantile(%v) = %v, sketch of the union = %v", q, merged.Quantile(q), union.Quantile(q))
+		}
+	}
+	if merged.Min != 0 || merged.Max != 4 || merged.Count != 16 {
+		t.Errorf("merged min/max/count = %v/%v/%d, want 0/4/16", merged.Min, merged.Max, merged.Count)
+	}
+
+	if _, ok := MergeSketches([]*UsageSketch{SketchOf(1), nil}); ok {
+		t.Error("a row without a sketch should make the merge unusable")
+	}
+	coarse := NewUsageSketch(0.05)
+	coarse.Add(1)
+	if _, ok := MergeSketches([]*UsageSketch{SketchOf(1), coarse}); ok {
+		t.Error("sketches with different accuracies should not merge")
+	}
+}
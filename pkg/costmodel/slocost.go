@@ -0,0 +1,113 @@
+// Package costmodel slocost.go: the cost of SLO compliance — what each nine of an availability target
+// costs in capacity. Serving demand at availability A takes capacity for the A-quantile of usage;
+// everything between typical demand (P50) and that quantile is headroom kept for the target.
+package costmodel
+
+import "math"
+
+// MaxSLONines caps the nines analysed; past it usage samples are too few to tell quantiles apart.
+const MaxSLONines = 5
+
+// SLOCapacityLevel is the capacity serving usage at one availability level and its monthly cost.
+type SLOCapacityLevel struct {
+	Availability float64 `json:"availability"` // %; 50 is the typical demand (P50)
+	CPU          float64 `json:"cpu"`          // cores
+	Mem          float64 `json:"mem"`          // bytes
+	MonthlyCost  float64 `json:"monthly_cost"`
+	// MarginalMonthlyCost is the cost over the previous level (the cost of this nine).
+	MarginalMonthlyCost float64 `json:"marginal_monthly_cost"`
+}
+
+// SLOCostInput is the usage distribution, requests and unit prices of one service.
+type SLOCostInput struct {
+	CPU, Mem            *UsageSketch // usage samples in cores / bytes
+	CPURequest          float64      // cores
+	MemRequest          float64      // bytes
+	CPUPricePerCoreHour float64
+	MemPricePerGBHour   float64
+	AvailabilityTarget  float64 // %, e.g. 99.9
+}
+
+// SLOCost is what the availability target of a service costs.
+type SLOCost struct {
+	AvailabilityTarget float64          `json:"availability_target"`
+	Nines              float64          `json:"nines"`
+	Baseline           SLOCapacityLevel `json:"baseline"` // typical demand (P50)
+	// Levels are the whole nines (90%, 99%, ...) up to one past the target.
+	Levels []SLOCapacityLevel `json:"levels"`
+	// Target is the capacity at the target; its marginal cost is over one nine less.
+	Target SLOCapacityLevel `json:"target"`
+	// RequestedMonthlyCost prices the current requests.
+	RequestedMonthlyCost float64 `json:"requested_monthly_cost"`
+	// SLOHeadroomMonthlyCost is the capacity kept above typical demand for the target.
+	SLOHeadroomMonthlyCost float64 `json:"slo_headroom_monthly_cost"`
+	// ExcessMonthlyCost is requested capacity beyond what the target needs (not explained by the SLO).
+	ExcessMonthlyCost float64 `json:"excess_monthly_cost"`
+	// NextNineMonthlyCost is the extra capacity for one more nine; RelaxNineMonthlySavings what
+	// dropping one nine releases (down to typical demand).
+	NextNineMonthlyCost     float64 `json:"next_nine_monthly_cost"`
+	RelaxNineMonthlySavings float64 `json:"relax_nine_monthly_savings"`
+}
+
+// NinesOf returns the number of nines of an availability in percent: 99.9 -> 3, 99.5 -> 2.3.
+func NinesOf(availability float64) float64 {
+	if availability >= 100 {
+		return MaxSLONines
+	}
+	if availability <= 0 {
+		return 0
+	}
+	return math.Min(MaxSLONines, roundToPrecision(-math.Log10(1-availability/100), 4))
+}
+
+// availabilityOf is the inverse of NinesOf.
+func availabilityOf(nines float64) float64 {
+	return 100 * (1 - math.Pow(10, -nines))
+}
+
+// ComputeSLOCost prices the capacity each availability level needs from the usage quantiles of in.
+// Capacity is never below typical demand; a resource without a sketch needs none.
+func ComputeSLOCost(in SLOCostInput) SLOCost {
+	level := func(availability float64) SLOCapacityLevel {
+		q := math.Max(availability, 50) / 100
+		l := SLOCapacityLevel{Availability: roundToPrecision(availability, 4)}
+		if in.CPU != nil {
+			l.CPU = in.CPU.Quantile(q)
+		}
+		if in.Mem != nil {
+			l.Mem = in.Mem.Quantile(q)
+		}
+		l.MonthlyCost = roundToPrecision(in.monthly(l.CPU, l.Mem), 2)
+		return l
+	}
+	nines := NinesOf(in.AvailabilityTarget)
+	out := SLOCost{
+		AvailabilityTarget:   in.AvailabilityTarget,
+		Nines:                roundToPrecision(nines, 2),
+		Baseline:             level(50),
+		Levels:               []SLOCapacityLevel{},
+		RequestedMonthlyCost: roundToPrecision(in.monthly(in.CPURequest, in.MemRequest), 2),
+	}
+	prev := out.Baseline
+	for k := 1; k <= MaxSLONines && float64(k) <= math.Floor(nines)+1; k++ {
+		l := level(availabilityOf(float64(k)))
+		l.MarginalMonthlyCost = roundToPrecision(l.MonthlyCost-prev.MonthlyCost, 2)
+		out.Levels = append(out.Levels, l)
+		prev = l
+	}
+	out.Target = level(in.AvailabilityTarget)
+	out.SLOHeadroomMonthlyCost = roundToPrecision(out.Target.MonthlyCost-out.Baseline.MonthlyCost, 2)
+	out.ExcessMonthlyCost = roundToPrecision(in.monthly(math.Max(0, in.CPURequest-out.Target.CPU), math.Max(0, in.MemRequest-out.Target.Mem)), 2)
+	if nines < MaxSLONines {
+		next := level(availabilityOf(nines + 1))
+		out.NextNineMonthlyCost = roundToPrecision(next.MonthlyCost-out.Target.MonthlyCost, 2)
+	}
+	relaxed := level(availabilityOf(math.Max(0, nines-1)))
+	out.RelaxNineMonthlySavings = roundToPrecision(out.Target.MonthlyCost-relaxed.MonthlyCost, 2)
+	out.Target.MarginalMonthlyCost = out.RelaxNineMonthlySavings
+	return out
+}
+
+func (in SLOCostInput) monthly(cpu, mem float64) float64 {
+	return (cpu*in.CPUPricePerCoreHour + mem/(1<<30)*in.MemPricePerGBHour) * HoursPerMonth
+}
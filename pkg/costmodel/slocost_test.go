@@ -0,0 +1,57 @@
+package costmodel
+
+import "testing"
+
+func TestNinesOf(t *testing.T) {
+	for _, tc := range []struct{ availability, nines float64 }{
+		{90, 1}, {99, 2}, {99.9, 3}, {99.95, 3.301}, {100, MaxSLONines}, {0, 0},
+	} {
+		if got := NinesOf(tc.availability); !FloatEquals(got, tc.nines, 0.001) {
+			t.Errorf("NinesOf(%v) = %v, want %v", tc.availability, got, tc.nines)
+		}
+	}
+}
+
+func TestComputeSLOCost(t *testing.T) {
+	// 10000 个 CPU 样本：大多在 1 核，尾部逐级升高（P90 以上 2 核，P99 以上 4 核，P99.9 以上 8 核）
+	cpu := NewUsageSketch(0)
+	for i := 0; i < 10000; i++ {
+		switch {
+		case i >= 9990:
+			cpu.Add(8)
+		case i >= 9900:
+			cpu.Add(4)
+		case i >= 9000:
+			cpu.Add(2)
+		default:
+			cpu.Add(1)
+		}
+	}
+	in := SLOCostInput{CPU: cpu, CPURequest: 10, CPUPricePerCoreHour: 0.1, AvailabilityTarget: 99}
+	c := ComputeSLOCost(in)
+
+	if c.Nines != 2 || len(c.Levels) != 3 {
+		t.Fatalf("nines = %v, levels = %+v; want 2 nines and levels 90, 99, 99.9", c.Nines, c.Levels)
+	}
+	perCore := 0.1 * HoursPerMonth
+	if !FloatEquals(c.Baseline.CPU, 1, 0.02) || !FloatEquals(c.Target.CPU, 2, 0.04) {
+		t.Errorf("baseline %v / target %v cores, want 1 / 2", c.Baseline.CPU, c.Target.CPU)
+	}
+	// 第二个 9（90% -> 99%）从 1 核到 2 核
+	if !FloatEquals(c.Levels[1].MarginalMonthlyCost, perCore, 2) || !FloatEquals(c.Target.MarginalMonthlyCost, perCore, 2) {
+		t.Errorf("cost of the second nine = %v / %v, want %v", c.Levels[1].MarginalMonthlyCost, c.Target.MarginalMonthlyCost, perCore)
+	}
+	// 再加一个 9 需要 4 核
+	if !FloatEquals(c.NextNineMonthlyCost, 2*perCore, 3) || !FloatEquals(c.Levels[2].CPU, 4, 0.1) {
+		t.Errorf("next nine = %v, level 99.9 = %+v", c.NextNineMonthlyCost, c.Levels[2])
+	}
+	if !FloatEquals(c.SLOHeadroomMonthlyCost, perCore, 2) || !FloatEquals(c.ExcessMonthlyCost, 8*perCore, 5) || !FloatEquals(c.RequestedMonthlyCost, 10*perCore, 0.01) {
+		t.Errorf("headroom %v, excess %v, requested %v", c.SLOHeadroomMonthlyCost, c.ExcessMonthlyCost, c.RequestedMonthlyCost)
+	}
+
+	// 一个 9 的目标放宽后回到典型需求
+	in.AvailabilityTarget = 90
+	if c := ComputeSLOCost(in); c.RelaxNineMonthlySavings != c.SLOHeadroomMonthlyCost {
+		t.Errorf("relaxing the only nine saves %v, want the whole headroom %v", c.RelaxNineMonthlySavings, c.SLOHeadroomMonthlyCost)
+	}
+}
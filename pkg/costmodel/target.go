@@ -0,0 +1,76 @@
+// Package costmodel target.go: tracking of a namespace's daily efficiency against a team-set target.
+package costmodel
+
+import (
+	"sort"
+	"time"
+)
+
+// EfficiencyPoint is the efficiency (0-100) of one day.
+type EfficiencyPoint struct {
+	Date       time.Time `json:"date"`
+	Efficiency float64   `json:"efficiency"`
+}
+
+// DailyEfficiency returns the efficiency score of a day from its billable and usage cost.
+func DailyEfficiency(billable, usage float64) float64 {
+	return roundPercentage(calculateEfficiencyScore(billable, usage))
+}
+
+// TargetAttainment summarizes daily efficiency against a target. A day meets the target when
+// its efficiency is at or above it; a day without data breaks a streak.
+type TargetAttainment struct {
+	Target            float64 `json:"target"`
+	Days              int     `json:"days"`           // days with data
+	DaysAtTarget      int     `json:"days_at_target"` // days meeting the target
+	AttainmentRate    float64 `json:"attainment_rate"`
+	AverageEfficiency float64 `json:"average_efficiency"`
+	CurrentStreak     int     `json:"current_streak"` // consecutive days meeting the target, ending on the last day
+	LongestStreak     int     `json:"longest_streak"`
+}
+
+// TrackEfficiencyTarget compares points with target. Points may be in any order; several points
+// of the same UTC day are averaged.
+func TrackEfficiencyTarget(points []EfficiencyPoint, target float64) TargetAttainment {
+	byDay := make(map[time.Time][]float64)
+	for _, p := range points {
+		day := p.Date.UTC().Truncate(24 * time.Hour)
+		byDay[day] = append(byDay[day], p.Efficiency)
+	}
+	days := make([]time.Time, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	a := TargetAttainment{Target: target, Days: len(days)}
+	var sum float64
+	streak := 0
+	for i, day := range days {
+		var daySum float64
+		for _, v := range byDay[day] {
+			daySum += v
+		}
+		efficiency := daySum / float64(len(byDay[day]))
+		sum += efficiency
+
+		if i > 0 && !days[i-1].AddDate(0, 0, 1).Equal(day) {
+			streak = 0
+		}
+		if efficiency >= target {
+			a.DaysAtTarget++
+			streak++
+		} else {
+			streak = 0
+		}
+		if streak > a.LongestStreak {
+			a.LongestStreak = streak
+		}
+	}
+	a.CurrentStreak = streak
+	if a.Days > 0 {
+		a.AttainmentRate = roundPercentage(float64(a.DaysAtTarget) / float64(a.Days) * 100)
+		a.AverageEfficiency = roundPercentage(sum / float64(a.Days))
+	}
+	return a
+}
@@ -0,0 +1,39 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackEfficiencyTarget(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d int, efficiency float64) EfficiencyPoint {
+		return EfficiencyPoint{Date: day.AddDate(0, 0, d), Efficiency: efficiency}
+	}
+	// 第 4 天缺数据，打断连续达标
+	points := []EfficiencyPoint{at(5, 70), at(0, 65), at(1, 61), at(2, 40), at(3, 60), at(5, 80), at(6, 62)}
+	got := TrackEfficiencyTarget(points, 60)
+	want := TargetAttainment{
+		Target: 60, Days: 6, DaysAtTarget: 5, AttainmentRate: 83.33,
+		AverageEfficiency: 60.5, CurrentStreak: 2, LongestStreak: 2,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got := TrackEfficiencyTarget(nil, 60); got != (TargetAttainment{Target: 60}) {
+		t.Errorf("no data: got %+v", got)
+	}
+	if got := TrackEfficiencyTarget(points[:2], 90); got.DaysAtTarget != 0 || got.CurrentStreak != 0 || got.LongestStreak != 0 {
+		t.Errorf("never met: got %+v", got)
+	}
+}
+
+func TestDailyEfficiency(t *testing.T) {
+	if got := DailyEfficiency(3, 1); got != 33.33 {
+		t.Errorf("DailyEfficiency(3, 1) = %v, want 33.33", got)
+	}
+	if got := DailyEfficiency(0, 1); got != 0 {
+		t.Errorf("zero billable: got %v, want 0", got)
+	}
+}
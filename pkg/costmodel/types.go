@@ -19,6 +19,10 @@ type ResourceMetric struct {
 	// Memory usage at P95 percentile in bytes
 	MemUsageP95 int64 `json:"mem_usage_p95"`
 
+	// Usage sketches of the samples behind the P95s (optional), for percentiles over merged windows
+	CPUUsageSketch *UsageSketch `json:"cpu_usage_sketch,omitempty"`
+	MemUsageSketch *UsageSketch `json:"mem_usage_sketch,omitempty"`
+
 	// Timestamp of the measurement
 	Timestamp time.Time `json:"timestamp"`
 }
@@ -88,6 +92,9 @@ const (
 
 	// LevelCluster represents cluster-level aggregation
 	LevelCluster
+
+	// LevelNodePool represents node-pool-level aggregation
+	LevelNodePool
 )
 
 // AggregationResult represents the result of aggregating costs at a specific level.
@@ -112,6 +119,7 @@ type DailyNamespaceCost struct {
 	BillableCost  float64   `json:"billable_cost"`
 	UsageCost     float64   `json:"usage_cost"`
 	WasteCost     float64   `json:"waste_cost"`
+	SharedCost    float64   `json:"shared_cost"` // allocated cluster-shared cost, not part of BillableCost
 	PodCount      int       `json:"pod_count"`
 	NodeCount     int       `json:"node_count"`
 	WorkloadCount int       `json:"workload_count"`
@@ -139,6 +147,10 @@ type HourlyWorkloadStat struct {
 	TotalBillableCost float64   `json:"total_billable_cost"`
 	TotalUsageCost    float64   `json:"total_usage_cost"`
 	TotalWasteCost    float64   `json:"total_waste_cost"`
+	NodePool          string    `json:"node_pool,omitempty"` // pool of the nodes running the workload (see NodePoolOf)
+	// Hourly usage sketches; when present, multi-hour P95s are computed from their merge.
+	CPUUsageSketch *UsageSketch `json:"cpu_usage_sketch,omitempty"`
+	MemUsageSketch *UsageSketch `json:"mem_usage_sketch,omitempty"`
 }
 
 // GlobalAggregatedResult represents the result of L0 global aggregation.
@@ -167,6 +179,8 @@ type AggregatedResult struct {
 	TotalWasteCost    float64   `json:"total_waste_cost"`
 	EfficiencyScore   float64   `json:"efficiency_score"`
 	ResourceCount     int       `json:"resource_count"`
+	CPUUsageP95       float64   `json:"cpu_usage_p95,omitempty"` // merged-sketch percentile (AggregateByWorkload); 0 without sketches
+	MemUsageP95       float64   `json:"mem_usage_p95,omitempty"` // bytes, as CPUUsageP95
 	Timestamp         time.Time `json:"timestamp"`
 }
 
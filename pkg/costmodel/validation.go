@@ -0,0 +1,187 @@
+// Package costmodel validation.go: input validation for the aggregators. Validation produces a
+// per-row ValidationReport, so bad ingested rows can be quarantined instead of failing a whole batch.
+package costmodel
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ValidationMode selects how the aggregators treat invalid input rows.
+type ValidationMode int
+
+const (
+	// ValidationOff aggregates the rows as given (default).
+	ValidationOff ValidationMode = iota
+	// ValidationReject fails the call with the *ValidationReport when any row is invalid.
+	ValidationReject
+	// ValidationQuarantine leaves invalid rows out and aggregates the rest.
+	ValidationQuarantine
+)
+
+// ValidationIssue is one invalid field of an input row.
+type ValidationIssue struct {
+	Row    int    `json:"row"`   // index in the input slice
+	Field  string `json:"field"` // JSON name of the field
+	Reason string `json:"reason"`
+}
+
+// ValidationReport lists the invalid rows of an aggregation input. It is the error returned in
+// ValidationReject mode and is filled through WithValidation in either mode.
+type ValidationReport struct {
+	Rows   int               `json:"rows"` // rows checked
+	Issues []ValidationIssue `json:"issues"`
+}
+
+func (r *ValidationReport) Error() string {
+	if r.Valid() {
+		return fmt.Sprintf("all %d input rows valid", r.Rows)
+	}
+	first := r.Issues[0]
+	return fmt.Sprintf("%d of %d input rows invalid: row %d %s %s",
+		len(r.InvalidRows()), r.Rows, first.Row, first.Field, first.Reason)
+}
+
+// Valid reports whether no issue was found.
+func (r *ValidationReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+// InvalidRows returns the sorted indices of the rows with at least one issue.
+func (r *ValidationReport) InvalidRows() []int {
+	seen := make(map[int]bool, len(r.Issues))
+	rows := make([]int, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		if !seen[issue.Row] {
+			seen[issue.Row] = true
+			rows = append(rows, issue.Row)
+		}
+	}
+	sort.Ints(rows)
+	return rows
+}
+
+func (r *ValidationReport) add(row int, field, reason string) {
+	r.Issues = append(r.Issues, ValidationIssue{Row: row, Field: field, Reason: reason})
+}
+
+func (r *ValidationReport) checkRequired(row int, field, value string) {
+	if value == "" {
+		r.add(row, field, "is required")
+	}
+}
+
+func (r *ValidationReport) checkNonNegative(row int, field string, value float64) {
+	if !isFinite(value) {
+		r.add(row, field, "must be finite")
+	} else if value < 0 {
+		r.add(row, field, "cannot be negative")
+	}
+}
+
+// AggregateOption configures AggregateGlobal, CalculateDomainBreakdown, AggregateByNamespace and
+// AggregateByWorkload.
+type AggregateOption func(*aggregateOptions)
+
+type aggregateOptions struct {
+	mode   ValidationMode
+	report *ValidationReport
+
+	sanitize  bool
+	nonFinite NonFinitePolicy
+	stats     *SanitizeStats
+}
+
+// WithValidation validates the input rows before aggregating. When report is non-nil it receives
+// the result of the validation, including the quarantined rows.
+func WithValidation(mode ValidationMode, report *ValidationReport) AggregateOption {
+	return func(o *aggregateOptions) {
+		o.mode = mode
+		o.report = report
+	}
+}
+
+// WithNonFinitePolicy applies policy to NaN/Inf values of the input rows before they are validated
+// and aggregated; stats, when non-nil, counts the zeroed values and dropped rows. Row indices in the
+// ValidationReport refer to the rows left after dropping.
+func WithNonFinitePolicy(policy NonFinitePolicy, stats *SanitizeStats) AggregateOption {
+	return func(o *aggregateOptions) {
+		o.sanitize = true
+		o.nonFinite = policy
+		o.stats = stats
+	}
+}
+
+// validated returns the rows to aggregate under the validation options. sanitize applies the
+// non-finite policy to a row; the caller's rows are not modified.
+func validated[T any](rows []T, opts []AggregateOption, sanitize func(*T, NonFinitePolicy, *SanitizeStats) (bool, error), validate func([]T) *ValidationReport) ([]T, error) {
+	var o aggregateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.sanitize {
+		kept := make([]T, 0, len(rows))
+		for _, row := range rows {
+			keep, err := sanitize(&row, o.nonFinite, o.stats)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+	if o.mode == ValidationOff {
+		return rows, nil
+	}
+	report := validate(rows)
+	if o.report != nil {
+		*o.report = *report
+	}
+	if report.Valid() {
+		return rows, nil
+	}
+	if o.mode == ValidationReject {
+		return nil, report
+	}
+	invalid := report.InvalidRows()
+	kept := make([]T, 0, len(rows)-len(invalid))
+	for i, row := range rows {
+		if len(invalid) > 0 && invalid[0] == i {
+			invalid = invalid[1:]
+			continue
+		}
+		kept = append(kept, row)
+	}
+	return kept, nil
+}
+
+// validateCostInput checks daily namespace costs for missing namespaces and negative or NaN/Inf values.
+func validateCostInput(costs []DailyNamespaceCost) *ValidationReport {
+	report := &ValidationReport{Rows: len(costs)}
+	for i, cost := range costs {
+		report.checkRequired(i, "namespace", cost.Namespace)
+		report.checkNonNegative(i, "billable_cost", cost.BillableCost)
+		report.checkNonNegative(i, "usage_cost", cost.UsageCost)
+		report.checkNonNegative(i, "waste_cost", cost.WasteCost)
+		report.checkNonNegative(i, "shared_cost", cost.SharedCost)
+		report.checkNonNegative(i, "pod_count", float64(cost.PodCount))
+	}
+	return report
+}
+
+// validateWorkloadStatInput checks hourly workload stats for missing identifiers and negative or NaN/Inf values.
+func validateWorkloadStatInput(stats []HourlyWorkloadStat) *ValidationReport {
+	report := &ValidationReport{Rows: len(stats)}
+	for i, stat := range stats {
+		report.checkRequired(i, "namespace", stat.Namespace)
+		report.checkRequired(i, "workload_name", stat.WorkloadName)
+		report.checkNonNegative(i, "cpu_request", stat.CPURequest)
+		report.checkNonNegative(i, "mem_request", float64(stat.MemRequest))
+		report.checkNonNegative(i, "total_billable_cost", stat.TotalBillableCost)
+		report.checkNonNegative(i, "total_usage_cost", stat.TotalUsageCost)
+		report.checkNonNegative(i, "total_waste_cost", stat.TotalWasteCost)
+	}
+	return report
+}
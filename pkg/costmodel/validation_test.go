@@ -0,0 +1,89 @@
+package costmodel
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateCostInput(t *testing.T) {
+	costs := []DailyNamespaceCost{
+		{Namespace: "ns1", BillableCost: 100, UsageCost: 60, WasteCost: 40},
+		{Namespace: "", BillableCost: 10},
+		{Namespace: "ns3", BillableCost: -5, WasteCost: -1},
+	}
+	report := validateCostInput(costs)
+	want := []ValidationIssue{
+		{Row: 1, Field: "namespace", Reason: "is required"},
+		{Row: 2, Field: "billable_cost", Reason: "cannot be negative"},
+		{Row: 2, Field: "waste_cost", Reason: "cannot be negative"},
+	}
+	if !reflect.DeepEqual(report.Issues, want) {
+		t.Fatalf("issues = %+v, want %+v", report.Issues, want)
+	}
+	if rows := report.InvalidRows(); !reflect.DeepEqual(rows, []int{1, 2}) {
+		t.Errorf("InvalidRows() = %v, want [1 2]", rows)
+	}
+	if report.Rows != 3 || report.Valid() {
+		t.Errorf("report = %+v, want 3 rows with issues", report)
+	}
+}
+
+func TestWithValidation(t *testing.T) {
+	costs := []DailyNamespaceCost{
+		{Namespace: "ns1", BillableCost: 100, UsageCost: 60, WasteCost: 40},
+		{Namespace: "ns2", BillableCost: -100},
+		{Namespace: "ns3", BillableCost: 50, UsageCost: 50},
+	}
+
+	t.Run("off aggregates every row", func(t *testing.T) {
+		got, err := AggregateGlobal(costs)
+		if err != nil {
+			t.Fatalf("AggregateGlobal() error = %v", err)
+		}
+		if got.TotalBillableCost != 50 {
+			t.Errorf("TotalBillableCost = %v, want 50", got.TotalBillableCost)
+		}
+	})
+
+	t.Run("reject returns the report", func(t *testing.T) {
+		var report ValidationReport
+		_, err := AggregateGlobal(costs, WithValidation(ValidationReject, &report))
+		var rerr *ValidationReport
+		if !errors.As(err, &rerr) {
+			t.Fatalf("error = %v, want *ValidationReport", err)
+		}
+		if len(report.Issues) != 1 || report.Issues[0].Row != 1 {
+			t.Errorf("report = %+v, want one issue on row 1", report)
+		}
+	})
+
+	t.Run("quarantine skips invalid rows", func(t *testing.T) {
+		var report ValidationReport
+		got, err := AggregateGlobal(costs, WithValidation(ValidationQuarantine, &report))
+		if err != nil {
+			t.Fatalf("AggregateGlobal() error = %v", err)
+		}
+		if got.TotalBillableCost != 150 {
+			t.Errorf("TotalBillableCost = %v, want 150", got.TotalBillableCost)
+		}
+		if rows := report.InvalidRows(); !reflect.DeepEqual(rows, []int{1}) {
+			t.Errorf("quarantined rows = %v, want [1]", rows)
+		}
+	})
+
+	t.Run("workload stats", func(t *testing.T) {
+		stats := []HourlyWorkloadStat{
+			{Namespace: "ns1", WorkloadName: "api", TotalBillableCost: 10},
+			{Namespace: "ns1", WorkloadName: "", TotalBillableCost: 10},
+		}
+		var report ValidationReport
+		got, err := AggregateByWorkload(stats, WithValidation(ValidationQuarantine, &report))
+		if err != nil {
+			t.Fatalf("AggregateByWorkload() error = %v", err)
+		}
+		if len(got) != 1 || len(report.Issues) != 1 || report.Issues[0].Field != "workload_name" {
+			t.Errorf("result = %v, report = %+v, want only the named workload", got, report)
+		}
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/myxxhui/lighthouse-src/internal/data/k8s"
+	"github.com/myxxhui/lighthouse-src/internal/data/mockid"
 	"github.com/myxxhui/lighthouse-src/internal/data/postgres"
 	"github.com/myxxhui/lighthouse-src/internal/data/prometheus"
 	"github.com/myxxhui/lighthouse-src/pkg/costmodel"
@@ -43,6 +44,7 @@ type Config struct {
 	DataSize    DataSize `json:"data_size"`
 	OutputDir   string   `json:"output_dir"`
 	Seed        int64    `json:"seed"`
+	IDMode      string   `json:"id_mode"`
 	Verbose     bool     `json:"verbose"`
 	GenerateAll bool     `json:"generate_all"`
 }
@@ -53,6 +55,7 @@ func main() {
 		dataSize       = flag.String("data-size", "medium", "Data size: small, medium, large")
 		outputDir      = flag.String("output-dir", "./testdata/generated", "Output directory for generated data")
 		seed           = flag.Int64("seed", 42, "Random seed for deterministic generation")
+		idMode         = flag.String("id-mode", "random", "ID generation: random, deterministic (seeded UUIDv5, stable across runs)")
 		verbose        = flag.Bool("verbose", false, "Enable verbose logging")
 		generateAll    = flag.Bool("all", false, "Generate all data types")
 		prometheusFlag = flag.Bool("prometheus", false, "Generate Prometheus mock data")
@@ -69,6 +72,7 @@ func main() {
 		DataSize:    DataSize(*dataSize),
 		OutputDir:   *outputDir,
 		Seed:        *seed,
+		IDMode:      *idMode,
 		Verbose:     *verbose,
 		GenerateAll: *generateAll,
 	}
@@ -91,6 +95,10 @@ func main() {
 		generatePostgres = true
 	}
 
+	if !mockid.Valid(config.IDMode) {
+		log.Fatalf("Invalid id mode %q: want %s or %s", config.IDMode, mockid.ModeRandom, mockid.ModeDeterministic)
+	}
+
 	// Create output directory
 	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
 		log.Fatalf("Failed to create output directory: %v", err)
@@ -197,6 +205,7 @@ func generateK8sData(ctx context.Context, config Config) error {
 		PodsPerDeployment:       2,
 		EventsPerResource:       5,
 		RandomSeed:              config.Seed,
+		IDMode:                  config.IDMode,
 		ErrorRate:               0.0,
 		LatencyMs:               0,
 	}
@@ -261,6 +270,7 @@ func generatePostgresData(ctx context.Context, config Config) error {
 		ErrorRate:             0.0,
 		LatencyMs:             0,
 		EnableTransactions:    true,
+		IDMode:                config.IDMode,
 	}
 
 	repo := postgres.NewMockRepository(postgresConfig)
@@ -23,6 +23,7 @@ export default defineConfig({
         { path: '/ROIDashboard', redirect: '/CostOverviewPage?tab=roi' },
         { path: '/PreventionPage', component: '@/pages/PreventionPage' },
         { path: '/FaultHandlingPage', component: '@/pages/FaultHandlingPage' },
+        { path: '/SelfObservabilityPage', component: '@/pages/SelfObservabilityPage' },
       ],
     },
   ],
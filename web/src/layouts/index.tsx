@@ -7,6 +7,7 @@ import {
   DashboardOutlined,
   SafetyCertificateOutlined,
   ToolOutlined,
+  MonitorOutlined,
 } from '@ant-design/icons';
 
 const defaultProps = {
@@ -18,6 +19,7 @@ const defaultProps = {
       { path: '/SLODashboard', name: 'SLO 红绿灯', icon: <DashboardOutlined /> },
       { path: '/PreventionPage', name: '智能预防', icon: <SafetyCertificateOutlined /> },
       { path: '/FaultHandlingPage', name: '智能故障处理', icon: <ToolOutlined /> },
+      { path: '/SelfObservabilityPage', name: '自观测', icon: <MonitorOutlined /> },
     ],
   },
   location: { pathname: '/' },
@@ -0,0 +1,141 @@
+import React, { useEffect, useState } from 'react';
+import { Card, Table, Tag, Statistic, Row, Col, Alert, Spin } from 'antd';
+import { LoadingOutlined } from '@ant-design/icons';
+import { costService } from '@/services/costService';
+import type { SelfSLOResponse, SelfSLORoute } from '@/types';
+
+// 自观测：Lighthouse 自身 API 的按路由可用性 / P95 延迟 SLO，数据来自 /metrics 同源的请求计数
+const SelfObservabilityPage: React.FC = () => {
+  const [data, setData] = useState<SelfSLOResponse | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    costService
+      .getSelfSLO()
+      .then(setData)
+      .catch(err => setError(err?.message ?? '未知错误'))
+      .finally(() => setLoading(false));
+  }, []);
+
+  const getStatusTag = (status: string) => {
+    switch (status) {
+      case 'healthy':
+        return <Tag color="success">健康</Tag>;
+      case 'warning':
+        return <Tag color="warning">警告</Tag>;
+      case 'critical':
+        return <Tag color="error">严重</Tag>;
+      default:
+        return <Tag>未知</Tag>;
+    }
+  };
+
+  const columns = [
+    {
+      title: '路由',
+      key: 'route',
+      render: (_: unknown, record: SelfSLORoute) => (
+        <span>
+          <Tag>{record.method}</Tag>
+          <strong>{record.route}</strong>
+        </span>
+      ),
+    },
+    {
+      title: '状态',
+      dataIndex: 'status',
+      key: 'status',
+      render: (status: string) => getStatusTag(status),
+    },
+    {
+      title: '请求数',
+      dataIndex: 'requests',
+      key: 'requests',
+      sorter: (a: SelfSLORoute, b: SelfSLORoute) => a.requests - b.requests,
+    },
+    {
+      title: '可用性 (%)',
+      key: 'availability',
+      render: (_: unknown, record: SelfSLORoute) =>
+        `${record.availability.toFixed(3)}% / ${record.availability_target}%`,
+      sorter: (a: SelfSLORoute, b: SelfSLORoute) => a.availability - b.availability,
+    },
+    {
+      title: 'P95 延迟 (ms)',
+      key: 'latency',
+      render: (_: unknown, record: SelfSLORoute) =>
+        `${Math.round(record.latency_p95_ms)} / ${record.latency_target_ms}`,
+      sorter: (a: SelfSLORoute, b: SelfSLORoute) => a.latency_p95_ms - b.latency_p95_ms,
+    },
+    {
+      title: 'P99 延迟 (ms)',
+      dataIndex: 'latency_p99_ms',
+      key: 'latency_p99_ms',
+      render: (value: number) => Math.round(value),
+    },
+    {
+      title: '剩余错误预算 (%)',
+      dataIndex: 'error_budget_remaining',
+      key: 'error_budget_remaining',
+      render: (value: number) => value.toFixed(1),
+      sorter: (a: SelfSLORoute, b: SelfSLORoute) => a.error_budget_remaining - b.error_budget_remaining,
+    },
+  ];
+
+  if (loading) {
+    return (
+      <div style={{ textAlign: 'center', padding: '40px' }}>
+        <Spin indicator={<LoadingOutlined spin />} />
+        <p>加载自观测数据中...</p>
+      </div>
+    );
+  }
+  if (error || !data) {
+    return <Alert message="加载自身 API SLO 失败" description={error} type="error" showIcon />;
+  }
+
+  const count = (status: string) => data.routes.filter(r => r.status === status).length;
+
+  return (
+    <div>
+      <h2>自观测：Lighthouse API SLO</h2>
+      <p>
+        窗口 {new Date(data.window_start).toLocaleString()} - {new Date(data.window_end).toLocaleString()}，
+        默认目标可用性 {data.availability_target}% / P95 {data.latency_target_ms}ms
+      </p>
+      <Row gutter={[16, 16]} style={{ marginBottom: 16 }}>
+        <Col xs={24} sm={12} md={6}>
+          <Card>
+            <Statistic title="整体状态" valueRender={() => getStatusTag(data.status)} />
+          </Card>
+        </Col>
+        <Col xs={24} sm={12} md={6}>
+          <Card>
+            <Statistic title="健康路由" value={count('healthy')} valueStyle={{ color: '#52c41a' }} />
+          </Card>
+        </Col>
+        <Col xs={24} sm={12} md={6}>
+          <Card>
+            <Statistic title="警告路由" value={count('warning')} valueStyle={{ color: '#faad14' }} />
+          </Card>
+        </Col>
+        <Col xs={24} sm={12} md={6}>
+          <Card>
+            <Statistic title="严重路由" value={count('critical')} valueStyle={{ color: '#ff4d4f' }} />
+          </Card>
+        </Col>
+      </Row>
+      <Card title="路由 SLO 详情">
+        <Table
+          dataSource={data.routes}
+          columns={columns}
+          rowKey={r => `${r.method} ${r.route}`}
+          pagination={{ pageSize: 20 }}
+        />
+      </Card>
+    </div>
+  );
+};
+
+export default SelfObservabilityPage;
@@ -4,6 +4,7 @@ import {
   NamespaceCost,
   DrilldownItem,
   SLOStatus,
+  SelfSLOResponse,
   ROITrend,
   SLOScope,
   CostTimeRange,
@@ -47,11 +48,11 @@ export const costService = {
   async getNamespaceCosts(params?: { period?: CostTimeRange }): Promise<NamespaceCost[]> {
     try {
       const query = params?.period ? { period: params.period } : {};
-      const response = await apiClient.get<NamespaceCostSummaryApiItem[]>(
-        `${COST_API_PREFIX}/namespaces`,
-        { params: query },
-      );
-      return adaptNamespacesToNamespaceCosts(response.data);
+      const response = await apiClient.get<
+        { items?: NamespaceCostSummaryApiItem[] } | NamespaceCostSummaryApiItem[]
+      >(`${COST_API_PREFIX}/namespaces`, { params: query });
+      const data = response.data;
+      return adaptNamespacesToNamespaceCosts(Array.isArray(data) ? data : (data?.items ?? []));
     } catch (error) {
       console.error('Failed to fetch namespace costs:', error);
       throw error;
@@ -91,6 +92,17 @@ export const costService = {
     }
   },
 
+  // 获取 Lighthouse 自身 API 的按路由 SLO (GET /api/v1/slo/self)
+  async getSelfSLO(): Promise<SelfSLOResponse> {
+    try {
+      const response = await apiClient.get<SelfSLOResponse>('/v1/slo/self');
+      return response.data;
+    } catch (error) {
+      console.error('Failed to fetch self SLO:', error);
+      throw error;
+    }
+  },
+
   // 获取ROI趋势数据 (GET /api/v1/roi/dashboard)
   async getROITrends(): Promise<ROITrend[]> {
     try {
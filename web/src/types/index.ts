@@ -101,6 +101,34 @@ export interface SLOStatus {
   scopeName?: string;
 }
 
+/** Lighthouse 自身 API 单个路由的 SLO（GET /api/v1/slo/self） */
+export interface SelfSLORoute {
+  method: string;
+  route: string;
+  status: 'healthy' | 'warning' | 'critical';
+  requests: number;
+  errors: number;
+  availability: number;
+  availability_target: number;
+  latency_p50_ms: number;
+  latency_p95_ms: number;
+  latency_p99_ms: number;
+  latency_target_ms: number;
+  slow_requests: number;
+  availability_budget_remaining: number;
+  latency_budget_remaining: number;
+  error_budget_remaining: number;
+}
+
+export interface SelfSLOResponse {
+  window_start: string;
+  window_end: string;
+  availability_target: number;
+  latency_target_ms: number;
+  status: 'healthy' | 'warning' | 'critical';
+  routes: SelfSLORoute[];
+}
+
 export interface ROITrend {
   date: string;
   value: number;